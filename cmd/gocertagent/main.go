@@ -0,0 +1,88 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentclient"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentserver"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentutils"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/mainutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+var (
+	socketPath       = flag.String("socket", mainutils.EnvOrDefault("SOCKET", "/tmp/gocertagent.sock"), "path of the Workload API socket")
+	serverUrl        = flag.String("server", mainutils.EnvOrDefault("SERVER", "http://localhost:8080"), "URL of the gocertcenter server")
+	organization     = flag.String("organization", mainutils.EnvOrDefault("ORGANIZATION", ""), "ID of the organization which owns the trust domain")
+	rootSerialNumber = flag.String("root", mainutils.EnvOrDefault("ROOT", ""), "serial number of the certificate which signs SVIDs")
+	entriesFile      = flag.String("entries", mainutils.EnvOrDefault("ENTRIES", "./entries.json"), "registration entries file")
+	rotationInterval = flag.Duration("rotation-interval", agentcontrollers.DefaultRotationInterval, "how often SVIDs are checked for rotation")
+)
+
+func main() {
+
+	flag.Parse()
+
+	organizationID, err := apputils.ParseBigInt(*organization, 10)
+	if err != nil {
+		log.Fatalf("[main]: Invalid organization: %v", err)
+	}
+	rootSerial, err := apputils.ParseBigInt(*rootSerialNumber, 10)
+	if err != nil {
+		log.Fatalf("[main]: Invalid root serial number: %v", err)
+	}
+
+	entries, err := agentutils.ReadRegistrationEntries(managers.NewFileManager(), *entriesFile)
+	if err != nil {
+		log.Fatalf("[main]: Failed to read registration entries: %v", err)
+	}
+
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+
+	client := agentclient.NewHttpAuthorityClient(
+		*serverUrl,
+		organizationID,
+		rootSerial,
+		managers.NewNetworkManager(),
+		certManager,
+	)
+
+	svidController := agentcontrollers.NewSvidController(entries, client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go svidController.Run(ctx, *rotationInterval)
+
+	listener, err := agentserver.ListenUnix(*socketPath)
+	if err != nil {
+		log.Fatalf("[main]: Failed to listen: %v", err)
+	}
+	server := agentserver.NewGrpcServer(svidController)
+
+	// Setup signal handling for graceful shutdown
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-shutdown
+		log.Printf("[main]: Shutting down: %s", *socketPath)
+		cancel()
+		server.Stop()
+	}()
+
+	log.Printf("[main]: Starting Workload API: %s (%d registration entries, rotation every %s)", *socketPath, len(entries), rotationInterval.Round(time.Second))
+	if err := server.Serve(listener); err != nil {
+		log.Printf("[main]: Failed to serve: %v", err)
+	}
+
+}
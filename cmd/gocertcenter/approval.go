@@ -0,0 +1,58 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// openApprovals creates the approval controller, or returns nil if no
+// approvers have been defined
+//   - repository: The repository of the operations
+//   - approvers: Comma separated approvers as name:token
+//   - threshold: The number of approvals needed
+//   - expiration: The lifetime of pending operations, e.g. "24h"
+func openApprovals(
+	repository appmodels.PendingOperationRepository,
+	approvers string,
+	threshold string,
+	expiration string,
+	randomManager managers.RandomManager,
+) (*appcontrollers.CertApprovalController, error) {
+	if strings.TrimSpace(approvers) == "" {
+		return nil, nil
+	}
+	tokens := make(map[string]string)
+	for i, item := range strings.Split(approvers, ",") {
+		name, token, found := strings.Cut(strings.TrimSpace(item), ":")
+		if !found || name == "" || token == "" {
+			return nil, fmt.Errorf("invalid approver %d, expected name:token", i+1)
+		}
+		if _, exists := tokens[token]; exists {
+			return nil, fmt.Errorf("approver token defined twice: %s", name)
+		}
+		tokens[token] = name
+	}
+	count, err := strconv.Atoi(threshold)
+	if err != nil {
+		return nil, fmt.Errorf("invalid approval threshold: %w", err)
+	}
+	duration, err := time.ParseDuration(expiration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid approval expiration: %w", err)
+	}
+	return appcontrollers.NewApprovalController(
+		repository,
+		tokens,
+		count,
+		duration,
+		randomManager,
+	)
+}
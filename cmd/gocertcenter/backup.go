@@ -0,0 +1,171 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/mainutils"
+)
+
+// backupCommands are the subcommands which run instead of the server
+var backupCommands = map[string]func(controller appmodels.BackupController, args []string) error{
+	"backup":  backupCommand,
+	"restore": restoreCommand,
+}
+
+// readBackupTrustAnchors returns the root certificates in a PEM file which
+// are trusted to sign backups, or nil if the file is not defined
+func readBackupTrustAnchors(file string) ([]*x509.Certificate, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust anchors: %w", err)
+	}
+	var anchors []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse trust anchor: %w", err)
+		}
+		anchors = append(anchors, certificate)
+	}
+	if len(anchors) == 0 {
+		return nil, fmt.Errorf("no certificates in %s", file)
+	}
+	return anchors, nil
+}
+
+// backupPassphrase returns the passphrase from a file, or from the
+// BACKUP_PASSPHRASE environment variable
+func backupPassphrase(file string) (string, error) {
+	return readPassphrase("-passphrase-file", file, "BACKUP_PASSPHRASE")
+}
+
+// readPassphrase returns the passphrase from a file given with the flag, or
+// from the environment variable
+func readPassphrase(flagName, file, variable string) (string, error) {
+	if file == "" {
+		passphrase := mainutils.EnvOrDefault(variable, "")
+		if passphrase == "" {
+			return "", fmt.Errorf("passphrase must be defined with %s or %s", flagName, variable)
+		}
+		return passphrase, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	passphrase := strings.TrimRight(string(data), "\r\n")
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase file is empty: %s", file)
+	}
+	return passphrase, nil
+}
+
+// backupCommand writes a backup of an organization:
+//
+//	gocertcenter [flags] backup -organization <id> -output <file>
+func backupCommand(controller appmodels.BackupController, args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	organizationFlag := flags.String("organization", "", "ID of the organization")
+	output := flags.String("output", "", "file to write the backup archive to")
+	passphraseFile := flags.String("passphrase-file", "", "file containing the passphrase (default BACKUP_PASSPHRASE)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *organizationFlag == "" || *output == "" {
+		flags.Usage()
+		return fmt.Errorf("-organization and -output must be defined")
+	}
+
+	organization, err := apputils.ParseBigInt(*organizationFlag, 10)
+	if err != nil {
+		return fmt.Errorf("invalid organization: %w", err)
+	}
+	passphrase, err := backupPassphrase(*passphraseFile)
+	if err != nil {
+		return err
+	}
+
+	archive, err := controller.Backup(organization, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*output, archive, 0600); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	log.Printf("[backup]: Organization %s saved to %s", organization, *output)
+	return nil
+}
+
+// restoreCommand verifies and restores a backup of an organization:
+//
+//	gocertcenter [flags] restore -input <file> [-verify] [-force]
+func restoreCommand(controller appmodels.BackupController, args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	input := flags.String("input", "", "file to read the backup archive from")
+	passphraseFile := flags.String("passphrase-file", "", "file containing the passphrase (default BACKUP_PASSPHRASE)")
+	verify := flags.Bool("verify", false, "only verify the backup without restoring it")
+	force := flags.Bool("force", false, "restore even if the data directory has newer certificates than the backup")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *input == "" {
+		flags.Usage()
+		return fmt.Errorf("-input must be defined")
+	}
+
+	passphrase, err := backupPassphrase(*passphraseFile)
+	if err != nil {
+		return err
+	}
+	archive, err := os.ReadFile(*input)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	var backup appmodels.OrganizationBackup
+	if *verify {
+		backup, err = controller.Verify(archive, passphrase, *force)
+	} else {
+		backup, err = controller.Restore(archive, passphrase, *force)
+	}
+	if err != nil {
+		return err
+	}
+
+	action := "restored"
+	if *verify {
+		action = "verified"
+	}
+	log.Printf(
+		"[restore]: Organization %s %s: %d certificates, %d private keys and %d revocations from %s",
+		backup.Organization().ID(),
+		action,
+		len(backup.Certificates()),
+		len(backup.PrivateKeys()),
+		len(backup.Revocations()),
+		backup.CreatedAt().UTC().Format(time.RFC3339),
+	)
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// keyShareCommands are the subcommands which export and import private
+// keys as key shares instead of running the server
+var keyShareCommands = map[string]func(controller appmodels.BackupController, certManager managers.CertificateManager, args []string) error{
+	"key-shares-export": keyShareExportCommand,
+	"key-shares-import": keyShareImportCommand,
+}
+
+// keyShareExportCommand splits the private key of a root or intermediate
+// certificate into key shares, one file each:
+//
+//	gocertcenter [flags] key-shares-export -organization <id> -certificate <serial> -shares <n> -threshold <m> -output <dir>
+func keyShareExportCommand(controller appmodels.BackupController, certManager managers.CertificateManager, args []string) error {
+	flags := flag.NewFlagSet("key-shares-export", flag.ExitOnError)
+	organizationFlag := flags.String("organization", "", "ID of the organization")
+	certificateFlag := flags.String("certificate", "", "serial number of the root or intermediate certificate")
+	shares := flags.Int("shares", 0, "number of key shares")
+	threshold := flags.Int("threshold", 0, "number of key shares needed to import the key")
+	output := flags.String("output", "", "directory to write the key shares to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *organizationFlag == "" || *certificateFlag == "" || *output == "" {
+		flags.Usage()
+		return fmt.Errorf("-organization, -certificate and -output must be defined")
+	}
+
+	organization, err := apputils.ParseBigInt(*organizationFlag, 10)
+	if err != nil {
+		return fmt.Errorf("invalid organization: %w", err)
+	}
+	certificate, err := apputils.ParseBigInt(*certificateFlag, 10)
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %w", err)
+	}
+
+	list, err := controller.ExportKeyShares(organization, certificate, *shares, *threshold)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*output, 0700); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for _, share := range list {
+		data, err := apputils.MarshalPrivateKeyShareAsPEM(certManager, share)
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(*output, fmt.Sprintf("%s-share-%d.pem", certificate, share.Index()))
+		if err := os.WriteFile(file, data, 0600); err != nil {
+			return fmt.Errorf("failed to write key share: %w", err)
+		}
+	}
+	log.Printf("[key-shares-export]: Private key of %s saved to %s as %d shares with threshold %d", certificate, *output, *shares, *threshold)
+	return nil
+}
+
+// keyShareImportCommand reconstructs the private key of a certificate from
+// key share files and saves it:
+//
+//	gocertcenter [flags] key-shares-import -organization <id> -certificate <serial> <share file>...
+func keyShareImportCommand(controller appmodels.BackupController, certManager managers.CertificateManager, args []string) error {
+	flags := flag.NewFlagSet("key-shares-import", flag.ExitOnError)
+	organizationFlag := flags.String("organization", "", "ID of the organization")
+	certificateFlag := flags.String("certificate", "", "serial number of the root or intermediate certificate")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *organizationFlag == "" || *certificateFlag == "" || flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("-organization, -certificate and share files must be defined")
+	}
+
+	organization, err := apputils.ParseBigInt(*organizationFlag, 10)
+	if err != nil {
+		return fmt.Errorf("invalid organization: %w", err)
+	}
+	certificate, err := apputils.ParseBigInt(*certificateFlag, 10)
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %w", err)
+	}
+
+	var shares []appmodels.PrivateKeyShare
+	for _, file := range flags.Args() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read key share: %w", err)
+		}
+		list, err := apputils.ParsePrivateKeySharesFromPEMBytes(certManager, data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		shares = append(shares, list...)
+	}
+
+	key, err := controller.ImportKeyShares(organization, certificate, shares)
+	if err != nil {
+		return err
+	}
+	log.Printf("[key-shares-import]: Private key of %s reconstructed from %d shares", key.SerialNumber(), len(shares))
+	return nil
+}
@@ -3,6 +3,8 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
@@ -12,17 +14,54 @@ import (
 	"syscall"
 	"time"
 
+	"google.golang.org/grpc"
+
 	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
 	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories"
 	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sealedrepository"
 	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
 	"github.com/hyperifyio/gocertcenter/internal/common/mainutils"
 	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdscontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdsserver"
 )
 
 var (
-	listenPort = flag.String("port", mainutils.EnvOrDefault("PORT", "8080"), "port on which the server listens")
-	dataDir    = flag.String("data-dir", mainutils.EnvOrDefault("DATA_DIR", "./tmp/data"), "application data directory")
+	listenPort  = flag.String("port", mainutils.EnvOrDefault("PORT", "8080"), "port on which the server listens")
+	dataDir     = flag.String("data-dir", mainutils.EnvOrDefault("DATA_DIR", "./tmp/data"), "application data directory")
+	storage     = flag.String("storage", mainutils.EnvOrDefault("STORAGE", "file"), "storage backend for certificates and EST, SCEP, Vault and ACME state: memory, file[:<dir>], sql[:sqlite:<file>|:postgres://<dsn>] or bolt[:<file>]")
+	readOnly    = flag.Bool("read-only", mainutils.EnvOrDefault("READ_ONLY", "") == "true", "respond 503 to requests which would change stored data")
+	sdsAddress  = flag.String("sds-address", mainutils.EnvOrDefault("SDS_ADDRESS", ""), "address on which the Envoy SDS server listens, e.g. unix:/run/gocertcenter/sds.sock (disabled if empty)")
+	sdsAllowTcp = flag.Bool("sds-allow-tcp", mainutils.EnvOrDefault("SDS_ALLOW_TCP", "") == "true", "allow a TCP address for the Envoy SDS server, which does not authenticate clients")
+	vaultToken  = flag.String("vault-token", mainutils.EnvOrDefault("VAULT_TOKEN", ""), "X-Vault-Token required by the Vault PKI API at /v1/pki/{organization} (disabled if empty)")
+	backupToken = flag.String("backup-token", mainutils.EnvOrDefault("BACKUP_TOKEN", ""), "X-Backup-Token required by the organization backup and restore API (disabled if empty)")
+
+	backupTrustAnchors = flag.String("backup-trust-anchors", mainutils.EnvOrDefault("BACKUP_TRUST_ANCHORS", ""), "PEM file of root certificates trusted to sign restored backups in addition to the stored roots of the organization")
+
+	trustedProxies = flag.String("trusted-proxies", mainutils.EnvOrDefault("TRUSTED_PROXIES", ""), "comma separated addresses or networks of reverse proxies whose X-Forwarded-Proto is trusted (disabled if empty)")
+
+	sealFile             = flag.String("seal-file", mainutils.EnvOrDefault("SEAL_FILE", ""), "seal file created with seal-init; private keys are encrypted and the server starts sealed (disabled if empty)")
+	sealToken            = flag.String("seal-token", mainutils.EnvOrDefault("SEAL_TOKEN", ""), "X-Seal-Token required by the seal and unseal API (disabled if empty)")
+	unsealPassphraseFile = flag.String("unseal-passphrase-file", mainutils.EnvOrDefault("UNSEAL_PASSPHRASE_FILE", ""), "file containing the passphrase to unseal at startup (default UNSEAL_PASSPHRASE)")
+
+	approvers          = flag.String("approvers", mainutils.EnvOrDefault("APPROVERS", ""), "comma separated name:token pairs of approvers whose X-Approval-Token approves sensitive operations (disabled if empty)")
+	approvalThreshold  = flag.String("approval-threshold", mainutils.EnvOrDefault("APPROVAL_THRESHOLD", "2"), "number of approvers besides the requester who must approve creating or revoking roots, creating intermediates, exporting or importing CA keys, and backing up or restoring organizations")
+	approvalExpiration = flag.String("approval-expiration", mainutils.EnvOrDefault("APPROVAL_EXPIRATION", "24h"), "how long pending operations can be approved and executed")
+
+	tlsCertFile   = flag.String("tls-cert-file", mainutils.EnvOrDefault("TLS_CERT_FILE", ""), "PEM file of the TLS server certificate and its chain (TLS disabled if empty, unless bootstrapped)")
+	tlsKeyFile    = flag.String("tls-key-file", mainutils.EnvOrDefault("TLS_KEY_FILE", ""), "PEM file of the private key of the TLS server certificate")
+	tlsBootstrap  = flag.Bool("tls-bootstrap", mainutils.EnvOrDefault("TLS_BOOTSTRAP", "") == "true", "serve TLS with a certificate issued and renewed from the system organization")
+	tlsHostnames  = flag.String("tls-hostnames", mainutils.EnvOrDefault("TLS_HOSTNAMES", "localhost"), "comma separated DNS names of the bootstrapped server certificate")
+	tlsExpiration = flag.String("tls-expiration", mainutils.EnvOrDefault("TLS_EXPIRATION", "720h"), "lifetime of bootstrapped server certificates, which are renewed after half of it")
+	tlsRootFile   = flag.String("tls-root-file", mainutils.EnvOrDefault("TLS_ROOT_FILE", ""), "file to save the bootstrapped system root certificate to for clients (disabled if empty)")
+
+	tlsOrganization = flag.String("tls-organization", mainutils.EnvOrDefault("TLS_ORGANIZATION", fmt.Sprint(appcontrollers.DefaultSystemOrganizationID)), "ID of the system organization which issues bootstrapped server certificates, created if missing")
+
+	archiveRetention = flag.String("archive-retention", mainutils.EnvOrDefault("ARCHIVE_RETENTION", "2160h"), "how long expired and superseded certificates are kept before archiving")
+	archiveInterval  = flag.String("archive-interval", mainutils.EnvOrDefault("ARCHIVE_INTERVAL", "1h"), "interval of archiving expired and superseded certificates (disabled if 0)")
 )
 
 func main() {
@@ -36,27 +75,241 @@ func main() {
 	randomManager := managers.NewRandomManager()
 	certManager := managers.NewCertificateManager(randomManager)
 
-	// fileManager := managers.NewFileManager()
-	// repository := filerepository.NewCollection(certManager, fileManager, *dataDir)
+	fileManager := managers.NewFileManager()
+
+	registry := apprepositories.NewDefaultRegistry()
+
+	if flag.Arg(0) == "migrate" {
+		if err := migrateCommand(registry, certManager, fileManager, flag.Args()[1:]); err != nil {
+			log.Fatalf("[main]: migrate: %v", err)
+		}
+		return
+	}
+
+	if command, exists := sealClientCommands[flag.Arg(0)]; exists {
+		if err := command(flag.Args()[1:]); err != nil {
+			log.Fatalf("[main]: %s: %v", flag.Arg(0), err)
+		}
+		return
+	}
+
+	repository, closeRepository, err := registry.Open(certManager, fileManager, *storage, *dataDir, *readOnly)
+	if err != nil {
+		log.Fatalf("[main]: Failed to open the storage: %v", err)
+	}
+	defer func() {
+		if err := closeRepository(); err != nil {
+			log.Printf("[main]: Failed to close the storage: %v", err)
+		}
+	}()
+
+	if flag.Arg(0) == "seal-init" {
+		if err := sealInitCommand(repository, certManager, *sealFile, flag.Args()[1:]); err != nil {
+			log.Fatalf("[main]: seal-init: %v", err)
+		}
+		return
+	}
+
+	// Private keys are encrypted with the seal key before they reach the storage
+	var sealController *appcontrollers.CertSealController
+	if *sealFile != "" {
+		sealController, err = openSeal(*sealFile, *sealToken, *unsealPassphraseFile)
+		if err != nil {
+			log.Fatalf("[main]: Failed to open the seal: %v", err)
+		}
+		defer sealController.Seal()
+		repository = sealedrepository.NewCollection(repository, sealController, certManager)
+	}
 
-	repository := memoryrepository.NewCollection()
 	defaultExpiration := 24 * time.Hour
 
 	appController := appcontrollers.NewApplicationController(
 		repository.Organization,
 		repository.Certificate,
 		repository.PrivateKey,
+		repository.UnitOfWork,
 		certManager,
 		randomManager,
 		defaultExpiration,
 	)
+	appController.SetRevocationRepository(repository.CertificateRevocation)
+
+	// Sensitive operations of the REST API, EST, Vault and SDS require
+	// approvals. The commands above run with local access to the storage
+	// and need none.
+	approvalController, err := openApprovals(repository.PendingOperation, *approvers, *approvalThreshold, *approvalExpiration, randomManager)
+	if err != nil {
+		log.Fatalf("[main]: Failed to configure approvals: %v", err)
+	}
+	var apiAppController appmodels.ApplicationController = appController
+	if approvalController != nil {
+		apiAppController = appcontrollers.NewApprovalApplicationController(appController, approvalController)
+	}
 
-	server, err := apiserver.NewServer(listenAddr, nil)
+	// Revocations are shared by the Vault PKI API and backups
+	revocationRepository := repository.CertificateRevocation
+
+	trustAnchors, err := readBackupTrustAnchors(*backupTrustAnchors)
+	if err != nil {
+		log.Fatalf("[main]: %v", err)
+	}
+
+	backup, isBackup := backupCommands[flag.Arg(0)]
+	keyShare, isKeyShare := keyShareCommands[flag.Arg(0)]
+	if isBackup || isKeyShare {
+		backupController := appcontrollers.NewBackupController(
+			repository.Organization,
+			repository.Certificate,
+			repository.PrivateKey,
+			repository.UnitOfWork,
+			revocationRepository,
+			repository,
+			certManager,
+			trustAnchors,
+			"",
+		)
+		if isBackup {
+			err = backup(backupController, flag.Args()[1:])
+		} else {
+			err = keyShare(backupController, certManager, flag.Args()[1:])
+		}
+		if err != nil {
+			log.Fatalf("[main]: %s: %v", flag.Arg(0), err)
+		}
+		return
+	}
+
+	// ACME nonces are single use and short lived, so they stay in memory. A
+	// client retries with a fresh nonce after a restart (RFC 8555 section
+	// 6.5).
+	acmeController := appcontrollers.NewAcmeController(
+		repository.AcmeAccount,
+		repository.AcmeOrder,
+		repository.AcmeAuthorization,
+		memoryrepository.NewAcmeNonceRepository(0),
+		managers.NewNetworkManager(),
+		randomManager,
+		defaultExpiration,
+	)
+
+	estController := appcontrollers.NewEstController(
+		repository.EstLabel,
+		repository.CertificateRevocation,
+		apiAppController,
+		defaultExpiration,
+	)
+
+	scepController := appcontrollers.NewScepController(
+		repository.ScepChallenge,
+		repository.PrivateKey,
+		revocationRepository,
+		defaultExpiration,
+	)
+
+	sshController := appcontrollers.NewSshController(
+		repository.SshAuthority,
+		repository.SshCertificate,
+		randomManager,
+		defaultExpiration,
+	)
+
+	// The server certificate of the system organization is issued with the
+	// application controller without approvals, like the commands above
+	var tlsConfig *tls.Config
+	var tlsController *appcontrollers.CertTlsController
+	clientAuthorities := appcontrollers.NewTlsClientAuthorities(appController, repository.CertificateRevocation)
+	if *tlsBootstrap {
+		if *tlsCertFile != "" || *tlsKeyFile != "" {
+			log.Fatalf("[main]: -tls-bootstrap cannot be used with -tls-cert-file or -tls-key-file")
+		}
+		if *readOnly {
+			log.Fatalf("[main]: -tls-bootstrap issues certificates and cannot be used in the read-only mode")
+		}
+		tlsConfig, tlsController, err = openTlsBootstrap(appController, clientAuthorities, repository.CertificateRevocation, certManager, *tlsOrganization, *tlsHostnames, *tlsExpiration, *tlsRootFile)
+	} else if *tlsCertFile != "" || *tlsKeyFile != "" {
+		tlsConfig, err = openTlsFiles(clientAuthorities, *tlsCertFile, *tlsKeyFile)
+	}
+	if err != nil {
+		log.Fatalf("[main]: Failed to configure TLS: %v", err)
+	}
+	if tlsConfig != nil {
+		if err := clientAuthorities.Refresh(time.Now()); err != nil {
+			log.Fatalf("[main]: Failed to find the client CA certificates: %v", err)
+		}
+	}
+
+	var server *apiserver.ApplicationServer
+	if tlsConfig != nil {
+		server, err = apiserver.NewTLSServer(listenAddr, tlsConfig, nil)
+	} else {
+		server, err = apiserver.NewServer(listenAddr, nil)
+	}
 	if err != nil {
 		log.Fatalf("[main]: Failed to create the server: %v", err)
 	}
 
-	apiController := appendpoints.NewHttpApiController(server, appController, certManager)
+	apiController := appendpoints.NewHttpApiController(server, apiAppController, certManager)
+	apiController.SetAcmeController(acmeController)
+	apiController.SetEstController(estController)
+	apiController.SetScepController(scepController)
+	apiController.SetSshController(sshController)
+	apiController.SetReadOnly(*readOnly)
+	proxies, err := parseTrustedProxies(*trustedProxies)
+	if err != nil {
+		log.Fatalf("[main]: %v", err)
+	}
+	apiController.SetTrustedProxies(proxies)
+	if sealController != nil {
+		apiController.SetSealController(sealController)
+	}
+
+	retention, err := time.ParseDuration(*archiveRetention)
+	if err != nil {
+		log.Fatalf("[main]: Invalid archive retention: %v", err)
+	}
+	interval, err := time.ParseDuration(*archiveInterval)
+	if err != nil {
+		log.Fatalf("[main]: Invalid archive interval: %v", err)
+	}
+	lifecycleController := appcontrollers.NewLifecycleController(
+		repository.Organization,
+		repository.Certificate,
+		revocationRepository,
+		retention,
+	)
+	apiController.SetLifecycleController(lifecycleController)
+
+	if *vaultToken != "" {
+		apiController.SetVaultController(appcontrollers.NewVaultController(
+			repository.VaultRole,
+			revocationRepository,
+			apiAppController,
+			*vaultToken,
+			defaultExpiration,
+		))
+	}
+
+	if approvalController != nil {
+		apiController.SetApprovalController(approvalController)
+	}
+
+	if *backupToken != "" {
+		var backupController appmodels.BackupController = appcontrollers.NewBackupController(
+			repository.Organization,
+			repository.Certificate,
+			repository.PrivateKey,
+			repository.UnitOfWork,
+			revocationRepository,
+			repository,
+			certManager,
+			trustAnchors,
+			*backupToken,
+		)
+		if approvalController != nil {
+			backupController = appcontrollers.NewApprovalBackupController(backupController, approvalController)
+		}
+		apiController.SetBackupController(backupController)
+	}
 
 	server.SetInfo(apiController.Info())
 
@@ -64,10 +317,66 @@ func main() {
 		log.Fatalf("[main]: Failed to setup routes: %v", err)
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if interval > 0 && !*readOnly {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lifecycleController.Run(ctx, interval)
+		}()
+	}
+
+	if tlsConfig != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clientAuthorities.Run(ctx, appcontrollers.DefaultTlsRefreshInterval)
+		}()
+	}
+
+	if tlsController != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tlsController.Run(ctx, appcontrollers.DefaultTlsRefreshInterval)
+		}()
+	}
+
+	var sdsServer *grpc.Server
+	if *sdsAddress != "" && *readOnly {
+		log.Fatalf("[main]: The SDS server issues certificates and cannot be used in the read-only mode")
+	}
+	if *sdsAddress != "" {
+		secretController := sdscontrollers.NewSecretController(apiAppController, certManager)
+		sdsServer = sdsserver.NewGrpcServer(ctx, secretController)
+		sdsListener, err := sdsserver.Listen(*sdsAddress, *sdsAllowTcp)
+		if err != nil {
+			log.Fatalf("[main]: Failed to listen SDS address: %v", err)
+		}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			secretController.Run(ctx, sdscontrollers.DefaultRefreshInterval)
+		}()
+		go func() {
+			defer wg.Done()
+			log.Printf("[main]: Starting SDS server: %s", sdsListener.Addr())
+			if err := sdsServer.Serve(sdsListener); err != nil {
+				log.Printf("[main]: SDS server stopped: %v", err)
+			}
+		}()
+	}
+
 	shutdownHandler := func() error {
 		if err := server.Stop(); err != nil {
 			log.Printf("[main]: Failed to stop server: %v", err)
 		}
+		if sdsServer != nil {
+			sdsServer.Stop()
+		}
+		cancel()
 		return nil
 	}
 
@@ -75,7 +384,7 @@ func main() {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
-	log.Printf("[main]: Starting server: %s", server.Address())
+	log.Printf("[main]: Starting server: %s", server.URL())
 	if err := server.Start(); err != nil {
 		log.Printf("[main]: Failed to start server: %v", err)
 	}
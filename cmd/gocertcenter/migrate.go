@@ -0,0 +1,71 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// migrateCommand copies all data from one storage to another and verifies
+// the result:
+//
+//	gocertcenter migrate -from file:/data -to sql:sqlite:/data/gocertcenter.db [-verify]
+func migrateCommand(
+	registry *apprepositories.Registry,
+	certManager managers.CertificateManager,
+	fileManager managers.FileManager,
+	args []string,
+) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	from := flags.String("from", "", "storage to copy from, e.g. file:/data")
+	to := flags.String("to", "", "storage to copy to, e.g. sql:sqlite:/data/gocertcenter.db, sql:postgres://host/db or bolt:/data/gocertcenter.bolt")
+	verifyOnly := flags.Bool("verify", false, "only verify a previous migration without copying")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" {
+		flags.Usage()
+		return fmt.Errorf("-from and -to must be defined")
+	}
+	if *from == *to {
+		return fmt.Errorf("-from and -to must be different")
+	}
+
+	source, closeSource, err := registry.Open(certManager, fileManager, *from, "", false)
+	if err != nil {
+		return fmt.Errorf("failed to open source: %w", err)
+	}
+	defer func() {
+		if err := closeSource(); err != nil {
+			log.Printf("[migrate]: Failed to close source: %v", err)
+		}
+	}()
+
+	target, closeTarget, err := registry.Open(certManager, fileManager, *to, "", false)
+	if err != nil {
+		return fmt.Errorf("failed to open target: %w", err)
+	}
+	defer func() {
+		if err := closeTarget(); err != nil {
+			log.Printf("[migrate]: Failed to close target: %v", err)
+		}
+	}()
+
+	controller := appcontrollers.NewMigrationController(source, target, certManager)
+	if !*verifyOnly {
+		if err := controller.Migrate(); err != nil {
+			return fmt.Errorf("migration failed, run the command again to resume it: %w", err)
+		}
+	}
+	if err := controller.Verify(); err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	log.Printf("[migrate]: %s matches %s", *to, *from)
+	return nil
+}
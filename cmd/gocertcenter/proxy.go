@@ -0,0 +1,40 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// parseTrustedProxies parses a comma separated list of IP addresses and
+// networks of trusted reverse proxies, e.g. "10.0.0.0/8,192.0.2.1"
+func parseTrustedProxies(value string) ([]*net.IPNet, error) {
+	var list []*net.IPNet
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy: %s", item)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			list = append(list, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+		}
+		list = append(list, network)
+	}
+	return list, nil
+}
@@ -0,0 +1,309 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sealedrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// sealClientCommands are the subcommands which unseal or seal a running
+// server
+var sealClientCommands = map[string]func(args []string) error{
+	"unseal": unsealCommand,
+	"seal":   sealCommand,
+}
+
+// openSeal reads the seal file and returns a sealed seal controller. The
+// controller is unsealed if an unseal passphrase has been defined.
+func openSeal(file, token, passphraseFile string) (*appcontrollers.CertSealController, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seal file: %w", err)
+	}
+	config, err := apputils.ParseSealConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seal file: %s: %w", file, err)
+	}
+	controller := appcontrollers.NewSealController(config, token)
+
+	if passphraseFile == "" && os.Getenv("UNSEAL_PASSPHRASE") == "" {
+		log.Printf("[main]: Private keys are sealed until unsealed at POST /seal/unseal")
+		return controller, nil
+	}
+	passphrase, err := readPassphrase("-unseal-passphrase-file", passphraseFile, "UNSEAL_PASSPHRASE")
+	if err != nil {
+		return nil, err
+	}
+	if err := controller.Unseal(passphrase); err != nil {
+		return nil, err
+	}
+	return controller, nil
+}
+
+// sealInitCommand creates the seal file and encrypts the private keys
+// stored before sealing was enabled:
+//
+//	gocertcenter -seal-file <file> [flags] seal-init [-shares <n> -threshold <m>]
+func sealInitCommand(
+	repository *appmodels.Collection,
+	certManager managers.CertificateManager,
+	file string,
+	args []string,
+) error {
+	flags := flag.NewFlagSet("seal-init", flag.ExitOnError)
+	shares := flags.Int("shares", 0, "number of key shares which can unseal instead of the passphrase (disabled if 0)")
+	threshold := flags.Int("threshold", 0, "number of key shares needed to unseal")
+	passphraseFile := flags.String("passphrase-file", "", "file containing the passphrase (default SEAL_PASSPHRASE)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if file == "" {
+		flags.Usage()
+		return fmt.Errorf("-seal-file must be defined")
+	}
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("seal file exists already: %s", file)
+	}
+
+	passphrase, err := readPassphrase("-passphrase-file", *passphraseFile, "SEAL_PASSPHRASE")
+	if err != nil {
+		return err
+	}
+	config, key, keyShares, err := apputils.NewSealConfig(passphrase, *shares, *threshold)
+	if err != nil {
+		return err
+	}
+	clear(key)
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode seal file: %w", err)
+	}
+
+	sealController := appcontrollers.NewSealController(config, "")
+	if err := sealController.Unseal(passphrase); err != nil {
+		return err
+	}
+	defer sealController.Seal()
+
+	output, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create seal file: %w", err)
+	}
+	if _, err := output.Write(data); err != nil {
+		_ = output.Close()
+		return fmt.Errorf("failed to write seal file: %w", err)
+	}
+	if err := output.Close(); err != nil {
+		return fmt.Errorf("failed to write seal file: %w", err)
+	}
+
+	sealed := sealedrepository.NewCollection(repository, sealController, certManager)
+	count, err := sealPrivateKeys(repository, sealed)
+	if err != nil {
+		return err
+	}
+	log.Printf("[seal-init]: Seal file saved to %s and %d private keys encrypted", file, count)
+
+	for i, share := range keyShares {
+		fmt.Printf("Key share %d: %s\n", i+1, apputils.EncodeSealKeyShare(share))
+		clear(share)
+	}
+	if len(keyShares) != 0 {
+		fmt.Printf("%d of %d key shares are needed to unseal. The shares are not stored anywhere.\n", *threshold, *shares)
+	}
+	return nil
+}
+
+// sealPrivateKeys encrypts the private keys of the repository which are not
+// encrypted yet, and returns how many were encrypted
+func sealPrivateKeys(repository, sealed *appmodels.Collection) (int, error) {
+	organizations, err := repository.Organization.FindAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find organizations: %w", err)
+	}
+	count := 0
+	for _, organization := range organizations {
+		certificates, err := repository.Certificate.FindAllByOrganization(organization.ID())
+		if err != nil {
+			return count, fmt.Errorf("failed to find certificates of organization %s: %w", organization.ID(), err)
+		}
+		page, err := repository.Certificate.FindPageByOrganization(organization.ID(), appmodels.CertificateQuery{Archived: true})
+		if err != nil {
+			return count, fmt.Errorf("failed to find archived certificates of organization %s: %w", organization.ID(), err)
+		}
+		for _, certificate := range append(certificates, page.Certificates...) {
+			saved, err := sealPrivateKey(repository, sealed, organization.ID(), certificate.SerialNumber())
+			if err != nil {
+				return count, fmt.Errorf("failed to encrypt private key %s: %w", certificate.SerialNumber(), err)
+			}
+			if saved {
+				count++
+			}
+		}
+		saved, err := sealSshAuthority(repository, sealed, organization.ID())
+		if err != nil {
+			return count, fmt.Errorf("failed to encrypt SSH CA key of organization %s: %w", organization.ID(), err)
+		}
+		if saved {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// sealPrivateKey encrypts one private key if it exists and is not encrypted
+// yet
+func sealPrivateKey(repository, sealed *appmodels.Collection, organization, serialNumber *big.Int) (bool, error) {
+
+	// Certificates without a stored private key are skipped
+	key, err := repository.PrivateKey.FindByOrganizationAndSerialNumber(organization, serialNumber)
+	if err != nil {
+		return false, nil
+	}
+	if _, ok := key.PrivateKey().(appmodels.SealedPrivateKey); ok {
+		return false, nil
+	}
+	if _, err := sealed.PrivateKey.Save(key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sealSshAuthority encrypts the SSH CA key of an organization if it exists
+// and is not encrypted yet
+func sealSshAuthority(repository, sealed *appmodels.Collection, organization *big.Int) (bool, error) {
+	authority, err := repository.SshAuthority.FindByOrganization(organization)
+	if err != nil {
+		return false, nil
+	}
+	if _, ok := authority.PrivateKey().PrivateKey().(appmodels.SealedPrivateKey); ok {
+		return false, nil
+	}
+	if _, err := sealed.SshAuthority.Save(authority); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// unsealCommand unseals a running server with the passphrase or one key
+// share:
+//
+//	gocertcenter -seal-token <token> unseal [-server <url>] [-ca-file <file>] [-share <share>]
+func unsealCommand(args []string) error {
+	flags := flag.NewFlagSet("unseal", flag.ExitOnError)
+	server := flags.String("server", "http://localhost:8080", "URL of the server")
+	share := flags.String("share", "", "key share to unseal with instead of the passphrase")
+	caFile := flags.String("ca-file", "", "PEM file of the certificate authority of the server, e.g. the saved system root (default system roots)")
+	passphraseFile := flags.String("passphrase-file", "", "file containing the passphrase (default UNSEAL_PASSPHRASE)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	body := appdtos.NewUnsealDTO("", *share)
+	if *share == "" {
+		passphrase, err := readPassphrase("-passphrase-file", *passphraseFile, "UNSEAL_PASSPHRASE")
+		if err != nil {
+			return err
+		}
+		body.Passphrase = passphrase
+	}
+	status, err := postSeal(strings.TrimRight(*server, "/")+"/seal/unseal", body, *caFile)
+	if err != nil {
+		return err
+	}
+	if status.Sealed {
+		log.Printf("[unseal]: Sealed, %d of %d key shares provided", status.Progress, status.Threshold)
+	} else {
+		log.Printf("[unseal]: Unsealed")
+	}
+	return nil
+}
+
+// sealCommand seals a running server:
+//
+//	gocertcenter -seal-token <token> seal [-server <url>] [-ca-file <file>]
+func sealCommand(args []string) error {
+	flags := flag.NewFlagSet("seal", flag.ExitOnError)
+	server := flags.String("server", "http://localhost:8080", "URL of the server")
+	caFile := flags.String("ca-file", "", "PEM file of the certificate authority of the server, e.g. the saved system root (default system roots)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if _, err := postSeal(strings.TrimRight(*server, "/")+"/seal", nil, *caFile); err != nil {
+		return err
+	}
+	log.Printf("[seal]: Sealed")
+	return nil
+}
+
+// postSeal sends a request to a seal endpoint and returns the seal status
+//   - url: The URL of the end-point
+//   - body: The request body
+//   - caFile: The PEM file of the certificate authority to trust, or empty
+//     for the system roots
+func postSeal(url string, body any, caFile string) (appdtos.SealStatusDTO, error) {
+	var status appdtos.SealStatusDTO
+	if *sealToken == "" {
+		return status, errors.New("-seal-token or SEAL_TOKEN must be defined")
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return status, fmt.Errorf("failed to encode request: %w", err)
+	}
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return status, fmt.Errorf("failed to create request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(appendpoints.SealTokenHeader, *sealToken)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return status, fmt.Errorf("failed to read certificate authority: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return status, fmt.Errorf("no certificates in %s", caFile)
+		}
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return status, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer response.Body.Close()
+	data, err = io.ReadAll(response.Body)
+	if err != nil {
+		return status, fmt.Errorf("failed to read response: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return status, fmt.Errorf("server responded %d: %s", response.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return status, fmt.Errorf("invalid response: %w", err)
+	}
+	return status, nil
+}
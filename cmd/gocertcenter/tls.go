@@ -0,0 +1,124 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// newTlsConfig returns the TLS configuration of the server with the defaults
+// shared by both TLS modes. Client certificates issued by the organizations
+// are verified when given, so that EST re-enrollment can use them.
+func newTlsConfig(clientAuthorities *appcontrollers.TlsClientAuthorities) *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	clientAuthorities.Configure(config)
+	return config
+}
+
+// openTlsFiles loads the server certificate and private key provided by the
+// operator
+func openTlsFiles(clientAuthorities *appcontrollers.TlsClientAuthorities, certFile, keyFile string) (*tls.Config, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("both -tls-cert-file and -tls-key-file must be defined")
+	}
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the TLS certificate: %w", err)
+	}
+	config := newTlsConfig(clientAuthorities)
+	config.Certificates = []tls.Certificate{certificate}
+	log.Printf("[main]: Serving TLS with the certificate of %s", certFile)
+	return config, nil
+}
+
+// openTlsBootstrap issues the server certificate from the system
+// organization, creating the organization and its root certificate on the
+// first start. A still valid server certificate of an earlier start is
+// reused. The root certificate is written to rootFile for clients.
+func openTlsBootstrap(
+	appController appmodels.ApplicationController,
+	clientAuthorities *appcontrollers.TlsClientAuthorities,
+	revocationRepository appmodels.CertificateRevocationRepository,
+	certManager managers.CertificateManager,
+	organization string,
+	hostnames string,
+	expiration string,
+	rootFile string,
+) (*tls.Config, *appcontrollers.CertTlsController, error) {
+	var names []string
+	for _, name := range strings.Split(hostnames, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil, errors.New("-tls-hostnames must be defined")
+	}
+	organizationID, err := apputils.ParseSerialNumber(organization)
+	if err != nil || organizationID.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("invalid -tls-organization: %s", organization)
+	}
+	duration, err := time.ParseDuration(expiration)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid TLS expiration: %w", err)
+	}
+	if duration <= 0 || duration >= appcontrollers.DefaultTlsRootExpiration {
+		return nil, nil, fmt.Errorf("TLS expiration must be positive and shorter than %s: %s", appcontrollers.DefaultTlsRootExpiration, duration)
+	}
+
+	controller := appcontrollers.NewTlsController(
+		appController,
+		organizationID,
+		names,
+		appcontrollers.DefaultTlsRootExpiration,
+		duration,
+	)
+	controller.SetRevocationRepository(revocationRepository)
+	if err := controller.Bootstrap(); err != nil {
+		if errors.Is(err, appmodels.ErrSealed) {
+			return nil, nil, fmt.Errorf("%w: unseal at startup with UNSEAL_PASSPHRASE or -unseal-passphrase-file", err)
+		}
+		return nil, nil, err
+	}
+
+	root := controller.RootCertificate()
+	log.Printf("[main]: Serving TLS with a certificate issued by %s of the system organization %s", root.SerialNumber(), controller.Organization().ID())
+	if rootFile != "" {
+		if err := writeCertificateFile(certManager, rootFile, root.Certificate()); err != nil {
+			return nil, nil, err
+		}
+		log.Printf("[main]: System root certificate saved to %s", rootFile)
+	}
+
+	config := newTlsConfig(clientAuthorities)
+	config.GetCertificate = controller.GetCertificate
+	return config, controller, nil
+}
+
+// writeCertificateFile writes a certificate as PEM
+func writeCertificateFile(certManager managers.CertificateManager, file string, certificate *x509.Certificate) error {
+	data := certManager.EncodePEMToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})
+	if data == nil {
+		return errors.New("failed to encode the root certificate")
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write the root certificate: %w", err)
+	}
+	return nil
+}
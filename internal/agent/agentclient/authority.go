@@ -0,0 +1,145 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentclient
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// HttpAuthorityClient implements agentmodels.AuthorityClient using the REST
+// API of gocertcenter
+type HttpAuthorityClient struct {
+
+	// url is the base URL of the gocertcenter server
+	url string
+
+	// organization is the ID of the organization which owns the trust domain
+	organization *big.Int
+
+	// rootSerialNumber is the serial number of the certificate which signs
+	// the SVIDs
+	rootSerialNumber *big.Int
+
+	networkManager managers.NetworkManager
+	certManager    managers.CertificateManager
+}
+
+func (c *HttpAuthorityClient) NewSvid(spiffeID string) ([]*x509.Certificate, any, error) {
+
+	body, err := json.Marshal(appdtos.NewCertificateRequestDTO(appdtos.SpiffeCertificate, "", 0, nil, "", "", spiffeID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("[NewSvid:%s]: %w", spiffeID, err)
+	}
+
+	data, err := c.networkManager.HttpPost(
+		fmt.Sprintf("%s/organizations/%s/certificates/%s/certificates", c.url, c.organization, c.rootSerialNumber),
+		"application/json",
+		body,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[NewSvid:%s]: %w", spiffeID, err)
+	}
+
+	var dto appdtos.CertificateCreatedDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, nil, fmt.Errorf("[NewSvid:%s]: failed to parse response: %w", spiffeID, err)
+	}
+
+	block, _ := c.certManager.DecodePEM([]byte(dto.Certificate.Certificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, nil, fmt.Errorf("[NewSvid:%s]: failed to decode certificate", spiffeID)
+	}
+	certificate, err := c.certManager.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[NewSvid:%s]: %w", spiffeID, err)
+	}
+	if len(certificate.URIs) != 1 || certificate.URIs[0].String() != spiffeID {
+		return nil, nil, fmt.Errorf("[NewSvid:%s]: certificate has a different SPIFFE ID", spiffeID)
+	}
+
+	privateKey, _, err := apputils.ParsePrivateKeyFromPEMBytes(c.certManager, []byte(dto.PrivateKey.PrivateKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("[NewSvid:%s]: %w", spiffeID, err)
+	}
+
+	return []*x509.Certificate{certificate}, privateKey, nil
+}
+
+func (c *HttpAuthorityClient) Bundle() (agentmodels.Bundle, error) {
+
+	data, err := c.networkManager.HttpGet(fmt.Sprintf("%s/organizations/%s", c.url, c.organization))
+	if err != nil {
+		return nil, fmt.Errorf("[Bundle]: failed to fetch organization: %w", err)
+	}
+	var organization appdtos.OrganizationDTO
+	if err := json.Unmarshal(data, &organization); err != nil {
+		return nil, fmt.Errorf("[Bundle]: failed to parse organization: %w", err)
+	}
+	if organization.SpiffeTrustDomain == "" {
+		return nil, fmt.Errorf("[Bundle]: organization %s has no SPIFFE trust domain", c.organization)
+	}
+
+	data, err = c.networkManager.HttpGet(fmt.Sprintf("%s/organizations/%s/spiffe/bundle", c.url, c.organization))
+	if err != nil {
+		return nil, fmt.Errorf("[Bundle]: %w", err)
+	}
+	var dto appdtos.SpiffeBundleDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[Bundle]: failed to parse bundle: %w", err)
+	}
+
+	var certificates []*x509.Certificate
+	for _, key := range dto.Keys {
+		if key.Use != apputils.SpiffeX509SvidUse || len(key.X5c) == 0 {
+			continue
+		}
+		der, err := base64.StdEncoding.DecodeString(key.X5c[0])
+		if err != nil {
+			return nil, fmt.Errorf("[Bundle]: x5c: %w", err)
+		}
+		certificate, err := c.certManager.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("[Bundle]: x5c: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("[Bundle]: no X.509 authorities")
+	}
+
+	return agentmodels.NewBundle(organization.SpiffeTrustDomain, certificates), nil
+}
+
+// NewHttpAuthorityClient creates a client for the REST API of gocertcenter
+//   - url: The base URL of the server, e.g. "http://localhost:8080"
+//   - organization: The ID of the organization which owns the trust domain
+//   - rootSerialNumber: The serial number of the certificate which signs SVIDs
+//   - networkManager: The network manager
+//   - certManager: The certificate manager
+func NewHttpAuthorityClient(
+	url string,
+	organization *big.Int,
+	rootSerialNumber *big.Int,
+	networkManager managers.NetworkManager,
+	certManager managers.CertificateManager,
+) *HttpAuthorityClient {
+	return &HttpAuthorityClient{
+		url:              strings.TrimSuffix(url, "/"),
+		organization:     organization,
+		rootSerialNumber: rootSerialNumber,
+		networkManager:   networkManager,
+		certManager:      certManager,
+	}
+}
+
+var _ agentmodels.AuthorityClient = (*HttpAuthorityClient)(nil)
@@ -0,0 +1,140 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentclient_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentclient"
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/commonmocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+type testAuthority struct {
+	certManager  managers.CertificateManager
+	organization appmodels.Organization
+	root         appmodels.Certificate
+	rootKey      appmodels.PrivateKey
+}
+
+func newTestAuthority(t *testing.T) *testAuthority {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	organization := appmodels.NewOrganization(big.NewInt(10), "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "example.org", appmodels.NIL_KEY_RETENTION_POLICY)
+	rootKey, err := apputils.GeneratePrivateKey(organization.ID(), big.NewInt(20), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	root, err := apputils.NewRootCertificate(certManager, big.NewInt(20), organization, time.Hour, appmodels.NIL_SIGNATURE_ALGORITHM, rootKey, "Test Root")
+	require.NoError(t, err)
+	return &testAuthority{certManager, organization, root, rootKey}
+}
+
+func (a *testAuthority) certificateCreatedJSON(t *testing.T, spiffeID string) []byte {
+	privateKey, err := apputils.GeneratePrivateKey(a.organization.ID(), big.NewInt(30), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	certificate, err := apputils.NewSpiffeCertificate(a.certManager, big.NewInt(30), a.organization, time.Hour, appmodels.NIL_SIGNATURE_ALGORITHM, appmodels.NewPublicKey(privateKey.PublicKey()), a.root, a.rootKey, spiffeID)
+	require.NoError(t, err)
+	dto, err := apputils.ToCertificateCreatedDTO(a.certManager, certificate, privateKey)
+	require.NoError(t, err)
+	data, err := json.Marshal(dto)
+	require.NoError(t, err)
+	return data
+}
+
+func (a *testAuthority) organizationJSON(t *testing.T) []byte {
+	data, err := json.Marshal(apputils.ToOrganizationDTO(a.organization))
+	require.NoError(t, err)
+	return data
+}
+
+func (a *testAuthority) bundleJSON(t *testing.T) []byte {
+	dto, err := apputils.ToSpiffeBundleDTO([]appmodels.Certificate{a.root}, 0, apputils.DefaultSpiffeBundleRefreshHint)
+	require.NoError(t, err)
+	data, err := json.Marshal(dto)
+	require.NoError(t, err)
+	return data
+}
+
+func TestHttpAuthorityClient_NewSvid(t *testing.T) {
+	authority := newTestAuthority(t)
+	networkManager := commonmocks.NewMockNetworkManager()
+	client := agentclient.NewHttpAuthorityClient("http://localhost:8080/", big.NewInt(10), big.NewInt(20), networkManager, authority.certManager)
+
+	networkManager.On("HttpPost", "http://localhost:8080/organizations/10/certificates/20/certificates", "application/json", mock.MatchedBy(func(body []byte) bool {
+		var dto appdtos.CertificateRequestDTO
+		return json.Unmarshal(body, &dto) == nil && dto.CertificateType == appdtos.SpiffeCertificate && dto.SpiffeID == "spiffe://example.org/web"
+	})).Return(authority.certificateCreatedJSON(t, "spiffe://example.org/web"), nil)
+
+	certificates, privateKey, err := client.NewSvid("spiffe://example.org/web")
+	require.NoError(t, err)
+	require.Len(t, certificates, 1)
+	assert.Equal(t, "spiffe://example.org/web", certificates[0].URIs[0].String())
+	assert.NoError(t, certificates[0].CheckSignatureFrom(authority.root.Certificate()))
+	assert.NotNil(t, privateKey)
+	networkManager.AssertExpectations(t)
+}
+
+func TestHttpAuthorityClient_NewSvid_Errors(t *testing.T) {
+	authority := newTestAuthority(t)
+	networkManager := commonmocks.NewMockNetworkManager()
+	client := agentclient.NewHttpAuthorityClient("http://localhost:8080", big.NewInt(10), big.NewInt(20), networkManager, authority.certManager)
+
+	networkManager.On("HttpPost", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("unexpected status: 400 Bad Request")).Once()
+	_, _, err := client.NewSvid("spiffe://example.org/web")
+	assert.ErrorContains(t, err, "400")
+
+	networkManager.On("HttpPost", mock.Anything, mock.Anything, mock.Anything).Return(authority.certificateCreatedJSON(t, "spiffe://example.org/other"), nil).Once()
+	_, _, err = client.NewSvid("spiffe://example.org/web")
+	assert.ErrorContains(t, err, "different SPIFFE ID")
+
+	networkManager.On("HttpPost", mock.Anything, mock.Anything, mock.Anything).Return([]byte(`{}`), nil).Once()
+	_, _, err = client.NewSvid("spiffe://example.org/web")
+	assert.Error(t, err)
+}
+
+func TestHttpAuthorityClient_Bundle(t *testing.T) {
+	authority := newTestAuthority(t)
+	networkManager := commonmocks.NewMockNetworkManager()
+	client := agentclient.NewHttpAuthorityClient("http://localhost:8080", big.NewInt(10), big.NewInt(20), networkManager, authority.certManager)
+
+	networkManager.On("HttpGet", "http://localhost:8080/organizations/10").Return(authority.organizationJSON(t), nil)
+	networkManager.On("HttpGet", "http://localhost:8080/organizations/10/spiffe/bundle").Return(authority.bundleJSON(t), nil)
+
+	bundle, err := client.Bundle()
+	require.NoError(t, err)
+	assert.Equal(t, "example.org", bundle.TrustDomain())
+	require.Len(t, bundle.Certificates(), 1)
+	assert.Equal(t, authority.root.Certificate().Raw, bundle.Certificates()[0].Raw)
+}
+
+func TestHttpAuthorityClient_Bundle_NoTrustDomain(t *testing.T) {
+	authority := newTestAuthority(t)
+	networkManager := commonmocks.NewMockNetworkManager()
+	client := agentclient.NewHttpAuthorityClient("http://localhost:8080", big.NewInt(10), big.NewInt(20), networkManager, authority.certManager)
+
+	networkManager.On("HttpGet", "http://localhost:8080/organizations/10").Return([]byte(`{"id":"10","slug":"test"}`), nil)
+
+	_, err := client.Bundle()
+	assert.ErrorContains(t, err, "no SPIFFE trust domain")
+}
+
+func TestHttpAuthorityClient_Bundle_Empty(t *testing.T) {
+	authority := newTestAuthority(t)
+	networkManager := commonmocks.NewMockNetworkManager()
+	client := agentclient.NewHttpAuthorityClient("http://localhost:8080", big.NewInt(10), big.NewInt(20), networkManager, authority.certManager)
+
+	networkManager.On("HttpGet", "http://localhost:8080/organizations/10").Return(authority.organizationJSON(t), nil)
+	networkManager.On("HttpGet", "http://localhost:8080/organizations/10/spiffe/bundle").Return([]byte(`{"keys":[]}`), nil)
+
+	_, err := client.Bundle()
+	assert.ErrorContains(t, err, "no X.509 authorities")
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentcontrollers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmodels"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentutils"
+)
+
+// DefaultRotationInterval is how often SVIDs are checked for rotation
+const DefaultRotationInterval = 30 * time.Second
+
+// CertSvidController implements agentmodels.SvidController
+type CertSvidController struct {
+	entries []agentmodels.RegistrationEntry
+	client  agentmodels.AuthorityClient
+
+	// mu protects svids, bundle and subscribers
+	mu sync.RWMutex
+
+	// svids are the current SVIDs by SPIFFE ID
+	svids map[string]agentmodels.Svid
+
+	bundle agentmodels.Bundle
+
+	subscribers map[chan struct{}]struct{}
+}
+
+func (r *CertSvidController) Entries(selectors []string) []agentmodels.RegistrationEntry {
+	var result []agentmodels.RegistrationEntry
+	for _, entry := range r.entries {
+		if agentutils.MatchSelectors(entry.Selectors(), selectors) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+func (r *CertSvidController) HasPathSelectors() bool {
+	for _, entry := range r.entries {
+		if agentutils.HasPathSelector(entry.Selectors()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *CertSvidController) Svids(selectors []string) []agentmodels.Svid {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	var result []agentmodels.Svid
+	found := make(map[string]bool)
+	for _, entry := range r.entries {
+		if found[entry.SpiffeID()] || !agentutils.MatchSelectors(entry.Selectors(), selectors) {
+			continue
+		}
+		svid, ok := r.svids[entry.SpiffeID()]
+		if !ok || now.After(svid.NotAfter()) {
+			continue
+		}
+		found[entry.SpiffeID()] = true
+		result = append(result, agentmodels.NewSvid(entry.SpiffeID(), entry.Hint(), svid.Certificates(), svid.PrivateKey()))
+	}
+	return result
+}
+
+func (r *CertSvidController) Bundle() agentmodels.Bundle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.bundle
+}
+
+func (r *CertSvidController) Rotate() error {
+
+	now := time.Now()
+	changed := false
+	var errs []error
+
+	bundle, err := r.client.Bundle()
+	if err != nil {
+		errs = append(errs, err)
+	} else if !sameBundle(r.Bundle(), bundle) {
+		r.mu.Lock()
+		r.bundle = bundle
+		r.mu.Unlock()
+		changed = true
+		log.Printf("[Rotate]: Updated bundle of %s", bundle.TrustDomain())
+	}
+
+	for _, spiffeID := range r.spiffeIDs() {
+
+		r.mu.RLock()
+		svid, ok := r.svids[spiffeID]
+		r.mu.RUnlock()
+		if ok && !agentutils.ShouldRotate(svid, now) {
+			continue
+		}
+
+		certificates, privateKey, err := r.client.NewSvid(spiffeID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		svid = agentmodels.NewSvid(spiffeID, "", certificates, privateKey)
+
+		r.mu.Lock()
+		r.svids[spiffeID] = svid
+		r.mu.Unlock()
+		changed = true
+		log.Printf("[Rotate:%s]: Issued SVID: %s, expires %s", spiffeID, svid.Certificates()[0].SerialNumber, svid.NotAfter().Format(time.RFC3339))
+	}
+
+	if changed {
+		r.notify()
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("[Rotate]: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (r *CertSvidController) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	r.mu.Lock()
+	r.subscribers[ch] = struct{}{}
+	r.mu.Unlock()
+	return ch, func() {
+		r.mu.Lock()
+		delete(r.subscribers, ch)
+		r.mu.Unlock()
+	}
+}
+
+// Run rotates SVIDs until the context is cancelled
+//   - ctx: The context
+//   - interval: How often SVIDs are checked
+func (r *CertSvidController) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Rotate(); err != nil {
+			log.Printf("[Run]: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// spiffeIDs returns the unique SPIFFE IDs of the registration entries
+func (r *CertSvidController) spiffeIDs() []string {
+	var result []string
+	found := make(map[string]bool)
+	for _, entry := range r.entries {
+		if !found[entry.SpiffeID()] {
+			found[entry.SpiffeID()] = true
+			result = append(result, entry.SpiffeID())
+		}
+	}
+	return result
+}
+
+// notify wakes up subscribers without blocking. A subscriber which has not
+// yet handled the previous change gets only one notification.
+func (r *CertSvidController) notify() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for ch := range r.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// sameBundle checks if two bundles have the same trust domain and authorities
+func sameBundle(a, b agentmodels.Bundle) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.TrustDomain() != b.TrustDomain() || len(a.Certificates()) != len(b.Certificates()) {
+		return false
+	}
+	for i, certificate := range a.Certificates() {
+		if !bytes.Equal(certificate.Raw, b.Certificates()[i].Raw) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewSvidController creates a controller which keeps the SVIDs of the
+// registration entries up to date
+//   - entries: The registration entries
+//   - client: The client for the gocertcenter server
+func NewSvidController(
+	entries []agentmodels.RegistrationEntry,
+	client agentmodels.AuthorityClient,
+) *CertSvidController {
+	return &CertSvidController{
+		entries:     entries,
+		client:      client,
+		svids:       make(map[string]agentmodels.Svid),
+		subscribers: make(map[chan struct{}]struct{}),
+	}
+}
+
+var _ agentmodels.SvidController = (*CertSvidController)(nil)
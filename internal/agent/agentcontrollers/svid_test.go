@@ -0,0 +1,173 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentcontrollers_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmocks"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmodels"
+)
+
+// newTestSvid creates a self-signed certificate with a SPIFFE ID
+func newTestSvid(t *testing.T, spiffeID string, notBefore, notAfter time.Time) ([]*x509.Certificate, any) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	uri, err := url.Parse(spiffeID)
+	require.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: notBefore, NotAfter: notAfter, URIs: []*url.URL{uri}}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return []*x509.Certificate{certificate}, key
+}
+
+func newTestBundle(t *testing.T) agentmodels.Bundle {
+	certificates, _ := newTestSvid(t, "spiffe://example.org/root", time.Now(), time.Now().Add(time.Hour))
+	return agentmodels.NewBundle("example.org", certificates)
+}
+
+func TestSvidController_Rotate(t *testing.T) {
+	entries := []agentmodels.RegistrationEntry{
+		agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{"unix:uid:1000"}, "web"),
+		agentmodels.NewRegistrationEntry("spiffe://example.org/db", []string{"unix:uid:1001"}, ""),
+		agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{"unix:uid:1002"}, ""),
+	}
+	client := new(agentmocks.MockAuthorityClient)
+	bundle := newTestBundle(t)
+	web, webKey := newTestSvid(t, "spiffe://example.org/web", time.Now().Add(-time.Minute), time.Now().Add(time.Hour))
+	db, dbKey := newTestSvid(t, "spiffe://example.org/db", time.Now().Add(-time.Minute), time.Now().Add(time.Hour))
+	client.On("Bundle").Return(bundle, nil)
+	client.On("NewSvid", "spiffe://example.org/web").Return(web, webKey, nil).Once()
+	client.On("NewSvid", "spiffe://example.org/db").Return(db, dbKey, nil).Once()
+
+	controller := agentcontrollers.NewSvidController(entries, client)
+	assert.Nil(t, controller.Bundle())
+	assert.Empty(t, controller.Svids([]string{"unix:uid:1000"}))
+
+	updates, unsubscribe := controller.Subscribe()
+	defer unsubscribe()
+
+	require.NoError(t, controller.Rotate())
+	assert.Len(t, updates, 1)
+	<-updates
+	assert.Equal(t, bundle, controller.Bundle())
+
+	svids := controller.Svids([]string{"unix:uid:1000", "unix:gid:100"})
+	require.Len(t, svids, 1)
+	assert.Equal(t, "spiffe://example.org/web", svids[0].SpiffeID())
+	assert.Equal(t, "web", svids[0].Hint())
+	assert.Equal(t, web, svids[0].Certificates())
+
+	svids = controller.Svids([]string{"unix:uid:1002"})
+	require.Len(t, svids, 1)
+	assert.Equal(t, "", svids[0].Hint())
+
+	assert.Empty(t, controller.Svids([]string{"unix:uid:0"}))
+	assert.Len(t, controller.Entries([]string{"unix:uid:1001"}), 1)
+
+	// Nothing is renewed or changed before the half-life
+	require.NoError(t, controller.Rotate())
+	assert.Len(t, updates, 0)
+	client.AssertExpectations(t)
+}
+
+func TestSvidController_Rotate_HalfLife(t *testing.T) {
+	entries := []agentmodels.RegistrationEntry{
+		agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{"unix:uid:1000"}, ""),
+	}
+	client := new(agentmocks.MockAuthorityClient)
+	old, oldKey := newTestSvid(t, "spiffe://example.org/web", time.Now().Add(-time.Hour), time.Now().Add(time.Minute))
+	renewed, renewedKey := newTestSvid(t, "spiffe://example.org/web", time.Now(), time.Now().Add(time.Hour))
+	client.On("Bundle").Return(newTestBundle(t), nil).Once()
+	client.On("NewSvid", "spiffe://example.org/web").Return(old, oldKey, nil).Once()
+	client.On("NewSvid", "spiffe://example.org/web").Return(renewed, renewedKey, nil).Once()
+
+	controller := agentcontrollers.NewSvidController(entries, client)
+	require.NoError(t, controller.Rotate())
+
+	bundle := controller.Bundle()
+	client.On("Bundle").Return(bundle, nil)
+	updates, unsubscribe := controller.Subscribe()
+	defer unsubscribe()
+
+	require.NoError(t, controller.Rotate())
+	assert.Len(t, updates, 1)
+	svids := controller.Svids([]string{"unix:uid:1000"})
+	require.Len(t, svids, 1)
+	assert.Equal(t, renewed, svids[0].Certificates())
+	client.AssertExpectations(t)
+}
+
+func TestSvidController_Rotate_Errors(t *testing.T) {
+	entries := []agentmodels.RegistrationEntry{
+		agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{"unix:uid:1000"}, ""),
+		agentmodels.NewRegistrationEntry("spiffe://example.org/db", []string{"unix:uid:1001"}, ""),
+	}
+	client := new(agentmocks.MockAuthorityClient)
+	db, dbKey := newTestSvid(t, "spiffe://example.org/db", time.Now(), time.Now().Add(time.Hour))
+	client.On("Bundle").Return(nil, errors.New("bundle failed"))
+	client.On("NewSvid", "spiffe://example.org/web").Return(nil, nil, errors.New("web failed"))
+	client.On("NewSvid", "spiffe://example.org/db").Return(db, dbKey, nil)
+
+	controller := agentcontrollers.NewSvidController(entries, client)
+	err := controller.Rotate()
+	assert.ErrorContains(t, err, "bundle failed")
+	assert.ErrorContains(t, err, "web failed")
+
+	assert.Nil(t, controller.Bundle())
+	assert.Empty(t, controller.Svids([]string{"unix:uid:1000"}))
+	assert.Len(t, controller.Svids([]string{"unix:uid:1001"}), 1)
+}
+
+func TestSvidController_Svids_Expired(t *testing.T) {
+	entries := []agentmodels.RegistrationEntry{
+		agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{"unix:uid:1000"}, ""),
+	}
+	client := new(agentmocks.MockAuthorityClient)
+	expired, expiredKey := newTestSvid(t, "spiffe://example.org/web", time.Now().Add(-time.Hour), time.Now().Add(-time.Minute))
+	client.On("Bundle").Return(newTestBundle(t), nil)
+	client.On("NewSvid", "spiffe://example.org/web").Return(expired, expiredKey, nil)
+
+	controller := agentcontrollers.NewSvidController(entries, client)
+	require.NoError(t, controller.Rotate())
+	assert.Empty(t, controller.Svids([]string{"unix:uid:1000"}))
+}
+
+func TestSvidController_Unsubscribe(t *testing.T) {
+	client := new(agentmocks.MockAuthorityClient)
+	client.On("Bundle").Return(newTestBundle(t), nil)
+
+	controller := agentcontrollers.NewSvidController(nil, client)
+	updates, unsubscribe := controller.Subscribe()
+	unsubscribe()
+	require.NoError(t, controller.Rotate())
+	assert.Len(t, updates, 0)
+}
+
+func TestSvidController_HasPathSelectors(t *testing.T) {
+	client := new(agentmocks.MockAuthorityClient)
+	controller := agentcontrollers.NewSvidController([]agentmodels.RegistrationEntry{
+		agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{"unix:uid:1000"}, ""),
+	}, client)
+	assert.False(t, controller.HasPathSelectors())
+
+	controller = agentcontrollers.NewSvidController([]agentmodels.RegistrationEntry{
+		agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{"unix:uid:1000"}, ""),
+		agentmodels.NewRegistrationEntry("spiffe://example.org/db", []string{"unix:uid:1000", "unix:path:/usr/bin/db"}, ""),
+	}, client)
+	assert.True(t, controller.HasPathSelectors())
+}
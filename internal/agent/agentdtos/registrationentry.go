@@ -0,0 +1,43 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentdtos
+
+// RegistrationEntryDTO describes a registration entry in the configuration
+// file of the agent
+type RegistrationEntryDTO struct {
+
+	// SpiffeID is the SPIFFE ID issued to matching workloads
+	SpiffeID string `json:"spiffeId"`
+
+	// Selectors must all match the workload, e.g. "unix:uid:1000"
+	Selectors []string `json:"selectors"`
+
+	// Hint is an optional string which tells the workload how the SVID should
+	// be used when it has more than one
+	Hint string `json:"hint,omitempty"`
+}
+
+func NewRegistrationEntryDTO(
+	spiffeID string,
+	selectors []string,
+	hint string,
+) RegistrationEntryDTO {
+	return RegistrationEntryDTO{
+		SpiffeID:  spiffeID,
+		Selectors: selectors,
+		Hint:      hint,
+	}
+}
+
+// RegistrationEntryListDTO is the configuration file of the agent
+type RegistrationEntryListDTO struct {
+	Entries []RegistrationEntryDTO `json:"entries"`
+}
+
+func NewRegistrationEntryListDTO(
+	entries []RegistrationEntryDTO,
+) RegistrationEntryListDTO {
+	return RegistrationEntryListDTO{
+		Entries: entries,
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentdtos_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentdtos"
+)
+
+func TestNewRegistrationEntryDTO(t *testing.T) {
+	dto := agentdtos.NewRegistrationEntryDTO("spiffe://example.org/web", []string{"unix:uid:1000"}, "internal")
+	assert.Equal(t, "spiffe://example.org/web", dto.SpiffeID)
+	assert.Equal(t, []string{"unix:uid:1000"}, dto.Selectors)
+	assert.Equal(t, "internal", dto.Hint)
+}
+
+func TestRegistrationEntryListDTO_JSON(t *testing.T) {
+	var dto agentdtos.RegistrationEntryListDTO
+	err := json.Unmarshal([]byte(`{"entries":[{"spiffeId":"spiffe://example.org/web","selectors":["unix:uid:1000","unix:path:/usr/bin/web"]}]}`), &dto)
+	require.NoError(t, err)
+	assert.Equal(t, agentdtos.NewRegistrationEntryListDTO([]agentdtos.RegistrationEntryDTO{
+		agentdtos.NewRegistrationEntryDTO("spiffe://example.org/web", []string{"unix:uid:1000", "unix:path:/usr/bin/web"}, ""),
+	}), dto)
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentmocks
+
+import (
+	"crypto/x509"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmodels"
+)
+
+// MockAuthorityClient is a mock implementation of agentmodels.AuthorityClient for testing purposes.
+type MockAuthorityClient struct {
+	mock.Mock
+}
+
+func (m *MockAuthorityClient) NewSvid(spiffeID string) ([]*x509.Certificate, any, error) {
+	args := m.Called(spiffeID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*x509.Certificate), args.Get(1), args.Error(2)
+}
+
+func (m *MockAuthorityClient) Bundle() (agentmodels.Bundle, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(agentmodels.Bundle), args.Error(1)
+}
+
+var _ agentmodels.AuthorityClient = (*MockAuthorityClient)(nil)
@@ -0,0 +1,41 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentmodels
+
+import (
+	"crypto/x509"
+)
+
+// BundleModel implements Bundle
+type BundleModel struct {
+
+	// trustDomain is the name of the trust domain, e.g. "example.org"
+	trustDomain string
+
+	// certificates are the X.509 authorities of the trust domain
+	certificates []*x509.Certificate
+}
+
+func (b *BundleModel) TrustDomain() string {
+	return b.trustDomain
+}
+
+func (b *BundleModel) Certificates() []*x509.Certificate {
+	return b.certificates
+}
+
+// NewBundle creates a trust bundle model
+//   - trustDomain: The name of the trust domain
+//   - certificates: The X.509 authorities of the trust domain
+func NewBundle(
+	trustDomain string,
+	certificates []*x509.Certificate,
+) *BundleModel {
+	return &BundleModel{
+		trustDomain:  trustDomain,
+		certificates: certificates,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ Bundle = (*BundleModel)(nil)
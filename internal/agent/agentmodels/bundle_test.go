@@ -0,0 +1,19 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentmodels_test
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmodels"
+)
+
+func TestNewBundle(t *testing.T) {
+	certificates := []*x509.Certificate{{Raw: []byte("root")}}
+	bundle := agentmodels.NewBundle("example.org", certificates)
+	assert.Equal(t, "example.org", bundle.TrustDomain())
+	assert.Equal(t, certificates, bundle.Certificates())
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentmodels
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+// RegistrationEntry maps local workloads to a SPIFFE ID. A workload is
+// entitled to the SPIFFE ID when it matches all selectors of the entry.
+type RegistrationEntry interface {
+	SpiffeID() string
+	Selectors() []string
+	Hint() string
+}
+
+// Svid is an X.509-SVID with its private key
+type Svid interface {
+	SpiffeID() string
+	Hint() string
+
+	// Certificates returns the certificate chain, the SVID first
+	Certificates() []*x509.Certificate
+
+	PrivateKey() any
+	NotBefore() time.Time
+	NotAfter() time.Time
+}
+
+// Bundle is the set of X.509 authorities of a trust domain
+type Bundle interface {
+	TrustDomain() string
+	Certificates() []*x509.Certificate
+}
+
+// AuthorityClient requests SVIDs and bundles from the central gocertcenter
+// server
+type AuthorityClient interface {
+
+	// NewSvid issues a new SVID with a new private key
+	NewSvid(spiffeID string) ([]*x509.Certificate, any, error)
+
+	// Bundle fetches the trust bundle of the organization
+	Bundle() (Bundle, error)
+}
+
+// SvidController keeps the SVIDs of registration entries and the trust
+// bundle up to date
+type SvidController interface {
+
+	// Entries returns the registration entries which match a workload with
+	// the selectors
+	Entries(selectors []string) []RegistrationEntry
+
+	// HasPathSelectors returns true if a registration entry has a path
+	// selector, so that the executable of every workload must be known
+	HasPathSelectors() bool
+
+	// Svids returns the current SVIDs for a workload with the selectors
+	Svids(selectors []string) []Svid
+
+	// Bundle returns the current trust bundle, or nil if it is not yet known
+	Bundle() Bundle
+
+	// Rotate renews SVIDs which are past their half-life and refreshes the
+	// bundle. Subscribers are notified if anything changed.
+	Rotate() error
+
+	// Subscribe returns a channel which receives a value after changes, and a
+	// function to cancel the subscription
+	Subscribe() (<-chan struct{}, func())
+}
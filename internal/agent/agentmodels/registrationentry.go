@@ -0,0 +1,48 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentmodels
+
+// RegistrationEntryModel implements RegistrationEntry
+type RegistrationEntryModel struct {
+
+	// spiffeID is the SPIFFE ID issued to matching workloads
+	spiffeID string
+
+	// selectors must all match the workload, e.g. "unix:uid:1000"
+	selectors []string
+
+	// hint is an optional string which tells the workload how the SVID should
+	// be used when it has more than one
+	hint string
+}
+
+func (e *RegistrationEntryModel) SpiffeID() string {
+	return e.spiffeID
+}
+
+func (e *RegistrationEntryModel) Selectors() []string {
+	return e.selectors
+}
+
+func (e *RegistrationEntryModel) Hint() string {
+	return e.hint
+}
+
+// NewRegistrationEntry creates a registration entry model
+//   - spiffeID: The SPIFFE ID issued to matching workloads
+//   - selectors: The selectors which must all match the workload
+//   - hint: Optional hint for the workload
+func NewRegistrationEntry(
+	spiffeID string,
+	selectors []string,
+	hint string,
+) *RegistrationEntryModel {
+	return &RegistrationEntryModel{
+		spiffeID:  spiffeID,
+		selectors: selectors,
+		hint:      hint,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ RegistrationEntry = (*RegistrationEntryModel)(nil)
@@ -0,0 +1,18 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentmodels_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmodels"
+)
+
+func TestNewRegistrationEntry(t *testing.T) {
+	entry := agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{"unix:uid:1000"}, "internal")
+	assert.Equal(t, "spiffe://example.org/web", entry.SpiffeID())
+	assert.Equal(t, []string{"unix:uid:1000"}, entry.Selectors())
+	assert.Equal(t, "internal", entry.Hint())
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentmodels
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+// SvidModel implements Svid
+type SvidModel struct {
+	spiffeID string
+	hint     string
+
+	// certificates is the certificate chain, the SVID first
+	certificates []*x509.Certificate
+
+	privateKey any
+}
+
+func (s *SvidModel) SpiffeID() string {
+	return s.spiffeID
+}
+
+func (s *SvidModel) Hint() string {
+	return s.hint
+}
+
+func (s *SvidModel) Certificates() []*x509.Certificate {
+	return s.certificates
+}
+
+func (s *SvidModel) PrivateKey() any {
+	return s.privateKey
+}
+
+func (s *SvidModel) NotBefore() time.Time {
+	return s.certificates[0].NotBefore
+}
+
+func (s *SvidModel) NotAfter() time.Time {
+	return s.certificates[0].NotAfter
+}
+
+// NewSvid creates an SVID model
+//   - spiffeID: The SPIFFE ID of the SVID
+//   - hint: Optional hint for the workload
+//   - certificates: The certificate chain, the SVID first
+//   - privateKey: The private key of the SVID
+func NewSvid(
+	spiffeID string,
+	hint string,
+	certificates []*x509.Certificate,
+	privateKey any,
+) *SvidModel {
+	if len(certificates) == 0 {
+		panic("NewSvid: certificates: must not be empty")
+	}
+	return &SvidModel{
+		spiffeID:     spiffeID,
+		hint:         hint,
+		certificates: certificates,
+		privateKey:   privateKey,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ Svid = (*SvidModel)(nil)
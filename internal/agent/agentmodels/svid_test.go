@@ -0,0 +1,35 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentmodels_test
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmodels"
+)
+
+func TestNewSvid(t *testing.T) {
+	notBefore := time.Unix(1000, 0)
+	notAfter := time.Unix(2000, 0)
+	leaf := &x509.Certificate{NotBefore: notBefore, NotAfter: notAfter}
+	issuer := &x509.Certificate{NotBefore: time.Unix(0, 0), NotAfter: time.Unix(3000, 0)}
+	key := "key"
+
+	svid := agentmodels.NewSvid("spiffe://example.org/web", "internal", []*x509.Certificate{leaf, issuer}, key)
+	assert.Equal(t, "spiffe://example.org/web", svid.SpiffeID())
+	assert.Equal(t, "internal", svid.Hint())
+	assert.Equal(t, []*x509.Certificate{leaf, issuer}, svid.Certificates())
+	assert.Equal(t, key, svid.PrivateKey())
+	assert.Equal(t, notBefore, svid.NotBefore())
+	assert.Equal(t, notAfter, svid.NotAfter())
+}
+
+func TestNewSvid_NoCertificates(t *testing.T) {
+	assert.Panics(t, func() {
+		agentmodels.NewSvid("spiffe://example.org/web", "", nil, nil)
+	})
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// PeerCredentialsAuthType is the authentication type of PeerCredentials
+const PeerCredentialsAuthType = "unix"
+
+// PeerCredentials are the credentials of the process on the other end of a
+// Unix domain socket, read when the connection was accepted
+type PeerCredentials struct {
+	credentials.CommonAuthInfo
+
+	Uid uint32
+	Gid uint32
+	Pid int32
+
+	// Path is the path of the executable of the process, or empty if it could
+	// not be read
+	Path string
+
+	// PathError is the error reading Path, or nil
+	PathError error
+}
+
+func (c PeerCredentials) AuthType() string {
+	return PeerCredentialsAuthType
+}
+
+// UnixCredentials implements credentials.TransportCredentials for servers
+// listening on a Unix domain socket. It does not encrypt connections, but
+// attaches PeerCredentials to each of them.
+type UnixCredentials struct{}
+
+func (c *UnixCredentials) ClientHandshake(_ context.Context, _ string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("[UnixCredentials:ClientHandshake]: not supported")
+}
+
+func (c *UnixCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, nil, fmt.Errorf("[UnixCredentials:ServerHandshake]: not a Unix domain socket: %s", conn.RemoteAddr().Network())
+	}
+	info, err := readPeerCredentials(unixConn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[UnixCredentials:ServerHandshake]: %w", err)
+	}
+	info.CommonAuthInfo = credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity}
+	return conn, info, nil
+}
+
+func (c *UnixCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: PeerCredentialsAuthType}
+}
+
+func (c *UnixCredentials) Clone() credentials.TransportCredentials {
+	return &UnixCredentials{}
+}
+
+func (c *UnixCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+// NewUnixCredentials creates transport credentials which read the peer
+// credentials of Unix domain socket connections
+func NewUnixCredentials() *UnixCredentials {
+	return &UnixCredentials{}
+}
+
+var _ credentials.TransportCredentials = (*UnixCredentials)(nil)
+var _ credentials.AuthInfo = PeerCredentials{}
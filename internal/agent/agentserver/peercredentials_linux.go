@@ -0,0 +1,51 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+//go:build linux
+
+package agentserver
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// readPeerCredentials reads the credentials of the peer process using
+// SO_PEERCRED, and the path of its executable from /proc
+func readPeerCredentials(conn *net.UnixConn) (PeerCredentials, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return PeerCredentials{}, fmt.Errorf("readPeerCredentials: %w", err)
+	}
+	var ucred *syscall.Ucred
+	var ucredErr error
+	err = raw.Control(func(fd uintptr) {
+		ucred, ucredErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return PeerCredentials{}, fmt.Errorf("readPeerCredentials: %w", err)
+	}
+	if ucredErr != nil {
+		return PeerCredentials{}, fmt.Errorf("readPeerCredentials: SO_PEERCRED: %w", ucredErr)
+	}
+
+	// SO_PEERCRED reports the process which connected, not the one which
+	// holds the socket now. The socket may have been inherited by or passed
+	// to another process, and the process which connected may have exited
+	// and its ID been reused, so the path is only as reliable as the process
+	// ID.
+	path, pathErr := os.Readlink(fmt.Sprintf("/proc/%d/exe", ucred.Pid))
+	if pathErr != nil {
+		path = ""
+		pathErr = fmt.Errorf("readPeerCredentials: executable of %d: %w", ucred.Pid, pathErr)
+	}
+
+	return PeerCredentials{
+		Uid:       ucred.Uid,
+		Gid:       ucred.Gid,
+		Pid:       ucred.Pid,
+		Path:      path,
+		PathError: pathErr,
+	}, nil
+}
@@ -0,0 +1,15 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+//go:build !linux
+
+package agentserver
+
+import (
+	"errors"
+	"net"
+)
+
+// readPeerCredentials is only supported on Linux
+func readPeerCredentials(_ *net.UnixConn) (PeerCredentials, error) {
+	return PeerCredentials{}, errors.New("readPeerCredentials: not supported on this platform")
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmodels"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentutils"
+)
+
+// WorkloadApiSecurityHeader is the metadata key every Workload API request
+// must have with the value "true"
+const WorkloadApiSecurityHeader = "workload.spiffe.io"
+
+// WorkloadApiServer implements the X.509 parts of the SPIFFE Workload API.
+// JWT-SVIDs are not supported.
+type WorkloadApiServer struct {
+	workload.UnimplementedSpiffeWorkloadAPIServer
+
+	svidController agentmodels.SvidController
+}
+
+func (s *WorkloadApiServer) FetchX509SVID(_ *workload.X509SVIDRequest, stream workload.SpiffeWorkloadAPI_FetchX509SVIDServer) error {
+
+	credentials, err := attest(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	// Without the path a workload would not match the entries with a path
+	// selector, but might match less specific ones
+	if credentials.PathError != nil && s.svidController.HasPathSelectors() {
+		log.Printf("[FetchX509SVID:%d]: %v", credentials.Pid, credentials.PathError)
+		return status.Error(codes.PermissionDenied, "failed to read the executable of the workload")
+	}
+	selectors := agentutils.UnixSelectors(credentials.Uid, credentials.Gid, credentials.Path)
+
+	updates, unsubscribe := s.svidController.Subscribe()
+	defer unsubscribe()
+
+	if len(s.svidController.Entries(selectors)) == 0 {
+		log.Printf("[FetchX509SVID:%d]: No identity for %v", credentials.Pid, selectors)
+		return status.Error(codes.PermissionDenied, "no identity issued")
+	}
+
+	for {
+		svids := s.svidController.Svids(selectors)
+		bundle := s.svidController.Bundle()
+		if len(svids) != 0 && bundle != nil {
+			response, err := agentutils.ToX509SvidResponse(svids, bundle)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			if err := stream.Send(response); err != nil {
+				return err
+			}
+			log.Printf("[FetchX509SVID:%d]: Sent %d SVIDs", credentials.Pid, len(svids))
+		}
+		select {
+		case <-updates:
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (s *WorkloadApiServer) FetchX509Bundles(_ *workload.X509BundlesRequest, stream workload.SpiffeWorkloadAPI_FetchX509BundlesServer) error {
+
+	if _, err := attest(stream.Context()); err != nil {
+		return err
+	}
+
+	updates, unsubscribe := s.svidController.Subscribe()
+	defer unsubscribe()
+
+	var sent agentmodels.Bundle
+	for {
+		if bundle := s.svidController.Bundle(); bundle != nil && bundle != sent {
+			response, err := agentutils.ToX509BundlesResponse(bundle)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			if err := stream.Send(response); err != nil {
+				return err
+			}
+			sent = bundle
+		}
+		select {
+		case <-updates:
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// attest checks the security header and returns the credentials of the
+// workload
+func attest(ctx context.Context) (PeerCredentials, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get(WorkloadApiSecurityHeader)) != 1 || md.Get(WorkloadApiSecurityHeader)[0] != "true" {
+		return PeerCredentials{}, status.Error(codes.InvalidArgument, "security header missing from request")
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return PeerCredentials{}, status.Error(codes.Internal, "no peer information")
+	}
+	credentials, ok := p.AuthInfo.(PeerCredentials)
+	if !ok {
+		return PeerCredentials{}, status.Error(codes.Internal, "no peer credentials")
+	}
+	return credentials, nil
+}
+
+// NewWorkloadApiServer creates a Workload API server
+//   - svidController: The controller which keeps SVIDs up to date
+func NewWorkloadApiServer(svidController agentmodels.SvidController) *WorkloadApiServer {
+	return &WorkloadApiServer{
+		svidController: svidController,
+	}
+}
+
+// NewGrpcServer creates a gRPC server for the Workload API which reads the
+// peer credentials of connections
+//   - svidController: The controller which keeps SVIDs up to date
+func NewGrpcServer(svidController agentmodels.SvidController) *grpc.Server {
+	server := grpc.NewServer(grpc.Creds(NewUnixCredentials()))
+	workload.RegisterSpiffeWorkloadAPIServer(server, NewWorkloadApiServer(svidController))
+	return server
+}
+
+// ListenUnix listens on a Unix domain socket which every local user may
+// connect to. A stale socket file from a previous run is removed.
+//   - socketPath: The path of the socket
+func ListenUnix(socketPath string) (net.Listener, error) {
+	if info, err := os.Lstat(socketPath); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("ListenUnix: not a socket: %s", socketPath)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, fmt.Errorf("ListenUnix: %w", err)
+		}
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("ListenUnix: %w", err)
+	}
+	if err := os.Chmod(socketPath, 0777); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("ListenUnix: %w", err)
+	}
+	return listener, nil
+}
+
+var _ workload.SpiffeWorkloadAPIServer = (*WorkloadApiServer)(nil)
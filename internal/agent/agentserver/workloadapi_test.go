@@ -0,0 +1,258 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentserver_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmocks"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmodels"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentserver"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+type testAuthority struct {
+	certManager  managers.CertificateManager
+	organization appmodels.Organization
+	root         appmodels.Certificate
+	rootKey      appmodels.PrivateKey
+	serial       int64
+}
+
+func newTestAuthority(t *testing.T) *testAuthority {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	organization := appmodels.NewOrganization(big.NewInt(10), "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "example.org", appmodels.NIL_KEY_RETENTION_POLICY)
+	rootKey, err := apputils.GeneratePrivateKey(organization.ID(), big.NewInt(1), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	root, err := apputils.NewRootCertificate(certManager, big.NewInt(1), organization, time.Hour, appmodels.NIL_SIGNATURE_ALGORITHM, rootKey, "Test Root")
+	require.NoError(t, err)
+	return &testAuthority{certManager: certManager, organization: organization, root: root, rootKey: rootKey, serial: 1}
+}
+
+func (a *testAuthority) newSvid(t *testing.T, spiffeID string) ([]*x509.Certificate, any) {
+	a.serial++
+	privateKey, err := apputils.GeneratePrivateKey(a.organization.ID(), big.NewInt(a.serial), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	certificate, err := apputils.NewSpiffeCertificate(a.certManager, big.NewInt(a.serial), a.organization, time.Hour, appmodels.NIL_SIGNATURE_ALGORITHM, appmodels.NewPublicKey(privateKey.PublicKey()), a.root, a.rootKey, spiffeID)
+	require.NoError(t, err)
+	return []*x509.Certificate{certificate.Certificate()}, privateKey.PrivateKey()
+}
+
+func (a *testAuthority) bundle() agentmodels.Bundle {
+	return agentmodels.NewBundle("example.org", []*x509.Certificate{a.root.Certificate()})
+}
+
+// startTestAgent starts a Workload API server with one registration entry
+// and returns the address of its socket
+func startTestAgent(t *testing.T, controller agentmodels.SvidController) string {
+	socketPath := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := agentserver.ListenUnix(socketPath)
+	require.NoError(t, err)
+	server := agentserver.NewGrpcServer(controller)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return "unix://" + socketPath
+}
+
+func currentUidSelector() string {
+	return fmt.Sprintf("unix:uid:%d", os.Getuid())
+}
+
+func TestWorkloadApi_FetchX509SVID(t *testing.T) {
+	authority := newTestAuthority(t)
+	svid, svidKey := authority.newSvid(t, "spiffe://example.org/web")
+	client := new(agentmocks.MockAuthorityClient)
+	client.On("Bundle").Return(authority.bundle(), nil)
+	client.On("NewSvid", "spiffe://example.org/web").Return(svid, svidKey, nil)
+
+	controller := agentcontrollers.NewSvidController([]agentmodels.RegistrationEntry{
+		agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{currentUidSelector()}, ""),
+		agentmodels.NewRegistrationEntry("spiffe://example.org/other", []string{currentUidSelector(), "unix:path:/nonexistent"}, ""),
+	}, client)
+	other, otherKey := authority.newSvid(t, "spiffe://example.org/other")
+	client.On("NewSvid", "spiffe://example.org/other").Return(other, otherKey, nil)
+	require.NoError(t, controller.Rotate())
+	addr := startTestAgent(t, controller)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	x509Context, err := workloadapi.FetchX509Context(ctx, workloadapi.WithAddr(addr))
+	require.NoError(t, err)
+	require.Len(t, x509Context.SVIDs, 1)
+	assert.Equal(t, "spiffe://example.org/web", x509Context.SVIDs[0].ID.String())
+
+	// The SVID verifies against the bundle the workload received
+	_, _, err = x509svid.Verify(x509Context.SVIDs[0].Certificates, x509Context.Bundles)
+	assert.NoError(t, err)
+
+	bundles, err := workloadapi.FetchX509Bundles(ctx, workloadapi.WithAddr(addr))
+	require.NoError(t, err)
+	bundle, err := bundles.GetX509BundleForTrustDomain(spiffeid.RequireTrustDomainFromString("example.org"))
+	require.NoError(t, err)
+	assert.Equal(t, []*x509.Certificate{authority.root.Certificate()}, bundle.X509Authorities())
+}
+
+func TestWorkloadApi_FetchX509SVID_NoIdentity(t *testing.T) {
+	client := new(agentmocks.MockAuthorityClient)
+	client.On("Bundle").Return(nil, fmt.Errorf("unavailable"))
+	controller := agentcontrollers.NewSvidController([]agentmodels.RegistrationEntry{
+		agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{fmt.Sprintf("unix:uid:%d", os.Getuid()+1)}, ""),
+	}, client)
+	client.On("NewSvid", mock.Anything).Return(nil, nil, fmt.Errorf("unavailable"))
+	addr := startTestAgent(t, controller)
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := workload.NewSpiffeWorkloadAPIClient(conn).FetchX509SVID(metadata.AppendToOutgoingContext(ctx, agentserver.WorkloadApiSecurityHeader, "true"), &workload.X509SVIDRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+// testX509SvidStream is a FetchX509SVID stream with the given context
+type testX509SvidStream struct {
+	workload.SpiffeWorkloadAPI_FetchX509SVIDServer
+	ctx context.Context
+}
+
+func (s *testX509SvidStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestWorkloadApi_FetchX509SVID_PathError(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(agentserver.WorkloadApiSecurityHeader, "true"))
+	ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: agentserver.PeerCredentials{
+		Uid:       uint32(os.Getuid()),
+		Gid:       uint32(os.Getgid()),
+		PathError: errors.New("no such file or directory"),
+	}})
+	stream := &testX509SvidStream{ctx: ctx}
+
+	// The workload is refused when an entry has a path selector
+	controller := agentcontrollers.NewSvidController([]agentmodels.RegistrationEntry{
+		agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{currentUidSelector()}, ""),
+		agentmodels.NewRegistrationEntry("spiffe://example.org/db", []string{currentUidSelector(), "unix:path:/usr/bin/db"}, ""),
+	}, new(agentmocks.MockAuthorityClient))
+	err := agentserver.NewWorkloadApiServer(controller).FetchX509SVID(&workload.X509SVIDRequest{}, stream)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "executable")
+
+	// Other entries do not need the path
+	controller = agentcontrollers.NewSvidController([]agentmodels.RegistrationEntry{
+		agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{fmt.Sprintf("unix:uid:%d", os.Getuid()+1)}, ""),
+	}, new(agentmocks.MockAuthorityClient))
+	err = agentserver.NewWorkloadApiServer(controller).FetchX509SVID(&workload.X509SVIDRequest{}, stream)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "no identity issued", status.Convert(err).Message())
+}
+
+func TestWorkloadApi_SecurityHeader(t *testing.T) {
+	controller := agentcontrollers.NewSvidController(nil, new(agentmocks.MockAuthorityClient))
+	addr := startTestAgent(t, controller)
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := workload.NewSpiffeWorkloadAPIClient(conn).FetchX509SVID(ctx, &workload.X509SVIDRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestWorkloadApi_Rotation(t *testing.T) {
+	authority := newTestAuthority(t)
+	first, firstKey := authority.newSvid(t, "spiffe://example.org/web")
+	second, secondKey := authority.newSvid(t, "spiffe://example.org/web")
+
+	// The first SVID is already past its half-life, so the next rotation
+	// renews it
+	first[0].NotBefore = time.Now().Add(-2 * time.Hour)
+
+	client := new(agentmocks.MockAuthorityClient)
+	client.On("Bundle").Return(authority.bundle(), nil)
+	client.On("NewSvid", "spiffe://example.org/web").Return(first, firstKey, nil).Once()
+	client.On("NewSvid", "spiffe://example.org/web").Return(second, secondKey, nil).Once()
+
+	controller := agentcontrollers.NewSvidController([]agentmodels.RegistrationEntry{
+		agentmodels.NewRegistrationEntry("spiffe://example.org/web", []string{currentUidSelector()}, ""),
+	}, client)
+	require.NoError(t, controller.Rotate())
+	addr := startTestAgent(t, controller)
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := workload.NewSpiffeWorkloadAPIClient(conn).FetchX509SVID(metadata.AppendToOutgoingContext(ctx, agentserver.WorkloadApiSecurityHeader, "true"), &workload.X509SVIDRequest{})
+	require.NoError(t, err)
+
+	response, err := stream.Recv()
+	require.NoError(t, err)
+	require.Len(t, response.Svids, 1)
+	assert.Equal(t, first[0].Raw, response.Svids[0].X509Svid)
+
+	require.NoError(t, controller.Rotate())
+
+	response, err = stream.Recv()
+	require.NoError(t, err)
+	require.Len(t, response.Svids, 1)
+	assert.Equal(t, second[0].Raw, response.Svids[0].X509Svid)
+	client.AssertExpectations(t)
+}
+
+func TestListenUnix_NotASocket(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(fileName, nil, 0600))
+	_, err := agentserver.ListenUnix(fileName)
+	assert.Error(t, err)
+}
+
+func TestListenUnix_StaleSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := agentserver.ListenUnix(socketPath)
+	require.NoError(t, err)
+	// Leave the socket file behind like a crashed agent would
+	if unixListener, ok := listener.(interface{ SetUnlinkOnClose(bool) }); ok {
+		unixListener.SetUnlinkOnClose(false)
+	}
+	require.NoError(t, listener.Close())
+
+	listener, err = agentserver.ListenUnix(socketPath)
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentutils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentdtos"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// ToRegistrationEntry validates and converts a registration entry DTO
+func ToRegistrationEntry(dto agentdtos.RegistrationEntryDTO) (agentmodels.RegistrationEntry, error) {
+	if _, err := apputils.ParseSpiffeID(dto.SpiffeID); err != nil {
+		return nil, fmt.Errorf("ToRegistrationEntry: spiffeId: %w", err)
+	}
+	if len(dto.Selectors) == 0 {
+		return nil, fmt.Errorf("ToRegistrationEntry: %s: selectors: must not be empty", dto.SpiffeID)
+	}
+	for _, selector := range dto.Selectors {
+		if err := ValidateSelector(selector); err != nil {
+			return nil, fmt.Errorf("ToRegistrationEntry: %s: %w", dto.SpiffeID, err)
+		}
+	}
+	return agentmodels.NewRegistrationEntry(dto.SpiffeID, dto.Selectors, dto.Hint), nil
+}
+
+// ReadRegistrationEntries reads registration entries from a JSON file
+//   - fileManager: The file manager
+//   - fileName: The path of the file
+func ReadRegistrationEntries(fileManager managers.FileManager, fileName string) ([]agentmodels.RegistrationEntry, error) {
+	data, err := fileManager.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("ReadRegistrationEntries: %w", err)
+	}
+	var dto agentdtos.RegistrationEntryListDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("ReadRegistrationEntries: %s: %w", fileName, err)
+	}
+	list := make([]agentmodels.RegistrationEntry, len(dto.Entries))
+	for i, v := range dto.Entries {
+		entry, err := ToRegistrationEntry(v)
+		if err != nil {
+			return nil, fmt.Errorf("ReadRegistrationEntries: %s: %w", fileName, err)
+		}
+		list[i] = entry
+	}
+	return list, nil
+}
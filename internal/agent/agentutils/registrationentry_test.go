@@ -0,0 +1,64 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentutils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentdtos"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestToRegistrationEntry(t *testing.T) {
+	entry, err := agentutils.ToRegistrationEntry(agentdtos.NewRegistrationEntryDTO("spiffe://example.org/web", []string{"unix:uid:1000"}, "internal"))
+	require.NoError(t, err)
+	assert.Equal(t, "spiffe://example.org/web", entry.SpiffeID())
+	assert.Equal(t, []string{"unix:uid:1000"}, entry.Selectors())
+	assert.Equal(t, "internal", entry.Hint())
+
+	_, err = agentutils.ToRegistrationEntry(agentdtos.NewRegistrationEntryDTO("spiffe://example.org", []string{"unix:uid:1000"}, ""))
+	assert.Error(t, err)
+
+	_, err = agentutils.ToRegistrationEntry(agentdtos.NewRegistrationEntryDTO("spiffe://example.org/web", nil, ""))
+	assert.Error(t, err)
+
+	_, err = agentutils.ToRegistrationEntry(agentdtos.NewRegistrationEntryDTO("spiffe://example.org/web", []string{"unix:user:web"}, ""))
+	assert.Error(t, err)
+}
+
+func TestReadRegistrationEntries(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "entries.json")
+	require.NoError(t, os.WriteFile(fileName, []byte(`{"entries":[
+		{"spiffeId":"spiffe://example.org/web","selectors":["unix:uid:1000"]},
+		{"spiffeId":"spiffe://example.org/db","selectors":["unix:uid:1001","unix:path:/usr/bin/db"],"hint":"internal"}
+	]}`), 0600))
+
+	entries, err := agentutils.ReadRegistrationEntries(managers.NewFileManager(), fileName)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "spiffe://example.org/web", entries[0].SpiffeID())
+	assert.Equal(t, []string{"unix:uid:1001", "unix:path:/usr/bin/db"}, entries[1].Selectors())
+	assert.Equal(t, "internal", entries[1].Hint())
+}
+
+func TestReadRegistrationEntries_Invalid(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := agentutils.ReadRegistrationEntries(managers.NewFileManager(), filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+
+	fileName := filepath.Join(dir, "entries.json")
+	require.NoError(t, os.WriteFile(fileName, []byte(`{"entries":[{"spiffeId":"https://example.org/web","selectors":["unix:uid:1000"]}]}`), 0600))
+	_, err = agentutils.ReadRegistrationEntries(managers.NewFileManager(), fileName)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(fileName, []byte(`{`), 0600))
+	_, err = agentutils.ReadRegistrationEntries(managers.NewFileManager(), fileName)
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentutils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+
+	// UnixSelectorType is the type of selectors which match the Unix peer
+	// credentials of a workload
+	UnixSelectorType = "unix"
+
+	// UnixUidSelector matches the user ID of the workload process
+	UnixUidSelector = "uid"
+
+	// UnixGidSelector matches the group ID of the workload process
+	UnixGidSelector = "gid"
+
+	// UnixPathSelector matches the path of the executable of the workload
+	// process
+	UnixPathSelector = "path"
+)
+
+// ValidateSelector checks a selector is in the form "unix:uid:1000",
+// "unix:gid:1000" or "unix:path:/usr/bin/web"
+func ValidateSelector(selector string) error {
+	selectorType, rest, found := strings.Cut(selector, ":")
+	if !found || selectorType != UnixSelectorType {
+		return fmt.Errorf("ValidateSelector: unsupported type: '%s'", selector)
+	}
+	name, value, found := strings.Cut(rest, ":")
+	if !found || value == "" {
+		return fmt.Errorf("ValidateSelector: value: must be defined: '%s'", selector)
+	}
+	switch name {
+	case UnixUidSelector, UnixGidSelector:
+		if _, err := strconv.ParseUint(value, 10, 32); err != nil {
+			return fmt.Errorf("ValidateSelector: %s: must be a number: '%s'", name, value)
+		}
+	case UnixPathSelector:
+		if !strings.HasPrefix(value, "/") {
+			return fmt.Errorf("ValidateSelector: %s: must be absolute: '%s'", name, value)
+		}
+	default:
+		return fmt.Errorf("ValidateSelector: unsupported selector: '%s'", selector)
+	}
+	return nil
+}
+
+// UnixSelectors returns the selectors of a workload process
+//   - uid: The user ID of the process
+//   - gid: The group ID of the process
+//   - path: The path of the executable, or empty if it is not known
+func UnixSelectors(uid, gid uint32, path string) []string {
+	selectors := []string{
+		fmt.Sprintf("%s:%s:%d", UnixSelectorType, UnixUidSelector, uid),
+		fmt.Sprintf("%s:%s:%d", UnixSelectorType, UnixGidSelector, gid),
+	}
+	if path != "" {
+		selectors = append(selectors, fmt.Sprintf("%s:%s:%s", UnixSelectorType, UnixPathSelector, path))
+	}
+	return selectors
+}
+
+// HasPathSelector returns true if one of the selectors matches the path of
+// the executable
+func HasPathSelector(selectors []string) bool {
+	prefix := UnixSelectorType + ":" + UnixPathSelector + ":"
+	for _, selector := range selectors {
+		if strings.HasPrefix(selector, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchSelectors checks if the workload has all selectors of a registration
+// entry. Entries without selectors match nothing.
+func MatchSelectors(entrySelectors, workloadSelectors []string) bool {
+	if len(entrySelectors) == 0 {
+		return false
+	}
+	for _, selector := range entrySelectors {
+		found := false
+		for _, workloadSelector := range workloadSelectors {
+			if selector == workloadSelector {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentutils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentutils"
+)
+
+func TestValidateSelector(t *testing.T) {
+	for _, selector := range []string{"unix:uid:0", "unix:uid:1000", "unix:gid:1000", "unix:path:/usr/bin/web"} {
+		assert.NoError(t, agentutils.ValidateSelector(selector), selector)
+	}
+	for _, selector := range []string{"", "unix", "unix:uid", "unix:uid:", "unix:uid:-1", "unix:uid:root", "unix:gid:4294967296", "unix:path:web", "unix:pid:1", "k8s:ns:default"} {
+		assert.Error(t, agentutils.ValidateSelector(selector), selector)
+	}
+}
+
+func TestUnixSelectors(t *testing.T) {
+	assert.Equal(t, []string{"unix:uid:1000", "unix:gid:100", "unix:path:/usr/bin/web"}, agentutils.UnixSelectors(1000, 100, "/usr/bin/web"))
+	assert.Equal(t, []string{"unix:uid:0", "unix:gid:0"}, agentutils.UnixSelectors(0, 0, ""))
+}
+
+func TestMatchSelectors(t *testing.T) {
+	workload := agentutils.UnixSelectors(1000, 100, "/usr/bin/web")
+	assert.True(t, agentutils.MatchSelectors([]string{"unix:uid:1000"}, workload))
+	assert.True(t, agentutils.MatchSelectors([]string{"unix:uid:1000", "unix:path:/usr/bin/web"}, workload))
+	assert.False(t, agentutils.MatchSelectors([]string{"unix:uid:1000", "unix:gid:0"}, workload))
+	assert.False(t, agentutils.MatchSelectors([]string{"unix:uid:0"}, workload))
+	assert.False(t, agentutils.MatchSelectors(nil, workload))
+}
+
+func TestHasPathSelector(t *testing.T) {
+	assert.True(t, agentutils.HasPathSelector([]string{"unix:uid:1000", "unix:path:/usr/bin/web"}))
+	assert.False(t, agentutils.HasPathSelector([]string{"unix:uid:1000", "unix:gid:100"}))
+	assert.False(t, agentutils.HasPathSelector(nil))
+}
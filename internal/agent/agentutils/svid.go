@@ -0,0 +1,73 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentutils
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// ShouldRotate checks if an SVID is past its half-life and should be renewed
+func ShouldRotate(svid agentmodels.Svid, now time.Time) bool {
+	lifetime := svid.NotAfter().Sub(svid.NotBefore())
+	return !now.Before(svid.NotBefore().Add(lifetime / 2))
+}
+
+// TrustDomainID returns the SPIFFE ID of a trust domain, e.g.
+// "spiffe://example.org"
+func TrustDomainID(trustDomain string) string {
+	return apputils.SpiffeScheme + "://" + trustDomain
+}
+
+// ConcatenateCertificates returns the DER encoded certificates one after
+// another, as used by the Workload API
+func ConcatenateCertificates(certificates []*x509.Certificate) []byte {
+	var buffer bytes.Buffer
+	for _, certificate := range certificates {
+		buffer.Write(certificate.Raw)
+	}
+	return buffer.Bytes()
+}
+
+// ToX509SvidResponse converts SVIDs and the bundle of their trust domain to a
+// Workload API response
+func ToX509SvidResponse(svids []agentmodels.Svid, bundle agentmodels.Bundle) (*workload.X509SVIDResponse, error) {
+	if bundle == nil {
+		return nil, fmt.Errorf("ToX509SvidResponse: bundle: must be defined")
+	}
+	bundleBytes := ConcatenateCertificates(bundle.Certificates())
+	list := make([]*workload.X509SVID, len(svids))
+	for i, svid := range svids {
+		key, err := x509.MarshalPKCS8PrivateKey(svid.PrivateKey())
+		if err != nil {
+			return nil, fmt.Errorf("ToX509SvidResponse: %s: %w", svid.SpiffeID(), err)
+		}
+		list[i] = &workload.X509SVID{
+			SpiffeId:    svid.SpiffeID(),
+			X509Svid:    ConcatenateCertificates(svid.Certificates()),
+			X509SvidKey: key,
+			Bundle:      bundleBytes,
+			Hint:        svid.Hint(),
+		}
+	}
+	return &workload.X509SVIDResponse{Svids: list}, nil
+}
+
+// ToX509BundlesResponse converts a bundle to a Workload API response
+func ToX509BundlesResponse(bundle agentmodels.Bundle) (*workload.X509BundlesResponse, error) {
+	if bundle == nil {
+		return nil, fmt.Errorf("ToX509BundlesResponse: bundle: must be defined")
+	}
+	return &workload.X509BundlesResponse{
+		Bundles: map[string][]byte{
+			TrustDomainID(bundle.TrustDomain()): ConcatenateCertificates(bundle.Certificates()),
+		},
+	}, nil
+}
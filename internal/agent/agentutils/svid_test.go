@@ -0,0 +1,78 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package agentutils_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentmodels"
+	"github.com/hyperifyio/gocertcenter/internal/agent/agentutils"
+)
+
+func TestShouldRotate(t *testing.T) {
+	notBefore := time.Unix(1000, 0)
+	svid := agentmodels.NewSvid("spiffe://example.org/web", "", []*x509.Certificate{{NotBefore: notBefore, NotAfter: notBefore.Add(time.Hour)}}, nil)
+	assert.False(t, agentutils.ShouldRotate(svid, notBefore))
+	assert.False(t, agentutils.ShouldRotate(svid, notBefore.Add(29*time.Minute)))
+	assert.True(t, agentutils.ShouldRotate(svid, notBefore.Add(30*time.Minute)))
+	assert.True(t, agentutils.ShouldRotate(svid, notBefore.Add(2*time.Hour)))
+}
+
+func TestTrustDomainID(t *testing.T) {
+	assert.Equal(t, "spiffe://example.org", agentutils.TrustDomainID("example.org"))
+}
+
+func TestConcatenateCertificates(t *testing.T) {
+	assert.Equal(t, []byte("ab"), agentutils.ConcatenateCertificates([]*x509.Certificate{{Raw: []byte("a")}, {Raw: []byte("b")}}))
+	assert.Empty(t, agentutils.ConcatenateCertificates(nil))
+}
+
+func TestToX509SvidResponse(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	svid := agentmodels.NewSvid("spiffe://example.org/web", "internal", []*x509.Certificate{certificate}, key)
+	bundle := agentmodels.NewBundle("example.org", []*x509.Certificate{{Raw: []byte("root")}})
+
+	response, err := agentutils.ToX509SvidResponse([]agentmodels.Svid{svid}, bundle)
+	require.NoError(t, err)
+	require.Len(t, response.Svids, 1)
+	assert.Equal(t, "spiffe://example.org/web", response.Svids[0].SpiffeId)
+	assert.Equal(t, "internal", response.Svids[0].Hint)
+	assert.Equal(t, der, response.Svids[0].X509Svid)
+	assert.Equal(t, []byte("root"), response.Svids[0].Bundle)
+	parsed, err := x509.ParsePKCS8PrivateKey(response.Svids[0].X509SvidKey)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	_, err = agentutils.ToX509SvidResponse([]agentmodels.Svid{svid}, nil)
+	assert.Error(t, err)
+
+	invalid := agentmodels.NewSvid("spiffe://example.org/web", "", []*x509.Certificate{certificate}, "not a key")
+	_, err = agentutils.ToX509SvidResponse([]agentmodels.Svid{invalid}, bundle)
+	assert.Error(t, err)
+}
+
+func TestToX509BundlesResponse(t *testing.T) {
+	bundle := agentmodels.NewBundle("example.org", []*x509.Certificate{{Raw: []byte("a")}, {Raw: []byte("b")}})
+	response, err := agentutils.ToX509BundlesResponse(bundle)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"spiffe://example.org": []byte("ab")}, response.Bundles)
+
+	_, err = agentutils.ToX509BundlesResponse(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,566 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// DefaultAcmeOrderExpiration is the lifetime of pending ACME orders and
+// authorizations
+const DefaultAcmeOrderExpiration = 7 * 24 * time.Hour
+
+// MaxAcmeOrderIdentifiers is the maximum number of identifiers in one order
+const MaxAcmeOrderIdentifiers = 100
+
+// CertAcmeController implements appmodels.AcmeController. Errors which should
+// be reported to the ACME client are returned as *appmodels.AcmeError.
+type CertAcmeController struct {
+	accountRepository       appmodels.AcmeAccountRepository
+	orderRepository         appmodels.AcmeOrderRepository
+	authorizationRepository appmodels.AcmeAuthorizationRepository
+	nonceRepository         appmodels.AcmeNonceRepository
+
+	networkManager managers.NetworkManager
+	randomManager  managers.RandomManager
+
+	// expiration is the expiration duration of issued certificates
+	expiration time.Duration
+
+	// http01Port is the port used to validate http-01 challenges
+	http01Port int
+
+	// tlsAlpn01Port is the port used to validate tls-alpn-01 challenges
+	tlsAlpn01Port int
+
+	// finalizeMutex makes moving an order from ready to processing atomic,
+	// so that an order is finalized only once
+	finalizeMutex sync.Mutex
+}
+
+// SetHttp01Port changes the port used to validate http-01 challenges
+func (r *CertAcmeController) SetHttp01Port(port int) {
+	r.http01Port = port
+}
+
+// SetTlsAlpn01Port changes the port used to validate tls-alpn-01 challenges
+func (r *CertAcmeController) SetTlsAlpn01Port(port int) {
+	r.tlsAlpn01Port = port
+}
+
+func (r *CertAcmeController) NewNonce() (string, error) {
+	nonce, err := apputils.GenerateAcmeToken(r.randomManager)
+	if err != nil {
+		return "", appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[NewNonce]: %v", err)
+	}
+	if err := r.nonceRepository.Save(nonce); err != nil {
+		return "", appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[NewNonce]: failed to save: %v", err)
+	}
+	return nonce, nil
+}
+
+func (r *CertAcmeController) UseNonce(nonce string) error {
+	if nonce == "" || r.nonceRepository.Delete(nonce) != nil {
+		return appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_NONCE, "unknown or already used nonce")
+	}
+	return nil
+}
+
+func (r *CertAcmeController) Account(issuer appmodels.CertificateController, id string) (appmodels.AcmeAccount, error) {
+	account, err := r.accountRepository.FindById(id)
+	if err != nil || !isAcmeAccountOf(account, issuer) {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_ACCOUNT_DOES_NOT_EXIST, "account not found: %s", id)
+	}
+	return account, nil
+}
+
+func (r *CertAcmeController) AccountByKey(issuer appmodels.CertificateController, publicKey any) (appmodels.AcmeAccount, error) {
+	thumbprint, err := apputils.JwkThumbprint(publicKey)
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_SIGNATURE_ALGORITHM, "[AccountByKey]: %v", err)
+	}
+	account, err := r.accountRepository.FindByThumbprint(issuer.OrganizationID(), issuer.Certificate().SerialNumber(), thumbprint)
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_ACCOUNT_DOES_NOT_EXIST, "no account for the key")
+	}
+	return account, nil
+}
+
+func (r *CertAcmeController) NewAccount(issuer appmodels.CertificateController, publicKey any, contact []string) (appmodels.AcmeAccount, error) {
+
+	thumbprint, err := apputils.JwkThumbprint(publicKey)
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_SIGNATURE_ALGORITHM, "[NewAccount]: %v", err)
+	}
+
+	organization := issuer.OrganizationID()
+	serialNumber := issuer.Certificate().SerialNumber()
+
+	if _, err := r.accountRepository.FindByThumbprint(organization, serialNumber, thumbprint); err == nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "[NewAccount]: account exists already for the key")
+	}
+
+	id, err := apputils.GenerateAcmeToken(r.randomManager)
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[NewAccount]: %v", err)
+	}
+
+	saved, err := r.accountRepository.Save(appmodels.NewAcmeAccount(
+		id,
+		organization,
+		serialNumber,
+		appmodels.ACME_STATUS_VALID,
+		contact,
+		publicKey,
+		thumbprint,
+		time.Now(),
+	))
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[NewAccount]: failed to save: %v", err)
+	}
+	return saved, nil
+}
+
+func (r *CertAcmeController) UpdateAccount(account appmodels.AcmeAccount, contact []string) (appmodels.AcmeAccount, error) {
+	return r.saveAccount(account, account.Status(), contact)
+}
+
+func (r *CertAcmeController) DeactivateAccount(account appmodels.AcmeAccount) (appmodels.AcmeAccount, error) {
+	return r.saveAccount(account, appmodels.ACME_STATUS_DEACTIVATED, account.Contact())
+}
+
+func (r *CertAcmeController) OrderCollection(account appmodels.AcmeAccount) ([]appmodels.AcmeOrder, error) {
+	list, err := r.orderRepository.FindAllByAccount(account.ID())
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[OrderCollection]: %v", err)
+	}
+	return list, nil
+}
+
+func (r *CertAcmeController) NewOrder(account appmodels.AcmeAccount, dnsNames []string) (appmodels.AcmeOrder, error) {
+
+	if len(dnsNames) == 0 {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "order must have at least one identifier")
+	}
+	if len(dnsNames) > MaxAcmeOrderIdentifiers {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_REJECTED_IDENTIFIER, "order must not have more than %d identifiers", MaxAcmeOrderIdentifiers)
+	}
+
+	names := make([]string, 0, len(dnsNames))
+	for _, name := range dnsNames {
+		name = strings.ToLower(name)
+		if err := apputils.ValidateServerCertificateCommonName(name); err != nil {
+			return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_REJECTED_IDENTIFIER, "invalid identifier '%s': %v", name, err)
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	orderID, err := apputils.GenerateAcmeToken(r.randomManager)
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[NewOrder]: %v", err)
+	}
+
+	expires := time.Now().Add(DefaultAcmeOrderExpiration)
+
+	authorizations := make([]string, len(names))
+	for i, name := range names {
+		authorization, err := r.newAuthorization(account, orderID, name, expires)
+		if err != nil {
+			return nil, err
+		}
+		authorizations[i] = authorization.ID()
+	}
+
+	saved, err := r.orderRepository.Save(appmodels.NewAcmeOrder(
+		orderID,
+		account.ID(),
+		appmodels.ACME_STATUS_PENDING,
+		expires,
+		names,
+		authorizations,
+		nil,
+		nil,
+	))
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[NewOrder]: failed to save: %v", err)
+	}
+	return saved, nil
+}
+
+func (r *CertAcmeController) Order(account appmodels.AcmeAccount, id string) (appmodels.AcmeOrder, error) {
+	order, err := r.orderRepository.FindById(id)
+	if err != nil || order.AccountID() != account.ID() {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_NOT_FOUND, "order not found: %s", id)
+	}
+	return order, nil
+}
+
+func (r *CertAcmeController) Authorization(account appmodels.AcmeAccount, id string) (appmodels.AcmeAuthorization, error) {
+	authorization, err := r.authorizationRepository.FindById(id)
+	if err != nil || authorization.AccountID() != account.ID() {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_NOT_FOUND, "authorization not found: %s", id)
+	}
+	return authorization, nil
+}
+
+func (r *CertAcmeController) ValidateChallenge(account appmodels.AcmeAccount, authorizationID, challengeID string) (appmodels.AcmeAuthorization, error) {
+
+	authorization, err := r.Authorization(account, authorizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := authorization.Challenge(challengeID)
+	if challenge == nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_NOT_FOUND, "challenge not found: %s", challengeID)
+	}
+
+	// Responding again to a processed challenge has no effect
+	if authorization.Status() != appmodels.ACME_STATUS_PENDING || challenge.Status() != appmodels.ACME_STATUS_PENDING {
+		return authorization, nil
+	}
+
+	if time.Now().After(authorization.Expires()) {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_UNAUTHORIZED, "authorization has expired: %s", authorizationID)
+	}
+
+	port := 0
+	switch challenge.Type() {
+	case appmodels.ACME_HTTP_01:
+		port = r.http01Port
+	case appmodels.ACME_TLS_ALPN_01:
+		port = r.tlsAlpn01Port
+	}
+
+	validationErr := apputils.ValidateAcmeChallenge(
+		r.networkManager,
+		challenge.Type(),
+		authorization.DNSName(),
+		port,
+		apputils.AcmeKeyAuthorization(challenge.Token(), account.Thumbprint()),
+	)
+
+	status := appmodels.ACME_STATUS_VALID
+	validated := time.Now()
+	if validationErr != nil {
+		status = appmodels.ACME_STATUS_INVALID
+		validated = time.Time{}
+	}
+
+	challenges := make([]appmodels.AcmeChallenge, len(authorization.Challenges()))
+	for i, item := range authorization.Challenges() {
+		if item.ID() == challengeID {
+			item = appmodels.NewAcmeChallenge(item.ID(), item.Type(), item.Token(), status, validated, validationErr)
+		}
+		challenges[i] = item
+	}
+
+	saved, err := r.authorizationRepository.Save(appmodels.NewAcmeAuthorization(
+		authorization.ID(),
+		authorization.AccountID(),
+		authorization.OrderID(),
+		status,
+		authorization.Expires(),
+		authorization.DNSName(),
+		authorization.Wildcard(),
+		challenges,
+	))
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[ValidateChallenge]: failed to save authorization: %v", err)
+	}
+
+	if err := r.updateOrderStatus(account, saved.OrderID(), validationErr); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (r *CertAcmeController) FinalizeOrder(issuer appmodels.CertificateController, account appmodels.AcmeAccount, id string, csr *x509.CertificateRequest) (appmodels.AcmeOrder, error) {
+
+	order, err := r.Order(account, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status() != appmodels.ACME_STATUS_READY {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_ORDER_NOT_READY, "order is %s", order.Status())
+	}
+
+	if csr == nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_CSR, "csr must be defined")
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_CSR, "invalid csr signature: %v", err)
+	}
+
+	names := make([]string, 0, len(csr.DNSNames)+1)
+	for _, name := range append([]string{csr.Subject.CommonName}, csr.DNSNames...) {
+		name = strings.ToLower(name)
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	expected := slices.Clone(order.DNSNames())
+	slices.Sort(names)
+	slices.Sort(expected)
+	if !slices.Equal(names, expected) {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_CSR, "csr identifiers do not match the order")
+	}
+
+	order, err = r.startProcessing(account, id)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer.SetExpirationDuration(r.expiration)
+	certificate, err := issuer.NewServerCertificateFromPublicKey(appmodels.NewPublicKey(csr.PublicKey), order.DNSNames()...)
+
+	// The order can be finalized again after the private keys are unsealed
+	if errors.Is(err, appmodels.ErrSealed) {
+		if _, err := r.orderRepository.Save(withOrderStatus(order, appmodels.ACME_STATUS_READY, nil)); err != nil {
+			log.Printf("[FinalizeOrder]: failed to save ready order %s: %v", order.ID(), err)
+		}
+		return nil, fmt.Errorf("[FinalizeOrder]: %w", err)
+	}
+	if err != nil {
+		acmeErr := appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[FinalizeOrder]: failed to issue certificate: %v", err)
+		if _, err := r.orderRepository.Save(withOrderStatus(order, appmodels.ACME_STATUS_INVALID, acmeErr)); err != nil {
+			log.Printf("[FinalizeOrder]: failed to save invalid order %s: %v", order.ID(), err)
+		}
+		return nil, acmeErr
+	}
+
+	saved, err := r.orderRepository.Save(appmodels.NewAcmeOrder(
+		order.ID(),
+		order.AccountID(),
+		appmodels.ACME_STATUS_VALID,
+		order.Expires(),
+		order.DNSNames(),
+		order.AuthorizationIDs(),
+		certificate.SerialNumber(),
+		nil,
+	))
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[FinalizeOrder]: failed to save: %v", err)
+	}
+	return saved, nil
+}
+
+// startProcessing moves a ready order to the processing state. Only one
+// request can move the order, so the certificate is issued once.
+func (r *CertAcmeController) startProcessing(account appmodels.AcmeAccount, id string) (appmodels.AcmeOrder, error) {
+	r.finalizeMutex.Lock()
+	defer r.finalizeMutex.Unlock()
+
+	order, err := r.Order(account, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status() != appmodels.ACME_STATUS_READY {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_ORDER_NOT_READY, "order is %s", order.Status())
+	}
+	saved, err := r.orderRepository.Save(withOrderStatus(order, appmodels.ACME_STATUS_PROCESSING, nil))
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[FinalizeOrder]: failed to save: %v", err)
+	}
+	return saved, nil
+}
+
+// withOrderStatus returns a copy of an order with another status
+func withOrderStatus(order appmodels.AcmeOrder, status appmodels.AcmeStatus, err *appmodels.AcmeError) appmodels.AcmeOrder {
+	return appmodels.NewAcmeOrder(
+		order.ID(),
+		order.AccountID(),
+		status,
+		order.Expires(),
+		order.DNSNames(),
+		order.AuthorizationIDs(),
+		order.CertificateSerialNumber(),
+		err,
+	)
+}
+
+func (r *CertAcmeController) CertificateChain(issuer appmodels.CertificateController, account appmodels.AcmeAccount, serialNumber *big.Int) ([]appmodels.Certificate, error) {
+
+	orders, err := r.OrderCollection(account)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := slices.ContainsFunc(orders, func(order appmodels.AcmeOrder) bool {
+		return order.CertificateSerialNumber() != nil && order.CertificateSerialNumber().Cmp(serialNumber) == 0
+	})
+	if !owned {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_NOT_FOUND, "certificate not found: %s", serialNumber)
+	}
+
+	certificate, err := issuer.ChildCertificate(serialNumber)
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_NOT_FOUND, "certificate not found: %s", serialNumber)
+	}
+
+	return []appmodels.Certificate{certificate, issuer.Certificate()}, nil
+}
+
+func (r *CertAcmeController) saveAccount(account appmodels.AcmeAccount, status appmodels.AcmeStatus, contact []string) (appmodels.AcmeAccount, error) {
+	saved, err := r.accountRepository.Save(appmodels.NewAcmeAccount(
+		account.ID(),
+		account.OrganizationID(),
+		account.IssuerSerialNumber(),
+		status,
+		contact,
+		account.PublicKey(),
+		account.Thumbprint(),
+		account.CreatedAt(),
+	))
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[saveAccount]: failed to save: %v", err)
+	}
+	return saved, nil
+}
+
+// newAuthorization creates the authorization and challenges for one
+// identifier. Wildcard identifiers can only be validated using DNS.
+func (r *CertAcmeController) newAuthorization(account appmodels.AcmeAccount, order, dnsName string, expires time.Time) (appmodels.AcmeAuthorization, error) {
+
+	wildcard := apputils.IsAcmeWildcard(dnsName)
+	types := []appmodels.AcmeChallengeType{appmodels.ACME_HTTP_01, appmodels.ACME_DNS_01, appmodels.ACME_TLS_ALPN_01}
+	if wildcard {
+		dnsName = dnsName[2:]
+		types = []appmodels.AcmeChallengeType{appmodels.ACME_DNS_01}
+	}
+
+	id, err := apputils.GenerateAcmeToken(r.randomManager)
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[newAuthorization]: %v", err)
+	}
+
+	challenges := make([]appmodels.AcmeChallenge, len(types))
+	for i, challengeType := range types {
+		token, err := apputils.GenerateAcmeToken(r.randomManager)
+		if err != nil {
+			return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[newAuthorization]: %v", err)
+		}
+		challenges[i] = appmodels.NewAcmeChallenge(
+			fmt.Sprintf("%d", i+1),
+			challengeType,
+			token,
+			appmodels.ACME_STATUS_PENDING,
+			time.Time{},
+			nil,
+		)
+	}
+
+	saved, err := r.authorizationRepository.Save(appmodels.NewAcmeAuthorization(
+		id,
+		account.ID(),
+		order,
+		appmodels.ACME_STATUS_PENDING,
+		expires,
+		dnsName,
+		wildcard,
+		challenges,
+	))
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[newAuthorization]: failed to save: %v", err)
+	}
+	return saved, nil
+}
+
+// updateOrderStatus moves a pending order to ready when all of its
+// authorizations are valid, or to invalid when one of them failed
+func (r *CertAcmeController) updateOrderStatus(account appmodels.AcmeAccount, id string, validationErr *appmodels.AcmeError) error {
+
+	order, err := r.Order(account, id)
+	if err != nil {
+		return err
+	}
+
+	if order.Status() != appmodels.ACME_STATUS_PENDING {
+		return nil
+	}
+
+	status := appmodels.ACME_STATUS_READY
+	if validationErr != nil {
+		status = appmodels.ACME_STATUS_INVALID
+	} else {
+		for _, authorizationID := range order.AuthorizationIDs() {
+			authorization, err := r.Authorization(account, authorizationID)
+			if err != nil {
+				return err
+			}
+			if authorization.Status() != appmodels.ACME_STATUS_VALID {
+				return nil
+			}
+		}
+	}
+
+	_, err = r.orderRepository.Save(appmodels.NewAcmeOrder(
+		order.ID(),
+		order.AccountID(),
+		status,
+		order.Expires(),
+		order.DNSNames(),
+		order.AuthorizationIDs(),
+		nil,
+		validationErr,
+	))
+	if err != nil {
+		return appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "[updateOrderStatus]: failed to save: %v", err)
+	}
+	return nil
+}
+
+// isAcmeAccountOf returns true if the account belongs to the ACME directory
+// of the issuer
+func isAcmeAccountOf(account appmodels.AcmeAccount, issuer appmodels.CertificateController) bool {
+	return account.OrganizationID().Cmp(issuer.OrganizationID()) == 0 &&
+		account.IssuerSerialNumber().Cmp(issuer.Certificate().SerialNumber()) == 0
+}
+
+// NewAcmeController creates an ACME controller
+//   - accountRepository: The ACME account repository
+//   - orderRepository: The ACME order repository
+//   - authorizationRepository: The ACME authorization repository
+//   - nonceRepository: The ACME nonce repository
+//   - networkManager: The network manager used to validate challenges
+//   - randomManager: The random manager used to create identifiers
+//   - expiration: The expiration duration of issued certificates
+func NewAcmeController(
+	accountRepository appmodels.AcmeAccountRepository,
+	orderRepository appmodels.AcmeOrderRepository,
+	authorizationRepository appmodels.AcmeAuthorizationRepository,
+	nonceRepository appmodels.AcmeNonceRepository,
+	networkManager managers.NetworkManager,
+	randomManager managers.RandomManager,
+	expiration time.Duration,
+) *CertAcmeController {
+	return &CertAcmeController{
+		accountRepository:       accountRepository,
+		orderRepository:         orderRepository,
+		authorizationRepository: authorizationRepository,
+		nonceRepository:         nonceRepository,
+		networkManager:          networkManager,
+		randomManager:           randomManager,
+		expiration:              expiration,
+		http01Port:              80,
+		tlsAlpn01Port:           443,
+	}
+}
+
+var _ appmodels.AcmeController = (*CertAcmeController)(nil)
@@ -0,0 +1,270 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/commonmocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestAcmeController(networkManager managers.NetworkManager) *appcontrollers.CertAcmeController {
+	return appcontrollers.NewAcmeController(
+		memoryrepository.NewAcmeAccountRepository(),
+		memoryrepository.NewAcmeOrderRepository(),
+		memoryrepository.NewAcmeAuthorizationRepository(),
+		memoryrepository.NewAcmeNonceRepository(0),
+		networkManager,
+		managers.NewRandomManager(),
+		time.Hour,
+	)
+}
+
+func newTestAcmeIssuer(organization, serialNumber int64) *appmocks.MockCertificateController {
+	issuerCert := new(appmocks.MockCertificate)
+	issuerCert.On("SerialNumber").Return(big.NewInt(serialNumber))
+	issuer := new(appmocks.MockCertificateController)
+	issuer.On("OrganizationID").Return(big.NewInt(organization))
+	issuer.On("Certificate").Return(issuerCert)
+	return issuer
+}
+
+func requireAcmeErrorType(t *testing.T, err error, errorType appmodels.AcmeErrorType) {
+	var acmeErr *appmodels.AcmeError
+	require.ErrorAs(t, err, &acmeErr)
+	assert.Equal(t, errorType, acmeErr.Type())
+}
+
+func TestCertAcmeController_Nonce(t *testing.T) {
+	controller := newTestAcmeController(commonmocks.NewMockNetworkManager())
+
+	nonce, err := controller.NewNonce()
+	require.NoError(t, err)
+	assert.NotEmpty(t, nonce)
+
+	assert.NoError(t, controller.UseNonce(nonce))
+	requireAcmeErrorType(t, controller.UseNonce(nonce), appmodels.ACME_ERROR_BAD_NONCE)
+	requireAcmeErrorType(t, controller.UseNonce(""), appmodels.ACME_ERROR_BAD_NONCE)
+}
+
+func TestCertAcmeController_Account(t *testing.T) {
+	controller := newTestAcmeController(commonmocks.NewMockNetworkManager())
+	issuer := newTestAcmeIssuer(1, 2)
+	otherIssuer := newTestAcmeIssuer(1, 3)
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	_, err := controller.AccountByKey(issuer, &key.PublicKey)
+	requireAcmeErrorType(t, err, appmodels.ACME_ERROR_ACCOUNT_DOES_NOT_EXIST)
+
+	account, err := controller.NewAccount(issuer, &key.PublicKey, []string{"mailto:a@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ACME_STATUS_VALID, account.Status())
+
+	_, err = controller.NewAccount(issuer, &key.PublicKey, nil)
+	assert.Error(t, err)
+
+	found, err := controller.AccountByKey(issuer, &key.PublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, account.ID(), found.ID())
+
+	found, err = controller.Account(issuer, account.ID())
+	require.NoError(t, err)
+	assert.Equal(t, account.ID(), found.ID())
+
+	_, err = controller.Account(otherIssuer, account.ID())
+	requireAcmeErrorType(t, err, appmodels.ACME_ERROR_ACCOUNT_DOES_NOT_EXIST)
+
+	updated, err := controller.UpdateAccount(account, []string{"mailto:b@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mailto:b@example.com"}, updated.Contact())
+
+	deactivated, err := controller.DeactivateAccount(updated)
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ACME_STATUS_DEACTIVATED, deactivated.Status())
+}
+
+func TestCertAcmeController_NewOrder(t *testing.T) {
+	controller := newTestAcmeController(commonmocks.NewMockNetworkManager())
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	account, err := controller.NewAccount(newTestAcmeIssuer(1, 2), &key.PublicKey, nil)
+	require.NoError(t, err)
+
+	_, err = controller.NewOrder(account, nil)
+	requireAcmeErrorType(t, err, appmodels.ACME_ERROR_MALFORMED)
+
+	_, err = controller.NewOrder(account, []string{"bad_name"})
+	requireAcmeErrorType(t, err, appmodels.ACME_ERROR_REJECTED_IDENTIFIER)
+
+	order, err := controller.NewOrder(account, []string{"Example.com", "example.com", "*.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ACME_STATUS_PENDING, order.Status())
+	assert.Equal(t, []string{"example.com", "*.example.com"}, order.DNSNames())
+	require.Len(t, order.AuthorizationIDs(), 2)
+
+	authorization, err := controller.Authorization(account, order.AuthorizationIDs()[0])
+	require.NoError(t, err)
+	assert.Len(t, authorization.Challenges(), 3)
+	assert.False(t, authorization.Wildcard())
+
+	wildcard, err := controller.Authorization(account, order.AuthorizationIDs()[1])
+	require.NoError(t, err)
+	assert.True(t, wildcard.Wildcard())
+	assert.Equal(t, "example.com", wildcard.DNSName())
+	require.Len(t, wildcard.Challenges(), 1)
+	assert.Equal(t, appmodels.ACME_DNS_01, wildcard.Challenges()[0].Type())
+
+	list, err := controller.OrderCollection(account)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	found, err := controller.Order(account, order.ID())
+	require.NoError(t, err)
+	assert.Equal(t, order.ID(), found.ID())
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, err := controller.NewAccount(newTestAcmeIssuer(1, 2), &otherKey.PublicKey, nil)
+	require.NoError(t, err)
+	_, err = controller.Order(other, order.ID())
+	requireAcmeErrorType(t, err, appmodels.ACME_ERROR_NOT_FOUND)
+	_, err = controller.Authorization(other, order.AuthorizationIDs()[0])
+	requireAcmeErrorType(t, err, appmodels.ACME_ERROR_NOT_FOUND)
+}
+
+func TestCertAcmeController_ValidateChallenge_Invalid(t *testing.T) {
+	networkManager := commonmocks.NewMockNetworkManager()
+	networkManager.On("HttpGet", mock.Anything).Return(nil, errors.New("refused"))
+	controller := newTestAcmeController(networkManager)
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	account, _ := controller.NewAccount(newTestAcmeIssuer(1, 2), &key.PublicKey, nil)
+	order, err := controller.NewOrder(account, []string{"example.com"})
+	require.NoError(t, err)
+
+	authorization, err := controller.Authorization(account, order.AuthorizationIDs()[0])
+	require.NoError(t, err)
+	challenge := authorization.Challenges()[0]
+	require.Equal(t, appmodels.ACME_HTTP_01, challenge.Type())
+
+	updated, err := controller.ValidateChallenge(account, authorization.ID(), challenge.ID())
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ACME_STATUS_INVALID, updated.Status())
+	assert.Equal(t, appmodels.ACME_ERROR_CONNECTION, updated.Challenge(challenge.ID()).Error().Type())
+
+	order, err = controller.Order(account, order.ID())
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ACME_STATUS_INVALID, order.Status())
+
+	_, err = controller.ValidateChallenge(account, authorization.ID(), "missing")
+	requireAcmeErrorType(t, err, appmodels.ACME_ERROR_NOT_FOUND)
+}
+
+func TestCertAcmeController_FinalizeOrder(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	thumbprint, _ := apputils.JwkThumbprint(&key.PublicKey)
+
+	networkManager := commonmocks.NewMockNetworkManager()
+	controller := newTestAcmeController(networkManager)
+	issuer := newTestAcmeIssuer(1, 2)
+
+	account, err := controller.NewAccount(issuer, &key.PublicKey, nil)
+	require.NoError(t, err)
+	order, err := controller.NewOrder(account, []string{"example.com"})
+	require.NoError(t, err)
+
+	csrKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "example.com"},
+		DNSNames: []string{"example.com"},
+	}, csrKey)
+	require.NoError(t, err)
+	csr, err := x509.ParseCertificateRequest(der)
+	require.NoError(t, err)
+
+	_, err = controller.FinalizeOrder(issuer, account, order.ID(), csr)
+	requireAcmeErrorType(t, err, appmodels.ACME_ERROR_ORDER_NOT_READY)
+
+	authorization, err := controller.Authorization(account, order.AuthorizationIDs()[0])
+	require.NoError(t, err)
+	challenge := authorization.Challenges()[1]
+	require.Equal(t, appmodels.ACME_DNS_01, challenge.Type())
+	networkManager.On("LookupTXT", "_acme-challenge.example.com").Return(
+		[]string{apputils.AcmeDnsRecordValue(apputils.AcmeKeyAuthorization(challenge.Token(), thumbprint))},
+		nil,
+	)
+
+	updated, err := controller.ValidateChallenge(account, authorization.ID(), challenge.ID())
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ACME_STATUS_VALID, updated.Status())
+
+	order, err = controller.Order(account, order.ID())
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ACME_STATUS_READY, order.Status())
+
+	otherDer, _ := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: []string{"other.com"}}, csrKey)
+	otherCsr, _ := x509.ParseCertificateRequest(otherDer)
+	_, err = controller.FinalizeOrder(issuer, account, order.ID(), otherCsr)
+	requireAcmeErrorType(t, err, appmodels.ACME_ERROR_BAD_CSR)
+
+	// The order stays ready while the private keys are sealed
+	issuer.On("SetExpirationDuration", time.Hour).Return()
+	issuer.On("NewServerCertificateFromPublicKey", mock.Anything, []string{"example.com"}).Return(nil, appmodels.ErrSealed).Once()
+	_, err = controller.FinalizeOrder(issuer, account, order.ID(), csr)
+	assert.ErrorIs(t, err, appmodels.ErrSealed)
+	order, err = controller.Order(account, order.ID())
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ACME_STATUS_READY, order.Status())
+
+	leaf := new(appmocks.MockCertificate)
+	leaf.On("SerialNumber").Return(big.NewInt(100))
+	issuer.On("NewServerCertificateFromPublicKey", mock.Anything, []string{"example.com"}).Return(leaf, nil).Once()
+	issuer.On("ChildCertificate", big.NewInt(100)).Return(leaf, nil)
+
+	// Concurrent requests finalize the order only once
+	results := make(chan appmodels.AcmeOrder, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			finalized, err := controller.FinalizeOrder(issuer, account, order.ID(), csr)
+			if err != nil {
+				requireAcmeErrorType(t, err, appmodels.ACME_ERROR_ORDER_NOT_READY)
+				return
+			}
+			results <- finalized
+		}()
+	}
+	wg.Wait()
+	close(results)
+	require.Len(t, results, 1)
+	finalized := <-results
+	issuer.AssertNumberOfCalls(t, "NewServerCertificateFromPublicKey", 2)
+	assert.Equal(t, appmodels.ACME_STATUS_VALID, finalized.Status())
+	assert.Equal(t, big.NewInt(100), finalized.CertificateSerialNumber())
+
+	chain, err := controller.CertificateChain(issuer, account, big.NewInt(100))
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	assert.Equal(t, leaf, chain[0])
+
+	_, err = controller.CertificateChain(issuer, account, big.NewInt(101))
+	requireAcmeErrorType(t, err, appmodels.ACME_ERROR_NOT_FOUND)
+}
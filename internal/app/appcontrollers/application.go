@@ -19,6 +19,11 @@ type CertApplicationController struct {
 	organizationRepository appmodels.OrganizationRepository
 	certificateRepository  appmodels.CertificateRepository
 	privateKeyRepository   appmodels.PrivateKeyRepository
+	unitOfWorkRepository   appmodels.UnitOfWorkRepository
+
+	// revocationRepository - Revoked certificates, if certificates can be
+	// revoked
+	revocationRepository appmodels.CertificateRevocationRepository
 
 	// defaultExpiration - Expiration time for new root certificates
 	defaultExpiration time.Duration
@@ -44,22 +49,31 @@ func (a *CertApplicationController) Organization(organization *big.Int) (appmode
 	return model, nil
 }
 
+// SetRevocationRepository sets the repository where the organization
+// controllers save revoked certificates
+func (a *CertApplicationController) SetRevocationRepository(repository appmodels.CertificateRevocationRepository) {
+	a.revocationRepository = repository
+}
+
 func (a *CertApplicationController) OrganizationController(organization *big.Int) (appmodels.OrganizationController, error) {
 	model, err := a.Organization(organization)
 	if err != nil {
 		return nil, fmt.Errorf("[OrganizationController:%s]: not found: %w", organization, err)
 	}
-	return NewOrganizationController(
+	controller := NewOrganizationController(
 		organization,
 		model,
 		a.organizationRepository,
 		a.certificateRepository,
 		a.privateKeyRepository,
+		a.unitOfWorkRepository,
 		a.certManager,
 		a.randomManager,
 		a.defaultExpiration,
 		a,
-	), nil
+	)
+	controller.SetRevocationRepository(a.revocationRepository)
+	return controller, nil
 }
 
 func (a *CertApplicationController) NewOrganization(model appmodels.Organization) (appmodels.Organization, error) {
@@ -90,10 +104,19 @@ func (a *CertApplicationController) OrganizationCollection() ([]appmodels.Organi
 	return list, nil
 }
 
+func (a *CertApplicationController) OrganizationPage(query appmodels.OrganizationQuery) (appmodels.OrganizationPage, error) {
+	page, err := a.organizationRepository.FindPage(query)
+	if err != nil {
+		return appmodels.OrganizationPage{}, fmt.Errorf("[OrganizationPage]: failed: %w", err)
+	}
+	return page, nil
+}
+
 // NewApplicationController implements appmodels.ApplicationController
 //   - organizationRepository appmodels.OrganizationRepository
 //   - certificateRepository appmodels.CertificateRepository
 //   - privateKeyRepository appmodels.PrivateKeyRepository
+//   - unitOfWorkRepository appmodels.UnitOfWorkRepository
 //   - certManager managers.CertificateManager
 //   - randomManager managers.RandomManager
 //   - defaultExpiration time.Duration,
@@ -101,6 +124,7 @@ func NewApplicationController(
 	organizationRepository appmodels.OrganizationRepository,
 	certificateRepository appmodels.CertificateRepository,
 	privateKeyRepository appmodels.PrivateKeyRepository,
+	unitOfWorkRepository appmodels.UnitOfWorkRepository,
 	certManager managers.CertificateManager,
 	randomManager managers.RandomManager,
 	defaultExpiration time.Duration,
@@ -109,8 +133,10 @@ func NewApplicationController(
 		organizationRepository: organizationRepository,
 		certificateRepository:  certificateRepository,
 		privateKeyRepository:   privateKeyRepository,
+		unitOfWorkRepository:   unitOfWorkRepository,
 		certManager:            certManager,
 		randomManager:          randomManager,
+		defaultExpiration:      defaultExpiration,
 	}
 }
 
@@ -18,7 +18,7 @@ import (
 func TestApplicationController_UsesOrganizationService(t *testing.T) {
 	mockOrgService := new(appmocks.MockOrganizationService)
 	controller := appcontrollers.NewApplicationController(
-		mockOrgService, nil, nil, nil, nil, 0,
+		mockOrgService, nil, nil, nil, nil, nil, 0,
 	)
 
 	assert.True(t, controller.UsesOrganizationService(mockOrgService), "should return true when the service matches")
@@ -34,7 +34,7 @@ func TestApplicationController_GetOrganizationModel(t *testing.T) {
 	mockOrgService.On("FindById", orgID).Return(mockOrg, nil)
 
 	controller := appcontrollers.NewApplicationController(
-		mockOrgService, nil, nil, nil, nil, 0,
+		mockOrgService, nil, nil, nil, nil, nil, 0,
 	)
 
 	org, err := controller.Organization(orgID)
@@ -62,7 +62,7 @@ func TestApplicationController_NewOrganization(t *testing.T) {
 	mockOrgService.On("Save", mock.Anything).Return(mockOrg, nil)
 
 	controller := appcontrollers.NewApplicationController(
-		mockOrgService, nil, nil, nil, nil, 0,
+		mockOrgService, nil, nil, nil, nil, nil, 0,
 	)
 
 	savedOrg, err := controller.NewOrganization(mockOrg)
@@ -80,7 +80,7 @@ func TestApplicationController_NewOrganization(t *testing.T) {
 func TestApplicationController_UsesCertificateService(t *testing.T) {
 	mockCertService := new(appmocks.MockCertificateService)
 	controller := appcontrollers.NewApplicationController(
-		nil, mockCertService, nil, nil, nil, 0,
+		nil, mockCertService, nil, nil, nil, nil, 0,
 	)
 
 	assert.True(t, controller.UsesCertificateService(mockCertService), "should return true when the service matches")
@@ -90,7 +90,7 @@ func TestApplicationController_UsesCertificateService(t *testing.T) {
 func TestApplicationController_UsesPrivateKeyService(t *testing.T) {
 	mockPrivateKeyService := new(appmocks.MockPrivateKeyService)
 	controller := appcontrollers.NewApplicationController(
-		nil, nil, mockPrivateKeyService, nil, nil, 0,
+		nil, nil, mockPrivateKeyService, nil, nil, nil, 0,
 	)
 
 	assert.True(t, controller.UsesPrivateKeyService(mockPrivateKeyService), "should return true when the service matches")
@@ -105,7 +105,7 @@ func TestApplicationController_GetOrganizationController(t *testing.T) {
 	mockOrgService.On("FindById", orgID).Return(mockOrg, nil)
 
 	controller := appcontrollers.NewApplicationController(
-		mockOrgService, nil, nil, nil, nil, 0,
+		mockOrgService, nil, nil, nil, nil, nil, 0,
 	)
 
 	orgController, err := controller.OrganizationController(orgID)
@@ -127,7 +127,7 @@ func TestApplicationController_GetOrganizationCollection(t *testing.T) {
 	mockOrgService.On("FindAll").Return([]appmodels.Organization{mockOrg1, mockOrg2}, nil)
 
 	controller := appcontrollers.NewApplicationController(
-		mockOrgService, nil, nil, nil, nil, 0,
+		mockOrgService, nil, nil, nil, nil, nil, 0,
 	)
 
 	orgs, err := controller.OrganizationCollection()
@@ -153,7 +153,7 @@ func TestApplicationController_NewOrganization_ValidationFails(t *testing.T) {
 	invalidMockOrg.On("Slug").Return(orgSlug)
 
 	controller := appcontrollers.NewApplicationController(
-		mockOrgService, nil, nil, nil, nil, 0,
+		mockOrgService, nil, nil, nil, nil, nil, 0,
 	)
 
 	_, err := controller.NewOrganization(invalidMockOrg)
@@ -176,7 +176,7 @@ func TestApplicationController_NewOrganization_SaveFails(t *testing.T) {
 	mockOrgService.On("Save", mock.Anything).Return(nil, fmt.Errorf("save error")) // Simulating failure on save
 
 	controller := appcontrollers.NewApplicationController(
-		mockOrgService, nil, nil, nil, nil, 0,
+		mockOrgService, nil, nil, nil, nil, nil, 0,
 	)
 
 	_, err := controller.NewOrganization(mockOrg)
@@ -0,0 +1,706 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// DefaultApprovalExpiration is the time approvers have to approve an
+// operation, and the requester has to execute it, before it expires
+const DefaultApprovalExpiration = 24 * time.Hour
+
+// CertApprovalController implements appmodels.ApprovalController
+type CertApprovalController struct {
+	repository    appmodels.PendingOperationRepository
+	randomManager managers.RandomManager
+
+	// approvers maps the approver tokens to the names of the approvers
+	approvers map[string]string
+
+	// threshold is the number of approvals an operation needs
+	threshold int
+
+	// expiration is the lifetime of an operation
+	expiration time.Duration
+
+	// mutex serializes the decisions and executions so that an approved
+	// operation runs only once
+	mutex sync.Mutex
+}
+
+func (r *CertApprovalController) Authenticate(token string) (string, error) {
+	if token == "" {
+		return "", appmodels.ErrApprovalPermissionDenied
+	}
+	approver := ""
+	for candidate, name := range r.approvers {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			approver = name
+		}
+	}
+	if approver == "" {
+		return "", appmodels.ErrApprovalPermissionDenied
+	}
+	return approver, nil
+}
+
+func (r *CertApprovalController) Threshold() int {
+	return r.threshold
+}
+
+func (r *CertApprovalController) Operations() ([]appmodels.PendingOperation, error) {
+	if _, err := r.ExpireOperations(time.Now()); err != nil {
+		return nil, err
+	}
+	return r.repository.FindAll()
+}
+
+func (r *CertApprovalController) Operation(id string) (appmodels.PendingOperation, error) {
+	if _, err := r.ExpireOperations(time.Now()); err != nil {
+		return nil, err
+	}
+	operation, err := r.repository.FindById(id)
+	if err != nil {
+		return nil, fmt.Errorf("[Operation:%s]: %w", id, appmodels.ErrOperationNotFound)
+	}
+	return operation, nil
+}
+
+func (r *CertApprovalController) Approve(id, approver string) (appmodels.PendingOperation, error) {
+	return r.decide(id, approver, true)
+}
+
+func (r *CertApprovalController) Reject(id, approver string) (appmodels.PendingOperation, error) {
+	return r.decide(id, approver, false)
+}
+
+func (r *CertApprovalController) Execute(
+	operationType appmodels.OperationType,
+	organization, certificate *big.Int,
+	details string,
+	requester string,
+	operation func() error,
+) error {
+	if requester == "" {
+		return fmt.Errorf("[Execute:%s]: %w: no requester", operationType, appmodels.ErrApprovalPermissionDenied)
+	}
+	now := time.Now()
+
+	r.mutex.Lock()
+	if _, err := r.expire(now); err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+	list, err := r.repository.FindAll()
+	if err != nil {
+		r.mutex.Unlock()
+		return fmt.Errorf("[Execute:%s]: %w", operationType, err)
+	}
+
+	var approved appmodels.PendingOperation
+	for _, item := range list {
+		if item.RequestedBy() != requester || !operationMatches(item, operationType, organization, certificate, details) {
+			continue
+		}
+		switch item.Status() {
+		case appmodels.OPERATION_STATUS_APPROVED:
+			approved = item
+		case appmodels.OPERATION_STATUS_PENDING, appmodels.OPERATION_STATUS_EXECUTING:
+			r.mutex.Unlock()
+			return appmodels.NewApprovalRequiredError(item)
+		}
+	}
+
+	if approved == nil {
+		defer r.mutex.Unlock()
+		id, err := apputils.GenerateAcmeToken(r.randomManager)
+		if err != nil {
+			return fmt.Errorf("[Execute:%s]: failed to create ID: %w", operationType, err)
+		}
+		pending, err := r.repository.Save(appmodels.NewPendingOperation(
+			id,
+			operationType,
+			organization,
+			certificate,
+			details,
+			requester,
+			appmodels.OPERATION_STATUS_PENDING,
+			now,
+			now.Add(r.expiration),
+			nil,
+			time.Time{},
+		))
+		if err != nil {
+			return fmt.Errorf("[Execute:%s]: failed to save: %w", operationType, err)
+		}
+		log.Printf("[Approval:%s] Requested %s for organization %s by %s: %d approvals required", id, operationType, organization, requester, r.threshold)
+		return appmodels.NewApprovalRequiredError(pending)
+	}
+
+	// The operation runs without the lock, and cannot be started again
+	// while it runs
+	executing, err := r.repository.Save(withOperationStatus(approved, appmodels.OPERATION_STATUS_EXECUTING, approved.Approvals(), time.Time{}))
+	r.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("[Execute:%s]: failed to save: %w", operationType, err)
+	}
+
+	operationErr := operation()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if operationErr != nil {
+		if _, err := r.repository.Save(withOperationStatus(executing, appmodels.OPERATION_STATUS_APPROVED, executing.Approvals(), time.Time{})); err != nil {
+			log.Printf("[Approval:%s] Failed to save: %v", executing.ID(), err)
+		}
+		return operationErr
+	}
+	if _, err := r.repository.Save(withOperationStatus(executing, appmodels.OPERATION_STATUS_EXECUTED, executing.Approvals(), time.Now())); err != nil {
+		log.Printf("[Approval:%s] Failed to save: %v", executing.ID(), err)
+	}
+	log.Printf("[Approval:%s] Executed %s for organization %s by %s", executing.ID(), operationType, organization, requester)
+	return nil
+}
+
+func (r *CertApprovalController) ExpireOperations(now time.Time) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.expire(now)
+}
+
+// decide records the decision of an approver
+func (r *CertApprovalController) decide(id, approver string, approved bool) (appmodels.PendingOperation, error) {
+	now := time.Now()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, err := r.expire(now); err != nil {
+		return nil, err
+	}
+
+	operation, err := r.repository.FindById(id)
+	if err != nil {
+		return nil, fmt.Errorf("[Approve:%s]: %w", id, appmodels.ErrOperationNotFound)
+	}
+	if operation.Status() != appmodels.OPERATION_STATUS_PENDING {
+		return nil, fmt.Errorf("[Approve:%s]: %w: %s", id, appmodels.ErrOperationNotPending, operation.Status())
+	}
+	if operation.RequestedBy() == approver {
+		return nil, fmt.Errorf("[Approve:%s]: %w: %s cannot decide on own request", id, appmodels.ErrOperationRequester, approver)
+	}
+	approvals := operation.Approvals()
+	for _, approval := range approvals {
+		if approval.Approver() == approver {
+			return nil, fmt.Errorf("[Approve:%s]: %w: %s", id, appmodels.ErrOperationAlreadyDecided, approver)
+		}
+	}
+	approvals = append(append([]appmodels.OperationApproval{}, approvals...), appmodels.NewOperationApproval(approver, approved, now))
+
+	status := appmodels.OPERATION_STATUS_PENDING
+	if !approved {
+		status = appmodels.OPERATION_STATUS_REJECTED
+	} else if countApprovals(approvals) >= r.threshold {
+		status = appmodels.OPERATION_STATUS_APPROVED
+	}
+
+	saved, err := r.repository.Save(withOperationStatus(operation, status, approvals, time.Time{}))
+	if err != nil {
+		return nil, fmt.Errorf("[Approve:%s]: failed to save: %w", id, err)
+	}
+	if approved {
+		log.Printf("[Approval:%s] Approved by %s (%d of %d): %s", id, approver, countApprovals(approvals), r.threshold, saved.Status())
+	} else {
+		log.Printf("[Approval:%s] Rejected by %s", id, approver)
+	}
+	return saved, nil
+}
+
+// expire expires the operations which have not been executed in time. The
+// mutex must be locked.
+func (r *CertApprovalController) expire(now time.Time) (int, error) {
+	list, err := r.repository.FindAll()
+	if err != nil {
+		return 0, fmt.Errorf("[ExpireOperations]: %w", err)
+	}
+	count := 0
+	for _, operation := range list {
+		if operation.Status() != appmodels.OPERATION_STATUS_PENDING && operation.Status() != appmodels.OPERATION_STATUS_APPROVED {
+			continue
+		}
+		if now.Before(operation.ExpiresAt()) {
+			continue
+		}
+		if _, err := r.repository.Save(withOperationStatus(operation, appmodels.OPERATION_STATUS_EXPIRED, operation.Approvals(), time.Time{})); err != nil {
+			return count, fmt.Errorf("[ExpireOperations]: failed to save: %w", err)
+		}
+		log.Printf("[Approval:%s] Expired %s", operation.ID(), operation.Type())
+		count++
+	}
+	return count, nil
+}
+
+// operationMatches returns true if the operation has the same parameters
+func operationMatches(
+	operation appmodels.PendingOperation,
+	operationType appmodels.OperationType,
+	organization, certificate *big.Int,
+	details string,
+) bool {
+	if operation.Type() != operationType || operation.Details() != details {
+		return false
+	}
+	if operation.OrganizationID().Cmp(organization) != 0 {
+		return false
+	}
+	if operation.SerialNumber() == nil || certificate == nil {
+		return operation.SerialNumber() == nil && certificate == nil
+	}
+	return operation.SerialNumber().Cmp(certificate) == 0
+}
+
+// withOperationStatus returns a copy of the operation with a new status
+func withOperationStatus(
+	operation appmodels.PendingOperation,
+	status appmodels.OperationStatus,
+	approvals []appmodels.OperationApproval,
+	executedAt time.Time,
+) appmodels.PendingOperation {
+	return appmodels.NewPendingOperation(
+		operation.ID(),
+		operation.Type(),
+		operation.OrganizationID(),
+		operation.SerialNumber(),
+		operation.Details(),
+		operation.RequestedBy(),
+		status,
+		operation.CreatedAt(),
+		operation.ExpiresAt(),
+		approvals,
+		executedAt,
+	)
+}
+
+// countApprovals returns the number of approving decisions
+func countApprovals(approvals []appmodels.OperationApproval) int {
+	count := 0
+	for _, approval := range approvals {
+		if approval.Approved() {
+			count++
+		}
+	}
+	return count
+}
+
+// certificateOptions are the options recorded for the details of an
+// operation which creates a certificate
+type certificateOptions struct {
+	expiration         time.Duration
+	keyType            appmodels.KeyType
+	signatureAlgorithm appmodels.SignatureAlgorithm
+}
+
+// details returns the parameters of a new certificate for an operation
+func (o certificateOptions) details(commonName string) string {
+	list := []string{fmt.Sprintf("commonName=%q", commonName)}
+	if o.keyType != appmodels.NIL_KEY_TYPE {
+		list = append(list, "keyType="+o.keyType.String())
+	}
+	if o.signatureAlgorithm != appmodels.NIL_SIGNATURE_ALGORITHM {
+		list = append(list, "signatureAlgorithm="+o.signatureAlgorithm.String())
+	}
+	if o.expiration != 0 {
+		list = append(list, "expiration="+o.expiration.String())
+	}
+	return strings.Join(list, " ")
+}
+
+// ApprovalApplicationController implements appmodels.ApplicationController
+// by requiring approvals for the sensitive operations of the organization
+// and certificate controllers it returns
+type ApprovalApplicationController struct {
+	appmodels.ApplicationController
+	approvalController appmodels.ApprovalController
+
+	// requester is the name of the approver the operations are requested
+	// by. Sensitive operations are denied without a requester.
+	requester string
+}
+
+func (r *ApprovalApplicationController) WithRequester(requester string) appmodels.ApplicationController {
+	return &ApprovalApplicationController{
+		ApplicationController: r.ApplicationController,
+		approvalController:    r.approvalController,
+		requester:             requester,
+	}
+}
+
+func (r *ApprovalApplicationController) OrganizationController(organization *big.Int) (appmodels.OrganizationController, error) {
+	controller, err := r.ApplicationController.OrganizationController(organization)
+	if err != nil {
+		return nil, err
+	}
+	return &approvalOrganizationController{
+		OrganizationController: controller,
+		parent:                 r,
+		approvalController:     r.approvalController,
+		requester:              r.requester,
+	}, nil
+}
+
+// NewApprovalApplicationController wraps an application controller to
+// require approvals for creating and revoking root certificates and for
+// creating intermediate certificates
+//   - controller: The application controller
+//   - approvalController: The approval controller
+func NewApprovalApplicationController(
+	controller appmodels.ApplicationController,
+	approvalController appmodels.ApprovalController,
+) *ApprovalApplicationController {
+	return &ApprovalApplicationController{
+		ApplicationController: controller,
+		approvalController:    approvalController,
+	}
+}
+
+var _ appmodels.RequesterApplicationController = (*ApprovalApplicationController)(nil)
+
+// approvalOrganizationController requires approvals for creating and
+// revoking root certificates
+type approvalOrganizationController struct {
+	appmodels.OrganizationController
+	parent             appmodels.ApplicationController
+	approvalController appmodels.ApprovalController
+	requester          string
+	options            certificateOptions
+}
+
+func (r *approvalOrganizationController) ApplicationController() appmodels.ApplicationController {
+	return r.parent
+}
+
+func (r *approvalOrganizationController) CertificateController(serialNumber *big.Int) (appmodels.CertificateController, error) {
+	controller, err := r.OrganizationController.CertificateController(serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	return r.wrap(controller), nil
+}
+
+func (r *approvalOrganizationController) SetExpirationDuration(expiration time.Duration) {
+	r.options.expiration = expiration
+	r.OrganizationController.SetExpirationDuration(expiration)
+}
+
+func (r *approvalOrganizationController) SetKeyType(keyType appmodels.KeyType) {
+	r.options.keyType = keyType
+	r.OrganizationController.SetKeyType(keyType)
+}
+
+func (r *approvalOrganizationController) SetSignatureAlgorithm(signatureAlgorithm appmodels.SignatureAlgorithm) {
+	r.options.signatureAlgorithm = signatureAlgorithm
+	r.OrganizationController.SetSignatureAlgorithm(signatureAlgorithm)
+}
+
+func (r *approvalOrganizationController) NewRootCertificate(commonName string) (appmodels.Certificate, error) {
+	var cert appmodels.Certificate
+	err := r.approvalController.Execute(
+		appmodels.OPERATION_CREATE_ROOT_CERTIFICATE,
+		r.OrganizationID(),
+		nil,
+		r.options.details(commonName),
+		r.requester,
+		func() error {
+			var err error
+			cert, err = r.OrganizationController.NewRootCertificate(commonName)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+func (r *approvalOrganizationController) RevokeCertificate(certificate appmodels.Certificate) (appmodels.RevokedCertificate, error) {
+	if !certificate.IsRootCertificate() {
+		return r.OrganizationController.RevokeCertificate(certificate)
+	}
+	var revoked appmodels.RevokedCertificate
+	err := r.approvalController.Execute(
+		appmodels.OPERATION_REVOKE_ROOT_CERTIFICATE,
+		r.OrganizationID(),
+		certificate.SerialNumber(),
+		"",
+		r.requester,
+		func() error {
+			var err error
+			revoked, err = r.OrganizationController.RevokeCertificate(certificate)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+// wrap returns a certificate controller which requires approvals
+func (r *approvalOrganizationController) wrap(controller appmodels.CertificateController) appmodels.CertificateController {
+	if controller == nil {
+		return nil
+	}
+	return &approvalCertificateController{
+		CertificateController: controller,
+		parent:                r,
+	}
+}
+
+var _ appmodels.OrganizationController = (*approvalOrganizationController)(nil)
+
+// approvalCertificateController requires approvals for creating
+// intermediate certificates
+type approvalCertificateController struct {
+	appmodels.CertificateController
+	parent  *approvalOrganizationController
+	options certificateOptions
+}
+
+func (r *approvalCertificateController) ApplicationController() appmodels.ApplicationController {
+	return r.parent.ApplicationController()
+}
+
+func (r *approvalCertificateController) OrganizationController() appmodels.OrganizationController {
+	return r.parent
+}
+
+func (r *approvalCertificateController) ChildCertificateController(serialNumber *big.Int) (appmodels.CertificateController, error) {
+	controller, err := r.CertificateController.ChildCertificateController(serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	return r.parent.wrap(controller), nil
+}
+
+func (r *approvalCertificateController) ParentCertificateController() appmodels.CertificateController {
+	return r.parent.wrap(r.CertificateController.ParentCertificateController())
+}
+
+func (r *approvalCertificateController) SetExpirationDuration(expiration time.Duration) {
+	r.options.expiration = expiration
+	r.CertificateController.SetExpirationDuration(expiration)
+}
+
+func (r *approvalCertificateController) SetKeyType(keyType appmodels.KeyType) {
+	r.options.keyType = keyType
+	r.CertificateController.SetKeyType(keyType)
+}
+
+func (r *approvalCertificateController) SetSignatureAlgorithm(signatureAlgorithm appmodels.SignatureAlgorithm) {
+	r.options.signatureAlgorithm = signatureAlgorithm
+	r.CertificateController.SetSignatureAlgorithm(signatureAlgorithm)
+}
+
+func (r *approvalCertificateController) NewIntermediateCertificate(commonName string) (appmodels.Certificate, appmodels.PrivateKey, error) {
+	var cert appmodels.Certificate
+	var privateKey appmodels.PrivateKey
+	err := r.parent.approvalController.Execute(
+		appmodels.OPERATION_CREATE_INTERMEDIATE_CERTIFICATE,
+		r.OrganizationID(),
+		r.Certificate().SerialNumber(),
+		r.options.details(commonName),
+		r.parent.requester,
+		func() error {
+			var err error
+			cert, privateKey, err = r.CertificateController.NewIntermediateCertificate(commonName)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, privateKey, nil
+}
+
+var _ appmodels.CertificateController = (*approvalCertificateController)(nil)
+
+// ApprovalBackupController implements appmodels.BackupController by
+// requiring approvals for exporting private keys of CA certificates, which
+// backups and key shares contain, and for replacing them by restoring
+// backups and importing key shares
+type ApprovalBackupController struct {
+	appmodels.BackupController
+	approvalController appmodels.ApprovalController
+
+	// requester is the name of the approver the operations are requested
+	// by. Sensitive operations are denied without a requester.
+	requester string
+}
+
+func (r *ApprovalBackupController) WithRequester(requester string) appmodels.BackupController {
+	return &ApprovalBackupController{
+		BackupController:   r.BackupController,
+		approvalController: r.approvalController,
+		requester:          requester,
+	}
+}
+
+func (r *ApprovalBackupController) Backup(organization *big.Int, passphrase string) ([]byte, error) {
+	var data []byte
+	err := r.approvalController.Execute(
+		appmodels.OPERATION_BACKUP_ORGANIZATION,
+		organization,
+		nil,
+		"",
+		r.requester,
+		func() error {
+			var err error
+			data, err = r.BackupController.Backup(organization, passphrase)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (r *ApprovalBackupController) ExportKeyShares(organization, certificate *big.Int, shares, threshold int) ([]appmodels.PrivateKeyShare, error) {
+	var list []appmodels.PrivateKeyShare
+	err := r.approvalController.Execute(
+		appmodels.OPERATION_EXPORT_PRIVATE_KEY,
+		organization,
+		certificate,
+		fmt.Sprintf("shares=%d threshold=%d", shares, threshold),
+		r.requester,
+		func() error {
+			var err error
+			list, err = r.BackupController.ExportKeyShares(organization, certificate, shares, threshold)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Restore verifies the archive before it requires approvals, so that the
+// operation names the organization and the checksum of the archive
+func (r *ApprovalBackupController) Restore(archive []byte, passphrase string, force bool) (appmodels.OrganizationBackup, error) {
+	backup, err := r.BackupController.Verify(archive, passphrase, force)
+	if err != nil {
+		return nil, err
+	}
+	var restored appmodels.OrganizationBackup
+	err = r.approvalController.Execute(
+		appmodels.OPERATION_RESTORE_ORGANIZATION,
+		backup.Organization().ID(),
+		nil,
+		fmt.Sprintf("sha256=%x force=%t", sha256.Sum256(archive), force),
+		r.requester,
+		func() error {
+			var err error
+			restored, err = r.BackupController.Restore(archive, passphrase, force)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+func (r *ApprovalBackupController) ImportKeyShares(organization, certificate *big.Int, shares []appmodels.PrivateKeyShare) (appmodels.PrivateKey, error) {
+	details := ""
+	if len(shares) != 0 {
+		details = "split=" + shares[0].SplitID()
+	}
+	var privateKey appmodels.PrivateKey
+	err := r.approvalController.Execute(
+		appmodels.OPERATION_IMPORT_PRIVATE_KEY,
+		organization,
+		certificate,
+		details,
+		r.requester,
+		func() error {
+			var err error
+			privateKey, err = r.BackupController.ImportKeyShares(organization, certificate, shares)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return privateKey, nil
+}
+
+// NewApprovalBackupController wraps a backup controller to require
+// approvals for backups, restores and key share exports and imports
+//   - controller: The backup controller
+//   - approvalController: The approval controller
+func NewApprovalBackupController(
+	controller appmodels.BackupController,
+	approvalController appmodels.ApprovalController,
+) *ApprovalBackupController {
+	return &ApprovalBackupController{
+		BackupController:   controller,
+		approvalController: approvalController,
+	}
+}
+
+var _ appmodels.RequesterBackupController = (*ApprovalBackupController)(nil)
+
+// NewApprovalController creates an approval controller
+//   - repository: The repository of the operations
+//   - approvers: The names of the approvers by their tokens
+//   - threshold: The number of approvals needed, from 1 to the number of
+//     approvers other than the requester
+//   - expiration: The lifetime of an operation
+//   - randomManager: The random manager for operation IDs
+func NewApprovalController(
+	repository appmodels.PendingOperationRepository,
+	approvers map[string]string,
+	threshold int,
+	expiration time.Duration,
+	randomManager managers.RandomManager,
+) (*CertApprovalController, error) {
+	if len(approvers) < 2 {
+		return nil, fmt.Errorf("NewApprovalController: at least two approvers are needed")
+	}
+	if threshold < 1 || threshold > len(approvers)-1 {
+		return nil, fmt.Errorf("NewApprovalController: threshold must be from 1 to %d: %d", len(approvers)-1, threshold)
+	}
+	names := make(map[string]bool, len(approvers))
+	for token, name := range approvers {
+		if token == "" || name == "" {
+			return nil, fmt.Errorf("NewApprovalController: approver names and tokens must be defined")
+		}
+		if names[name] {
+			return nil, fmt.Errorf("NewApprovalController: approver defined twice: %s", name)
+		}
+		names[name] = true
+	}
+	if expiration <= 0 {
+		expiration = DefaultApprovalExpiration
+	}
+	return &CertApprovalController{
+		repository:    repository,
+		randomManager: randomManager,
+		approvers:     approvers,
+		threshold:     threshold,
+		expiration:    expiration,
+	}, nil
+}
+
+var _ appmodels.ApprovalController = (*CertApprovalController)(nil)
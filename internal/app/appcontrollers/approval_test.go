@@ -0,0 +1,326 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestApprovalController(t *testing.T, threshold int, expiration time.Duration) (*appcontrollers.CertApprovalController, *memoryrepository.MemoryPendingOperationRepository) {
+	repository := memoryrepository.NewPendingOperationRepository()
+	controller, err := appcontrollers.NewApprovalController(
+		repository,
+		map[string]string{"alice-token": "alice", "bob-token": "bob", "carol-token": "carol"},
+		threshold,
+		expiration,
+		managers.NewRandomManager(),
+	)
+	require.NoError(t, err)
+	return controller, repository
+}
+
+func requireApprovalRequired(t *testing.T, err error) appmodels.PendingOperation {
+	var approvalErr *appmodels.ApprovalRequiredError
+	require.ErrorAs(t, err, &approvalErr)
+	return approvalErr.Operation()
+}
+
+func TestNewApprovalController(t *testing.T) {
+	repository := memoryrepository.NewPendingOperationRepository()
+	randomManager := managers.NewRandomManager()
+	approvers := map[string]string{"a": "alice", "b": "bob"}
+
+	_, err := appcontrollers.NewApprovalController(repository, nil, 1, time.Hour, randomManager)
+	assert.Error(t, err)
+	_, err = appcontrollers.NewApprovalController(repository, map[string]string{"a": "alice"}, 1, time.Hour, randomManager)
+	assert.Error(t, err)
+	_, err = appcontrollers.NewApprovalController(repository, approvers, 0, time.Hour, randomManager)
+	assert.Error(t, err)
+
+	// The requester cannot approve, so one of the approvers cannot be
+	// counted
+	_, err = appcontrollers.NewApprovalController(repository, approvers, 2, time.Hour, randomManager)
+	assert.Error(t, err)
+	_, err = appcontrollers.NewApprovalController(repository, map[string]string{"a": "alice", "b": "alice"}, 1, time.Hour, randomManager)
+	assert.ErrorContains(t, err, "defined twice")
+
+	controller, err := appcontrollers.NewApprovalController(repository, approvers, 1, 0, randomManager)
+	require.NoError(t, err)
+	assert.Equal(t, 1, controller.Threshold())
+}
+
+func TestCertApprovalController_Authenticate(t *testing.T) {
+	controller, _ := newTestApprovalController(t, 2, time.Hour)
+
+	approver, err := controller.Authenticate("bob-token")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", approver)
+
+	_, err = controller.Authenticate("wrong")
+	assert.ErrorIs(t, err, appmodels.ErrApprovalPermissionDenied)
+	_, err = controller.Authenticate("")
+	assert.ErrorIs(t, err, appmodels.ErrApprovalPermissionDenied)
+}
+
+func TestCertApprovalController_Execute(t *testing.T) {
+	controller, _ := newTestApprovalController(t, 2, time.Hour)
+	organization := big.NewInt(1)
+	runs := 0
+	run := func() error {
+		runs++
+		return nil
+	}
+
+	// The first request creates a pending operation
+	err := controller.Execute(appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, organization, nil, "commonName=Root", "carol", run)
+	pending := requireApprovalRequired(t, err)
+	assert.Equal(t, appmodels.OPERATION_STATUS_PENDING, pending.Status())
+	assert.Equal(t, "carol", pending.RequestedBy())
+	assert.Equal(t, 0, runs)
+
+	// Operations without a requester are denied
+	err = controller.Execute(appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, organization, nil, "commonName=Root", "", run)
+	assert.ErrorIs(t, err, appmodels.ErrApprovalPermissionDenied)
+
+	// The requester cannot approve the own request
+	_, err = controller.Approve(pending.ID(), "carol")
+	assert.ErrorIs(t, err, appmodels.ErrOperationRequester)
+
+	// Repeating the request does not create another operation
+	err = controller.Execute(appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, organization, nil, "commonName=Root", "carol", run)
+	assert.Equal(t, pending.ID(), requireApprovalRequired(t, err).ID())
+
+	// One approval is not enough
+	operation, err := controller.Approve(pending.ID(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.OPERATION_STATUS_PENDING, operation.Status())
+	_, err = controller.Approve(pending.ID(), "alice")
+	assert.ErrorIs(t, err, appmodels.ErrOperationAlreadyDecided)
+	err = controller.Execute(appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, organization, nil, "commonName=Root", "carol", run)
+	requireApprovalRequired(t, err)
+	assert.Equal(t, 0, runs)
+
+	operation, err = controller.Approve(pending.ID(), "bob")
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.OPERATION_STATUS_APPROVED, operation.Status())
+
+	// Another approver cannot execute the approved operation
+	err = controller.Execute(appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, organization, nil, "commonName=Root", "alice", run)
+	assert.NotEqual(t, pending.ID(), requireApprovalRequired(t, err).ID())
+	assert.Equal(t, 0, runs)
+
+	// Different parameters are not approved
+	err = controller.Execute(appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, organization, nil, "commonName=Other", "carol", run)
+	assert.NotEqual(t, pending.ID(), requireApprovalRequired(t, err).ID())
+
+	// A failed operation can be retried
+	failure := errors.New("failure")
+	err = controller.Execute(appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, organization, nil, "commonName=Root", "carol", func() error {
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	require.NoError(t, controller.Execute(appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, organization, nil, "commonName=Root", "carol", run))
+	assert.Equal(t, 1, runs)
+
+	// The approval is used only once
+	err = controller.Execute(appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, organization, nil, "commonName=Root", "carol", run)
+	assert.NotEqual(t, pending.ID(), requireApprovalRequired(t, err).ID())
+	assert.Equal(t, 1, runs)
+
+	operation, err = controller.Operation(pending.ID())
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.OPERATION_STATUS_EXECUTED, operation.Status())
+	assert.False(t, operation.ExecutedAt().IsZero())
+	require.Len(t, operation.Approvals(), 2)
+	assert.Equal(t, "alice", operation.Approvals()[0].Approver())
+	assert.Equal(t, "bob", operation.Approvals()[1].Approver())
+
+	_, err = controller.Approve(pending.ID(), "alice")
+	assert.ErrorIs(t, err, appmodels.ErrOperationNotPending)
+	_, err = controller.Operation("unknown")
+	assert.ErrorIs(t, err, appmodels.ErrOperationNotFound)
+}
+
+func TestCertApprovalController_Reject(t *testing.T) {
+	controller, _ := newTestApprovalController(t, 2, time.Hour)
+
+	err := controller.Execute(appmodels.OPERATION_BACKUP_ORGANIZATION, big.NewInt(1), nil, "", "carol", func() error { return nil })
+	pending := requireApprovalRequired(t, err)
+
+	_, err = controller.Approve(pending.ID(), "alice")
+	require.NoError(t, err)
+	operation, err := controller.Reject(pending.ID(), "bob")
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.OPERATION_STATUS_REJECTED, operation.Status())
+	require.Len(t, operation.Approvals(), 2)
+	assert.False(t, operation.Approvals()[1].Approved())
+
+	_, err = controller.Approve(pending.ID(), "bob")
+	assert.ErrorIs(t, err, appmodels.ErrOperationNotPending)
+}
+
+func TestCertApprovalController_ExpireOperations(t *testing.T) {
+	controller, _ := newTestApprovalController(t, 1, time.Hour)
+
+	err := controller.Execute(appmodels.OPERATION_BACKUP_ORGANIZATION, big.NewInt(1), nil, "", "carol", func() error { return nil })
+	pending := requireApprovalRequired(t, err)
+
+	count, err := controller.ExpireOperations(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	count, err = controller.ExpireOperations(time.Now().Add(2 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	operation, err := controller.Operation(pending.ID())
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.OPERATION_STATUS_EXPIRED, operation.Status())
+	_, err = controller.Approve(pending.ID(), "alice")
+	assert.ErrorIs(t, err, appmodels.ErrOperationNotPending)
+
+	list, err := controller.Operations()
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestApprovalApplicationController(t *testing.T) {
+	env := newTestBackupEnvironment()
+	approvalController, _ := newTestApprovalController(t, 2, time.Hour)
+	approvalAppController := appcontrollers.NewApprovalApplicationController(env.appController, approvalController)
+	organization := env.newOrganization(t).OrganizationID()
+
+	// Sensitive operations are denied without a requester
+	organizationController, err := approvalAppController.OrganizationController(organization)
+	require.NoError(t, err)
+	_, err = organizationController.NewRootCertificate("Test Root")
+	assert.ErrorIs(t, err, appmodels.ErrApprovalPermissionDenied)
+
+	appController := approvalAppController.WithRequester("carol")
+
+	organizationController, err = appController.OrganizationController(organization)
+	require.NoError(t, err)
+	assert.Equal(t, appController, organizationController.ApplicationController())
+
+	// The root certificate is created after the approvals
+	organizationController.SetKeyType(appmodels.ECDSA_P256)
+	_, err = organizationController.NewRootCertificate("Test Root")
+	pending := requireApprovalRequired(t, err)
+	assert.Equal(t, appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, pending.Type())
+	assert.Equal(t, `commonName="Test Root" keyType=ECDSA_P256`, pending.Details())
+	assert.Equal(t, "carol", pending.RequestedBy())
+	_, err = approvalController.Approve(pending.ID(), "alice")
+	require.NoError(t, err)
+	_, err = approvalController.Approve(pending.ID(), "bob")
+	require.NoError(t, err)
+
+	// The approval does not cover another key type
+	organizationController, err = appController.OrganizationController(organization)
+	require.NoError(t, err)
+	_, err = organizationController.NewRootCertificate("Test Root")
+	requireApprovalRequired(t, err)
+
+	organizationController.SetKeyType(appmodels.ECDSA_P256)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+
+	// One approver cannot create an intermediate certificate
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	assert.Equal(t, organizationController, rootController.OrganizationController())
+	_, _, err = rootController.NewIntermediateCertificate("Test Intermediate")
+	pending = requireApprovalRequired(t, err)
+	assert.Equal(t, appmodels.OPERATION_CREATE_INTERMEDIATE_CERTIFICATE, pending.Type())
+	assert.Equal(t, root.SerialNumber(), pending.SerialNumber())
+	_, err = approvalController.Approve(pending.ID(), "alice")
+	require.NoError(t, err)
+	_, _, err = rootController.NewIntermediateCertificate("Test Intermediate")
+	requireApprovalRequired(t, err)
+
+	_, err = approvalController.Approve(pending.ID(), "bob")
+	require.NoError(t, err)
+	intermediate, _, err := rootController.NewIntermediateCertificate("Test Intermediate")
+	require.NoError(t, err)
+	assert.True(t, intermediate.IsIntermediateCertificate())
+
+	// Leaf certificates do not need approvals
+	intermediateController, err := rootController.ChildCertificateController(intermediate.SerialNumber())
+	require.NoError(t, err)
+	_, _, err = intermediateController.NewServerCertificate("example.com")
+	require.NoError(t, err)
+
+	// Intermediates of intermediates need approvals too
+	_, _, err = intermediateController.NewIntermediateCertificate("Test Sub")
+	requireApprovalRequired(t, err)
+}
+
+func TestApprovalBackupController(t *testing.T) {
+	env, organizationController := newTestBackupSource(t)
+	approvalController, _ := newTestApprovalController(t, 1, time.Hour)
+	approvalBackupController := appcontrollers.NewApprovalBackupController(env.controller, approvalController)
+	organization := organizationController.OrganizationID()
+	root := findTestRootCertificate(t, env.repository, organization)
+
+	_, err := approvalBackupController.Backup(organization, "passphrase")
+	assert.ErrorIs(t, err, appmodels.ErrApprovalPermissionDenied)
+
+	controller := approvalBackupController.WithRequester("carol")
+	_, err = controller.Backup(organization, "passphrase")
+	pending := requireApprovalRequired(t, err)
+	assert.Equal(t, appmodels.OPERATION_BACKUP_ORGANIZATION, pending.Type())
+	_, err = approvalController.Approve(pending.ID(), "alice")
+	require.NoError(t, err)
+	data, err := controller.Backup(organization, "passphrase")
+	require.NoError(t, err)
+	assert.NotEmpty(t, data)
+
+	_, err = controller.ExportKeyShares(organization, root.SerialNumber(), 3, 2)
+	pending = requireApprovalRequired(t, err)
+	assert.Equal(t, appmodels.OPERATION_EXPORT_PRIVATE_KEY, pending.Type())
+	assert.Equal(t, "shares=3 threshold=2", pending.Details())
+	_, err = approvalController.Approve(pending.ID(), "alice")
+	require.NoError(t, err)
+	shares, err := controller.ExportKeyShares(organization, root.SerialNumber(), 3, 2)
+	require.NoError(t, err)
+	assert.Len(t, shares, 3)
+
+	// Importing replaces the private key
+	_, err = controller.ImportKeyShares(organization, root.SerialNumber(), shares[:2])
+	pending = requireApprovalRequired(t, err)
+	assert.Equal(t, appmodels.OPERATION_IMPORT_PRIVATE_KEY, pending.Type())
+	assert.Equal(t, "split="+shares[0].SplitID(), pending.Details())
+	_, err = approvalController.Approve(pending.ID(), "alice")
+	require.NoError(t, err)
+	_, err = controller.ImportKeyShares(organization, root.SerialNumber(), shares[1:])
+	require.NoError(t, err)
+
+	// Restoring replaces the organization
+	_, err = controller.Restore(data, "passphrase", false)
+	pending = requireApprovalRequired(t, err)
+	assert.Equal(t, appmodels.OPERATION_RESTORE_ORGANIZATION, pending.Type())
+	assert.Equal(t, organization, pending.OrganizationID())
+	assert.Contains(t, pending.Details(), "force=false")
+	_, err = approvalController.Approve(pending.ID(), "alice")
+	require.NoError(t, err)
+	backup, err := controller.Restore(data, "passphrase", false)
+	require.NoError(t, err)
+	assert.Equal(t, organization, backup.Organization().ID())
+
+	// An archive which cannot be restored is not requested
+	_, err = controller.Restore(data, "wrong", false)
+	assert.Error(t, err)
+	var approvalErr *appmodels.ApprovalRequiredError
+	assert.False(t, errors.As(err, &approvalErr))
+}
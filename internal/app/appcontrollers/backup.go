@@ -0,0 +1,525 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+	"github.com/hyperifyio/gocertcenter/internal/common/shamirutils"
+)
+
+// CertBackupController implements appmodels.BackupController. Errors which
+// should be reported to the client are returned as *appmodels.BackupError.
+type CertBackupController struct {
+	organizationRepository appmodels.OrganizationRepository
+	certificateRepository  appmodels.CertificateRepository
+	privateKeyRepository   appmodels.PrivateKeyRepository
+	unitOfWorkRepository   appmodels.UnitOfWorkRepository
+
+	// revocationRepository is optional. Backups have no revocations without
+	// it.
+	revocationRepository appmodels.CertificateRevocationRepository
+
+	// protocolState is optional. It keeps the state of the EST, SCEP, Vault
+	// and ACME servers, which backups do not have without it.
+	protocolState *appmodels.Collection
+
+	certManager managers.CertificateManager
+
+	// trustAnchors are root certificates trusted to sign backups of any
+	// organization, e.g. to restore an organization which is not stored
+	trustAnchors []*x509.Certificate
+
+	// token is the required backup token, or empty to accept any client
+	token string
+}
+
+func (r *CertBackupController) Authenticate(token string) error {
+	if r.token == "" {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(r.token), []byte(token)) != 1 {
+		return appmodels.NewBackupError(appmodels.BACKUP_ERROR_PERMISSION_DENIED, "permission denied")
+	}
+	return nil
+}
+
+func (r *CertBackupController) Backup(organization *big.Int, passphrase string) ([]byte, error) {
+
+	if passphrase == "" {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_BAD_REQUEST, "passphrase must be defined")
+	}
+
+	model, err := r.organizationRepository.FindById(organization)
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_NOT_FOUND, "organization not found: %s", organization)
+	}
+
+	certificates, err := r.certificateRepository.FindAllByOrganization(organization)
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:Backup]: certificates: %v", organization, err)
+	}
+
+	// Private keys which are not stored are not included
+	var privateKeys []appmodels.PrivateKey
+	keys := make(map[string]appmodels.PrivateKey)
+	for _, certificate := range certificates {
+		key, err := r.privateKeyRepository.FindByOrganizationAndSerialNumber(organization, certificate.SerialNumber())
+		if err == nil {
+			privateKeys = append(privateKeys, key)
+			keys[certificate.SerialNumber().String()] = key
+		}
+	}
+
+	revocations, err := r.revocations(organization, certificates)
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:Backup]: revocations: %v", organization, err)
+	}
+
+	revocationLists := r.revocationLists(organization, certificates)
+
+	estLabels, scepChallenge, vaultRoles, err := r.protocols(organization)
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:Backup]: %v", organization, err)
+	}
+	acmeAccounts, acmeOrders, acmeAuthorizations, err := r.acme(organization)
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:Backup]: ACME: %v", organization, err)
+	}
+
+	now := time.Now()
+	var signer appmodels.Certificate
+	for _, root := range apputils.FilterValidRootCertificates(certificates, now) {
+		if keys[root.SerialNumber().String()] != nil && (signer == nil || root.NotBefore().After(signer.NotBefore())) {
+			signer = root
+		}
+	}
+	if signer == nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_BAD_REQUEST, "organization has no valid root certificate to sign the backup")
+	}
+
+	backup := appmodels.NewOrganizationBackup(
+		apputils.BackupArchiveVersion,
+		now,
+		model,
+		certificates,
+		privateKeys,
+		revocations,
+		revocationLists,
+		estLabels,
+		scepChallenge,
+		vaultRoles,
+		acmeAccounts,
+		acmeOrders,
+		acmeAuthorizations,
+	)
+	archive, err := apputils.WriteBackupArchive(r.certManager, backup, signer, keys[signer.SerialNumber().String()], passphrase)
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:Backup]: %v", organization, err)
+	}
+	log.Printf("[%s:Backup]: %d certificates, %d private keys and %d revocations exported", organization, len(certificates), len(privateKeys), len(revocations))
+	return archive, nil
+}
+
+func (r *CertBackupController) Verify(archive []byte, passphrase string, force bool) (appmodels.OrganizationBackup, error) {
+
+	backup, err := apputils.ReadBackupArchive(r.certManager, archive, passphrase, r.backupTrustAnchors)
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_BAD_REQUEST, "invalid backup: %v", err)
+	}
+
+	if force {
+		return backup, nil
+	}
+
+	newer, err := r.newerItems(backup)
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:Verify]: %v", backup.Organization().ID(), err)
+	}
+	if newer > 0 {
+		return nil, appmodels.NewBackupError(
+			appmodels.BACKUP_ERROR_CONFLICT,
+			"organization %s has %d certificates or revocations which are newer than the backup from %s",
+			backup.Organization().ID(),
+			newer,
+			backup.CreatedAt().UTC().Format(time.RFC3339),
+		)
+	}
+	return backup, nil
+}
+
+func (r *CertBackupController) Restore(archive []byte, passphrase string, force bool) (appmodels.OrganizationBackup, error) {
+
+	backup, err := r.Verify(archive, passphrase, force)
+	if err != nil {
+		return nil, err
+	}
+	organization := backup.Organization().ID()
+
+	if _, err := r.organizationRepository.Save(backup.Organization()); err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:Restore]: organization: %v", organization, err)
+	}
+
+	work := r.unitOfWorkRepository.NewUnitOfWork()
+	for _, key := range backup.PrivateKeys() {
+		work.SavePrivateKey(key)
+	}
+	for _, certificate := range backup.Certificates() {
+		work.SaveCertificate(certificate)
+	}
+	if err := work.Commit(); err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:Restore]: certificates: %v", organization, err)
+	}
+
+	if r.revocationRepository != nil {
+		for _, revocation := range backup.Revocations() {
+			if _, err := r.revocationRepository.Save(revocation); err != nil {
+				return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:Restore]: revocations: %v", organization, err)
+			}
+		}
+		for _, revocationList := range backup.RevocationLists() {
+			// A stored CRL number is kept if it is higher, since CRL numbers
+			// must not decrease
+			stored, err := r.revocationRepository.FindRevocationList(organization, revocationList.IssuerSerialNumber())
+			if err == nil && stored.Number().Cmp(revocationList.Number()) >= 0 {
+				continue
+			}
+			if _, err := r.revocationRepository.SaveRevocationList(revocationList); err != nil {
+				return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:Restore]: revocation lists: %v", organization, err)
+			}
+		}
+	}
+
+	if err := r.restoreProtocols(backup); err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:Restore]: %v", organization, err)
+	}
+
+	log.Printf("[%s:Restore]: %d certificates, %d private keys and %d revocations restored from a backup created at %s", organization, len(backup.Certificates()), len(backup.PrivateKeys()), len(backup.Revocations()), backup.CreatedAt().UTC().Format(time.RFC3339))
+	return backup, nil
+}
+
+func (r *CertBackupController) ExportKeyShares(organization, certificate *big.Int, shares, threshold int) ([]appmodels.PrivateKeyShare, error) {
+
+	if threshold < 2 || shares < threshold || shares > shamirutils.MaxShares {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_BAD_REQUEST, "threshold must be at least 2 and shares from threshold to %d", shamirutils.MaxShares)
+	}
+
+	model, err := r.keyShareCertificate(organization, certificate)
+	if err != nil {
+		return nil, err
+	}
+	if !model.IsCA() {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_BAD_REQUEST, "only private keys of root and intermediate certificates can be exported")
+	}
+
+	key, err := r.privateKeyRepository.FindByOrganizationAndSerialNumber(organization, certificate)
+	if errors.Is(err, appmodels.ErrSealed) {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SEALED, "private keys are sealed")
+	}
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_NOT_FOUND, "private key not found: %s", certificate)
+	}
+
+	result, err := apputils.SplitPrivateKey(r.certManager, key, shares, threshold)
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:ExportKeyShares]: %s: %v", organization, certificate, err)
+	}
+	log.Printf("[%s:ExportKeyShares]: Private key of %s exported as %d shares with threshold %d", organization, certificate, shares, threshold)
+	return result, nil
+}
+
+func (r *CertBackupController) ImportKeyShares(organization, certificate *big.Int, shares []appmodels.PrivateKeyShare) (appmodels.PrivateKey, error) {
+
+	model, err := r.keyShareCertificate(organization, certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := apputils.CombinePrivateKeyShares(r.certManager, model, shares)
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_BAD_REQUEST, "failed to reconstruct the private key: %v", err)
+	}
+
+	if _, err := r.privateKeyRepository.Save(key); errors.Is(err, appmodels.ErrSealed) {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SEALED, "private keys are sealed")
+	} else if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "[%s:ImportKeyShares]: %s: %v", organization, certificate, err)
+	}
+	log.Printf("[%s:ImportKeyShares]: Private key of %s reconstructed from %d shares", organization, certificate, len(shares))
+	return key, nil
+}
+
+// keyShareCertificate returns the certificate of an exported or imported
+// private key
+func (r *CertBackupController) keyShareCertificate(organization, certificate *big.Int) (appmodels.Certificate, error) {
+	if _, err := r.organizationRepository.FindById(organization); err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_NOT_FOUND, "organization not found: %s", organization)
+	}
+	model, err := r.certificateRepository.FindByOrganizationAndSerialNumber(organization, certificate)
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_NOT_FOUND, "certificate not found: %s", certificate)
+	}
+	return model, nil
+}
+
+// backupTrustAnchors returns the configured trust anchors and the stored
+// root certificates of the organization. An archive signed by a root which
+// is only in the archive itself is not trusted.
+func (r *CertBackupController) backupTrustAnchors(organization *big.Int) ([]*x509.Certificate, error) {
+	anchors := append([]*x509.Certificate(nil), r.trustAnchors...)
+	if _, err := r.organizationRepository.FindById(organization); err != nil {
+		return anchors, nil
+	}
+	certificates, err := r.certificateRepository.FindAllByOrganization(organization)
+	if err != nil {
+		return nil, err
+	}
+	for _, certificate := range certificates {
+		if certificate.IsRootCertificate() {
+			anchors = append(anchors, certificate.Certificate())
+		}
+	}
+	return anchors, nil
+}
+
+// revocations returns the revocations of certificates issued by the CA
+// certificates
+func (r *CertBackupController) revocations(organization *big.Int, certificates []appmodels.Certificate) ([]appmodels.CertificateRevocation, error) {
+	var list []appmodels.CertificateRevocation
+	if r.revocationRepository == nil {
+		return list, nil
+	}
+	for _, certificate := range certificates {
+		if !certificate.IsCA() {
+			continue
+		}
+		revocations, err := r.revocationRepository.FindAllByOrganizationAndIssuer(organization, certificate.SerialNumber())
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, revocations...)
+	}
+	return list, nil
+}
+
+// revocationLists returns the last CRL of each CA certificate. CA
+// certificates which have not issued a CRL are not included.
+func (r *CertBackupController) revocationLists(organization *big.Int, certificates []appmodels.Certificate) []appmodels.RevocationList {
+	var list []appmodels.RevocationList
+	if r.revocationRepository == nil {
+		return list
+	}
+	for _, certificate := range certificates {
+		if !certificate.IsCA() {
+			continue
+		}
+		revocationList, err := r.revocationRepository.FindRevocationList(organization, certificate.SerialNumber())
+		if err == nil {
+			list = append(list, revocationList)
+		}
+	}
+	return list
+}
+
+// protocols returns the EST labels, the SCEP challenge password and the
+// Vault roles of the organization
+func (r *CertBackupController) protocols(organization *big.Int) ([]appmodels.EstLabel, appmodels.ScepChallenge, []appmodels.VaultRole, error) {
+	if r.protocolState == nil {
+		return nil, nil, nil, nil
+	}
+
+	var estLabels []appmodels.EstLabel
+	if r.protocolState.EstLabel != nil {
+		list, err := r.protocolState.EstLabel.FindAllByOrganization(organization)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("EST labels: %w", err)
+		}
+		estLabels = list
+	}
+
+	var scepChallenge appmodels.ScepChallenge
+	if r.protocolState.ScepChallenge != nil {
+		if challenge, err := r.protocolState.ScepChallenge.FindByOrganization(organization); err == nil {
+			scepChallenge = challenge
+		}
+	}
+
+	var vaultRoles []appmodels.VaultRole
+	if r.protocolState.VaultRole != nil {
+		list, err := r.protocolState.VaultRole.FindAllByOrganization(organization)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Vault roles: %w", err)
+		}
+		vaultRoles = list
+	}
+	return estLabels, scepChallenge, vaultRoles, nil
+}
+
+// acme returns the ACME accounts of the organization with their orders and
+// the authorizations of the orders
+func (r *CertBackupController) acme(organization *big.Int) ([]appmodels.AcmeAccount, []appmodels.AcmeOrder, []appmodels.AcmeAuthorization, error) {
+	if r.protocolState == nil || r.protocolState.AcmeAccount == nil {
+		return nil, nil, nil, nil
+	}
+	accounts, err := r.protocolState.AcmeAccount.FindAllByOrganization(organization)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var orders []appmodels.AcmeOrder
+	var authorizations []appmodels.AcmeAuthorization
+	for _, account := range accounts {
+		list, err := r.protocolState.AcmeOrder.FindAllByAccount(account.ID())
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("orders of %s: %w", account.ID(), err)
+		}
+		for _, order := range list {
+			for _, id := range order.AuthorizationIDs() {
+				authorization, err := r.protocolState.AcmeAuthorization.FindById(id)
+				if err != nil {
+					return nil, nil, nil, fmt.Errorf("authorization %s: %w", id, err)
+				}
+				authorizations = append(authorizations, authorization)
+			}
+		}
+		orders = append(orders, list...)
+	}
+	return accounts, orders, authorizations, nil
+}
+
+// restoreProtocols saves the state of the EST, SCEP, Vault and ACME servers
+// in the backup. Stored items with the same keys are replaced.
+func (r *CertBackupController) restoreProtocols(backup appmodels.OrganizationBackup) error {
+	if r.protocolState == nil {
+		return nil
+	}
+	if r.protocolState.EstLabel != nil {
+		for _, label := range backup.EstLabels() {
+			if _, err := r.protocolState.EstLabel.Save(label); err != nil {
+				return fmt.Errorf("EST label %s: %w", label.Label(), err)
+			}
+		}
+	}
+	if r.protocolState.ScepChallenge != nil && backup.ScepChallenge() != nil {
+		if _, err := r.protocolState.ScepChallenge.Save(backup.ScepChallenge()); err != nil {
+			return fmt.Errorf("SCEP challenge: %w", err)
+		}
+	}
+	if r.protocolState.VaultRole != nil {
+		for _, role := range backup.VaultRoles() {
+			if _, err := r.protocolState.VaultRole.Save(role); err != nil {
+				return fmt.Errorf("Vault role %s: %w", role.Name(), err)
+			}
+		}
+	}
+	if r.protocolState.AcmeAccount != nil {
+		for _, account := range backup.AcmeAccounts() {
+			if _, err := r.protocolState.AcmeAccount.Save(account); err != nil {
+				return fmt.Errorf("ACME account %s: %w", account.ID(), err)
+			}
+		}
+		for _, order := range backup.AcmeOrders() {
+			if _, err := r.protocolState.AcmeOrder.Save(order); err != nil {
+				return fmt.Errorf("ACME order %s: %w", order.ID(), err)
+			}
+		}
+		for _, authorization := range backup.AcmeAuthorizations() {
+			if _, err := r.protocolState.AcmeAuthorization.Save(authorization); err != nil {
+				return fmt.Errorf("ACME authorization %s: %w", authorization.ID(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// newerItems returns the number of stored certificates and revocations of
+// the organization which the backup does not include. A stored certificate
+// which differs from the certificate with the same serial number in the
+// backup is also newer.
+func (r *CertBackupController) newerItems(backup appmodels.OrganizationBackup) (int, error) {
+
+	organization := backup.Organization().ID()
+	if _, err := r.organizationRepository.FindById(organization); err != nil {
+		return 0, nil
+	}
+
+	certificates := make(map[string][]byte)
+	for _, certificate := range backup.Certificates() {
+		certificates[certificate.SerialNumber().String()] = certificate.Certificate().Raw
+	}
+	revocations := make(map[string]bool)
+	for _, revocation := range backup.Revocations() {
+		revocations[revocation.RevokedCertificate().SerialNumber().String()] = true
+	}
+
+	stored, err := r.certificateRepository.FindAllByOrganization(organization)
+	if err != nil {
+		return 0, err
+	}
+	newer := 0
+	for _, certificate := range stored {
+		raw, exists := certificates[certificate.SerialNumber().String()]
+		if !exists || !bytes.Equal(raw, certificate.Certificate().Raw) {
+			newer++
+		}
+	}
+
+	storedRevocations, err := r.revocations(organization, stored)
+	if err != nil {
+		return 0, err
+	}
+	for _, revocation := range storedRevocations {
+		if !revocations[revocation.RevokedCertificate().SerialNumber().String()] {
+			newer++
+		}
+	}
+	return newer, nil
+}
+
+// NewBackupController creates a backup controller
+//   - organizationRepository: The organization repository
+//   - certificateRepository: The certificate repository
+//   - privateKeyRepository: The private key repository
+//   - unitOfWorkRepository: Saves restored certificates and private keys
+//     together
+//   - revocationRepository: The certificate revocation repository, or nil
+//   - protocolState: The repositories of the EST, SCEP, Vault and ACME
+//     state, or nil
+//   - certManager: The certificate manager
+//   - trustAnchors: Root certificates trusted to sign backups in addition to
+//     the stored root certificates of the organization
+//   - token: The required backup token, or empty to accept any client
+func NewBackupController(
+	organizationRepository appmodels.OrganizationRepository,
+	certificateRepository appmodels.CertificateRepository,
+	privateKeyRepository appmodels.PrivateKeyRepository,
+	unitOfWorkRepository appmodels.UnitOfWorkRepository,
+	revocationRepository appmodels.CertificateRevocationRepository,
+	protocolState *appmodels.Collection,
+	certManager managers.CertificateManager,
+	trustAnchors []*x509.Certificate,
+	token string,
+) *CertBackupController {
+	return &CertBackupController{
+		organizationRepository: organizationRepository,
+		certificateRepository:  certificateRepository,
+		privateKeyRepository:   privateKeyRepository,
+		unitOfWorkRepository:   unitOfWorkRepository,
+		revocationRepository:   revocationRepository,
+		protocolState:          protocolState,
+		certManager:            certManager,
+		trustAnchors:           trustAnchors,
+		token:                  token,
+	}
+}
+
+var _ appmodels.BackupController = (*CertBackupController)(nil)
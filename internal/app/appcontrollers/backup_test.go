@@ -0,0 +1,375 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func requireBackupErrorType(t *testing.T, err error, errorType appmodels.BackupErrorType) {
+	var backupErr *appmodels.BackupError
+	require.ErrorAs(t, err, &backupErr)
+	assert.Equal(t, errorType, backupErr.Type())
+}
+
+// testBackupEnvironment is a backup controller of memory repositories
+type testBackupEnvironment struct {
+	repository    *appmodels.Collection
+	revocations   appmodels.CertificateRevocationRepository
+	appController *appcontrollers.CertApplicationController
+	controller    *appcontrollers.CertBackupController
+}
+
+// newTestBackupEnvironment creates the environment. The trust anchors are
+// trusted to sign backups in addition to the stored root certificates.
+func newTestBackupEnvironment(trustAnchors ...*x509.Certificate) *testBackupEnvironment {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := memoryrepository.NewCollection()
+	revocations := repository.CertificateRevocation
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+	return &testBackupEnvironment{
+		repository:    repository,
+		revocations:   revocations,
+		appController: appController,
+		controller: appcontrollers.NewBackupController(
+			repository.Organization,
+			repository.Certificate,
+			repository.PrivateKey,
+			repository.UnitOfWork,
+			revocations,
+			repository,
+			certManager,
+			trustAnchors,
+			"token",
+		),
+	}
+}
+
+// newOrganization creates an organization which retains leaf keys
+func (env *testBackupEnvironment) newOrganization(t *testing.T) appmodels.OrganizationController {
+	organization := appmodels.NewSerialNumber(10)
+	_, err := env.appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.KEY_RETENTION_RETAIN))
+	require.NoError(t, err)
+	controller, err := env.appController.OrganizationController(organization)
+	require.NoError(t, err)
+	return controller
+}
+
+// newTestBackupSource creates an organization with a root, an intermediate
+// and a revoked client certificate
+func newTestBackupSource(t *testing.T) (*testBackupEnvironment, appmodels.OrganizationController) {
+	env := newTestBackupEnvironment()
+	organizationController := env.newOrganization(t)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	_, _, err = rootController.NewIntermediateCertificate("Test Intermediate")
+	require.NoError(t, err)
+	client, _, err := rootController.NewClientCertificate("client")
+	require.NoError(t, err)
+	_, err = env.revocations.Save(appmodels.NewCertificateRevocation(
+		organizationController.OrganizationID(),
+		root.SerialNumber(),
+		appmodels.NewRevokedCertificate(client.SerialNumber(), time.Now(), client.NotAfter()),
+	))
+	require.NoError(t, err)
+	_, err = env.revocations.SaveRevocationList(appmodels.NewRevocationList(
+		organizationController.OrganizationID(),
+		root.SerialNumber(),
+		big.NewInt(42),
+		time.Now(),
+	))
+	require.NoError(t, err)
+	return env, organizationController
+}
+
+func TestCertBackupController_Authenticate(t *testing.T) {
+	controller := appcontrollers.NewBackupController(nil, nil, nil, nil, nil, nil, nil, nil, "secret")
+	assert.NoError(t, controller.Authenticate("secret"))
+	requireBackupErrorType(t, controller.Authenticate("wrong"), appmodels.BACKUP_ERROR_PERMISSION_DENIED)
+
+	open := appcontrollers.NewBackupController(nil, nil, nil, nil, nil, nil, nil, nil, "")
+	assert.NoError(t, open.Authenticate(""))
+}
+
+// saveTestProtocolState saves an EST label, a SCEP challenge password, a
+// Vault role and an ACME account with an order and its authorization
+func saveTestProtocolState(t *testing.T, repository *appmodels.Collection, organization, issuer *big.Int) {
+	now := time.Now().UTC().Truncate(time.Second)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = repository.AcmeAccount.Save(appmodels.NewAcmeAccount("account", organization, issuer, appmodels.ACME_STATUS_VALID, []string{"mailto:alice@example.com"}, &key.PublicKey, "thumbprint", now))
+	require.NoError(t, err)
+	_, err = repository.AcmeOrder.Save(appmodels.NewAcmeOrder("order", "account", appmodels.ACME_STATUS_PENDING, now.Add(time.Hour), []string{"example.com"}, []string{"authorization"}, nil, nil))
+	require.NoError(t, err)
+	challenge := appmodels.NewAcmeChallenge("challenge", appmodels.ACME_HTTP_01, "token", appmodels.ACME_STATUS_PENDING, time.Time{}, nil)
+	_, err = repository.AcmeAuthorization.Save(appmodels.NewAcmeAuthorization("authorization", "account", "order", appmodels.ACME_STATUS_PENDING, now.Add(time.Hour), "example.com", false, []appmodels.AcmeChallenge{challenge}))
+	require.NoError(t, err)
+	_, err = repository.EstLabel.Save(appmodels.NewEstLabel("devices", organization, issuer, "alice", []byte("hash")))
+	require.NoError(t, err)
+	_, err = repository.ScepChallenge.Save(appmodels.NewScepChallenge(organization, []byte("hash")))
+	require.NoError(t, err)
+	_, err = repository.VaultRole.Save(appmodels.NewVaultRole("web", organization, issuer, []string{"example.com"}, true, false, false, true, appmodels.ECDSA_P256, time.Hour, 24*time.Hour))
+	require.NoError(t, err)
+
+}
+
+func TestCertBackupController_BackupAndRestore(t *testing.T) {
+	source, organizationController := newTestBackupSource(t)
+	organization := organizationController.OrganizationID()
+
+	archive, err := source.controller.Backup(organization, "passphrase")
+	require.NoError(t, err)
+
+	target := newTestBackupEnvironment(findTestRootCertificate(t, source.repository, organization).Certificate())
+	backup, err := target.controller.Restore(archive, "passphrase", false)
+	require.NoError(t, err)
+	assert.Len(t, backup.Certificates(), 3)
+	assert.Len(t, backup.PrivateKeys(), 3)
+	assert.Len(t, backup.Revocations(), 1)
+
+	restored, err := target.repository.Organization.FindById(organization)
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.KEY_RETENTION_RETAIN, restored.KeyRetentionPolicy())
+
+	certificates, err := target.repository.Certificate.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Len(t, certificates, 3)
+	for _, certificate := range certificates {
+		_, err := target.repository.PrivateKey.FindByOrganizationAndSerialNumber(organization, certificate.SerialNumber())
+		assert.NoError(t, err)
+	}
+
+	revoked := backup.Revocations()[0].RevokedCertificate().SerialNumber()
+	_, err = target.revocations.FindByOrganizationAndSerialNumber(organization, revoked)
+	assert.NoError(t, err)
+
+	require.Len(t, backup.RevocationLists(), 1)
+	issuer := backup.RevocationLists()[0].IssuerSerialNumber()
+	revocationList, err := target.revocations.FindRevocationList(organization, issuer)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), revocationList.Number())
+
+	// A higher stored CRL number is kept
+	_, err = target.revocations.SaveRevocationList(appmodels.NewRevocationList(organization, issuer, big.NewInt(50), time.Now()))
+	require.NoError(t, err)
+
+	// Restoring the same backup again changes nothing
+	_, err = target.controller.Restore(archive, "passphrase", false)
+	assert.NoError(t, err)
+	revocationList, err = target.revocations.FindRevocationList(organization, issuer)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(50), revocationList.Number())
+}
+
+func TestCertBackupController_BackupAndRestoreProtocolState(t *testing.T) {
+	source, organizationController := newTestBackupSource(t)
+	organization := organizationController.OrganizationID()
+	root := findTestRootCertificate(t, source.repository, organization)
+	saveTestProtocolState(t, source.repository, organization, root.SerialNumber())
+
+	archive, err := source.controller.Backup(organization, "passphrase")
+	require.NoError(t, err)
+
+	target := newTestBackupEnvironment(root.Certificate())
+	backup, err := target.controller.Restore(archive, "passphrase", false)
+	require.NoError(t, err)
+	assert.Len(t, backup.EstLabels(), 1)
+	assert.NotNil(t, backup.ScepChallenge())
+	assert.Len(t, backup.VaultRoles(), 1)
+	assert.Len(t, backup.AcmeAccounts(), 1)
+	assert.Len(t, backup.AcmeOrders(), 1)
+	assert.Len(t, backup.AcmeAuthorizations(), 1)
+
+	label, err := target.repository.EstLabel.FindByLabel("devices")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", label.Username())
+	_, err = target.repository.ScepChallenge.FindByOrganization(organization)
+	assert.NoError(t, err)
+	role, err := target.repository.VaultRole.FindByOrganizationAndName(organization, "web")
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, role.TTL())
+	account, err := target.repository.AcmeAccount.FindByThumbprint(organization, root.SerialNumber(), "thumbprint")
+	require.NoError(t, err)
+	assert.Equal(t, "account", account.ID())
+	_, err = target.repository.AcmeOrder.FindById("order")
+	assert.NoError(t, err)
+	_, err = target.repository.AcmeAuthorization.FindById("authorization")
+	assert.NoError(t, err)
+}
+
+func TestCertBackupController_RestoreNewerData(t *testing.T) {
+	source, organizationController := newTestBackupSource(t)
+	organization := organizationController.OrganizationID()
+
+	archive, err := source.controller.Backup(organization, "passphrase")
+	require.NoError(t, err)
+
+	_, err = organizationController.NewRootCertificate("Newer Root")
+	require.NoError(t, err)
+
+	_, err = source.controller.Verify(archive, "passphrase", false)
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_CONFLICT)
+	_, err = source.controller.Restore(archive, "passphrase", false)
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_CONFLICT)
+
+	_, err = source.controller.Verify(archive, "passphrase", true)
+	assert.NoError(t, err)
+	_, err = source.controller.Restore(archive, "passphrase", true)
+	assert.NoError(t, err)
+}
+
+func TestCertBackupController_RestoreUntrustedSigner(t *testing.T) {
+	source, organizationController := newTestBackupSource(t)
+	organization := organizationController.OrganizationID()
+	archive, err := source.controller.Backup(organization, "passphrase")
+	require.NoError(t, err)
+
+	// The root certificate in the archive is not trusted by itself
+	target := newTestBackupEnvironment()
+	_, err = target.controller.Restore(archive, "passphrase", true)
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_BAD_REQUEST)
+
+	// Neither is a configured trust anchor of another organization
+	other, otherController := newTestBackupSource(t)
+	target = newTestBackupEnvironment(findTestRootCertificate(t, other.repository, otherController.OrganizationID()).Certificate())
+	_, err = target.controller.Restore(archive, "passphrase", true)
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_BAD_REQUEST)
+
+	list, err := target.repository.Organization.FindAll()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestCertBackupController_RestoreWrongPassphrase(t *testing.T) {
+	source, organizationController := newTestBackupSource(t)
+	organization := organizationController.OrganizationID()
+	archive, err := source.controller.Backup(organization, "passphrase")
+	require.NoError(t, err)
+
+	target := newTestBackupEnvironment(findTestRootCertificate(t, source.repository, organization).Certificate())
+	_, err = target.controller.Restore(archive, "wrong", false)
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_BAD_REQUEST)
+
+	list, err := target.repository.Organization.FindAll()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestCertBackupController_BackupErrors(t *testing.T) {
+	env := newTestBackupEnvironment()
+	organization := env.newOrganization(t).OrganizationID()
+
+	_, err := env.controller.Backup(organization, "")
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_BAD_REQUEST)
+
+	_, err = env.controller.Backup(big.NewInt(404), "passphrase")
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_NOT_FOUND)
+
+	// An organization without a root certificate cannot sign the backup
+	_, err = env.controller.Backup(organization, "passphrase")
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_BAD_REQUEST)
+}
+
+// findTestRootCertificate returns the root certificate of the organization
+func findTestRootCertificate(t *testing.T, repository *appmodels.Collection, organization *big.Int) appmodels.Certificate {
+	certificates, err := repository.Certificate.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	for _, certificate := range certificates {
+		if certificate.IsRootCertificate() {
+			return certificate
+		}
+	}
+	require.Fail(t, "no root certificate")
+	return nil
+}
+
+func TestCertBackupController_KeyShares(t *testing.T) {
+	source, organizationController := newTestBackupSource(t)
+	organization := organizationController.OrganizationID()
+	root := findTestRootCertificate(t, source.repository, organization)
+
+	shares, err := source.controller.ExportKeyShares(organization, root.SerialNumber(), 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	// The key is reconstructed in a storage which has only the certificate
+	target := newTestBackupEnvironment()
+	model, err := source.repository.Organization.FindById(organization)
+	require.NoError(t, err)
+	_, err = target.repository.Organization.Save(model)
+	require.NoError(t, err)
+	_, err = target.repository.Certificate.Save(root)
+	require.NoError(t, err)
+
+	_, err = target.controller.ImportKeyShares(organization, root.SerialNumber(), shares[:2])
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_BAD_REQUEST)
+	_, err = target.repository.PrivateKey.FindByOrganizationAndSerialNumber(organization, root.SerialNumber())
+	assert.Error(t, err)
+
+	key, err := target.controller.ImportKeyShares(organization, root.SerialNumber(), []appmodels.PrivateKeyShare{shares[3], shares[0], shares[4]})
+	require.NoError(t, err)
+	sourceKey, err := source.repository.PrivateKey.FindByOrganizationAndSerialNumber(organization, root.SerialNumber())
+	require.NoError(t, err)
+	assert.Equal(t, sourceKey.PrivateKey(), key.PrivateKey())
+	saved, err := target.repository.PrivateKey.FindByOrganizationAndSerialNumber(organization, root.SerialNumber())
+	require.NoError(t, err)
+	assert.Equal(t, sourceKey.PrivateKey(), saved.PrivateKey())
+}
+
+func TestCertBackupController_KeySharesErrors(t *testing.T) {
+	env, organizationController := newTestBackupSource(t)
+	organization := organizationController.OrganizationID()
+	root := findTestRootCertificate(t, env.repository, organization).SerialNumber()
+
+	_, err := env.controller.ExportKeyShares(organization, root, 5, 1)
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_BAD_REQUEST)
+	_, err = env.controller.ExportKeyShares(organization, root, 2, 3)
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_BAD_REQUEST)
+	_, err = env.controller.ExportKeyShares(big.NewInt(404), root, 5, 3)
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_NOT_FOUND)
+	_, err = env.controller.ExportKeyShares(organization, big.NewInt(404), 5, 3)
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_NOT_FOUND)
+
+	// Leaf keys are not exported
+	certificates, err := env.repository.Certificate.FindAllByOrganizationAndSignedBy(organization, root)
+	require.NoError(t, err)
+	for _, certificate := range certificates {
+		if !certificate.IsCA() {
+			_, err = env.controller.ExportKeyShares(organization, certificate.SerialNumber(), 5, 3)
+			requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_BAD_REQUEST)
+		}
+	}
+
+	// Shares of a certificate cannot be imported to another certificate
+	shares, err := env.controller.ExportKeyShares(organization, root, 3, 2)
+	require.NoError(t, err)
+	_, err = env.controller.ImportKeyShares(organization, certificates[0].SerialNumber(), shares)
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_BAD_REQUEST)
+	_, err = env.controller.ImportKeyShares(organization, big.NewInt(404), shares)
+	requireBackupErrorType(t, err, appmodels.BACKUP_ERROR_NOT_FOUND)
+}
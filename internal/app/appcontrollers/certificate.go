@@ -15,6 +15,10 @@ import (
 	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
 )
 
+const (
+	DefaultKeyType = appmodels.ECDSA_P384
+)
+
 // CertCertificateController implements appmodels.CertificateController to control
 // operations for certificate models.
 //
@@ -39,8 +43,17 @@ type CertCertificateController struct {
 
 	certificateRepository appmodels.CertificateRepository
 	privateKeyRepository  appmodels.PrivateKeyRepository
+	unitOfWorkRepository  appmodels.UnitOfWorkRepository
 
 	expiration time.Duration
+
+	// keyType is the key type for new certificates. If not set,
+	// DefaultKeyType is used.
+	keyType appmodels.KeyType
+
+	// signatureAlgorithm is the signature algorithm for new certificates. If
+	// not set, the organization default is used.
+	signatureAlgorithm appmodels.SignatureAlgorithm
 }
 
 func (r *CertCertificateController) OrganizationController() appmodels.OrganizationController {
@@ -90,6 +103,19 @@ func (r *CertCertificateController) ChildCertificateCollection(certificateType s
 	return list, nil
 }
 
+func (r *CertCertificateController) ChildCertificatePage(query appmodels.CertificateQuery) (appmodels.CertificatePage, error) {
+	organization := r.OrganizationID()
+	if err := apputils.ValidateCertificateCursor(query); err != nil {
+		return appmodels.CertificatePage{}, fmt.Errorf("[%s@%s:ChildCertificatePage]: %w", r.serialNumber.String(), organization, err)
+	}
+	query.SignedBy = r.serialNumber
+	page, err := r.certificateRepository.FindPageByOrganization(organization, query)
+	if err != nil {
+		return appmodels.CertificatePage{}, fmt.Errorf("[%s@%s:ChildCertificatePage]: failed: %w", r.serialNumber.String(), organization, err)
+	}
+	return page, nil
+}
+
 func (r *CertCertificateController) ChildCertificate(serialNumber *big.Int) (appmodels.Certificate, error) {
 	organization := r.OrganizationID()
 	if r.certificateRepository == nil {
@@ -121,6 +147,7 @@ func (r *CertCertificateController) ChildCertificateController(serialNumber *big
 		model,
 		r.certificateRepository,
 		r.privateKeyRepository,
+		r.unitOfWorkRepository,
 		r.certManager,
 		r.randomManager,
 		r.expiration,
@@ -153,6 +180,9 @@ func (r *CertCertificateController) PrivateKey() (appmodels.PrivateKey, error) {
 	if err != nil {
 		return nil, fmt.Errorf("[%s@%s:PrivateKey]: failed: %w", r.serialNumber, organization, err)
 	}
+	if !r.Organization().KeyRetentionPolicy().ReleasesLeafKeys() && !r.Certificate().IsCA() {
+		return nil, fmt.Errorf("[%s@%s:PrivateKey]: private key is not released by the key retention policy", r.serialNumber, organization)
+	}
 	return model, nil
 }
 
@@ -173,6 +203,54 @@ func (r *CertCertificateController) SetExpirationDuration(expiration time.Durati
 	r.expiration = expiration
 }
 
+func (r *CertCertificateController) SetKeyType(keyType appmodels.KeyType) {
+	r.keyType = keyType
+}
+
+func (r *CertCertificateController) SetSignatureAlgorithm(signatureAlgorithm appmodels.SignatureAlgorithm) {
+	r.signatureAlgorithm = signatureAlgorithm
+}
+
+// newKeyType returns the key type for a new certificate
+func (r *CertCertificateController) newKeyType() appmodels.KeyType {
+	if r.keyType == appmodels.NIL_KEY_TYPE {
+		return DefaultKeyType
+	}
+	return r.keyType
+}
+
+// newSignatureAlgorithm returns the signature algorithm for a new certificate
+func (r *CertCertificateController) newSignatureAlgorithm() appmodels.SignatureAlgorithm {
+	if r.signatureAlgorithm == appmodels.NIL_SIGNATURE_ALGORITHM {
+		return r.Organization().SignatureAlgorithm()
+	}
+	return r.signatureAlgorithm
+}
+
+// leafPrivateKey returns the private key of a new leaf certificate if the
+// key retention policy of the organization stores it, otherwise nil
+func (r *CertCertificateController) leafPrivateKey(privateKey appmodels.PrivateKey) appmodels.PrivateKey {
+	if privateKey == nil || !r.Organization().KeyRetentionPolicy().StoresLeafKeys() {
+		return nil
+	}
+	return privateKey
+}
+
+// saveCertificate saves a new certificate. If the private key is defined, the
+// certificate and the key are committed together or not at all.
+func (r *CertCertificateController) saveCertificate(cert appmodels.Certificate, privateKey appmodels.PrivateKey) (appmodels.Certificate, error) {
+	if privateKey == nil {
+		return r.certificateRepository.Save(cert)
+	}
+	work := r.unitOfWorkRepository.NewUnitOfWork()
+	work.SavePrivateKey(privateKey)
+	work.SaveCertificate(cert)
+	if err := work.Commit(); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
 func (r *CertCertificateController) NewIntermediateCertificate(commonName string) (appmodels.Certificate, appmodels.PrivateKey, error) {
 
 	organization := r.OrganizationID()
@@ -193,7 +271,7 @@ func (r *CertCertificateController) NewIntermediateCertificate(commonName string
 	newPrivateKey, err := apputils.GeneratePrivateKey(
 		organization,
 		serialNumber,
-		appmodels.ECDSA_P384,
+		r.newKeyType(),
 	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("[%s@%s:NewIntermediateCertificate:%s]: failed to create private key: %w", r.serialNumber, organization, commonName, err)
@@ -204,6 +282,7 @@ func (r *CertCertificateController) NewIntermediateCertificate(commonName string
 		serialNumber,
 		model,
 		r.expiration,
+		r.newSignatureAlgorithm(),
 		newPrivateKey,
 		parentCertificate,
 		parentPrivateKey,
@@ -214,17 +293,11 @@ func (r *CertCertificateController) NewIntermediateCertificate(commonName string
 	}
 	log.Printf("[%s@%s:NewIntermediateCertificate:%s]: Certificate generated", r.serialNumber, organization, commonName)
 
-	// savedPrivateKey, err := r.privateKeyRepository.Save(newPrivateKey)
-	// if err != nil {
-	// 	return nil, nil, fmt.Errorf("[%s@%s:NewIntermediateCertificate:%s]: could not save private key: %w", r.serialNumber, organization, commonName, err)
-	// }
-	// log.Printf("[%s@%s:NewIntermediateCertificate:%s]: Private key saved", r.serialNumber, organization, commonName)
-
-	savedModel, err := r.certificateRepository.Save(cert)
+	savedModel, err := r.saveCertificate(cert, newPrivateKey)
 	if err != nil {
 		return nil, nil, fmt.Errorf("[%s@%s:NewIntermediateCertificate:%s]: could not save certificate: %w", r.serialNumber, organization, commonName, err)
 	}
-	log.Printf("[%s@%s:NewIntermediateCertificate:%s]: Certificate saved", r.serialNumber, organization, commonName)
+	log.Printf("[%s@%s:NewIntermediateCertificate:%s]: Certificate and private key saved", r.serialNumber, organization, commonName)
 
 	return savedModel, newPrivateKey, nil
 }
@@ -237,75 +310,98 @@ func (r *CertCertificateController) NewServerCertificate(dnsNames ...string) (ap
 		return nil, nil, fmt.Errorf("[%s@%s:NewServerCertificate:%s]: server certificate must have at least one dns name", r.serialNumber, organization, strings.Join(dnsNames, ","))
 	}
 
-	model := r.Organization()
-
-	parentCertificate := r.Certificate()
-
-	parentPrivateKey, err := r.PrivateKey()
-	if err != nil {
-		return nil, nil, fmt.Errorf("[%s@%s:NewServerCertificate:%s]: failed to fetch private key: %w", r.serialNumber, organization, strings.Join(dnsNames, ","), err)
-	}
-
 	serialNumber, err := apputils.GenerateSerialNumber(r.randomManager)
 	if err != nil {
 		return nil, nil, fmt.Errorf("[%s@%s:NewServerCertificate:%s]: failed to create serial number: %w", r.serialNumber, organization, strings.Join(dnsNames, ","), err)
 	}
 
 	newPrivateKey, err := apputils.GeneratePrivateKey(
-		model.ID(),
+		organization,
 		serialNumber,
-		appmodels.ECDSA_P384,
+		r.newKeyType(),
 	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("[%s@%s:NewServerCertificate:%s]: failed to create private key: %w", r.serialNumber, organization, strings.Join(dnsNames, ","), err)
 	}
 
+	savedModel, err := r.newServerCertificate(serialNumber, newPrivateKey, newPrivateKey, dnsNames)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[%s@%s:NewServerCertificate:%s]: %w", r.serialNumber, organization, strings.Join(dnsNames, ","), err)
+	}
+
+	return savedModel, newPrivateKey, nil
+}
+
+func (r *CertCertificateController) NewServerCertificateFromPublicKey(publicKey appmodels.PublicKey, dnsNames ...string) (appmodels.Certificate, error) {
+
+	organization := r.OrganizationID()
+
+	if len(dnsNames) <= 0 {
+		return nil, fmt.Errorf("[%s@%s:NewServerCertificateFromPublicKey:%s]: server certificate must have at least one dns name", r.serialNumber, organization, strings.Join(dnsNames, ","))
+	}
+
+	if publicKey == nil || publicKey.PublicKey() == nil {
+		return nil, fmt.Errorf("[%s@%s:NewServerCertificateFromPublicKey:%s]: public key must be defined", r.serialNumber, organization, strings.Join(dnsNames, ","))
+	}
+
+	serialNumber, err := apputils.GenerateSerialNumber(r.randomManager)
+	if err != nil {
+		return nil, fmt.Errorf("[%s@%s:NewServerCertificateFromPublicKey:%s]: failed to create serial number: %w", r.serialNumber, organization, strings.Join(dnsNames, ","), err)
+	}
+
+	savedModel, err := r.newServerCertificate(serialNumber, publicKey, nil, dnsNames)
+	if err != nil {
+		return nil, fmt.Errorf("[%s@%s:NewServerCertificateFromPublicKey:%s]: %w", r.serialNumber, organization, strings.Join(dnsNames, ","), err)
+	}
+
+	return savedModel, nil
+}
+
+// newServerCertificate signs and saves a server certificate for the public key.
+// The private key is nil if it is not known.
+func (r *CertCertificateController) newServerCertificate(serialNumber *big.Int, publicKey appmodels.PublicKey, privateKey appmodels.PrivateKey, dnsNames []string) (appmodels.Certificate, error) {
+
+	organization := r.OrganizationID()
+
+	model := r.Organization()
+
+	parentCertificate := r.Certificate()
+
+	parentPrivateKey, err := r.PrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch private key: %w", err)
+	}
+
 	cert, err := apputils.NewServerCertificate(
 		r.certManager,
 		serialNumber,
 		model,
 		r.expiration,
-		newPrivateKey,
+		r.newSignatureAlgorithm(),
+		publicKey,
 		parentCertificate,
 		parentPrivateKey,
 		dnsNames[0],
 		dnsNames...,
 	)
 	if err != nil {
-		return nil, nil, fmt.Errorf("[%s@%s:NewServerCertificate:%s]: failed to create intermediate certificate: %w", r.serialNumber, organization, strings.Join(dnsNames, ","), err)
+		return nil, fmt.Errorf("failed to create server certificate: %w", err)
 	}
 	log.Printf("[%s@%s:NewServerCertificate:%s]: Certificate generated", r.serialNumber, organization, strings.Join(dnsNames, ","))
 
-	// savedPrivateKey, err := r.privateKeyRepository.Save(newPrivateKey)
-	// if err != nil {
-	// 	return nil, nil, fmt.Errorf("[%s@%s:NewServerCertificate:%s]: could not save private key: %w", r.serialNumber, organization, strings.Join(dnsNames, ","), err)
-	// }
-	// log.Printf("[%s@%s:NewServerCertificate:%s]: Private key saved", r.serialNumber, organization, strings.Join(dnsNames, ","))
-
-	savedModel, err := r.certificateRepository.Save(cert)
+	savedModel, err := r.saveCertificate(cert, r.leafPrivateKey(privateKey))
 	if err != nil {
-		return nil, nil, fmt.Errorf("[%s@%s:NewServerCertificate:%s]: could not save certificate: %w", r.serialNumber, organization, strings.Join(dnsNames, ","), err)
+		return nil, fmt.Errorf("could not save certificate: %w", err)
 	}
 	log.Printf("[%s@%s:NewServerCertificate:%s]: Certificate saved", r.serialNumber, organization, strings.Join(dnsNames, ","))
 
-	return savedModel, newPrivateKey, nil
+	return savedModel, nil
 }
 
 func (r *CertCertificateController) NewClientCertificate(commonName string) (appmodels.Certificate, appmodels.PrivateKey, error) {
 
 	organization := r.OrganizationID()
 
-	parentPrivateKey, err := r.PrivateKey()
-	if err != nil {
-		return nil, nil, fmt.Errorf("[%s@%s:NewClientCertificate:%s]: failed to fetch private key: %w", r.serialNumber, organization, commonName, err)
-	}
-	log.Printf("[%s@%s:NewClientCertificate:%s]: parentPrivateKey accquired", r.serialNumber, organization, commonName)
-
-	model := r.Organization()
-	log.Printf("[%s@%s:NewClientCertificate:%s]: model = %s", r.serialNumber, organization, commonName, model)
-
-	parentCertificate := r.Certificate()
-
 	serialNumber, err := apputils.GenerateSerialNumber(r.randomManager)
 	if err != nil {
 		return nil, nil, fmt.Errorf("[%s@%s:NewClientCertificate:%s]: failed to create serial number: %w", r.serialNumber, organization, commonName, err)
@@ -315,43 +411,165 @@ func (r *CertCertificateController) NewClientCertificate(commonName string) (app
 	newPrivateKey, err := apputils.GeneratePrivateKey(
 		organization,
 		serialNumber,
-		appmodels.ECDSA_P384,
+		r.newKeyType(),
 	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("[%s@%s:NewClientCertificate:%s]: failed to create private key: %w", r.serialNumber, organization, commonName, err)
 	}
 	log.Printf("[%s@%s:NewClientCertificate:%s]: Private key generated", r.serialNumber, organization, commonName)
 
+	savedModel, err := r.newClientCertificate(serialNumber, newPrivateKey, newPrivateKey, commonName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[%s@%s:NewClientCertificate:%s]: %w", r.serialNumber, organization, commonName, err)
+	}
+
+	return savedModel, newPrivateKey, nil
+}
+
+func (r *CertCertificateController) NewClientCertificateFromPublicKey(publicKey appmodels.PublicKey, commonName string) (appmodels.Certificate, error) {
+
+	organization := r.OrganizationID()
+
+	if publicKey == nil || publicKey.PublicKey() == nil {
+		return nil, fmt.Errorf("[%s@%s:NewClientCertificateFromPublicKey:%s]: public key must be defined", r.serialNumber, organization, commonName)
+	}
+
+	serialNumber, err := apputils.GenerateSerialNumber(r.randomManager)
+	if err != nil {
+		return nil, fmt.Errorf("[%s@%s:NewClientCertificateFromPublicKey:%s]: failed to create serial number: %w", r.serialNumber, organization, commonName, err)
+	}
+
+	savedModel, err := r.newClientCertificate(serialNumber, publicKey, nil, commonName)
+	if err != nil {
+		return nil, fmt.Errorf("[%s@%s:NewClientCertificateFromPublicKey:%s]: %w", r.serialNumber, organization, commonName, err)
+	}
+
+	return savedModel, nil
+}
+
+// newClientCertificate signs and saves a client certificate for the public key.
+// The private key is nil if it is not known.
+func (r *CertCertificateController) newClientCertificate(serialNumber *big.Int, publicKey appmodels.PublicKey, privateKey appmodels.PrivateKey, commonName string) (appmodels.Certificate, error) {
+
+	organization := r.OrganizationID()
+
+	parentPrivateKey, err := r.PrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch private key: %w", err)
+	}
+
+	model := r.Organization()
+
+	parentCertificate := r.Certificate()
+
 	cert, err := apputils.NewClientCertificate(
 		r.certManager,
 		serialNumber,
 		model,
 		r.expiration,
-		newPrivateKey,
+		r.newSignatureAlgorithm(),
+		publicKey,
 		parentCertificate,
 		parentPrivateKey,
 		commonName,
 	)
 	if err != nil {
-		return nil, nil, fmt.Errorf("[%s@%s:NewClientCertificate:%s]: failed to create intermediate certificate: %w", r.serialNumber, organization, commonName, err)
+		return nil, fmt.Errorf("failed to create client certificate: %w", err)
 	}
 	log.Printf("[%s@%s:NewClientCertificate:%s]: Certificate generated", r.serialNumber, organization, commonName)
 
-	// savedPrivateKey, err := r.privateKeyRepository.Save(newPrivateKey)
-	// if err != nil {
-	// 	return nil, nil, fmt.Errorf("[%s@%s:NewClientCertificate:%s]: could not save private key: %w", r.serialNumber, organization, commonName, err)
-	// }
-	// log.Printf("[%s@%s:NewClientCertificate:%s]: Private key saved", r.serialNumber, organization, commonName)
-
-	savedModel, err := r.certificateRepository.Save(cert)
+	savedModel, err := r.saveCertificate(cert, r.leafPrivateKey(privateKey))
 	if err != nil {
-		return nil, nil, fmt.Errorf("[%s@%s:NewClientCertificate:%s]: could not save certificate: %w", r.serialNumber, organization, commonName, err)
+		return nil, fmt.Errorf("could not save certificate: %w", err)
 	}
 	log.Printf("[%s@%s:NewClientCertificate:%s]: Certificate saved", r.serialNumber, organization, commonName)
 
+	return savedModel, nil
+}
+
+func (r *CertCertificateController) NewSpiffeCertificate(spiffeID string) (appmodels.Certificate, appmodels.PrivateKey, error) {
+
+	organization := r.OrganizationID()
+
+	serialNumber, err := apputils.GenerateSerialNumber(r.randomManager)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[%s@%s:NewSpiffeCertificate:%s]: failed to create serial number: %w", r.serialNumber, organization, spiffeID, err)
+	}
+
+	newPrivateKey, err := apputils.GeneratePrivateKey(
+		organization,
+		serialNumber,
+		r.newKeyType(),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[%s@%s:NewSpiffeCertificate:%s]: failed to create private key: %w", r.serialNumber, organization, spiffeID, err)
+	}
+
+	savedModel, err := r.newSpiffeCertificate(serialNumber, newPrivateKey, newPrivateKey, spiffeID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[%s@%s:NewSpiffeCertificate:%s]: %w", r.serialNumber, organization, spiffeID, err)
+	}
+
 	return savedModel, newPrivateKey, nil
 }
 
+func (r *CertCertificateController) NewSpiffeCertificateFromPublicKey(publicKey appmodels.PublicKey, spiffeID string) (appmodels.Certificate, error) {
+
+	organization := r.OrganizationID()
+
+	if publicKey == nil || publicKey.PublicKey() == nil {
+		return nil, fmt.Errorf("[%s@%s:NewSpiffeCertificateFromPublicKey:%s]: public key must be defined", r.serialNumber, organization, spiffeID)
+	}
+
+	serialNumber, err := apputils.GenerateSerialNumber(r.randomManager)
+	if err != nil {
+		return nil, fmt.Errorf("[%s@%s:NewSpiffeCertificateFromPublicKey:%s]: failed to create serial number: %w", r.serialNumber, organization, spiffeID, err)
+	}
+
+	savedModel, err := r.newSpiffeCertificate(serialNumber, publicKey, nil, spiffeID)
+	if err != nil {
+		return nil, fmt.Errorf("[%s@%s:NewSpiffeCertificateFromPublicKey:%s]: %w", r.serialNumber, organization, spiffeID, err)
+	}
+
+	return savedModel, nil
+}
+
+// newSpiffeCertificate signs and saves an X.509-SVID for the public key.
+// The private key is nil if it is not known.
+func (r *CertCertificateController) newSpiffeCertificate(serialNumber *big.Int, publicKey appmodels.PublicKey, privateKey appmodels.PrivateKey, spiffeID string) (appmodels.Certificate, error) {
+
+	organization := r.OrganizationID()
+
+	parentPrivateKey, err := r.PrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch private key: %w", err)
+	}
+
+	cert, err := apputils.NewSpiffeCertificate(
+		r.certManager,
+		serialNumber,
+		r.Organization(),
+		r.expiration,
+		r.newSignatureAlgorithm(),
+		publicKey,
+		r.Certificate(),
+		parentPrivateKey,
+		spiffeID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SPIFFE certificate: %w", err)
+	}
+	log.Printf("[%s@%s:NewSpiffeCertificate:%s]: Certificate generated", r.serialNumber, organization, spiffeID)
+
+	savedModel, err := r.saveCertificate(cert, r.leafPrivateKey(privateKey))
+	if err != nil {
+		return nil, fmt.Errorf("could not save certificate: %w", err)
+	}
+	log.Printf("[%s@%s:NewSpiffeCertificate:%s]: Certificate saved", r.serialNumber, organization, spiffeID)
+
+	return savedModel, nil
+}
+
 func (r *CertCertificateController) UsesCertificateService(service appmodels.CertificateRepository) bool {
 	return r.certificateRepository == service
 }
@@ -372,6 +590,7 @@ func (r *CertCertificateController) FindCertificate(organization *big.Int, certi
 //   - model appmodels.Certificate
 //   - certificateRepository is appmodels.CertificateRepository
 //   - privateKeyRepository is appmodels.PrivateKeyRepository
+//   - unitOfWorkRepository is appmodels.UnitOfWorkRepository
 //   - certManager is managers.CertificateManager
 //   - randomManager is  managers.RandomManager
 //   - expiration time.Duration is
@@ -384,6 +603,7 @@ func NewCertificateController(
 	model appmodels.Certificate,
 	certificateRepository appmodels.CertificateRepository,
 	privateKeyRepository appmodels.PrivateKeyRepository,
+	unitOfWorkRepository appmodels.UnitOfWorkRepository,
 	certManager managers.CertificateManager,
 	randomManager managers.RandomManager,
 	expiration time.Duration,
@@ -401,6 +621,10 @@ func NewCertificateController(
 		panic("NewCertificateController: privateKeyRepository not defined")
 	}
 
+	if unitOfWorkRepository == nil {
+		panic("NewCertificateController: unitOfWorkRepository not defined")
+	}
+
 	if certManager == nil {
 		panic("NewCertificateController: certManager not defined")
 	}
@@ -414,6 +638,7 @@ func NewCertificateController(
 		model:                        model,
 		certificateRepository:        certificateRepository,
 		privateKeyRepository:         privateKeyRepository,
+		unitOfWorkRepository:         unitOfWorkRepository,
 		expiration:                   expiration,
 		certManager:                  certManager,
 		randomManager:                randomManager,
@@ -11,8 +11,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
 	"github.com/hyperifyio/gocertcenter/internal/common/managers"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
@@ -27,6 +29,7 @@ func TestNewCertificateController(t *testing.T) {
 	mockOrganizationController := &appmocks.MockOrganizationController{}
 	mockCertificateRepository := &appmocks.MockCertificateService{}
 	mockPrivateKeyRepository := &appmocks.MockPrivateKeyService{}
+	mockUnitOfWorkRepository := &appmocks.MockUnitOfWorkService{}
 
 	mockCertManager := &managers.SystemCertificateManager{}
 	mockRandomManager := &commonmocks.MockRandomManager{}
@@ -38,6 +41,7 @@ func TestNewCertificateController(t *testing.T) {
 		model,
 		mockCertificateRepository,
 		mockPrivateKeyRepository,
+		mockUnitOfWorkRepository,
 		mockCertManager,
 		mockRandomManager,
 		time.Second,
@@ -54,6 +58,7 @@ func TestCertificateController_GetOrganizationController(t *testing.T) {
 	model := &appmocks.MockCertificate{}
 	mockCertificateRepository := &appmocks.MockCertificateService{}
 	mockPrivateKeyRepository := &appmocks.MockPrivateKeyService{}
+	mockUnitOfWorkRepository := &appmocks.MockUnitOfWorkService{}
 
 	mockCertManager := &managers.SystemCertificateManager{}
 	mockRandomManager := &commonmocks.MockRandomManager{}
@@ -66,6 +71,7 @@ func TestCertificateController_GetOrganizationController(t *testing.T) {
 		model,
 		mockCertificateRepository,
 		mockPrivateKeyRepository,
+		mockUnitOfWorkRepository,
 		mockCertManager,
 		mockRandomManager,
 		time.Second,
@@ -85,6 +91,7 @@ func TestCertificateController_GetOrganizationController(t *testing.T) {
 			model,
 			mockCertificateRepository,
 			mockPrivateKeyRepository,
+			mockUnitOfWorkRepository,
 			mockCertManager,
 			mockRandomManager,
 			time.Second,
@@ -99,6 +106,7 @@ func TestCertificateController_GetCertificateModel(t *testing.T) {
 	mockOrganizationController := &appmocks.MockOrganizationController{}
 	mockCertificateRepository := &appmocks.MockCertificateService{}
 	mockPrivateKeyRepository := &appmocks.MockPrivateKeyService{}
+	mockUnitOfWorkRepository := &appmocks.MockUnitOfWorkService{}
 
 	mockCertManager := &managers.SystemCertificateManager{}
 	mockRandomManager := &commonmocks.MockRandomManager{}
@@ -110,6 +118,7 @@ func TestCertificateController_GetCertificateModel(t *testing.T) {
 		mockCert,
 		mockCertificateRepository,
 		mockPrivateKeyRepository,
+		mockUnitOfWorkRepository,
 		mockCertManager,
 		mockRandomManager,
 		time.Second,
@@ -125,6 +134,7 @@ func TestCertificateController_GetChildCertificateCollection(t *testing.T) {
 	serialNumber := appmodels.NewSerialNumber(123)
 	certs := []appmodels.Certificate{mockCert}
 	mockPrivateKeyRepository := &appmocks.MockPrivateKeyService{}
+	mockUnitOfWorkRepository := &appmocks.MockUnitOfWorkService{}
 	mockOrganizationController := &appmocks.MockOrganizationController{}
 	mockOrganizationController.On("OrganizationID").Return(big.NewInt(123))
 
@@ -140,6 +150,7 @@ func TestCertificateController_GetChildCertificateCollection(t *testing.T) {
 		mockCert,
 		mockCertRepo,
 		mockPrivateKeyRepository,
+		mockUnitOfWorkRepository,
 		mockCertManager,
 		mockRandomManager,
 		time.Second,
@@ -162,6 +173,7 @@ func TestCertificateController_NewIntermediateCertificate(t *testing.T) {
 	mockPrivateKey := new(appmocks.MockPrivateKey)
 	mockCertRepo := new(appmocks.MockCertificateService)
 	mockPrivateKeyRepo := new(appmocks.MockPrivateKeyService)
+	mockUnitOfWorkRepository := &appmocks.MockUnitOfWorkService{}
 	mockCertManager := new(commonmocks.MockCertificateManager)
 	mockOrganization := new(appmocks.MockOrganization)
 	mockRandomManager := new(commonmocks.MockRandomManager)
@@ -171,6 +183,8 @@ func TestCertificateController_NewIntermediateCertificate(t *testing.T) {
 	mockOrganization.On("Name").Return("Example")
 	mockOrganization.On("Slug").Return(orgSlug)
 	mockOrganization.On("Names").Return([]string{"Example"})
+	mockOrganization.On("SignatureAlgorithm").Return(appmodels.NIL_SIGNATURE_ALGORITHM)
+	mockOrganization.On("KeyRetentionPolicy").Return(appmodels.KEY_RETENTION_NONE)
 
 	mockOrgController.On("OrganizationID").Return(orgID)
 	mockOrgController.On("Organization").Return(mockOrganization)
@@ -187,11 +201,16 @@ func TestCertificateController_NewIntermediateCertificate(t *testing.T) {
 
 	mockCert.On("Certificate").Return(&x509.Certificate{})
 	mockCert.On("SerialNumber").Return(serialNumber)
+	mockCert.On("IsCA").Return(true)
 
 	mockPrivateKey.On("PrivateKey").Return(&rsa.PrivateKey{})
 
 	mockPrivateKeyRepo.On("FindByOrganizationAndSerialNumber", orgID, serialNumber).Return(mockPrivateKey, nil)
-	mockCertRepo.On("Save", mock.Anything).Return(mockCert, nil)
+	mockWork := &appmocks.MockUnitOfWork{}
+	mockWork.On("SavePrivateKey", mock.Anything).Return()
+	mockWork.On("SaveCertificate", mock.Anything).Return()
+	mockWork.On("Commit").Return(nil)
+	mockUnitOfWorkRepository.On("NewUnitOfWork").Return(mockWork)
 
 	controller := appcontrollers.NewCertificateController(
 		mockOrgController,
@@ -200,6 +219,7 @@ func TestCertificateController_NewIntermediateCertificate(t *testing.T) {
 		mockCert,
 		mockCertRepo,
 		mockPrivateKeyRepo,
+		mockUnitOfWorkRepository,
 		mockCertManager,
 		mockRandomManager,
 		time.Hour*24,
@@ -212,7 +232,278 @@ func TestCertificateController_NewIntermediateCertificate(t *testing.T) {
 	assert.NotNil(t, createdPrivateKey)
 
 	// Verify interactions
+	mockCertManager.AssertExpectations(t)
+	mockPrivateKeyRepo.AssertExpectations(t)
+	mockUnitOfWorkRepository.AssertExpectations(t)
+	mockWork.AssertExpectations(t)
+}
+
+func TestCertificateController_NewServerCertificateFromPublicKey(t *testing.T) {
+	orgID := big.NewInt(123)
+	mockCert := new(appmocks.MockCertificate)
+	mockPrivateKey := new(appmocks.MockPrivateKey)
+	mockCertRepo := new(appmocks.MockCertificateService)
+	mockPrivateKeyRepo := new(appmocks.MockPrivateKeyService)
+	mockUnitOfWorkRepository := &appmocks.MockUnitOfWorkService{}
+	mockCertManager := new(commonmocks.MockCertificateManager)
+	mockOrganization := new(appmocks.MockOrganization)
+	mockRandomManager := new(commonmocks.MockRandomManager)
+	mockOrgController := new(appmocks.MockOrganizationController)
+
+	mockOrganization.On("ID").Return(orgID)
+	mockOrganization.On("Names").Return([]string{"Example"})
+	mockOrganization.On("SignatureAlgorithm").Return(appmodels.NIL_SIGNATURE_ALGORITHM)
+	mockOrganization.On("KeyRetentionPolicy").Return(appmodels.KEY_RETENTION_NONE)
+
+	mockOrgController.On("OrganizationID").Return(orgID)
+	mockOrgController.On("Organization").Return(mockOrganization)
+
+	serialNumber := appmodels.NewSerialNumber(123)
+	newSerialNumber := appmodels.NewSerialNumber(456)
+	publicKey := appmodels.NewPublicKey(&rsa.PublicKey{})
+
+	mockRandomManager.On("CreateBigInt", mock.Anything).Return(newSerialNumber, nil)
+
+	mockCertManager.On("CreateCertificate", mock.Anything, mock.Anything, mock.Anything, publicKey.PublicKey(), mock.Anything).Return([]byte("certBytes"), nil)
+	mockCertManager.On("ParseCertificate", []byte("certBytes")).Return(&x509.Certificate{SerialNumber: newSerialNumber}, nil)
+
+	mockCert.On("Certificate").Return(&x509.Certificate{})
+	mockCert.On("SerialNumber").Return(serialNumber)
+	mockCert.On("IsCA").Return(true)
+
+	mockPrivateKey.On("PrivateKey").Return(&rsa.PrivateKey{})
+
+	mockPrivateKeyRepo.On("FindByOrganizationAndSerialNumber", orgID, serialNumber).Return(mockPrivateKey, nil)
+	mockCertRepo.On("Save", mock.Anything).Return(mockCert, nil)
+
+	controller := appcontrollers.NewCertificateController(
+		mockOrgController,
+		nil,
+		serialNumber,
+		mockCert,
+		mockCertRepo,
+		mockPrivateKeyRepo,
+		mockUnitOfWorkRepository,
+		mockCertManager,
+		mockRandomManager,
+		time.Hour*24,
+	)
+
+	createdCert, err := controller.NewServerCertificateFromPublicKey(publicKey, "example.com")
+	assert.NoError(t, err)
+	assert.NotNil(t, createdCert)
+
+	_, err = controller.NewServerCertificateFromPublicKey(nil, "example.com")
+	assert.Error(t, err)
+
+	_, err = controller.NewServerCertificateFromPublicKey(publicKey)
+	assert.Error(t, err)
+
 	mockCertManager.AssertExpectations(t)
 	mockPrivateKeyRepo.AssertExpectations(t)
 	mockCertRepo.AssertExpectations(t)
 }
+
+func TestCertificateController_NewClientCertificateFromPublicKey(t *testing.T) {
+	orgID := big.NewInt(123)
+	mockCert := new(appmocks.MockCertificate)
+	mockPrivateKey := new(appmocks.MockPrivateKey)
+	mockCertRepo := new(appmocks.MockCertificateService)
+	mockPrivateKeyRepo := new(appmocks.MockPrivateKeyService)
+	mockUnitOfWorkRepository := &appmocks.MockUnitOfWorkService{}
+	mockCertManager := new(commonmocks.MockCertificateManager)
+	mockOrganization := new(appmocks.MockOrganization)
+	mockRandomManager := new(commonmocks.MockRandomManager)
+	mockOrgController := new(appmocks.MockOrganizationController)
+
+	mockOrganization.On("ID").Return(orgID)
+	mockOrganization.On("Names").Return([]string{"Example"})
+	mockOrganization.On("SignatureAlgorithm").Return(appmodels.NIL_SIGNATURE_ALGORITHM)
+	mockOrganization.On("KeyRetentionPolicy").Return(appmodels.KEY_RETENTION_NONE)
+
+	mockOrgController.On("OrganizationID").Return(orgID)
+	mockOrgController.On("Organization").Return(mockOrganization)
+
+	serialNumber := appmodels.NewSerialNumber(123)
+	newSerialNumber := appmodels.NewSerialNumber(456)
+	publicKey := appmodels.NewPublicKey(&rsa.PublicKey{})
+
+	mockRandomManager.On("CreateBigInt", mock.Anything).Return(newSerialNumber, nil)
+
+	mockCertManager.On("CreateCertificate", mock.Anything, mock.Anything, mock.Anything, publicKey.PublicKey(), mock.Anything).Return([]byte("certBytes"), nil)
+	mockCertManager.On("ParseCertificate", []byte("certBytes")).Return(&x509.Certificate{SerialNumber: newSerialNumber}, nil)
+
+	mockCert.On("Certificate").Return(&x509.Certificate{})
+	mockCert.On("SerialNumber").Return(serialNumber)
+	mockCert.On("IsCA").Return(true)
+
+	mockPrivateKey.On("PrivateKey").Return(&rsa.PrivateKey{})
+
+	mockPrivateKeyRepo.On("FindByOrganizationAndSerialNumber", orgID, serialNumber).Return(mockPrivateKey, nil)
+	mockCertRepo.On("Save", mock.Anything).Return(mockCert, nil)
+
+	controller := appcontrollers.NewCertificateController(
+		mockOrgController,
+		nil,
+		serialNumber,
+		mockCert,
+		mockCertRepo,
+		mockPrivateKeyRepo,
+		mockUnitOfWorkRepository,
+		mockCertManager,
+		mockRandomManager,
+		time.Hour*24,
+	)
+
+	createdCert, err := controller.NewClientCertificateFromPublicKey(publicKey, "client")
+	assert.NoError(t, err)
+	assert.NotNil(t, createdCert)
+
+	_, err = controller.NewClientCertificateFromPublicKey(nil, "client")
+	assert.Error(t, err)
+
+	mockCertManager.AssertExpectations(t)
+	mockPrivateKeyRepo.AssertExpectations(t)
+	mockCertRepo.AssertExpectations(t)
+}
+
+func TestCertificateController_NewSpiffeCertificateFromPublicKey(t *testing.T) {
+	orgID := big.NewInt(123)
+	mockCert := new(appmocks.MockCertificate)
+	mockPrivateKey := new(appmocks.MockPrivateKey)
+	mockCertRepo := new(appmocks.MockCertificateService)
+	mockPrivateKeyRepo := new(appmocks.MockPrivateKeyService)
+	mockUnitOfWorkRepository := &appmocks.MockUnitOfWorkService{}
+	mockCertManager := new(commonmocks.MockCertificateManager)
+	mockOrganization := new(appmocks.MockOrganization)
+	mockRandomManager := new(commonmocks.MockRandomManager)
+	mockOrgController := new(appmocks.MockOrganizationController)
+
+	mockOrganization.On("ID").Return(orgID)
+	mockOrganization.On("Names").Return([]string{"Example"})
+	mockOrganization.On("SignatureAlgorithm").Return(appmodels.NIL_SIGNATURE_ALGORITHM)
+	mockOrganization.On("KeyRetentionPolicy").Return(appmodels.KEY_RETENTION_NONE)
+	mockOrganization.On("SpiffeTrustDomain").Return("example.org")
+
+	mockOrgController.On("OrganizationID").Return(orgID)
+	mockOrgController.On("Organization").Return(mockOrganization)
+
+	serialNumber := appmodels.NewSerialNumber(123)
+	newSerialNumber := appmodels.NewSerialNumber(456)
+	publicKey := appmodels.NewPublicKey(&rsa.PublicKey{})
+
+	mockRandomManager.On("CreateBigInt", mock.Anything).Return(newSerialNumber, nil)
+
+	mockCertManager.On("CreateCertificate", mock.Anything, mock.MatchedBy(func(template *x509.Certificate) bool {
+		return len(template.URIs) == 1 && template.URIs[0].String() == "spiffe://example.org/web" && !template.IsCA
+	}), mock.Anything, publicKey.PublicKey(), mock.Anything).Return([]byte("certBytes"), nil)
+	mockCertManager.On("ParseCertificate", []byte("certBytes")).Return(&x509.Certificate{SerialNumber: newSerialNumber}, nil)
+
+	mockCert.On("Certificate").Return(&x509.Certificate{})
+	mockCert.On("SerialNumber").Return(serialNumber)
+	mockCert.On("IsCA").Return(true)
+
+	mockPrivateKey.On("PrivateKey").Return(&rsa.PrivateKey{})
+
+	mockPrivateKeyRepo.On("FindByOrganizationAndSerialNumber", orgID, serialNumber).Return(mockPrivateKey, nil)
+	mockCertRepo.On("Save", mock.Anything).Return(mockCert, nil)
+
+	controller := appcontrollers.NewCertificateController(
+		mockOrgController,
+		nil,
+		serialNumber,
+		mockCert,
+		mockCertRepo,
+		mockPrivateKeyRepo,
+		mockUnitOfWorkRepository,
+		mockCertManager,
+		mockRandomManager,
+		time.Hour*24,
+	)
+
+	createdCert, err := controller.NewSpiffeCertificateFromPublicKey(publicKey, "spiffe://example.org/web")
+	assert.NoError(t, err)
+	assert.NotNil(t, createdCert)
+
+	_, err = controller.NewSpiffeCertificateFromPublicKey(nil, "spiffe://example.org/web")
+	assert.Error(t, err)
+
+	_, err = controller.NewSpiffeCertificateFromPublicKey(publicKey, "spiffe://other.org/web")
+	assert.Error(t, err)
+
+	mockCertManager.AssertExpectations(t)
+	mockPrivateKeyRepo.AssertExpectations(t)
+	mockCertRepo.AssertExpectations(t)
+}
+
+// newTestRootController creates a memory based organization with the key
+// retention policy and returns the controller of its root certificate
+func newTestRootController(t *testing.T, policy appmodels.KeyRetentionPolicy) (appmodels.CertificateController, *appmodels.Collection) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := memoryrepository.NewCollection()
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+	organization := appmodels.NewSerialNumber(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", policy))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	return rootController, repository
+}
+
+func TestCertificateController_NewIntermediateCertificate_StoresPrivateKey(t *testing.T) {
+	rootController, repository := newTestRootController(t, appmodels.NIL_KEY_RETENTION_POLICY)
+
+	intermediate, _, err := rootController.NewIntermediateCertificate("Test Intermediate")
+	require.NoError(t, err)
+
+	_, err = repository.PrivateKey.FindByOrganizationAndSerialNumber(intermediate.OrganizationID(), intermediate.SerialNumber())
+	require.NoError(t, err)
+
+	intermediateController, err := rootController.ChildCertificateController(intermediate.SerialNumber())
+	require.NoError(t, err)
+	leaf, _, err := intermediateController.NewServerCertificate("www.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, intermediate.SerialNumber(), leaf.SignedBy())
+}
+
+func TestCertificateController_LeafKeyRetentionPolicy(t *testing.T) {
+	tests := []struct {
+		policy   appmodels.KeyRetentionPolicy
+		stored   bool
+		released bool
+	}{
+		{appmodels.NIL_KEY_RETENTION_POLICY, false, false},
+		{appmodels.KEY_RETENTION_NONE, false, false},
+		{appmodels.KEY_RETENTION_ESCROW, true, false},
+		{appmodels.KEY_RETENTION_RETAIN, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			rootController, repository := newTestRootController(t, tt.policy)
+
+			leaf, _, err := rootController.NewClientCertificate("client")
+			require.NoError(t, err)
+
+			_, err = repository.PrivateKey.FindByOrganizationAndSerialNumber(leaf.OrganizationID(), leaf.SerialNumber())
+			assert.Equal(t, tt.stored, err == nil)
+
+			leafController, err := rootController.ChildCertificateController(leaf.SerialNumber())
+			require.NoError(t, err)
+			_, err = leafController.PrivateKey()
+			assert.Equal(t, tt.released, err == nil)
+		})
+	}
+}
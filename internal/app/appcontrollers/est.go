@@ -0,0 +1,303 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers
+
+import (
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// CertEstController implements appmodels.EstController. Errors which should
+// be reported to the EST client are returned as *appmodels.EstError.
+type CertEstController struct {
+	labelRepository appmodels.EstLabelRepository
+
+	// revocationRepository is optional. Revoked client certificates are
+	// rejected when it is set.
+	revocationRepository appmodels.CertificateRevocationRepository
+
+	appController appmodels.ApplicationController
+
+	// expiration is the expiration duration of issued certificates
+	expiration time.Duration
+}
+
+func (r *CertEstController) Label(label string) (appmodels.EstLabel, error) {
+	model, err := r.labelRepository.FindByLabel(label)
+	if err != nil {
+		return nil, appmodels.NewEstError(appmodels.EST_ERROR_NOT_FOUND, "label not found: %s", label)
+	}
+	return model, nil
+}
+
+func (r *CertEstController) NewLabel(issuer appmodels.CertificateController, label, username, password string) (appmodels.EstLabel, error) {
+
+	if err := apputils.ValidateEstLabel(label); err != nil {
+		return nil, appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "label: %v", err)
+	}
+
+	if !issuer.Certificate().IsCA() {
+		return nil, appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "issuer is not a CA certificate")
+	}
+
+	if _, err := r.labelRepository.FindByLabel(label); err == nil {
+		return nil, appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "label already exists: %s", label)
+	}
+
+	var passwordHash []byte
+	if username != "" {
+		if password == "" {
+			return nil, appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "password: must be defined")
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, appmodels.NewEstError(appmodels.EST_ERROR_SERVER_INTERNAL, "[NewLabel]: failed to hash password: %v", err)
+		}
+		passwordHash = hash
+	}
+
+	saved, err := r.labelRepository.Save(appmodels.NewEstLabel(
+		label,
+		issuer.OrganizationID(),
+		issuer.Certificate().SerialNumber(),
+		username,
+		passwordHash,
+	))
+	if err != nil {
+		return nil, appmodels.NewEstError(appmodels.EST_ERROR_SERVER_INTERNAL, "[NewLabel]: failed to save: %v", err)
+	}
+	return saved, nil
+}
+
+func (r *CertEstController) Issuer(label appmodels.EstLabel) (appmodels.CertificateController, error) {
+	organizationController, err := r.appController.OrganizationController(label.OrganizationID())
+	if err != nil {
+		return nil, appmodels.NewEstError(appmodels.EST_ERROR_NOT_FOUND, "[Issuer]: organization: %v", err)
+	}
+	issuer, err := findCertificateController(organizationController, label.IssuerSerialNumber())
+	if err != nil {
+		return nil, appmodels.NewEstError(appmodels.EST_ERROR_NOT_FOUND, "[Issuer]: %v", err)
+	}
+	return issuer, nil
+}
+
+func (r *CertEstController) Authenticate(label appmodels.EstLabel, username, password string) error {
+	if label.Username() == "" || len(label.PasswordHash()) == 0 {
+		return appmodels.NewEstError(appmodels.EST_ERROR_UNAUTHORIZED, "basic authentication is not enabled")
+	}
+	usernameMatches := subtle.ConstantTimeCompare([]byte(label.Username()), []byte(username)) == 1
+	if bcrypt.CompareHashAndPassword(label.PasswordHash(), []byte(password)) != nil || !usernameMatches {
+		return appmodels.NewEstError(appmodels.EST_ERROR_UNAUTHORIZED, "invalid username or password")
+	}
+	return nil
+}
+
+func (r *CertEstController) VerifyClientCertificate(issuer appmodels.CertificateController, certificate *x509.Certificate) error {
+	if certificate == nil {
+		return appmodels.NewEstError(appmodels.EST_ERROR_UNAUTHORIZED, "client certificate must be defined")
+	}
+	if err := certificate.CheckSignatureFrom(issuer.Certificate().Certificate()); err != nil {
+		return appmodels.NewEstError(appmodels.EST_ERROR_UNAUTHORIZED, "client certificate was not issued by the CA: %v", err)
+	}
+	now := time.Now()
+	if now.Before(certificate.NotBefore) || now.After(certificate.NotAfter) {
+		return appmodels.NewEstError(appmodels.EST_ERROR_UNAUTHORIZED, "client certificate is not valid now")
+	}
+
+	// Server, RA and other certificates of the CA do not authenticate
+	// clients
+	if !slices.Contains(certificate.ExtKeyUsage, x509.ExtKeyUsageClientAuth) {
+		return appmodels.NewEstError(appmodels.EST_ERROR_UNAUTHORIZED, "client certificate is not for client authentication")
+	}
+	if r.revocationRepository != nil {
+		if _, err := r.revocationRepository.FindByOrganizationAndSerialNumber(issuer.OrganizationID(), certificate.SerialNumber); err == nil {
+			return appmodels.NewEstError(appmodels.EST_ERROR_UNAUTHORIZED, "client certificate is revoked")
+		}
+	}
+	return nil
+}
+
+func (r *CertEstController) CACertificates(issuer appmodels.CertificateController) []appmodels.Certificate {
+	var list []appmodels.Certificate
+	for current := issuer; current != nil; current = current.ParentCertificateController() {
+		list = append(list, current.Certificate())
+	}
+	return list
+}
+
+func (r *CertEstController) CsrAttributes(issuer appmodels.CertificateController) []asn1.ObjectIdentifier {
+	oid := apputils.SignatureAlgorithmOID(issuer.Certificate().Certificate().SignatureAlgorithm)
+	if oid == nil {
+		return nil
+	}
+	return []asn1.ObjectIdentifier{oid}
+}
+
+func (r *CertEstController) Enroll(issuer appmodels.CertificateController, csr *x509.CertificateRequest) (appmodels.Certificate, error) {
+
+	if csr == nil {
+		return nil, appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "csr must be defined")
+	}
+
+	publicKey := appmodels.NewPublicKey(csr.PublicKey)
+	issuer.SetExpirationDuration(r.expiration)
+
+	if len(csr.DNSNames) != 0 {
+		dnsNames := estDNSNames(csr)
+		if err := apputils.ValidateDNSNames(dnsNames); err != nil {
+			return nil, appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "dns names: %v", err)
+		}
+		certificate, err := issuer.NewServerCertificateFromPublicKey(publicKey, dnsNames...)
+		if errors.Is(err, appmodels.ErrSealed) {
+			return nil, fmt.Errorf("[Enroll]: %w", err)
+		} else if err != nil {
+			return nil, appmodels.NewEstError(appmodels.EST_ERROR_SERVER_INTERNAL, "[Enroll]: failed to issue certificate: %v", err)
+		}
+		return certificate, nil
+	}
+
+	commonName := csr.Subject.CommonName
+	if err := apputils.ValidateClientCertificateCommonName(commonName); err != nil {
+		return nil, appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "common name: %v", err)
+	}
+	certificate, err := issuer.NewClientCertificateFromPublicKey(publicKey, commonName)
+	if errors.Is(err, appmodels.ErrSealed) {
+		return nil, fmt.Errorf("[Enroll]: %w", err)
+	} else if err != nil {
+		return nil, appmodels.NewEstError(appmodels.EST_ERROR_SERVER_INTERNAL, "[Enroll]: failed to issue certificate: %v", err)
+	}
+	return certificate, nil
+}
+
+func (r *CertEstController) Reenroll(issuer appmodels.CertificateController, current *x509.Certificate, csr *x509.CertificateRequest) (appmodels.Certificate, error) {
+
+	if csr == nil {
+		return nil, appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "csr must be defined")
+	}
+
+	// Clients authenticated with HTTP basic authentication are handled like
+	// enrollments
+	if current != nil {
+
+		if err := r.VerifyClientCertificate(issuer, current); err != nil {
+			return nil, err
+		}
+
+		// RFC 7030 section 4.2.2: the subject and subject alternative names
+		// must be the same as in the current certificate. Only the common
+		// name is compared since the organization is set by the CA.
+		if csr.Subject.CommonName != current.Subject.CommonName {
+			return nil, appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "subject does not match the current certificate")
+		}
+		if !slices.Equal(estSortedNames(csr.DNSNames), estSortedNames(current.DNSNames)) {
+			return nil, appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "subject alternative names do not match the current certificate")
+		}
+	}
+
+	return r.Enroll(issuer, csr)
+}
+
+// findCertificateController returns the controller of a root or an
+// intermediate certificate of the organization
+func findCertificateController(organizationController appmodels.OrganizationController, serialNumber *big.Int) (appmodels.CertificateController, error) {
+	if controller, err := organizationController.CertificateController(serialNumber); err == nil {
+		return controller, nil
+	}
+	roots, err := organizationController.CertificateCollection()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch root certificates: %w", err)
+	}
+	for _, root := range roots {
+		rootController, err := organizationController.CertificateController(root.SerialNumber())
+		if err != nil {
+			continue
+		}
+		if controller, err := findChildCertificateController(rootController, serialNumber); err == nil {
+			return controller, nil
+		}
+	}
+	return nil, fmt.Errorf("certificate not found: %s", serialNumber)
+}
+
+// findChildCertificateController searches the intermediate certificates
+// under the certificate controller
+func findChildCertificateController(parent appmodels.CertificateController, serialNumber *big.Int) (appmodels.CertificateController, error) {
+	children, err := parent.ChildCertificateCollection("intermediate")
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		controller, err := parent.ChildCertificateController(child.SerialNumber())
+		if err != nil {
+			continue
+		}
+		if child.SerialNumber().Cmp(serialNumber) == 0 {
+			return controller, nil
+		}
+		if found, err := findChildCertificateController(controller, serialNumber); err == nil {
+			return found, nil
+		}
+	}
+	return nil, fmt.Errorf("certificate not found: %s", serialNumber)
+}
+
+// estDNSNames returns the DNS names of the request with the common name
+// first when it is one of them
+func estDNSNames(csr *x509.CertificateRequest) []string {
+	names := make([]string, 0, len(csr.DNSNames))
+	commonName := strings.ToLower(csr.Subject.CommonName)
+	for _, name := range csr.DNSNames {
+		name = strings.ToLower(name)
+		if slices.Contains(names, name) {
+			continue
+		}
+		if name == commonName {
+			names = append([]string{name}, names...)
+		} else {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func estSortedNames(names []string) []string {
+	list := make([]string, len(names))
+	for i, name := range names {
+		list[i] = strings.ToLower(name)
+	}
+	slices.Sort(list)
+	return slices.Compact(list)
+}
+
+// NewEstController creates an EST controller
+//   - labelRepository: The EST label repository
+//   - revocationRepository: The certificate revocation repository, or nil
+//   - appController: The application controller used to find issuers
+//   - expiration: The expiration duration of issued certificates
+func NewEstController(
+	labelRepository appmodels.EstLabelRepository,
+	revocationRepository appmodels.CertificateRevocationRepository,
+	appController appmodels.ApplicationController,
+	expiration time.Duration,
+) *CertEstController {
+	return &CertEstController{
+		labelRepository:      labelRepository,
+		revocationRepository: revocationRepository,
+		appController:        appController,
+		expiration:           expiration,
+	}
+}
+
+var _ appmodels.EstController = (*CertEstController)(nil)
@@ -0,0 +1,234 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func requireEstErrorType(t *testing.T, err error, errorType appmodels.EstErrorType) {
+	var estErr *appmodels.EstError
+	require.ErrorAs(t, err, &estErr)
+	assert.Equal(t, errorType, estErr.Type())
+}
+
+// newTestEstIssuer creates a mock issuer backed by a real self-signed CA
+func newTestEstIssuer(t *testing.T) (*appmocks.MockCertificateController, *x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	issuerCert := new(appmocks.MockCertificate)
+	issuerCert.On("SerialNumber").Return(big.NewInt(2))
+	issuerCert.On("IsCA").Return(true)
+	issuerCert.On("Certificate").Return(caCert)
+
+	issuer := new(appmocks.MockCertificateController)
+	issuer.On("OrganizationID").Return(big.NewInt(1))
+	issuer.On("Certificate").Return(issuerCert)
+	issuer.On("ParentCertificateController").Return(nil)
+	return issuer, caCert, key
+}
+
+func newTestEstCertificate(t *testing.T, caCert *x509.Certificate, caKey *ecdsa.PrivateKey, commonName string, dnsNames []string, extKeyUsage x509.ExtKeyUsage) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{extKeyUsage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return certificate
+}
+
+func newTestEstCsr(t *testing.T, commonName string, dnsNames []string) *x509.CertificateRequest {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: commonName},
+		DNSNames: dnsNames,
+	}, key)
+	require.NoError(t, err)
+	csr, err := x509.ParseCertificateRequest(der)
+	require.NoError(t, err)
+	return csr
+}
+
+func TestCertEstController_NewLabel(t *testing.T) {
+	controller := appcontrollers.NewEstController(memoryrepository.NewEstLabelRepository(), nil, nil, time.Hour)
+	issuer, _, _ := newTestEstIssuer(t)
+
+	label, err := controller.NewLabel(issuer, "devices", "user", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), label.IssuerSerialNumber())
+
+	_, err = controller.NewLabel(issuer, "devices", "", "")
+	requireEstErrorType(t, err, appmodels.EST_ERROR_BAD_REQUEST)
+
+	_, err = controller.NewLabel(issuer, "Bad Label", "", "")
+	requireEstErrorType(t, err, appmodels.EST_ERROR_BAD_REQUEST)
+
+	_, err = controller.NewLabel(issuer, "other", "user", "")
+	requireEstErrorType(t, err, appmodels.EST_ERROR_BAD_REQUEST)
+
+	found, err := controller.Label("devices")
+	require.NoError(t, err)
+	assert.Equal(t, label, found)
+
+	_, err = controller.Label("missing")
+	requireEstErrorType(t, err, appmodels.EST_ERROR_NOT_FOUND)
+
+	assert.NoError(t, controller.Authenticate(label, "user", "secret"))
+	requireEstErrorType(t, controller.Authenticate(label, "user", "wrong"), appmodels.EST_ERROR_UNAUTHORIZED)
+	requireEstErrorType(t, controller.Authenticate(label, "other", "secret"), appmodels.EST_ERROR_UNAUTHORIZED)
+
+	certOnly, err := controller.NewLabel(issuer, "certs", "", "")
+	require.NoError(t, err)
+	requireEstErrorType(t, controller.Authenticate(certOnly, "", ""), appmodels.EST_ERROR_UNAUTHORIZED)
+}
+
+func TestCertEstController_Issuer(t *testing.T) {
+	issuer := new(appmocks.MockCertificateController)
+	orgController := new(appmocks.MockOrganizationController)
+	orgController.On("CertificateController", big.NewInt(2)).Return(issuer, nil)
+	appController := new(appmocks.MockApplicationController)
+	appController.On("OrganizationController", big.NewInt(1)).Return(orgController, nil)
+	appController.On("OrganizationController", big.NewInt(3)).Return((*appmocks.MockOrganizationController)(nil), errors.New("not found"))
+
+	controller := appcontrollers.NewEstController(memoryrepository.NewEstLabelRepository(), nil, appController, time.Hour)
+
+	found, err := controller.Issuer(appmodels.NewEstLabel("devices", big.NewInt(1), big.NewInt(2), "", nil))
+	require.NoError(t, err)
+	assert.Equal(t, issuer, found)
+
+	_, err = controller.Issuer(appmodels.NewEstLabel("devices", big.NewInt(3), big.NewInt(2), "", nil))
+	requireEstErrorType(t, err, appmodels.EST_ERROR_NOT_FOUND)
+}
+
+func TestCertEstController_CACertificatesAndCsrAttributes(t *testing.T) {
+	controller := appcontrollers.NewEstController(memoryrepository.NewEstLabelRepository(), nil, nil, time.Hour)
+	issuer, _, _ := newTestEstIssuer(t)
+
+	list := controller.CACertificates(issuer)
+	require.Len(t, list, 1)
+	assert.Equal(t, big.NewInt(2), list[0].SerialNumber())
+
+	assert.Equal(t, []asn1.ObjectIdentifier{{1, 2, 840, 10045, 4, 3, 2}}, controller.CsrAttributes(issuer))
+}
+
+func TestCertEstController_Enroll(t *testing.T) {
+	controller := appcontrollers.NewEstController(memoryrepository.NewEstLabelRepository(), nil, nil, time.Hour)
+	issuer, _, _ := newTestEstIssuer(t)
+	leaf := new(appmocks.MockCertificate)
+	issuer.On("SetExpirationDuration", time.Hour).Return()
+	issuer.On("NewServerCertificateFromPublicKey", mock.Anything, []string{"www.example.com", "example.com"}).Return(leaf, nil)
+	issuer.On("NewClientCertificateFromPublicKey", mock.Anything, "device-1").Return(leaf, nil)
+
+	certificate, err := controller.Enroll(issuer, newTestEstCsr(t, "www.example.com", []string{"example.com", "www.example.com"}))
+	require.NoError(t, err)
+	assert.Equal(t, leaf, certificate)
+
+	certificate, err = controller.Enroll(issuer, newTestEstCsr(t, "device-1", nil))
+	require.NoError(t, err)
+	assert.Equal(t, leaf, certificate)
+
+	_, err = controller.Enroll(issuer, newTestEstCsr(t, "", nil))
+	requireEstErrorType(t, err, appmodels.EST_ERROR_BAD_REQUEST)
+
+	_, err = controller.Enroll(issuer, newTestEstCsr(t, "", []string{"bad_name"}))
+	requireEstErrorType(t, err, appmodels.EST_ERROR_BAD_REQUEST)
+
+	_, err = controller.Enroll(issuer, nil)
+	requireEstErrorType(t, err, appmodels.EST_ERROR_BAD_REQUEST)
+}
+
+func TestCertEstController_Reenroll(t *testing.T) {
+	controller := appcontrollers.NewEstController(memoryrepository.NewEstLabelRepository(), nil, nil, time.Hour)
+	issuer, caCert, caKey := newTestEstIssuer(t)
+	leaf := new(appmocks.MockCertificate)
+	issuer.On("SetExpirationDuration", time.Hour).Return()
+	issuer.On("NewClientCertificateFromPublicKey", mock.Anything, "device-1").Return(leaf, nil)
+
+	current := newTestEstCertificate(t, caCert, caKey, "device-1", nil, x509.ExtKeyUsageClientAuth)
+
+	certificate, err := controller.Reenroll(issuer, current, newTestEstCsr(t, "device-1", nil))
+	require.NoError(t, err)
+	assert.Equal(t, leaf, certificate)
+
+	_, err = controller.Reenroll(issuer, current, newTestEstCsr(t, "device-2", nil))
+	requireEstErrorType(t, err, appmodels.EST_ERROR_BAD_REQUEST)
+
+	_, err = controller.Reenroll(issuer, current, newTestEstCsr(t, "device-1", []string{"example.com"}))
+	requireEstErrorType(t, err, appmodels.EST_ERROR_BAD_REQUEST)
+
+	certificate, err = controller.Reenroll(issuer, nil, newTestEstCsr(t, "device-1", nil))
+	require.NoError(t, err)
+	assert.Equal(t, leaf, certificate)
+
+	_, otherCert, otherKey := newTestEstIssuer(t)
+	foreign := newTestEstCertificate(t, otherCert, otherKey, "device-1", nil, x509.ExtKeyUsageClientAuth)
+	_, err = controller.Reenroll(issuer, foreign, newTestEstCsr(t, "device-1", nil))
+	requireEstErrorType(t, err, appmodels.EST_ERROR_UNAUTHORIZED)
+}
+
+func TestCertEstController_VerifyClientCertificate(t *testing.T) {
+	revocationRepository := memoryrepository.NewCertificateRevocationRepository()
+	controller := appcontrollers.NewEstController(memoryrepository.NewEstLabelRepository(), revocationRepository, nil, time.Hour)
+	issuer, caCert, caKey := newTestEstIssuer(t)
+
+	current := newTestEstCertificate(t, caCert, caKey, "device-1", nil, x509.ExtKeyUsageClientAuth)
+	assert.NoError(t, controller.VerifyClientCertificate(issuer, current))
+
+	// Server certificates of the CA do not authenticate clients
+	server := newTestEstCertificate(t, caCert, caKey, "device-1", []string{"device-1.example.com"}, x509.ExtKeyUsageServerAuth)
+	requireEstErrorType(t, controller.VerifyClientCertificate(issuer, server), appmodels.EST_ERROR_UNAUTHORIZED)
+	_, err := controller.Reenroll(issuer, server, newTestEstCsr(t, "device-1", []string{"device-1.example.com"}))
+	requireEstErrorType(t, err, appmodels.EST_ERROR_UNAUTHORIZED)
+
+	_, err = revocationRepository.Save(appmodels.NewCertificateRevocation(
+		big.NewInt(1),
+		caCert.SerialNumber,
+		appmodels.NewRevokedCertificate(current.SerialNumber, time.Now(), current.NotAfter),
+	))
+	require.NoError(t, err)
+	requireEstErrorType(t, controller.VerifyClientCertificate(issuer, current), appmodels.EST_ERROR_UNAUTHORIZED)
+	_, err = controller.Reenroll(issuer, current, newTestEstCsr(t, "device-1", nil))
+	requireEstErrorType(t, err, appmodels.EST_ERROR_UNAUTHORIZED)
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// CertLifecycleController implements appmodels.LifecycleController
+type CertLifecycleController struct {
+	organizationRepository appmodels.OrganizationRepository
+	certificateRepository  appmodels.CertificateRepository
+
+	// revocationRepository is optional. Certificates are never revoked
+	// without it.
+	revocationRepository appmodels.CertificateRevocationRepository
+
+	// retention is how long expired and superseded certificates are kept
+	// before they are archived
+	retention time.Duration
+}
+
+func (r *CertLifecycleController) Retention() time.Duration {
+	return r.retention
+}
+
+func (r *CertLifecycleController) CertificateStates(organization *big.Int, certificates []appmodels.Certificate, now time.Time) (map[string]appmodels.CertificateState, error) {
+
+	// Newer certificates which supersede these are among the current ones
+	current, err := r.certificateRepository.FindAllByOrganization(organization)
+	if err != nil {
+		return nil, fmt.Errorf("[%s:CertificateStates]: failed to find certificates: %w", organization, err)
+	}
+	all := append([]appmodels.Certificate(nil), current...)
+	seen := make(map[string]bool, len(current))
+	for _, certificate := range current {
+		seen[certificate.SerialNumber().String()] = true
+	}
+	for _, certificate := range certificates {
+		if !seen[certificate.SerialNumber().String()] {
+			all = append(all, certificate)
+		}
+	}
+
+	revoked, err := r.revokedSerialNumbers(organization, all)
+	if err != nil {
+		return nil, fmt.Errorf("[%s:CertificateStates]: %w", organization, err)
+	}
+	superseded := apputils.SupersededCertificates(all, revoked, now)
+
+	states := make(map[string]appmodels.CertificateState, len(certificates))
+	for _, certificate := range certificates {
+		serialNumber := certificate.SerialNumber().String()
+		_, isSuperseded := superseded[serialNumber]
+		states[serialNumber] = apputils.CertificateStateAt(certificate, now, revoked[serialNumber], isSuperseded)
+	}
+	return states, nil
+}
+
+func (r *CertLifecycleController) ArchiveCertificates(organization *big.Int, now time.Time) ([]appmodels.Certificate, error) {
+
+	list, err := r.certificateRepository.FindAllByOrganization(organization)
+	if err != nil {
+		return nil, fmt.Errorf("[%s:ArchiveCertificates]: failed to find certificates: %w", organization, err)
+	}
+	revoked, err := r.revokedSerialNumbers(organization, list)
+	if err != nil {
+		return nil, fmt.Errorf("[%s:ArchiveCertificates]: %w", organization, err)
+	}
+	superseded := apputils.SupersededCertificates(list, revoked, now)
+
+	// children counts the certificates each certificate has signed which
+	// are not archived
+	children := make(map[string]int)
+	var candidates []appmodels.Certificate
+	for _, certificate := range list {
+		if signedBy := certificate.SignedBy(); signedBy != nil && signedBy.Cmp(certificate.SerialNumber()) != 0 {
+			children[signedBy.String()]++
+		}
+		since, isSuperseded := superseded[certificate.SerialNumber().String()]
+		if now.After(certificate.NotAfter().Add(r.retention)) || (isSuperseded && now.After(since.Add(r.retention))) {
+			candidates = append(candidates, certificate)
+		}
+	}
+
+	// Archiving a certificate may allow archiving its issuer in the next
+	// round
+	archived := make([]appmodels.Certificate, 0)
+	for progress := true; progress; {
+		progress = false
+		remaining := candidates[:0]
+		for _, certificate := range candidates {
+			serialNumber := certificate.SerialNumber()
+			if children[serialNumber.String()] != 0 {
+				remaining = append(remaining, certificate)
+				continue
+			}
+			if err := r.certificateRepository.Archive(organization, serialNumber); err != nil {
+				return archived, fmt.Errorf("[%s:ArchiveCertificates]: failed to archive %s: %w", organization, serialNumber, err)
+			}
+			if signedBy := certificate.SignedBy(); signedBy != nil && signedBy.Cmp(serialNumber) != 0 {
+				children[signedBy.String()]--
+			}
+			archived = append(archived, certificate)
+			progress = true
+		}
+		candidates = remaining
+	}
+	return archived, nil
+}
+
+// ArchiveAll archives the certificates of every organization
+//   - now: The current time
+func (r *CertLifecycleController) ArchiveAll(now time.Time) error {
+	organizations, err := r.organizationRepository.FindAll()
+	if err != nil {
+		return fmt.Errorf("[ArchiveAll]: failed to find organizations: %w", err)
+	}
+	var errs []error
+	for _, organization := range organizations {
+		archived, err := r.ArchiveCertificates(organization.ID(), now)
+		if len(archived) != 0 {
+			log.Printf("[%s:ArchiveAll]: %d certificates archived", organization.ID(), len(archived))
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run archives certificates until the context is cancelled
+//   - ctx: The context
+//   - interval: How often certificates are checked
+func (r *CertLifecycleController) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := r.ArchiveAll(time.Now()); err != nil {
+			log.Printf("[Run]: %v", err)
+		}
+	}
+}
+
+// revokedSerialNumbers returns the serial numbers of revoked certificates
+// signed by the CA certificates of the list
+func (r *CertLifecycleController) revokedSerialNumbers(organization *big.Int, certificates []appmodels.Certificate) (map[string]bool, error) {
+	revoked := make(map[string]bool)
+	if r.revocationRepository == nil {
+		return revoked, nil
+	}
+	for _, certificate := range certificates {
+		if !certificate.IsCA() {
+			continue
+		}
+		revocations, err := r.revocationRepository.FindAllByOrganizationAndIssuer(organization, certificate.SerialNumber())
+		if err != nil {
+			return nil, fmt.Errorf("failed to find revocations: %w", err)
+		}
+		for _, revocation := range revocations {
+			revoked[revocation.RevokedCertificate().SerialNumber().String()] = true
+		}
+	}
+	return revoked, nil
+}
+
+// NewLifecycleController creates a controller for certificate lifecycle
+// states and archival
+//   - organizationRepository: The organization repository
+//   - certificateRepository: The certificate repository
+//   - revocationRepository: The certificate revocation repository, or nil
+//   - retention: How long expired and superseded certificates are kept
+//     before they are archived
+func NewLifecycleController(
+	organizationRepository appmodels.OrganizationRepository,
+	certificateRepository appmodels.CertificateRepository,
+	revocationRepository appmodels.CertificateRevocationRepository,
+	retention time.Duration,
+) *CertLifecycleController {
+	return &CertLifecycleController{
+		organizationRepository: organizationRepository,
+		certificateRepository:  certificateRepository,
+		revocationRepository:   revocationRepository,
+		retention:              retention,
+	}
+}
+
+var _ appmodels.LifecycleController = (*CertLifecycleController)(nil)
@@ -0,0 +1,121 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+// newTestLifecycleCertificate creates a certificate model with only the
+// fields the lifecycle controller reads
+func newTestLifecycleCertificate(serialNumber int64, signedBy *big.Int, commonName string, isCA bool, notBefore, notAfter time.Time) appmodels.Certificate {
+	issuer := pkix.Name{CommonName: "Root"}
+	if signedBy == nil {
+		issuer = pkix.Name{CommonName: commonName}
+	}
+	cert := &x509.Certificate{
+		SerialNumber:          big.NewInt(serialNumber),
+		Subject:               pkix.Name{CommonName: commonName},
+		Issuer:                issuer,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: isCA,
+	}
+	if !isCA {
+		cert.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+	return appmodels.NewCertificate(big.NewInt(10), signedBy, cert)
+}
+
+func TestCertLifecycleController(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	organization := big.NewInt(10)
+	rootSerial := big.NewInt(1)
+
+	root := newTestLifecycleCertificate(1, nil, "Root", true, now.Add(-100*day), now.Add(100*day))
+	expiredCA := newTestLifecycleCertificate(2, rootSerial, "Old CA", true, now.Add(-100*day), now.Add(-50*day))
+	expiredChild := newTestLifecycleCertificate(3, big.NewInt(2), "child.example.com", false, now.Add(-100*day), now.Add(-60*day))
+	replaced := newTestLifecycleCertificate(4, rootSerial, "www.example.com", false, now.Add(-40*day), now.Add(40*day))
+	replacement := newTestLifecycleCertificate(5, rootSerial, "www.example.com", false, now.Add(-35*day), now.Add(45*day))
+	recentlyExpired := newTestLifecycleCertificate(6, rootSerial, "api.example.com", false, now.Add(-20*day), now.Add(-day))
+	expiring := newTestLifecycleCertificate(7, rootSerial, "mail.example.com", false, now.Add(-20*day), now.Add(day))
+	pending := newTestLifecycleCertificate(8, rootSerial, "next.example.com", false, now.Add(day), now.Add(20*day))
+	revoked := newTestLifecycleCertificate(9, rootSerial, "bad.example.com", false, now.Add(-20*day), now.Add(20*day))
+
+	certificates := memoryrepository.NewCertificateRepository()
+	list := []appmodels.Certificate{root, expiredCA, expiredChild, replaced, replacement, recentlyExpired, expiring, pending, revoked}
+	for _, certificate := range list {
+		_, err := certificates.Save(certificate)
+		require.NoError(t, err)
+	}
+	revocations := memoryrepository.NewCertificateRevocationRepository()
+	_, err := revocations.Save(appmodels.NewCertificateRevocation(organization, rootSerial, appmodels.NewRevokedCertificate(revoked.SerialNumber(), now, revoked.NotAfter())))
+	require.NoError(t, err)
+
+	organizations := memoryrepository.NewOrganizationRepository()
+	_, err = organizations.Save(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+
+	controller := appcontrollers.NewLifecycleController(organizations, certificates, revocations, 30*day)
+	assert.Equal(t, 30*day, controller.Retention())
+
+	states, err := controller.CertificateStates(organization, list, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]appmodels.CertificateState{
+		"1": appmodels.CERTIFICATE_STATE_ACTIVE,
+		"2": appmodels.CERTIFICATE_STATE_EXPIRED,
+		"3": appmodels.CERTIFICATE_STATE_EXPIRED,
+		"4": appmodels.CERTIFICATE_STATE_SUPERSEDED,
+		"5": appmodels.CERTIFICATE_STATE_ACTIVE,
+		"6": appmodels.CERTIFICATE_STATE_EXPIRED,
+		"7": appmodels.CERTIFICATE_STATE_EXPIRING,
+		"8": appmodels.CERTIFICATE_STATE_PENDING,
+		"9": appmodels.CERTIFICATE_STATE_REVOKED,
+	}, states)
+
+	// The expired CA is archived after its child, and the certificate
+	// which expired a day ago is kept
+	archived, err := controller.ArchiveCertificates(organization, now)
+	require.NoError(t, err)
+	var serialNumbers []string
+	for _, certificate := range archived {
+		serialNumbers = append(serialNumbers, certificate.SerialNumber().String())
+	}
+	assert.Equal(t, []string{"3", "4", "2"}, serialNumbers)
+
+	current, err := certificates.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Len(t, current, 6)
+	page, err := certificates.FindPageByOrganization(organization, appmodels.CertificateQuery{Archived: true})
+	require.NoError(t, err)
+	assert.Len(t, page.Certificates, 3)
+
+	// States of archived certificates are still derived
+	states, err = controller.CertificateStates(organization, page.Certificates, now)
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.CERTIFICATE_STATE_SUPERSEDED, states["4"])
+
+	// Nothing is left to archive
+	require.NoError(t, controller.ArchiveAll(now))
+	current, err = certificates.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Len(t, current, 6)
+
+	// Without revocations nothing is revoked
+	states, err = appcontrollers.NewLifecycleController(organizations, certificates, nil, 30*day).CertificateStates(organization, []appmodels.Certificate{revoked}, now)
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.CERTIFICATE_STATE_ACTIVE, states["9"])
+}
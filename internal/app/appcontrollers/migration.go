@@ -0,0 +1,831 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"reflect"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// CertMigrationController implements appmodels.MigrationController
+type CertMigrationController struct {
+	source *appmodels.Collection
+	target *appmodels.Collection
+
+	certManager managers.CertificateManager
+}
+
+// migrationCounts counts the items which were copied and skipped
+type migrationCounts struct {
+	organizations      int
+	certificates       int
+	privateKeys        int
+	revocations        int
+	revocationLists    int
+	sshAuthorities     int
+	sshCertificates    int
+	archived           int
+	operations         int
+	estLabels          int
+	scepChallenges     int
+	vaultRoles         int
+	acmeAccounts       int
+	acmeOrders         int
+	acmeAuthorizations int
+	skipped            int
+}
+
+func (r *CertMigrationController) Migrate() error {
+
+	organizations, err := r.source.Organization.FindAll()
+	if err != nil {
+		return fmt.Errorf("[Migrate]: failed to find organizations: %w", err)
+	}
+
+	var counts migrationCounts
+	for _, organization := range organizations {
+		if err := r.migrateOrganization(organization, &counts); err != nil {
+			return fmt.Errorf("[%s:Migrate]: %w", organization.ID(), err)
+		}
+	}
+	if err := r.migrateOperations(&counts); err != nil {
+		return fmt.Errorf("[Migrate]: %w", err)
+	}
+
+	log.Printf(
+		"[Migrate]: %d organizations, %d certificates, %d private keys, %d revocations, %d CRL numbers, %d SSH CAs, %d SSH certificates, %d approval operations, %d EST labels, %d SCEP challenges, %d Vault roles, %d ACME accounts, %d ACME orders and %d ACME authorizations copied, %d certificates archived, %d already migrated",
+		counts.organizations,
+		counts.certificates,
+		counts.privateKeys,
+		counts.revocations,
+		counts.revocationLists,
+		counts.sshAuthorities,
+		counts.sshCertificates,
+		counts.operations,
+		counts.estLabels,
+		counts.scepChallenges,
+		counts.vaultRoles,
+		counts.acmeAccounts,
+		counts.acmeOrders,
+		counts.acmeAuthorizations,
+		counts.archived,
+		counts.skipped,
+	)
+	return nil
+}
+
+func (r *CertMigrationController) Verify() error {
+
+	organizations, err := r.source.Organization.FindAll()
+	if err != nil {
+		return fmt.Errorf("[Verify]: failed to find organizations: %w", err)
+	}
+
+	var errs []error
+	certificates := 0
+	for _, organization := range organizations {
+		count, err := r.verifyOrganization(organization)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("[%s:Verify]: %w", organization.ID(), err))
+		}
+		certificates += count
+	}
+	if err := r.verifyOperations(); err != nil {
+		errs = append(errs, fmt.Errorf("[Verify]: %w", err))
+	}
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+
+	log.Printf("[Verify]: %d organizations and %d certificates match", len(organizations), certificates)
+	return nil
+}
+
+// migrateOperations copies the operations of the approvals, including the
+// executed ones for auditing. Operations which exist in the target are not
+// changed.
+func (r *CertMigrationController) migrateOperations(counts *migrationCounts) error {
+	if r.source.PendingOperation == nil || r.target.PendingOperation == nil {
+		return nil
+	}
+	operations, err := r.source.PendingOperation.FindAll()
+	if err != nil {
+		return fmt.Errorf("failed to find operations: %w", err)
+	}
+	for _, operation := range operations {
+		if _, err := r.target.PendingOperation.FindById(operation.ID()); err == nil {
+			counts.skipped++
+			continue
+		}
+		if _, err := r.target.PendingOperation.Save(operation); err != nil {
+			return fmt.Errorf("[%s]: failed to save operation: %w", operation.ID(), err)
+		}
+		counts.operations++
+	}
+	return nil
+}
+
+// verifyOperations checks that the operations of the approvals exist in the
+// target
+func (r *CertMigrationController) verifyOperations() error {
+	if r.source.PendingOperation == nil || r.target.PendingOperation == nil {
+		return nil
+	}
+	operations, err := r.source.PendingOperation.FindAll()
+	if err != nil {
+		return fmt.Errorf("failed to find operations: %w", err)
+	}
+	for _, operation := range operations {
+		if _, err := r.target.PendingOperation.FindById(operation.ID()); err != nil {
+			return fmt.Errorf("[%s]: operation is missing", operation.ID())
+		}
+	}
+	return nil
+}
+
+// migrateOrganization copies an organization and its certificates, private
+// keys, revocations, SSH certificate authority and the state of the EST,
+// SCEP, Vault and ACME servers
+func (r *CertMigrationController) migrateOrganization(organization appmodels.Organization, counts *migrationCounts) error {
+
+	id := organization.ID()
+	existing, err := r.target.Organization.FindById(id)
+	if err == nil {
+		if !reflect.DeepEqual(apputils.ToOrganizationDTO(existing), apputils.ToOrganizationDTO(organization)) {
+			return fmt.Errorf("target has a different organization with the same ID")
+		}
+		counts.skipped++
+	} else {
+		if _, err := r.target.Organization.Save(organization); err != nil {
+			return fmt.Errorf("failed to save organization: %w", err)
+		}
+		counts.organizations++
+	}
+
+	certificates, err := r.source.Certificate.FindAllByOrganization(id)
+	if err != nil {
+		return fmt.Errorf("failed to find certificates: %w", err)
+	}
+	for _, certificate := range sortCertificatesByIssuer(certificates) {
+		if err := r.migrateCertificate(certificate, counts); err != nil {
+			return fmt.Errorf("certificate %s: %w", certificate.SerialNumber(), err)
+		}
+	}
+
+	if err := r.migrateArchivedCertificates(id, counts); err != nil {
+		return err
+	}
+
+	for _, certificate := range certificates {
+		if !certificate.IsCA() {
+			continue
+		}
+		if err := r.migrateRevocations(id, certificate.SerialNumber(), counts); err != nil {
+			return err
+		}
+	}
+
+	if err := r.migrateSsh(id, counts); err != nil {
+		return err
+	}
+	if err := r.migrateProtocols(id, counts); err != nil {
+		return err
+	}
+	return r.migrateAcme(id, counts)
+}
+
+// migrateProtocols copies the EST labels, the SCEP challenge password and
+// the Vault roles of an organization. Items which exist in the target are
+// not changed.
+func (r *CertMigrationController) migrateProtocols(organization *big.Int, counts *migrationCounts) error {
+
+	if r.source.EstLabel != nil && r.target.EstLabel != nil {
+		labels, err := r.source.EstLabel.FindAllByOrganization(organization)
+		if err != nil {
+			return fmt.Errorf("failed to find EST labels: %w", err)
+		}
+		for _, label := range labels {
+			if _, err := r.target.EstLabel.FindByLabel(label.Label()); err == nil {
+				counts.skipped++
+				continue
+			}
+			if _, err := r.target.EstLabel.Save(label); err != nil {
+				return fmt.Errorf("failed to save EST label %s: %w", label.Label(), err)
+			}
+			counts.estLabels++
+		}
+	}
+
+	if r.source.ScepChallenge != nil && r.target.ScepChallenge != nil {
+		if challenge, err := r.source.ScepChallenge.FindByOrganization(organization); err == nil {
+			if _, err := r.target.ScepChallenge.FindByOrganization(organization); err == nil {
+				counts.skipped++
+			} else {
+				if _, err := r.target.ScepChallenge.Save(challenge); err != nil {
+					return fmt.Errorf("failed to save SCEP challenge: %w", err)
+				}
+				counts.scepChallenges++
+			}
+		}
+	}
+
+	if r.source.VaultRole != nil && r.target.VaultRole != nil {
+		roles, err := r.source.VaultRole.FindAllByOrganization(organization)
+		if err != nil {
+			return fmt.Errorf("failed to find Vault roles: %w", err)
+		}
+		for _, role := range roles {
+			if _, err := r.target.VaultRole.FindByOrganizationAndName(organization, role.Name()); err == nil {
+				counts.skipped++
+				continue
+			}
+			if _, err := r.target.VaultRole.Save(role); err != nil {
+				return fmt.Errorf("failed to save Vault role %s: %w", role.Name(), err)
+			}
+			counts.vaultRoles++
+		}
+	}
+	return nil
+}
+
+// migrateAcme copies the ACME accounts of an organization with their orders
+// and the authorizations of the orders. Items which exist in the target are
+// not changed.
+func (r *CertMigrationController) migrateAcme(organization *big.Int, counts *migrationCounts) error {
+	if r.source.AcmeAccount == nil || r.target.AcmeAccount == nil {
+		return nil
+	}
+	accounts, err := r.source.AcmeAccount.FindAllByOrganization(organization)
+	if err != nil {
+		return fmt.Errorf("failed to find ACME accounts: %w", err)
+	}
+	for _, account := range accounts {
+		if _, err := r.target.AcmeAccount.FindById(account.ID()); err == nil {
+			counts.skipped++
+		} else {
+			if _, err := r.target.AcmeAccount.Save(account); err != nil {
+				return fmt.Errorf("failed to save ACME account %s: %w", account.ID(), err)
+			}
+			counts.acmeAccounts++
+		}
+
+		orders, err := r.source.AcmeOrder.FindAllByAccount(account.ID())
+		if err != nil {
+			return fmt.Errorf("failed to find ACME orders of %s: %w", account.ID(), err)
+		}
+		for _, order := range orders {
+			if _, err := r.target.AcmeOrder.FindById(order.ID()); err == nil {
+				counts.skipped++
+			} else {
+				if _, err := r.target.AcmeOrder.Save(order); err != nil {
+					return fmt.Errorf("failed to save ACME order %s: %w", order.ID(), err)
+				}
+				counts.acmeOrders++
+			}
+			for _, id := range order.AuthorizationIDs() {
+				authorization, err := r.source.AcmeAuthorization.FindById(id)
+				if err != nil {
+					return fmt.Errorf("failed to find ACME authorization %s: %w", id, err)
+				}
+				if _, err := r.target.AcmeAuthorization.FindById(id); err == nil {
+					counts.skipped++
+					continue
+				}
+				if _, err := r.target.AcmeAuthorization.Save(authorization); err != nil {
+					return fmt.Errorf("failed to save ACME authorization %s: %w", id, err)
+				}
+				counts.acmeAuthorizations++
+			}
+		}
+	}
+	return nil
+}
+
+// migrateRevocations copies the revocations of certificates issued by a CA
+// certificate and the number of its last CRL
+func (r *CertMigrationController) migrateRevocations(organization, issuer *big.Int, counts *migrationCounts) error {
+
+	revocations, err := r.source.CertificateRevocation.FindAllByOrganizationAndIssuer(organization, issuer)
+	if err != nil {
+		return fmt.Errorf("failed to find revocations of %s: %w", issuer, err)
+	}
+	for _, revocation := range revocations {
+		serialNumber := revocation.RevokedCertificate().SerialNumber()
+		if _, err := r.target.CertificateRevocation.FindByOrganizationAndSerialNumber(organization, serialNumber); err == nil {
+			counts.skipped++
+			continue
+		}
+		if _, err := r.target.CertificateRevocation.Save(revocation); err != nil {
+			return fmt.Errorf("failed to save revocation of %s: %w", serialNumber, err)
+		}
+		counts.revocations++
+	}
+
+	// CRL numbers must not decrease, so a higher number in the target is kept
+	revocationList, err := r.source.CertificateRevocation.FindRevocationList(organization, issuer)
+	if err != nil {
+		return nil
+	}
+	existing, err := r.target.CertificateRevocation.FindRevocationList(organization, issuer)
+	if err == nil && existing.Number().Cmp(revocationList.Number()) >= 0 {
+		counts.skipped++
+		return nil
+	}
+	if _, err := r.target.CertificateRevocation.SaveRevocationList(revocationList); err != nil {
+		return fmt.Errorf("failed to save CRL number of %s: %w", issuer, err)
+	}
+	counts.revocationLists++
+	return nil
+}
+
+// migrateSsh copies the SSH certificate authority of an organization and the
+// SSH certificates it has signed
+func (r *CertMigrationController) migrateSsh(organization *big.Int, counts *migrationCounts) error {
+
+	authority, err := r.source.SshAuthority.FindByOrganization(organization)
+	if err != nil {
+		return nil
+	}
+	existing, err := r.target.SshAuthority.FindByOrganization(organization)
+	if err == nil {
+		matches, err := r.sshAuthoritiesMatch(existing, authority)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			return fmt.Errorf("target has a different SSH certificate authority")
+		}
+		counts.skipped++
+	} else {
+		if _, err := r.target.SshAuthority.Save(authority); err != nil {
+			return fmt.Errorf("failed to save SSH certificate authority: %w", err)
+		}
+		counts.sshAuthorities++
+	}
+
+	certificates, err := r.source.SshCertificate.FindAllByOrganization(organization)
+	if err != nil {
+		return fmt.Errorf("failed to find SSH certificates: %w", err)
+	}
+	for _, certificate := range certificates {
+		existing, err := r.target.SshCertificate.FindByOrganizationAndSerial(organization, certificate.Serial())
+		if err == nil {
+			if !bytes.Equal(existing.Certificate().Marshal(), certificate.Certificate().Marshal()) {
+				return fmt.Errorf("SSH certificate %d: target has a different certificate with the same serial number", certificate.Serial())
+			}
+			// A certificate revoked after a previous run is saved again
+			if existing.IsRevoked() || !certificate.IsRevoked() {
+				counts.skipped++
+				continue
+			}
+		}
+		if _, err := r.target.SshCertificate.Save(certificate); err != nil {
+			return fmt.Errorf("failed to save SSH certificate %d: %w", certificate.Serial(), err)
+		}
+		counts.sshCertificates++
+	}
+	return nil
+}
+
+func (r *CertMigrationController) sshAuthoritiesMatch(a, b appmodels.SshAuthority) (bool, error) {
+	if a.ID().Cmp(b.ID()) != 0 {
+		return false, nil
+	}
+	return r.privateKeysMatch(a.PrivateKey(), b.PrivateKey())
+}
+
+// migrateCertificate copies a certificate and its private key in the same
+// unit of work
+func (r *CertMigrationController) migrateCertificate(certificate appmodels.Certificate, counts *migrationCounts) error {
+
+	organization := certificate.OrganizationID()
+	serialNumber := certificate.SerialNumber()
+
+	// Certificates without a stored private key are copied alone
+	privateKey, err := r.source.PrivateKey.FindByOrganizationAndSerialNumber(organization, serialNumber)
+	if err != nil {
+		privateKey = nil
+	}
+
+	saveCertificate := true
+	existing, err := r.target.Certificate.FindByOrganizationAndSerialNumber(organization, serialNumber)
+	if err == nil {
+		if apputils.CertificateFingerprint(existing) != apputils.CertificateFingerprint(certificate) {
+			return fmt.Errorf("target has a different certificate with the same serial number")
+		}
+		saveCertificate = false
+	}
+
+	savePrivateKey := privateKey != nil
+	if privateKey != nil {
+		existingKey, err := r.target.PrivateKey.FindByOrganizationAndSerialNumber(organization, serialNumber)
+		if err == nil {
+			matches, err := r.privateKeysMatch(existingKey, privateKey)
+			if err != nil {
+				return err
+			}
+			if !matches {
+				return fmt.Errorf("target has a different private key for the certificate")
+			}
+			savePrivateKey = false
+		}
+	}
+
+	if !saveCertificate && !savePrivateKey {
+		counts.skipped++
+		return nil
+	}
+	work := r.target.UnitOfWork.NewUnitOfWork()
+	if savePrivateKey {
+		work.SavePrivateKey(privateKey)
+	}
+	if saveCertificate {
+		work.SaveCertificate(certificate)
+	}
+	if err := work.Commit(); err != nil {
+		return fmt.Errorf("failed to save: %w", err)
+	}
+	if savePrivateKey {
+		counts.privateKeys++
+	}
+	if saveCertificate {
+		counts.certificates++
+	}
+	return nil
+}
+
+// migrateArchivedCertificates copies the archived certificates of an
+// organization and archives them in the target. Certificates are archived
+// only after the certificates they have signed.
+func (r *CertMigrationController) migrateArchivedCertificates(organization *big.Int, counts *migrationCounts) error {
+
+	archived, err := r.archivedFingerprints(r.target.Certificate, organization)
+	if err != nil {
+		return fmt.Errorf("failed to find target archive: %w", err)
+	}
+
+	page, err := r.source.Certificate.FindPageByOrganization(organization, appmodels.CertificateQuery{Archived: true})
+	if err != nil {
+		return fmt.Errorf("failed to find archived certificates: %w", err)
+	}
+
+	var saved []appmodels.Certificate
+	for _, certificate := range sortCertificatesByIssuer(page.Certificates) {
+		fingerprint, exists := archived[certificate.SerialNumber().String()]
+		if exists {
+			if fingerprint != apputils.CertificateFingerprint(certificate) {
+				return fmt.Errorf("archived certificate %s: target has a different certificate with the same serial number", certificate.SerialNumber())
+			}
+			counts.skipped++
+			continue
+		}
+		if err := r.migrateCertificate(certificate, counts); err != nil {
+			return fmt.Errorf("archived certificate %s: %w", certificate.SerialNumber(), err)
+		}
+		saved = append(saved, certificate)
+	}
+
+	for i := len(saved) - 1; i >= 0; i-- {
+		serialNumber := saved[i].SerialNumber()
+		if err := r.target.Certificate.Archive(organization, serialNumber); err != nil {
+			return fmt.Errorf("failed to archive certificate %s: %w", serialNumber, err)
+		}
+		counts.archived++
+	}
+	return nil
+}
+
+// archivedFingerprints returns the fingerprints of archived certificates by
+// serial number
+func (r *CertMigrationController) archivedFingerprints(repository appmodels.CertificateRepository, organization *big.Int) (map[string]string, error) {
+	page, err := repository.FindPageByOrganization(organization, appmodels.CertificateQuery{Archived: true})
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := make(map[string]string, len(page.Certificates))
+	for _, certificate := range page.Certificates {
+		fingerprints[certificate.SerialNumber().String()] = apputils.CertificateFingerprint(certificate)
+	}
+	return fingerprints, nil
+}
+
+// verifyOrganization compares an organization of the source and the target
+// storage and returns the number of certificates
+func (r *CertMigrationController) verifyOrganization(organization appmodels.Organization) (int, error) {
+
+	id := organization.ID()
+	existing, err := r.target.Organization.FindById(id)
+	if err != nil {
+		return 0, fmt.Errorf("organization is missing: %w", err)
+	}
+	if !reflect.DeepEqual(apputils.ToOrganizationDTO(existing), apputils.ToOrganizationDTO(organization)) {
+		return 0, fmt.Errorf("organization does not match")
+	}
+
+	sourceCertificates, err := r.source.Certificate.FindAllByOrganization(id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find source certificates: %w", err)
+	}
+	targetCertificates, err := r.target.Certificate.FindAllByOrganization(id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find target certificates: %w", err)
+	}
+	if len(sourceCertificates) != len(targetCertificates) {
+		return 0, fmt.Errorf("source has %d certificates and target %d", len(sourceCertificates), len(targetCertificates))
+	}
+
+	fingerprints := make(map[string]string, len(targetCertificates))
+	for _, certificate := range targetCertificates {
+		fingerprints[certificate.SerialNumber().String()] = apputils.CertificateFingerprint(certificate)
+	}
+
+	var errs []error
+	for _, certificate := range sourceCertificates {
+		serialNumber := certificate.SerialNumber()
+		if fingerprints[serialNumber.String()] != apputils.CertificateFingerprint(certificate) {
+			errs = append(errs, fmt.Errorf("certificate %s does not match", serialNumber))
+			continue
+		}
+		if err := r.verifyPrivateKey(id, serialNumber); err != nil {
+			errs = append(errs, fmt.Errorf("private key %s: %w", serialNumber, err))
+		}
+		if certificate.IsCA() {
+			if err := r.verifyRevocations(id, serialNumber); err != nil {
+				errs = append(errs, fmt.Errorf("revocations of %s: %w", serialNumber, err))
+			}
+		}
+	}
+	if err := r.verifyArchivedCertificates(id); err != nil {
+		errs = append(errs, err)
+	}
+	if err := r.verifySsh(id); err != nil {
+		errs = append(errs, err)
+	}
+	if err := r.verifyProtocols(id); err != nil {
+		errs = append(errs, err)
+	}
+	if err := r.verifyAcme(id); err != nil {
+		errs = append(errs, err)
+	}
+	return len(sourceCertificates), errors.Join(errs...)
+}
+
+// verifyProtocols checks that the target has the EST labels, the SCEP
+// challenge password and the Vault roles of the source
+func (r *CertMigrationController) verifyProtocols(organization *big.Int) error {
+
+	if r.source.EstLabel != nil && r.target.EstLabel != nil {
+		labels, err := r.source.EstLabel.FindAllByOrganization(organization)
+		if err != nil {
+			return fmt.Errorf("failed to find source EST labels: %w", err)
+		}
+		for _, label := range labels {
+			if _, err := r.target.EstLabel.FindByLabel(label.Label()); err != nil {
+				return fmt.Errorf("EST label %s is missing", label.Label())
+			}
+		}
+	}
+
+	if r.source.ScepChallenge != nil && r.target.ScepChallenge != nil {
+		if _, err := r.source.ScepChallenge.FindByOrganization(organization); err == nil {
+			if _, err := r.target.ScepChallenge.FindByOrganization(organization); err != nil {
+				return fmt.Errorf("SCEP challenge is missing")
+			}
+		}
+	}
+
+	if r.source.VaultRole != nil && r.target.VaultRole != nil {
+		roles, err := r.source.VaultRole.FindAllByOrganization(organization)
+		if err != nil {
+			return fmt.Errorf("failed to find source Vault roles: %w", err)
+		}
+		for _, role := range roles {
+			if _, err := r.target.VaultRole.FindByOrganizationAndName(organization, role.Name()); err != nil {
+				return fmt.Errorf("Vault role %s is missing", role.Name())
+			}
+		}
+	}
+	return nil
+}
+
+// verifyAcme checks that the target has the ACME accounts of the source with
+// their orders and authorizations
+func (r *CertMigrationController) verifyAcme(organization *big.Int) error {
+	if r.source.AcmeAccount == nil || r.target.AcmeAccount == nil {
+		return nil
+	}
+	accounts, err := r.source.AcmeAccount.FindAllByOrganization(organization)
+	if err != nil {
+		return fmt.Errorf("failed to find source ACME accounts: %w", err)
+	}
+	for _, account := range accounts {
+		if _, err := r.target.AcmeAccount.FindById(account.ID()); err != nil {
+			return fmt.Errorf("ACME account %s is missing", account.ID())
+		}
+		orders, err := r.source.AcmeOrder.FindAllByAccount(account.ID())
+		if err != nil {
+			return fmt.Errorf("failed to find source ACME orders of %s: %w", account.ID(), err)
+		}
+		for _, order := range orders {
+			if _, err := r.target.AcmeOrder.FindById(order.ID()); err != nil {
+				return fmt.Errorf("ACME order %s is missing", order.ID())
+			}
+			for _, id := range order.AuthorizationIDs() {
+				if _, err := r.target.AcmeAuthorization.FindById(id); err != nil {
+					return fmt.Errorf("ACME authorization %s is missing", id)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// verifyArchivedCertificates checks that the target has the archived
+// certificates of the source
+func (r *CertMigrationController) verifyArchivedCertificates(organization *big.Int) error {
+	source, err := r.archivedFingerprints(r.source.Certificate, organization)
+	if err != nil {
+		return fmt.Errorf("failed to find source archive: %w", err)
+	}
+	target, err := r.archivedFingerprints(r.target.Certificate, organization)
+	if err != nil {
+		return fmt.Errorf("failed to find target archive: %w", err)
+	}
+	if len(source) != len(target) {
+		return fmt.Errorf("source has %d archived certificates and target %d", len(source), len(target))
+	}
+	for serialNumber, fingerprint := range source {
+		if target[serialNumber] != fingerprint {
+			return fmt.Errorf("archived certificate %s does not match", serialNumber)
+		}
+	}
+	return nil
+}
+
+// verifyPrivateKey checks that the target has the private key of the source,
+// or neither has it
+func (r *CertMigrationController) verifyPrivateKey(organization, serialNumber *big.Int) error {
+	sourceKey, sourceErr := r.source.PrivateKey.FindByOrganizationAndSerialNumber(organization, serialNumber)
+	targetKey, targetErr := r.target.PrivateKey.FindByOrganizationAndSerialNumber(organization, serialNumber)
+	if sourceErr != nil {
+		return nil
+	}
+	if targetErr != nil {
+		return fmt.Errorf("missing: %w", targetErr)
+	}
+	matches, err := r.privateKeysMatch(sourceKey, targetKey)
+	if err != nil {
+		return err
+	}
+	if !matches {
+		return fmt.Errorf("does not match")
+	}
+	return nil
+}
+
+// verifyRevocations checks that the target has the revocations of the source
+// and at least the same CRL number
+func (r *CertMigrationController) verifyRevocations(organization, issuer *big.Int) error {
+	revocations, err := r.source.CertificateRevocation.FindAllByOrganizationAndIssuer(organization, issuer)
+	if err != nil {
+		return fmt.Errorf("failed to find source revocations: %w", err)
+	}
+	targetRevocations, err := r.target.CertificateRevocation.FindAllByOrganizationAndIssuer(organization, issuer)
+	if err != nil {
+		return fmt.Errorf("failed to find target revocations: %w", err)
+	}
+	if len(revocations) != len(targetRevocations) {
+		return fmt.Errorf("source has %d revocations and target %d", len(revocations), len(targetRevocations))
+	}
+	for _, revocation := range revocations {
+		if _, err := r.target.CertificateRevocation.FindByOrganizationAndSerialNumber(organization, revocation.RevokedCertificate().SerialNumber()); err != nil {
+			return fmt.Errorf("revocation of %s is missing", revocation.RevokedCertificate().SerialNumber())
+		}
+	}
+
+	revocationList, err := r.source.CertificateRevocation.FindRevocationList(organization, issuer)
+	if err != nil {
+		return nil
+	}
+	targetRevocationList, err := r.target.CertificateRevocation.FindRevocationList(organization, issuer)
+	if err != nil {
+		return fmt.Errorf("CRL number is missing: %w", err)
+	}
+	if targetRevocationList.Number().Cmp(revocationList.Number()) < 0 {
+		return fmt.Errorf("CRL number %s is lower than %s", targetRevocationList.Number(), revocationList.Number())
+	}
+	return nil
+}
+
+// verifySsh checks that the target has the SSH certificate authority of the
+// source and the SSH certificates it has signed
+func (r *CertMigrationController) verifySsh(organization *big.Int) error {
+	authority, err := r.source.SshAuthority.FindByOrganization(organization)
+	if err != nil {
+		return nil
+	}
+	targetAuthority, err := r.target.SshAuthority.FindByOrganization(organization)
+	if err != nil {
+		return fmt.Errorf("SSH certificate authority is missing: %w", err)
+	}
+	matches, err := r.sshAuthoritiesMatch(authority, targetAuthority)
+	if err != nil {
+		return fmt.Errorf("SSH certificate authority: %w", err)
+	}
+	if !matches {
+		return fmt.Errorf("SSH certificate authority does not match")
+	}
+
+	certificates, err := r.source.SshCertificate.FindAllByOrganization(organization)
+	if err != nil {
+		return fmt.Errorf("failed to find source SSH certificates: %w", err)
+	}
+	targetCertificates, err := r.target.SshCertificate.FindAllByOrganization(organization)
+	if err != nil {
+		return fmt.Errorf("failed to find target SSH certificates: %w", err)
+	}
+	if len(certificates) != len(targetCertificates) {
+		return fmt.Errorf("source has %d SSH certificates and target %d", len(certificates), len(targetCertificates))
+	}
+	for _, certificate := range certificates {
+		existing, err := r.target.SshCertificate.FindByOrganizationAndSerial(organization, certificate.Serial())
+		if err != nil {
+			return fmt.Errorf("SSH certificate %d is missing", certificate.Serial())
+		}
+		if !bytes.Equal(existing.Certificate().Marshal(), certificate.Certificate().Marshal()) || existing.IsRevoked() != certificate.IsRevoked() {
+			return fmt.Errorf("SSH certificate %d does not match", certificate.Serial())
+		}
+	}
+	return nil
+}
+
+func (r *CertMigrationController) privateKeysMatch(a, b appmodels.PrivateKey) (bool, error) {
+	aFingerprint, err := apputils.PrivateKeyFingerprint(r.certManager, a)
+	if err != nil {
+		return false, err
+	}
+	bFingerprint, err := apputils.PrivateKeyFingerprint(r.certManager, b)
+	if err != nil {
+		return false, err
+	}
+	return aFingerprint == bFingerprint, nil
+}
+
+// sortCertificatesByIssuer returns the certificates so that every issuer is
+// before the certificates it has signed. Certificates whose issuer is not in
+// the list are kept at the end in their original order.
+func sortCertificatesByIssuer(certificates []appmodels.Certificate) []appmodels.Certificate {
+	sorted := make([]appmodels.Certificate, 0, len(certificates))
+	added := make(map[string]bool, len(certificates))
+	for len(sorted) < len(certificates) {
+		progress := false
+		for _, certificate := range certificates {
+			serialNumber := certificate.SerialNumber().String()
+			if added[serialNumber] {
+				continue
+			}
+			issuer := certificate.SignedBy()
+			if issuer != nil && issuer.Cmp(certificate.SerialNumber()) != 0 && !added[issuer.String()] {
+				continue
+			}
+			sorted = append(sorted, certificate)
+			added[serialNumber] = true
+			progress = true
+		}
+		if !progress {
+			break
+		}
+	}
+	for _, certificate := range certificates {
+		if !added[certificate.SerialNumber().String()] {
+			sorted = append(sorted, certificate)
+		}
+	}
+	return sorted
+}
+
+// NewMigrationController creates a migration controller
+//   - source: The storage to copy from
+//   - target: The storage to copy to
+//   - certManager: The certificate manager
+func NewMigrationController(
+	source *appmodels.Collection,
+	target *appmodels.Collection,
+	certManager managers.CertificateManager,
+) *CertMigrationController {
+	return &CertMigrationController{
+		source:      source,
+		target:      target,
+		certManager: certManager,
+	}
+}
+
+var _ appmodels.MigrationController = (*CertMigrationController)(nil)
@@ -0,0 +1,231 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestMigrationTarget(t *testing.T) *appmodels.Collection {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	return filerepository.NewCollection(certManager, managers.NewFileManager(), t.TempDir())
+}
+
+func TestCertMigrationController_Migrate(t *testing.T) {
+	source, organizationController := newTestBackupSource(t)
+	organization := organizationController.OrganizationID()
+	target := newTestMigrationTarget(t)
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+
+	sshController := appcontrollers.NewSshController(source.repository.SshAuthority, source.repository.SshCertificate, managers.NewRandomManager(), time.Hour)
+	_, err := sshController.NewAuthority(organization, appmodels.NIL_KEY_TYPE)
+	require.NoError(t, err)
+	sshCertificate, err := sshController.Sign(organization, newTestSshPublicKey(t), appmodels.SSH_USER_CERTIFICATE, "alice", []string{"alice"}, time.Hour, nil, nil)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	operation, err := source.repository.PendingOperation.Save(appmodels.NewPendingOperation("operation", appmodels.OPERATION_BACKUP_ORGANIZATION, organization, nil, "", "alice", appmodels.OPERATION_STATUS_EXECUTED, now, now.Add(time.Hour), []appmodels.OperationApproval{appmodels.NewOperationApproval("bob", true, now)}, now))
+	require.NoError(t, err)
+
+	controller := appcontrollers.NewMigrationController(source.repository, target, certManager)
+	require.NoError(t, controller.Migrate())
+	require.NoError(t, controller.Verify())
+
+	migratedOperation, err := target.PendingOperation.FindById("operation")
+	require.NoError(t, err)
+	assert.Equal(t, operation, migratedOperation)
+
+	certificates, err := target.Certificate.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Len(t, certificates, 3)
+	for _, certificate := range certificates {
+		_, err := target.PrivateKey.FindByOrganizationAndSerialNumber(organization, certificate.SerialNumber())
+		assert.NoError(t, err)
+	}
+
+	root := findTestRootCertificate(t, source.repository, organization)
+	revocations, err := target.CertificateRevocation.FindAllByOrganizationAndIssuer(organization, root.SerialNumber())
+	require.NoError(t, err)
+	assert.Len(t, revocations, 1)
+	revocationList, err := target.CertificateRevocation.FindRevocationList(organization, root.SerialNumber())
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), revocationList.Number())
+
+	authority, err := target.SshAuthority.FindByOrganization(organization)
+	require.NoError(t, err)
+	sourceAuthority, err := source.repository.SshAuthority.FindByOrganization(organization)
+	require.NoError(t, err)
+	assert.Equal(t, sourceAuthority.ID(), authority.ID())
+	_, err = target.SshCertificate.FindByOrganizationAndSerial(organization, sshCertificate.Serial())
+	require.NoError(t, err)
+
+	// Running the migration again copies nothing
+	require.NoError(t, controller.Migrate())
+	require.NoError(t, controller.Verify())
+
+	// A certificate revoked after the migration is copied again
+	_, err = sshController.Revoke(organization, sshCertificate.Serial())
+	require.NoError(t, err)
+	assert.Error(t, controller.Verify())
+	require.NoError(t, controller.Migrate())
+	require.NoError(t, controller.Verify())
+	revoked, err := target.SshCertificate.FindByOrganizationAndSerial(organization, sshCertificate.Serial())
+	require.NoError(t, err)
+	assert.True(t, revoked.IsRevoked())
+}
+
+func TestCertMigrationController_ProtocolState(t *testing.T) {
+	source, organizationController := newTestBackupSource(t)
+	organization := organizationController.OrganizationID()
+	target := newTestMigrationTarget(t)
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	root := findTestRootCertificate(t, source.repository, organization)
+	issuer := root.SerialNumber()
+
+	saveTestProtocolState(t, source.repository, organization, issuer)
+
+	controller := appcontrollers.NewMigrationController(source.repository, target, certManager)
+	require.NoError(t, controller.Migrate())
+	require.NoError(t, controller.Verify())
+
+	account, err := target.AcmeAccount.FindByThumbprint(organization, issuer, "thumbprint")
+	require.NoError(t, err)
+	assert.Equal(t, "account", account.ID())
+	order, err := target.AcmeOrder.FindById("order")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"authorization"}, order.AuthorizationIDs())
+	authorization, err := target.AcmeAuthorization.FindById("authorization")
+	require.NoError(t, err)
+	assert.Len(t, authorization.Challenges(), 1)
+	label, err := target.EstLabel.FindByLabel("devices")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", label.Username())
+	_, err = target.ScepChallenge.FindByOrganization(organization)
+	require.NoError(t, err)
+	role, err := target.VaultRole.FindByOrganizationAndName(organization, "web")
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, role.TTL())
+
+	// An EST label added after the migration is reported and copied again
+	_, err = source.repository.EstLabel.Save(appmodels.NewEstLabel("servers", organization, issuer, "bob", []byte("hash")))
+	require.NoError(t, err)
+	assert.Error(t, controller.Verify())
+	require.NoError(t, controller.Migrate())
+	require.NoError(t, controller.Verify())
+}
+
+func TestCertMigrationController_Archived(t *testing.T) {
+	source, organizationController := newTestBackupSource(t)
+	organization := organizationController.OrganizationID()
+	target := newTestMigrationTarget(t)
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+
+	certificates, err := source.repository.Certificate.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	client := apputils.FilterClientCertificates(certificates)[0]
+	require.NoError(t, source.repository.Certificate.Archive(organization, client.SerialNumber()))
+
+	controller := appcontrollers.NewMigrationController(source.repository, target, certManager)
+	require.NoError(t, controller.Migrate())
+	require.NoError(t, controller.Verify())
+
+	certificates, err = target.Certificate.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Len(t, certificates, 2)
+	page, err := target.Certificate.FindPageByOrganization(organization, appmodels.CertificateQuery{Archived: true})
+	require.NoError(t, err)
+	require.Len(t, page.Certificates, 1)
+	assert.Equal(t, client.SerialNumber(), page.Certificates[0].SerialNumber())
+
+	// Running the migration again copies nothing
+	require.NoError(t, controller.Migrate())
+	require.NoError(t, controller.Verify())
+}
+
+func TestCertMigrationController_Resume(t *testing.T) {
+	source, organizationController := newTestBackupSource(t)
+	organization := organizationController.OrganizationID()
+	target := newTestMigrationTarget(t)
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+
+	// An interrupted migration which copied only the organization and the
+	// root certificate
+	model, err := source.repository.Organization.FindById(organization)
+	require.NoError(t, err)
+	_, err = target.Organization.Save(model)
+	require.NoError(t, err)
+	certificates, err := source.repository.Certificate.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	root := apputils.FilterRootCertificates(certificates)[0]
+	_, err = target.Certificate.Save(root)
+	require.NoError(t, err)
+
+	controller := appcontrollers.NewMigrationController(source.repository, target, certManager)
+	assert.Error(t, controller.Verify())
+	require.NoError(t, controller.Migrate())
+	require.NoError(t, controller.Verify())
+
+	_, err = target.PrivateKey.FindByOrganizationAndSerialNumber(organization, root.SerialNumber())
+	assert.NoError(t, err)
+}
+
+func TestCertMigrationController_Conflict(t *testing.T) {
+	source, organizationController := newTestBackupSource(t)
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+
+	target := newTestMigrationTarget(t)
+	require.NoError(t, appcontrollers.NewMigrationController(source.repository, target, certManager).Migrate())
+
+	// Organizations with the same ID but different content are conflicts
+	model, err := source.repository.Organization.FindById(organizationController.OrganizationID())
+	require.NoError(t, err)
+	conflicting := appmodels.NewOrganization(organizationController.OrganizationID(), "other", model.Names(), model.SignatureAlgorithm(), "", model.KeyRetentionPolicy())
+	_, err = source.repository.Organization.Save(conflicting)
+	require.NoError(t, err)
+
+	controller := appcontrollers.NewMigrationController(source.repository, target, certManager)
+	assert.ErrorContains(t, controller.Migrate(), "different organization")
+	assert.ErrorContains(t, controller.Verify(), "organization does not match")
+}
+
+func TestCertMigrationController_CertificateConflict(t *testing.T) {
+	source, organizationController := newTestBackupSource(t)
+	organization := organizationController.OrganizationID()
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+
+	model, err := source.repository.Organization.FindById(organization)
+	require.NoError(t, err)
+	certificates, err := source.repository.Certificate.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	root := apputils.FilterRootCertificates(certificates)[0]
+
+	// A different root certificate with the same serial number
+	key, err := apputils.GeneratePrivateKey(organization, root.SerialNumber(), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	conflicting, err := apputils.NewRootCertificate(certManager, root.SerialNumber(), model, time.Hour, appmodels.NIL_SIGNATURE_ALGORITHM, key, "Other Root")
+	require.NoError(t, err)
+
+	target := newTestMigrationTarget(t)
+	_, err = target.Organization.Save(model)
+	require.NoError(t, err)
+	_, err = target.Certificate.Save(conflicting)
+	require.NoError(t, err)
+
+	controller := appcontrollers.NewMigrationController(source.repository, target, certManager)
+	assert.ErrorContains(t, controller.Migrate(), "different certificate")
+
+	saved, err := target.Certificate.FindByOrganizationAndSerialNumber(organization, root.SerialNumber())
+	require.NoError(t, err)
+	assert.Equal(t, conflicting.Certificate().Raw, saved.Certificate().Raw)
+}
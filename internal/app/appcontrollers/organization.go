@@ -4,6 +4,7 @@ package appcontrollers
 
 import (
 	"fmt"
+	"log"
 	"math/big"
 	"time"
 
@@ -39,12 +40,21 @@ type CertOrganizationController struct {
 	organizationRepository appmodels.OrganizationRepository
 	certificateRepository  appmodels.CertificateRepository
 	privateKeyRepository   appmodels.PrivateKeyRepository
+	unitOfWorkRepository   appmodels.UnitOfWorkRepository
+
+	// revocationRepository - Revoked certificates. Certificates cannot be
+	// revoked without it.
+	revocationRepository appmodels.CertificateRevocationRepository
 
 	// defaultExpiration - Expiration time for new root certificates
 	defaultExpiration time.Duration
 
 	// defaultKeyType - The default key type for root certificates
 	defaultKeyType appmodels.KeyType
+
+	// signatureAlgorithm - The signature algorithm for root certificates. If
+	// not set, the organization default is used.
+	signatureAlgorithm appmodels.SignatureAlgorithm
 }
 
 func (r *CertOrganizationController) CertificateCollection() ([]appmodels.Certificate, error) {
@@ -59,6 +69,21 @@ func (r *CertOrganizationController) CertificateCollection() ([]appmodels.Certif
 	return list, nil
 }
 
+func (r *CertOrganizationController) CertificatePage(query appmodels.CertificateQuery) (appmodels.CertificatePage, error) {
+	organization := r.OrganizationID()
+	if r.certificateRepository == nil {
+		return appmodels.CertificatePage{}, fmt.Errorf("[%s:CertificatePage]: no certificate repository", organization)
+	}
+	if err := apputils.ValidateCertificateCursor(query); err != nil {
+		return appmodels.CertificatePage{}, fmt.Errorf("[%s:CertificatePage]: %w", organization, err)
+	}
+	page, err := r.certificateRepository.FindPageByOrganization(organization, query)
+	if err != nil {
+		return appmodels.CertificatePage{}, fmt.Errorf("[%s:CertificatePage]: failed: %w", organization, err)
+	}
+	return page, nil
+}
+
 func (r *CertOrganizationController) OrganizationID() *big.Int {
 	return r.id
 }
@@ -83,6 +108,7 @@ func (r *CertOrganizationController) CertificateController(serialNumber *big.Int
 		model,
 		r.certificateRepository,
 		r.privateKeyRepository,
+		r.unitOfWorkRepository,
 		r.certManager,
 		r.randomManager,
 		r.defaultExpiration,
@@ -105,6 +131,14 @@ func (r *CertOrganizationController) ExpirationDuration() time.Duration {
 	return r.defaultExpiration
 }
 
+func (r *CertOrganizationController) SetKeyType(keyType appmodels.KeyType) {
+	r.defaultKeyType = keyType
+}
+
+func (r *CertOrganizationController) SetSignatureAlgorithm(signatureAlgorithm appmodels.SignatureAlgorithm) {
+	r.signatureAlgorithm = signatureAlgorithm
+}
+
 func (r *CertOrganizationController) NewRootCertificate(commonName string) (appmodels.Certificate, error) {
 
 	organization := r.OrganizationID()
@@ -117,6 +151,10 @@ func (r *CertOrganizationController) NewRootCertificate(commonName string) (appm
 		return nil, fmt.Errorf("[%s:NewRootCertificate:%s]: no certificate repository", organization, commonName)
 	}
 
+	if r.unitOfWorkRepository == nil {
+		return nil, fmt.Errorf("[%s:NewRootCertificate:%s]: no unit of work repository", organization, commonName)
+	}
+
 	serialNumber, err := apputils.GenerateSerialNumber(r.randomManager)
 	if err != nil {
 		return nil, fmt.Errorf("[%s:NewRootCertificate:%s]: failed to create serial number: %w", organization, commonName, err)
@@ -141,11 +179,17 @@ func (r *CertOrganizationController) NewRootCertificate(commonName string) (appm
 		return nil, fmt.Errorf("[%s:NewRootCertificate:%s]: failed to generate private key: %w", organization, commonName, err)
 	}
 
+	signatureAlgorithm := r.signatureAlgorithm
+	if signatureAlgorithm == appmodels.NIL_SIGNATURE_ALGORITHM {
+		signatureAlgorithm = r.Organization().SignatureAlgorithm()
+	}
+
 	cert, err := apputils.NewRootCertificate(
 		r.certManager,
 		serialNumber,
 		r.Organization(),
 		r.defaultExpiration,
+		signatureAlgorithm,
 		privateKey,
 		commonName,
 	)
@@ -153,17 +197,14 @@ func (r *CertOrganizationController) NewRootCertificate(commonName string) (appm
 		return nil, fmt.Errorf("[%s:NewRootCertificate:%s]: failed to create certificate: %w", organization, commonName, err)
 	}
 
-	_, err = r.privateKeyRepository.Save(privateKey)
-	if err != nil {
-		return nil, fmt.Errorf("[%s:NewRootCertificate:%s]: could not save private key: %w", organization, commonName, err)
+	work := r.unitOfWorkRepository.NewUnitOfWork()
+	work.SavePrivateKey(privateKey)
+	work.SaveCertificate(cert)
+	if err := work.Commit(); err != nil {
+		return nil, fmt.Errorf("[%s:NewRootCertificate:%s]: could not save certificate and private key: %w", organization, commonName, err)
 	}
 
-	savedModel, err := r.certificateRepository.Save(cert)
-	if err != nil {
-		return nil, fmt.Errorf("[%s:NewRootCertificate:%s]: could not save certificate: %w", organization, commonName, err)
-	}
-
-	return savedModel, nil
+	return cert, nil
 }
 
 func (r *CertOrganizationController) UsesOrganizationService(service appmodels.OrganizationRepository) bool {
@@ -174,9 +215,45 @@ func (r *CertOrganizationController) UsesApplicationController(service appmodels
 	return r.parent == service
 }
 
+// SetRevocationRepository sets the repository where revoked certificates are
+// saved
+func (r *CertOrganizationController) SetRevocationRepository(repository appmodels.CertificateRevocationRepository) {
+	r.revocationRepository = repository
+}
+
 func (r *CertOrganizationController) RevokeCertificate(certificate appmodels.Certificate) (appmodels.RevokedCertificate, error) {
-	// TODO implement me
-	panic("implement me")
+	organization := r.OrganizationID()
+	if certificate == nil {
+		return nil, fmt.Errorf("[%s:RevokeCertificate]: certificate: must be defined", organization)
+	}
+	serialNumber := certificate.SerialNumber()
+	if r.revocationRepository == nil {
+		return nil, fmt.Errorf("[%s:RevokeCertificate:%s]: no revocation repository", organization, serialNumber)
+	}
+	if certificate.OrganizationID().Cmp(organization) != 0 {
+		return nil, fmt.Errorf("[%s:RevokeCertificate:%s]: certificate belongs to organization %s", organization, serialNumber, certificate.OrganizationID())
+	}
+
+	if revocation, err := r.revocationRepository.FindByOrganizationAndSerialNumber(organization, serialNumber); err == nil {
+		return revocation.RevokedCertificate(), nil
+	}
+
+	// Root certificates are listed in their own CRL
+	issuer := certificate.SignedBy()
+	if issuer == nil {
+		issuer = serialNumber
+	}
+
+	revocation, err := r.revocationRepository.Save(appmodels.NewCertificateRevocation(
+		organization,
+		issuer,
+		appmodels.NewRevokedCertificate(serialNumber, time.Now(), certificate.NotAfter()),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("[%s:RevokeCertificate:%s]: failed to save: %w", organization, serialNumber, err)
+	}
+	log.Printf("[%s:RevokeCertificate:%s]: Certificate revoked", organization, serialNumber)
+	return revocation.RevokedCertificate(), nil
 }
 
 // NewOrganizationController creates a new instance of CertOrganizationController
@@ -187,6 +264,7 @@ func (r *CertOrganizationController) RevokeCertificate(certificate appmodels.Cer
 //   - organizationRepository appmodels.OrganizationRepository
 //   - certificateRepository appmodels.CertificateRepository
 //   - privateKeyRepository appmodels.PrivateKeyRepository
+//   - unitOfWorkRepository appmodels.UnitOfWorkRepository
 //   - certManager managers.CertificateManager
 //   - randomManager managers.RandomManager
 //   - defaultExpiration time.Duration
@@ -198,6 +276,7 @@ func NewOrganizationController(
 	organizationRepository appmodels.OrganizationRepository,
 	certificateRepository appmodels.CertificateRepository,
 	privateKeyRepository appmodels.PrivateKeyRepository,
+	unitOfWorkRepository appmodels.UnitOfWorkRepository,
 	certManager managers.CertificateManager,
 	randomManager managers.RandomManager,
 	defaultExpiration time.Duration,
@@ -209,6 +288,7 @@ func NewOrganizationController(
 		organizationRepository: organizationRepository,
 		certificateRepository:  certificateRepository,
 		privateKeyRepository:   privateKeyRepository,
+		unitOfWorkRepository:   unitOfWorkRepository,
 		certManager:            certManager,
 		randomManager:          randomManager,
 		defaultExpiration:      defaultExpiration,
@@ -11,10 +11,12 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
 	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
 	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
 	"github.com/hyperifyio/gocertcenter/internal/common/commonmocks"
 )
 
@@ -34,6 +36,7 @@ func TestNewOrganizationController(t *testing.T) {
 		mockOrganizationRepository,
 		mockCertificateRepository,
 		mockPrivateKeyRepository,
+		new(appmocks.MockUnitOfWorkService),
 		certManager,
 		randomManager,
 		24*time.Hour,
@@ -67,6 +70,7 @@ func TestOrganizationController_NewRootCertificate_SerialNumberExists(t *testing
 		&appmocks.MockOrganizationService{},
 		mockCertificateRepository,
 		mockPrivateKeyRepository,
+		new(appmocks.MockUnitOfWorkService),
 		mockCertManager,
 		mockRandomManager,
 		24*time.Hour,
@@ -92,6 +96,7 @@ func TestOrganizationController_GetCertificateController_FetchFail(t *testing.T)
 		&appmocks.MockOrganizationService{},
 		mockCertificateRepository,
 		&appmocks.MockPrivateKeyService{},
+		nil,
 		commonmocks.NewMockCertificateManager(),
 		commonmocks.NewMockRandomManager(),
 		24*time.Hour,
@@ -120,6 +125,7 @@ func TestOrganizationController_GetCertificateCollection_Success(t *testing.T) {
 		&appmocks.MockOrganizationService{},
 		mockCertificateRepository,
 		&appmocks.MockPrivateKeyService{},
+		nil,
 		commonmocks.NewMockCertificateManager(),
 		commonmocks.NewMockRandomManager(),
 		24*time.Hour,
@@ -141,6 +147,7 @@ func TestOrganizationController_GetCertificateCollection_NoCertificateRepository
 		&appmocks.MockOrganizationService{},
 		nil, // No certificate repository provided
 		&appmocks.MockPrivateKeyService{},
+		nil,
 		commonmocks.NewMockCertificateManager(),
 		commonmocks.NewMockRandomManager(),
 		24*time.Hour,
@@ -159,7 +166,7 @@ func TestOrganizationController_GetOrganizationModel(t *testing.T) {
 	controller := appcontrollers.NewOrganizationController(
 		big.NewInt(123),
 		mockModel, // This is the model we expect to retrieve
-		nil, nil, nil, nil, nil, 0,
+		nil, nil, nil, nil, nil, nil, 0,
 		new(appmocks.MockApplicationController),
 	)
 
@@ -173,6 +180,7 @@ func TestOrganizationController_GetApplicationController(t *testing.T) {
 		big.NewInt(123),
 		nil,
 		nil, nil, nil,
+		nil,
 		nil, nil,
 		0,
 		mockParent,
@@ -187,6 +195,7 @@ func TestOrganizationController_SetExpirationDuration(t *testing.T) {
 		big.NewInt(123),
 		nil,
 		nil, nil, nil,
+		nil,
 		nil, nil,
 		24*time.Hour, // initial duration
 		new(appmocks.MockApplicationController),
@@ -204,6 +213,7 @@ func TestOrganizationController_UsesApplicationController(t *testing.T) {
 		big.NewInt(123),
 		nil,
 		nil, nil, nil,
+		nil,
 		nil, nil,
 		0,
 		mockParent,
@@ -213,24 +223,48 @@ func TestOrganizationController_UsesApplicationController(t *testing.T) {
 	assert.False(t, controller.UsesApplicationController(new(appmocks.MockApplicationController)), "Controller should not use a different application controller")
 }
 
-func TestOrganizationController_RevokeCertificate_NotImplemented(t *testing.T) {
-	t.Skip("RevokeCertificate method is not implemented yet")
-
-	// The following is a placeholder structure for the future test
+func TestOrganizationController_RevokeCertificate(t *testing.T) {
+	organization := big.NewInt(123)
 	controller := appcontrollers.NewOrganizationController(
-		big.NewInt(123),
+		organization,
 		nil,
 		nil, nil, nil,
+		nil,
 		nil, nil,
 		0,
 		new(appmocks.MockApplicationController),
 	)
+	notAfter := time.Now().Add(time.Hour)
 	mockCertificate := new(appmocks.MockCertificate)
-	// Assume mockCertificate setup is done here
+	mockCertificate.On("OrganizationID").Return(organization)
+	mockCertificate.On("SerialNumber").Return(big.NewInt(5))
+	mockCertificate.On("SignedBy").Return(big.NewInt(2))
+	mockCertificate.On("NotAfter").Return(notAfter)
 
 	_, err := controller.RevokeCertificate(mockCertificate)
-	assert.Error(t, err, "Expected not implemented error for RevokeCertificate")
-	// Further assertions once implemented
+	assert.Error(t, err, "Expected an error without a revocation repository")
+
+	revocations := memoryrepository.NewCertificateRevocationRepository()
+	controller.SetRevocationRepository(revocations)
+	revoked, err := controller.RevokeCertificate(mockCertificate)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), revoked.SerialNumber())
+	assert.Equal(t, notAfter, revoked.ExpirationTime())
+
+	list, err := revocations.FindAllByOrganizationAndIssuer(organization, big.NewInt(2))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	// Revoking again returns the first revocation
+	again, err := controller.RevokeCertificate(mockCertificate)
+	require.NoError(t, err)
+	assert.Equal(t, revoked.RevocationTime(), again.RevocationTime())
+
+	other := new(appmocks.MockCertificate)
+	other.On("OrganizationID").Return(big.NewInt(124))
+	other.On("SerialNumber").Return(big.NewInt(6))
+	_, err = controller.RevokeCertificate(other)
+	assert.Error(t, err, "Expected an error for a certificate of another organization")
 }
 
 func TestOrganizationController_GetCertificateCollection_Failure(t *testing.T) {
@@ -252,6 +286,7 @@ func TestOrganizationController_GetCertificateCollection_Failure(t *testing.T) {
 		new(appmocks.MockOrganizationService),
 		mockCertificateRepository,
 		mockPrivateKeyRepository,
+		new(appmocks.MockUnitOfWorkService),
 		mockCertManager,
 		mockRandomManager,
 		24*time.Hour,
@@ -293,6 +328,7 @@ func TestOrganizationController_GetCertificateController(t *testing.T) {
 		new(appmocks.MockOrganizationService),
 		mockCertificateRepository,
 		mockPrivateKeyRepository,
+		new(appmocks.MockUnitOfWorkService),
 		mockCertManager,
 		mockRandomManager,
 		24*time.Hour,
@@ -329,6 +365,7 @@ func TestNewRootCertificate_SerialNumberGenerationFail(t *testing.T) {
 		new(appmocks.MockOrganizationService),
 		mockCertificateRepository,
 		mockPrivateKeyRepository,
+		new(appmocks.MockUnitOfWorkService),
 		mockCertManager,
 		mockRandomManager,
 		24*time.Hour,
@@ -351,6 +388,7 @@ func TestNewRootCertificate_NoCertificateRepository(t *testing.T) {
 		new(appmocks.MockOrganizationService),
 		nil, // No certificate repository
 		new(appmocks.MockPrivateKeyService),
+		nil,
 		commonmocks.NewMockCertificateManager(),
 		commonmocks.NewMockRandomManager(),
 		24*time.Hour,
@@ -370,7 +408,7 @@ func TestNewRootCertificate_NoPrivateKeyRepository(t *testing.T) {
 		new(appmocks.MockOrganization),
 		new(appmocks.MockOrganizationService),
 		new(appmocks.MockCertificateService),
-		nil, // No private key repository
+		nil, nil, // No private key repository
 		commonmocks.NewMockCertificateManager(),
 		commonmocks.NewMockRandomManager(),
 		24*time.Hour,
@@ -381,3 +419,22 @@ func TestNewRootCertificate_NoPrivateKeyRepository(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no certificate repository")
 }
+
+func TestNewRootCertificate_NoUnitOfWorkRepository(t *testing.T) {
+	controller := appcontrollers.NewOrganizationController(
+		big.NewInt(123),
+		new(appmocks.MockOrganization),
+		new(appmocks.MockOrganizationService),
+		new(appmocks.MockCertificateService),
+		new(appmocks.MockPrivateKeyService),
+		nil, // No unit of work repository
+		commonmocks.NewMockCertificateManager(),
+		commonmocks.NewMockRandomManager(),
+		24*time.Hour,
+		new(appmocks.MockApplicationController),
+	)
+
+	_, err := controller.NewRootCertificate("Common Name")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no unit of work repository")
+}
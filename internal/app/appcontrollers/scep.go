@@ -0,0 +1,306 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+const (
+
+	// ScepRaCommonName is the common name of RA certificates
+	ScepRaCommonName = "SCEP RA"
+
+	// DefaultScepRaExpiration is the expiration duration of RA certificates
+	DefaultScepRaExpiration = 365 * 24 * time.Hour
+
+	// ScepRaRenewBefore is how long before the expiration a new RA
+	// certificate is issued
+	ScepRaRenewBefore = 30 * 24 * time.Hour
+)
+
+// ScepCapabilities are the capabilities reported in GetCACaps
+var ScepCapabilities = []string{
+	"AES",
+	"DES3",
+	"POSTPKIOperation",
+	"Renewal",
+	"SHA-256",
+	"SCEPStandard",
+}
+
+// CertScepController implements appmodels.ScepController
+type CertScepController struct {
+	challengeRepository  appmodels.ScepChallengeRepository
+	privateKeyRepository appmodels.PrivateKeyRepository
+
+	// revocationRepository is optional. Renewals signed with a revoked
+	// certificate are rejected when it is set.
+	revocationRepository appmodels.CertificateRevocationRepository
+
+	// expiration is the expiration duration of issued certificates
+	expiration time.Duration
+
+	// raLock prevents concurrent requests from issuing more than one RA
+	// certificate
+	raLock sync.Mutex
+}
+
+func (r *CertScepController) SetChallengePassword(organization *big.Int, password string) (appmodels.ScepChallenge, error) {
+
+	if organization == nil {
+		return nil, fmt.Errorf("[SetChallengePassword]: organization: must be defined")
+	}
+
+	if password == "" {
+		return nil, fmt.Errorf("[SetChallengePassword]: password: must be defined")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("[SetChallengePassword]: failed to hash password: %w", err)
+	}
+
+	saved, err := r.challengeRepository.Save(appmodels.NewScepChallenge(organization, hash))
+	if err != nil {
+		return nil, fmt.Errorf("[SetChallengePassword]: failed to save: %w", err)
+	}
+	return saved, nil
+}
+
+func (r *CertScepController) Capabilities() []string {
+	return ScepCapabilities
+}
+
+func (r *CertScepController) RA(issuer appmodels.CertificateController) (appmodels.Certificate, appmodels.PrivateKey, error) {
+
+	r.raLock.Lock()
+	defer r.raLock.Unlock()
+
+	organization := issuer.OrganizationID()
+	issuerSerialNumber := issuer.Certificate().SerialNumber()
+
+	certificate, privateKey, err := r.findRA(issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[%s@%s:RA]: %w", issuerSerialNumber, organization, err)
+	}
+	if certificate != nil {
+		return certificate, privateKey, nil
+	}
+
+	// The RA decrypts the requests, so it must have an RSA key
+	issuer.SetKeyType(appmodels.RSA_2048)
+	issuer.SetExpirationDuration(DefaultScepRaExpiration)
+	certificate, privateKey, err = issuer.NewClientCertificate(ScepRaCommonName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[%s@%s:RA]: failed to create certificate: %w", issuerSerialNumber, organization, err)
+	}
+
+	if _, err := r.privateKeyRepository.Save(privateKey); err != nil {
+		return nil, nil, fmt.Errorf("[%s@%s:RA]: failed to save private key: %w", issuerSerialNumber, organization, err)
+	}
+	log.Printf("[%s@%s:RA]: Created RA certificate: %s", issuerSerialNumber, organization, certificate.SerialNumber())
+
+	return certificate, privateKey, nil
+}
+
+func (r *CertScepController) CACertificates(issuer appmodels.CertificateController) []appmodels.Certificate {
+	var list []appmodels.Certificate
+	for current := issuer; current != nil; current = current.ParentCertificateController() {
+		list = append(list, current.Certificate())
+	}
+	return list
+}
+
+func (r *CertScepController) PKIOperation(issuer appmodels.CertificateController, message []byte) ([]byte, error) {
+
+	request, err := apputils.ParseScepRequest(message)
+	if err != nil {
+		return nil, fmt.Errorf("[PKIOperation]: %w", err)
+	}
+
+	raCertificate, raPrivateKey, err := r.RA(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("[PKIOperation]: %w", err)
+	}
+	raKey, ok := raPrivateKey.PrivateKey().(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("[PKIOperation]: RA key must be an RSA key")
+	}
+
+	failure := func(failInfo string, format string, args ...any) ([]byte, error) {
+		log.Printf("[PKIOperation:%s]: Rejected: %s", request.TransactionID, fmt.Sprintf(format, args...))
+		response, err := apputils.EncodeScepResponse(request, apputils.SCEP_STATUS_FAILURE, failInfo, nil, raCertificate.Certificate(), raKey, nil)
+		if err != nil {
+			return nil, fmt.Errorf("[PKIOperation]: %w", err)
+		}
+		return response, nil
+	}
+
+	if request.MessageType != apputils.SCEP_MESSAGE_PKCS_REQ && request.MessageType != apputils.SCEP_MESSAGE_RENEWAL_REQ {
+		return failure(apputils.SCEP_FAIL_BAD_REQUEST, "unsupported message type: %s", request.MessageType)
+	}
+
+	// The response is encrypted for the signer certificate
+	if _, ok := request.Signer.PublicKey.(*rsa.PublicKey); !ok {
+		return failure(apputils.SCEP_FAIL_BAD_ALG, "signer must have an RSA key")
+	}
+
+	// Failures to decrypt and to parse the request respond the same, so that
+	// the responses cannot be used as a padding oracle of the RA key
+	content, algorithm, err := apputils.DecryptPkcs7EnvelopedData(request.EnvelopedData, raCertificate.Certificate(), raKey)
+	var csr *x509.CertificateRequest
+	if err == nil {
+		csr, err = x509.ParseCertificateRequest(content)
+	}
+	if err != nil {
+		return failure(apputils.SCEP_FAIL_BAD_MESSAGE_CHECK, "failed to decrypt the request")
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return failure(apputils.SCEP_FAIL_BAD_MESSAGE_CHECK, "invalid csr signature: %v", err)
+	}
+
+	commonName := csr.Subject.CommonName
+	if err := apputils.ValidateClientCertificateCommonName(commonName); err != nil {
+		return failure(apputils.SCEP_FAIL_BAD_REQUEST, "common name: %v", err)
+	}
+	if commonName == ScepRaCommonName {
+		return failure(apputils.SCEP_FAIL_BAD_REQUEST, "common name is reserved: %s", commonName)
+	}
+
+	if request.MessageType == apputils.SCEP_MESSAGE_RENEWAL_REQ {
+		if err := r.verifyRenewal(issuer, request.Signer, commonName); err != nil {
+			return failure(apputils.SCEP_FAIL_BAD_CERT_ID, "%v", err)
+		}
+	} else {
+		if err := r.verifyChallengePassword(issuer.OrganizationID(), csr); err != nil {
+			return failure(apputils.SCEP_FAIL_BAD_REQUEST, "%v", err)
+		}
+	}
+
+	issuer.SetExpirationDuration(r.expiration)
+	certificate, err := issuer.NewClientCertificateFromPublicKey(appmodels.NewPublicKey(csr.PublicKey), commonName)
+	if err != nil {
+		return nil, fmt.Errorf("[PKIOperation]: failed to issue certificate: %w", err)
+	}
+	log.Printf("[PKIOperation:%s]: Issued certificate: %s", request.TransactionID, certificate.SerialNumber())
+
+	response, err := apputils.EncodeScepResponse(
+		request,
+		apputils.SCEP_STATUS_SUCCESS,
+		"",
+		[]*x509.Certificate{certificate.Certificate()},
+		raCertificate.Certificate(),
+		raKey,
+		algorithm,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[PKIOperation]: %w", err)
+	}
+	return response, nil
+}
+
+// findRA returns the newest RA certificate of the issuer which has a known
+// private key and is not about to expire. A new RA certificate is not
+// created while the private keys are sealed, so appmodels.ErrSealed is
+// returned. Nil is returned without an error only if the issuer has no
+// usable RA certificate, since a failure to list the certificates must not
+// create a new one.
+func (r *CertScepController) findRA(issuer appmodels.CertificateController) (appmodels.Certificate, appmodels.PrivateKey, error) {
+	children, err := issuer.ChildCertificateCollection("client")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find RA certificates: %w", err)
+	}
+	renewAt := time.Now().Add(ScepRaRenewBefore)
+	var certificate appmodels.Certificate
+	var privateKey appmodels.PrivateKey
+	for _, child := range children {
+		if child.CommonName() != ScepRaCommonName || child.NotAfter().Before(renewAt) {
+			continue
+		}
+		if certificate != nil && !child.NotAfter().After(certificate.NotAfter()) {
+			continue
+		}
+		key, err := r.privateKeyRepository.FindByOrganizationAndSerialNumber(issuer.OrganizationID(), child.SerialNumber())
+		if errors.Is(err, appmodels.ErrSealed) {
+			return nil, nil, err
+		} else if err != nil {
+			continue
+		}
+		if _, ok := key.PrivateKey().(*rsa.PrivateKey); !ok {
+			continue
+		}
+		certificate, privateKey = child, key
+	}
+	return certificate, privateKey, nil
+}
+
+// verifyChallengePassword checks the challenge password of a PKCSReq
+func (r *CertScepController) verifyChallengePassword(organization *big.Int, csr *x509.CertificateRequest) error {
+	challenge, err := r.challengeRepository.FindByOrganization(organization)
+	if err != nil {
+		return fmt.Errorf("challenge password is not configured")
+	}
+	password, err := apputils.ScepChallengePassword(csr)
+	if err != nil {
+		return fmt.Errorf("challenge password: %w", err)
+	}
+	if password == "" || bcrypt.CompareHashAndPassword(challenge.PasswordHash(), []byte(password)) != nil {
+		return fmt.Errorf("invalid challenge password")
+	}
+	return nil
+}
+
+// verifyRenewal checks a RenewalReq is signed with a valid certificate
+// issued by the issuer to the same client which has not been revoked
+func (r *CertScepController) verifyRenewal(issuer appmodels.CertificateController, signer *x509.Certificate, commonName string) error {
+	if err := signer.CheckSignatureFrom(issuer.Certificate().Certificate()); err != nil {
+		return fmt.Errorf("signer certificate was not issued by the CA: %w", err)
+	}
+	now := time.Now()
+	if now.Before(signer.NotBefore) || now.After(signer.NotAfter) {
+		return fmt.Errorf("signer certificate is not valid now")
+	}
+	if signer.Subject.CommonName != commonName {
+		return fmt.Errorf("common name does not match the signer certificate")
+	}
+	if r.revocationRepository != nil {
+		if _, err := r.revocationRepository.FindByOrganizationAndSerialNumber(issuer.OrganizationID(), signer.SerialNumber); err == nil {
+			return fmt.Errorf("signer certificate is revoked")
+		}
+	}
+	return nil
+}
+
+// NewScepController creates a SCEP controller
+//   - challengeRepository: The challenge password repository
+//   - privateKeyRepository: The repository for RA private keys
+//   - revocationRepository: The certificate revocation repository, or nil
+//   - expiration: The expiration duration of issued certificates
+func NewScepController(
+	challengeRepository appmodels.ScepChallengeRepository,
+	privateKeyRepository appmodels.PrivateKeyRepository,
+	revocationRepository appmodels.CertificateRevocationRepository,
+	expiration time.Duration,
+) *CertScepController {
+	return &CertScepController{
+		challengeRepository:  challengeRepository,
+		privateKeyRepository: privateKeyRepository,
+		revocationRepository: revocationRepository,
+		expiration:           expiration,
+	}
+}
+
+var _ appmodels.ScepController = (*CertScepController)(nil)
@@ -0,0 +1,229 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// newTestScepIssuer creates a root certificate controller backed by memory
+// repositories
+func newTestScepIssuer(t *testing.T) (appmodels.CertificateController, *appmodels.Collection) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := memoryrepository.NewCollection()
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+	organization := appmodels.NewSerialNumber(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	organizationController.SetExpirationDuration(2 * appcontrollers.DefaultScepRaExpiration)
+	root, err := organizationController.NewRootCertificate("Test Root CA")
+	require.NoError(t, err)
+	issuer, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	return issuer, repository
+}
+
+// newTestScepClient creates a self-signed RSA certificate used to sign
+// PKCSReq messages
+func newTestScepClient(t *testing.T, commonName string) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return certificate, key
+}
+
+func newTestScepMessage(t *testing.T, messageType string, ra appmodels.Certificate, signer *x509.Certificate, key *rsa.PrivateKey, commonName, challengePassword string) []byte {
+	csr, err := apputils.NewScepCertificateRequest(&x509.CertificateRequest{Subject: pkix.Name{CommonName: commonName}}, challengePassword, key)
+	require.NoError(t, err)
+	message, err := apputils.EncodeScepRequest(messageType, "transaction", []byte("nonce"), csr, ra.Certificate(), signer, key, apputils.Pkcs7Aes128CbcOID)
+	require.NoError(t, err)
+	return message
+}
+
+func TestCertScepController_RA(t *testing.T) {
+	issuer, repository := newTestScepIssuer(t)
+	controller := appcontrollers.NewScepController(memoryrepository.NewScepChallengeRepository(), repository.PrivateKey, repository.CertificateRevocation, time.Hour)
+
+	ra, key, err := controller.RA(issuer)
+	require.NoError(t, err)
+	assert.Equal(t, appcontrollers.ScepRaCommonName, ra.CommonName())
+	assert.IsType(t, &rsa.PrivateKey{}, key.PrivateKey())
+	assert.NoError(t, ra.Certificate().CheckSignatureFrom(issuer.Certificate().Certificate()))
+
+	again, _, err := controller.RA(issuer)
+	require.NoError(t, err)
+	assert.Equal(t, ra.SerialNumber(), again.SerialNumber())
+
+	assert.Len(t, controller.CACertificates(issuer), 1)
+	assert.Contains(t, controller.Capabilities(), "SCEPStandard")
+}
+
+// failingChildrenCertificateController fails to list the child
+// certificates
+type failingChildrenCertificateController struct {
+	appmodels.CertificateController
+}
+
+func (c *failingChildrenCertificateController) ChildCertificateCollection(string) ([]appmodels.Certificate, error) {
+	return nil, errors.New("storage failure")
+}
+
+func TestCertScepController_RAListFails(t *testing.T) {
+	issuer, repository := newTestScepIssuer(t)
+	controller := appcontrollers.NewScepController(memoryrepository.NewScepChallengeRepository(), repository.PrivateKey, repository.CertificateRevocation, time.Hour)
+
+	_, _, err := controller.RA(&failingChildrenCertificateController{issuer})
+	assert.ErrorContains(t, err, "storage failure")
+
+	// No RA certificate was created
+	children, err := issuer.ChildCertificateCollection("client")
+	require.NoError(t, err)
+	assert.Empty(t, children)
+}
+
+func TestCertScepController_PKIOperation(t *testing.T) {
+	issuer, repository := newTestScepIssuer(t)
+	controller := appcontrollers.NewScepController(memoryrepository.NewScepChallengeRepository(), repository.PrivateKey, repository.CertificateRevocation, time.Hour)
+
+	ra, _, err := controller.RA(issuer)
+	require.NoError(t, err)
+	signer, key := newTestScepClient(t, "device-1")
+
+	// No challenge password is configured yet
+	response, err := controller.PKIOperation(issuer, newTestScepMessage(t, apputils.SCEP_MESSAGE_PKCS_REQ, ra, signer, key, "device-1", "secret"))
+	require.NoError(t, err)
+	parsed, err := apputils.ParseScepResponse(response, signer, key)
+	require.NoError(t, err)
+	assert.Equal(t, apputils.SCEP_STATUS_FAILURE, parsed.Status)
+	assert.Equal(t, apputils.SCEP_FAIL_BAD_REQUEST, parsed.FailInfo)
+
+	_, err = controller.SetChallengePassword(issuer.OrganizationID(), "secret")
+	require.NoError(t, err)
+
+	response, err = controller.PKIOperation(issuer, newTestScepMessage(t, apputils.SCEP_MESSAGE_PKCS_REQ, ra, signer, key, "device-1", "wrong"))
+	require.NoError(t, err)
+	parsed, err = apputils.ParseScepResponse(response, signer, key)
+	require.NoError(t, err)
+	assert.Equal(t, apputils.SCEP_STATUS_FAILURE, parsed.Status)
+
+	// Content which is not a certificate request fails like content which
+	// cannot be decrypted
+	for _, recipient := range []*x509.Certificate{ra.Certificate(), signer} {
+		message, err := apputils.EncodeScepRequest(apputils.SCEP_MESSAGE_PKCS_REQ, "transaction", []byte("nonce"), []byte("not a request"), recipient, signer, key, apputils.Pkcs7Aes128CbcOID)
+		require.NoError(t, err)
+		response, err = controller.PKIOperation(issuer, message)
+		require.NoError(t, err)
+		parsed, err = apputils.ParseScepResponse(response, signer, key)
+		require.NoError(t, err)
+		assert.Equal(t, apputils.SCEP_STATUS_FAILURE, parsed.Status)
+		assert.Equal(t, apputils.SCEP_FAIL_BAD_MESSAGE_CHECK, parsed.FailInfo)
+	}
+
+	response, err = controller.PKIOperation(issuer, newTestScepMessage(t, apputils.SCEP_MESSAGE_PKCS_REQ, ra, signer, key, "device-1", "secret"))
+	require.NoError(t, err)
+	parsed, err = apputils.ParseScepResponse(response, signer, key)
+	require.NoError(t, err)
+	require.Equal(t, apputils.SCEP_STATUS_SUCCESS, parsed.Status)
+	assert.Equal(t, "transaction", parsed.TransactionID)
+	assert.Equal(t, []byte("nonce"), parsed.RecipientNonce)
+	require.Len(t, parsed.Certificates, 1)
+	issued := parsed.Certificates[0]
+	assert.Equal(t, "device-1", issued.Subject.CommonName)
+	assert.NoError(t, issued.CheckSignatureFrom(issuer.Certificate().Certificate()))
+
+	// Renewal is signed with the issued certificate and needs no password
+	response, err = controller.PKIOperation(issuer, newTestScepMessage(t, apputils.SCEP_MESSAGE_RENEWAL_REQ, ra, issued, key, "device-1", ""))
+	require.NoError(t, err)
+	parsed, err = apputils.ParseScepResponse(response, issued, key)
+	require.NoError(t, err)
+	require.Equal(t, apputils.SCEP_STATUS_SUCCESS, parsed.Status)
+	assert.Equal(t, "device-1", parsed.Certificates[0].Subject.CommonName)
+
+	// Renewal of another client or with a self-signed certificate fails
+	response, err = controller.PKIOperation(issuer, newTestScepMessage(t, apputils.SCEP_MESSAGE_RENEWAL_REQ, ra, issued, key, "device-2", ""))
+	require.NoError(t, err)
+	parsed, err = apputils.ParseScepResponse(response, issued, key)
+	require.NoError(t, err)
+	assert.Equal(t, apputils.SCEP_FAIL_BAD_CERT_ID, parsed.FailInfo)
+
+	response, err = controller.PKIOperation(issuer, newTestScepMessage(t, apputils.SCEP_MESSAGE_RENEWAL_REQ, ra, signer, key, "device-1", ""))
+	require.NoError(t, err)
+	parsed, err = apputils.ParseScepResponse(response, signer, key)
+	require.NoError(t, err)
+	assert.Equal(t, apputils.SCEP_FAIL_BAD_CERT_ID, parsed.FailInfo)
+
+	// Renewal with a revoked certificate fails
+	_, err = repository.CertificateRevocation.Save(appmodels.NewCertificateRevocation(
+		issuer.OrganizationID(),
+		issuer.Certificate().SerialNumber(),
+		appmodels.NewRevokedCertificate(issued.SerialNumber, time.Now(), issued.NotAfter),
+	))
+	require.NoError(t, err)
+	response, err = controller.PKIOperation(issuer, newTestScepMessage(t, apputils.SCEP_MESSAGE_RENEWAL_REQ, ra, issued, key, "device-1", ""))
+	require.NoError(t, err)
+	parsed, err = apputils.ParseScepResponse(response, issued, key)
+	require.NoError(t, err)
+	assert.Equal(t, apputils.SCEP_STATUS_FAILURE, parsed.Status)
+	assert.Equal(t, apputils.SCEP_FAIL_BAD_CERT_ID, parsed.FailInfo)
+
+	// The RA common name is reserved
+	response, err = controller.PKIOperation(issuer, newTestScepMessage(t, apputils.SCEP_MESSAGE_PKCS_REQ, ra, signer, key, appcontrollers.ScepRaCommonName, "secret"))
+	require.NoError(t, err)
+	parsed, err = apputils.ParseScepResponse(response, signer, key)
+	require.NoError(t, err)
+	assert.Equal(t, apputils.SCEP_STATUS_FAILURE, parsed.Status)
+
+	_, err = controller.PKIOperation(issuer, []byte("invalid"))
+	assert.Error(t, err)
+}
+
+func TestCertScepController_SetChallengePassword(t *testing.T) {
+	controller := appcontrollers.NewScepController(memoryrepository.NewScepChallengeRepository(), memoryrepository.NewPrivateKeyRepository(), nil, time.Hour)
+
+	challenge, err := controller.SetChallengePassword(big.NewInt(1), "secret")
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), challenge.OrganizationID())
+	assert.NotEqual(t, []byte("secret"), challenge.PasswordHash())
+
+	_, err = controller.SetChallengePassword(big.NewInt(1), "")
+	assert.Error(t, err)
+
+	_, err = controller.SetChallengePassword(nil, "secret")
+	assert.Error(t, err)
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// CertSealController implements appmodels.SealController
+type CertSealController struct {
+
+	// config is the seal file
+	config appdtos.SealConfigDTO
+
+	// token is the required seal token. Every token is refused if it is
+	// empty.
+	token string
+
+	mutex sync.RWMutex
+
+	// key is the seal key, or nil while sealed
+	key []byte
+
+	// shares are the key shares provided since sealing
+	shares [][]byte
+}
+
+func (r *CertSealController) Authenticate(token string) error {
+	if r.token == "" || subtle.ConstantTimeCompare([]byte(r.token), []byte(token)) != 1 {
+		return appmodels.ErrSealPermissionDenied
+	}
+	return nil
+}
+
+func (r *CertSealController) IsSealed() bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.key == nil
+}
+
+func (r *CertSealController) Threshold() int {
+	return r.config.Threshold
+}
+
+func (r *CertSealController) Progress() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.shares)
+}
+
+func (r *CertSealController) Unseal(passphrase string) error {
+	key, err := apputils.OpenSealConfig(r.config, passphrase)
+	if err != nil {
+		return fmt.Errorf("[Unseal]: %w", err)
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.setKey(key)
+	return nil
+}
+
+func (r *CertSealController) UnsealShare(share []byte) error {
+	if r.config.Threshold == 0 {
+		return errors.New("[UnsealShare]: the seal key has no shares")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.key != nil {
+		return nil
+	}
+	for _, existing := range r.shares {
+		if bytes.Equal(existing, share) {
+			return errors.New("[UnsealShare]: the share has been provided already")
+		}
+	}
+	r.shares = append(r.shares, bytes.Clone(share))
+	log.Printf("[UnsealShare]: %d of %d key shares provided", len(r.shares), r.config.Threshold)
+	if len(r.shares) < r.config.Threshold {
+		return nil
+	}
+
+	// A wrong share restarts the unseal, since it cannot be told which
+	// share was wrong
+	key, err := apputils.CombineSealKeyShares(r.config, r.shares)
+	if err != nil {
+		r.wipeShares()
+		return fmt.Errorf("[UnsealShare]: %w", err)
+	}
+	r.setKey(key)
+	return nil
+}
+
+func (r *CertSealController) Seal() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	clear(r.key)
+	r.key = nil
+	r.wipeShares()
+	log.Printf("[Seal]: Sealed")
+}
+
+func (r *CertSealController) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if r.key == nil {
+		return nil, appmodels.ErrSealed
+	}
+	return apputils.SealEncrypt(r.key, plaintext, additionalData)
+}
+
+func (r *CertSealController) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if r.key == nil {
+		return nil, appmodels.ErrSealed
+	}
+	return apputils.SealDecrypt(r.key, ciphertext, additionalData)
+}
+
+// setKey keeps the seal key and wipes the key shares. The mutex must be
+// locked.
+func (r *CertSealController) setKey(key []byte) {
+	clear(r.key)
+	r.key = key
+	r.wipeShares()
+	log.Printf("[Unseal]: Unsealed")
+}
+
+// wipeShares overwrites and forgets the key shares. The mutex must be
+// locked.
+func (r *CertSealController) wipeShares() {
+	for _, share := range r.shares {
+		clear(share)
+	}
+	r.shares = nil
+}
+
+// NewSealController creates a sealed seal controller
+//   - config: The seal file
+//   - token: The required seal token, or empty to refuse every token
+func NewSealController(
+	config appdtos.SealConfigDTO,
+	token string,
+) *CertSealController {
+	return &CertSealController{
+		config: config,
+		token:  token,
+	}
+}
+
+var _ appmodels.SealController = (*CertSealController)(nil)
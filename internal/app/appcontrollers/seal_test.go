@@ -0,0 +1,91 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+func TestCertSealController_Authenticate(t *testing.T) {
+	config, _, _, err := apputils.NewSealConfig("secret", 0, 0)
+	require.NoError(t, err)
+
+	controller := appcontrollers.NewSealController(config, "token")
+	assert.NoError(t, controller.Authenticate("token"))
+	assert.ErrorIs(t, controller.Authenticate("wrong"), appmodels.ErrSealPermissionDenied)
+
+	// Without a token every client is refused
+	closed := appcontrollers.NewSealController(config, "")
+	assert.ErrorIs(t, closed.Authenticate(""), appmodels.ErrSealPermissionDenied)
+}
+
+func TestCertSealController_Unseal(t *testing.T) {
+	config, _, _, err := apputils.NewSealConfig("secret", 0, 0)
+	require.NoError(t, err)
+	controller := appcontrollers.NewSealController(config, "")
+
+	assert.True(t, controller.IsSealed())
+	assert.Equal(t, 0, controller.Threshold())
+	_, err = controller.Encrypt([]byte("data"), nil)
+	assert.ErrorIs(t, err, appmodels.ErrSealed)
+
+	assert.Error(t, controller.Unseal("wrong"))
+	assert.True(t, controller.IsSealed())
+	assert.Error(t, controller.UnsealShare([]byte("share")), "no shares")
+
+	require.NoError(t, controller.Unseal("secret"))
+	assert.False(t, controller.IsSealed())
+	ciphertext, err := controller.Encrypt([]byte("data"), []byte("ad"))
+	require.NoError(t, err)
+	plaintext, err := controller.Decrypt(ciphertext, []byte("ad"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), plaintext)
+
+	controller.Seal()
+	assert.True(t, controller.IsSealed())
+	_, err = controller.Decrypt(ciphertext, []byte("ad"))
+	assert.ErrorIs(t, err, appmodels.ErrSealed)
+}
+
+func TestCertSealController_UnsealShare(t *testing.T) {
+	config, _, shares, err := apputils.NewSealConfig("secret", 5, 3)
+	require.NoError(t, err)
+	controller := appcontrollers.NewSealController(config, "")
+	assert.Equal(t, 3, controller.Threshold())
+
+	require.NoError(t, controller.UnsealShare(shares[0]))
+	assert.Error(t, controller.UnsealShare(shares[0]), "duplicate share")
+	require.NoError(t, controller.UnsealShare(shares[3]))
+	assert.Equal(t, 2, controller.Progress())
+	assert.True(t, controller.IsSealed())
+
+	// A wrong share restarts the unseal
+	wrong := append([]byte(nil), shares[4]...)
+	wrong[0] ^= 1
+	assert.Error(t, controller.UnsealShare(wrong))
+	assert.Equal(t, 0, controller.Progress())
+	assert.True(t, controller.IsSealed())
+
+	for _, share := range shares[2:] {
+		require.NoError(t, controller.UnsealShare(share))
+	}
+	assert.False(t, controller.IsSealed())
+	assert.Equal(t, 0, controller.Progress())
+
+	// Sealing wipes the provided shares
+	controller.Seal()
+	require.NoError(t, controller.UnsealShare(shares[1]))
+	controller.Seal()
+	assert.Equal(t, 0, controller.Progress())
+
+	// The passphrase unseals too
+	require.NoError(t, controller.Unseal("secret"))
+	assert.False(t, controller.IsSealed())
+}
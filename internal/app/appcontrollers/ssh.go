@@ -0,0 +1,315 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+const (
+
+	// DefaultSshAuthorityKeyType is the key type of SSH CA keys when none is
+	// given
+	DefaultSshAuthorityKeyType = appmodels.Ed25519
+
+	// SshClockSkew is how long before the signing time certificates become
+	// valid, to allow for clock differences between hosts
+	SshClockSkew = 5 * time.Minute
+)
+
+// CertSshController implements appmodels.SshController
+type CertSshController struct {
+	authorityRepository   appmodels.SshAuthorityRepository
+	certificateRepository appmodels.SshCertificateRepository
+	randomManager         managers.RandomManager
+
+	// expiration is the default validity duration of signed certificates
+	expiration time.Duration
+
+	// mu prevents concurrent requests from creating more than one CA key or
+	// certificates with the same serial number
+	mu sync.Mutex
+}
+
+func (r *CertSshController) Authority(organization *big.Int) (appmodels.SshAuthority, error) {
+	if organization == nil {
+		return nil, fmt.Errorf("[Authority]: organization: must be defined")
+	}
+	authority, err := r.authorityRepository.FindByOrganization(organization)
+	if err != nil {
+		return nil, fmt.Errorf("[%s:Authority]: %w", organization, err)
+	}
+	return authority, nil
+}
+
+func (r *CertSshController) NewAuthority(organization *big.Int, keyType appmodels.KeyType) (appmodels.SshAuthority, error) {
+
+	if organization == nil {
+		return nil, fmt.Errorf("[NewAuthority]: organization: must be defined")
+	}
+
+	if keyType == appmodels.NIL_KEY_TYPE {
+		keyType = DefaultSshAuthorityKeyType
+	}
+	if keyType == appmodels.ECDSA_P224 {
+		return nil, fmt.Errorf("[%s:NewAuthority]: key type not supported by OpenSSH: %s", organization, keyType)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.authorityRepository.FindByOrganization(organization); err == nil {
+		return nil, fmt.Errorf("[%s:NewAuthority]: already exists", organization)
+	} else if errors.Is(err, appmodels.ErrSealed) {
+		return nil, fmt.Errorf("[%s:NewAuthority]: %w", organization, err)
+	}
+
+	id, err := apputils.GenerateSerialNumber(r.randomManager)
+	if err != nil {
+		return nil, fmt.Errorf("[%s:NewAuthority]: failed to create ID: %w", organization, err)
+	}
+
+	privateKey, err := apputils.GeneratePrivateKey(organization, id, keyType)
+	if err != nil {
+		return nil, fmt.Errorf("[%s:NewAuthority]: failed to create private key: %w", organization, err)
+	}
+
+	saved, err := r.authorityRepository.Save(appmodels.NewSshAuthority(organization, id, privateKey, time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("[%s:NewAuthority]: failed to save: %w", organization, err)
+	}
+	log.Printf("[%s:NewAuthority]: Created SSH CA: %s", organization, id)
+	return saved, nil
+}
+
+func (r *CertSshController) Sign(
+	organization *big.Int,
+	publicKey ssh.PublicKey,
+	certificateType appmodels.SshCertificateType,
+	keyID string,
+	principals []string,
+	expiration time.Duration,
+	criticalOptions map[string]string,
+	extensions map[string]string,
+) (appmodels.SshCertificate, error) {
+
+	if publicKey == nil {
+		return nil, fmt.Errorf("[Sign]: publicKey: must be defined")
+	}
+
+	var certType uint32
+	switch certificateType {
+	case appmodels.SSH_USER_CERTIFICATE:
+		certType = ssh.UserCert
+	case appmodels.SSH_HOST_CERTIFICATE:
+		certType = ssh.HostCert
+	default:
+		return nil, fmt.Errorf("[Sign]: certificateType: unsupported: %s", certificateType)
+	}
+
+	if keyID == "" {
+		return nil, fmt.Errorf("[Sign]: keyID: must be defined")
+	}
+	if err := apputils.ValidateSshPrincipals(principals); err != nil {
+		return nil, fmt.Errorf("[Sign]: principals: %w", err)
+	}
+	if err := apputils.ValidateSshCriticalOptions(certificateType, criticalOptions); err != nil {
+		return nil, fmt.Errorf("[Sign]: criticalOptions: %w", err)
+	}
+	if extensions == nil && certificateType == appmodels.SSH_USER_CERTIFICATE {
+		extensions = apputils.SshDefaultUserExtensions
+	}
+	if err := apputils.ValidateSshExtensions(certificateType, extensions); err != nil {
+		return nil, fmt.Errorf("[Sign]: extensions: %w", err)
+	}
+
+	if expiration <= 0 {
+		expiration = r.expiration
+	}
+
+	authority, err := r.Authority(organization)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := apputils.NewSshSigner(authority.PrivateKey())
+	if err != nil {
+		return nil, fmt.Errorf("[%s:Sign]: %w", organization, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	serial, err := r.newSerial(organization)
+	if err != nil {
+		return nil, fmt.Errorf("[%s:Sign]: failed to create serial: %w", organization, err)
+	}
+
+	now := time.Now()
+	certificate := &ssh.Certificate{
+		Key:             publicKey,
+		Serial:          serial,
+		CertType:        certType,
+		KeyId:           keyID,
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(now.Add(-SshClockSkew).Unix()),
+		ValidBefore:     uint64(now.Add(expiration).Unix()),
+		Permissions: ssh.Permissions{
+			CriticalOptions: copySshOptions(criticalOptions),
+			Extensions:      copySshOptions(extensions),
+		},
+	}
+	if err := certificate.SignCert(rand.Reader, signer); err != nil {
+		return nil, fmt.Errorf("[%s:Sign]: failed to sign: %w", organization, err)
+	}
+
+	saved, err := r.certificateRepository.Save(appmodels.NewSshCertificate(organization, certificate, time.Time{}))
+	if err != nil {
+		return nil, fmt.Errorf("[%s:Sign]: failed to save: %w", organization, err)
+	}
+	log.Printf("[%s:Sign]: Signed %s certificate %d for '%s': %v", organization, apputils.SshCertificateTypeName(certificateType), serial, keyID, principals)
+	return saved, nil
+}
+
+func (r *CertSshController) Certificates(organization *big.Int) ([]appmodels.SshCertificate, error) {
+	if organization == nil {
+		return nil, fmt.Errorf("[Certificates]: organization: must be defined")
+	}
+	list, err := r.certificateRepository.FindAllByOrganization(organization)
+	if err != nil {
+		return nil, fmt.Errorf("[%s:Certificates]: %w", organization, err)
+	}
+	return list, nil
+}
+
+func (r *CertSshController) Certificate(organization *big.Int, serial uint64) (appmodels.SshCertificate, error) {
+	if organization == nil {
+		return nil, fmt.Errorf("[Certificate]: organization: must be defined")
+	}
+	certificate, err := r.certificateRepository.FindByOrganizationAndSerial(organization, serial)
+	if err != nil {
+		return nil, fmt.Errorf("[%s:Certificate]: %w", organization, err)
+	}
+	return certificate, nil
+}
+
+func (r *CertSshController) Revoke(organization *big.Int, serial uint64) (appmodels.SshCertificate, error) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	certificate, err := r.Certificate(organization, serial)
+	if err != nil {
+		return nil, err
+	}
+	if certificate.IsRevoked() {
+		return certificate, nil
+	}
+
+	saved, err := r.certificateRepository.Save(appmodels.NewSshCertificate(organization, certificate.Certificate(), time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("[%s:Revoke]: failed to save: %w", organization, err)
+	}
+	log.Printf("[%s:Revoke]: Revoked certificate: %d", organization, serial)
+	return saved, nil
+}
+
+func (r *CertSshController) RevocationList(organization *big.Int) ([]byte, error) {
+
+	authority, err := r.Authority(organization)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := apputils.NewSshSigner(authority.PrivateKey())
+	if err != nil {
+		return nil, fmt.Errorf("[%s:RevocationList]: %w", organization, err)
+	}
+
+	list, err := r.Certificates(organization)
+	if err != nil {
+		return nil, err
+	}
+
+	// The version is the time of the latest revocation, so it only changes
+	// when the list changes
+	var version uint64
+	var serials []uint64
+	for _, certificate := range list {
+		if !certificate.IsRevoked() {
+			continue
+		}
+		serials = append(serials, certificate.Serial())
+		if revokedAt := uint64(certificate.RevokedAt().Unix()); revokedAt > version {
+			version = revokedAt
+		}
+	}
+
+	krl, err := apputils.EncodeSshKrl(signer.PublicKey(), serials, version, time.Now(), "")
+	if err != nil {
+		return nil, fmt.Errorf("[%s:RevocationList]: %w", organization, err)
+	}
+	return krl, nil
+}
+
+// newSerial returns a random serial number which is not used in the
+// organization. Zero is not used since OpenSSH treats it as unset.
+func (r *CertSshController) newSerial(organization *big.Int) (uint64, error) {
+	max := new(big.Int).Lsh(big.NewInt(1), 63)
+	for {
+		value, err := r.randomManager.CreateBigInt(max)
+		if err != nil {
+			return 0, err
+		}
+		serial := value.Uint64()
+		if serial == 0 {
+			continue
+		}
+		if _, err := r.certificateRepository.FindByOrganizationAndSerial(organization, serial); err != nil {
+			return serial, nil
+		}
+	}
+}
+
+// copySshOptions returns a copy of options, or nil if there are none
+func copySshOptions(options map[string]string) map[string]string {
+	if len(options) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(options))
+	for name, value := range options {
+		result[name] = value
+	}
+	return result
+}
+
+// NewSshController creates an SSH certificate authority controller
+//   - authorityRepository: The repository for SSH CA keys
+//   - certificateRepository: The repository for signed certificates
+//   - randomManager: The random manager for key IDs and serial numbers
+//   - expiration: The default validity duration of signed certificates
+func NewSshController(
+	authorityRepository appmodels.SshAuthorityRepository,
+	certificateRepository appmodels.SshCertificateRepository,
+	randomManager managers.RandomManager,
+	expiration time.Duration,
+) *CertSshController {
+	return &CertSshController{
+		authorityRepository:   authorityRepository,
+		certificateRepository: certificateRepository,
+		randomManager:         randomManager,
+		expiration:            expiration,
+	}
+}
+
+var _ appmodels.SshController = (*CertSshController)(nil)
@@ -0,0 +1,184 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestSshController() *appcontrollers.CertSshController {
+	return appcontrollers.NewSshController(
+		memoryrepository.NewSshAuthorityRepository(),
+		memoryrepository.NewSshCertificateRepository(),
+		managers.NewRandomManager(),
+		time.Hour,
+	)
+}
+
+func newTestSshPublicKey(t *testing.T) ssh.PublicKey {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	require.NoError(t, err)
+	return sshPublicKey
+}
+
+func TestCertSshController_NewAuthority(t *testing.T) {
+	controller := newTestSshController()
+	organization := big.NewInt(1)
+
+	_, err := controller.Authority(organization)
+	assert.Error(t, err)
+
+	_, err = controller.NewAuthority(organization, appmodels.ECDSA_P224)
+	assert.Error(t, err)
+
+	authority, err := controller.NewAuthority(organization, appmodels.NIL_KEY_TYPE)
+	require.NoError(t, err)
+	assert.Equal(t, organization, authority.OrganizationID())
+	assert.Equal(t, appcontrollers.DefaultSshAuthorityKeyType, authority.PrivateKey().KeyType())
+
+	found, err := controller.Authority(organization)
+	require.NoError(t, err)
+	assert.Equal(t, authority, found)
+
+	_, err = controller.NewAuthority(organization, appmodels.RSA_2048)
+	assert.Error(t, err)
+
+	_, err = controller.NewAuthority(nil, appmodels.Ed25519)
+	assert.Error(t, err)
+}
+
+func TestCertSshController_Sign(t *testing.T) {
+	for _, keyType := range []appmodels.KeyType{appmodels.RSA_2048, appmodels.ECDSA_P256, appmodels.Ed25519} {
+		t.Run(keyType.String(), func(t *testing.T) {
+			controller := newTestSshController()
+			organization := big.NewInt(1)
+			authority, err := controller.NewAuthority(organization, keyType)
+			require.NoError(t, err)
+			caSigner, err := apputils.NewSshSigner(authority.PrivateKey())
+			require.NoError(t, err)
+
+			publicKey := newTestSshPublicKey(t)
+			certificate, err := controller.Sign(
+				organization,
+				publicKey,
+				appmodels.SSH_USER_CERTIFICATE,
+				"alice@example.com",
+				[]string{"alice"},
+				0,
+				map[string]string{"source-address": "10.0.0.0/8"},
+				nil,
+			)
+			require.NoError(t, err)
+			assert.Equal(t, appmodels.SSH_USER_CERTIFICATE, certificate.Type())
+			assert.Equal(t, "alice@example.com", certificate.KeyID())
+			assert.Equal(t, []string{"alice"}, certificate.Principals())
+			assert.NotZero(t, certificate.Serial())
+			assert.WithinDuration(t, time.Now().Add(time.Hour), certificate.ValidBefore(), time.Minute)
+			assert.Equal(t, apputils.SshDefaultUserExtensions, certificate.Certificate().Extensions)
+			assert.Equal(t, "10.0.0.0/8", certificate.Certificate().CriticalOptions["source-address"])
+
+			checker := &ssh.CertChecker{
+				IsUserAuthority: func(auth ssh.PublicKey) bool {
+					return string(auth.Marshal()) == string(caSigner.PublicKey().Marshal())
+				},
+			}
+			assert.NoError(t, checker.CheckCert("alice", certificate.Certificate()))
+			assert.Error(t, checker.CheckCert("root", certificate.Certificate()))
+
+			host, err := controller.Sign(organization, publicKey, appmodels.SSH_HOST_CERTIFICATE, "host", []string{"host.example.com"}, time.Minute, nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, uint32(ssh.HostCert), host.Certificate().CertType)
+			assert.Empty(t, host.Certificate().Extensions)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), host.ValidBefore(), time.Minute)
+
+			list, err := controller.Certificates(organization)
+			require.NoError(t, err)
+			assert.Len(t, list, 2)
+
+			found, err := controller.Certificate(organization, certificate.Serial())
+			require.NoError(t, err)
+			assert.Equal(t, certificate, found)
+		})
+	}
+}
+
+func TestCertSshController_SignInvalid(t *testing.T) {
+	controller := newTestSshController()
+	organization := big.NewInt(1)
+	publicKey := newTestSshPublicKey(t)
+
+	// No CA yet
+	_, err := controller.Sign(organization, publicKey, appmodels.SSH_USER_CERTIFICATE, "alice", []string{"alice"}, 0, nil, nil)
+	assert.Error(t, err)
+
+	_, err = controller.NewAuthority(organization, appmodels.Ed25519)
+	require.NoError(t, err)
+
+	_, err = controller.Sign(organization, nil, appmodels.SSH_USER_CERTIFICATE, "alice", []string{"alice"}, 0, nil, nil)
+	assert.Error(t, err)
+	_, err = controller.Sign(organization, publicKey, appmodels.NIL_SSH_CERTIFICATE_TYPE, "alice", []string{"alice"}, 0, nil, nil)
+	assert.Error(t, err)
+	_, err = controller.Sign(organization, publicKey, appmodels.SSH_USER_CERTIFICATE, "", []string{"alice"}, 0, nil, nil)
+	assert.Error(t, err)
+	_, err = controller.Sign(organization, publicKey, appmodels.SSH_USER_CERTIFICATE, "alice", nil, 0, nil, nil)
+	assert.Error(t, err)
+	_, err = controller.Sign(organization, publicKey, appmodels.SSH_HOST_CERTIFICATE, "host", []string{"host"}, 0, map[string]string{"force-command": "/bin/true"}, nil)
+	assert.Error(t, err)
+	_, err = controller.Sign(organization, publicKey, appmodels.SSH_USER_CERTIFICATE, "alice", []string{"alice"}, 0, nil, map[string]string{"unknown": ""})
+	assert.Error(t, err)
+}
+
+func TestCertSshController_Revoke(t *testing.T) {
+	controller := newTestSshController()
+	organization := big.NewInt(1)
+	authority, err := controller.NewAuthority(organization, appmodels.Ed25519)
+	require.NoError(t, err)
+	caSigner, err := apputils.NewSshSigner(authority.PrivateKey())
+	require.NoError(t, err)
+
+	empty, err := controller.RevocationList(organization)
+	require.NoError(t, err)
+	expected, err := apputils.EncodeSshKrl(caSigner.PublicKey(), nil, 0, time.Now(), "")
+	require.NoError(t, err)
+	assert.Len(t, empty, len(expected))
+
+	certificate, err := controller.Sign(organization, newTestSshPublicKey(t), appmodels.SSH_USER_CERTIFICATE, "alice", []string{"alice"}, 0, nil, nil)
+	require.NoError(t, err)
+
+	revoked, err := controller.Revoke(organization, certificate.Serial())
+	require.NoError(t, err)
+	assert.True(t, revoked.IsRevoked())
+
+	again, err := controller.Revoke(organization, certificate.Serial())
+	require.NoError(t, err)
+	assert.Equal(t, revoked.RevokedAt(), again.RevokedAt())
+
+	krl, err := controller.RevocationList(organization)
+	require.NoError(t, err)
+	expected, err = apputils.EncodeSshKrl(caSigner.PublicKey(), []uint64{certificate.Serial()}, uint64(revoked.RevokedAt().Unix()), time.Now(), "")
+	require.NoError(t, err)
+	assert.Equal(t, expected[:20], krl[:20])
+	assert.Equal(t, expected[28:], krl[28:])
+
+	_, err = controller.Revoke(organization, certificate.Serial()+1)
+	assert.Error(t, err)
+
+	_, err = controller.RevocationList(big.NewInt(2))
+	assert.Error(t, err)
+}
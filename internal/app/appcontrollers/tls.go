@@ -0,0 +1,588 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// DefaultSystemOrganizationID is the ID of the organization which issues the
+// certificates of the server itself, unless another ID is configured
+const DefaultSystemOrganizationID = 1
+
+// SystemOrganizationSlug is the slug of a new system organization. The system
+// organization is found by its ID, not by the slug.
+const SystemOrganizationSlug = "system"
+
+// SystemOrganizationName is the name of the system organization
+const SystemOrganizationName = "gocertcenter system"
+
+// SystemRootCommonName is the common name of root certificates of the
+// system organization
+const SystemRootCommonName = "gocertcenter system root"
+
+// DefaultTlsRootExpiration is the lifetime of system root certificates
+const DefaultTlsRootExpiration = 10 * 365 * 24 * time.Hour
+
+// DefaultTlsExpiration is the lifetime of server certificates
+const DefaultTlsExpiration = 30 * 24 * time.Hour
+
+// DefaultTlsRefreshInterval is how often the server certificate is checked
+// for renewal
+const DefaultTlsRefreshInterval = time.Minute
+
+// CertTlsController implements appmodels.TlsController
+type CertTlsController struct {
+	appController appmodels.ApplicationController
+
+	// organizationID is the ID of the system organization
+	organizationID *big.Int
+
+	// revocationRepository is optional. Revoked server certificates are not
+	// reused when it is set.
+	revocationRepository appmodels.CertificateRevocationRepository
+
+	// hostnames are the DNS names of the server certificate
+	hostnames []string
+
+	// rootExpiration is the lifetime of a new system root certificate
+	rootExpiration time.Duration
+
+	// expiration is the lifetime of a server certificate
+	expiration time.Duration
+
+	// mu protects the fields below
+	mu             sync.RWMutex
+	organization   appmodels.Organization
+	root           appmodels.Certificate
+	certificate    appmodels.Certificate
+	tlsCertificate *tls.Certificate
+}
+
+func (r *CertTlsController) Bootstrap() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	organization, err := r.systemOrganization()
+	if err != nil {
+		return fmt.Errorf("[Bootstrap]: %w", err)
+	}
+	r.organization = organization
+	now := time.Now()
+	reused, err := r.reuse(now)
+	if err != nil {
+		return fmt.Errorf("[Bootstrap]: %w", err)
+	}
+	if reused {
+		return nil
+	}
+	if err := r.issue(now); err != nil {
+		return fmt.Errorf("[Bootstrap]: %w", err)
+	}
+	return nil
+}
+
+// SetRevocationRepository sets the repository used to skip revoked server
+// certificates on Bootstrap
+func (r *CertTlsController) SetRevocationRepository(repository appmodels.CertificateRevocationRepository) {
+	r.revocationRepository = repository
+}
+
+func (r *CertTlsController) Organization() appmodels.Organization {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.organization
+}
+
+func (r *CertTlsController) RootCertificate() appmodels.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.root
+}
+
+func (r *CertTlsController) Certificate() appmodels.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.certificate
+}
+
+func (r *CertTlsController) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.tlsCertificate == nil {
+		return nil, errors.New("[GetCertificate]: no server certificate")
+	}
+	return r.tlsCertificate, nil
+}
+
+func (r *CertTlsController) Refresh(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.organization == nil {
+		return errors.New("[Refresh]: not bootstrapped")
+	}
+	if r.certificate != nil && !apputils.ShouldRenewCertificate(r.certificate, now) {
+		return nil
+	}
+	if err := r.issue(now); err != nil {
+		return fmt.Errorf("[Refresh]: %w", err)
+	}
+	return nil
+}
+
+// Run renews the server certificate until the context is cancelled
+//   - ctx: The context
+//   - interval: How often the certificate is checked
+func (r *CertTlsController) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := r.Refresh(time.Now()); err != nil {
+			log.Printf("[Run]: %v", err)
+		}
+	}
+}
+
+// systemOrganization finds the system organization by its ID, or creates it
+// on the first start
+func (r *CertTlsController) systemOrganization() (appmodels.Organization, error) {
+	if organization, err := r.appController.Organization(r.organizationID); err == nil {
+		return organization, nil
+	}
+
+	// Leaf keys are retained, so that the server certificate can be reused
+	// after a restart
+	organization, err := r.appController.NewOrganization(appmodels.NewOrganization(
+		r.organizationID,
+		SystemOrganizationSlug,
+		[]string{SystemOrganizationName},
+		appmodels.NIL_SIGNATURE_ALGORITHM,
+		"",
+		appmodels.KEY_RETENTION_RETAIN,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the system organization: %w", err)
+	}
+	log.Printf("[TLS] Created the system organization: %s", organization.ID())
+	return organization, nil
+}
+
+// reuse takes the server certificate of an earlier start in use if it is
+// issued for the same hostnames by a valid root certificate, is not past its
+// half-life nor revoked, and its private key is available. Returns false if
+// no certificate can be reused. The mutex must be locked.
+func (r *CertTlsController) reuse(now time.Time) (bool, error) {
+	organizationController, err := r.appController.OrganizationController(r.organization.ID())
+	if err != nil {
+		return false, err
+	}
+	roots, err := organizationController.CertificateCollection()
+	if err != nil {
+		return false, fmt.Errorf("failed to find certificates: %w", err)
+	}
+
+	var found, foundRoot appmodels.Certificate
+	var foundTlsCertificate *tls.Certificate
+	for _, root := range apputils.FilterValidRootCertificates(roots, now) {
+		rootController, err := organizationController.CertificateController(root.SerialNumber())
+		if err != nil {
+			return false, err
+		}
+		children, err := rootController.ChildCertificateCollection("server")
+		if err != nil {
+			return false, fmt.Errorf("failed to find server certificates: %w", err)
+		}
+		for _, certificate := range children {
+			if now.Before(certificate.NotBefore()) || apputils.ShouldRenewCertificate(certificate, now) {
+				continue
+			}
+			if !r.matchesHostnames(certificate) || r.isRevoked(certificate) {
+				continue
+			}
+
+			// The key is not available if it was sealed or not retained
+			controller, err := rootController.ChildCertificateController(certificate.SerialNumber())
+			if err != nil {
+				continue
+			}
+			privateKey, err := controller.PrivateKey()
+			if err != nil {
+				continue
+			}
+			tlsCertificate, err := apputils.ToTlsCertificate([]appmodels.Certificate{certificate}, privateKey)
+			if err != nil {
+				continue
+			}
+			if found == nil || certificate.NotAfter().After(found.NotAfter()) {
+				found, foundRoot, foundTlsCertificate = certificate, root, tlsCertificate
+			}
+		}
+	}
+	if found == nil {
+		return false, nil
+	}
+
+	r.root = foundRoot
+	r.certificate = found
+	r.tlsCertificate = foundTlsCertificate
+	log.Printf("[TLS] Using server certificate %s for %s, expires %s", r.certificate.SerialNumber(), strings.Join(r.hostnames, ","), r.certificate.NotAfter().Format(time.RFC3339))
+	return true, nil
+}
+
+// matchesHostnames returns true if the certificate is issued for exactly the
+// hostnames of the controller
+func (r *CertTlsController) matchesHostnames(certificate appmodels.Certificate) bool {
+	names := certificate.Certificate().DNSNames
+	if len(names) != len(r.hostnames) {
+		return false
+	}
+	for _, hostname := range r.hostnames {
+		if !slices.Contains(names, hostname) {
+			return false
+		}
+	}
+	return true
+}
+
+// isRevoked returns true if the certificate has been revoked
+func (r *CertTlsController) isRevoked(certificate appmodels.Certificate) bool {
+	if r.revocationRepository == nil {
+		return false
+	}
+	_, err := r.revocationRepository.FindByOrganizationAndSerialNumber(certificate.OrganizationID(), certificate.SerialNumber())
+	return err == nil
+}
+
+// issue issues a new server certificate. A new root certificate is created
+// if no root certificate outlives the server certificate. The mutex must be
+// locked.
+func (r *CertTlsController) issue(now time.Time) error {
+	organizationController, err := r.appController.OrganizationController(r.organization.ID())
+	if err != nil {
+		return err
+	}
+
+	root, err := r.rootCertificate(organizationController, now)
+	if err != nil {
+		return err
+	}
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	if err != nil {
+		return err
+	}
+	rootController.SetExpirationDuration(r.expiration)
+	certificate, privateKey, err := rootController.NewServerCertificate(r.hostnames...)
+	if err != nil {
+		return fmt.Errorf("failed to issue server certificate: %w", err)
+	}
+	tlsCertificate, err := apputils.ToTlsCertificate([]appmodels.Certificate{certificate}, privateKey)
+	if err != nil {
+		return err
+	}
+
+	r.root = root
+	r.certificate = certificate
+	r.tlsCertificate = tlsCertificate
+	log.Printf("[TLS] Issued server certificate %s for %s, expires %s", certificate.SerialNumber(), strings.Join(r.hostnames, ","), certificate.NotAfter().Format(time.RFC3339))
+	return nil
+}
+
+// rootCertificate returns the valid system root certificate which expires
+// last, or creates a new one if none outlives a new server certificate
+func (r *CertTlsController) rootCertificate(organizationController appmodels.OrganizationController, now time.Time) (appmodels.Certificate, error) {
+	list, err := organizationController.CertificateCollection()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find certificates: %w", err)
+	}
+	var root appmodels.Certificate
+	for _, certificate := range apputils.FilterValidRootCertificates(list, now) {
+		if root == nil || certificate.NotAfter().After(root.NotAfter()) {
+			root = certificate
+		}
+	}
+	if root != nil && !root.NotAfter().Before(now.Add(r.expiration)) {
+		return root, nil
+	}
+
+	organizationController.SetExpirationDuration(r.rootExpiration)
+	root, err = organizationController.NewRootCertificate(SystemRootCommonName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create root certificate: %w", err)
+	}
+	log.Printf("[TLS] Created system root certificate %s, expires %s. Clients must trust it.", root.SerialNumber(), root.NotAfter().Format(time.RFC3339))
+	return root, nil
+}
+
+// NewTlsController creates a controller which issues and renews the server
+// certificate
+//   - appController: The application controller, which must not require
+//     approvals
+//   - organizationID: The ID of the system organization, or nil for
+//     DefaultSystemOrganizationID
+//   - hostnames: The DNS names of the server certificate
+//   - rootExpiration: The lifetime of new system root certificates
+//   - expiration: The lifetime of server certificates
+func NewTlsController(
+	appController appmodels.ApplicationController,
+	organizationID *big.Int,
+	hostnames []string,
+	rootExpiration time.Duration,
+	expiration time.Duration,
+) *CertTlsController {
+	if rootExpiration <= 0 {
+		rootExpiration = DefaultTlsRootExpiration
+	}
+	if expiration <= 0 {
+		expiration = DefaultTlsExpiration
+	}
+	if organizationID == nil {
+		organizationID = big.NewInt(DefaultSystemOrganizationID)
+	}
+	return &CertTlsController{
+		appController:  appController,
+		organizationID: organizationID,
+		hostnames:      hostnames,
+		rootExpiration: rootExpiration,
+		expiration:     expiration,
+	}
+}
+
+var _ appmodels.TlsController = (*CertTlsController)(nil)
+
+// TlsClientAuthorities provides the CA certificates of all organizations for
+// verifying TLS client certificates, e.g. in EST re-enrollment. Intermediate
+// certificates are included because clients often send only their own
+// certificate. The pool is rebuilt by Refresh, which Run calls in the
+// background, so that new CA certificates are trusted without a restart and
+// handshakes only read the current pool.
+type TlsClientAuthorities struct {
+	appController appmodels.ApplicationController
+
+	// revocationRepository is optional. Revoked client and intermediate
+	// certificates are rejected when it is set.
+	revocationRepository appmodels.CertificateRevocationRepository
+
+	// mu protects the fields below
+	mu   sync.RWMutex
+	pool *x509.CertPool
+
+	// organizations are the organization IDs of the CA certificates in the
+	// pool by their DER encoding
+	organizations map[string]*big.Int
+}
+
+// Pool returns the root and intermediate certificates of the last refresh,
+// or nil before the first refresh
+func (r *TlsClientAuthorities) Pool() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pool
+}
+
+// Refresh rebuilds the pool from the certificates valid at the given time.
+// The previous pool is kept if the certificates cannot be found.
+//   - now: The current time
+func (r *TlsClientAuthorities) Refresh(now time.Time) error {
+	pool, organizations, err := r.build(now)
+	if err != nil {
+		return fmt.Errorf("[Refresh]: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pool = pool
+	r.organizations = organizations
+	return nil
+}
+
+// Run rebuilds the pool until the context is cancelled
+//   - ctx: The context
+//   - interval: How often the pool is rebuilt
+func (r *TlsClientAuthorities) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := r.Refresh(time.Now()); err != nil {
+			log.Printf("[TLS] Using the previous client CA certificates: %v", err)
+		}
+	}
+}
+
+// Configure makes the TLS configuration verify client certificates, when
+// given, against the pool with VerifyPeerCertificate. Requests without a
+// client certificate are accepted as before. Resumed sessions, which skip
+// VerifyPeerCertificate, are verified again, so that a certificate revoked
+// since the first handshake is rejected.
+//   - config: The TLS configuration of the server
+func (r *TlsClientAuthorities) Configure(config *tls.Config) {
+	config.ClientAuth = tls.RequestClientCert
+	config.VerifyPeerCertificate = r.VerifyPeerCertificate
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if !state.DidResume {
+			return nil
+		}
+		rawCerts := make([][]byte, len(state.PeerCertificates))
+		for i, certificate := range state.PeerCertificates {
+			rawCerts[i] = certificate.Raw
+		}
+		return r.VerifyPeerCertificate(rawCerts, nil)
+	}
+}
+
+// VerifyPeerCertificate verifies a client certificate against the pool and
+// rejects it if it or an intermediate certificate of its chain is revoked.
+// The certificates verified by crypto/tls are not used, since the server
+// only requests client certificates.
+//   - rawCerts: The certificates sent by the client
+func (r *TlsClientAuthorities) VerifyPeerCertificate(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return nil
+	}
+	certificates := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		certificate, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("[VerifyPeerCertificate]: invalid client certificate: %w", err)
+		}
+		certificates[i] = certificate
+	}
+
+	r.mu.RLock()
+	pool, organizations := r.pool, r.organizations
+	r.mu.RUnlock()
+	if pool == nil {
+		return errors.New("[VerifyPeerCertificate]: client CA certificates are not available")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, certificate := range certificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+	chains, err := certificates[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return fmt.Errorf("[VerifyPeerCertificate]: %w", err)
+	}
+	for _, chain := range chains {
+		if !r.isChainRevoked(chain, organizations) {
+			return nil
+		}
+	}
+	return errors.New("[VerifyPeerCertificate]: client certificate is revoked")
+}
+
+// isChainRevoked returns true if a certificate of the chain is revoked. The
+// chain ends with a root or an intermediate certificate of the pool, and all
+// of its certificates belong to the organization of that certificate.
+func (r *TlsClientAuthorities) isChainRevoked(chain []*x509.Certificate, organizations map[string]*big.Int) bool {
+	if r.revocationRepository == nil || len(chain) == 0 {
+		return false
+	}
+	organization, exists := organizations[string(chain[len(chain)-1].Raw)]
+	if !exists {
+		return true
+	}
+	for _, certificate := range chain {
+		if _, err := r.revocationRepository.FindByOrganizationAndSerialNumber(organization, certificate.SerialNumber); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// build collects the valid CA certificates of all organizations
+func (r *TlsClientAuthorities) build(now time.Time) (*x509.CertPool, map[string]*big.Int, error) {
+	organizations, err := r.appController.OrganizationCollection()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find organizations: %w", err)
+	}
+	pool := x509.NewCertPool()
+	issuers := make(map[string]*big.Int)
+	add := func(certificate appmodels.Certificate) {
+		pool.AddCert(certificate.Certificate())
+		issuers[string(certificate.Certificate().Raw)] = certificate.OrganizationID()
+	}
+	for _, organization := range organizations {
+		organizationController, err := r.appController.OrganizationController(organization.ID())
+		if err != nil {
+			return nil, nil, err
+		}
+		roots, err := organizationController.CertificateCollection()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find certificates of %s: %w", organization.ID(), err)
+		}
+		for _, root := range apputils.FilterValidRootCertificates(roots, now) {
+			add(root)
+			rootController, err := organizationController.CertificateController(root.SerialNumber())
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := addIntermediateCertificates(add, rootController, now); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return pool, issuers, nil
+}
+
+// addIntermediateCertificates adds the valid intermediate certificates under
+// the certificate controller
+func addIntermediateCertificates(add func(appmodels.Certificate), parent appmodels.CertificateController, now time.Time) error {
+	children, err := parent.ChildCertificateCollection("intermediate")
+	if err != nil {
+		return fmt.Errorf("failed to find intermediate certificates: %w", err)
+	}
+	for _, child := range children {
+		if now.Before(child.NotBefore()) || now.After(child.NotAfter()) {
+			continue
+		}
+		add(child)
+		controller, err := parent.ChildCertificateController(child.SerialNumber())
+		if err != nil {
+			return err
+		}
+		if err := addIntermediateCertificates(add, controller, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewTlsClientAuthorities creates the client CA certificates of the TLS
+// server. Refresh must be called before the first handshake.
+//   - appController: The application controller, which must not require
+//     approvals
+//   - revocationRepository: The certificate revocation repository, or nil
+func NewTlsClientAuthorities(
+	appController appmodels.ApplicationController,
+	revocationRepository appmodels.CertificateRevocationRepository,
+) *TlsClientAuthorities {
+	return &TlsClientAuthorities{
+		appController:        appController,
+		revocationRepository: revocationRepository,
+	}
+}
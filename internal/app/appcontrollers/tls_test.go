@@ -0,0 +1,250 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTlsApplicationController() (appmodels.ApplicationController, *appmodels.Collection) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := memoryrepository.NewCollection()
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+	appController.SetRevocationRepository(repository.CertificateRevocation)
+	return appController, repository
+}
+
+func TestCertTlsController(t *testing.T) {
+	appController, _ := newTlsApplicationController()
+	controller := appcontrollers.NewTlsController(appController, nil, []string{"localhost", "ca.example.com"}, 0, 2*time.Hour)
+
+	_, err := controller.GetCertificate(nil)
+	assert.Error(t, err)
+	assert.Error(t, controller.Refresh(time.Now()))
+	assert.Nil(t, controller.Certificate())
+
+	require.NoError(t, controller.Bootstrap())
+	organization := controller.Organization()
+	require.NotNil(t, organization)
+	assert.Equal(t, 0, organization.ID().Cmp(big.NewInt(appcontrollers.DefaultSystemOrganizationID)))
+	assert.Equal(t, appcontrollers.SystemOrganizationSlug, organization.Slug())
+	root := controller.RootCertificate()
+	require.NotNil(t, root)
+	assert.True(t, root.IsRootCertificate())
+	assert.True(t, root.NotAfter().After(time.Now().Add(365*24*time.Hour)))
+
+	// The server certificate is valid for the hostnames under the root
+	tlsCertificate, err := controller.GetCertificate(nil)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(root.Certificate())
+	for _, hostname := range []string{"localhost", "ca.example.com"} {
+		_, err = tlsCertificate.Leaf.Verify(x509.VerifyOptions{DNSName: hostname, Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+		assert.NoError(t, err)
+	}
+	first := controller.Certificate()
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), first.NotAfter(), time.Minute)
+
+	// The certificate is renewed after its half-life
+	require.NoError(t, controller.Refresh(time.Now()))
+	assert.Equal(t, first, controller.Certificate())
+	require.NoError(t, controller.Refresh(time.Now().Add(time.Hour)))
+	assert.NotEqual(t, first.SerialNumber(), controller.Certificate().SerialNumber())
+	renewed, err := controller.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, tlsCertificate, renewed)
+
+	// Another start uses the same organization, root certificate and a still
+	// valid server certificate
+	organizationController, err := appController.OrganizationController(organization.ID())
+	require.NoError(t, err)
+	countServerCertificates := func() int {
+		certificateController, err := organizationController.CertificateController(root.SerialNumber())
+		require.NoError(t, err)
+		list, err := certificateController.ChildCertificateCollection("server")
+		require.NoError(t, err)
+		return len(list)
+	}
+	issued := countServerCertificates()
+	restarted := appcontrollers.NewTlsController(appController, nil, []string{"ca.example.com", "localhost"}, 0, 2*time.Hour)
+	require.NoError(t, restarted.Bootstrap())
+	assert.Equal(t, 0, organization.ID().Cmp(restarted.Organization().ID()))
+	assert.Equal(t, 0, root.SerialNumber().Cmp(restarted.RootCertificate().SerialNumber()))
+	assert.Equal(t, issued, countServerCertificates())
+	assert.Contains(t, []string{first.SerialNumber().String(), controller.Certificate().SerialNumber().String()}, restarted.Certificate().SerialNumber().String())
+	reused, err := restarted.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, restarted.Certificate().Certificate(), reused.Leaf)
+	assert.NotNil(t, reused.PrivateKey)
+	organizations, err := appController.OrganizationCollection()
+	require.NoError(t, err)
+	assert.Len(t, organizations, 1)
+
+	// A certificate is issued when the hostnames change
+	other := appcontrollers.NewTlsController(appController, nil, []string{"localhost"}, 0, 2*time.Hour)
+	require.NoError(t, other.Bootstrap())
+	assert.Equal(t, issued+1, countServerCertificates())
+	assert.Equal(t, []string{"localhost"}, other.Certificate().Certificate().DNSNames)
+
+	// A new root certificate is created when the root would expire before
+	// the server certificate
+	longer := appcontrollers.NewTlsController(appController, nil, []string{"ca.example.com"}, 0, 20*365*24*time.Hour)
+	require.NoError(t, longer.Bootstrap())
+	assert.NotEqual(t, 0, root.SerialNumber().Cmp(longer.RootCertificate().SerialNumber()))
+}
+
+func TestCertTlsController_RevokedCertificate(t *testing.T) {
+	appController, repository := newTlsApplicationController()
+	hostnames := []string{"localhost"}
+	controller := appcontrollers.NewTlsController(appController, nil, hostnames, 0, 2*time.Hour)
+	require.NoError(t, controller.Bootstrap())
+	first := controller.Certificate()
+
+	organizationController, err := appController.OrganizationController(controller.Organization().ID())
+	require.NoError(t, err)
+	_, err = organizationController.RevokeCertificate(first)
+	require.NoError(t, err)
+
+	// Without the revocation repository the revoked certificate is reused
+	unaware := appcontrollers.NewTlsController(appController, nil, hostnames, 0, 2*time.Hour)
+	require.NoError(t, unaware.Bootstrap())
+	assert.Equal(t, 0, first.SerialNumber().Cmp(unaware.Certificate().SerialNumber()))
+
+	restarted := appcontrollers.NewTlsController(appController, nil, hostnames, 0, 2*time.Hour)
+	restarted.SetRevocationRepository(repository.CertificateRevocation)
+	require.NoError(t, restarted.Bootstrap())
+	assert.NotEqual(t, 0, first.SerialNumber().Cmp(restarted.Certificate().SerialNumber()))
+}
+
+func TestCertTlsController_OrganizationID(t *testing.T) {
+	appController, _ := newTlsApplicationController()
+
+	// An organization with the system slug is not the system organization
+	_, err := appController.NewOrganization(appmodels.NewOrganization(
+		big.NewInt(7),
+		appcontrollers.SystemOrganizationSlug,
+		[]string{"Not the system"},
+		appmodels.NIL_SIGNATURE_ALGORITHM,
+		"",
+		appmodels.KEY_RETENTION_NONE,
+	))
+	require.NoError(t, err)
+
+	controller := appcontrollers.NewTlsController(appController, big.NewInt(42), []string{"localhost"}, 0, 2*time.Hour)
+	require.NoError(t, controller.Bootstrap())
+	assert.Equal(t, 0, big.NewInt(42).Cmp(controller.Organization().ID()))
+	assert.Equal(t, appmodels.KEY_RETENTION_RETAIN, controller.Organization().KeyRetentionPolicy())
+	organizations, err := appController.OrganizationCollection()
+	require.NoError(t, err)
+	assert.Len(t, organizations, 2)
+
+	restarted := appcontrollers.NewTlsController(appController, big.NewInt(42), []string{"localhost"}, 0, 2*time.Hour)
+	require.NoError(t, restarted.Bootstrap())
+	assert.Equal(t, 0, controller.Certificate().SerialNumber().Cmp(restarted.Certificate().SerialNumber()))
+}
+
+func TestTlsClientAuthorities(t *testing.T) {
+	appController, repository := newTlsApplicationController()
+	newOrganization := func(id int64) appmodels.OrganizationController {
+		_, err := appController.NewOrganization(appmodels.NewOrganization(
+			big.NewInt(id),
+			"org",
+			[]string{"Org"},
+			appmodels.NIL_SIGNATURE_ALGORITHM,
+			"",
+			appmodels.KEY_RETENTION_NONE,
+		))
+		require.NoError(t, err)
+		organizationController, err := appController.OrganizationController(big.NewInt(id))
+		require.NoError(t, err)
+		return organizationController
+	}
+
+	organizationController := newOrganization(1)
+	root, err := organizationController.NewRootCertificate("Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	intermediate, _, err := rootController.NewIntermediateCertificate("Intermediate")
+	require.NoError(t, err)
+	intermediateController, err := rootController.ChildCertificateController(intermediate.SerialNumber())
+	require.NoError(t, err)
+	client, _, err := intermediateController.NewClientCertificate("client")
+	require.NoError(t, err)
+
+	authorities := appcontrollers.NewTlsClientAuthorities(appController, repository.CertificateRevocation)
+	assert.Nil(t, authorities.Pool())
+	assert.Error(t, authorities.VerifyPeerCertificate([][]byte{client.Certificate().Raw}, nil))
+	require.NoError(t, authorities.Refresh(time.Now()))
+	assert.NotNil(t, authorities.Pool())
+
+	// A client which sends only its own certificate is verified, and a
+	// request without a certificate is accepted
+	assert.NoError(t, authorities.VerifyPeerCertificate([][]byte{client.Certificate().Raw}, nil))
+	assert.NoError(t, authorities.VerifyPeerCertificate(nil, nil))
+	assert.Error(t, authorities.VerifyPeerCertificate([][]byte{[]byte("invalid")}, nil))
+
+	// New organizations are trusted after the next refresh
+	otherController := newOrganization(2)
+	otherRoot, err := otherController.NewRootCertificate("Other")
+	require.NoError(t, err)
+	otherRootController, err := otherController.CertificateController(otherRoot.SerialNumber())
+	require.NoError(t, err)
+	otherClient, _, err := otherRootController.NewClientCertificate("other")
+	require.NoError(t, err)
+	assert.Error(t, authorities.VerifyPeerCertificate([][]byte{otherClient.Certificate().Raw}, nil))
+	require.NoError(t, authorities.Refresh(time.Now()))
+	assert.NoError(t, authorities.VerifyPeerCertificate([][]byte{otherClient.Certificate().Raw}, nil))
+
+	// Revoked client and intermediate certificates are rejected without a
+	// refresh
+	_, err = repository.CertificateRevocation.Save(appmodels.NewCertificateRevocation(
+		big.NewInt(2),
+		otherRoot.SerialNumber(),
+		appmodels.NewRevokedCertificate(otherClient.SerialNumber(), time.Now(), otherClient.NotAfter()),
+	))
+	require.NoError(t, err)
+	assert.ErrorContains(t, authorities.VerifyPeerCertificate([][]byte{otherClient.Certificate().Raw}, nil), "revoked")
+
+	assert.NoError(t, authorities.VerifyPeerCertificate([][]byte{client.Certificate().Raw, intermediate.Certificate().Raw}, nil))
+	_, err = repository.CertificateRevocation.Save(appmodels.NewCertificateRevocation(
+		big.NewInt(1),
+		root.SerialNumber(),
+		appmodels.NewRevokedCertificate(intermediate.SerialNumber(), time.Now(), intermediate.NotAfter()),
+	))
+	require.NoError(t, err)
+	assert.ErrorContains(t, authorities.VerifyPeerCertificate([][]byte{client.Certificate().Raw, intermediate.Certificate().Raw}, nil), "revoked")
+
+	// The TLS configuration requests client certificates and verifies them
+	// with the authorities, also on resumed sessions
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	authorities.Configure(config)
+	assert.Equal(t, tls.RequestClientCert, config.ClientAuth)
+	assert.Nil(t, config.GetConfigForClient)
+	require.NotNil(t, config.VerifyPeerCertificate)
+	require.NotNil(t, config.VerifyConnection)
+	assert.NoError(t, config.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{otherClient.Certificate()}}))
+	assert.Error(t, config.VerifyConnection(tls.ConnectionState{DidResume: true, PeerCertificates: []*x509.Certificate{otherClient.Certificate()}}))
+	assert.NoError(t, config.VerifyConnection(tls.ConnectionState{DidResume: true}))
+}
@@ -0,0 +1,394 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers
+
+import (
+	"crypto/subtle"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// VaultRevocationListValidity is how long CRLs of the Vault PKI API are valid
+const VaultRevocationListValidity = 72 * time.Hour
+
+// CertVaultController implements appmodels.VaultController. Errors which
+// should be reported to the Vault client are returned as
+// *appmodels.VaultError.
+type CertVaultController struct {
+	roleRepository       appmodels.VaultRoleRepository
+	revocationRepository appmodels.CertificateRevocationRepository
+	appController        appmodels.ApplicationController
+
+	// token is the required X-Vault-Token, or empty to accept any client
+	token string
+
+	// expiration is the expiration duration of issued certificates when
+	// neither the request nor the role defines one
+	expiration time.Duration
+}
+
+func (r *CertVaultController) Authenticate(token string) error {
+	if r.token == "" {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(r.token), []byte(token)) != 1 {
+		return appmodels.NewVaultError(appmodels.VAULT_ERROR_PERMISSION_DENIED, "permission denied")
+	}
+	return nil
+}
+
+func (r *CertVaultController) Role(organization *big.Int, name string) (appmodels.VaultRole, error) {
+	role, err := r.roleRepository.FindByOrganizationAndName(organization, name)
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_NOT_FOUND, "unknown role: %s", name)
+	}
+	return role, nil
+}
+
+func (r *CertVaultController) Roles(organization *big.Int) ([]appmodels.VaultRole, error) {
+	list, err := r.roleRepository.FindAllByOrganization(organization)
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "[%s:Roles]: %v", organization, err)
+	}
+	return list, nil
+}
+
+func (r *CertVaultController) SaveRole(role appmodels.VaultRole) (appmodels.VaultRole, error) {
+
+	if err := apputils.ValidateVaultRoleName(role.Name()); err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "name: %v", err)
+	}
+	if role.MaxTTL() != 0 && role.TTL() > role.MaxTTL() {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "ttl cannot be larger than max_ttl")
+	}
+	if _, err := r.Issuer(role.OrganizationID(), role.IssuerSerialNumber()); err != nil {
+		return nil, err
+	}
+
+	saved, err := r.roleRepository.Save(role)
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "[SaveRole]: failed to save: %v", err)
+	}
+	return saved, nil
+}
+
+func (r *CertVaultController) DeleteRole(organization *big.Int, name string) error {
+	if _, err := r.Role(organization, name); err != nil {
+		return err
+	}
+	if err := r.roleRepository.Delete(organization, name); err != nil {
+		return appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "[DeleteRole]: failed to delete: %v", err)
+	}
+	return nil
+}
+
+func (r *CertVaultController) Issue(role appmodels.VaultRole, commonName string, altNames []string, ttl time.Duration) (appmodels.Certificate, appmodels.PrivateKey, error) {
+
+	names, err := vaultNames(role, commonName, altNames)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	issuer, err := r.roleIssuer(role, ttl)
+	if err != nil {
+		return nil, nil, err
+	}
+	if role.KeyType() != appmodels.NIL_KEY_TYPE {
+		issuer.SetKeyType(role.KeyType())
+	}
+
+	var certificate appmodels.Certificate
+	var privateKey appmodels.PrivateKey
+	if role.ServerFlag() {
+		certificate, privateKey, err = issuer.NewServerCertificate(names...)
+	} else {
+		certificate, privateKey, err = issuer.NewClientCertificate(names[0])
+	}
+	if errors.Is(err, appmodels.ErrSealed) {
+		return nil, nil, fmt.Errorf("[Issue]: %w", err)
+	} else if err != nil {
+		return nil, nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "[Issue]: failed to issue certificate: %v", err)
+	}
+	return certificate, privateKey, nil
+}
+
+func (r *CertVaultController) Sign(role appmodels.VaultRole, csr *x509.CertificateRequest, commonName string, altNames []string, ttl time.Duration) (appmodels.Certificate, error) {
+
+	if csr == nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "csr must be defined")
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "invalid csr signature: %v", err)
+	}
+	if commonName == "" {
+		commonName = csr.Subject.CommonName
+	}
+	if role.ServerFlag() {
+		altNames = append(slices.Clone(altNames), csr.DNSNames...)
+	}
+
+	names, err := vaultNames(role, commonName, altNames)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer, err := r.roleIssuer(role, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := appmodels.NewPublicKey(csr.PublicKey)
+	var certificate appmodels.Certificate
+	if role.ServerFlag() {
+		certificate, err = issuer.NewServerCertificateFromPublicKey(publicKey, names...)
+	} else {
+		certificate, err = issuer.NewClientCertificateFromPublicKey(publicKey, names[0])
+	}
+	if errors.Is(err, appmodels.ErrSealed) {
+		return nil, fmt.Errorf("[Sign]: %w", err)
+	} else if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "[Sign]: failed to issue certificate: %v", err)
+	}
+	return certificate, nil
+}
+
+func (r *CertVaultController) Revoke(organization *big.Int, serialNumber *big.Int) (appmodels.CertificateRevocation, error) {
+
+	if revocation, err := r.revocationRepository.FindByOrganizationAndSerialNumber(organization, serialNumber); err == nil {
+		return revocation, nil
+	}
+
+	organizationController, err := r.appController.OrganizationController(organization)
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_NOT_FOUND, "organization not found: %s", organization)
+	}
+	certificate, err := organizationController.Certificate(serialNumber)
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "certificate with serial %s not found", apputils.FormatVaultSerialNumber(serialNumber))
+	}
+	if certificate.IsCA() {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "CA certificates cannot be revoked")
+	}
+
+	issuer, err := r.certificateIssuer(organizationController, certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	revocation, err := r.revocationRepository.Save(appmodels.NewCertificateRevocation(
+		organization,
+		issuer,
+		appmodels.NewRevokedCertificate(serialNumber, time.Now(), certificate.NotAfter()),
+	))
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "[Revoke]: failed to save: %v", err)
+	}
+	return revocation, nil
+}
+
+func (r *CertVaultController) Issuer(organization *big.Int, serialNumber *big.Int) (appmodels.CertificateController, error) {
+	organizationController, err := r.appController.OrganizationController(organization)
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_NOT_FOUND, "organization not found: %s", organization)
+	}
+	issuer, err := findCertificateController(organizationController, serialNumber)
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_NOT_FOUND, "issuer not found: %s", serialNumber)
+	}
+	if !issuer.Certificate().IsCA() {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "issuer is not a CA certificate: %s", serialNumber)
+	}
+	return issuer, nil
+}
+
+func (r *CertVaultController) DefaultIssuer(organization *big.Int) (appmodels.CertificateController, error) {
+	organizationController, err := r.appController.OrganizationController(organization)
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_NOT_FOUND, "organization not found: %s", organization)
+	}
+	list, err := organizationController.CertificateCollection()
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "[%s:DefaultIssuer]: %v", organization, err)
+	}
+	var newest appmodels.Certificate
+	for _, root := range apputils.FilterValidRootCertificates(list, time.Now()) {
+		if newest == nil || root.NotBefore().After(newest.NotBefore()) {
+			newest = root
+		}
+	}
+	if newest == nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_NOT_FOUND, "no default issuer")
+	}
+	issuer, err := organizationController.CertificateController(newest.SerialNumber())
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "[%s:DefaultIssuer]: %v", organization, err)
+	}
+	return issuer, nil
+}
+
+func (r *CertVaultController) CACertificates(issuer appmodels.CertificateController) []appmodels.Certificate {
+	var list []appmodels.Certificate
+	for current := issuer; current != nil; current = current.ParentCertificateController() {
+		list = append(list, current.Certificate())
+	}
+	return list
+}
+
+func (r *CertVaultController) RevocationList(issuer appmodels.CertificateController) ([]byte, error) {
+	privateKey, err := issuer.PrivateKey()
+	if errors.Is(err, appmodels.ErrSealed) {
+		return nil, fmt.Errorf("[RevocationList]: %w", err)
+	} else if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "[RevocationList]: private key: %v", err)
+	}
+	organization := issuer.OrganizationID()
+	serialNumber := issuer.Certificate().SerialNumber()
+	revocations, err := r.revocationRepository.FindAllByOrganizationAndIssuer(organization, serialNumber)
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "[RevocationList]: %v", err)
+	}
+	revoked := make([]appmodels.RevokedCertificate, len(revocations))
+	for i, revocation := range revocations {
+		revoked[i] = revocation.RevokedCertificate()
+	}
+
+	// The previous CRL is not found before the first CRL of the issuer
+	previous, _ := r.revocationRepository.FindRevocationList(organization, serialNumber)
+	now := time.Now()
+	number := apputils.NextRevocationListNumber(previous, now)
+
+	der, err := apputils.CreateCertificateRevocationList(issuer.Certificate(), privateKey, revoked, number, now, VaultRevocationListValidity)
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "[RevocationList]: %v", err)
+	}
+	if _, err := r.revocationRepository.SaveRevocationList(appmodels.NewRevocationList(organization, serialNumber, number, now)); err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "[RevocationList]: %v", err)
+	}
+	return der, nil
+}
+
+// roleIssuer returns the issuer of the role configured to issue certificates
+// with the requested validity duration
+func (r *CertVaultController) roleIssuer(role appmodels.VaultRole, ttl time.Duration) (appmodels.CertificateController, error) {
+	issuer, err := r.Issuer(role.OrganizationID(), role.IssuerSerialNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl <= 0 {
+		ttl = role.TTL()
+	}
+	if ttl <= 0 {
+		ttl = r.expiration
+	}
+	if role.MaxTTL() > 0 && ttl > role.MaxTTL() {
+		ttl = role.MaxTTL()
+	}
+
+	// Certificates must not outlive the issuer
+	if remaining := time.Until(issuer.Certificate().NotAfter()); ttl > remaining {
+		ttl = remaining
+	}
+
+	issuer.SetExpirationDuration(ttl)
+	return issuer, nil
+}
+
+// certificateIssuer returns the serial number of the CA which signed the
+// certificate. The signer recorded in the certificate and the issuers of the
+// roles are tried.
+func (r *CertVaultController) certificateIssuer(organizationController appmodels.OrganizationController, certificate appmodels.Certificate) (*big.Int, error) {
+	var candidates []*big.Int
+	if signedBy := certificate.SignedBy(); signedBy != nil && signedBy.Cmp(certificate.SerialNumber()) != 0 {
+		candidates = append(candidates, signedBy)
+	}
+	roles, err := r.Roles(organizationController.OrganizationID())
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		candidates = append(candidates, role.IssuerSerialNumber())
+	}
+	for _, candidate := range candidates {
+		issuer, err := findCertificateController(organizationController, candidate)
+		if err != nil {
+			continue
+		}
+		if certificate.Certificate().CheckSignatureFrom(issuer.Certificate().Certificate()) == nil {
+			return candidate, nil
+		}
+	}
+	return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "issuer of certificate %s not found", apputils.FormatVaultSerialNumber(certificate.SerialNumber()))
+}
+
+// vaultNames returns the common name followed by the unique alternative
+// names, when all of them are allowed by the role. Client certificates only
+// support the common name.
+func vaultNames(role appmodels.VaultRole, commonName string, altNames []string) ([]string, error) {
+
+	commonName = strings.ToLower(strings.TrimSpace(commonName))
+	if commonName == "" {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "the common_name field is required")
+	}
+
+	names := []string{commonName}
+	for _, name := range altNames {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		if !apputils.VaultRoleAllowsName(role, name) {
+			return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "name %s not allowed by this role", name)
+		}
+	}
+
+	if role.ServerFlag() {
+		if err := apputils.ValidateDNSNames(names); err != nil {
+			return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "dns names: %v", err)
+		}
+		return names, nil
+	}
+
+	if len(names) != 1 {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "alt_names are not supported by client certificate roles")
+	}
+	if err := apputils.ValidateClientCertificateCommonName(commonName); err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "common name: %v", err)
+	}
+	return names, nil
+}
+
+// NewVaultController creates a controller for the Vault PKI API
+//   - roleRepository: The Vault role repository
+//   - revocationRepository: The certificate revocation repository
+//   - appController: The application controller used to find issuers
+//   - token: The required X-Vault-Token, or empty to accept any client
+//   - expiration: The default expiration duration of issued certificates
+func NewVaultController(
+	roleRepository appmodels.VaultRoleRepository,
+	revocationRepository appmodels.CertificateRevocationRepository,
+	appController appmodels.ApplicationController,
+	token string,
+	expiration time.Duration,
+) *CertVaultController {
+	return &CertVaultController{
+		roleRepository:       roleRepository,
+		revocationRepository: revocationRepository,
+		appController:        appController,
+		token:                token,
+		expiration:           expiration,
+	}
+}
+
+var _ appmodels.VaultController = (*CertVaultController)(nil)
@@ -0,0 +1,234 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appcontrollers_test
+
+import (
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func requireVaultErrorType(t *testing.T, err error, errorType appmodels.VaultErrorType) {
+	var vaultErr *appmodels.VaultError
+	require.ErrorAs(t, err, &vaultErr)
+	assert.Equal(t, errorType, vaultErr.Type())
+}
+
+// newTestVaultController creates a Vault controller for an organization with
+// a root certificate
+func newTestVaultController(t *testing.T, token string) (*appcontrollers.CertVaultController, *big.Int, appmodels.Certificate) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := filerepository.NewCollection(certManager, managers.NewFileManager(), t.TempDir())
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+	organization := appmodels.NewSerialNumber(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	organizationController.SetExpirationDuration(24 * time.Hour)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	controller := appcontrollers.NewVaultController(
+		memoryrepository.NewVaultRoleRepository(),
+		memoryrepository.NewCertificateRevocationRepository(),
+		appController,
+		token,
+		time.Hour,
+	)
+	return controller, organization, root
+}
+
+func TestCertVaultController_Authenticate(t *testing.T) {
+	controller := appcontrollers.NewVaultController(nil, nil, nil, "secret", time.Hour)
+	assert.NoError(t, controller.Authenticate("secret"))
+	requireVaultErrorType(t, controller.Authenticate("wrong"), appmodels.VAULT_ERROR_PERMISSION_DENIED)
+	requireVaultErrorType(t, controller.Authenticate(""), appmodels.VAULT_ERROR_PERMISSION_DENIED)
+
+	open := appcontrollers.NewVaultController(nil, nil, nil, "", time.Hour)
+	assert.NoError(t, open.Authenticate(""))
+}
+
+func TestCertVaultController_Roles(t *testing.T) {
+	controller, organization, root := newTestVaultController(t, "")
+
+	role := appmodels.NewVaultRole("web", organization, root.SerialNumber(), []string{"example.com"}, true, false, false, true, appmodels.NIL_KEY_TYPE, 0, 0)
+	saved, err := controller.SaveRole(role)
+	require.NoError(t, err)
+	assert.Equal(t, role, saved)
+
+	_, err = controller.SaveRole(appmodels.NewVaultRole("bad/name", organization, root.SerialNumber(), nil, false, false, true, true, appmodels.NIL_KEY_TYPE, 0, 0))
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_BAD_REQUEST)
+
+	_, err = controller.SaveRole(appmodels.NewVaultRole("unknown", organization, big.NewInt(999), nil, false, false, true, true, appmodels.NIL_KEY_TYPE, 0, 0))
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_NOT_FOUND)
+
+	_, err = controller.SaveRole(appmodels.NewVaultRole("ttl", organization, root.SerialNumber(), nil, false, false, true, true, appmodels.NIL_KEY_TYPE, 2*time.Hour, time.Hour))
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_BAD_REQUEST)
+
+	found, err := controller.Role(organization, "web")
+	require.NoError(t, err)
+	assert.Equal(t, role, found)
+
+	list, err := controller.Roles(organization)
+	require.NoError(t, err)
+	assert.Equal(t, []appmodels.VaultRole{role}, list)
+
+	require.NoError(t, controller.DeleteRole(organization, "web"))
+	_, err = controller.Role(organization, "web")
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_NOT_FOUND)
+	requireVaultErrorType(t, controller.DeleteRole(organization, "web"), appmodels.VAULT_ERROR_NOT_FOUND)
+}
+
+func TestCertVaultController_Issue(t *testing.T) {
+	controller, organization, root := newTestVaultController(t, "")
+	role, err := controller.SaveRole(appmodels.NewVaultRole("web", organization, root.SerialNumber(), []string{"example.com"}, true, false, false, true, appmodels.ECDSA_P384, 0, 2*time.Hour))
+	require.NoError(t, err)
+
+	certificate, privateKey, err := controller.Issue(role, "www.example.com", []string{"api.example.com", "WWW.example.com"}, 10*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"www.example.com", "api.example.com"}, certificate.Certificate().DNSNames)
+	assert.True(t, certificate.IsServerCertificate())
+	assert.Equal(t, appmodels.ECDSA_P384, privateKey.KeyType())
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), certificate.NotAfter(), time.Minute)
+	assert.NoError(t, certificate.Certificate().CheckSignatureFrom(root.Certificate()))
+
+	_, _, err = controller.Issue(role, "www.example.org", nil, 0)
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_BAD_REQUEST)
+
+	_, _, err = controller.Issue(role, "example.com", nil, 0)
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_BAD_REQUEST)
+
+	_, _, err = controller.Issue(role, "", nil, 0)
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_BAD_REQUEST)
+}
+
+func TestCertVaultController_Issue_Client(t *testing.T) {
+	controller, organization, root := newTestVaultController(t, "")
+	role, err := controller.SaveRole(appmodels.NewVaultRole("clients", organization, root.SerialNumber(), nil, false, false, true, false, appmodels.NIL_KEY_TYPE, 30*time.Minute, 0))
+	require.NoError(t, err)
+
+	certificate, _, err := controller.Issue(role, "alice", nil, 0)
+	require.NoError(t, err)
+	assert.True(t, certificate.IsClientCertificate())
+	assert.Equal(t, "alice", certificate.CommonName())
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), certificate.NotAfter(), time.Minute)
+
+	_, _, err = controller.Issue(role, "alice", []string{"bob"}, 0)
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_BAD_REQUEST)
+}
+
+func TestCertVaultController_Sign(t *testing.T) {
+	controller, organization, root := newTestVaultController(t, "")
+	role, err := controller.SaveRole(appmodels.NewVaultRole("web", organization, root.SerialNumber(), []string{"example.com"}, true, true, false, true, appmodels.NIL_KEY_TYPE, 0, 0))
+	require.NoError(t, err)
+
+	csr := newTestEstCsr(t, "example.com", []string{"www.example.com"})
+	certificate, err := controller.Sign(role, csr, "", nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com", "www.example.com"}, certificate.Certificate().DNSNames)
+	assert.Equal(t, csr.PublicKey, certificate.Certificate().PublicKey)
+
+	_, err = controller.Sign(role, newTestEstCsr(t, "example.org", nil), "", nil, 0)
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_BAD_REQUEST)
+
+	_, err = controller.Sign(role, nil, "", nil, 0)
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_BAD_REQUEST)
+}
+
+func TestCertVaultController_RevokeAndRevocationList(t *testing.T) {
+	controller, organization, root := newTestVaultController(t, "")
+	role, err := controller.SaveRole(appmodels.NewVaultRole("web", organization, root.SerialNumber(), nil, false, false, true, true, appmodels.NIL_KEY_TYPE, 0, 0))
+	require.NoError(t, err)
+	certificate, _, err := controller.Issue(role, "www.example.com", nil, 0)
+	require.NoError(t, err)
+
+	revocation, err := controller.Revoke(organization, certificate.SerialNumber())
+	require.NoError(t, err)
+	assert.Equal(t, 0, root.SerialNumber().Cmp(revocation.IssuerSerialNumber()))
+
+	again, err := controller.Revoke(organization, certificate.SerialNumber())
+	require.NoError(t, err)
+	assert.Equal(t, revocation, again)
+
+	_, err = controller.Revoke(organization, big.NewInt(999))
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_BAD_REQUEST)
+
+	_, err = controller.Revoke(organization, root.SerialNumber())
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_BAD_REQUEST)
+
+	issuer, err := controller.Issuer(organization, root.SerialNumber())
+	require.NoError(t, err)
+	assert.Len(t, controller.CACertificates(issuer), 1)
+
+	der, err := controller.RevocationList(issuer)
+	require.NoError(t, err)
+	crl, err := x509.ParseRevocationList(der)
+	require.NoError(t, err)
+	assert.NoError(t, crl.CheckSignatureFrom(root.Certificate()))
+	require.Len(t, crl.RevokedCertificateEntries, 1)
+	assert.Equal(t, 0, certificate.SerialNumber().Cmp(crl.RevokedCertificateEntries[0].SerialNumber))
+
+	// The CRL number increases on every update
+	der, err = controller.RevocationList(issuer)
+	require.NoError(t, err)
+	next, err := x509.ParseRevocationList(der)
+	require.NoError(t, err)
+	assert.Equal(t, 1, next.Number.Cmp(crl.Number))
+}
+
+func TestCertVaultController_DefaultIssuer_Repository(t *testing.T) {
+	controller, organization, root := newTestVaultController(t, "")
+	issuer, err := controller.DefaultIssuer(organization)
+	require.NoError(t, err)
+	assert.Equal(t, 0, root.SerialNumber().Cmp(issuer.Certificate().SerialNumber()))
+}
+
+func TestCertVaultController_DefaultIssuer(t *testing.T) {
+	older := new(appmocks.MockCertificate)
+	older.On("IsRootCertificate").Return(true)
+	older.On("NotBefore").Return(time.Now().Add(-2 * time.Hour))
+	older.On("NotAfter").Return(time.Now().Add(time.Hour))
+	newer := new(appmocks.MockCertificate)
+	newer.On("IsRootCertificate").Return(true)
+	newer.On("NotBefore").Return(time.Now().Add(-time.Hour))
+	newer.On("NotAfter").Return(time.Now().Add(time.Hour))
+	newer.On("SerialNumber").Return(big.NewInt(3))
+
+	issuer := new(appmocks.MockCertificateController)
+	orgController := new(appmocks.MockOrganizationController)
+	orgController.On("CertificateCollection").Return([]appmodels.Certificate{older, newer}, nil)
+	orgController.On("CertificateController", big.NewInt(3)).Return(issuer, nil)
+	appController := new(appmocks.MockApplicationController)
+	appController.On("OrganizationController", big.NewInt(1)).Return(orgController, nil)
+	appController.On("OrganizationController", big.NewInt(2)).Return((*appmocks.MockOrganizationController)(nil), errors.New("not found"))
+
+	controller := appcontrollers.NewVaultController(nil, nil, appController, "", time.Hour)
+
+	found, err := controller.DefaultIssuer(big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, issuer, found)
+
+	_, err = controller.DefaultIssuer(big.NewInt(2))
+	requireVaultErrorType(t, err, appmodels.VAULT_ERROR_NOT_FOUND)
+}
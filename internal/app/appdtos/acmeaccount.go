@@ -0,0 +1,86 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+import "time"
+
+// AcmeAccountDTO is the ACME (RFC 8555 section 7.1.2) account object
+type AcmeAccountDTO struct {
+	Status  string   `json:"status" jsonschema:"title=Account status,required" jsonschema_extras:"example=valid"`
+	Contact []string `json:"contact,omitempty" jsonschema:"title=Contact URLs"`
+	Orders  string   `json:"orders" jsonschema:"title=URL for the order list,required"`
+}
+
+func NewAcmeAccountDTO(
+	status string,
+	contact []string,
+	orders string,
+) AcmeAccountDTO {
+	return AcmeAccountDTO{
+		Status:  status,
+		Contact: contact,
+		Orders:  orders,
+	}
+}
+
+// AcmeAccountRequestDTO is the payload for creating, looking up, updating or
+// deactivating an ACME account
+type AcmeAccountRequestDTO struct {
+	Contact              []string `json:"contact,omitempty"`
+	TermsOfServiceAgreed bool     `json:"termsOfServiceAgreed,omitempty"`
+	OnlyReturnExisting   bool     `json:"onlyReturnExisting,omitempty"`
+
+	// Status is only used to deactivate an account
+	Status string `json:"status,omitempty"`
+}
+
+func NewAcmeAccountRequestDTO(
+	contact []string,
+	termsOfServiceAgreed bool,
+	onlyReturnExisting bool,
+	status string,
+) AcmeAccountRequestDTO {
+	return AcmeAccountRequestDTO{
+		Contact:              contact,
+		TermsOfServiceAgreed: termsOfServiceAgreed,
+		OnlyReturnExisting:   onlyReturnExisting,
+		Status:               status,
+	}
+}
+
+// AcmeAccountRecordDTO is the stored form of an ACME account
+type AcmeAccountRecordDTO struct {
+	ID           string   `json:"id"`
+	Organization string   `json:"organization"`
+	Issuer       string   `json:"issuer"`
+	Status       string   `json:"status"`
+	Contact      []string `json:"contact,omitempty"`
+
+	// PublicKey is the account key in the PKIX DER format
+	PublicKey []byte `json:"publicKey"`
+
+	Thumbprint string    `json:"thumbprint"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func NewAcmeAccountRecordDTO(
+	id string,
+	organization string,
+	issuer string,
+	status string,
+	contact []string,
+	publicKey []byte,
+	thumbprint string,
+	createdAt time.Time,
+) AcmeAccountRecordDTO {
+	return AcmeAccountRecordDTO{
+		ID:           id,
+		Organization: organization,
+		Issuer:       issuer,
+		Status:       status,
+		Contact:      contact,
+		PublicKey:    publicKey,
+		Thumbprint:   thumbprint,
+		CreatedAt:    createdAt,
+	}
+}
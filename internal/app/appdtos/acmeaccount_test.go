@@ -0,0 +1,43 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewAcmeAccountDTO(t *testing.T) {
+	contact := []string{"mailto:admin@example.com"}
+	dto := appdtos.NewAcmeAccountDTO("valid", contact, "https://x/orders")
+	assert.Equal(t, "valid", dto.Status)
+	assert.Equal(t, contact, dto.Contact)
+	assert.Equal(t, "https://x/orders", dto.Orders)
+}
+
+func TestNewAcmeAccountRequestDTO(t *testing.T) {
+	contact := []string{"mailto:admin@example.com"}
+	dto := appdtos.NewAcmeAccountRequestDTO(contact, true, true, "deactivated")
+	assert.Equal(t, contact, dto.Contact)
+	assert.True(t, dto.TermsOfServiceAgreed)
+	assert.True(t, dto.OnlyReturnExisting)
+	assert.Equal(t, "deactivated", dto.Status)
+}
+
+func TestNewAcmeAccountRecordDTO(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	contact := []string{"mailto:admin@example.com"}
+	dto := appdtos.NewAcmeAccountRecordDTO("id", "1", "2", "valid", contact, []byte{1, 2}, "thumbprint", createdAt)
+	assert.Equal(t, "id", dto.ID)
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "2", dto.Issuer)
+	assert.Equal(t, "valid", dto.Status)
+	assert.Equal(t, contact, dto.Contact)
+	assert.Equal(t, []byte{1, 2}, dto.PublicKey)
+	assert.Equal(t, "thumbprint", dto.Thumbprint)
+	assert.Equal(t, createdAt, dto.CreatedAt)
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+import "time"
+
+// AcmeAuthorizationDTO is the ACME (RFC 8555 section 7.1.4) authorization object
+type AcmeAuthorizationDTO struct {
+	Identifier AcmeIdentifierDTO  `json:"identifier" jsonschema:"title=The identifier to authorize,required"`
+	Status     string             `json:"status" jsonschema:"title=Authorization status,required" jsonschema_extras:"example=pending"`
+	Expires    string             `json:"expires,omitempty" jsonschema:"title=Expiration time in RFC 3339 format"`
+	Challenges []AcmeChallengeDTO `json:"challenges" jsonschema:"title=Challenges the client can complete,required"`
+	Wildcard   bool               `json:"wildcard,omitempty" jsonschema:"title=True for wildcard identifiers"`
+}
+
+func NewAcmeAuthorizationDTO(
+	identifier AcmeIdentifierDTO,
+	status string,
+	expires string,
+	challenges []AcmeChallengeDTO,
+	wildcard bool,
+) AcmeAuthorizationDTO {
+	return AcmeAuthorizationDTO{
+		Identifier: identifier,
+		Status:     status,
+		Expires:    expires,
+		Challenges: challenges,
+		Wildcard:   wildcard,
+	}
+}
+
+// AcmeAuthorizationRecordDTO is the stored form of an ACME authorization
+type AcmeAuthorizationRecordDTO struct {
+	ID         string                   `json:"id"`
+	Account    string                   `json:"account"`
+	Order      string                   `json:"order"`
+	Status     string                   `json:"status"`
+	Expires    time.Time                `json:"expires"`
+	DNSName    string                   `json:"dnsName"`
+	Wildcard   bool                     `json:"wildcard,omitempty"`
+	Challenges []AcmeChallengeRecordDTO `json:"challenges"`
+}
+
+func NewAcmeAuthorizationRecordDTO(
+	id string,
+	account string,
+	order string,
+	status string,
+	expires time.Time,
+	dnsName string,
+	wildcard bool,
+	challenges []AcmeChallengeRecordDTO,
+) AcmeAuthorizationRecordDTO {
+	return AcmeAuthorizationRecordDTO{
+		ID:         id,
+		Account:    account,
+		Order:      order,
+		Status:     status,
+		Expires:    expires,
+		DNSName:    dnsName,
+		Wildcard:   wildcard,
+		Challenges: challenges,
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewAcmeAuthorizationDTO(t *testing.T) {
+	identifier := appdtos.NewAcmeIdentifierDTO("dns", "example.com")
+	challenges := []appdtos.AcmeChallengeDTO{
+		appdtos.NewAcmeChallengeDTO("dns-01", "https://x/chall/1/2", "token", "pending", "", nil),
+	}
+	dto := appdtos.NewAcmeAuthorizationDTO(identifier, "pending", "2024-01-01T00:00:00Z", challenges, true)
+	assert.Equal(t, identifier, dto.Identifier)
+	assert.Equal(t, "pending", dto.Status)
+	assert.Equal(t, "2024-01-01T00:00:00Z", dto.Expires)
+	assert.Equal(t, challenges, dto.Challenges)
+	assert.True(t, dto.Wildcard)
+}
+
+func TestNewAcmeAuthorizationRecordDTO(t *testing.T) {
+	expires := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	challenges := []appdtos.AcmeChallengeRecordDTO{appdtos.NewAcmeChallengeRecordDTO("1", "dns-01", "token", "pending", time.Time{}, nil)}
+	dto := appdtos.NewAcmeAuthorizationRecordDTO("id", "account", "order", "pending", expires, "example.com", true, challenges)
+	assert.Equal(t, "id", dto.ID)
+	assert.Equal(t, "account", dto.Account)
+	assert.Equal(t, "order", dto.Order)
+	assert.Equal(t, "pending", dto.Status)
+	assert.Equal(t, expires, dto.Expires)
+	assert.Equal(t, "example.com", dto.DNSName)
+	assert.True(t, dto.Wildcard)
+	assert.Equal(t, challenges, dto.Challenges)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+import "time"
+
+// AcmeChallengeDTO is the ACME (RFC 8555 section 8) challenge object
+type AcmeChallengeDTO struct {
+	Type      string          `json:"type" jsonschema:"title=Challenge type,required" jsonschema_extras:"example=http-01"`
+	URL       string          `json:"url" jsonschema:"title=URL for responding to the challenge,required"`
+	Token     string          `json:"token" jsonschema:"title=Challenge token,required"`
+	Status    string          `json:"status" jsonschema:"title=Challenge status,required" jsonschema_extras:"example=pending"`
+	Validated string          `json:"validated,omitempty" jsonschema:"title=Validation time in RFC 3339 format"`
+	Error     *AcmeProblemDTO `json:"error,omitempty" jsonschema:"title=The error from the validation"`
+}
+
+func NewAcmeChallengeDTO(
+	challengeType string,
+	url string,
+	token string,
+	status string,
+	validated string,
+	error *AcmeProblemDTO,
+) AcmeChallengeDTO {
+	return AcmeChallengeDTO{
+		Type:      challengeType,
+		URL:       url,
+		Token:     token,
+		Status:    status,
+		Validated: validated,
+		Error:     error,
+	}
+}
+
+// AcmeChallengeRecordDTO is the stored form of an ACME challenge
+type AcmeChallengeRecordDTO struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Token  string `json:"token"`
+	Status string `json:"status"`
+
+	// Validated is zero unless the challenge has been validated
+	Validated time.Time `json:"validated"`
+
+	Error *AcmeErrorRecordDTO `json:"error,omitempty"`
+}
+
+func NewAcmeChallengeRecordDTO(
+	id string,
+	challengeType string,
+	token string,
+	status string,
+	validated time.Time,
+	err *AcmeErrorRecordDTO,
+) AcmeChallengeRecordDTO {
+	return AcmeChallengeRecordDTO{
+		ID:        id,
+		Type:      challengeType,
+		Token:     token,
+		Status:    status,
+		Validated: validated,
+		Error:     err,
+	}
+}
+
+// AcmeErrorRecordDTO is the stored form of the error of an ACME order or
+// challenge
+type AcmeErrorRecordDTO struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+}
+
+func NewAcmeErrorRecordDTO(
+	errorType string,
+	detail string,
+) AcmeErrorRecordDTO {
+	return AcmeErrorRecordDTO{
+		Type:   errorType,
+		Detail: detail,
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewAcmeChallengeDTO(t *testing.T) {
+	problem := appdtos.NewAcmeProblemDTO(appdtos.AcmeErrorTypePrefix+"connection", "refused", 400)
+	dto := appdtos.NewAcmeChallengeDTO("http-01", "https://x/chall/1/2", "token", "invalid", "2024-01-01T00:00:00Z", &problem)
+	assert.Equal(t, "http-01", dto.Type)
+	assert.Equal(t, "https://x/chall/1/2", dto.URL)
+	assert.Equal(t, "token", dto.Token)
+	assert.Equal(t, "invalid", dto.Status)
+	assert.Equal(t, "2024-01-01T00:00:00Z", dto.Validated)
+	assert.Equal(t, &problem, dto.Error)
+}
+
+func TestNewAcmeChallengeRecordDTO(t *testing.T) {
+	validated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	challengeError := appdtos.NewAcmeErrorRecordDTO("connection", "refused")
+	dto := appdtos.NewAcmeChallengeRecordDTO("1", "http-01", "token", "invalid", validated, &challengeError)
+	assert.Equal(t, "1", dto.ID)
+	assert.Equal(t, "http-01", dto.Type)
+	assert.Equal(t, "token", dto.Token)
+	assert.Equal(t, "invalid", dto.Status)
+	assert.Equal(t, validated, dto.Validated)
+	assert.Equal(t, "connection", dto.Error.Type)
+	assert.Equal(t, "refused", dto.Error.Detail)
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+// AcmeDirectoryDTO is the ACME (RFC 8555 section 7.1.1) directory object
+type AcmeDirectoryDTO struct {
+	NewNonce   string `json:"newNonce" jsonschema:"title=URL for new nonces,required"`
+	NewAccount string `json:"newAccount" jsonschema:"title=URL for new accounts,required"`
+	NewOrder   string `json:"newOrder" jsonschema:"title=URL for new orders,required"`
+}
+
+func NewAcmeDirectoryDTO(
+	newNonce string,
+	newAccount string,
+	newOrder string,
+) AcmeDirectoryDTO {
+	return AcmeDirectoryDTO{
+		NewNonce:   newNonce,
+		NewAccount: newAccount,
+		NewOrder:   newOrder,
+	}
+}
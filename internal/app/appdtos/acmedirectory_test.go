@@ -0,0 +1,18 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewAcmeDirectoryDTO(t *testing.T) {
+	dto := appdtos.NewAcmeDirectoryDTO("https://x/new-nonce", "https://x/new-account", "https://x/new-order")
+	assert.Equal(t, "https://x/new-nonce", dto.NewNonce)
+	assert.Equal(t, "https://x/new-account", dto.NewAccount)
+	assert.Equal(t, "https://x/new-order", dto.NewOrder)
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+// AcmeDnsIdentifier is the only ACME identifier type supported
+const AcmeDnsIdentifier = "dns"
+
+// AcmeIdentifierDTO is an ACME (RFC 8555 section 9.7.7) identifier
+type AcmeIdentifierDTO struct {
+	Type  string `json:"type" jsonschema:"title=Identifier type,required" jsonschema_extras:"example=dns"`
+	Value string `json:"value" jsonschema:"title=Identifier value,required" jsonschema_extras:"example=www.example.com"`
+}
+
+func NewAcmeIdentifierDTO(
+	identifierType string,
+	value string,
+) AcmeIdentifierDTO {
+	return AcmeIdentifierDTO{
+		Type:  identifierType,
+		Value: value,
+	}
+}
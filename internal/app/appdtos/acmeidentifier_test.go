@@ -0,0 +1,17 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewAcmeIdentifierDTO(t *testing.T) {
+	dto := appdtos.NewAcmeIdentifierDTO(appdtos.AcmeDnsIdentifier, "www.example.com")
+	assert.Equal(t, "dns", dto.Type)
+	assert.Equal(t, "www.example.com", dto.Value)
+}
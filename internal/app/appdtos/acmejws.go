@@ -0,0 +1,81 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+import (
+	"encoding/json"
+)
+
+// AcmeJwsDTO is a JWS in the flattened JSON serialization (RFC 7515 section
+// 7.2.2), which is the body of every ACME POST request
+type AcmeJwsDTO struct {
+	Protected string `json:"protected" jsonschema:"title=base64url encoded protected header,required"`
+	Payload   string `json:"payload" jsonschema:"title=base64url encoded payload,required"`
+	Signature string `json:"signature" jsonschema:"title=base64url encoded signature,required"`
+}
+
+func NewAcmeJwsDTO(
+	protected string,
+	payload string,
+	signature string,
+) AcmeJwsDTO {
+	return AcmeJwsDTO{
+		Protected: protected,
+		Payload:   payload,
+		Signature: signature,
+	}
+}
+
+// AcmeJwsHeaderDTO is the protected header of an ACME JWS (RFC 8555 section
+// 6.2). Exactly one of Key and KeyID is set.
+type AcmeJwsHeaderDTO struct {
+	Algorithm string          `json:"alg"`
+	Nonce     string          `json:"nonce"`
+	URL       string          `json:"url"`
+	Key       json.RawMessage `json:"jwk,omitempty"`
+	KeyID     string          `json:"kid,omitempty"`
+}
+
+func NewAcmeJwsHeaderDTO(
+	algorithm string,
+	nonce string,
+	url string,
+	key json.RawMessage,
+	keyID string,
+) AcmeJwsHeaderDTO {
+	return AcmeJwsHeaderDTO{
+		Algorithm: algorithm,
+		Nonce:     nonce,
+		URL:       url,
+		Key:       key,
+		KeyID:     keyID,
+	}
+}
+
+// JwkDTO is a JSON Web Key (RFC 7517) for RSA, EC or OKP public keys
+type JwkDTO struct {
+	KeyType string `json:"kty"`
+	Curve   string `json:"crv,omitempty"`
+	X       string `json:"x,omitempty"`
+	Y       string `json:"y,omitempty"`
+	N       string `json:"n,omitempty"`
+	E       string `json:"e,omitempty"`
+}
+
+func NewJwkDTO(
+	keyType string,
+	curve string,
+	x string,
+	y string,
+	n string,
+	e string,
+) JwkDTO {
+	return JwkDTO{
+		KeyType: keyType,
+		Curve:   curve,
+		X:       x,
+		Y:       y,
+		N:       n,
+		E:       e,
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewAcmeJwsDTO(t *testing.T) {
+	dto := appdtos.NewAcmeJwsDTO("protected", "payload", "signature")
+	assert.Equal(t, "protected", dto.Protected)
+	assert.Equal(t, "payload", dto.Payload)
+	assert.Equal(t, "signature", dto.Signature)
+}
+
+func TestNewAcmeJwsHeaderDTO(t *testing.T) {
+	key := json.RawMessage(`{"kty":"EC"}`)
+	dto := appdtos.NewAcmeJwsHeaderDTO("ES256", "nonce", "https://x/new-account", key, "")
+	assert.Equal(t, "ES256", dto.Algorithm)
+	assert.Equal(t, "nonce", dto.Nonce)
+	assert.Equal(t, "https://x/new-account", dto.URL)
+	assert.Equal(t, key, dto.Key)
+	assert.Equal(t, "", dto.KeyID)
+}
+
+func TestNewJwkDTO(t *testing.T) {
+	dto := appdtos.NewJwkDTO("EC", "P-256", "x", "y", "", "")
+	assert.Equal(t, "EC", dto.KeyType)
+	assert.Equal(t, "P-256", dto.Curve)
+	assert.Equal(t, "x", dto.X)
+	assert.Equal(t, "y", dto.Y)
+	assert.Empty(t, dto.N)
+	assert.Empty(t, dto.E)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+import "time"
+
+// AcmeOrderDTO is the ACME (RFC 8555 section 7.1.3) order object
+type AcmeOrderDTO struct {
+	Status         string              `json:"status" jsonschema:"title=Order status,required" jsonschema_extras:"example=pending"`
+	Expires        string              `json:"expires,omitempty" jsonschema:"title=Expiration time in RFC 3339 format"`
+	Identifiers    []AcmeIdentifierDTO `json:"identifiers" jsonschema:"title=Requested identifiers,required"`
+	Authorizations []string            `json:"authorizations" jsonschema:"title=URLs of the authorizations,required"`
+	Finalize       string              `json:"finalize" jsonschema:"title=URL for finalizing the order,required"`
+	Certificate    string              `json:"certificate,omitempty" jsonschema:"title=URL of the issued certificate"`
+	Error          *AcmeProblemDTO     `json:"error,omitempty" jsonschema:"title=The error that made the order invalid"`
+}
+
+func NewAcmeOrderDTO(
+	status string,
+	expires string,
+	identifiers []AcmeIdentifierDTO,
+	authorizations []string,
+	finalize string,
+	certificate string,
+	error *AcmeProblemDTO,
+) AcmeOrderDTO {
+	return AcmeOrderDTO{
+		Status:         status,
+		Expires:        expires,
+		Identifiers:    identifiers,
+		Authorizations: authorizations,
+		Finalize:       finalize,
+		Certificate:    certificate,
+		Error:          error,
+	}
+}
+
+// AcmeOrderRequestDTO is the payload for creating an ACME order
+type AcmeOrderRequestDTO struct {
+	Identifiers []AcmeIdentifierDTO `json:"identifiers"`
+	NotBefore   string              `json:"notBefore,omitempty"`
+	NotAfter    string              `json:"notAfter,omitempty"`
+}
+
+func NewAcmeOrderRequestDTO(
+	identifiers []AcmeIdentifierDTO,
+) AcmeOrderRequestDTO {
+	return AcmeOrderRequestDTO{
+		Identifiers: identifiers,
+	}
+}
+
+// AcmeOrderListDTO is the ACME (RFC 8555 section 7.1.2.1) orders list object
+type AcmeOrderListDTO struct {
+	Orders []string `json:"orders" jsonschema:"title=URLs of the orders,required"`
+}
+
+func NewAcmeOrderListDTO(
+	orders []string,
+) AcmeOrderListDTO {
+	return AcmeOrderListDTO{
+		Orders: orders,
+	}
+}
+
+// AcmeFinalizeRequestDTO is the payload for finalizing an ACME order
+type AcmeFinalizeRequestDTO struct {
+
+	// CSR is the base64url encoded DER certificate signing request
+	CSR string `json:"csr"`
+}
+
+func NewAcmeFinalizeRequestDTO(
+	csr string,
+) AcmeFinalizeRequestDTO {
+	return AcmeFinalizeRequestDTO{
+		CSR: csr,
+	}
+}
+
+// AcmeOrderRecordDTO is the stored form of an ACME order
+type AcmeOrderRecordDTO struct {
+	ID             string    `json:"id"`
+	Account        string    `json:"account"`
+	Status         string    `json:"status"`
+	Expires        time.Time `json:"expires"`
+	DNSNames       []string  `json:"dnsNames"`
+	Authorizations []string  `json:"authorizations"`
+
+	// Certificate is the serial number of the issued certificate, or empty
+	Certificate string `json:"certificate,omitempty"`
+
+	Error *AcmeErrorRecordDTO `json:"error,omitempty"`
+}
+
+func NewAcmeOrderRecordDTO(
+	id string,
+	account string,
+	status string,
+	expires time.Time,
+	dnsNames []string,
+	authorizations []string,
+	certificate string,
+	err *AcmeErrorRecordDTO,
+) AcmeOrderRecordDTO {
+	return AcmeOrderRecordDTO{
+		ID:             id,
+		Account:        account,
+		Status:         status,
+		Expires:        expires,
+		DNSNames:       dnsNames,
+		Authorizations: authorizations,
+		Certificate:    certificate,
+		Error:          err,
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewAcmeOrderDTO(t *testing.T) {
+	identifiers := []appdtos.AcmeIdentifierDTO{appdtos.NewAcmeIdentifierDTO("dns", "www.example.com")}
+	authorizations := []string{"https://x/authz/1"}
+	problem := appdtos.NewAcmeProblemDTO(appdtos.AcmeErrorTypePrefix+"badCSR", "bad", 400)
+
+	dto := appdtos.NewAcmeOrderDTO("invalid", "2024-01-01T00:00:00Z", identifiers, authorizations, "https://x/finalize", "https://x/cert/1", &problem)
+	assert.Equal(t, "invalid", dto.Status)
+	assert.Equal(t, "2024-01-01T00:00:00Z", dto.Expires)
+	assert.Equal(t, identifiers, dto.Identifiers)
+	assert.Equal(t, authorizations, dto.Authorizations)
+	assert.Equal(t, "https://x/finalize", dto.Finalize)
+	assert.Equal(t, "https://x/cert/1", dto.Certificate)
+	assert.Equal(t, &problem, dto.Error)
+}
+
+func TestNewAcmeOrderRequestDTO(t *testing.T) {
+	identifiers := []appdtos.AcmeIdentifierDTO{appdtos.NewAcmeIdentifierDTO("dns", "www.example.com")}
+	dto := appdtos.NewAcmeOrderRequestDTO(identifiers)
+	assert.Equal(t, identifiers, dto.Identifiers)
+}
+
+func TestNewAcmeOrderListDTO(t *testing.T) {
+	orders := []string{"https://x/order/1", "https://x/order/2"}
+	dto := appdtos.NewAcmeOrderListDTO(orders)
+	assert.Equal(t, orders, dto.Orders)
+}
+
+func TestNewAcmeFinalizeRequestDTO(t *testing.T) {
+	dto := appdtos.NewAcmeFinalizeRequestDTO("MIIB")
+	assert.Equal(t, "MIIB", dto.CSR)
+}
+
+func TestNewAcmeOrderRecordDTO(t *testing.T) {
+	expires := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	orderError := appdtos.NewAcmeErrorRecordDTO("badCSR", "invalid")
+	dto := appdtos.NewAcmeOrderRecordDTO("id", "account", "invalid", expires, []string{"www.example.com"}, []string{"authz"}, "3", &orderError)
+	assert.Equal(t, "id", dto.ID)
+	assert.Equal(t, "account", dto.Account)
+	assert.Equal(t, "invalid", dto.Status)
+	assert.Equal(t, expires, dto.Expires)
+	assert.Equal(t, []string{"www.example.com"}, dto.DNSNames)
+	assert.Equal(t, []string{"authz"}, dto.Authorizations)
+	assert.Equal(t, "3", dto.Certificate)
+	assert.Equal(t, &orderError, dto.Error)
+}
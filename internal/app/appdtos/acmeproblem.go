@@ -0,0 +1,25 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+// AcmeErrorTypePrefix is the URN prefix of ACME error types
+const AcmeErrorTypePrefix = "urn:ietf:params:acme:error:"
+
+// AcmeProblemDTO is an RFC 7807 problem document used for ACME errors
+type AcmeProblemDTO struct {
+	Type   string `json:"type" jsonschema:"title=Error type URN,required" jsonschema_extras:"example=urn:ietf:params:acme:error:malformed"`
+	Detail string `json:"detail,omitempty" jsonschema:"title=Human-readable explanation"`
+	Status int    `json:"status,omitempty" jsonschema:"title=HTTP status code"`
+}
+
+func NewAcmeProblemDTO(
+	problemType string,
+	detail string,
+	status int,
+) AcmeProblemDTO {
+	return AcmeProblemDTO{
+		Type:   problemType,
+		Detail: detail,
+		Status: status,
+	}
+}
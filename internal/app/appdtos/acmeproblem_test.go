@@ -0,0 +1,18 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewAcmeProblemDTO(t *testing.T) {
+	dto := appdtos.NewAcmeProblemDTO(appdtos.AcmeErrorTypePrefix+"malformed", "detail", 400)
+	assert.Equal(t, "urn:ietf:params:acme:error:malformed", dto.Type)
+	assert.Equal(t, "detail", dto.Detail)
+	assert.Equal(t, 400, dto.Status)
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+import (
+	"time"
+)
+
+// OperationApprovalDTO is the decision of one approver
+type OperationApprovalDTO struct {
+	Approver  string    `json:"approver"`
+	Approved  bool      `json:"approved"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewOperationApprovalDTO(
+	approver string,
+	approved bool,
+	createdAt time.Time,
+) OperationApprovalDTO {
+	return OperationApprovalDTO{
+		Approver:  approver,
+		Approved:  approved,
+		CreatedAt: createdAt,
+	}
+}
+
+// PendingOperationDTO describes an operation which requires approvals
+type PendingOperationDTO struct {
+	ID string `json:"id"`
+
+	// Type is the operation, e.g. "create-intermediate-certificate"
+	Type string `json:"type"`
+
+	Organization string `json:"organization"`
+
+	// Certificate is the serial number of the target certificate, if any
+	Certificate string `json:"certificate,omitempty"`
+
+	// Details are the parameters the operation runs with
+	Details string `json:"details,omitempty"`
+
+	// RequestedBy is the approver who requested the operation, and who
+	// alone can execute it
+	RequestedBy string `json:"requestedBy"`
+
+	// Status is "pending", "approved", "executing", "executed", "rejected"
+	// or "expired"
+	Status string `json:"status"`
+
+	// Threshold is the number of approvals needed
+	Threshold int `json:"threshold"`
+
+	CreatedAt  time.Time              `json:"createdAt"`
+	ExpiresAt  time.Time              `json:"expiresAt"`
+	ExecutedAt *time.Time             `json:"executedAt,omitempty"`
+	Approvals  []OperationApprovalDTO `json:"approvals"`
+}
+
+func NewPendingOperationDTO(
+	id string,
+	operationType string,
+	organization string,
+	certificate string,
+	details string,
+	requestedBy string,
+	status string,
+	threshold int,
+	createdAt time.Time,
+	expiresAt time.Time,
+	executedAt *time.Time,
+	approvals []OperationApprovalDTO,
+) PendingOperationDTO {
+	return PendingOperationDTO{
+		ID:           id,
+		Type:         operationType,
+		Organization: organization,
+		Certificate:  certificate,
+		Details:      details,
+		RequestedBy:  requestedBy,
+		Status:       status,
+		Threshold:    threshold,
+		CreatedAt:    createdAt,
+		ExpiresAt:    expiresAt,
+		ExecutedAt:   executedAt,
+		Approvals:    approvals,
+	}
+}
+
+// PendingOperationListDTO is a list of operations
+type PendingOperationListDTO struct {
+	Payload []PendingOperationDTO `json:"payload" jsonschema:"title=Pending Operation Payload DTOs,required"`
+}
+
+func NewPendingOperationListDTO(
+	payload []PendingOperationDTO,
+) PendingOperationListDTO {
+	return PendingOperationListDTO{
+		Payload: payload,
+	}
+}
+
+// PendingOperationRecordDTO is the stored form of a pending operation
+type PendingOperationRecordDTO struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	Organization string `json:"organization"`
+
+	// Certificate is the serial number of the target certificate, or empty
+	Certificate string `json:"certificate,omitempty"`
+
+	Details     string                 `json:"details,omitempty"`
+	RequestedBy string                 `json:"requestedBy"`
+	Status      string                 `json:"status"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	ExpiresAt   time.Time              `json:"expiresAt"`
+	Approvals   []OperationApprovalDTO `json:"approvals"`
+
+	// ExecutedAt is zero unless the operation has been executed
+	ExecutedAt time.Time `json:"executedAt"`
+}
+
+func NewPendingOperationRecordDTO(
+	id string,
+	operationType string,
+	organization string,
+	certificate string,
+	details string,
+	requestedBy string,
+	status string,
+	createdAt time.Time,
+	expiresAt time.Time,
+	approvals []OperationApprovalDTO,
+	executedAt time.Time,
+) PendingOperationRecordDTO {
+	return PendingOperationRecordDTO{
+		ID:           id,
+		Type:         operationType,
+		Organization: organization,
+		Certificate:  certificate,
+		Details:      details,
+		RequestedBy:  requestedBy,
+		Status:       status,
+		CreatedAt:    createdAt,
+		ExpiresAt:    expiresAt,
+		Approvals:    approvals,
+		ExecutedAt:   executedAt,
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewOperationApprovalDTO(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	dto := appdtos.NewOperationApprovalDTO("alice", true, createdAt)
+	assert.Equal(t, "alice", dto.Approver)
+	assert.True(t, dto.Approved)
+	assert.Equal(t, createdAt, dto.CreatedAt)
+}
+
+func TestNewPendingOperationDTO(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	executedAt := createdAt.Add(time.Minute)
+	approvals := []appdtos.OperationApprovalDTO{appdtos.NewOperationApprovalDTO("alice", true, createdAt)}
+	dto := appdtos.NewPendingOperationDTO("id", "create-root-certificate", "1", "2", "commonName=Test", "bob", "executed", 2, createdAt, createdAt.Add(time.Hour), &executedAt, approvals)
+	assert.Equal(t, "id", dto.ID)
+	assert.Equal(t, "create-root-certificate", dto.Type)
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "2", dto.Certificate)
+	assert.Equal(t, "commonName=Test", dto.Details)
+	assert.Equal(t, "bob", dto.RequestedBy)
+	assert.Equal(t, "executed", dto.Status)
+	assert.Equal(t, 2, dto.Threshold)
+	assert.Equal(t, createdAt, dto.CreatedAt)
+	assert.Equal(t, createdAt.Add(time.Hour), dto.ExpiresAt)
+	assert.Equal(t, &executedAt, dto.ExecutedAt)
+	assert.Equal(t, approvals, dto.Approvals)
+}
+
+func TestNewPendingOperationListDTO(t *testing.T) {
+	payload := []appdtos.PendingOperationDTO{{ID: "id"}}
+	dto := appdtos.NewPendingOperationListDTO(payload)
+	assert.Equal(t, payload, dto.Payload)
+}
+
+func TestNewPendingOperationRecordDTO(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	approvals := []appdtos.OperationApprovalDTO{appdtos.NewOperationApprovalDTO("alice", true, createdAt)}
+	dto := appdtos.NewPendingOperationRecordDTO("id", "create-root-certificate", "1", "2", "commonName=Test", "bob", "executed", createdAt, createdAt.Add(time.Hour), approvals, createdAt.Add(time.Minute))
+	assert.Equal(t, "id", dto.ID)
+	assert.Equal(t, "create-root-certificate", dto.Type)
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "2", dto.Certificate)
+	assert.Equal(t, "commonName=Test", dto.Details)
+	assert.Equal(t, "bob", dto.RequestedBy)
+	assert.Equal(t, "executed", dto.Status)
+	assert.Equal(t, createdAt, dto.CreatedAt)
+	assert.Equal(t, createdAt.Add(time.Hour), dto.ExpiresAt)
+	assert.Equal(t, approvals, dto.Approvals)
+	assert.Equal(t, createdAt.Add(time.Minute), dto.ExecutedAt)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+import (
+	"time"
+)
+
+// BackupManifestDTO is the unencrypted manifest of a backup archive. The
+// signature of the archive is calculated over the encoded manifest, which
+// includes the digest of the encrypted payload.
+type BackupManifestDTO struct {
+
+	// Version is the format version of the archive
+	Version int `json:"version"`
+
+	// Organization is the ID of the organization
+	Organization string `json:"organization"`
+
+	// CreatedAt is the time the backup was created
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Signer is the PEM encoded root certificate which signed the archive
+	Signer string `json:"signer"`
+
+	Encryption BackupEncryptionDTO `json:"encryption"`
+
+	// PayloadSha256 is the SHA-256 digest of the encrypted payload
+	PayloadSha256 []byte `json:"payloadSha256"`
+}
+
+func NewBackupManifestDTO(
+	version int,
+	organization string,
+	createdAt time.Time,
+	signer string,
+	encryption BackupEncryptionDTO,
+	payloadSha256 []byte,
+) BackupManifestDTO {
+	return BackupManifestDTO{
+		Version:       version,
+		Organization:  organization,
+		CreatedAt:     createdAt,
+		Signer:        signer,
+		Encryption:    encryption,
+		PayloadSha256: payloadSha256,
+	}
+}
+
+// BackupEncryptionDTO describes how the payload of a backup archive is
+// encrypted with a key derived from the passphrase
+type BackupEncryptionDTO struct {
+	Cipher string `json:"cipher"`
+	Kdf    string `json:"kdf"`
+	Salt   []byte `json:"salt"`
+
+	// N, R and P are the scrypt cost parameters
+	N int `json:"n"`
+	R int `json:"r"`
+	P int `json:"p"`
+
+	Nonce []byte `json:"nonce"`
+}
+
+func NewBackupEncryptionDTO(
+	cipher string,
+	kdf string,
+	salt []byte,
+	n, r, p int,
+	nonce []byte,
+) BackupEncryptionDTO {
+	return BackupEncryptionDTO{
+		Cipher: cipher,
+		Kdf:    kdf,
+		Salt:   salt,
+		N:      n,
+		R:      r,
+		P:      p,
+		Nonce:  nonce,
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewBackupManifestDTO(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	encryption := appdtos.NewBackupEncryptionDTO("AES-256-GCM", "scrypt", []byte("salt"), 32768, 8, 1, []byte("nonce"))
+	dto := appdtos.NewBackupManifestDTO(1, "10", createdAt, "signer", encryption, []byte("digest"))
+	assert.Equal(t, 1, dto.Version)
+	assert.Equal(t, "10", dto.Organization)
+	assert.Equal(t, createdAt, dto.CreatedAt)
+	assert.Equal(t, "signer", dto.Signer)
+	assert.Equal(t, encryption, dto.Encryption)
+	assert.Equal(t, []byte("digest"), dto.PayloadSha256)
+}
+
+func TestNewBackupEncryptionDTO(t *testing.T) {
+	dto := appdtos.NewBackupEncryptionDTO("AES-256-GCM", "scrypt", []byte("salt"), 32768, 8, 1, []byte("nonce"))
+	assert.Equal(t, "AES-256-GCM", dto.Cipher)
+	assert.Equal(t, "scrypt", dto.Kdf)
+	assert.Equal(t, []byte("salt"), dto.Salt)
+	assert.Equal(t, 32768, dto.N)
+	assert.Equal(t, 8, dto.R)
+	assert.Equal(t, 1, dto.P)
+	assert.Equal(t, []byte("nonce"), dto.Nonce)
+}
@@ -12,6 +12,9 @@ type CertificateDTO struct {
 	IsIntermediateCertificate bool   `json:"isIntermediateCertificate"`
 	IsServerCertificate       bool   `json:"isServerCertificate"`
 	IsClientCertificate       bool   `json:"isClientCertificate"`
+	SignatureAlgorithm        string `json:"signatureAlgorithm"`
+	SpiffeID                  string `json:"spiffeId,omitempty"`
+	State                     string `json:"state,omitempty"`
 	Certificate               string `json:"certificate"`
 }
 
@@ -25,6 +28,9 @@ func NewCertificateDTO(
 	isIntermediateCertificate bool,
 	isServerCertificate bool,
 	isClientCertificate bool,
+	signatureAlgorithm string,
+	spiffeID string,
+	state string,
 	certificate string,
 ) CertificateDTO {
 	return CertificateDTO{
@@ -37,6 +43,9 @@ func NewCertificateDTO(
 		IsIntermediateCertificate: isIntermediateCertificate,
 		IsServerCertificate:       isServerCertificate,
 		IsClientCertificate:       isClientCertificate,
+		SignatureAlgorithm:        signatureAlgorithm,
+		SpiffeID:                  spiffeID,
+		State:                     state,
 		Certificate:               certificate,
 	}
 }
@@ -21,6 +21,9 @@ func TestNewCertificateDTO(t *testing.T) {
 		isIntermediateCertificate bool
 		isServerCertificate       bool
 		isClientCertificate       bool
+		signatureAlgorithm        string
+		spiffeID                  string
+		state                     string
 		certificate               string
 		want                      appdtos.CertificateDTO
 	}{
@@ -35,6 +38,7 @@ func TestNewCertificateDTO(t *testing.T) {
 			isIntermediateCertificate: false,
 			isServerCertificate:       false,
 			isClientCertificate:       false,
+			signatureAlgorithm:        "SHA384_WITH_RSA_PSS",
 			certificate:               "cert-data-root",
 			want: appdtos.CertificateDTO{
 				CommonName:                "Root CA certificate",
@@ -46,6 +50,7 @@ func TestNewCertificateDTO(t *testing.T) {
 				IsIntermediateCertificate: false,
 				IsServerCertificate:       false,
 				IsClientCertificate:       false,
+				SignatureAlgorithm:        "SHA384_WITH_RSA_PSS",
 				Certificate:               "cert-data-root",
 			},
 		},
@@ -60,6 +65,7 @@ func TestNewCertificateDTO(t *testing.T) {
 			isIntermediateCertificate: true,
 			isServerCertificate:       false,
 			isClientCertificate:       false,
+			signatureAlgorithm:        "ECDSA_WITH_SHA384",
 			certificate:               "cert-data-intermediate",
 			want: appdtos.CertificateDTO{
 				CommonName:                "Intermediate CA certificate",
@@ -71,9 +77,35 @@ func TestNewCertificateDTO(t *testing.T) {
 				IsIntermediateCertificate: true,
 				IsServerCertificate:       false,
 				IsClientCertificate:       false,
+				SignatureAlgorithm:        "ECDSA_WITH_SHA384",
 				Certificate:               "cert-data-intermediate",
 			},
 		},
+		{
+			commonName:          "SPIFFE certificate",
+			serialNumber:        "555",
+			signedBy:            "Root CA",
+			parents:             []string{"Root CA"},
+			organization:        "Test Org",
+			isServerCertificate: true,
+			isClientCertificate: true,
+			signatureAlgorithm:  "ECDSA_WITH_SHA384",
+			spiffeID:            "spiffe://example.org/web",
+			state:               "expiring",
+			certificate:         "cert-data-spiffe",
+			want: appdtos.CertificateDTO{
+				CommonName:          "SPIFFE certificate",
+				SerialNumber:        "555",
+				SignedBy:            "Root CA",
+				Organization:        "Test Org",
+				IsServerCertificate: true,
+				IsClientCertificate: true,
+				SignatureAlgorithm:  "ECDSA_WITH_SHA384",
+				SpiffeID:            "spiffe://example.org/web",
+				State:               "expiring",
+				Certificate:         "cert-data-spiffe",
+			},
+		},
 		// Add more test cases as needed
 	}
 
@@ -89,6 +121,9 @@ func TestNewCertificateDTO(t *testing.T) {
 				tt.isIntermediateCertificate,
 				tt.isServerCertificate,
 				tt.isClientCertificate,
+				tt.signatureAlgorithm,
+				tt.spiffeID,
+				tt.state,
 				tt.certificate,
 			)
 			if !reflect.DeepEqual(got, tt.want) {
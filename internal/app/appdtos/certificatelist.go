@@ -4,6 +4,10 @@ package appdtos
 
 type CertificateListDTO struct {
 	Payload []CertificateDTO `json:"payload" jsonschema:"title=Certificate Payload DTOs,required"`
+
+	// NextCursor continues the listing from the following page. It is empty
+	// on the last page.
+	NextCursor string `json:"nextCursor,omitempty" jsonschema:"title=Next page cursor"`
 }
 
 func NewCertificateListDTO(
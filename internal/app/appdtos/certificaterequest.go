@@ -16,6 +16,17 @@ type CertificateRequestDTO struct {
 
 	// Expiration in minutes
 	Expiration int `json:"expiration"`
+
+	// KeyType of the new private key, e.g. "RSA_3072". Empty means the default.
+	KeyType string `json:"keyType,omitempty"`
+
+	// SignatureAlgorithm the issuer uses to sign the certificate, e.g.
+	// "SHA384_WITH_RSA_PSS". Empty means the organization default.
+	SignatureAlgorithm string `json:"signatureAlgorithm,omitempty"`
+
+	// SpiffeID of a SPIFFE certificate, e.g.
+	// "spiffe://example.org/ns/default/sa/web"
+	SpiffeID string `json:"spiffeId,omitempty"`
 }
 
 func NewCertificateRequestDTO(
@@ -23,11 +34,17 @@ func NewCertificateRequestDTO(
 	commonName string,
 	expiration int,
 	dnsNames []string,
+	keyType string,
+	signatureAlgorithm string,
+	spiffeID string,
 ) CertificateRequestDTO {
 	return CertificateRequestDTO{
-		CertificateType: certificateType,
-		CommonName:      commonName,
-		DnsNames:        dnsNames,
-		Expiration:      expiration,
+		CertificateType:    certificateType,
+		CommonName:         commonName,
+		DnsNames:           dnsNames,
+		Expiration:         expiration,
+		KeyType:            keyType,
+		SignatureAlgorithm: signatureAlgorithm,
+		SpiffeID:           spiffeID,
 	}
 }
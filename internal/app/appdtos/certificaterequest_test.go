@@ -16,6 +16,9 @@ func TestNewCertificateRequestDTO(t *testing.T) {
 		commonName      string
 		expiration      int
 		dnsNames        []string
+		keyType         string
+		sigAlg          string
+		spiffeID        string
 		want            appdtos.CertificateRequestDTO
 	}{
 		{
@@ -31,12 +34,38 @@ func TestNewCertificateRequestDTO(t *testing.T) {
 				Expiration:      1440,
 			},
 		},
+		{
+			name:            "Root certificate with key type and signature algorithm",
+			certificateType: appdtos.RootCertificate,
+			commonName:      "Example Root CA",
+			expiration:      525600,
+			keyType:         "RSA_3072",
+			sigAlg:          "SHA384_WITH_RSA_PSS",
+			want: appdtos.CertificateRequestDTO{
+				CertificateType:    appdtos.RootCertificate,
+				CommonName:         "Example Root CA",
+				Expiration:         525600,
+				KeyType:            "RSA_3072",
+				SignatureAlgorithm: "SHA384_WITH_RSA_PSS",
+			},
+		},
+		{
+			name:            "SPIFFE certificate",
+			certificateType: appdtos.SpiffeCertificate,
+			expiration:      60,
+			spiffeID:        "spiffe://example.org/web",
+			want: appdtos.CertificateRequestDTO{
+				CertificateType: appdtos.SpiffeCertificate,
+				Expiration:      60,
+				SpiffeID:        "spiffe://example.org/web",
+			},
+		},
 		// Add more test cases for different scenarios
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := appdtos.NewCertificateRequestDTO(tt.certificateType, tt.commonName, tt.expiration, tt.dnsNames)
+			got := appdtos.NewCertificateRequestDTO(tt.certificateType, tt.commonName, tt.expiration, tt.dnsNames, tt.keyType, tt.sigAlg, tt.spiffeID)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewCertificateRequestDTO() got = %v, want %v", got, tt.want)
 			}
@@ -0,0 +1,38 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+import (
+	"time"
+)
+
+// CertificateRevocationDTO is a revoked certificate and the certificate which
+// issued it
+type CertificateRevocationDTO struct {
+
+	// Issuer is the serial number of the issuing certificate
+	Issuer string `json:"issuer"`
+
+	// SerialNumber is the serial number of revoked certificate
+	SerialNumber string `json:"serialNumber"`
+
+	// RevocationTime is the time when the certificate was revoked
+	RevocationTime time.Time `json:"revocationTime"`
+
+	// ExpirationTime is the original expiration time of the certificate
+	ExpirationTime time.Time `json:"expirationTime"`
+}
+
+func NewCertificateRevocationDTO(
+	issuer string,
+	serialNumber string,
+	revocationTime time.Time,
+	expirationTime time.Time,
+) CertificateRevocationDTO {
+	return CertificateRevocationDTO{
+		Issuer:         issuer,
+		SerialNumber:   serialNumber,
+		RevocationTime: revocationTime,
+		ExpirationTime: expirationTime,
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewCertificateRevocationDTO(t *testing.T) {
+	revocationTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	expirationTime := revocationTime.Add(time.Hour)
+	dto := appdtos.NewCertificateRevocationDTO("1", "2", revocationTime, expirationTime)
+	assert.Equal(t, "1", dto.Issuer)
+	assert.Equal(t, "2", dto.SerialNumber)
+	assert.Equal(t, revocationTime, dto.RevocationTime)
+	assert.Equal(t, expirationTime, dto.ExpirationTime)
+}
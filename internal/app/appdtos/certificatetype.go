@@ -10,6 +10,10 @@ const (
 	IntermediateCertificate CertificateType = "intermediate"
 	ServerCertificate       CertificateType = "server"
 	ClientCertificate       CertificateType = "client"
+
+	// SpiffeCertificate is an X.509-SVID for both client and server
+	// authentication of a SPIFFE workload
+	SpiffeCertificate CertificateType = "spiffe"
 )
 
 func (f CertificateType) IsClientCertificate() bool {
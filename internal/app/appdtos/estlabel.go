@@ -0,0 +1,87 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+// EstLabelDTO describes an EST (RFC 7030) label of an issuing certificate
+type EstLabelDTO struct {
+
+	// Label is the path segment of the EST server, e.g. "devices" in
+	// "/.well-known/est/devices/simpleenroll"
+	Label string `json:"label"`
+
+	// Organization is the ID of the organization
+	Organization string `json:"organization"`
+
+	// SerialNumber is the serial number of the issuing certificate
+	SerialNumber string `json:"serialNumber"`
+
+	// Username for HTTP basic authentication. Empty means only TLS client
+	// certificates are accepted.
+	Username string `json:"username,omitempty"`
+}
+
+func NewEstLabelDTO(
+	label string,
+	organization string,
+	serialNumber string,
+	username string,
+) EstLabelDTO {
+	return EstLabelDTO{
+		Label:        label,
+		Organization: organization,
+		SerialNumber: serialNumber,
+		Username:     username,
+	}
+}
+
+// EstLabelRequestDTO is the body for creating EST labels
+type EstLabelRequestDTO struct {
+
+	// Label is the path segment of the EST server
+	Label string `json:"label"`
+
+	// Username for HTTP basic authentication, or empty
+	Username string `json:"username,omitempty"`
+
+	// Password for HTTP basic authentication
+	Password string `json:"password,omitempty"`
+}
+
+func NewEstLabelRequestDTO(
+	label string,
+	username string,
+	password string,
+) EstLabelRequestDTO {
+	return EstLabelRequestDTO{
+		Label:    label,
+		Username: username,
+		Password: password,
+	}
+}
+
+// EstLabelRecordDTO is the stored form of an EST label
+type EstLabelRecordDTO struct {
+	Label        string `json:"label"`
+	Organization string `json:"organization"`
+	SerialNumber string `json:"serialNumber"`
+	Username     string `json:"username,omitempty"`
+
+	// PasswordHash is the bcrypt hash of the basic authentication password
+	PasswordHash []byte `json:"passwordHash,omitempty"`
+}
+
+func NewEstLabelRecordDTO(
+	label string,
+	organization string,
+	serialNumber string,
+	username string,
+	passwordHash []byte,
+) EstLabelRecordDTO {
+	return EstLabelRecordDTO{
+		Label:        label,
+		Organization: organization,
+		SerialNumber: serialNumber,
+		Username:     username,
+		PasswordHash: passwordHash,
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewEstLabelDTO(t *testing.T) {
+	dto := appdtos.NewEstLabelDTO("devices", "1", "2", "user")
+	assert.Equal(t, "devices", dto.Label)
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "2", dto.SerialNumber)
+	assert.Equal(t, "user", dto.Username)
+}
+
+func TestNewEstLabelRequestDTO(t *testing.T) {
+	dto := appdtos.NewEstLabelRequestDTO("devices", "user", "secret")
+	assert.Equal(t, "devices", dto.Label)
+	assert.Equal(t, "user", dto.Username)
+	assert.Equal(t, "secret", dto.Password)
+}
+
+func TestNewEstLabelRecordDTO(t *testing.T) {
+	dto := appdtos.NewEstLabelRecordDTO("devices", "1", "2", "user", []byte("hash"))
+	assert.Equal(t, "devices", dto.Label)
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "2", dto.SerialNumber)
+	assert.Equal(t, "user", dto.Username)
+	assert.Equal(t, []byte("hash"), dto.PasswordHash)
+}
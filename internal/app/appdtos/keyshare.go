@@ -0,0 +1,59 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+// KeyShareRequestDTO requests a private key to be split into key shares
+type KeyShareRequestDTO struct {
+
+	// Shares is the number of key shares to create
+	Shares int `json:"shares"`
+
+	// Threshold is the number of key shares needed to reconstruct the key
+	Threshold int `json:"threshold"`
+}
+
+func NewKeyShareRequestDTO(
+	shares int,
+	threshold int,
+) KeyShareRequestDTO {
+	return KeyShareRequestDTO{
+		Shares:    shares,
+		Threshold: threshold,
+	}
+}
+
+// KeySharesDTO describes the key shares of a private key. Shares are only
+// included when the key is exported.
+type KeySharesDTO struct {
+
+	// Organization is the ID of the organization
+	Organization string `json:"organization"`
+
+	// Certificate is the serial number of the certificate of the key
+	Certificate string `json:"certificate"`
+
+	// Type is the type of the private key
+	Type string `json:"type"`
+
+	// Threshold is the number of key shares needed to reconstruct the key
+	Threshold int `json:"threshold,omitempty"`
+
+	// Shares are the PEM encoded key shares
+	Shares []string `json:"shares,omitempty"`
+}
+
+func NewKeySharesDTO(
+	organization string,
+	certificate string,
+	keyType string,
+	threshold int,
+	shares []string,
+) KeySharesDTO {
+	return KeySharesDTO{
+		Organization: organization,
+		Certificate:  certificate,
+		Type:         keyType,
+		Threshold:    threshold,
+		Shares:       shares,
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewKeyShareRequestDTO(t *testing.T) {
+	dto := appdtos.NewKeyShareRequestDTO(5, 3)
+	assert.Equal(t, 5, dto.Shares)
+	assert.Equal(t, 3, dto.Threshold)
+}
+
+func TestNewKeySharesDTO(t *testing.T) {
+	dto := appdtos.NewKeySharesDTO("1", "2", "ECDSA_P384", 3, []string{"share"})
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "2", dto.Certificate)
+	assert.Equal(t, "ECDSA_P384", dto.Type)
+	assert.Equal(t, 3, dto.Threshold)
+	assert.Equal(t, []string{"share"}, dto.Shares)
+}
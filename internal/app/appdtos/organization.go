@@ -7,16 +7,34 @@ type OrganizationDTO struct {
 	Slug     string   `json:"slug"`
 	Name     string   `json:"name"`
 	AllNames []string `json:"allNames"`
+
+	// SignatureAlgorithm is the default signature algorithm for certificates
+	// issued by the organization. Empty means the default for the issuer key.
+	SignatureAlgorithm string `json:"signatureAlgorithm,omitempty"`
+
+	// SpiffeTrustDomain is the SPIFFE trust domain name, e.g. "example.org".
+	// Empty means SPIFFE mode is not enabled.
+	SpiffeTrustDomain string `json:"spiffeTrustDomain,omitempty"`
+
+	// KeyRetentionPolicy defines if private keys of leaf certificates are
+	// stored: "NONE", "ESCROW" or "RETAIN". Empty means "NONE".
+	KeyRetentionPolicy string `json:"keyRetentionPolicy,omitempty"`
 }
 
 func NewOrganizationDTO(
 	id, slug, name string,
 	allNames []string,
+	signatureAlgorithm string,
+	spiffeTrustDomain string,
+	keyRetentionPolicy string,
 ) OrganizationDTO {
 	return OrganizationDTO{
-		ID:       id,
-		Slug:     slug,
-		Name:     name,
-		AllNames: allNames,
+		ID:                 id,
+		Slug:               slug,
+		Name:               name,
+		AllNames:           allNames,
+		SignatureAlgorithm: signatureAlgorithm,
+		SpiffeTrustDomain:  spiffeTrustDomain,
+		KeyRetentionPolicy: keyRetentionPolicy,
 	}
 }
@@ -17,6 +17,9 @@ func TestNewOrganizationDTO(t *testing.T) {
 		slug     string
 		orgName  string
 		allNames []string
+		sigAlg   string
+		trustDom string
+		policy   string
 		want     appdtos.OrganizationDTO
 	}{
 		{
@@ -32,6 +35,21 @@ func TestNewOrganizationDTO(t *testing.T) {
 				AllNames: []string{"Organization One"},
 			},
 		},
+		{
+			name:     "Signature algorithm",
+			id:       "1003",
+			slug:     "org3",
+			orgName:  "Organization Three",
+			allNames: []string{"Organization Three"},
+			sigAlg:   "SHA384_WITH_RSA_PSS",
+			want: appdtos.OrganizationDTO{
+				ID:                 "1003",
+				Slug:               "org3",
+				Name:               "Organization Three",
+				AllNames:           []string{"Organization Three"},
+				SignatureAlgorithm: "SHA384_WITH_RSA_PSS",
+			},
+		},
 		{
 			name:     "Multiple names",
 			id:       "1002",
@@ -45,13 +63,43 @@ func TestNewOrganizationDTO(t *testing.T) {
 				AllNames: []string{"Organization Two", "Org 2"},
 			},
 		},
+		{
+			name:     "SPIFFE trust domain",
+			id:       "1004",
+			slug:     "org4",
+			orgName:  "Organization Four",
+			allNames: []string{"Organization Four"},
+			trustDom: "example.org",
+			want: appdtos.OrganizationDTO{
+				ID:                "1004",
+				Slug:              "org4",
+				Name:              "Organization Four",
+				AllNames:          []string{"Organization Four"},
+				SpiffeTrustDomain: "example.org",
+			},
+		},
+		{
+			name:     "Key retention policy",
+			id:       "1005",
+			slug:     "org5",
+			orgName:  "Organization Five",
+			allNames: []string{"Organization Five"},
+			policy:   "ESCROW",
+			want: appdtos.OrganizationDTO{
+				ID:                 "1005",
+				Slug:               "org5",
+				Name:               "Organization Five",
+				AllNames:           []string{"Organization Five"},
+				KeyRetentionPolicy: "ESCROW",
+			},
+		},
 		// Add more test cases as needed
 	}
 
 	// Execute tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := appdtos.NewOrganizationDTO(tt.id, tt.slug, tt.orgName, tt.allNames)
+			got := appdtos.NewOrganizationDTO(tt.id, tt.slug, tt.orgName, tt.allNames, tt.sigAlg, tt.trustDom, tt.policy)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewOrganizationDTO() = %v, want %v", got, tt.want)
 			}
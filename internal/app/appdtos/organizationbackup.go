@@ -0,0 +1,43 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+import (
+	"time"
+)
+
+// OrganizationBackupDTO summarizes the content of a backup archive
+type OrganizationBackupDTO struct {
+
+	// Version is the format version of the archive
+	Version int `json:"version"`
+
+	// CreatedAt is the time the backup was created
+	CreatedAt time.Time `json:"createdAt"`
+
+	Organization OrganizationDTO `json:"organization"`
+
+	// Certificates, PrivateKeys and Revocations are the number of items in
+	// the archive
+	Certificates int `json:"certificates"`
+	PrivateKeys  int `json:"privateKeys"`
+	Revocations  int `json:"revocations"`
+}
+
+func NewOrganizationBackupDTO(
+	version int,
+	createdAt time.Time,
+	organization OrganizationDTO,
+	certificates int,
+	privateKeys int,
+	revocations int,
+) OrganizationBackupDTO {
+	return OrganizationBackupDTO{
+		Version:      version,
+		CreatedAt:    createdAt,
+		Organization: organization,
+		Certificates: certificates,
+		PrivateKeys:  privateKeys,
+		Revocations:  revocations,
+	}
+}
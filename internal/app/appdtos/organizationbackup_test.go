@@ -0,0 +1,24 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewOrganizationBackupDTO(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	organization := appdtos.NewOrganizationDTO("10", "test", "Test", []string{"Test"}, "", "", "")
+	dto := appdtos.NewOrganizationBackupDTO(1, createdAt, organization, 3, 2, 1)
+	assert.Equal(t, 1, dto.Version)
+	assert.Equal(t, createdAt, dto.CreatedAt)
+	assert.Equal(t, organization, dto.Organization)
+	assert.Equal(t, 3, dto.Certificates)
+	assert.Equal(t, 2, dto.PrivateKeys)
+	assert.Equal(t, 1, dto.Revocations)
+}
@@ -4,6 +4,10 @@ package appdtos
 
 type OrganizationListDTO struct {
 	Payload []OrganizationDTO `json:"payload" jsonschema:"title=Organization Payload DTOs,required"`
+
+	// NextCursor continues the listing from the following page. It is empty
+	// on the last page.
+	NextCursor string `json:"nextCursor,omitempty" jsonschema:"title=Next page cursor"`
 }
 
 func NewOrganizationListDTO(
@@ -0,0 +1,32 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+import (
+	"time"
+)
+
+// RevocationListDTO is the last CRL created by an issuer
+type RevocationListDTO struct {
+
+	// Issuer is the serial number of the issuing certificate
+	Issuer string `json:"issuer"`
+
+	// Number is the CRL number
+	Number string `json:"number"`
+
+	// ThisUpdate is the time the CRL was created
+	ThisUpdate time.Time `json:"thisUpdate"`
+}
+
+func NewRevocationListDTO(
+	issuer string,
+	number string,
+	thisUpdate time.Time,
+) RevocationListDTO {
+	return RevocationListDTO{
+		Issuer:     issuer,
+		Number:     number,
+		ThisUpdate: thisUpdate,
+	}
+}
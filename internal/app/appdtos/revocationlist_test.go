@@ -0,0 +1,20 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewRevocationListDTO(t *testing.T) {
+	now := time.Now()
+	dto := appdtos.NewRevocationListDTO("1", "2", now)
+	assert.Equal(t, "1", dto.Issuer)
+	assert.Equal(t, "2", dto.Number)
+	assert.Equal(t, now, dto.ThisUpdate)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+// ScepChallengeDTO describes the SCEP challenge password of an organization.
+// The password itself is never returned.
+type ScepChallengeDTO struct {
+
+	// Organization is the ID of the organization
+	Organization string `json:"organization"`
+}
+
+func NewScepChallengeDTO(
+	organization string,
+) ScepChallengeDTO {
+	return ScepChallengeDTO{
+		Organization: organization,
+	}
+}
+
+// ScepChallengeRequestDTO is the body for setting the SCEP challenge password
+type ScepChallengeRequestDTO struct {
+
+	// ChallengePassword is the password SCEP clients include in their
+	// certificate signing requests
+	ChallengePassword string `json:"challengePassword"`
+}
+
+func NewScepChallengeRequestDTO(
+	challengePassword string,
+) ScepChallengeRequestDTO {
+	return ScepChallengeRequestDTO{
+		ChallengePassword: challengePassword,
+	}
+}
+
+// ScepChallengeRecordDTO is the stored form of a SCEP challenge password
+type ScepChallengeRecordDTO struct {
+	Organization string `json:"organization"`
+
+	// PasswordHash is the bcrypt hash of the challenge password
+	PasswordHash []byte `json:"passwordHash"`
+}
+
+func NewScepChallengeRecordDTO(
+	organization string,
+	passwordHash []byte,
+) ScepChallengeRecordDTO {
+	return ScepChallengeRecordDTO{
+		Organization: organization,
+		PasswordHash: passwordHash,
+	}
+}
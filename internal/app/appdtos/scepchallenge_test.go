@@ -0,0 +1,27 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewScepChallengeDTO(t *testing.T) {
+	dto := appdtos.NewScepChallengeDTO("1")
+	assert.Equal(t, "1", dto.Organization)
+}
+
+func TestNewScepChallengeRequestDTO(t *testing.T) {
+	dto := appdtos.NewScepChallengeRequestDTO("secret")
+	assert.Equal(t, "secret", dto.ChallengePassword)
+}
+
+func TestNewScepChallengeRecordDTO(t *testing.T) {
+	dto := appdtos.NewScepChallengeRecordDTO("1", []byte("hash"))
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, []byte("hash"), dto.PasswordHash)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+// SealConfigDTO is the seal file, which keeps the seal key encrypted with
+// the passphrase. The key shares are never stored.
+type SealConfigDTO struct {
+
+	// Version is the format version of the seal file
+	Version int `json:"version"`
+
+	// Encryption describes how WrappedKey is encrypted with the passphrase
+	Encryption BackupEncryptionDTO `json:"encryption"`
+
+	// WrappedKey is the encrypted seal key
+	WrappedKey []byte `json:"wrappedKey"`
+
+	// KeyCheck is a HMAC-SHA256 of a fixed message with the seal key. It
+	// verifies keys combined from shares.
+	KeyCheck []byte `json:"keyCheck"`
+
+	// Shares and Threshold are the number of key shares and the number of
+	// shares needed to unseal. Both are zero without shares.
+	Shares    int `json:"shares,omitempty"`
+	Threshold int `json:"threshold,omitempty"`
+}
+
+func NewSealConfigDTO(
+	version int,
+	encryption BackupEncryptionDTO,
+	wrappedKey []byte,
+	keyCheck []byte,
+	shares int,
+	threshold int,
+) SealConfigDTO {
+	return SealConfigDTO{
+		Version:    version,
+		Encryption: encryption,
+		WrappedKey: wrappedKey,
+		KeyCheck:   keyCheck,
+		Shares:     shares,
+		Threshold:  threshold,
+	}
+}
+
+// SealStatusDTO is the state of the seal
+type SealStatusDTO struct {
+	Sealed bool `json:"sealed"`
+
+	// Threshold is the number of key shares needed to unseal, or 0 if only
+	// the passphrase unseals
+	Threshold int `json:"threshold"`
+
+	// Progress is the number of key shares provided so far
+	Progress int `json:"progress"`
+}
+
+func NewSealStatusDTO(
+	sealed bool,
+	threshold int,
+	progress int,
+) SealStatusDTO {
+	return SealStatusDTO{
+		Sealed:    sealed,
+		Threshold: threshold,
+		Progress:  progress,
+	}
+}
+
+// UnsealDTO is the request to unseal with either the passphrase or a key
+// share
+type UnsealDTO struct {
+	Passphrase string `json:"passphrase,omitempty"`
+
+	// Share is a key share in base64
+	Share string `json:"share,omitempty"`
+}
+
+func NewUnsealDTO(
+	passphrase string,
+	share string,
+) UnsealDTO {
+	return UnsealDTO{
+		Passphrase: passphrase,
+		Share:      share,
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewSealConfigDTO(t *testing.T) {
+	encryption := appdtos.NewBackupEncryptionDTO("AES-256-GCM", "scrypt", []byte("salt"), 32768, 8, 1, []byte("nonce"))
+	dto := appdtos.NewSealConfigDTO(1, encryption, []byte("wrapped"), []byte("check"), 5, 3)
+	assert.Equal(t, 1, dto.Version)
+	assert.Equal(t, encryption, dto.Encryption)
+	assert.Equal(t, []byte("wrapped"), dto.WrappedKey)
+	assert.Equal(t, []byte("check"), dto.KeyCheck)
+	assert.Equal(t, 5, dto.Shares)
+	assert.Equal(t, 3, dto.Threshold)
+}
+
+func TestNewSealStatusDTO(t *testing.T) {
+	dto := appdtos.NewSealStatusDTO(true, 3, 1)
+	assert.True(t, dto.Sealed)
+	assert.Equal(t, 3, dto.Threshold)
+	assert.Equal(t, 1, dto.Progress)
+}
+
+func TestNewUnsealDTO(t *testing.T) {
+	dto := appdtos.NewUnsealDTO("secret", "c2hhcmU=")
+	assert.Equal(t, "secret", dto.Passphrase)
+	assert.Equal(t, "c2hhcmU=", dto.Share)
+}
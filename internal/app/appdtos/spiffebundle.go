@@ -0,0 +1,52 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+// SpiffeJwkDTO is a JSON Web Key in a SPIFFE bundle. X.509 authorities have
+// the use "x509-svid" and exactly one certificate in X5c.
+type SpiffeJwkDTO struct {
+	JwkDTO
+
+	// Use is the SPIFFE key use, e.g. "x509-svid"
+	Use string `json:"use"`
+
+	// X5c is the base64 (not base64url) encoded DER certificate chain
+	X5c []string `json:"x5c,omitempty"`
+}
+
+func NewSpiffeJwkDTO(
+	jwk JwkDTO,
+	use string,
+	x5c []string,
+) SpiffeJwkDTO {
+	return SpiffeJwkDTO{
+		JwkDTO: jwk,
+		Use:    use,
+		X5c:    x5c,
+	}
+}
+
+// SpiffeBundleDTO is a SPIFFE trust bundle in the SPIFFE bundle format, which
+// is a JWK set with SPIFFE specific parameters
+type SpiffeBundleDTO struct {
+	Keys []SpiffeJwkDTO `json:"keys"`
+
+	// Sequence is the sequence number of the bundle. Zero means not set.
+	Sequence uint64 `json:"spiffe_sequence,omitempty"`
+
+	// RefreshHint is how often in seconds the consumers should check for
+	// updates. Zero means not set.
+	RefreshHint int `json:"spiffe_refresh_hint,omitempty"`
+}
+
+func NewSpiffeBundleDTO(
+	keys []SpiffeJwkDTO,
+	sequence uint64,
+	refreshHint int,
+) SpiffeBundleDTO {
+	return SpiffeBundleDTO{
+		Keys:        keys,
+		Sequence:    sequence,
+		RefreshHint: refreshHint,
+	}
+}
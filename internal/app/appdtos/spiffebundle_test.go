@@ -0,0 +1,39 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewSpiffeJwkDTO(t *testing.T) {
+	jwk := appdtos.NewJwkDTO("EC", "P-256", "x", "y", "", "")
+	dto := appdtos.NewSpiffeJwkDTO(jwk, "x509-svid", []string{"der"})
+	assert.Equal(t, jwk, dto.JwkDTO)
+	assert.Equal(t, "x509-svid", dto.Use)
+	assert.Equal(t, []string{"der"}, dto.X5c)
+}
+
+func TestNewSpiffeBundleDTO(t *testing.T) {
+	keys := []appdtos.SpiffeJwkDTO{
+		appdtos.NewSpiffeJwkDTO(appdtos.NewJwkDTO("EC", "P-256", "x", "y", "", ""), "x509-svid", []string{"der"}),
+	}
+	dto := appdtos.NewSpiffeBundleDTO(keys, 2, 300)
+	assert.Equal(t, keys, dto.Keys)
+	assert.Equal(t, uint64(2), dto.Sequence)
+	assert.Equal(t, 300, dto.RefreshHint)
+
+	data, err := json.Marshal(dto)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"keys": [{"kty": "EC", "crv": "P-256", "x": "x", "y": "y", "use": "x509-svid", "x5c": ["der"]}],
+		"spiffe_sequence": 2,
+		"spiffe_refresh_hint": 300
+	}`, string(data))
+}
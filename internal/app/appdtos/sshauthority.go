@@ -0,0 +1,78 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+import (
+	"time"
+)
+
+// SshAuthorityDTO describes the SSH certificate authority of an organization
+type SshAuthorityDTO struct {
+
+	// Organization is the ID of the organization
+	Organization string `json:"organization"`
+
+	// KeyType is the type of the CA key, e.g. "Ed25519"
+	KeyType string `json:"keyType"`
+
+	// PublicKey is the CA public key in the authorized_keys format
+	PublicKey string `json:"publicKey"`
+}
+
+func NewSshAuthorityDTO(
+	organization string,
+	keyType string,
+	publicKey string,
+) SshAuthorityDTO {
+	return SshAuthorityDTO{
+		Organization: organization,
+		KeyType:      keyType,
+		PublicKey:    publicKey,
+	}
+}
+
+// SshAuthorityRequestDTO is the body for creating the SSH certificate
+// authority of an organization
+type SshAuthorityRequestDTO struct {
+
+	// KeyType of the CA key, e.g. "Ed25519". Empty means the default.
+	KeyType string `json:"keyType,omitempty"`
+}
+
+func NewSshAuthorityRequestDTO(
+	keyType string,
+) SshAuthorityRequestDTO {
+	return SshAuthorityRequestDTO{
+		KeyType: keyType,
+	}
+}
+
+// SshAuthorityRecordDTO is the stored form of the SSH certificate authority
+// of an organization
+type SshAuthorityRecordDTO struct {
+
+	// Organization is the ID of the organization
+	Organization string `json:"organization"`
+
+	// ID is the unique identifier of the CA key
+	ID string `json:"id"`
+
+	// PrivateKey is the CA key as PEM. It is encrypted if the key is sealed.
+	PrivateKey string `json:"privateKey"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewSshAuthorityRecordDTO(
+	organization string,
+	id string,
+	privateKey string,
+	createdAt time.Time,
+) SshAuthorityRecordDTO {
+	return SshAuthorityRecordDTO{
+		Organization: organization,
+		ID:           id,
+		PrivateKey:   privateKey,
+		CreatedAt:    createdAt,
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewSshAuthorityDTO(t *testing.T) {
+	dto := appdtos.NewSshAuthorityDTO("1", "Ed25519", "ssh-ed25519 AAAA")
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "Ed25519", dto.KeyType)
+	assert.Equal(t, "ssh-ed25519 AAAA", dto.PublicKey)
+}
+
+func TestNewSshAuthorityRequestDTO(t *testing.T) {
+	dto := appdtos.NewSshAuthorityRequestDTO("ECDSA_P256")
+	assert.Equal(t, "ECDSA_P256", dto.KeyType)
+}
+
+func TestNewSshAuthorityRecordDTO(t *testing.T) {
+	now := time.Now()
+	dto := appdtos.NewSshAuthorityRecordDTO("1", "2", "PEM", now)
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "2", dto.ID)
+	assert.Equal(t, "PEM", dto.PrivateKey)
+	assert.Equal(t, now, dto.CreatedAt)
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+import (
+	"time"
+)
+
+// SshCertificateDTO describes an SSH certificate signed by the SSH
+// certificate authority of an organization
+type SshCertificateDTO struct {
+
+	// Organization is the ID of the organization
+	Organization string `json:"organization"`
+
+	// Serial is the serial number of the certificate
+	Serial string `json:"serial"`
+
+	// Type is either "user" or "host"
+	Type string `json:"type"`
+
+	// KeyID is the key identifier logged by the server
+	KeyID string `json:"keyId"`
+
+	// Principals are the user or host names the certificate is valid for
+	Principals []string `json:"principals"`
+
+	ValidAfter  time.Time `json:"validAfter"`
+	ValidBefore time.Time `json:"validBefore"`
+
+	// Revoked is true if the certificate has been revoked
+	Revoked bool `json:"revoked"`
+
+	// Certificate is the certificate in the OpenSSH format
+	Certificate string `json:"certificate"`
+}
+
+func NewSshCertificateDTO(
+	organization string,
+	serial string,
+	certificateType string,
+	keyID string,
+	principals []string,
+	validAfter time.Time,
+	validBefore time.Time,
+	revoked bool,
+	certificate string,
+) SshCertificateDTO {
+	return SshCertificateDTO{
+		Organization: organization,
+		Serial:       serial,
+		Type:         certificateType,
+		KeyID:        keyID,
+		Principals:   principals,
+		ValidAfter:   validAfter,
+		ValidBefore:  validBefore,
+		Revoked:      revoked,
+		Certificate:  certificate,
+	}
+}
+
+// SshCertificateListDTO is a list of SSH certificates
+type SshCertificateListDTO struct {
+	Payload []SshCertificateDTO `json:"payload" jsonschema:"title=SSH Certificate Payload DTOs,required"`
+}
+
+func NewSshCertificateListDTO(
+	payload []SshCertificateDTO,
+) SshCertificateListDTO {
+	return SshCertificateListDTO{
+		Payload: payload,
+	}
+}
+
+// SshCertificateRequestDTO is the body for signing SSH certificates
+type SshCertificateRequestDTO struct {
+
+	// Type is either "user" or "host"
+	Type string `json:"type"`
+
+	// PublicKey is the public key to certify in the authorized_keys format
+	PublicKey string `json:"publicKey"`
+
+	// KeyID is the key identifier logged by the server
+	KeyID string `json:"keyId"`
+
+	// Principals are the user or host names the certificate is valid for
+	Principals []string `json:"principals"`
+
+	// Expiration in minutes. Zero means the default.
+	Expiration int `json:"expiration,omitempty"`
+
+	// CriticalOptions, e.g. "force-command" or "source-address"
+	CriticalOptions map[string]string `json:"criticalOptions,omitempty"`
+
+	// Extensions, e.g. "permit-pty". When not set, user certificates get the
+	// default extensions of ssh-keygen.
+	Extensions map[string]string `json:"extensions,omitempty"`
+}
+
+func NewSshCertificateRequestDTO(
+	certificateType string,
+	publicKey string,
+	keyID string,
+	principals []string,
+	expiration int,
+	criticalOptions map[string]string,
+	extensions map[string]string,
+) SshCertificateRequestDTO {
+	return SshCertificateRequestDTO{
+		Type:            certificateType,
+		PublicKey:       publicKey,
+		KeyID:           keyID,
+		Principals:      principals,
+		Expiration:      expiration,
+		CriticalOptions: criticalOptions,
+		Extensions:      extensions,
+	}
+}
+
+// SshCertificateRecordDTO is the stored form of a signed SSH certificate
+type SshCertificateRecordDTO struct {
+
+	// Organization is the ID of the organization
+	Organization string `json:"organization"`
+
+	// Certificate is the certificate in the OpenSSH wire format
+	Certificate []byte `json:"certificate"`
+
+	// RevokedAt is the time of revocation, or zero if not revoked
+	RevokedAt time.Time `json:"revokedAt"`
+}
+
+func NewSshCertificateRecordDTO(
+	organization string,
+	certificate []byte,
+	revokedAt time.Time,
+) SshCertificateRecordDTO {
+	return SshCertificateRecordDTO{
+		Organization: organization,
+		Certificate:  certificate,
+		RevokedAt:    revokedAt,
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewSshCertificateDTO(t *testing.T) {
+	validAfter := time.Unix(1000, 0)
+	validBefore := time.Unix(2000, 0)
+	dto := appdtos.NewSshCertificateDTO("1", "2", "user", "alice", []string{"alice"}, validAfter, validBefore, true, "cert")
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "2", dto.Serial)
+	assert.Equal(t, "user", dto.Type)
+	assert.Equal(t, "alice", dto.KeyID)
+	assert.Equal(t, []string{"alice"}, dto.Principals)
+	assert.Equal(t, validAfter, dto.ValidAfter)
+	assert.Equal(t, validBefore, dto.ValidBefore)
+	assert.True(t, dto.Revoked)
+	assert.Equal(t, "cert", dto.Certificate)
+}
+
+func TestNewSshCertificateListDTO(t *testing.T) {
+	payload := []appdtos.SshCertificateDTO{{Serial: "1"}}
+	dto := appdtos.NewSshCertificateListDTO(payload)
+	assert.Equal(t, payload, dto.Payload)
+}
+
+func TestNewSshCertificateRequestDTO(t *testing.T) {
+	dto := appdtos.NewSshCertificateRequestDTO(
+		"host",
+		"ssh-ed25519 AAAA",
+		"host-1",
+		[]string{"host1.example.com"},
+		60,
+		map[string]string{"source-address": "10.0.0.0/8"},
+		map[string]string{},
+	)
+	assert.Equal(t, "host", dto.Type)
+	assert.Equal(t, "ssh-ed25519 AAAA", dto.PublicKey)
+	assert.Equal(t, "host-1", dto.KeyID)
+	assert.Equal(t, []string{"host1.example.com"}, dto.Principals)
+	assert.Equal(t, 60, dto.Expiration)
+	assert.Equal(t, map[string]string{"source-address": "10.0.0.0/8"}, dto.CriticalOptions)
+	assert.Equal(t, map[string]string{}, dto.Extensions)
+}
+
+func TestNewSshCertificateRecordDTO(t *testing.T) {
+	revokedAt := time.Unix(3000, 0)
+	dto := appdtos.NewSshCertificateRecordDTO("1", []byte{1, 2}, revokedAt)
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, []byte{1, 2}, dto.Certificate)
+	assert.Equal(t, revokedAt, dto.RevokedAt)
+}
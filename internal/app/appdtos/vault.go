@@ -0,0 +1,316 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos
+
+// VaultResponseDTO is the envelope of HashiCorp Vault API responses
+type VaultResponseDTO struct {
+	RequestID     string   `json:"request_id"`
+	LeaseID       string   `json:"lease_id"`
+	Renewable     bool     `json:"renewable"`
+	LeaseDuration int      `json:"lease_duration"`
+	Data          any      `json:"data" jsonschema:"type=object"`
+	WrapInfo      any      `json:"wrap_info" jsonschema:"type=object"`
+	Warnings      []string `json:"warnings"`
+	Auth          any      `json:"auth" jsonschema:"type=object"`
+}
+
+func NewVaultResponseDTO(
+	requestID string,
+	data any,
+) VaultResponseDTO {
+	return VaultResponseDTO{
+		RequestID: requestID,
+		Data:      data,
+	}
+}
+
+// VaultErrorDTO is the body of HashiCorp Vault API error responses
+type VaultErrorDTO struct {
+	Errors []string `json:"errors"`
+}
+
+func NewVaultErrorDTO(
+	errors []string,
+) VaultErrorDTO {
+	return VaultErrorDTO{
+		Errors: errors,
+	}
+}
+
+// VaultListDTO is the data of Vault LIST responses
+type VaultListDTO struct {
+	Keys []string `json:"keys"`
+}
+
+func NewVaultListDTO(
+	keys []string,
+) VaultListDTO {
+	return VaultListDTO{
+		Keys: keys,
+	}
+}
+
+// VaultRoleRequestDTO is the body for writing Vault PKI roles
+type VaultRoleRequestDTO struct {
+
+	// IssuerRef is the serial number of the issuing certificate, or "default"
+	// for the newest root certificate of the organization
+	IssuerRef string `json:"issuer_ref,omitempty"`
+
+	// AllowedDomains is a comma separated string or a list of domains. It is
+	// documented as a string since the schema cannot describe both.
+	AllowedDomains any `json:"allowed_domains,omitempty" jsonschema:"type=string"`
+
+	AllowSubdomains  bool `json:"allow_subdomains,omitempty"`
+	AllowBareDomains bool `json:"allow_bare_domains,omitempty"`
+	AllowAnyName     bool `json:"allow_any_name,omitempty"`
+
+	// ServerFlag selects server certificates. Vault defaults to true.
+	ServerFlag *bool `json:"server_flag,omitempty"`
+
+	// ClientFlag is accepted for compatibility. Server certificates are
+	// valid for clients as well.
+	ClientFlag *bool `json:"client_flag,omitempty"`
+
+	// KeyType is "rsa", "ec", "ed25519" or "any"
+	KeyType string `json:"key_type,omitempty"`
+
+	// KeyBits is the size of RSA keys or the curve of EC keys, or zero
+	KeyBits int `json:"key_bits,omitempty"`
+
+	// TTL is a duration string like "72h" or a number of seconds
+	TTL any `json:"ttl,omitempty" jsonschema:"oneof_type=string;integer"`
+
+	// MaxTTL is a duration string like "720h" or a number of seconds
+	MaxTTL any `json:"max_ttl,omitempty" jsonschema:"oneof_type=string;integer"`
+}
+
+func NewVaultRoleRequestDTO(
+	issuerRef string,
+	allowedDomains any,
+	allowSubdomains bool,
+	allowBareDomains bool,
+	allowAnyName bool,
+	serverFlag *bool,
+	clientFlag *bool,
+	keyType string,
+	keyBits int,
+	ttl any,
+	maxTTL any,
+) VaultRoleRequestDTO {
+	return VaultRoleRequestDTO{
+		IssuerRef:        issuerRef,
+		AllowedDomains:   allowedDomains,
+		AllowSubdomains:  allowSubdomains,
+		AllowBareDomains: allowBareDomains,
+		AllowAnyName:     allowAnyName,
+		ServerFlag:       serverFlag,
+		ClientFlag:       clientFlag,
+		KeyType:          keyType,
+		KeyBits:          keyBits,
+		TTL:              ttl,
+		MaxTTL:           maxTTL,
+	}
+}
+
+// VaultRoleDTO describes a Vault PKI role
+type VaultRoleDTO struct {
+
+	// IssuerRef is the serial number of the issuing certificate
+	IssuerRef string `json:"issuer_ref"`
+
+	AllowedDomains   []string `json:"allowed_domains"`
+	AllowSubdomains  bool     `json:"allow_subdomains"`
+	AllowBareDomains bool     `json:"allow_bare_domains"`
+	AllowAnyName     bool     `json:"allow_any_name"`
+	ServerFlag       bool     `json:"server_flag"`
+	ClientFlag       bool     `json:"client_flag"`
+	KeyType          string   `json:"key_type"`
+	KeyBits          int      `json:"key_bits"`
+
+	// TTL in seconds
+	TTL int64 `json:"ttl"`
+
+	// MaxTTL in seconds
+	MaxTTL int64 `json:"max_ttl"`
+}
+
+func NewVaultRoleDTO(
+	issuerRef string,
+	allowedDomains []string,
+	allowSubdomains bool,
+	allowBareDomains bool,
+	allowAnyName bool,
+	serverFlag bool,
+	clientFlag bool,
+	keyType string,
+	keyBits int,
+	ttl int64,
+	maxTTL int64,
+) VaultRoleDTO {
+	return VaultRoleDTO{
+		IssuerRef:        issuerRef,
+		AllowedDomains:   allowedDomains,
+		AllowSubdomains:  allowSubdomains,
+		AllowBareDomains: allowBareDomains,
+		AllowAnyName:     allowAnyName,
+		ServerFlag:       serverFlag,
+		ClientFlag:       clientFlag,
+		KeyType:          keyType,
+		KeyBits:          keyBits,
+		TTL:              ttl,
+		MaxTTL:           maxTTL,
+	}
+}
+
+// VaultCertificateRequestDTO is the body for issuing and signing
+// certificates with Vault PKI roles
+type VaultCertificateRequestDTO struct {
+	CommonName string `json:"common_name,omitempty"`
+
+	// AltNames is a comma separated list of DNS names
+	AltNames string `json:"alt_names,omitempty"`
+
+	// TTL is a duration string like "72h" or a number of seconds
+	TTL any `json:"ttl,omitempty" jsonschema:"oneof_type=string;integer"`
+
+	// Format must be empty or "pem"
+	Format string `json:"format,omitempty"`
+
+	// Csr is the PEM encoded certificate signing request of sign requests
+	Csr string `json:"csr,omitempty"`
+}
+
+func NewVaultCertificateRequestDTO(
+	commonName string,
+	altNames string,
+	ttl any,
+	format string,
+	csr string,
+) VaultCertificateRequestDTO {
+	return VaultCertificateRequestDTO{
+		CommonName: commonName,
+		AltNames:   altNames,
+		TTL:        ttl,
+		Format:     format,
+		Csr:        csr,
+	}
+}
+
+// VaultCertificateDTO is the data of Vault issue and sign responses
+type VaultCertificateDTO struct {
+	Certificate string   `json:"certificate"`
+	IssuingCA   string   `json:"issuing_ca"`
+	CAChain     []string `json:"ca_chain"`
+
+	// PrivateKey is empty for signed certificates
+	PrivateKey     string `json:"private_key,omitempty"`
+	PrivateKeyType string `json:"private_key_type,omitempty"`
+
+	// SerialNumber is colon separated hex, e.g. "39:dd:2e"
+	SerialNumber string `json:"serial_number"`
+
+	// Expiration is the expiration time in Unix seconds
+	Expiration int64 `json:"expiration"`
+}
+
+func NewVaultCertificateDTO(
+	certificate string,
+	issuingCA string,
+	caChain []string,
+	privateKey string,
+	privateKeyType string,
+	serialNumber string,
+	expiration int64,
+) VaultCertificateDTO {
+	return VaultCertificateDTO{
+		Certificate:    certificate,
+		IssuingCA:      issuingCA,
+		CAChain:        caChain,
+		PrivateKey:     privateKey,
+		PrivateKeyType: privateKeyType,
+		SerialNumber:   serialNumber,
+		Expiration:     expiration,
+	}
+}
+
+// VaultRevokeRequestDTO is the body for revoking certificates
+type VaultRevokeRequestDTO struct {
+
+	// SerialNumber is colon or hyphen separated hex
+	SerialNumber string `json:"serial_number"`
+}
+
+func NewVaultRevokeRequestDTO(
+	serialNumber string,
+) VaultRevokeRequestDTO {
+	return VaultRevokeRequestDTO{
+		SerialNumber: serialNumber,
+	}
+}
+
+// VaultRevokeDTO is the data of Vault revoke responses
+type VaultRevokeDTO struct {
+
+	// RevocationTime in Unix seconds
+	RevocationTime int64 `json:"revocation_time"`
+
+	RevocationTimeRFC3339 string `json:"revocation_time_rfc3339"`
+}
+
+func NewVaultRevokeDTO(
+	revocationTime int64,
+	revocationTimeRFC3339 string,
+) VaultRevokeDTO {
+	return VaultRevokeDTO{
+		RevocationTime:        revocationTime,
+		RevocationTimeRFC3339: revocationTimeRFC3339,
+	}
+}
+
+// VaultRoleRecordDTO is the stored form of a Vault PKI role
+type VaultRoleRecordDTO struct {
+	Name             string   `json:"name"`
+	Organization     string   `json:"organization"`
+	Issuer           string   `json:"issuer"`
+	AllowedDomains   []string `json:"allowedDomains"`
+	AllowSubdomains  bool     `json:"allowSubdomains"`
+	AllowBareDomains bool     `json:"allowBareDomains"`
+	AllowAnyName     bool     `json:"allowAnyName"`
+	ServerFlag       bool     `json:"serverFlag"`
+
+	// KeyType is the type of generated keys, or empty for the issuer default
+	KeyType string `json:"keyType,omitempty"`
+
+	// TTL and MaxTTL are in nanoseconds
+	TTL    int64 `json:"ttl"`
+	MaxTTL int64 `json:"maxTtl"`
+}
+
+func NewVaultRoleRecordDTO(
+	name string,
+	organization string,
+	issuer string,
+	allowedDomains []string,
+	allowSubdomains bool,
+	allowBareDomains bool,
+	allowAnyName bool,
+	serverFlag bool,
+	keyType string,
+	ttl int64,
+	maxTTL int64,
+) VaultRoleRecordDTO {
+	return VaultRoleRecordDTO{
+		Name:             name,
+		Organization:     organization,
+		Issuer:           issuer,
+		AllowedDomains:   allowedDomains,
+		AllowSubdomains:  allowSubdomains,
+		AllowBareDomains: allowBareDomains,
+		AllowAnyName:     allowAnyName,
+		ServerFlag:       serverFlag,
+		KeyType:          keyType,
+		TTL:              ttl,
+		MaxTTL:           maxTTL,
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appdtos_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+)
+
+func TestNewVaultResponseDTO(t *testing.T) {
+	dto := appdtos.NewVaultResponseDTO("id", appdtos.NewVaultListDTO([]string{"web"}))
+	data, err := json.Marshal(dto)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"request_id":"id","lease_id":"","renewable":false,"lease_duration":0,"data":{"keys":["web"]},"wrap_info":null,"warnings":null,"auth":null}`, string(data))
+}
+
+func TestNewVaultErrorDTO(t *testing.T) {
+	dto := appdtos.NewVaultErrorDTO([]string{"permission denied"})
+	assert.Equal(t, []string{"permission denied"}, dto.Errors)
+}
+
+func TestNewVaultRoleRequestDTO(t *testing.T) {
+	serverFlag := false
+	dto := appdtos.NewVaultRoleRequestDTO("default", "example.com", true, false, false, &serverFlag, nil, "ec", 256, "72h", 3600)
+	assert.Equal(t, "default", dto.IssuerRef)
+	assert.Equal(t, "example.com", dto.AllowedDomains)
+	assert.True(t, dto.AllowSubdomains)
+	assert.False(t, dto.AllowBareDomains)
+	assert.False(t, dto.AllowAnyName)
+	assert.Equal(t, &serverFlag, dto.ServerFlag)
+	assert.Nil(t, dto.ClientFlag)
+	assert.Equal(t, "ec", dto.KeyType)
+	assert.Equal(t, 256, dto.KeyBits)
+	assert.Equal(t, "72h", dto.TTL)
+	assert.Equal(t, 3600, dto.MaxTTL)
+}
+
+func TestNewVaultRoleDTO(t *testing.T) {
+	dto := appdtos.NewVaultRoleDTO("2", []string{"example.com"}, true, true, false, true, true, "rsa", 2048, 60, 120)
+	assert.Equal(t, "2", dto.IssuerRef)
+	assert.Equal(t, []string{"example.com"}, dto.AllowedDomains)
+	assert.True(t, dto.AllowSubdomains)
+	assert.True(t, dto.AllowBareDomains)
+	assert.False(t, dto.AllowAnyName)
+	assert.True(t, dto.ServerFlag)
+	assert.True(t, dto.ClientFlag)
+	assert.Equal(t, "rsa", dto.KeyType)
+	assert.Equal(t, 2048, dto.KeyBits)
+	assert.Equal(t, int64(60), dto.TTL)
+	assert.Equal(t, int64(120), dto.MaxTTL)
+}
+
+func TestNewVaultCertificateRequestDTO(t *testing.T) {
+	dto := appdtos.NewVaultCertificateRequestDTO("www.example.com", "example.com", "1h", "pem", "csr")
+	assert.Equal(t, "www.example.com", dto.CommonName)
+	assert.Equal(t, "example.com", dto.AltNames)
+	assert.Equal(t, "1h", dto.TTL)
+	assert.Equal(t, "pem", dto.Format)
+	assert.Equal(t, "csr", dto.Csr)
+}
+
+func TestNewVaultCertificateDTO(t *testing.T) {
+	dto := appdtos.NewVaultCertificateDTO("cert", "ca", []string{"ca"}, "key", "ec", "01:02", 1000)
+	assert.Equal(t, "cert", dto.Certificate)
+	assert.Equal(t, "ca", dto.IssuingCA)
+	assert.Equal(t, []string{"ca"}, dto.CAChain)
+	assert.Equal(t, "key", dto.PrivateKey)
+	assert.Equal(t, "ec", dto.PrivateKeyType)
+	assert.Equal(t, "01:02", dto.SerialNumber)
+	assert.Equal(t, int64(1000), dto.Expiration)
+}
+
+func TestNewVaultRevokeRequestDTO(t *testing.T) {
+	dto := appdtos.NewVaultRevokeRequestDTO("01:02")
+	assert.Equal(t, "01:02", dto.SerialNumber)
+}
+
+func TestNewVaultRevokeDTO(t *testing.T) {
+	dto := appdtos.NewVaultRevokeDTO(1000, "1970-01-01T00:16:40Z")
+	assert.Equal(t, int64(1000), dto.RevocationTime)
+	assert.Equal(t, "1970-01-01T00:16:40Z", dto.RevocationTimeRFC3339)
+}
+
+func TestNewVaultRoleRecordDTO(t *testing.T) {
+	dto := appdtos.NewVaultRoleRecordDTO("web", "1", "2", []string{"example.com"}, true, false, true, false, "RSA_2048", 60, 120)
+	assert.Equal(t, "web", dto.Name)
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "2", dto.Issuer)
+	assert.Equal(t, []string{"example.com"}, dto.AllowedDomains)
+	assert.True(t, dto.AllowSubdomains)
+	assert.False(t, dto.AllowBareDomains)
+	assert.True(t, dto.AllowAnyName)
+	assert.False(t, dto.ServerFlag)
+	assert.Equal(t, "RSA_2048", dto.KeyType)
+	assert.Equal(t, int64(60), dto.TTL)
+	assert.Equal(t, int64(120), dto.MaxTTL)
+}
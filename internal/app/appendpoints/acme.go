@@ -0,0 +1,259 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// AcmeJoseContentType is the content type of ACME POST requests
+const AcmeJoseContentType = "application/jose+json"
+
+// AcmeProblemContentType is the content type of ACME error responses
+const AcmeProblemContentType = "application/problem+json"
+
+// AcmeCertificateContentType is the content type of certificate downloads
+const AcmeCertificateContentType = "application/pem-certificate-chain"
+
+// acmeOrigin returns the scheme and host the request was sent to, e.g.
+// "https://example.com". X-Forwarded-Proto is only used when the request came
+// from a trusted proxy.
+func (c *HttpApiController) acmeOrigin(request apitypes.Request) string {
+	scheme := "http"
+	if request.IsTLS() || (c.isTrustedProxy(request) && request.Header("X-Forwarded-Proto") == "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + request.Host()
+}
+
+// isTrustedProxy returns true if the request was sent by a trusted reverse
+// proxy, whose forwarding headers can be used
+func (c *HttpApiController) isTrustedProxy(request apitypes.Request) bool {
+	if len(c.trustedProxies) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(request.RemoteAddr())
+	if err != nil {
+		host = request.RemoteAddr()
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range c.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// acmeBaseURL returns the absolute URL of the ACME directory of the request,
+// e.g. "https://example.com/acme/1/2"
+func (c *HttpApiController) acmeBaseURL(request apitypes.Request) string {
+	return fmt.Sprintf(
+		"%s/acme/%s/%s",
+		c.acmeOrigin(request),
+		request.Variable("organization"),
+		request.Variable("rootSerialNumber"),
+	)
+}
+
+// acmeIssuer returns the issuing certificate controller of the ACME directory.
+// The organization is addressed by its numeric ID.
+func (c *HttpApiController) acmeIssuer(request apitypes.Request) (appmodels.CertificateController, error) {
+	organization, err := apputils.ParseBigInt(request.Variable("organization"), 10)
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_NOT_FOUND, "directory not found: organization: %v", err)
+	}
+	rootSerialNumber, err := c.rootSerialNumber(request)
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_NOT_FOUND, "directory not found: %v", err)
+	}
+	organizationController, err := c.appController.OrganizationController(organization)
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_NOT_FOUND, "directory not found: %v", err)
+	}
+	issuer, err := organizationController.CertificateController(rootSerialNumber)
+	if err != nil {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_NOT_FOUND, "directory not found: %v", err)
+	}
+	if !issuer.Certificate().IsCA() {
+		return nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_NOT_FOUND, "directory not found: not a CA certificate")
+	}
+	return issuer, nil
+}
+
+// acmeHeaders sets the headers common to all ACME responses, including a
+// fresh anti-replay nonce
+func (c *HttpApiController) acmeHeaders(response apitypes.Response, request apitypes.Request) {
+	nonce, err := c.acmeController.NewNonce()
+	if err != nil {
+		c.logf(request, "failed to create nonce: %v", err)
+	} else {
+		response.SetHeader("Replay-Nonce", nonce)
+	}
+	response.SetHeader("Link", fmt.Sprintf("<%s/directory>;rel=\"index\"", c.acmeBaseURL(request)))
+	response.SetHeader("Cache-Control", "no-store")
+}
+
+// acmeProblem sends an ACME error response. Errors which are not
+// *appmodels.AcmeError are logged and reported as internal errors, and
+// sealed private keys as 503 Service Unavailable.
+func (c *HttpApiController) acmeProblem(response apitypes.Response, request apitypes.Request, err error) error {
+	var acmeErr *appmodels.AcmeError
+	sealed := errors.Is(err, appmodels.ErrSealed)
+	if sealed {
+		log.Printf("[%s %s]: %v", request.Method(), request.URL(), err)
+		acmeErr = appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "private keys are sealed")
+	} else if !errors.As(err, &acmeErr) {
+		log.Printf("[%s %s]: Internal Server Error: %v", request.Method(), request.URL(), err)
+		acmeErr = appmodels.NewAcmeError(appmodels.ACME_ERROR_SERVER_INTERNAL, "internal server error")
+	} else {
+		c.logf(request, "ACME error: %v", acmeErr)
+	}
+	dto := apputils.ToAcmeProblemDTO(acmeErr)
+	if sealed {
+		dto.Status = http.StatusServiceUnavailable
+	}
+	response.SetHeader("Content-Type", AcmeProblemContentType)
+	response.Send(dto.Status, dto)
+	return nil
+}
+
+// acmeJws parses an ACME request body, checks the URL in its protected header
+// and consumes its nonce. The signature is not verified.
+func (c *HttpApiController) acmeJws(request apitypes.Request) (appdtos.AcmeJwsDTO, appdtos.AcmeJwsHeaderDTO, []byte, error) {
+
+	body, err := request.BodyBytes()
+	if err != nil {
+		return appdtos.AcmeJwsDTO{}, appdtos.AcmeJwsHeaderDTO{}, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "failed to read body: %v", err)
+	}
+
+	jws, header, payload, err := apputils.ParseAcmeJws(body)
+	if err != nil {
+		return jws, header, nil, err
+	}
+
+	if header.URL != c.acmeOrigin(request)+request.URL().Path {
+		return jws, header, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_UNAUTHORIZED, "url in the protected header does not match the request: %s", header.URL)
+	}
+
+	if err := c.acmeController.UseNonce(header.Nonce); err != nil {
+		return jws, header, nil, err
+	}
+
+	return jws, header, payload, nil
+}
+
+// acmeVerifyJwk verifies a request signed with the key in its "jwk" header.
+// It is used only to create or look up accounts.
+func (c *HttpApiController) acmeVerifyJwk(request apitypes.Request) ([]byte, any, error) {
+
+	jws, header, payload, err := c.acmeJws(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(header.Key) == 0 {
+		return nil, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "jwk must be defined")
+	}
+
+	publicKey, err := apputils.ParseJwk(header.Key)
+	if err != nil {
+		return nil, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_SIGNATURE_ALGORITHM, "%v", err)
+	}
+
+	if err := apputils.VerifyAcmeJws(jws, header.Algorithm, publicKey); err != nil {
+		return nil, nil, err
+	}
+
+	return payload, publicKey, nil
+}
+
+// acmeVerifyKid verifies a request signed by an existing valid account in its
+// "kid" header
+func (c *HttpApiController) acmeVerifyKid(request apitypes.Request, issuer appmodels.CertificateController) ([]byte, appmodels.AcmeAccount, error) {
+
+	jws, header, payload, err := c.acmeJws(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prefix := c.acmeBaseURL(request) + "/account/"
+	if header.KeyID == "" || !strings.HasPrefix(header.KeyID, prefix) {
+		return nil, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "kid must be an account URL")
+	}
+
+	account, err := c.acmeController.Account(issuer, strings.TrimPrefix(header.KeyID, prefix))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := apputils.VerifyAcmeJws(jws, header.Algorithm, account.PublicKey()); err != nil {
+		return nil, nil, err
+	}
+
+	if account.Status() != appmodels.ACME_STATUS_VALID {
+		return nil, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_UNAUTHORIZED, "account is %s", account.Status())
+	}
+
+	return payload, account, nil
+}
+
+// acmeDecodePayload decodes a JSON payload of an ACME request
+func (c *HttpApiController) acmeDecodePayload(payload []byte, body any) error {
+	if err := json.Unmarshal(payload, body); err != nil {
+		return appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "failed to decode payload: %v", err)
+	}
+	return nil
+}
+
+func (c *HttpApiController) acmeAccountURL(request apitypes.Request, account appmodels.AcmeAccount) string {
+	return c.acmeBaseURL(request) + "/account/" + account.ID()
+}
+
+func (c *HttpApiController) acmeOrderURL(request apitypes.Request, order appmodels.AcmeOrder) string {
+	return c.acmeBaseURL(request) + "/order/" + order.ID()
+}
+
+func (c *HttpApiController) acmeAuthorizationURL(request apitypes.Request, authorization string) string {
+	return c.acmeBaseURL(request) + "/authz/" + authorization
+}
+
+func (c *HttpApiController) acmeAccountDTO(request apitypes.Request, account appmodels.AcmeAccount) appdtos.AcmeAccountDTO {
+	return apputils.ToAcmeAccountDTO(account, c.acmeAccountURL(request, account)+"/orders")
+}
+
+func (c *HttpApiController) acmeOrderDTO(request apitypes.Request, order appmodels.AcmeOrder) appdtos.AcmeOrderDTO {
+	baseURL := c.acmeBaseURL(request)
+	authorizations := make([]string, len(order.AuthorizationIDs()))
+	for i, id := range order.AuthorizationIDs() {
+		authorizations[i] = c.acmeAuthorizationURL(request, id)
+	}
+	certificateURL := ""
+	if order.CertificateSerialNumber() != nil {
+		certificateURL = baseURL + "/cert/" + order.CertificateSerialNumber().String()
+	}
+	return apputils.ToAcmeOrderDTO(
+		order,
+		authorizations,
+		c.acmeOrderURL(request, order)+"/finalize",
+		certificateURL,
+	)
+}
+
+func (c *HttpApiController) acmeAuthorizationDTO(request apitypes.Request, authorization appmodels.AcmeAuthorization) appdtos.AcmeAuthorizationDTO {
+	return apputils.ToAcmeAuthorizationDTO(authorization, c.acmeBaseURL(request)+"/chall/"+authorization.ID())
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// TestAcme_Client runs the complete ACME flow with the golang.org/x/crypto/acme
+// client using an http-01 challenge served from a local test server
+func TestAcme_Client(t *testing.T) {
+
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := filerepository.NewCollection(certManager, managers.NewFileManager(), t.TempDir())
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization := appmodels.NewSerialNumber(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	organizationController.SetExpirationDuration(24 * time.Hour)
+	root, err := organizationController.NewRootCertificate("Test Root CA")
+	require.NoError(t, err)
+
+	acmeRepository := memoryrepository.NewAcmeCollection()
+	acmeController := appcontrollers.NewAcmeController(
+		acmeRepository.Account,
+		acmeRepository.Order,
+		acmeRepository.Authorization,
+		acmeRepository.Nonce,
+		managers.NewNetworkManager(),
+		randomManager,
+		time.Hour,
+	)
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	controller.SetAcmeController(acmeController)
+
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// The http-01 challenge responses are served by the client
+	challenges := make(map[string]string)
+	challengeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if response, exists := challenges[r.URL.Path]; exists {
+			_, _ = w.Write([]byte(response))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer challengeServer.Close()
+	challengeURL, _ := url.Parse(challengeServer.URL)
+	port, _ := strconv.Atoi(challengeURL.Port())
+	acmeController.SetHttp01Port(port)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	client := &acme.Client{
+		Key:          key,
+		DirectoryURL: fmt.Sprintf("%s/acme/%s/%s/directory", server.URL, organization, root.SerialNumber()),
+	}
+	ctx := context.Background()
+
+	account, err := client.Register(ctx, &acme.Account{Contact: []string{"mailto:admin@example.com"}}, acme.AcceptTOS)
+	require.NoError(t, err)
+	assert.Equal(t, acme.StatusValid, account.Status)
+
+	_, err = client.Register(ctx, &acme.Account{}, acme.AcceptTOS)
+	assert.ErrorIs(t, err, acme.ErrAccountAlreadyExists)
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs("localhost"))
+	require.NoError(t, err)
+	assert.Equal(t, acme.StatusPending, order.Status)
+	require.Len(t, order.AuthzURLs, 1)
+
+	authorization, err := client.GetAuthorization(ctx, order.AuthzURLs[0])
+	require.NoError(t, err)
+
+	var challenge *acme.Challenge
+	for _, item := range authorization.Challenges {
+		if item.Type == "http-01" {
+			challenge = item
+		}
+	}
+	require.NotNil(t, challenge)
+
+	response, err := client.HTTP01ChallengeResponse(challenge.Token)
+	require.NoError(t, err)
+	challenges[client.HTTP01ChallengePath(challenge.Token)] = response
+
+	_, err = client.Accept(ctx, challenge)
+	require.NoError(t, err)
+
+	_, err = client.WaitAuthorization(ctx, authorization.URI)
+	require.NoError(t, err)
+
+	order, err = client.WaitOrder(ctx, order.URI)
+	require.NoError(t, err)
+	assert.Equal(t, acme.StatusReady, order.Status)
+
+	certificateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: []string{"localhost"}}, certificateKey)
+	require.NoError(t, err)
+
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+
+	leaf, err := x509.ParseCertificate(chain[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"localhost"}, leaf.DNSNames)
+	assert.True(t, leaf.PublicKey.(*ecdsa.PublicKey).Equal(&certificateKey.PublicKey))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(root.Certificate())
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: pool, DNSName: "localhost"})
+	assert.NoError(t, err)
+}
+
+func TestAcme_DisabledWithoutController(t *testing.T) {
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), nil, nil)
+
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/acme/1/2/directory")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestAcme_ForwardedProto(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := memoryrepository.NewCollection()
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+	organization := appmodels.NewSerialNumber(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	root, err := organizationController.NewRootCertificate("Test Root CA")
+	require.NoError(t, err)
+
+	acmeRepository := memoryrepository.NewAcmeCollection()
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	controller.SetAcmeController(appcontrollers.NewAcmeController(
+		acmeRepository.Account,
+		acmeRepository.Order,
+		acmeRepository.Authorization,
+		acmeRepository.Nonce,
+		managers.NewNetworkManager(),
+		randomManager,
+		time.Hour,
+	))
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	newNonce := func() string {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/acme/%s/%s/directory", server.URL, organization, root.SerialNumber()), nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Forwarded-Proto", "https")
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var directory map[string]any
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&directory))
+		return directory["newNonce"].(string)
+	}
+
+	// The header is ignored unless the client is a trusted proxy
+	assert.True(t, strings.HasPrefix(newNonce(), "http://"))
+	_, other, _ := net.ParseCIDR("192.0.2.0/24")
+	controller.SetTrustedProxies([]*net.IPNet{other})
+	assert.True(t, strings.HasPrefix(newNonce(), "http://"))
+	_, loopback, _ := net.ParseCIDR("127.0.0.0/8")
+	controller.SetTrustedProxies([]*net.IPNet{other, loopback})
+	assert.True(t, strings.HasPrefix(newNonce(), "https://"))
+}
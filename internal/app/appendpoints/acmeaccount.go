@@ -0,0 +1,83 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// AcmeAccountDefinitions returns OpenAPI definitions
+func (c *HttpApiController) AcmeAccountDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns, updates or deactivates an ACME account",
+		Description: "An empty payload returns the account. Otherwise the payload is an AcmeAccountRequestDTO.",
+		RequestBody: &swagger.ContentValue{
+			Description: "JWS request",
+			Content: swagger.Content{
+				AcmeJoseContentType: {
+					Value: appdtos.AcmeJwsDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.AcmeAccountDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// AcmeAccount handles a request
+func (c *HttpApiController) AcmeAccount(response apitypes.Response, request apitypes.Request) error {
+
+	if c.acmeController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.acmeIssuer(request)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	c.acmeHeaders(response, request)
+
+	payload, account, err := c.acmeVerifyKid(request, issuer)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	if account.ID() != request.Variable("accountId") {
+		return c.acmeProblem(response, request, appmodels.NewAcmeError(appmodels.ACME_ERROR_UNAUTHORIZED, "account does not match the key"))
+	}
+
+	if len(payload) != 0 {
+
+		var body appdtos.AcmeAccountRequestDTO
+		if err := c.acmeDecodePayload(payload, &body); err != nil {
+			return c.acmeProblem(response, request, err)
+		}
+
+		if body.Status == appmodels.ACME_STATUS_DEACTIVATED.String() {
+			account, err = c.acmeController.DeactivateAccount(account)
+		} else if body.Status != "" {
+			err = appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "unsupported status: %s", body.Status)
+		} else if body.Contact != nil {
+			account, err = c.acmeController.UpdateAccount(account, body.Contact)
+		}
+		if err != nil {
+			return c.acmeProblem(response, request, err)
+		}
+
+	}
+
+	return c.ok(response, c.acmeAccountDTO(request, account))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).AcmeAccountDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).AcmeAccount
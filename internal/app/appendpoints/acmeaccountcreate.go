@@ -0,0 +1,90 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"net/http"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// AcmeNewAccountDefinitions returns OpenAPI definitions
+func (c *HttpApiController) AcmeNewAccountDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Creates or looks up an ACME account",
+		Description: "The request is a JWS signed with the account key given in the jwk header. The payload is an AcmeAccountRequestDTO.",
+		RequestBody: &swagger.ContentValue{
+			Description: "JWS request",
+			Content: swagger.Content{
+				AcmeJoseContentType: {
+					Value: appdtos.AcmeJwsDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.AcmeAccountDTO{}},
+				},
+			},
+			201: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.AcmeAccountDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// AcmeNewAccount handles a request
+func (c *HttpApiController) AcmeNewAccount(response apitypes.Response, request apitypes.Request) error {
+
+	if c.acmeController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.acmeIssuer(request)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	c.acmeHeaders(response, request)
+
+	payload, publicKey, err := c.acmeVerifyJwk(request)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	var body appdtos.AcmeAccountRequestDTO
+	if err := c.acmeDecodePayload(payload, &body); err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	// An existing account is returned as is
+	if account, err := c.acmeController.AccountByKey(issuer, publicKey); err == nil {
+		response.SetHeader("Location", c.acmeAccountURL(request, account))
+		response.Send(http.StatusOK, c.acmeAccountDTO(request, account))
+		return nil
+	}
+
+	if body.OnlyReturnExisting {
+		return c.acmeProblem(response, request, appmodels.NewAcmeError(appmodels.ACME_ERROR_ACCOUNT_DOES_NOT_EXIST, "no account for the key"))
+	}
+
+	account, err := c.acmeController.NewAccount(issuer, publicKey, body.Contact)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+	c.logf(request, "created ACME account: %s", account.ID())
+
+	response.SetHeader("Location", c.acmeAccountURL(request, account))
+	response.Send(http.StatusCreated, c.acmeAccountDTO(request, account))
+	return nil
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).AcmeNewAccountDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).AcmeNewAccount
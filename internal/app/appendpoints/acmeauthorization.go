@@ -0,0 +1,63 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// AcmeAuthorizationDefinitions returns OpenAPI definitions
+func (c *HttpApiController) AcmeAuthorizationDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns an ACME authorization and its challenges",
+		Description: "",
+		RequestBody: &swagger.ContentValue{
+			Description: "JWS request with an empty payload",
+			Content: swagger.Content{
+				AcmeJoseContentType: {
+					Value: appdtos.AcmeJwsDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.AcmeAuthorizationDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// AcmeAuthorization handles a request
+func (c *HttpApiController) AcmeAuthorization(response apitypes.Response, request apitypes.Request) error {
+
+	if c.acmeController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.acmeIssuer(request)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	c.acmeHeaders(response, request)
+
+	_, account, err := c.acmeVerifyKid(request, issuer)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	authorization, err := c.acmeController.Authorization(account, request.Variable("authorizationId"))
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	return c.ok(response, c.acmeAuthorizationDTO(request, authorization))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).AcmeAuthorizationDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).AcmeAuthorization
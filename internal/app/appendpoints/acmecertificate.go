@@ -0,0 +1,76 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// AcmeCertificateDefinitions returns OpenAPI definitions
+func (c *HttpApiController) AcmeCertificateDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Downloads a certificate issued by ACME",
+		Description: "Returns the certificate followed by the issuing certificate in PEM format.",
+		RequestBody: &swagger.ContentValue{
+			Description: "JWS request with an empty payload",
+			Content: swagger.Content{
+				AcmeJoseContentType: {
+					Value: appdtos.AcmeJwsDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					AcmeCertificateContentType: {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// AcmeCertificate handles a request
+func (c *HttpApiController) AcmeCertificate(response apitypes.Response, request apitypes.Request) error {
+
+	if c.acmeController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.acmeIssuer(request)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	c.acmeHeaders(response, request)
+
+	_, account, err := c.acmeVerifyKid(request, issuer)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	serialNumber, err := c.serialNumber(request)
+	if err != nil {
+		return c.acmeProblem(response, request, appmodels.NewAcmeError(appmodels.ACME_ERROR_NOT_FOUND, "%v", err))
+	}
+
+	chain, err := c.acmeController.CertificateChain(issuer, account, serialNumber)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	var data []byte
+	for _, certificate := range chain {
+		data = append(data, apputils.CertificateToPEMBytes(certificate)...)
+	}
+
+	response.SetHeader("Content-Type", AcmeCertificateContentType)
+	return response.SendBytes(data)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).AcmeCertificateDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).AcmeCertificate
@@ -0,0 +1,75 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"fmt"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// AcmeChallengeDefinitions returns OpenAPI definitions
+func (c *HttpApiController) AcmeChallengeDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Validates an ACME challenge",
+		Description: "A payload of {} starts the validation. The validation completes before the response is sent.",
+		RequestBody: &swagger.ContentValue{
+			Description: "JWS request",
+			Content: swagger.Content{
+				AcmeJoseContentType: {
+					Value: appdtos.AcmeJwsDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.AcmeChallengeDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// AcmeChallenge handles a request
+func (c *HttpApiController) AcmeChallenge(response apitypes.Response, request apitypes.Request) error {
+
+	if c.acmeController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.acmeIssuer(request)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	c.acmeHeaders(response, request)
+
+	_, account, err := c.acmeVerifyKid(request, issuer)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	authorizationID := request.Variable("authorizationId")
+	challengeID := request.Variable("challengeId")
+
+	authorization, err := c.acmeController.ValidateChallenge(account, authorizationID, challengeID)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+	c.logf(request, "ACME authorization %s is %s", authorization.ID(), authorization.Status())
+
+	authorizationURL := c.acmeAuthorizationURL(request, authorization.ID())
+	response.SetHeader("Link", fmt.Sprintf("<%s>;rel=\"up\"", authorizationURL))
+	return c.ok(response, apputils.ToAcmeChallengeDTO(
+		authorization.Challenge(challengeID),
+		c.acmeBaseURL(request)+"/chall/"+authorization.ID()+"/"+challengeID,
+	))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).AcmeChallengeDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).AcmeChallenge
@@ -0,0 +1,48 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// AcmeDirectoryDefinitions returns OpenAPI definitions
+func (c *HttpApiController) AcmeDirectoryDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns the ACME directory of an issuing certificate",
+		Description: "ACME (RFC 8555) clients start from this URL.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.AcmeDirectoryDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// AcmeDirectory handles a request
+func (c *HttpApiController) AcmeDirectory(response apitypes.Response, request apitypes.Request) error {
+
+	if c.acmeController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	if _, err := c.acmeIssuer(request); err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	baseURL := c.acmeBaseURL(request)
+	response.SetHeader("Cache-Control", "no-store")
+	return c.ok(response, appdtos.NewAcmeDirectoryDTO(
+		baseURL+"/new-nonce",
+		baseURL+"/new-account",
+		baseURL+"/new-order",
+	))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).AcmeDirectoryDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).AcmeDirectory
@@ -0,0 +1,47 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"net/http"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// AcmeNewNonceDefinitions returns OpenAPI definitions
+func (c *HttpApiController) AcmeNewNonceDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns a new ACME anti-replay nonce in the Replay-Nonce header",
+		Description: "",
+		Responses: map[int]swagger.ContentValue{
+			200: {},
+			204: {},
+		},
+	}
+}
+
+// AcmeNewNonce handles a request
+func (c *HttpApiController) AcmeNewNonce(response apitypes.Response, request apitypes.Request) error {
+
+	if c.acmeController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	if _, err := c.acmeIssuer(request); err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	c.acmeHeaders(response, request)
+
+	if request.Method() == http.MethodHead {
+		response.SendStatus(http.StatusOK)
+	} else {
+		response.SendStatus(http.StatusNoContent)
+	}
+	return nil
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).AcmeNewNonceDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).AcmeNewNonce
@@ -0,0 +1,64 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// AcmeOrderDefinitions returns OpenAPI definitions
+func (c *HttpApiController) AcmeOrderDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns an ACME order",
+		Description: "",
+		RequestBody: &swagger.ContentValue{
+			Description: "JWS request with an empty payload",
+			Content: swagger.Content{
+				AcmeJoseContentType: {
+					Value: appdtos.AcmeJwsDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.AcmeOrderDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// AcmeOrder handles a request
+func (c *HttpApiController) AcmeOrder(response apitypes.Response, request apitypes.Request) error {
+
+	if c.acmeController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.acmeIssuer(request)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	c.acmeHeaders(response, request)
+
+	_, account, err := c.acmeVerifyKid(request, issuer)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	order, err := c.acmeController.Order(account, request.Variable("orderId"))
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	response.SetHeader("Location", c.acmeOrderURL(request, order))
+	return c.ok(response, c.acmeOrderDTO(request, order))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).AcmeOrderDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).AcmeOrder
@@ -0,0 +1,73 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// AcmeOrderCollectionDefinitions returns OpenAPI definitions
+func (c *HttpApiController) AcmeOrderCollectionDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns the orders of an ACME account",
+		Description: "",
+		RequestBody: &swagger.ContentValue{
+			Description: "JWS request with an empty payload",
+			Content: swagger.Content{
+				AcmeJoseContentType: {
+					Value: appdtos.AcmeJwsDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.AcmeOrderListDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// AcmeOrderCollection handles a request
+func (c *HttpApiController) AcmeOrderCollection(response apitypes.Response, request apitypes.Request) error {
+
+	if c.acmeController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.acmeIssuer(request)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	c.acmeHeaders(response, request)
+
+	_, account, err := c.acmeVerifyKid(request, issuer)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	if account.ID() != request.Variable("accountId") {
+		return c.acmeProblem(response, request, appmodels.NewAcmeError(appmodels.ACME_ERROR_UNAUTHORIZED, "account does not match the key"))
+	}
+
+	list, err := c.acmeController.OrderCollection(account)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	orders := make([]string, len(list))
+	for i, order := range list {
+		orders[i] = c.acmeOrderURL(request, order)
+	}
+
+	return c.ok(response, appdtos.NewAcmeOrderListDTO(orders))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).AcmeOrderCollectionDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).AcmeOrderCollection
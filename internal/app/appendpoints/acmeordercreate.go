@@ -0,0 +1,86 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"net/http"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// AcmeNewOrderDefinitions returns OpenAPI definitions
+func (c *HttpApiController) AcmeNewOrderDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Creates an ACME order",
+		Description: "The payload is an AcmeOrderRequestDTO. Only DNS identifiers are supported.",
+		RequestBody: &swagger.ContentValue{
+			Description: "JWS request",
+			Content: swagger.Content{
+				AcmeJoseContentType: {
+					Value: appdtos.AcmeJwsDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			201: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.AcmeOrderDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// AcmeNewOrder handles a request
+func (c *HttpApiController) AcmeNewOrder(response apitypes.Response, request apitypes.Request) error {
+
+	if c.acmeController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.acmeIssuer(request)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	c.acmeHeaders(response, request)
+
+	payload, account, err := c.acmeVerifyKid(request, issuer)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	var body appdtos.AcmeOrderRequestDTO
+	if err := c.acmeDecodePayload(payload, &body); err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	if body.NotBefore != "" || body.NotAfter != "" {
+		return c.acmeProblem(response, request, appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "notBefore and notAfter are not supported"))
+	}
+
+	dnsNames := make([]string, len(body.Identifiers))
+	for i, identifier := range body.Identifiers {
+		if identifier.Type != appdtos.AcmeDnsIdentifier {
+			return c.acmeProblem(response, request, appmodels.NewAcmeError(appmodels.ACME_ERROR_UNSUPPORTED_IDENTIFIER, "unsupported identifier type: %s", identifier.Type))
+		}
+		dnsNames[i] = identifier.Value
+	}
+
+	order, err := c.acmeController.NewOrder(account, dnsNames)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+	c.logf(request, "created ACME order: %s", order.ID())
+
+	response.SetHeader("Location", c.acmeOrderURL(request, order))
+	response.Send(http.StatusCreated, c.acmeOrderDTO(request, order))
+	return nil
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).AcmeNewOrderDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).AcmeNewOrder
@@ -0,0 +1,84 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// AcmeFinalizeOrderDefinitions returns OpenAPI definitions
+func (c *HttpApiController) AcmeFinalizeOrderDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Issues the certificate of a ready ACME order",
+		Description: "The payload is an AcmeFinalizeRequestDTO with the certificate signing request.",
+		RequestBody: &swagger.ContentValue{
+			Description: "JWS request",
+			Content: swagger.Content{
+				AcmeJoseContentType: {
+					Value: appdtos.AcmeJwsDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.AcmeOrderDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// AcmeFinalizeOrder handles a request
+func (c *HttpApiController) AcmeFinalizeOrder(response apitypes.Response, request apitypes.Request) error {
+
+	if c.acmeController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.acmeIssuer(request)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	c.acmeHeaders(response, request)
+
+	payload, account, err := c.acmeVerifyKid(request, issuer)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	var body appdtos.AcmeFinalizeRequestDTO
+	if err := c.acmeDecodePayload(payload, &body); err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+
+	der, err := base64.RawURLEncoding.DecodeString(body.CSR)
+	if err != nil {
+		return c.acmeProblem(response, request, appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_CSR, "failed to decode csr: %v", err))
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return c.acmeProblem(response, request, appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_CSR, "failed to parse csr: %v", err))
+	}
+
+	order, err := c.acmeController.FinalizeOrder(issuer, account, request.Variable("orderId"), csr)
+	if err != nil {
+		return c.acmeProblem(response, request, err)
+	}
+	c.logf(request, "issued ACME certificate: %s", order.CertificateSerialNumber())
+
+	response.SetHeader("Location", c.acmeOrderURL(request, order))
+	return c.ok(response, c.acmeOrderDTO(request, order))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).AcmeFinalizeOrderDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).AcmeFinalizeOrder
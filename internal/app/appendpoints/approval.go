@@ -0,0 +1,92 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"errors"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// ApprovalTokenHeader is the header of approver tokens
+const ApprovalTokenHeader = "X-Approval-Token"
+
+// approvalError sends an error response of the approval end-points
+func (c *HttpApiController) approvalError(response apitypes.Response, request apitypes.Request, err error) error {
+	switch {
+	case isApprovalDenied(err):
+		return c.forbidden(response, request, err)
+	case errors.Is(err, appmodels.ErrOperationNotFound):
+		return c.notFound(response, request, err)
+	case errors.Is(err, appmodels.ErrOperationNotPending):
+		return c.conflict(response, request, err, "operation is not pending")
+	case errors.Is(err, appmodels.ErrOperationAlreadyDecided):
+		return c.conflict(response, request, err, "approver has decided already")
+	default:
+		return c.internalServerError(response, request, err)
+	}
+}
+
+// decideOperation authenticates the approver and records the decision on the
+// operation of the path
+func (c *HttpApiController) decideOperation(response apitypes.Response, request apitypes.Request, approved bool) error {
+
+	if c.approvalController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	approver, err := c.approvalController.Authenticate(request.Header(ApprovalTokenHeader))
+	if err != nil {
+		return c.approvalError(response, request, err)
+	}
+
+	var operation appmodels.PendingOperation
+	if approved {
+		operation, err = c.approvalController.Approve(request.Variable("operation"), approver)
+	} else {
+		operation, err = c.approvalController.Reject(request.Variable("operation"), approver)
+	}
+	if err != nil {
+		return c.approvalError(response, request, err)
+	}
+	return c.ok(response, c.pendingOperationDTO(operation))
+}
+
+// requester authenticates the approver token of a request. Requests without
+// the token have no requester, and their sensitive operations are denied.
+func (c *HttpApiController) requester(request apitypes.Request) (string, error) {
+	token := request.Header(ApprovalTokenHeader)
+	if c.approvalController == nil || token == "" {
+		return "", nil
+	}
+	return c.approvalController.Authenticate(token)
+}
+
+// applicationController returns the application controller which requests
+// sensitive operations on behalf of the approver of the request
+func (c *HttpApiController) applicationController(request apitypes.Request) (appmodels.ApplicationController, error) {
+	controller, ok := c.appController.(appmodels.RequesterApplicationController)
+	if !ok {
+		return c.appController, nil
+	}
+	requester, err := c.requester(request)
+	if err != nil {
+		return nil, err
+	}
+	return controller.WithRequester(requester), nil
+}
+
+// requestBackupController returns the backup controller which requests
+// sensitive operations on behalf of the approver of the request
+func (c *HttpApiController) requestBackupController(request apitypes.Request) (appmodels.BackupController, error) {
+	controller, ok := c.backupController.(appmodels.RequesterBackupController)
+	if !ok {
+		return c.backupController, nil
+	}
+	requester, err := c.requester(request)
+	if err != nil {
+		return nil, err
+	}
+	return controller.WithRequester(requester), nil
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestApprovals(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := memoryrepository.NewCollection()
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+	approvalController, err := appcontrollers.NewApprovalController(
+		memoryrepository.NewPendingOperationRepository(),
+		map[string]string{"alice-token": "alice", "bob-token": "bob", "carol-token": "carol"},
+		2,
+		time.Hour,
+		randomManager,
+	)
+	require.NoError(t, err)
+
+	organization, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	_, err = appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.KEY_RETENTION_RETAIN))
+	require.NoError(t, err)
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appcontrollers.NewApprovalApplicationController(appController, approvalController), certManager)
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	send := func(method, path, token string, body any) *http.Response {
+		t.Helper()
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, err := http.NewRequest(method, httpServer.URL+path, bytes.NewReader(data))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set(appendpoints.ApprovalTokenHeader, token)
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+	decodeOperation := func(res *http.Response) appdtos.PendingOperationDTO {
+		t.Helper()
+		var dto appdtos.PendingOperationDTO
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&dto))
+		return dto
+	}
+	decide := func(id, token, decision string) *http.Response {
+		t.Helper()
+		return send(http.MethodPost, "/approvals/"+id+"/"+decision, token, nil)
+	}
+
+	// Approvals are disabled without the controller
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/approvals", "alice-token", nil).StatusCode)
+	controller.SetApprovalController(approvalController)
+	assert.Equal(t, http.StatusForbidden, send(http.MethodGet, "/approvals", "", nil).StatusCode)
+	assert.Equal(t, http.StatusForbidden, send(http.MethodGet, "/approvals", "wrong", nil).StatusCode)
+
+	// Sensitive operations are requested by approvers
+	rootPath := "/organizations/" + organization.String() + "/certificates"
+	rootRequest := appdtos.CertificateRequestDTO{CertificateType: appdtos.RootCertificate, CommonName: "Test Root"}
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPost, rootPath, "", rootRequest).StatusCode)
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPost, rootPath, "wrong", rootRequest).StatusCode)
+
+	// Creating a root certificate requires two approvals besides the
+	// requester
+	res := send(http.MethodPost, rootPath, "carol-token", rootRequest)
+	require.Equal(t, http.StatusAccepted, res.StatusCode)
+	pending := decodeOperation(res)
+	assert.Equal(t, "create-root-certificate", pending.Type)
+	assert.Equal(t, "carol", pending.RequestedBy)
+	assert.Equal(t, "pending", pending.Status)
+	assert.Equal(t, 2, pending.Threshold)
+
+	assert.Equal(t, http.StatusForbidden, decide(pending.ID, "carol-token", "approve").StatusCode)
+	require.Equal(t, http.StatusOK, decide(pending.ID, "alice-token", "approve").StatusCode)
+	assert.Equal(t, http.StatusConflict, decide(pending.ID, "alice-token", "approve").StatusCode)
+	assert.Equal(t, http.StatusForbidden, decide(pending.ID, "mallory-token", "approve").StatusCode)
+	assert.Equal(t, http.StatusNotFound, decide("unknown", "alice-token", "approve").StatusCode)
+	res = decide(pending.ID, "bob-token", "approve")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "approved", decodeOperation(res).Status)
+
+	// The approval belongs to the requester
+	assert.Equal(t, http.StatusAccepted, send(http.MethodPost, rootPath, "alice-token", rootRequest).StatusCode)
+
+	res = send(http.MethodPost, rootPath, "carol-token", rootRequest)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var root appdtos.CertificateDTO
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&root))
+
+	// A single approver cannot create an intermediate certificate
+	intermediatePath := "/organizations/" + organization.String() + "/certificates/" + root.SerialNumber + "/certificates"
+	intermediateRequest := appdtos.CertificateRequestDTO{CertificateType: appdtos.IntermediateCertificate, CommonName: "Test Intermediate"}
+	res = send(http.MethodPost, intermediatePath, "carol-token", intermediateRequest)
+	require.Equal(t, http.StatusAccepted, res.StatusCode)
+	pending = decodeOperation(res)
+	assert.Equal(t, "create-intermediate-certificate", pending.Type)
+	assert.Equal(t, root.SerialNumber, pending.Certificate)
+
+	require.Equal(t, http.StatusOK, decide(pending.ID, "alice-token", "approve").StatusCode)
+	assert.Equal(t, http.StatusConflict, decide(pending.ID, "alice-token", "approve").StatusCode)
+	assert.Equal(t, http.StatusAccepted, send(http.MethodPost, intermediatePath, "carol-token", intermediateRequest).StatusCode)
+
+	res = decide(pending.ID, "bob-token", "reject")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "rejected", decodeOperation(res).Status)
+	assert.Equal(t, http.StatusConflict, decide(pending.ID, "bob-token", "approve").StatusCode)
+
+	// The audit lists who approved what
+	res = send(http.MethodGet, "/approvals/"+pending.ID, "bob-token", nil)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	audit := decodeOperation(res)
+	require.Len(t, audit.Approvals, 2)
+	assert.Equal(t, "alice", audit.Approvals[0].Approver)
+	assert.True(t, audit.Approvals[0].Approved)
+	assert.Equal(t, "bob", audit.Approvals[1].Approver)
+	assert.False(t, audit.Approvals[1].Approved)
+
+	res = send(http.MethodGet, "/approvals", "carol-token", nil)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var list appdtos.PendingOperationListDTO
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+	require.Len(t, list.Payload, 3)
+	assert.Equal(t, "executed", list.Payload[0].Status)
+	assert.NotNil(t, list.Payload[0].ExecutedAt)
+
+	certificates, err := repository.Certificate.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Len(t, certificates, 1)
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// ApproveOperationDefinitions returns OpenAPI definitions
+func (c *HttpApiController) ApproveOperationDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Approves an operation",
+		Description: "Records the approval of the approver of the " + ApprovalTokenHeader + " header. The operation is approved when enough approvers have approved it, and runs when the original request is repeated with the same parameters. Each approver decides once.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.PendingOperationDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// ApproveOperation handles a request
+func (c *HttpApiController) ApproveOperation(response apitypes.Response, request apitypes.Request) error {
+	return c.decideOperation(response, request, true)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).ApproveOperationDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).ApproveOperation
@@ -0,0 +1,47 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// GetApprovalCollectionDefinitions returns OpenAPI definitions
+func (c *HttpApiController) GetApprovalCollectionDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns the operations which require approvals",
+		Description: "Lists pending operations and, for auditing, the approved, executed, rejected and expired operations with the decisions of the approvers. The " + ApprovalTokenHeader + " header must contain an approver token.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.PendingOperationListDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// GetApprovalCollection handles a request
+func (c *HttpApiController) GetApprovalCollection(response apitypes.Response, request apitypes.Request) error {
+
+	if c.approvalController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	if _, err := c.approvalController.Authenticate(request.Header(ApprovalTokenHeader)); err != nil {
+		return c.approvalError(response, request, err)
+	}
+
+	list, err := c.approvalController.Operations()
+	if err != nil {
+		return c.approvalError(response, request, err)
+	}
+	return c.ok(response, apputils.ToPendingOperationListDTO(list, c.approvalController.Threshold()))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).GetApprovalCollectionDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).GetApprovalCollection
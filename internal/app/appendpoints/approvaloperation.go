@@ -0,0 +1,53 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// pendingOperationDTO converts an operation to a DTO
+func (c *HttpApiController) pendingOperationDTO(operation appmodels.PendingOperation) appdtos.PendingOperationDTO {
+	return apputils.ToPendingOperationDTO(operation, c.approvalController.Threshold())
+}
+
+// GetApprovalOperationDefinitions returns OpenAPI definitions
+func (c *HttpApiController) GetApprovalOperationDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns an operation which requires approvals",
+		Description: "The " + ApprovalTokenHeader + " header must contain an approver token.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.PendingOperationDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// GetApprovalOperation handles a request
+func (c *HttpApiController) GetApprovalOperation(response apitypes.Response, request apitypes.Request) error {
+
+	if c.approvalController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	if _, err := c.approvalController.Authenticate(request.Header(ApprovalTokenHeader)); err != nil {
+		return c.approvalError(response, request, err)
+	}
+
+	operation, err := c.approvalController.Operation(request.Variable("operation"))
+	if err != nil {
+		return c.approvalError(response, request, err)
+	}
+	return c.ok(response, c.pendingOperationDTO(operation))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).GetApprovalOperationDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).GetApprovalOperation
@@ -0,0 +1,33 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// RejectOperationDefinitions returns OpenAPI definitions
+func (c *HttpApiController) RejectOperationDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Rejects an operation",
+		Description: "Records the rejection of the approver of the " + ApprovalTokenHeader + " header. One rejection rejects the operation.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.PendingOperationDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// RejectOperation handles a request
+func (c *HttpApiController) RejectOperation(response apitypes.Response, request apitypes.Request) error {
+	return c.decideOperation(response, request, false)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).RejectOperationDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).RejectOperation
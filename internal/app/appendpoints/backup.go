@@ -0,0 +1,59 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"errors"
+	"log"
+	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// BackupTokenHeader is the header of backup client tokens
+const BackupTokenHeader = "X-Backup-Token"
+
+// BackupPassphraseHeader is the header of the passphrase which encrypts
+// and decrypts backup archives
+const BackupPassphraseHeader = "X-Backup-Passphrase"
+
+// BackupContentType is the content type of backup archives
+const BackupContentType = "application/x-tar"
+
+// backupError sends a backup error response. Errors which are not
+// *appmodels.BackupError, and internal errors, are logged and reported
+// without details.
+func (c *HttpApiController) backupError(response apitypes.Response, request apitypes.Request, err error) error {
+	var approvalErr *appmodels.ApprovalRequiredError
+	if errors.As(err, &approvalErr) {
+		return c.approvalRequired(response, request, approvalErr)
+	}
+	if isApprovalDenied(err) {
+		return c.forbidden(response, request, err)
+	}
+	var backupErr *appmodels.BackupError
+	if !errors.As(err, &backupErr) || backupErr.Type() == appmodels.BACKUP_ERROR_SERVER_INTERNAL {
+		log.Printf("[%s %s]: Internal Server Error: %v", request.Method(), request.URL(), err)
+		backupErr = appmodels.NewBackupError(appmodels.BACKUP_ERROR_SERVER_INTERNAL, "internal server error")
+	} else {
+		c.logf(request, "backup error: %v", backupErr)
+	}
+	response.SendError(apputils.BackupErrorStatusCode(backupErr.Type()), backupErr.Detail())
+	return nil
+}
+
+// backupOrganization authenticates the request with the backup token and
+// parses the organization of the path. The organization does not need to
+// exist, since it may be restored.
+func (c *HttpApiController) backupOrganization(request apitypes.Request) (*big.Int, error) {
+	if err := c.backupController.Authenticate(request.Header(BackupTokenHeader)); err != nil {
+		return nil, err
+	}
+	organization, err := apputils.ParseBigInt(request.Variable("organization"), 10)
+	if err != nil {
+		return nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_BAD_REQUEST, "invalid organization: %s", request.Variable("organization"))
+	}
+	return organization, nil
+}
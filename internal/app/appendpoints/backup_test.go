@@ -0,0 +1,152 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// backupServer is a test server with the backup end-points enabled
+type backupServer struct {
+	t             *testing.T
+	server        *httptest.Server
+	appController *appcontrollers.CertApplicationController
+}
+
+// newBackupServer creates the server. The trust anchors are trusted to sign
+// restored backups in addition to the stored root certificates.
+func newBackupServer(t *testing.T, trustAnchors ...*x509.Certificate) *backupServer {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := filerepository.NewCollection(certManager, managers.NewFileManager(), t.TempDir())
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	controller.SetBackupController(appcontrollers.NewBackupController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		nil,
+		nil,
+		certManager,
+		trustAnchors,
+		"token",
+	))
+
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return &backupServer{t: t, server: server, appController: appController}
+}
+
+func (s *backupServer) do(path, token, passphrase string, body []byte) (int, []byte) {
+	request, err := http.NewRequest(http.MethodPost, s.server.URL+path, bytes.NewReader(body))
+	require.NoError(s.t, err)
+	request.Header.Set(appendpoints.BackupTokenHeader, token)
+	request.Header.Set(appendpoints.BackupPassphraseHeader, passphrase)
+	res, err := http.DefaultClient.Do(request)
+	require.NoError(s.t, err)
+	defer func() { _ = res.Body.Close() }()
+	data, err := io.ReadAll(res.Body)
+	require.NoError(s.t, err)
+	return res.StatusCode, data
+}
+
+// TestBackup_Client exports an organization from one server and restores it
+// to another
+func TestBackup_Client(t *testing.T) {
+
+	source := newBackupServer(t)
+	organization := appmodels.NewSerialNumber(10)
+	_, err := source.appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := source.appController.OrganizationController(organization)
+	require.NoError(t, err)
+	root, err := organizationController.NewRootCertificate("Test Root CA")
+	require.NoError(t, err)
+
+	backupPath := fmt.Sprintf("/organizations/%s/backup", organization)
+	restorePath := fmt.Sprintf("/organizations/%s/restore", organization)
+
+	status, _ := source.do(backupPath, "wrong", "passphrase", nil)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, _ = source.do(backupPath, "token", "", nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, archive := source.do(backupPath, "token", "passphrase", nil)
+	require.Equal(t, http.StatusOK, status, string(archive))
+
+	untrusted := newBackupServer(t)
+	status, _ = untrusted.do(restorePath, "token", "passphrase", archive)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	target := newBackupServer(t, root.Certificate())
+
+	status, _ = target.do(restorePath, "token", "wrong", archive)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = target.do(fmt.Sprintf("/organizations/%s/restore", appmodels.NewSerialNumber(11)), "token", "passphrase", archive)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// A dry run verifies the archive without restoring it
+	status, content := target.do(restorePath+"?dryRun=true", "token", "passphrase", archive)
+	require.Equal(t, http.StatusOK, status, string(content))
+	_, err = target.appController.OrganizationController(organization)
+	assert.Error(t, err)
+
+	status, content = target.do(restorePath, "token", "passphrase", archive)
+	require.Equal(t, http.StatusOK, status, string(content))
+	var dto appdtos.OrganizationBackupDTO
+	require.NoError(t, json.Unmarshal(content, &dto))
+	assert.Equal(t, organization.String(), dto.Organization.ID)
+	assert.Equal(t, 1, dto.Certificates)
+	assert.Equal(t, 1, dto.PrivateKeys)
+
+	restoredController, err := target.appController.OrganizationController(organization)
+	require.NoError(t, err)
+	restored, err := restoredController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	assert.Equal(t, root.Certificate().Raw, restored.Certificate().Certificate().Raw)
+
+	// The source has a newer root certificate than the backup
+	_, err = organizationController.NewRootCertificate("Newer Root CA")
+	require.NoError(t, err)
+	status, _ = source.do(restorePath, "token", "passphrase", archive)
+	assert.Equal(t, http.StatusConflict, status)
+	status, content = source.do(restorePath+"?force=true", "token", "passphrase", archive)
+	assert.Equal(t, http.StatusOK, status, string(content))
+}
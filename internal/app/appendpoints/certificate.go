@@ -3,6 +3,8 @@
 package appendpoints
 
 import (
+	"time"
+
 	swagger "github.com/davidebianchi/gswagger"
 
 	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
@@ -37,6 +39,9 @@ func (c *HttpApiController) Certificate(response apitypes.Response, request apit
 
 	model := controller.Certificate()
 	dto := apputils.ToCertificateDTO(model)
+	if err := c.setCertificateState(&dto, model, time.Now()); err != nil {
+		return c.internalServerError(response, request, err)
+	}
 	return c.ok(response, dto)
 }
 
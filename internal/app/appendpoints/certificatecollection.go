@@ -3,6 +3,8 @@
 package appendpoints
 
 import (
+	"time"
+
 	swagger "github.com/davidebianchi/gswagger"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
@@ -13,8 +15,8 @@ import (
 // CertificateCollectionDefinitions returns OpenAPI definitions
 func (c *HttpApiController) CertificateCollectionDefinitions() swagger.Definitions {
 	return swagger.Definitions{
-		Summary:     "Returns a collection of root certificate entities",
-		Description: "",
+		Summary:     "Returns a collection of certificates signed by a certificate",
+		Description: "Supports the type, status, expiringBefore, name, archived, sort, order, limit and cursor query parameters",
 		Responses: map[int]swagger.ContentValue{
 			200: {
 				Content: swagger.Content{
@@ -28,10 +30,9 @@ func (c *HttpApiController) CertificateCollectionDefinitions() swagger.Definitio
 // CertificateCollection handles a request to get organization's certificates
 func (c *HttpApiController) CertificateCollection(response apitypes.Response, request apitypes.Request) error {
 
-	// certificateType is server, client, root or intermediate
-	certificateType := request.QueryParam("type")
-	if !(certificateType == "" || certificateType == "server" || certificateType == "client" || certificateType == "root") {
-		return c.badRequest(response, request, "query param invalid: type", nil)
+	query, param, err := parseCertificateQuery(request, time.Now())
+	if err != nil {
+		return c.badRequest(response, request, "query param invalid: "+param, err)
 	}
 
 	// Fetch root certificate controller
@@ -40,14 +41,17 @@ func (c *HttpApiController) CertificateCollection(response apitypes.Response, re
 		return c.notFound(response, request, err)
 	}
 
-	// Get certificate list
-	list, err := controller.ChildCertificateCollection(certificateType)
+	// Get certificate page
+	page, err := controller.ChildCertificatePage(query)
 	if err != nil {
 		return c.internalServerError(response, request, err)
 	}
 
-	c.logf(request, "list len = %d", len(list))
-	dto := apputils.ToCertificateListDTO(list)
+	c.logf(request, "list len = %d", len(page.Certificates))
+	dto := apputils.ToCertificatePageDTO(page)
+	if err := c.setCertificateStates(dto.Payload, page.Certificates, query.Now); err != nil {
+		return c.internalServerError(response, request, err)
+	}
 	return c.ok(response, dto)
 }
 
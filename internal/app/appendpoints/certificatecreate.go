@@ -62,12 +62,30 @@ func (c *HttpApiController) CreateCertificate(response apitypes.Response, reques
 		return c.badRequest(response, request, "body type invalid", err)
 	}
 
+	// Parse key type and signature algorithm
+	keyType, err := apputils.ParseKeyType(body.KeyType)
+	if err != nil {
+		return c.badRequest(response, request, "body keyType invalid", err)
+	}
+	signatureAlgorithm, err := apputils.ParseSignatureAlgorithm(body.SignatureAlgorithm)
+	if err != nil {
+		return c.badRequest(response, request, "body signatureAlgorithm invalid", err)
+	}
+
 	// Fetch root certificate controller
 	rootCertificateController, err := c.rootCertificateController(request)
 	if rootCertificateController == nil {
 		return c.notFound(response, request, err)
 	}
 
+	if keyType != appmodels.NIL_KEY_TYPE {
+		rootCertificateController.SetKeyType(keyType)
+	}
+
+	if signatureAlgorithm != appmodels.NIL_SIGNATURE_ALGORITHM {
+		rootCertificateController.SetSignatureAlgorithm(signatureAlgorithm)
+	}
+
 	var cert appmodels.Certificate
 	var privateKey appmodels.PrivateKey
 
@@ -87,6 +105,22 @@ func (c *HttpApiController) CreateCertificate(response apitypes.Response, reques
 		}
 		c.logf(request, "created server certificate: %s", cert.SerialNumber())
 
+	} else if certificateType == appdtos.SpiffeCertificate {
+
+		spiffeID, err := apputils.ParseSpiffeID(body.SpiffeID)
+		if err != nil {
+			return c.badRequest(response, request, "body spiffeId invalid", err)
+		}
+		if spiffeID.Host != rootCertificateController.Organization().SpiffeTrustDomain() {
+			return c.badRequest(response, request, "body spiffeId not in the trust domain of the organization", nil)
+		}
+
+		cert, privateKey, err = rootCertificateController.NewSpiffeCertificate(body.SpiffeID)
+		if err != nil {
+			return c.internalServerError(response, request, err)
+		}
+		c.logf(request, "created SPIFFE certificate: %s", cert.SerialNumber())
+
 	} else if certificateType == appdtos.IntermediateCertificate {
 
 		cert, privateKey, err = rootCertificateController.NewIntermediateCertificate(commonName)
@@ -3,6 +3,8 @@
 package appendpoints
 
 import (
+	"net"
+
 	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
 	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
 	"github.com/hyperifyio/gocertcenter/internal/common/managers"
@@ -13,6 +15,44 @@ type HttpApiController struct {
 	server        apitypes.Server
 	appController appmodels.ApplicationController
 	certManager   managers.CertificateManager
+
+	// acmeController is optional. ACME end-points respond 404 without it.
+	acmeController appmodels.AcmeController
+
+	// estController is optional. EST end-points respond 404 without it.
+	estController appmodels.EstController
+
+	// scepController is optional. SCEP end-points respond 404 without it.
+	scepController appmodels.ScepController
+
+	// sshController is optional. SSH CA end-points respond 404 without it.
+	sshController appmodels.SshController
+
+	// vaultController is optional. Vault PKI end-points respond 404 without
+	// it.
+	vaultController appmodels.VaultController
+
+	// backupController is optional. Backup end-points respond 404 without
+	// it.
+	backupController appmodels.BackupController
+
+	// lifecycleController is optional. Certificates are returned without a
+	// lifecycle state, and the archive end-point responds 404, without it.
+	lifecycleController appmodels.LifecycleController
+
+	// sealController is optional. Seal end-points respond 404 without it.
+	sealController appmodels.SealController
+
+	// approvalController is optional. Approval end-points respond 404
+	// without it.
+	approvalController appmodels.ApprovalController
+
+	// readOnly makes end-points which change stored data respond 503
+	readOnly bool
+
+	// trustedProxies are the networks of reverse proxies whose
+	// X-Forwarded-Proto header is trusted
+	trustedProxies []*net.IPNet
 }
 
 func NewHttpApiController(
@@ -27,6 +67,66 @@ func NewHttpApiController(
 	}
 }
 
+// SetAcmeController enables the ACME end-points
+func (c *HttpApiController) SetAcmeController(acmeController appmodels.AcmeController) {
+	c.acmeController = acmeController
+}
+
+// SetEstController enables the EST end-points
+func (c *HttpApiController) SetEstController(estController appmodels.EstController) {
+	c.estController = estController
+}
+
+// SetScepController enables the SCEP end-points
+func (c *HttpApiController) SetScepController(scepController appmodels.ScepController) {
+	c.scepController = scepController
+}
+
+// SetSshController enables the SSH certificate authority end-points
+func (c *HttpApiController) SetSshController(sshController appmodels.SshController) {
+	c.sshController = sshController
+}
+
+// SetVaultController enables the HashiCorp Vault PKI compatible end-points
+func (c *HttpApiController) SetVaultController(vaultController appmodels.VaultController) {
+	c.vaultController = vaultController
+}
+
+// SetBackupController enables the organization backup and restore
+// end-points
+func (c *HttpApiController) SetBackupController(backupController appmodels.BackupController) {
+	c.backupController = backupController
+}
+
+// SetLifecycleController enables the certificate lifecycle states and the
+// archive end-point
+func (c *HttpApiController) SetLifecycleController(lifecycleController appmodels.LifecycleController) {
+	c.lifecycleController = lifecycleController
+}
+
+// SetSealController enables the seal and unseal end-points
+func (c *HttpApiController) SetSealController(sealController appmodels.SealController) {
+	c.sealController = sealController
+}
+
+// SetApprovalController enables the approval end-points. The application
+// and backup controllers must be wrapped separately to require approvals.
+func (c *HttpApiController) SetApprovalController(approvalController appmodels.ApprovalController) {
+	c.approvalController = approvalController
+}
+
+// SetReadOnly enables or disables the read-only mode, in which end-points
+// which change stored data respond 503 Service Unavailable
+func (c *HttpApiController) SetReadOnly(readOnly bool) {
+	c.readOnly = readOnly
+}
+
+// SetTrustedProxies changes the networks of reverse proxies whose
+// X-Forwarded-Proto header is trusted. The header is ignored by default.
+func (c *HttpApiController) SetTrustedProxies(trustedProxies []*net.IPNet) {
+	c.trustedProxies = trustedProxies
+}
+
 // Note! Other methods are defined in adjacent files.
 
 var _ apitypes.AppController = (*HttpApiController)(nil)
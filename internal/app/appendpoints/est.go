@@ -0,0 +1,147 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"crypto/x509"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// EstCertsContentType is the content type of EST certificate responses
+const EstCertsContentType = "application/pkcs7-mime; smime-type=certs-only"
+
+// EstCsrContentType is the content type of EST enrollment requests
+const EstCsrContentType = "application/pkcs10"
+
+// EstCsrAttrsContentType is the content type of EST CSR attributes responses
+const EstCsrAttrsContentType = "application/csrattrs"
+
+// EstRealm is the HTTP basic authentication realm of EST servers
+const EstRealm = "estrealm"
+
+// estIssuer returns the label of the request and its issuing certificate
+func (c *HttpApiController) estIssuer(request apitypes.Request) (appmodels.EstLabel, appmodels.CertificateController, error) {
+	label, err := c.estController.Label(request.Variable("label"))
+	if err != nil {
+		return nil, nil, err
+	}
+	issuer, err := c.estController.Issuer(label)
+	if err != nil {
+		return nil, nil, err
+	}
+	return label, issuer, nil
+}
+
+// estAuthenticate authenticates the client with HTTP basic authentication,
+// or on re-enrollment also with a TLS client certificate issued by the CA.
+// It returns the client certificate, or nil if basic authentication was used.
+func (c *HttpApiController) estAuthenticate(request apitypes.Request, label appmodels.EstLabel, issuer appmodels.CertificateController, reenroll bool) (*x509.Certificate, error) {
+
+	// A certificate of the CA only proves the names it was issued for, so it
+	// cannot authorize enrolling new names
+	if certificates := request.PeerCertificates(); reenroll && len(certificates) != 0 {
+		err := c.estController.VerifyClientCertificate(issuer, certificates[0])
+		if err == nil {
+			return certificates[0], nil
+		}
+		c.logf(request, "client certificate not accepted: %v", err)
+	}
+
+	username, password, ok := request.BasicAuth()
+	if !ok {
+		return nil, appmodels.NewEstError(appmodels.EST_ERROR_UNAUTHORIZED, "authentication required")
+	}
+
+	if err := c.estController.Authenticate(label, username, password); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// estError sends an EST error response. Errors which are not
+// *appmodels.EstError are logged and reported as internal errors, and
+// sealed private keys as 503 Service Unavailable.
+func (c *HttpApiController) estError(response apitypes.Response, request apitypes.Request, err error) error {
+	if errors.Is(err, appmodels.ErrSealed) {
+		return c.sealed(response, request, err)
+	}
+	var estErr *appmodels.EstError
+	if !errors.As(err, &estErr) {
+		log.Printf("[%s %s]: Internal Server Error: %v", request.Method(), request.URL(), err)
+		estErr = appmodels.NewEstError(appmodels.EST_ERROR_SERVER_INTERNAL, "internal server error")
+	} else {
+		c.logf(request, "EST error: %v", estErr)
+	}
+	statusCode := apputils.EstErrorStatusCode(estErr.Type())
+	if statusCode == http.StatusUnauthorized {
+		response.SetHeader("WWW-Authenticate", "Basic realm=\""+EstRealm+"\"")
+	}
+	response.SendError(statusCode, estErr.Detail())
+	return nil
+}
+
+// estSendBase64 sends a base64 encoded EST response
+func (c *HttpApiController) estSendBase64(response apitypes.Response, contentType string, der []byte) error {
+	response.SetHeader("Content-Type", contentType)
+	response.SetHeader("Content-Transfer-Encoding", "base64")
+	return response.SendBytes(apputils.EncodeEstBase64(der))
+}
+
+// estSendCertificates sends certificates as a PKCS #7 certs-only response
+func (c *HttpApiController) estSendCertificates(response apitypes.Response, request apitypes.Request, certificates []appmodels.Certificate) error {
+	list := make([]*x509.Certificate, len(certificates))
+	for i, certificate := range certificates {
+		list[i] = certificate.Certificate()
+	}
+	der, err := apputils.EncodePkcs7Certificates(list)
+	if err != nil {
+		return c.estError(response, request, err)
+	}
+	return c.estSendBase64(response, EstCertsContentType, der)
+}
+
+// estEnroll handles the simpleenroll and simplereenroll operations
+func (c *HttpApiController) estEnroll(response apitypes.Response, request apitypes.Request, reenroll bool) error {
+
+	if c.estController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	label, issuer, err := c.estIssuer(request)
+	if err != nil {
+		return c.estError(response, request, err)
+	}
+
+	current, err := c.estAuthenticate(request, label, issuer, reenroll)
+	if err != nil {
+		return c.estError(response, request, err)
+	}
+
+	body, err := request.BodyBytes()
+	if err != nil {
+		return c.estError(response, request, appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "failed to read body"))
+	}
+
+	csr, err := apputils.ParseEstCsr(body)
+	if err != nil {
+		return c.estError(response, request, appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "invalid csr: %v", err))
+	}
+
+	var certificate appmodels.Certificate
+	if reenroll {
+		certificate, err = c.estController.Reenroll(issuer, current, csr)
+	} else {
+		certificate, err = c.estController.Enroll(issuer, csr)
+	}
+	if err != nil {
+		return c.estError(response, request, err)
+	}
+
+	return c.estSendCertificates(response, request, []appmodels.Certificate{certificate})
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestEstRequest(t *testing.T, method, url string, body []byte) *http.Request {
+	request, err := http.NewRequest(method, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	if body != nil {
+		request.Header.Set("Content-Type", appendpoints.EstCsrContentType)
+	}
+	return request
+}
+
+func readTestEstCertificates(t *testing.T, res *http.Response) []*x509.Certificate {
+	defer func() { _ = res.Body.Close() }()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, appendpoints.EstCertsContentType, res.Header.Get("Content-Type"))
+	assert.Equal(t, "base64", res.Header.Get("Content-Transfer-Encoding"))
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	der, err := apputils.DecodeEstBase64(body)
+	require.NoError(t, err)
+	certificates, err := apputils.ParsePkcs7Certificates(der)
+	require.NoError(t, err)
+	return certificates
+}
+
+func newTestEstCsr(t *testing.T, key *ecdsa.PrivateKey, commonName string) []byte {
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: commonName}}, key)
+	require.NoError(t, err)
+	return apputils.EncodeEstBase64(der)
+}
+
+// TestEst_Client runs the EST enrollment and re-enrollment flow over TLS
+func TestEst_Client(t *testing.T) {
+
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := filerepository.NewCollection(certManager, managers.NewFileManager(), t.TempDir())
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+	appController.SetRevocationRepository(repository.CertificateRevocation)
+
+	organization := appmodels.NewSerialNumber(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	organizationController.SetExpirationDuration(24 * time.Hour)
+	root, err := organizationController.NewRootCertificate("Test Root CA")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+
+	estController := appcontrollers.NewEstController(memoryrepository.NewEstLabelRepository(), repository.CertificateRevocation, appController, time.Hour)
+	_, err = estController.NewLabel(rootController, "devices", "user", "secret")
+	require.NoError(t, err)
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	controller.SetEstController(estController)
+
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	server := httptest.NewUnstartedServer(router)
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+	baseURL := server.URL + "/.well-known/est/devices"
+	client := server.Client()
+
+	res, err := client.Get(baseURL + "/cacerts")
+	require.NoError(t, err)
+	caCertificates := readTestEstCertificates(t, res)
+	require.Len(t, caCertificates, 1)
+	assert.Equal(t, root.Certificate().Raw, caCertificates[0].Raw)
+
+	res, err = client.Get(baseURL + "/csrattrs")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	der, err := apputils.DecodeEstBase64(body)
+	require.NoError(t, err)
+	var oids []asn1.ObjectIdentifier
+	_, err = asn1.Unmarshal(der, &oids)
+	require.NoError(t, err)
+	assert.Len(t, oids, 1)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	res, err = client.Do(newTestEstRequest(t, http.MethodPost, baseURL+"/simpleenroll", newTestEstCsr(t, key, "device-1")))
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Contains(t, res.Header.Get("WWW-Authenticate"), "Basic")
+
+	request := newTestEstRequest(t, http.MethodPost, baseURL+"/simpleenroll", newTestEstCsr(t, key, "device-1"))
+	request.SetBasicAuth("user", "wrong")
+	res, err = client.Do(request)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	request = newTestEstRequest(t, http.MethodPost, baseURL+"/simpleenroll", newTestEstCsr(t, key, "device-1"))
+	request.SetBasicAuth("user", "secret")
+	res, err = client.Do(request)
+	require.NoError(t, err)
+	issued := readTestEstCertificates(t, res)
+	require.Len(t, issued, 1)
+	assert.Equal(t, "device-1", issued[0].Subject.CommonName)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(root.Certificate())
+	_, err = issued[0].Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	require.NoError(t, err)
+
+	// Re-enroll using the issued certificate as the TLS client certificate
+	transport := client.Transport.(*http.Transport).Clone()
+	transport.TLSClientConfig.Certificates = []tls.Certificate{
+		{Certificate: [][]byte{issued[0].Raw}, PrivateKey: key},
+	}
+	tlsClient := &http.Client{Transport: transport}
+
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	res, err = tlsClient.Do(newTestEstRequest(t, http.MethodPost, baseURL+"/simplereenroll", newTestEstCsr(t, newKey, "device-1")))
+	require.NoError(t, err)
+	renewed := readTestEstCertificates(t, res)
+	require.Len(t, renewed, 1)
+	assert.Equal(t, "device-1", renewed[0].Subject.CommonName)
+	assert.NotEqual(t, issued[0].SerialNumber, renewed[0].SerialNumber)
+	assert.True(t, renewed[0].PublicKey.(*ecdsa.PublicKey).Equal(&newKey.PublicKey))
+
+	res, err = tlsClient.Do(newTestEstRequest(t, http.MethodPost, baseURL+"/simplereenroll", newTestEstCsr(t, newKey, "device-2")))
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	// A client certificate does not authorize new enrollments
+	res, err = tlsClient.Do(newTestEstRequest(t, http.MethodPost, baseURL+"/simpleenroll", newTestEstCsr(t, newKey, "device-2")))
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	// A revoked certificate cannot re-enroll
+	revoked, err := rootController.ChildCertificate(issued[0].SerialNumber)
+	require.NoError(t, err)
+	_, err = organizationController.RevokeCertificate(revoked)
+	require.NoError(t, err)
+	res, err = tlsClient.Do(newTestEstRequest(t, http.MethodPost, baseURL+"/simplereenroll", newTestEstCsr(t, newKey, "device-1")))
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	res, err = client.Get(server.URL + "/.well-known/est/missing/cacerts")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestEst_DisabledWithoutController(t *testing.T) {
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), nil, nil)
+
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/.well-known/est/devices/cacerts")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// EstCACertificatesDefinitions returns OpenAPI definitions
+func (c *HttpApiController) EstCACertificatesDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns the CA certificates of an EST label",
+		Description: "Returns the issuing certificate and its parents as a base64 encoded PKCS #7 certs-only structure (RFC 7030 section 4.1).",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					EstCertsContentType: {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// EstCACertificates handles a request
+func (c *HttpApiController) EstCACertificates(response apitypes.Response, request apitypes.Request) error {
+
+	if c.estController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	_, issuer, err := c.estIssuer(request)
+	if err != nil {
+		return c.estError(response, request, err)
+	}
+
+	return c.estSendCertificates(response, request, c.estController.CACertificates(issuer))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).EstCACertificatesDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).EstCACertificates
@@ -0,0 +1,56 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"net/http"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// EstCsrAttributesDefinitions returns OpenAPI definitions
+func (c *HttpApiController) EstCsrAttributesDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns the CSR attributes of an EST label",
+		Description: "Returns the attributes clients should use in their certificate signing requests (RFC 7030 section 4.5), or 204 when there are none.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					EstCsrAttrsContentType: {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// EstCsrAttributes handles a request
+func (c *HttpApiController) EstCsrAttributes(response apitypes.Response, request apitypes.Request) error {
+
+	if c.estController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	_, issuer, err := c.estIssuer(request)
+	if err != nil {
+		return c.estError(response, request, err)
+	}
+
+	oids := c.estController.CsrAttributes(issuer)
+	if len(oids) == 0 {
+		response.SendStatus(http.StatusNoContent)
+		return nil
+	}
+
+	der, err := apputils.EncodeEstCsrAttributes(oids)
+	if err != nil {
+		return c.estError(response, request, err)
+	}
+
+	return c.estSendBase64(response, EstCsrAttrsContentType, der)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).EstCsrAttributesDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).EstCsrAttributes
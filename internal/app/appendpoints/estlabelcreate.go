@@ -0,0 +1,76 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"encoding/json"
+	"errors"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// CreateEstLabelDefinitions returns OpenAPI definitions
+func (c *HttpApiController) CreateEstLabelDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Creates an EST label for an issuing certificate",
+		Description: "The label is served at /.well-known/est/{label}. When a username is given, EST clients may authenticate using HTTP basic authentication.",
+		RequestBody: &swagger.ContentValue{
+			Description: "EST label request data",
+			Content: swagger.Content{
+				"application/json": {
+					Value: appdtos.EstLabelRequestDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.EstLabelDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// CreateEstLabel handles a request
+func (c *HttpApiController) CreateEstLabel(response apitypes.Response, request apitypes.Request) error {
+
+	if c.estController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	var body appdtos.EstLabelRequestDTO
+	if err := json.NewDecoder(request.Body()).Decode(&body); err != nil {
+		return c.badRequest(response, request, "body invalid", err)
+	}
+
+	var issuer appmodels.CertificateController
+	var err error
+	if request.Variable("serialNumber") != "" {
+		issuer, err = c.innerCertificateController(request)
+	} else {
+		issuer, err = c.rootCertificateController(request)
+	}
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	label, err := c.estController.NewLabel(issuer, body.Label, body.Username, body.Password)
+	if err != nil {
+		var estErr *appmodels.EstError
+		if errors.As(err, &estErr) && estErr.Type() == appmodels.EST_ERROR_BAD_REQUEST {
+			return c.badRequest(response, request, estErr.Detail(), err)
+		}
+		return c.internalServerError(response, request, err)
+	}
+
+	return c.ok(response, apputils.ToEstLabelDTO(label))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).CreateEstLabelDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).CreateEstLabel
@@ -0,0 +1,38 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// EstSimpleEnrollDefinitions returns OpenAPI definitions
+func (c *HttpApiController) EstSimpleEnrollDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Issues a certificate using EST",
+		Description: "Issues a certificate for a base64 encoded PKCS #10 request (RFC 7030 section 4.2.1). Requests with DNS names are issued a server certificate and others a client certificate. The client must authenticate with HTTP basic authentication or with a TLS client certificate issued by the CA.",
+		RequestBody: &swagger.ContentValue{
+			Description: "Base64 encoded certificate signing request",
+			Content: swagger.Content{
+				EstCsrContentType: {Value: ""},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					EstCertsContentType: {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// EstSimpleEnroll handles a request
+func (c *HttpApiController) EstSimpleEnroll(response apitypes.Response, request apitypes.Request) error {
+	return c.estEnroll(response, request, false)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).EstSimpleEnrollDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).EstSimpleEnroll
@@ -0,0 +1,38 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// EstSimpleReenrollDefinitions returns OpenAPI definitions
+func (c *HttpApiController) EstSimpleReenrollDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Renews a certificate using EST",
+		Description: "Renews a certificate for a base64 encoded PKCS #10 request (RFC 7030 section 4.2.2). When the client authenticates with its current certificate, the subject and subject alternative names of the request must match it.",
+		RequestBody: &swagger.ContentValue{
+			Description: "Base64 encoded certificate signing request",
+			Content: swagger.Content{
+				EstCsrContentType: {Value: ""},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					EstCertsContentType: {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// EstSimpleReenroll handles a request
+func (c *HttpApiController) EstSimpleReenroll(response apitypes.Response, request apitypes.Request) error {
+	return c.estEnroll(response, request, true)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).EstSimpleReenrollDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).EstSimpleReenroll
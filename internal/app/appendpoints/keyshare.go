@@ -0,0 +1,37 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// KeyShareContentType is the content type of PEM encoded private key shares
+const KeyShareContentType = "application/x-pem-file"
+
+// keyShareCertificate authenticates the request with the backup token and
+// finds the root certificate, or the intermediate certificate when the path
+// has one
+func (c *HttpApiController) keyShareCertificate(request apitypes.Request) (*big.Int, *big.Int, error) {
+	if err := c.backupController.Authenticate(request.Header(BackupTokenHeader)); err != nil {
+		return nil, nil, err
+	}
+
+	var controller appmodels.CertificateController
+	var err error
+	if request.Variable("serialNumber") != "" {
+		controller, err = c.innerCertificateController(request)
+	} else {
+		controller, err = c.rootCertificateController(request)
+	}
+	if err != nil {
+		c.logf(request, "certificate not found: %v", err)
+		return nil, nil, appmodels.NewBackupError(appmodels.BACKUP_ERROR_NOT_FOUND, "certificate not found")
+	}
+
+	certificate := controller.Certificate()
+	return certificate.OrganizationID(), certificate.SerialNumber(), nil
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestKeyShares(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := memoryrepository.NewCollection()
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.KEY_RETENTION_RETAIN))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	intermediate, _, err := rootController.NewIntermediateCertificate("Test Intermediate")
+	require.NoError(t, err)
+	client, _, err := rootController.NewClientCertificate("client")
+	require.NoError(t, err)
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	controller.SetBackupController(appcontrollers.NewBackupController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		nil,
+		nil,
+		certManager,
+		nil,
+		"token",
+	))
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	send := func(path, token string, body []byte) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, httpServer.URL+path, bytes.NewReader(body))
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set(appendpoints.BackupTokenHeader, token)
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+	export := func(path string, shares, threshold int) *http.Response {
+		t.Helper()
+		body, err := json.Marshal(appdtos.NewKeyShareRequestDTO(shares, threshold))
+		require.NoError(t, err)
+		return send(path, "token", body)
+	}
+
+	rootPath := "/organizations/" + organization.String() + "/certificates/" + root.SerialNumber().String()
+	intermediatePath := rootPath + "/certificates/" + intermediate.SerialNumber().String()
+
+	body, err := json.Marshal(appdtos.NewKeyShareRequestDTO(3, 2))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, send(rootPath+"/key-shares", "", body).StatusCode)
+	assert.Equal(t, http.StatusForbidden, send(rootPath+"/key-shares/import", "wrong", nil).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, export(rootPath+"/key-shares", 3, 4).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, export(rootPath+"/certificates/"+client.SerialNumber().String()+"/key-shares", 3, 2).StatusCode)
+	assert.Equal(t, http.StatusNotFound, export(rootPath+"/certificates/404/key-shares", 3, 2).StatusCode)
+
+	for _, path := range []string{rootPath, intermediatePath} {
+		res := export(path+"/key-shares", 3, 2)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var dto appdtos.KeySharesDTO
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&dto))
+		require.Len(t, dto.Shares, 3)
+		assert.Equal(t, 2, dto.Threshold)
+
+		assert.Equal(t, http.StatusBadRequest, send(path+"/key-shares/import", "token", []byte(dto.Shares[0])).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, send(path+"/key-shares/import", "token", []byte("not a share")).StatusCode)
+
+		res = send(path+"/key-shares/import", "token", []byte(dto.Shares[2]+dto.Shares[0]))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var imported appdtos.KeySharesDTO
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&imported))
+		assert.Equal(t, dto.Certificate, imported.Certificate)
+		assert.Empty(t, imported.Shares)
+	}
+
+	// Shares of the root cannot be imported as the key of the intermediate
+	res := export(rootPath+"/key-shares", 2, 2)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var dto appdtos.KeySharesDTO
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&dto))
+	assert.Equal(t, http.StatusBadRequest, send(intermediatePath+"/key-shares/import", "token", []byte(strings.Join(dto.Shares, ""))).StatusCode)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// ExportKeySharesDefinitions returns OpenAPI definitions
+func (c *HttpApiController) ExportKeySharesDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Exports the private key of a CA certificate as key shares",
+		Description: "Splits the private key of a root or intermediate certificate into PEM encoded shares with Shamir's secret sharing. Any threshold of the shares reconstructs the key. The " + BackupTokenHeader + " header must contain the backup token.",
+		RequestBody: &swagger.ContentValue{
+			Content: swagger.Content{
+				"application/json": {Value: appdtos.KeyShareRequestDTO{}},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.KeySharesDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// ExportKeyShares handles a request
+func (c *HttpApiController) ExportKeyShares(response apitypes.Response, request apitypes.Request) error {
+
+	if c.backupController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, certificate, err := c.keyShareCertificate(request)
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+
+	body, err := c.DecodeKeyShareRequestFromRequestBody(request)
+	if err != nil {
+		return c.backupError(response, request, appmodels.NewBackupError(appmodels.BACKUP_ERROR_BAD_REQUEST, "invalid body: %v", err))
+	}
+
+	controller, err := c.requestBackupController(request)
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+
+	shares, err := controller.ExportKeyShares(organization, certificate, body.Shares, body.Threshold)
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+
+	dto, err := apputils.ToKeySharesDTO(c.certManager, shares)
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+	return c.ok(response, dto)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).ExportKeySharesDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).ExportKeyShares
@@ -0,0 +1,75 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// ImportKeySharesDefinitions returns OpenAPI definitions
+func (c *HttpApiController) ImportKeySharesDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Imports the private key of a CA certificate from key shares",
+		Description: "Reconstructs the private key of a root or intermediate certificate from the concatenated PEM encoded shares of the body and saves it. The " + BackupTokenHeader + " header must contain the backup token.",
+		RequestBody: &swagger.ContentValue{
+			Content: swagger.Content{
+				KeyShareContentType: {Value: ""},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.KeySharesDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// ImportKeyShares handles a request
+func (c *HttpApiController) ImportKeyShares(response apitypes.Response, request apitypes.Request) error {
+
+	if c.backupController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, certificate, err := c.keyShareCertificate(request)
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+
+	body, err := request.BodyBytes()
+	if err != nil {
+		return c.backupError(response, request, appmodels.NewBackupError(appmodels.BACKUP_ERROR_BAD_REQUEST, "failed to read the shares: %v", err))
+	}
+	shares, err := apputils.ParsePrivateKeySharesFromPEMBytes(c.certManager, body)
+	if err != nil {
+		return c.backupError(response, request, appmodels.NewBackupError(appmodels.BACKUP_ERROR_BAD_REQUEST, "invalid shares: %v", err))
+	}
+
+	controller, err := c.requestBackupController(request)
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+
+	key, err := controller.ImportKeyShares(organization, certificate, shares)
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+
+	return c.ok(response, appdtos.NewKeySharesDTO(
+		key.OrganizationID().String(),
+		key.SerialNumber().String(),
+		key.KeyType().String(),
+		0,
+		nil,
+	))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).ImportKeySharesDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).ImportKeyShares
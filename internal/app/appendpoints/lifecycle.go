@@ -0,0 +1,39 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// setCertificateStates fills in the lifecycle states of certificate DTOs.
+// The payload must be in the same order as the certificates. Nothing is
+// done without a lifecycle controller.
+func (c *HttpApiController) setCertificateStates(payload []appdtos.CertificateDTO, certificates []appmodels.Certificate, now time.Time) error {
+	if c.lifecycleController == nil || len(certificates) == 0 {
+		return nil
+	}
+	states, err := c.lifecycleController.CertificateStates(certificates[0].OrganizationID(), certificates, now)
+	if err != nil {
+		return err
+	}
+	for i, certificate := range certificates {
+		if state, ok := states[certificate.SerialNumber().String()]; ok {
+			payload[i].State = state.String()
+		}
+	}
+	return nil
+}
+
+// setCertificateState fills in the lifecycle state of a certificate DTO
+func (c *HttpApiController) setCertificateState(dto *appdtos.CertificateDTO, certificate appmodels.Certificate, now time.Time) error {
+	payload := []appdtos.CertificateDTO{*dto}
+	if err := c.setCertificateStates(payload, []appmodels.Certificate{certificate}, now); err != nil {
+		return err
+	}
+	*dto = payload[0]
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestCertificateLifecycle(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := memoryrepository.NewCollection()
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	_, _, err = rootController.NewServerCertificate("www.example.com")
+	require.NoError(t, err)
+
+	// A certificate which expired before the retention period
+	_, err = repository.Certificate.Save(appmodels.NewCertificate(organization, root.SerialNumber(), &x509.Certificate{
+		SerialNumber: big.NewInt(1234),
+		Subject:      pkix.Name{CommonName: "old.example.com"},
+		Issuer:       root.Certificate().Subject,
+		NotBefore:    time.Now().Add(-72 * time.Hour),
+		NotAfter:     time.Now().Add(-48 * time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}))
+	require.NoError(t, err)
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	organizationPath := httpServer.URL + "/organizations/" + organization.String()
+	childrenPath := organizationPath + "/certificates/" + root.SerialNumber().String() + "/certificates"
+	states := func(method, path string) (map[string]string, int) {
+		t.Helper()
+		req, err := http.NewRequest(method, path, nil)
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return nil, res.StatusCode
+		}
+		var dto appdtos.CertificateListDTO
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&dto))
+		result := map[string]string{}
+		for _, cert := range dto.Payload {
+			result[cert.CommonName] = cert.State
+		}
+		return result, res.StatusCode
+	}
+
+	// Without a lifecycle controller there are no states or archive
+	list, status := states(http.MethodGet, childrenPath)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]string{"www.example.com": "", "old.example.com": ""}, list)
+	_, status = states(http.MethodPost, organizationPath+"/archive")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	controller.SetLifecycleController(appcontrollers.NewLifecycleController(repository.Organization, repository.Certificate, nil, time.Hour))
+
+	list, status = states(http.MethodGet, childrenPath)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]string{"www.example.com": "active", "old.example.com": "expired"}, list)
+
+	list, status = states(http.MethodPost, organizationPath+"/archive")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]string{"old.example.com": "expired"}, list)
+
+	list, status = states(http.MethodGet, childrenPath)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]string{"www.example.com": "active"}, list)
+
+	list, status = states(http.MethodGet, childrenPath+"?archived=true")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]string{"old.example.com": "expired"}, list)
+
+	list, status = states(http.MethodGet, organizationPath+"/certificates")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]string{"Test Root": "active", "www.example.com": "active"}, list)
+
+	_, status = states(http.MethodGet, childrenPath+"?archived=maybe")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	res, err := http.Get(organizationPath + "/certificates/" + root.SerialNumber().String())
+	require.NoError(t, err)
+	defer res.Body.Close()
+	var dto appdtos.CertificateDTO
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&dto))
+	assert.Equal(t, "active", dto.State)
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"time"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// ArchiveOrganizationDefinitions returns OpenAPI definitions
+func (c *HttpApiController) ArchiveOrganizationDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Archives expired and superseded certificates of an organization",
+		Description: "Moves certificates which have been expired or superseded for longer than the retention period into the archive and returns them. Archived certificates are listed with the archived=true query parameter.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.CertificateListDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// ArchiveOrganization handles a request to archive organization's
+// certificates
+func (c *HttpApiController) ArchiveOrganization(response apitypes.Response, request apitypes.Request) error {
+
+	if c.lifecycleController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	controller, err := c.organizationController(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	now := time.Now()
+	list, err := c.lifecycleController.ArchiveCertificates(controller.OrganizationID(), now)
+	if err != nil {
+		return c.internalServerError(response, request, err)
+	}
+
+	c.logf(request, "archived len = %d", len(list))
+	dto := apputils.ToCertificateListDTO(list)
+	if err := c.setCertificateStates(dto.Payload, list, now); err != nil {
+		return c.internalServerError(response, request, err)
+	}
+	return c.ok(response, dto)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).ArchiveOrganizationDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).ArchiveOrganization
@@ -0,0 +1,53 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// OrganizationBackupDefinitions returns OpenAPI definitions
+func (c *HttpApiController) OrganizationBackupDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Exports a backup of an organization",
+		Description: "Returns a tar archive of the organization, its certificates, private keys and revocations. The archive is encrypted with the passphrase of the " + BackupPassphraseHeader + " header and signed by the newest root certificate of the organization. The " + BackupTokenHeader + " header must contain the backup token.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					BackupContentType: {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// OrganizationBackup handles a request
+func (c *HttpApiController) OrganizationBackup(response apitypes.Response, request apitypes.Request) error {
+
+	if c.backupController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.backupOrganization(request)
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+
+	controller, err := c.requestBackupController(request)
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+
+	archive, err := controller.Backup(organization, request.Header(BackupPassphraseHeader))
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+
+	response.SetHeader("Content-Type", BackupContentType)
+	return response.SendBytes(archive)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).OrganizationBackupDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).OrganizationBackup
@@ -13,8 +13,8 @@ import (
 // OrganizationCollectionDefinitions returns OpenAPI definitions
 func (c *HttpApiController) OrganizationCollectionDefinitions() swagger.Definitions {
 	return swagger.Definitions{
-		Summary:     "Returns a specific root certificate",
-		Description: "",
+		Summary:     "Returns a collection of organizations",
+		Description: "Supports the name, limit and cursor query parameters",
 		Responses: map[int]swagger.ContentValue{
 			200: {
 				Content: swagger.Content{
@@ -27,12 +27,16 @@ func (c *HttpApiController) OrganizationCollectionDefinitions() swagger.Definiti
 
 // OrganizationCollection handles a request
 func (c *HttpApiController) OrganizationCollection(response apitypes.Response, request apitypes.Request) error {
-	list, err := c.appController.OrganizationCollection()
+	query, param, err := parseOrganizationQuery(request)
+	if err != nil {
+		return c.badRequest(response, request, "query param invalid: "+param, err)
+	}
+	page, err := c.appController.OrganizationPage(query)
 	if err != nil {
 		return c.internalServerError(response, request, err)
 	}
-	c.logf(request, "list len = %d", len(list))
-	dto := apputils.ToOrganizationListDTO(list)
+	c.logf(request, "list len = %d", len(page.Organizations))
+	dto := apputils.ToOrganizationPageDTO(page)
 	return c.ok(response, dto)
 }
 
@@ -4,6 +4,7 @@ package appendpoints
 
 import (
 	"fmt"
+	"strings"
 
 	swagger "github.com/davidebianchi/gswagger"
 
@@ -63,6 +64,23 @@ func (c *HttpApiController) CreateOrganization(response apitypes.Response, reque
 		names = append(names, name)
 	}
 
+	signatureAlgorithm, err := apputils.ParseSignatureAlgorithm(body.SignatureAlgorithm)
+	if err != nil {
+		return c.badRequest(response, request, "body signatureAlgorithm invalid", err)
+	}
+
+	spiffeTrustDomain := strings.ToLower(strings.TrimSpace(body.SpiffeTrustDomain))
+	if spiffeTrustDomain != "" {
+		if err := apputils.ValidateSpiffeTrustDomain(spiffeTrustDomain); err != nil {
+			return c.badRequest(response, request, "body spiffeTrustDomain invalid", err)
+		}
+	}
+
+	keyRetentionPolicy, err := apputils.ParseKeyRetentionPolicy(body.KeyRetentionPolicy)
+	if err != nil {
+		return c.badRequest(response, request, "body keyRetentionPolicy invalid", err)
+	}
+
 	randomManager := c.certManager.RandomManager()
 
 	newOrgId, err := apputils.GenerateSerialNumber(randomManager)
@@ -72,7 +90,7 @@ func (c *HttpApiController) CreateOrganization(response apitypes.Response, reque
 
 	slug = apputils.Slugify(slug)
 
-	model := appmodels.NewOrganization(newOrgId, slug, names)
+	model := appmodels.NewOrganization(newOrgId, slug, names, signatureAlgorithm, spiffeTrustDomain, keyRetentionPolicy)
 
 	savedModel, err := c.appController.NewOrganization(model)
 	if err != nil {
@@ -0,0 +1,79 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// RestoreOrganizationDefinitions returns OpenAPI definitions
+func (c *HttpApiController) RestoreOrganizationDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Restores an organization from a backup",
+		Description: "Verifies the signature and contents of a backup archive and restores it. With ?dryRun=true the archive is only verified. Restore fails with 409 if the server has certificates or revocations which the backup does not include, unless ?force=true is set. The " + BackupTokenHeader + " header must contain the backup token.",
+		RequestBody: &swagger.ContentValue{
+			Description: "Backup archive",
+			Content: swagger.Content{
+				BackupContentType: {Value: ""},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.OrganizationBackupDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// RestoreOrganization handles a request
+func (c *HttpApiController) RestoreOrganization(response apitypes.Response, request apitypes.Request) error {
+
+	if c.backupController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.backupOrganization(request)
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+
+	archive, err := request.BodyBytes()
+	if err != nil {
+		return c.backupError(response, request, appmodels.NewBackupError(appmodels.BACKUP_ERROR_BAD_REQUEST, "failed to read the archive: %v", err))
+	}
+
+	passphrase := request.Header(BackupPassphraseHeader)
+	force := request.QueryParam("force") == "true"
+
+	backup, err := c.backupController.Verify(archive, passphrase, force)
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+	if backup.Organization().ID().Cmp(organization) != 0 {
+		return c.backupError(response, request, appmodels.NewBackupError(appmodels.BACKUP_ERROR_BAD_REQUEST, "backup is for organization %s", backup.Organization().ID()))
+	}
+
+	controller, err := c.requestBackupController(request)
+	if err != nil {
+		return c.backupError(response, request, err)
+	}
+
+	if request.QueryParam("dryRun") != "true" {
+		backup, err = controller.Restore(archive, passphrase, force)
+		if err != nil {
+			return c.backupError(response, request, err)
+		}
+	}
+
+	return c.ok(response, apputils.ToOrganizationBackupDTO(backup))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).RestoreOrganizationDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).RestoreOrganization
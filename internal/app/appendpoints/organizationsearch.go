@@ -0,0 +1,57 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"time"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// OrganizationSearchDefinitions returns OpenAPI definitions
+func (c *HttpApiController) OrganizationSearchDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Searches all certificates of an organization",
+		Description: "Supports the san, cn, fingerprint, ski, aki, serial, keyType, issuer, issuedAfter, issuedBefore, expiringAfter and expiringBefore search parameters in addition to the certificate collection query parameters",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.CertificateListDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// OrganizationSearch handles a request to search organization's certificates
+func (c *HttpApiController) OrganizationSearch(response apitypes.Response, request apitypes.Request) error {
+
+	query, param, err := parseCertificateSearch(request, time.Now())
+	if err != nil {
+		return c.badRequest(response, request, "query param invalid: "+param, err)
+	}
+
+	controller, err := c.organizationController(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	page, err := controller.CertificatePage(query)
+	if err != nil {
+		return c.internalServerError(response, request, err)
+	}
+
+	c.logf(request, "search len = %d", len(page.Certificates))
+	dto := apputils.ToCertificatePageDTO(page)
+	if err := c.setCertificateStates(dto.Payload, page.Certificates, query.Now); err != nil {
+		return c.internalServerError(response, request, err)
+	}
+	return c.ok(response, dto)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).OrganizationSearchDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).OrganizationSearch
@@ -0,0 +1,165 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// MaxPageLimit is the largest page size a client may request. Without the
+// limit query parameter collections are returned in full.
+const MaxPageLimit = 1000
+
+// parsePageLimit parses the limit query parameter. Zero means no limit.
+func parsePageLimit(request apitypes.Request) (int, error) {
+	value := request.QueryParam("limit")
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > MaxPageLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d: %s", MaxPageLimit, value)
+	}
+	return limit, nil
+}
+
+// parseCertificateQuery reads the filter, sort and pagination query
+// parameters of a certificate collection. On error the name of the invalid
+// parameter is returned.
+func parseCertificateQuery(request apitypes.Request, now time.Time) (appmodels.CertificateQuery, string, error) {
+	var err error
+	query := appmodels.CertificateQuery{
+		Name: request.QueryParam("name"),
+		Now:  now,
+	}
+
+	if query.Type, err = apputils.ParseCertificateType(request.QueryParam("type")); err != nil {
+		return query, "type", err
+	}
+
+	if query.Status, err = apputils.ParseCertificateStatus(request.QueryParam("status")); err != nil {
+		return query, "status", err
+	}
+
+	if value := request.QueryParam("expiringBefore"); value != "" {
+		if query.ExpiringBefore, err = time.Parse(time.RFC3339, value); err != nil {
+			return query, "expiringBefore", err
+		}
+	}
+
+	switch archived := request.QueryParam("archived"); archived {
+	case "", "false":
+	case "true":
+		query.Archived = true
+	default:
+		return query, "archived", fmt.Errorf("unsupported archived: %s", archived)
+	}
+
+	if query.Sort, err = apputils.ParseCertificateSortField(request.QueryParam("sort")); err != nil {
+		return query, "sort", err
+	}
+
+	switch order := request.QueryParam("order"); order {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, "order", fmt.Errorf("unsupported order: %s", order)
+	}
+
+	if query.Limit, err = parsePageLimit(request); err != nil {
+		return query, "limit", err
+	}
+
+	if query.After, err = apputils.DecodeCertificateCursor(request.QueryParam("cursor")); err != nil {
+		return query, "cursor", err
+	}
+	if err = apputils.ValidateCertificateCursor(query); err != nil {
+		return query, "cursor", err
+	}
+
+	return query, "", nil
+}
+
+// parseCertificateSearch reads the search criteria of the organization-wide
+// certificate search in addition to the collection query parameters. On
+// error the name of the invalid parameter is returned.
+func parseCertificateSearch(request apitypes.Request, now time.Time) (appmodels.CertificateQuery, string, error) {
+	query, param, err := parseCertificateQuery(request, now)
+	if err != nil {
+		return query, param, err
+	}
+
+	query.CommonName = request.QueryParam("cn")
+	query.SubjectAlternativeName = request.QueryParam("san")
+	query.Issuer = request.QueryParam("issuer")
+
+	if value := request.QueryParam("fingerprint"); value != "" {
+		fingerprint, err := apputils.ParseHexBytes(value)
+		if err != nil || len(fingerprint) != sha256.Size {
+			return query, "fingerprint", fmt.Errorf("fingerprint must be a SHA-256 digest: %s", value)
+		}
+		query.Fingerprint = hex.EncodeToString(fingerprint)
+	}
+
+	if value := request.QueryParam("ski"); value != "" {
+		if query.SubjectKeyId, err = apputils.ParseHexBytes(value); err != nil {
+			return query, "ski", err
+		}
+	}
+
+	if value := request.QueryParam("aki"); value != "" {
+		if query.AuthorityKeyId, err = apputils.ParseHexBytes(value); err != nil {
+			return query, "aki", err
+		}
+	}
+
+	if value := request.QueryParam("serial"); value != "" {
+		if query.SerialNumber, err = apputils.ParseSerialNumber(value); err != nil {
+			return query, "serial", err
+		}
+	}
+
+	if query.KeyType, err = apputils.ParseKeyType(request.QueryParam("keyType")); err != nil {
+		return query, "keyType", err
+	}
+
+	for name, target := range map[string]*time.Time{
+		"issuedAfter":   &query.IssuedAfter,
+		"issuedBefore":  &query.IssuedBefore,
+		"expiringAfter": &query.ExpiringAfter,
+	} {
+		if value := request.QueryParam(name); value != "" {
+			if *target, err = time.Parse(time.RFC3339, value); err != nil {
+				return query, name, err
+			}
+		}
+	}
+
+	return query, "", nil
+}
+
+// parseOrganizationQuery reads the filter and pagination query parameters of
+// the organization collection. On error the name of the invalid parameter is
+// returned.
+func parseOrganizationQuery(request apitypes.Request) (appmodels.OrganizationQuery, string, error) {
+	var err error
+	query := appmodels.OrganizationQuery{
+		Name: request.QueryParam("name"),
+	}
+	if query.Limit, err = parsePageLimit(request); err != nil {
+		return query, "limit", err
+	}
+	if query.After, err = apputils.DecodeOrganizationCursor(request.QueryParam("cursor")); err != nil {
+		return query, "cursor", err
+	}
+	return query, "", nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestCollectionPagination(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := memoryrepository.NewCollection()
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	_, err = appController.NewOrganization(appmodels.NewOrganization(big.NewInt(20), "other", []string{"Other Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	_, _, err = rootController.NewIntermediateCertificate("Test Intermediate")
+	require.NoError(t, err)
+	for _, name := range []string{"c.example.com", "a.example.com", "b.example.com"} {
+		_, _, err = rootController.NewServerCertificate(name)
+		require.NoError(t, err)
+	}
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	get := func(path string, query url.Values, dto any) int {
+		t.Helper()
+		res, err := http.Get(server.URL + path + "?" + query.Encode())
+		require.NoError(t, err)
+		defer res.Body.Close()
+		if res.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(dto))
+		}
+		return res.StatusCode
+	}
+
+	organizationPath := "/organizations/" + organization.String()
+	childPath := organizationPath + "/certificates/" + root.SerialNumber().String() + "/certificates"
+
+	// Server certificates by common name, two per page
+	query := url.Values{"type": {"server"}, "sort": {"commonName"}, "limit": {"2"}}
+	var names []string
+	for {
+		var dto appdtos.CertificateListDTO
+		require.Equal(t, http.StatusOK, get(childPath, query, &dto))
+		for _, cert := range dto.Payload {
+			names = append(names, cert.CommonName)
+		}
+		if dto.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", dto.NextCursor)
+	}
+	assert.Equal(t, []string{"a.example.com", "b.example.com", "c.example.com"}, names)
+
+	var dto appdtos.CertificateListDTO
+	require.Equal(t, http.StatusOK, get(childPath, url.Values{"type": {"intermediate"}}, &dto))
+	require.Len(t, dto.Payload, 1)
+	assert.Equal(t, "Test Intermediate", dto.Payload[0].CommonName)
+
+	dto = appdtos.CertificateListDTO{}
+	require.Equal(t, http.StatusOK, get(organizationPath+"/certificates", url.Values{"name": {"B.EXAMPLE"}, "status": {"valid"}}, &dto))
+	require.Len(t, dto.Payload, 1)
+	assert.Equal(t, "b.example.com", dto.Payload[0].CommonName)
+	assert.Empty(t, dto.NextCursor)
+
+	dto = appdtos.CertificateListDTO{}
+	require.Equal(t, http.StatusOK, get(organizationPath+"/certificates", url.Values{"sort": {"notAfter"}, "order": {"desc"}, "limit": {"1"}}, &dto))
+	require.Len(t, dto.Payload, 1)
+	require.NotEmpty(t, dto.NextCursor)
+	cursor := dto.NextCursor
+
+	for _, query := range []url.Values{
+		{"type": {"leaf"}},
+		{"status": {"revoked"}},
+		{"expiringBefore": {"tomorrow"}},
+		{"sort": {"serial"}},
+		{"order": {"up"}},
+		{"limit": {"0"}},
+		{"limit": {"1001"}},
+		{"cursor": {"!"}},
+		{"cursor": {cursor}, "sort": {"commonName"}},
+	} {
+		assert.Equal(t, http.StatusBadRequest, get(organizationPath+"/certificates", query, nil), query.Encode())
+	}
+
+	// Organizations in ID order, one per page
+	query = url.Values{"limit": {"1"}}
+	var slugs []string
+	for {
+		var organizations appdtos.OrganizationListDTO
+		require.Equal(t, http.StatusOK, get("/organizations", query, &organizations))
+		require.Len(t, organizations.Payload, 1)
+		slugs = append(slugs, organizations.Payload[0].Slug)
+		if organizations.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", organizations.NextCursor)
+	}
+	assert.Equal(t, []string{"other", "test"}, slugs)
+
+	var organizations appdtos.OrganizationListDTO
+	require.Equal(t, http.StatusOK, get("/organizations", url.Values{"name": {"TEST"}}, &organizations))
+	require.Len(t, organizations.Payload, 1)
+	assert.Equal(t, "test", organizations.Payload[0].Slug)
+	assert.Equal(t, http.StatusBadRequest, get("/organizations", url.Values{"limit": {"x"}}, nil))
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"net/http"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// readOnlySafeRoutes are routes which use a mutating HTTP method without
+// changing stored data. They are served in the read-only mode.
+var readOnlySafeRoutes = map[string]bool{
+	http.MethodPost + " /organizations/{organization}/backup":                                                                 true,
+	http.MethodPost + " /organizations/{organization}/certificates/{rootSerialNumber}/key-shares":                             true,
+	http.MethodPost + " /organizations/{organization}/certificates/{rootSerialNumber}/certificates/{serialNumber}/key-shares": true,
+	http.MethodPost + " /seal":        true,
+	http.MethodPost + " /seal/unseal": true,
+}
+
+// IsReadOnlyRoute returns true if the route does not change stored data and
+// is served in the read-only mode
+func IsReadOnlyRoute(route apitypes.Route) bool {
+	switch route.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return readOnlySafeRoutes[route.Method+" "+route.Path]
+	}
+}
+
+// readOnlyRoutes replaces the handlers of routes which change stored data
+func (c *HttpApiController) readOnlyRoutes(routes []apitypes.Route) []apitypes.Route {
+	for i, route := range routes {
+		if !IsReadOnlyRoute(route) {
+			routes[i].Handler = c.readOnlyError
+		}
+	}
+	return routes
+}
+
+// readOnlyError responds 503 Service Unavailable in the read-only mode
+func (c *HttpApiController) readOnlyError(response apitypes.Response, request apitypes.Request) error {
+	return c.serviceUnavailable(response, request, "server is in read-only mode")
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestIsReadOnlyRoute(t *testing.T) {
+	assert.True(t, appendpoints.IsReadOnlyRoute(apitypes.Route{Method: http.MethodGet, Path: "/organizations"}))
+	assert.True(t, appendpoints.IsReadOnlyRoute(apitypes.Route{Method: http.MethodHead, Path: "/acme/{organization}/{rootSerialNumber}/new-nonce"}))
+	assert.True(t, appendpoints.IsReadOnlyRoute(apitypes.Route{Method: http.MethodPost, Path: "/organizations/{organization}/backup"}))
+	assert.False(t, appendpoints.IsReadOnlyRoute(apitypes.Route{Method: http.MethodPost, Path: "/organizations"}))
+	assert.False(t, appendpoints.IsReadOnlyRoute(apitypes.Route{Method: http.MethodDelete, Path: "/organizations/{organization}/ssh/certificates/{serial}"}))
+}
+
+func TestReadOnly(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := memoryrepository.NewCollection()
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	controller.SetReadOnly(true)
+
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/organizations")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = http.Post(server.URL+"/organizations", "application/json", strings.NewReader(`{"slug":"test"}`))
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+	list, err := repository.Organization.FindAll()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
@@ -51,3 +51,44 @@ func (c *HttpApiController) DecodeCertificateRequestFromRequestBody(request apit
 
 	return body, nil
 }
+
+// DecodeUnsealFromRequestBody parses unseal DTO from request body
+func (c *HttpApiController) DecodeUnsealFromRequestBody(request apitypes.Request) (appdtos.UnsealDTO, error) {
+
+	if request == nil {
+		return appdtos.UnsealDTO{}, errors.New("request must be defined")
+	}
+
+	bodyIO := request.Body()
+
+	// Decode the JSON body into the struct
+	var body appdtos.UnsealDTO
+	err := json.NewDecoder(bodyIO).Decode(&body)
+	if err != nil {
+		return appdtos.UnsealDTO{}, fmt.Errorf("request decoding failed: %s", err)
+	}
+	_ = bodyIO.Close()
+
+	return body, nil
+}
+
+// DecodeKeyShareRequestFromRequestBody parses key share request DTO from
+// request body
+func (c *HttpApiController) DecodeKeyShareRequestFromRequestBody(request apitypes.Request) (appdtos.KeyShareRequestDTO, error) {
+
+	if request == nil {
+		return appdtos.KeyShareRequestDTO{}, errors.New("request must be defined")
+	}
+
+	bodyIO := request.Body()
+
+	// Decode the JSON body into the struct
+	var body appdtos.KeyShareRequestDTO
+	err := json.NewDecoder(bodyIO).Decode(&body)
+	if err != nil {
+		return appdtos.KeyShareRequestDTO{}, fmt.Errorf("request decoding failed: %s", err)
+	}
+	_ = bodyIO.Close()
+
+	return body, nil
+}
@@ -5,6 +5,7 @@ package appendpoints
 import (
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
@@ -27,6 +28,18 @@ func (c *HttpApiController) requestOrganization(request apitypes.Request) string
 	return organization
 }
 
+// organizationID returns the numeric ID of an existing organization
+func (c *HttpApiController) organizationID(request apitypes.Request) (*big.Int, error) {
+	organization, err := apputils.ParseBigInt(request.Variable("organization"), 10)
+	if err != nil {
+		return nil, fmt.Errorf("[%s %s]: failed to parse organization id: %v", request.Method(), request.URL(), err)
+	}
+	if _, err := c.appController.OrganizationController(organization); err != nil {
+		return nil, fmt.Errorf("[%s %s]: failed to find organization controller: %v", request.Method(), request.URL(), err)
+	}
+	return organization, nil
+}
+
 func (c *HttpApiController) rootSerialNumber(request apitypes.Request) (*big.Int, error) {
 	serialNumberString := request.Variable("rootSerialNumber")
 	serialNumber, err := apputils.ParseBigInt(serialNumberString, 10)
@@ -47,6 +60,15 @@ func (c *HttpApiController) serialNumber(request apitypes.Request) (*big.Int, er
 	return serialNumber, nil
 }
 
+func (c *HttpApiController) sshSerial(request apitypes.Request) (uint64, error) {
+	serial, err := strconv.ParseUint(request.Variable("serial"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("[%s %s]: failed to parse serial: %v", request.Method(), request.URL(), err)
+	}
+	c.logf(request, "serial = %d", serial)
+	return serial, nil
+}
+
 func (c *HttpApiController) organizationController(request apitypes.Request) (appmodels.OrganizationController, error) {
 	organization := c.requestOrganization(request)
 	if organization == "" {
@@ -56,9 +78,13 @@ func (c *HttpApiController) organizationController(request apitypes.Request) (ap
 	if err != nil {
 		return nil, fmt.Errorf("[%s %s]: failed to parse organization id: %s", request.Method(), request.URL(), organization)
 	}
-	controller, err := c.appController.OrganizationController(id)
+	appController, err := c.applicationController(request)
 	if err != nil {
-		return nil, fmt.Errorf("[%s %s]: failed to find organization controller: %v", request.Method(), request.URL(), err)
+		return nil, fmt.Errorf("[%s %s]: %w", request.Method(), request.URL(), err)
+	}
+	controller, err := appController.OrganizationController(id)
+	if err != nil {
+		return nil, fmt.Errorf("[%s %s]: failed to find organization controller: %w", request.Method(), request.URL(), err)
 	}
 	return controller, nil
 }
@@ -67,7 +93,7 @@ func (c *HttpApiController) rootCertificateController(request apitypes.Request)
 
 	controller, err := c.organizationController(request)
 	if err != nil {
-		return nil, fmt.Errorf("[%s %s]: failed to find organization controller: %v", request.Method(), request.URL(), err)
+		return nil, fmt.Errorf("[%s %s]: failed to find organization controller: %w", request.Method(), request.URL(), err)
 	}
 
 	rootSerialNumber, err := c.rootSerialNumber(request)
@@ -87,7 +113,7 @@ func (c *HttpApiController) innerCertificateController(request apitypes.Request)
 
 	rootCertificateController, err := c.rootCertificateController(request)
 	if err != nil {
-		return nil, fmt.Errorf("[%s %s]: failed to find root certificate controller: %v", request.Method(), request.URL(), err)
+		return nil, fmt.Errorf("[%s %s]: failed to find root certificate controller: %w", request.Method(), request.URL(), err)
 	}
 
 	serialNumber, err := c.serialNumber(request)
@@ -3,14 +3,25 @@
 package appendpoints
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
 	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
 )
 
+// badRequest responds 503 Service Unavailable instead if the error was
+// caused by sealed private keys, and 403 Forbidden if an approval was denied
 func (c *HttpApiController) badRequest(response apitypes.Response, request apitypes.Request, publicMessage string, err error) error {
+	if errors.Is(err, appmodels.ErrSealed) {
+		return c.sealed(response, request, err)
+	}
+	if isApprovalDenied(err) {
+		return c.forbidden(response, request, err)
+	}
 	msg := fmt.Sprintf("[%s %s]: %s", request.Method(), request.URL(), publicMessage)
 	if err != nil {
 		log.Printf("%s: %v", msg, err)
@@ -19,7 +30,16 @@ func (c *HttpApiController) badRequest(response apitypes.Response, request apity
 	return nil
 }
 
+// notFound responds 503 Service Unavailable instead if the error was caused
+// by sealed private keys, e.g. when an SSH CA key cannot be read, and 403
+// Forbidden if an approver token was not accepted
 func (c *HttpApiController) notFound(response apitypes.Response, request apitypes.Request, err error) error {
+	if errors.Is(err, appmodels.ErrSealed) {
+		return c.sealed(response, request, err)
+	}
+	if isApprovalDenied(err) {
+		return c.forbidden(response, request, err)
+	}
 	publicMsg := fmt.Sprintf("[%s %s]: Not Found", request.Method(), request.URL())
 	if err != nil {
 		log.Printf("%s: %v", publicMsg, err)
@@ -28,7 +48,29 @@ func (c *HttpApiController) notFound(response apitypes.Response, request apitype
 	return nil
 }
 
+func (c *HttpApiController) forbidden(response apitypes.Response, request apitypes.Request, err error) error {
+	publicMsg := fmt.Sprintf("[%s %s]: Forbidden", request.Method(), request.URL())
+	if err != nil {
+		log.Printf("%s: %v", publicMsg, err)
+	}
+	response.SendError(http.StatusForbidden, publicMsg)
+	return nil
+}
+
+// internalServerError responds 503 Service Unavailable instead if the error
+// was caused by sealed private keys, 202 Accepted if the operation requires
+// approvals, and 403 Forbidden if the approval was denied
 func (c *HttpApiController) internalServerError(response apitypes.Response, request apitypes.Request, err error) error {
+	var approvalErr *appmodels.ApprovalRequiredError
+	if errors.As(err, &approvalErr) {
+		return c.approvalRequired(response, request, approvalErr)
+	}
+	if isApprovalDenied(err) {
+		return c.forbidden(response, request, err)
+	}
+	if errors.Is(err, appmodels.ErrSealed) {
+		return c.sealed(response, request, err)
+	}
 	publicMsg := fmt.Sprintf("[%s %s]: Internal Server Error", request.Method(), request.URL())
 	if err != nil {
 		log.Printf("%s: %v", publicMsg, err)
@@ -46,6 +88,38 @@ func (c *HttpApiController) conflict(response apitypes.Response, request apitype
 	return nil
 }
 
+// sealed responds 503 Service Unavailable to a request which needs sealed
+// private keys
+func (c *HttpApiController) sealed(response apitypes.Response, request apitypes.Request, err error) error {
+	log.Printf("[%s %s]: %v", request.Method(), request.URL(), err)
+	return c.serviceUnavailable(response, request, "private keys are sealed")
+}
+
+func (c *HttpApiController) serviceUnavailable(response apitypes.Response, request apitypes.Request, publicMessage string) error {
+	publicMsg := fmt.Sprintf("[%s %s]: Service Unavailable: %s", request.Method(), request.URL(), publicMessage)
+	c.logf(request, "Service Unavailable: %s", publicMessage)
+	response.SendError(http.StatusServiceUnavailable, publicMsg)
+	return nil
+}
+
+// approvalRequired responds 202 Accepted with the operation which must be
+// approved before the request is repeated
+func (c *HttpApiController) approvalRequired(response apitypes.Response, request apitypes.Request, err *appmodels.ApprovalRequiredError) error {
+	threshold := 0
+	if c.approvalController != nil {
+		threshold = c.approvalController.Threshold()
+	}
+	c.logf(request, "approval required: %s", err.Operation().ID())
+	response.Send(http.StatusAccepted, apputils.ToPendingOperationDTO(err.Operation(), threshold))
+	return nil
+}
+
+// isApprovalDenied returns true if a sensitive operation was requested
+// without an accepted approver token, or by someone else than the requester
+func isApprovalDenied(err error) bool {
+	return errors.Is(err, appmodels.ErrApprovalPermissionDenied) || errors.Is(err, appmodels.ErrOperationRequester)
+}
+
 func (c *HttpApiController) ok(response apitypes.Response, data interface{}) error {
 	response.Send(http.StatusOK, data)
 	return nil
@@ -3,6 +3,8 @@
 package appendpoints
 
 import (
+	"time"
+
 	swagger "github.com/davidebianchi/gswagger"
 
 	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
@@ -38,6 +40,9 @@ func (c *HttpApiController) RootCertificate(response apitypes.Response, request
 	c.logf(request, "model = %v", model)
 
 	dto := apputils.ToCertificateDTO(model)
+	if err := c.setCertificateState(&dto, model, time.Now()); err != nil {
+		return c.internalServerError(response, request, err)
+	}
 	return c.ok(response, dto)
 
 }
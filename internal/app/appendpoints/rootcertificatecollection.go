@@ -3,6 +3,8 @@
 package appendpoints
 
 import (
+	"time"
+
 	swagger "github.com/davidebianchi/gswagger"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
@@ -13,8 +15,8 @@ import (
 // GetRootCertificateCollectionDefinitions returns OpenAPI definitions
 func (c *HttpApiController) RootCertificateCollectionDefinitions() swagger.Definitions {
 	return swagger.Definitions{
-		Summary:     "Returns a collection of root certificate entities",
-		Description: "",
+		Summary:     "Returns a collection of certificates of the organization",
+		Description: "Supports the type, status, expiringBefore, name, archived, sort, order, limit and cursor query parameters",
 		Responses: map[int]swagger.ContentValue{
 			200: {
 				Content: swagger.Content{
@@ -28,18 +30,26 @@ func (c *HttpApiController) RootCertificateCollectionDefinitions() swagger.Defin
 // GetRootCertificateCollection handles a request to get organization's certificates
 func (c *HttpApiController) RootCertificateCollection(response apitypes.Response, request apitypes.Request) error {
 
+	query, param, err := parseCertificateQuery(request, time.Now())
+	if err != nil {
+		return c.badRequest(response, request, "query param invalid: "+param, err)
+	}
+
 	controller, err := c.organizationController(request)
 	if err != nil {
 		return c.notFound(response, request, err)
 	}
 
-	list, err := controller.CertificateCollection()
+	page, err := controller.CertificatePage(query)
 	if err != nil {
 		return c.internalServerError(response, request, err)
 	}
 
-	c.logf(request, "list len = %d", len(list))
-	dto := apputils.ToCertificateListDTO(list)
+	c.logf(request, "list len = %d", len(page.Certificates))
+	dto := apputils.ToCertificatePageDTO(page)
+	if err := c.setCertificateStates(dto.Payload, page.Certificates, query.Now); err != nil {
+		return c.internalServerError(response, request, err)
+	}
 	return c.ok(response, dto)
 }
 
@@ -8,6 +8,7 @@ import (
 	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
 	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
 )
 
@@ -51,11 +52,29 @@ func (c *HttpApiController) CreateRootCertificate(response apitypes.Response, re
 		return c.badRequest(response, request, "body type invalid", nil)
 	}
 
+	keyType, err := apputils.ParseKeyType(body.KeyType)
+	if err != nil {
+		return c.badRequest(response, request, "body keyType invalid", err)
+	}
+
+	signatureAlgorithm, err := apputils.ParseSignatureAlgorithm(body.SignatureAlgorithm)
+	if err != nil {
+		return c.badRequest(response, request, "body signatureAlgorithm invalid", err)
+	}
+
 	organizationController, err := c.organizationController(request)
 	if err != nil {
 		return c.notFound(response, request, err)
 	}
 
+	if keyType != appmodels.NIL_KEY_TYPE {
+		organizationController.SetKeyType(keyType)
+	}
+
+	if signatureAlgorithm != appmodels.NIL_SIGNATURE_ALGORITHM {
+		organizationController.SetSignatureAlgorithm(signatureAlgorithm)
+	}
+
 	commonName := body.CommonName
 
 	cert, err := organizationController.NewRootCertificate(commonName)
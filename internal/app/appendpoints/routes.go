@@ -9,7 +9,133 @@ import (
 )
 
 func (c *HttpApiController) Routes() []apitypes.Route {
-	return []apitypes.Route{
+	routes := []apitypes.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/.well-known/est/{label}/cacerts",
+			Handler:     c.EstCACertificates,
+			Definitions: c.EstCACertificatesDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/.well-known/est/{label}/simpleenroll",
+			Handler:     c.EstSimpleEnroll,
+			Definitions: c.EstSimpleEnrollDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/.well-known/est/{label}/simplereenroll",
+			Handler:     c.EstSimpleReenroll,
+			Definitions: c.EstSimpleReenrollDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/.well-known/est/{label}/csrattrs",
+			Handler:     c.EstCsrAttributes,
+			Definitions: c.EstCsrAttributesDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/scep/{organization}/{rootSerialNumber}",
+			Handler:     c.Scep,
+			Definitions: c.ScepDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/scep/{organization}/{rootSerialNumber}",
+			Handler:     c.Scep,
+			Definitions: c.ScepDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/scep/{organization}/{rootSerialNumber}/pkiclient.exe",
+			Handler:     c.Scep,
+			Definitions: c.ScepDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/scep/{organization}/{rootSerialNumber}/pkiclient.exe",
+			Handler:     c.Scep,
+			Definitions: c.ScepDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/acme/{organization}/{rootSerialNumber}/directory",
+			Handler:     c.AcmeDirectory,
+			Definitions: c.AcmeDirectoryDefinitions(),
+		},
+		{
+			Method:      http.MethodHead,
+			Path:        "/acme/{organization}/{rootSerialNumber}/new-nonce",
+			Handler:     c.AcmeNewNonce,
+			Definitions: c.AcmeNewNonceDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/acme/{organization}/{rootSerialNumber}/new-nonce",
+			Handler:     c.AcmeNewNonce,
+			Definitions: c.AcmeNewNonceDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/acme/{organization}/{rootSerialNumber}/new-account",
+			Handler:     c.AcmeNewAccount,
+			Definitions: c.AcmeNewAccountDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/acme/{organization}/{rootSerialNumber}/account/{accountId}/orders",
+			Handler:     c.AcmeOrderCollection,
+			Definitions: c.AcmeOrderCollectionDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/acme/{organization}/{rootSerialNumber}/account/{accountId}",
+			Handler:     c.AcmeAccount,
+			Definitions: c.AcmeAccountDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/acme/{organization}/{rootSerialNumber}/new-order",
+			Handler:     c.AcmeNewOrder,
+			Definitions: c.AcmeNewOrderDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/acme/{organization}/{rootSerialNumber}/order/{orderId}/finalize",
+			Handler:     c.AcmeFinalizeOrder,
+			Definitions: c.AcmeFinalizeOrderDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/acme/{organization}/{rootSerialNumber}/order/{orderId}",
+			Handler:     c.AcmeOrder,
+			Definitions: c.AcmeOrderDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/acme/{organization}/{rootSerialNumber}/authz/{authorizationId}",
+			Handler:     c.AcmeAuthorization,
+			Definitions: c.AcmeAuthorizationDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/acme/{organization}/{rootSerialNumber}/chall/{authorizationId}/{challengeId}",
+			Handler:     c.AcmeChallenge,
+			Definitions: c.AcmeChallengeDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/acme/{organization}/{rootSerialNumber}/cert/{serialNumber}",
+			Handler:     c.AcmeCertificate,
+			Definitions: c.AcmeCertificateDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/organizations/{organization}/certificates/{rootSerialNumber}/certificates/{serialNumber}/est",
+			Handler:     c.CreateEstLabel,
+			Definitions: c.CreateEstLabelDefinitions(),
+		},
 		{
 			Method:      http.MethodDelete,
 			Path:        "/organizations/{organization}/certificates/{rootSerialNumber}/certificates/{serialNumber}",
@@ -34,6 +160,12 @@ func (c *HttpApiController) Routes() []apitypes.Route {
 			Handler:     c.CreateCertificate,
 			Definitions: c.CreateCertificateDefinitions(),
 		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/organizations/{organization}/certificates/{rootSerialNumber}/est",
+			Handler:     c.CreateEstLabel,
+			Definitions: c.CreateEstLabelDefinitions(),
+		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/organizations/{organization}/certificates/{rootSerialNumber}/crl",
@@ -64,6 +196,276 @@ func (c *HttpApiController) Routes() []apitypes.Route {
 			Handler:     c.CreateRootCertificate,
 			Definitions: c.CreateRootCertificateDefinitions(),
 		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/organizations/{organization}/spiffe/bundle",
+			Handler:     c.SpiffeBundle,
+			Definitions: c.SpiffeBundleDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/organizations/{organization}/ssh/ca.pub",
+			Handler:     c.SshAuthorityPublicKey,
+			Definitions: c.SshAuthorityPublicKeyDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/organizations/{organization}/ssh/krl",
+			Handler:     c.SshRevocationList,
+			Definitions: c.SshRevocationListDefinitions(),
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/organizations/{organization}/ssh/certificates/{serial}",
+			Handler:     c.RevokeSshCertificate,
+			Definitions: c.RevokeSshCertificateDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/organizations/{organization}/ssh/certificates/{serial}",
+			Handler:     c.SshCertificate,
+			Definitions: c.SshCertificateDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/organizations/{organization}/ssh/certificates",
+			Handler:     c.SshCertificateCollection,
+			Definitions: c.SshCertificateCollectionDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/organizations/{organization}/ssh/certificates",
+			Handler:     c.CreateSshCertificate,
+			Definitions: c.CreateSshCertificateDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/organizations/{organization}/ssh",
+			Handler:     c.SshAuthority,
+			Definitions: c.SshAuthorityDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/organizations/{organization}/ssh",
+			Handler:     c.CreateSshAuthority,
+			Definitions: c.CreateSshAuthorityDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/pki/{organization}/roles",
+			Handler:     c.VaultRoleCollection,
+			Definitions: c.VaultRoleCollectionDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/pki/{organization}/roles/{role}",
+			Handler:     c.VaultRole,
+			Definitions: c.VaultRoleDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/v1/pki/{organization}/roles/{role}",
+			Handler:     c.SaveVaultRole,
+			Definitions: c.SaveVaultRoleDefinitions(),
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/v1/pki/{organization}/roles/{role}",
+			Handler:     c.SaveVaultRole,
+			Definitions: c.SaveVaultRoleDefinitions(),
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/v1/pki/{organization}/roles/{role}",
+			Handler:     c.DeleteVaultRole,
+			Definitions: c.DeleteVaultRoleDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/v1/pki/{organization}/issue/{role}",
+			Handler:     c.VaultIssue,
+			Definitions: c.VaultIssueDefinitions(),
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/v1/pki/{organization}/issue/{role}",
+			Handler:     c.VaultIssue,
+			Definitions: c.VaultIssueDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/v1/pki/{organization}/sign/{role}",
+			Handler:     c.VaultSign,
+			Definitions: c.VaultSignDefinitions(),
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/v1/pki/{organization}/sign/{role}",
+			Handler:     c.VaultSign,
+			Definitions: c.VaultSignDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/v1/pki/{organization}/revoke",
+			Handler:     c.VaultRevoke,
+			Definitions: c.VaultRevokeDefinitions(),
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/v1/pki/{organization}/revoke",
+			Handler:     c.VaultRevoke,
+			Definitions: c.VaultRevokeDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/pki/{organization}/ca",
+			Handler:     c.VaultCA,
+			Definitions: c.VaultCADefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/pki/{organization}/ca/pem",
+			Handler:     c.VaultCAPem,
+			Definitions: c.VaultCAPemDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/pki/{organization}/ca_chain",
+			Handler:     c.VaultCAChain,
+			Definitions: c.VaultCAChainDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/pki/{organization}/crl",
+			Handler:     c.VaultCrl,
+			Definitions: c.VaultCrlDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/pki/{organization}/crl/pem",
+			Handler:     c.VaultCrlPem,
+			Definitions: c.VaultCrlPemDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/pki/{organization}/issuer/{issuer}/der",
+			Handler:     c.VaultCA,
+			Definitions: c.VaultCADefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/pki/{organization}/issuer/{issuer}/pem",
+			Handler:     c.VaultCAPem,
+			Definitions: c.VaultCAPemDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/pki/{organization}/issuer/{issuer}/crl/der",
+			Handler:     c.VaultCrl,
+			Definitions: c.VaultCrlDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/pki/{organization}/issuer/{issuer}/crl/pem",
+			Handler:     c.VaultCrlPem,
+			Definitions: c.VaultCrlPemDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/organizations/{organization}/scep/challenge",
+			Handler:     c.SetScepChallenge,
+			Definitions: c.SetScepChallengeDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/organizations/{organization}/search",
+			Handler:     c.OrganizationSearch,
+			Definitions: c.OrganizationSearchDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/organizations/{organization}/archive",
+			Handler:     c.ArchiveOrganization,
+			Definitions: c.ArchiveOrganizationDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/organizations/{organization}/backup",
+			Handler:     c.OrganizationBackup,
+			Definitions: c.OrganizationBackupDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/organizations/{organization}/restore",
+			Handler:     c.RestoreOrganization,
+			Definitions: c.RestoreOrganizationDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/organizations/{organization}/certificates/{rootSerialNumber}/certificates/{serialNumber}/key-shares",
+			Handler:     c.ExportKeyShares,
+			Definitions: c.ExportKeySharesDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/organizations/{organization}/certificates/{rootSerialNumber}/certificates/{serialNumber}/key-shares/import",
+			Handler:     c.ImportKeyShares,
+			Definitions: c.ImportKeySharesDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/organizations/{organization}/certificates/{rootSerialNumber}/key-shares",
+			Handler:     c.ExportKeyShares,
+			Definitions: c.ExportKeySharesDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/organizations/{organization}/certificates/{rootSerialNumber}/key-shares/import",
+			Handler:     c.ImportKeyShares,
+			Definitions: c.ImportKeySharesDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/seal",
+			Handler:     c.SealStatus,
+			Definitions: c.SealStatusDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/seal",
+			Handler:     c.Seal,
+			Definitions: c.SealDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/seal/unseal",
+			Handler:     c.Unseal,
+			Definitions: c.UnsealDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/approvals",
+			Handler:     c.GetApprovalCollection,
+			Definitions: c.GetApprovalCollectionDefinitions(),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/approvals/{operation}",
+			Handler:     c.GetApprovalOperation,
+			Definitions: c.GetApprovalOperationDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/approvals/{operation}/approve",
+			Handler:     c.ApproveOperation,
+			Definitions: c.ApproveOperationDefinitions(),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/approvals/{operation}/reject",
+			Handler:     c.RejectOperation,
+			Definitions: c.RejectOperationDefinitions(),
+		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/organizations/{organization}",
@@ -89,6 +491,10 @@ func (c *HttpApiController) Routes() []apitypes.Route {
 			Definitions: c.IndexDefinitions(),
 		},
 	}
+	if c.readOnly {
+		return c.readOnlyRoutes(routes)
+	}
+	return routes
 }
 
 var _ apitypes.ApplicationRoutesFunc = (*HttpApiController)(nil).Routes
@@ -0,0 +1,127 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strings"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// ScepCACertContentType is the content type of GetCACert responses which
+// include the RA certificate
+const ScepCACertContentType = "application/x-x509-ca-ra-cert"
+
+// ScepPkiMessageContentType is the content type of PKIOperation messages
+const ScepPkiMessageContentType = "application/x-pki-message"
+
+// scepIssuer returns the root certificate of the SCEP server
+func (c *HttpApiController) scepIssuer(request apitypes.Request) (appmodels.CertificateController, error) {
+	organization, err := apputils.ParseBigInt(request.Variable("organization"), 10)
+	if err != nil {
+		return nil, fmt.Errorf("organization: %w", err)
+	}
+	rootSerialNumber, err := c.rootSerialNumber(request)
+	if err != nil {
+		return nil, err
+	}
+	organizationController, err := c.appController.OrganizationController(organization)
+	if err != nil {
+		return nil, err
+	}
+	issuer, err := organizationController.CertificateController(rootSerialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !issuer.Certificate().IsRootCertificate() {
+		return nil, fmt.Errorf("not a root certificate")
+	}
+	return issuer, nil
+}
+
+// ScepDefinitions returns OpenAPI definitions
+func (c *HttpApiController) ScepDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "SCEP server of a root certificate",
+		Description: "Implements the GetCACaps, GetCACert and PKIOperation operations (RFC 8894) selected by the operation query parameter. PKCSReq messages must include the challenge password of the organization and RenewalReq messages must be signed with a valid certificate issued by the CA.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"text/plain":              {Value: ""},
+					ScepCACertContentType:     {Value: ""},
+					ScepPkiMessageContentType: {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// Scep handles a request
+func (c *HttpApiController) Scep(response apitypes.Response, request apitypes.Request) error {
+
+	if c.scepController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.scepIssuer(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	operation := request.QueryParam("operation")
+	switch operation {
+
+	case "GetCACaps":
+		response.SetHeader("Content-Type", "text/plain")
+		return response.SendBytes([]byte(strings.Join(c.scepController.Capabilities(), "\n")))
+
+	case "GetCACert":
+		ra, _, err := c.scepController.RA(issuer)
+		if err != nil {
+			return c.internalServerError(response, request, err)
+		}
+		certificates := []*x509.Certificate{ra.Certificate()}
+		for _, certificate := range c.scepController.CACertificates(issuer) {
+			certificates = append(certificates, certificate.Certificate())
+		}
+		der, err := apputils.EncodePkcs7Certificates(certificates)
+		if err != nil {
+			return c.internalServerError(response, request, err)
+		}
+		response.SetHeader("Content-Type", ScepCACertContentType)
+		return response.SendBytes(der)
+
+	case "PKIOperation":
+		if c.readOnly {
+			return c.readOnlyError(response, request)
+		}
+		var message []byte
+		if request.Method() == http.MethodGet {
+			message, err = apputils.DecodeScepMessage(request.QueryParam("message"))
+		} else {
+			message, err = request.BodyBytes()
+		}
+		if err != nil || len(message) == 0 {
+			return c.badRequest(response, request, "message invalid", err)
+		}
+		der, err := c.scepController.PKIOperation(issuer, message)
+		if err != nil {
+			return c.badRequest(response, request, "message invalid", err)
+		}
+		response.SetHeader("Content-Type", ScepPkiMessageContentType)
+		return response.SendBytes(der)
+
+	default:
+		return c.badRequest(response, request, "operation invalid", fmt.Errorf("unsupported operation: '%s'", operation))
+	}
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).ScepDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).Scep
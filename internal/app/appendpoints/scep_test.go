@@ -0,0 +1,197 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// testScepCertificateRepository lists the saved certificates, which the file
+// repository does not support yet
+type testScepCertificateRepository struct {
+	*filerepository.FileCertificateRepository
+	certificates []appmodels.Certificate
+}
+
+func (r *testScepCertificateRepository) Save(certificate appmodels.Certificate) (appmodels.Certificate, error) {
+	saved, err := r.FileCertificateRepository.Save(certificate)
+	if err == nil {
+		r.certificates = append(r.certificates, certificate)
+	}
+	return saved, err
+}
+
+func (r *testScepCertificateRepository) FindAllByOrganizationAndSignedBy(organization *big.Int, certificate *big.Int) ([]appmodels.Certificate, error) {
+	var list []appmodels.Certificate
+	for _, saved := range r.certificates {
+		if saved.OrganizationID().Cmp(organization) == 0 && saved.SignedBy() != nil && saved.SignedBy().Cmp(certificate) == 0 {
+			list = append(list, saved)
+		}
+	}
+	return list, nil
+}
+
+func readTestScepBody(t *testing.T, res *http.Response, contentType string) []byte {
+	defer func() { _ = res.Body.Close() }()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, contentType, res.Header.Get("Content-Type"))
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return body
+}
+
+// TestScep_Client runs the SCEP enrollment and renewal flow
+func TestScep_Client(t *testing.T) {
+
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := filerepository.NewCollection(certManager, managers.NewFileManager(), t.TempDir())
+	certificateRepository := &testScepCertificateRepository{FileCertificateRepository: repository.Certificate.(*filerepository.FileCertificateRepository)}
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		certificateRepository,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization := appmodels.NewSerialNumber(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	organizationController.SetExpirationDuration(2 * appcontrollers.DefaultScepRaExpiration)
+	root, err := organizationController.NewRootCertificate("Test Root CA")
+	require.NoError(t, err)
+
+	scepController := appcontrollers.NewScepController(memoryrepository.NewScepChallengeRepository(), repository.PrivateKey, repository.CertificateRevocation, time.Hour)
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	controller.SetScepController(scepController)
+
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseURL := server.URL + "/scep/10/" + root.SerialNumber().String() + "/pkiclient.exe"
+
+	res, err := http.Post(server.URL+"/organizations/10/scep/challenge", "application/json", bytes.NewReader([]byte(`{"challengePassword":"secret"}`)))
+	require.NoError(t, err)
+	assert.Contains(t, string(readTestScepBody(t, res, "application/json")), `"organization":"10"`)
+
+	res, err = http.Get(baseURL + "?operation=GetCACaps")
+	require.NoError(t, err)
+	assert.Contains(t, string(readTestScepBody(t, res, "text/plain")), "POSTPKIOperation")
+
+	res, err = http.Get(baseURL + "?operation=GetCACert")
+	require.NoError(t, err)
+	certificates, err := apputils.ParsePkcs7Certificates(readTestScepBody(t, res, appendpoints.ScepCACertContentType))
+	require.NoError(t, err)
+	require.Len(t, certificates, 2)
+	ra := certificates[0]
+	assert.Equal(t, appcontrollers.ScepRaCommonName, ra.Subject.CommonName)
+	assert.Equal(t, root.Certificate().Raw, certificates[1].Raw)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "device-1"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	selfSigned, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	csr, err := apputils.NewScepCertificateRequest(&x509.CertificateRequest{Subject: pkix.Name{CommonName: "device-1"}}, "secret", key)
+	require.NoError(t, err)
+
+	// PKCSReq using GET
+	message, err := apputils.EncodeScepRequest(apputils.SCEP_MESSAGE_PKCS_REQ, "1", []byte("nonce-1"), csr, ra, selfSigned, key, apputils.Pkcs7Aes256CbcOID)
+	require.NoError(t, err)
+	res, err = http.Get(baseURL + "?operation=PKIOperation&message=" + url.QueryEscape(base64.StdEncoding.EncodeToString(message)))
+	require.NoError(t, err)
+	response, err := apputils.ParseScepResponse(readTestScepBody(t, res, appendpoints.ScepPkiMessageContentType), selfSigned, key)
+	require.NoError(t, err)
+	require.Equal(t, apputils.SCEP_STATUS_SUCCESS, response.Status)
+	assert.Equal(t, []byte("nonce-1"), response.RecipientNonce)
+	require.Len(t, response.Certificates, 1)
+	issued := response.Certificates[0]
+	assert.Equal(t, "device-1", issued.Subject.CommonName)
+	assert.NoError(t, issued.CheckSignatureFrom(root.Certificate()))
+
+	// RenewalReq using POST, signed with the issued certificate
+	csr, err = apputils.NewScepCertificateRequest(&x509.CertificateRequest{Subject: pkix.Name{CommonName: "device-1"}}, "", key)
+	require.NoError(t, err)
+	message, err = apputils.EncodeScepRequest(apputils.SCEP_MESSAGE_RENEWAL_REQ, "2", []byte("nonce-2"), csr, ra, issued, key, apputils.Pkcs7DesEde3CbcOID)
+	require.NoError(t, err)
+	res, err = http.Post(baseURL+"?operation=PKIOperation", appendpoints.ScepPkiMessageContentType, bytes.NewReader(message))
+	require.NoError(t, err)
+	response, err = apputils.ParseScepResponse(readTestScepBody(t, res, appendpoints.ScepPkiMessageContentType), issued, key)
+	require.NoError(t, err)
+	require.Equal(t, apputils.SCEP_STATUS_SUCCESS, response.Status)
+	assert.NotEqual(t, issued.SerialNumber, response.Certificates[0].SerialNumber)
+
+	res, err = http.Post(baseURL+"?operation=PKIOperation", appendpoints.ScepPkiMessageContentType, bytes.NewReader([]byte("invalid")))
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, err = http.Get(baseURL + "?operation=Unknown")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, err = http.Get(server.URL + "/scep/10/999?operation=GetCACaps")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestScep_DisabledWithoutController(t *testing.T) {
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), nil, nil)
+
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/scep/10/1?operation=GetCACaps")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"encoding/json"
+	"fmt"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// SetScepChallengeDefinitions returns OpenAPI definitions
+func (c *HttpApiController) SetScepChallengeDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Sets the SCEP challenge password of an organization",
+		Description: "SCEP clients must include the challenge password in PKCSReq messages. The previous password stops working.",
+		RequestBody: &swagger.ContentValue{
+			Description: "SCEP challenge password request data",
+			Content: swagger.Content{
+				"application/json": {
+					Value: appdtos.ScepChallengeRequestDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.ScepChallengeDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// SetScepChallenge handles a request
+func (c *HttpApiController) SetScepChallenge(response apitypes.Response, request apitypes.Request) error {
+
+	if c.scepController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.organizationID(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	var body appdtos.ScepChallengeRequestDTO
+	if err := json.NewDecoder(request.Body()).Decode(&body); err != nil {
+		return c.badRequest(response, request, "body invalid", err)
+	}
+	if body.ChallengePassword == "" {
+		return c.badRequest(response, request, "challengePassword invalid", fmt.Errorf("challengePassword: must be defined"))
+	}
+
+	challenge, err := c.scepController.SetChallengePassword(organization, body.ChallengePassword)
+	if err != nil {
+		return c.internalServerError(response, request, err)
+	}
+
+	return c.ok(response, apputils.ToScepChallengeDTO(challenge))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).SetScepChallengeDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).SetScepChallenge
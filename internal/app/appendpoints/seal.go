@@ -0,0 +1,55 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// SealTokenHeader is the header of seal client tokens
+const SealTokenHeader = "X-Seal-Token"
+
+// sealStatusDTO returns the state of the seal
+func (c *HttpApiController) sealStatusDTO() appdtos.SealStatusDTO {
+	return appdtos.NewSealStatusDTO(
+		c.sealController.IsSealed(),
+		c.sealController.Threshold(),
+		c.sealController.Progress(),
+	)
+}
+
+// SealDefinitions returns OpenAPI definitions
+func (c *HttpApiController) SealDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Seals the private keys",
+		Description: "Wipes the seal key and the provided key shares from memory. Operations which need private keys respond 503 until unsealed. The " + SealTokenHeader + " header must contain the seal token.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.SealStatusDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// Seal handles a request
+func (c *HttpApiController) Seal(response apitypes.Response, request apitypes.Request) error {
+
+	if c.sealController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	if err := c.sealController.Authenticate(request.Header(SealTokenHeader)); err != nil {
+		return c.forbidden(response, request, err)
+	}
+
+	c.sealController.Seal()
+	return c.ok(response, c.sealStatusDTO())
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).SealDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).Seal
@@ -0,0 +1,202 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sealedrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestSealing(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	config, _, shares, err := apputils.NewSealConfig("secret", 3, 2)
+	require.NoError(t, err)
+	sealController := appcontrollers.NewSealController(config, "token")
+	repository := sealedrepository.NewCollection(memoryrepository.NewCollection(), sealController, certManager)
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	_, err = appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	controller.SetSealController(sealController)
+	controller.SetSshController(appcontrollers.NewSshController(repository.SshAuthority, repository.SshCertificate, randomManager, time.Hour))
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	send := func(method, path, token string, body any) *http.Response {
+		t.Helper()
+		var data []byte
+		if body != nil {
+			data, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+		req, err := http.NewRequest(method, httpServer.URL+path, bytes.NewReader(data))
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set(appendpoints.SealTokenHeader, token)
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+	status := func(res *http.Response) appdtos.SealStatusDTO {
+		t.Helper()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var dto appdtos.SealStatusDTO
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&dto))
+		return dto
+	}
+	rootPath := "/organizations/" + organization.String() + "/certificates"
+	rootRequest := appdtos.CertificateRequestDTO{CommonName: "Test Root"}
+
+	sshPath := "/organizations/" + organization.String() + "/ssh"
+
+	// Private keys cannot be created while sealed
+	assert.Equal(t, appdtos.NewSealStatusDTO(true, 2, 0), status(send(http.MethodGet, "/seal", "", nil)))
+	assert.Equal(t, http.StatusServiceUnavailable, send(http.MethodPost, rootPath, "", rootRequest).StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, send(http.MethodPost, sshPath, "", appdtos.SshAuthorityRequestDTO{}).StatusCode)
+
+	// Unsealing needs the token
+	share := appdtos.NewUnsealDTO("", apputils.EncodeSealKeyShare(shares[2]))
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/seal/unseal", "", share).StatusCode)
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/seal/unseal", "wrong", share).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/seal/unseal", "token", appdtos.UnsealDTO{}).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/seal/unseal", "token", appdtos.NewUnsealDTO("", "!")).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/seal/unseal", "token", appdtos.NewUnsealDTO("wrong", "")).StatusCode)
+
+	assert.Equal(t, appdtos.NewSealStatusDTO(true, 2, 1), status(send(http.MethodPost, "/seal/unseal", "token", share)))
+	share = appdtos.NewUnsealDTO("", apputils.EncodeSealKeyShare(shares[0]))
+	assert.Equal(t, appdtos.NewSealStatusDTO(false, 2, 0), status(send(http.MethodPost, "/seal/unseal", "token", share)))
+
+	res := send(http.MethodPost, rootPath, "", rootRequest)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var root appdtos.CertificateDTO
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&root))
+	childPath := rootPath + "/" + root.SerialNumber + "/certificates"
+	serverRequest := appdtos.CertificateRequestDTO{CertificateType: appdtos.ServerCertificate, CommonName: "www.example.com", DnsNames: []string{"www.example.com"}}
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, childPath, "", serverRequest).StatusCode)
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, sshPath, "", appdtos.SshAuthorityRequestDTO{}).StatusCode)
+
+	// Sealing again stops signing, but certificates are still served
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/seal", "", nil).StatusCode)
+	assert.Equal(t, appdtos.NewSealStatusDTO(true, 2, 0), status(send(http.MethodPost, "/seal", "token", nil)))
+	assert.Equal(t, http.StatusServiceUnavailable, send(http.MethodPost, childPath, "", serverRequest).StatusCode)
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, childPath, "", nil).StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, send(http.MethodGet, sshPath, "", nil).StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, send(http.MethodPost, sshPath, "", appdtos.SshAuthorityRequestDTO{}).StatusCode)
+
+	// The passphrase unseals too
+	assert.Equal(t, appdtos.NewSealStatusDTO(false, 2, 0), status(send(http.MethodPost, "/seal/unseal", "token", appdtos.NewUnsealDTO("secret", ""))))
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, childPath, "", serverRequest).StatusCode)
+}
+
+// TestSealing_Protocols checks that the EST and Vault APIs respond 503
+// Service Unavailable while the private keys are sealed
+func TestSealing_Protocols(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	config, _, _, err := apputils.NewSealConfig("secret", 0, 0)
+	require.NoError(t, err)
+	sealController := appcontrollers.NewSealController(config, "")
+	require.NoError(t, sealController.Unseal("secret"))
+	repository := sealedrepository.NewCollection(memoryrepository.NewCollection(), sealController, certManager)
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization := appmodels.NewSerialNumber(10)
+	_, err = appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	root, err := organizationController.NewRootCertificate("Test Root CA")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+
+	estController := appcontrollers.NewEstController(memoryrepository.NewEstLabelRepository(), nil, appController, time.Hour)
+	_, err = estController.NewLabel(rootController, "devices", "user", "secret")
+	require.NoError(t, err)
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	controller.SetEstController(estController)
+	controller.SetVaultController(appcontrollers.NewVaultController(
+		memoryrepository.NewVaultRoleRepository(),
+		memoryrepository.NewCertificateRevocationRepository(),
+		appController,
+		"s.token",
+		time.Hour,
+	))
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	vault := &vaultClient{t: t, baseURL: httpServer.URL + "/v1/pki/" + organization.String(), token: "s.token"}
+	var role appdtos.VaultRoleDTO
+	vault.data(http.MethodPost, "/roles/web", map[string]any{"issuer_ref": root.SerialNumber().String(), "allow_any_name": true}, &role)
+
+	sealController.Seal()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	request := newTestEstRequest(t, http.MethodPost, httpServer.URL+"/.well-known/est/devices/simpleenroll", newTestEstCsr(t, key, "device-1"))
+	request.SetBasicAuth("user", "secret")
+	res, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+	status, errs := vault.errors(http.MethodPost, "/issue/web", map[string]any{"common_name": "www.example.com"})
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, []string{"private keys are sealed"}, errs)
+	status, _ = vault.errors(http.MethodGet, "/crl", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// SealStatusDefinitions returns OpenAPI definitions
+func (c *HttpApiController) SealStatusDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns the state of the seal",
+		Description: "Tells whether the private keys are sealed, and how many key shares have been provided of the threshold",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.SealStatusDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// SealStatus handles a request
+func (c *HttpApiController) SealStatus(response apitypes.Response, request apitypes.Request) error {
+
+	if c.sealController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	return c.ok(response, c.sealStatusDTO())
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).SealStatusDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).SealStatus
@@ -0,0 +1,128 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestOrganizationSearch(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := memoryrepository.NewCollection()
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	server, _, err := rootController.NewServerCertificate("www.example.com")
+	require.NoError(t, err)
+	_, _, err = rootController.NewServerCertificate("api.example.com")
+	require.NoError(t, err)
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	searchPath := httpServer.URL + "/organizations/" + organization.String() + "/search"
+	search := func(query url.Values) ([]string, int) {
+		t.Helper()
+		res, err := http.Get(searchPath + "?" + query.Encode())
+		require.NoError(t, err)
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return nil, res.StatusCode
+		}
+		var dto appdtos.CertificateListDTO
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&dto))
+		names := []string{}
+		for _, cert := range dto.Payload {
+			names = append(names, cert.CommonName)
+		}
+		return names, res.StatusCode
+	}
+
+	cert := server.Certificate()
+	fingerprint := apputils.CertificateFingerprint(server)
+	withColons := func(value []byte) string {
+		var parts []string
+		for _, b := range value {
+			parts = append(parts, fmt.Sprintf("%02X", b))
+		}
+		return strings.Join(parts, ":")
+	}
+
+	tests := []struct {
+		query url.Values
+		want  []string
+	}{
+		{url.Values{"san": {"WWW.EXAMPLE"}}, []string{"www.example.com"}},
+		{url.Values{"cn": {"example.com"}, "sort": {"commonName"}}, []string{"api.example.com", "www.example.com"}},
+		{url.Values{"fingerprint": {fingerprint}}, []string{"www.example.com"}},
+		{url.Values{"ski": {withColons(root.Certificate().SubjectKeyId)}}, []string{"Test Root"}},
+		{url.Values{"aki": {hex.EncodeToString(root.Certificate().SubjectKeyId)}, "sort": {"commonName"}}, []string{"api.example.com", "www.example.com"}},
+		{url.Values{"serial": {server.SerialNumber().String()}}, []string{"www.example.com"}},
+		{url.Values{"serial": {"0x" + server.SerialNumber().Text(16)}}, []string{"www.example.com"}},
+		{url.Values{"keyType": {apputils.CertificateKeyType(cert).String()}, "type": {"root"}}, []string{"Test Root"}},
+		{url.Values{"issuer": {"test root"}, "cn": {"api"}}, []string{"api.example.com"}},
+		{url.Values{"issuedAfter": {time.Now().Add(time.Hour).Format(time.RFC3339)}}, []string{}},
+		{url.Values{"expiringAfter": {time.Now().Format(time.RFC3339)}, "cn": {"www"}}, []string{"www.example.com"}},
+	}
+	for _, tt := range tests {
+		names, status := search(tt.query)
+		require.Equal(t, http.StatusOK, status, tt.query.Encode())
+		assert.Equal(t, tt.want, names, tt.query.Encode())
+	}
+
+	for _, query := range []url.Values{
+		{"fingerprint": {"abcd"}},
+		{"ski": {"xyz"}},
+		{"serial": {"0xg"}},
+		{"keyType": {"DSA"}},
+		{"issuedBefore": {"yesterday"}},
+	} {
+		_, status := search(query)
+		assert.Equal(t, http.StatusBadRequest, status, query.Encode())
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"fmt"
+	"time"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// SpiffeBundleDefinitions returns OpenAPI definitions
+func (c *HttpApiController) SpiffeBundleDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns the SPIFFE trust bundle of an organization",
+		Description: "The bundle is in the SPIFFE bundle format (JWK set) and includes the currently valid root certificates of the organization as X.509 authorities. The organization must have a SPIFFE trust domain.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.SpiffeBundleDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// SpiffeBundle handles a request
+func (c *HttpApiController) SpiffeBundle(response apitypes.Response, request apitypes.Request) error {
+
+	organization, err := c.organizationID(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	organizationController, err := c.appController.OrganizationController(organization)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	if organizationController.Organization().SpiffeTrustDomain() == "" {
+		return c.notFound(response, request, fmt.Errorf("organization %s has no SPIFFE trust domain", organization))
+	}
+
+	list, err := organizationController.CertificateCollection()
+	if err != nil {
+		return c.internalServerError(response, request, err)
+	}
+
+	dto, err := apputils.ToSpiffeBundleDTO(
+		apputils.FilterSpiffeBundleCertificates(list, time.Now()),
+		0,
+		apputils.DefaultSpiffeBundleRefreshHint,
+	)
+	if err != nil {
+		return c.internalServerError(response, request, err)
+	}
+
+	return c.ok(response, dto)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).SpiffeBundleDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).SpiffeBundle
@@ -0,0 +1,138 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestSpiffeServer(t *testing.T) (*httptest.Server, appmodels.ApplicationController) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := filerepository.NewCollection(certManager, managers.NewFileManager(), t.TempDir())
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, appController
+}
+
+func createTestSpiffeOrganization(t *testing.T, server *httptest.Server, trustDomain string) string {
+	status, data := doTestSshRequest(t, http.MethodPost, server.URL+"/organizations", `{"slug":"test","name":"Test Org","spiffeTrustDomain":"`+trustDomain+`"}`)
+	require.Equal(t, http.StatusOK, status, string(data))
+	var created appdtos.OrganizationCreatedDTO
+	require.NoError(t, json.Unmarshal(data, &created))
+	return created.Organization.ID
+}
+
+func TestSpiffe_IssueSvid(t *testing.T) {
+	server, appController := newTestSpiffeServer(t)
+	organization := createTestSpiffeOrganization(t, server, "Example.org")
+	base := server.URL + "/organizations/" + organization
+
+	organizationID, ok := new(big.Int).SetString(organization, 10)
+	require.True(t, ok)
+	organizationController, err := appController.OrganizationController(organizationID)
+	require.NoError(t, err)
+	assert.Equal(t, "example.org", organizationController.Organization().SpiffeTrustDomain())
+	organizationController.SetExpirationDuration(24 * time.Hour)
+	root, err := organizationController.NewRootCertificate("Test Root CA")
+	require.NoError(t, err)
+	rootSerialNumber := root.SerialNumber().String()
+
+	status, data := doTestSshRequest(t, http.MethodPost, base+"/certificates/"+rootSerialNumber+"/certificates", `{"type":"spiffe","spiffeId":"spiffe://other.org/web"}`)
+	assert.Equal(t, http.StatusBadRequest, status, string(data))
+
+	status, data = doTestSshRequest(t, http.MethodPost, base+"/certificates/"+rootSerialNumber+"/certificates", `{"type":"spiffe","spiffeId":"spiffe://example.org/ns/default/sa/web"}`)
+	require.Equal(t, http.StatusOK, status, string(data))
+	var created appdtos.CertificateCreatedDTO
+	require.NoError(t, json.Unmarshal(data, &created))
+	assert.Equal(t, "spiffe://example.org/ns/default/sa/web", created.Certificate.SpiffeID)
+
+	block, _ := pem.Decode([]byte(created.Certificate.Certificate))
+	require.NotNil(t, block)
+	svid, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.Certificate())
+	_, err = svid.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	})
+	assert.NoError(t, err)
+	require.Len(t, svid.URIs, 1)
+	assert.Equal(t, "spiffe://example.org/ns/default/sa/web", svid.URIs[0].String())
+}
+
+func TestSpiffe_BundleWithoutTrustDomain(t *testing.T) {
+	server, _ := newTestSpiffeServer(t)
+	organization := createTestSpiffeOrganization(t, server, "")
+
+	status, _ := doTestSshRequest(t, http.MethodGet, server.URL+"/organizations/"+organization+"/spiffe/bundle", "")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestSpiffe_InvalidTrustDomain(t *testing.T) {
+	server, _ := newTestSpiffeServer(t)
+
+	status, _ := doTestSshRequest(t, http.MethodPost, server.URL+"/organizations", `{"slug":"test","name":"Test Org","spiffeTrustDomain":"example.org:443"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestSpiffe_Bundle(t *testing.T) {
+	server, appController := newTestSpiffeServer(t)
+	organization := createTestSpiffeOrganization(t, server, "example.org")
+
+	organizationID, ok := new(big.Int).SetString(organization, 10)
+	require.True(t, ok)
+	organizationController, err := appController.OrganizationController(organizationID)
+	require.NoError(t, err)
+	organizationController.SetExpirationDuration(24 * time.Hour)
+	root, err := organizationController.NewRootCertificate("Test Root CA")
+	require.NoError(t, err)
+
+	status, data := doTestSshRequest(t, http.MethodGet, server.URL+"/organizations/"+organization+"/spiffe/bundle", "")
+	require.Equal(t, http.StatusOK, status, string(data))
+	var bundle appdtos.SpiffeBundleDTO
+	require.NoError(t, json.Unmarshal(data, &bundle))
+	require.Len(t, bundle.Keys, 1)
+	assert.Equal(t, "x509-svid", bundle.Keys[0].Use)
+	require.Len(t, bundle.Keys[0].X5c, 1)
+	der, err := base64.StdEncoding.DecodeString(bundle.Keys[0].X5c[0])
+	require.NoError(t, err)
+	assert.Equal(t, root.Certificate().Raw, der)
+}
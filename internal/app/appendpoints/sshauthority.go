@@ -0,0 +1,53 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// SshAuthorityDefinitions returns OpenAPI definitions
+func (c *HttpApiController) SshAuthorityDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns the SSH certificate authority of an organization",
+		Description: "",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.SshAuthorityDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// SshAuthority handles a request
+func (c *HttpApiController) SshAuthority(response apitypes.Response, request apitypes.Request) error {
+
+	if c.sshController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.organizationID(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	authority, err := c.sshController.Authority(organization)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	dto, err := apputils.ToSshAuthorityDTO(authority)
+	if err != nil {
+		return c.internalServerError(response, request, err)
+	}
+	return c.ok(response, dto)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).SshAuthorityDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).SshAuthority
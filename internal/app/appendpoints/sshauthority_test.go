@@ -0,0 +1,180 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appendpoints"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apimocks"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apiserver"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestSshServer(t *testing.T, enabled bool) *httptest.Server {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := filerepository.NewCollection(certManager, managers.NewFileManager(), t.TempDir())
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(appmodels.NewSerialNumber(10), "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+
+	controller := appendpoints.NewHttpApiController(apimocks.NewMockServer(), appController, certManager)
+	if enabled {
+		controller.SetSshController(appcontrollers.NewSshController(
+			memoryrepository.NewSshAuthorityRepository(),
+			memoryrepository.NewSshCertificateRepository(),
+			randomManager,
+			time.Hour,
+		))
+	}
+
+	router := mux.NewRouter()
+	for _, route := range controller.Routes() {
+		router.HandleFunc(route.Path, apiserver.ResponseHandler(route.Handler)).Methods(route.Method)
+	}
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func doTestSshRequest(t *testing.T, method, url string, body string) (int, []byte) {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer func() { _ = res.Body.Close() }()
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, data
+}
+
+// TestSsh_Client runs the SSH CA flow from creating the CA to revoking a
+// certificate
+func TestSsh_Client(t *testing.T) {
+	server := newTestSshServer(t, true)
+	baseURL := server.URL + "/organizations/10/ssh"
+
+	status, _ := doTestSshRequest(t, http.MethodGet, baseURL, "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = doTestSshRequest(t, http.MethodPost, baseURL, `{"keyType":"invalid"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, body := doTestSshRequest(t, http.MethodPost, baseURL, `{}`)
+	require.Equal(t, http.StatusOK, status, string(body))
+	var authority appdtos.SshAuthorityDTO
+	require.NoError(t, json.Unmarshal(body, &authority))
+	assert.Equal(t, "10", authority.Organization)
+	assert.Equal(t, "Ed25519", authority.KeyType)
+
+	status, _ = doTestSshRequest(t, http.MethodPost, baseURL, `{}`)
+	assert.Equal(t, http.StatusConflict, status)
+
+	caKey, err := apputils.ParseSshPublicKey(authority.PublicKey)
+	require.NoError(t, err)
+
+	status, body = doTestSshRequest(t, http.MethodGet, baseURL+"/ca.pub?format=known_hosts&hosts=*.example.com", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "@cert-authority *.example.com "+authority.PublicKey+"\n", string(body))
+
+	status, body = doTestSshRequest(t, http.MethodGet, baseURL+"/ca.pub?format=authorized_keys", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "cert-authority "+authority.PublicKey+"\n", string(body))
+
+	status, _ = doTestSshRequest(t, http.MethodGet, baseURL+"/ca.pub?format=other", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	require.NoError(t, err)
+	request, err := json.Marshal(appdtos.NewSshCertificateRequestDTO(
+		"user",
+		apputils.FormatSshPublicKey(sshPublicKey),
+		"alice@example.com",
+		[]string{"alice"},
+		60,
+		map[string]string{"force-command": "/usr/bin/true"},
+		nil,
+	))
+	require.NoError(t, err)
+
+	status, body = doTestSshRequest(t, http.MethodPost, baseURL+"/certificates", string(request))
+	require.Equal(t, http.StatusOK, status, string(body))
+	var certificate appdtos.SshCertificateDTO
+	require.NoError(t, json.Unmarshal(body, &certificate))
+	assert.Equal(t, "user", certificate.Type)
+	assert.False(t, certificate.Revoked)
+
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(certificate.Certificate))
+	require.NoError(t, err)
+	checker := &ssh.CertChecker{
+		SupportedCriticalOptions: []string{"force-command"},
+		IsUserAuthority: func(auth ssh.PublicKey) bool {
+			return bytes.Equal(auth.Marshal(), caKey.Marshal())
+		},
+	}
+	assert.NoError(t, checker.CheckCert("alice", parsed.(*ssh.Certificate)))
+	assert.Equal(t, "/usr/bin/true", parsed.(*ssh.Certificate).CriticalOptions["force-command"])
+
+	status, _ = doTestSshRequest(t, http.MethodPost, baseURL+"/certificates", `{"type":"user","publicKey":"invalid"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, body = doTestSshRequest(t, http.MethodGet, baseURL+"/certificates", "")
+	require.Equal(t, http.StatusOK, status)
+	var list appdtos.SshCertificateListDTO
+	require.NoError(t, json.Unmarshal(body, &list))
+	require.Len(t, list.Payload, 1)
+
+	status, body = doTestSshRequest(t, http.MethodDelete, baseURL+"/certificates/"+certificate.Serial, "")
+	require.Equal(t, http.StatusOK, status)
+	require.NoError(t, json.Unmarshal(body, &certificate))
+	assert.True(t, certificate.Revoked)
+
+	status, _ = doTestSshRequest(t, http.MethodGet, baseURL+"/certificates/1", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, body = doTestSshRequest(t, http.MethodGet, baseURL+"/krl", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, bytes.HasPrefix(body, []byte(apputils.SshKrlMagic)))
+	serial, err := strconv.ParseUint(certificate.Serial, 10, 64)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasSuffix(body, ssh.Marshal(struct{ Serial uint64 }{serial})))
+}
+
+func TestSsh_DisabledWithoutController(t *testing.T) {
+	server := newTestSshServer(t, false)
+	status, _ := doTestSshRequest(t, http.MethodPost, server.URL+"/organizations/10/ssh", `{}`)
+	assert.Equal(t, http.StatusNotFound, status)
+}
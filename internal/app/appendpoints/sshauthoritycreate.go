@@ -0,0 +1,77 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"encoding/json"
+	"fmt"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// CreateSshAuthorityDefinitions returns OpenAPI definitions
+func (c *HttpApiController) CreateSshAuthorityDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Creates the SSH certificate authority of an organization",
+		Description: "Generates the SSH CA key which signs user and host certificates. An organization has only one SSH CA.",
+		RequestBody: &swagger.ContentValue{
+			Description: "SSH certificate authority request data",
+			Content: swagger.Content{
+				"application/json": {
+					Value: appdtos.SshAuthorityRequestDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.SshAuthorityDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// CreateSshAuthority handles a request
+func (c *HttpApiController) CreateSshAuthority(response apitypes.Response, request apitypes.Request) error {
+
+	if c.sshController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.organizationID(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	var body appdtos.SshAuthorityRequestDTO
+	if err := json.NewDecoder(request.Body()).Decode(&body); err != nil {
+		return c.badRequest(response, request, "body invalid", err)
+	}
+	keyType, err := apputils.ParseKeyType(body.KeyType)
+	if err != nil {
+		return c.badRequest(response, request, "keyType invalid", err)
+	}
+
+	if _, err := c.sshController.Authority(organization); err == nil {
+		return c.conflict(response, request, fmt.Errorf("organization %s already has an SSH CA", organization), "SSH CA exists")
+	}
+
+	authority, err := c.sshController.NewAuthority(organization, keyType)
+	if err != nil {
+		return c.badRequest(response, request, "keyType invalid", err)
+	}
+
+	dto, err := apputils.ToSshAuthorityDTO(authority)
+	if err != nil {
+		return c.internalServerError(response, request, err)
+	}
+	return c.ok(response, dto)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).CreateSshAuthorityDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).CreateSshAuthority
@@ -0,0 +1,73 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"fmt"
+	"strings"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// SshAuthorityPublicKeyDefinitions returns OpenAPI definitions
+func (c *HttpApiController) SshAuthorityPublicKeyDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns the public key of the SSH certificate authority",
+		Description: "Without the format query parameter the key is returned as is, e.g. for TrustedUserCAKeys in sshd_config. The format \"authorized_keys\" returns a cert-authority line and \"known_hosts\" returns a @cert-authority line for the comma separated host patterns in the hosts query parameter.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"text/plain": {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// SshAuthorityPublicKey handles a request
+func (c *HttpApiController) SshAuthorityPublicKey(response apitypes.Response, request apitypes.Request) error {
+
+	if c.sshController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.organizationID(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	authority, err := c.sshController.Authority(organization)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	signer, err := apputils.NewSshSigner(authority.PrivateKey())
+	if err != nil {
+		return c.internalServerError(response, request, err)
+	}
+
+	var line string
+	switch format := request.QueryParam("format"); format {
+	case "":
+		line = apputils.FormatSshPublicKey(signer.PublicKey())
+	case "authorized_keys":
+		line = apputils.FormatSshAuthorizedKeysAuthority(signer.PublicKey())
+	case "known_hosts":
+		var hosts []string
+		if value := request.QueryParam("hosts"); value != "" {
+			hosts = strings.Split(value, ",")
+		}
+		line = apputils.FormatSshKnownHostsAuthority(signer.PublicKey(), hosts)
+	default:
+		return c.badRequest(response, request, "format invalid", fmt.Errorf("unsupported format: '%s'", format))
+	}
+
+	response.SetHeader("Content-Type", "text/plain")
+	return response.SendBytes([]byte(line + "\n"))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).SshAuthorityPublicKeyDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).SshAuthorityPublicKey
@@ -0,0 +1,54 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// SshCertificateDefinitions returns OpenAPI definitions
+func (c *HttpApiController) SshCertificateDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns an SSH certificate signed by the SSH CA of an organization",
+		Description: "",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.SshCertificateDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// SshCertificate handles a request
+func (c *HttpApiController) SshCertificate(response apitypes.Response, request apitypes.Request) error {
+
+	if c.sshController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.organizationID(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	serial, err := c.sshSerial(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	certificate, err := c.sshController.Certificate(organization, serial)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	return c.ok(response, apputils.ToSshCertificateDTO(certificate))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).SshCertificateDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).SshCertificate
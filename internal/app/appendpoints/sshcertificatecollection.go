@@ -0,0 +1,49 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// SshCertificateCollectionDefinitions returns OpenAPI definitions
+func (c *HttpApiController) SshCertificateCollectionDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns SSH certificates signed by the SSH CA of an organization",
+		Description: "",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.SshCertificateListDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// SshCertificateCollection handles a request
+func (c *HttpApiController) SshCertificateCollection(response apitypes.Response, request apitypes.Request) error {
+
+	if c.sshController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.organizationID(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	list, err := c.sshController.Certificates(organization)
+	if err != nil {
+		return c.internalServerError(response, request, err)
+	}
+
+	return c.ok(response, apputils.ToSshCertificateListDTO(list))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).SshCertificateCollectionDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).SshCertificateCollection
@@ -0,0 +1,92 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"encoding/json"
+	"time"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// CreateSshCertificateDefinitions returns OpenAPI definitions
+func (c *HttpApiController) CreateSshCertificateDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Signs an SSH certificate",
+		Description: "Signs a user or host certificate for the public key with the SSH CA of the organization.",
+		RequestBody: &swagger.ContentValue{
+			Description: "SSH certificate request data",
+			Content: swagger.Content{
+				"application/json": {
+					Value: appdtos.SshCertificateRequestDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.SshCertificateDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// CreateSshCertificate handles a request
+func (c *HttpApiController) CreateSshCertificate(response apitypes.Response, request apitypes.Request) error {
+
+	if c.sshController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.organizationID(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	if _, err := c.sshController.Authority(organization); err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	var body appdtos.SshCertificateRequestDTO
+	if err := json.NewDecoder(request.Body()).Decode(&body); err != nil {
+		return c.badRequest(response, request, "body invalid", err)
+	}
+
+	certificateType, err := apputils.ParseSshCertificateType(body.Type)
+	if err != nil {
+		return c.badRequest(response, request, "type invalid", err)
+	}
+
+	publicKey, err := apputils.ParseSshPublicKey(body.PublicKey)
+	if err != nil {
+		return c.badRequest(response, request, "publicKey invalid", err)
+	}
+
+	if body.Expiration < 0 {
+		return c.badRequest(response, request, "expiration invalid", nil)
+	}
+
+	certificate, err := c.sshController.Sign(
+		organization,
+		publicKey,
+		certificateType,
+		body.KeyID,
+		body.Principals,
+		time.Duration(body.Expiration)*time.Minute,
+		body.CriticalOptions,
+		body.Extensions,
+	)
+	if err != nil {
+		return c.badRequest(response, request, "request invalid", err)
+	}
+
+	return c.ok(response, apputils.ToSshCertificateDTO(certificate))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).CreateSshCertificateDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).CreateSshCertificate
@@ -0,0 +1,58 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// RevokeSshCertificateDefinitions returns OpenAPI definitions
+func (c *HttpApiController) RevokeSshCertificateDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Revokes an SSH certificate",
+		Description: "The certificate is added to the key revocation list of the organization.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.SshCertificateDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// RevokeSshCertificate handles a request
+func (c *HttpApiController) RevokeSshCertificate(response apitypes.Response, request apitypes.Request) error {
+
+	if c.sshController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.organizationID(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	serial, err := c.sshSerial(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	if _, err := c.sshController.Certificate(organization, serial); err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	certificate, err := c.sshController.Revoke(organization, serial)
+	if err != nil {
+		return c.internalServerError(response, request, err)
+	}
+
+	return c.ok(response, apputils.ToSshCertificateDTO(certificate))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).RevokeSshCertificateDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).RevokeSshCertificate
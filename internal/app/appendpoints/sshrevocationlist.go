@@ -0,0 +1,52 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// SshRevocationListDefinitions returns OpenAPI definitions
+func (c *HttpApiController) SshRevocationListDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns the SSH key revocation list of an organization",
+		Description: "The list is in the OpenSSH KRL format used by RevokedKeys in sshd_config.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/octet-stream": {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// SshRevocationList handles a request
+func (c *HttpApiController) SshRevocationList(response apitypes.Response, request apitypes.Request) error {
+
+	if c.sshController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.organizationID(request)
+	if err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	if _, err := c.sshController.Authority(organization); err != nil {
+		return c.notFound(response, request, err)
+	}
+
+	krl, err := c.sshController.RevocationList(organization)
+	if err != nil {
+		return c.internalServerError(response, request, err)
+	}
+
+	response.SetHeader("Content-Type", "application/octet-stream")
+	return response.SendBytes(krl)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).SshRevocationListDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).SshRevocationList
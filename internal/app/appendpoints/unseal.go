@@ -0,0 +1,71 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"errors"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// UnsealDefinitions returns OpenAPI definitions
+func (c *HttpApiController) UnsealDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Unseals the private keys",
+		Description: "Unseals with the passphrase, or adds a key share. The private keys are unsealed once the threshold of key shares has been provided. The " + SealTokenHeader + " header must contain the seal token.",
+		RequestBody: &swagger.ContentValue{
+			Content: swagger.Content{
+				"application/json": {Value: appdtos.UnsealDTO{}},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.SealStatusDTO{}},
+				},
+			},
+		},
+	}
+}
+
+// Unseal handles a request
+func (c *HttpApiController) Unseal(response apitypes.Response, request apitypes.Request) error {
+
+	if c.sealController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	if err := c.sealController.Authenticate(request.Header(SealTokenHeader)); err != nil {
+		return c.forbidden(response, request, err)
+	}
+
+	body, err := c.DecodeUnsealFromRequestBody(request)
+	if err != nil {
+		return c.badRequest(response, request, "body invalid", err)
+	}
+
+	switch {
+	case body.Passphrase != "" && body.Share == "":
+		err = c.sealController.Unseal(body.Passphrase)
+	case body.Share != "" && body.Passphrase == "":
+		share, decodeErr := apputils.DecodeSealKeyShare(body.Share)
+		if decodeErr != nil {
+			return c.badRequest(response, request, "share invalid", decodeErr)
+		}
+		err = c.sealController.UnsealShare(share)
+	default:
+		err = errors.New("either passphrase or share must be defined")
+	}
+	if err != nil {
+		return c.badRequest(response, request, "unseal failed", err)
+	}
+
+	return c.ok(response, c.sealStatusDTO())
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).UnsealDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).Unseal
@@ -0,0 +1,186 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// VaultTokenHeader is the header of Vault client tokens
+const VaultTokenHeader = "X-Vault-Token"
+
+// VaultCrlContentType is the content type of DER encoded CRLs
+const VaultCrlContentType = "application/pkix-crl"
+
+// VaultCertContentType is the content type of DER encoded certificates
+const VaultCertContentType = "application/pkix-cert"
+
+// VaultPemContentType is the content type of PEM encoded certificates and
+// CRLs
+const VaultPemContentType = "application/pem-certificate-chain"
+
+// VaultDefaultIssuer is the issuer reference of the newest root certificate
+const VaultDefaultIssuer = "default"
+
+// vaultError sends a Vault error response. Errors which are not
+// *appmodels.VaultError are logged and reported as internal errors, and
+// sealed private keys as 503 Service Unavailable.
+func (c *HttpApiController) vaultError(response apitypes.Response, request apitypes.Request, err error) error {
+	if errors.Is(err, appmodels.ErrSealed) {
+		log.Printf("[%s %s]: %v", request.Method(), request.URL(), err)
+		response.Send(http.StatusServiceUnavailable, appdtos.NewVaultErrorDTO([]string{"private keys are sealed"}))
+		return nil
+	}
+	var vaultErr *appmodels.VaultError
+	if !errors.As(err, &vaultErr) {
+		log.Printf("[%s %s]: Internal Server Error: %v", request.Method(), request.URL(), err)
+		vaultErr = appmodels.NewVaultError(appmodels.VAULT_ERROR_SERVER_INTERNAL, "internal server error")
+	} else {
+		c.logf(request, "Vault error: %v", vaultErr)
+	}
+	response.Send(apputils.VaultErrorStatusCode(vaultErr.Type()), appdtos.NewVaultErrorDTO([]string{vaultErr.Detail()}))
+	return nil
+}
+
+// vaultOk sends a Vault response with data
+func (c *HttpApiController) vaultOk(response apitypes.Response, data any) error {
+	response.Send(http.StatusOK, appdtos.NewVaultResponseDTO(apputils.NewVaultRequestID(), data))
+	return nil
+}
+
+// vaultOrganization authenticates the request with the Vault token and
+// returns the organization of the mount. Public end-points do not require a
+// token.
+func (c *HttpApiController) vaultOrganization(request apitypes.Request, public bool) (*big.Int, error) {
+	if !public {
+		if err := c.vaultController.Authenticate(request.Header(VaultTokenHeader)); err != nil {
+			return nil, err
+		}
+	}
+	organization, err := c.organizationID(request)
+	if err != nil {
+		log.Printf("%v", err)
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_NOT_FOUND, "no handler for route %q", request.URL().Path)
+	}
+	return organization, nil
+}
+
+// vaultIssuer returns the issuer of the "issuer" path variable, or the
+// default issuer when the variable is not defined. Issuers are public and
+// the Vault token is not required.
+func (c *HttpApiController) vaultIssuer(request apitypes.Request) (appmodels.CertificateController, error) {
+	organization, err := c.vaultOrganization(request, true)
+	if err != nil {
+		return nil, err
+	}
+	return c.vaultIssuerRef(organization, request.Variable("issuer"))
+}
+
+// vaultIssuerRef returns the issuer by a serial number, or the default
+// issuer for "default" and an empty reference
+func (c *HttpApiController) vaultIssuerRef(organization *big.Int, ref string) (appmodels.CertificateController, error) {
+	if ref == "" || ref == VaultDefaultIssuer {
+		return c.vaultController.DefaultIssuer(organization)
+	}
+	serialNumber, err := apputils.ParseBigInt(ref, 10)
+	if err != nil {
+		return nil, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "invalid issuer reference: %s", ref)
+	}
+	return c.vaultController.Issuer(organization, serialNumber)
+}
+
+// vaultDecode decodes a JSON request body. An empty body is accepted.
+func (c *HttpApiController) vaultDecode(request apitypes.Request, body any) error {
+	if err := json.NewDecoder(request.Body()).Decode(body); err != nil && !errors.Is(err, io.EOF) {
+		return appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "failed to parse JSON input: %v", err)
+	}
+	return nil
+}
+
+// vaultSendBytes sends a raw response with a content type
+func (c *HttpApiController) vaultSendBytes(response apitypes.Response, contentType string, data []byte) error {
+	response.SetHeader("Content-Type", contentType)
+	return response.SendBytes(data)
+}
+
+// vaultPem encodes DER bytes as PEM
+func vaultPem(blockType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+// vaultIssueOrSign handles the issue and sign operations
+func (c *HttpApiController) vaultIssueOrSign(response apitypes.Response, request apitypes.Request, sign bool) error {
+
+	if c.vaultController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.vaultOrganization(request, false)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	role, err := c.vaultController.Role(organization, request.Variable("role"))
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	var body appdtos.VaultCertificateRequestDTO
+	if err := c.vaultDecode(request, &body); err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	if body.Format != "" && body.Format != "pem" {
+		return c.vaultError(response, request, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "unsupported format: %s", body.Format))
+	}
+
+	ttl, err := apputils.ParseVaultDuration(body.TTL)
+	if err != nil {
+		return c.vaultError(response, request, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "ttl: %v", err))
+	}
+
+	altNames, err := apputils.ParseVaultStringList(body.AltNames)
+	if err != nil {
+		return c.vaultError(response, request, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "alt_names: %v", err))
+	}
+
+	var certificate appmodels.Certificate
+	var privateKey appmodels.PrivateKey
+	if sign {
+		csr, err := apputils.ParseVaultCsr(body.Csr)
+		if err != nil {
+			return c.vaultError(response, request, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "csr: %v", err))
+		}
+		certificate, err = c.vaultController.Sign(role, csr, body.CommonName, altNames, ttl)
+		if err != nil {
+			return c.vaultError(response, request, err)
+		}
+	} else {
+		certificate, privateKey, err = c.vaultController.Issue(role, body.CommonName, altNames, ttl)
+		if err != nil {
+			return c.vaultError(response, request, err)
+		}
+	}
+
+	issuer, err := c.vaultController.Issuer(role.OrganizationID(), role.IssuerSerialNumber())
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	dto, err := apputils.ToVaultCertificateDTO(c.certManager, certificate, privateKey, c.vaultController.CACertificates(issuer))
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+	return c.vaultOk(response, dto)
+}
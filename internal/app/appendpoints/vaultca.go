@@ -0,0 +1,42 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// VaultCADefinitions returns OpenAPI definitions
+func (c *HttpApiController) VaultCADefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns a Vault PKI issuer certificate in DER format",
+		Description: "Returns the issuing certificate. Without an issuer reference the newest root certificate of the organization is returned. No token is required.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					VaultCertContentType: {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// VaultCA handles a request
+func (c *HttpApiController) VaultCA(response apitypes.Response, request apitypes.Request) error {
+
+	if c.vaultController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.vaultIssuer(request)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	return c.vaultSendBytes(response, VaultCertContentType, issuer.Certificate().Certificate().Raw)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).VaultCADefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).VaultCA
@@ -0,0 +1,46 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// VaultCAChainDefinitions returns OpenAPI definitions
+func (c *HttpApiController) VaultCAChainDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns a Vault PKI issuer certificate chain in PEM format",
+		Description: "Returns the issuing certificate followed by its parents. No token is required.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					VaultPemContentType: {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// VaultCAChain handles a request
+func (c *HttpApiController) VaultCAChain(response apitypes.Response, request apitypes.Request) error {
+
+	if c.vaultController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.vaultIssuer(request)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	var data []byte
+	for _, certificate := range c.vaultController.CACertificates(issuer) {
+		data = append(data, vaultPem("CERTIFICATE", certificate.Certificate().Raw)...)
+	}
+	return c.vaultSendBytes(response, VaultPemContentType, data)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).VaultCAChainDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).VaultCAChain
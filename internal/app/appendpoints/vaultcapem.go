@@ -0,0 +1,42 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// VaultCAPemDefinitions returns OpenAPI definitions
+func (c *HttpApiController) VaultCAPemDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns a Vault PKI issuer certificate in PEM format",
+		Description: "Returns the issuing certificate. Without an issuer reference the newest root certificate of the organization is returned. No token is required.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					VaultPemContentType: {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// VaultCAPem handles a request
+func (c *HttpApiController) VaultCAPem(response apitypes.Response, request apitypes.Request) error {
+
+	if c.vaultController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.vaultIssuer(request)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	return c.vaultSendBytes(response, VaultPemContentType, vaultPem("CERTIFICATE", issuer.Certificate().Certificate().Raw))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).VaultCAPemDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).VaultCAPem
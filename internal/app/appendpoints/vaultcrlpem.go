@@ -0,0 +1,46 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// VaultCrlPemDefinitions returns OpenAPI definitions
+func (c *HttpApiController) VaultCrlPemDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns a Vault PKI certificate revocation list in PEM format",
+		Description: "Returns a CRL signed by the issuer. Without an issuer reference the CRL of the newest root certificate of the organization is returned. No token is required.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					VaultPemContentType: {Value: ""},
+				},
+			},
+		},
+	}
+}
+
+// VaultCrlPem handles a request
+func (c *HttpApiController) VaultCrlPem(response apitypes.Response, request apitypes.Request) error {
+
+	if c.vaultController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	issuer, err := c.vaultIssuer(request)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	der, err := c.vaultController.RevocationList(issuer)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+	return c.vaultSendBytes(response, VaultPemContentType, vaultPem("X509 CRL", der))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).VaultCrlPemDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).VaultCrlPem
@@ -0,0 +1,41 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// VaultIssueDefinitions returns OpenAPI definitions
+func (c *HttpApiController) VaultIssueDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Issues a certificate with a Vault PKI role",
+		Description: "Issues a certificate and a new private key with the issuer of the role. Roles with server_flag issue server certificates for the common name and alt_names, and others client certificates for the common name.",
+		RequestBody: &swagger.ContentValue{
+			Description: "Vault certificate request data",
+			Content: swagger.Content{
+				"application/json": {
+					Value: appdtos.VaultCertificateRequestDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.VaultResponseDTO{Data: appdtos.VaultCertificateDTO{}}},
+				},
+			},
+		},
+	}
+}
+
+// VaultIssue handles a request
+func (c *HttpApiController) VaultIssue(response apitypes.Response, request apitypes.Request) error {
+	return c.vaultIssueOrSign(response, request, false)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).VaultIssueDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).VaultIssue
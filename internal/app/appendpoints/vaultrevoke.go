@@ -0,0 +1,68 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// VaultRevokeDefinitions returns OpenAPI definitions
+func (c *HttpApiController) VaultRevokeDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Revokes a certificate with the Vault PKI API",
+		Description: "Revokes a certificate of the organization by its colon separated hex serial number. The certificate is added to the CRL of its issuer.",
+		RequestBody: &swagger.ContentValue{
+			Description: "Vault revoke request data",
+			Content: swagger.Content{
+				"application/json": {
+					Value: appdtos.VaultRevokeRequestDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.VaultResponseDTO{Data: appdtos.VaultRevokeDTO{}}},
+				},
+			},
+		},
+	}
+}
+
+// VaultRevoke handles a request
+func (c *HttpApiController) VaultRevoke(response apitypes.Response, request apitypes.Request) error {
+
+	if c.vaultController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.vaultOrganization(request, false)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	var body appdtos.VaultRevokeRequestDTO
+	if err := c.vaultDecode(request, &body); err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	serialNumber, err := apputils.ParseVaultSerialNumber(body.SerialNumber)
+	if err != nil {
+		return c.vaultError(response, request, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "serial_number: %v", err))
+	}
+
+	revocation, err := c.vaultController.Revoke(organization, serialNumber)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	return c.vaultOk(response, apputils.ToVaultRevokeDTO(revocation))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).VaultRevokeDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).VaultRevoke
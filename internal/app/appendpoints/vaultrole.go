@@ -0,0 +1,49 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// VaultRoleDefinitions returns OpenAPI definitions
+func (c *HttpApiController) VaultRoleDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Returns a Vault PKI role",
+		Description: "Reads a role of the Vault PKI secrets engine API. The organization is the mount of the engine.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.VaultResponseDTO{Data: appdtos.VaultRoleDTO{}}},
+				},
+			},
+		},
+	}
+}
+
+// VaultRole handles a request
+func (c *HttpApiController) VaultRole(response apitypes.Response, request apitypes.Request) error {
+
+	if c.vaultController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.vaultOrganization(request, false)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	role, err := c.vaultController.Role(organization, request.Variable("role"))
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	return c.vaultOk(response, apputils.ToVaultRoleDTO(role))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).VaultRoleDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).VaultRole
@@ -0,0 +1,52 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// VaultRoleCollectionDefinitions returns OpenAPI definitions
+func (c *HttpApiController) VaultRoleCollectionDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Lists Vault PKI roles",
+		Description: "Lists the role names of the organization. Vault clients send LIST requests as GET requests with the query parameter list=true.",
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.VaultResponseDTO{Data: appdtos.VaultListDTO{}}},
+				},
+			},
+		},
+	}
+}
+
+// VaultRoleCollection handles a request
+func (c *HttpApiController) VaultRoleCollection(response apitypes.Response, request apitypes.Request) error {
+
+	if c.vaultController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.vaultOrganization(request, false)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	roles, err := c.vaultController.Roles(organization)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	keys := make([]string, len(roles))
+	for i, role := range roles {
+		keys[i] = role.Name()
+	}
+	return c.vaultOk(response, appdtos.NewVaultListDTO(keys))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).VaultRoleCollectionDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).VaultRoleCollection
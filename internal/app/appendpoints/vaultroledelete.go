@@ -0,0 +1,45 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	"net/http"
+
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// DeleteVaultRoleDefinitions returns OpenAPI definitions
+func (c *HttpApiController) DeleteVaultRoleDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Deletes a Vault PKI role",
+		Description: "Deletes a role of the Vault PKI secrets engine API. Certificates issued with the role are not revoked.",
+		Responses: map[int]swagger.ContentValue{
+			204: {},
+		},
+	}
+}
+
+// DeleteVaultRole handles a request
+func (c *HttpApiController) DeleteVaultRole(response apitypes.Response, request apitypes.Request) error {
+
+	if c.vaultController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.vaultOrganization(request, false)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	if err := c.vaultController.DeleteRole(organization, request.Variable("role")); err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	response.SendStatus(http.StatusNoContent)
+	return nil
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).DeleteVaultRoleDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).DeleteVaultRole
@@ -0,0 +1,105 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// SaveVaultRoleDefinitions returns OpenAPI definitions
+func (c *HttpApiController) SaveVaultRoleDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Creates or updates a Vault PKI role",
+		Description: "Writes a role of the Vault PKI secrets engine API. The issuer_ref is the serial number of a CA certificate of the organization, or \"default\" for the newest root certificate.",
+		RequestBody: &swagger.ContentValue{
+			Description: "Vault role data",
+			Content: swagger.Content{
+				"application/json": {
+					Value: appdtos.VaultRoleRequestDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.VaultResponseDTO{Data: appdtos.VaultRoleDTO{}}},
+				},
+			},
+		},
+	}
+}
+
+// SaveVaultRole handles a request
+func (c *HttpApiController) SaveVaultRole(response apitypes.Response, request apitypes.Request) error {
+
+	if c.vaultController == nil {
+		return c.notFound(response, request, nil)
+	}
+
+	organization, err := c.vaultOrganization(request, false)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	var body appdtos.VaultRoleRequestDTO
+	if err := c.vaultDecode(request, &body); err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	allowedDomains, err := apputils.ParseVaultStringList(body.AllowedDomains)
+	if err != nil {
+		return c.vaultError(response, request, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "allowed_domains: %v", err))
+	}
+
+	keyType, err := apputils.ParseVaultKeyType(body.KeyType, body.KeyBits)
+	if err != nil {
+		return c.vaultError(response, request, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "key_type: %v", err))
+	}
+
+	ttl, err := apputils.ParseVaultDuration(body.TTL)
+	if err != nil {
+		return c.vaultError(response, request, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "ttl: %v", err))
+	}
+
+	maxTTL, err := apputils.ParseVaultDuration(body.MaxTTL)
+	if err != nil {
+		return c.vaultError(response, request, appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "max_ttl: %v", err))
+	}
+
+	issuer, err := c.vaultIssuerRef(organization, body.IssuerRef)
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	serverFlag := true
+	if body.ServerFlag != nil {
+		serverFlag = *body.ServerFlag
+	}
+
+	role, err := c.vaultController.SaveRole(appmodels.NewVaultRole(
+		request.Variable("role"),
+		organization,
+		issuer.Certificate().SerialNumber(),
+		allowedDomains,
+		body.AllowSubdomains,
+		body.AllowBareDomains,
+		body.AllowAnyName,
+		serverFlag,
+		keyType,
+		ttl,
+		maxTTL,
+	))
+	if err != nil {
+		return c.vaultError(response, request, err)
+	}
+
+	return c.vaultOk(response, apputils.ToVaultRoleDTO(role))
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).SaveVaultRoleDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).SaveVaultRole
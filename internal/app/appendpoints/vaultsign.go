@@ -0,0 +1,41 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appendpoints
+
+import (
+	swagger "github.com/davidebianchi/gswagger"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/common/api/apitypes"
+)
+
+// VaultSignDefinitions returns OpenAPI definitions
+func (c *HttpApiController) VaultSignDefinitions() swagger.Definitions {
+	return swagger.Definitions{
+		Summary:     "Signs a certificate signing request with a Vault PKI role",
+		Description: "Issues a certificate for a PEM encoded PKCS #10 request with the issuer of the role. The common name defaults to the one in the request.",
+		RequestBody: &swagger.ContentValue{
+			Description: "Vault certificate request data",
+			Content: swagger.Content{
+				"application/json": {
+					Value: appdtos.VaultCertificateRequestDTO{},
+				},
+			},
+		},
+		Responses: map[int]swagger.ContentValue{
+			200: {
+				Content: swagger.Content{
+					"application/json": {Value: appdtos.VaultResponseDTO{Data: appdtos.VaultCertificateDTO{}}},
+				},
+			},
+		},
+	}
+}
+
+// VaultSign handles a request
+func (c *HttpApiController) VaultSign(response apitypes.Response, request apitypes.Request) error {
+	return c.vaultIssueOrSign(response, request, true)
+}
+
+var _ apitypes.RequestDefinitionsFunc = (*HttpApiController)(nil).VaultSignDefinitions
+var _ apitypes.RequestHandlerFunc = (*HttpApiController)(nil).VaultSign
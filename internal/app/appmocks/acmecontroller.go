@@ -0,0 +1,116 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmocks
+
+import (
+	"crypto/x509"
+	"math/big"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MockAcmeController is a mock implementation of appmodels.AcmeController for testing purposes.
+type MockAcmeController struct {
+	mock.Mock
+}
+
+func (m *MockAcmeController) NewNonce() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAcmeController) UseNonce(nonce string) error {
+	args := m.Called(nonce)
+	return args.Error(0)
+}
+
+func (m *MockAcmeController) Account(issuer appmodels.CertificateController, id string) (appmodels.AcmeAccount, error) {
+	args := m.Called(issuer, id)
+	return mockAcmeAccount(args.Get(0)), args.Error(1)
+}
+
+func (m *MockAcmeController) AccountByKey(issuer appmodels.CertificateController, publicKey any) (appmodels.AcmeAccount, error) {
+	args := m.Called(issuer, publicKey)
+	return mockAcmeAccount(args.Get(0)), args.Error(1)
+}
+
+func (m *MockAcmeController) NewAccount(issuer appmodels.CertificateController, publicKey any, contact []string) (appmodels.AcmeAccount, error) {
+	args := m.Called(issuer, publicKey, contact)
+	return mockAcmeAccount(args.Get(0)), args.Error(1)
+}
+
+func (m *MockAcmeController) UpdateAccount(account appmodels.AcmeAccount, contact []string) (appmodels.AcmeAccount, error) {
+	args := m.Called(account, contact)
+	return mockAcmeAccount(args.Get(0)), args.Error(1)
+}
+
+func (m *MockAcmeController) DeactivateAccount(account appmodels.AcmeAccount) (appmodels.AcmeAccount, error) {
+	args := m.Called(account)
+	return mockAcmeAccount(args.Get(0)), args.Error(1)
+}
+
+func (m *MockAcmeController) OrderCollection(account appmodels.AcmeAccount) ([]appmodels.AcmeOrder, error) {
+	args := m.Called(account)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]appmodels.AcmeOrder), args.Error(1)
+}
+
+func (m *MockAcmeController) NewOrder(account appmodels.AcmeAccount, dnsNames []string) (appmodels.AcmeOrder, error) {
+	args := m.Called(account, dnsNames)
+	return mockAcmeOrder(args.Get(0)), args.Error(1)
+}
+
+func (m *MockAcmeController) Order(account appmodels.AcmeAccount, id string) (appmodels.AcmeOrder, error) {
+	args := m.Called(account, id)
+	return mockAcmeOrder(args.Get(0)), args.Error(1)
+}
+
+func (m *MockAcmeController) Authorization(account appmodels.AcmeAccount, id string) (appmodels.AcmeAuthorization, error) {
+	args := m.Called(account, id)
+	return mockAcmeAuthorization(args.Get(0)), args.Error(1)
+}
+
+func (m *MockAcmeController) ValidateChallenge(account appmodels.AcmeAccount, authorization, challenge string) (appmodels.AcmeAuthorization, error) {
+	args := m.Called(account, authorization, challenge)
+	return mockAcmeAuthorization(args.Get(0)), args.Error(1)
+}
+
+func (m *MockAcmeController) FinalizeOrder(issuer appmodels.CertificateController, account appmodels.AcmeAccount, id string, csr *x509.CertificateRequest) (appmodels.AcmeOrder, error) {
+	args := m.Called(issuer, account, id, csr)
+	return mockAcmeOrder(args.Get(0)), args.Error(1)
+}
+
+func (m *MockAcmeController) CertificateChain(issuer appmodels.CertificateController, account appmodels.AcmeAccount, serialNumber *big.Int) ([]appmodels.Certificate, error) {
+	args := m.Called(issuer, account, serialNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]appmodels.Certificate), args.Error(1)
+}
+
+func mockAcmeAccount(value any) appmodels.AcmeAccount {
+	if value == nil {
+		return nil
+	}
+	return value.(appmodels.AcmeAccount)
+}
+
+func mockAcmeOrder(value any) appmodels.AcmeOrder {
+	if value == nil {
+		return nil
+	}
+	return value.(appmodels.AcmeOrder)
+}
+
+func mockAcmeAuthorization(value any) appmodels.AcmeAuthorization {
+	if value == nil {
+		return nil
+	}
+	return value.(appmodels.AcmeAuthorization)
+}
+
+var _ appmodels.AcmeController = (*MockAcmeController)(nil)
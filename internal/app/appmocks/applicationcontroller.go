@@ -20,6 +20,11 @@ func (m *MockApplicationController) OrganizationCollection() ([]appmodels.Organi
 	panic("implement me")
 }
 
+func (m *MockApplicationController) OrganizationPage(query appmodels.OrganizationQuery) (appmodels.OrganizationPage, error) {
+	args := m.Called(query)
+	return args.Get(0).(appmodels.OrganizationPage), args.Error(1)
+}
+
 // UsesOrganizationService mocks the UsesOrganizationService method
 func (m *MockApplicationController) UsesOrganizationService(service appmodels.OrganizationRepository) bool {
 	args := m.Called(service)
@@ -0,0 +1,59 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmocks
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MockApprovalController is a mock implementation of appmodels.ApprovalController for testing purposes.
+type MockApprovalController struct {
+	mock.Mock
+}
+
+func (m *MockApprovalController) Authenticate(token string) (string, error) {
+	args := m.Called(token)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockApprovalController) Threshold() int {
+	args := m.Called()
+	return args.Int(0)
+}
+
+func (m *MockApprovalController) Operations() ([]appmodels.PendingOperation, error) {
+	args := m.Called()
+	return args.Get(0).([]appmodels.PendingOperation), args.Error(1)
+}
+
+func (m *MockApprovalController) Operation(id string) (appmodels.PendingOperation, error) {
+	args := m.Called(id)
+	return args.Get(0).(appmodels.PendingOperation), args.Error(1)
+}
+
+func (m *MockApprovalController) Approve(id, approver string) (appmodels.PendingOperation, error) {
+	args := m.Called(id, approver)
+	return args.Get(0).(appmodels.PendingOperation), args.Error(1)
+}
+
+func (m *MockApprovalController) Reject(id, approver string) (appmodels.PendingOperation, error) {
+	args := m.Called(id, approver)
+	return args.Get(0).(appmodels.PendingOperation), args.Error(1)
+}
+
+func (m *MockApprovalController) Execute(operationType appmodels.OperationType, organization, certificate *big.Int, details string, requester string, operation func() error) error {
+	args := m.Called(operationType, organization, certificate, details, requester, operation)
+	return args.Error(0)
+}
+
+func (m *MockApprovalController) ExpireOperations(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+var _ appmodels.ApprovalController = (*MockApprovalController)(nil)
@@ -0,0 +1,63 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmocks
+
+import (
+	"math/big"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MockBackupController is a mock implementation of appmodels.BackupController for testing purposes.
+type MockBackupController struct {
+	mock.Mock
+}
+
+func (m *MockBackupController) Authenticate(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockBackupController) Backup(organization *big.Int, passphrase string) ([]byte, error) {
+	args := m.Called(organization, passphrase)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockBackupController) Verify(archive []byte, passphrase string, force bool) (appmodels.OrganizationBackup, error) {
+	args := m.Called(archive, passphrase, force)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.OrganizationBackup), args.Error(1)
+}
+
+func (m *MockBackupController) Restore(archive []byte, passphrase string, force bool) (appmodels.OrganizationBackup, error) {
+	args := m.Called(archive, passphrase, force)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.OrganizationBackup), args.Error(1)
+}
+
+func (m *MockBackupController) ExportKeyShares(organization, certificate *big.Int, shares, threshold int) ([]appmodels.PrivateKeyShare, error) {
+	args := m.Called(organization, certificate, shares, threshold)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]appmodels.PrivateKeyShare), args.Error(1)
+}
+
+func (m *MockBackupController) ImportKeyShares(organization, certificate *big.Int, shares []appmodels.PrivateKeyShare) (appmodels.PrivateKey, error) {
+	args := m.Called(organization, certificate, shares)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.PrivateKey), args.Error(1)
+}
+
+var _ appmodels.BackupController = (*MockBackupController)(nil)
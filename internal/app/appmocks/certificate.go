@@ -92,4 +92,14 @@ func (m *MockCertificate) IsClientCertificate() bool {
 	return args.Bool(0)
 }
 
+func (m *MockCertificate) SignatureAlgorithm() appmodels.SignatureAlgorithm {
+	args := m.Called()
+	return args.Get(0).(appmodels.SignatureAlgorithm)
+}
+
+func (m *MockCertificate) SpiffeID() string {
+	args := m.Called()
+	return args.String(0)
+}
+
 var _ appmodels.Certificate = (*MockCertificate)(nil)
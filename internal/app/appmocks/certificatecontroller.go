@@ -21,6 +21,11 @@ func (m *MockCertificateController) ChildCertificateCollection(certificateType s
 	return args.Get(0).([]appmodels.Certificate), args.Error(2)
 }
 
+func (m *MockCertificateController) ChildCertificatePage(query appmodels.CertificateQuery) (appmodels.CertificatePage, error) {
+	args := m.Called(query)
+	return args.Get(0).(appmodels.CertificatePage), args.Error(1)
+}
+
 func (m *MockCertificateController) ApplicationController() appmodels.ApplicationController {
 	args := m.Called()
 	return args.Get(0).(appmodels.ApplicationController)
@@ -58,6 +63,9 @@ func (m *MockCertificateController) ParentCertificate() appmodels.Certificate {
 
 func (m *MockCertificateController) ParentCertificateController() appmodels.CertificateController {
 	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
 	return args.Get(0).(appmodels.CertificateController)
 }
 
@@ -65,6 +73,14 @@ func (m *MockCertificateController) SetExpirationDuration(expiration time.Durati
 	m.Called(expiration)
 }
 
+func (m *MockCertificateController) SetKeyType(keyType appmodels.KeyType) {
+	m.Called(keyType)
+}
+
+func (m *MockCertificateController) SetSignatureAlgorithm(signatureAlgorithm appmodels.SignatureAlgorithm) {
+	m.Called(signatureAlgorithm)
+}
+
 func (m *MockCertificateController) NewIntermediateCertificate(commonName string) (appmodels.Certificate, appmodels.PrivateKey, error) {
 	args := m.Called(commonName)
 	return args.Get(0).(appmodels.Certificate), args.Get(1).(appmodels.PrivateKey), args.Error(2)
@@ -75,6 +91,35 @@ func (m *MockCertificateController) NewServerCertificate(dnsNames ...string) (ap
 	return args.Get(0).(appmodels.Certificate), args.Get(1).(appmodels.PrivateKey), args.Error(2)
 }
 
+func (m *MockCertificateController) NewServerCertificateFromPublicKey(publicKey appmodels.PublicKey, dnsNames ...string) (appmodels.Certificate, error) {
+	args := m.Called(publicKey, dnsNames)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.Certificate), args.Error(1)
+}
+
+func (m *MockCertificateController) NewClientCertificateFromPublicKey(publicKey appmodels.PublicKey, commonName string) (appmodels.Certificate, error) {
+	args := m.Called(publicKey, commonName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.Certificate), args.Error(1)
+}
+
+func (m *MockCertificateController) NewSpiffeCertificateFromPublicKey(publicKey appmodels.PublicKey, spiffeID string) (appmodels.Certificate, error) {
+	args := m.Called(publicKey, spiffeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.Certificate), args.Error(1)
+}
+
+func (m *MockCertificateController) NewSpiffeCertificate(spiffeID string) (appmodels.Certificate, appmodels.PrivateKey, error) {
+	args := m.Called(spiffeID)
+	return args.Get(0).(appmodels.Certificate), args.Get(1).(appmodels.PrivateKey), args.Error(2)
+}
+
 func (m *MockCertificateController) NewClientCertificate(commonName string) (appmodels.Certificate, appmodels.PrivateKey, error) {
 	args := m.Called(commonName)
 	return args.Get(0).(appmodels.Certificate), args.Get(1).(appmodels.PrivateKey), args.Error(2)
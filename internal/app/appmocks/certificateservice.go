@@ -31,6 +31,11 @@ func (m *MockCertificateService) FindAllByOrganization(organization *big.Int) ([
 	return args.Get(0).([]appmodels.Certificate), args.Error(1)
 }
 
+func (m *MockCertificateService) FindPageByOrganization(organization *big.Int, query appmodels.CertificateQuery) (appmodels.CertificatePage, error) {
+	args := m.Called(organization, query)
+	return args.Get(0).(appmodels.CertificatePage), args.Error(1)
+}
+
 func (m *MockCertificateService) FindByOrganizationAndSerialNumber(organization *big.Int, certificate *big.Int) (appmodels.Certificate, error) {
 	args := m.Called(organization, certificate)
 	if args.Get(0) == nil {
@@ -47,4 +52,9 @@ func (m *MockCertificateService) Save(certificate appmodels.Certificate) (appmod
 	return args.Get(0).(appmodels.Certificate), args.Error(1)
 }
 
+func (m *MockCertificateService) Archive(organization *big.Int, certificate *big.Int) error {
+	args := m.Called(organization, certificate)
+	return args.Error(0)
+}
+
 var _ appmodels.CertificateRepository = (*MockCertificateService)(nil)
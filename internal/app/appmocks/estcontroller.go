@@ -0,0 +1,87 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmocks
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MockEstController is a mock implementation of appmodels.EstController for testing purposes.
+type MockEstController struct {
+	mock.Mock
+}
+
+func (m *MockEstController) Label(label string) (appmodels.EstLabel, error) {
+	args := m.Called(label)
+	return mockEstLabel(args.Get(0)), args.Error(1)
+}
+
+func (m *MockEstController) NewLabel(issuer appmodels.CertificateController, label, username, password string) (appmodels.EstLabel, error) {
+	args := m.Called(issuer, label, username, password)
+	return mockEstLabel(args.Get(0)), args.Error(1)
+}
+
+func (m *MockEstController) Issuer(label appmodels.EstLabel) (appmodels.CertificateController, error) {
+	args := m.Called(label)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.CertificateController), args.Error(1)
+}
+
+func (m *MockEstController) Authenticate(label appmodels.EstLabel, username, password string) error {
+	args := m.Called(label, username, password)
+	return args.Error(0)
+}
+
+func (m *MockEstController) VerifyClientCertificate(issuer appmodels.CertificateController, certificate *x509.Certificate) error {
+	args := m.Called(issuer, certificate)
+	return args.Error(0)
+}
+
+func (m *MockEstController) CACertificates(issuer appmodels.CertificateController) []appmodels.Certificate {
+	args := m.Called(issuer)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]appmodels.Certificate)
+}
+
+func (m *MockEstController) CsrAttributes(issuer appmodels.CertificateController) []asn1.ObjectIdentifier {
+	args := m.Called(issuer)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]asn1.ObjectIdentifier)
+}
+
+func (m *MockEstController) Enroll(issuer appmodels.CertificateController, csr *x509.CertificateRequest) (appmodels.Certificate, error) {
+	args := m.Called(issuer, csr)
+	return mockEstCertificate(args.Get(0)), args.Error(1)
+}
+
+func (m *MockEstController) Reenroll(issuer appmodels.CertificateController, current *x509.Certificate, csr *x509.CertificateRequest) (appmodels.Certificate, error) {
+	args := m.Called(issuer, current, csr)
+	return mockEstCertificate(args.Get(0)), args.Error(1)
+}
+
+func mockEstLabel(value any) appmodels.EstLabel {
+	if value == nil {
+		return nil
+	}
+	return value.(appmodels.EstLabel)
+}
+
+func mockEstCertificate(value any) appmodels.Certificate {
+	if value == nil {
+		return nil
+	}
+	return value.(appmodels.Certificate)
+}
+
+var _ appmodels.EstController = (*MockEstController)(nil)
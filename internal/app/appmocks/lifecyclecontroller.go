@@ -0,0 +1,45 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmocks
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MockLifecycleController is a mock implementation of appmodels.LifecycleController for testing purposes.
+type MockLifecycleController struct {
+	mock.Mock
+}
+
+func (m *MockLifecycleController) Retention() time.Duration {
+	args := m.Called()
+	return args.Get(0).(time.Duration)
+}
+
+func (m *MockLifecycleController) CertificateStates(organization *big.Int, certificates []appmodels.Certificate, now time.Time) (map[string]appmodels.CertificateState, error) {
+	args := m.Called(organization, certificates, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]appmodels.CertificateState), args.Error(1)
+}
+
+func (m *MockLifecycleController) ArchiveCertificates(organization *big.Int, now time.Time) ([]appmodels.Certificate, error) {
+	args := m.Called(organization, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]appmodels.Certificate), args.Error(1)
+}
+
+func (m *MockLifecycleController) Run(ctx context.Context, interval time.Duration) {
+	m.Called(ctx, interval)
+}
+
+var _ appmodels.LifecycleController = (*MockLifecycleController)(nil)
@@ -0,0 +1,26 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmocks
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MockMigrationController is a mock implementation of appmodels.MigrationController for testing purposes.
+type MockMigrationController struct {
+	mock.Mock
+}
+
+func (m *MockMigrationController) Migrate() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockMigrationController) Verify() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+var _ appmodels.MigrationController = (*MockMigrationController)(nil)
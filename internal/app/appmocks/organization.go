@@ -35,4 +35,19 @@ func (m *MockOrganization) Names() []string {
 	return args.Get(0).([]string)
 }
 
+func (m *MockOrganization) SignatureAlgorithm() appmodels.SignatureAlgorithm {
+	args := m.Called()
+	return args.Get(0).(appmodels.SignatureAlgorithm)
+}
+
+func (m *MockOrganization) SpiffeTrustDomain() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockOrganization) KeyRetentionPolicy() appmodels.KeyRetentionPolicy {
+	args := m.Called()
+	return args.Get(0).(appmodels.KeyRetentionPolicy)
+}
+
 var _ appmodels.Organization = (*MockOrganization)(nil)
@@ -29,6 +29,11 @@ func (m *MockOrganizationController) CertificateCollection() ([]appmodels.Certif
 	return args.Get(0).([]appmodels.Certificate), args.Error(1)
 }
 
+func (m *MockOrganizationController) CertificatePage(query appmodels.CertificateQuery) (appmodels.CertificatePage, error) {
+	args := m.Called(query)
+	return args.Get(0).(appmodels.CertificatePage), args.Error(1)
+}
+
 func (m *MockOrganizationController) OrganizationID() *big.Int {
 	args := m.Called()
 	return args.Get(0).(*big.Int)
@@ -58,6 +63,14 @@ func (m *MockOrganizationController) SetExpirationDuration(expiration time.Durat
 	m.Called(expiration)
 }
 
+func (m *MockOrganizationController) SetKeyType(keyType appmodels.KeyType) {
+	m.Called(keyType)
+}
+
+func (m *MockOrganizationController) SetSignatureAlgorithm(signatureAlgorithm appmodels.SignatureAlgorithm) {
+	m.Called(signatureAlgorithm)
+}
+
 func (m *MockOrganizationController) NewRootCertificate(commonName string) (appmodels.Certificate, error) {
 	args := m.Called(commonName)
 	return args.Get(0).(appmodels.Certificate), args.Error(1)
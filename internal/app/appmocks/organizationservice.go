@@ -23,6 +23,11 @@ func (m *MockOrganizationService) FindAll() ([]appmodels.Organization, error) {
 	return args.Get(0).([]appmodels.Organization), args.Error(1)
 }
 
+func (m *MockOrganizationService) FindPage(query appmodels.OrganizationQuery) (appmodels.OrganizationPage, error) {
+	args := m.Called(query)
+	return args.Get(0).(appmodels.OrganizationPage), args.Error(1)
+}
+
 // GetExistingOrganization mocks the GetExistingOrganization method
 func (m *MockOrganizationService) FindById(organization *big.Int) (appmodels.Organization, error) {
 	args := m.Called(organization)
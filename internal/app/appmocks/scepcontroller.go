@@ -0,0 +1,59 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmocks
+
+import (
+	"math/big"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MockScepController is a mock implementation of appmodels.ScepController for testing purposes.
+type MockScepController struct {
+	mock.Mock
+}
+
+func (m *MockScepController) SetChallengePassword(organization *big.Int, password string) (appmodels.ScepChallenge, error) {
+	args := m.Called(organization, password)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.ScepChallenge), args.Error(1)
+}
+
+func (m *MockScepController) Capabilities() []string {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]string)
+}
+
+func (m *MockScepController) RA(issuer appmodels.CertificateController) (appmodels.Certificate, appmodels.PrivateKey, error) {
+	args := m.Called(issuer)
+	var privateKey appmodels.PrivateKey
+	if args.Get(1) != nil {
+		privateKey = args.Get(1).(appmodels.PrivateKey)
+	}
+	return mockEstCertificate(args.Get(0)), privateKey, args.Error(2)
+}
+
+func (m *MockScepController) CACertificates(issuer appmodels.CertificateController) []appmodels.Certificate {
+	args := m.Called(issuer)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]appmodels.Certificate)
+}
+
+func (m *MockScepController) PKIOperation(issuer appmodels.CertificateController, message []byte) ([]byte, error) {
+	args := m.Called(issuer, message)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+var _ appmodels.ScepController = (*MockScepController)(nil)
@@ -0,0 +1,66 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmocks
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MockSealController is a mock implementation of appmodels.SealController for testing purposes.
+type MockSealController struct {
+	mock.Mock
+}
+
+func (m *MockSealController) Authenticate(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockSealController) IsSealed() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockSealController) Threshold() int {
+	args := m.Called()
+	return args.Int(0)
+}
+
+func (m *MockSealController) Progress() int {
+	args := m.Called()
+	return args.Int(0)
+}
+
+func (m *MockSealController) Unseal(passphrase string) error {
+	args := m.Called(passphrase)
+	return args.Error(0)
+}
+
+func (m *MockSealController) UnsealShare(share []byte) error {
+	args := m.Called(share)
+	return args.Error(0)
+}
+
+func (m *MockSealController) Seal() {
+	m.Called()
+}
+
+func (m *MockSealController) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	args := m.Called(plaintext, additionalData)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockSealController) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	args := m.Called(ciphertext, additionalData)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+var _ appmodels.SealController = (*MockSealController)(nil)
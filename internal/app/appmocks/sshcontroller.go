@@ -0,0 +1,85 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmocks
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MockSshController is a mock implementation of appmodels.SshController for testing purposes.
+type MockSshController struct {
+	mock.Mock
+}
+
+func (m *MockSshController) Authority(organization *big.Int) (appmodels.SshAuthority, error) {
+	args := m.Called(organization)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.SshAuthority), args.Error(1)
+}
+
+func (m *MockSshController) NewAuthority(organization *big.Int, keyType appmodels.KeyType) (appmodels.SshAuthority, error) {
+	args := m.Called(organization, keyType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.SshAuthority), args.Error(1)
+}
+
+func (m *MockSshController) Sign(
+	organization *big.Int,
+	publicKey ssh.PublicKey,
+	certificateType appmodels.SshCertificateType,
+	keyID string,
+	principals []string,
+	expiration time.Duration,
+	criticalOptions map[string]string,
+	extensions map[string]string,
+) (appmodels.SshCertificate, error) {
+	args := m.Called(organization, publicKey, certificateType, keyID, principals, expiration, criticalOptions, extensions)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.SshCertificate), args.Error(1)
+}
+
+func (m *MockSshController) Certificates(organization *big.Int) ([]appmodels.SshCertificate, error) {
+	args := m.Called(organization)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]appmodels.SshCertificate), args.Error(1)
+}
+
+func (m *MockSshController) Certificate(organization *big.Int, serial uint64) (appmodels.SshCertificate, error) {
+	args := m.Called(organization, serial)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.SshCertificate), args.Error(1)
+}
+
+func (m *MockSshController) Revoke(organization *big.Int, serial uint64) (appmodels.SshCertificate, error) {
+	args := m.Called(organization, serial)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.SshCertificate), args.Error(1)
+}
+
+func (m *MockSshController) RevocationList(organization *big.Int) ([]byte, error) {
+	args := m.Called(organization)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+var _ appmodels.SshController = (*MockSshController)(nil)
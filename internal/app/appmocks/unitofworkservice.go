@@ -0,0 +1,41 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package appmocks
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MockUnitOfWorkService is a mock implementation of
+// appmodels.UnitOfWorkRepository interface.
+type MockUnitOfWorkService struct {
+	mock.Mock
+}
+
+func (m *MockUnitOfWorkService) NewUnitOfWork() appmodels.UnitOfWork {
+	args := m.Called()
+	return args.Get(0).(appmodels.UnitOfWork)
+}
+
+// MockUnitOfWork is a mock implementation of appmodels.UnitOfWork interface.
+type MockUnitOfWork struct {
+	mock.Mock
+}
+
+func (m *MockUnitOfWork) SaveCertificate(certificate appmodels.Certificate) {
+	m.Called(certificate)
+}
+
+func (m *MockUnitOfWork) SavePrivateKey(key appmodels.PrivateKey) {
+	m.Called(key)
+}
+
+func (m *MockUnitOfWork) Commit() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+var _ appmodels.UnitOfWorkRepository = (*MockUnitOfWorkService)(nil)
+var _ appmodels.UnitOfWork = (*MockUnitOfWork)(nil)
@@ -0,0 +1,117 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmocks
+
+import (
+	"crypto/x509"
+	"math/big"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MockVaultController is a mock implementation of appmodels.VaultController for testing purposes.
+type MockVaultController struct {
+	mock.Mock
+}
+
+func (m *MockVaultController) Authenticate(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockVaultController) Role(organization *big.Int, name string) (appmodels.VaultRole, error) {
+	args := m.Called(organization, name)
+	return mockVaultRole(args.Get(0)), args.Error(1)
+}
+
+func (m *MockVaultController) Roles(organization *big.Int) ([]appmodels.VaultRole, error) {
+	args := m.Called(organization)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]appmodels.VaultRole), args.Error(1)
+}
+
+func (m *MockVaultController) SaveRole(role appmodels.VaultRole) (appmodels.VaultRole, error) {
+	args := m.Called(role)
+	return mockVaultRole(args.Get(0)), args.Error(1)
+}
+
+func (m *MockVaultController) DeleteRole(organization *big.Int, name string) error {
+	args := m.Called(organization, name)
+	return args.Error(0)
+}
+
+func (m *MockVaultController) Issue(role appmodels.VaultRole, commonName string, altNames []string, ttl time.Duration) (appmodels.Certificate, appmodels.PrivateKey, error) {
+	args := m.Called(role, commonName, altNames, ttl)
+	var privateKey appmodels.PrivateKey
+	if args.Get(1) != nil {
+		privateKey = args.Get(1).(appmodels.PrivateKey)
+	}
+	return mockVaultCertificate(args.Get(0)), privateKey, args.Error(2)
+}
+
+func (m *MockVaultController) Sign(role appmodels.VaultRole, csr *x509.CertificateRequest, commonName string, altNames []string, ttl time.Duration) (appmodels.Certificate, error) {
+	args := m.Called(role, csr, commonName, altNames, ttl)
+	return mockVaultCertificate(args.Get(0)), args.Error(1)
+}
+
+func (m *MockVaultController) Revoke(organization *big.Int, serialNumber *big.Int) (appmodels.CertificateRevocation, error) {
+	args := m.Called(organization, serialNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(appmodels.CertificateRevocation), args.Error(1)
+}
+
+func (m *MockVaultController) Issuer(organization *big.Int, serialNumber *big.Int) (appmodels.CertificateController, error) {
+	args := m.Called(organization, serialNumber)
+	return mockVaultIssuer(args.Get(0)), args.Error(1)
+}
+
+func (m *MockVaultController) DefaultIssuer(organization *big.Int) (appmodels.CertificateController, error) {
+	args := m.Called(organization)
+	return mockVaultIssuer(args.Get(0)), args.Error(1)
+}
+
+func (m *MockVaultController) CACertificates(issuer appmodels.CertificateController) []appmodels.Certificate {
+	args := m.Called(issuer)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]appmodels.Certificate)
+}
+
+func (m *MockVaultController) RevocationList(issuer appmodels.CertificateController) ([]byte, error) {
+	args := m.Called(issuer)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func mockVaultRole(value any) appmodels.VaultRole {
+	if value == nil {
+		return nil
+	}
+	return value.(appmodels.VaultRole)
+}
+
+func mockVaultCertificate(value any) appmodels.Certificate {
+	if value == nil {
+		return nil
+	}
+	return value.(appmodels.Certificate)
+}
+
+func mockVaultIssuer(value any) appmodels.CertificateController {
+	if value == nil {
+		return nil
+	}
+	return value.(appmodels.CertificateController)
+}
+
+var _ appmodels.VaultController = (*MockVaultController)(nil)
@@ -0,0 +1,102 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"math/big"
+	"time"
+)
+
+// AcmeAccountModel model implements AcmeAccount
+type AcmeAccountModel struct {
+
+	// id is the unique identifier of the account
+	id string
+
+	// organization is the organization of the ACME directory
+	organization *big.Int
+
+	// issuer is the serial number of the issuing certificate of the ACME directory
+	issuer *big.Int
+
+	// status is the account status
+	status AcmeStatus
+
+	// contact is the list of contact URLs, e.g. "mailto:admin@example.com"
+	contact []string
+
+	// publicKey is the account public key
+	publicKey any
+
+	// thumbprint is the RFC 7638 thumbprint of the account public key
+	thumbprint string
+
+	// createdAt is the time the account was created
+	createdAt time.Time
+}
+
+func (a *AcmeAccountModel) ID() string {
+	return a.id
+}
+
+func (a *AcmeAccountModel) OrganizationID() *big.Int {
+	return a.organization
+}
+
+func (a *AcmeAccountModel) IssuerSerialNumber() *big.Int {
+	return a.issuer
+}
+
+func (a *AcmeAccountModel) Status() AcmeStatus {
+	return a.status
+}
+
+func (a *AcmeAccountModel) Contact() []string {
+	return a.contact
+}
+
+func (a *AcmeAccountModel) PublicKey() any {
+	return a.publicKey
+}
+
+func (a *AcmeAccountModel) Thumbprint() string {
+	return a.thumbprint
+}
+
+func (a *AcmeAccountModel) CreatedAt() time.Time {
+	return a.createdAt
+}
+
+// NewAcmeAccount creates an ACME account model
+//   - id: The account ID
+//   - organization: The organization of the ACME directory
+//   - issuer: The serial number of the issuing certificate
+//   - status: The account status
+//   - contact: The contact URLs
+//   - publicKey: The account public key
+//   - thumbprint: The RFC 7638 thumbprint of the public key
+//   - createdAt: The creation time
+func NewAcmeAccount(
+	id string,
+	organization *big.Int,
+	issuer *big.Int,
+	status AcmeStatus,
+	contact []string,
+	publicKey any,
+	thumbprint string,
+	createdAt time.Time,
+) *AcmeAccountModel {
+	return &AcmeAccountModel{
+		id:           id,
+		organization: organization,
+		issuer:       issuer,
+		status:       status,
+		contact:      contact,
+		publicKey:    publicKey,
+		thumbprint:   thumbprint,
+		createdAt:    createdAt,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ AcmeAccount = (*AcmeAccountModel)(nil)
@@ -0,0 +1,46 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewAcmeAccount(t *testing.T) {
+	organization := big.NewInt(123)
+	issuer := big.NewInt(456)
+	contact := []string{"mailto:admin@example.com"}
+	publicKey := "key"
+	createdAt := time.Now()
+
+	account := appmodels.NewAcmeAccount("acc1", organization, issuer, appmodels.ACME_STATUS_VALID, contact, publicKey, "thumb", createdAt)
+
+	if account.ID() != "acc1" {
+		t.Errorf("ID() = %v, want %v", account.ID(), "acc1")
+	}
+	if account.OrganizationID().Cmp(organization) != 0 {
+		t.Errorf("OrganizationID() = %v, want %v", account.OrganizationID(), organization)
+	}
+	if account.IssuerSerialNumber().Cmp(issuer) != 0 {
+		t.Errorf("IssuerSerialNumber() = %v, want %v", account.IssuerSerialNumber(), issuer)
+	}
+	if account.Status() != appmodels.ACME_STATUS_VALID {
+		t.Errorf("Status() = %v, want %v", account.Status(), appmodels.ACME_STATUS_VALID)
+	}
+	if len(account.Contact()) != 1 || account.Contact()[0] != contact[0] {
+		t.Errorf("Contact() = %v, want %v", account.Contact(), contact)
+	}
+	if account.PublicKey() != publicKey {
+		t.Errorf("PublicKey() = %v, want %v", account.PublicKey(), publicKey)
+	}
+	if account.Thumbprint() != "thumb" {
+		t.Errorf("Thumbprint() = %v, want %v", account.Thumbprint(), "thumb")
+	}
+	if !account.CreatedAt().Equal(createdAt) {
+		t.Errorf("CreatedAt() = %v, want %v", account.CreatedAt(), createdAt)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"time"
+)
+
+// AcmeAuthorizationModel model implements AcmeAuthorization
+type AcmeAuthorizationModel struct {
+
+	// id is the unique identifier of the authorization
+	id string
+
+	// account is the ID of the account which owns the authorization
+	account string
+
+	// order is the ID of the order which the authorization belongs to
+	order string
+
+	// status is the authorization status
+	status AcmeStatus
+
+	// expires is the time after which the authorization cannot be completed
+	expires time.Time
+
+	// dnsName is the DNS identifier without the wildcard prefix
+	dnsName string
+
+	// wildcard is true if the order requested a wildcard for dnsName
+	wildcard bool
+
+	// challenges are the challenges the client can complete
+	challenges []AcmeChallenge
+}
+
+func (a *AcmeAuthorizationModel) ID() string {
+	return a.id
+}
+
+func (a *AcmeAuthorizationModel) AccountID() string {
+	return a.account
+}
+
+func (a *AcmeAuthorizationModel) OrderID() string {
+	return a.order
+}
+
+func (a *AcmeAuthorizationModel) Status() AcmeStatus {
+	return a.status
+}
+
+func (a *AcmeAuthorizationModel) Expires() time.Time {
+	return a.expires
+}
+
+func (a *AcmeAuthorizationModel) DNSName() string {
+	return a.dnsName
+}
+
+func (a *AcmeAuthorizationModel) Wildcard() bool {
+	return a.wildcard
+}
+
+func (a *AcmeAuthorizationModel) Challenges() []AcmeChallenge {
+	return a.challenges
+}
+
+// Challenge returns a challenge by its ID, or nil if not found
+func (a *AcmeAuthorizationModel) Challenge(id string) AcmeChallenge {
+	for _, challenge := range a.challenges {
+		if challenge.ID() == id {
+			return challenge
+		}
+	}
+	return nil
+}
+
+// NewAcmeAuthorization creates an ACME authorization model
+//   - id: The authorization ID
+//   - account: The ID of the account owning the authorization
+//   - order: The ID of the order
+//   - status: The authorization status
+//   - expires: The expiration time
+//   - dnsName: The DNS identifier without the wildcard prefix
+//   - wildcard: True if the identifier is a wildcard
+//   - challenges: The challenges
+func NewAcmeAuthorization(
+	id string,
+	account string,
+	order string,
+	status AcmeStatus,
+	expires time.Time,
+	dnsName string,
+	wildcard bool,
+	challenges []AcmeChallenge,
+) *AcmeAuthorizationModel {
+	return &AcmeAuthorizationModel{
+		id:         id,
+		account:    account,
+		order:      order,
+		status:     status,
+		expires:    expires,
+		dnsName:    dnsName,
+		wildcard:   wildcard,
+		challenges: challenges,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ AcmeAuthorization = (*AcmeAuthorizationModel)(nil)
@@ -0,0 +1,58 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewAcmeAuthorization(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	httpChallenge := appmodels.NewAcmeChallenge("1", appmodels.ACME_HTTP_01, "token1", appmodels.ACME_STATUS_PENDING, time.Time{}, nil)
+	dnsChallenge := appmodels.NewAcmeChallenge("2", appmodels.ACME_DNS_01, "token2", appmodels.ACME_STATUS_PENDING, time.Time{}, nil)
+
+	authorization := appmodels.NewAcmeAuthorization(
+		"authz1",
+		"acc1",
+		"order1",
+		appmodels.ACME_STATUS_PENDING,
+		expires,
+		"example.com",
+		true,
+		[]appmodels.AcmeChallenge{httpChallenge, dnsChallenge},
+	)
+
+	if authorization.ID() != "authz1" {
+		t.Errorf("ID() = %v, want %v", authorization.ID(), "authz1")
+	}
+	if authorization.AccountID() != "acc1" {
+		t.Errorf("AccountID() = %v, want %v", authorization.AccountID(), "acc1")
+	}
+	if authorization.OrderID() != "order1" {
+		t.Errorf("OrderID() = %v, want %v", authorization.OrderID(), "order1")
+	}
+	if authorization.Status() != appmodels.ACME_STATUS_PENDING {
+		t.Errorf("Status() = %v, want %v", authorization.Status(), appmodels.ACME_STATUS_PENDING)
+	}
+	if !authorization.Expires().Equal(expires) {
+		t.Errorf("Expires() = %v, want %v", authorization.Expires(), expires)
+	}
+	if authorization.DNSName() != "example.com" {
+		t.Errorf("DNSName() = %v, want %v", authorization.DNSName(), "example.com")
+	}
+	if !authorization.Wildcard() {
+		t.Errorf("Wildcard() = false, want true")
+	}
+	if len(authorization.Challenges()) != 2 {
+		t.Errorf("Challenges() = %v", authorization.Challenges())
+	}
+	if authorization.Challenge("2") != dnsChallenge {
+		t.Errorf("Challenge(2) = %v, want %v", authorization.Challenge("2"), dnsChallenge)
+	}
+	if authorization.Challenge("3") != nil {
+		t.Errorf("Challenge(3) = %v, want nil", authorization.Challenge("3"))
+	}
+}
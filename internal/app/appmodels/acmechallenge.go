@@ -0,0 +1,81 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"time"
+)
+
+// AcmeChallengeModel model implements AcmeChallenge
+type AcmeChallengeModel struct {
+
+	// id is the identifier of the challenge inside the authorization
+	id string
+
+	// challengeType is the type of the challenge
+	challengeType AcmeChallengeType
+
+	// token is the random token the client uses to build the key authorization
+	token string
+
+	// status is the challenge status
+	status AcmeStatus
+
+	// validated is the time the challenge was validated
+	validated time.Time
+
+	// err is the error from the last validation attempt
+	err *AcmeError
+}
+
+func (c *AcmeChallengeModel) ID() string {
+	return c.id
+}
+
+func (c *AcmeChallengeModel) Type() AcmeChallengeType {
+	return c.challengeType
+}
+
+func (c *AcmeChallengeModel) Token() string {
+	return c.token
+}
+
+func (c *AcmeChallengeModel) Status() AcmeStatus {
+	return c.status
+}
+
+func (c *AcmeChallengeModel) Validated() time.Time {
+	return c.validated
+}
+
+func (c *AcmeChallengeModel) Error() *AcmeError {
+	return c.err
+}
+
+// NewAcmeChallenge creates an ACME challenge model
+//   - id: The challenge ID
+//   - challengeType: The challenge type
+//   - token: The challenge token
+//   - status: The challenge status
+//   - validated: The validation time, or zero time
+//   - err: The validation error, or nil
+func NewAcmeChallenge(
+	id string,
+	challengeType AcmeChallengeType,
+	token string,
+	status AcmeStatus,
+	validated time.Time,
+	err *AcmeError,
+) *AcmeChallengeModel {
+	return &AcmeChallengeModel{
+		id:            id,
+		challengeType: challengeType,
+		token:         token,
+		status:        status,
+		validated:     validated,
+		err:           err,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ AcmeChallenge = (*AcmeChallengeModel)(nil)
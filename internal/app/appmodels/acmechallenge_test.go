@@ -0,0 +1,36 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewAcmeChallenge(t *testing.T) {
+	validated := time.Now()
+	acmeErr := appmodels.NewAcmeError(appmodels.ACME_ERROR_INCORRECT_RESPONSE, "mismatch")
+
+	challenge := appmodels.NewAcmeChallenge("1", appmodels.ACME_TLS_ALPN_01, "token", appmodels.ACME_STATUS_INVALID, validated, acmeErr)
+
+	if challenge.ID() != "1" {
+		t.Errorf("ID() = %v, want %v", challenge.ID(), "1")
+	}
+	if challenge.Type() != appmodels.ACME_TLS_ALPN_01 {
+		t.Errorf("Type() = %v, want %v", challenge.Type(), appmodels.ACME_TLS_ALPN_01)
+	}
+	if challenge.Token() != "token" {
+		t.Errorf("Token() = %v, want %v", challenge.Token(), "token")
+	}
+	if challenge.Status() != appmodels.ACME_STATUS_INVALID {
+		t.Errorf("Status() = %v, want %v", challenge.Status(), appmodels.ACME_STATUS_INVALID)
+	}
+	if !challenge.Validated().Equal(validated) {
+		t.Errorf("Validated() = %v, want %v", challenge.Validated(), validated)
+	}
+	if challenge.Error() != acmeErr {
+		t.Errorf("Error() = %v, want %v", challenge.Error(), acmeErr)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import "fmt"
+
+// AcmeErrorType represents an ACME (RFC 8555 section 6.7) error type
+type AcmeErrorType int
+
+const (
+	// ACME_ERROR_SERVER_INTERNAL represents an internal server error
+	ACME_ERROR_SERVER_INTERNAL AcmeErrorType = iota
+
+	// ACME_ERROR_MALFORMED represents a malformed request
+	ACME_ERROR_MALFORMED
+
+	// ACME_ERROR_BAD_NONCE represents an unacceptable anti-replay nonce
+	ACME_ERROR_BAD_NONCE
+
+	// ACME_ERROR_BAD_SIGNATURE_ALGORITHM represents an unsupported JWS algorithm
+	ACME_ERROR_BAD_SIGNATURE_ALGORITHM
+
+	// ACME_ERROR_UNAUTHORIZED represents a client without sufficient authorization
+	ACME_ERROR_UNAUTHORIZED
+
+	// ACME_ERROR_ACCOUNT_DOES_NOT_EXIST represents an unknown account
+	ACME_ERROR_ACCOUNT_DOES_NOT_EXIST
+
+	// ACME_ERROR_ORDER_NOT_READY represents an order which cannot be finalized yet
+	ACME_ERROR_ORDER_NOT_READY
+
+	// ACME_ERROR_BAD_CSR represents an unacceptable certificate signing request
+	ACME_ERROR_BAD_CSR
+
+	// ACME_ERROR_REJECTED_IDENTIFIER represents an identifier the server will not issue for
+	ACME_ERROR_REJECTED_IDENTIFIER
+
+	// ACME_ERROR_UNSUPPORTED_IDENTIFIER represents an identifier type the server does not support
+	ACME_ERROR_UNSUPPORTED_IDENTIFIER
+
+	// ACME_ERROR_INCORRECT_RESPONSE represents a challenge response which did not match
+	ACME_ERROR_INCORRECT_RESPONSE
+
+	// ACME_ERROR_CONNECTION represents a failure to connect to the client for validation
+	ACME_ERROR_CONNECTION
+
+	// ACME_ERROR_DNS represents a DNS lookup failure during validation
+	ACME_ERROR_DNS
+
+	// ACME_ERROR_TLS represents a TLS failure during validation
+	ACME_ERROR_TLS
+
+	// ACME_ERROR_NOT_FOUND represents a missing resource. It is not defined
+	// in RFC 8555 and is sent as ACME_ERROR_MALFORMED.
+	ACME_ERROR_NOT_FOUND
+)
+
+// String returns the error type as used in the ACME protocol, e.g. "badNonce"
+func (t AcmeErrorType) String() string {
+	switch t {
+	case ACME_ERROR_SERVER_INTERNAL:
+		return "serverInternal"
+	case ACME_ERROR_MALFORMED, ACME_ERROR_NOT_FOUND:
+		return "malformed"
+	case ACME_ERROR_BAD_NONCE:
+		return "badNonce"
+	case ACME_ERROR_BAD_SIGNATURE_ALGORITHM:
+		return "badSignatureAlgorithm"
+	case ACME_ERROR_UNAUTHORIZED:
+		return "unauthorized"
+	case ACME_ERROR_ACCOUNT_DOES_NOT_EXIST:
+		return "accountDoesNotExist"
+	case ACME_ERROR_ORDER_NOT_READY:
+		return "orderNotReady"
+	case ACME_ERROR_BAD_CSR:
+		return "badCSR"
+	case ACME_ERROR_REJECTED_IDENTIFIER:
+		return "rejectedIdentifier"
+	case ACME_ERROR_UNSUPPORTED_IDENTIFIER:
+		return "unsupportedIdentifier"
+	case ACME_ERROR_INCORRECT_RESPONSE:
+		return "incorrectResponse"
+	case ACME_ERROR_CONNECTION:
+		return "connection"
+	case ACME_ERROR_DNS:
+		return "dns"
+	case ACME_ERROR_TLS:
+		return "tls"
+	default:
+		return fmt.Sprintf("AcmeErrorType(%d)", t)
+	}
+}
+
+// AcmeError is an error which can be reported to an ACME client as a problem
+// document
+type AcmeError struct {
+	errorType AcmeErrorType
+	detail    string
+}
+
+func (e *AcmeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.errorType, e.detail)
+}
+
+// Type returns the ACME error type
+func (e *AcmeError) Type() AcmeErrorType {
+	return e.errorType
+}
+
+// Detail returns a human-readable explanation which is safe to show to the
+// client
+func (e *AcmeError) Detail() string {
+	return e.detail
+}
+
+// NewAcmeError creates an ACME error
+//   - errorType: The ACME error type
+//   - format: The detail message format, see fmt.Sprintf
+func NewAcmeError(errorType AcmeErrorType, format string, args ...any) *AcmeError {
+	return &AcmeError{
+		errorType: errorType,
+		detail:    fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewAcmeError(t *testing.T) {
+	err := appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_NONCE, "nonce %s is unknown", "abc")
+
+	if err.Type() != appmodels.ACME_ERROR_BAD_NONCE {
+		t.Errorf("Type() = %v, want %v", err.Type(), appmodels.ACME_ERROR_BAD_NONCE)
+	}
+	if err.Detail() != "nonce abc is unknown" {
+		t.Errorf("Detail() = %v, want %v", err.Detail(), "nonce abc is unknown")
+	}
+	if err.Error() != "badNonce: nonce abc is unknown" {
+		t.Errorf("Error() = %v", err.Error())
+	}
+}
+
+func TestAcmeError_As(t *testing.T) {
+	wrapped := fmt.Errorf("failed: %w", appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_CSR, "bad"))
+
+	var acmeErr *appmodels.AcmeError
+	if !errors.As(wrapped, &acmeErr) {
+		t.Fatalf("errors.As() did not find AcmeError")
+	}
+	if acmeErr.Type() != appmodels.ACME_ERROR_BAD_CSR {
+		t.Errorf("Type() = %v, want %v", acmeErr.Type(), appmodels.ACME_ERROR_BAD_CSR)
+	}
+}
+
+func TestAcmeErrorType_String(t *testing.T) {
+	tests := []struct {
+		errorType appmodels.AcmeErrorType
+		want      string
+	}{
+		{appmodels.ACME_ERROR_SERVER_INTERNAL, "serverInternal"},
+		{appmodels.ACME_ERROR_MALFORMED, "malformed"},
+		{appmodels.ACME_ERROR_NOT_FOUND, "malformed"},
+		{appmodels.ACME_ERROR_ACCOUNT_DOES_NOT_EXIST, "accountDoesNotExist"},
+		{appmodels.ACME_ERROR_ORDER_NOT_READY, "orderNotReady"},
+		{appmodels.ACME_ERROR_BAD_CSR, "badCSR"},
+		{appmodels.ACME_ERROR_INCORRECT_RESPONSE, "incorrectResponse"},
+		{appmodels.AcmeErrorType(999), "AcmeErrorType(999)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.errorType.String(); got != tt.want {
+			t.Errorf("AcmeErrorType.String() = %v, want %v", got, tt.want)
+		}
+	}
+}
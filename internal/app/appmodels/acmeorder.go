@@ -0,0 +1,102 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"math/big"
+	"time"
+)
+
+// AcmeOrderModel model implements AcmeOrder
+type AcmeOrderModel struct {
+
+	// id is the unique identifier of the order
+	id string
+
+	// account is the ID of the account which owns the order
+	account string
+
+	// status is the order status
+	status AcmeStatus
+
+	// expires is the time after which the order cannot be completed
+	expires time.Time
+
+	// dnsNames are the requested DNS identifiers
+	dnsNames []string
+
+	// authorizations are the IDs of the authorizations of the order
+	authorizations []string
+
+	// certificate is the serial number of the issued certificate
+	certificate *big.Int
+
+	// err is the error which made the order invalid
+	err *AcmeError
+}
+
+func (o *AcmeOrderModel) ID() string {
+	return o.id
+}
+
+func (o *AcmeOrderModel) AccountID() string {
+	return o.account
+}
+
+func (o *AcmeOrderModel) Status() AcmeStatus {
+	return o.status
+}
+
+func (o *AcmeOrderModel) Expires() time.Time {
+	return o.expires
+}
+
+func (o *AcmeOrderModel) DNSNames() []string {
+	return o.dnsNames
+}
+
+func (o *AcmeOrderModel) AuthorizationIDs() []string {
+	return o.authorizations
+}
+
+func (o *AcmeOrderModel) CertificateSerialNumber() *big.Int {
+	return o.certificate
+}
+
+func (o *AcmeOrderModel) Error() *AcmeError {
+	return o.err
+}
+
+// NewAcmeOrder creates an ACME order model
+//   - id: The order ID
+//   - account: The ID of the account owning the order
+//   - status: The order status
+//   - expires: The expiration time
+//   - dnsNames: The requested DNS identifiers
+//   - authorizations: The authorization IDs
+//   - certificate: The serial number of the issued certificate, or nil
+//   - err: The error which made the order invalid, or nil
+func NewAcmeOrder(
+	id string,
+	account string,
+	status AcmeStatus,
+	expires time.Time,
+	dnsNames []string,
+	authorizations []string,
+	certificate *big.Int,
+	err *AcmeError,
+) *AcmeOrderModel {
+	return &AcmeOrderModel{
+		id:             id,
+		account:        account,
+		status:         status,
+		expires:        expires,
+		dnsNames:       dnsNames,
+		authorizations: authorizations,
+		certificate:    certificate,
+		err:            err,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ AcmeOrder = (*AcmeOrderModel)(nil)
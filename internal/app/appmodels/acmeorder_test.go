@@ -0,0 +1,53 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewAcmeOrder(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	certificate := big.NewInt(789)
+	acmeErr := appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_CSR, "bad")
+
+	order := appmodels.NewAcmeOrder(
+		"order1",
+		"acc1",
+		appmodels.ACME_STATUS_VALID,
+		expires,
+		[]string{"example.com", "*.example.com"},
+		[]string{"authz1", "authz2"},
+		certificate,
+		acmeErr,
+	)
+
+	if order.ID() != "order1" {
+		t.Errorf("ID() = %v, want %v", order.ID(), "order1")
+	}
+	if order.AccountID() != "acc1" {
+		t.Errorf("AccountID() = %v, want %v", order.AccountID(), "acc1")
+	}
+	if order.Status() != appmodels.ACME_STATUS_VALID {
+		t.Errorf("Status() = %v, want %v", order.Status(), appmodels.ACME_STATUS_VALID)
+	}
+	if !order.Expires().Equal(expires) {
+		t.Errorf("Expires() = %v, want %v", order.Expires(), expires)
+	}
+	if len(order.DNSNames()) != 2 || order.DNSNames()[1] != "*.example.com" {
+		t.Errorf("DNSNames() = %v", order.DNSNames())
+	}
+	if len(order.AuthorizationIDs()) != 2 || order.AuthorizationIDs()[0] != "authz1" {
+		t.Errorf("AuthorizationIDs() = %v", order.AuthorizationIDs())
+	}
+	if order.CertificateSerialNumber().Cmp(certificate) != 0 {
+		t.Errorf("CertificateSerialNumber() = %v, want %v", order.CertificateSerialNumber(), certificate)
+	}
+	if order.Error() != acmeErr {
+		t.Errorf("Error() = %v, want %v", order.Error(), acmeErr)
+	}
+}
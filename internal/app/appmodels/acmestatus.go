@@ -0,0 +1,92 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import "fmt"
+
+// AcmeStatus represents the status of an ACME (RFC 8555) resource
+type AcmeStatus int
+
+const (
+	// NIL_ACME_STATUS represents an unknown status
+	NIL_ACME_STATUS AcmeStatus = iota
+
+	// ACME_STATUS_PENDING represents a resource waiting for an action
+	ACME_STATUS_PENDING
+
+	// ACME_STATUS_READY represents an order ready to be finalized
+	ACME_STATUS_READY
+
+	// ACME_STATUS_PROCESSING represents an order or a challenge in progress
+	ACME_STATUS_PROCESSING
+
+	// ACME_STATUS_VALID represents a successfully completed resource
+	ACME_STATUS_VALID
+
+	// ACME_STATUS_INVALID represents a failed resource
+	ACME_STATUS_INVALID
+
+	// ACME_STATUS_DEACTIVATED represents a resource deactivated by the client
+	ACME_STATUS_DEACTIVATED
+
+	// ACME_STATUS_EXPIRED represents a resource which expired before completion
+	ACME_STATUS_EXPIRED
+
+	// ACME_STATUS_REVOKED represents a resource revoked by the server
+	ACME_STATUS_REVOKED
+)
+
+// String returns the status as used in the ACME protocol, e.g. "pending"
+func (s AcmeStatus) String() string {
+	switch s {
+	case ACME_STATUS_PENDING:
+		return "pending"
+	case ACME_STATUS_READY:
+		return "ready"
+	case ACME_STATUS_PROCESSING:
+		return "processing"
+	case ACME_STATUS_VALID:
+		return "valid"
+	case ACME_STATUS_INVALID:
+		return "invalid"
+	case ACME_STATUS_DEACTIVATED:
+		return "deactivated"
+	case ACME_STATUS_EXPIRED:
+		return "expired"
+	case ACME_STATUS_REVOKED:
+		return "revoked"
+	default:
+		return fmt.Sprintf("AcmeStatus(%d)", s)
+	}
+}
+
+// AcmeChallengeType represents the type of ACME challenge
+type AcmeChallengeType int
+
+const (
+	// NIL_ACME_CHALLENGE_TYPE represents an unknown challenge type
+	NIL_ACME_CHALLENGE_TYPE AcmeChallengeType = iota
+
+	// ACME_HTTP_01 represents the http-01 challenge (RFC 8555 section 8.3)
+	ACME_HTTP_01
+
+	// ACME_DNS_01 represents the dns-01 challenge (RFC 8555 section 8.4)
+	ACME_DNS_01
+
+	// ACME_TLS_ALPN_01 represents the tls-alpn-01 challenge (RFC 8737)
+	ACME_TLS_ALPN_01
+)
+
+// String returns the challenge type as used in the ACME protocol, e.g. "http-01"
+func (t AcmeChallengeType) String() string {
+	switch t {
+	case ACME_HTTP_01:
+		return "http-01"
+	case ACME_DNS_01:
+		return "dns-01"
+	case ACME_TLS_ALPN_01:
+		return "tls-alpn-01"
+	default:
+		return fmt.Sprintf("AcmeChallengeType(%d)", t)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"testing"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestAcmeStatus_String(t *testing.T) {
+	tests := []struct {
+		status appmodels.AcmeStatus
+		want   string
+	}{
+		{appmodels.ACME_STATUS_PENDING, "pending"},
+		{appmodels.ACME_STATUS_READY, "ready"},
+		{appmodels.ACME_STATUS_PROCESSING, "processing"},
+		{appmodels.ACME_STATUS_VALID, "valid"},
+		{appmodels.ACME_STATUS_INVALID, "invalid"},
+		{appmodels.ACME_STATUS_DEACTIVATED, "deactivated"},
+		{appmodels.ACME_STATUS_EXPIRED, "expired"},
+		{appmodels.ACME_STATUS_REVOKED, "revoked"},
+		{appmodels.NIL_ACME_STATUS, "AcmeStatus(0)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.String(); got != tt.want {
+			t.Errorf("AcmeStatus.String() = %v, want %v", got, tt.want)
+		}
+	}
+}
+
+func TestAcmeChallengeType_String(t *testing.T) {
+	tests := []struct {
+		challengeType appmodels.AcmeChallengeType
+		want          string
+	}{
+		{appmodels.ACME_HTTP_01, "http-01"},
+		{appmodels.ACME_DNS_01, "dns-01"},
+		{appmodels.ACME_TLS_ALPN_01, "tls-alpn-01"},
+		{appmodels.NIL_ACME_CHALLENGE_TYPE, "AcmeChallengeType(0)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.challengeType.String(); got != tt.want {
+			t.Errorf("AcmeChallengeType.String() = %v, want %v", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import "fmt"
+
+// BackupErrorType represents the kind of a backup or restore error
+type BackupErrorType int
+
+const (
+	// BACKUP_ERROR_SERVER_INTERNAL represents an internal server error
+	BACKUP_ERROR_SERVER_INTERNAL BackupErrorType = iota
+
+	// BACKUP_ERROR_BAD_REQUEST represents an archive which cannot be
+	// decrypted or verified, or an otherwise unacceptable request
+	BACKUP_ERROR_BAD_REQUEST
+
+	// BACKUP_ERROR_PERMISSION_DENIED represents a client which failed to
+	// authenticate
+	BACKUP_ERROR_PERMISSION_DENIED
+
+	// BACKUP_ERROR_NOT_FOUND represents an unknown organization
+	BACKUP_ERROR_NOT_FOUND
+
+	// BACKUP_ERROR_CONFLICT represents existing data which is newer than the
+	// backup
+	BACKUP_ERROR_CONFLICT
+
+	// BACKUP_ERROR_SEALED represents private keys which cannot be read or
+	// stored while sealed
+	BACKUP_ERROR_SEALED
+)
+
+func (t BackupErrorType) String() string {
+	switch t {
+	case BACKUP_ERROR_SERVER_INTERNAL:
+		return "BACKUP_ERROR_SERVER_INTERNAL"
+	case BACKUP_ERROR_BAD_REQUEST:
+		return "BACKUP_ERROR_BAD_REQUEST"
+	case BACKUP_ERROR_PERMISSION_DENIED:
+		return "BACKUP_ERROR_PERMISSION_DENIED"
+	case BACKUP_ERROR_NOT_FOUND:
+		return "BACKUP_ERROR_NOT_FOUND"
+	case BACKUP_ERROR_CONFLICT:
+		return "BACKUP_ERROR_CONFLICT"
+	case BACKUP_ERROR_SEALED:
+		return "BACKUP_ERROR_SEALED"
+	default:
+		return fmt.Sprintf("BackupErrorType(%d)", t)
+	}
+}
+
+// BackupError is an error which can be reported to the client of the backup
+// end-points
+type BackupError struct {
+	errorType BackupErrorType
+	detail    string
+}
+
+func (e *BackupError) Error() string {
+	return fmt.Sprintf("%s: %s", e.errorType, e.detail)
+}
+
+// Type returns the backup error type
+func (e *BackupError) Type() BackupErrorType {
+	return e.errorType
+}
+
+// Detail returns a human-readable explanation which is safe to show to the
+// client
+func (e *BackupError) Detail() string {
+	return e.detail
+}
+
+// NewBackupError creates a backup error
+//   - errorType: The backup error type
+//   - format: The detail message format, see fmt.Sprintf
+func NewBackupError(errorType BackupErrorType, format string, args ...any) *BackupError {
+	return &BackupError{
+		errorType: errorType,
+		detail:    fmt.Sprintf(format, args...),
+	}
+}
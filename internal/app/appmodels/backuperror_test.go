@@ -0,0 +1,46 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewBackupError(t *testing.T) {
+	err := appmodels.NewBackupError(appmodels.BACKUP_ERROR_CONFLICT, "newer %s", "data")
+
+	if err.Type() != appmodels.BACKUP_ERROR_CONFLICT {
+		t.Errorf("Type() = %v, want %v", err.Type(), appmodels.BACKUP_ERROR_CONFLICT)
+	}
+	if err.Detail() != "newer data" {
+		t.Errorf("Detail() = %v, want %v", err.Detail(), "newer data")
+	}
+	if err.Error() != "BACKUP_ERROR_CONFLICT: newer data" {
+		t.Errorf("Error() = %v", err.Error())
+	}
+}
+
+func TestBackupError_As(t *testing.T) {
+	wrapped := fmt.Errorf("failed: %w", appmodels.NewBackupError(appmodels.BACKUP_ERROR_NOT_FOUND, "missing"))
+
+	var backupErr *appmodels.BackupError
+	if !errors.As(wrapped, &backupErr) {
+		t.Fatalf("errors.As() did not find BackupError")
+	}
+	if backupErr.Type() != appmodels.BACKUP_ERROR_NOT_FOUND {
+		t.Errorf("Type() = %v, want %v", backupErr.Type(), appmodels.BACKUP_ERROR_NOT_FOUND)
+	}
+}
+
+func TestBackupErrorType_String(t *testing.T) {
+	if appmodels.BACKUP_ERROR_BAD_REQUEST.String() != "BACKUP_ERROR_BAD_REQUEST" {
+		t.Errorf("String() = %v", appmodels.BACKUP_ERROR_BAD_REQUEST.String())
+	}
+	if appmodels.BackupErrorType(99).String() != "BackupErrorType(99)" {
+		t.Errorf("String() = %v", appmodels.BackupErrorType(99).String())
+	}
+}
@@ -111,6 +111,20 @@ func (c *CertificateModel) SignedBy() *big.Int {
 	return c.signedBy
 }
 
+func (c *CertificateModel) SignatureAlgorithm() SignatureAlgorithm {
+	return NewSignatureAlgorithmFromX509(c.certificate.SignatureAlgorithm)
+}
+
+// SpiffeID returns the SPIFFE ID of an X.509-SVID. An X.509-SVID has exactly
+// one URI SAN with the spiffe scheme.
+func (c *CertificateModel) SpiffeID() string {
+	uris := c.certificate.URIs
+	if len(uris) != 1 || uris[0].Scheme != "spiffe" {
+		return ""
+	}
+	return uris[0].String()
+}
+
 func (c *CertificateModel) Certificate() *x509.Certificate {
 	return c.certificate
 }
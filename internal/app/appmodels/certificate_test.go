@@ -8,6 +8,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"math/big"
+	"net/url"
 	"testing"
 	"time"
 
@@ -536,3 +537,28 @@ func TestCertificate_GetNotBeforeAndAfter(t *testing.T) {
 	assert.True(t, certModel.NotAfter().Equal(mockCert.NotAfter), "NotAfter should return the correct notAfter time")
 	assert.True(t, certModel.NotAfter().Equal(notAfter), "NotAfter should return the correct notAfter time")
 }
+
+func TestCertificate_SpiffeID(t *testing.T) {
+	spiffeID, _ := url.Parse("spiffe://example.org/web")
+	other, _ := url.Parse("https://example.org/web")
+
+	tests := []struct {
+		name string
+		uris []*url.URL
+		want string
+	}{
+		{name: "No URIs", uris: nil, want: ""},
+		{name: "SPIFFE ID", uris: []*url.URL{spiffeID}, want: "spiffe://example.org/web"},
+		{name: "Other URI", uris: []*url.URL{other}, want: ""},
+		{name: "Multiple URIs", uris: []*url.URL{spiffeID, other}, want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			certData := newMockX509Certificate(false, nil, nil)
+			certData.URIs = test.uris
+			cert := appmodels.NewCertificate(big.NewInt(123), big.NewInt(1), certData)
+			assert.Equal(t, test.want, cert.SpiffeID())
+		})
+	}
+}
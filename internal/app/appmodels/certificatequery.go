@@ -0,0 +1,136 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"math/big"
+	"time"
+)
+
+// CertificateQuery describes a page of certificates of an organization.
+// Zero values of the filters match every certificate.
+type CertificateQuery struct {
+
+	// SignedBy limits the query to certificates issued by this certificate.
+	// Nil matches certificates of every issuer.
+	SignedBy *big.Int
+
+	// Type limits the query to certificates of this type
+	Type CertificateType
+
+	// Status limits the query to certificates with this status at Now
+	Status CertificateStatus
+
+	// ExpiringBefore limits the query to certificates which expire before
+	// this time
+	ExpiringBefore time.Time
+
+	// Name limits the query to certificates whose common name or a subject
+	// alternative name contains this case-insensitive substring
+	Name string
+
+	// CommonName limits the query to certificates whose common name contains
+	// this case-insensitive substring
+	CommonName string
+
+	// SubjectAlternativeName limits the query to certificates with a subject
+	// alternative name containing this case-insensitive substring
+	SubjectAlternativeName string
+
+	// Fingerprint limits the query to the certificate with this SHA-256
+	// fingerprint in lower case hex
+	Fingerprint string
+
+	// SubjectKeyId and AuthorityKeyId limit the query to certificates with
+	// these key identifiers
+	SubjectKeyId   []byte
+	AuthorityKeyId []byte
+
+	// SerialNumber limits the query to the certificate with this serial
+	// number
+	SerialNumber *big.Int
+
+	// KeyType limits the query to certificates with this public key type
+	KeyType KeyType
+
+	// Issuer limits the query to certificates whose issuer distinguished
+	// name contains this case-insensitive substring
+	Issuer string
+
+	// IssuedAfter and IssuedBefore limit the NotBefore time to the range
+	// [IssuedAfter, IssuedBefore)
+	IssuedAfter  time.Time
+	IssuedBefore time.Time
+
+	// ExpiringAfter limits the query to certificates which expire at or
+	// after this time
+	ExpiringAfter time.Time
+
+	// Archived queries the archive of the organization instead of the
+	// certificates in use
+	Archived bool
+
+	// Now is the time used to evaluate Status
+	Now time.Time
+
+	// Sort is the order of the certificates
+	Sort CertificateSortField
+
+	// Descending reverses the order
+	Descending bool
+
+	// After continues the query after the last certificate of a previous
+	// page. Nil starts from the beginning.
+	After *CertificateCursor
+
+	// Limit is the maximum number of certificates on the page. Zero means
+	// no limit.
+	Limit int
+}
+
+// CertificateCursor is the position of a certificate in the order of a
+// certificate query
+type CertificateCursor struct {
+
+	// Sort and Descending are the order the cursor was created for
+	Sort       CertificateSortField
+	Descending bool
+
+	// Time is the NotAfter or NotBefore time as Unix seconds when sorting
+	// by time
+	Time int64
+
+	// CommonName is the subject common name when sorting by it
+	CommonName string
+
+	// SerialNumber breaks ties between certificates with equal sort values
+	SerialNumber *big.Int
+}
+
+// CertificatePage is one page of a certificate query
+type CertificatePage struct {
+	Certificates []Certificate
+
+	// Next is the cursor for the following page, or nil if this was the last
+	// page
+	Next *CertificateCursor
+}
+
+// NewCertificateCursor returns the cursor of a certificate in the order of
+// the query
+func NewCertificateCursor(query CertificateQuery, certificate Certificate) *CertificateCursor {
+	cursor := &CertificateCursor{
+		Sort:         query.Sort,
+		Descending:   query.Descending,
+		SerialNumber: certificate.SerialNumber(),
+	}
+	switch query.Sort {
+	case SORT_BY_NOT_AFTER:
+		cursor.Time = certificate.NotAfter().Unix()
+	case SORT_BY_CREATED_AT:
+		cursor.Time = certificate.NotBefore().Unix()
+	case SORT_BY_COMMON_NAME:
+		cursor.CommonName = certificate.CommonName()
+	}
+	return cursor
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewCertificateCursor(t *testing.T) {
+	notBefore := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	certificate := appmodels.NewCertificate(big.NewInt(1), nil, &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	})
+
+	cursor := appmodels.NewCertificateCursor(appmodels.CertificateQuery{Sort: appmodels.SORT_BY_NOT_AFTER, Descending: true}, certificate)
+	assert.Equal(t, appmodels.SORT_BY_NOT_AFTER, cursor.Sort)
+	assert.True(t, cursor.Descending)
+	assert.Equal(t, notAfter.Unix(), cursor.Time)
+	assert.Equal(t, big.NewInt(42), cursor.SerialNumber)
+
+	cursor = appmodels.NewCertificateCursor(appmodels.CertificateQuery{Sort: appmodels.SORT_BY_CREATED_AT}, certificate)
+	assert.Equal(t, notBefore.Unix(), cursor.Time)
+
+	cursor = appmodels.NewCertificateCursor(appmodels.CertificateQuery{Sort: appmodels.SORT_BY_COMMON_NAME}, certificate)
+	assert.Equal(t, "example.com", cursor.CommonName)
+	assert.Zero(t, cursor.Time)
+}
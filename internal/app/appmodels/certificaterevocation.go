@@ -0,0 +1,51 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"math/big"
+)
+
+// CertificateRevocationModel model implements CertificateRevocation
+type CertificateRevocationModel struct {
+
+	// organization is the organization of the revoked certificate
+	organization *big.Int
+
+	// issuer is the serial number of the certificate which signed the
+	// revoked certificate
+	issuer *big.Int
+
+	revokedCertificate RevokedCertificate
+}
+
+func (r *CertificateRevocationModel) OrganizationID() *big.Int {
+	return r.organization
+}
+
+func (r *CertificateRevocationModel) IssuerSerialNumber() *big.Int {
+	return r.issuer
+}
+
+func (r *CertificateRevocationModel) RevokedCertificate() RevokedCertificate {
+	return r.revokedCertificate
+}
+
+// NewCertificateRevocation creates a certificate revocation model
+//   - organization: The organization of the revoked certificate
+//   - issuer: The serial number of the issuing certificate
+//   - revokedCertificate: The revoked certificate
+func NewCertificateRevocation(
+	organization *big.Int,
+	issuer *big.Int,
+	revokedCertificate RevokedCertificate,
+) *CertificateRevocationModel {
+	return &CertificateRevocationModel{
+		organization:       organization,
+		issuer:             issuer,
+		revokedCertificate: revokedCertificate,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ CertificateRevocation = (*CertificateRevocationModel)(nil)
@@ -0,0 +1,29 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewCertificateRevocation(t *testing.T) {
+	organization := big.NewInt(123)
+	issuer := big.NewInt(456)
+	revoked := appmodels.NewRevokedCertificate(big.NewInt(789), time.Now(), time.Now().Add(time.Hour))
+
+	revocation := appmodels.NewCertificateRevocation(organization, issuer, revoked)
+
+	if revocation.OrganizationID().Cmp(organization) != 0 {
+		t.Errorf("OrganizationID() = %v, want %v", revocation.OrganizationID(), organization)
+	}
+	if revocation.IssuerSerialNumber().Cmp(issuer) != 0 {
+		t.Errorf("IssuerSerialNumber() = %v, want %v", revocation.IssuerSerialNumber(), issuer)
+	}
+	if revocation.RevokedCertificate() != revoked {
+		t.Errorf("RevokedCertificate() = %v, want %v", revocation.RevokedCertificate(), revoked)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import "fmt"
+
+// CertificateSortField selects the order of certificates in a query. Ties
+// are always broken by the serial number.
+type CertificateSortField int
+
+const (
+	// NIL_CERTIFICATE_SORT_FIELD sorts certificates by the serial number only
+	NIL_CERTIFICATE_SORT_FIELD CertificateSortField = iota
+
+	// SORT_BY_NOT_AFTER sorts certificates by the expiration time
+	SORT_BY_NOT_AFTER
+
+	// SORT_BY_CREATED_AT sorts certificates by the issue time, i.e. the
+	// NotBefore time
+	SORT_BY_CREATED_AT
+
+	// SORT_BY_COMMON_NAME sorts certificates by the subject common name
+	SORT_BY_COMMON_NAME
+)
+
+func (f CertificateSortField) String() string {
+	switch f {
+	case SORT_BY_NOT_AFTER:
+		return "notAfter"
+	case SORT_BY_CREATED_AT:
+		return "createdAt"
+	case SORT_BY_COMMON_NAME:
+		return "commonName"
+	default:
+		return fmt.Sprintf("CertificateSortField(%d)", f)
+	}
+}
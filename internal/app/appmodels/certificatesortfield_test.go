@@ -0,0 +1,18 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestCertificateSortField_String(t *testing.T) {
+	assert.Equal(t, "notAfter", appmodels.SORT_BY_NOT_AFTER.String())
+	assert.Equal(t, "createdAt", appmodels.SORT_BY_CREATED_AT.String())
+	assert.Equal(t, "commonName", appmodels.SORT_BY_COMMON_NAME.String())
+	assert.Equal(t, "CertificateSortField(0)", appmodels.NIL_CERTIFICATE_SORT_FIELD.String())
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import "fmt"
+
+// CertificateState represents the lifecycle state of a certificate. The
+// state is derived from the validity period, revocations and newer
+// certificates; it is not stored.
+type CertificateState int
+
+const (
+	// NIL_CERTIFICATE_STATE represents an unknown state
+	NIL_CERTIFICATE_STATE CertificateState = iota
+
+	// CERTIFICATE_STATE_PENDING represents a certificate which is not valid
+	// yet
+	CERTIFICATE_STATE_PENDING
+
+	// CERTIFICATE_STATE_ACTIVE represents a certificate inside its validity
+	// period
+	CERTIFICATE_STATE_ACTIVE
+
+	// CERTIFICATE_STATE_EXPIRING represents an active certificate close to
+	// its NotAfter time
+	CERTIFICATE_STATE_EXPIRING
+
+	// CERTIFICATE_STATE_EXPIRED represents a certificate past its NotAfter
+	// time
+	CERTIFICATE_STATE_EXPIRED
+
+	// CERTIFICATE_STATE_REVOKED represents a revoked certificate
+	CERTIFICATE_STATE_REVOKED
+
+	// CERTIFICATE_STATE_SUPERSEDED represents a certificate replaced by a
+	// newer certificate with the same name, type and issuer
+	CERTIFICATE_STATE_SUPERSEDED
+)
+
+// String returns the state as used in the API, e.g. "active"
+func (s CertificateState) String() string {
+	switch s {
+	case CERTIFICATE_STATE_PENDING:
+		return "pending"
+	case CERTIFICATE_STATE_ACTIVE:
+		return "active"
+	case CERTIFICATE_STATE_EXPIRING:
+		return "expiring"
+	case CERTIFICATE_STATE_EXPIRED:
+		return "expired"
+	case CERTIFICATE_STATE_REVOKED:
+		return "revoked"
+	case CERTIFICATE_STATE_SUPERSEDED:
+		return "superseded"
+	default:
+		return fmt.Sprintf("CertificateState(%d)", s)
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestCertificateState_String(t *testing.T) {
+	assert.Equal(t, "pending", appmodels.CERTIFICATE_STATE_PENDING.String())
+	assert.Equal(t, "active", appmodels.CERTIFICATE_STATE_ACTIVE.String())
+	assert.Equal(t, "expiring", appmodels.CERTIFICATE_STATE_EXPIRING.String())
+	assert.Equal(t, "expired", appmodels.CERTIFICATE_STATE_EXPIRED.String())
+	assert.Equal(t, "revoked", appmodels.CERTIFICATE_STATE_REVOKED.String())
+	assert.Equal(t, "superseded", appmodels.CERTIFICATE_STATE_SUPERSEDED.String())
+	assert.Equal(t, "CertificateState(0)", appmodels.NIL_CERTIFICATE_STATE.String())
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"fmt"
+	"time"
+)
+
+// CertificateStatus represents the validity of a certificate at a point in
+// time
+type CertificateStatus int
+
+const (
+	// NIL_CERTIFICATE_STATUS matches every certificate
+	NIL_CERTIFICATE_STATUS CertificateStatus = iota
+
+	// VALID_CERTIFICATE represents a certificate inside its validity period
+	VALID_CERTIFICATE
+
+	// EXPIRED_CERTIFICATE represents a certificate past its NotAfter time
+	EXPIRED_CERTIFICATE
+)
+
+func (s CertificateStatus) String() string {
+	switch s {
+	case VALID_CERTIFICATE:
+		return "valid"
+	case EXPIRED_CERTIFICATE:
+		return "expired"
+	default:
+		return fmt.Sprintf("CertificateStatus(%d)", s)
+	}
+}
+
+// Matches returns true if the certificate has this status at the given time.
+// NIL_CERTIFICATE_STATUS matches every certificate.
+func (s CertificateStatus) Matches(certificate Certificate, now time.Time) bool {
+	switch s {
+	case VALID_CERTIFICATE:
+		return !now.Before(certificate.NotBefore()) && !now.After(certificate.NotAfter())
+	case EXPIRED_CERTIFICATE:
+		return now.After(certificate.NotAfter())
+	case NIL_CERTIFICATE_STATUS:
+		return true
+	default:
+		return false
+	}
+}
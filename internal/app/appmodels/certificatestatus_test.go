@@ -0,0 +1,36 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestCertificateStatus_String(t *testing.T) {
+	assert.Equal(t, "valid", appmodels.VALID_CERTIFICATE.String())
+	assert.Equal(t, "expired", appmodels.EXPIRED_CERTIFICATE.String())
+	assert.Equal(t, "CertificateStatus(0)", appmodels.NIL_CERTIFICATE_STATUS.String())
+}
+
+func TestCertificateStatus_Matches(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	certificate := appmodels.NewCertificate(big.NewInt(1), nil, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+	})
+
+	assert.True(t, appmodels.NIL_CERTIFICATE_STATUS.Matches(certificate, now))
+	assert.True(t, appmodels.VALID_CERTIFICATE.Matches(certificate, now))
+	assert.False(t, appmodels.EXPIRED_CERTIFICATE.Matches(certificate, now))
+	assert.False(t, appmodels.VALID_CERTIFICATE.Matches(certificate, now.Add(-2*time.Hour)))
+	assert.False(t, appmodels.EXPIRED_CERTIFICATE.Matches(certificate, now.Add(-2*time.Hour)))
+	assert.True(t, appmodels.EXPIRED_CERTIFICATE.Matches(certificate, now.Add(2*time.Hour)))
+}
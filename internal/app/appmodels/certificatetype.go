@@ -0,0 +1,63 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import "fmt"
+
+// CertificateType represents the role of a X.509 certificate in the
+// certificate chain of an organization
+type CertificateType int
+
+const (
+	// NIL_CERTIFICATE_TYPE matches every certificate
+	NIL_CERTIFICATE_TYPE CertificateType = iota
+
+	// ROOT_CERTIFICATE represents a self-signed CA certificate
+	ROOT_CERTIFICATE
+
+	// INTERMEDIATE_CERTIFICATE represents a CA certificate signed by another
+	// CA certificate
+	INTERMEDIATE_CERTIFICATE
+
+	// SERVER_CERTIFICATE represents a certificate for TLS server
+	// authentication
+	SERVER_CERTIFICATE
+
+	// CLIENT_CERTIFICATE represents a certificate for TLS client
+	// authentication
+	CLIENT_CERTIFICATE
+)
+
+func (t CertificateType) String() string {
+	switch t {
+	case ROOT_CERTIFICATE:
+		return "root"
+	case INTERMEDIATE_CERTIFICATE:
+		return "intermediate"
+	case SERVER_CERTIFICATE:
+		return "server"
+	case CLIENT_CERTIFICATE:
+		return "client"
+	default:
+		return fmt.Sprintf("CertificateType(%d)", t)
+	}
+}
+
+// Matches returns true if the certificate is of this type.
+// NIL_CERTIFICATE_TYPE matches every certificate.
+func (t CertificateType) Matches(certificate Certificate) bool {
+	switch t {
+	case ROOT_CERTIFICATE:
+		return certificate.IsRootCertificate()
+	case INTERMEDIATE_CERTIFICATE:
+		return certificate.IsIntermediateCertificate()
+	case SERVER_CERTIFICATE:
+		return certificate.IsServerCertificate()
+	case CLIENT_CERTIFICATE:
+		return certificate.IsClientCertificate()
+	case NIL_CERTIFICATE_TYPE:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestCertificateType_String(t *testing.T) {
+	assert.Equal(t, "root", appmodels.ROOT_CERTIFICATE.String())
+	assert.Equal(t, "intermediate", appmodels.INTERMEDIATE_CERTIFICATE.String())
+	assert.Equal(t, "server", appmodels.SERVER_CERTIFICATE.String())
+	assert.Equal(t, "client", appmodels.CLIENT_CERTIFICATE.String())
+	assert.Equal(t, "CertificateType(0)", appmodels.NIL_CERTIFICATE_TYPE.String())
+}
+
+func TestCertificateType_Matches(t *testing.T) {
+	root := appmodels.NewCertificate(big.NewInt(1), nil, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Root"},
+		Issuer:                pkix.Name{CommonName: "Root"},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	})
+	server := appmodels.NewCertificate(big.NewInt(1), big.NewInt(1), &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "server.example.com"},
+		Issuer:       pkix.Name{CommonName: "Root"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+
+	assert.True(t, appmodels.NIL_CERTIFICATE_TYPE.Matches(root))
+	assert.True(t, appmodels.ROOT_CERTIFICATE.Matches(root))
+	assert.False(t, appmodels.INTERMEDIATE_CERTIFICATE.Matches(root))
+	assert.False(t, appmodels.ROOT_CERTIFICATE.Matches(server))
+	assert.True(t, appmodels.SERVER_CERTIFICATE.Matches(server))
+	assert.False(t, appmodels.CLIENT_CERTIFICATE.Matches(server))
+	assert.False(t, appmodels.CertificateType(99).Matches(server))
+}
@@ -4,19 +4,78 @@ package appmodels
 
 // Collection implements collection of model services
 type Collection struct {
-	Organization OrganizationRepository
-	Certificate  CertificateRepository
-	PrivateKey   PrivateKeyRepository
+	Organization   OrganizationRepository
+	Certificate    CertificateRepository
+	PrivateKey     PrivateKeyRepository
+	UnitOfWork     UnitOfWorkRepository
+	SshAuthority   SshAuthorityRepository
+	SshCertificate SshCertificateRepository
+
+	CertificateRevocation CertificateRevocationRepository
+	PendingOperation      PendingOperationRepository
+
+	AcmeAccount       AcmeAccountRepository
+	AcmeOrder         AcmeOrderRepository
+	AcmeAuthorization AcmeAuthorizationRepository
+	EstLabel          EstLabelRepository
+	ScepChallenge     ScepChallengeRepository
+	VaultRole         VaultRoleRepository
 }
 
 func NewCollection(
 	organization OrganizationRepository,
 	certificate CertificateRepository,
 	privateKey PrivateKeyRepository,
+	unitOfWork UnitOfWorkRepository,
+	sshAuthority SshAuthorityRepository,
+	sshCertificate SshCertificateRepository,
+	certificateRevocation CertificateRevocationRepository,
+	pendingOperation PendingOperationRepository,
+	acmeAccount AcmeAccountRepository,
+	acmeOrder AcmeOrderRepository,
+	acmeAuthorization AcmeAuthorizationRepository,
+	estLabel EstLabelRepository,
+	scepChallenge ScepChallengeRepository,
+	vaultRole VaultRoleRepository,
 ) *Collection {
 	return &Collection{
-		Organization: organization,
-		Certificate:  certificate,
-		PrivateKey:   privateKey,
+		Organization:   organization,
+		Certificate:    certificate,
+		PrivateKey:     privateKey,
+		UnitOfWork:     unitOfWork,
+		SshAuthority:   sshAuthority,
+		SshCertificate: sshCertificate,
+
+		CertificateRevocation: certificateRevocation,
+		PendingOperation:      pendingOperation,
+
+		AcmeAccount:       acmeAccount,
+		AcmeOrder:         acmeOrder,
+		AcmeAuthorization: acmeAuthorization,
+		EstLabel:          estLabel,
+		ScepChallenge:     scepChallenge,
+		VaultRole:         vaultRole,
+	}
+}
+
+// AcmeCollection implements collection of ACME state services
+type AcmeCollection struct {
+	Account       AcmeAccountRepository
+	Order         AcmeOrderRepository
+	Authorization AcmeAuthorizationRepository
+	Nonce         AcmeNonceRepository
+}
+
+func NewAcmeCollection(
+	account AcmeAccountRepository,
+	order AcmeOrderRepository,
+	authorization AcmeAuthorizationRepository,
+	nonce AcmeNonceRepository,
+) *AcmeCollection {
+	return &AcmeCollection{
+		Account:       account,
+		Order:         order,
+		Authorization: authorization,
+		Nonce:         nonce,
 	}
 }
@@ -7,14 +7,26 @@ import (
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
 	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
 )
 
 func TestNewCollection(t *testing.T) {
 	mockOrganizationService := &appmocks.MockOrganizationService{}
 	mockCertificateService := &appmocks.MockCertificateService{}
 	mockPrivateKeyService := &appmocks.MockPrivateKeyService{}
+	mockUnitOfWorkService := &appmocks.MockUnitOfWorkService{}
+	sshAuthority := memoryrepository.NewSshAuthorityRepository()
+	sshCertificate := memoryrepository.NewSshCertificateRepository()
+	revocations := memoryrepository.NewCertificateRevocationRepository()
+	operations := memoryrepository.NewPendingOperationRepository()
+	acmeAccount := memoryrepository.NewAcmeAccountRepository()
+	acmeOrder := memoryrepository.NewAcmeOrderRepository()
+	acmeAuthorization := memoryrepository.NewAcmeAuthorizationRepository()
+	estLabel := memoryrepository.NewEstLabelRepository()
+	scepChallenge := memoryrepository.NewScepChallengeRepository()
+	vaultRole := memoryrepository.NewVaultRoleRepository()
 
-	collection := appmodels.NewCollection(mockOrganizationService, mockCertificateService, mockPrivateKeyService)
+	collection := appmodels.NewCollection(mockOrganizationService, mockCertificateService, mockPrivateKeyService, mockUnitOfWorkService, sshAuthority, sshCertificate, revocations, operations, acmeAccount, acmeOrder, acmeAuthorization, estLabel, scepChallenge, vaultRole)
 
 	if collection.Organization != mockOrganizationService {
 		t.Errorf("Certificate service was not correctly assigned")
@@ -27,4 +39,73 @@ func TestNewCollection(t *testing.T) {
 	if collection.PrivateKey != mockPrivateKeyService {
 		t.Errorf("Private Key service was not correctly assigned")
 	}
+
+	if collection.UnitOfWork != mockUnitOfWorkService {
+		t.Errorf("Unit of work service was not correctly assigned")
+	}
+
+	if collection.SshAuthority != sshAuthority {
+		t.Errorf("SSH authority service was not correctly assigned")
+	}
+
+	if collection.SshCertificate != sshCertificate {
+		t.Errorf("SSH certificate service was not correctly assigned")
+	}
+
+	if collection.CertificateRevocation != revocations {
+		t.Errorf("Certificate revocation service was not correctly assigned")
+	}
+
+	if collection.PendingOperation != operations {
+		t.Errorf("Pending operation service was not correctly assigned")
+	}
+
+	if collection.AcmeAccount != acmeAccount {
+		t.Errorf("ACME account service was not correctly assigned")
+	}
+
+	if collection.AcmeOrder != acmeOrder {
+		t.Errorf("ACME order service was not correctly assigned")
+	}
+
+	if collection.AcmeAuthorization != acmeAuthorization {
+		t.Errorf("ACME authorization service was not correctly assigned")
+	}
+
+	if collection.EstLabel != estLabel {
+		t.Errorf("EST label service was not correctly assigned")
+	}
+
+	if collection.ScepChallenge != scepChallenge {
+		t.Errorf("SCEP challenge service was not correctly assigned")
+	}
+
+	if collection.VaultRole != vaultRole {
+		t.Errorf("Vault role service was not correctly assigned")
+	}
+}
+
+func TestNewAcmeCollection(t *testing.T) {
+	account := memoryrepository.NewAcmeAccountRepository()
+	order := memoryrepository.NewAcmeOrderRepository()
+	authorization := memoryrepository.NewAcmeAuthorizationRepository()
+	nonce := memoryrepository.NewAcmeNonceRepository(0)
+
+	collection := appmodels.NewAcmeCollection(account, order, authorization, nonce)
+
+	if collection.Account != account {
+		t.Errorf("Account service was not correctly assigned")
+	}
+
+	if collection.Order != order {
+		t.Errorf("Order service was not correctly assigned")
+	}
+
+	if collection.Authorization != authorization {
+		t.Errorf("Authorization service was not correctly assigned")
+	}
+
+	if collection.Nonce != nonce {
+		t.Errorf("Nonce service was not correctly assigned")
+	}
 }
@@ -0,0 +1,68 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import "fmt"
+
+// EstErrorType represents the kind of an EST (RFC 7030) error
+type EstErrorType int
+
+const (
+	// EST_ERROR_SERVER_INTERNAL represents an internal server error
+	EST_ERROR_SERVER_INTERNAL EstErrorType = iota
+
+	// EST_ERROR_BAD_REQUEST represents a malformed or unacceptable request
+	EST_ERROR_BAD_REQUEST
+
+	// EST_ERROR_UNAUTHORIZED represents a client which failed to authenticate
+	EST_ERROR_UNAUTHORIZED
+
+	// EST_ERROR_NOT_FOUND represents an unknown label
+	EST_ERROR_NOT_FOUND
+)
+
+func (t EstErrorType) String() string {
+	switch t {
+	case EST_ERROR_SERVER_INTERNAL:
+		return "EST_ERROR_SERVER_INTERNAL"
+	case EST_ERROR_BAD_REQUEST:
+		return "EST_ERROR_BAD_REQUEST"
+	case EST_ERROR_UNAUTHORIZED:
+		return "EST_ERROR_UNAUTHORIZED"
+	case EST_ERROR_NOT_FOUND:
+		return "EST_ERROR_NOT_FOUND"
+	default:
+		return fmt.Sprintf("EstErrorType(%d)", t)
+	}
+}
+
+// EstError is an error which can be reported to an EST client
+type EstError struct {
+	errorType EstErrorType
+	detail    string
+}
+
+func (e *EstError) Error() string {
+	return fmt.Sprintf("%s: %s", e.errorType, e.detail)
+}
+
+// Type returns the EST error type
+func (e *EstError) Type() EstErrorType {
+	return e.errorType
+}
+
+// Detail returns a human-readable explanation which is safe to show to the
+// client
+func (e *EstError) Detail() string {
+	return e.detail
+}
+
+// NewEstError creates an EST error
+//   - errorType: The EST error type
+//   - format: The detail message format, see fmt.Sprintf
+func NewEstError(errorType EstErrorType, format string, args ...any) *EstError {
+	return &EstError{
+		errorType: errorType,
+		detail:    fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewEstError(t *testing.T) {
+	err := appmodels.NewEstError(appmodels.EST_ERROR_BAD_REQUEST, "bad %s", "csr")
+
+	if err.Type() != appmodels.EST_ERROR_BAD_REQUEST {
+		t.Errorf("Type() = %v, want %v", err.Type(), appmodels.EST_ERROR_BAD_REQUEST)
+	}
+	if err.Detail() != "bad csr" {
+		t.Errorf("Detail() = %v, want %v", err.Detail(), "bad csr")
+	}
+	if err.Error() != "EST_ERROR_BAD_REQUEST: bad csr" {
+		t.Errorf("Error() = %v", err.Error())
+	}
+}
+
+func TestEstError_As(t *testing.T) {
+	wrapped := fmt.Errorf("failed: %w", appmodels.NewEstError(appmodels.EST_ERROR_NOT_FOUND, "missing"))
+
+	var estErr *appmodels.EstError
+	if !errors.As(wrapped, &estErr) {
+		t.Fatalf("errors.As() did not find EstError")
+	}
+	if estErr.Type() != appmodels.EST_ERROR_NOT_FOUND {
+		t.Errorf("Type() = %v, want %v", estErr.Type(), appmodels.EST_ERROR_NOT_FOUND)
+	}
+}
+
+func TestEstErrorType_String(t *testing.T) {
+	if appmodels.EST_ERROR_UNAUTHORIZED.String() != "EST_ERROR_UNAUTHORIZED" {
+		t.Errorf("String() = %v", appmodels.EST_ERROR_UNAUTHORIZED.String())
+	}
+	if appmodels.EstErrorType(99).String() != "EstErrorType(99)" {
+		t.Errorf("String() = %v", appmodels.EstErrorType(99).String())
+	}
+}
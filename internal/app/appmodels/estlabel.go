@@ -0,0 +1,72 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"math/big"
+)
+
+// EstLabelModel model implements EstLabel
+type EstLabelModel struct {
+
+	// label is the unique path segment of the EST server, e.g. "devices"
+	label string
+
+	// organization is the organization of the issuing certificate
+	organization *big.Int
+
+	// issuer is the serial number of the issuing certificate
+	issuer *big.Int
+
+	// username is the HTTP basic authentication username, or empty
+	username string
+
+	// passwordHash is the bcrypt hash of the HTTP basic authentication
+	// password, or nil
+	passwordHash []byte
+}
+
+func (l *EstLabelModel) Label() string {
+	return l.label
+}
+
+func (l *EstLabelModel) OrganizationID() *big.Int {
+	return l.organization
+}
+
+func (l *EstLabelModel) IssuerSerialNumber() *big.Int {
+	return l.issuer
+}
+
+func (l *EstLabelModel) Username() string {
+	return l.username
+}
+
+func (l *EstLabelModel) PasswordHash() []byte {
+	return l.passwordHash
+}
+
+// NewEstLabel creates an EST label model
+//   - label: The label of the EST server
+//   - organization: The organization of the issuing certificate
+//   - issuer: The serial number of the issuing certificate
+//   - username: The HTTP basic authentication username, or empty
+//   - passwordHash: The bcrypt hash of the password, or nil
+func NewEstLabel(
+	label string,
+	organization *big.Int,
+	issuer *big.Int,
+	username string,
+	passwordHash []byte,
+) *EstLabelModel {
+	return &EstLabelModel{
+		label:        label,
+		organization: organization,
+		issuer:       issuer,
+		username:     username,
+		passwordHash: passwordHash,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ EstLabel = (*EstLabelModel)(nil)
@@ -0,0 +1,33 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewEstLabel(t *testing.T) {
+	organization := big.NewInt(123)
+	issuer := big.NewInt(456)
+
+	label := appmodels.NewEstLabel("devices", organization, issuer, "user", []byte("hash"))
+
+	if label.Label() != "devices" {
+		t.Errorf("Label() = %v, want %v", label.Label(), "devices")
+	}
+	if label.OrganizationID().Cmp(organization) != 0 {
+		t.Errorf("OrganizationID() = %v, want %v", label.OrganizationID(), organization)
+	}
+	if label.IssuerSerialNumber().Cmp(issuer) != 0 {
+		t.Errorf("IssuerSerialNumber() = %v, want %v", label.IssuerSerialNumber(), issuer)
+	}
+	if label.Username() != "user" {
+		t.Errorf("Username() = %v, want %v", label.Username(), "user")
+	}
+	if string(label.PasswordHash()) != "hash" {
+		t.Errorf("PasswordHash() = %v, want %v", label.PasswordHash(), "hash")
+	}
+}
@@ -3,10 +3,15 @@
 package appmodels
 
 import (
+	"context"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/asn1"
 	"math/big"
 	"time"
+
+	"golang.org/x/crypto/ssh"
 )
 
 // Organization describes an interface for OrganizationModel model
@@ -23,6 +28,19 @@ type Organization interface {
 
 	// Names returns the full name of the organization including department
 	Names() []string
+
+	// SignatureAlgorithm returns the default signature algorithm for
+	// certificates issued by the organization
+	SignatureAlgorithm() SignatureAlgorithm
+
+	// SpiffeTrustDomain returns the SPIFFE trust domain name of the
+	// organization, e.g. "example.org". An empty string means SPIFFE mode is
+	// not enabled.
+	SpiffeTrustDomain() string
+
+	// KeyRetentionPolicy returns the policy for private keys of leaf
+	// certificates the server generates
+	KeyRetentionPolicy() KeyRetentionPolicy
 }
 
 // Certificate describes an interface for CertificateModel model
@@ -64,6 +82,14 @@ type Certificate interface {
 	OrganizationID() *big.Int
 	OrganizationName() string
 	Organization() []string
+
+	// SignatureAlgorithm returns the algorithm the issuer used to sign this
+	// certificate
+	SignatureAlgorithm() SignatureAlgorithm
+
+	// SpiffeID returns the SPIFFE ID of an X.509-SVID, or an empty string
+	SpiffeID() string
+
 	Certificate() *x509.Certificate
 }
 
@@ -94,6 +120,104 @@ type PrivateKey interface {
 	SerialNumber() *big.Int
 }
 
+// SealedPrivateKey is the data of a private key which is encrypted with the
+// seal key. Repositories store it like other private key data, so it is
+// returned by PrivateKey.PrivateKey() when read without the seal key.
+type SealedPrivateKey interface {
+
+	// KeyType returns the type of the encrypted key
+	KeyType() KeyType
+
+	// Ciphertext returns the encrypted PKCS #8 encoding of the key
+	Ciphertext() []byte
+}
+
+// PrivateKeyShare is one share of a private key which has been split with
+// Shamir's secret sharing. Threshold shares of the same split reconstruct
+// the key.
+type PrivateKeyShare interface {
+
+	// OrganizationID returns the organization of the key
+	OrganizationID() *big.Int
+
+	// SerialNumber returns the serial number of the certificate of the key
+	SerialNumber() *big.Int
+
+	// KeyType returns the type of the key
+	KeyType() KeyType
+
+	// SplitID returns the random ID shared by the shares of one split
+	SplitID() string
+
+	// Index returns the number of the share, from 1 to Shares
+	Index() int
+
+	// Shares returns the number of shares in the split
+	Shares() int
+
+	// Threshold returns the number of shares needed to reconstruct the key
+	Threshold() int
+
+	// Data returns the share of the PKCS #8 encoding of the key
+	Data() []byte
+}
+
+// PendingOperation is a sensitive operation which runs only after enough
+// approvers have approved it
+type PendingOperation interface {
+
+	// ID returns the random ID of the operation
+	ID() string
+
+	// Type returns the type of the operation
+	Type() OperationType
+
+	// OrganizationID returns the organization of the operation
+	OrganizationID() *big.Int
+
+	// SerialNumber returns the serial number of the certificate the
+	// operation targets, or nil
+	SerialNumber() *big.Int
+
+	// Details returns the parameters of the operation, e.g. the common name
+	// of a new certificate. An approved operation only runs with the same
+	// parameters.
+	Details() string
+
+	// RequestedBy returns the name of the approver who requested the
+	// operation. Only the requester can execute it, and the requester's
+	// own approval is not accepted.
+	RequestedBy() string
+
+	Status() OperationStatus
+
+	// CreatedAt returns when the operation was requested
+	CreatedAt() time.Time
+
+	// ExpiresAt returns when the operation expires unless it has been
+	// executed
+	ExpiresAt() time.Time
+
+	// Approvals returns the decisions of the approvers in order
+	Approvals() []OperationApproval
+
+	// ExecutedAt returns when the operation was executed, or zero
+	ExecutedAt() time.Time
+}
+
+// OperationApproval is the decision of one approver on a pending operation
+type OperationApproval interface {
+
+	// Approver returns the name of the approver
+	Approver() string
+
+	// Approved returns false if the approver rejected the operation
+	Approved() bool
+
+	// CreatedAt returns the time of the decision
+	CreatedAt() time.Time
+}
+
 // RevokedCertificate describes an interface for RevokedCertificateModel model
 type RevokedCertificate interface {
 
@@ -106,6 +230,273 @@ type RevokedCertificate interface {
 	RevokedCertificate() pkix.RevokedCertificate
 }
 
+// AcmeAccount describes an interface for AcmeAccountModel model. An ACME
+// account belongs to the ACME directory of a single issuing certificate.
+type AcmeAccount interface {
+
+	// ID returns the unique identifier of the account
+	ID() string
+
+	// OrganizationID returns the organization of the ACME directory
+	OrganizationID() *big.Int
+
+	// IssuerSerialNumber returns the serial number of the issuing certificate
+	IssuerSerialNumber() *big.Int
+
+	Status() AcmeStatus
+
+	// Contact returns contact URLs, e.g. "mailto:admin@example.com"
+	Contact() []string
+
+	// PublicKey returns the account public key used to verify requests
+	PublicKey() any
+
+	// Thumbprint returns the RFC 7638 thumbprint of the account public key
+	Thumbprint() string
+
+	CreatedAt() time.Time
+}
+
+// AcmeOrder describes an interface for AcmeOrderModel model
+type AcmeOrder interface {
+	ID() string
+
+	// AccountID returns the ID of the account which owns the order
+	AccountID() string
+
+	Status() AcmeStatus
+	Expires() time.Time
+
+	// DNSNames returns the requested DNS identifiers, including wildcards
+	DNSNames() []string
+
+	// AuthorizationIDs returns the authorizations which must be completed
+	AuthorizationIDs() []string
+
+	// CertificateSerialNumber returns the serial number of the issued
+	// certificate, or nil if the order is not valid yet
+	CertificateSerialNumber() *big.Int
+
+	// Error returns the error which made the order invalid, or nil
+	Error() *AcmeError
+}
+
+// AcmeAuthorization describes an interface for AcmeAuthorizationModel model
+type AcmeAuthorization interface {
+	ID() string
+
+	// AccountID returns the ID of the account which owns the authorization
+	AccountID() string
+
+	// OrderID returns the ID of the order the authorization was created for
+	OrderID() string
+
+	Status() AcmeStatus
+	Expires() time.Time
+
+	// DNSName returns the DNS identifier without the wildcard prefix
+	DNSName() string
+
+	// Wildcard returns true if the order requested a wildcard certificate
+	Wildcard() bool
+
+	Challenges() []AcmeChallenge
+
+	// Challenge returns a challenge by its ID, or nil if not found
+	Challenge(id string) AcmeChallenge
+}
+
+// AcmeChallenge describes an interface for AcmeChallengeModel model
+type AcmeChallenge interface {
+
+	// ID returns the identifier of the challenge inside the authorization
+	ID() string
+
+	Type() AcmeChallengeType
+	Token() string
+	Status() AcmeStatus
+
+	// Validated returns the time the challenge was validated
+	Validated() time.Time
+
+	// Error returns the error from the last validation attempt, or nil
+	Error() *AcmeError
+}
+
+// EstLabel describes an interface for EstLabelModel model. A label maps an
+// EST (RFC 7030) server path to an issuing certificate.
+type EstLabel interface {
+	Label() string
+
+	// OrganizationID returns the organization of the issuing certificate
+	OrganizationID() *big.Int
+
+	// IssuerSerialNumber returns the serial number of the issuing certificate
+	IssuerSerialNumber() *big.Int
+
+	// Username returns the HTTP basic authentication username. Empty
+	// username means only TLS client certificates are accepted.
+	Username() string
+
+	// PasswordHash returns the bcrypt hash of the basic authentication password
+	PasswordHash() []byte
+}
+
+// ScepChallenge describes an interface for ScepChallengeModel model. It holds
+// the SCEP challenge password of an organization.
+type ScepChallenge interface {
+	OrganizationID() *big.Int
+
+	// PasswordHash returns the bcrypt hash of the challenge password
+	PasswordHash() []byte
+}
+
+// SshAuthority describes an interface for SshAuthorityModel model. It holds
+// the SSH certificate authority key of an organization.
+type SshAuthority interface {
+	OrganizationID() *big.Int
+
+	// ID returns the unique identifier of the CA key
+	ID() *big.Int
+
+	// PrivateKey returns the signing key of the CA
+	PrivateKey() PrivateKey
+
+	CreatedAt() time.Time
+}
+
+// SshCertificate describes an interface for SshCertificateModel model. It
+// holds an OpenSSH certificate signed by the SSH CA of an organization.
+type SshCertificate interface {
+	OrganizationID() *big.Int
+
+	// Serial returns the serial number of the certificate, which is unique
+	// within the organization
+	Serial() uint64
+
+	Type() SshCertificateType
+	KeyID() string
+	Principals() []string
+	ValidAfter() time.Time
+	ValidBefore() time.Time
+
+	// IsRevoked returns true if the certificate has been revoked
+	IsRevoked() bool
+
+	// RevokedAt returns the time of revocation, or zero if not revoked
+	RevokedAt() time.Time
+
+	// Certificate returns the signed OpenSSH certificate
+	Certificate() *ssh.Certificate
+}
+
+// VaultRole describes an interface for VaultRoleModel model. A role of the
+// HashiCorp Vault PKI API facade maps a role name to an issuing certificate
+// and the names it may issue certificates for.
+type VaultRole interface {
+	Name() string
+
+	// OrganizationID returns the organization of the issuing certificate
+	OrganizationID() *big.Int
+
+	// IssuerSerialNumber returns the serial number of the issuing certificate
+	IssuerSerialNumber() *big.Int
+
+	// AllowedDomains returns the domains certificates may be issued for
+	AllowedDomains() []string
+
+	// AllowSubdomains returns true if subdomains of the allowed domains are
+	// allowed
+	AllowSubdomains() bool
+
+	// AllowBareDomains returns true if the allowed domains themselves are
+	// allowed
+	AllowBareDomains() bool
+
+	// AllowAnyName returns true if any name is allowed
+	AllowAnyName() bool
+
+	// ServerFlag returns true if server certificates are issued. Otherwise
+	// client certificates are issued.
+	ServerFlag() bool
+
+	// KeyType returns the key type of issued private keys, or NIL_KEY_TYPE
+	// for the default
+	KeyType() KeyType
+
+	// TTL returns the default validity duration, or zero for the default
+	TTL() time.Duration
+
+	// MaxTTL returns the maximum validity duration, or zero for no limit
+	MaxTTL() time.Duration
+}
+
+// CertificateRevocation describes an interface for CertificateRevocationModel
+// model. It records a revoked certificate for the CRL of its issuer.
+type CertificateRevocation interface {
+	OrganizationID() *big.Int
+
+	// IssuerSerialNumber returns the serial number of the certificate which
+	// signed the revoked certificate
+	IssuerSerialNumber() *big.Int
+
+	RevokedCertificate() RevokedCertificate
+}
+
+// RevocationList describes an interface for RevocationListModel model. It
+// records the last CRL of an issuer, so that the CRL numbers keep growing
+// after a restart or a restore.
+type RevocationList interface {
+	OrganizationID() *big.Int
+
+	// IssuerSerialNumber returns the serial number of the certificate which
+	// signed the CRL
+	IssuerSerialNumber() *big.Int
+
+	// Number returns the CRL number
+	Number() *big.Int
+
+	// ThisUpdate returns the time the CRL was created
+	ThisUpdate() time.Time
+}
+
+// OrganizationBackup describes an interface for OrganizationBackupModel
+// model. It is the content of an organization backup archive.
+type OrganizationBackup interface {
+
+	// Version returns the format version of the archive
+	Version() int
+
+	// CreatedAt returns the time the backup was created
+	CreatedAt() time.Time
+
+	Organization() Organization
+	Certificates() []Certificate
+
+	// PrivateKeys returns the private keys which were stored when the
+	// backup was created
+	PrivateKeys() []PrivateKey
+
+	Revocations() []CertificateRevocation
+
+	// RevocationLists returns the last CRLs of the CA certificates
+	RevocationLists() []RevocationList
+
+	EstLabels() []EstLabel
+
+	// ScepChallenge returns the SCEP challenge password, or nil
+	ScepChallenge() ScepChallenge
+
+	VaultRoles() []VaultRole
+	AcmeAccounts() []AcmeAccount
+
+	// AcmeOrders returns the orders of the ACME accounts
+	AcmeOrders() []AcmeOrder
+
+	// AcmeAuthorizations returns the authorizations of the ACME orders
+	AcmeAuthorizations() []AcmeAuthorization
+}
+
 // OrganizationRepository defines the interface for storing organization models,
 // facilitating the abstraction of data access mechanisms. By declaring this
 // interface it supports easy substitution of its implementation, thereby
@@ -113,6 +504,10 @@ type RevokedCertificate interface {
 // data layer.
 type OrganizationRepository interface {
 	FindAll() ([]Organization, error)
+
+	// FindPage returns one page of organizations matching the query
+	FindPage(query OrganizationQuery) (OrganizationPage, error)
+
 	FindById(organization *big.Int) (Organization, error)
 	Save(certificate Organization) (Organization, error)
 }
@@ -127,8 +522,17 @@ type CertificateRepository interface {
 	// FindAllByOrganizationAndSignedBy returns all certificates signed by this certificate
 	FindAllByOrganizationAndSignedBy(organization *big.Int, certificate *big.Int) ([]Certificate, error)
 
+	// FindPageByOrganization returns one page of certificates of the
+	// organization matching the query
+	FindPageByOrganization(organization *big.Int, query CertificateQuery) (CertificatePage, error)
+
 	FindByOrganizationAndSerialNumber(organization *big.Int, certificate *big.Int) (Certificate, error)
 	Save(certificate Certificate) (Certificate, error)
+
+	// Archive moves a certificate into the archive of the organization.
+	// Archived certificates are only returned by FindPageByOrganization when
+	// the query asks for the archive.
+	Archive(organization *big.Int, certificate *big.Int) error
 }
 
 // PrivateKeyRepository defines the interface for storing private keys,
@@ -143,6 +547,126 @@ type PrivateKeyRepository interface {
 	Save(key PrivateKey) (PrivateKey, error)
 }
 
+// UnitOfWork collects certificates and private keys which must be stored
+// together, e.g. a new CA certificate and its private key. Commit stores
+// either all of them or none.
+type UnitOfWork interface {
+	SaveCertificate(certificate Certificate)
+	SavePrivateKey(key PrivateKey)
+
+	// Commit stores the collected changes. A unit of work can be committed
+	// only once.
+	Commit() error
+}
+
+// UnitOfWorkRepository starts units of work over the certificate and private
+// key repositories of the same storage
+type UnitOfWorkRepository interface {
+	NewUnitOfWork() UnitOfWork
+}
+
+// AcmeAccountRepository defines the interface for storing ACME accounts
+type AcmeAccountRepository interface {
+	FindById(id string) (AcmeAccount, error)
+
+	// FindByThumbprint returns the account of an ACME directory by the
+	// thumbprint of the account key
+	FindByThumbprint(organization *big.Int, issuer *big.Int, thumbprint string) (AcmeAccount, error)
+
+	// FindAllByOrganization returns the accounts of all ACME directories of
+	// the organization in the order they were created
+	FindAllByOrganization(organization *big.Int) ([]AcmeAccount, error)
+
+	Save(account AcmeAccount) (AcmeAccount, error)
+}
+
+// AcmeOrderRepository defines the interface for storing ACME orders
+type AcmeOrderRepository interface {
+	FindById(id string) (AcmeOrder, error)
+	FindAllByAccount(account string) ([]AcmeOrder, error)
+	Save(order AcmeOrder) (AcmeOrder, error)
+}
+
+// AcmeAuthorizationRepository defines the interface for storing ACME
+// authorizations and their challenges
+type AcmeAuthorizationRepository interface {
+	FindById(id string) (AcmeAuthorization, error)
+	Save(authorization AcmeAuthorization) (AcmeAuthorization, error)
+}
+
+// AcmeNonceRepository defines the interface for storing ACME anti-replay
+// nonces
+type AcmeNonceRepository interface {
+	Save(nonce string) error
+
+	// Delete removes the nonce. It returns an error if the nonce does not
+	// exist, e.g. when it was already used.
+	Delete(nonce string) error
+}
+
+// EstLabelRepository defines the interface for storing EST labels
+type EstLabelRepository interface {
+	FindByLabel(label string) (EstLabel, error)
+
+	// FindAllByOrganization returns the labels of the organization sorted
+	// by the label
+	FindAllByOrganization(organization *big.Int) ([]EstLabel, error)
+
+	Save(label EstLabel) (EstLabel, error)
+}
+
+// ScepChallengeRepository defines the interface for storing SCEP challenge
+// passwords
+type ScepChallengeRepository interface {
+	FindByOrganization(organization *big.Int) (ScepChallenge, error)
+	Save(challenge ScepChallenge) (ScepChallenge, error)
+}
+
+// SshAuthorityRepository defines the interface for storing SSH CA keys
+type SshAuthorityRepository interface {
+	FindByOrganization(organization *big.Int) (SshAuthority, error)
+	Save(authority SshAuthority) (SshAuthority, error)
+}
+
+// SshCertificateRepository defines the interface for storing signed SSH
+// certificates
+type SshCertificateRepository interface {
+	FindAllByOrganization(organization *big.Int) ([]SshCertificate, error)
+	FindByOrganizationAndSerial(organization *big.Int, serial uint64) (SshCertificate, error)
+	Save(certificate SshCertificate) (SshCertificate, error)
+}
+
+// VaultRoleRepository defines the interface for storing Vault PKI roles
+type VaultRoleRepository interface {
+	FindAllByOrganization(organization *big.Int) ([]VaultRole, error)
+	FindByOrganizationAndName(organization *big.Int, name string) (VaultRole, error)
+	Save(role VaultRole) (VaultRole, error)
+	Delete(organization *big.Int, name string) error
+}
+
+// PendingOperationRepository defines the interface for storing pending
+// operations and their approvals
+type PendingOperationRepository interface {
+
+	// FindAll returns all operations in the order they were requested
+	FindAll() ([]PendingOperation, error)
+
+	FindById(id string) (PendingOperation, error)
+	Save(operation PendingOperation) (PendingOperation, error)
+}
+
+// CertificateRevocationRepository defines the interface for storing
+// certificate revocations
+type CertificateRevocationRepository interface {
+	FindAllByOrganizationAndIssuer(organization *big.Int, issuer *big.Int) ([]CertificateRevocation, error)
+	FindByOrganizationAndSerialNumber(organization *big.Int, certificate *big.Int) (CertificateRevocation, error)
+	Save(revocation CertificateRevocation) (CertificateRevocation, error)
+
+	// FindRevocationList returns the last CRL of an issuer
+	FindRevocationList(organization *big.Int, issuer *big.Int) (RevocationList, error)
+	SaveRevocationList(revocationList RevocationList) (RevocationList, error)
+}
+
 // ApplicationController controls an application. An application may own one
 // or more organizations.
 type ApplicationController interface {
@@ -168,6 +692,9 @@ type ApplicationController interface {
 	// OrganizationCollection returns all organizations
 	OrganizationCollection() ([]Organization, error)
 
+	// OrganizationPage returns one page of organizations
+	OrganizationPage(query OrganizationQuery) (OrganizationPage, error)
+
 	// Organization returns an organization model by an organization ID
 	Organization(organization *big.Int) (Organization, error)
 
@@ -194,6 +721,9 @@ type OrganizationController interface {
 	// CertificateCollection returns all the root level certificates for the organization
 	CertificateCollection() ([]Certificate, error)
 
+	// CertificatePage returns one page of certificates of the organization
+	CertificatePage(query CertificateQuery) (CertificatePage, error)
+
 	// CertificateController returns a controller for a root certificate specified by its serial number
 	//  * serialNumber - The serial number of the root certificate
 	CertificateController(serialNumber *big.Int) (CertificateController, error)
@@ -206,6 +736,15 @@ type OrganizationController interface {
 	//  * expiration - the expiration duration
 	SetExpirationDuration(expiration time.Duration)
 
+	// SetKeyType sets the private key type used in NewRootCertificate
+	//  * keyType - the key type
+	SetKeyType(keyType KeyType)
+
+	// SetSignatureAlgorithm sets the signature algorithm used in
+	// NewRootCertificate. When not set, the organization default is used.
+	//  * signatureAlgorithm - the signature algorithm
+	SetSignatureAlgorithm(signatureAlgorithm SignatureAlgorithm)
+
 	// NewRootCertificate creates a new root certificate for the organization
 	//  * commonName - The name of the root CA
 	NewRootCertificate(commonName string) (Certificate, error)
@@ -245,6 +784,10 @@ type CertificateController interface {
 	// ChildCertificateCollection returns all child certificates
 	ChildCertificateCollection(certificateType string) ([]Certificate, error)
 
+	// ChildCertificatePage returns one page of the certificates signed by
+	// this certificate. The SignedBy field of the query is ignored.
+	ChildCertificatePage(query CertificateQuery) (CertificatePage, error)
+
 	// Certificate returns a child certificate model
 	//  * serialNumber - The serial number of the child certificate
 	ChildCertificate(serialNumber *big.Int) (Certificate, error)
@@ -273,6 +816,17 @@ type CertificateController interface {
 	//  * expiration - The expiration duration
 	SetExpirationDuration(expiration time.Duration)
 
+	// SetKeyType sets the private key type used in
+	// NewIntermediateCertificate, NewServerCertificate, or NewClientCertificate
+	//  * keyType - The key type
+	SetKeyType(keyType KeyType)
+
+	// SetSignatureAlgorithm sets the signature algorithm used in
+	// NewIntermediateCertificate, NewServerCertificate, or
+	// NewClientCertificate. When not set, the organization default is used.
+	//  * signatureAlgorithm - The signature algorithm
+	SetSignatureAlgorithm(signatureAlgorithm SignatureAlgorithm)
+
 	// NewIntermediateCertificate creates a new child certificate as an
 	// intermediate CA certificate
 	//  * commonName - The name of the intermediate CA
@@ -283,9 +837,36 @@ type CertificateController interface {
 	//     used as a common name as well.
 	NewServerCertificate(dnsNames ...string) (Certificate, PrivateKey, error)
 
+	// NewServerCertificateFromPublicKey creates a new server certificate for
+	// an existing key pair, e.g. from a certificate signing request. The
+	// private key is not known to the server.
+	//   - publicKey: The public key of the new certificate
+	//   - dnsNames: List of domain names the new certificate. The first one is
+	//     used as a common name as well.
+	NewServerCertificateFromPublicKey(publicKey PublicKey, dnsNames ...string) (Certificate, error)
+
 	// NewClientCertificate creates a new client certificate
 	//  * commonName - The name of the client
 	NewClientCertificate(commonName string) (Certificate, PrivateKey, error)
+
+	// NewClientCertificateFromPublicKey creates a new client certificate for
+	// an existing key pair, e.g. from a certificate signing request. The
+	// private key is not known to the server.
+	//  * publicKey - The public key of the new certificate
+	//  * commonName - The name of the client
+	NewClientCertificateFromPublicKey(publicKey PublicKey, commonName string) (Certificate, error)
+
+	// NewSpiffeCertificate creates a new X.509-SVID. The organization must
+	// have a SPIFFE trust domain.
+	//  * spiffeID - The SPIFFE ID in the trust domain of the organization,
+	//    e.g. "spiffe://example.org/ns/default/sa/web"
+	NewSpiffeCertificate(spiffeID string) (Certificate, PrivateKey, error)
+
+	// NewSpiffeCertificateFromPublicKey creates a new X.509-SVID for an
+	// existing public key, e.g. from a certificate signing request
+	//  * publicKey - The public key of the workload
+	//  * spiffeID - The SPIFFE ID in the trust domain of the organization
+	NewSpiffeCertificateFromPublicKey(publicKey PublicKey, spiffeID string) (Certificate, error)
 }
 
 // PrivateKeyController controls a private key owned by the certificate
@@ -315,3 +896,513 @@ type PrivateKeyController interface {
 	// controls
 	CertificateController() CertificateController
 }
+
+// AcmeController controls the ACME (RFC 8555) protocol state. Each issuing
+// certificate has its own ACME directory, which is identified by the
+// certificate controller.
+type AcmeController interface {
+
+	// NewNonce creates a new anti-replay nonce
+	NewNonce() (string, error)
+
+	// UseNonce consumes an anti-replay nonce. It returns an AcmeError if the
+	// nonce is unknown or already used.
+	UseNonce(nonce string) error
+
+	// Account returns an account of the ACME directory
+	//  * issuer - The issuing certificate of the ACME directory
+	//  * id - The account ID
+	Account(issuer CertificateController, id string) (AcmeAccount, error)
+
+	// AccountByKey returns an account of the ACME directory by its public key
+	//  * issuer - The issuing certificate of the ACME directory
+	//  * publicKey - The account public key
+	AccountByKey(issuer CertificateController, publicKey any) (AcmeAccount, error)
+
+	// NewAccount creates a new account in the ACME directory
+	//  * issuer - The issuing certificate of the ACME directory
+	//  * publicKey - The account public key
+	//  * contact - The contact URLs
+	NewAccount(issuer CertificateController, publicKey any, contact []string) (AcmeAccount, error)
+
+	// UpdateAccount changes the contact URLs of the account
+	UpdateAccount(account AcmeAccount, contact []string) (AcmeAccount, error)
+
+	// DeactivateAccount deactivates the account
+	DeactivateAccount(account AcmeAccount) (AcmeAccount, error)
+
+	// OrderCollection returns all orders of the account
+	OrderCollection(account AcmeAccount) ([]AcmeOrder, error)
+
+	// NewOrder creates a new order and its authorizations
+	//  * account - The account
+	//  * dnsNames - The requested DNS identifiers
+	NewOrder(account AcmeAccount, dnsNames []string) (AcmeOrder, error)
+
+	// Order returns an order owned by the account
+	Order(account AcmeAccount, id string) (AcmeOrder, error)
+
+	// Authorization returns an authorization owned by the account
+	Authorization(account AcmeAccount, id string) (AcmeAuthorization, error)
+
+	// ValidateChallenge validates a challenge and updates the authorization
+	// and the order. A failed validation is not an error; it is reported in
+	// the returned challenge.
+	//  * account - The account
+	//  * authorization - The authorization ID
+	//  * challenge - The challenge ID
+	ValidateChallenge(account AcmeAccount, authorization, challenge string) (AcmeAuthorization, error)
+
+	// FinalizeOrder issues the certificate of a ready order
+	//  * issuer - The issuing certificate of the ACME directory
+	//  * account - The account
+	//  * id - The order ID
+	//  * csr - The certificate signing request
+	FinalizeOrder(issuer CertificateController, account AcmeAccount, id string, csr *x509.CertificateRequest) (AcmeOrder, error)
+
+	// CertificateChain returns a certificate issued to the account followed
+	// by the issuing certificate
+	//  * issuer - The issuing certificate of the ACME directory
+	//  * account - The account
+	//  * serialNumber - The serial number of the certificate
+	CertificateChain(issuer CertificateController, account AcmeAccount, serialNumber *big.Int) ([]Certificate, error)
+}
+
+// EstController controls the EST (RFC 7030) enrollment protocol. Each label
+// is served by one issuing certificate.
+type EstController interface {
+
+	// Label returns an EST label
+	Label(label string) (EstLabel, error)
+
+	// NewLabel creates a new EST label for the issuing certificate
+	//  * issuer - The issuing certificate
+	//  * label - The label
+	//  * username - The HTTP basic authentication username, or empty
+	//  * password - The HTTP basic authentication password
+	NewLabel(issuer CertificateController, label, username, password string) (EstLabel, error)
+
+	// Issuer returns the issuing certificate controller of the label
+	Issuer(label EstLabel) (CertificateController, error)
+
+	// Authenticate checks HTTP basic authentication credentials
+	Authenticate(label EstLabel, username, password string) error
+
+	// VerifyClientCertificate checks a TLS client certificate was issued by
+	// the issuing certificate for client authentication, is valid now and is
+	// not revoked
+	VerifyClientCertificate(issuer CertificateController, certificate *x509.Certificate) error
+
+	// CACertificates returns the issuing certificate followed by its parents
+	CACertificates(issuer CertificateController) []Certificate
+
+	// CsrAttributes returns the object identifiers clients should use in
+	// their certificate signing requests
+	CsrAttributes(issuer CertificateController) []asn1.ObjectIdentifier
+
+	// Enroll issues a certificate. A request with DNS names is issued a
+	// server certificate and other requests a client certificate.
+	//  * issuer - The issuing certificate
+	//  * csr - The certificate signing request
+	Enroll(issuer CertificateController, csr *x509.CertificateRequest) (Certificate, error)
+
+	// Reenroll renews a certificate. The subject and the subject alternative
+	// names of the request must match the current certificate.
+	//  * issuer - The issuing certificate
+	//  * current - The certificate to renew, or nil if the client
+	//    authenticated using HTTP basic authentication
+	//  * csr - The certificate signing request
+	Reenroll(issuer CertificateController, current *x509.Certificate, csr *x509.CertificateRequest) (Certificate, error)
+}
+
+// ScepController controls the SCEP (RFC 8894) enrollment protocol. Each root
+// certificate has its own SCEP server with an automatically managed RA
+// certificate.
+type ScepController interface {
+
+	// SetChallengePassword sets the challenge password required in PKCSReq
+	// messages of the organization
+	//  * organization - The organization
+	//  * password - The challenge password
+	SetChallengePassword(organization *big.Int, password string) (ScepChallenge, error)
+
+	// Capabilities returns the capabilities reported in GetCACaps
+	Capabilities() []string
+
+	// RA returns the RA certificate and its private key. A new RA certificate
+	// is issued when there is none or the current one is about to expire.
+	//  * issuer - The issuing certificate
+	RA(issuer CertificateController) (Certificate, PrivateKey, error)
+
+	// CACertificates returns the issuing certificate followed by its parents
+	CACertificates(issuer CertificateController) []Certificate
+
+	// PKIOperation handles a PKCSReq or a RenewalReq message and returns a
+	// CertRep message. Requests which are rejected are answered with a
+	// failure CertRep. An error is returned only when the message cannot be
+	// answered.
+	//  * issuer - The issuing certificate
+	//  * message - The DER encoded PKIMessage
+	PKIOperation(issuer CertificateController, message []byte) ([]byte, error)
+}
+
+// SshController controls the SSH certificate authorities of organizations.
+// Each organization may have one SSH CA key which signs user and host
+// certificates.
+type SshController interface {
+
+	// Authority returns the SSH CA of the organization
+	//  * organization - The organization
+	Authority(organization *big.Int) (SshAuthority, error)
+
+	// NewAuthority creates the SSH CA of the organization. It fails if the
+	// organization already has one.
+	//  * organization - The organization
+	//  * keyType - The key type of the CA key
+	NewAuthority(organization *big.Int, keyType KeyType) (SshAuthority, error)
+
+	// Sign signs a new certificate for a public key
+	//  * organization - The organization
+	//  * publicKey - The public key to certify
+	//  * certificateType - The certificate type
+	//  * keyID - The key identifier logged by the server
+	//  * principals - The user or host names the certificate is valid for
+	//  * expiration - The validity duration, or zero for the default
+	//  * criticalOptions - The critical options
+	//  * extensions - The extensions. When nil, user certificates get the
+	//    default permit-* extensions.
+	Sign(
+		organization *big.Int,
+		publicKey ssh.PublicKey,
+		certificateType SshCertificateType,
+		keyID string,
+		principals []string,
+		expiration time.Duration,
+		criticalOptions map[string]string,
+		extensions map[string]string,
+	) (SshCertificate, error)
+
+	// Certificates returns all certificates signed by the SSH CA of the
+	// organization
+	//  * organization - The organization
+	Certificates(organization *big.Int) ([]SshCertificate, error)
+
+	// Certificate returns a certificate signed by the SSH CA of the
+	// organization
+	//  * organization - The organization
+	//  * serial - The serial number of the certificate
+	Certificate(organization *big.Int, serial uint64) (SshCertificate, error)
+
+	// Revoke revokes a certificate
+	//  * organization - The organization
+	//  * serial - The serial number of the certificate
+	Revoke(organization *big.Int, serial uint64) (SshCertificate, error)
+
+	// RevocationList returns the OpenSSH key revocation list (KRL) of the
+	// organization
+	//  * organization - The organization
+	RevocationList(organization *big.Int) ([]byte, error)
+}
+
+// VaultController controls the HashiCorp Vault PKI secrets engine API facade.
+// Each organization is served as its own mount. Errors which should be
+// reported to the Vault client are returned as *appmodels.VaultError.
+type VaultController interface {
+
+	// Authenticate checks the Vault token of a request
+	//  * token - The X-Vault-Token header
+	Authenticate(token string) error
+
+	// Role returns a role of the organization
+	//  * organization - The organization
+	//  * name - The role name
+	Role(organization *big.Int, name string) (VaultRole, error)
+
+	// Roles returns the roles of the organization
+	//  * organization - The organization
+	Roles(organization *big.Int) ([]VaultRole, error)
+
+	// SaveRole creates or replaces a role. The issuer must be a CA
+	// certificate of the organization.
+	SaveRole(role VaultRole) (VaultRole, error)
+
+	// DeleteRole deletes a role
+	//  * organization - The organization
+	//  * name - The role name
+	DeleteRole(organization *big.Int, name string) error
+
+	// Issue issues a certificate and a new private key
+	//  * role - The role
+	//  * commonName - The common name
+	//  * altNames - Additional DNS names
+	//  * ttl - The requested validity duration, or zero for the role default
+	Issue(role VaultRole, commonName string, altNames []string, ttl time.Duration) (Certificate, PrivateKey, error)
+
+	// Sign issues a certificate for a certificate signing request
+	//  * role - The role
+	//  * csr - The certificate signing request
+	//  * commonName - The common name, or empty to use the one in the request
+	//  * altNames - Additional DNS names
+	//  * ttl - The requested validity duration, or zero for the role default
+	Sign(role VaultRole, csr *x509.CertificateRequest, commonName string, altNames []string, ttl time.Duration) (Certificate, error)
+
+	// Revoke revokes a certificate of the organization
+	//  * organization - The organization
+	//  * serialNumber - The serial number of the certificate
+	Revoke(organization *big.Int, serialNumber *big.Int) (CertificateRevocation, error)
+
+	// Issuer returns the controller of an issuing certificate
+	//  * organization - The organization
+	//  * serialNumber - The serial number of the issuing certificate
+	Issuer(organization *big.Int, serialNumber *big.Int) (CertificateController, error)
+
+	// DefaultIssuer returns the newest valid root certificate of the
+	// organization
+	//  * organization - The organization
+	DefaultIssuer(organization *big.Int) (CertificateController, error)
+
+	// CACertificates returns the issuing certificate followed by its parents
+	CACertificates(issuer CertificateController) []Certificate
+
+	// RevocationList returns a DER encoded CRL signed by the issuer
+	RevocationList(issuer CertificateController) ([]byte, error)
+}
+
+// BackupController exports organizations as signed and passphrase encrypted
+// backup archives and restores them. Errors which should be reported to the
+// client are returned as *appmodels.BackupError.
+type BackupController interface {
+
+	// Authenticate returns an error unless the token is accepted
+	Authenticate(token string) error
+
+	// Backup exports an organization as an archive. The archive is signed
+	// with the newest valid root certificate of the organization.
+	//  * organization - The organization
+	//  * passphrase - The passphrase used to encrypt the archive
+	Backup(organization *big.Int, passphrase string) ([]byte, error)
+
+	// Verify decrypts an archive, verifies its signature and checks that it
+	// can be restored without changing stored data. Stored certificates and
+	// revocations which are not included in the archive are newer than the
+	// backup, and the archive cannot be restored over them unless forced.
+	//  * archive - The archive
+	//  * passphrase - The passphrase used to encrypt the archive
+	//  * force - Accept an organization with newer data
+	Verify(archive []byte, passphrase string, force bool) (OrganizationBackup, error)
+
+	// Restore verifies an archive like Verify and saves its content
+	//  * archive - The archive
+	//  * passphrase - The passphrase used to encrypt the archive
+	//  * force - Restore even if the organization has newer data
+	Restore(archive []byte, passphrase string, force bool) (OrganizationBackup, error)
+
+	// ExportKeyShares splits the private key of a root or intermediate
+	// certificate into shares, so that no single custodian holds the key
+	//  * organization - The organization
+	//  * certificate - The serial number of the certificate
+	//  * shares - The number of shares
+	//  * threshold - The number of shares needed to reconstruct the key
+	ExportKeyShares(organization, certificate *big.Int, shares, threshold int) ([]PrivateKeyShare, error)
+
+	// ImportKeyShares reconstructs the private key of a certificate from
+	// shares created by ExportKeyShares and saves it
+	//  * organization - The organization
+	//  * certificate - The serial number of the certificate
+	//  * shares - At least threshold shares of the same export
+	ImportKeyShares(organization, certificate *big.Int, shares []PrivateKeyShare) (PrivateKey, error)
+}
+
+// MigrationController copies organizations, certificates, private keys and
+// revocations from one storage to another through the repository interfaces
+type MigrationController interface {
+
+	// Migrate copies everything the target storage does not have yet. Items
+	// which the target already has with the same fingerprint are skipped, so
+	// an interrupted migration is resumed by running it again. Items which
+	// the target has with a different content are conflicts, and the
+	// migration stops without overwriting them.
+	Migrate() error
+
+	// Verify checks that the target storage has the same number of
+	// certificates, private keys and revocations for each organization of
+	// the source storage, and that their fingerprints match
+	Verify() error
+}
+
+// LifecycleController derives the lifecycle states of certificates and
+// archives certificates which are no longer in use
+type LifecycleController interface {
+
+	// Retention returns how long expired and superseded certificates are
+	// kept with the certificates in use before they are archived
+	Retention() time.Duration
+
+	// CertificateStates returns the states of certificates of an
+	// organization indexed by the decimal serial number
+	//  * organization - The organization
+	//  * certificates - The certificates, which may include archived ones
+	//  * now - The time to evaluate the states at
+	CertificateStates(organization *big.Int, certificates []Certificate, now time.Time) (map[string]CertificateState, error)
+
+	// ArchiveCertificates moves the expired and superseded certificates of
+	// an organization which have been in that state longer than the
+	// retention period into the archive. CA certificates are archived only
+	// after the certificates they have signed. Returns the archived
+	// certificates.
+	//  * organization - The organization
+	//  * now - The current time
+	ArchiveCertificates(organization *big.Int, now time.Time) ([]Certificate, error)
+
+	// Run archives the certificates of every organization on each interval
+	// until the context is cancelled
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// SealController keeps the seal key which encrypts private keys at rest.
+// The key is only held in memory, and private keys cannot be used while it
+// is sealed.
+type SealController interface {
+
+	// Authenticate returns an error unless the token is accepted
+	Authenticate(token string) error
+
+	// IsSealed returns true if the seal key is not in memory
+	IsSealed() bool
+
+	// Threshold returns the number of key shares needed to unseal, or 0 if
+	// the seal key has not been split into shares
+	Threshold() int
+
+	// Progress returns the number of key shares provided since sealing
+	Progress() int
+
+	// Unseal decrypts the seal key with the passphrase
+	//  * passphrase - The passphrase
+	Unseal(passphrase string) error
+
+	// UnsealShare adds a key share. The seal key is reconstructed once
+	// Threshold() shares have been provided.
+	//  * share - The key share
+	UnsealShare(share []byte) error
+
+	// Seal wipes the seal key and the provided key shares from memory
+	Seal()
+
+	// Encrypt encrypts data with the seal key. Returns ErrSealed while
+	// sealed.
+	//  * plaintext - The data to encrypt
+	//  * additionalData - The data which must match when decrypting
+	Encrypt(plaintext, additionalData []byte) ([]byte, error)
+
+	// Decrypt decrypts data encrypted by Encrypt. Returns ErrSealed while
+	// sealed.
+	//  * ciphertext - The encrypted data
+	//  * additionalData - The data which was used when encrypting
+	Decrypt(ciphertext, additionalData []byte) ([]byte, error)
+}
+
+// ApprovalController requires M of N designated approvers to approve
+// sensitive operations before they run. An operation which has not been
+// approved is saved as pending, and runs when it is requested again with the
+// same parameters after the approvals.
+type ApprovalController interface {
+
+	// Authenticate returns the name of the approver of the token, or an
+	// error unless the token is accepted
+	Authenticate(token string) (string, error)
+
+	// Threshold returns the number of approvals needed
+	Threshold() int
+
+	// Operations returns all operations, including executed, rejected and
+	// expired operations for auditing
+	Operations() ([]PendingOperation, error)
+
+	// Operation returns an operation
+	//  * id - The ID of the operation
+	Operation(id string) (PendingOperation, error)
+
+	// Approve records the approval of an approver. The operation is
+	// approved when the threshold is reached.
+	//  * id - The ID of the operation
+	//  * approver - The name of the approver
+	Approve(id, approver string) (PendingOperation, error)
+
+	// Reject records the rejection of an approver, which rejects the
+	// operation
+	//  * id - The ID of the operation
+	//  * approver - The name of the approver
+	Reject(id, approver string) (PendingOperation, error)
+
+	// Execute runs an approved operation once for the approver who requested
+	// it. An operation which has not been approved returns
+	// *ApprovalRequiredError without running.
+	//  * operationType - The type of the operation
+	//  * organization - The organization
+	//  * certificate - The serial number of the target certificate, or nil
+	//  * details - The parameters of the operation
+	//  * requester - The name of the approver who requests the operation.
+	//    The operation is denied without a requester.
+	//  * operation - Runs the operation. The approval can be used again if
+	//    it returns an error.
+	Execute(operationType OperationType, organization, certificate *big.Int, details string, requester string, operation func() error) error
+
+	// ExpireOperations expires the operations which were not executed in
+	// time, and returns how many expired
+	//  * now - The current time
+	ExpireOperations(now time.Time) (int, error)
+}
+
+// RequesterApplicationController is an application controller which runs
+// sensitive operations on behalf of a requester
+type RequesterApplicationController interface {
+	ApplicationController
+
+	// WithRequester returns the controller for a requester
+	//  * requester - The name of the approver who requests operations
+	WithRequester(requester string) ApplicationController
+}
+
+// RequesterBackupController is a backup controller which runs sensitive
+// operations on behalf of a requester
+type RequesterBackupController interface {
+	BackupController
+
+	// WithRequester returns the controller for a requester
+	//  * requester - The name of the approver who requests operations
+	WithRequester(requester string) BackupController
+}
+
+// TlsController provides the certificate of the TLS listener of the server.
+// The certificate is issued by a root certificate of the system
+// organization, which is created on the first start, and renewed in the
+// process before it expires.
+type TlsController interface {
+
+	// Bootstrap finds or creates the system organization and its root
+	// certificate, and reuses a still valid server certificate or issues a
+	// new one
+	Bootstrap() error
+
+	// Organization returns the system organization, or nil before Bootstrap
+	Organization() Organization
+
+	// RootCertificate returns the root certificate which issued the server
+	// certificate, or nil before Bootstrap. Clients trust this certificate.
+	RootCertificate() Certificate
+
+	// Certificate returns the server certificate, or nil before Bootstrap
+	Certificate() Certificate
+
+	// GetCertificate returns the server certificate for crypto/tls
+	GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
+
+	// Refresh renews the server certificate if it is past its half-life
+	//  * now - The current time
+	Refresh(now time.Time) error
+
+	// Run refreshes the server certificate on each interval until the
+	// context is cancelled
+	Run(ctx context.Context, interval time.Duration)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package appmodels
+
+import "fmt"
+
+// KeyRetentionPolicy defines what happens to the private keys of leaf
+// certificates the server generates. Private keys of CA certificates are
+// always stored.
+type KeyRetentionPolicy int
+
+const (
+	// NIL_KEY_RETENTION_POLICY means the default policy, KEY_RETENTION_NONE,
+	// is used.
+	NIL_KEY_RETENTION_POLICY KeyRetentionPolicy = iota
+
+	// KEY_RETENTION_NONE means leaf private keys are returned to the
+	// requester once and never stored
+	KEY_RETENTION_NONE
+
+	// KEY_RETENTION_ESCROW means leaf private keys are stored for recovery,
+	// e.g. in backups, but the server does not use or hand them out
+	KEY_RETENTION_ESCROW
+
+	// KEY_RETENTION_RETAIN means leaf private keys are stored and available
+	// to the server like the keys of CA certificates
+	KEY_RETENTION_RETAIN
+)
+
+func (p KeyRetentionPolicy) String() string {
+	switch p {
+	case KEY_RETENTION_NONE:
+		return "NONE"
+	case KEY_RETENTION_ESCROW:
+		return "ESCROW"
+	case KEY_RETENTION_RETAIN:
+		return "RETAIN"
+	default:
+		return fmt.Sprintf("KeyRetentionPolicy(%d)", p)
+	}
+}
+
+// StoresLeafKeys returns true if the private keys of leaf certificates are
+// saved to the repository
+func (p KeyRetentionPolicy) StoresLeafKeys() bool {
+	return p == KEY_RETENTION_ESCROW || p == KEY_RETENTION_RETAIN
+}
+
+// ReleasesLeafKeys returns true if the stored private keys of leaf
+// certificates may be read by the server
+func (p KeyRetentionPolicy) ReleasesLeafKeys() bool {
+	return p == KEY_RETENTION_RETAIN
+}
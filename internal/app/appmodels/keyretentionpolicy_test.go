@@ -0,0 +1,37 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestKeyRetentionPolicy_String(t *testing.T) {
+	assert.Equal(t, "NONE", appmodels.KEY_RETENTION_NONE.String())
+	assert.Equal(t, "ESCROW", appmodels.KEY_RETENTION_ESCROW.String())
+	assert.Equal(t, "RETAIN", appmodels.KEY_RETENTION_RETAIN.String())
+	assert.Equal(t, "KeyRetentionPolicy(0)", appmodels.NIL_KEY_RETENTION_POLICY.String())
+}
+
+func TestKeyRetentionPolicy_StoresAndReleasesLeafKeys(t *testing.T) {
+	tests := []struct {
+		policy   appmodels.KeyRetentionPolicy
+		stores   bool
+		releases bool
+	}{
+		{appmodels.NIL_KEY_RETENTION_POLICY, false, false},
+		{appmodels.KEY_RETENTION_NONE, false, false},
+		{appmodels.KEY_RETENTION_ESCROW, true, false},
+		{appmodels.KEY_RETENTION_RETAIN, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			assert.Equal(t, tt.stores, tt.policy.StoresLeafKeys())
+			assert.Equal(t, tt.releases, tt.policy.ReleasesLeafKeys())
+		})
+	}
+}
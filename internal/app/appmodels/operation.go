@@ -0,0 +1,159 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"errors"
+	"fmt"
+)
+
+// OperationType represents a sensitive operation which may require approval
+type OperationType int
+
+const (
+	// NIL_OPERATION_TYPE represents an unknown operation
+	NIL_OPERATION_TYPE OperationType = iota
+
+	// OPERATION_CREATE_ROOT_CERTIFICATE represents creating a root certificate
+	OPERATION_CREATE_ROOT_CERTIFICATE
+
+	// OPERATION_REVOKE_ROOT_CERTIFICATE represents revoking a root certificate
+	OPERATION_REVOKE_ROOT_CERTIFICATE
+
+	// OPERATION_CREATE_INTERMEDIATE_CERTIFICATE represents creating an
+	// intermediate certificate
+	OPERATION_CREATE_INTERMEDIATE_CERTIFICATE
+
+	// OPERATION_EXPORT_PRIVATE_KEY represents exporting the private key of a
+	// CA certificate as key shares
+	OPERATION_EXPORT_PRIVATE_KEY
+
+	// OPERATION_BACKUP_ORGANIZATION represents exporting a backup of an
+	// organization, which includes the private keys of its CA certificates
+	OPERATION_BACKUP_ORGANIZATION
+
+	// OPERATION_RESTORE_ORGANIZATION represents restoring an organization
+	// from a backup, which replaces its certificates and private keys
+	OPERATION_RESTORE_ORGANIZATION
+
+	// OPERATION_IMPORT_PRIVATE_KEY represents importing the private key of a
+	// CA certificate from key shares
+	OPERATION_IMPORT_PRIVATE_KEY
+)
+
+// String returns the operation type as used in the REST API, e.g.
+// "create-root-certificate"
+func (t OperationType) String() string {
+	switch t {
+	case OPERATION_CREATE_ROOT_CERTIFICATE:
+		return "create-root-certificate"
+	case OPERATION_REVOKE_ROOT_CERTIFICATE:
+		return "revoke-root-certificate"
+	case OPERATION_CREATE_INTERMEDIATE_CERTIFICATE:
+		return "create-intermediate-certificate"
+	case OPERATION_EXPORT_PRIVATE_KEY:
+		return "export-private-key"
+	case OPERATION_BACKUP_ORGANIZATION:
+		return "backup-organization"
+	case OPERATION_RESTORE_ORGANIZATION:
+		return "restore-organization"
+	case OPERATION_IMPORT_PRIVATE_KEY:
+		return "import-private-key"
+	default:
+		return fmt.Sprintf("OperationType(%d)", t)
+	}
+}
+
+// OperationStatus represents the status of a pending operation
+type OperationStatus int
+
+const (
+	// NIL_OPERATION_STATUS represents an unknown status
+	NIL_OPERATION_STATUS OperationStatus = iota
+
+	// OPERATION_STATUS_PENDING represents an operation waiting for approvals
+	OPERATION_STATUS_PENDING
+
+	// OPERATION_STATUS_APPROVED represents an operation which has enough
+	// approvals and runs when it is requested again
+	OPERATION_STATUS_APPROVED
+
+	// OPERATION_STATUS_EXECUTING represents an approved operation which is
+	// running
+	OPERATION_STATUS_EXECUTING
+
+	// OPERATION_STATUS_EXECUTED represents an operation which has run. It
+	// cannot run again without new approvals.
+	OPERATION_STATUS_EXECUTED
+
+	// OPERATION_STATUS_REJECTED represents an operation rejected by an
+	// approver
+	OPERATION_STATUS_REJECTED
+
+	// OPERATION_STATUS_EXPIRED represents an operation which was not
+	// approved or executed in time
+	OPERATION_STATUS_EXPIRED
+)
+
+// String returns the status as used in the REST API, e.g. "pending"
+func (s OperationStatus) String() string {
+	switch s {
+	case OPERATION_STATUS_PENDING:
+		return "pending"
+	case OPERATION_STATUS_APPROVED:
+		return "approved"
+	case OPERATION_STATUS_EXECUTING:
+		return "executing"
+	case OPERATION_STATUS_EXECUTED:
+		return "executed"
+	case OPERATION_STATUS_REJECTED:
+		return "rejected"
+	case OPERATION_STATUS_EXPIRED:
+		return "expired"
+	default:
+		return fmt.Sprintf("OperationStatus(%d)", s)
+	}
+}
+
+// ErrApprovalPermissionDenied is returned when an approver token is not
+// accepted
+var ErrApprovalPermissionDenied = errors.New("approval: permission denied")
+
+// ErrOperationRequester is returned when the requester of an operation
+// approves it, or when someone else than the requester executes it
+var ErrOperationRequester = errors.New("operation belongs to another requester")
+
+// ErrOperationNotFound is returned for an unknown pending operation
+var ErrOperationNotFound = errors.New("operation not found")
+
+// ErrOperationNotPending is returned when an operation which is no longer
+// pending is approved or rejected
+var ErrOperationNotPending = errors.New("operation is not pending")
+
+// ErrOperationAlreadyDecided is returned when an approver approves or
+// rejects an operation a second time
+var ErrOperationAlreadyDecided = errors.New("approver has decided already")
+
+// ApprovalRequiredError is returned when a sensitive operation is requested
+// before it has been approved. The request must be repeated once the
+// operation has been approved.
+type ApprovalRequiredError struct {
+	operation PendingOperation
+}
+
+func (e *ApprovalRequiredError) Error() string {
+	return fmt.Sprintf("approval required: %s %s", e.operation.Type(), e.operation.ID())
+}
+
+// Operation returns the operation waiting for approvals
+func (e *ApprovalRequiredError) Operation() PendingOperation {
+	return e.operation
+}
+
+// NewApprovalRequiredError creates an approval required error
+//   - operation: The operation waiting for approvals
+func NewApprovalRequiredError(operation PendingOperation) *ApprovalRequiredError {
+	return &ApprovalRequiredError{
+		operation: operation,
+	}
+}
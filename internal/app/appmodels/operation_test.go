@@ -0,0 +1,61 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestOperationType_String(t *testing.T) {
+	tests := []struct {
+		operationType appmodels.OperationType
+		want          string
+	}{
+		{appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, "create-root-certificate"},
+		{appmodels.OPERATION_REVOKE_ROOT_CERTIFICATE, "revoke-root-certificate"},
+		{appmodels.OPERATION_CREATE_INTERMEDIATE_CERTIFICATE, "create-intermediate-certificate"},
+		{appmodels.OPERATION_EXPORT_PRIVATE_KEY, "export-private-key"},
+		{appmodels.OPERATION_BACKUP_ORGANIZATION, "backup-organization"},
+		{appmodels.NIL_OPERATION_TYPE, "OperationType(0)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.operationType.String(); got != tt.want {
+			t.Errorf("OperationType.String() = %v, want %v", got, tt.want)
+		}
+	}
+}
+
+func TestOperationStatus_String(t *testing.T) {
+	tests := []struct {
+		status appmodels.OperationStatus
+		want   string
+	}{
+		{appmodels.OPERATION_STATUS_PENDING, "pending"},
+		{appmodels.OPERATION_STATUS_APPROVED, "approved"},
+		{appmodels.OPERATION_STATUS_EXECUTING, "executing"},
+		{appmodels.OPERATION_STATUS_EXECUTED, "executed"},
+		{appmodels.OPERATION_STATUS_REJECTED, "rejected"},
+		{appmodels.OPERATION_STATUS_EXPIRED, "expired"},
+		{appmodels.NIL_OPERATION_STATUS, "OperationStatus(0)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.String(); got != tt.want {
+			t.Errorf("OperationStatus.String() = %v, want %v", got, tt.want)
+		}
+	}
+}
+
+func TestApprovalRequiredError(t *testing.T) {
+	operation := appmodels.NewPendingOperation("id", appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, big.NewInt(1), nil, "", "alice", appmodels.OPERATION_STATUS_PENDING, time.Now(), time.Now(), nil, time.Time{})
+	err := appmodels.NewApprovalRequiredError(operation)
+	assert.Equal(t, operation, err.Operation())
+	assert.Equal(t, "approval required: create-root-certificate id", err.Error())
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"time"
+)
+
+// OperationApprovalModel model implements OperationApproval
+type OperationApprovalModel struct {
+
+	// approver is the name of the approver
+	approver string
+
+	// approved is false if the approver rejected the operation
+	approved bool
+
+	// createdAt is the time of the decision
+	createdAt time.Time
+}
+
+func (a *OperationApprovalModel) Approver() string {
+	return a.approver
+}
+
+func (a *OperationApprovalModel) Approved() bool {
+	return a.approved
+}
+
+func (a *OperationApprovalModel) CreatedAt() time.Time {
+	return a.createdAt
+}
+
+// NewOperationApproval creates a model of an approver decision
+//   - approver is the name of the approver
+//   - approved is false if the approver rejected the operation
+//   - createdAt is the time of the decision
+func NewOperationApproval(
+	approver string,
+	approved bool,
+	createdAt time.Time,
+) *OperationApprovalModel {
+	return &OperationApprovalModel{
+		approver:  approver,
+		approved:  approved,
+		createdAt: createdAt,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ OperationApproval = (*OperationApprovalModel)(nil)
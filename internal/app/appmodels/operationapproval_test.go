@@ -0,0 +1,20 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewOperationApproval(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	approval := appmodels.NewOperationApproval("alice", false, createdAt)
+	assert.Equal(t, "alice", approval.Approver())
+	assert.False(t, approval.Approved())
+	assert.Equal(t, createdAt, approval.CreatedAt())
+}
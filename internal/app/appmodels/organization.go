@@ -11,6 +11,16 @@ type OrganizationModel struct {
 	id    *big.Int
 	slug  string
 	names []string
+
+	// signatureAlgorithm is the default signature algorithm for certificates
+	// issued by the organization
+	signatureAlgorithm SignatureAlgorithm
+
+	// spiffeTrustDomain is the SPIFFE trust domain name, or empty
+	spiffeTrustDomain string
+
+	// keyRetentionPolicy defines if leaf private keys are stored
+	keyRetentionPolicy KeyRetentionPolicy
 }
 
 // ID returns the numeric unique identifier for this organization
@@ -40,16 +50,43 @@ func (o *OrganizationModel) Names() []string {
 	return sliceCopy
 }
 
+// SignatureAlgorithm returns the default signature algorithm for certificates
+// issued by the organization
+func (o *OrganizationModel) SignatureAlgorithm() SignatureAlgorithm {
+	return o.signatureAlgorithm
+}
+
+// SpiffeTrustDomain returns the SPIFFE trust domain name of the organization,
+// or an empty string when SPIFFE mode is not enabled
+func (o *OrganizationModel) SpiffeTrustDomain() string {
+	return o.spiffeTrustDomain
+}
+
+// KeyRetentionPolicy returns the policy for private keys of leaf
+// certificates. The NIL value is returned as KEY_RETENTION_NONE.
+func (o *OrganizationModel) KeyRetentionPolicy() KeyRetentionPolicy {
+	if o.keyRetentionPolicy == NIL_KEY_RETENTION_POLICY {
+		return KEY_RETENTION_NONE
+	}
+	return o.keyRetentionPolicy
+}
+
 // NewOrganization creates a organization model from existing data
 func NewOrganization(
 	id *big.Int,
 	slug string,
 	names []string,
+	signatureAlgorithm SignatureAlgorithm,
+	spiffeTrustDomain string,
+	keyRetentionPolicy KeyRetentionPolicy,
 ) *OrganizationModel {
 	return &OrganizationModel{
-		id:    id,
-		slug:  slug,
-		names: names,
+		id:                 id,
+		slug:               slug,
+		names:              names,
+		signatureAlgorithm: signatureAlgorithm,
+		spiffeTrustDomain:  spiffeTrustDomain,
+		keyRetentionPolicy: keyRetentionPolicy,
 	}
 }
 
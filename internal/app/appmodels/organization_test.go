@@ -13,7 +13,7 @@ func TestNewOrganization(t *testing.T) {
 	orgID := big.NewInt(123)
 	orgSlug := "org789"
 	names := []string{"Test Org", "Test Org Department"}
-	org := appmodels.NewOrganization(orgID, orgSlug, names)
+	org := appmodels.NewOrganization(orgID, orgSlug, names, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
 
 	if org.ID() != orgID {
 		t.Errorf("ID() = %s, want %s", org.ID(), orgID)
@@ -33,7 +33,7 @@ func TestNewOrganization(t *testing.T) {
 func TestOrganization_ID(t *testing.T) {
 	orgID := big.NewInt(1)
 	orgSlug := "org456"
-	org := appmodels.NewOrganization(orgID, orgSlug, nil)
+	org := appmodels.NewOrganization(orgID, orgSlug, nil, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
 
 	if got := org.ID(); got != orgID {
 		t.Errorf("ID() = %s, want = %s", got, orgID)
@@ -43,7 +43,7 @@ func TestOrganization_ID(t *testing.T) {
 func TestOrganization_Slug(t *testing.T) {
 	orgID := big.NewInt(1)
 	orgSlug := "org456"
-	org := appmodels.NewOrganization(orgID, orgSlug, nil)
+	org := appmodels.NewOrganization(orgID, orgSlug, nil, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
 
 	if got := org.Slug(); got != orgSlug {
 		t.Errorf("ID() = %s, want = %s", got, orgID)
@@ -54,7 +54,7 @@ func TestOrganization_Name(t *testing.T) {
 	orgID := big.NewInt(1)
 	orgSlug := "org789"
 	names := []string{"Primary Name", "Secondary Name"}
-	org := appmodels.NewOrganization(orgID, orgSlug, names)
+	org := appmodels.NewOrganization(orgID, orgSlug, names, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
 
 	if got := org.Name(); got != names[0] {
 		t.Errorf("Name() = %s, want = %s", got, names[0])
@@ -64,7 +64,7 @@ func TestOrganization_Name(t *testing.T) {
 func TestOrganization_Name_NoNames(t *testing.T) {
 	orgID := big.NewInt(1)
 	orgSlug := "orgNoNames"
-	org := appmodels.NewOrganization(orgID, orgSlug, []string{})
+	org := appmodels.NewOrganization(orgID, orgSlug, []string{}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
 	if name := org.Name(); name != "" {
 		t.Errorf("Name() with no names should return an empty string, got: %s", name)
 	}
@@ -74,10 +74,18 @@ func TestOrganization_Names(t *testing.T) {
 	orgID := big.NewInt(1)
 	orgSlug := "org101112"
 	names := []string{"Primary Name", "Secondary Name"}
-	org := appmodels.NewOrganization(orgID, orgSlug, names)
+	org := appmodels.NewOrganization(orgID, orgSlug, names, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
 
 	gotNames := org.Names()
 	if len(gotNames) != len(names) || gotNames[0] != names[0] || gotNames[1] != names[1] {
 		t.Errorf("Names() got = %v, want = %v", gotNames, names)
 	}
 }
+
+func TestOrganization_SpiffeTrustDomain(t *testing.T) {
+	org := appmodels.NewOrganization(big.NewInt(1), "org131415", nil, appmodels.NIL_SIGNATURE_ALGORITHM, "example.org", appmodels.NIL_KEY_RETENTION_POLICY)
+
+	if got := org.SpiffeTrustDomain(); got != "example.org" {
+		t.Errorf("SpiffeTrustDomain() = %s, want = %s", got, "example.org")
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"time"
+)
+
+// OrganizationBackupModel model implements OrganizationBackup
+type OrganizationBackupModel struct {
+
+	// version is the format version of the archive
+	version int
+
+	// createdAt is the time the backup was created
+	createdAt time.Time
+
+	organization Organization
+	certificates []Certificate
+	privateKeys  []PrivateKey
+	revocations  []CertificateRevocation
+
+	revocationLists []RevocationList
+
+	estLabels          []EstLabel
+	scepChallenge      ScepChallenge
+	vaultRoles         []VaultRole
+	acmeAccounts       []AcmeAccount
+	acmeOrders         []AcmeOrder
+	acmeAuthorizations []AcmeAuthorization
+}
+
+func (b *OrganizationBackupModel) Version() int {
+	return b.version
+}
+
+func (b *OrganizationBackupModel) CreatedAt() time.Time {
+	return b.createdAt
+}
+
+func (b *OrganizationBackupModel) Organization() Organization {
+	return b.organization
+}
+
+func (b *OrganizationBackupModel) Certificates() []Certificate {
+	return b.certificates
+}
+
+func (b *OrganizationBackupModel) PrivateKeys() []PrivateKey {
+	return b.privateKeys
+}
+
+func (b *OrganizationBackupModel) Revocations() []CertificateRevocation {
+	return b.revocations
+}
+
+func (b *OrganizationBackupModel) RevocationLists() []RevocationList {
+	return b.revocationLists
+}
+
+func (b *OrganizationBackupModel) EstLabels() []EstLabel {
+	return b.estLabels
+}
+
+func (b *OrganizationBackupModel) ScepChallenge() ScepChallenge {
+	return b.scepChallenge
+}
+
+func (b *OrganizationBackupModel) VaultRoles() []VaultRole {
+	return b.vaultRoles
+}
+
+func (b *OrganizationBackupModel) AcmeAccounts() []AcmeAccount {
+	return b.acmeAccounts
+}
+
+func (b *OrganizationBackupModel) AcmeOrders() []AcmeOrder {
+	return b.acmeOrders
+}
+
+func (b *OrganizationBackupModel) AcmeAuthorizations() []AcmeAuthorization {
+	return b.acmeAuthorizations
+}
+
+// NewOrganizationBackup creates an organization backup model
+//   - version: The format version of the archive
+//   - createdAt: The time the backup was created
+//   - organization: The organization
+//   - certificates: The certificates of the organization
+//   - privateKeys: The stored private keys of the certificates
+//   - revocations: The certificate revocations of the organization
+//   - revocationLists: The last CRLs of the CA certificates
+//   - estLabels: The EST labels of the organization
+//   - scepChallenge: The SCEP challenge password, or nil
+//   - vaultRoles: The Vault roles of the organization
+//   - acmeAccounts: The ACME accounts of the organization
+//   - acmeOrders: The orders of the ACME accounts
+//   - acmeAuthorizations: The authorizations of the ACME orders
+func NewOrganizationBackup(
+	version int,
+	createdAt time.Time,
+	organization Organization,
+	certificates []Certificate,
+	privateKeys []PrivateKey,
+	revocations []CertificateRevocation,
+	revocationLists []RevocationList,
+	estLabels []EstLabel,
+	scepChallenge ScepChallenge,
+	vaultRoles []VaultRole,
+	acmeAccounts []AcmeAccount,
+	acmeOrders []AcmeOrder,
+	acmeAuthorizations []AcmeAuthorization,
+) *OrganizationBackupModel {
+	return &OrganizationBackupModel{
+		version:      version,
+		createdAt:    createdAt,
+		organization: organization,
+		certificates: certificates,
+		privateKeys:  privateKeys,
+		revocations:  revocations,
+
+		revocationLists: revocationLists,
+
+		estLabels:          estLabels,
+		scepChallenge:      scepChallenge,
+		vaultRoles:         vaultRoles,
+		acmeAccounts:       acmeAccounts,
+		acmeOrders:         acmeOrders,
+		acmeAuthorizations: acmeAuthorizations,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ OrganizationBackup = (*OrganizationBackupModel)(nil)
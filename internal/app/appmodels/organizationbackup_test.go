@@ -0,0 +1,66 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewOrganizationBackup(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	organizationID := big.NewInt(10)
+	organization := appmodels.NewOrganization(organizationID, "test", []string{"Test"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
+	certificates := []appmodels.Certificate{
+		appmodels.NewCertificate(organizationID, nil, &x509.Certificate{SerialNumber: big.NewInt(1)}),
+	}
+	privateKeys := []appmodels.PrivateKey{
+		appmodels.NewPrivateKey(organizationID, big.NewInt(1), appmodels.ECDSA_P256, nil),
+	}
+	revocations := []appmodels.CertificateRevocation{
+		appmodels.NewCertificateRevocation(organizationID, big.NewInt(1), appmodels.NewRevokedCertificate(big.NewInt(2), createdAt, createdAt)),
+	}
+
+	revocationLists := []appmodels.RevocationList{
+		appmodels.NewRevocationList(organizationID, big.NewInt(1), big.NewInt(3), createdAt),
+	}
+
+	estLabels := []appmodels.EstLabel{
+		appmodels.NewEstLabel("devices", organizationID, big.NewInt(1), "alice", []byte("hash")),
+	}
+	scepChallenge := appmodels.NewScepChallenge(organizationID, []byte("hash"))
+	vaultRoles := []appmodels.VaultRole{
+		appmodels.NewVaultRole("web", organizationID, big.NewInt(1), []string{"example.com"}, true, false, false, true, appmodels.NIL_KEY_TYPE, time.Hour, time.Hour),
+	}
+	acmeAccounts := []appmodels.AcmeAccount{
+		appmodels.NewAcmeAccount("account", organizationID, big.NewInt(1), appmodels.ACME_STATUS_VALID, nil, nil, "thumbprint", createdAt),
+	}
+	acmeOrders := []appmodels.AcmeOrder{
+		appmodels.NewAcmeOrder("order", "account", appmodels.ACME_STATUS_PENDING, createdAt, []string{"example.com"}, []string{"authorization"}, nil, nil),
+	}
+	acmeAuthorizations := []appmodels.AcmeAuthorization{
+		appmodels.NewAcmeAuthorization("authorization", "account", "order", appmodels.ACME_STATUS_PENDING, createdAt, "example.com", false, nil),
+	}
+
+	backup := appmodels.NewOrganizationBackup(1, createdAt, organization, certificates, privateKeys, revocations, revocationLists, estLabels, scepChallenge, vaultRoles, acmeAccounts, acmeOrders, acmeAuthorizations)
+
+	assert.Equal(t, 1, backup.Version())
+	assert.Equal(t, createdAt, backup.CreatedAt())
+	assert.Equal(t, organization, backup.Organization())
+	assert.Equal(t, certificates, backup.Certificates())
+	assert.Equal(t, privateKeys, backup.PrivateKeys())
+	assert.Equal(t, revocations, backup.Revocations())
+	assert.Equal(t, revocationLists, backup.RevocationLists())
+	assert.Equal(t, estLabels, backup.EstLabels())
+	assert.Equal(t, scepChallenge, backup.ScepChallenge())
+	assert.Equal(t, vaultRoles, backup.VaultRoles())
+	assert.Equal(t, acmeAccounts, backup.AcmeAccounts())
+	assert.Equal(t, acmeOrders, backup.AcmeOrders())
+	assert.Equal(t, acmeAuthorizations, backup.AcmeAuthorizations())
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import "math/big"
+
+// OrganizationQuery describes a page of organizations in the order of their
+// IDs
+type OrganizationQuery struct {
+
+	// Name limits the query to organizations whose slug or a name contains
+	// this case-insensitive substring. An empty name matches every
+	// organization.
+	Name string
+
+	// After continues the query after this organization ID. Nil starts from
+	// the beginning.
+	After *big.Int
+
+	// Limit is the maximum number of organizations on the page. Zero means
+	// no limit.
+	Limit int
+}
+
+// OrganizationPage is one page of an organization query
+type OrganizationPage struct {
+	Organizations []Organization
+
+	// Next is the ID to continue the following page from, or nil if this
+	// was the last page
+	Next *big.Int
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"math/big"
+	"time"
+)
+
+// PendingOperationModel model implements PendingOperation
+type PendingOperationModel struct {
+
+	// id is the random ID of the operation
+	id string
+
+	// operationType is the type of the operation
+	operationType OperationType
+
+	// organization is the organization of the operation
+	organization *big.Int
+
+	// certificate is the serial number of the certificate the operation
+	// targets, or nil
+	certificate *big.Int
+
+	// details describe the parameters of the operation
+	details string
+
+	// requestedBy is the name of the approver who requested the operation
+	requestedBy string
+
+	// status is the status of the operation
+	status OperationStatus
+
+	// createdAt is when the operation was requested
+	createdAt time.Time
+
+	// expiresAt is when the operation expires unless it has been executed
+	expiresAt time.Time
+
+	// approvals are the decisions of the approvers in order
+	approvals []OperationApproval
+
+	// executedAt is when the operation was executed, or zero
+	executedAt time.Time
+}
+
+func (o *PendingOperationModel) ID() string {
+	return o.id
+}
+
+func (o *PendingOperationModel) Type() OperationType {
+	return o.operationType
+}
+
+func (o *PendingOperationModel) OrganizationID() *big.Int {
+	return o.organization
+}
+
+func (o *PendingOperationModel) SerialNumber() *big.Int {
+	return o.certificate
+}
+
+func (o *PendingOperationModel) Details() string {
+	return o.details
+}
+
+func (o *PendingOperationModel) RequestedBy() string {
+	return o.requestedBy
+}
+
+func (o *PendingOperationModel) Status() OperationStatus {
+	return o.status
+}
+
+func (o *PendingOperationModel) CreatedAt() time.Time {
+	return o.createdAt
+}
+
+func (o *PendingOperationModel) ExpiresAt() time.Time {
+	return o.expiresAt
+}
+
+func (o *PendingOperationModel) Approvals() []OperationApproval {
+	return o.approvals
+}
+
+func (o *PendingOperationModel) ExecutedAt() time.Time {
+	return o.executedAt
+}
+
+// NewPendingOperation creates a model of a pending operation
+//   - id is the random ID of the operation
+//   - operationType is the type of the operation
+//   - organization is the organization of the operation
+//   - certificate is the serial number of the target certificate, or nil
+//   - details describe the parameters of the operation
+//   - requestedBy is the name of the approver who requested the operation
+//   - status is the status of the operation
+//   - createdAt is when the operation was requested
+//   - expiresAt is when the operation expires unless it has been executed
+//   - approvals are the decisions of the approvers
+//   - executedAt is when the operation was executed, or zero
+func NewPendingOperation(
+	id string,
+	operationType OperationType,
+	organization *big.Int,
+	certificate *big.Int,
+	details string,
+	requestedBy string,
+	status OperationStatus,
+	createdAt time.Time,
+	expiresAt time.Time,
+	approvals []OperationApproval,
+	executedAt time.Time,
+) *PendingOperationModel {
+	return &PendingOperationModel{
+		id:            id,
+		operationType: operationType,
+		organization:  organization,
+		certificate:   certificate,
+		details:       details,
+		requestedBy:   requestedBy,
+		status:        status,
+		createdAt:     createdAt,
+		expiresAt:     expiresAt,
+		approvals:     approvals,
+		executedAt:    executedAt,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ PendingOperation = (*PendingOperationModel)(nil)
@@ -0,0 +1,42 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewPendingOperation(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	approvals := []appmodels.OperationApproval{appmodels.NewOperationApproval("alice", true, createdAt)}
+	operation := appmodels.NewPendingOperation(
+		"id",
+		appmodels.OPERATION_CREATE_INTERMEDIATE_CERTIFICATE,
+		big.NewInt(1),
+		big.NewInt(2),
+		"commonName=Test",
+		"bob",
+		appmodels.OPERATION_STATUS_APPROVED,
+		createdAt,
+		createdAt.Add(time.Hour),
+		approvals,
+		createdAt.Add(time.Minute),
+	)
+	assert.Equal(t, "id", operation.ID())
+	assert.Equal(t, appmodels.OPERATION_CREATE_INTERMEDIATE_CERTIFICATE, operation.Type())
+	assert.Equal(t, big.NewInt(1), operation.OrganizationID())
+	assert.Equal(t, big.NewInt(2), operation.SerialNumber())
+	assert.Equal(t, "commonName=Test", operation.Details())
+	assert.Equal(t, "bob", operation.RequestedBy())
+	assert.Equal(t, appmodels.OPERATION_STATUS_APPROVED, operation.Status())
+	assert.Equal(t, createdAt, operation.CreatedAt())
+	assert.Equal(t, createdAt.Add(time.Hour), operation.ExpiresAt())
+	assert.Equal(t, approvals, operation.Approvals())
+	assert.Equal(t, createdAt.Add(time.Minute), operation.ExecutedAt())
+}
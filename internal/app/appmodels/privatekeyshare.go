@@ -0,0 +1,101 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"math/big"
+)
+
+// PrivateKeyShareModel model implements PrivateKeyShare
+type PrivateKeyShareModel struct {
+
+	// organization is the organization of the key
+	organization *big.Int
+
+	// certificate is the serial number of the certificate of the key
+	certificate *big.Int
+
+	// keyType is the type of the key
+	keyType KeyType
+
+	// splitID is the random ID shared by the shares of one split
+	splitID string
+
+	// index is the number of the share
+	index int
+
+	// shares is the number of shares in the split
+	shares int
+
+	// threshold is the number of shares needed to reconstruct the key
+	threshold int
+
+	// data is the share of the PKCS #8 encoding of the key
+	data []byte
+}
+
+func (s *PrivateKeyShareModel) OrganizationID() *big.Int {
+	return s.organization
+}
+
+func (s *PrivateKeyShareModel) SerialNumber() *big.Int {
+	return s.certificate
+}
+
+func (s *PrivateKeyShareModel) KeyType() KeyType {
+	return s.keyType
+}
+
+func (s *PrivateKeyShareModel) SplitID() string {
+	return s.splitID
+}
+
+func (s *PrivateKeyShareModel) Index() int {
+	return s.index
+}
+
+func (s *PrivateKeyShareModel) Shares() int {
+	return s.shares
+}
+
+func (s *PrivateKeyShareModel) Threshold() int {
+	return s.threshold
+}
+
+func (s *PrivateKeyShareModel) Data() []byte {
+	return s.data
+}
+
+// NewPrivateKeyShare creates a model of a private key share
+//   - organization is the organization of the key
+//   - certificate is the serial number of the certificate of the key
+//   - keyType is the type of the key
+//   - splitID is the random ID shared by the shares of one split
+//   - index is the number of the share
+//   - shares is the number of shares in the split
+//   - threshold is the number of shares needed to reconstruct the key
+//   - data is the share
+func NewPrivateKeyShare(
+	organization *big.Int,
+	certificate *big.Int,
+	keyType KeyType,
+	splitID string,
+	index int,
+	shares int,
+	threshold int,
+	data []byte,
+) *PrivateKeyShareModel {
+	return &PrivateKeyShareModel{
+		organization: organization,
+		certificate:  certificate,
+		keyType:      keyType,
+		splitID:      splitID,
+		index:        index,
+		shares:       shares,
+		threshold:    threshold,
+		data:         data,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ PrivateKeyShare = (*PrivateKeyShareModel)(nil)
@@ -0,0 +1,24 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewPrivateKeyShare(t *testing.T) {
+	share := appmodels.NewPrivateKeyShare(big.NewInt(1), big.NewInt(2), appmodels.ECDSA_P384, "split", 3, 5, 2, []byte("data"))
+	assert.Equal(t, big.NewInt(1), share.OrganizationID())
+	assert.Equal(t, big.NewInt(2), share.SerialNumber())
+	assert.Equal(t, appmodels.ECDSA_P384, share.KeyType())
+	assert.Equal(t, "split", share.SplitID())
+	assert.Equal(t, 3, share.Index())
+	assert.Equal(t, 5, share.Shares())
+	assert.Equal(t, 2, share.Threshold())
+	assert.Equal(t, []byte("data"), share.Data())
+}
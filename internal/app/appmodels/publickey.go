@@ -0,0 +1,25 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+// PublicKeyModel model implements PublicKey
+type PublicKeyModel struct {
+
+	// data is the internal public key data
+	data any
+}
+
+func (k *PublicKeyModel) PublicKey() any {
+	return k.data
+}
+
+// NewPublicKey creates a public key model from existing data
+//   - data is *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey
+func NewPublicKey(data any) *PublicKeyModel {
+	return &PublicKeyModel{
+		data: data,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ PublicKey = (*PublicKeyModel)(nil)
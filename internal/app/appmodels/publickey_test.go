@@ -0,0 +1,25 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewPublicKey(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	model := appmodels.NewPublicKey(publicKey)
+
+	got, ok := model.PublicKey().(ed25519.PublicKey)
+	if !ok || !got.Equal(publicKey) {
+		t.Errorf("PublicKey() = %v, want %v", model.PublicKey(), publicKey)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"math/big"
+	"time"
+)
+
+// RevocationListModel model implements RevocationList
+type RevocationListModel struct {
+
+	// organization is the organization of the issuer
+	organization *big.Int
+
+	// issuer is the serial number of the certificate which signed the CRL
+	issuer *big.Int
+
+	// number is the CRL number
+	number *big.Int
+
+	thisUpdate time.Time
+}
+
+func (r *RevocationListModel) OrganizationID() *big.Int {
+	return r.organization
+}
+
+func (r *RevocationListModel) IssuerSerialNumber() *big.Int {
+	return r.issuer
+}
+
+func (r *RevocationListModel) Number() *big.Int {
+	return r.number
+}
+
+func (r *RevocationListModel) ThisUpdate() time.Time {
+	return r.thisUpdate
+}
+
+// NewRevocationList creates a model of the last CRL of an issuer
+//   - organization: The organization of the issuer
+//   - issuer: The serial number of the issuing certificate
+//   - number: The CRL number
+//   - thisUpdate: The time the CRL was created
+func NewRevocationList(
+	organization *big.Int,
+	issuer *big.Int,
+	number *big.Int,
+	thisUpdate time.Time,
+) *RevocationListModel {
+	return &RevocationListModel{
+		organization: organization,
+		issuer:       issuer,
+		number:       number,
+		thisUpdate:   thisUpdate,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ RevocationList = (*RevocationListModel)(nil)
@@ -0,0 +1,29 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewRevocationList(t *testing.T) {
+	now := time.Now()
+	revocationList := appmodels.NewRevocationList(big.NewInt(123), big.NewInt(456), big.NewInt(7), now)
+
+	if revocationList.OrganizationID().Cmp(big.NewInt(123)) != 0 {
+		t.Errorf("OrganizationID() = %v, want 123", revocationList.OrganizationID())
+	}
+	if revocationList.IssuerSerialNumber().Cmp(big.NewInt(456)) != 0 {
+		t.Errorf("IssuerSerialNumber() = %v, want 456", revocationList.IssuerSerialNumber())
+	}
+	if revocationList.Number().Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("Number() = %v, want 7", revocationList.Number())
+	}
+	if !revocationList.ThisUpdate().Equal(now) {
+		t.Errorf("ThisUpdate() = %v, want %v", revocationList.ThisUpdate(), now)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"math/big"
+)
+
+// ScepChallengeModel model implements ScepChallenge
+type ScepChallengeModel struct {
+
+	// organization is the organization which the challenge password belongs to
+	organization *big.Int
+
+	// passwordHash is the bcrypt hash of the challenge password
+	passwordHash []byte
+}
+
+func (c *ScepChallengeModel) OrganizationID() *big.Int {
+	return c.organization
+}
+
+func (c *ScepChallengeModel) PasswordHash() []byte {
+	return c.passwordHash
+}
+
+// NewScepChallenge creates a SCEP challenge password model
+//   - organization: The organization
+//   - passwordHash: The bcrypt hash of the challenge password
+func NewScepChallenge(
+	organization *big.Int,
+	passwordHash []byte,
+) *ScepChallengeModel {
+	return &ScepChallengeModel{
+		organization: organization,
+		passwordHash: passwordHash,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ ScepChallenge = (*ScepChallengeModel)(nil)
@@ -0,0 +1,23 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewScepChallenge(t *testing.T) {
+	organization := big.NewInt(123)
+
+	challenge := appmodels.NewScepChallenge(organization, []byte("hash"))
+
+	if challenge.OrganizationID().Cmp(organization) != 0 {
+		t.Errorf("OrganizationID() = %v, want %v", challenge.OrganizationID(), organization)
+	}
+	if string(challenge.PasswordHash()) != "hash" {
+		t.Errorf("PasswordHash() = %v, want %v", string(challenge.PasswordHash()), "hash")
+	}
+}
@@ -0,0 +1,12 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import "errors"
+
+// ErrSealed is returned when private keys are used while the seal key is
+// not in memory
+var ErrSealed = errors.New("private keys are sealed")
+
+// ErrSealPermissionDenied is returned when a seal token is not accepted
+var ErrSealPermissionDenied = errors.New("seal: permission denied")
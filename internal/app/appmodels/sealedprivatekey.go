@@ -0,0 +1,37 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+// SealedPrivateKeyModel model implements SealedPrivateKey
+type SealedPrivateKeyModel struct {
+
+	// keyType is the type of the encrypted key
+	keyType KeyType
+
+	// ciphertext is the encrypted PKCS #8 encoding of the key
+	ciphertext []byte
+}
+
+func (k *SealedPrivateKeyModel) KeyType() KeyType {
+	return k.keyType
+}
+
+func (k *SealedPrivateKeyModel) Ciphertext() []byte {
+	return k.ciphertext
+}
+
+// NewSealedPrivateKey creates a model of an encrypted private key
+//   - keyType is the type of the encrypted key
+//   - ciphertext is the encrypted key
+func NewSealedPrivateKey(
+	keyType KeyType,
+	ciphertext []byte,
+) *SealedPrivateKeyModel {
+	return &SealedPrivateKeyModel{
+		keyType:    keyType,
+		ciphertext: ciphertext,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ SealedPrivateKey = (*SealedPrivateKeyModel)(nil)
@@ -0,0 +1,17 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewSealedPrivateKey(t *testing.T) {
+	key := appmodels.NewSealedPrivateKey(appmodels.ECDSA_P384, []byte("ciphertext"))
+	assert.Equal(t, appmodels.ECDSA_P384, key.KeyType())
+	assert.Equal(t, []byte("ciphertext"), key.Ciphertext())
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"crypto/x509"
+	"fmt"
+)
+
+// SignatureAlgorithm represents the algorithm an issuer uses to sign
+// certificates.
+type SignatureAlgorithm int
+
+const (
+	// NIL_SIGNATURE_ALGORITHM means the default algorithm for the issuer key
+	// type is used.
+	NIL_SIGNATURE_ALGORITHM SignatureAlgorithm = iota
+
+	// SHA256_WITH_RSA represents RSA PKCS #1 v1.5 signature with SHA-256
+	SHA256_WITH_RSA
+
+	// SHA384_WITH_RSA represents RSA PKCS #1 v1.5 signature with SHA-384
+	SHA384_WITH_RSA
+
+	// SHA512_WITH_RSA represents RSA PKCS #1 v1.5 signature with SHA-512
+	SHA512_WITH_RSA
+
+	// SHA256_WITH_RSA_PSS represents RSA-PSS signature with SHA-256
+	SHA256_WITH_RSA_PSS
+
+	// SHA384_WITH_RSA_PSS represents RSA-PSS signature with SHA-384
+	SHA384_WITH_RSA_PSS
+
+	// SHA512_WITH_RSA_PSS represents RSA-PSS signature with SHA-512
+	SHA512_WITH_RSA_PSS
+
+	// ECDSA_WITH_SHA256 represents ECDSA signature with SHA-256
+	ECDSA_WITH_SHA256
+
+	// ECDSA_WITH_SHA384 represents ECDSA signature with SHA-384
+	ECDSA_WITH_SHA384
+
+	// ECDSA_WITH_SHA512 represents ECDSA signature with SHA-512
+	ECDSA_WITH_SHA512
+
+	// PURE_Ed25519 represents Ed25519 signature
+	PURE_Ed25519
+)
+
+func (sa SignatureAlgorithm) String() string {
+	switch sa {
+	case SHA256_WITH_RSA:
+		return "SHA256_WITH_RSA"
+	case SHA384_WITH_RSA:
+		return "SHA384_WITH_RSA"
+	case SHA512_WITH_RSA:
+		return "SHA512_WITH_RSA"
+	case SHA256_WITH_RSA_PSS:
+		return "SHA256_WITH_RSA_PSS"
+	case SHA384_WITH_RSA_PSS:
+		return "SHA384_WITH_RSA_PSS"
+	case SHA512_WITH_RSA_PSS:
+		return "SHA512_WITH_RSA_PSS"
+	case ECDSA_WITH_SHA256:
+		return "ECDSA_WITH_SHA256"
+	case ECDSA_WITH_SHA384:
+		return "ECDSA_WITH_SHA384"
+	case ECDSA_WITH_SHA512:
+		return "ECDSA_WITH_SHA512"
+	case PURE_Ed25519:
+		return "PURE_Ed25519"
+	default:
+		return fmt.Sprintf("SignatureAlgorithm(%d)", sa)
+	}
+}
+
+// X509 returns the matching x509.SignatureAlgorithm. The NIL value maps to
+// x509.UnknownSignatureAlgorithm, which lets the x509 package pick the
+// default for the signing key.
+func (sa SignatureAlgorithm) X509() x509.SignatureAlgorithm {
+	switch sa {
+	case SHA256_WITH_RSA:
+		return x509.SHA256WithRSA
+	case SHA384_WITH_RSA:
+		return x509.SHA384WithRSA
+	case SHA512_WITH_RSA:
+		return x509.SHA512WithRSA
+	case SHA256_WITH_RSA_PSS:
+		return x509.SHA256WithRSAPSS
+	case SHA384_WITH_RSA_PSS:
+		return x509.SHA384WithRSAPSS
+	case SHA512_WITH_RSA_PSS:
+		return x509.SHA512WithRSAPSS
+	case ECDSA_WITH_SHA256:
+		return x509.ECDSAWithSHA256
+	case ECDSA_WITH_SHA384:
+		return x509.ECDSAWithSHA384
+	case ECDSA_WITH_SHA512:
+		return x509.ECDSAWithSHA512
+	case PURE_Ed25519:
+		return x509.PureEd25519
+	default:
+		return x509.UnknownSignatureAlgorithm
+	}
+}
+
+func (sa SignatureAlgorithm) IsRSA() bool {
+	return sa == SHA256_WITH_RSA || sa == SHA384_WITH_RSA || sa == SHA512_WITH_RSA || sa.IsRSAPSS()
+}
+
+func (sa SignatureAlgorithm) IsRSAPSS() bool {
+	return sa == SHA256_WITH_RSA_PSS || sa == SHA384_WITH_RSA_PSS || sa == SHA512_WITH_RSA_PSS
+}
+
+func (sa SignatureAlgorithm) IsECDSA() bool {
+	return sa == ECDSA_WITH_SHA256 || sa == ECDSA_WITH_SHA384 || sa == ECDSA_WITH_SHA512
+}
+
+func (sa SignatureAlgorithm) IsEd25519() bool {
+	return sa == PURE_Ed25519
+}
+
+// IsCompatibleWith returns true if an issuer with a key of type keyType can
+// sign using this algorithm. The NIL value is compatible with every key type.
+func (sa SignatureAlgorithm) IsCompatibleWith(keyType KeyType) bool {
+	switch {
+	case sa == NIL_SIGNATURE_ALGORITHM:
+		return true
+	case sa.IsRSA():
+		return keyType.IsRSA()
+	case sa.IsECDSA():
+		return keyType.IsECDSA()
+	case sa.IsEd25519():
+		return keyType.IsEd25519()
+	default:
+		return false
+	}
+}
+
+// NewSignatureAlgorithmFromX509 returns the SignatureAlgorithm matching an
+// x509.SignatureAlgorithm, or NIL_SIGNATURE_ALGORITHM if it is not supported.
+func NewSignatureAlgorithmFromX509(algorithm x509.SignatureAlgorithm) SignatureAlgorithm {
+	switch algorithm {
+	case x509.SHA256WithRSA:
+		return SHA256_WITH_RSA
+	case x509.SHA384WithRSA:
+		return SHA384_WITH_RSA
+	case x509.SHA512WithRSA:
+		return SHA512_WITH_RSA
+	case x509.SHA256WithRSAPSS:
+		return SHA256_WITH_RSA_PSS
+	case x509.SHA384WithRSAPSS:
+		return SHA384_WITH_RSA_PSS
+	case x509.SHA512WithRSAPSS:
+		return SHA512_WITH_RSA_PSS
+	case x509.ECDSAWithSHA256:
+		return ECDSA_WITH_SHA256
+	case x509.ECDSAWithSHA384:
+		return ECDSA_WITH_SHA384
+	case x509.ECDSAWithSHA512:
+		return ECDSA_WITH_SHA512
+	case x509.PureEd25519:
+		return PURE_Ed25519
+	default:
+		return NIL_SIGNATURE_ALGORITHM
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestSignatureAlgorithm_String(t *testing.T) {
+	tests := []struct {
+		algorithm appmodels.SignatureAlgorithm
+		want      string
+	}{
+		{appmodels.SHA256_WITH_RSA, "SHA256_WITH_RSA"},
+		{appmodels.SHA384_WITH_RSA, "SHA384_WITH_RSA"},
+		{appmodels.SHA512_WITH_RSA, "SHA512_WITH_RSA"},
+		{appmodels.SHA256_WITH_RSA_PSS, "SHA256_WITH_RSA_PSS"},
+		{appmodels.SHA384_WITH_RSA_PSS, "SHA384_WITH_RSA_PSS"},
+		{appmodels.SHA512_WITH_RSA_PSS, "SHA512_WITH_RSA_PSS"},
+		{appmodels.ECDSA_WITH_SHA256, "ECDSA_WITH_SHA256"},
+		{appmodels.ECDSA_WITH_SHA384, "ECDSA_WITH_SHA384"},
+		{appmodels.ECDSA_WITH_SHA512, "ECDSA_WITH_SHA512"},
+		{appmodels.PURE_Ed25519, "PURE_Ed25519"},
+		{appmodels.SignatureAlgorithm(999), "SignatureAlgorithm(999)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.algorithm.String(); got != tt.want {
+				t.Errorf("SignatureAlgorithm.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignatureAlgorithm_X509(t *testing.T) {
+	tests := []struct {
+		algorithm appmodels.SignatureAlgorithm
+		want      x509.SignatureAlgorithm
+	}{
+		{appmodels.NIL_SIGNATURE_ALGORITHM, x509.UnknownSignatureAlgorithm},
+		{appmodels.SHA256_WITH_RSA, x509.SHA256WithRSA},
+		{appmodels.SHA384_WITH_RSA, x509.SHA384WithRSA},
+		{appmodels.SHA512_WITH_RSA, x509.SHA512WithRSA},
+		{appmodels.SHA256_WITH_RSA_PSS, x509.SHA256WithRSAPSS},
+		{appmodels.SHA384_WITH_RSA_PSS, x509.SHA384WithRSAPSS},
+		{appmodels.SHA512_WITH_RSA_PSS, x509.SHA512WithRSAPSS},
+		{appmodels.ECDSA_WITH_SHA256, x509.ECDSAWithSHA256},
+		{appmodels.ECDSA_WITH_SHA384, x509.ECDSAWithSHA384},
+		{appmodels.ECDSA_WITH_SHA512, x509.ECDSAWithSHA512},
+		{appmodels.PURE_Ed25519, x509.PureEd25519},
+	}
+
+	for _, tt := range tests {
+		if got := tt.algorithm.X509(); got != tt.want {
+			t.Errorf("%v.X509() = %v, want %v", tt.algorithm, got, tt.want)
+		}
+		if got := appmodels.NewSignatureAlgorithmFromX509(tt.want); got != tt.algorithm {
+			t.Errorf("NewSignatureAlgorithmFromX509(%v) = %v, want %v", tt.want, got, tt.algorithm)
+		}
+	}
+}
+
+func TestSignatureAlgorithm_IsCompatibleWith(t *testing.T) {
+	tests := []struct {
+		algorithm appmodels.SignatureAlgorithm
+		keyType   appmodels.KeyType
+		want      bool
+	}{
+		{appmodels.NIL_SIGNATURE_ALGORITHM, appmodels.ECDSA_P384, true},
+		{appmodels.NIL_SIGNATURE_ALGORITHM, appmodels.RSA_2048, true},
+		{appmodels.SHA256_WITH_RSA, appmodels.RSA_2048, true},
+		{appmodels.SHA384_WITH_RSA_PSS, appmodels.RSA_3072, true},
+		{appmodels.SHA384_WITH_RSA_PSS, appmodels.ECDSA_P384, false},
+		{appmodels.ECDSA_WITH_SHA384, appmodels.ECDSA_P384, true},
+		{appmodels.ECDSA_WITH_SHA384, appmodels.RSA_4096, false},
+		{appmodels.ECDSA_WITH_SHA256, appmodels.Ed25519, false},
+		{appmodels.PURE_Ed25519, appmodels.Ed25519, true},
+		{appmodels.PURE_Ed25519, appmodels.ECDSA_P256, false},
+		{appmodels.SignatureAlgorithm(999), appmodels.RSA_2048, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.algorithm.IsCompatibleWith(tt.keyType); got != tt.want {
+			t.Errorf("%v.IsCompatibleWith(%v) = %v, want %v", tt.algorithm, tt.keyType, got, tt.want)
+		}
+	}
+}
+
+func TestSignatureAlgorithm_IsRSAPSS(t *testing.T) {
+	if !appmodels.SHA384_WITH_RSA_PSS.IsRSAPSS() {
+		t.Errorf("SHA384_WITH_RSA_PSS.IsRSAPSS() = false, want true")
+	}
+	if !appmodels.SHA384_WITH_RSA_PSS.IsRSA() {
+		t.Errorf("SHA384_WITH_RSA_PSS.IsRSA() = false, want true")
+	}
+	if appmodels.SHA384_WITH_RSA.IsRSAPSS() {
+		t.Errorf("SHA384_WITH_RSA.IsRSAPSS() = true, want false")
+	}
+}
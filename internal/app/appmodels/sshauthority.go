@@ -0,0 +1,61 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"math/big"
+	"time"
+)
+
+// SshAuthorityModel model implements SshAuthority
+type SshAuthorityModel struct {
+
+	// organization is the organization which owns the SSH CA
+	organization *big.Int
+
+	// id is the unique identifier of the SSH CA key
+	id *big.Int
+
+	// privateKey is the signing key of the SSH CA
+	privateKey PrivateKey
+
+	createdAt time.Time
+}
+
+func (a *SshAuthorityModel) OrganizationID() *big.Int {
+	return a.organization
+}
+
+func (a *SshAuthorityModel) ID() *big.Int {
+	return a.id
+}
+
+func (a *SshAuthorityModel) PrivateKey() PrivateKey {
+	return a.privateKey
+}
+
+func (a *SshAuthorityModel) CreatedAt() time.Time {
+	return a.createdAt
+}
+
+// NewSshAuthority creates an SSH certificate authority model
+//   - organization: The organization which owns the SSH CA
+//   - id: The unique identifier of the key
+//   - privateKey: The signing key
+//   - createdAt: The creation time
+func NewSshAuthority(
+	organization *big.Int,
+	id *big.Int,
+	privateKey PrivateKey,
+	createdAt time.Time,
+) *SshAuthorityModel {
+	return &SshAuthorityModel{
+		organization: organization,
+		id:           id,
+		privateKey:   privateKey,
+		createdAt:    createdAt,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ SshAuthority = (*SshAuthorityModel)(nil)
@@ -0,0 +1,33 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewSshAuthority(t *testing.T) {
+	organization := big.NewInt(123)
+	id := big.NewInt(456)
+	privateKey := appmodels.NewPrivateKey(organization, id, appmodels.Ed25519, nil)
+	createdAt := time.Unix(1000, 0)
+
+	authority := appmodels.NewSshAuthority(organization, id, privateKey, createdAt)
+
+	if authority.OrganizationID().Cmp(organization) != 0 {
+		t.Errorf("OrganizationID() = %v, want %v", authority.OrganizationID(), organization)
+	}
+	if authority.ID().Cmp(id) != 0 {
+		t.Errorf("ID() = %v, want %v", authority.ID(), id)
+	}
+	if authority.PrivateKey() != privateKey {
+		t.Errorf("PrivateKey() = %v, want %v", authority.PrivateKey(), privateKey)
+	}
+	if !authority.CreatedAt().Equal(createdAt) {
+		t.Errorf("CreatedAt() = %v, want %v", authority.CreatedAt(), createdAt)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SshCertificateModel model implements SshCertificate
+type SshCertificateModel struct {
+
+	// organization is the organization whose SSH CA signed the certificate
+	organization *big.Int
+
+	// certificate is the signed OpenSSH certificate
+	certificate *ssh.Certificate
+
+	// revokedAt is the time of revocation, or zero if not revoked
+	revokedAt time.Time
+}
+
+func (c *SshCertificateModel) OrganizationID() *big.Int {
+	return c.organization
+}
+
+func (c *SshCertificateModel) Serial() uint64 {
+	return c.certificate.Serial
+}
+
+func (c *SshCertificateModel) Type() SshCertificateType {
+	if c.certificate.CertType == ssh.HostCert {
+		return SSH_HOST_CERTIFICATE
+	}
+	return SSH_USER_CERTIFICATE
+}
+
+func (c *SshCertificateModel) KeyID() string {
+	return c.certificate.KeyId
+}
+
+func (c *SshCertificateModel) Principals() []string {
+	return c.certificate.ValidPrincipals
+}
+
+func (c *SshCertificateModel) ValidAfter() time.Time {
+	return time.Unix(int64(c.certificate.ValidAfter), 0)
+}
+
+func (c *SshCertificateModel) ValidBefore() time.Time {
+	return time.Unix(int64(c.certificate.ValidBefore), 0)
+}
+
+func (c *SshCertificateModel) IsRevoked() bool {
+	return !c.revokedAt.IsZero()
+}
+
+func (c *SshCertificateModel) RevokedAt() time.Time {
+	return c.revokedAt
+}
+
+func (c *SshCertificateModel) Certificate() *ssh.Certificate {
+	return c.certificate
+}
+
+// NewSshCertificate creates an SSH certificate model
+//   - organization: The organization whose SSH CA signed the certificate
+//   - certificate: The signed certificate
+//   - revokedAt: The time of revocation, or zero if not revoked
+func NewSshCertificate(
+	organization *big.Int,
+	certificate *ssh.Certificate,
+	revokedAt time.Time,
+) *SshCertificateModel {
+	return &SshCertificateModel{
+		organization: organization,
+		certificate:  certificate,
+		revokedAt:    revokedAt,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ SshCertificate = (*SshCertificateModel)(nil)
@@ -0,0 +1,58 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewSshCertificate(t *testing.T) {
+	organization := big.NewInt(123)
+	certificate := &ssh.Certificate{
+		Serial:          42,
+		CertType:        ssh.HostCert,
+		KeyId:           "host-1",
+		ValidPrincipals: []string{"host1.example.com"},
+		ValidAfter:      1000,
+		ValidBefore:     2000,
+	}
+
+	model := appmodels.NewSshCertificate(organization, certificate, time.Time{})
+
+	if model.OrganizationID().Cmp(organization) != 0 {
+		t.Errorf("OrganizationID() = %v, want %v", model.OrganizationID(), organization)
+	}
+	if model.Serial() != 42 {
+		t.Errorf("Serial() = %v, want %v", model.Serial(), 42)
+	}
+	if model.Type() != appmodels.SSH_HOST_CERTIFICATE {
+		t.Errorf("Type() = %v, want %v", model.Type(), appmodels.SSH_HOST_CERTIFICATE)
+	}
+	if model.KeyID() != "host-1" {
+		t.Errorf("KeyID() = %v, want %v", model.KeyID(), "host-1")
+	}
+	if !reflect.DeepEqual(model.Principals(), []string{"host1.example.com"}) {
+		t.Errorf("Principals() = %v", model.Principals())
+	}
+	if !model.ValidAfter().Equal(time.Unix(1000, 0)) || !model.ValidBefore().Equal(time.Unix(2000, 0)) {
+		t.Errorf("ValidAfter() = %v, ValidBefore() = %v", model.ValidAfter(), model.ValidBefore())
+	}
+	if model.IsRevoked() {
+		t.Errorf("IsRevoked() = true, want false")
+	}
+	if model.Certificate() != certificate {
+		t.Errorf("Certificate() = %v, want %v", model.Certificate(), certificate)
+	}
+
+	revoked := appmodels.NewSshCertificate(organization, certificate, time.Unix(1500, 0))
+	if !revoked.IsRevoked() || !revoked.RevokedAt().Equal(time.Unix(1500, 0)) {
+		t.Errorf("RevokedAt() = %v", revoked.RevokedAt())
+	}
+}
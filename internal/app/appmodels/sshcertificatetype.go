@@ -0,0 +1,31 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import "fmt"
+
+// SshCertificateType represents the type of OpenSSH certificate
+type SshCertificateType int
+
+const (
+	NIL_SSH_CERTIFICATE_TYPE SshCertificateType = iota
+
+	// SSH_USER_CERTIFICATE represents a certificate which authenticates a
+	// user to a server
+	SSH_USER_CERTIFICATE
+
+	// SSH_HOST_CERTIFICATE represents a certificate which authenticates a
+	// server to users
+	SSH_HOST_CERTIFICATE
+)
+
+func (t SshCertificateType) String() string {
+	switch t {
+	case SSH_USER_CERTIFICATE:
+		return "SSH_USER_CERTIFICATE"
+	case SSH_HOST_CERTIFICATE:
+		return "SSH_HOST_CERTIFICATE"
+	default:
+		return fmt.Sprintf("SshCertificateType(%d)", t)
+	}
+}
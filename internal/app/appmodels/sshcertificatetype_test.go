@@ -0,0 +1,25 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"testing"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestSshCertificateType_String(t *testing.T) {
+	tests := []struct {
+		certificateType appmodels.SshCertificateType
+		want            string
+	}{
+		{appmodels.SSH_USER_CERTIFICATE, "SSH_USER_CERTIFICATE"},
+		{appmodels.SSH_HOST_CERTIFICATE, "SSH_HOST_CERTIFICATE"},
+		{appmodels.NIL_SSH_CERTIFICATE_TYPE, "SshCertificateType(0)"},
+	}
+	for _, tt := range tests {
+		if got := tt.certificateType.String(); got != tt.want {
+			t.Errorf("String() = %v, want %v", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import "fmt"
+
+// VaultErrorType represents the kind of a Vault PKI API error
+type VaultErrorType int
+
+const (
+	// VAULT_ERROR_SERVER_INTERNAL represents an internal server error
+	VAULT_ERROR_SERVER_INTERNAL VaultErrorType = iota
+
+	// VAULT_ERROR_BAD_REQUEST represents a malformed or unacceptable request
+	VAULT_ERROR_BAD_REQUEST
+
+	// VAULT_ERROR_PERMISSION_DENIED represents a missing or invalid token
+	VAULT_ERROR_PERMISSION_DENIED
+
+	// VAULT_ERROR_NOT_FOUND represents an unknown role or certificate
+	VAULT_ERROR_NOT_FOUND
+)
+
+func (t VaultErrorType) String() string {
+	switch t {
+	case VAULT_ERROR_SERVER_INTERNAL:
+		return "VAULT_ERROR_SERVER_INTERNAL"
+	case VAULT_ERROR_BAD_REQUEST:
+		return "VAULT_ERROR_BAD_REQUEST"
+	case VAULT_ERROR_PERMISSION_DENIED:
+		return "VAULT_ERROR_PERMISSION_DENIED"
+	case VAULT_ERROR_NOT_FOUND:
+		return "VAULT_ERROR_NOT_FOUND"
+	default:
+		return fmt.Sprintf("VaultErrorType(%d)", t)
+	}
+}
+
+// VaultError is an error which can be reported to a Vault client
+type VaultError struct {
+	errorType VaultErrorType
+	detail    string
+}
+
+func (e *VaultError) Error() string {
+	return fmt.Sprintf("%s: %s", e.errorType, e.detail)
+}
+
+// Type returns the Vault error type
+func (e *VaultError) Type() VaultErrorType {
+	return e.errorType
+}
+
+// Detail returns a human-readable explanation which is safe to show to the
+// client
+func (e *VaultError) Detail() string {
+	return e.detail
+}
+
+// NewVaultError creates a Vault error
+//   - errorType: The Vault error type
+//   - format: The detail message format, see fmt.Sprintf
+func NewVaultError(errorType VaultErrorType, format string, args ...any) *VaultError {
+	return &VaultError{
+		errorType: errorType,
+		detail:    fmt.Sprintf(format, args...),
+	}
+}
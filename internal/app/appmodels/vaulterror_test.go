@@ -0,0 +1,46 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewVaultError(t *testing.T) {
+	err := appmodels.NewVaultError(appmodels.VAULT_ERROR_BAD_REQUEST, "bad %s", "role")
+
+	if err.Type() != appmodels.VAULT_ERROR_BAD_REQUEST {
+		t.Errorf("Type() = %v, want %v", err.Type(), appmodels.VAULT_ERROR_BAD_REQUEST)
+	}
+	if err.Detail() != "bad role" {
+		t.Errorf("Detail() = %v, want %v", err.Detail(), "bad role")
+	}
+	if err.Error() != "VAULT_ERROR_BAD_REQUEST: bad role" {
+		t.Errorf("Error() = %v", err.Error())
+	}
+}
+
+func TestVaultError_As(t *testing.T) {
+	wrapped := fmt.Errorf("failed: %w", appmodels.NewVaultError(appmodels.VAULT_ERROR_NOT_FOUND, "missing"))
+
+	var vaultErr *appmodels.VaultError
+	if !errors.As(wrapped, &vaultErr) {
+		t.Fatalf("errors.As() did not find VaultError")
+	}
+	if vaultErr.Type() != appmodels.VAULT_ERROR_NOT_FOUND {
+		t.Errorf("Type() = %v, want %v", vaultErr.Type(), appmodels.VAULT_ERROR_NOT_FOUND)
+	}
+}
+
+func TestVaultErrorType_String(t *testing.T) {
+	if appmodels.VAULT_ERROR_PERMISSION_DENIED.String() != "VAULT_ERROR_PERMISSION_DENIED" {
+		t.Errorf("String() = %v", appmodels.VAULT_ERROR_PERMISSION_DENIED.String())
+	}
+	if appmodels.VaultErrorType(99).String() != "VaultErrorType(99)" {
+		t.Errorf("String() = %v", appmodels.VaultErrorType(99).String())
+	}
+}
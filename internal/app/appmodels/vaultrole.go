@@ -0,0 +1,127 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels
+
+import (
+	"math/big"
+	"time"
+)
+
+// VaultRoleModel model implements VaultRole
+type VaultRoleModel struct {
+
+	// name is the unique name of the role within the organization
+	name string
+
+	// organization is the organization of the issuing certificate
+	organization *big.Int
+
+	// issuer is the serial number of the issuing certificate
+	issuer *big.Int
+
+	// allowedDomains are the domains certificates may be issued for
+	allowedDomains []string
+
+	allowSubdomains  bool
+	allowBareDomains bool
+	allowAnyName     bool
+
+	// serverFlag selects server certificates instead of client certificates
+	serverFlag bool
+
+	// keyType is the key type of issued private keys, or NIL_KEY_TYPE
+	keyType KeyType
+
+	// ttl is the default validity duration, or zero
+	ttl time.Duration
+
+	// maxTTL is the maximum validity duration, or zero
+	maxTTL time.Duration
+}
+
+func (r *VaultRoleModel) Name() string {
+	return r.name
+}
+
+func (r *VaultRoleModel) OrganizationID() *big.Int {
+	return r.organization
+}
+
+func (r *VaultRoleModel) IssuerSerialNumber() *big.Int {
+	return r.issuer
+}
+
+func (r *VaultRoleModel) AllowedDomains() []string {
+	return r.allowedDomains
+}
+
+func (r *VaultRoleModel) AllowSubdomains() bool {
+	return r.allowSubdomains
+}
+
+func (r *VaultRoleModel) AllowBareDomains() bool {
+	return r.allowBareDomains
+}
+
+func (r *VaultRoleModel) AllowAnyName() bool {
+	return r.allowAnyName
+}
+
+func (r *VaultRoleModel) ServerFlag() bool {
+	return r.serverFlag
+}
+
+func (r *VaultRoleModel) KeyType() KeyType {
+	return r.keyType
+}
+
+func (r *VaultRoleModel) TTL() time.Duration {
+	return r.ttl
+}
+
+func (r *VaultRoleModel) MaxTTL() time.Duration {
+	return r.maxTTL
+}
+
+// NewVaultRole creates a Vault PKI role model
+//   - name: The role name
+//   - organization: The organization of the issuing certificate
+//   - issuer: The serial number of the issuing certificate
+//   - allowedDomains: The domains certificates may be issued for
+//   - allowSubdomains: Subdomains of the allowed domains are allowed
+//   - allowBareDomains: The allowed domains themselves are allowed
+//   - allowAnyName: Any name is allowed
+//   - serverFlag: Server certificates are issued instead of client certificates
+//   - keyType: The key type of issued private keys, or NIL_KEY_TYPE
+//   - ttl: The default validity duration, or zero
+//   - maxTTL: The maximum validity duration, or zero
+func NewVaultRole(
+	name string,
+	organization *big.Int,
+	issuer *big.Int,
+	allowedDomains []string,
+	allowSubdomains bool,
+	allowBareDomains bool,
+	allowAnyName bool,
+	serverFlag bool,
+	keyType KeyType,
+	ttl time.Duration,
+	maxTTL time.Duration,
+) *VaultRoleModel {
+	return &VaultRoleModel{
+		name:             name,
+		organization:     organization,
+		issuer:           issuer,
+		allowedDomains:   allowedDomains,
+		allowSubdomains:  allowSubdomains,
+		allowBareDomains: allowBareDomains,
+		allowAnyName:     allowAnyName,
+		serverFlag:       serverFlag,
+		keyType:          keyType,
+		ttl:              ttl,
+		maxTTL:           maxTTL,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ VaultRole = (*VaultRoleModel)(nil)
@@ -0,0 +1,40 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package appmodels_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+func TestNewVaultRole(t *testing.T) {
+	organization := big.NewInt(123)
+	issuer := big.NewInt(456)
+
+	role := appmodels.NewVaultRole("web", organization, issuer, []string{"example.com"}, true, false, false, true, appmodels.ECDSA_P256, time.Hour, 2*time.Hour)
+
+	if role.Name() != "web" {
+		t.Errorf("Name() = %v, want %v", role.Name(), "web")
+	}
+	if role.OrganizationID().Cmp(organization) != 0 {
+		t.Errorf("OrganizationID() = %v, want %v", role.OrganizationID(), organization)
+	}
+	if role.IssuerSerialNumber().Cmp(issuer) != 0 {
+		t.Errorf("IssuerSerialNumber() = %v, want %v", role.IssuerSerialNumber(), issuer)
+	}
+	if len(role.AllowedDomains()) != 1 || role.AllowedDomains()[0] != "example.com" {
+		t.Errorf("AllowedDomains() = %v", role.AllowedDomains())
+	}
+	if !role.AllowSubdomains() || role.AllowBareDomains() || role.AllowAnyName() || !role.ServerFlag() {
+		t.Errorf("flags = %v %v %v %v", role.AllowSubdomains(), role.AllowBareDomains(), role.AllowAnyName(), role.ServerFlag())
+	}
+	if role.KeyType() != appmodels.ECDSA_P256 {
+		t.Errorf("KeyType() = %v, want %v", role.KeyType(), appmodels.ECDSA_P256)
+	}
+	if role.TTL() != time.Hour || role.MaxTTL() != 2*time.Hour {
+		t.Errorf("TTL() = %v, MaxTTL() = %v", role.TTL(), role.MaxTTL())
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// BoltAcmeAccountRepository implements appmodels.AcmeAccountRepository on
+// bbolt. Accounts are stored as JSON by their IDs.
+type BoltAcmeAccountRepository struct {
+	database *Database
+}
+
+func (r *BoltAcmeAccountRepository) FindById(id string) (appmodels.AcmeAccount, error) {
+	var data []byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(AcmeAccountsBucketName); bucket != nil {
+			// The value is only valid during the transaction
+			data = append([]byte(nil), bucket.Get([]byte(id))...)
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("not found: %s", id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindById]: %w", err)
+	}
+	model, err := parseAcmeAccount(data)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltAcmeAccountRepository) FindByThumbprint(organization *big.Int, issuer *big.Int, thumbprint string) (appmodels.AcmeAccount, error) {
+	list, err := r.findAll()
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindByThumbprint]: %w", err)
+	}
+	for _, account := range list {
+		if account.Thumbprint() == thumbprint &&
+			account.OrganizationID().Cmp(organization) == 0 &&
+			account.IssuerSerialNumber().Cmp(issuer) == 0 {
+			return account, nil
+		}
+	}
+	return nil, fmt.Errorf("[AcmeAccount:FindByThumbprint]: not found: %s", thumbprint)
+}
+
+func (r *BoltAcmeAccountRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.AcmeAccount, error) {
+	all, err := r.findAll()
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindAllByOrganization]: %w", err)
+	}
+	list := make([]appmodels.AcmeAccount, 0, len(all))
+	for _, account := range all {
+		if account.OrganizationID().Cmp(organization) == 0 {
+			list = append(list, account)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt().Equal(list[j].CreatedAt()) {
+			return list[i].ID() < list[j].ID()
+		}
+		return list[i].CreatedAt().Before(list[j].CreatedAt())
+	})
+	return list, nil
+}
+
+func (r *BoltAcmeAccountRepository) Save(model appmodels.AcmeAccount) (appmodels.AcmeAccount, error) {
+	dto, err := apputils.ToAcmeAccountRecordDTO(model)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:Save]: %w", err)
+	}
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:Save]: failed to marshal JSON: %w", err)
+	}
+	err = r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(AcmeAccountsBucketName)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(model.ID()), data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// findAll reads every account. The bucket has no index by the thumbprint or
+// the organization.
+func (r *BoltAcmeAccountRepository) findAll() ([]appmodels.AcmeAccount, error) {
+	var values [][]byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		// The bucket is created when the first account is saved
+		bucket := tx.Bucket(AcmeAccountsBucketName)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, value []byte) error {
+			values = append(values, append([]byte(nil), value...))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := make([]appmodels.AcmeAccount, 0, len(values))
+	for _, data := range values {
+		model, err := parseAcmeAccount(data)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, model)
+	}
+	return list, nil
+}
+
+// parseAcmeAccount parses a stored account
+func parseAcmeAccount(data []byte) (appmodels.AcmeAccount, error) {
+	dto := appdtos.AcmeAccountRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return apputils.FromAcmeAccountRecordDTO(dto)
+}
+
+// NewAcmeAccountRepository creates a bbolt based repository for ACME
+// accounts
+func NewAcmeAccountRepository(
+	database *Database,
+) *BoltAcmeAccountRepository {
+	return &BoltAcmeAccountRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.AcmeAccountRepository = (*BoltAcmeAccountRepository)(nil)
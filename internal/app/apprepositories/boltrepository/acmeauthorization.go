@@ -0,0 +1,77 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// BoltAcmeAuthorizationRepository implements
+// appmodels.AcmeAuthorizationRepository on bbolt. Authorizations and their
+// challenges are stored as JSON by their IDs.
+type BoltAcmeAuthorizationRepository struct {
+	database *Database
+}
+
+func (r *BoltAcmeAuthorizationRepository) FindById(id string) (appmodels.AcmeAuthorization, error) {
+	var data []byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(AcmeAuthorizationsBucketName); bucket != nil {
+			// The value is only valid during the transaction
+			data = append([]byte(nil), bucket.Get([]byte(id))...)
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("not found: %s", id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:FindById]: %w", err)
+	}
+	dto := appdtos.AcmeAuthorizationRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:FindById]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromAcmeAuthorizationRecordDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltAcmeAuthorizationRepository) Save(model appmodels.AcmeAuthorization) (appmodels.AcmeAuthorization, error) {
+	data, err := json.Marshal(apputils.ToAcmeAuthorizationRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:Save]: failed to marshal JSON: %w", err)
+	}
+	err = r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(AcmeAuthorizationsBucketName)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(model.ID()), data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewAcmeAuthorizationRepository creates a bbolt based repository for ACME
+// authorizations
+func NewAcmeAuthorizationRepository(
+	database *Database,
+) *BoltAcmeAuthorizationRepository {
+	return &BoltAcmeAuthorizationRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.AcmeAuthorizationRepository = (*BoltAcmeAuthorizationRepository)(nil)
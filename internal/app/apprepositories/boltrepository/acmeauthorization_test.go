@@ -0,0 +1,33 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/boltrepository"
+)
+
+func TestAcmeAuthorizationRepository_SaveAndFind(t *testing.T) {
+	database := newTestDatabase(t)
+	repo := boltrepository.NewAcmeAuthorizationRepository(database)
+	expires := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	authorization := appmodels.NewAcmeAuthorization("authz", "account", "order", appmodels.ACME_STATUS_PENDING, expires, "example.com", false, []appmodels.AcmeChallenge{
+		appmodels.NewAcmeChallenge("1", appmodels.ACME_HTTP_01, "token", appmodels.ACME_STATUS_PENDING, time.Time{}, nil),
+	})
+
+	_, err := repo.Save(authorization)
+	require.NoError(t, err)
+
+	found, err := boltrepository.NewAcmeAuthorizationRepository(database).FindById("authz")
+	require.NoError(t, err)
+	assert.Equal(t, authorization, found)
+
+	_, err = repo.FindById("missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// BoltAcmeOrderRepository implements appmodels.AcmeOrderRepository on bbolt.
+// Orders are stored as JSON by their IDs.
+type BoltAcmeOrderRepository struct {
+	database *Database
+}
+
+func (r *BoltAcmeOrderRepository) FindById(id string) (appmodels.AcmeOrder, error) {
+	var data []byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(AcmeOrdersBucketName); bucket != nil {
+			// The value is only valid during the transaction
+			data = append([]byte(nil), bucket.Get([]byte(id))...)
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("not found: %s", id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:FindById]: %w", err)
+	}
+	model, err := parseAcmeOrder(data)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+// FindAllByAccount reads every order since the bucket has no index by the
+// account
+func (r *BoltAcmeOrderRepository) FindAllByAccount(account string) ([]appmodels.AcmeOrder, error) {
+	var values [][]byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		// The bucket is created when the first order is saved
+		bucket := tx.Bucket(AcmeOrdersBucketName)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, value []byte) error {
+			values = append(values, append([]byte(nil), value...))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:FindAllByAccount]: %w", err)
+	}
+	list := make([]appmodels.AcmeOrder, 0)
+	for _, data := range values {
+		model, err := parseAcmeOrder(data)
+		if err != nil {
+			return nil, fmt.Errorf("[AcmeOrder:FindAllByAccount]: %w", err)
+		}
+		if model.AccountID() == account {
+			list = append(list, model)
+		}
+	}
+	return list, nil
+}
+
+func (r *BoltAcmeOrderRepository) Save(model appmodels.AcmeOrder) (appmodels.AcmeOrder, error) {
+	data, err := json.Marshal(apputils.ToAcmeOrderRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:Save]: failed to marshal JSON: %w", err)
+	}
+	err = r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(AcmeOrdersBucketName)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(model.ID()), data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// parseAcmeOrder parses a stored order
+func parseAcmeOrder(data []byte) (appmodels.AcmeOrder, error) {
+	dto := appdtos.AcmeOrderRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return apputils.FromAcmeOrderRecordDTO(dto)
+}
+
+// NewAcmeOrderRepository creates a bbolt based repository for ACME orders
+func NewAcmeOrderRepository(
+	database *Database,
+) *BoltAcmeOrderRepository {
+	return &BoltAcmeOrderRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.AcmeOrderRepository = (*BoltAcmeOrderRepository)(nil)
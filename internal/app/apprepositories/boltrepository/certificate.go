@@ -0,0 +1,348 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// BoltCertificateRepository implements models.CertificateRepository on bbolt.
+// Certificates are stored as DER and indexed by issuer and expiration time.
+type BoltCertificateRepository struct {
+	certManager managers.CertificateManager
+	database    *Database
+}
+
+func (r *BoltCertificateRepository) FindAllByOrganizationAndSignedBy(organization *big.Int, certificate *big.Int) ([]appmodels.Certificate, error) {
+	prefix, err := IssuerKey(certificate)
+	if err != nil {
+		return nil, fmt.Errorf("[Certificate:FindAllByOrganizationAndSignedBy]: %w", err)
+	}
+	list, err := r.findAllByIndex(organization, SignedByBucketName, func(cursor *bolt.Cursor) ([][]byte, error) {
+		var keys [][]byte
+		for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+			keys = append(keys, k[len(prefix):])
+		}
+		return keys, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[Certificate:FindAllByOrganizationAndSignedBy]: %w", err)
+	}
+	return list, nil
+}
+
+func (r *BoltCertificateRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.Certificate, error) {
+	list, err := r.findAllByIndex(organization, CertificatesBucketName, func(cursor *bolt.Cursor) ([][]byte, error) {
+		var keys [][]byte
+		for k, _ := cursor.First(); k != nil; k, _ = cursor.Next() {
+			keys = append(keys, k)
+		}
+		return keys, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[Certificate:FindAllByOrganization]: %w", err)
+	}
+	return list, nil
+}
+
+// FindAllByOrganizationAndNotAfterBefore returns certificates of an
+// organization which expire before the given time, the earliest first
+func (r *BoltCertificateRepository) FindAllByOrganizationAndNotAfterBefore(organization *big.Int, before time.Time) ([]appmodels.Certificate, error) {
+	end := NotAfterKey(before)
+	list, err := r.findAllByIndex(organization, NotAfterBucketName, func(cursor *bolt.Cursor) ([][]byte, error) {
+		var keys [][]byte
+		for k, _ := cursor.First(); k != nil && bytes.Compare(k[:len(end)], end) < 0; k, _ = cursor.Next() {
+			keys = append(keys, k[len(end):])
+		}
+		return keys, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[Certificate:FindAllByOrganizationAndNotAfterBefore]: %w", err)
+	}
+	return list, nil
+}
+
+// FindPageByOrganization returns a page of certificates of an organization.
+// The serial number, issuer and expiration indexes narrow down the candidates
+// before the remaining filters are applied.
+func (r *BoltCertificateRepository) FindPageByOrganization(organization *big.Int, query appmodels.CertificateQuery) (appmodels.CertificatePage, error) {
+	var list []appmodels.Certificate
+	var err error
+	if query.Archived {
+		list, err = r.findAllArchived(organization)
+	} else if query.SerialNumber != nil {
+		if cert, findErr := r.FindByOrganizationAndSerialNumber(organization, query.SerialNumber); findErr == nil {
+			list = append(list, cert)
+		}
+	} else if query.SignedBy != nil {
+		list, err = r.FindAllByOrganizationAndSignedBy(organization, query.SignedBy)
+	} else if !query.ExpiringBefore.IsZero() {
+		list, err = r.FindAllByOrganizationAndNotAfterBefore(organization, query.ExpiringBefore)
+	} else {
+		list, err = r.FindAllByOrganization(organization)
+	}
+	if err != nil {
+		return appmodels.CertificatePage{}, fmt.Errorf("[Certificate:FindPageByOrganization]: %w", err)
+	}
+	return apputils.QueryCertificates(list, query), nil
+}
+
+func (r *BoltCertificateRepository) FindByOrganizationAndSerialNumber(
+	organization *big.Int,
+	certificate *big.Int,
+) (appmodels.Certificate, error) {
+	if certificate == nil {
+		return nil, errors.New("no certificate serial number provided")
+	}
+	var model appmodels.Certificate
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil {
+			return err
+		}
+		key, err := SerialNumberKey(certificate)
+		if err != nil {
+			return err
+		}
+		if bucket == nil || bucket.Bucket(CertificatesBucketName).Get(key) == nil {
+			return fmt.Errorf("not found: %s/%s", organization, certificate)
+		}
+		model, err = r.readCertificate(bucket, organization, key)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[Certificate:FindByOrganizationAndSerialNumber]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltCertificateRepository) Save(certificate appmodels.Certificate) (appmodels.Certificate, error) {
+	err := r.database.db.Update(func(tx *bolt.Tx) error {
+		return r.putCertificate(tx, certificate)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save certificate: %w", err)
+	}
+	return r.FindByOrganizationAndSerialNumber(certificate.OrganizationID(), certificate.SerialNumber())
+}
+
+// Archive moves a certificate and its issuer from the certificate buckets to
+// the archive buckets and removes its index entries
+func (r *BoltCertificateRepository) Archive(organization *big.Int, certificate *big.Int) error {
+	if certificate == nil {
+		return errors.New("no certificate serial number provided")
+	}
+	err := r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil {
+			return err
+		}
+		key, err := SerialNumberKey(certificate)
+		if err != nil {
+			return err
+		}
+		if bucket == nil || bucket.Bucket(CertificatesBucketName).Get(key) == nil {
+			return fmt.Errorf("not found: %s/%s", organization, certificate)
+		}
+		// Buckets of organizations created before the archive
+		if bucket, err = createOrganizationBucket(tx, organization); err != nil {
+			return err
+		}
+
+		der := append([]byte(nil), bucket.Bucket(CertificatesBucketName).Get(key)...)
+		issuer := append([]byte{}, bucket.Bucket(IssuersBucketName).Get(key)...)
+		if err := r.deleteIndexes(bucket, key, der); err != nil {
+			return err
+		}
+		if err := bucket.Bucket(ArchivedCertificatesBucketName).Put(key, der); err != nil {
+			return err
+		}
+		if err := bucket.Bucket(ArchivedIssuersBucketName).Put(key, issuer); err != nil {
+			return err
+		}
+		if err := bucket.Bucket(CertificatesBucketName).Delete(key); err != nil {
+			return err
+		}
+		return bucket.Bucket(IssuersBucketName).Delete(key)
+	})
+	if err != nil {
+		return fmt.Errorf("[Certificate:Archive]: %w", err)
+	}
+	return nil
+}
+
+// findAllArchived reads the archived certificates of an organization
+func (r *BoltCertificateRepository) findAllArchived(organization *big.Int) ([]appmodels.Certificate, error) {
+	list := make([]appmodels.Certificate, 0)
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil || bucket == nil || bucket.Bucket(ArchivedCertificatesBucketName) == nil {
+			return err
+		}
+		issuers := bucket.Bucket(ArchivedIssuersBucketName)
+		return bucket.Bucket(ArchivedCertificatesBucketName).ForEach(func(key, der []byte) error {
+			serialNumber, err := ParseSerialNumberKey(key)
+			if err != nil {
+				return err
+			}
+			cert, err := r.certManager.ParseCertificate(append([]byte(nil), der...))
+			if err != nil {
+				return fmt.Errorf("failed to parse archived certificate '%s': %w", serialNumber, err)
+			}
+			signedBy, err := parseIssuer(issuers.Get(key))
+			if err != nil {
+				return fmt.Errorf("failed to parse issuer of archived certificate '%s': %w", serialNumber, err)
+			}
+			list = append(list, appmodels.NewCertificate(organization, signedBy, cert))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// putCertificate stores a certificate and updates the indexes in a write
+// transaction. Index entries of a previously saved version are removed.
+func (r *BoltCertificateRepository) putCertificate(tx *bolt.Tx, certificate appmodels.Certificate) error {
+	bucket, err := createOrganizationBucket(tx, certificate.OrganizationID())
+	if err != nil {
+		return err
+	}
+	key, err := SerialNumberKey(certificate.SerialNumber())
+	if err != nil {
+		return err
+	}
+	issuerKey, err := IssuerKey(certificate.SignedBy())
+	if err != nil {
+		return fmt.Errorf("invalid issuer: %w", err)
+	}
+
+	if previous := bucket.Bucket(CertificatesBucketName).Get(key); previous != nil {
+		if err := r.deleteIndexes(bucket, key, previous); err != nil {
+			return err
+		}
+	}
+
+	issuer := []byte{}
+	if signedBy := certificate.SignedBy(); signedBy != nil {
+		issuer = []byte(signedBy.String())
+	}
+	cert := certificate.Certificate()
+	if err := bucket.Bucket(CertificatesBucketName).Put(key, cert.Raw); err != nil {
+		return err
+	}
+	if err := bucket.Bucket(IssuersBucketName).Put(key, issuer); err != nil {
+		return err
+	}
+	if err := bucket.Bucket(SignedByBucketName).Put(append(issuerKey, key...), []byte{}); err != nil {
+		return err
+	}
+	return bucket.Bucket(NotAfterBucketName).Put(append(NotAfterKey(cert.NotAfter), key...), []byte{})
+}
+
+// deleteIndexes removes the index entries of a stored certificate
+func (r *BoltCertificateRepository) deleteIndexes(bucket *bolt.Bucket, key, der []byte) error {
+	cert, err := r.certManager.ParseCertificate(der)
+	if err != nil {
+		return fmt.Errorf("failed to parse stored certificate: %w", err)
+	}
+	signedBy, err := parseIssuer(bucket.Bucket(IssuersBucketName).Get(key))
+	if err != nil {
+		return err
+	}
+	issuerKey, err := IssuerKey(signedBy)
+	if err != nil {
+		return err
+	}
+	if err := bucket.Bucket(SignedByBucketName).Delete(append(issuerKey, key...)); err != nil {
+		return err
+	}
+	return bucket.Bucket(NotAfterBucketName).Delete(append(NotAfterKey(cert.NotAfter), key...))
+}
+
+// findAllByIndex reads the certificates whose serial number keys are
+// collected from an index bucket
+func (r *BoltCertificateRepository) findAllByIndex(
+	organization *big.Int,
+	indexName []byte,
+	collect func(cursor *bolt.Cursor) ([][]byte, error),
+) ([]appmodels.Certificate, error) {
+	list := make([]appmodels.Certificate, 0)
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil || bucket == nil {
+			return err
+		}
+		keys, err := collect(bucket.Bucket(indexName).Cursor())
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			model, err := r.readCertificate(bucket, organization, key)
+			if err != nil {
+				return err
+			}
+			list = append(list, model)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// readCertificate reads a certificate from the organization bucket
+func (r *BoltCertificateRepository) readCertificate(bucket *bolt.Bucket, organization *big.Int, key []byte) (appmodels.Certificate, error) {
+	serialNumber, err := ParseSerialNumberKey(key)
+	if err != nil {
+		return nil, err
+	}
+	der := bucket.Bucket(CertificatesBucketName).Get(key)
+	if der == nil {
+		return nil, fmt.Errorf("index refers to a missing certificate: %s", serialNumber)
+	}
+	// The value is only valid during the transaction, and the parsed
+	// certificate refers to it
+	cert, err := r.certManager.ParseCertificate(append([]byte(nil), der...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate '%s': %w", serialNumber, err)
+	}
+	signedBy, err := parseIssuer(bucket.Bucket(IssuersBucketName).Get(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse issuer of certificate '%s': %w", serialNumber, err)
+	}
+	return appmodels.NewCertificate(organization, signedBy, cert), nil
+}
+
+func parseIssuer(value []byte) (*big.Int, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	return apputils.ParseBigInt(string(value), 10)
+}
+
+// NewCertificateRepository creates a bbolt based repository for certificates
+func NewCertificateRepository(
+	certManager managers.CertificateManager,
+	database *Database,
+) *BoltCertificateRepository {
+	return &BoltCertificateRepository{
+		certManager: certManager,
+		database:    database,
+	}
+}
+
+var _ appmodels.CertificateRepository = (*BoltCertificateRepository)(nil)
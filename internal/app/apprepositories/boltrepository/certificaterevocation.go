@@ -0,0 +1,165 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// BoltCertificateRevocationRepository implements
+// appmodels.CertificateRevocationRepository on bbolt. Revocations are stored
+// as JSON in the order of the serial numbers of the revoked certificates.
+type BoltCertificateRevocationRepository struct {
+	database *Database
+}
+
+func (r *BoltCertificateRevocationRepository) FindAllByOrganizationAndIssuer(organization *big.Int, issuer *big.Int) ([]appmodels.CertificateRevocation, error) {
+	var values [][]byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil || bucket == nil {
+			return err
+		}
+		revocations := bucket.Bucket(RevocationsBucketName)
+		if revocations == nil {
+			return nil
+		}
+		return revocations.ForEach(func(_, value []byte) error {
+			values = append(values, append([]byte(nil), value...))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindAllByOrganizationAndIssuer]: %w", err)
+	}
+	var list []appmodels.CertificateRevocation
+	for _, data := range values {
+		model, err := parseCertificateRevocation(organization, data)
+		if err != nil {
+			return nil, fmt.Errorf("[CertificateRevocation:FindAllByOrganizationAndIssuer]: %w", err)
+		}
+		if model.IssuerSerialNumber().Cmp(issuer) == 0 {
+			list = append(list, model)
+		}
+	}
+	return list, nil
+}
+
+func (r *BoltCertificateRevocationRepository) FindByOrganizationAndSerialNumber(organization *big.Int, certificate *big.Int) (appmodels.CertificateRevocation, error) {
+	data, err := r.get(organization, RevocationsBucketName, certificate)
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindByOrganizationAndSerialNumber]: %w", err)
+	}
+	model, err := parseCertificateRevocation(organization, data)
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindByOrganizationAndSerialNumber]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltCertificateRevocationRepository) Save(model appmodels.CertificateRevocation) (appmodels.CertificateRevocation, error) {
+	data, err := json.Marshal(apputils.ToCertificateRevocationDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:Save]: failed to marshal JSON: %w", err)
+	}
+	if err := r.put(model.OrganizationID(), RevocationsBucketName, model.RevokedCertificate().SerialNumber(), data); err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltCertificateRevocationRepository) FindRevocationList(organization *big.Int, issuer *big.Int) (appmodels.RevocationList, error) {
+	data, err := r.get(organization, RevocationListsBucketName, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindRevocationList]: %w", err)
+	}
+	dto := appdtos.RevocationListDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindRevocationList]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromRevocationListDTO(organization, dto)
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindRevocationList]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltCertificateRevocationRepository) SaveRevocationList(model appmodels.RevocationList) (appmodels.RevocationList, error) {
+	data, err := json.Marshal(apputils.ToRevocationListDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:SaveRevocationList]: failed to marshal JSON: %w", err)
+	}
+	if err := r.put(model.OrganizationID(), RevocationListsBucketName, model.IssuerSerialNumber(), data); err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:SaveRevocationList]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// get returns a copy of the value of a serial number in a bucket of the
+// organization
+func (r *BoltCertificateRevocationRepository) get(organization *big.Int, name []byte, serialNumber *big.Int) ([]byte, error) {
+	key, err := SerialNumberKey(serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	err = r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil {
+			return err
+		}
+		if bucket != nil && bucket.Bucket(name) != nil {
+			// The value is only valid during the transaction
+			data = append([]byte(nil), bucket.Bucket(name).Get(key)...)
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("not found: %s@%s", serialNumber, organization)
+		}
+		return nil
+	})
+	return data, err
+}
+
+// put saves the value of a serial number in a bucket of the organization
+func (r *BoltCertificateRevocationRepository) put(organization *big.Int, name []byte, serialNumber *big.Int, data []byte) error {
+	key, err := SerialNumberKey(serialNumber)
+	if err != nil {
+		return err
+	}
+	return r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := createOrganizationBucket(tx, organization)
+		if err != nil {
+			return err
+		}
+		return bucket.Bucket(name).Put(key, data)
+	})
+}
+
+// parseCertificateRevocation parses a stored revocation
+func parseCertificateRevocation(organization *big.Int, data []byte) (appmodels.CertificateRevocation, error) {
+	dto := appdtos.CertificateRevocationDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return apputils.FromCertificateRevocationDTO(organization, dto)
+}
+
+// NewCertificateRevocationRepository creates a bbolt based repository for
+// certificate revocations
+func NewCertificateRevocationRepository(
+	database *Database,
+) *BoltCertificateRevocationRepository {
+	return &BoltCertificateRevocationRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.CertificateRevocationRepository = (*BoltCertificateRevocationRepository)(nil)
@@ -0,0 +1,244 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// The bucket layout is:
+//
+//	organizations/
+//	  {organization}/
+//	    organization        -> organization JSON
+//	    certificates/       {serial} -> certificate DER
+//	    issuers/            {serial} -> issuer serial, empty for root certificates
+//	    private_keys/       {serial} -> private key PEM
+//	    signed_by/          {issuer}{serial} -> empty
+//	    not_after/          {unix time}{serial} -> empty
+//	    archived/           {serial} -> archived certificate DER
+//	    archived_issuers/   {serial} -> issuer serial of an archived certificate
+//	    ssh_authority       -> SSH CA JSON with the private key as PEM
+//	    ssh_certificates/   {ssh serial} -> SSH certificate JSON
+//	    revocations/        {serial} -> revocation JSON
+//	    revocation_lists/   {issuer} -> last CRL number JSON
+//	    scep_challenge      -> SCEP challenge password JSON
+//	    vault_roles/        {name} -> Vault role JSON
+//	operations/
+//	  {id}                  -> pending operation JSON
+//	acme_accounts/
+//	  {id}                  -> ACME account JSON
+//	acme_orders/
+//	  {id}                  -> ACME order JSON
+//	acme_authorizations/
+//	  {id}                  -> ACME authorization JSON with its challenges
+//	est_labels/
+//	  {label}               -> EST label JSON
+//
+// Serial numbers are encoded with SerialNumberKey, and SSH serial numbers
+// with SshSerialKey, so that the cursor order of the buckets is the numeric
+// order.
+var (
+	OrganizationsBucketName = []byte("organizations")
+	OrganizationKey         = []byte("organization")
+	CertificatesBucketName  = []byte("certificates")
+	IssuersBucketName       = []byte("issuers")
+	PrivateKeysBucketName   = []byte("private_keys")
+	SignedByBucketName      = []byte("signed_by")
+	NotAfterBucketName      = []byte("not_after")
+
+	ArchivedCertificatesBucketName = []byte("archived")
+	ArchivedIssuersBucketName      = []byte("archived_issuers")
+
+	SshAuthorityKey           = []byte("ssh_authority")
+	SshCertificatesBucketName = []byte("ssh_certificates")
+
+	RevocationsBucketName     = []byte("revocations")
+	RevocationListsBucketName = []byte("revocation_lists")
+
+	OperationsBucketName = []byte("operations")
+
+	ScepChallengeKey     = []byte("scep_challenge")
+	VaultRolesBucketName = []byte("vault_roles")
+	EstLabelsBucketName  = []byte("est_labels")
+
+	AcmeAccountsBucketName       = []byte("acme_accounts")
+	AcmeOrdersBucketName         = []byte("acme_orders")
+	AcmeAuthorizationsBucketName = []byte("acme_authorizations")
+)
+
+// SerialNumberKeySize is the size of an encoded serial number. X.509 serial
+// numbers are at most 20 bytes; organization IDs are 128 bits.
+const SerialNumberKeySize = 32
+
+// Database is an opened bbolt database shared by the repositories
+type Database struct {
+	db *bolt.DB
+}
+
+// DB returns the underlying bbolt database
+func (d *Database) DB() *bolt.DB {
+	return d.db
+}
+
+// Close closes the database
+func (d *Database) Close() error {
+	return d.db.Close()
+}
+
+// NewDatabase wraps an opened bbolt database and creates the top level bucket
+func NewDatabase(db *bolt.DB) (*Database, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(OrganizationsBucketName)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize bolt database: %w", err)
+	}
+	return &Database{db: db}, nil
+}
+
+// NewReadOnlyDatabase wraps a bbolt database opened in read-only mode. The
+// top level bucket must exist.
+func NewReadOnlyDatabase(db *bolt.DB) (*Database, error) {
+	err := db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(OrganizationsBucketName) == nil {
+			return errors.New("bolt database is not initialized")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Database{db: db}, nil
+}
+
+// OpenDatabase opens or creates a bbolt database file. The file is locked
+// while it is open, so another process fails after a timeout.
+func OpenDatabase(fileName string) (*Database, error) {
+	db, err := bolt.Open(fileName, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt database '%s': %w", fileName, err)
+	}
+	database, err := NewDatabase(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return database, nil
+}
+
+// OpenReadOnlyDatabase opens an existing bbolt database file in read-only
+// mode. Other readers may open the file at the same time, but a writer fails
+// after a timeout.
+func OpenReadOnlyDatabase(fileName string) (*Database, error) {
+	db, err := bolt.Open(fileName, 0600, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt database '%s': %w", fileName, err)
+	}
+	database, err := NewReadOnlyDatabase(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return database, nil
+}
+
+// SerialNumberKey encodes a non-negative integer as a fixed size big-endian
+// key
+func SerialNumberKey(value *big.Int) ([]byte, error) {
+	if value == nil || value.Sign() < 0 || len(value.Bytes()) > SerialNumberKeySize {
+		return nil, fmt.Errorf("invalid serial number: %v", value)
+	}
+	return value.FillBytes(make([]byte, SerialNumberKeySize)), nil
+}
+
+// ParseSerialNumberKey decodes a key created by SerialNumberKey
+func ParseSerialNumberKey(key []byte) (*big.Int, error) {
+	if len(key) != SerialNumberKeySize {
+		return nil, fmt.Errorf("invalid serial number key length: %d", len(key))
+	}
+	return new(big.Int).SetBytes(key), nil
+}
+
+// SshSerialKey encodes an SSH certificate serial number as a big-endian key
+func SshSerialKey(serial uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, serial)
+	return key
+}
+
+// IssuerKey encodes the issuer prefix of the signed_by index. Root
+// certificates have a nil issuer and a key with a zero flag byte.
+func IssuerKey(issuer *big.Int) ([]byte, error) {
+	if issuer == nil {
+		return make([]byte, 1+SerialNumberKeySize), nil
+	}
+	key, err := SerialNumberKey(issuer)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{1}, key...), nil
+}
+
+// NotAfterKey encodes the time prefix of the not_after index
+func NotAfterKey(notAfter time.Time) []byte {
+	key := make([]byte, 8)
+	unix := notAfter.Unix()
+	if unix < 0 {
+		unix = 0
+	}
+	binary.BigEndian.PutUint64(key, uint64(unix))
+	return key
+}
+
+// organizationBucket returns the bucket of an organization, or nil if it does
+// not exist
+func organizationBucket(tx *bolt.Tx, organization *big.Int) (*bolt.Bucket, error) {
+	key, err := SerialNumberKey(organization)
+	if err != nil {
+		return nil, err
+	}
+	root := tx.Bucket(OrganizationsBucketName)
+	if root == nil {
+		return nil, errors.New("bolt database is not initialized")
+	}
+	return root.Bucket(key), nil
+}
+
+// createOrganizationBucket returns the bucket of an organization and its
+// nested buckets, creating them if missing
+func createOrganizationBucket(tx *bolt.Tx, organization *big.Int) (*bolt.Bucket, error) {
+	key, err := SerialNumberKey(organization)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := tx.Bucket(OrganizationsBucketName).CreateBucketIfNotExists(key)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range [][]byte{
+		CertificatesBucketName,
+		IssuersBucketName,
+		PrivateKeysBucketName,
+		SignedByBucketName,
+		NotAfterBucketName,
+		ArchivedCertificatesBucketName,
+		ArchivedIssuersBucketName,
+		SshCertificatesBucketName,
+		RevocationsBucketName,
+		RevocationListsBucketName,
+		VaultRolesBucketName,
+	} {
+		if _, err := bucket.CreateBucketIfNotExists(name); err != nil {
+			return nil, err
+		}
+	}
+	return bucket, nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository_test
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/boltrepository"
+)
+
+// newTestDatabase opens a bbolt database in a temporary directory
+func newTestDatabase(t *testing.T) *boltrepository.Database {
+	t.Helper()
+	database, err := boltrepository.OpenDatabase(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = database.Close() })
+	return database
+}
+
+func TestOpenDatabase_Reopen(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.db")
+	database, err := boltrepository.OpenDatabase(fileName)
+	require.NoError(t, err)
+	assert.NotNil(t, database.DB())
+	require.NoError(t, database.Close())
+
+	database, err = boltrepository.OpenDatabase(fileName)
+	require.NoError(t, err)
+	assert.NoError(t, database.Close())
+}
+
+func TestOpenReadOnlyDatabase(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.db")
+	_, err := boltrepository.OpenReadOnlyDatabase(fileName)
+	assert.Error(t, err, "Expected a missing file to be refused")
+
+	database, err := boltrepository.OpenDatabase(fileName)
+	require.NoError(t, err)
+	_, err = boltrepository.NewOrganizationRepository(database).Save(appmodels.NewOrganization(big.NewInt(1), "test", []string{"Test"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	require.NoError(t, database.Close())
+
+	database, err = boltrepository.OpenReadOnlyDatabase(fileName)
+	require.NoError(t, err)
+	defer database.Close()
+	assert.True(t, database.DB().IsReadOnly())
+
+	repository := boltrepository.NewOrganizationRepository(database)
+	_, err = repository.FindById(big.NewInt(1))
+	assert.NoError(t, err)
+	_, err = repository.Save(appmodels.NewOrganization(big.NewInt(2), "other", []string{"Other"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	assert.Error(t, err)
+}
+
+func TestSerialNumberKey(t *testing.T) {
+	small, err := boltrepository.SerialNumberKey(big.NewInt(2))
+	require.NoError(t, err)
+	large, err := boltrepository.SerialNumberKey(big.NewInt(256))
+	require.NoError(t, err)
+	assert.Len(t, small, boltrepository.SerialNumberKeySize)
+	assert.Less(t, string(small), string(large), "Expected byte order to match numeric order")
+
+	parsed, err := boltrepository.ParseSerialNumberKey(large)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(256), parsed)
+
+	_, err = boltrepository.SerialNumberKey(nil)
+	assert.Error(t, err)
+	_, err = boltrepository.SerialNumberKey(big.NewInt(-1))
+	assert.Error(t, err)
+	_, err = boltrepository.SerialNumberKey(new(big.Int).Lsh(big.NewInt(1), 8*boltrepository.SerialNumberKeySize))
+	assert.Error(t, err)
+	_, err = boltrepository.ParseSerialNumberKey([]byte{1})
+	assert.Error(t, err)
+}
+
+func TestIssuerKey(t *testing.T) {
+	root, err := boltrepository.IssuerKey(nil)
+	require.NoError(t, err)
+	issuer, err := boltrepository.IssuerKey(big.NewInt(0))
+	require.NoError(t, err)
+	assert.Len(t, root, 1+boltrepository.SerialNumberKeySize)
+	assert.NotEqual(t, root, issuer)
+}
+
+func TestNotAfterKey(t *testing.T) {
+	earlier := boltrepository.NotAfterKey(time.Unix(100, 0))
+	later := boltrepository.NotAfterKey(time.Unix(1000, 0))
+	assert.Less(t, string(earlier), string(later))
+	assert.Equal(t, boltrepository.NotAfterKey(time.Unix(0, 0)), boltrepository.NotAfterKey(time.Unix(-5, 0)))
+}
+
+func TestSshSerialKey(t *testing.T) {
+	assert.Len(t, boltrepository.SshSerialKey(1), 8)
+	assert.Less(t, string(boltrepository.SshSerialKey(255)), string(boltrepository.SshSerialKey(256)))
+}
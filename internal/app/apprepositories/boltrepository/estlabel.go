@@ -0,0 +1,110 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// BoltEstLabelRepository implements appmodels.EstLabelRepository on bbolt.
+// Labels are stored as JSON in the order of the labels.
+type BoltEstLabelRepository struct {
+	database *Database
+}
+
+func (r *BoltEstLabelRepository) FindByLabel(label string) (appmodels.EstLabel, error) {
+	var data []byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(EstLabelsBucketName); bucket != nil {
+			// The value is only valid during the transaction
+			data = append([]byte(nil), bucket.Get([]byte(label))...)
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("not found: %s", label)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[EstLabel:FindByLabel]: %w", err)
+	}
+	model, err := parseEstLabel(data)
+	if err != nil {
+		return nil, fmt.Errorf("[EstLabel:FindByLabel]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltEstLabelRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.EstLabel, error) {
+	var values [][]byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		// The bucket is created when the first label is saved
+		bucket := tx.Bucket(EstLabelsBucketName)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, value []byte) error {
+			values = append(values, append([]byte(nil), value...))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[EstLabel:FindAllByOrganization]: %w", err)
+	}
+	list := make([]appmodels.EstLabel, 0)
+	for _, data := range values {
+		model, err := parseEstLabel(data)
+		if err != nil {
+			return nil, fmt.Errorf("[EstLabel:FindAllByOrganization]: %w", err)
+		}
+		if model.OrganizationID().Cmp(organization) == 0 {
+			list = append(list, model)
+		}
+	}
+	return list, nil
+}
+
+func (r *BoltEstLabelRepository) Save(model appmodels.EstLabel) (appmodels.EstLabel, error) {
+	data, err := json.Marshal(apputils.ToEstLabelRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[EstLabel:Save]: failed to marshal JSON: %w", err)
+	}
+	err = r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(EstLabelsBucketName)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(model.Label()), data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[EstLabel:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// parseEstLabel parses a stored label
+func parseEstLabel(data []byte) (appmodels.EstLabel, error) {
+	dto := appdtos.EstLabelRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return apputils.FromEstLabelRecordDTO(dto)
+}
+
+// NewEstLabelRepository creates a bbolt based repository for EST labels
+func NewEstLabelRepository(
+	database *Database,
+) *BoltEstLabelRepository {
+	return &BoltEstLabelRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.EstLabelRepository = (*BoltEstLabelRepository)(nil)
@@ -0,0 +1,174 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// BoltOrganizationRepository implements models.OrganizationRepository on bbolt
+type BoltOrganizationRepository struct {
+	database *Database
+}
+
+func (r *BoltOrganizationRepository) FindAll() ([]appmodels.Organization, error) {
+	list := make([]appmodels.Organization, 0)
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(OrganizationsBucketName).ForEachBucket(func(key []byte) error {
+			data := tx.Bucket(OrganizationsBucketName).Bucket(key).Get(OrganizationKey)
+			// Buckets without organization data only hold certificates
+			if data == nil {
+				return nil
+			}
+			model, err := parseOrganization(data)
+			if err != nil {
+				return err
+			}
+			list = append(list, model)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[Organization:FindAll]: %w", err)
+	}
+	return list, nil
+}
+
+// FindPage returns a page of organizations. The scan starts from the cursor
+// and stops once the page is full.
+func (r *BoltOrganizationRepository) FindPage(query appmodels.OrganizationQuery) (appmodels.OrganizationPage, error) {
+	list := make([]appmodels.Organization, 0)
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		root := tx.Bucket(OrganizationsBucketName)
+		cursor := root.Cursor()
+		key, _ := cursor.First()
+		if query.After != nil {
+			after, err := SerialNumberKey(query.After)
+			if err != nil {
+				return err
+			}
+			key, _ = cursor.Seek(after)
+			if key != nil && bytes.Equal(key, after) {
+				key, _ = cursor.Next()
+			}
+		}
+		for ; key != nil; key, _ = cursor.Next() {
+			if query.Limit > 0 && len(list) > query.Limit {
+				break
+			}
+			bucket := root.Bucket(key)
+			if bucket == nil {
+				continue
+			}
+			// Buckets without organization data only hold certificates
+			data := bucket.Get(OrganizationKey)
+			if data == nil {
+				continue
+			}
+			model, err := parseOrganization(data)
+			if err != nil {
+				return err
+			}
+			if query.Name != "" && !apputils.OrganizationMatchesName(model, query.Name) {
+				continue
+			}
+			list = append(list, model)
+		}
+		return nil
+	})
+	if err != nil {
+		return appmodels.OrganizationPage{}, fmt.Errorf("[Organization:FindPage]: %w", err)
+	}
+	return apputils.QueryOrganizations(list, query), nil
+}
+
+func (r *BoltOrganizationRepository) FindById(id *big.Int) (appmodels.Organization, error) {
+	var model appmodels.Organization
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, id)
+		if err != nil {
+			return err
+		}
+		var data []byte
+		if bucket != nil {
+			data = bucket.Get(OrganizationKey)
+		}
+		if data == nil {
+			return fmt.Errorf("not found: %s", id)
+		}
+		model, err = parseOrganization(data)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[Organization:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltOrganizationRepository) Save(organization appmodels.Organization) (appmodels.Organization, error) {
+	id := organization.ID()
+	data, err := json.Marshal(apputils.ToOrganizationDTO(organization))
+	if err != nil {
+		return nil, fmt.Errorf("[Organization:Save]: failed to marshal organization: %w", err)
+	}
+	err = r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := createOrganizationBucket(tx, id)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(OrganizationKey, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[Organization:Save]: failed to save '%s': %w", id, err)
+	}
+	return r.FindById(id)
+}
+
+func parseOrganization(data []byte) (appmodels.Organization, error) {
+	dto := appdtos.OrganizationDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal organization: %w", err)
+	}
+	id, err := apputils.ParseBigInt(dto.ID, 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse organization id '%s': %w", dto.ID, err)
+	}
+	if id == nil {
+		return nil, errors.New("organization id missing")
+	}
+	signatureAlgorithm, err := apputils.ParseSignatureAlgorithm(dto.SignatureAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse organization signature algorithm '%s': %w", dto.SignatureAlgorithm, err)
+	}
+	keyRetentionPolicy, err := apputils.ParseKeyRetentionPolicy(dto.KeyRetentionPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse organization key retention policy '%s': %w", dto.KeyRetentionPolicy, err)
+	}
+	return appmodels.NewOrganization(
+		id,
+		dto.Slug,
+		dto.AllNames,
+		signatureAlgorithm,
+		dto.SpiffeTrustDomain,
+		keyRetentionPolicy,
+	), nil
+}
+
+// NewOrganizationRepository creates a bbolt based repository for organizations
+func NewOrganizationRepository(database *Database) *BoltOrganizationRepository {
+	return &BoltOrganizationRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.OrganizationRepository = (*BoltOrganizationRepository)(nil)
@@ -0,0 +1,75 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/boltrepository"
+)
+
+func TestBoltOrganizationRepository(t *testing.T) {
+	repo := boltrepository.NewOrganizationRepository(newTestDatabase(t))
+
+	list, err := repo.FindAll()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	organization := appmodels.NewOrganization(big.NewInt(300), "acme", []string{"Acme", "Acme Inc"}, appmodels.SHA384_WITH_RSA, "acme.example", appmodels.KEY_RETENTION_ESCROW)
+	saved, err := repo.Save(organization)
+	require.NoError(t, err)
+	assert.Equal(t, organization.ID(), saved.ID())
+	assert.Equal(t, []string{"Acme", "Acme Inc"}, saved.Names())
+	assert.Equal(t, appmodels.SHA384_WITH_RSA, saved.SignatureAlgorithm())
+	assert.Equal(t, "acme.example", saved.SpiffeTrustDomain())
+	assert.Equal(t, appmodels.KEY_RETENTION_ESCROW, saved.KeyRetentionPolicy())
+
+	_, err = repo.Save(appmodels.NewOrganization(big.NewInt(3), "beta", []string{"Beta"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+
+	list, err = repo.FindAll()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, big.NewInt(3), list[0].ID())
+	assert.Equal(t, big.NewInt(300), list[1].ID())
+
+	_, err = repo.FindById(big.NewInt(999))
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestBoltOrganizationRepository_FindPage(t *testing.T) {
+	repo := boltrepository.NewOrganizationRepository(newTestDatabase(t))
+	for _, v := range []struct {
+		id   int64
+		slug string
+	}{{10, "gamma"}, {2, "beta"}, {1, "alpha"}} {
+		_, err := repo.Save(appmodels.NewOrganization(big.NewInt(v.id), v.slug, []string{v.slug + " Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+		assert.NoError(t, err)
+	}
+	idsOf := func(page appmodels.OrganizationPage) []string {
+		var ids []string
+		for _, org := range page.Organizations {
+			ids = append(ids, org.ID().String())
+		}
+		return ids
+	}
+
+	page, err := repo.FindPage(appmodels.OrganizationQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, idsOf(page))
+	if assert.NotNil(t, page.Next) {
+		page, err = repo.FindPage(appmodels.OrganizationQuery{Limit: 2, After: page.Next})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"10"}, idsOf(page))
+		assert.Nil(t, page.Next)
+	}
+
+	page, err = repo.FindPage(appmodels.OrganizationQuery{Name: "GAM"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10"}, idsOf(page))
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// BoltPendingOperationRepository implements
+// appmodels.PendingOperationRepository on bbolt. Operations are stored as
+// JSON by their IDs.
+type BoltPendingOperationRepository struct {
+	database *Database
+}
+
+func (r *BoltPendingOperationRepository) FindAll() ([]appmodels.PendingOperation, error) {
+	var values [][]byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		// The bucket is created when the first operation is saved
+		bucket := tx.Bucket(OperationsBucketName)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, value []byte) error {
+			values = append(values, append([]byte(nil), value...))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[PendingOperation:FindAll]: %w", err)
+	}
+	list := make([]appmodels.PendingOperation, 0, len(values))
+	for _, data := range values {
+		model, err := parsePendingOperation(data)
+		if err != nil {
+			return nil, fmt.Errorf("[PendingOperation:FindAll]: %w", err)
+		}
+		list = append(list, model)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt().Equal(list[j].CreatedAt()) {
+			return list[i].ID() < list[j].ID()
+		}
+		return list[i].CreatedAt().Before(list[j].CreatedAt())
+	})
+	return list, nil
+}
+
+func (r *BoltPendingOperationRepository) FindById(id string) (appmodels.PendingOperation, error) {
+	var data []byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(OperationsBucketName); bucket != nil {
+			// The value is only valid during the transaction
+			data = append([]byte(nil), bucket.Get([]byte(id))...)
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("not found: %s", id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[PendingOperation:FindById]: %w", err)
+	}
+	model, err := parsePendingOperation(data)
+	if err != nil {
+		return nil, fmt.Errorf("[PendingOperation:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltPendingOperationRepository) Save(model appmodels.PendingOperation) (appmodels.PendingOperation, error) {
+	data, err := json.Marshal(apputils.ToPendingOperationRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[PendingOperation:Save]: failed to marshal JSON: %w", err)
+	}
+	err = r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(OperationsBucketName)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(model.ID()), data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[PendingOperation:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// parsePendingOperation parses a stored operation
+func parsePendingOperation(data []byte) (appmodels.PendingOperation, error) {
+	dto := appdtos.PendingOperationRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return apputils.FromPendingOperationRecordDTO(dto)
+}
+
+// NewPendingOperationRepository creates a bbolt based repository for
+// operations waiting for approval
+func NewPendingOperationRepository(
+	database *Database,
+) *BoltPendingOperationRepository {
+	return &BoltPendingOperationRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.PendingOperationRepository = (*BoltPendingOperationRepository)(nil)
@@ -0,0 +1,98 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// BoltPrivateKeyRepository implements models.PrivateKeyRepository on bbolt.
+// Private keys are stored as PEM.
+type BoltPrivateKeyRepository struct {
+	certManager managers.CertificateManager
+	database    *Database
+}
+
+func (r *BoltPrivateKeyRepository) FindByOrganizationAndSerialNumber(
+	organization,
+	certificate *big.Int,
+) (appmodels.PrivateKey, error) {
+	if certificate == nil {
+		return nil, errors.New("no certificate serial number provided")
+	}
+	var data []byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil {
+			return err
+		}
+		key, err := SerialNumberKey(certificate)
+		if err != nil {
+			return err
+		}
+		if bucket != nil {
+			// The value is only valid during the transaction
+			data = append([]byte(nil), bucket.Bucket(PrivateKeysBucketName).Get(key)...)
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("not found: %s/%s", organization, certificate)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[PrivateKey:FindByOrganizationAndSerialNumber]: %w", err)
+	}
+	privateKey, keyType, err := apputils.ParsePrivateKeyFromPEMBytes(r.certManager, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return appmodels.NewPrivateKey(organization, certificate, keyType, privateKey), nil
+}
+
+func (r *BoltPrivateKeyRepository) Save(key appmodels.PrivateKey) (appmodels.PrivateKey, error) {
+	pemData, err := apputils.MarshalPrivateKeyAsPEM(r.certManager, key.PrivateKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize private key to PEM: %w", err)
+	}
+	err = r.database.db.Update(func(tx *bolt.Tx) error {
+		return putPrivateKey(tx, key.OrganizationID(), key.SerialNumber(), pemData)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save private key: %w", err)
+	}
+	return key, nil
+}
+
+// putPrivateKey stores a PEM encoded private key in a write transaction
+func putPrivateKey(tx *bolt.Tx, organization, serialNumber *big.Int, pemData []byte) error {
+	bucket, err := createOrganizationBucket(tx, organization)
+	if err != nil {
+		return err
+	}
+	key, err := SerialNumberKey(serialNumber)
+	if err != nil {
+		return err
+	}
+	return bucket.Bucket(PrivateKeysBucketName).Put(key, pemData)
+}
+
+// NewPrivateKeyRepository creates a bbolt based repository for private keys
+func NewPrivateKeyRepository(
+	certManager managers.CertificateManager,
+	database *Database,
+) *BoltPrivateKeyRepository {
+	return &BoltPrivateKeyRepository{
+		certManager: certManager,
+		database:    database,
+	}
+}
+
+var _ appmodels.PrivateKeyRepository = (*BoltPrivateKeyRepository)(nil)
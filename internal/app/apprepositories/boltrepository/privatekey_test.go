@@ -0,0 +1,41 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/boltrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestBoltPrivateKeyRepository(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	repo := boltrepository.NewPrivateKeyRepository(certManager, newTestDatabase(t))
+
+	organization := big.NewInt(123)
+	serialNumber := big.NewInt(1)
+	key, err := apputils.GeneratePrivateKey(organization, serialNumber, appmodels.ECDSA_P256)
+	require.NoError(t, err)
+
+	_, err = repo.Save(key)
+	require.NoError(t, err)
+
+	found, err := repo.FindByOrganizationAndSerialNumber(organization, serialNumber)
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ECDSA_P256, found.KeyType())
+	assert.Equal(t, key.PrivateKey(), found.PrivateKey())
+
+	_, err = repo.FindByOrganizationAndSerialNumber(organization, big.NewInt(2))
+	assert.ErrorContains(t, err, "not found")
+	_, err = repo.FindByOrganizationAndSerialNumber(big.NewInt(1), serialNumber)
+	assert.ErrorContains(t, err, "not found")
+	_, err = repo.FindByOrganizationAndSerialNumber(organization, nil)
+	assert.EqualError(t, err, "no certificate serial number provided")
+}
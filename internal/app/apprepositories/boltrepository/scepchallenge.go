@@ -0,0 +1,81 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// BoltScepChallengeRepository implements appmodels.ScepChallengeRepository
+// on bbolt. The challenge is stored as JSON in the organization bucket.
+type BoltScepChallengeRepository struct {
+	database *Database
+}
+
+func (r *BoltScepChallengeRepository) FindByOrganization(organization *big.Int) (appmodels.ScepChallenge, error) {
+	var data []byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil {
+			return err
+		}
+		if bucket != nil {
+			// The value is only valid during the transaction
+			data = append([]byte(nil), bucket.Get(ScepChallengeKey)...)
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("not found: %s", organization)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:FindByOrganization]: %w", err)
+	}
+	dto := appdtos.ScepChallengeRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:FindByOrganization]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromScepChallengeRecordDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:FindByOrganization]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltScepChallengeRepository) Save(model appmodels.ScepChallenge) (appmodels.ScepChallenge, error) {
+	data, err := json.Marshal(apputils.ToScepChallengeRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:Save]: failed to marshal JSON: %w", err)
+	}
+	err = r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := createOrganizationBucket(tx, model.OrganizationID())
+		if err != nil {
+			return err
+		}
+		return bucket.Put(ScepChallengeKey, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewScepChallengeRepository creates a bbolt based repository for SCEP
+// challenge passwords
+func NewScepChallengeRepository(
+	database *Database,
+) *BoltScepChallengeRepository {
+	return &BoltScepChallengeRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.ScepChallengeRepository = (*BoltScepChallengeRepository)(nil)
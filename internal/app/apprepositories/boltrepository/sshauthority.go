@@ -0,0 +1,88 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// BoltSshAuthorityRepository implements appmodels.SshAuthorityRepository on
+// bbolt. The CA key is stored as PEM inside the JSON value.
+type BoltSshAuthorityRepository struct {
+	certManager managers.CertificateManager
+	database    *Database
+}
+
+func (r *BoltSshAuthorityRepository) FindByOrganization(organization *big.Int) (appmodels.SshAuthority, error) {
+	var data []byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil {
+			return err
+		}
+		if bucket != nil {
+			// The value is only valid during the transaction
+			data = append([]byte(nil), bucket.Get(SshAuthorityKey)...)
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("not found: %s", organization)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: %w", err)
+	}
+	dto := appdtos.SshAuthorityRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromSshAuthorityRecordDTO(r.certManager, dto)
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltSshAuthorityRepository) Save(model appmodels.SshAuthority) (appmodels.SshAuthority, error) {
+	dto, err := apputils.ToSshAuthorityRecordDTO(r.certManager, model)
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:Save]: %w", err)
+	}
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:Save]: failed to marshal JSON: %w", err)
+	}
+	err = r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := createOrganizationBucket(tx, model.OrganizationID())
+		if err != nil {
+			return err
+		}
+		return bucket.Put(SshAuthorityKey, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewSshAuthorityRepository creates a bbolt based repository for SSH CA keys
+func NewSshAuthorityRepository(
+	certManager managers.CertificateManager,
+	database *Database,
+) *BoltSshAuthorityRepository {
+	return &BoltSshAuthorityRepository{
+		certManager: certManager,
+		database:    database,
+	}
+}
+
+var _ appmodels.SshAuthorityRepository = (*BoltSshAuthorityRepository)(nil)
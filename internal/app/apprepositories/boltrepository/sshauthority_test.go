@@ -0,0 +1,52 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/boltrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestSshAuthority(t *testing.T, organization *big.Int) appmodels.SshAuthority {
+	t.Helper()
+	privateKey, err := apputils.GeneratePrivateKey(organization, big.NewInt(7), appmodels.Ed25519)
+	require.NoError(t, err)
+	return appmodels.NewSshAuthority(organization, big.NewInt(7), privateKey, time.Unix(1000, 0))
+}
+
+func TestSshAuthorityRepository_SaveAndFind(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	repo := boltrepository.NewSshAuthorityRepository(certManager, newTestDatabase(t))
+	organization := big.NewInt(123)
+
+	_, err := repo.FindByOrganization(organization)
+	assert.ErrorContains(t, err, "not found")
+
+	authority := newTestSshAuthority(t, organization)
+	_, err = repo.Save(authority)
+	require.NoError(t, err)
+
+	found, err := repo.FindByOrganization(organization)
+	require.NoError(t, err)
+	assert.Equal(t, organization, found.OrganizationID())
+	assert.Equal(t, big.NewInt(7), found.ID())
+	assert.True(t, authority.CreatedAt().Equal(found.CreatedAt()))
+	assert.Equal(t, appmodels.Ed25519, found.PrivateKey().KeyType())
+	assert.Equal(t, authority.PrivateKey().PublicKey(), found.PrivateKey().PublicKey())
+
+	// Saving again replaces the CA
+	_, err = repo.Save(appmodels.NewSshAuthority(organization, big.NewInt(8), authority.PrivateKey(), time.Unix(2000, 0)))
+	require.NoError(t, err)
+	found, err = repo.FindByOrganization(organization)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(8), found.ID())
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// BoltSshCertificateRepository implements appmodels.SshCertificateRepository
+// on bbolt. Certificates are stored as JSON in the order of their serial
+// numbers.
+type BoltSshCertificateRepository struct {
+	database *Database
+}
+
+func (r *BoltSshCertificateRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.SshCertificate, error) {
+	var values [][]byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil || bucket == nil {
+			return err
+		}
+		certificates := bucket.Bucket(SshCertificatesBucketName)
+		if certificates == nil {
+			return nil
+		}
+		return certificates.ForEach(func(_, value []byte) error {
+			values = append(values, append([]byte(nil), value...))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[SshCertificate:FindAllByOrganization]: %w", err)
+	}
+	list := make([]appmodels.SshCertificate, 0, len(values))
+	for _, data := range values {
+		model, err := parseSshCertificate(data)
+		if err != nil {
+			return nil, fmt.Errorf("[SshCertificate:FindAllByOrganization]: %w", err)
+		}
+		list = append(list, model)
+	}
+	return list, nil
+}
+
+func (r *BoltSshCertificateRepository) FindByOrganizationAndSerial(organization *big.Int, serial uint64) (appmodels.SshCertificate, error) {
+	var data []byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil {
+			return err
+		}
+		if bucket != nil && bucket.Bucket(SshCertificatesBucketName) != nil {
+			// The value is only valid during the transaction
+			data = append([]byte(nil), bucket.Bucket(SshCertificatesBucketName).Get(SshSerialKey(serial))...)
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("not found: %d@%s", serial, organization)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[SshCertificate:FindByOrganizationAndSerial]: %w", err)
+	}
+	model, err := parseSshCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("[SshCertificate:FindByOrganizationAndSerial]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltSshCertificateRepository) Save(model appmodels.SshCertificate) (appmodels.SshCertificate, error) {
+	data, err := json.Marshal(apputils.ToSshCertificateRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[SshCertificate:Save]: failed to marshal JSON: %w", err)
+	}
+	err = r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := createOrganizationBucket(tx, model.OrganizationID())
+		if err != nil {
+			return err
+		}
+		return bucket.Bucket(SshCertificatesBucketName).Put(SshSerialKey(model.Serial()), data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[SshCertificate:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// parseSshCertificate parses a stored SSH certificate
+func parseSshCertificate(data []byte) (appmodels.SshCertificate, error) {
+	dto := appdtos.SshCertificateRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return apputils.FromSshCertificateRecordDTO(dto)
+}
+
+// NewSshCertificateRepository creates a bbolt based repository for signed SSH
+// certificates
+func NewSshCertificateRepository(
+	database *Database,
+) *BoltSshCertificateRepository {
+	return &BoltSshCertificateRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.SshCertificateRepository = (*BoltSshCertificateRepository)(nil)
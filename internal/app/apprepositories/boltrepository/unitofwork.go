@@ -0,0 +1,96 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// BoltUnitOfWorkRepository implements appmodels.UnitOfWorkRepository on bbolt
+type BoltUnitOfWorkRepository struct {
+	certificates *BoltCertificateRepository
+	certManager  managers.CertificateManager
+	database     *Database
+}
+
+func (r *BoltUnitOfWorkRepository) NewUnitOfWork() appmodels.UnitOfWork {
+	return &BoltUnitOfWork{repository: r}
+}
+
+// BoltUnitOfWork implements appmodels.UnitOfWork. All changes are saved in a
+// single write transaction.
+type BoltUnitOfWork struct {
+	repository   *BoltUnitOfWorkRepository
+	certificates []appmodels.Certificate
+	keys         []appmodels.PrivateKey
+	committed    bool
+}
+
+func (w *BoltUnitOfWork) SaveCertificate(certificate appmodels.Certificate) {
+	w.certificates = append(w.certificates, certificate)
+}
+
+func (w *BoltUnitOfWork) SavePrivateKey(key appmodels.PrivateKey) {
+	w.keys = append(w.keys, key)
+}
+
+func (w *BoltUnitOfWork) Commit() error {
+	if w.committed {
+		return errors.New("[UnitOfWork:Commit]: already committed")
+	}
+	w.committed = true
+
+	keyData := make([][]byte, 0, len(w.keys))
+	for _, key := range w.keys {
+		pemData, err := apputils.MarshalPrivateKeyAsPEM(w.repository.certManager, key.PrivateKey())
+		if err != nil {
+			return fmt.Errorf("[UnitOfWork:Commit]: failed to serialize private key to PEM: %w", err)
+		}
+		keyData = append(keyData, pemData)
+	}
+	for _, certificate := range w.certificates {
+		if certificate.Certificate() == nil {
+			return fmt.Errorf("[UnitOfWork:Commit]: no certificate data: %s", certificate.SerialNumber())
+		}
+	}
+
+	err := w.repository.database.db.Update(func(tx *bolt.Tx) error {
+		for i, key := range w.keys {
+			if err := putPrivateKey(tx, key.OrganizationID(), key.SerialNumber(), keyData[i]); err != nil {
+				return fmt.Errorf("failed to save private key: %w", err)
+			}
+		}
+		for _, certificate := range w.certificates {
+			if err := w.repository.certificates.putCertificate(tx, certificate); err != nil {
+				return fmt.Errorf("failed to save certificate: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("[UnitOfWork:Commit]: %w", err)
+	}
+	return nil
+}
+
+// NewUnitOfWorkRepository creates units of work on a bbolt database
+func NewUnitOfWorkRepository(
+	certManager managers.CertificateManager,
+	database *Database,
+) *BoltUnitOfWorkRepository {
+	return &BoltUnitOfWorkRepository{
+		certificates: NewCertificateRepository(certManager, database),
+		certManager:  certManager,
+		database:     database,
+	}
+}
+
+var _ appmodels.UnitOfWorkRepository = (*BoltUnitOfWorkRepository)(nil)
+var _ appmodels.UnitOfWork = (*BoltUnitOfWork)(nil)
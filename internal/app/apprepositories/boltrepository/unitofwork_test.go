@@ -0,0 +1,55 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/boltrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestBoltUnitOfWork_Commit(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	collection := boltrepository.NewCollection(certManager, newTestDatabase(t))
+
+	organizationModel := appmodels.NewOrganization(big.NewInt(10), "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
+	serialNumber := big.NewInt(42)
+	key, err := apputils.GeneratePrivateKey(organizationModel.ID(), serialNumber, appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	root, err := apputils.NewRootCertificate(certManager, serialNumber, organizationModel, time.Hour, appmodels.ECDSA_WITH_SHA256, key, "Test Root")
+	require.NoError(t, err)
+
+	work := collection.UnitOfWork.NewUnitOfWork()
+	work.SaveCertificate(root)
+	work.SavePrivateKey(key)
+	require.NoError(t, work.Commit())
+
+	saved, err := collection.Certificate.FindByOrganizationAndSerialNumber(organizationModel.ID(), serialNumber)
+	require.NoError(t, err)
+	assert.Equal(t, root.Certificate().Raw, saved.Certificate().Raw)
+	foundKey, err := collection.PrivateKey.FindByOrganizationAndSerialNumber(organizationModel.ID(), serialNumber)
+	require.NoError(t, err)
+	assert.Equal(t, key.PrivateKey(), foundKey.PrivateKey())
+
+	assert.ErrorContains(t, work.Commit(), "already committed")
+
+	// An invalid issuer fails the certificate after the private key was
+	// written, and neither is stored
+	otherKey, err := apputils.GeneratePrivateKey(organizationModel.ID(), big.NewInt(43), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	work = collection.UnitOfWork.NewUnitOfWork()
+	work.SavePrivateKey(otherKey)
+	work.SaveCertificate(appmodels.NewCertificate(organizationModel.ID(), big.NewInt(-1), root.Certificate()))
+	assert.ErrorContains(t, work.Commit(), "failed to save certificate")
+	_, err = collection.PrivateKey.FindByOrganizationAndSerialNumber(organizationModel.ID(), big.NewInt(43))
+	assert.ErrorContains(t, err, "not found")
+}
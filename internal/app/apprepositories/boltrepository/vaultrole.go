@@ -0,0 +1,141 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// BoltVaultRoleRepository implements appmodels.VaultRoleRepository on bbolt.
+// Roles are stored as JSON in the order of their names.
+type BoltVaultRoleRepository struct {
+	database *Database
+}
+
+func (r *BoltVaultRoleRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.VaultRole, error) {
+	var values [][]byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil || bucket == nil {
+			return err
+		}
+		// Organizations created before Vault roles were stored have no
+		// bucket until the first role is saved
+		roles := bucket.Bucket(VaultRolesBucketName)
+		if roles == nil {
+			return nil
+		}
+		return roles.ForEach(func(_, value []byte) error {
+			values = append(values, append([]byte(nil), value...))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[VaultRole:FindAllByOrganization]: %w", err)
+	}
+	list := make([]appmodels.VaultRole, 0, len(values))
+	for _, data := range values {
+		model, err := parseVaultRole(data)
+		if err != nil {
+			return nil, fmt.Errorf("[VaultRole:FindAllByOrganization]: %w", err)
+		}
+		list = append(list, model)
+	}
+	return list, nil
+}
+
+func (r *BoltVaultRoleRepository) FindByOrganizationAndName(organization *big.Int, name string) (appmodels.VaultRole, error) {
+	var data []byte
+	err := r.database.db.View(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil {
+			return err
+		}
+		if bucket != nil {
+			if roles := bucket.Bucket(VaultRolesBucketName); roles != nil {
+				// The value is only valid during the transaction
+				data = append([]byte(nil), roles.Get([]byte(name))...)
+			}
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("not found: %s@%s", name, organization)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[VaultRole:FindByOrganizationAndName]: %w", err)
+	}
+	model, err := parseVaultRole(data)
+	if err != nil {
+		return nil, fmt.Errorf("[VaultRole:FindByOrganizationAndName]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltVaultRoleRepository) Save(model appmodels.VaultRole) (appmodels.VaultRole, error) {
+	data, err := json.Marshal(apputils.ToVaultRoleRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[VaultRole:Save]: failed to marshal JSON: %w", err)
+	}
+	err = r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := createOrganizationBucket(tx, model.OrganizationID())
+		if err != nil {
+			return err
+		}
+		return bucket.Bucket(VaultRolesBucketName).Put([]byte(model.Name()), data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[VaultRole:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+func (r *BoltVaultRoleRepository) Delete(organization *big.Int, name string) error {
+	err := r.database.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := organizationBucket(tx, organization)
+		if err != nil {
+			return err
+		}
+		var roles *bolt.Bucket
+		if bucket != nil {
+			roles = bucket.Bucket(VaultRolesBucketName)
+		}
+		if roles == nil || roles.Get([]byte(name)) == nil {
+			return fmt.Errorf("not found: %s@%s", name, organization)
+		}
+		return roles.Delete([]byte(name))
+	})
+	if err != nil {
+		return fmt.Errorf("[VaultRole:Delete]: %w", err)
+	}
+	return nil
+}
+
+// parseVaultRole parses a stored role
+func parseVaultRole(data []byte) (appmodels.VaultRole, error) {
+	dto := appdtos.VaultRoleRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return apputils.FromVaultRoleRecordDTO(dto)
+}
+
+// NewVaultRoleRepository creates a bbolt based repository for Vault PKI
+// roles
+func NewVaultRoleRepository(
+	database *Database,
+) *BoltVaultRoleRepository {
+	return &BoltVaultRoleRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.VaultRoleRepository = (*BoltVaultRoleRepository)(nil)
@@ -0,0 +1,45 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package boltrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/boltrepository"
+)
+
+func TestVaultRoleRepository_SaveFindAndDelete(t *testing.T) {
+	database := newTestDatabase(t)
+	repo := boltrepository.NewVaultRoleRepository(database)
+	organization := big.NewInt(1)
+
+	list, err := repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	web := appmodels.NewVaultRole("web", organization, big.NewInt(2), []string{"example.com"}, true, false, false, true, appmodels.ECDSA_P256, time.Hour, 24*time.Hour)
+	api := appmodels.NewVaultRole("api", organization, big.NewInt(2), nil, false, false, true, false, appmodels.NIL_KEY_TYPE, 0, 0)
+	for _, role := range []appmodels.VaultRole{web, api} {
+		_, err = repo.Save(role)
+		require.NoError(t, err)
+	}
+
+	found, err := boltrepository.NewVaultRoleRepository(database).FindByOrganizationAndName(organization, "web")
+	require.NoError(t, err)
+	assert.Equal(t, web, found)
+
+	list, err = repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Equal(t, []appmodels.VaultRole{api, web}, list)
+
+	require.NoError(t, repo.Delete(organization, "api"))
+	assert.Error(t, repo.Delete(organization, "api"))
+	_, err = repo.FindByOrganizationAndName(organization, "api")
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/fsutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// FileAcmeAccountRepository implements appmodels.AcmeAccountRepository for a
+// file system. Each account is a JSON file named by its ID.
+type FileAcmeAccountRepository struct {
+	filePath    string
+	fileManager managers.FileManager
+}
+
+func (r *FileAcmeAccountRepository) FindById(id string) (appmodels.AcmeAccount, error) {
+	if !isTokenID(id) {
+		return nil, fmt.Errorf("[AcmeAccount:FindById]: invalid ID: %q", id)
+	}
+	data, err := r.fileManager.ReadFile(AcmeAccountJsonPath(r.filePath, id))
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindById]: not found: %s: %w", id, err)
+	}
+	dto := appdtos.AcmeAccountRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindById]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromAcmeAccountRecordDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *FileAcmeAccountRepository) FindByThumbprint(organization *big.Int, issuer *big.Int, thumbprint string) (appmodels.AcmeAccount, error) {
+	list, err := r.findAll()
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindByThumbprint]: %w", err)
+	}
+	for _, account := range list {
+		if account.Thumbprint() == thumbprint &&
+			account.OrganizationID().Cmp(organization) == 0 &&
+			account.IssuerSerialNumber().Cmp(issuer) == 0 {
+			return account, nil
+		}
+	}
+	return nil, fmt.Errorf("[AcmeAccount:FindByThumbprint]: not found: %s", thumbprint)
+}
+
+func (r *FileAcmeAccountRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.AcmeAccount, error) {
+	all, err := r.findAll()
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindAllByOrganization]: %w", err)
+	}
+	list := make([]appmodels.AcmeAccount, 0, len(all))
+	for _, account := range all {
+		if account.OrganizationID().Cmp(organization) == 0 {
+			list = append(list, account)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt().Equal(list[j].CreatedAt()) {
+			return list[i].ID() < list[j].ID()
+		}
+		return list[i].CreatedAt().Before(list[j].CreatedAt())
+	})
+	return list, nil
+}
+
+func (r *FileAcmeAccountRepository) Save(model appmodels.AcmeAccount) (appmodels.AcmeAccount, error) {
+	if !isTokenID(model.ID()) {
+		return nil, fmt.Errorf("[AcmeAccount:Save]: invalid ID: %q", model.ID())
+	}
+	dto, err := apputils.ToAcmeAccountRecordDTO(model)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:Save]: %w", err)
+	}
+	data, err := json.MarshalIndent(dto, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:Save]: failed to marshal JSON: %w", err)
+	}
+	if err := fsutils.SaveBytes(r.fileManager, AcmeAccountJsonPath(r.filePath, model.ID()), data, 0600, 0700); err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// findAll reads every account. The file system has no index by the
+// thumbprint or the organization.
+func (r *FileAcmeAccountRepository) findAll() ([]appmodels.AcmeAccount, error) {
+	entries, err := r.fileManager.ReadDir(AcmeAccountsDirectory(r.filePath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []appmodels.AcmeAccount{}, nil
+		}
+		return nil, err
+	}
+	list := make([]appmodels.AcmeAccount, 0, len(entries))
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || !isTokenID(id) {
+			continue
+		}
+		model, err := r.FindById(id)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, model)
+	}
+	return list, nil
+}
+
+// NewAcmeAccountRepository creates a file based repository for ACME accounts
+func NewAcmeAccountRepository(
+	fileManager managers.FileManager,
+	filePath string,
+) *FileAcmeAccountRepository {
+	return &FileAcmeAccountRepository{
+		filePath:    filePath,
+		fileManager: fileManager,
+	}
+}
+
+var _ appmodels.AcmeAccountRepository = (*FileAcmeAccountRepository)(nil)
@@ -0,0 +1,69 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/fsutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// FileAcmeAuthorizationRepository implements
+// appmodels.AcmeAuthorizationRepository for a file system. Each
+// authorization is a JSON file named by its ID and includes its challenges.
+type FileAcmeAuthorizationRepository struct {
+	filePath    string
+	fileManager managers.FileManager
+}
+
+func (r *FileAcmeAuthorizationRepository) FindById(id string) (appmodels.AcmeAuthorization, error) {
+	if !isTokenID(id) {
+		return nil, fmt.Errorf("[AcmeAuthorization:FindById]: invalid ID: %q", id)
+	}
+	data, err := r.fileManager.ReadFile(AcmeAuthorizationJsonPath(r.filePath, id))
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:FindById]: not found: %s: %w", id, err)
+	}
+	dto := appdtos.AcmeAuthorizationRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:FindById]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromAcmeAuthorizationRecordDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *FileAcmeAuthorizationRepository) Save(model appmodels.AcmeAuthorization) (appmodels.AcmeAuthorization, error) {
+	if !isTokenID(model.ID()) {
+		return nil, fmt.Errorf("[AcmeAuthorization:Save]: invalid ID: %q", model.ID())
+	}
+	data, err := json.MarshalIndent(apputils.ToAcmeAuthorizationRecordDTO(model), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:Save]: failed to marshal JSON: %w", err)
+	}
+	if err := fsutils.SaveBytes(r.fileManager, AcmeAuthorizationJsonPath(r.filePath, model.ID()), data, 0600, 0700); err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewAcmeAuthorizationRepository creates a file based repository for ACME
+// authorizations
+func NewAcmeAuthorizationRepository(
+	fileManager managers.FileManager,
+	filePath string,
+) *FileAcmeAuthorizationRepository {
+	return &FileAcmeAuthorizationRepository{
+		filePath:    filePath,
+		fileManager: fileManager,
+	}
+}
+
+var _ appmodels.AcmeAuthorizationRepository = (*FileAcmeAuthorizationRepository)(nil)
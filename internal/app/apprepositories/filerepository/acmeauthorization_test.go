@@ -0,0 +1,36 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestAcmeAuthorizationRepository_SaveAndFind(t *testing.T) {
+	dir := t.TempDir()
+	repo := filerepository.NewAcmeAuthorizationRepository(managers.NewFileManager(), dir)
+	expires := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	authorization := appmodels.NewAcmeAuthorization("authz", "account", "order", appmodels.ACME_STATUS_PENDING, expires, "example.com", false, []appmodels.AcmeChallenge{
+		appmodels.NewAcmeChallenge("1", appmodels.ACME_HTTP_01, "token", appmodels.ACME_STATUS_PENDING, time.Time{}, nil),
+	})
+
+	_, err := repo.Save(authorization)
+	require.NoError(t, err)
+
+	found, err := filerepository.NewAcmeAuthorizationRepository(managers.NewFileManager(), dir).FindById("authz")
+	require.NoError(t, err)
+	assert.Equal(t, authorization, found)
+
+	_, err = repo.FindById("missing")
+	assert.Error(t, err)
+	_, err = repo.FindById("../x")
+	assert.Error(t, err)
+}
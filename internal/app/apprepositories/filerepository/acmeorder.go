@@ -0,0 +1,97 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/fsutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// FileAcmeOrderRepository implements appmodels.AcmeOrderRepository for a
+// file system. Each order is a JSON file named by its ID.
+type FileAcmeOrderRepository struct {
+	filePath    string
+	fileManager managers.FileManager
+}
+
+func (r *FileAcmeOrderRepository) FindById(id string) (appmodels.AcmeOrder, error) {
+	if !isTokenID(id) {
+		return nil, fmt.Errorf("[AcmeOrder:FindById]: invalid ID: %q", id)
+	}
+	data, err := r.fileManager.ReadFile(AcmeOrderJsonPath(r.filePath, id))
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:FindById]: not found: %s: %w", id, err)
+	}
+	dto := appdtos.AcmeOrderRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:FindById]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromAcmeOrderRecordDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+// FindAllByAccount reads every order since the file system has no index by
+// the account
+func (r *FileAcmeOrderRepository) FindAllByAccount(account string) ([]appmodels.AcmeOrder, error) {
+	entries, err := r.fileManager.ReadDir(AcmeOrdersDirectory(r.filePath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []appmodels.AcmeOrder{}, nil
+		}
+		return nil, fmt.Errorf("[AcmeOrder:FindAllByAccount]: %w", err)
+	}
+	list := make([]appmodels.AcmeOrder, 0)
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || !isTokenID(id) {
+			continue
+		}
+		model, err := r.FindById(id)
+		if err != nil {
+			return nil, fmt.Errorf("[AcmeOrder:FindAllByAccount]: %w", err)
+		}
+		if model.AccountID() == account {
+			list = append(list, model)
+		}
+	}
+	return list, nil
+}
+
+func (r *FileAcmeOrderRepository) Save(model appmodels.AcmeOrder) (appmodels.AcmeOrder, error) {
+	if !isTokenID(model.ID()) {
+		return nil, fmt.Errorf("[AcmeOrder:Save]: invalid ID: %q", model.ID())
+	}
+	data, err := json.MarshalIndent(apputils.ToAcmeOrderRecordDTO(model), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:Save]: failed to marshal JSON: %w", err)
+	}
+	if err := fsutils.SaveBytes(r.fileManager, AcmeOrderJsonPath(r.filePath, model.ID()), data, 0600, 0700); err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewAcmeOrderRepository creates a file based repository for ACME orders
+func NewAcmeOrderRepository(
+	fileManager managers.FileManager,
+	filePath string,
+) *FileAcmeOrderRepository {
+	return &FileAcmeOrderRepository{
+		filePath:    filePath,
+		fileManager: fileManager,
+	}
+}
+
+var _ appmodels.AcmeOrderRepository = (*FileAcmeOrderRepository)(nil)
@@ -0,0 +1,50 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestAcmeOrderRepository_SaveAndFind(t *testing.T) {
+	dir := t.TempDir()
+	repo := filerepository.NewAcmeOrderRepository(managers.NewFileManager(), dir)
+	expires := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	list, err := repo.FindAllByAccount("account")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	order := appmodels.NewAcmeOrder("order", "account", appmodels.ACME_STATUS_PENDING, expires, []string{"www.example.com"}, []string{"authz"}, nil, nil)
+	_, err = repo.Save(order)
+	require.NoError(t, err)
+	_, err = repo.Save(appmodels.NewAcmeOrder("other", "account2", appmodels.ACME_STATUS_PENDING, expires, []string{"example.com"}, []string{"authz2"}, nil, nil))
+	require.NoError(t, err)
+
+	// Saving again replaces the order
+	valid := appmodels.NewAcmeOrder("order", "account", appmodels.ACME_STATUS_VALID, expires, []string{"www.example.com"}, []string{"authz"}, big.NewInt(3), nil)
+	_, err = repo.Save(valid)
+	require.NoError(t, err)
+
+	found, err := filerepository.NewAcmeOrderRepository(managers.NewFileManager(), dir).FindById("order")
+	require.NoError(t, err)
+	assert.Equal(t, valid, found)
+
+	list, err = repo.FindAllByAccount("account")
+	require.NoError(t, err)
+	assert.Equal(t, []appmodels.AcmeOrder{valid}, list)
+
+	_, err = repo.FindById("missing")
+	assert.Error(t, err)
+	_, err = repo.FindById("../x")
+	assert.Error(t, err)
+}
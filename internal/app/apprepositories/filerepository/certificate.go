@@ -3,29 +3,85 @@
 package filerepository
 
 import (
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io/fs"
+	"log"
 	"math/big"
+	"sync"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
 	"github.com/hyperifyio/gocertcenter/internal/common/managers"
 )
 
-// FileCertificateRepository implements models.CertificateRepository for a file system
+// FileCertificateRepository implements models.CertificateRepository for a file system.
+// The issuer of each certificate is kept in an index file, which is rebuilt
+// from the certificate tree when missing.
 type FileCertificateRepository struct {
 	filePath    string
 	certManager managers.CertificateManager
 	fileManager managers.FileManager
+	mutex       sync.Mutex
+	index       *CertificateIndex
 }
 
 func (r *FileCertificateRepository) FindAllByOrganizationAndSignedBy(organization *big.Int, certificate *big.Int) ([]appmodels.Certificate, error) {
-	// TODO implement me
-	panic("implement me")
+	entries, err := r.findIndexEntries(organization)
+	if err != nil {
+		return nil, err
+	}
+	var filtered []certificateIndexEntry
+	for _, entry := range entries {
+		if isSameSerialNumber(entry.signedBy, certificate) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return r.readCertificates(organization, filtered)
 }
 
 func (r *FileCertificateRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.Certificate, error) {
-	// TODO implement me
-	panic("implement me")
+	entries, err := r.findIndexEntries(organization)
+	if err != nil {
+		return nil, err
+	}
+	return r.readCertificates(organization, entries)
+}
+
+// FindPageByOrganization returns a page of certificates of an organization.
+// The serial number and issuer filters are applied on the index; the rest of
+// the query needs the certificate files.
+func (r *FileCertificateRepository) FindPageByOrganization(organization *big.Int, query appmodels.CertificateQuery) (appmodels.CertificatePage, error) {
+	if query.Archived {
+		list, err := r.readArchivedCertificates(organization)
+		if err != nil {
+			return appmodels.CertificatePage{}, err
+		}
+		return apputils.QueryCertificates(list, query), nil
+	}
+	entries, err := r.findIndexEntries(organization)
+	if err != nil {
+		return appmodels.CertificatePage{}, err
+	}
+	if query.SignedBy != nil || query.SerialNumber != nil {
+		var filtered []certificateIndexEntry
+		for _, entry := range entries {
+			if query.SignedBy != nil && !isSameSerialNumber(entry.signedBy, query.SignedBy) {
+				continue
+			}
+			if query.SerialNumber != nil && entry.serialNumber.Cmp(query.SerialNumber) != 0 {
+				continue
+			}
+			filtered = append(filtered, entry)
+		}
+		entries = filtered
+	}
+	list, err := r.readCertificates(organization, entries)
+	if err != nil {
+		return appmodels.CertificatePage{}, err
+	}
+	return apputils.QueryCertificates(list, query), nil
 }
 
 func (r *FileCertificateRepository) FindByOrganizationAndSerialNumber(
@@ -43,7 +99,12 @@ func (r *FileCertificateRepository) FindByOrganizationAndSerialNumber(
 		return nil, fmt.Errorf("failed to read certificate: %w", err)
 	}
 
-	return appmodels.NewCertificate(organization, certificate, cert), nil
+	signedBy, err := r.findSignedBy(organization, certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	return appmodels.NewCertificate(organization, signedBy, cert), nil
 }
 
 func (r *FileCertificateRepository) Save(certificate appmodels.Certificate) (appmodels.Certificate, error) {
@@ -58,9 +119,196 @@ func (r *FileCertificateRepository) Save(certificate appmodels.Certificate) (app
 	if err != nil {
 		return nil, fmt.Errorf("failed to save certificate: %w", err)
 	}
+
+	r.mutex.Lock()
+	index, err := r.loadIndex()
+	if err == nil {
+		index.Set(organization, serialNumber, certificate.SignedBy())
+		err = SaveCertificateIndexFile(r.fileManager, CertificateIndexJsonPath(r.filePath), index)
+	}
+	r.mutex.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save certificate index: %w", err)
+	}
+
 	return r.FindByOrganizationAndSerialNumber(organization, serialNumber)
 }
 
+// Archive moves the certificate directory, including a stored private key,
+// from the certificate tree to `{dir}/organizations/{organization}/archive`
+func (r *FileCertificateRepository) Archive(organization *big.Int, certificate *big.Int) error {
+	if certificate == nil {
+		return errors.New("no certificate serial number provided")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	index, err := r.loadIndex()
+	if err != nil {
+		return err
+	}
+	if _, exists := index.Lookup(organization, certificate); !exists {
+		return fmt.Errorf("failed to archive certificate: not found: %s/%s", organization, certificate)
+	}
+
+	if err := r.fileManager.MkdirAll(ArchiveDirectory(r.filePath, organization), 0700); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	err = r.fileManager.Rename(
+		CertificateDirectory(r.filePath, organization, certificate),
+		ArchivedCertificateDirectory(r.filePath, organization, certificate),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to archive certificate: %w", err)
+	}
+
+	index.Remove(organization, certificate)
+	if err := SaveCertificateIndexFile(r.fileManager, CertificateIndexJsonPath(r.filePath), index); err != nil {
+		return fmt.Errorf("failed to save certificate index: %w", err)
+	}
+	return nil
+}
+
+// readArchivedCertificates reads the archived certificates of an
+// organization. The archive is not indexed, so the issuers are resolved by
+// verifying the signatures against the archived and the current CA
+// certificates.
+func (r *FileCertificateRepository) readArchivedCertificates(organization *big.Int) ([]appmodels.Certificate, error) {
+	serialNumbers, err := ReadDirectoryNumbers(r.fileManager, ArchiveDirectory(r.filePath, organization))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived certificates: %w", err)
+	}
+	archived := make([]*x509.Certificate, 0, len(serialNumbers))
+	for _, serialNumber := range serialNumbers {
+		cert, err := ReadCertificateFile(r.fileManager, r.certManager, ArchivedCertificatePemPath(r.filePath, organization, serialNumber))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archived certificate '%s': %w", serialNumber, err)
+		}
+		archived = append(archived, cert)
+	}
+	if len(archived) == 0 {
+		return []appmodels.Certificate{}, nil
+	}
+
+	current, err := r.FindAllByOrganization(organization)
+	if err != nil {
+		return nil, err
+	}
+	candidates := append([]*x509.Certificate(nil), archived...)
+	for _, certificate := range current {
+		candidates = append(candidates, certificate.Certificate())
+	}
+
+	list := make([]appmodels.Certificate, 0, len(archived))
+	for _, cert := range archived {
+		signedBy, found := findCertificateIssuer(cert, candidates)
+		if !found {
+			log.Printf("[Certificate:%s] Issuer not found for archived certificate %s, skipped", organization, cert.SerialNumber)
+			continue
+		}
+		list = append(list, appmodels.NewCertificate(organization, signedBy, cert))
+	}
+	return list, nil
+}
+
+// findSignedBy looks up the issuer of a certificate from the index. A
+// certificate missing from the index was added to the tree outside this
+// repository, so the organization is re-indexed in memory. Reads never write
+// the index file; the change is saved with the next Save or Archive.
+func (r *FileCertificateRepository) findSignedBy(organization, certificate *big.Int) (*big.Int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	index, err := r.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	if signedBy, exists := index.Lookup(organization, certificate); exists {
+		return signedBy, nil
+	}
+
+	err = IndexOrganizationCertificates(r.fileManager, r.certManager, r.filePath, organization, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to index certificates: %w", err)
+	}
+	signedBy, exists := index.Lookup(organization, certificate)
+	if !exists {
+		return nil, fmt.Errorf("issuer not found: %s/%s", organization, certificate)
+	}
+	return signedBy, nil
+}
+
+// loadIndex returns the cached certificate index, reading it from the disk or
+// rebuilding it in memory from the certificate tree on first use. A missing
+// index file is written by SaveCertificateIndex, e.g. when the data
+// directory is opened. The caller must hold the mutex.
+func (r *FileCertificateRepository) loadIndex() (*CertificateIndex, error) {
+	if r.index != nil {
+		return r.index, nil
+	}
+	index, err := ReadCertificateIndexFile(r.fileManager, CertificateIndexJsonPath(r.filePath))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to load certificate index: %w", err)
+		}
+		index, err = RebuildCertificateIndex(r.fileManager, r.certManager, r.filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rebuild certificate index: %w", err)
+		}
+	}
+	r.index = index
+	return index, nil
+}
+
+// certificateIndexEntry is a snapshot of one certificate in the index
+type certificateIndexEntry struct {
+	serialNumber *big.Int
+	signedBy     *big.Int
+}
+
+// findIndexEntries returns the indexed certificates of an organization in
+// ascending serial number order
+func (r *FileCertificateRepository) findIndexEntries(organization *big.Int) ([]certificateIndexEntry, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	index, err := r.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	serialNumbers := index.SerialNumbers(organization)
+	entries := make([]certificateIndexEntry, 0, len(serialNumbers))
+	for _, serialNumber := range serialNumbers {
+		signedBy, _ := index.Lookup(organization, serialNumber)
+		entries = append(entries, certificateIndexEntry{serialNumber, signedBy})
+	}
+	return entries, nil
+}
+
+// readCertificates reads the certificate files of index entries
+func (r *FileCertificateRepository) readCertificates(
+	organization *big.Int,
+	entries []certificateIndexEntry,
+) ([]appmodels.Certificate, error) {
+	list := make([]appmodels.Certificate, 0, len(entries))
+	for _, entry := range entries {
+		fileName := CertificatePemPath(r.filePath, organization, entry.serialNumber)
+		cert, err := ReadCertificateFile(r.fileManager, r.certManager, fileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate '%s': %w", entry.serialNumber, err)
+		}
+		list = append(list, appmodels.NewCertificate(organization, entry.signedBy, cert))
+	}
+	return list, nil
+}
+
+func isSameSerialNumber(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Cmp(b) == 0
+}
+
 // NewCertificateRepository creates a file based repository
 func NewCertificateRepository(
 	certManager managers.CertificateManager,
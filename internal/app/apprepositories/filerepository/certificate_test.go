@@ -15,7 +15,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
 	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
 	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
 	"github.com/hyperifyio/gocertcenter/internal/common/commonmocks"
@@ -152,6 +154,7 @@ func TestCertificateRepository_CreateCertificate(t *testing.T) {
 	mockCertificate.On("Certificate").Return(cert, nil)
 	mockCertificate.On("OrganizationID").Return(big.NewInt(123))
 	mockCertificate.On("SerialNumber").Return(template.SerialNumber)
+	mockCertificate.On("SignedBy").Return((*big.Int)(nil))
 	mockCertificate.On("ID").Return("")
 
 	// Attempt to save the certificate.
@@ -191,6 +194,7 @@ func TestCertificateRepository_CreateCertificate_SaveFail(t *testing.T) {
 	mockCertificate.On("Certificate").Return(&x509.Certificate{}, nil)
 	mockCertificate.On("OrganizationID").Return(big.NewInt(123))
 	mockCertificate.On("SerialNumber").Return(appmodels.NewSerialNumber(1))
+	mockCertificate.On("SignedBy").Return((*big.Int)(nil))
 
 	// Attempt to save the certificate, expecting a failure
 	_, err = repo.Save(&mockCertificate)
@@ -199,3 +203,343 @@ func TestCertificateRepository_CreateCertificate_SaveFail(t *testing.T) {
 	assert.Error(t, err, "Expected an error due to failed certificate save")
 	assert.Contains(t, err.Error(), "failed to save certificate", "Error message should indicate a failure in saving the certificate")
 }
+
+// newTestChainCertificate creates a certificate signed by parent, or a
+// self-signed root certificate if parent is nil
+func newTestChainCertificate(
+	t *testing.T,
+	serialNumber int64,
+	isCA bool,
+	parent *x509.Certificate,
+	parentKey *rsa.PrivateKey,
+) (*x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serialNumber),
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("Test Certificate %d", serialNumber)},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if parent == nil {
+		parent, parentKey = template, privateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &privateKey.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert, privateKey
+}
+
+// saveTestChain saves a root (1), an intermediate (2) signed by the root and
+// two leaf certificates (3, 4) signed by the intermediate
+func saveTestChain(t *testing.T, repo *filerepository.FileCertificateRepository, organization *big.Int) {
+	t.Helper()
+	root, rootKey := newTestChainCertificate(t, 1, true, nil, nil)
+	intermediate, intermediateKey := newTestChainCertificate(t, 2, true, root, rootKey)
+	leaf1, _ := newTestChainCertificate(t, 3, false, intermediate, intermediateKey)
+	leaf2, _ := newTestChainCertificate(t, 4, false, intermediate, intermediateKey)
+	for _, model := range []appmodels.Certificate{
+		appmodels.NewCertificate(organization, nil, root),
+		appmodels.NewCertificate(organization, big.NewInt(1), intermediate),
+		appmodels.NewCertificate(organization, big.NewInt(2), leaf2),
+		appmodels.NewCertificate(organization, big.NewInt(2), leaf1),
+	} {
+		_, err := repo.Save(model)
+		assert.NoError(t, err)
+	}
+}
+
+func serialNumbersOf(list []appmodels.Certificate) []string {
+	result := make([]string, 0, len(list))
+	for _, cert := range list {
+		result = append(result, cert.SerialNumber().String())
+	}
+	return result
+}
+
+func TestCertificateRepository_SignedByAndListing(t *testing.T) {
+
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	fileManager := managers.NewFileManager()
+
+	tempDir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	organization := big.NewInt(123)
+	saveTestChain(t, filerepository.NewCertificateRepository(certManager, fileManager, tempDir), organization)
+
+	// A new repository instance reads the persisted index
+	repo := filerepository.NewCertificateRepository(certManager, fileManager, tempDir)
+
+	root, err := repo.FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Nil(t, root.SignedBy())
+
+	leaf, err := repo.FindByOrganizationAndSerialNumber(organization, big.NewInt(3))
+	assert.NoError(t, err)
+	assert.Equal(t, "2", leaf.SignedBy().String())
+
+	list, err := repo.FindAllByOrganization(organization)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "4"}, serialNumbersOf(list))
+
+	list, err = repo.FindAllByOrganizationAndSignedBy(organization, big.NewInt(2))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3", "4"}, serialNumbersOf(list))
+
+	list, err = repo.FindAllByOrganizationAndSignedBy(organization, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, serialNumbersOf(list))
+
+	list, err = repo.FindAllByOrganization(big.NewInt(999))
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestCertificateRepository_RebuildIndex(t *testing.T) {
+
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	fileManager := managers.NewFileManager()
+
+	tempDir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	organization := big.NewInt(123)
+	saveTestChain(t, filerepository.NewCertificateRepository(certManager, fileManager, tempDir), organization)
+	assert.NoError(t, os.Remove(filerepository.CertificateIndexJsonPath(tempDir)))
+
+	repo := filerepository.NewCertificateRepository(certManager, fileManager, tempDir)
+
+	list, err := repo.FindAllByOrganizationAndSignedBy(organization, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2"}, serialNumbersOf(list))
+
+	list, err = repo.FindAllByOrganizationAndSignedBy(organization, big.NewInt(2))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3", "4"}, serialNumbersOf(list))
+
+	// Reads do not write the index
+	_, err = os.Stat(filerepository.CertificateIndexJsonPath(tempDir))
+	assert.True(t, os.IsNotExist(err), "Expected the rebuilt index to be kept in memory")
+
+	require.NoError(t, filerepository.SaveCertificateIndex(fileManager, certManager, tempDir))
+	index, err := filerepository.ReadCertificateIndexFile(fileManager, filerepository.CertificateIndexJsonPath(tempDir))
+	require.NoError(t, err)
+	signedBy, exists := index.Lookup(organization, big.NewInt(3))
+	assert.True(t, exists)
+	assert.Equal(t, "2", signedBy.String())
+}
+
+func TestCertificateRepository_RebuildIndex_IssuerNotFound(t *testing.T) {
+
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	fileManager := managers.NewFileManager()
+
+	tempDir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	organization := big.NewInt(123)
+	saveTestChain(t, filerepository.NewCertificateRepository(certManager, fileManager, tempDir), organization)
+	assert.NoError(t, os.RemoveAll(filerepository.CertificateDirectory(tempDir, organization, big.NewInt(2))))
+	assert.NoError(t, os.Remove(filerepository.CertificateIndexJsonPath(tempDir)))
+
+	repo := filerepository.NewCertificateRepository(certManager, fileManager, tempDir)
+
+	// Certificates of the missing issuer are not indexed as roots
+	list, err := repo.FindAllByOrganization(organization)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, serialNumbersOf(list))
+
+	_, err = repo.FindByOrganizationAndSerialNumber(organization, big.NewInt(3))
+	assert.ErrorContains(t, err, "issuer not found")
+}
+
+func TestCertificateRepository_FindAll_CorruptIndex(t *testing.T) {
+
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	fileManager := managers.NewFileManager()
+
+	tempDir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	err := fsutils.SaveBytes(fileManager, filerepository.CertificateIndexJsonPath(tempDir), []byte("{"), 0600, 0700)
+	assert.NoError(t, err)
+
+	repo := filerepository.NewCertificateRepository(certManager, fileManager, tempDir)
+	_, err = repo.FindAllByOrganization(big.NewInt(123))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load certificate index")
+}
+
+func TestCertificateRepository_FindPageByOrganization(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	collection := filerepository.NewCollection(certManager, managers.NewFileManager(), t.TempDir())
+	appController := appcontrollers.NewApplicationController(
+		collection.Organization,
+		collection.Certificate,
+		collection.PrivateKey,
+		collection.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization := big.NewInt(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	organizationController.SetExpirationDuration(24 * time.Hour)
+
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	intermediate, _, err := rootController.NewIntermediateCertificate("Test Intermediate")
+	require.NoError(t, err)
+	serverB, _, err := rootController.NewServerCertificate("b.example.com")
+	require.NoError(t, err)
+	serverA, _, err := rootController.NewServerCertificate("a.example.com")
+	require.NoError(t, err)
+	client, _, err := rootController.NewClientCertificate("alice")
+	require.NoError(t, err)
+
+	repo := collection.Certificate
+	find := func(query appmodels.CertificateQuery) appmodels.CertificatePage {
+		t.Helper()
+		page, err := repo.FindPageByOrganization(organization, query)
+		require.NoError(t, err)
+		return page
+	}
+
+	// Servers by common name, one per page
+	query := appmodels.CertificateQuery{
+		SignedBy: root.SerialNumber(),
+		Type:     appmodels.SERVER_CERTIFICATE,
+		Sort:     appmodels.SORT_BY_COMMON_NAME,
+		Limit:    1,
+	}
+	page := find(query)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{serverA}), serialNumbersOf(page.Certificates))
+	require.NotNil(t, page.Next)
+	query.After = page.Next
+	page = find(query)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{serverB}), serialNumbersOf(page.Certificates))
+	assert.Nil(t, page.Next)
+
+	page = find(appmodels.CertificateQuery{Type: appmodels.INTERMEDIATE_CERTIFICATE})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{intermediate}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{SerialNumber: serverB.SerialNumber(), CommonName: "B.EXAMPLE"})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{serverB}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{SerialNumber: serverB.SerialNumber(), SignedBy: intermediate.SerialNumber()})
+	assert.Empty(t, page.Certificates)
+
+	page = find(appmodels.CertificateQuery{Type: appmodels.ROOT_CERTIFICATE})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{root}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{Name: "ALI"})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{client}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{Name: "%"})
+	assert.Empty(t, page.Certificates)
+
+	page = find(appmodels.CertificateQuery{Status: appmodels.VALID_CERTIFICATE, Now: time.Now()})
+	assert.Len(t, page.Certificates, 5)
+
+	page = find(appmodels.CertificateQuery{Status: appmodels.EXPIRED_CERTIFICATE, Now: time.Now()})
+	assert.Empty(t, page.Certificates)
+
+	page = find(appmodels.CertificateQuery{ExpiringBefore: client.NotAfter().Add(time.Second)})
+	assert.Contains(t, serialNumbersOf(page.Certificates), serialNumbersOf([]appmodels.Certificate{client})[0])
+	for _, cert := range page.Certificates {
+		assert.True(t, cert.NotAfter().Before(client.NotAfter().Add(time.Second)))
+	}
+
+	// Walking all pages in descending order returns every certificate once
+	all, err := repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	var expected []appmodels.Certificate
+	for i := len(all) - 1; i >= 0; i-- {
+		expected = append(expected, all[i])
+	}
+	var walked []appmodels.Certificate
+	query = appmodels.CertificateQuery{Descending: true, Limit: 2}
+	for {
+		page = find(query)
+		walked = append(walked, page.Certificates...)
+		if page.Next == nil {
+			break
+		}
+		query.After = page.Next
+	}
+	assert.Equal(t, serialNumbersOf(expected), serialNumbersOf(walked))
+}
+
+func TestCertificateRepository_Archive(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	collection := filerepository.NewCollection(certManager, managers.NewFileManager(), t.TempDir())
+	appController := appcontrollers.NewApplicationController(
+		collection.Organization,
+		collection.Certificate,
+		collection.PrivateKey,
+		collection.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization := big.NewInt(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	archived, _, err := rootController.NewServerCertificate("old.example.com")
+	require.NoError(t, err)
+	kept, _, err := rootController.NewServerCertificate("new.example.com")
+	require.NoError(t, err)
+
+	repo := collection.Certificate
+	require.NoError(t, repo.Archive(organization, archived.SerialNumber()))
+	assert.Error(t, repo.Archive(organization, archived.SerialNumber()))
+	assert.Error(t, repo.Archive(organization, big.NewInt(12345)))
+
+	_, err = repo.FindByOrganizationAndSerialNumber(organization, archived.SerialNumber())
+	assert.Error(t, err)
+	list, err := repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, serialNumbersOf([]appmodels.Certificate{root, kept}), serialNumbersOf(list))
+	list, err = repo.FindAllByOrganizationAndSignedBy(organization, root.SerialNumber())
+	require.NoError(t, err)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{kept}), serialNumbersOf(list))
+
+	page, err := repo.FindPageByOrganization(organization, appmodels.CertificateQuery{Type: appmodels.SERVER_CERTIFICATE})
+	require.NoError(t, err)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{kept}), serialNumbersOf(page.Certificates))
+
+	page, err = repo.FindPageByOrganization(organization, appmodels.CertificateQuery{Archived: true, SignedBy: root.SerialNumber(), Name: "old"})
+	require.NoError(t, err)
+	require.Len(t, page.Certificates, 1)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{archived}), serialNumbersOf(page.Certificates))
+	assert.Equal(t, root.SerialNumber(), page.Certificates[0].SignedBy())
+
+	page, err = repo.FindPageByOrganization(big.NewInt(11), appmodels.CertificateQuery{Archived: true})
+	require.NoError(t, err)
+	assert.Empty(t, page.Certificates)
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/fsutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// FileCertificateRevocationRepository implements
+// appmodels.CertificateRevocationRepository for a file system. Each
+// revocation is a JSON file named by the serial number of the revoked
+// certificate, and the last CRL of each issuer a JSON file named by the
+// serial number of the issuer.
+type FileCertificateRevocationRepository struct {
+	filePath    string
+	fileManager managers.FileManager
+}
+
+func (r *FileCertificateRevocationRepository) FindAllByOrganizationAndIssuer(organization *big.Int, issuer *big.Int) ([]appmodels.CertificateRevocation, error) {
+	entries, err := r.fileManager.ReadDir(RevocationsDirectory(r.filePath, organization))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("[CertificateRevocation:FindAllByOrganizationAndIssuer]: %w", err)
+	}
+	var list []appmodels.CertificateRevocation
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		serialNumber := parseIndexSerialNumber(name)
+		if serialNumber == nil {
+			continue
+		}
+		model, err := r.FindByOrganizationAndSerialNumber(organization, serialNumber)
+		if err != nil {
+			return nil, fmt.Errorf("[CertificateRevocation:FindAllByOrganizationAndIssuer]: %w", err)
+		}
+		if model.IssuerSerialNumber().Cmp(issuer) == 0 {
+			list = append(list, model)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].RevokedCertificate().SerialNumber().Cmp(list[j].RevokedCertificate().SerialNumber()) < 0
+	})
+	return list, nil
+}
+
+func (r *FileCertificateRevocationRepository) FindByOrganizationAndSerialNumber(organization *big.Int, certificate *big.Int) (appmodels.CertificateRevocation, error) {
+	data, err := r.fileManager.ReadFile(RevocationJsonPath(r.filePath, organization, certificate))
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindByOrganizationAndSerialNumber]: not found: %s@%s: %w", certificate, organization, err)
+	}
+	dto := appdtos.CertificateRevocationDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindByOrganizationAndSerialNumber]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromCertificateRevocationDTO(organization, dto)
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindByOrganizationAndSerialNumber]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *FileCertificateRevocationRepository) Save(model appmodels.CertificateRevocation) (appmodels.CertificateRevocation, error) {
+	data, err := json.MarshalIndent(apputils.ToCertificateRevocationDTO(model), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:Save]: failed to marshal JSON: %w", err)
+	}
+	fileName := RevocationJsonPath(r.filePath, model.OrganizationID(), model.RevokedCertificate().SerialNumber())
+	if err := fsutils.SaveBytes(r.fileManager, fileName, data, 0600, 0700); err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+func (r *FileCertificateRevocationRepository) FindRevocationList(organization *big.Int, issuer *big.Int) (appmodels.RevocationList, error) {
+	data, err := r.fileManager.ReadFile(RevocationListJsonPath(r.filePath, organization, issuer))
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindRevocationList]: not found: %s@%s: %w", issuer, organization, err)
+	}
+	dto := appdtos.RevocationListDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindRevocationList]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromRevocationListDTO(organization, dto)
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindRevocationList]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *FileCertificateRevocationRepository) SaveRevocationList(model appmodels.RevocationList) (appmodels.RevocationList, error) {
+	data, err := json.MarshalIndent(apputils.ToRevocationListDTO(model), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:SaveRevocationList]: failed to marshal JSON: %w", err)
+	}
+	fileName := RevocationListJsonPath(r.filePath, model.OrganizationID(), model.IssuerSerialNumber())
+	if err := fsutils.SaveBytes(r.fileManager, fileName, data, 0600, 0700); err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:SaveRevocationList]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewCertificateRevocationRepository creates a file based repository for
+// certificate revocations
+func NewCertificateRevocationRepository(
+	fileManager managers.FileManager,
+	filePath string,
+) *FileCertificateRevocationRepository {
+	return &FileCertificateRevocationRepository{
+		filePath:    filePath,
+		fileManager: fileManager,
+	}
+}
+
+var _ appmodels.CertificateRevocationRepository = (*FileCertificateRevocationRepository)(nil)
@@ -12,9 +12,22 @@ func NewCollection(
 	fileManager managers.FileManager,
 	filePath string,
 ) *appmodels.Collection {
+	certificates := NewCertificateRepository(certManager, fileManager, filePath)
+	keys := NewPrivateKeyRepository(certManager, fileManager, filePath)
 	return appmodels.NewCollection(
 		NewOrganizationRepository(certManager, fileManager, filePath),
-		NewCertificateRepository(certManager, fileManager, filePath),
-		NewPrivateKeyRepository(certManager, fileManager, filePath),
+		certificates,
+		keys,
+		NewUnitOfWorkRepository(certificates, keys),
+		NewSshAuthorityRepository(certManager, fileManager, filePath),
+		NewSshCertificateRepository(fileManager, filePath),
+		NewCertificateRevocationRepository(fileManager, filePath),
+		NewPendingOperationRepository(fileManager, filePath),
+		NewAcmeAccountRepository(fileManager, filePath),
+		NewAcmeOrderRepository(fileManager, filePath),
+		NewAcmeAuthorizationRepository(fileManager, filePath),
+		NewEstLabelRepository(fileManager, filePath),
+		NewScepChallengeRepository(fileManager, filePath),
+		NewVaultRoleRepository(fileManager, filePath),
 	)
 }
@@ -27,6 +27,17 @@ func TestNewCollection(t *testing.T) {
 	assert.NotNil(t, collection.Organization, "Expected non-nil Organization service")
 	assert.NotNil(t, collection.Certificate, "Expected non-nil Certificate service")
 	assert.NotNil(t, collection.PrivateKey, "Expected non-nil PrivateKey service")
+	assert.NotNil(t, collection.UnitOfWork, "Expected non-nil UnitOfWork service")
+	assert.NotNil(t, collection.SshAuthority, "Expected non-nil SshAuthority service")
+	assert.NotNil(t, collection.SshCertificate, "Expected non-nil SshCertificate service")
+	assert.NotNil(t, collection.CertificateRevocation, "Expected non-nil CertificateRevocation service")
+	assert.NotNil(t, collection.PendingOperation, "Expected non-nil PendingOperation service")
+	assert.NotNil(t, collection.AcmeAccount, "Expected non-nil AcmeAccount service")
+	assert.NotNil(t, collection.AcmeOrder, "Expected non-nil AcmeOrder service")
+	assert.NotNil(t, collection.AcmeAuthorization, "Expected non-nil AcmeAuthorization service")
+	assert.NotNil(t, collection.EstLabel, "Expected non-nil EstLabel service")
+	assert.NotNil(t, collection.ScepChallenge, "Expected non-nil ScepChallenge service")
+	assert.NotNil(t, collection.VaultRole, "Expected non-nil VaultRole service")
 
 	// Additional checks can include verifying that the repositories are correctly initialized with the filePath
 	// This step requires access to the internal state of the repositories or using reflection if not directly accessible
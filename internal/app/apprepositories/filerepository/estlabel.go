@@ -0,0 +1,96 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/fsutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// FileEstLabelRepository implements appmodels.EstLabelRepository for a file
+// system. Each label is a JSON file named by the label.
+type FileEstLabelRepository struct {
+	filePath    string
+	fileManager managers.FileManager
+}
+
+func (r *FileEstLabelRepository) FindByLabel(label string) (appmodels.EstLabel, error) {
+	if err := apputils.ValidateEstLabel(label); err != nil {
+		return nil, fmt.Errorf("[EstLabel:FindByLabel]: invalid label: %q: %w", label, err)
+	}
+	data, err := r.fileManager.ReadFile(EstLabelJsonPath(r.filePath, label))
+	if err != nil {
+		return nil, fmt.Errorf("[EstLabel:FindByLabel]: not found: %s: %w", label, err)
+	}
+	dto := appdtos.EstLabelRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[EstLabel:FindByLabel]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromEstLabelRecordDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[EstLabel:FindByLabel]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *FileEstLabelRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.EstLabel, error) {
+	entries, err := r.fileManager.ReadDir(EstLabelsDirectory(r.filePath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []appmodels.EstLabel{}, nil
+		}
+		return nil, fmt.Errorf("[EstLabel:FindAllByOrganization]: %w", err)
+	}
+	list := make([]appmodels.EstLabel, 0)
+	for _, entry := range entries {
+		label, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || apputils.ValidateEstLabel(label) != nil {
+			continue
+		}
+		model, err := r.FindByLabel(label)
+		if err != nil {
+			return nil, fmt.Errorf("[EstLabel:FindAllByOrganization]: %w", err)
+		}
+		if model.OrganizationID().Cmp(organization) == 0 {
+			list = append(list, model)
+		}
+	}
+	return list, nil
+}
+
+func (r *FileEstLabelRepository) Save(model appmodels.EstLabel) (appmodels.EstLabel, error) {
+	if err := apputils.ValidateEstLabel(model.Label()); err != nil {
+		return nil, fmt.Errorf("[EstLabel:Save]: invalid label: %q: %w", model.Label(), err)
+	}
+	data, err := json.MarshalIndent(apputils.ToEstLabelRecordDTO(model), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("[EstLabel:Save]: failed to marshal JSON: %w", err)
+	}
+	if err := fsutils.SaveBytes(r.fileManager, EstLabelJsonPath(r.filePath, model.Label()), data, 0600, 0700); err != nil {
+		return nil, fmt.Errorf("[EstLabel:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewEstLabelRepository creates a file based repository for EST labels
+func NewEstLabelRepository(
+	fileManager managers.FileManager,
+	filePath string,
+) *FileEstLabelRepository {
+	return &FileEstLabelRepository{
+		filePath:    filePath,
+		fileManager: fileManager,
+	}
+}
+
+var _ appmodels.EstLabelRepository = (*FileEstLabelRepository)(nil)
@@ -0,0 +1,226 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/big"
+	"sort"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// CertificateIndex is the on-disk index of certificates. It maps an
+// organization ID to the serial numbers of its certificates, and each serial
+// number to the serial number of the certificate which signed it. Root
+// certificates have an empty issuer.
+type CertificateIndex struct {
+	Organizations map[string]map[string]string `json:"organizations"`
+}
+
+// NewCertificateIndex creates an empty certificate index
+func NewCertificateIndex() *CertificateIndex {
+	return &CertificateIndex{
+		Organizations: make(map[string]map[string]string),
+	}
+}
+
+// Set records the issuer of a certificate. A nil signedBy marks a root
+// certificate.
+func (i *CertificateIndex) Set(organization, certificate, signedBy *big.Int) {
+	if i.Organizations == nil {
+		i.Organizations = make(map[string]map[string]string)
+	}
+	orgKey := organization.String()
+	certificates, exists := i.Organizations[orgKey]
+	if !exists {
+		certificates = make(map[string]string)
+		i.Organizations[orgKey] = certificates
+	}
+	issuer := ""
+	if signedBy != nil {
+		issuer = signedBy.String()
+	}
+	certificates[certificate.String()] = issuer
+}
+
+// Remove removes a certificate from the index
+func (i *CertificateIndex) Remove(organization, certificate *big.Int) {
+	delete(i.Organizations[organization.String()], certificate.String())
+}
+
+// Lookup returns the issuer of a certificate. The issuer is nil for root
+// certificates. The second return value is false if the certificate has not
+// been indexed.
+func (i *CertificateIndex) Lookup(organization, certificate *big.Int) (*big.Int, bool) {
+	certificates, exists := i.Organizations[organization.String()]
+	if !exists {
+		return nil, false
+	}
+	issuer, exists := certificates[certificate.String()]
+	if !exists {
+		return nil, false
+	}
+	return parseIndexSerialNumber(issuer), true
+}
+
+// SerialNumbers returns the serial numbers of indexed certificates of an
+// organization in ascending order
+func (i *CertificateIndex) SerialNumbers(organization *big.Int) []*big.Int {
+	certificates := i.Organizations[organization.String()]
+	list := make([]*big.Int, 0, len(certificates))
+	for serial := range certificates {
+		if serialNumber := parseIndexSerialNumber(serial); serialNumber != nil {
+			list = append(list, serialNumber)
+		}
+	}
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].Cmp(list[b]) < 0
+	})
+	return list
+}
+
+// RebuildCertificateIndex creates a certificate index from the
+// `{dir}/organizations/*/certificates/*/cert.pem` tree. The issuer of each
+// certificate is resolved by verifying its signature against the other CA
+// certificates of the same organization.
+func RebuildCertificateIndex(
+	fileManager managers.FileManager,
+	certManager managers.CertificateManager,
+	dir string,
+) (*CertificateIndex, error) {
+	index := NewCertificateIndex()
+	organizations, err := ReadDirectoryNumbers(fileManager, OrganizationsDirectory(dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	for _, organization := range organizations {
+		if err := IndexOrganizationCertificates(fileManager, certManager, dir, organization, index); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+// SaveCertificateIndex rebuilds the certificate index from the certificate
+// tree and saves it, if the index file is missing. An existing index is not
+// touched.
+func SaveCertificateIndex(
+	fileManager managers.FileManager,
+	certManager managers.CertificateManager,
+	dir string,
+) error {
+	fileName := CertificateIndexJsonPath(dir)
+	if _, err := fileManager.ReadFile(fileName); err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	index, err := RebuildCertificateIndex(fileManager, certManager, dir)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild certificate index: %w", err)
+	}
+	if err := SaveCertificateIndexFile(fileManager, fileName, index); err != nil {
+		return fmt.Errorf("failed to save certificate index: %w", err)
+	}
+	return nil
+}
+
+// IndexOrganizationCertificates (re)indexes every certificate of an
+// organization found in the `{dir}/organizations/{organization}/certificates`
+// directory
+func IndexOrganizationCertificates(
+	fileManager managers.FileManager,
+	certManager managers.CertificateManager,
+	dir string,
+	organization *big.Int,
+	index *CertificateIndex,
+) error {
+
+	serialNumbers, err := ReadDirectoryNumbers(fileManager, CertificatesDirectory(dir, organization))
+	if err != nil {
+		return fmt.Errorf("failed to list certificates of organization '%s': %w", organization, err)
+	}
+
+	certificates := make([]*x509.Certificate, 0, len(serialNumbers))
+	for _, serialNumber := range serialNumbers {
+		cert, err := ReadCertificateFile(fileManager, certManager, CertificatePemPath(dir, organization, serialNumber))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("failed to index certificate '%s' of organization '%s': %w", serialNumber, organization, err)
+		}
+		certificates = append(certificates, cert)
+	}
+
+	// A certificate whose issuer is not found is not indexed, since indexing
+	// it without an issuer would make it a root certificate
+	for _, cert := range certificates {
+		signedBy, found := findCertificateIssuer(cert, certificates)
+		if !found {
+			log.Printf("[CertificateIndex:%s] Issuer not found for certificate %s, skipped", organization, cert.SerialNumber)
+			index.Remove(organization, cert.SerialNumber)
+			continue
+		}
+		index.Set(organization, cert.SerialNumber, signedBy)
+	}
+
+	return nil
+}
+
+// ReadDirectoryNumbers returns the names of subdirectories which are decimal
+// numbers, in ascending order. A missing directory is handled as empty.
+func ReadDirectoryNumbers(fileManager managers.FileManager, dir string) ([]*big.Int, error) {
+	entries, err := fileManager.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []*big.Int{}, nil
+		}
+		return nil, err
+	}
+	list := make([]*big.Int, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if value := parseIndexSerialNumber(entry.Name()); value != nil {
+			list = append(list, value)
+		}
+	}
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].Cmp(list[b]) < 0
+	})
+	return list, nil
+}
+
+// findCertificateIssuer returns the serial number of the certificate which
+// signed cert. The issuer is nil for self-signed certificates.
+func findCertificateIssuer(cert *x509.Certificate, candidates []*x509.Certificate) (*big.Int, bool) {
+	if bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil {
+		return nil, true
+	}
+	for _, candidate := range candidates {
+		if candidate == cert || !candidate.IsCA {
+			continue
+		}
+		if cert.CheckSignatureFrom(candidate) == nil {
+			return candidate.SerialNumber, true
+		}
+	}
+	return nil, false
+}
+
+func parseIndexSerialNumber(value string) *big.Int {
+	if value == "" {
+		return nil
+	}
+	serialNumber, ok := new(big.Int).SetString(value, 10)
+	if !ok || serialNumber.Sign() < 0 {
+		return nil
+	}
+	return serialNumber
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository_test
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+)
+
+func TestCertificateIndex_SetAndLookup(t *testing.T) {
+	index := filerepository.NewCertificateIndex()
+	organization := big.NewInt(123)
+
+	index.Set(organization, big.NewInt(10), nil)
+	index.Set(organization, big.NewInt(2), big.NewInt(10))
+
+	signedBy, exists := index.Lookup(organization, big.NewInt(10))
+	assert.True(t, exists)
+	assert.Nil(t, signedBy)
+
+	signedBy, exists = index.Lookup(organization, big.NewInt(2))
+	assert.True(t, exists)
+	assert.Equal(t, "10", signedBy.String())
+
+	_, exists = index.Lookup(organization, big.NewInt(3))
+	assert.False(t, exists)
+	_, exists = index.Lookup(big.NewInt(1), big.NewInt(2))
+	assert.False(t, exists)
+
+	assert.Equal(t, []*big.Int{big.NewInt(2), big.NewInt(10)}, index.SerialNumbers(organization))
+	assert.Empty(t, index.SerialNumbers(big.NewInt(1)))
+}
+
+func TestReadDirectoryNumbers(t *testing.T) {
+	tempDir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	for _, name := range []string{"10", "9", "abc"} {
+		assert.NoError(t, os.Mkdir(filepath.Join(tempDir, name), 0700))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "8"), []byte{}, 0600))
+
+	fileManager := managers.NewFileManager()
+	list, err := filerepository.ReadDirectoryNumbers(fileManager, tempDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []*big.Int{big.NewInt(9), big.NewInt(10)}, list)
+
+	list, err = filerepository.ReadDirectoryNumbers(fileManager, filepath.Join(tempDir, "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+}
@@ -3,18 +3,64 @@
 package filerepository
 
 import (
+	"fmt"
 	"math/big"
 	"path/filepath"
+	"strconv"
+	"time"
 )
 
 const (
-	OrganizationsDirectoryName = "organizations"
-	CertificatesDirectoryName  = "certificates"
-	OrganizationJsonName       = "organization.json"
-	CertificatePemName         = "cert.pem"
-	PrivateKeyPemName          = "privkey.pem"
+	OrganizationsDirectoryName      = "organizations"
+	CertificatesDirectoryName       = "certificates"
+	ArchiveDirectoryName            = "archive"
+	OrganizationJsonName            = "organization.json"
+	CertificatePemName              = "cert.pem"
+	PrivateKeyPemName               = "privkey.pem"
+	CertificateIndexJsonName        = "certificates.json"
+	SchemaVersionName               = "schema-version"
+	BackupsDirectoryName            = "backups"
+	SshDirectoryName                = "ssh"
+	SshAuthorityJsonName            = "authority.json"
+	RevocationsDirectoryName        = "revocations"
+	RevocationListsDirectoryName    = "revocation-lists"
+	OperationsDirectoryName         = "operations"
+	AcmeDirectoryName               = "acme"
+	AcmeAccountsDirectoryName       = "accounts"
+	AcmeOrdersDirectoryName         = "orders"
+	AcmeAuthorizationsDirectoryName = "authorizations"
+	EstLabelsDirectoryName          = "est-labels"
+	ScepChallengeJsonName           = "scep-challenge.json"
+	VaultRolesDirectoryName         = "vault-roles"
 )
 
+// SchemaVersionPath returns a path like `{dir}/schema-version`
+func SchemaVersionPath(dir string) string {
+	return filepath.Join(dir, SchemaVersionName)
+}
+
+// SchemaBackupDirectory returns a path like
+// `{dir}/backups/schema-{version}-{time}` for a backup taken before the
+// schema is migrated from the version
+func SchemaBackupDirectory(dir string, version int, now time.Time) string {
+	return filepath.Join(dir, BackupsDirectoryName, fmt.Sprintf("schema-%d-%s", version, now.UTC().Format("20060102T150405Z")))
+}
+
+// CertificateIndexJsonPath returns a path like `{dir}/certificates.json`
+func CertificateIndexJsonPath(dir string) string {
+	return filepath.Join(dir, CertificateIndexJsonName)
+}
+
+// OrganizationsDirectory returns a path like `{dir}/organizations`
+func OrganizationsDirectory(dir string) string {
+	return filepath.Join(dir, OrganizationsDirectoryName)
+}
+
+// CertificatesDirectory returns a path like `{dir}/organizations/{organization}/certificates`
+func CertificatesDirectory(dir string, organization *big.Int) string {
+	return filepath.Join(dir, OrganizationsDirectoryName, organization.String(), CertificatesDirectoryName)
+}
+
 // OrganizationDirectory returns a path like `{dir}/organizations/{organization}`
 func OrganizationDirectory(dir string, organization *big.Int) string {
 	return filepath.Join(dir, OrganizationsDirectoryName, organization.String())
@@ -43,3 +89,113 @@ func CertificateDirectory(
 	parts := []string{dir, OrganizationsDirectoryName, organization.String(), CertificatesDirectoryName, certificate.String()}
 	return filepath.Join(parts...)
 }
+
+// ArchiveDirectory returns a path like `{dir}/organizations/{organization}/archive`
+func ArchiveDirectory(dir string, organization *big.Int) string {
+	return filepath.Join(dir, OrganizationsDirectoryName, organization.String(), ArchiveDirectoryName)
+}
+
+// ArchivedCertificateDirectory returns a path like `{dir}/organizations/{organization}/archive/{certificate}`
+func ArchivedCertificateDirectory(dir string, organization, certificate *big.Int) string {
+	return filepath.Join(ArchiveDirectory(dir, organization), certificate.String())
+}
+
+// ArchivedCertificatePemPath returns a path like `{dir}/organizations/{organization}/archive/{certificate}/cert.pem`
+func ArchivedCertificatePemPath(dir string, organization, certificate *big.Int) string {
+	return filepath.Join(ArchivedCertificateDirectory(dir, organization, certificate), CertificatePemName)
+}
+
+// SshDirectory returns a path like `{dir}/organizations/{organization}/ssh`
+func SshDirectory(dir string, organization *big.Int) string {
+	return filepath.Join(dir, OrganizationsDirectoryName, organization.String(), SshDirectoryName)
+}
+
+// SshAuthorityJsonPath returns a path like `{dir}/organizations/{organization}/ssh/authority.json`
+func SshAuthorityJsonPath(dir string, organization *big.Int) string {
+	return filepath.Join(SshDirectory(dir, organization), SshAuthorityJsonName)
+}
+
+// SshCertificatesDirectory returns a path like `{dir}/organizations/{organization}/ssh/certificates`
+func SshCertificatesDirectory(dir string, organization *big.Int) string {
+	return filepath.Join(SshDirectory(dir, organization), CertificatesDirectoryName)
+}
+
+// SshCertificateJsonPath returns a path like `{dir}/organizations/{organization}/ssh/certificates/{serial}.json`
+func SshCertificateJsonPath(dir string, organization *big.Int, serial uint64) string {
+	return filepath.Join(SshCertificatesDirectory(dir, organization), strconv.FormatUint(serial, 10)+".json")
+}
+
+// RevocationsDirectory returns a path like `{dir}/organizations/{organization}/revocations`
+func RevocationsDirectory(dir string, organization *big.Int) string {
+	return filepath.Join(dir, OrganizationsDirectoryName, organization.String(), RevocationsDirectoryName)
+}
+
+// RevocationJsonPath returns a path like `{dir}/organizations/{organization}/revocations/{certificate}.json`
+func RevocationJsonPath(dir string, organization, certificate *big.Int) string {
+	return filepath.Join(RevocationsDirectory(dir, organization), certificate.String()+".json")
+}
+
+// RevocationListJsonPath returns a path like `{dir}/organizations/{organization}/revocation-lists/{issuer}.json`
+func RevocationListJsonPath(dir string, organization, issuer *big.Int) string {
+	return filepath.Join(dir, OrganizationsDirectoryName, organization.String(), RevocationListsDirectoryName, issuer.String()+".json")
+}
+
+// OperationsDirectory returns a path like `{dir}/operations`
+func OperationsDirectory(dir string) string {
+	return filepath.Join(dir, OperationsDirectoryName)
+}
+
+// OperationJsonPath returns a path like `{dir}/operations/{id}.json`
+func OperationJsonPath(dir string, id string) string {
+	return filepath.Join(OperationsDirectory(dir), id+".json")
+}
+
+// AcmeAccountsDirectory returns a path like `{dir}/acme/accounts`
+func AcmeAccountsDirectory(dir string) string {
+	return filepath.Join(dir, AcmeDirectoryName, AcmeAccountsDirectoryName)
+}
+
+// AcmeAccountJsonPath returns a path like `{dir}/acme/accounts/{id}.json`
+func AcmeAccountJsonPath(dir string, id string) string {
+	return filepath.Join(AcmeAccountsDirectory(dir), id+".json")
+}
+
+// AcmeOrdersDirectory returns a path like `{dir}/acme/orders`
+func AcmeOrdersDirectory(dir string) string {
+	return filepath.Join(dir, AcmeDirectoryName, AcmeOrdersDirectoryName)
+}
+
+// AcmeOrderJsonPath returns a path like `{dir}/acme/orders/{id}.json`
+func AcmeOrderJsonPath(dir string, id string) string {
+	return filepath.Join(AcmeOrdersDirectory(dir), id+".json")
+}
+
+// AcmeAuthorizationJsonPath returns a path like `{dir}/acme/authorizations/{id}.json`
+func AcmeAuthorizationJsonPath(dir string, id string) string {
+	return filepath.Join(dir, AcmeDirectoryName, AcmeAuthorizationsDirectoryName, id+".json")
+}
+
+// EstLabelsDirectory returns a path like `{dir}/est-labels`
+func EstLabelsDirectory(dir string) string {
+	return filepath.Join(dir, EstLabelsDirectoryName)
+}
+
+// EstLabelJsonPath returns a path like `{dir}/est-labels/{label}.json`
+func EstLabelJsonPath(dir string, label string) string {
+	return filepath.Join(EstLabelsDirectory(dir), label+".json")
+}
+
+// ScepChallengeJsonPath returns a path like `{dir}/organizations/{organization}/scep-challenge.json`
+func ScepChallengeJsonPath(dir string, organization *big.Int) string {
+	return filepath.Join(OrganizationDirectory(dir, organization), ScepChallengeJsonName)
+}
+
+// VaultRolesDirectory returns a path like `{dir}/organizations/{organization}/vault-roles`
+func VaultRolesDirectory(dir string, organization *big.Int) string {
+	return filepath.Join(OrganizationDirectory(dir, organization), VaultRolesDirectoryName)
+}
+
+// VaultRoleJsonPath returns a path like `{dir}/organizations/{organization}/vault-roles/{name}.json`
+func VaultRoleJsonPath(dir string, organization *big.Int, name string) string {
+	return filepath.Join(VaultRolesDirectory(dir, organization), name+".json")
+}
@@ -5,6 +5,7 @@ package filerepository_test
 import (
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -29,6 +30,20 @@ func TestGetOrganizationJsonPath(t *testing.T) {
 	assert.Equal(t, expected, result)
 }
 
+func TestGetCertificateIndexJsonPath(t *testing.T) {
+	assert.Equal(t, "/data/certificates.json", filerepository.CertificateIndexJsonPath("/data"))
+}
+
+func TestGetOrganizationsDirectory(t *testing.T) {
+	assert.Equal(t, "/data/organizations", filerepository.OrganizationsDirectory("/data"))
+}
+
+func TestGetCertificatesDirectory(t *testing.T) {
+	organization := big.NewInt(123)
+	expected := "/data/organizations/123/certificates"
+	assert.Equal(t, expected, filerepository.CertificatesDirectory("/data", organization))
+}
+
 func TestGetPrivateKeyPemPathWithTwoCertificates(t *testing.T) {
 	certificate := appmodels.NewSerialNumber(456)
 	dir := "/data"
@@ -82,3 +97,55 @@ func TestGetCertificateDirectoryWithOneCertificate(t *testing.T) {
 	result := filerepository.CertificateDirectory(dir, organization, certificate)
 	assert.Equal(t, expected, result)
 }
+
+func TestSchemaVersionPath(t *testing.T) {
+	assert.Equal(t, "/data/schema-version", filerepository.SchemaVersionPath("/data"))
+}
+
+func TestSchemaBackupDirectory(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	assert.Equal(t, "/data/backups/schema-1-20240501T123000Z", filerepository.SchemaBackupDirectory("/data", 1, now))
+}
+
+func TestSshPaths(t *testing.T) {
+	organization := big.NewInt(12)
+	assert.Equal(t, "/data/organizations/12/ssh", filerepository.SshDirectory("/data", organization))
+	assert.Equal(t, "/data/organizations/12/ssh/authority.json", filerepository.SshAuthorityJsonPath("/data", organization))
+	assert.Equal(t, "/data/organizations/12/ssh/certificates", filerepository.SshCertificatesDirectory("/data", organization))
+	assert.Equal(t, "/data/organizations/12/ssh/certificates/42.json", filerepository.SshCertificateJsonPath("/data", organization, 42))
+}
+
+func TestRevocationPaths(t *testing.T) {
+	organization := big.NewInt(12)
+	assert.Equal(t, "/data/organizations/12/revocations", filerepository.RevocationsDirectory("/data", organization))
+	assert.Equal(t, "/data/organizations/12/revocations/42.json", filerepository.RevocationJsonPath("/data", organization, big.NewInt(42)))
+	assert.Equal(t, "/data/organizations/12/revocation-lists/1.json", filerepository.RevocationListJsonPath("/data", organization, big.NewInt(1)))
+}
+
+func TestOperationJsonPath(t *testing.T) {
+	assert.Equal(t, "/data/operations", filerepository.OperationsDirectory("/data"))
+	assert.Equal(t, "/data/operations/abc.json", filerepository.OperationJsonPath("/data", "abc"))
+}
+
+func TestAcmePaths(t *testing.T) {
+	assert.Equal(t, "/data/acme/accounts", filerepository.AcmeAccountsDirectory("/data"))
+	assert.Equal(t, "/data/acme/accounts/abc.json", filerepository.AcmeAccountJsonPath("/data", "abc"))
+	assert.Equal(t, "/data/acme/orders", filerepository.AcmeOrdersDirectory("/data"))
+	assert.Equal(t, "/data/acme/orders/abc.json", filerepository.AcmeOrderJsonPath("/data", "abc"))
+	assert.Equal(t, "/data/acme/authorizations/abc.json", filerepository.AcmeAuthorizationJsonPath("/data", "abc"))
+}
+
+func TestEstLabelPaths(t *testing.T) {
+	assert.Equal(t, "/data/est-labels", filerepository.EstLabelsDirectory("/data"))
+	assert.Equal(t, "/data/est-labels/devices.json", filerepository.EstLabelJsonPath("/data", "devices"))
+}
+
+func TestScepChallengeJsonPath(t *testing.T) {
+	assert.Equal(t, "/data/organizations/12/scep-challenge.json", filerepository.ScepChallengeJsonPath("/data", big.NewInt(12)))
+}
+
+func TestVaultRolePaths(t *testing.T) {
+	organization := big.NewInt(12)
+	assert.Equal(t, "/data/organizations/12/vault-roles", filerepository.VaultRolesDirectory("/data", organization))
+	assert.Equal(t, "/data/organizations/12/vault-roles/web.json", filerepository.VaultRoleJsonPath("/data", organization, "web"))
+}
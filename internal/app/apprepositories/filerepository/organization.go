@@ -3,7 +3,9 @@
 package filerepository
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"math/big"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
@@ -19,11 +21,55 @@ type FileOrganizationRepository struct {
 }
 
 func (r *FileOrganizationRepository) FindAll() ([]appmodels.Organization, error) {
-	// FIXME: Not implemented yet
-	var list []appmodels.Organization
+	ids, err := ReadDirectoryNumbers(r.fileManager, OrganizationsDirectory(r.filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	list := make([]appmodels.Organization, 0, len(ids))
+	for _, id := range ids {
+		model, err := r.FindById(id)
+		if err != nil {
+			// Directories without organization.json only hold certificates
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		list = append(list, model)
+	}
 	return list, nil
 }
 
+// FindPage returns a page of organizations. Organization files are read in
+// ID order only until the page is full.
+func (r *FileOrganizationRepository) FindPage(query appmodels.OrganizationQuery) (appmodels.OrganizationPage, error) {
+	ids, err := ReadDirectoryNumbers(r.fileManager, OrganizationsDirectory(r.filePath))
+	if err != nil {
+		return appmodels.OrganizationPage{}, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	list := make([]appmodels.Organization, 0)
+	for _, id := range ids {
+		if query.Limit > 0 && len(list) > query.Limit {
+			break
+		}
+		if query.After != nil && id.Cmp(query.After) <= 0 {
+			continue
+		}
+		model, err := r.FindById(id)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return appmodels.OrganizationPage{}, err
+		}
+		if query.Name != "" && !apputils.OrganizationMatchesName(model, query.Name) {
+			continue
+		}
+		list = append(list, model)
+	}
+	return apputils.QueryOrganizations(list, query), nil
+}
+
 func (r *FileOrganizationRepository) FindById(id *big.Int) (appmodels.Organization, error) {
 	fileName := OrganizationJsonPath(r.filePath, id)
 	dto, err := ReadOrganizationJsonFile(r.fileManager, fileName)
@@ -34,10 +80,21 @@ func (r *FileOrganizationRepository) FindById(id *big.Int) (appmodels.Organizati
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse organization id '%s': %w", dto.ID, err)
 	}
+	signatureAlgorithm, err := apputils.ParseSignatureAlgorithm(dto.SignatureAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse organization signature algorithm '%s': %w", dto.SignatureAlgorithm, err)
+	}
+	keyRetentionPolicy, err := apputils.ParseKeyRetentionPolicy(dto.KeyRetentionPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse organization key retention policy '%s': %w", dto.KeyRetentionPolicy, err)
+	}
 	model := appmodels.NewOrganization(
 		id,
 		dto.Slug,
 		dto.AllNames,
+		signatureAlgorithm,
+		dto.SpiffeTrustDomain,
+		keyRetentionPolicy,
 	)
 	return model, nil
 }
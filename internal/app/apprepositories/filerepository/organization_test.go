@@ -10,6 +10,7 @@ import (
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
 	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
 	"github.com/hyperifyio/gocertcenter/internal/common/managers"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
@@ -34,7 +35,7 @@ func TestOrganizationRepository_GetExistingOrganization(t *testing.T) {
 	err := filerepository.SaveOrganizationJsonFile(
 		fileManager,
 		orgJsonPath,
-		appdtos.NewOrganizationDTO(orgID.String(), "org123", "Test Org", []string{"Test Org"}),
+		appdtos.NewOrganizationDTO(orgID.String(), "org123", "Test Org", []string{"Test Org"}, "", "", ""),
 	)
 	assert.NoError(t, err)
 
@@ -68,6 +69,9 @@ func TestOrganizationRepository_CreateOrganization(t *testing.T) {
 	mockOrg.On("Name").Return(orgName)
 	mockOrg.On("Slug").Return("testorg")
 	mockOrg.On("Names").Return([]string{orgName})
+	mockOrg.On("SignatureAlgorithm").Return(appmodels.NIL_SIGNATURE_ALGORITHM)
+	mockOrg.On("SpiffeTrustDomain").Return("")
+	mockOrg.On("KeyRetentionPolicy").Return(appmodels.KEY_RETENTION_RETAIN)
 	mockOrg.On("ID").Return(orgID)
 	repo := filerepository.NewOrganizationRepository(certManager, fileManager, filePath)
 
@@ -75,6 +79,7 @@ func TestOrganizationRepository_CreateOrganization(t *testing.T) {
 	org, err := repo.Save(mockOrg)
 	assert.NoError(t, err)
 	assert.NotNil(t, org)
+	assert.Equal(t, appmodels.KEY_RETENTION_RETAIN, org.KeyRetentionPolicy())
 	// Perform more assertions based on the mockOrg and the expected behaviors
 
 	orgJsonPath := filerepository.OrganizationJsonPath(filePath, orgID)
@@ -85,9 +90,41 @@ func TestOrganizationRepository_CreateOrganization(t *testing.T) {
 	assert.Equal(t, orgID.String(), savedOrg.ID, "The saved organization ID should match the original ID")
 	expectedNames := []string{orgName}
 	assert.Equal(t, expectedNames, savedOrg.AllNames, "The saved organization names should match the original names")
+	assert.Equal(t, "RETAIN", savedOrg.KeyRetentionPolicy)
 
 }
 
+func TestOrganizationRepository_FindAll(t *testing.T) {
+
+	randomManager := managers.NewRandomManager()
+	fileManager := managers.NewFileManager()
+	certManager := managers.NewCertificateManager(randomManager)
+
+	// Setup
+	tempDir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	repo := filerepository.NewOrganizationRepository(certManager, fileManager, tempDir)
+
+	list, err := repo.FindAll()
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	for _, id := range []int64{20, 3} {
+		_, err = repo.Save(appmodels.NewOrganization(big.NewInt(id), "org", []string{"Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+		assert.NoError(t, err)
+	}
+
+	// An organization directory with certificates only is skipped
+	assert.NoError(t, fileManager.MkdirAll(filerepository.CertificatesDirectory(tempDir, big.NewInt(7)), 0700))
+
+	list, err = repo.FindAll()
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, big.NewInt(3), list[0].ID())
+	assert.Equal(t, big.NewInt(20), list[1].ID())
+}
+
 func TestOrganizationRepository_GetExistingOrganization_ReadFail(t *testing.T) {
 
 	randomManager := managers.NewRandomManager()
@@ -132,6 +169,9 @@ func TestOrganizationRepository_CreateOrganization_SaveFail(t *testing.T) {
 	mockOrg.On("Slug").Return(orgSlug)
 	mockOrg.On("Name").Return(orgName)
 	mockOrg.On("Names").Return([]string{orgName})
+	mockOrg.On("SignatureAlgorithm").Return(appmodels.NIL_SIGNATURE_ALGORITHM)
+	mockOrg.On("SpiffeTrustDomain").Return("")
+	mockOrg.On("KeyRetentionPolicy").Return(appmodels.KEY_RETENTION_NONE)
 	mockOrg.On("ID").Return(orgId)
 
 	// Test
@@ -142,3 +182,35 @@ func TestOrganizationRepository_CreateOrganization_SaveFail(t *testing.T) {
 	assert.Nil(t, org, "Organization should be nil when saving fails")
 	assert.Contains(t, err.Error(), "organization creation failed", "Error message should indicate failure in organization creation")
 }
+
+func TestOrganizationRepository_FindPage(t *testing.T) {
+	repo := filerepository.NewOrganizationRepository(managers.NewCertificateManager(managers.NewRandomManager()), managers.NewFileManager(), t.TempDir())
+	for _, v := range []struct {
+		id   int64
+		slug string
+	}{{10, "gamma"}, {2, "beta"}, {1, "alpha"}} {
+		_, err := repo.Save(appmodels.NewOrganization(big.NewInt(v.id), v.slug, []string{v.slug + " Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+		assert.NoError(t, err)
+	}
+	idsOf := func(page appmodels.OrganizationPage) []string {
+		var ids []string
+		for _, org := range page.Organizations {
+			ids = append(ids, org.ID().String())
+		}
+		return ids
+	}
+
+	page, err := repo.FindPage(appmodels.OrganizationQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, idsOf(page))
+	if assert.NotNil(t, page.Next) {
+		page, err = repo.FindPage(appmodels.OrganizationQuery{Limit: 2, After: page.Next})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"10"}, idsOf(page))
+		assert.Nil(t, page.Next)
+	}
+
+	page, err = repo.FindPage(appmodels.OrganizationQuery{Name: "GAM"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10"}, idsOf(page))
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/fsutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// FilePendingOperationRepository implements
+// appmodels.PendingOperationRepository for a file system. Each operation is a
+// JSON file named by its ID.
+type FilePendingOperationRepository struct {
+	filePath    string
+	fileManager managers.FileManager
+}
+
+func (r *FilePendingOperationRepository) FindAll() ([]appmodels.PendingOperation, error) {
+	entries, err := r.fileManager.ReadDir(OperationsDirectory(r.filePath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []appmodels.PendingOperation{}, nil
+		}
+		return nil, fmt.Errorf("[PendingOperation:FindAll]: %w", err)
+	}
+	list := make([]appmodels.PendingOperation, 0, len(entries))
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || !isTokenID(id) {
+			continue
+		}
+		model, err := r.FindById(id)
+		if err != nil {
+			return nil, fmt.Errorf("[PendingOperation:FindAll]: %w", err)
+		}
+		list = append(list, model)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt().Equal(list[j].CreatedAt()) {
+			return list[i].ID() < list[j].ID()
+		}
+		return list[i].CreatedAt().Before(list[j].CreatedAt())
+	})
+	return list, nil
+}
+
+func (r *FilePendingOperationRepository) FindById(id string) (appmodels.PendingOperation, error) {
+	if !isTokenID(id) {
+		return nil, fmt.Errorf("[PendingOperation:FindById]: invalid ID: %q", id)
+	}
+	data, err := r.fileManager.ReadFile(OperationJsonPath(r.filePath, id))
+	if err != nil {
+		return nil, fmt.Errorf("[PendingOperation:FindById]: not found: %s: %w", id, err)
+	}
+	dto := appdtos.PendingOperationRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[PendingOperation:FindById]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromPendingOperationRecordDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[PendingOperation:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *FilePendingOperationRepository) Save(model appmodels.PendingOperation) (appmodels.PendingOperation, error) {
+	if !isTokenID(model.ID()) {
+		return nil, fmt.Errorf("[PendingOperation:Save]: invalid ID: %q", model.ID())
+	}
+	data, err := json.MarshalIndent(apputils.ToPendingOperationRecordDTO(model), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("[PendingOperation:Save]: failed to marshal JSON: %w", err)
+	}
+	if err := fsutils.SaveBytes(r.fileManager, OperationJsonPath(r.filePath, model.ID()), data, 0600, 0700); err != nil {
+		return nil, fmt.Errorf("[PendingOperation:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// isTokenID returns true if the ID is safe to use as a file name. Operation
+// and ACME IDs are URL safe base64 tokens.
+func isTokenID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// NewPendingOperationRepository creates a file based repository for
+// operations waiting for approval
+func NewPendingOperationRepository(
+	fileManager managers.FileManager,
+	filePath string,
+) *FilePendingOperationRepository {
+	return &FilePendingOperationRepository{
+		filePath:    filePath,
+		fileManager: fileManager,
+	}
+}
+
+var _ appmodels.PendingOperationRepository = (*FilePendingOperationRepository)(nil)
@@ -0,0 +1,60 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestPendingOperationRepository_SaveAndFind(t *testing.T) {
+	dir := t.TempDir()
+	repo := filerepository.NewPendingOperationRepository(managers.NewFileManager(), dir)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	list, err := repo.FindAll()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	second := appmodels.NewPendingOperation("a", appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, big.NewInt(1), nil, "", "alice", appmodels.OPERATION_STATUS_PENDING, now.Add(time.Minute), now.Add(time.Hour), []appmodels.OperationApproval{}, time.Time{})
+	first := appmodels.NewPendingOperation("b", appmodels.OPERATION_REVOKE_ROOT_CERTIFICATE, big.NewInt(1), big.NewInt(2), "", "alice", appmodels.OPERATION_STATUS_PENDING, now, now.Add(time.Hour), []appmodels.OperationApproval{}, time.Time{})
+	_, err = repo.Save(second)
+	require.NoError(t, err)
+	_, err = repo.Save(first)
+	require.NoError(t, err)
+
+	// Saving again replaces the operation
+	approved := appmodels.NewPendingOperation("a", appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, big.NewInt(1), nil, "", "alice", appmodels.OPERATION_STATUS_APPROVED, now.Add(time.Minute), now.Add(time.Hour), []appmodels.OperationApproval{appmodels.NewOperationApproval("bob", true, now)}, time.Time{})
+	_, err = repo.Save(approved)
+	require.NoError(t, err)
+
+	found, err := repo.FindById("a")
+	require.NoError(t, err)
+	assert.Equal(t, approved, found)
+
+	list, err = repo.FindAll()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "b", list[0].ID())
+	assert.Equal(t, "a", list[1].ID())
+
+	// Operations are read back by a new repository of the same directory
+	found, err = filerepository.NewPendingOperationRepository(managers.NewFileManager(), dir).FindById("b")
+	require.NoError(t, err)
+	assert.Equal(t, first, found)
+
+	_, err = repo.FindById("missing")
+	assert.Error(t, err)
+	_, err = repo.FindById("../organizations")
+	assert.Error(t, err)
+	_, err = repo.Save(appmodels.NewPendingOperation("../x", appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, big.NewInt(1), nil, "", "alice", appmodels.OPERATION_STATUS_PENDING, now, now, nil, time.Time{}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/fsutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// FileScepChallengeRepository implements appmodels.ScepChallengeRepository
+// for a file system. The challenge is a JSON file in the organization
+// directory.
+type FileScepChallengeRepository struct {
+	filePath    string
+	fileManager managers.FileManager
+}
+
+func (r *FileScepChallengeRepository) FindByOrganization(organization *big.Int) (appmodels.ScepChallenge, error) {
+	data, err := r.fileManager.ReadFile(ScepChallengeJsonPath(r.filePath, organization))
+	if err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:FindByOrganization]: not found: %s: %w", organization, err)
+	}
+	dto := appdtos.ScepChallengeRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:FindByOrganization]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromScepChallengeRecordDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:FindByOrganization]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *FileScepChallengeRepository) Save(model appmodels.ScepChallenge) (appmodels.ScepChallenge, error) {
+	data, err := json.MarshalIndent(apputils.ToScepChallengeRecordDTO(model), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:Save]: failed to marshal JSON: %w", err)
+	}
+	if err := fsutils.SaveBytes(r.fileManager, ScepChallengeJsonPath(r.filePath, model.OrganizationID()), data, 0600, 0700); err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewScepChallengeRepository creates a file based repository for SCEP
+// challenge passwords
+func NewScepChallengeRepository(
+	fileManager managers.FileManager,
+	filePath string,
+) *FileScepChallengeRepository {
+	return &FileScepChallengeRepository{
+		filePath:    filePath,
+		fileManager: fileManager,
+	}
+}
+
+var _ appmodels.ScepChallengeRepository = (*FileScepChallengeRepository)(nil)
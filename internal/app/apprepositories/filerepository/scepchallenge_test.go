@@ -0,0 +1,38 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestScepChallengeRepository_SaveAndFind(t *testing.T) {
+	dir := t.TempDir()
+	repo := filerepository.NewScepChallengeRepository(managers.NewFileManager(), dir)
+
+	_, err := repo.FindByOrganization(big.NewInt(1))
+	assert.Error(t, err)
+
+	_, err = repo.Save(appmodels.NewScepChallenge(big.NewInt(1), []byte("old")))
+	require.NoError(t, err)
+
+	// Saving again replaces the challenge
+	challenge := appmodels.NewScepChallenge(big.NewInt(1), []byte("hash"))
+	_, err = repo.Save(challenge)
+	require.NoError(t, err)
+
+	found, err := filerepository.NewScepChallengeRepository(managers.NewFileManager(), dir).FindByOrganization(big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, challenge, found)
+
+	_, err = repo.FindByOrganization(big.NewInt(2))
+	assert.Error(t, err)
+}
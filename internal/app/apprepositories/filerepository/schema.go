@@ -0,0 +1,249 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/fsutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// Migration is one step of the data directory layout. Migrations are applied
+// in order and each one only once.
+type Migration struct {
+
+	// Version is the schema version after this migration
+	Version int
+
+	// Description describes the layout of this version
+	Description string
+
+	// Apply upgrades the data directory from the previous version in place.
+	// Nil means the files did not change and only the version is updated.
+	Apply func(fileManager managers.FileManager, dir string) error
+}
+
+// Migrations is the schema history. New migrations are appended to the end;
+// existing ones must never be changed.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "organizations/{organization}/certificates/{certificate}/cert.pem with the certificates.json issuer index",
+	},
+	{
+		// Older binaries would not see archived certificates
+		Version:     2,
+		Description: "organizations/{organization}/archive/{certificate}/cert.pem for archived certificates",
+	},
+	{
+		// Older binaries would not see SSH certificate authorities
+		Version:     3,
+		Description: "organizations/{organization}/ssh/authority.json and ssh/certificates/{serial}.json for SSH certificate authorities",
+	},
+	{
+		// Older binaries would not see revocations
+		Version:     4,
+		Description: "organizations/{organization}/revocations/{certificate}.json and revocation-lists/{issuer}.json for certificate revocations",
+	},
+	{
+		// Older binaries would not see ACME, EST, SCEP and Vault state
+		Version:     5,
+		Description: "acme/, est-labels/{label}.json, organizations/{organization}/scep-challenge.json and organizations/{organization}/vault-roles/{name}.json for protocol state",
+	},
+}
+
+// LatestSchemaVersion returns the schema version this binary writes
+func LatestSchemaVersion() int {
+	return Migrations[len(Migrations)-1].Version
+}
+
+// SchemaVersion returns the schema version of a data directory, or zero if
+// the directory has no version file
+func SchemaVersion(fileManager managers.FileManager, dir string) (int, error) {
+	data, err := fileManager.ReadFile(SchemaVersionPath(dir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid schema version: %q", strings.TrimSpace(string(data)))
+	}
+	return version, nil
+}
+
+// SaveSchemaVersion saves the schema version of a data directory
+func SaveSchemaVersion(fileManager managers.FileManager, dir string, version int) error {
+	return fsutils.SaveBytes(fileManager, SchemaVersionPath(dir), []byte(strconv.Itoa(version)+"\n"), 0600, 0700)
+}
+
+// Migrate upgrades a data directory to the latest schema version. Files are
+// copied under `{dir}/backups` before the first migration which changes them.
+// A data directory with a newer schema than this binary knows is refused.
+func Migrate(fileManager managers.FileManager, dir string) error {
+
+	current, err := SchemaVersion(fileManager, dir)
+	if err != nil {
+		return err
+	}
+
+	latest := LatestSchemaVersion()
+	if current > latest {
+		return fmt.Errorf("data directory schema version %d is newer than supported version %d", current, latest)
+	}
+	if current == latest {
+		return nil
+	}
+
+	// A new data directory has nothing to migrate
+	if current == 0 {
+		empty, err := isEmptyDataDirectory(fileManager, dir)
+		if err != nil {
+			return err
+		}
+		if empty {
+			return SaveSchemaVersion(fileManager, dir, latest)
+		}
+	}
+
+	backedUp := false
+	for _, migration := range Migrations {
+		if migration.Version <= current {
+			continue
+		}
+		if migration.Apply != nil {
+			if !backedUp {
+				backupDir := SchemaBackupDirectory(dir, current, time.Now())
+				if err := BackupDataDirectory(fileManager, dir, backupDir); err != nil {
+					return fmt.Errorf("failed to back up data directory before migrating schema version %d: %w", current, err)
+				}
+				log.Printf("[filerepository]: Data directory backed up to %s", backupDir)
+				backedUp = true
+			}
+			if err := migration.Apply(fileManager, dir); err != nil {
+				return fmt.Errorf("failed to migrate data directory schema to version %d: %w", migration.Version, err)
+			}
+		}
+		if err := SaveSchemaVersion(fileManager, dir, migration.Version); err != nil {
+			return fmt.Errorf("failed to save schema version %d: %w", migration.Version, err)
+		}
+		log.Printf("[filerepository]: Data directory migrated to schema version %d: %s", migration.Version, migration.Description)
+	}
+	return nil
+}
+
+// BackupDataDirectory copies the organizations and the certificate index of
+// a data directory to a backup directory
+func BackupDataDirectory(fileManager managers.FileManager, dir, backupDir string) error {
+	if err := fileManager.MkdirAll(backupDir, 0700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := copyDataFile(fileManager, CertificateIndexJsonPath(dir), CertificateIndexJsonPath(backupDir)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := copyDataDirectory(fileManager, OrganizationsDirectory(dir), OrganizationsDirectory(backupDir)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// OpenCollection migrates a data directory to the latest schema version,
+// saves the certificate index if it is missing and creates a collection of
+// repositories for it
+func OpenCollection(
+	certManager managers.CertificateManager,
+	fileManager managers.FileManager,
+	dir string,
+) (*appmodels.Collection, error) {
+	if err := Migrate(fileManager, dir); err != nil {
+		return nil, fmt.Errorf("data directory '%s': %w", dir, err)
+	}
+	if err := SaveCertificateIndex(fileManager, certManager, dir); err != nil {
+		return nil, fmt.Errorf("data directory '%s': %w", dir, err)
+	}
+	return NewCollection(certManager, fileManager, dir), nil
+}
+
+// OpenReadOnlyCollection creates a collection of repositories which only
+// read the data directory. The schema version must be the latest one, since
+// the directory cannot be migrated, and a missing certificate index is
+// rebuilt in memory only.
+func OpenReadOnlyCollection(
+	certManager managers.CertificateManager,
+	fileManager managers.FileManager,
+	dir string,
+) (*appmodels.Collection, error) {
+	current, err := SchemaVersion(fileManager, dir)
+	if err != nil {
+		return nil, fmt.Errorf("data directory '%s': %w", dir, err)
+	}
+	latest := LatestSchemaVersion()
+	if current > latest {
+		return nil, fmt.Errorf("data directory '%s': schema version %d is newer than supported version %d", dir, current, latest)
+	}
+	if current < latest {
+		return nil, fmt.Errorf("data directory '%s': schema version %d is older than version %d and must be migrated without read-only mode", dir, current, latest)
+	}
+	return NewCollection(certManager, managers.NewReadOnlyFileManager(fileManager), dir), nil
+}
+
+// isEmptyDataDirectory returns true if the directory has no organizations or
+// certificate index
+func isEmptyDataDirectory(fileManager managers.FileManager, dir string) (bool, error) {
+	if _, err := fileManager.ReadFile(CertificateIndexJsonPath(dir)); err == nil {
+		return false, nil
+	}
+	entries, err := fileManager.ReadDir(OrganizationsDirectory(dir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to read organizations directory: %w", err)
+	}
+	return len(entries) == 0, nil
+}
+
+func copyDataFile(fileManager managers.FileManager, source, target string) error {
+	data, err := fileManager.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	if err := fsutils.SaveBytes(fileManager, target, data, 0600, 0700); err != nil {
+		return fmt.Errorf("failed to copy '%s': %w", source, err)
+	}
+	return nil
+}
+
+func copyDataDirectory(fileManager managers.FileManager, source, target string) error {
+	entries, err := fileManager.ReadDir(source)
+	if err != nil {
+		return err
+	}
+	if err := fileManager.MkdirAll(target, 0700); err != nil {
+		return fmt.Errorf("failed to create '%s': %w", target, err)
+	}
+	for _, entry := range entries {
+		sourcePath := filepath.Join(source, entry.Name())
+		targetPath := filepath.Join(target, entry.Name())
+		if entry.IsDir() {
+			err = copyDataDirectory(fileManager, sourcePath, targetPath)
+		} else {
+			err = copyDataFile(fileManager, sourcePath, targetPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
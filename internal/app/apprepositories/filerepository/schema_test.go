@@ -0,0 +1,209 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository_test
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// withMigrations replaces the schema history for a test
+func withMigrations(t *testing.T, migrations []filerepository.Migration) {
+	original := filerepository.Migrations
+	filerepository.Migrations = migrations
+	t.Cleanup(func() { filerepository.Migrations = original })
+}
+
+func TestMigrations_Ordered(t *testing.T) {
+	for i, migration := range filerepository.Migrations {
+		assert.Equal(t, i+1, migration.Version)
+		assert.NotEmpty(t, migration.Description)
+	}
+}
+
+func TestMigrate_NewDataDirectory(t *testing.T) {
+	fileManager := managers.NewFileManager()
+	dir := t.TempDir()
+
+	require.NoError(t, filerepository.Migrate(fileManager, dir))
+
+	version, err := filerepository.SchemaVersion(fileManager, dir)
+	require.NoError(t, err)
+	assert.Equal(t, filerepository.LatestSchemaVersion(), version)
+	_, err = os.Stat(filepath.Join(dir, filerepository.BackupsDirectoryName))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMigrate_UnversionedDataDirectory(t *testing.T) {
+	fileManager := managers.NewFileManager()
+	dir := t.TempDir()
+	organizationJson := filerepository.OrganizationJsonPath(dir, big.NewInt(1))
+	require.NoError(t, os.MkdirAll(filepath.Dir(organizationJson), 0700))
+	require.NoError(t, os.WriteFile(organizationJson, []byte(`{"id":"1"}`), 0600))
+
+	var applied []int
+	withMigrations(t, []filerepository.Migration{
+		{Version: 1, Description: "original layout"},
+		{Version: 2, Description: "renamed", Apply: func(fileManager managers.FileManager, dir string) error {
+			applied = append(applied, 2)
+			return fileManager.Rename(organizationJson, organizationJson+".v2")
+		}},
+	})
+
+	require.NoError(t, filerepository.Migrate(fileManager, dir))
+	assert.Equal(t, []int{2}, applied)
+
+	version, err := filerepository.SchemaVersion(fileManager, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+	_, err = os.Stat(organizationJson + ".v2")
+	assert.NoError(t, err)
+
+	// The original files are backed up before they are changed
+	backups, err := os.ReadDir(filepath.Join(dir, filerepository.BackupsDirectoryName))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Contains(t, backups[0].Name(), "schema-0-")
+	backupDir := filepath.Join(dir, filerepository.BackupsDirectoryName, backups[0].Name())
+	data, err := os.ReadFile(filerepository.OrganizationJsonPath(backupDir, big.NewInt(1)))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"1"}`, string(data))
+
+	// Migrations are applied only once
+	require.NoError(t, filerepository.Migrate(fileManager, dir))
+	assert.Equal(t, []int{2}, applied)
+}
+
+func TestMigrate_WithoutChanges(t *testing.T) {
+	fileManager := managers.NewFileManager()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filerepository.CertificateIndexJsonPath(dir), []byte(`{}`), 0600))
+
+	require.NoError(t, filerepository.Migrate(fileManager, dir))
+
+	version, err := filerepository.SchemaVersion(fileManager, dir)
+	require.NoError(t, err)
+	assert.Equal(t, filerepository.LatestSchemaVersion(), version)
+	_, err = os.Stat(filepath.Join(dir, filerepository.BackupsDirectoryName))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMigrate_FromVersion1(t *testing.T) {
+	fileManager := managers.NewFileManager()
+	dir := t.TempDir()
+	require.NoError(t, filerepository.SaveSchemaVersion(fileManager, dir, 1))
+	require.NoError(t, os.WriteFile(filerepository.CertificateIndexJsonPath(dir), []byte(`{}`), 0600))
+
+	require.NoError(t, filerepository.Migrate(fileManager, dir))
+
+	version, err := filerepository.SchemaVersion(fileManager, dir)
+	require.NoError(t, err)
+	assert.Equal(t, filerepository.LatestSchemaVersion(), version)
+	assert.GreaterOrEqual(t, version, 2)
+}
+
+func TestMigrate_NewerSchema(t *testing.T) {
+	fileManager := managers.NewFileManager()
+	dir := t.TempDir()
+	require.NoError(t, filerepository.SaveSchemaVersion(fileManager, dir, filerepository.LatestSchemaVersion()+1))
+
+	err := filerepository.Migrate(fileManager, dir)
+	assert.ErrorContains(t, err, "newer than supported")
+
+	_, err = filerepository.OpenCollection(managers.NewCertificateManager(managers.NewRandomManager()), fileManager, dir)
+	assert.ErrorContains(t, err, "newer than supported")
+}
+
+func TestSchemaVersion_Invalid(t *testing.T) {
+	fileManager := managers.NewFileManager()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filerepository.SchemaVersionPath(dir), []byte("abc\n"), 0600))
+
+	_, err := filerepository.SchemaVersion(fileManager, dir)
+	assert.ErrorContains(t, err, "invalid schema version")
+	assert.Error(t, filerepository.Migrate(fileManager, dir))
+}
+
+func TestMigrate_FailedMigration(t *testing.T) {
+	fileManager := managers.NewFileManager()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filerepository.CertificateIndexJsonPath(dir), []byte(`{}`), 0600))
+
+	withMigrations(t, []filerepository.Migration{
+		{Version: 1, Description: "original layout"},
+		{Version: 2, Description: "broken", Apply: func(fileManager managers.FileManager, dir string) error {
+			return os.ErrPermission
+		}},
+	})
+
+	assert.ErrorContains(t, filerepository.Migrate(fileManager, dir), "version 2")
+
+	// The completed migration is kept, so the next run continues from it
+	version, err := filerepository.SchemaVersion(fileManager, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+}
+
+func TestOpenCollection(t *testing.T) {
+	fileManager := managers.NewFileManager()
+	dir := t.TempDir()
+	collection, err := filerepository.OpenCollection(managers.NewCertificateManager(managers.NewRandomManager()), fileManager, dir)
+	require.NoError(t, err)
+	assert.NotNil(t, collection.Organization)
+	_, err = os.Stat(filerepository.SchemaVersionPath(dir))
+	assert.NoError(t, err)
+}
+
+func TestOpenCollection_SavesMissingIndex(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	fileManager := managers.NewFileManager()
+	dir := t.TempDir()
+	organization := big.NewInt(123)
+	saveTestChain(t, filerepository.NewCertificateRepository(certManager, fileManager, dir), organization)
+	require.NoError(t, os.Remove(filerepository.CertificateIndexJsonPath(dir)))
+
+	_, err := filerepository.OpenCollection(certManager, fileManager, dir)
+	require.NoError(t, err)
+
+	index, err := filerepository.ReadCertificateIndexFile(fileManager, filerepository.CertificateIndexJsonPath(dir))
+	require.NoError(t, err)
+	assert.Len(t, index.SerialNumbers(organization), 4)
+}
+
+func TestOpenReadOnlyCollection(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	fileManager := managers.NewFileManager()
+	dir := t.TempDir()
+	organization := big.NewInt(123)
+
+	_, err := filerepository.OpenReadOnlyCollection(certManager, fileManager, dir)
+	assert.ErrorContains(t, err, "must be migrated without read-only mode")
+	_, err = os.Stat(filerepository.SchemaVersionPath(dir))
+	assert.True(t, os.IsNotExist(err), "Expected the schema version not to be saved")
+
+	_, err = filerepository.OpenCollection(certManager, fileManager, dir)
+	require.NoError(t, err)
+	saveTestChain(t, filerepository.NewCertificateRepository(certManager, fileManager, dir), organization)
+	require.NoError(t, os.Remove(filerepository.CertificateIndexJsonPath(dir)))
+
+	collection, err := filerepository.OpenReadOnlyCollection(certManager, fileManager, dir)
+	require.NoError(t, err)
+
+	// The missing index is rebuilt in memory only
+	certificates, err := collection.Certificate.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Len(t, certificates, 4)
+	_, err = os.Stat(filerepository.CertificateIndexJsonPath(dir))
+	assert.True(t, os.IsNotExist(err), "Expected the index not to be saved")
+
+	_, err = collection.Certificate.Save(certificates[0])
+	assert.ErrorIs(t, err, managers.ErrReadOnlyFileSystem)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/fsutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// FileSshAuthorityRepository implements appmodels.SshAuthorityRepository for
+// a file system. The CA key is stored as PEM inside the JSON file.
+type FileSshAuthorityRepository struct {
+	filePath    string
+	certManager managers.CertificateManager
+	fileManager managers.FileManager
+}
+
+func (r *FileSshAuthorityRepository) FindByOrganization(organization *big.Int) (appmodels.SshAuthority, error) {
+	data, err := r.fileManager.ReadFile(SshAuthorityJsonPath(r.filePath, organization))
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: not found: %s: %w", organization, err)
+	}
+	dto := appdtos.SshAuthorityRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromSshAuthorityRecordDTO(r.certManager, dto)
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *FileSshAuthorityRepository) Save(model appmodels.SshAuthority) (appmodels.SshAuthority, error) {
+	dto, err := apputils.ToSshAuthorityRecordDTO(r.certManager, model)
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:Save]: %w", err)
+	}
+	data, err := json.MarshalIndent(dto, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:Save]: failed to marshal JSON: %w", err)
+	}
+	fileName := SshAuthorityJsonPath(r.filePath, model.OrganizationID())
+	if err := fsutils.SaveBytes(r.fileManager, fileName, data, 0600, 0700); err != nil {
+		return nil, fmt.Errorf("[SshAuthority:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewSshAuthorityRepository creates a file based repository for SSH CA keys
+func NewSshAuthorityRepository(
+	certManager managers.CertificateManager,
+	fileManager managers.FileManager,
+	filePath string,
+) *FileSshAuthorityRepository {
+	return &FileSshAuthorityRepository{
+		filePath:    filePath,
+		certManager: certManager,
+		fileManager: fileManager,
+	}
+}
+
+var _ appmodels.SshAuthorityRepository = (*FileSshAuthorityRepository)(nil)
@@ -0,0 +1,63 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository_test
+
+import (
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestSshAuthority(t *testing.T, organization *big.Int) appmodels.SshAuthority {
+	t.Helper()
+	privateKey, err := apputils.GeneratePrivateKey(organization, big.NewInt(7), appmodels.Ed25519)
+	require.NoError(t, err)
+	return appmodels.NewSshAuthority(organization, big.NewInt(7), privateKey, time.Unix(1000, 0).UTC())
+}
+
+func TestSshAuthorityRepository_SaveAndFind(t *testing.T) {
+	dir := t.TempDir()
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	repo := filerepository.NewSshAuthorityRepository(certManager, managers.NewFileManager(), dir)
+	organization := big.NewInt(123)
+
+	_, err := repo.FindByOrganization(organization)
+	assert.ErrorContains(t, err, "not found")
+
+	authority := newTestSshAuthority(t, organization)
+	_, err = repo.Save(authority)
+	require.NoError(t, err)
+
+	info, err := os.Stat(filerepository.SshAuthorityJsonPath(dir, organization))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	found, err := repo.FindByOrganization(organization)
+	require.NoError(t, err)
+	assert.Equal(t, organization, found.OrganizationID())
+	assert.Equal(t, big.NewInt(7), found.ID())
+	assert.True(t, authority.CreatedAt().Equal(found.CreatedAt()))
+	assert.Equal(t, authority.PrivateKey().PublicKey(), found.PrivateKey().PublicKey())
+}
+
+func TestSshAuthorityRepository_FindCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	repo := filerepository.NewSshAuthorityRepository(certManager, managers.NewFileManager(), dir)
+	organization := big.NewInt(123)
+	fileName := filerepository.SshAuthorityJsonPath(dir, organization)
+	require.NoError(t, os.MkdirAll(filerepository.SshDirectory(dir, organization), 0700))
+	require.NoError(t, os.WriteFile(fileName, []byte("{"), 0600))
+
+	_, err := repo.FindByOrganization(organization)
+	assert.ErrorContains(t, err, "failed to unmarshal")
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/fsutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// FileSshCertificateRepository implements appmodels.SshCertificateRepository
+// for a file system. Each certificate is a JSON file named by its serial
+// number.
+type FileSshCertificateRepository struct {
+	filePath    string
+	fileManager managers.FileManager
+}
+
+func (r *FileSshCertificateRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.SshCertificate, error) {
+	entries, err := r.fileManager.ReadDir(SshCertificatesDirectory(r.filePath, organization))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []appmodels.SshCertificate{}, nil
+		}
+		return nil, fmt.Errorf("[SshCertificate:FindAllByOrganization]: %w", err)
+	}
+	list := make([]appmodels.SshCertificate, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		serial, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		model, err := r.FindByOrganizationAndSerial(organization, serial)
+		if err != nil {
+			return nil, fmt.Errorf("[SshCertificate:FindAllByOrganization]: %w", err)
+		}
+		list = append(list, model)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Serial() < list[j].Serial()
+	})
+	return list, nil
+}
+
+func (r *FileSshCertificateRepository) FindByOrganizationAndSerial(organization *big.Int, serial uint64) (appmodels.SshCertificate, error) {
+	data, err := r.fileManager.ReadFile(SshCertificateJsonPath(r.filePath, organization, serial))
+	if err != nil {
+		return nil, fmt.Errorf("[SshCertificate:FindByOrganizationAndSerial]: not found: %d@%s: %w", serial, organization, err)
+	}
+	dto := appdtos.SshCertificateRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[SshCertificate:FindByOrganizationAndSerial]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromSshCertificateRecordDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[SshCertificate:FindByOrganizationAndSerial]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *FileSshCertificateRepository) Save(model appmodels.SshCertificate) (appmodels.SshCertificate, error) {
+	data, err := json.MarshalIndent(apputils.ToSshCertificateRecordDTO(model), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("[SshCertificate:Save]: failed to marshal JSON: %w", err)
+	}
+	fileName := SshCertificateJsonPath(r.filePath, model.OrganizationID(), model.Serial())
+	if err := fsutils.SaveBytes(r.fileManager, fileName, data, 0600, 0700); err != nil {
+		return nil, fmt.Errorf("[SshCertificate:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewSshCertificateRepository creates a file based repository for signed SSH
+// certificates
+func NewSshCertificateRepository(
+	fileManager managers.FileManager,
+	filePath string,
+) *FileSshCertificateRepository {
+	return &FileSshCertificateRepository{
+		filePath:    filePath,
+		fileManager: fileManager,
+	}
+}
+
+var _ appmodels.SshCertificateRepository = (*FileSshCertificateRepository)(nil)
@@ -0,0 +1,74 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestSshCertificate(t *testing.T, organization *big.Int, serial uint64, revokedAt time.Time) appmodels.SshCertificate {
+	t.Helper()
+	signer, err := apputils.NewSshSigner(newTestSshAuthority(t, organization).PrivateKey())
+	require.NoError(t, err)
+	certificate := &ssh.Certificate{
+		Key:             signer.PublicKey(),
+		Serial:          serial,
+		CertType:        ssh.UserCert,
+		KeyId:           "alice",
+		ValidPrincipals: []string{"alice"},
+		ValidAfter:      1000,
+		ValidBefore:     2000,
+	}
+	require.NoError(t, certificate.SignCert(rand.Reader, signer))
+	return appmodels.NewSshCertificate(organization, certificate, revokedAt)
+}
+
+func TestSshCertificateRepository_SaveAndFind(t *testing.T) {
+	dir := t.TempDir()
+	repo := filerepository.NewSshCertificateRepository(managers.NewFileManager(), dir)
+	organization := big.NewInt(123)
+
+	list, err := repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	_, err = repo.Save(newTestSshCertificate(t, organization, 20, time.Time{}))
+	require.NoError(t, err)
+	_, err = repo.Save(newTestSshCertificate(t, organization, 3, time.Time{}))
+	require.NoError(t, err)
+
+	// Saving again replaces the certificate, e.g. when it is revoked
+	revokedAt := time.Unix(1500, 0).UTC()
+	_, err = repo.Save(newTestSshCertificate(t, organization, 20, revokedAt))
+	require.NoError(t, err)
+
+	found, err := repo.FindByOrganizationAndSerial(organization, 20)
+	require.NoError(t, err)
+	assert.True(t, found.IsRevoked())
+	assert.True(t, revokedAt.Equal(found.RevokedAt()))
+	assert.Equal(t, []string{"alice"}, found.Principals())
+
+	list, err = repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, uint64(3), list[0].Serial())
+	assert.Equal(t, uint64(20), list[1].Serial())
+
+	_, err = repo.FindByOrganizationAndSerial(organization, 4)
+	assert.ErrorContains(t, err, "not found")
+	list, err = repo.FindAllByOrganization(big.NewInt(124))
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
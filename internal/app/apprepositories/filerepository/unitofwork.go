@@ -0,0 +1,151 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/fsutils"
+)
+
+// FileUnitOfWorkRepository implements appmodels.UnitOfWorkRepository over the
+// file certificate and private key repositories
+type FileUnitOfWorkRepository struct {
+	certificates *FileCertificateRepository
+	keys         *FilePrivateKeyRepository
+}
+
+func (r *FileUnitOfWorkRepository) NewUnitOfWork() appmodels.UnitOfWork {
+	return &FileUnitOfWork{repository: r}
+}
+
+// FileUnitOfWork implements appmodels.UnitOfWork. Private keys are written
+// before certificates, so a certificate is never visible without its key. If
+// any write fails, the files written so far are restored.
+type FileUnitOfWork struct {
+	repository   *FileUnitOfWorkRepository
+	certificates []appmodels.Certificate
+	keys         []appmodels.PrivateKey
+	committed    bool
+}
+
+// fileBackup is the previous content of a file, or nil if it did not exist
+type fileBackup struct {
+	fileName string
+	data     []byte
+}
+
+func (w *FileUnitOfWork) SaveCertificate(certificate appmodels.Certificate) {
+	w.certificates = append(w.certificates, certificate)
+}
+
+func (w *FileUnitOfWork) SavePrivateKey(key appmodels.PrivateKey) {
+	w.keys = append(w.keys, key)
+}
+
+func (w *FileUnitOfWork) Commit() error {
+	if w.committed {
+		return errors.New("[UnitOfWork:Commit]: already committed")
+	}
+	w.committed = true
+
+	certificates := w.repository.certificates
+	keys := w.repository.keys
+
+	// Prepare everything before the first write
+	keyData := make([][]byte, 0, len(w.keys))
+	for _, key := range w.keys {
+		pemData, err := apputils.MarshalPrivateKeyAsPEM(keys.certManager, key.PrivateKey())
+		if err != nil {
+			return fmt.Errorf("[UnitOfWork:Commit]: failed to serialize private key to PEM: %w", err)
+		}
+		keyData = append(keyData, pemData)
+	}
+	for _, certificate := range w.certificates {
+		if certificate.Certificate() == nil {
+			return fmt.Errorf("[UnitOfWork:Commit]: no certificate data: %s", certificate.SerialNumber())
+		}
+	}
+
+	var backups []fileBackup
+	for i, key := range w.keys {
+		fileName := PrivateKeyPemPath(keys.filePath, key.OrganizationID(), key.SerialNumber())
+		backup, err := w.backup(fileName)
+		if err == nil {
+			backups = append(backups, backup)
+			err = fsutils.SaveBytes(keys.fileManager, fileName, keyData[i], 0600, 0700)
+		}
+		if err != nil {
+			return w.rollback(backups, fmt.Errorf("failed to save private key: %w", err))
+		}
+	}
+	for _, certificate := range w.certificates {
+		fileName := CertificatePemPath(certificates.filePath, certificate.OrganizationID(), certificate.SerialNumber())
+		backup, err := w.backup(fileName)
+		if err == nil {
+			backups = append(backups, backup)
+			_, err = certificates.Save(certificate)
+		}
+		if err != nil {
+			return w.rollback(backups, err)
+		}
+	}
+	return nil
+}
+
+// backup reads the current content of a file
+func (w *FileUnitOfWork) backup(fileName string) (fileBackup, error) {
+	data, err := w.repository.keys.fileManager.ReadFile(fileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fileBackup{fileName: fileName}, nil
+		}
+		return fileBackup{}, fmt.Errorf("failed to read '%s': %w", fileName, err)
+	}
+	return fileBackup{fileName: fileName, data: data}, nil
+}
+
+// rollback restores the backed up files in reverse order and drops the cached
+// certificate index, so that it is read again from the disk
+func (w *FileUnitOfWork) rollback(backups []fileBackup, cause error) error {
+	fileManager := w.repository.keys.fileManager
+	errs := []error{fmt.Errorf("[UnitOfWork:Commit]: %w", cause)}
+	for i := len(backups) - 1; i >= 0; i-- {
+		backup := backups[i]
+		var err error
+		if backup.data == nil {
+			err = fileManager.Remove(backup.fileName)
+			if errors.Is(err, fs.ErrNotExist) {
+				err = nil
+			}
+		} else {
+			err = fsutils.SaveBytes(fileManager, backup.fileName, backup.data, 0600, 0700)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore '%s': %w", backup.fileName, err))
+		}
+	}
+	certificates := w.repository.certificates
+	certificates.mutex.Lock()
+	certificates.index = nil
+	certificates.mutex.Unlock()
+	return errors.Join(errs...)
+}
+
+// NewUnitOfWorkRepository creates units of work over file repositories
+func NewUnitOfWorkRepository(
+	certificates *FileCertificateRepository,
+	keys *FilePrivateKeyRepository,
+) *FileUnitOfWorkRepository {
+	return &FileUnitOfWorkRepository{
+		certificates: certificates,
+		keys:         keys,
+	}
+}
+
+var _ appmodels.UnitOfWorkRepository = (*FileUnitOfWorkRepository)(nil)
+var _ appmodels.UnitOfWork = (*FileUnitOfWork)(nil)
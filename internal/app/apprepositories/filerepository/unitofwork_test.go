@@ -0,0 +1,69 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository_test
+
+import (
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+)
+
+func newTestUnitOfWorkRepository(t *testing.T) (*filerepository.FileCertificateRepository, *filerepository.FilePrivateKeyRepository, *filerepository.FileUnitOfWorkRepository, string) {
+	t.Helper()
+	tempDir, cleanup := setupTempDir(t)
+	t.Cleanup(cleanup)
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	fileManager := managers.NewFileManager()
+	certificates := filerepository.NewCertificateRepository(certManager, fileManager, tempDir)
+	keys := filerepository.NewPrivateKeyRepository(certManager, fileManager, tempDir)
+	return certificates, keys, filerepository.NewUnitOfWorkRepository(certificates, keys), tempDir
+}
+
+func TestUnitOfWork_Commit(t *testing.T) {
+	certificates, keys, repo, _ := newTestUnitOfWorkRepository(t)
+	organization := big.NewInt(123)
+	root, rootKey := newTestChainCertificate(t, 1, true, nil, nil)
+
+	work := repo.NewUnitOfWork()
+	work.SaveCertificate(appmodels.NewCertificate(organization, nil, root))
+	work.SavePrivateKey(appmodels.NewPrivateKey(organization, big.NewInt(1), appmodels.RSA_2048, rootKey))
+	require.NoError(t, work.Commit())
+
+	cert, err := certificates.FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	require.NoError(t, err)
+	assert.Nil(t, cert.SignedBy())
+	_, err = keys.FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	assert.NoError(t, err)
+
+	assert.ErrorContains(t, work.Commit(), "already committed")
+}
+
+func TestUnitOfWork_CommitFailureRestoresFiles(t *testing.T) {
+	certificates, _, repo, tempDir := newTestUnitOfWorkRepository(t)
+	organization := big.NewInt(123)
+	root, rootKey := newTestChainCertificate(t, 1, true, nil, nil)
+
+	// A directory in place of the certificate file makes the write fail
+	require.NoError(t, os.MkdirAll(filerepository.CertificatePemPath(tempDir, organization, big.NewInt(1)), 0700))
+
+	work := repo.NewUnitOfWork()
+	work.SaveCertificate(appmodels.NewCertificate(organization, nil, root))
+	work.SavePrivateKey(appmodels.NewPrivateKey(organization, big.NewInt(1), appmodels.RSA_2048, rootKey))
+	assert.Error(t, work.Commit())
+
+	_, err := os.Stat(filerepository.PrivateKeyPemPath(tempDir, organization, big.NewInt(1)))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.Remove(filerepository.CertificatePemPath(tempDir, organization, big.NewInt(1))))
+	list, err := certificates.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
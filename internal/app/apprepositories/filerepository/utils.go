@@ -110,3 +110,35 @@ func ReadPrivateKeyFile(
 
 	return privateKey, keyType, nil
 }
+
+// SaveCertificateIndexFile marshals the certificate index into JSON and saves it
+func SaveCertificateIndexFile(
+	fileManager managers.FileManager,
+	fileName string,
+	index *CertificateIndex,
+) error {
+	jsonData, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal certificate index into JSON: %w", err)
+	}
+	return fsutils.SaveBytes(fileManager, fileName, jsonData, 0600, 0700)
+}
+
+// ReadCertificateIndexFile reads the certificate index from a JSON file
+func ReadCertificateIndexFile(fileManager managers.FileManager, fileName string) (*CertificateIndex, error) {
+
+	fileData, err := fileManager.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate index file: %w", err)
+	}
+
+	index := NewCertificateIndex()
+	if err := json.Unmarshal(fileData, index); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal certificate index JSON data: %w", err)
+	}
+	if index.Organizations == nil {
+		index.Organizations = make(map[string]map[string]string)
+	}
+
+	return index, nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package filerepository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/fsutils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// FileVaultRoleRepository implements appmodels.VaultRoleRepository for a
+// file system. Each role is a JSON file named by the role in the
+// organization directory.
+type FileVaultRoleRepository struct {
+	filePath    string
+	fileManager managers.FileManager
+}
+
+func (r *FileVaultRoleRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.VaultRole, error) {
+	entries, err := r.fileManager.ReadDir(VaultRolesDirectory(r.filePath, organization))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []appmodels.VaultRole{}, nil
+		}
+		return nil, fmt.Errorf("[VaultRole:FindAllByOrganization]: %w", err)
+	}
+	list := make([]appmodels.VaultRole, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || apputils.ValidateVaultRoleName(name) != nil {
+			continue
+		}
+		model, err := r.FindByOrganizationAndName(organization, name)
+		if err != nil {
+			return nil, fmt.Errorf("[VaultRole:FindAllByOrganization]: %w", err)
+		}
+		list = append(list, model)
+	}
+	return list, nil
+}
+
+func (r *FileVaultRoleRepository) FindByOrganizationAndName(organization *big.Int, name string) (appmodels.VaultRole, error) {
+	if err := apputils.ValidateVaultRoleName(name); err != nil {
+		return nil, fmt.Errorf("[VaultRole:FindByOrganizationAndName]: invalid name: %q: %w", name, err)
+	}
+	data, err := r.fileManager.ReadFile(VaultRoleJsonPath(r.filePath, organization, name))
+	if err != nil {
+		return nil, fmt.Errorf("[VaultRole:FindByOrganizationAndName]: not found: %s@%s: %w", name, organization, err)
+	}
+	dto := appdtos.VaultRoleRecordDTO{}
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("[VaultRole:FindByOrganizationAndName]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromVaultRoleRecordDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[VaultRole:FindByOrganizationAndName]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *FileVaultRoleRepository) Save(model appmodels.VaultRole) (appmodels.VaultRole, error) {
+	if err := apputils.ValidateVaultRoleName(model.Name()); err != nil {
+		return nil, fmt.Errorf("[VaultRole:Save]: invalid name: %q: %w", model.Name(), err)
+	}
+	data, err := json.MarshalIndent(apputils.ToVaultRoleRecordDTO(model), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("[VaultRole:Save]: failed to marshal JSON: %w", err)
+	}
+	if err := fsutils.SaveBytes(r.fileManager, VaultRoleJsonPath(r.filePath, model.OrganizationID(), model.Name()), data, 0600, 0700); err != nil {
+		return nil, fmt.Errorf("[VaultRole:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+func (r *FileVaultRoleRepository) Delete(organization *big.Int, name string) error {
+	if err := apputils.ValidateVaultRoleName(name); err != nil {
+		return fmt.Errorf("[VaultRole:Delete]: invalid name: %q: %w", name, err)
+	}
+	if err := r.fileManager.Remove(VaultRoleJsonPath(r.filePath, organization, name)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("[VaultRole:Delete]: not found: %s@%s", name, organization)
+		}
+		return fmt.Errorf("[VaultRole:Delete]: %w", err)
+	}
+	return nil
+}
+
+// NewVaultRoleRepository creates a file based repository for Vault PKI roles
+func NewVaultRoleRepository(
+	fileManager managers.FileManager,
+	filePath string,
+) *FileVaultRoleRepository {
+	return &FileVaultRoleRepository{
+		filePath:    filePath,
+		fileManager: fileManager,
+	}
+}
+
+var _ appmodels.VaultRoleRepository = (*FileVaultRoleRepository)(nil)
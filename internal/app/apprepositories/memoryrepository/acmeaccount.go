@@ -0,0 +1,83 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MemoryAcmeAccountRepository implements appmodels.AcmeAccountRepository in a memory
+type MemoryAcmeAccountRepository struct {
+	mu       sync.RWMutex
+	accounts map[string]appmodels.AcmeAccount
+}
+
+func (r *MemoryAcmeAccountRepository) FindById(id string) (appmodels.AcmeAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if account, exists := r.accounts[id]; exists {
+		return account, nil
+	}
+	return nil, fmt.Errorf("[AcmeAccount:FindById]: not found: %s", id)
+}
+
+func (r *MemoryAcmeAccountRepository) FindByThumbprint(organization *big.Int, issuer *big.Int, thumbprint string) (appmodels.AcmeAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, account := range r.accounts {
+		if account.Thumbprint() == thumbprint &&
+			account.OrganizationID().Cmp(organization) == 0 &&
+			account.IssuerSerialNumber().Cmp(issuer) == 0 {
+			return account, nil
+		}
+	}
+	return nil, fmt.Errorf("[AcmeAccount:FindByThumbprint]: not found: %s", thumbprint)
+}
+
+func (r *MemoryAcmeAccountRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.AcmeAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]appmodels.AcmeAccount, 0)
+	for _, account := range r.accounts {
+		if account.OrganizationID().Cmp(organization) == 0 {
+			list = append(list, account)
+		}
+	}
+	sortAcmeAccounts(list)
+	return list, nil
+}
+
+func (r *MemoryAcmeAccountRepository) Save(account appmodels.AcmeAccount) (appmodels.AcmeAccount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := account.ID()
+	r.accounts[id] = account
+	log.Printf("[AcmeAccount:Save:%s] Saved", id)
+	return account, nil
+}
+
+// sortAcmeAccounts sorts accounts in the order they were created
+func sortAcmeAccounts(list []appmodels.AcmeAccount) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt().Equal(list[j].CreatedAt()) {
+			return list[i].ID() < list[j].ID()
+		}
+		return list[i].CreatedAt().Before(list[j].CreatedAt())
+	})
+}
+
+// NewAcmeAccountRepository creates a memory based repository for ACME accounts
+func NewAcmeAccountRepository() *MemoryAcmeAccountRepository {
+	return &MemoryAcmeAccountRepository{
+		accounts: make(map[string]appmodels.AcmeAccount),
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.AcmeAccountRepository = (*MemoryAcmeAccountRepository)(nil)
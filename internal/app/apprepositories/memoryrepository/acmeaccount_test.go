@@ -0,0 +1,57 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func TestAcmeAccountRepository_SaveAndFind(t *testing.T) {
+	repo := memoryrepository.NewAcmeAccountRepository()
+	account := appmodels.NewAcmeAccount("acc1", big.NewInt(1), big.NewInt(2), appmodels.ACME_STATUS_VALID, nil, nil, "thumb", time.Now())
+
+	_, err := repo.Save(account)
+	assert.NoError(t, err)
+
+	found, err := repo.FindById("acc1")
+	assert.NoError(t, err)
+	assert.Equal(t, account, found)
+
+	found, err = repo.FindByThumbprint(big.NewInt(1), big.NewInt(2), "thumb")
+	assert.NoError(t, err)
+	assert.Equal(t, account, found)
+
+	_, err = repo.FindByThumbprint(big.NewInt(1), big.NewInt(3), "thumb")
+	assert.Error(t, err)
+
+	_, err = repo.FindById("acc2")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ": not found:")
+}
+
+func TestAcmeAccountRepository_FindAllByOrganization(t *testing.T) {
+	repo := memoryrepository.NewAcmeAccountRepository()
+	now := time.Now()
+	second := appmodels.NewAcmeAccount("a", big.NewInt(1), big.NewInt(2), appmodels.ACME_STATUS_VALID, nil, nil, "thumb1", now.Add(time.Minute))
+	first := appmodels.NewAcmeAccount("b", big.NewInt(1), big.NewInt(3), appmodels.ACME_STATUS_VALID, nil, nil, "thumb2", now)
+	other := appmodels.NewAcmeAccount("c", big.NewInt(2), big.NewInt(4), appmodels.ACME_STATUS_VALID, nil, nil, "thumb3", now)
+	for _, account := range []appmodels.AcmeAccount{second, first, other} {
+		_, err := repo.Save(account)
+		assert.NoError(t, err)
+	}
+
+	list, err := repo.FindAllByOrganization(big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []appmodels.AcmeAccount{first, second}, list)
+
+	list, err = repo.FindAllByOrganization(big.NewInt(3))
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MemoryAcmeAuthorizationRepository implements appmodels.AcmeAuthorizationRepository in a memory
+type MemoryAcmeAuthorizationRepository struct {
+	mu             sync.RWMutex
+	authorizations map[string]appmodels.AcmeAuthorization
+}
+
+func (r *MemoryAcmeAuthorizationRepository) FindById(id string) (appmodels.AcmeAuthorization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if authorization, exists := r.authorizations[id]; exists {
+		return authorization, nil
+	}
+	return nil, fmt.Errorf("[AcmeAuthorization:FindById]: not found: %s", id)
+}
+
+func (r *MemoryAcmeAuthorizationRepository) Save(authorization appmodels.AcmeAuthorization) (appmodels.AcmeAuthorization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := authorization.ID()
+	r.authorizations[id] = authorization
+	log.Printf("[AcmeAuthorization:Save:%s] Saved: %s", id, authorization.Status())
+	return authorization, nil
+}
+
+// NewAcmeAuthorizationRepository creates a memory based repository for ACME authorizations
+func NewAcmeAuthorizationRepository() *MemoryAcmeAuthorizationRepository {
+	return &MemoryAcmeAuthorizationRepository{
+		authorizations: make(map[string]appmodels.AcmeAuthorization),
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.AcmeAuthorizationRepository = (*MemoryAcmeAuthorizationRepository)(nil)
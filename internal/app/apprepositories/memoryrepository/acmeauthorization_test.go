@@ -0,0 +1,28 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func TestAcmeAuthorizationRepository_SaveAndFind(t *testing.T) {
+	repo := memoryrepository.NewAcmeAuthorizationRepository()
+	authorization := appmodels.NewAcmeAuthorization("authz1", "acc1", "order1", appmodels.ACME_STATUS_PENDING, time.Now(), "example.com", false, nil)
+
+	_, err := repo.Save(authorization)
+	assert.NoError(t, err)
+
+	found, err := repo.FindById("authz1")
+	assert.NoError(t, err)
+	assert.Equal(t, authorization, found)
+
+	_, err = repo.FindById("authz2")
+	assert.Error(t, err)
+}
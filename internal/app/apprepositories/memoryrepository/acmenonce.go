@@ -0,0 +1,65 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MaxAcmeNonces is the number of unused nonces kept in memory. The oldest
+// nonce is forgotten when the limit is reached.
+const MaxAcmeNonces = 10000
+
+// MemoryAcmeNonceRepository implements appmodels.AcmeNonceRepository in a memory
+type MemoryAcmeNonceRepository struct {
+	mu     sync.Mutex
+	nonces map[string]struct{}
+	queue  []string
+	limit  int
+}
+
+func (r *MemoryAcmeNonceRepository) Save(nonce string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for len(r.queue) >= r.limit {
+		delete(r.nonces, r.queue[0])
+		r.queue = r.queue[1:]
+	}
+	r.nonces[nonce] = struct{}{}
+	r.queue = append(r.queue, nonce)
+	return nil
+}
+
+func (r *MemoryAcmeNonceRepository) Delete(nonce string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.nonces[nonce]; !exists {
+		return fmt.Errorf("[AcmeNonce:Delete]: not found: %s", nonce)
+	}
+	delete(r.nonces, nonce)
+	for i, item := range r.queue {
+		if item == nonce {
+			r.queue = append(r.queue[:i], r.queue[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// NewAcmeNonceRepository creates a memory based repository for ACME nonces
+//   - limit: The number of unused nonces to keep, or 0 for MaxAcmeNonces
+func NewAcmeNonceRepository(limit int) *MemoryAcmeNonceRepository {
+	if limit <= 0 {
+		limit = MaxAcmeNonces
+	}
+	return &MemoryAcmeNonceRepository{
+		nonces: make(map[string]struct{}),
+		limit:  limit,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.AcmeNonceRepository = (*MemoryAcmeNonceRepository)(nil)
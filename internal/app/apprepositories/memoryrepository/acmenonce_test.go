@@ -0,0 +1,35 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func TestAcmeNonceRepository_SaveAndDelete(t *testing.T) {
+	repo := memoryrepository.NewAcmeNonceRepository(0)
+
+	assert.NoError(t, repo.Save("nonce1"))
+	assert.NoError(t, repo.Delete("nonce1"))
+
+	// A nonce can only be used once
+	assert.Error(t, repo.Delete("nonce1"))
+	assert.Error(t, repo.Delete("unknown"))
+}
+
+func TestAcmeNonceRepository_Limit(t *testing.T) {
+	repo := memoryrepository.NewAcmeNonceRepository(2)
+
+	assert.NoError(t, repo.Save("nonce1"))
+	assert.NoError(t, repo.Save("nonce2"))
+	assert.NoError(t, repo.Save("nonce3"))
+
+	// The oldest nonce was forgotten
+	assert.Error(t, repo.Delete("nonce1"))
+	assert.NoError(t, repo.Delete("nonce2"))
+	assert.NoError(t, repo.Delete("nonce3"))
+}
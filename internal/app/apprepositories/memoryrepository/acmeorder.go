@@ -0,0 +1,57 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MemoryAcmeOrderRepository implements appmodels.AcmeOrderRepository in a memory
+type MemoryAcmeOrderRepository struct {
+	mu     sync.RWMutex
+	orders map[string]appmodels.AcmeOrder
+}
+
+func (r *MemoryAcmeOrderRepository) FindById(id string) (appmodels.AcmeOrder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if order, exists := r.orders[id]; exists {
+		return order, nil
+	}
+	return nil, fmt.Errorf("[AcmeOrder:FindById]: not found: %s", id)
+}
+
+func (r *MemoryAcmeOrderRepository) FindAllByAccount(account string) ([]appmodels.AcmeOrder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var result []appmodels.AcmeOrder
+	for _, order := range r.orders {
+		if order.AccountID() == account {
+			result = append(result, order)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryAcmeOrderRepository) Save(order appmodels.AcmeOrder) (appmodels.AcmeOrder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := order.ID()
+	r.orders[id] = order
+	log.Printf("[AcmeOrder:Save:%s] Saved: %s", id, order.Status())
+	return order, nil
+}
+
+// NewAcmeOrderRepository creates a memory based repository for ACME orders
+func NewAcmeOrderRepository() *MemoryAcmeOrderRepository {
+	return &MemoryAcmeOrderRepository{
+		orders: make(map[string]appmodels.AcmeOrder),
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.AcmeOrderRepository = (*MemoryAcmeOrderRepository)(nil)
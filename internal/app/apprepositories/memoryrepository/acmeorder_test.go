@@ -0,0 +1,36 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func TestAcmeOrderRepository_SaveAndFind(t *testing.T) {
+	repo := memoryrepository.NewAcmeOrderRepository()
+	order1 := appmodels.NewAcmeOrder("order1", "acc1", appmodels.ACME_STATUS_PENDING, time.Now(), []string{"example.com"}, nil, nil, nil)
+	order2 := appmodels.NewAcmeOrder("order2", "acc2", appmodels.ACME_STATUS_PENDING, time.Now(), []string{"example.org"}, nil, nil, nil)
+
+	_, err := repo.Save(order1)
+	assert.NoError(t, err)
+	_, err = repo.Save(order2)
+	assert.NoError(t, err)
+
+	found, err := repo.FindById("order1")
+	assert.NoError(t, err)
+	assert.Equal(t, order1, found)
+
+	list, err := repo.FindAllByAccount("acc2")
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, order2, list[0])
+
+	_, err = repo.FindById("order3")
+	assert.Error(t, err)
+}
@@ -7,61 +7,254 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
 )
 
 // MemoryCertificateRepository implements models.CertificateRepository in a memory
 // @implements models.CertificateRepository
 type MemoryCertificateRepository struct {
-	certificates map[string]appmodels.Certificate
+	mu sync.RWMutex
+
+	// certificates are indexed by the organization and the serial number
+	certificates map[string]map[string]appmodels.Certificate
+
+	// signedBy indexes serial numbers by the organization and the serial
+	// number of the issuer. Root certificates have an empty issuer.
+	signedBy map[string]map[string]map[string]struct{}
+
+	// notAfter lists the certificates of each organization in expiration
+	// order
+	notAfter map[string][]appmodels.Certificate
+
+	// archived holds the archived certificates by the organization and the
+	// serial number
+	archived map[string]map[string]appmodels.Certificate
 }
 
 func (r *MemoryCertificateRepository) FindAllByOrganizationAndSignedBy(organization *big.Int, certificate *big.Int) ([]appmodels.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	var result []appmodels.Certificate
 	if r.certificates == nil {
 		return result, nil
 	}
-	for _, cert := range r.certificates {
-		if cert.OrganizationID() == organization && certificate == cert.SignedBy() {
-			result = append(result, cert)
-		}
+	org := organization.String()
+	for serial := range r.signedBy[org][issuerLocator(certificate)] {
+		result = append(result, copyCertificate(r.certificates[org][serial]))
 	}
+	sortCertificatesBySerialNumber(result)
 	return result, nil
 }
 
 func (r *MemoryCertificateRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	if r.certificates == nil {
 		return nil, errors.New("[Certificate:FindAllByOrganization]: not initialized")
 	}
-	var result []appmodels.Certificate
-	for _, cert := range r.certificates {
-		if cert.OrganizationID() == organization {
-			result = append(result, cert)
-		}
+	certificates := r.certificates[organization.String()]
+	result := make([]appmodels.Certificate, 0, len(certificates))
+	for _, cert := range certificates {
+		result = append(result, copyCertificate(cert))
 	}
+	sortCertificatesBySerialNumber(result)
 	return result, nil
 }
 
+// FindAllByOrganizationAndNotAfterBefore returns certificates of an
+// organization which expire before the given time, the earliest first
+func (r *MemoryCertificateRepository) FindAllByOrganizationAndNotAfterBefore(organization *big.Int, before time.Time) ([]appmodels.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := r.notAfter[organization.String()]
+	end := sort.Search(len(list), func(i int) bool {
+		return !list[i].NotAfter().Before(before)
+	})
+	result := make([]appmodels.Certificate, 0, end)
+	for _, cert := range list[:end] {
+		result = append(result, copyCertificate(cert))
+	}
+	return result, nil
+}
+
+// FindPageByOrganization returns a page of certificates of an organization.
+// The serial number, issuer and expiration indexes narrow down the candidates
+// before the remaining filters are applied. The archive is not indexed.
+func (r *MemoryCertificateRepository) FindPageByOrganization(organization *big.Int, query appmodels.CertificateQuery) (appmodels.CertificatePage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	org := organization.String()
+	var candidates []appmodels.Certificate
+	if query.Archived {
+		for _, cert := range r.archived[org] {
+			candidates = append(candidates, cert)
+		}
+	} else if query.SerialNumber != nil {
+		if cert, exists := r.certificates[org][query.SerialNumber.String()]; exists {
+			candidates = append(candidates, cert)
+		}
+	} else if query.SignedBy != nil {
+		for serial := range r.signedBy[org][issuerLocator(query.SignedBy)] {
+			candidates = append(candidates, r.certificates[org][serial])
+		}
+	} else if !query.ExpiringBefore.IsZero() {
+		list := r.notAfter[org]
+		end := sort.Search(len(list), func(i int) bool {
+			return !list[i].NotAfter().Before(query.ExpiringBefore)
+		})
+		candidates = list[:end]
+	} else {
+		for _, cert := range r.certificates[org] {
+			candidates = append(candidates, cert)
+		}
+	}
+	page := apputils.QueryCertificates(candidates, query)
+	for i, cert := range page.Certificates {
+		page.Certificates[i] = copyCertificate(cert)
+	}
+	return page, nil
+}
+
 func (r *MemoryCertificateRepository) FindByOrganizationAndSerialNumber(organization *big.Int, certificate *big.Int) (appmodels.Certificate, error) {
-	id := getCertificateLocator(organization, certificate)
-	if certificate, exists := r.certificates[id]; exists {
-		return certificate, nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if certificate, exists := r.certificates[organization.String()][certificate.String()]; exists {
+		return copyCertificate(certificate), nil
 	}
-	return nil, fmt.Errorf("[Certificate:FindByOrganizationAndSerialNumber]: not found: %s", id)
+	return nil, fmt.Errorf("[Certificate:FindByOrganizationAndSerialNumber]: not found: %s", getCertificateLocator(organization, certificate))
 }
 
 func (r *MemoryCertificateRepository) Save(certificate appmodels.Certificate) (appmodels.Certificate, error) {
-	id := getCertificateLocator(certificate.OrganizationID(), certificate.SerialNumber())
-	r.certificates[id] = certificate
-	log.Printf("[Certificate:Save:%s] Saved: %v", id, certificate)
-	return certificate, nil
+	model, err := prepareCertificate(certificate)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.save(model); err != nil {
+		return nil, err
+	}
+	return copyCertificate(model), nil
+}
+
+func (r *MemoryCertificateRepository) Archive(organization *big.Int, certificate *big.Int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	org := organization.String()
+	serial := certificate.String()
+	model, exists := r.certificates[org][serial]
+	if !exists {
+		return fmt.Errorf("[Certificate:Archive]: not found: %s", getCertificateLocator(organization, certificate))
+	}
+	r.removeIndexes(org, serial, model)
+	delete(r.certificates[org], serial)
+	if _, exists := r.archived[org]; !exists {
+		r.archived[org] = make(map[string]appmodels.Certificate)
+	}
+	r.archived[org][serial] = model
+	log.Printf("[Certificate:Archive:%s/%s] Archived", org, serial)
+	return nil
+}
+
+// prepareCertificate returns a copy of the certificate to save, or an error
+// if it cannot be saved
+func prepareCertificate(certificate appmodels.Certificate) (appmodels.Certificate, error) {
+	model := copyCertificate(certificate)
+	if model.Certificate() == nil {
+		return nil, fmt.Errorf("[Certificate:Save:%s]: no certificate data", model.OrganizationID())
+	}
+	if serialNumber := certificate.SerialNumber(); serialNumber == nil || serialNumber.Cmp(model.SerialNumber()) != 0 {
+		return nil, fmt.Errorf("[Certificate:Save:%s/%s]: serial number does not match the certificate data", model.OrganizationID(), model.SerialNumber())
+	}
+	return model, nil
+}
+
+// save stores a prepared certificate and updates the indexes. The caller must
+// hold the write lock.
+func (r *MemoryCertificateRepository) save(model appmodels.Certificate) error {
+	if r.certificates == nil {
+		return errors.New("[Certificate:Save]: not initialized")
+	}
+
+	org := model.OrganizationID().String()
+	serial := model.SerialNumber().String()
+
+	if previous, exists := r.certificates[org][serial]; exists {
+		r.removeIndexes(org, serial, previous)
+	}
+
+	if _, exists := r.certificates[org]; !exists {
+		r.certificates[org] = make(map[string]appmodels.Certificate)
+		r.signedBy[org] = make(map[string]map[string]struct{})
+	}
+	r.certificates[org][serial] = model
+
+	issuer := issuerLocator(model.SignedBy())
+	if _, exists := r.signedBy[org][issuer]; !exists {
+		r.signedBy[org][issuer] = make(map[string]struct{})
+	}
+	r.signedBy[org][issuer][serial] = struct{}{}
+
+	list := r.notAfter[org]
+	i := sort.Search(len(list), func(i int) bool {
+		return compareExpiration(list[i], model) >= 0
+	})
+	list = append(list, nil)
+	copy(list[i+1:], list[i:])
+	list[i] = model
+	r.notAfter[org] = list
+
+	log.Printf("[Certificate:Save:%s/%s] Saved", org, serial)
+	return nil
+}
+
+// removeIndexes removes a stored certificate from the secondary indexes. The
+// caller must hold the write lock.
+func (r *MemoryCertificateRepository) removeIndexes(org, serial string, previous appmodels.Certificate) {
+	issuer := issuerLocator(previous.SignedBy())
+	delete(r.signedBy[org][issuer], serial)
+	if len(r.signedBy[org][issuer]) == 0 {
+		delete(r.signedBy[org], issuer)
+	}
+	list := r.notAfter[org]
+	for i, cert := range list {
+		if cert == previous {
+			r.notAfter[org] = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+}
+
+// compareExpiration orders certificates by expiration time and then by serial
+// number
+func compareExpiration(a, b appmodels.Certificate) int {
+	if a.NotAfter().Before(b.NotAfter()) {
+		return -1
+	}
+	if a.NotAfter().After(b.NotAfter()) {
+		return 1
+	}
+	return a.SerialNumber().Cmp(b.SerialNumber())
+}
+
+func sortCertificatesBySerialNumber(list []appmodels.Certificate) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].SerialNumber().Cmp(list[j].SerialNumber()) < 0
+	})
 }
 
 // NewCertificateRepository creates a memory based repository for certificates
 func NewCertificateRepository() *MemoryCertificateRepository {
 	return &MemoryCertificateRepository{
-		certificates: make(map[string]appmodels.Certificate),
+		certificates: make(map[string]map[string]appmodels.Certificate),
+		signedBy:     make(map[string]map[string]map[string]struct{}),
+		notAfter:     make(map[string][]appmodels.Certificate),
+		archived:     make(map[string]map[string]appmodels.Certificate),
 	}
 }
 
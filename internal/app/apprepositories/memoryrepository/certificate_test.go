@@ -3,17 +3,40 @@
 package memoryrepository_test
 
 import (
+	"crypto/x509"
 	"math/big"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
 	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
 	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
 )
 
+func serialNumbersOf(list []appmodels.Certificate) []*big.Int {
+	result := make([]*big.Int, 0, len(list))
+	for _, cert := range list {
+		result = append(result, cert.SerialNumber())
+	}
+	return result
+}
+
+// newTestX509Certificate creates a parsed certificate with only the fields
+// which the repository reads
+func newTestX509Certificate(serialNumber *big.Int, notAfter time.Time) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		NotAfter:     notAfter,
+	}
+}
+
 func TestCertificateRepository_CreateAndGetCertificate(t *testing.T) {
 	organization := big.NewInt(123)
 	repo := memoryrepository.NewCertificateRepository()
@@ -23,6 +46,8 @@ func TestCertificateRepository_CreateAndGetCertificate(t *testing.T) {
 	// Setting up expectations
 	mockCert.On("SerialNumber").Return(serialNumber)
 	mockCert.On("OrganizationID").Return(organization)
+	mockCert.On("SignedBy").Return((*big.Int)(nil))
+	mockCert.On("Certificate").Return(newTestX509Certificate(serialNumber, time.Now()))
 
 	// Test Save
 	_, err := repo.Save(mockCert)
@@ -61,10 +86,12 @@ func TestCertificateRepository_FindAllByOrganizationAndSignedBy(t *testing.T) {
 	mockCert1.On("SerialNumber").Return(serialNumber1)
 	mockCert1.On("OrganizationID").Return(organization)
 	mockCert1.On("SignedBy").Return(signedBy1)
+	mockCert1.On("Certificate").Return(newTestX509Certificate(serialNumber1, time.Now()))
 
 	mockCert2.On("SerialNumber").Return(serialNumber2)
 	mockCert2.On("OrganizationID").Return(organization)
 	mockCert2.On("SignedBy").Return(signedBy2)
+	mockCert2.On("Certificate").Return(newTestX509Certificate(serialNumber2, time.Now()))
 
 	// Test Save
 	_, err1 := repo.Save(mockCert1)
@@ -94,9 +121,13 @@ func TestCertificateRepository_FindAllByOrganization(t *testing.T) {
 	// Setting up expectations
 	mockCert1.On("SerialNumber").Return(serialNumber1)
 	mockCert1.On("OrganizationID").Return(organization)
+	mockCert1.On("SignedBy").Return((*big.Int)(nil))
+	mockCert1.On("Certificate").Return(newTestX509Certificate(serialNumber1, time.Now()))
 
 	mockCert2.On("SerialNumber").Return(serialNumber2)
 	mockCert2.On("OrganizationID").Return(organization)
+	mockCert2.On("SignedBy").Return((*big.Int)(nil))
+	mockCert2.On("Certificate").Return(newTestX509Certificate(serialNumber2, time.Now()))
 
 	// Test Save
 	_, err1 := repo.Save(mockCert1)
@@ -147,3 +178,258 @@ func TestCertificateRepository_FindAllByOrganization_WithNilCertificates(t *test
 	assert.Error(t, err, "[Certificate:FindAllByOrganization]: not initialized")
 	assert.Contains(t, err.Error(), "[Certificate:FindAllByOrganization]: not initialized", "Error message should indicate that the repository is not initialized")
 }
+
+func TestCertificateRepository_ValueComparison(t *testing.T) {
+	repo := memoryrepository.NewCertificateRepository()
+	root := appmodels.NewCertificate(big.NewInt(123), nil, newTestX509Certificate(big.NewInt(1), time.Now().Add(2*time.Hour)))
+	leaf2 := appmodels.NewCertificate(big.NewInt(123), big.NewInt(1), newTestX509Certificate(big.NewInt(20), time.Now().Add(time.Hour)))
+	leaf1 := appmodels.NewCertificate(big.NewInt(123), big.NewInt(1), newTestX509Certificate(big.NewInt(3), time.Now().Add(3*time.Hour)))
+	for _, cert := range []appmodels.Certificate{root, leaf2, leaf1} {
+		_, err := repo.Save(cert)
+		assert.NoError(t, err)
+	}
+
+	// Freshly parsed integers are different pointers with equal values
+	organization, _ := new(big.Int).SetString("123", 10)
+	issuer, _ := new(big.Int).SetString("1", 10)
+
+	list, err := repo.FindAllByOrganization(organization)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "3", "20"}, serialNumberStrings(list))
+
+	list, err = repo.FindAllByOrganizationAndSignedBy(organization, issuer)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3", "20"}, serialNumberStrings(list))
+
+	list, err = repo.FindAllByOrganizationAndSignedBy(organization, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, serialNumberStrings(list))
+
+	list, err = repo.FindAllByOrganizationAndNotAfterBefore(organization, time.Now().Add(150*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"20", "1"}, serialNumberStrings(list))
+
+	// Saving again with another issuer and expiration updates the indexes
+	_, err = repo.Save(appmodels.NewCertificate(big.NewInt(123), nil, newTestX509Certificate(big.NewInt(20), time.Now().Add(4*time.Hour))))
+	assert.NoError(t, err)
+	list, err = repo.FindAllByOrganizationAndSignedBy(organization, issuer)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3"}, serialNumberStrings(list))
+	list, err = repo.FindAllByOrganizationAndNotAfterBefore(organization, time.Now().Add(150*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, serialNumberStrings(list))
+	list, err = repo.FindAllByOrganization(organization)
+	assert.NoError(t, err)
+	assert.Len(t, list, 3)
+}
+
+func TestCertificateRepository_DefensiveCopies(t *testing.T) {
+	repo := memoryrepository.NewCertificateRepository()
+	organization := big.NewInt(123)
+	serialNumber := big.NewInt(1)
+	cert := newTestX509Certificate(serialNumber, time.Now())
+	saved, err := repo.Save(appmodels.NewCertificate(organization, nil, cert))
+	assert.NoError(t, err)
+
+	// Modifying the saved or returned values does not change the repository
+	organization.SetInt64(999)
+	cert.SerialNumber.SetInt64(2)
+	saved.SerialNumber().SetInt64(3)
+
+	found, err := repo.FindByOrganizationAndSerialNumber(big.NewInt(123), big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "123", found.OrganizationID().String())
+	assert.Equal(t, "1", found.SerialNumber().String())
+}
+
+func TestCertificateRepository_Concurrency(t *testing.T) {
+	repo := memoryrepository.NewCertificateRepository()
+	organization := big.NewInt(123)
+	var wg sync.WaitGroup
+	for i := int64(1); i <= 50; i++ {
+		wg.Add(1)
+		go func(serial int64) {
+			defer wg.Done()
+			_, err := repo.Save(appmodels.NewCertificate(organization, big.NewInt(1), newTestX509Certificate(big.NewInt(serial), time.Now())))
+			assert.NoError(t, err)
+			_, err = repo.FindAllByOrganization(organization)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	list, err := repo.FindAllByOrganizationAndSignedBy(organization, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Len(t, list, 50)
+}
+
+func serialNumberStrings(list []appmodels.Certificate) []string {
+	result := make([]string, 0, len(list))
+	for _, cert := range list {
+		result = append(result, cert.SerialNumber().String())
+	}
+	return result
+}
+
+func TestMemoryCertificateRepository_FindPageByOrganization(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	collection := memoryrepository.NewCollection()
+	appController := appcontrollers.NewApplicationController(
+		collection.Organization,
+		collection.Certificate,
+		collection.PrivateKey,
+		collection.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization := big.NewInt(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	organizationController.SetExpirationDuration(24 * time.Hour)
+
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	intermediate, _, err := rootController.NewIntermediateCertificate("Test Intermediate")
+	require.NoError(t, err)
+	serverB, _, err := rootController.NewServerCertificate("b.example.com")
+	require.NoError(t, err)
+	serverA, _, err := rootController.NewServerCertificate("a.example.com")
+	require.NoError(t, err)
+	client, _, err := rootController.NewClientCertificate("alice")
+	require.NoError(t, err)
+
+	repo := collection.Certificate
+	find := func(query appmodels.CertificateQuery) appmodels.CertificatePage {
+		t.Helper()
+		page, err := repo.FindPageByOrganization(organization, query)
+		require.NoError(t, err)
+		return page
+	}
+
+	// Servers by common name, one per page
+	query := appmodels.CertificateQuery{
+		SignedBy: root.SerialNumber(),
+		Type:     appmodels.SERVER_CERTIFICATE,
+		Sort:     appmodels.SORT_BY_COMMON_NAME,
+		Limit:    1,
+	}
+	page := find(query)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{serverA}), serialNumbersOf(page.Certificates))
+	require.NotNil(t, page.Next)
+	query.After = page.Next
+	page = find(query)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{serverB}), serialNumbersOf(page.Certificates))
+	assert.Nil(t, page.Next)
+
+	page = find(appmodels.CertificateQuery{Type: appmodels.INTERMEDIATE_CERTIFICATE})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{intermediate}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{SerialNumber: serverB.SerialNumber(), CommonName: "B.EXAMPLE"})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{serverB}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{SerialNumber: serverB.SerialNumber(), SignedBy: intermediate.SerialNumber()})
+	assert.Empty(t, page.Certificates)
+
+	page = find(appmodels.CertificateQuery{Type: appmodels.ROOT_CERTIFICATE})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{root}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{Name: "ALI"})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{client}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{Name: "%"})
+	assert.Empty(t, page.Certificates)
+
+	page = find(appmodels.CertificateQuery{Status: appmodels.VALID_CERTIFICATE, Now: time.Now()})
+	assert.Len(t, page.Certificates, 5)
+
+	page = find(appmodels.CertificateQuery{Status: appmodels.EXPIRED_CERTIFICATE, Now: time.Now()})
+	assert.Empty(t, page.Certificates)
+
+	page = find(appmodels.CertificateQuery{ExpiringBefore: client.NotAfter().Add(time.Second)})
+	assert.Contains(t, serialNumbersOf(page.Certificates), serialNumbersOf([]appmodels.Certificate{client})[0])
+	for _, cert := range page.Certificates {
+		assert.True(t, cert.NotAfter().Before(client.NotAfter().Add(time.Second)))
+	}
+
+	// Walking all pages in descending order returns every certificate once
+	all, err := repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	var expected []appmodels.Certificate
+	for i := len(all) - 1; i >= 0; i-- {
+		expected = append(expected, all[i])
+	}
+	var walked []appmodels.Certificate
+	query = appmodels.CertificateQuery{Descending: true, Limit: 2}
+	for {
+		page = find(query)
+		walked = append(walked, page.Certificates...)
+		if page.Next == nil {
+			break
+		}
+		query.After = page.Next
+	}
+	assert.Equal(t, serialNumbersOf(expected), serialNumbersOf(walked))
+}
+
+func TestMemoryCertificateRepository_Archive(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	collection := memoryrepository.NewCollection()
+	appController := appcontrollers.NewApplicationController(
+		collection.Organization,
+		collection.Certificate,
+		collection.PrivateKey,
+		collection.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization := big.NewInt(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	archived, _, err := rootController.NewServerCertificate("old.example.com")
+	require.NoError(t, err)
+	kept, _, err := rootController.NewServerCertificate("new.example.com")
+	require.NoError(t, err)
+
+	repo := collection.Certificate
+	require.NoError(t, repo.Archive(organization, archived.SerialNumber()))
+	assert.Error(t, repo.Archive(organization, archived.SerialNumber()))
+	assert.Error(t, repo.Archive(organization, big.NewInt(12345)))
+
+	_, err = repo.FindByOrganizationAndSerialNumber(organization, archived.SerialNumber())
+	assert.Error(t, err)
+	list, err := repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, serialNumbersOf([]appmodels.Certificate{root, kept}), serialNumbersOf(list))
+	list, err = repo.FindAllByOrganizationAndSignedBy(organization, root.SerialNumber())
+	require.NoError(t, err)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{kept}), serialNumbersOf(list))
+
+	page, err := repo.FindPageByOrganization(organization, appmodels.CertificateQuery{Type: appmodels.SERVER_CERTIFICATE})
+	require.NoError(t, err)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{kept}), serialNumbersOf(page.Certificates))
+
+	page, err = repo.FindPageByOrganization(organization, appmodels.CertificateQuery{Archived: true, SignedBy: root.SerialNumber(), Name: "old"})
+	require.NoError(t, err)
+	require.Len(t, page.Certificates, 1)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{archived}), serialNumbersOf(page.Certificates))
+	assert.Equal(t, root.SerialNumber(), page.Certificates[0].SignedBy())
+
+	page, err = repo.FindPageByOrganization(big.NewInt(11), appmodels.CertificateQuery{Archived: true})
+	require.NoError(t, err)
+	assert.Empty(t, page.Certificates)
+}
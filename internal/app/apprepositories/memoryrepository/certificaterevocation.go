@@ -0,0 +1,97 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MemoryCertificateRevocationRepository implements
+// appmodels.CertificateRevocationRepository in a memory
+type MemoryCertificateRevocationRepository struct {
+	mu sync.RWMutex
+
+	// revocations are indexed by the organization and the serial number of
+	// the revoked certificate
+	revocations map[string]map[string]appmodels.CertificateRevocation
+
+	// revocationLists are indexed by the organization and the serial number
+	// of the issuer
+	revocationLists map[string]map[string]appmodels.RevocationList
+}
+
+func (r *MemoryCertificateRevocationRepository) FindAllByOrganizationAndIssuer(organization *big.Int, issuer *big.Int) ([]appmodels.CertificateRevocation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var list []appmodels.CertificateRevocation
+	for _, model := range r.revocations[organization.String()] {
+		if model.IssuerSerialNumber().Cmp(issuer) == 0 {
+			list = append(list, model)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].RevokedCertificate().SerialNumber().Cmp(list[j].RevokedCertificate().SerialNumber()) < 0
+	})
+	return list, nil
+}
+
+func (r *MemoryCertificateRevocationRepository) FindByOrganizationAndSerialNumber(organization *big.Int, certificate *big.Int) (appmodels.CertificateRevocation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if model, exists := r.revocations[organization.String()][certificate.String()]; exists {
+		return model, nil
+	}
+	return nil, fmt.Errorf("[CertificateRevocation:FindByOrganizationAndSerialNumber]: not found: %s@%s", certificate, organization)
+}
+
+func (r *MemoryCertificateRevocationRepository) Save(model appmodels.CertificateRevocation) (appmodels.CertificateRevocation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	organization := model.OrganizationID().String()
+	if _, exists := r.revocations[organization]; !exists {
+		r.revocations[organization] = make(map[string]appmodels.CertificateRevocation)
+	}
+	serialNumber := model.RevokedCertificate().SerialNumber()
+	r.revocations[organization][serialNumber.String()] = model
+	log.Printf("[CertificateRevocation:Save:%s] Saved: %s", organization, serialNumber)
+	return model, nil
+}
+
+func (r *MemoryCertificateRevocationRepository) FindRevocationList(organization *big.Int, issuer *big.Int) (appmodels.RevocationList, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if model, exists := r.revocationLists[organization.String()][issuer.String()]; exists {
+		return model, nil
+	}
+	return nil, fmt.Errorf("[CertificateRevocation:FindRevocationList]: not found: %s@%s", issuer, organization)
+}
+
+func (r *MemoryCertificateRevocationRepository) SaveRevocationList(model appmodels.RevocationList) (appmodels.RevocationList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	organization := model.OrganizationID().String()
+	if _, exists := r.revocationLists[organization]; !exists {
+		r.revocationLists[organization] = make(map[string]appmodels.RevocationList)
+	}
+	r.revocationLists[organization][model.IssuerSerialNumber().String()] = model
+	log.Printf("[CertificateRevocation:SaveRevocationList:%s] Saved: %s: %s", organization, model.IssuerSerialNumber(), model.Number())
+	return model, nil
+}
+
+// NewCertificateRevocationRepository creates a memory based repository for
+// certificate revocations
+func NewCertificateRevocationRepository() *MemoryCertificateRevocationRepository {
+	return &MemoryCertificateRevocationRepository{
+		revocations:     make(map[string]map[string]appmodels.CertificateRevocation),
+		revocationLists: make(map[string]map[string]appmodels.RevocationList),
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.CertificateRevocationRepository = (*MemoryCertificateRevocationRepository)(nil)
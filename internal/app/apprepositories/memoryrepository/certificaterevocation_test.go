@@ -0,0 +1,54 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func TestCertificateRevocationRepository_SaveAndFind(t *testing.T) {
+	repo := memoryrepository.NewCertificateRevocationRepository()
+	now := time.Now()
+	first := appmodels.NewCertificateRevocation(big.NewInt(1), big.NewInt(2), appmodels.NewRevokedCertificate(big.NewInt(10), now, now.Add(time.Hour)))
+	second := appmodels.NewCertificateRevocation(big.NewInt(1), big.NewInt(2), appmodels.NewRevokedCertificate(big.NewInt(5), now, now.Add(time.Hour)))
+	other := appmodels.NewCertificateRevocation(big.NewInt(1), big.NewInt(3), appmodels.NewRevokedCertificate(big.NewInt(7), now, now.Add(time.Hour)))
+
+	for _, model := range []appmodels.CertificateRevocation{first, second, other} {
+		_, err := repo.Save(model)
+		require.NoError(t, err)
+	}
+
+	found, err := repo.FindByOrganizationAndSerialNumber(big.NewInt(1), big.NewInt(10))
+	require.NoError(t, err)
+	assert.Equal(t, first, found)
+
+	list, err := repo.FindAllByOrganizationAndIssuer(big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err)
+	assert.Equal(t, []appmodels.CertificateRevocation{second, first}, list)
+
+	_, err = repo.FindByOrganizationAndSerialNumber(big.NewInt(2), big.NewInt(10))
+	assert.ErrorContains(t, err, ": not found:")
+}
+
+func TestCertificateRevocationRepository_RevocationList(t *testing.T) {
+	repo := memoryrepository.NewCertificateRevocationRepository()
+
+	_, err := repo.FindRevocationList(big.NewInt(1), big.NewInt(2))
+	assert.ErrorContains(t, err, ": not found:")
+
+	model := appmodels.NewRevocationList(big.NewInt(1), big.NewInt(2), big.NewInt(5), time.Now())
+	_, err = repo.SaveRevocationList(model)
+	require.NoError(t, err)
+
+	found, err := repo.FindRevocationList(big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err)
+	assert.Equal(t, model, found)
+}
@@ -7,9 +7,31 @@ import (
 )
 
 func NewCollection() *appmodels.Collection {
+	certificates := NewCertificateRepository()
+	keys := NewPrivateKeyRepository()
 	return appmodels.NewCollection(
 		NewOrganizationRepository(),
-		NewCertificateRepository(),
-		NewPrivateKeyRepository(),
+		certificates,
+		keys,
+		NewUnitOfWorkRepository(certificates, keys),
+		NewSshAuthorityRepository(),
+		NewSshCertificateRepository(),
+		NewCertificateRevocationRepository(),
+		NewPendingOperationRepository(),
+		NewAcmeAccountRepository(),
+		NewAcmeOrderRepository(),
+		NewAcmeAuthorizationRepository(),
+		NewEstLabelRepository(),
+		NewScepChallengeRepository(),
+		NewVaultRoleRepository(),
+	)
+}
+
+func NewAcmeCollection() *appmodels.AcmeCollection {
+	return appmodels.NewAcmeCollection(
+		NewAcmeAccountRepository(),
+		NewAcmeOrderRepository(),
+		NewAcmeAuthorizationRepository(),
+		NewAcmeNonceRepository(0),
 	)
 }
@@ -23,4 +23,25 @@ func TestNewCollection(t *testing.T) {
 	assert.NotNil(t, collection.Organization, "Organization should be initialized")
 	assert.NotNil(t, collection.Certificate, "Certificate should be initialized")
 	assert.NotNil(t, collection.PrivateKey, "PrivateKey should be initialized")
+	assert.NotNil(t, collection.UnitOfWork, "UnitOfWork should be initialized")
+	assert.NotNil(t, collection.SshAuthority, "SshAuthority should be initialized")
+	assert.NotNil(t, collection.SshCertificate, "SshCertificate should be initialized")
+	assert.NotNil(t, collection.CertificateRevocation, "CertificateRevocation should be initialized")
+	assert.NotNil(t, collection.PendingOperation, "PendingOperation should be initialized")
+	assert.NotNil(t, collection.AcmeAccount, "AcmeAccount should be initialized")
+	assert.NotNil(t, collection.AcmeOrder, "AcmeOrder should be initialized")
+	assert.NotNil(t, collection.AcmeAuthorization, "AcmeAuthorization should be initialized")
+	assert.NotNil(t, collection.EstLabel, "EstLabel should be initialized")
+	assert.NotNil(t, collection.ScepChallenge, "ScepChallenge should be initialized")
+	assert.NotNil(t, collection.VaultRole, "VaultRole should be initialized")
+}
+
+func TestNewAcmeCollection(t *testing.T) {
+	collection := memoryrepository.NewAcmeCollection()
+
+	assert.NotNil(t, collection, "Collection should not be nil")
+	assert.NotNil(t, collection.Account, "Account should be initialized")
+	assert.NotNil(t, collection.Order, "Order should be initialized")
+	assert.NotNil(t, collection.Authorization, "Authorization should be initialized")
+	assert.NotNil(t, collection.Nonce, "Nonce should be initialized")
 }
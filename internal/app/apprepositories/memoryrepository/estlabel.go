@@ -0,0 +1,62 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MemoryEstLabelRepository implements appmodels.EstLabelRepository in a memory
+type MemoryEstLabelRepository struct {
+	mu     sync.RWMutex
+	labels map[string]appmodels.EstLabel
+}
+
+func (r *MemoryEstLabelRepository) FindByLabel(label string) (appmodels.EstLabel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if model, exists := r.labels[label]; exists {
+		return model, nil
+	}
+	return nil, fmt.Errorf("[EstLabel:FindByLabel]: not found: %s", label)
+}
+
+func (r *MemoryEstLabelRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.EstLabel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]appmodels.EstLabel, 0)
+	for _, model := range r.labels {
+		if model.OrganizationID().Cmp(organization) == 0 {
+			list = append(list, model)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Label() < list[j].Label()
+	})
+	return list, nil
+}
+
+func (r *MemoryEstLabelRepository) Save(model appmodels.EstLabel) (appmodels.EstLabel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	label := model.Label()
+	r.labels[label] = model
+	log.Printf("[EstLabel:Save:%s] Saved", label)
+	return model, nil
+}
+
+// NewEstLabelRepository creates a memory based repository for EST labels
+func NewEstLabelRepository() *MemoryEstLabelRepository {
+	return &MemoryEstLabelRepository{
+		labels: make(map[string]appmodels.EstLabel),
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.EstLabelRepository = (*MemoryEstLabelRepository)(nil)
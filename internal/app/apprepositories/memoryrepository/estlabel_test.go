@@ -0,0 +1,48 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func TestEstLabelRepository_SaveAndFind(t *testing.T) {
+	repo := memoryrepository.NewEstLabelRepository()
+	label := appmodels.NewEstLabel("devices", big.NewInt(1), big.NewInt(2), "", nil)
+
+	_, err := repo.Save(label)
+	assert.NoError(t, err)
+
+	found, err := repo.FindByLabel("devices")
+	assert.NoError(t, err)
+	assert.Equal(t, label, found)
+
+	_, err = repo.FindByLabel("other")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ": not found:")
+}
+
+func TestEstLabelRepository_FindAllByOrganization(t *testing.T) {
+	repo := memoryrepository.NewEstLabelRepository()
+	devices := appmodels.NewEstLabel("devices", big.NewInt(1), big.NewInt(2), "", nil)
+	cameras := appmodels.NewEstLabel("cameras", big.NewInt(1), big.NewInt(2), "", nil)
+	other := appmodels.NewEstLabel("other", big.NewInt(2), big.NewInt(3), "", nil)
+	for _, label := range []appmodels.EstLabel{devices, cameras, other} {
+		_, err := repo.Save(label)
+		assert.NoError(t, err)
+	}
+
+	list, err := repo.FindAllByOrganization(big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []appmodels.EstLabel{cameras, devices}, list)
+
+	list, err = repo.FindAllByOrganization(big.NewInt(3))
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+}
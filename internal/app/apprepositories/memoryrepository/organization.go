@@ -6,42 +6,72 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"sort"
+	"sync"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
 )
 
 // MemoryOrganizationRepository implements models.OrganizationRepository in a memory
 // @implements models.OrganizationRepository
 type MemoryOrganizationRepository struct {
-	organizations map[*big.Int]appmodels.Organization
+	mu sync.RWMutex
+
+	// organizations are indexed by the decimal organization ID
+	organizations map[string]appmodels.Organization
 }
 
 func (r *MemoryOrganizationRepository) FindAll() ([]appmodels.Organization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	list := make([]appmodels.Organization, 0, len(r.organizations))
 	for _, org := range r.organizations {
-		list = append(list, org)
+		list = append(list, copyOrganization(org))
 	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID().Cmp(list[j].ID()) < 0
+	})
 	return list, nil
 }
 
+func (r *MemoryOrganizationRepository) FindPage(query appmodels.OrganizationQuery) (appmodels.OrganizationPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]appmodels.Organization, 0, len(r.organizations))
+	for _, org := range r.organizations {
+		list = append(list, org)
+	}
+	page := apputils.QueryOrganizations(list, query)
+	for i, org := range page.Organizations {
+		page.Organizations[i] = copyOrganization(org)
+	}
+	return page, nil
+}
+
 func (r *MemoryOrganizationRepository) FindById(id *big.Int) (appmodels.Organization, error) {
-	if organization, exists := r.organizations[id]; exists {
-		return organization, nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if organization, exists := r.organizations[id.String()]; exists {
+		return copyOrganization(organization), nil
 	}
 	return nil, fmt.Errorf("[Organization:FindById]: not found: %s", id)
 }
 
 func (r *MemoryOrganizationRepository) Save(organization appmodels.Organization) (appmodels.Organization, error) {
 	id := organization.ID()
-	r.organizations[id] = organization
-	log.Printf("[Organization:Save:%s] Saved: %v", id, organization)
-	return organization, nil
+	model := copyOrganization(organization)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.organizations[id.String()] = model
+	log.Printf("[Organization:Save:%s] Saved: %v", id, model)
+	return copyOrganization(model), nil
 }
 
 // NewOrganizationRepository creates a memory based repository for organizations
 func NewOrganizationRepository() *MemoryOrganizationRepository {
 	return &MemoryOrganizationRepository{
-		organizations: make(map[*big.Int]appmodels.Organization),
+		organizations: make(map[string]appmodels.Organization),
 	}
 }
 
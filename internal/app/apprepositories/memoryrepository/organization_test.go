@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
 )
@@ -20,6 +21,11 @@ func TestOrganizationRepository_CreateAndGetOrganization(t *testing.T) {
 
 	// Setting up expectations
 	mockOrg.On("ID").Return(id)
+	mockOrg.On("Slug").Return("test")
+	mockOrg.On("Names").Return([]string{"Test"})
+	mockOrg.On("SignatureAlgorithm").Return(appmodels.NIL_SIGNATURE_ALGORITHM)
+	mockOrg.On("SpiffeTrustDomain").Return("")
+	mockOrg.On("KeyRetentionPolicy").Return(appmodels.KEY_RETENTION_NONE)
 
 	// Test Save
 	_, err := repo.Save(mockOrg)
@@ -56,7 +62,17 @@ func TestOrganizationRepository_FindAll(t *testing.T) {
 
 	// Setting up expectations for the mock organizations
 	mockOrg1.On("ID").Return(id1)
+	mockOrg1.On("Slug").Return("test")
+	mockOrg1.On("Names").Return([]string{"Test"})
+	mockOrg1.On("SignatureAlgorithm").Return(appmodels.NIL_SIGNATURE_ALGORITHM)
+	mockOrg1.On("SpiffeTrustDomain").Return("")
+	mockOrg1.On("KeyRetentionPolicy").Return(appmodels.KEY_RETENTION_NONE)
 	mockOrg2.On("ID").Return(id2)
+	mockOrg2.On("Slug").Return("test")
+	mockOrg2.On("Names").Return([]string{"Test"})
+	mockOrg2.On("SignatureAlgorithm").Return(appmodels.NIL_SIGNATURE_ALGORITHM)
+	mockOrg2.On("SpiffeTrustDomain").Return("")
+	mockOrg2.On("KeyRetentionPolicy").Return(appmodels.KEY_RETENTION_NONE)
 
 	// Save mock organizations to the repository
 	_, err1 := repo.Save(mockOrg1)
@@ -72,15 +88,76 @@ func TestOrganizationRepository_FindAll(t *testing.T) {
 
 	// Verify that returned organizations match saved ones
 	// Since map iteration order is not guaranteed, use a map to verify existence
-	foundIds := make(map[*big.Int]bool)
+	foundIds := make(map[string]bool)
 	for _, org := range organizations {
-		foundIds[org.ID()] = true
+		foundIds[org.ID().String()] = true
 	}
 
-	assert.True(t, foundIds[id1], "Expected to find organization with ID %s", id1)
-	assert.True(t, foundIds[id2], "Expected to find organization with ID %s", id2)
+	assert.True(t, foundIds[id1.String()], "Expected to find organization with ID %s", id1)
+	assert.True(t, foundIds[id2.String()], "Expected to find organization with ID %s", id2)
 
 	// Verify expectations were met for the mock organizations
 	mockOrg1.AssertExpectations(t)
 	mockOrg2.AssertExpectations(t)
 }
+
+func TestOrganizationRepository_ValueComparisonAndCopies(t *testing.T) {
+	repo := memoryrepository.NewOrganizationRepository()
+	id := big.NewInt(20)
+	names := []string{"Acme"}
+	_, err := repo.Save(appmodels.NewOrganization(id, "acme", names, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	assert.NoError(t, err)
+	_, err = repo.Save(appmodels.NewOrganization(big.NewInt(3), "beta", []string{"Beta"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	assert.NoError(t, err)
+
+	// Modifying the saved values does not change the repository
+	id.SetInt64(999)
+
+	parsed, _ := new(big.Int).SetString("20", 10)
+	found, err := repo.FindById(parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, "20", found.ID().String())
+	found.ID().SetInt64(1)
+
+	found, err = repo.FindById(big.NewInt(20))
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", found.Slug())
+
+	list, err := repo.FindAll()
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "3", list[0].ID().String())
+	assert.Equal(t, "20", list[1].ID().String())
+}
+
+func TestOrganizationRepository_FindPage(t *testing.T) {
+	repo := memoryrepository.NewOrganizationRepository()
+	for _, v := range []struct {
+		id   int64
+		slug string
+	}{{10, "gamma"}, {2, "beta"}, {1, "alpha"}} {
+		_, err := repo.Save(appmodels.NewOrganization(big.NewInt(v.id), v.slug, []string{v.slug + " Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+		assert.NoError(t, err)
+	}
+	idsOf := func(page appmodels.OrganizationPage) []string {
+		var ids []string
+		for _, org := range page.Organizations {
+			ids = append(ids, org.ID().String())
+		}
+		return ids
+	}
+
+	page, err := repo.FindPage(appmodels.OrganizationQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, idsOf(page))
+	if assert.NotNil(t, page.Next) {
+		page, err = repo.FindPage(appmodels.OrganizationQuery{Limit: 2, After: page.Next})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"10"}, idsOf(page))
+		assert.Nil(t, page.Next)
+	}
+
+	page, err = repo.FindPage(appmodels.OrganizationQuery{Name: "GAM"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10"}, idsOf(page))
+}
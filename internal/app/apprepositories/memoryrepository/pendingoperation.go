@@ -0,0 +1,63 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MemoryPendingOperationRepository implements
+// appmodels.PendingOperationRepository in a memory
+type MemoryPendingOperationRepository struct {
+	mu         sync.RWMutex
+	operations map[string]appmodels.PendingOperation
+}
+
+func (r *MemoryPendingOperationRepository) FindAll() ([]appmodels.PendingOperation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]appmodels.PendingOperation, 0, len(r.operations))
+	for _, model := range r.operations {
+		list = append(list, model)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt().Equal(list[j].CreatedAt()) {
+			return list[i].ID() < list[j].ID()
+		}
+		return list[i].CreatedAt().Before(list[j].CreatedAt())
+	})
+	return list, nil
+}
+
+func (r *MemoryPendingOperationRepository) FindById(id string) (appmodels.PendingOperation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if model, exists := r.operations[id]; exists {
+		return model, nil
+	}
+	return nil, fmt.Errorf("[PendingOperation:FindById]: not found: %s", id)
+}
+
+func (r *MemoryPendingOperationRepository) Save(model appmodels.PendingOperation) (appmodels.PendingOperation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.operations[model.ID()] = model
+	log.Printf("[PendingOperation:Save:%s] Saved: %s %s", model.ID(), model.Type(), model.Status())
+	return model, nil
+}
+
+// NewPendingOperationRepository creates a memory based repository for
+// operations waiting for approval
+func NewPendingOperationRepository() *MemoryPendingOperationRepository {
+	return &MemoryPendingOperationRepository{
+		operations: make(map[string]appmodels.PendingOperation),
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.PendingOperationRepository = (*MemoryPendingOperationRepository)(nil)
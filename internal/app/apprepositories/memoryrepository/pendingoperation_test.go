@@ -0,0 +1,38 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func TestPendingOperationRepository_SaveAndFind(t *testing.T) {
+	repo := memoryrepository.NewPendingOperationRepository()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	second := appmodels.NewPendingOperation("a", appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, big.NewInt(1), nil, "", "alice", appmodels.OPERATION_STATUS_PENDING, now.Add(time.Minute), now.Add(time.Hour), nil, time.Time{})
+	first := appmodels.NewPendingOperation("b", appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, big.NewInt(1), nil, "", "alice", appmodels.OPERATION_STATUS_PENDING, now, now.Add(time.Hour), nil, time.Time{})
+
+	_, err := repo.Save(second)
+	require.NoError(t, err)
+	_, err = repo.Save(first)
+	require.NoError(t, err)
+
+	found, err := repo.FindById("a")
+	require.NoError(t, err)
+	assert.Equal(t, second, found)
+
+	list, err := repo.FindAll()
+	require.NoError(t, err)
+	assert.Equal(t, []appmodels.PendingOperation{first, second}, list)
+
+	_, err = repo.FindById("c")
+	assert.ErrorContains(t, err, ": not found:")
+}
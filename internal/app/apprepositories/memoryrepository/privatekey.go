@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"sync"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
 )
@@ -13,28 +14,44 @@ import (
 // MemoryPrivateKeyRepository implements models.PrivateKeyRepository in a memory
 // @implements models.PrivateKeyRepository
 type MemoryPrivateKeyRepository struct {
-	keys map[string]appmodels.PrivateKey
+	mu sync.RWMutex
+
+	// keys are indexed by the organization and the serial number
+	keys map[string]map[string]appmodels.PrivateKey
 }
 
 func (r *MemoryPrivateKeyRepository) FindByOrganizationAndSerialNumber(organization *big.Int, certificate *big.Int) (appmodels.PrivateKey, error) {
-	id := getCertificateLocator(organization, certificate)
-	if key, exists := r.keys[id]; exists {
-		return key, nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if key, exists := r.keys[organization.String()][certificate.String()]; exists {
+		return copyPrivateKey(key), nil
 	}
-	return nil, fmt.Errorf("[PrivateKey:FindById]: not found: %s", id)
+	return nil, fmt.Errorf("[PrivateKey:FindById]: not found: %s", getCertificateLocator(organization, certificate))
 }
 
 func (r *MemoryPrivateKeyRepository) Save(key appmodels.PrivateKey) (appmodels.PrivateKey, error) {
-	id := getCertificateLocator(key.OrganizationID(), key.SerialNumber())
-	r.keys[id] = key
-	log.Printf("[PrivateKey:Save:%s] Saved: %v", id, key)
-	return key, nil
+	model := copyPrivateKey(key)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.save(model)
+	return copyPrivateKey(model), nil
+}
+
+// save stores a copied private key. The caller must hold the write lock.
+func (r *MemoryPrivateKeyRepository) save(model appmodels.PrivateKey) {
+	org := model.OrganizationID().String()
+	serial := model.SerialNumber().String()
+	if _, exists := r.keys[org]; !exists {
+		r.keys[org] = make(map[string]appmodels.PrivateKey)
+	}
+	r.keys[org][serial] = model
+	log.Printf("[PrivateKey:Save:%s/%s] Saved", org, serial)
 }
 
 // NewPrivateKeyRepository is a memory based repository for private keys
 func NewPrivateKeyRepository() *MemoryPrivateKeyRepository {
 	return &MemoryPrivateKeyRepository{
-		keys: make(map[string]appmodels.PrivateKey),
+		keys: make(map[string]map[string]appmodels.PrivateKey),
 	}
 }
 
@@ -24,6 +24,8 @@ func TestPrivateKeyRepository_CreateAndGetPrivateKey(t *testing.T) {
 	mockKey := new(appmocks.MockPrivateKey)
 	mockKey.On("OrganizationID").Return(organization)
 	mockKey.On("SerialNumber").Return(serialNumber)
+	mockKey.On("KeyType").Return(appmodels.ECDSA_P256)
+	mockKey.On("PrivateKey").Return("key")
 
 	// Test Save
 	_, err := repo.Save(mockKey)
@@ -49,3 +51,16 @@ func TestPrivateKeyRepository_GetExistingPrivateKeyNotFound(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), ": not found:")
 }
+
+func TestPrivateKeyRepository_ValueComparison(t *testing.T) {
+	repo := memoryrepository.NewPrivateKeyRepository()
+	_, err := repo.Save(appmodels.NewPrivateKey(big.NewInt(123), big.NewInt(1), appmodels.ECDSA_P256, "key"))
+	assert.NoError(t, err)
+
+	organization, _ := new(big.Int).SetString("123", 10)
+	serialNumber, _ := new(big.Int).SetString("1", 10)
+	found, err := repo.FindByOrganizationAndSerialNumber(organization, serialNumber)
+	assert.NoError(t, err)
+	assert.Equal(t, appmodels.ECDSA_P256, found.KeyType())
+	assert.Equal(t, "key", found.PrivateKey())
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MemoryScepChallengeRepository implements appmodels.ScepChallengeRepository
+// in a memory
+type MemoryScepChallengeRepository struct {
+	mu         sync.RWMutex
+	challenges map[string]appmodels.ScepChallenge
+}
+
+func (r *MemoryScepChallengeRepository) FindByOrganization(organization *big.Int) (appmodels.ScepChallenge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if model, exists := r.challenges[organization.String()]; exists {
+		return model, nil
+	}
+	return nil, fmt.Errorf("[ScepChallenge:FindByOrganization]: not found: %s", organization)
+}
+
+func (r *MemoryScepChallengeRepository) Save(model appmodels.ScepChallenge) (appmodels.ScepChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	organization := model.OrganizationID().String()
+	r.challenges[organization] = model
+	log.Printf("[ScepChallenge:Save:%s] Saved", organization)
+	return model, nil
+}
+
+// NewScepChallengeRepository creates a memory based repository for SCEP
+// challenge passwords
+func NewScepChallengeRepository() *MemoryScepChallengeRepository {
+	return &MemoryScepChallengeRepository{
+		challenges: make(map[string]appmodels.ScepChallenge),
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.ScepChallengeRepository = (*MemoryScepChallengeRepository)(nil)
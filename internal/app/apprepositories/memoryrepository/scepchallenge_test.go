@@ -0,0 +1,29 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func TestScepChallengeRepository_SaveAndFind(t *testing.T) {
+	repo := memoryrepository.NewScepChallengeRepository()
+	challenge := appmodels.NewScepChallenge(big.NewInt(1), []byte("hash"))
+
+	_, err := repo.Save(challenge)
+	assert.NoError(t, err)
+
+	found, err := repo.FindByOrganization(big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, challenge, found)
+
+	_, err = repo.FindByOrganization(big.NewInt(2))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ": not found:")
+}
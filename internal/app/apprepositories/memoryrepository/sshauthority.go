@@ -0,0 +1,48 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MemorySshAuthorityRepository implements appmodels.SshAuthorityRepository
+// in a memory
+type MemorySshAuthorityRepository struct {
+	mu          sync.RWMutex
+	authorities map[string]appmodels.SshAuthority
+}
+
+func (r *MemorySshAuthorityRepository) FindByOrganization(organization *big.Int) (appmodels.SshAuthority, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if model, exists := r.authorities[organization.String()]; exists {
+		return model, nil
+	}
+	return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: not found: %s", organization)
+}
+
+func (r *MemorySshAuthorityRepository) Save(model appmodels.SshAuthority) (appmodels.SshAuthority, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	organization := model.OrganizationID().String()
+	r.authorities[organization] = model
+	log.Printf("[SshAuthority:Save:%s] Saved: %s", organization, model.ID())
+	return model, nil
+}
+
+// NewSshAuthorityRepository creates a memory based repository for SSH CA
+// keys
+func NewSshAuthorityRepository() *MemorySshAuthorityRepository {
+	return &MemorySshAuthorityRepository{
+		authorities: make(map[string]appmodels.SshAuthority),
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.SshAuthorityRepository = (*MemorySshAuthorityRepository)(nil)
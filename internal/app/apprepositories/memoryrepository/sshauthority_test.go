@@ -0,0 +1,30 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func TestSshAuthorityRepository_SaveAndFind(t *testing.T) {
+	repo := memoryrepository.NewSshAuthorityRepository()
+	authority := appmodels.NewSshAuthority(big.NewInt(1), big.NewInt(2), nil, time.Now())
+
+	_, err := repo.Save(authority)
+	assert.NoError(t, err)
+
+	found, err := repo.FindByOrganization(big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, authority, found)
+
+	_, err = repo.FindByOrganization(big.NewInt(2))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ": not found:")
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MemorySshCertificateRepository implements
+// appmodels.SshCertificateRepository in a memory
+type MemorySshCertificateRepository struct {
+	mu sync.RWMutex
+
+	// certificates are indexed by the organization and the serial number
+	certificates map[string]map[uint64]appmodels.SshCertificate
+}
+
+func (r *MemorySshCertificateRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.SshCertificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]appmodels.SshCertificate, 0, len(r.certificates[organization.String()]))
+	for _, model := range r.certificates[organization.String()] {
+		list = append(list, model)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Serial() < list[j].Serial()
+	})
+	return list, nil
+}
+
+func (r *MemorySshCertificateRepository) FindByOrganizationAndSerial(organization *big.Int, serial uint64) (appmodels.SshCertificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if model, exists := r.certificates[organization.String()][serial]; exists {
+		return model, nil
+	}
+	return nil, fmt.Errorf("[SshCertificate:FindByOrganizationAndSerial]: not found: %d@%s", serial, organization)
+}
+
+func (r *MemorySshCertificateRepository) Save(model appmodels.SshCertificate) (appmodels.SshCertificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	organization := model.OrganizationID().String()
+	if _, exists := r.certificates[organization]; !exists {
+		r.certificates[organization] = make(map[uint64]appmodels.SshCertificate)
+	}
+	r.certificates[organization][model.Serial()] = model
+	log.Printf("[SshCertificate:Save:%s] Saved: %d", organization, model.Serial())
+	return model, nil
+}
+
+// NewSshCertificateRepository creates a memory based repository for signed
+// SSH certificates
+func NewSshCertificateRepository() *MemorySshCertificateRepository {
+	return &MemorySshCertificateRepository{
+		certificates: make(map[string]map[uint64]appmodels.SshCertificate),
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.SshCertificateRepository = (*MemorySshCertificateRepository)(nil)
@@ -0,0 +1,50 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func TestSshCertificateRepository_SaveAndFind(t *testing.T) {
+	repo := memoryrepository.NewSshCertificateRepository()
+	second := appmodels.NewSshCertificate(big.NewInt(1), &ssh.Certificate{Serial: 20}, time.Time{})
+	first := appmodels.NewSshCertificate(big.NewInt(1), &ssh.Certificate{Serial: 10}, time.Time{})
+
+	_, err := repo.Save(second)
+	assert.NoError(t, err)
+	_, err = repo.Save(first)
+	assert.NoError(t, err)
+
+	found, err := repo.FindByOrganizationAndSerial(big.NewInt(1), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, first, found)
+
+	list, err := repo.FindAllByOrganization(big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []appmodels.SshCertificate{first, second}, list)
+
+	list, err = repo.FindAllByOrganization(big.NewInt(2))
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	// Saving again replaces the certificate, e.g. when it is revoked
+	revoked := appmodels.NewSshCertificate(big.NewInt(1), &ssh.Certificate{Serial: 10}, time.Now())
+	_, err = repo.Save(revoked)
+	assert.NoError(t, err)
+	found, err = repo.FindByOrganizationAndSerial(big.NewInt(1), 10)
+	assert.NoError(t, err)
+	assert.True(t, found.IsRevoked())
+
+	_, err = repo.FindByOrganizationAndSerial(big.NewInt(2), 10)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ": not found:")
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package memoryrepository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MemoryUnitOfWorkRepository implements appmodels.UnitOfWorkRepository over
+// the memory certificate and private key repositories
+type MemoryUnitOfWorkRepository struct {
+	certificates *MemoryCertificateRepository
+	keys         *MemoryPrivateKeyRepository
+}
+
+func (r *MemoryUnitOfWorkRepository) NewUnitOfWork() appmodels.UnitOfWork {
+	return &MemoryUnitOfWork{repository: r}
+}
+
+// MemoryUnitOfWork implements appmodels.UnitOfWork. Changes are applied while
+// holding the locks of both repositories, so readers never see a certificate
+// without its private key.
+type MemoryUnitOfWork struct {
+	repository   *MemoryUnitOfWorkRepository
+	certificates []appmodels.Certificate
+	keys         []appmodels.PrivateKey
+	committed    bool
+}
+
+func (w *MemoryUnitOfWork) SaveCertificate(certificate appmodels.Certificate) {
+	w.certificates = append(w.certificates, certificate)
+}
+
+func (w *MemoryUnitOfWork) SavePrivateKey(key appmodels.PrivateKey) {
+	w.keys = append(w.keys, key)
+}
+
+func (w *MemoryUnitOfWork) Commit() error {
+	if w.committed {
+		return errors.New("[UnitOfWork:Commit]: already committed")
+	}
+	w.committed = true
+
+	certificates := make([]appmodels.Certificate, 0, len(w.certificates))
+	for _, certificate := range w.certificates {
+		model, err := prepareCertificate(certificate)
+		if err != nil {
+			return fmt.Errorf("[UnitOfWork:Commit]: %w", err)
+		}
+		certificates = append(certificates, model)
+	}
+	keys := make([]appmodels.PrivateKey, 0, len(w.keys))
+	for _, key := range w.keys {
+		keys = append(keys, copyPrivateKey(key))
+	}
+
+	// Locks are always taken in the same order
+	w.repository.certificates.mu.Lock()
+	defer w.repository.certificates.mu.Unlock()
+	w.repository.keys.mu.Lock()
+	defer w.repository.keys.mu.Unlock()
+
+	if w.repository.certificates.certificates == nil {
+		return errors.New("[UnitOfWork:Commit]: certificate repository not initialized")
+	}
+	for _, key := range keys {
+		w.repository.keys.save(key)
+	}
+	for _, certificate := range certificates {
+		if err := w.repository.certificates.save(certificate); err != nil {
+			return fmt.Errorf("[UnitOfWork:Commit]: %w", err)
+		}
+	}
+	return nil
+}
+
+// NewUnitOfWorkRepository creates units of work over memory repositories
+func NewUnitOfWorkRepository(
+	certificates *MemoryCertificateRepository,
+	keys *MemoryPrivateKeyRepository,
+) *MemoryUnitOfWorkRepository {
+	return &MemoryUnitOfWorkRepository{
+		certificates: certificates,
+		keys:         keys,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.UnitOfWorkRepository = (*MemoryUnitOfWorkRepository)(nil)
+var _ appmodels.UnitOfWork = (*MemoryUnitOfWork)(nil)
@@ -0,0 +1,46 @@
+// Copyright (c) 2024. Heusala Group <info@hg.fi>. All rights reserved.
+
+package memoryrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func TestUnitOfWork_Commit(t *testing.T) {
+	certificates := memoryrepository.NewCertificateRepository()
+	keys := memoryrepository.NewPrivateKeyRepository()
+	repo := memoryrepository.NewUnitOfWorkRepository(certificates, keys)
+
+	work := repo.NewUnitOfWork()
+	work.SaveCertificate(appmodels.NewCertificate(big.NewInt(123), nil, newTestX509Certificate(big.NewInt(1), time.Now())))
+	work.SavePrivateKey(appmodels.NewPrivateKey(big.NewInt(123), big.NewInt(1), appmodels.ECDSA_P256, "key"))
+	assert.NoError(t, work.Commit())
+
+	_, err := certificates.FindByOrganizationAndSerialNumber(big.NewInt(123), big.NewInt(1))
+	assert.NoError(t, err)
+	_, err = keys.FindByOrganizationAndSerialNumber(big.NewInt(123), big.NewInt(1))
+	assert.NoError(t, err)
+
+	assert.ErrorContains(t, work.Commit(), "already committed")
+}
+
+func TestUnitOfWork_CommitFailureStoresNothing(t *testing.T) {
+	certificates := memoryrepository.NewCertificateRepository()
+	keys := memoryrepository.NewPrivateKeyRepository()
+	repo := memoryrepository.NewUnitOfWorkRepository(certificates, keys)
+
+	work := repo.NewUnitOfWork()
+	work.SavePrivateKey(appmodels.NewPrivateKey(big.NewInt(123), big.NewInt(1), appmodels.ECDSA_P256, "key"))
+	work.SaveCertificate(appmodels.NewCertificate(big.NewInt(123), nil, nil))
+	assert.ErrorContains(t, work.Commit(), "no certificate data")
+
+	_, err := keys.FindByOrganizationAndSerialNumber(big.NewInt(123), big.NewInt(1))
+	assert.ErrorContains(t, err, "not found")
+}
@@ -3,10 +3,71 @@
 package memoryrepository
 
 import (
+	"crypto/x509"
 	"fmt"
 	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
 )
 
 func getCertificateLocator(organization *big.Int, certificate *big.Int) string {
 	return fmt.Sprintf("%s/%s", organization.String(), certificate.String())
 }
+
+// copyBigInt returns a copy of a big integer, or nil
+func copyBigInt(value *big.Int) *big.Int {
+	if value == nil {
+		return nil
+	}
+	return new(big.Int).Set(value)
+}
+
+// copyOrganization returns a copy of an organization which does not share
+// mutable state with the original
+func copyOrganization(organization appmodels.Organization) appmodels.Organization {
+	return appmodels.NewOrganization(
+		copyBigInt(organization.ID()),
+		organization.Slug(),
+		organization.Names(),
+		organization.SignatureAlgorithm(),
+		organization.SpiffeTrustDomain(),
+		organization.KeyRetentionPolicy(),
+	)
+}
+
+// copyCertificate returns a copy of a certificate which does not share
+// mutable state with the original. The parsed X.509 certificate is copied
+// shallowly; its byte slices are never modified after parsing.
+func copyCertificate(certificate appmodels.Certificate) appmodels.Certificate {
+	var cert *x509.Certificate
+	if original := certificate.Certificate(); original != nil {
+		copied := *original
+		copied.SerialNumber = copyBigInt(original.SerialNumber)
+		cert = &copied
+	}
+	return appmodels.NewCertificate(
+		copyBigInt(certificate.OrganizationID()),
+		copyBigInt(certificate.SignedBy()),
+		cert,
+	)
+}
+
+// copyPrivateKey returns a copy of a private key model. The key itself is
+// shared, since the crypto packages never modify it.
+func copyPrivateKey(key appmodels.PrivateKey) appmodels.PrivateKey {
+	return appmodels.NewPrivateKey(
+		copyBigInt(key.OrganizationID()),
+		copyBigInt(key.SerialNumber()),
+		key.KeyType(),
+		key.PrivateKey(),
+	)
+}
+
+// issuerLocator returns the key of the issuer index. Root certificates have
+// an empty issuer.
+func issuerLocator(signedBy *big.Int) string {
+	if signedBy == nil {
+		return ""
+	}
+	return signedBy.String()
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MemoryVaultRoleRepository implements appmodels.VaultRoleRepository in a
+// memory
+type MemoryVaultRoleRepository struct {
+	mu sync.RWMutex
+
+	// roles are indexed by the organization and the role name
+	roles map[string]map[string]appmodels.VaultRole
+}
+
+func (r *MemoryVaultRoleRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.VaultRole, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]appmodels.VaultRole, 0, len(r.roles[organization.String()]))
+	for _, model := range r.roles[organization.String()] {
+		list = append(list, model)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	return list, nil
+}
+
+func (r *MemoryVaultRoleRepository) FindByOrganizationAndName(organization *big.Int, name string) (appmodels.VaultRole, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if model, exists := r.roles[organization.String()][name]; exists {
+		return model, nil
+	}
+	return nil, fmt.Errorf("[VaultRole:FindByOrganizationAndName]: not found: %s@%s", name, organization)
+}
+
+func (r *MemoryVaultRoleRepository) Save(model appmodels.VaultRole) (appmodels.VaultRole, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	organization := model.OrganizationID().String()
+	if _, exists := r.roles[organization]; !exists {
+		r.roles[organization] = make(map[string]appmodels.VaultRole)
+	}
+	r.roles[organization][model.Name()] = model
+	log.Printf("[VaultRole:Save:%s] Saved: %s", organization, model.Name())
+	return model, nil
+}
+
+func (r *MemoryVaultRoleRepository) Delete(organization *big.Int, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.roles[organization.String()][name]; !exists {
+		return fmt.Errorf("[VaultRole:Delete]: not found: %s@%s", name, organization)
+	}
+	delete(r.roles[organization.String()], name)
+	log.Printf("[VaultRole:Delete:%s] Deleted: %s", organization, name)
+	return nil
+}
+
+// NewVaultRoleRepository creates a memory based repository for Vault PKI
+// roles
+func NewVaultRoleRepository() *MemoryVaultRoleRepository {
+	return &MemoryVaultRoleRepository{
+		roles: make(map[string]map[string]appmodels.VaultRole),
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.VaultRoleRepository = (*MemoryVaultRoleRepository)(nil)
@@ -0,0 +1,43 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package memoryrepository_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+)
+
+func TestVaultRoleRepository_SaveFindAndDelete(t *testing.T) {
+	repo := memoryrepository.NewVaultRoleRepository()
+	organization := big.NewInt(1)
+	web := appmodels.NewVaultRole("web", organization, big.NewInt(2), nil, false, false, true, true, appmodels.NIL_KEY_TYPE, 0, 0)
+	api := appmodels.NewVaultRole("api", organization, big.NewInt(2), nil, false, false, true, true, appmodels.NIL_KEY_TYPE, 0, 0)
+
+	_, err := repo.Save(web)
+	require.NoError(t, err)
+	_, err = repo.Save(api)
+	require.NoError(t, err)
+
+	found, err := repo.FindByOrganizationAndName(big.NewInt(1), "web")
+	require.NoError(t, err)
+	assert.Equal(t, web, found)
+
+	list, err := repo.FindAllByOrganization(big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, []appmodels.VaultRole{api, web}, list)
+
+	list, err = repo.FindAllByOrganization(big.NewInt(3))
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	require.NoError(t, repo.Delete(big.NewInt(1), "web"))
+	_, err = repo.FindByOrganizationAndName(big.NewInt(1), "web")
+	assert.ErrorContains(t, err, ": not found:")
+	assert.ErrorContains(t, repo.Delete(big.NewInt(1), "web"), ": not found:")
+}
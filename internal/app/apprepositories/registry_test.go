@@ -0,0 +1,133 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apprepositories_test
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func openTestStorage(t *testing.T, storage, dataDir string) *appmodels.Collection {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	collection, closeStorage, err := apprepositories.NewDefaultRegistry().Open(certManager, managers.NewFileManager(), storage, dataDir, false)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, closeStorage()) })
+
+	// Each storage saves and finds an organization
+	organization := appmodels.NewOrganization(big.NewInt(10), "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
+	_, err = collection.Organization.Save(organization)
+	require.NoError(t, err)
+	found, err := collection.Organization.FindById(big.NewInt(10))
+	require.NoError(t, err)
+	assert.Equal(t, "test", found.Slug())
+	return collection
+}
+
+func TestRegistry_Names(t *testing.T) {
+	assert.Equal(t, []string{"bolt", "file", "memory", "sql"}, apprepositories.NewDefaultRegistry().Names())
+}
+
+func TestRegistry_Open(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "data")
+
+	openTestStorage(t, "memory", "")
+
+	openTestStorage(t, "file", dataDir)
+	_, err := os.Stat(filerepository.OrganizationJsonPath(dataDir, big.NewInt(10)))
+	assert.NoError(t, err)
+
+	otherDir := t.TempDir()
+	openTestStorage(t, "file:"+otherDir, dataDir)
+	_, err = os.Stat(filerepository.OrganizationJsonPath(otherDir, big.NewInt(10)))
+	assert.NoError(t, err)
+
+	openTestStorage(t, "sql", dataDir)
+	_, err = os.Stat(filepath.Join(dataDir, apprepositories.SqliteDatabaseFileName))
+	assert.NoError(t, err)
+
+	openTestStorage(t, "sql:sqlite:"+filepath.Join(otherDir, "test.db"), "")
+
+	openTestStorage(t, "bolt", dataDir)
+	_, err = os.Stat(filepath.Join(dataDir, apprepositories.BoltDatabaseFileName))
+	assert.NoError(t, err)
+}
+
+func TestRegistry_OpenErrors(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	fileManager := managers.NewFileManager()
+	registry := apprepositories.NewDefaultRegistry()
+
+	_, _, err := registry.Open(certManager, fileManager, "mongo", "", false)
+	assert.ErrorContains(t, err, "must be one of bolt, file, memory, sql")
+
+	_, _, err = registry.Open(certManager, fileManager, "file", "", false)
+	assert.ErrorContains(t, err, "must be defined")
+
+	_, _, err = registry.Open(certManager, fileManager, "bolt", "", false)
+	assert.ErrorContains(t, err, "must be defined")
+
+	insecure := t.TempDir()
+	require.NoError(t, os.Chmod(insecure, 0777))
+	_, _, err = registry.Open(certManager, fileManager, "file", insecure, false)
+	assert.ErrorContains(t, err, "must not be writable by group or others")
+
+	// An explicit SQLite file is validated like the data directory
+	_, _, err = registry.Open(certManager, fileManager, "sql:sqlite:"+filepath.Join(insecure, "test.db"), "", false)
+	assert.ErrorContains(t, err, "must not be writable by group or others")
+	_, _, err = registry.Open(certManager, fileManager, "sql:sqlite:file:"+filepath.Join(insecure, "test.db")+"?cache=shared", "", false)
+	assert.ErrorContains(t, err, "must not be writable by group or others")
+}
+
+func TestRegistry_OpenReadOnly(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	fileManager := managers.NewFileManager()
+	registry := apprepositories.NewDefaultRegistry()
+	organization := appmodels.NewOrganization(big.NewInt(11), "other", []string{"Other Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
+
+	for _, storage := range []string{"file", "sql", "bolt"} {
+		t.Run(storage, func(t *testing.T) {
+			dataDir := filepath.Join(t.TempDir(), "data")
+
+			// A missing storage is not created
+			_, _, err := registry.Open(certManager, fileManager, storage, dataDir, true)
+			assert.Error(t, err)
+			_, err = os.Stat(dataDir)
+			assert.True(t, os.IsNotExist(err), "Expected the data directory not to be created")
+
+			writable, closeWritable, err := registry.Open(certManager, fileManager, storage, dataDir, false)
+			require.NoError(t, err)
+			_, err = writable.Organization.Save(appmodels.NewOrganization(big.NewInt(10), "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+			require.NoError(t, err)
+			require.NoError(t, closeWritable())
+
+			collection, closeStorage, err := registry.Open(certManager, fileManager, storage, dataDir, true)
+			require.NoError(t, err)
+			defer func() { assert.NoError(t, closeStorage()) }()
+			_, err = collection.Organization.FindById(big.NewInt(10))
+			assert.NoError(t, err)
+			_, err = collection.Organization.Save(organization)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := apprepositories.NewRegistry()
+	registry.Register("test", apprepositories.OpenMemoryStorage)
+	assert.Equal(t, []string{"test"}, registry.Names())
+
+	collection, closeStorage, err := registry.Open(nil, nil, "test", "", false)
+	require.NoError(t, err)
+	assert.NotNil(t, collection.Certificate)
+	assert.NoError(t, closeStorage())
+}
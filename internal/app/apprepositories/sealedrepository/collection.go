@@ -0,0 +1,37 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sealedrepository
+
+import (
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// NewCollection wraps the private keys and SSH CA keys of a collection so
+// that they are stored encrypted with the seal key. Other repositories are
+// shared with the collection.
+//   - collection: The collection to wrap
+//   - sealController: The seal controller which has the seal key
+//   - certManager: The certificate manager
+func NewCollection(
+	collection *appmodels.Collection,
+	sealController appmodels.SealController,
+	certManager managers.CertificateManager,
+) *appmodels.Collection {
+	return appmodels.NewCollection(
+		collection.Organization,
+		collection.Certificate,
+		NewPrivateKeyRepository(collection.PrivateKey, sealController, certManager),
+		NewUnitOfWorkRepository(collection.UnitOfWork, sealController, certManager),
+		NewSshAuthorityRepository(collection.SshAuthority, sealController, certManager),
+		collection.SshCertificate,
+		collection.CertificateRevocation,
+		collection.PendingOperation,
+		collection.AcmeAccount,
+		collection.AcmeOrder,
+		collection.AcmeAuthorization,
+		collection.EstLabel,
+		collection.ScepChallenge,
+		collection.VaultRole,
+	)
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sealedrepository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sealedrepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestNewCollection(t *testing.T) {
+	inner := memoryrepository.NewCollection()
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	collection := sealedrepository.NewCollection(inner, newTestSealController(t), certManager)
+
+	assert.Same(t, inner.Organization, collection.Organization)
+	assert.Same(t, inner.Certificate, collection.Certificate)
+	assert.IsType(t, &sealedrepository.SealedPrivateKeyRepository{}, collection.PrivateKey)
+	assert.IsType(t, &sealedrepository.SealedUnitOfWorkRepository{}, collection.UnitOfWork)
+	assert.IsType(t, &sealedrepository.SealedSshAuthorityRepository{}, collection.SshAuthority)
+	assert.Same(t, inner.SshCertificate, collection.SshCertificate)
+	assert.Same(t, inner.CertificateRevocation, collection.CertificateRevocation)
+	assert.Same(t, inner.PendingOperation, collection.PendingOperation)
+	assert.Same(t, inner.AcmeAccount, collection.AcmeAccount)
+	assert.Same(t, inner.AcmeOrder, collection.AcmeOrder)
+	assert.Same(t, inner.AcmeAuthorization, collection.AcmeAuthorization)
+	assert.Same(t, inner.EstLabel, collection.EstLabel)
+	assert.Same(t, inner.ScepChallenge, collection.ScepChallenge)
+	assert.Same(t, inner.VaultRole, collection.VaultRole)
+}
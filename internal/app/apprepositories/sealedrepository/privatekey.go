@@ -0,0 +1,75 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sealedrepository
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// SealedPrivateKeyRepository implements appmodels.PrivateKeyRepository over
+// another private key repository. Keys are encrypted with the seal key
+// before they are stored and decrypted when they are read, so the other
+// repository only has encrypted keys. While sealed, keys can be neither
+// read nor stored, and appmodels.ErrSealed is returned.
+type SealedPrivateKeyRepository struct {
+	repository     appmodels.PrivateKeyRepository
+	sealController appmodels.SealController
+	certManager    managers.CertificateManager
+}
+
+func (r *SealedPrivateKeyRepository) FindByOrganizationAndSerialNumber(
+	organization,
+	certificate *big.Int,
+) (appmodels.PrivateKey, error) {
+	key, err := r.repository.FindByOrganizationAndSerialNumber(organization, certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys stored before sealing was enabled are not encrypted, but they
+	// are not released while sealed either
+	if _, ok := key.PrivateKey().(appmodels.SealedPrivateKey); !ok && r.sealController.IsSealed() {
+		return nil, fmt.Errorf("[PrivateKey:FindByOrganizationAndSerialNumber]: %w", appmodels.ErrSealed)
+	}
+
+	unsealed, err := apputils.UnsealPrivateKey(r.certManager, r.sealController, key)
+	if err != nil {
+		return nil, fmt.Errorf("[PrivateKey:FindByOrganizationAndSerialNumber]: %w", err)
+	}
+	return unsealed, nil
+}
+
+func (r *SealedPrivateKeyRepository) Save(key appmodels.PrivateKey) (appmodels.PrivateKey, error) {
+	sealed, err := apputils.SealPrivateKey(r.certManager, r.sealController, key)
+	if err != nil {
+		return nil, fmt.Errorf("[PrivateKey:Save]: %w", err)
+	}
+	if _, err := r.repository.Save(sealed); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// NewPrivateKeyRepository creates a private key repository which encrypts
+// the keys of another repository with the seal key
+//   - repository: The repository which stores the encrypted keys
+//   - sealController: The seal controller which has the seal key
+//   - certManager: The certificate manager
+func NewPrivateKeyRepository(
+	repository appmodels.PrivateKeyRepository,
+	sealController appmodels.SealController,
+	certManager managers.CertificateManager,
+) *SealedPrivateKeyRepository {
+	return &SealedPrivateKeyRepository{
+		repository:     repository,
+		sealController: sealController,
+		certManager:    certManager,
+	}
+}
+
+var _ appmodels.PrivateKeyRepository = (*SealedPrivateKeyRepository)(nil)
@@ -0,0 +1,82 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sealedrepository_test
+
+import (
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sealedrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// newTestSealController returns an unsealed seal controller
+func newTestSealController(t *testing.T) *appcontrollers.CertSealController {
+	config, _, _, err := apputils.NewSealConfig("secret", 0, 0)
+	require.NoError(t, err)
+	controller := appcontrollers.NewSealController(config, "")
+	require.NoError(t, controller.Unseal("secret"))
+	return controller
+}
+
+func TestSealedPrivateKeyRepository(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	dir := t.TempDir()
+	inner := filerepository.NewPrivateKeyRepository(certManager, managers.NewFileManager(), dir)
+	sealController := newTestSealController(t)
+	repository := sealedrepository.NewPrivateKeyRepository(inner, sealController, certManager)
+
+	organization := big.NewInt(10)
+	key, err := apputils.GeneratePrivateKey(organization, big.NewInt(1), appmodels.ECDSA_P384)
+	require.NoError(t, err)
+	saved, err := repository.Save(key)
+	require.NoError(t, err)
+	assert.Same(t, key, saved)
+
+	// The key is encrypted at rest
+	data, err := os.ReadFile(filerepository.PrivateKeyPemPath(dir, organization, big.NewInt(1)))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), apputils.SealedPrivateKeyPemType)
+	assert.Contains(t, string(data), "Key-Type: ECDSA_P384")
+	stored, err := inner.FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	require.NoError(t, err)
+	assert.Implements(t, (*appmodels.SealedPrivateKey)(nil), stored.PrivateKey())
+
+	found, err := repository.FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, key.PrivateKey(), found.PrivateKey())
+	assert.Equal(t, appmodels.ECDSA_P384, found.KeyType())
+
+	// A key stored before sealing was enabled is readable only while
+	// unsealed
+	plain, err := apputils.GeneratePrivateKey(organization, big.NewInt(2), appmodels.Ed25519)
+	require.NoError(t, err)
+	_, err = inner.Save(plain)
+	require.NoError(t, err)
+	found, err = repository.FindByOrganizationAndSerialNumber(organization, big.NewInt(2))
+	require.NoError(t, err)
+	assert.Equal(t, plain.PrivateKey(), found.PrivateKey())
+
+	sealController.Seal()
+	_, err = repository.FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	assert.ErrorIs(t, err, appmodels.ErrSealed)
+	_, err = repository.FindByOrganizationAndSerialNumber(organization, big.NewInt(2))
+	assert.ErrorIs(t, err, appmodels.ErrSealed)
+	_, err = repository.Save(key)
+	assert.ErrorIs(t, err, appmodels.ErrSealed)
+	_, err = repository.FindByOrganizationAndSerialNumber(organization, big.NewInt(3))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, appmodels.ErrSealed)
+
+	// Another seal key does not decrypt the key
+	_, err = sealedrepository.NewPrivateKeyRepository(inner, newTestSealController(t), certManager).FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	assert.Error(t, err)
+}
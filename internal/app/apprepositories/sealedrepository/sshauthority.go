@@ -0,0 +1,71 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sealedrepository
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// SealedSshAuthorityRepository implements appmodels.SshAuthorityRepository
+// over another SSH CA repository. The CA keys are encrypted with the seal
+// key like the private keys of certificates. While sealed, CA keys can be
+// neither read nor stored, and appmodels.ErrSealed is returned.
+type SealedSshAuthorityRepository struct {
+	repository     appmodels.SshAuthorityRepository
+	sealController appmodels.SealController
+	certManager    managers.CertificateManager
+}
+
+func (r *SealedSshAuthorityRepository) FindByOrganization(organization *big.Int) (appmodels.SshAuthority, error) {
+	authority, err := r.repository.FindByOrganization(organization)
+	if err != nil {
+		return nil, err
+	}
+
+	// CA keys stored before sealing was enabled are not released while
+	// sealed either
+	if _, ok := authority.PrivateKey().PrivateKey().(appmodels.SealedPrivateKey); !ok && r.sealController.IsSealed() {
+		return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: %w", appmodels.ErrSealed)
+	}
+
+	unsealed, err := apputils.UnsealSshAuthorityKey(r.certManager, r.sealController, authority)
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: %w", err)
+	}
+	return unsealed, nil
+}
+
+func (r *SealedSshAuthorityRepository) Save(authority appmodels.SshAuthority) (appmodels.SshAuthority, error) {
+	sealed, err := apputils.SealSshAuthorityKey(r.certManager, r.sealController, authority)
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:Save]: %w", err)
+	}
+	if _, err := r.repository.Save(sealed); err != nil {
+		return nil, err
+	}
+	return authority, nil
+}
+
+// NewSshAuthorityRepository creates an SSH CA repository which encrypts the
+// CA keys of another repository with the seal key
+//   - repository: The repository which stores the encrypted keys
+//   - sealController: The seal controller which has the seal key
+//   - certManager: The certificate manager
+func NewSshAuthorityRepository(
+	repository appmodels.SshAuthorityRepository,
+	sealController appmodels.SealController,
+	certManager managers.CertificateManager,
+) *SealedSshAuthorityRepository {
+	return &SealedSshAuthorityRepository{
+		repository:     repository,
+		sealController: sealController,
+		certManager:    certManager,
+	}
+}
+
+var _ appmodels.SshAuthorityRepository = (*SealedSshAuthorityRepository)(nil)
@@ -0,0 +1,66 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sealedrepository_test
+
+import (
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sealedrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestSealedSshAuthorityRepository(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	dir := t.TempDir()
+	inner := filerepository.NewSshAuthorityRepository(certManager, managers.NewFileManager(), dir)
+	sealController := newTestSealController(t)
+	repository := sealedrepository.NewSshAuthorityRepository(inner, sealController, certManager)
+
+	organization := big.NewInt(10)
+	key, err := apputils.GeneratePrivateKey(organization, big.NewInt(1), appmodels.Ed25519)
+	require.NoError(t, err)
+	authority := appmodels.NewSshAuthority(organization, big.NewInt(1), key, time.Now())
+	saved, err := repository.Save(authority)
+	require.NoError(t, err)
+	assert.Same(t, authority, saved)
+
+	// The CA key is encrypted at rest
+	data, err := os.ReadFile(filerepository.SshAuthorityJsonPath(dir, organization))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), apputils.SealedPrivateKeyPemType)
+	stored, err := inner.FindByOrganization(organization)
+	require.NoError(t, err)
+	assert.Implements(t, (*appmodels.SealedPrivateKey)(nil), stored.PrivateKey().PrivateKey())
+
+	found, err := repository.FindByOrganization(organization)
+	require.NoError(t, err)
+	assert.Equal(t, key.PrivateKey(), found.PrivateKey().PrivateKey())
+	assert.Equal(t, authority.ID(), found.ID())
+
+	sealController.Seal()
+	_, err = repository.FindByOrganization(organization)
+	assert.ErrorIs(t, err, appmodels.ErrSealed)
+	_, err = repository.Save(authority)
+	assert.ErrorIs(t, err, appmodels.ErrSealed)
+	_, err = repository.FindByOrganization(big.NewInt(11))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, appmodels.ErrSealed)
+
+	// A CA key stored before sealing was enabled is not released while
+	// sealed
+	plainKey, err := apputils.GeneratePrivateKey(big.NewInt(12), big.NewInt(2), appmodels.Ed25519)
+	require.NoError(t, err)
+	_, err = inner.Save(appmodels.NewSshAuthority(big.NewInt(12), big.NewInt(2), plainKey, time.Now()))
+	require.NoError(t, err)
+	_, err = repository.FindByOrganization(big.NewInt(12))
+	assert.ErrorIs(t, err, appmodels.ErrSealed)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sealedrepository
+
+import (
+	"fmt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// SealedUnitOfWorkRepository implements appmodels.UnitOfWorkRepository over
+// another unit of work repository. Private keys are encrypted with the seal
+// key before they are saved.
+type SealedUnitOfWorkRepository struct {
+	repository     appmodels.UnitOfWorkRepository
+	sealController appmodels.SealController
+	certManager    managers.CertificateManager
+}
+
+func (r *SealedUnitOfWorkRepository) NewUnitOfWork() appmodels.UnitOfWork {
+	return &SealedUnitOfWork{
+		work:       r.repository.NewUnitOfWork(),
+		repository: r,
+	}
+}
+
+// SealedUnitOfWork implements appmodels.UnitOfWork. A private key which
+// cannot be encrypted fails the commit, so nothing is stored.
+type SealedUnitOfWork struct {
+	work       appmodels.UnitOfWork
+	repository *SealedUnitOfWorkRepository
+
+	// err is the first error of encrypting a private key
+	err error
+}
+
+func (w *SealedUnitOfWork) SaveCertificate(certificate appmodels.Certificate) {
+	w.work.SaveCertificate(certificate)
+}
+
+func (w *SealedUnitOfWork) SavePrivateKey(key appmodels.PrivateKey) {
+	if w.err != nil {
+		return
+	}
+	sealed, err := apputils.SealPrivateKey(w.repository.certManager, w.repository.sealController, key)
+	if err != nil {
+		w.err = err
+		return
+	}
+	w.work.SavePrivateKey(sealed)
+}
+
+func (w *SealedUnitOfWork) Commit() error {
+	if w.err != nil {
+		return fmt.Errorf("[UnitOfWork:Commit]: %w", w.err)
+	}
+	return w.work.Commit()
+}
+
+// NewUnitOfWorkRepository creates units of work which encrypt private keys
+// with the seal key
+//   - repository: The repository which stores the encrypted keys
+//   - sealController: The seal controller which has the seal key
+//   - certManager: The certificate manager
+func NewUnitOfWorkRepository(
+	repository appmodels.UnitOfWorkRepository,
+	sealController appmodels.SealController,
+	certManager managers.CertificateManager,
+) *SealedUnitOfWorkRepository {
+	return &SealedUnitOfWorkRepository{
+		repository:     repository,
+		sealController: sealController,
+		certManager:    certManager,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ appmodels.UnitOfWorkRepository = (*SealedUnitOfWorkRepository)(nil)
+var _ appmodels.UnitOfWork = (*SealedUnitOfWork)(nil)
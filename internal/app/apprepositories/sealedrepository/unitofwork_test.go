@@ -0,0 +1,61 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sealedrepository_test
+
+import (
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/memoryrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sealedrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestSealedUnitOfWork(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	certificates := memoryrepository.NewCertificateRepository()
+	keys := memoryrepository.NewPrivateKeyRepository()
+	sealController := newTestSealController(t)
+	repository := sealedrepository.NewUnitOfWorkRepository(memoryrepository.NewUnitOfWorkRepository(certificates, keys), sealController, certManager)
+
+	organization := big.NewInt(10)
+	newCertificate := func(serialNumber int64) appmodels.Certificate {
+		return appmodels.NewCertificate(organization, nil, &x509.Certificate{
+			SerialNumber: big.NewInt(serialNumber),
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		})
+	}
+	key, err := apputils.GeneratePrivateKey(organization, big.NewInt(1), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+
+	work := repository.NewUnitOfWork()
+	work.SaveCertificate(newCertificate(1))
+	work.SavePrivateKey(key)
+	require.NoError(t, work.Commit())
+
+	stored, err := keys.FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	require.NoError(t, err)
+	assert.Implements(t, (*appmodels.SealedPrivateKey)(nil), stored.PrivateKey())
+	found, err := sealedrepository.NewPrivateKeyRepository(keys, sealController, certManager).FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, key.PrivateKey(), found.PrivateKey())
+
+	// While sealed nothing is stored
+	sealController.Seal()
+	other, err := apputils.GeneratePrivateKey(organization, big.NewInt(2), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	work = repository.NewUnitOfWork()
+	work.SaveCertificate(newCertificate(2))
+	work.SavePrivateKey(other)
+	assert.ErrorIs(t, work.Commit(), appmodels.ErrSealed)
+	_, err = certificates.FindByOrganizationAndSerialNumber(organization, big.NewInt(2))
+	assert.Error(t, err)
+}
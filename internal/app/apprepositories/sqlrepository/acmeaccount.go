@@ -0,0 +1,143 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// SqlAcmeAccountRepository implements appmodels.AcmeAccountRepository on a
+// SQL database. Accounts are stored as JSON records.
+type SqlAcmeAccountRepository struct {
+	database *Database
+}
+
+func (r *SqlAcmeAccountRepository) FindById(id string) (appmodels.AcmeAccount, error) {
+	var data string
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT record FROM acme_accounts WHERE id = ?`),
+		id,
+	).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[AcmeAccount:FindById]: not found: %s", id)
+		}
+		return nil, fmt.Errorf("[AcmeAccount:FindById]: failed to read: %w", err)
+	}
+	model, err := parseAcmeAccount(data)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlAcmeAccountRepository) FindByThumbprint(organization *big.Int, issuer *big.Int, thumbprint string) (appmodels.AcmeAccount, error) {
+	var data string
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT record FROM acme_accounts WHERE organization = ? AND issuer = ? AND thumbprint = ?`),
+		organization.String(),
+		issuer.String(),
+		thumbprint,
+	).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[AcmeAccount:FindByThumbprint]: not found: %s", thumbprint)
+		}
+		return nil, fmt.Errorf("[AcmeAccount:FindByThumbprint]: failed to read: %w", err)
+	}
+	model, err := parseAcmeAccount(data)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindByThumbprint]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlAcmeAccountRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.AcmeAccount, error) {
+	rows, err := r.database.db.Query(
+		r.database.dialect.Rebind(`SELECT record FROM acme_accounts WHERE organization = ?`),
+		organization.String(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindAllByOrganization]: failed to query: %w", err)
+	}
+	defer rows.Close()
+	list := []appmodels.AcmeAccount{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("[AcmeAccount:FindAllByOrganization]: failed to read: %w", err)
+		}
+		model, err := parseAcmeAccount(data)
+		if err != nil {
+			return nil, fmt.Errorf("[AcmeAccount:FindAllByOrganization]: %w", err)
+		}
+		list = append(list, model)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:FindAllByOrganization]: %w", err)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt().Equal(list[j].CreatedAt()) {
+			return list[i].ID() < list[j].ID()
+		}
+		return list[i].CreatedAt().Before(list[j].CreatedAt())
+	})
+	return list, nil
+}
+
+func (r *SqlAcmeAccountRepository) Save(model appmodels.AcmeAccount) (appmodels.AcmeAccount, error) {
+	dto, err := apputils.ToAcmeAccountRecordDTO(model)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:Save]: %w", err)
+	}
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:Save]: failed to marshal JSON: %w", err)
+	}
+	_, err = r.database.db.Exec(
+		r.database.dialect.Rebind(`INSERT INTO acme_accounts (id, organization, issuer, thumbprint, created_at, record)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				thumbprint = excluded.thumbprint,
+				record = excluded.record`),
+		model.ID(),
+		model.OrganizationID().String(),
+		model.IssuerSerialNumber().String(),
+		model.Thumbprint(),
+		model.CreatedAt().Unix(),
+		string(data),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAccount:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// parseAcmeAccount parses a stored account
+func parseAcmeAccount(data string) (appmodels.AcmeAccount, error) {
+	dto := appdtos.AcmeAccountRecordDTO{}
+	if err := json.Unmarshal([]byte(data), &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return apputils.FromAcmeAccountRecordDTO(dto)
+}
+
+// NewAcmeAccountRepository creates a SQL based repository for ACME accounts
+func NewAcmeAccountRepository(
+	database *Database,
+) *SqlAcmeAccountRepository {
+	return &SqlAcmeAccountRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.AcmeAccountRepository = (*SqlAcmeAccountRepository)(nil)
@@ -0,0 +1,57 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sqlrepository"
+)
+
+func TestAcmeAccountRepository_SaveAndFind(t *testing.T) {
+	database := newTestDatabase(t)
+	repo := sqlrepository.NewAcmeAccountRepository(database)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	list, err := repo.FindAllByOrganization(big.NewInt(1))
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	second := appmodels.NewAcmeAccount("a", big.NewInt(1), big.NewInt(2), appmodels.ACME_STATUS_VALID, []string{"mailto:admin@example.com"}, &key.PublicKey, "thumb1", now.Add(time.Minute))
+	first := appmodels.NewAcmeAccount("b", big.NewInt(1), big.NewInt(3), appmodels.ACME_STATUS_VALID, nil, &key.PublicKey, "thumb2", now)
+	other := appmodels.NewAcmeAccount("c", big.NewInt(2), big.NewInt(4), appmodels.ACME_STATUS_VALID, nil, &key.PublicKey, "thumb1", now)
+	for _, account := range []appmodels.AcmeAccount{second, first, other} {
+		_, err = repo.Save(account)
+		require.NoError(t, err)
+	}
+
+	// Accounts are read back by a new repository of the same database
+	repo = sqlrepository.NewAcmeAccountRepository(database)
+	found, err := repo.FindById("a")
+	require.NoError(t, err)
+	assert.Equal(t, second, found)
+
+	found, err = repo.FindByThumbprint(big.NewInt(1), big.NewInt(2), "thumb1")
+	require.NoError(t, err)
+	assert.Equal(t, second, found)
+	_, err = repo.FindByThumbprint(big.NewInt(1), big.NewInt(3), "thumb1")
+	assert.Error(t, err)
+
+	list, err = repo.FindAllByOrganization(big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, []appmodels.AcmeAccount{first, second}, list)
+
+	_, err = repo.FindById("missing")
+	assert.Error(t, err)
+}
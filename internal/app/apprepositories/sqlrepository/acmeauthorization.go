@@ -0,0 +1,75 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// SqlAcmeAuthorizationRepository implements
+// appmodels.AcmeAuthorizationRepository on a SQL database. Authorizations
+// and their challenges are stored as JSON records.
+type SqlAcmeAuthorizationRepository struct {
+	database *Database
+}
+
+func (r *SqlAcmeAuthorizationRepository) FindById(id string) (appmodels.AcmeAuthorization, error) {
+	var data string
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT record FROM acme_authorizations WHERE id = ?`),
+		id,
+	).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[AcmeAuthorization:FindById]: not found: %s", id)
+		}
+		return nil, fmt.Errorf("[AcmeAuthorization:FindById]: failed to read: %w", err)
+	}
+	dto := appdtos.AcmeAuthorizationRecordDTO{}
+	if err := json.Unmarshal([]byte(data), &dto); err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:FindById]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromAcmeAuthorizationRecordDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlAcmeAuthorizationRepository) Save(model appmodels.AcmeAuthorization) (appmodels.AcmeAuthorization, error) {
+	data, err := json.Marshal(apputils.ToAcmeAuthorizationRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:Save]: failed to marshal JSON: %w", err)
+	}
+	_, err = r.database.db.Exec(
+		r.database.dialect.Rebind(`INSERT INTO acme_authorizations (id, record)
+			VALUES (?, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				record = excluded.record`),
+		model.ID(),
+		string(data),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeAuthorization:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewAcmeAuthorizationRepository creates a SQL based repository for ACME
+// authorizations
+func NewAcmeAuthorizationRepository(
+	database *Database,
+) *SqlAcmeAuthorizationRepository {
+	return &SqlAcmeAuthorizationRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.AcmeAuthorizationRepository = (*SqlAcmeAuthorizationRepository)(nil)
@@ -0,0 +1,106 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// SqlAcmeOrderRepository implements appmodels.AcmeOrderRepository on a SQL
+// database. Orders are stored as JSON records.
+type SqlAcmeOrderRepository struct {
+	database *Database
+}
+
+func (r *SqlAcmeOrderRepository) FindById(id string) (appmodels.AcmeOrder, error) {
+	var data string
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT record FROM acme_orders WHERE id = ?`),
+		id,
+	).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[AcmeOrder:FindById]: not found: %s", id)
+		}
+		return nil, fmt.Errorf("[AcmeOrder:FindById]: failed to read: %w", err)
+	}
+	model, err := parseAcmeOrder(data)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlAcmeOrderRepository) FindAllByAccount(account string) ([]appmodels.AcmeOrder, error) {
+	rows, err := r.database.db.Query(
+		r.database.dialect.Rebind(`SELECT record FROM acme_orders WHERE account = ? ORDER BY id`),
+		account,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:FindAllByAccount]: failed to query: %w", err)
+	}
+	defer rows.Close()
+	list := []appmodels.AcmeOrder{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("[AcmeOrder:FindAllByAccount]: failed to read: %w", err)
+		}
+		model, err := parseAcmeOrder(data)
+		if err != nil {
+			return nil, fmt.Errorf("[AcmeOrder:FindAllByAccount]: %w", err)
+		}
+		list = append(list, model)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:FindAllByAccount]: %w", err)
+	}
+	return list, nil
+}
+
+func (r *SqlAcmeOrderRepository) Save(model appmodels.AcmeOrder) (appmodels.AcmeOrder, error) {
+	data, err := json.Marshal(apputils.ToAcmeOrderRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:Save]: failed to marshal JSON: %w", err)
+	}
+	_, err = r.database.db.Exec(
+		r.database.dialect.Rebind(`INSERT INTO acme_orders (id, account, record)
+			VALUES (?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				record = excluded.record`),
+		model.ID(),
+		model.AccountID(),
+		string(data),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[AcmeOrder:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// parseAcmeOrder parses a stored order
+func parseAcmeOrder(data string) (appmodels.AcmeOrder, error) {
+	dto := appdtos.AcmeOrderRecordDTO{}
+	if err := json.Unmarshal([]byte(data), &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return apputils.FromAcmeOrderRecordDTO(dto)
+}
+
+// NewAcmeOrderRepository creates a SQL based repository for ACME orders
+func NewAcmeOrderRepository(
+	database *Database,
+) *SqlAcmeOrderRepository {
+	return &SqlAcmeOrderRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.AcmeOrderRepository = (*SqlAcmeOrderRepository)(nil)
@@ -0,0 +1,479 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// SqlCertificateRepository implements models.CertificateRepository on a SQL
+// database. Certificates are stored as DER.
+type SqlCertificateRepository struct {
+	certManager managers.CertificateManager
+	database    *Database
+}
+
+func (r *SqlCertificateRepository) FindAllByOrganizationAndSignedBy(organization *big.Int, certificate *big.Int) ([]appmodels.Certificate, error) {
+	if certificate == nil {
+		return r.findAll(
+			`SELECT serial, signed_by, certificate FROM certificates WHERE organization = ? AND signed_by IS NULL`,
+			organization,
+			organization.String(),
+		)
+	}
+	return r.findAll(
+		`SELECT serial, signed_by, certificate FROM certificates WHERE organization = ? AND signed_by = ?`,
+		organization,
+		organization.String(),
+		certificate.String(),
+	)
+}
+
+func (r *SqlCertificateRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.Certificate, error) {
+	return r.findAll(
+		`SELECT serial, signed_by, certificate FROM certificates WHERE organization = ?`,
+		organization,
+		organization.String(),
+	)
+}
+
+// FindPageByOrganization returns a page of certificates of an organization.
+// The filters, the order and the cursor are evaluated by the database. The
+// archive has the same columns in the archived_certificates table.
+func (r *SqlCertificateRepository) FindPageByOrganization(organization *big.Int, query appmodels.CertificateQuery) (appmodels.CertificatePage, error) {
+
+	conditions := []string{"organization = ?"}
+	args := []any{organization.String()}
+
+	if query.SignedBy != nil {
+		conditions = append(conditions, "signed_by = ?")
+		args = append(args, query.SignedBy.String())
+	}
+
+	if column := certificateTypeColumn(query.Type); column != "" {
+		conditions = append(conditions, column+" = 1")
+	}
+
+	switch query.Status {
+	case appmodels.VALID_CERTIFICATE:
+		conditions = append(conditions, "not_before <= ? AND not_after >= ?")
+		args = append(args, query.Now.Unix(), query.Now.Unix())
+	case appmodels.EXPIRED_CERTIFICATE:
+		conditions = append(conditions, "not_after < ?")
+		args = append(args, query.Now.Unix())
+	}
+
+	if !query.ExpiringBefore.IsZero() {
+		conditions = append(conditions, "not_after < ?")
+		args = append(args, query.ExpiringBefore.Unix())
+	}
+
+	if query.Name != "" {
+		conditions = append(conditions, `search_names LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(strings.ToLower(query.Name))+"%")
+	}
+
+	conditions, args = appendSearchConditions(conditions, args, query)
+
+	column := certificateSortColumn(query.Sort)
+	direction, comparison := "ASC", ">"
+	if query.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if cursor := query.After; cursor != nil {
+		serial := cursor.SerialNumber.String()
+		condition := "(LENGTH(serial) " + comparison + " ? OR (LENGTH(serial) = ? AND serial " + comparison + " ?))"
+		serialArgs := []any{len(serial), len(serial), serial}
+		if column != "" {
+			var value any = cursor.Time
+			if query.Sort == appmodels.SORT_BY_COMMON_NAME {
+				value = cursor.CommonName
+			}
+			condition = "(" + column + " " + comparison + " ? OR (" + column + " = ? AND " + condition + "))"
+			serialArgs = append([]any{value, value}, serialArgs...)
+		}
+		conditions = append(conditions, condition)
+		args = append(args, serialArgs...)
+	}
+
+	// Serial numbers are decimal strings, so ordering by the length first
+	// gives the numeric order
+	order := "LENGTH(serial) " + direction + ", serial " + direction
+	if column != "" {
+		order = column + " " + direction + ", " + order
+	}
+
+	table := "certificates"
+	if query.Archived {
+		table = "archived_certificates"
+	}
+	statement := `SELECT serial, signed_by, certificate FROM ` + table + ` WHERE ` +
+		strings.Join(conditions, " AND ") + ` ORDER BY ` + order
+	if query.Limit > 0 {
+		statement += ` LIMIT ?`
+		args = append(args, query.Limit+1)
+	}
+
+	rows, err := r.database.db.Query(r.database.dialect.Rebind(statement), args...)
+	if err != nil {
+		return appmodels.CertificatePage{}, fmt.Errorf("[Certificate:FindPageByOrganization]: query failed: %w", err)
+	}
+	defer rows.Close()
+
+	page := appmodels.CertificatePage{
+		Certificates: make([]appmodels.Certificate, 0),
+	}
+	for rows.Next() {
+		if query.Limit > 0 && len(page.Certificates) == query.Limit {
+			page.Next = appmodels.NewCertificateCursor(query, page.Certificates[len(page.Certificates)-1])
+			break
+		}
+		model, err := r.scanCertificate(organization, rows)
+		if err != nil {
+			return appmodels.CertificatePage{}, fmt.Errorf("[Certificate:FindPageByOrganization]: %w", err)
+		}
+		page.Certificates = append(page.Certificates, model)
+	}
+	if err := rows.Err(); err != nil {
+		return appmodels.CertificatePage{}, fmt.Errorf("[Certificate:FindPageByOrganization]: query failed: %w", err)
+	}
+	return page, nil
+}
+
+func (r *SqlCertificateRepository) FindByOrganizationAndSerialNumber(
+	organization *big.Int,
+	certificate *big.Int,
+) (appmodels.Certificate, error) {
+	if certificate == nil {
+		return nil, errors.New("no certificate serial number provided")
+	}
+	row := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT serial, signed_by, certificate FROM certificates WHERE organization = ? AND serial = ?`),
+		organization.String(),
+		certificate.String(),
+	)
+	model, err := r.scanCertificate(organization, row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[Certificate:FindByOrganizationAndSerialNumber]: not found: %s/%s", organization, certificate)
+		}
+		return nil, fmt.Errorf("[Certificate:FindByOrganizationAndSerialNumber]: %w", err)
+	}
+	return model, nil
+}
+
+// Archive moves a certificate row to the archived_certificates table
+func (r *SqlCertificateRepository) Archive(organization *big.Int, certificate *big.Int) error {
+	if certificate == nil {
+		return errors.New("no certificate serial number provided")
+	}
+	dialect := r.database.dialect
+	err := withTransaction(r.database.db, func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			dialect.Rebind(`INSERT INTO archived_certificates (`+certificateColumnNames+`)
+				SELECT `+certificateColumnNames+` FROM certificates WHERE organization = ? AND serial = ?`),
+			organization.String(),
+			certificate.String(),
+		)
+		if err != nil {
+			return err
+		}
+		if count, err := result.RowsAffected(); err != nil {
+			return err
+		} else if count == 0 {
+			return fmt.Errorf("not found: %s/%s", organization, certificate)
+		}
+		_, err = tx.Exec(
+			dialect.Rebind(`DELETE FROM certificates WHERE organization = ? AND serial = ?`),
+			organization.String(),
+			certificate.String(),
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("[Certificate:Archive]: %w", err)
+	}
+	return nil
+}
+
+func (r *SqlCertificateRepository) Save(certificate appmodels.Certificate) (appmodels.Certificate, error) {
+	organization := certificate.OrganizationID()
+	serialNumber := certificate.SerialNumber()
+	err := withTransaction(r.database.db, func(tx *sql.Tx) error {
+		return saveCertificate(tx, r.database.dialect, certificate)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save certificate: %w", err)
+	}
+	return r.FindByOrganizationAndSerialNumber(organization, serialNumber)
+}
+
+// saveCertificate inserts or updates a certificate in a transaction
+func saveCertificate(tx *sql.Tx, dialect Dialect, certificate appmodels.Certificate) error {
+	var signedBy sql.NullString
+	if issuer := certificate.SignedBy(); issuer != nil {
+		signedBy = sql.NullString{String: issuer.String(), Valid: true}
+	}
+	cert := certificate.Certificate()
+	columns := newCertificateColumns(certificate)
+	_, err := tx.Exec(
+		dialect.Rebind(`INSERT INTO certificates (organization, serial, signed_by, not_after, certificate,
+				not_before, common_name, search_names, is_root, is_intermediate, is_server, is_client,
+				fingerprint, subject_key_id, authority_key_id, key_type, issuer_name, san_names)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (organization, serial) DO UPDATE SET
+				signed_by = excluded.signed_by,
+				not_after = excluded.not_after,
+				certificate = excluded.certificate,
+				not_before = excluded.not_before,
+				common_name = excluded.common_name,
+				search_names = excluded.search_names,
+				is_root = excluded.is_root,
+				is_intermediate = excluded.is_intermediate,
+				is_server = excluded.is_server,
+				is_client = excluded.is_client,
+				fingerprint = excluded.fingerprint,
+				subject_key_id = excluded.subject_key_id,
+				authority_key_id = excluded.authority_key_id,
+				key_type = excluded.key_type,
+				issuer_name = excluded.issuer_name,
+				san_names = excluded.san_names`),
+		certificate.OrganizationID().String(),
+		certificate.SerialNumber().String(),
+		signedBy,
+		cert.NotAfter.Unix(),
+		cert.Raw,
+		columns.notBefore,
+		columns.commonName,
+		columns.searchNames,
+		columns.isRoot,
+		columns.isIntermediate,
+		columns.isServer,
+		columns.isClient,
+		columns.fingerprint,
+		columns.subjectKeyId,
+		columns.authorityKeyId,
+		columns.keyType,
+		columns.issuerName,
+		columns.sanNames,
+	)
+	return err
+}
+
+// certificateColumnNames lists the columns of the certificates and the
+// archived_certificates tables
+const certificateColumnNames = `organization, serial, signed_by, not_after, certificate,
+	not_before, common_name, search_names, is_root, is_intermediate, is_server, is_client,
+	fingerprint, subject_key_id, authority_key_id, key_type, issuer_name, san_names`
+
+// certificateColumns are the values of the query columns of a certificate,
+// which are derived from the DER data
+type certificateColumns struct {
+	notBefore      int64
+	commonName     string
+	searchNames    string
+	isRoot         int
+	isIntermediate int
+	isServer       int
+	isClient       int
+	fingerprint    string
+	subjectKeyId   string
+	authorityKeyId string
+	keyType        string
+	issuerName     string
+	sanNames       string
+}
+
+func newCertificateColumns(certificate appmodels.Certificate) certificateColumns {
+	cert := certificate.Certificate()
+	return certificateColumns{
+		notBefore:      certificate.NotBefore().Unix(),
+		commonName:     certificate.CommonName(),
+		searchNames:    strings.Join(apputils.CertificateSearchNames(certificate.Certificate()), "\n"),
+		isRoot:         boolColumn(certificate.IsRootCertificate()),
+		isIntermediate: boolColumn(certificate.IsIntermediateCertificate()),
+		isServer:       boolColumn(certificate.IsServerCertificate()),
+		isClient:       boolColumn(certificate.IsClientCertificate()),
+		fingerprint:    apputils.CertificateFingerprint(certificate),
+		subjectKeyId:   hex.EncodeToString(cert.SubjectKeyId),
+		authorityKeyId: hex.EncodeToString(cert.AuthorityKeyId),
+		keyType:        keyTypeColumn(apputils.CertificateKeyType(cert)),
+		issuerName:     strings.ToLower(cert.Issuer.String()),
+		sanNames:       strings.Join(apputils.CertificateSubjectAlternativeNames(cert), "\n"),
+	}
+}
+
+// keyTypeColumn returns the name of a key type, or an empty string for an
+// unsupported key
+func keyTypeColumn(keyType appmodels.KeyType) string {
+	if keyType == appmodels.NIL_KEY_TYPE {
+		return ""
+	}
+	return keyType.String()
+}
+
+func boolColumn(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// findAll returns certificates of an organization in ascending serial number
+// order
+func (r *SqlCertificateRepository) findAll(query string, organization *big.Int, args ...any) ([]appmodels.Certificate, error) {
+	rows, err := r.database.db.Query(r.database.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("[Certificate:FindAll]: query failed: %w", err)
+	}
+	defer rows.Close()
+
+	list := make([]appmodels.Certificate, 0)
+	for rows.Next() {
+		model, err := r.scanCertificate(organization, rows)
+		if err != nil {
+			return nil, fmt.Errorf("[Certificate:FindAll]: %w", err)
+		}
+		list = append(list, model)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[Certificate:FindAll]: query failed: %w", err)
+	}
+
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].SerialNumber().Cmp(list[b].SerialNumber()) < 0
+	})
+	return list, nil
+}
+
+func (r *SqlCertificateRepository) scanCertificate(organization *big.Int, row rowScanner) (appmodels.Certificate, error) {
+	var serial string
+	var signedBy sql.NullString
+	var der []byte
+	if err := row.Scan(&serial, &signedBy, &der); err != nil {
+		return nil, err
+	}
+	cert, err := r.certManager.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate '%s': %w", serial, err)
+	}
+	var issuer *big.Int
+	if signedBy.Valid {
+		issuer, err = apputils.ParseBigInt(signedBy.String, 10)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse issuer of certificate '%s': %w", serial, err)
+		}
+	}
+	return appmodels.NewCertificate(organization, issuer, cert), nil
+}
+
+// NewCertificateRepository creates a SQL based repository for certificates
+func NewCertificateRepository(
+	certManager managers.CertificateManager,
+	database *Database,
+) *SqlCertificateRepository {
+	return &SqlCertificateRepository{
+		certManager: certManager,
+		database:    database,
+	}
+}
+
+// appendSearchConditions adds the search criteria of a query to the WHERE
+// conditions
+func appendSearchConditions(conditions []string, args []any, query appmodels.CertificateQuery) ([]string, []any) {
+	if query.CommonName != "" {
+		conditions = append(conditions, `LOWER(common_name) LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(strings.ToLower(query.CommonName))+"%")
+	}
+	if query.SubjectAlternativeName != "" {
+		conditions = append(conditions, `san_names LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(strings.ToLower(query.SubjectAlternativeName))+"%")
+	}
+	if query.Fingerprint != "" {
+		conditions = append(conditions, "fingerprint = ?")
+		args = append(args, query.Fingerprint)
+	}
+	if query.SubjectKeyId != nil {
+		conditions = append(conditions, "subject_key_id = ?")
+		args = append(args, hex.EncodeToString(query.SubjectKeyId))
+	}
+	if query.AuthorityKeyId != nil {
+		conditions = append(conditions, "authority_key_id = ?")
+		args = append(args, hex.EncodeToString(query.AuthorityKeyId))
+	}
+	if query.SerialNumber != nil {
+		conditions = append(conditions, "serial = ?")
+		args = append(args, query.SerialNumber.String())
+	}
+	if query.KeyType != appmodels.NIL_KEY_TYPE {
+		conditions = append(conditions, "key_type = ?")
+		args = append(args, query.KeyType.String())
+	}
+	if query.Issuer != "" {
+		conditions = append(conditions, `issuer_name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(strings.ToLower(query.Issuer))+"%")
+	}
+	if !query.IssuedAfter.IsZero() {
+		conditions = append(conditions, "not_before >= ?")
+		args = append(args, query.IssuedAfter.Unix())
+	}
+	if !query.IssuedBefore.IsZero() {
+		conditions = append(conditions, "not_before < ?")
+		args = append(args, query.IssuedBefore.Unix())
+	}
+	if !query.ExpiringAfter.IsZero() {
+		conditions = append(conditions, "not_after >= ?")
+		args = append(args, query.ExpiringAfter.Unix())
+	}
+	return conditions, args
+}
+
+// certificateTypeColumn returns the flag column of a certificate type, or an
+// empty string if every certificate matches
+func certificateTypeColumn(t appmodels.CertificateType) string {
+	switch t {
+	case appmodels.ROOT_CERTIFICATE:
+		return "is_root"
+	case appmodels.INTERMEDIATE_CERTIFICATE:
+		return "is_intermediate"
+	case appmodels.SERVER_CERTIFICATE:
+		return "is_server"
+	case appmodels.CLIENT_CERTIFICATE:
+		return "is_client"
+	default:
+		return ""
+	}
+}
+
+// certificateSortColumn returns the column of a sort field, or an empty
+// string when sorting by the serial number only
+func certificateSortColumn(f appmodels.CertificateSortField) string {
+	switch f {
+	case appmodels.SORT_BY_NOT_AFTER:
+		return "not_after"
+	case appmodels.SORT_BY_CREATED_AT:
+		return "not_before"
+	case appmodels.SORT_BY_COMMON_NAME:
+		return "common_name"
+	default:
+		return ""
+	}
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern using a backslash
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+var _ appmodels.CertificateRepository = (*SqlCertificateRepository)(nil)
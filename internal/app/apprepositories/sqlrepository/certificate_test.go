@@ -0,0 +1,284 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sqlrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func serialNumbersOf(list []appmodels.Certificate) []*big.Int {
+	result := make([]*big.Int, 0, len(list))
+	for _, cert := range list {
+		result = append(result, cert.SerialNumber())
+	}
+	return result
+}
+
+func TestSqlCertificateRepository(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	collection := sqlrepository.NewCollection(certManager, newTestDatabase(t))
+	appController := appcontrollers.NewApplicationController(
+		collection.Organization,
+		collection.Certificate,
+		collection.PrivateKey,
+		collection.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization := big.NewInt(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	organizationController.SetExpirationDuration(24 * time.Hour)
+
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	server, _, err := rootController.NewServerCertificate("server.example.com")
+	require.NoError(t, err)
+	client, _, err := rootController.NewClientCertificate("alice")
+	require.NoError(t, err)
+
+	repo := collection.Certificate
+
+	found, err := repo.FindByOrganizationAndSerialNumber(organization, root.SerialNumber())
+	require.NoError(t, err)
+	assert.Nil(t, found.SignedBy())
+	assert.Equal(t, root.Certificate().Raw, found.Certificate().Raw)
+	assert.Equal(t, organization, found.OrganizationID())
+
+	found, err = repo.FindByOrganizationAndSerialNumber(organization, server.SerialNumber())
+	require.NoError(t, err)
+	assert.Equal(t, 0, root.SerialNumber().Cmp(found.SignedBy()))
+
+	list, err := repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Len(t, list, 3)
+	for i := 1; i < len(list); i++ {
+		assert.Negative(t, list[i-1].SerialNumber().Cmp(list[i].SerialNumber()), "Expected ascending serial numbers")
+	}
+
+	list, err = repo.FindAllByOrganizationAndSignedBy(organization, root.SerialNumber())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*big.Int{server.SerialNumber(), client.SerialNumber()}, serialNumbersOf(list))
+
+	list, err = repo.FindAllByOrganizationAndSignedBy(organization, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*big.Int{root.SerialNumber()}, serialNumbersOf(list))
+
+	list, err = repo.FindAllByOrganization(big.NewInt(999))
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	_, err = repo.FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	assert.ErrorContains(t, err, "not found")
+
+	_, err = repo.FindByOrganizationAndSerialNumber(organization, nil)
+	assert.EqualError(t, err, "no certificate serial number provided")
+
+	// The root private key was saved by the organization controller
+	_, err = collection.PrivateKey.FindByOrganizationAndSerialNumber(organization, root.SerialNumber())
+	assert.NoError(t, err)
+}
+
+func TestSqlCertificateRepository_FindPageByOrganization(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	collection := sqlrepository.NewCollection(certManager, newTestDatabase(t))
+	appController := appcontrollers.NewApplicationController(
+		collection.Organization,
+		collection.Certificate,
+		collection.PrivateKey,
+		collection.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization := big.NewInt(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	organizationController.SetExpirationDuration(24 * time.Hour)
+
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	intermediate, _, err := rootController.NewIntermediateCertificate("Test Intermediate")
+	require.NoError(t, err)
+	serverB, _, err := rootController.NewServerCertificate("b.example.com")
+	require.NoError(t, err)
+	serverA, _, err := rootController.NewServerCertificate("a.example.com")
+	require.NoError(t, err)
+	client, _, err := rootController.NewClientCertificate("alice")
+	require.NoError(t, err)
+
+	repo := collection.Certificate
+	find := func(query appmodels.CertificateQuery) appmodels.CertificatePage {
+		t.Helper()
+		page, err := repo.FindPageByOrganization(organization, query)
+		require.NoError(t, err)
+		return page
+	}
+
+	// Servers by common name, one per page
+	query := appmodels.CertificateQuery{
+		SignedBy: root.SerialNumber(),
+		Type:     appmodels.SERVER_CERTIFICATE,
+		Sort:     appmodels.SORT_BY_COMMON_NAME,
+		Limit:    1,
+	}
+	page := find(query)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{serverA}), serialNumbersOf(page.Certificates))
+	require.NotNil(t, page.Next)
+	query.After = page.Next
+	page = find(query)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{serverB}), serialNumbersOf(page.Certificates))
+	assert.Nil(t, page.Next)
+
+	page = find(appmodels.CertificateQuery{Type: appmodels.INTERMEDIATE_CERTIFICATE})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{intermediate}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{Type: appmodels.ROOT_CERTIFICATE})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{root}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{Name: "ALI"})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{client}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{Name: "%"})
+	assert.Empty(t, page.Certificates)
+
+	page = find(appmodels.CertificateQuery{Status: appmodels.VALID_CERTIFICATE, Now: time.Now()})
+	assert.Len(t, page.Certificates, 5)
+
+	page = find(appmodels.CertificateQuery{Status: appmodels.EXPIRED_CERTIFICATE, Now: time.Now()})
+	assert.Empty(t, page.Certificates)
+
+	page = find(appmodels.CertificateQuery{ExpiringBefore: client.NotAfter().Add(time.Second)})
+	assert.Contains(t, serialNumbersOf(page.Certificates), serialNumbersOf([]appmodels.Certificate{client})[0])
+	for _, cert := range page.Certificates {
+		assert.True(t, cert.NotAfter().Before(client.NotAfter().Add(time.Second)))
+	}
+
+	// Search criteria
+	page = find(appmodels.CertificateQuery{CommonName: "EXAMPLE", SubjectAlternativeName: "a.example"})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{serverA}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{Fingerprint: apputils.CertificateFingerprint(serverB)})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{serverB}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{SubjectKeyId: root.Certificate().SubjectKeyId})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{root}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{AuthorityKeyId: root.Certificate().SubjectKeyId, Type: appmodels.CLIENT_CERTIFICATE})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{client}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{SerialNumber: intermediate.SerialNumber()})
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{intermediate}), serialNumbersOf(page.Certificates))
+
+	page = find(appmodels.CertificateQuery{KeyType: apputils.CertificateKeyType(root.Certificate())})
+	assert.Len(t, page.Certificates, 5)
+
+	page = find(appmodels.CertificateQuery{Issuer: "cn=test intermediate"})
+	assert.Empty(t, page.Certificates)
+
+	page = find(appmodels.CertificateQuery{Issuer: "test root", IssuedAfter: root.NotBefore(), IssuedBefore: time.Now().Add(time.Hour), ExpiringAfter: time.Now()})
+	assert.Len(t, page.Certificates, 5)
+
+	page = find(appmodels.CertificateQuery{IssuedBefore: root.NotBefore()})
+	assert.Empty(t, page.Certificates)
+
+	// Walking all pages in descending order returns every certificate once
+	all, err := repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	var expected []appmodels.Certificate
+	for i := len(all) - 1; i >= 0; i-- {
+		expected = append(expected, all[i])
+	}
+	var walked []appmodels.Certificate
+	query = appmodels.CertificateQuery{Descending: true, Limit: 2}
+	for {
+		page = find(query)
+		walked = append(walked, page.Certificates...)
+		if page.Next == nil {
+			break
+		}
+		query.After = page.Next
+	}
+	assert.Equal(t, serialNumbersOf(expected), serialNumbersOf(walked))
+}
+
+func TestSqlCertificateRepository_Archive(t *testing.T) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	collection := sqlrepository.NewCollection(certManager, newTestDatabase(t))
+	appController := appcontrollers.NewApplicationController(
+		collection.Organization,
+		collection.Certificate,
+		collection.PrivateKey,
+		collection.UnitOfWork,
+		certManager,
+		randomManager,
+		time.Hour,
+	)
+
+	organization := big.NewInt(10)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(organization)
+	require.NoError(t, err)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	rootController, err := organizationController.CertificateController(root.SerialNumber())
+	require.NoError(t, err)
+	archived, _, err := rootController.NewServerCertificate("old.example.com")
+	require.NoError(t, err)
+	kept, _, err := rootController.NewServerCertificate("new.example.com")
+	require.NoError(t, err)
+
+	repo := collection.Certificate
+	require.NoError(t, repo.Archive(organization, archived.SerialNumber()))
+	assert.Error(t, repo.Archive(organization, archived.SerialNumber()))
+	assert.Error(t, repo.Archive(organization, big.NewInt(12345)))
+
+	_, err = repo.FindByOrganizationAndSerialNumber(organization, archived.SerialNumber())
+	assert.Error(t, err)
+	list, err := repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, serialNumbersOf([]appmodels.Certificate{root, kept}), serialNumbersOf(list))
+	list, err = repo.FindAllByOrganizationAndSignedBy(organization, root.SerialNumber())
+	require.NoError(t, err)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{kept}), serialNumbersOf(list))
+
+	page, err := repo.FindPageByOrganization(organization, appmodels.CertificateQuery{Type: appmodels.SERVER_CERTIFICATE})
+	require.NoError(t, err)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{kept}), serialNumbersOf(page.Certificates))
+
+	page, err = repo.FindPageByOrganization(organization, appmodels.CertificateQuery{Archived: true, SignedBy: root.SerialNumber(), Name: "old"})
+	require.NoError(t, err)
+	require.Len(t, page.Certificates, 1)
+	assert.Equal(t, serialNumbersOf([]appmodels.Certificate{archived}), serialNumbersOf(page.Certificates))
+	assert.Equal(t, root.SerialNumber(), page.Certificates[0].SignedBy())
+
+	page, err = repo.FindPageByOrganization(big.NewInt(11), appmodels.CertificateQuery{Archived: true})
+	require.NoError(t, err)
+	assert.Empty(t, page.Certificates)
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// SqlCertificateRevocationRepository implements
+// appmodels.CertificateRevocationRepository on a SQL database
+type SqlCertificateRevocationRepository struct {
+	database *Database
+}
+
+func (r *SqlCertificateRevocationRepository) FindAllByOrganizationAndIssuer(organization *big.Int, issuer *big.Int) ([]appmodels.CertificateRevocation, error) {
+	rows, err := r.database.db.Query(
+		r.database.dialect.Rebind(`SELECT serial, revoked_at, expires_at FROM certificate_revocations WHERE organization = ? AND issuer = ?`),
+		organization.String(),
+		issuer.String(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindAllByOrganizationAndIssuer]: failed to query: %w", err)
+	}
+	defer rows.Close()
+	var list []appmodels.CertificateRevocation
+	for rows.Next() {
+		var serialNumber string
+		var revokedAt, expiresAt int64
+		if err := rows.Scan(&serialNumber, &revokedAt, &expiresAt); err != nil {
+			return nil, fmt.Errorf("[CertificateRevocation:FindAllByOrganizationAndIssuer]: failed to read: %w", err)
+		}
+		model, err := newCertificateRevocation(organization, issuer, serialNumber, revokedAt, expiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("[CertificateRevocation:FindAllByOrganizationAndIssuer]: %w", err)
+		}
+		list = append(list, model)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindAllByOrganizationAndIssuer]: %w", err)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].RevokedCertificate().SerialNumber().Cmp(list[j].RevokedCertificate().SerialNumber()) < 0
+	})
+	return list, nil
+}
+
+func (r *SqlCertificateRevocationRepository) FindByOrganizationAndSerialNumber(organization *big.Int, certificate *big.Int) (appmodels.CertificateRevocation, error) {
+	var issuer string
+	var revokedAt, expiresAt int64
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT issuer, revoked_at, expires_at FROM certificate_revocations WHERE organization = ? AND serial = ?`),
+		organization.String(),
+		certificate.String(),
+	).Scan(&issuer, &revokedAt, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[CertificateRevocation:FindByOrganizationAndSerialNumber]: not found: %s@%s", certificate, organization)
+		}
+		return nil, fmt.Errorf("[CertificateRevocation:FindByOrganizationAndSerialNumber]: failed to read: %w", err)
+	}
+	issuerSerialNumber, err := apputils.ParseBigInt(issuer, 10)
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindByOrganizationAndSerialNumber]: issuer: %w", err)
+	}
+	model, err := newCertificateRevocation(organization, issuerSerialNumber, certificate.String(), revokedAt, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindByOrganizationAndSerialNumber]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlCertificateRevocationRepository) Save(model appmodels.CertificateRevocation) (appmodels.CertificateRevocation, error) {
+	revoked := model.RevokedCertificate()
+	_, err := r.database.db.Exec(
+		r.database.dialect.Rebind(`INSERT INTO certificate_revocations (organization, serial, issuer, revoked_at, expires_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (organization, serial) DO UPDATE SET
+				issuer = excluded.issuer,
+				revoked_at = excluded.revoked_at,
+				expires_at = excluded.expires_at`),
+		model.OrganizationID().String(),
+		revoked.SerialNumber().String(),
+		model.IssuerSerialNumber().String(),
+		revoked.RevocationTime().Unix(),
+		revoked.ExpirationTime().Unix(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlCertificateRevocationRepository) FindRevocationList(organization *big.Int, issuer *big.Int) (appmodels.RevocationList, error) {
+	var number string
+	var thisUpdate int64
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT number, this_update FROM revocation_lists WHERE organization = ? AND issuer = ?`),
+		organization.String(),
+		issuer.String(),
+	).Scan(&number, &thisUpdate)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[CertificateRevocation:FindRevocationList]: not found: %s@%s", issuer, organization)
+		}
+		return nil, fmt.Errorf("[CertificateRevocation:FindRevocationList]: failed to read: %w", err)
+	}
+	crlNumber, err := apputils.ParseBigInt(number, 10)
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:FindRevocationList]: number: %w", err)
+	}
+	return appmodels.NewRevocationList(organization, issuer, crlNumber, time.Unix(thisUpdate, 0)), nil
+}
+
+func (r *SqlCertificateRevocationRepository) SaveRevocationList(model appmodels.RevocationList) (appmodels.RevocationList, error) {
+	_, err := r.database.db.Exec(
+		r.database.dialect.Rebind(`INSERT INTO revocation_lists (organization, issuer, number, this_update)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (organization, issuer) DO UPDATE SET
+				number = excluded.number,
+				this_update = excluded.this_update`),
+		model.OrganizationID().String(),
+		model.IssuerSerialNumber().String(),
+		model.Number().String(),
+		model.ThisUpdate().Unix(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[CertificateRevocation:SaveRevocationList]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// newCertificateRevocation creates a model from a row
+func newCertificateRevocation(organization, issuer *big.Int, serialNumber string, revokedAt, expiresAt int64) (appmodels.CertificateRevocation, error) {
+	certificate, err := apputils.ParseBigInt(serialNumber, 10)
+	if err != nil {
+		return nil, fmt.Errorf("serial number: %w", err)
+	}
+	return appmodels.NewCertificateRevocation(
+		organization,
+		issuer,
+		appmodels.NewRevokedCertificate(certificate, time.Unix(revokedAt, 0), time.Unix(expiresAt, 0)),
+	), nil
+}
+
+// NewCertificateRevocationRepository creates a SQL based repository for
+// certificate revocations
+func NewCertificateRevocationRepository(
+	database *Database,
+) *SqlCertificateRevocationRepository {
+	return &SqlCertificateRevocationRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.CertificateRevocationRepository = (*SqlCertificateRevocationRepository)(nil)
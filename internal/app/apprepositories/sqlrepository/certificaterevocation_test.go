@@ -0,0 +1,66 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sqlrepository"
+)
+
+func TestCertificateRevocationRepository_SaveAndFind(t *testing.T) {
+	repo := sqlrepository.NewCertificateRevocationRepository(newTestDatabase(t))
+	now := time.Unix(1000, 0).UTC()
+
+	list, err := repo.FindAllByOrganizationAndIssuer(big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	for _, model := range []appmodels.CertificateRevocation{
+		appmodels.NewCertificateRevocation(big.NewInt(1), big.NewInt(2), appmodels.NewRevokedCertificate(big.NewInt(10), now, now.Add(time.Hour))),
+		appmodels.NewCertificateRevocation(big.NewInt(1), big.NewInt(2), appmodels.NewRevokedCertificate(big.NewInt(5), now, now.Add(time.Hour))),
+		appmodels.NewCertificateRevocation(big.NewInt(1), big.NewInt(3), appmodels.NewRevokedCertificate(big.NewInt(7), now, now.Add(time.Hour))),
+	} {
+		_, err := repo.Save(model)
+		require.NoError(t, err)
+	}
+
+	found, err := repo.FindByOrganizationAndSerialNumber(big.NewInt(1), big.NewInt(10))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), found.IssuerSerialNumber())
+	assert.True(t, now.Equal(found.RevokedCertificate().RevocationTime()))
+	assert.True(t, now.Add(time.Hour).Equal(found.RevokedCertificate().ExpirationTime()))
+
+	list, err = repo.FindAllByOrganizationAndIssuer(big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, big.NewInt(5), list[0].RevokedCertificate().SerialNumber())
+	assert.Equal(t, big.NewInt(10), list[1].RevokedCertificate().SerialNumber())
+
+	_, err = repo.FindByOrganizationAndSerialNumber(big.NewInt(2), big.NewInt(10))
+	assert.ErrorContains(t, err, ": not found:")
+}
+
+func TestCertificateRevocationRepository_RevocationList(t *testing.T) {
+	repo := sqlrepository.NewCertificateRevocationRepository(newTestDatabase(t))
+	now := time.Unix(1000, 0).UTC()
+
+	_, err := repo.FindRevocationList(big.NewInt(1), big.NewInt(2))
+	assert.ErrorContains(t, err, ": not found:")
+
+	_, err = repo.SaveRevocationList(appmodels.NewRevocationList(big.NewInt(1), big.NewInt(2), big.NewInt(5), now))
+	require.NoError(t, err)
+	_, err = repo.SaveRevocationList(appmodels.NewRevocationList(big.NewInt(1), big.NewInt(2), big.NewInt(6), now))
+	require.NoError(t, err)
+
+	found, err := repo.FindRevocationList(big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(6), found.Number())
+	assert.True(t, now.Equal(found.ThisUpdate()))
+}
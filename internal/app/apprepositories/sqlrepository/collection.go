@@ -0,0 +1,30 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func NewCollection(
+	certManager managers.CertificateManager,
+	database *Database,
+) *appmodels.Collection {
+	return appmodels.NewCollection(
+		NewOrganizationRepository(database),
+		NewCertificateRepository(certManager, database),
+		NewPrivateKeyRepository(certManager, database),
+		NewUnitOfWorkRepository(certManager, database),
+		NewSshAuthorityRepository(certManager, database),
+		NewSshCertificateRepository(database),
+		NewCertificateRevocationRepository(database),
+		NewPendingOperationRepository(database),
+		NewAcmeAccountRepository(database),
+		NewAcmeOrderRepository(database),
+		NewAcmeAuthorizationRepository(database),
+		NewEstLabelRepository(database),
+		NewScepChallengeRepository(database),
+		NewVaultRoleRepository(database),
+	)
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sqlrepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/commonmocks"
+)
+
+func TestNewCollection(t *testing.T) {
+	collection := sqlrepository.NewCollection(&commonmocks.MockCertificateManager{}, newTestDatabase(t))
+	assert.NotNil(t, collection.Organization)
+	assert.NotNil(t, collection.Certificate)
+	assert.NotNil(t, collection.PrivateKey)
+	assert.NotNil(t, collection.UnitOfWork)
+	assert.NotNil(t, collection.SshAuthority)
+	assert.NotNil(t, collection.SshCertificate)
+	assert.NotNil(t, collection.CertificateRevocation)
+	assert.NotNil(t, collection.PendingOperation)
+	assert.NotNil(t, collection.AcmeAccount)
+	assert.NotNil(t, collection.AcmeOrder)
+	assert.NotNil(t, collection.AcmeAuthorization)
+	assert.NotNil(t, collection.EstLabel)
+	assert.NotNil(t, collection.ScepChallenge)
+	assert.NotNil(t, collection.VaultRole)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository_test
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sqlrepository"
+)
+
+// newTestDatabase opens a migrated SQLite database in a temporary directory
+func newTestDatabase(t *testing.T) *sqlrepository.Database {
+	t.Helper()
+	database, err := sqlrepository.OpenDatabase("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = database.Close() })
+	return database
+}
+
+func TestOpenDatabase(t *testing.T) {
+	database := newTestDatabase(t)
+	assert.Equal(t, sqlrepository.SqliteDialect, database.Dialect())
+	assert.NoError(t, database.DB().Ping())
+
+	version, err := sqlrepository.SchemaVersion(database.DB())
+	assert.NoError(t, err)
+	assert.Equal(t, sqlrepository.Migrations[len(sqlrepository.Migrations)-1].Version, version)
+}
+
+func TestOpenDatabase_Reopen(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.db")
+	database, err := sqlrepository.OpenDatabase("sqlite", fileName)
+	require.NoError(t, err)
+	require.NoError(t, database.Close())
+
+	// Migrations which have been applied are skipped
+	database, err = sqlrepository.OpenDatabase("sqlite3", fileName)
+	require.NoError(t, err)
+	assert.NoError(t, database.Close())
+}
+
+func TestOpenReadOnlyDatabase(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.db")
+	_, err := sqlrepository.OpenReadOnlyDatabase("sqlite", fileName)
+	assert.ErrorContains(t, err, "failed to read schema version", "Expected a database without a schema to be refused")
+
+	database, err := sqlrepository.OpenDatabase("sqlite", fileName)
+	require.NoError(t, err)
+	require.NoError(t, database.Close())
+
+	database, err = sqlrepository.OpenReadOnlyDatabase("sqlite", fileName)
+	require.NoError(t, err)
+	defer database.Close()
+
+	repository := sqlrepository.NewOrganizationRepository(database)
+	list, err := repository.FindAll()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	_, err = repository.Save(appmodels.NewOrganization(big.NewInt(1), "test", []string{"Test"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	assert.Error(t, err)
+}
+
+func TestOpenReadOnlyDatabase_OlderSchema(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.db")
+	migrations := sqlrepository.Migrations
+	sqlrepository.Migrations = migrations[:2]
+	database, err := sqlrepository.OpenDatabase("sqlite", fileName)
+	sqlrepository.Migrations = migrations
+	require.NoError(t, err)
+	require.NoError(t, database.Close())
+
+	_, err = sqlrepository.OpenReadOnlyDatabase("sqlite", fileName)
+	assert.ErrorContains(t, err, "must be migrated without read-only mode")
+}
+
+func TestOpenDatabase_UnsupportedDialect(t *testing.T) {
+	_, err := sqlrepository.OpenDatabase("oracle", "")
+	assert.ErrorContains(t, err, "unsupported SQL dialect")
+}
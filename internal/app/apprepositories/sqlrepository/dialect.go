@@ -0,0 +1,110 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect describes the differences between the supported SQL databases
+type Dialect struct {
+
+	// name is the name of the dialect, e.g. "sqlite" or "postgres"
+	name string
+
+	// driverName is the database/sql driver used for this dialect
+	driverName string
+
+	// blobType is the column type for binary data
+	blobType string
+
+	// numberedPlaceholders is true if query parameters are written as $1, $2,
+	// etc. instead of question marks
+	numberedPlaceholders bool
+}
+
+// SqliteDialect uses the pure Go SQLite driver for single node deployments
+var SqliteDialect = Dialect{
+	name:                 "sqlite",
+	driverName:           "sqlite",
+	blobType:             "BLOB",
+	numberedPlaceholders: false,
+}
+
+// PostgresDialect uses the pgx driver for shared deployments
+var PostgresDialect = Dialect{
+	name:                 "postgres",
+	driverName:           "pgx",
+	blobType:             "BYTEA",
+	numberedPlaceholders: true,
+}
+
+// Name returns the name of the dialect
+func (d Dialect) Name() string {
+	return d.name
+}
+
+// DriverName returns the database/sql driver name
+func (d Dialect) DriverName() string {
+	return d.driverName
+}
+
+// BlobType returns the column type for binary data
+func (d Dialect) BlobType() string {
+	return d.blobType
+}
+
+// ReadOnlyDataSourceName returns the data source name with a parameter which
+// makes every connection refuse writes
+func (d Dialect) ReadOnlyDataSourceName(dataSourceName string) string {
+	if d == PostgresDialect {
+		if !strings.Contains(dataSourceName, "://") {
+			return strings.TrimSpace(dataSourceName + " default_transaction_read_only=on")
+		}
+		return appendQueryParameter(dataSourceName, "default_transaction_read_only=on")
+	}
+	return appendQueryParameter(dataSourceName, "_pragma=query_only(1)")
+}
+
+// Rebind replaces question mark placeholders with the placeholder syntax of
+// the dialect. Queries in this package must not contain literal question
+// marks.
+func (d Dialect) Rebind(query string) string {
+	if !d.numberedPlaceholders {
+		return query
+	}
+	var builder strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		builder.WriteRune(c)
+	}
+	return builder.String()
+}
+
+// ParseDialect returns the dialect by name. Driver names and common aliases
+// are accepted as well.
+func ParseDialect(name string) (Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "sqlite", "sqlite3":
+		return SqliteDialect, nil
+	case "postgres", "postgresql", "pgx":
+		return PostgresDialect, nil
+	}
+	return Dialect{}, fmt.Errorf("ParseDialect: unsupported SQL dialect: %s", name)
+}
+
+// appendQueryParameter appends a parameter to the query string of a data
+// source name
+func appendQueryParameter(dataSourceName, parameter string) string {
+	if strings.Contains(dataSourceName, "?") {
+		return dataSourceName + "&" + parameter
+	}
+	return dataSourceName + "?" + parameter
+}
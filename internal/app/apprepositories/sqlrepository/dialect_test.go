@@ -0,0 +1,45 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sqlrepository"
+)
+
+func TestDialect_Rebind(t *testing.T) {
+	query := `SELECT a FROM b WHERE c = ? AND d = ?`
+	assert.Equal(t, query, sqlrepository.SqliteDialect.Rebind(query))
+	assert.Equal(t, `SELECT a FROM b WHERE c = $1 AND d = $2`, sqlrepository.PostgresDialect.Rebind(query))
+}
+
+func TestDialect_ReadOnlyDataSourceName(t *testing.T) {
+	assert.Equal(t, "/data/test.db?_pragma=query_only(1)", sqlrepository.SqliteDialect.ReadOnlyDataSourceName("/data/test.db"))
+	assert.Equal(t, "file:test.db?cache=shared&_pragma=query_only(1)", sqlrepository.SqliteDialect.ReadOnlyDataSourceName("file:test.db?cache=shared"))
+	assert.Equal(t, "postgres://host/db?default_transaction_read_only=on", sqlrepository.PostgresDialect.ReadOnlyDataSourceName("postgres://host/db"))
+	assert.Equal(t, "host=db user=app default_transaction_read_only=on", sqlrepository.PostgresDialect.ReadOnlyDataSourceName("host=db user=app"))
+}
+
+func TestParseDialect(t *testing.T) {
+	for name, expected := range map[string]sqlrepository.Dialect{
+		"sqlite":     sqlrepository.SqliteDialect,
+		"SQLite3":    sqlrepository.SqliteDialect,
+		"postgres":   sqlrepository.PostgresDialect,
+		"postgresql": sqlrepository.PostgresDialect,
+		"pgx":        sqlrepository.PostgresDialect,
+	} {
+		dialect, err := sqlrepository.ParseDialect(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, expected, dialect, name)
+	}
+
+	_, err := sqlrepository.ParseDialect("mysql")
+	assert.Error(t, err)
+
+	assert.Equal(t, "pgx", sqlrepository.PostgresDialect.DriverName())
+	assert.Equal(t, "BYTEA", sqlrepository.PostgresDialect.BlobType())
+	assert.Equal(t, "sqlite", sqlrepository.SqliteDialect.Name())
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// SqlEstLabelRepository implements appmodels.EstLabelRepository on a SQL
+// database. Labels are stored as JSON records.
+type SqlEstLabelRepository struct {
+	database *Database
+}
+
+func (r *SqlEstLabelRepository) FindByLabel(label string) (appmodels.EstLabel, error) {
+	var data string
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT record FROM est_labels WHERE label = ?`),
+		label,
+	).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[EstLabel:FindByLabel]: not found: %s", label)
+		}
+		return nil, fmt.Errorf("[EstLabel:FindByLabel]: failed to read: %w", err)
+	}
+	model, err := parseEstLabel(data)
+	if err != nil {
+		return nil, fmt.Errorf("[EstLabel:FindByLabel]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlEstLabelRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.EstLabel, error) {
+	rows, err := r.database.db.Query(
+		r.database.dialect.Rebind(`SELECT record FROM est_labels WHERE organization = ? ORDER BY label`),
+		organization.String(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[EstLabel:FindAllByOrganization]: failed to query: %w", err)
+	}
+	defer rows.Close()
+	list := []appmodels.EstLabel{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("[EstLabel:FindAllByOrganization]: failed to read: %w", err)
+		}
+		model, err := parseEstLabel(data)
+		if err != nil {
+			return nil, fmt.Errorf("[EstLabel:FindAllByOrganization]: %w", err)
+		}
+		list = append(list, model)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[EstLabel:FindAllByOrganization]: %w", err)
+	}
+	return list, nil
+}
+
+func (r *SqlEstLabelRepository) Save(model appmodels.EstLabel) (appmodels.EstLabel, error) {
+	data, err := json.Marshal(apputils.ToEstLabelRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[EstLabel:Save]: failed to marshal JSON: %w", err)
+	}
+	_, err = r.database.db.Exec(
+		r.database.dialect.Rebind(`INSERT INTO est_labels (label, organization, record)
+			VALUES (?, ?, ?)
+			ON CONFLICT (label) DO UPDATE SET
+				organization = excluded.organization,
+				record = excluded.record`),
+		model.Label(),
+		model.OrganizationID().String(),
+		string(data),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[EstLabel:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// parseEstLabel parses a stored label
+func parseEstLabel(data string) (appmodels.EstLabel, error) {
+	dto := appdtos.EstLabelRecordDTO{}
+	if err := json.Unmarshal([]byte(data), &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return apputils.FromEstLabelRecordDTO(dto)
+}
+
+// NewEstLabelRepository creates a SQL based repository for EST labels
+func NewEstLabelRepository(
+	database *Database,
+) *SqlEstLabelRepository {
+	return &SqlEstLabelRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.EstLabelRepository = (*SqlEstLabelRepository)(nil)
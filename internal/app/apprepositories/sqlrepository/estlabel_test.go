@@ -0,0 +1,42 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sqlrepository"
+)
+
+func TestEstLabelRepository_SaveAndFind(t *testing.T) {
+	database := newTestDatabase(t)
+	repo := sqlrepository.NewEstLabelRepository(database)
+
+	list, err := repo.FindAllByOrganization(big.NewInt(1))
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	devices := appmodels.NewEstLabel("devices", big.NewInt(1), big.NewInt(2), "user", []byte("hash"))
+	cameras := appmodels.NewEstLabel("cameras", big.NewInt(1), big.NewInt(2), "", nil)
+	other := appmodels.NewEstLabel("other", big.NewInt(2), big.NewInt(3), "", nil)
+	for _, label := range []appmodels.EstLabel{devices, cameras, other} {
+		_, err = repo.Save(label)
+		require.NoError(t, err)
+	}
+
+	found, err := sqlrepository.NewEstLabelRepository(database).FindByLabel("devices")
+	require.NoError(t, err)
+	assert.Equal(t, devices, found)
+
+	list, err = repo.FindAllByOrganization(big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, []appmodels.EstLabel{cameras, devices}, list)
+
+	_, err = repo.FindByLabel("missing")
+	assert.Error(t, err)
+}
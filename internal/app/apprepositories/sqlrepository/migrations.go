@@ -0,0 +1,406 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// Migration is one step of the database schema. Migrations are applied in
+// order and each one only once.
+type Migration struct {
+
+	// Version is the schema version after this migration
+	Version int
+
+	// Statements returns the SQL statements of the migration for a dialect
+	Statements func(dialect Dialect) []string
+
+	// Apply optionally converts existing rows after the statements have been
+	// executed, in the same transaction
+	Apply func(tx *sql.Tx, dialect Dialect) error
+}
+
+// Migrations is the schema history. New migrations are appended to the end;
+// existing ones must never be changed.
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Statements: func(dialect Dialect) []string {
+			return []string{
+				`CREATE TABLE organizations (
+					id TEXT NOT NULL PRIMARY KEY,
+					slug TEXT NOT NULL,
+					names TEXT NOT NULL,
+					signature_algorithm TEXT NOT NULL,
+					spiffe_trust_domain TEXT NOT NULL
+				)`,
+				`CREATE TABLE certificates (
+					organization TEXT NOT NULL,
+					serial TEXT NOT NULL,
+					signed_by TEXT,
+					not_after BIGINT NOT NULL,
+					certificate ` + dialect.BlobType() + ` NOT NULL,
+					PRIMARY KEY (organization, serial)
+				)`,
+				`CREATE INDEX certificates_signed_by_idx ON certificates (organization, signed_by)`,
+				`CREATE INDEX certificates_not_after_idx ON certificates (not_after)`,
+				`CREATE TABLE private_keys (
+					organization TEXT NOT NULL,
+					serial TEXT NOT NULL,
+					private_key ` + dialect.BlobType() + ` NOT NULL,
+					PRIMARY KEY (organization, serial)
+				)`,
+			}
+		},
+	},
+	{
+		Version: 2,
+		Statements: func(dialect Dialect) []string {
+			return []string{
+				`ALTER TABLE organizations ADD COLUMN key_retention_policy TEXT NOT NULL DEFAULT ''`,
+			}
+		},
+	},
+	{
+		Version: 3,
+		Statements: func(dialect Dialect) []string {
+			return []string{
+				`ALTER TABLE certificates ADD COLUMN not_before BIGINT NOT NULL DEFAULT 0`,
+				`ALTER TABLE certificates ADD COLUMN common_name TEXT NOT NULL DEFAULT ''`,
+				`ALTER TABLE certificates ADD COLUMN search_names TEXT NOT NULL DEFAULT ''`,
+				`ALTER TABLE certificates ADD COLUMN is_root INTEGER NOT NULL DEFAULT 0`,
+				`ALTER TABLE certificates ADD COLUMN is_intermediate INTEGER NOT NULL DEFAULT 0`,
+				`ALTER TABLE certificates ADD COLUMN is_server INTEGER NOT NULL DEFAULT 0`,
+				`ALTER TABLE certificates ADD COLUMN is_client INTEGER NOT NULL DEFAULT 0`,
+				`CREATE INDEX certificates_org_not_after_idx ON certificates (organization, not_after)`,
+				`CREATE INDEX certificates_org_not_before_idx ON certificates (organization, not_before)`,
+				`CREATE INDEX certificates_org_common_name_idx ON certificates (organization, common_name)`,
+			}
+		},
+		Apply: backfillCertificateColumns,
+	},
+	{
+		Version: 4,
+		Statements: func(dialect Dialect) []string {
+			return []string{
+				`ALTER TABLE certificates ADD COLUMN fingerprint TEXT NOT NULL DEFAULT ''`,
+				`ALTER TABLE certificates ADD COLUMN subject_key_id TEXT NOT NULL DEFAULT ''`,
+				`ALTER TABLE certificates ADD COLUMN authority_key_id TEXT NOT NULL DEFAULT ''`,
+				`ALTER TABLE certificates ADD COLUMN key_type TEXT NOT NULL DEFAULT ''`,
+				`ALTER TABLE certificates ADD COLUMN issuer_name TEXT NOT NULL DEFAULT ''`,
+				`ALTER TABLE certificates ADD COLUMN san_names TEXT NOT NULL DEFAULT ''`,
+				`CREATE INDEX certificates_fingerprint_idx ON certificates (organization, fingerprint)`,
+				`CREATE INDEX certificates_subject_key_id_idx ON certificates (organization, subject_key_id)`,
+				`CREATE INDEX certificates_authority_key_id_idx ON certificates (organization, authority_key_id)`,
+			}
+		},
+		Apply: backfillCertificateSearchColumns,
+	},
+	{
+		Version: 5,
+		Statements: func(dialect Dialect) []string {
+			// The archive has the same columns as the certificates table so
+			// that the same queries work on both
+			return []string{
+				`CREATE TABLE archived_certificates (
+					organization TEXT NOT NULL,
+					serial TEXT NOT NULL,
+					signed_by TEXT,
+					not_after BIGINT NOT NULL,
+					certificate ` + dialect.BlobType() + ` NOT NULL,
+					not_before BIGINT NOT NULL DEFAULT 0,
+					common_name TEXT NOT NULL DEFAULT '',
+					search_names TEXT NOT NULL DEFAULT '',
+					is_root INTEGER NOT NULL DEFAULT 0,
+					is_intermediate INTEGER NOT NULL DEFAULT 0,
+					is_server INTEGER NOT NULL DEFAULT 0,
+					is_client INTEGER NOT NULL DEFAULT 0,
+					fingerprint TEXT NOT NULL DEFAULT '',
+					subject_key_id TEXT NOT NULL DEFAULT '',
+					authority_key_id TEXT NOT NULL DEFAULT '',
+					key_type TEXT NOT NULL DEFAULT '',
+					issuer_name TEXT NOT NULL DEFAULT '',
+					san_names TEXT NOT NULL DEFAULT '',
+					PRIMARY KEY (organization, serial)
+				)`,
+				`CREATE INDEX archived_certificates_signed_by_idx ON archived_certificates (organization, signed_by)`,
+			}
+		},
+	},
+	{
+		Version: 6,
+		Statements: func(dialect Dialect) []string {
+			// SSH serial numbers are unsigned 64 bit integers, which do not
+			// fit in a BIGINT
+			return []string{
+				`CREATE TABLE ssh_authorities (
+					organization TEXT NOT NULL PRIMARY KEY,
+					id TEXT NOT NULL,
+					private_key ` + dialect.BlobType() + ` NOT NULL,
+					created_at BIGINT NOT NULL
+				)`,
+				`CREATE TABLE ssh_certificates (
+					organization TEXT NOT NULL,
+					serial TEXT NOT NULL,
+					certificate ` + dialect.BlobType() + ` NOT NULL,
+					revoked_at BIGINT NOT NULL DEFAULT 0,
+					PRIMARY KEY (organization, serial)
+				)`,
+			}
+		},
+	},
+	{
+		Version: 7,
+		Statements: func(dialect Dialect) []string {
+			return []string{
+				`CREATE TABLE certificate_revocations (
+					organization TEXT NOT NULL,
+					serial TEXT NOT NULL,
+					issuer TEXT NOT NULL,
+					revoked_at BIGINT NOT NULL,
+					expires_at BIGINT NOT NULL,
+					PRIMARY KEY (organization, serial)
+				)`,
+				`CREATE INDEX certificate_revocations_issuer_idx ON certificate_revocations (organization, issuer)`,
+				`CREATE TABLE revocation_lists (
+					organization TEXT NOT NULL,
+					issuer TEXT NOT NULL,
+					number TEXT NOT NULL,
+					this_update BIGINT NOT NULL,
+					PRIMARY KEY (organization, issuer)
+				)`,
+			}
+		},
+	},
+	{
+		Version: 8,
+		Statements: func(dialect Dialect) []string {
+			// The approvals of an operation are stored in its JSON record
+			return []string{
+				`CREATE TABLE pending_operations (
+					id TEXT NOT NULL PRIMARY KEY,
+					created_at BIGINT NOT NULL,
+					operation TEXT NOT NULL
+				)`,
+			}
+		},
+	},
+	{
+		Version: 9,
+		Statements: func(dialect Dialect) []string {
+			// ACME, EST, SCEP and Vault state is stored as JSON records
+			// with the columns used in lookups
+			return []string{
+				`CREATE TABLE acme_accounts (
+					id TEXT NOT NULL PRIMARY KEY,
+					organization TEXT NOT NULL,
+					issuer TEXT NOT NULL,
+					thumbprint TEXT NOT NULL,
+					created_at BIGINT NOT NULL,
+					record TEXT NOT NULL
+				)`,
+				`CREATE INDEX acme_accounts_thumbprint_idx ON acme_accounts (organization, issuer, thumbprint)`,
+				`CREATE TABLE acme_orders (
+					id TEXT NOT NULL PRIMARY KEY,
+					account TEXT NOT NULL,
+					record TEXT NOT NULL
+				)`,
+				`CREATE INDEX acme_orders_account_idx ON acme_orders (account)`,
+				`CREATE TABLE acme_authorizations (
+					id TEXT NOT NULL PRIMARY KEY,
+					record TEXT NOT NULL
+				)`,
+				`CREATE TABLE est_labels (
+					label TEXT NOT NULL PRIMARY KEY,
+					organization TEXT NOT NULL,
+					record TEXT NOT NULL
+				)`,
+				`CREATE INDEX est_labels_organization_idx ON est_labels (organization)`,
+				`CREATE TABLE scep_challenges (
+					organization TEXT NOT NULL PRIMARY KEY,
+					record TEXT NOT NULL
+				)`,
+				`CREATE TABLE vault_roles (
+					organization TEXT NOT NULL,
+					name TEXT NOT NULL,
+					record TEXT NOT NULL,
+					PRIMARY KEY (organization, name)
+				)`,
+			}
+		},
+	},
+}
+
+// SchemaVersion returns the current schema version of the database, or zero
+// if no migrations have been applied
+func SchemaVersion(db *sql.DB) (int, error) {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER NOT NULL PRIMARY KEY,
+		applied_at BIGINT NOT NULL
+	)`); err != nil {
+		return 0, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	var version sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+// CheckSchemaVersion checks that the database has the latest schema without
+// changing it, e.g. before it is used in read-only mode
+func CheckSchemaVersion(db *sql.DB) error {
+	var version sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	current := int(version.Int64)
+	latest := Migrations[len(Migrations)-1].Version
+	if current > latest {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", current, latest)
+	}
+	if current < latest {
+		return fmt.Errorf("database schema version %d is older than version %d and must be migrated without read-only mode", current, latest)
+	}
+	return nil
+}
+
+// Migrate applies the missing migrations. Each migration is applied in its own
+// transaction. A database with a newer schema than this binary knows is
+// refused.
+func Migrate(db *sql.DB, dialect Dialect) error {
+
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	latest := Migrations[len(Migrations)-1].Version
+	if current > latest {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", current, latest)
+	}
+
+	for _, migration := range Migrations {
+		if migration.Version <= current {
+			continue
+		}
+		err := withTransaction(db, func(tx *sql.Tx) error {
+			for _, statement := range migration.Statements(dialect) {
+				if _, err := tx.Exec(statement); err != nil {
+					return err
+				}
+			}
+			if migration.Apply != nil {
+				if err := migration.Apply(tx, dialect); err != nil {
+					return err
+				}
+			}
+			_, err := tx.Exec(
+				dialect.Rebind(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`),
+				migration.Version,
+				time.Now().Unix(),
+			)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to migrate database schema to version %d: %w", migration.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// backfillCertificateColumns fills the query columns of certificates saved
+// before schema version 3
+func backfillCertificateColumns(tx *sql.Tx, dialect Dialect) error {
+	return backfillCertificates(
+		tx,
+		dialect,
+		`UPDATE certificates SET not_before = ?, common_name = ?, search_names = ?,
+			is_root = ?, is_intermediate = ?, is_server = ?, is_client = ?
+			WHERE organization = ? AND serial = ?`,
+		func(columns certificateColumns) []any {
+			return []any{
+				columns.notBefore,
+				columns.commonName,
+				columns.searchNames,
+				columns.isRoot,
+				columns.isIntermediate,
+				columns.isServer,
+				columns.isClient,
+			}
+		},
+	)
+}
+
+// backfillCertificateSearchColumns fills the search columns of certificates
+// saved before schema version 4
+func backfillCertificateSearchColumns(tx *sql.Tx, dialect Dialect) error {
+	return backfillCertificates(
+		tx,
+		dialect,
+		`UPDATE certificates SET fingerprint = ?, subject_key_id = ?, authority_key_id = ?,
+			key_type = ?, issuer_name = ?, san_names = ?
+			WHERE organization = ? AND serial = ?`,
+		func(columns certificateColumns) []any {
+			return []any{
+				columns.fingerprint,
+				columns.subjectKeyId,
+				columns.authorityKeyId,
+				columns.keyType,
+				columns.issuerName,
+				columns.sanNames,
+			}
+		},
+	)
+}
+
+// backfillCertificates executes an update statement for every stored
+// certificate. The statement takes the values derived from the certificate
+// followed by the organization and the serial number.
+func backfillCertificates(
+	tx *sql.Tx,
+	dialect Dialect,
+	statement string,
+	values func(columns certificateColumns) []any,
+) error {
+	type row struct {
+		organization, serial string
+		der                  []byte
+	}
+	rows, err := tx.Query(`SELECT organization, serial, certificate FROM certificates`)
+	if err != nil {
+		return err
+	}
+	var list []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.organization, &r.serial, &r.der); err != nil {
+			rows.Close()
+			return err
+		}
+		list = append(list, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, r := range list {
+		cert, err := x509.ParseCertificate(r.der)
+		if err != nil {
+			return fmt.Errorf("failed to parse certificate '%s/%s': %w", r.organization, r.serial, err)
+		}
+		args := append(values(newCertificateColumns(appmodels.NewCertificate(nil, nil, cert))), r.organization, r.serial)
+		if _, err := tx.Exec(dialect.Rebind(statement), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository_test
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sqlrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestMigrations_Ordered(t *testing.T) {
+	for i, migration := range sqlrepository.Migrations {
+		assert.Equal(t, i+1, migration.Version)
+		assert.NotEmpty(t, migration.Statements(sqlrepository.SqliteDialect))
+		assert.NotEmpty(t, migration.Statements(sqlrepository.PostgresDialect))
+	}
+}
+
+func TestMigrate_Indexes(t *testing.T) {
+	database := newTestDatabase(t)
+	rows, err := database.DB().Query(`SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = 'certificates'`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	assert.Contains(t, names, "certificates_signed_by_idx")
+	assert.Contains(t, names, "certificates_not_after_idx")
+}
+
+func TestMigrate_NewerSchema(t *testing.T) {
+	database := newTestDatabase(t)
+	_, err := database.DB().Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (999, 0)`)
+	require.NoError(t, err)
+
+	err = sqlrepository.Migrate(database.DB(), database.Dialect())
+	assert.ErrorContains(t, err, "newer than supported")
+}
+
+func TestMigrate_BackfillCertificateColumns(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	organization := big.NewInt(10)
+	root, _ := newTestRootCertificate(t, certManager, organization, big.NewInt(1))
+
+	// Save a certificate with the schema before the query columns
+	migrations := sqlrepository.Migrations
+	sqlrepository.Migrations = migrations[:2]
+	fileName := filepath.Join(t.TempDir(), "test.db")
+	database, err := sqlrepository.OpenDatabase("sqlite", fileName)
+	sqlrepository.Migrations = migrations
+	require.NoError(t, err)
+	_, err = database.DB().Exec(
+		`INSERT INTO certificates (organization, serial, signed_by, not_after, certificate) VALUES (?, ?, NULL, ?, ?)`,
+		organization.String(),
+		root.SerialNumber().String(),
+		root.NotAfter().Unix(),
+		root.Certificate().Raw,
+	)
+	require.NoError(t, err)
+	require.NoError(t, database.Close())
+
+	database, err = sqlrepository.OpenDatabase("sqlite", fileName)
+	require.NoError(t, err)
+	defer database.Close()
+
+	repo := sqlrepository.NewCertificateRepository(certManager, database)
+	page, err := repo.FindPageByOrganization(organization, appmodels.CertificateQuery{
+		Type: appmodels.ROOT_CERTIFICATE,
+		Name: "test root",
+		Sort: appmodels.SORT_BY_CREATED_AT,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*big.Int{root.SerialNumber()}, serialNumbersOf(page.Certificates))
+
+	page, err = repo.FindPageByOrganization(organization, appmodels.CertificateQuery{
+		Fingerprint:  apputils.CertificateFingerprint(root),
+		SubjectKeyId: root.Certificate().SubjectKeyId,
+		Issuer:       "test root",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*big.Int{root.SerialNumber()}, serialNumbersOf(page.Certificates))
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// SqlOrganizationRepository implements models.OrganizationRepository on a SQL database
+type SqlOrganizationRepository struct {
+	database *Database
+}
+
+func (r *SqlOrganizationRepository) FindAll() ([]appmodels.Organization, error) {
+	rows, err := r.database.db.Query(
+		`SELECT id, slug, names, signature_algorithm, spiffe_trust_domain, key_retention_policy FROM organizations`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[Organization:FindAll]: query failed: %w", err)
+	}
+	defer rows.Close()
+
+	list := make([]appmodels.Organization, 0)
+	for rows.Next() {
+		model, err := scanOrganization(rows)
+		if err != nil {
+			return nil, fmt.Errorf("[Organization:FindAll]: %w", err)
+		}
+		list = append(list, model)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[Organization:FindAll]: query failed: %w", err)
+	}
+
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].ID().Cmp(list[b].ID()) < 0
+	})
+	return list, nil
+}
+
+// FindPage returns a page of organizations in ID order. The name filter and
+// the cursor are evaluated by the database.
+func (r *SqlOrganizationRepository) FindPage(query appmodels.OrganizationQuery) (appmodels.OrganizationPage, error) {
+	conditions := []string{"1 = 1"}
+	var args []any
+	if query.Name != "" {
+		pattern := "%" + escapeLike(strings.ToLower(query.Name)) + "%"
+		conditions = append(conditions, `(LOWER(slug) LIKE ? ESCAPE '\' OR LOWER(names) LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+	if query.After != nil {
+		after := query.After.String()
+		conditions = append(conditions, "(LENGTH(id) > ? OR (LENGTH(id) = ? AND id > ?))")
+		args = append(args, len(after), len(after), after)
+	}
+	// IDs are decimal strings, so ordering by the length first gives the
+	// numeric order
+	statement := `SELECT id, slug, names, signature_algorithm, spiffe_trust_domain, key_retention_policy FROM organizations WHERE ` +
+		strings.Join(conditions, " AND ") + ` ORDER BY LENGTH(id), id`
+	if query.Limit > 0 {
+		statement += ` LIMIT ?`
+		args = append(args, query.Limit+1)
+	}
+
+	rows, err := r.database.db.Query(r.database.dialect.Rebind(statement), args...)
+	if err != nil {
+		return appmodels.OrganizationPage{}, fmt.Errorf("[Organization:FindPage]: query failed: %w", err)
+	}
+	defer rows.Close()
+
+	page := appmodels.OrganizationPage{
+		Organizations: make([]appmodels.Organization, 0),
+	}
+	for rows.Next() {
+		if query.Limit > 0 && len(page.Organizations) == query.Limit {
+			page.Next = page.Organizations[len(page.Organizations)-1].ID()
+			break
+		}
+		model, err := scanOrganization(rows)
+		if err != nil {
+			return appmodels.OrganizationPage{}, fmt.Errorf("[Organization:FindPage]: %w", err)
+		}
+		page.Organizations = append(page.Organizations, model)
+	}
+	if err := rows.Err(); err != nil {
+		return appmodels.OrganizationPage{}, fmt.Errorf("[Organization:FindPage]: query failed: %w", err)
+	}
+	return page, nil
+}
+
+func (r *SqlOrganizationRepository) FindById(id *big.Int) (appmodels.Organization, error) {
+	if id == nil {
+		return nil, errors.New("[Organization:FindById]: no organization ID provided")
+	}
+	row := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT id, slug, names, signature_algorithm, spiffe_trust_domain, key_retention_policy FROM organizations WHERE id = ?`),
+		id.String(),
+	)
+	model, err := scanOrganization(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[Organization:FindById]: not found: %s", id)
+		}
+		return nil, fmt.Errorf("[Organization:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlOrganizationRepository) Save(organization appmodels.Organization) (appmodels.Organization, error) {
+	id := organization.ID()
+	names, err := json.Marshal(organization.Names())
+	if err != nil {
+		return nil, fmt.Errorf("[Organization:Save]: failed to marshal names: %w", err)
+	}
+	err = withTransaction(r.database.db, func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			r.database.dialect.Rebind(`INSERT INTO organizations (id, slug, names, signature_algorithm, spiffe_trust_domain, key_retention_policy)
+				VALUES (?, ?, ?, ?, ?, ?)
+				ON CONFLICT (id) DO UPDATE SET
+					slug = excluded.slug,
+					names = excluded.names,
+					signature_algorithm = excluded.signature_algorithm,
+					spiffe_trust_domain = excluded.spiffe_trust_domain,
+					key_retention_policy = excluded.key_retention_policy`),
+			id.String(),
+			organization.Slug(),
+			string(names),
+			apputils.SignatureAlgorithmToString(organization.SignatureAlgorithm()),
+			organization.SpiffeTrustDomain(),
+			apputils.KeyRetentionPolicyToString(organization.KeyRetentionPolicy()),
+		)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[Organization:Save]: failed to save '%s': %w", id, err)
+	}
+	return r.FindById(id)
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOrganization(row rowScanner) (appmodels.Organization, error) {
+	var id, slug, names, signatureAlgorithm, spiffeTrustDomain, keyRetentionPolicy string
+	if err := row.Scan(&id, &slug, &names, &signatureAlgorithm, &spiffeTrustDomain, &keyRetentionPolicy); err != nil {
+		return nil, err
+	}
+	parsedID, err := apputils.ParseBigInt(id, 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse organization id '%s': %w", id, err)
+	}
+	var allNames []string
+	if err := json.Unmarshal([]byte(names), &allNames); err != nil {
+		return nil, fmt.Errorf("failed to parse organization names '%s': %w", id, err)
+	}
+	algorithm, err := apputils.ParseSignatureAlgorithm(signatureAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse organization signature algorithm '%s': %w", signatureAlgorithm, err)
+	}
+	policy, err := apputils.ParseKeyRetentionPolicy(keyRetentionPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse organization key retention policy '%s': %w", keyRetentionPolicy, err)
+	}
+	return appmodels.NewOrganization(parsedID, slug, allNames, algorithm, spiffeTrustDomain, policy), nil
+}
+
+// NewOrganizationRepository creates a SQL based repository for organizations
+func NewOrganizationRepository(database *Database) *SqlOrganizationRepository {
+	return &SqlOrganizationRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.OrganizationRepository = (*SqlOrganizationRepository)(nil)
@@ -0,0 +1,112 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// SqlPendingOperationRepository implements
+// appmodels.PendingOperationRepository on a SQL database. Operations are
+// stored as JSON records.
+type SqlPendingOperationRepository struct {
+	database *Database
+}
+
+func (r *SqlPendingOperationRepository) FindAll() ([]appmodels.PendingOperation, error) {
+	rows, err := r.database.db.Query(`SELECT operation FROM pending_operations`)
+	if err != nil {
+		return nil, fmt.Errorf("[PendingOperation:FindAll]: failed to query: %w", err)
+	}
+	defer rows.Close()
+	list := []appmodels.PendingOperation{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("[PendingOperation:FindAll]: failed to read: %w", err)
+		}
+		model, err := parsePendingOperation(data)
+		if err != nil {
+			return nil, fmt.Errorf("[PendingOperation:FindAll]: %w", err)
+		}
+		list = append(list, model)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[PendingOperation:FindAll]: %w", err)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt().Equal(list[j].CreatedAt()) {
+			return list[i].ID() < list[j].ID()
+		}
+		return list[i].CreatedAt().Before(list[j].CreatedAt())
+	})
+	return list, nil
+}
+
+func (r *SqlPendingOperationRepository) FindById(id string) (appmodels.PendingOperation, error) {
+	var data string
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT operation FROM pending_operations WHERE id = ?`),
+		id,
+	).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[PendingOperation:FindById]: not found: %s", id)
+		}
+		return nil, fmt.Errorf("[PendingOperation:FindById]: failed to read: %w", err)
+	}
+	model, err := parsePendingOperation(data)
+	if err != nil {
+		return nil, fmt.Errorf("[PendingOperation:FindById]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlPendingOperationRepository) Save(model appmodels.PendingOperation) (appmodels.PendingOperation, error) {
+	data, err := json.Marshal(apputils.ToPendingOperationRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[PendingOperation:Save]: failed to marshal JSON: %w", err)
+	}
+	_, err = r.database.db.Exec(
+		r.database.dialect.Rebind(`INSERT INTO pending_operations (id, created_at, operation)
+			VALUES (?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				operation = excluded.operation`),
+		model.ID(),
+		model.CreatedAt().Unix(),
+		string(data),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[PendingOperation:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// parsePendingOperation parses a stored operation
+func parsePendingOperation(data string) (appmodels.PendingOperation, error) {
+	dto := appdtos.PendingOperationRecordDTO{}
+	if err := json.Unmarshal([]byte(data), &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return apputils.FromPendingOperationRecordDTO(dto)
+}
+
+// NewPendingOperationRepository creates a SQL based repository for
+// operations waiting for approval
+func NewPendingOperationRepository(
+	database *Database,
+) *SqlPendingOperationRepository {
+	return &SqlPendingOperationRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.PendingOperationRepository = (*SqlPendingOperationRepository)(nil)
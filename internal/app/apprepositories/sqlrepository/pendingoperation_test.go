@@ -0,0 +1,49 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sqlrepository"
+)
+
+func TestPendingOperationRepository_SaveAndFind(t *testing.T) {
+	repo := sqlrepository.NewPendingOperationRepository(newTestDatabase(t))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	list, err := repo.FindAll()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	second := appmodels.NewPendingOperation("a", appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, big.NewInt(1), nil, "", "alice", appmodels.OPERATION_STATUS_PENDING, now.Add(time.Minute), now.Add(time.Hour), []appmodels.OperationApproval{}, time.Time{})
+	first := appmodels.NewPendingOperation("b", appmodels.OPERATION_REVOKE_ROOT_CERTIFICATE, big.NewInt(1), big.NewInt(2), "", "alice", appmodels.OPERATION_STATUS_PENDING, now, now.Add(time.Hour), []appmodels.OperationApproval{}, time.Time{})
+	_, err = repo.Save(second)
+	require.NoError(t, err)
+	_, err = repo.Save(first)
+	require.NoError(t, err)
+
+	// Saving again replaces the operation
+	approved := appmodels.NewPendingOperation("a", appmodels.OPERATION_CREATE_ROOT_CERTIFICATE, big.NewInt(1), nil, "", "alice", appmodels.OPERATION_STATUS_APPROVED, now.Add(time.Minute), now.Add(time.Hour), []appmodels.OperationApproval{appmodels.NewOperationApproval("bob", true, now)}, time.Time{})
+	_, err = repo.Save(approved)
+	require.NoError(t, err)
+
+	found, err := repo.FindById("a")
+	require.NoError(t, err)
+	assert.Equal(t, approved, found)
+
+	list, err = repo.FindAll()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "b", list[0].ID())
+	assert.Equal(t, "a", list[1].ID())
+
+	_, err = repo.FindById("missing")
+	assert.Error(t, err)
+}
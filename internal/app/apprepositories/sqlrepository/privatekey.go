@@ -0,0 +1,88 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// SqlPrivateKeyRepository implements models.PrivateKeyRepository on a SQL
+// database. Private keys are stored as PEM.
+type SqlPrivateKeyRepository struct {
+	certManager managers.CertificateManager
+	database    *Database
+}
+
+func (r *SqlPrivateKeyRepository) FindByOrganizationAndSerialNumber(
+	organization,
+	certificate *big.Int,
+) (appmodels.PrivateKey, error) {
+	if certificate == nil {
+		return nil, errors.New("no certificate serial number provided")
+	}
+	var data []byte
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT private_key FROM private_keys WHERE organization = ? AND serial = ?`),
+		organization.String(),
+		certificate.String(),
+	).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[PrivateKey:FindByOrganizationAndSerialNumber]: not found: %s/%s", organization, certificate)
+		}
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	privateKey, keyType, err := apputils.ParsePrivateKeyFromPEMBytes(r.certManager, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return appmodels.NewPrivateKey(organization, certificate, keyType, privateKey), nil
+}
+
+func (r *SqlPrivateKeyRepository) Save(key appmodels.PrivateKey) (appmodels.PrivateKey, error) {
+	pemData, err := apputils.MarshalPrivateKeyAsPEM(r.certManager, key.PrivateKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize private key to PEM: %w", err)
+	}
+	err = withTransaction(r.database.db, func(tx *sql.Tx) error {
+		return savePrivateKey(tx, r.database.dialect, key.OrganizationID(), key.SerialNumber(), pemData)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save private key: %w", err)
+	}
+	return key, nil
+}
+
+// savePrivateKey inserts or updates a PEM encoded private key in a transaction
+func savePrivateKey(tx *sql.Tx, dialect Dialect, organization, serialNumber *big.Int, pemData []byte) error {
+	_, err := tx.Exec(
+		dialect.Rebind(`INSERT INTO private_keys (organization, serial, private_key)
+			VALUES (?, ?, ?)
+			ON CONFLICT (organization, serial) DO UPDATE SET
+				private_key = excluded.private_key`),
+		organization.String(),
+		serialNumber.String(),
+		pemData,
+	)
+	return err
+}
+
+// NewPrivateKeyRepository creates a SQL based repository for private keys
+func NewPrivateKeyRepository(
+	certManager managers.CertificateManager,
+	database *Database,
+) *SqlPrivateKeyRepository {
+	return &SqlPrivateKeyRepository{
+		certManager: certManager,
+		database:    database,
+	}
+}
+
+var _ appmodels.PrivateKeyRepository = (*SqlPrivateKeyRepository)(nil)
@@ -0,0 +1,75 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// SqlScepChallengeRepository implements appmodels.ScepChallengeRepository on
+// a SQL database. Challenges are stored as JSON records.
+type SqlScepChallengeRepository struct {
+	database *Database
+}
+
+func (r *SqlScepChallengeRepository) FindByOrganization(organization *big.Int) (appmodels.ScepChallenge, error) {
+	var data string
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT record FROM scep_challenges WHERE organization = ?`),
+		organization.String(),
+	).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[ScepChallenge:FindByOrganization]: not found: %s", organization)
+		}
+		return nil, fmt.Errorf("[ScepChallenge:FindByOrganization]: failed to read: %w", err)
+	}
+	dto := appdtos.ScepChallengeRecordDTO{}
+	if err := json.Unmarshal([]byte(data), &dto); err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:FindByOrganization]: failed to unmarshal JSON: %w", err)
+	}
+	model, err := apputils.FromScepChallengeRecordDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:FindByOrganization]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlScepChallengeRepository) Save(model appmodels.ScepChallenge) (appmodels.ScepChallenge, error) {
+	data, err := json.Marshal(apputils.ToScepChallengeRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:Save]: failed to marshal JSON: %w", err)
+	}
+	_, err = r.database.db.Exec(
+		r.database.dialect.Rebind(`INSERT INTO scep_challenges (organization, record)
+			VALUES (?, ?)
+			ON CONFLICT (organization) DO UPDATE SET
+				record = excluded.record`),
+		model.OrganizationID().String(),
+		string(data),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[ScepChallenge:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewScepChallengeRepository creates a SQL based repository for SCEP
+// challenge passwords
+func NewScepChallengeRepository(
+	database *Database,
+) *SqlScepChallengeRepository {
+	return &SqlScepChallengeRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.ScepChallengeRepository = (*SqlScepChallengeRepository)(nil)
@@ -0,0 +1,88 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// SqlSshAuthorityRepository implements appmodels.SshAuthorityRepository on a
+// SQL database. The CA key is stored as PEM.
+type SqlSshAuthorityRepository struct {
+	certManager managers.CertificateManager
+	database    *Database
+}
+
+func (r *SqlSshAuthorityRepository) FindByOrganization(organization *big.Int) (appmodels.SshAuthority, error) {
+	var id string
+	var data []byte
+	var createdAt int64
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT id, private_key, created_at FROM ssh_authorities WHERE organization = ?`),
+		organization.String(),
+	).Scan(&id, &data, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: not found: %s", organization)
+		}
+		return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: failed to read: %w", err)
+	}
+	serial, err := apputils.ParseBigInt(id, 10)
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: invalid id: %w", err)
+	}
+	privateKey, keyType, err := apputils.ParsePrivateKeyFromPEMBytes(r.certManager, data)
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:FindByOrganization]: failed to parse private key: %w", err)
+	}
+	return appmodels.NewSshAuthority(
+		organization,
+		serial,
+		appmodels.NewPrivateKey(organization, serial, keyType, privateKey),
+		time.Unix(createdAt, 0),
+	), nil
+}
+
+func (r *SqlSshAuthorityRepository) Save(model appmodels.SshAuthority) (appmodels.SshAuthority, error) {
+	pemData, err := apputils.MarshalPrivateKeyAsPEM(r.certManager, model.PrivateKey().PrivateKey())
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:Save]: failed to serialize private key to PEM: %w", err)
+	}
+	_, err = r.database.db.Exec(
+		r.database.dialect.Rebind(`INSERT INTO ssh_authorities (organization, id, private_key, created_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (organization) DO UPDATE SET
+				id = excluded.id,
+				private_key = excluded.private_key,
+				created_at = excluded.created_at`),
+		model.OrganizationID().String(),
+		model.ID().String(),
+		pemData,
+		model.CreatedAt().Unix(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[SshAuthority:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// NewSshAuthorityRepository creates a SQL based repository for SSH CA keys
+func NewSshAuthorityRepository(
+	certManager managers.CertificateManager,
+	database *Database,
+) *SqlSshAuthorityRepository {
+	return &SqlSshAuthorityRepository{
+		certManager: certManager,
+		database:    database,
+	}
+}
+
+var _ appmodels.SshAuthorityRepository = (*SqlSshAuthorityRepository)(nil)
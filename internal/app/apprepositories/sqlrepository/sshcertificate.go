@@ -0,0 +1,122 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// SqlSshCertificateRepository implements appmodels.SshCertificateRepository
+// on a SQL database. Certificates are stored in the OpenSSH wire format.
+type SqlSshCertificateRepository struct {
+	database *Database
+}
+
+func (r *SqlSshCertificateRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.SshCertificate, error) {
+	rows, err := r.database.db.Query(
+		r.database.dialect.Rebind(`SELECT certificate, revoked_at FROM ssh_certificates WHERE organization = ?`),
+		organization.String(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[SshCertificate:FindAllByOrganization]: failed to query: %w", err)
+	}
+	defer rows.Close()
+	list := []appmodels.SshCertificate{}
+	for rows.Next() {
+		var data []byte
+		var revokedAt int64
+		if err := rows.Scan(&data, &revokedAt); err != nil {
+			return nil, fmt.Errorf("[SshCertificate:FindAllByOrganization]: failed to read: %w", err)
+		}
+		model, err := newSshCertificate(organization, data, revokedAt)
+		if err != nil {
+			return nil, fmt.Errorf("[SshCertificate:FindAllByOrganization]: %w", err)
+		}
+		list = append(list, model)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[SshCertificate:FindAllByOrganization]: %w", err)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Serial() < list[j].Serial()
+	})
+	return list, nil
+}
+
+func (r *SqlSshCertificateRepository) FindByOrganizationAndSerial(organization *big.Int, serial uint64) (appmodels.SshCertificate, error) {
+	var data []byte
+	var revokedAt int64
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT certificate, revoked_at FROM ssh_certificates WHERE organization = ? AND serial = ?`),
+		organization.String(),
+		strconv.FormatUint(serial, 10),
+	).Scan(&data, &revokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[SshCertificate:FindByOrganizationAndSerial]: not found: %d@%s", serial, organization)
+		}
+		return nil, fmt.Errorf("[SshCertificate:FindByOrganizationAndSerial]: failed to read: %w", err)
+	}
+	model, err := newSshCertificate(organization, data, revokedAt)
+	if err != nil {
+		return nil, fmt.Errorf("[SshCertificate:FindByOrganizationAndSerial]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlSshCertificateRepository) Save(model appmodels.SshCertificate) (appmodels.SshCertificate, error) {
+	var revokedAt int64
+	if model.IsRevoked() {
+		revokedAt = model.RevokedAt().Unix()
+	}
+	_, err := r.database.db.Exec(
+		r.database.dialect.Rebind(`INSERT INTO ssh_certificates (organization, serial, certificate, revoked_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (organization, serial) DO UPDATE SET
+				certificate = excluded.certificate,
+				revoked_at = excluded.revoked_at`),
+		model.OrganizationID().String(),
+		strconv.FormatUint(model.Serial(), 10),
+		model.Certificate().Marshal(),
+		revokedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[SshCertificate:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+// newSshCertificate creates a model from a row. A zero revocation time means
+// the certificate is not revoked.
+func newSshCertificate(organization *big.Int, data []byte, revokedAt int64) (appmodels.SshCertificate, error) {
+	certificate, err := apputils.ParseSshCertificate(data)
+	if err != nil {
+		return nil, err
+	}
+	var revoked time.Time
+	if revokedAt != 0 {
+		revoked = time.Unix(revokedAt, 0)
+	}
+	return appmodels.NewSshCertificate(organization, certificate, revoked), nil
+}
+
+// NewSshCertificateRepository creates a SQL based repository for signed SSH
+// certificates
+func NewSshCertificateRepository(
+	database *Database,
+) *SqlSshCertificateRepository {
+	return &SqlSshCertificateRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.SshCertificateRepository = (*SqlSshCertificateRepository)(nil)
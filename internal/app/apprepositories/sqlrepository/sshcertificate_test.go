@@ -0,0 +1,76 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository_test
+
+import (
+	"crypto/rand"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sqlrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+func newTestSshCertificate(t *testing.T, organization *big.Int, serial uint64, revokedAt time.Time) appmodels.SshCertificate {
+	t.Helper()
+	signer, err := apputils.NewSshSigner(newTestSshAuthority(t, organization).PrivateKey())
+	require.NoError(t, err)
+	certificate := &ssh.Certificate{
+		Key:             signer.PublicKey(),
+		Serial:          serial,
+		CertType:        ssh.UserCert,
+		KeyId:           "alice",
+		ValidPrincipals: []string{"alice"},
+		ValidAfter:      1000,
+		ValidBefore:     2000,
+	}
+	require.NoError(t, certificate.SignCert(rand.Reader, signer))
+	return appmodels.NewSshCertificate(organization, certificate, revokedAt)
+}
+
+func TestSshCertificateRepository_SaveAndFind(t *testing.T) {
+	repo := sqlrepository.NewSshCertificateRepository(newTestDatabase(t))
+	organization := big.NewInt(123)
+
+	list, err := repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// Serial numbers above the signed 64 bit range are stored as well
+	_, err = repo.Save(newTestSshCertificate(t, organization, math.MaxUint64, time.Time{}))
+	require.NoError(t, err)
+	_, err = repo.Save(newTestSshCertificate(t, organization, 20, time.Time{}))
+	require.NoError(t, err)
+	_, err = repo.Save(newTestSshCertificate(t, organization, 3, time.Time{}))
+	require.NoError(t, err)
+
+	revokedAt := time.Unix(1500, 0)
+	_, err = repo.Save(newTestSshCertificate(t, organization, 20, revokedAt))
+	require.NoError(t, err)
+
+	found, err := repo.FindByOrganizationAndSerial(organization, 20)
+	require.NoError(t, err)
+	assert.True(t, revokedAt.Equal(found.RevokedAt()))
+	assert.Equal(t, []string{"alice"}, found.Principals())
+
+	found, err = repo.FindByOrganizationAndSerial(organization, 3)
+	require.NoError(t, err)
+	assert.False(t, found.IsRevoked())
+
+	list, err = repo.FindAllByOrganization(organization)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, uint64(3), list[0].Serial())
+	assert.Equal(t, uint64(20), list[1].Serial())
+	assert.Equal(t, uint64(math.MaxUint64), list[2].Serial())
+
+	_, err = repo.FindByOrganizationAndSerial(organization, 4)
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// SqlUnitOfWorkRepository implements appmodels.UnitOfWorkRepository on a SQL
+// database
+type SqlUnitOfWorkRepository struct {
+	certManager managers.CertificateManager
+	database    *Database
+}
+
+func (r *SqlUnitOfWorkRepository) NewUnitOfWork() appmodels.UnitOfWork {
+	return &SqlUnitOfWork{repository: r}
+}
+
+// SqlUnitOfWork implements appmodels.UnitOfWork. All changes are saved in a
+// single database transaction.
+type SqlUnitOfWork struct {
+	repository   *SqlUnitOfWorkRepository
+	certificates []appmodels.Certificate
+	keys         []appmodels.PrivateKey
+	committed    bool
+}
+
+func (w *SqlUnitOfWork) SaveCertificate(certificate appmodels.Certificate) {
+	w.certificates = append(w.certificates, certificate)
+}
+
+func (w *SqlUnitOfWork) SavePrivateKey(key appmodels.PrivateKey) {
+	w.keys = append(w.keys, key)
+}
+
+func (w *SqlUnitOfWork) Commit() error {
+	if w.committed {
+		return errors.New("[UnitOfWork:Commit]: already committed")
+	}
+	w.committed = true
+
+	keyData := make([][]byte, 0, len(w.keys))
+	for _, key := range w.keys {
+		pemData, err := apputils.MarshalPrivateKeyAsPEM(w.repository.certManager, key.PrivateKey())
+		if err != nil {
+			return fmt.Errorf("[UnitOfWork:Commit]: failed to serialize private key to PEM: %w", err)
+		}
+		keyData = append(keyData, pemData)
+	}
+	for _, certificate := range w.certificates {
+		if certificate.Certificate() == nil {
+			return fmt.Errorf("[UnitOfWork:Commit]: no certificate data: %s", certificate.SerialNumber())
+		}
+	}
+
+	dialect := w.repository.database.dialect
+	err := withTransaction(w.repository.database.db, func(tx *sql.Tx) error {
+		for i, key := range w.keys {
+			if err := savePrivateKey(tx, dialect, key.OrganizationID(), key.SerialNumber(), keyData[i]); err != nil {
+				return fmt.Errorf("failed to save private key: %w", err)
+			}
+		}
+		for _, certificate := range w.certificates {
+			if err := saveCertificate(tx, dialect, certificate); err != nil {
+				return fmt.Errorf("failed to save certificate: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("[UnitOfWork:Commit]: %w", err)
+	}
+	return nil
+}
+
+// NewUnitOfWorkRepository creates units of work on a SQL database
+func NewUnitOfWorkRepository(
+	certManager managers.CertificateManager,
+	database *Database,
+) *SqlUnitOfWorkRepository {
+	return &SqlUnitOfWorkRepository{
+		certManager: certManager,
+		database:    database,
+	}
+}
+
+var _ appmodels.UnitOfWorkRepository = (*SqlUnitOfWorkRepository)(nil)
+var _ appmodels.UnitOfWork = (*SqlUnitOfWork)(nil)
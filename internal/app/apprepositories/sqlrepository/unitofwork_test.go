@@ -0,0 +1,67 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/sqlrepository"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestRootCertificate(t *testing.T, certManager managers.CertificateManager, organization, serialNumber *big.Int) (appmodels.Certificate, appmodels.PrivateKey) {
+	t.Helper()
+	key, err := apputils.GeneratePrivateKey(organization, serialNumber, appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	model := appmodels.NewOrganization(organization, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
+	cert, err := apputils.NewRootCertificate(certManager, serialNumber, model, time.Hour, appmodels.NIL_SIGNATURE_ALGORITHM, key, "Test Root")
+	require.NoError(t, err)
+	return cert, key
+}
+
+func TestSqlUnitOfWork_Commit(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	database := newTestDatabase(t)
+	collection := sqlrepository.NewCollection(certManager, database)
+	organization := big.NewInt(123)
+	cert, key := newTestRootCertificate(t, certManager, organization, big.NewInt(1))
+
+	work := collection.UnitOfWork.NewUnitOfWork()
+	work.SaveCertificate(cert)
+	work.SavePrivateKey(key)
+	require.NoError(t, work.Commit())
+
+	_, err := collection.Certificate.FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	assert.NoError(t, err)
+	_, err = collection.PrivateKey.FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	assert.NoError(t, err)
+
+	assert.ErrorContains(t, work.Commit(), "already committed")
+}
+
+func TestSqlUnitOfWork_CommitRollback(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	database := newTestDatabase(t)
+	collection := sqlrepository.NewCollection(certManager, database)
+	organization := big.NewInt(123)
+	cert, key := newTestRootCertificate(t, certManager, organization, big.NewInt(1))
+
+	// The certificate insert fails after the private key was inserted
+	_, err := database.DB().Exec(`DROP TABLE certificates`)
+	require.NoError(t, err)
+
+	work := collection.UnitOfWork.NewUnitOfWork()
+	work.SaveCertificate(cert)
+	work.SavePrivateKey(key)
+	assert.ErrorContains(t, work.Commit(), "failed to save certificate")
+
+	_, err = collection.PrivateKey.FindByOrganizationAndSerialNumber(organization, big.NewInt(1))
+	assert.ErrorContains(t, err, "not found")
+}
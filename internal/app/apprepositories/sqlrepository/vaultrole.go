@@ -0,0 +1,127 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sqlrepository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+// SqlVaultRoleRepository implements appmodels.VaultRoleRepository on a SQL
+// database. Roles are stored as JSON records.
+type SqlVaultRoleRepository struct {
+	database *Database
+}
+
+func (r *SqlVaultRoleRepository) FindAllByOrganization(organization *big.Int) ([]appmodels.VaultRole, error) {
+	rows, err := r.database.db.Query(
+		r.database.dialect.Rebind(`SELECT record FROM vault_roles WHERE organization = ? ORDER BY name`),
+		organization.String(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[VaultRole:FindAllByOrganization]: failed to query: %w", err)
+	}
+	defer rows.Close()
+	list := []appmodels.VaultRole{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("[VaultRole:FindAllByOrganization]: failed to read: %w", err)
+		}
+		model, err := parseVaultRole(data)
+		if err != nil {
+			return nil, fmt.Errorf("[VaultRole:FindAllByOrganization]: %w", err)
+		}
+		list = append(list, model)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[VaultRole:FindAllByOrganization]: %w", err)
+	}
+	return list, nil
+}
+
+func (r *SqlVaultRoleRepository) FindByOrganizationAndName(organization *big.Int, name string) (appmodels.VaultRole, error) {
+	var data string
+	err := r.database.db.QueryRow(
+		r.database.dialect.Rebind(`SELECT record FROM vault_roles WHERE organization = ? AND name = ?`),
+		organization.String(),
+		name,
+	).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("[VaultRole:FindByOrganizationAndName]: not found: %s@%s", name, organization)
+		}
+		return nil, fmt.Errorf("[VaultRole:FindByOrganizationAndName]: failed to read: %w", err)
+	}
+	model, err := parseVaultRole(data)
+	if err != nil {
+		return nil, fmt.Errorf("[VaultRole:FindByOrganizationAndName]: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlVaultRoleRepository) Save(model appmodels.VaultRole) (appmodels.VaultRole, error) {
+	data, err := json.Marshal(apputils.ToVaultRoleRecordDTO(model))
+	if err != nil {
+		return nil, fmt.Errorf("[VaultRole:Save]: failed to marshal JSON: %w", err)
+	}
+	_, err = r.database.db.Exec(
+		r.database.dialect.Rebind(`INSERT INTO vault_roles (organization, name, record)
+			VALUES (?, ?, ?)
+			ON CONFLICT (organization, name) DO UPDATE SET
+				record = excluded.record`),
+		model.OrganizationID().String(),
+		model.Name(),
+		string(data),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[VaultRole:Save]: failed to save: %w", err)
+	}
+	return model, nil
+}
+
+func (r *SqlVaultRoleRepository) Delete(organization *big.Int, name string) error {
+	result, err := r.database.db.Exec(
+		r.database.dialect.Rebind(`DELETE FROM vault_roles WHERE organization = ? AND name = ?`),
+		organization.String(),
+		name,
+	)
+	if err != nil {
+		return fmt.Errorf("[VaultRole:Delete]: failed to delete: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("[VaultRole:Delete]: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("[VaultRole:Delete]: not found: %s@%s", name, organization)
+	}
+	return nil
+}
+
+// parseVaultRole parses a stored role
+func parseVaultRole(data string) (appmodels.VaultRole, error) {
+	dto := appdtos.VaultRoleRecordDTO{}
+	if err := json.Unmarshal([]byte(data), &dto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return apputils.FromVaultRoleRecordDTO(dto)
+}
+
+// NewVaultRoleRepository creates a SQL based repository for Vault PKI roles
+func NewVaultRoleRepository(
+	database *Database,
+) *SqlVaultRoleRepository {
+	return &SqlVaultRoleRepository{
+		database: database,
+	}
+}
+
+var _ appmodels.VaultRoleRepository = (*SqlVaultRoleRepository)(nil)
@@ -0,0 +1,371 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// AcmeTokenBits is the entropy of ACME tokens, nonces and identifiers
+const AcmeTokenBits = 128
+
+// GenerateAcmeToken creates a random base64url encoded value with
+// AcmeTokenBits of entropy
+func GenerateAcmeToken(randomManager managers.RandomManager) (string, error) {
+	if randomManager == nil {
+		return "", fmt.Errorf("GenerateAcmeToken: randomManager: must be defined")
+	}
+	value, err := randomManager.CreateBigInt(new(big.Int).Lsh(big.NewInt(1), AcmeTokenBits))
+	if err != nil {
+		return "", fmt.Errorf("GenerateAcmeToken: failed to create random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(value.FillBytes(make([]byte, AcmeTokenBits/8))), nil
+}
+
+// AcmeKeyAuthorization returns the key authorization of a challenge token
+// (RFC 8555 section 8.1)
+func AcmeKeyAuthorization(token, thumbprint string) string {
+	return token + "." + thumbprint
+}
+
+// AcmeDnsRecordValue returns the TXT record value expected by a dns-01
+// challenge for the key authorization
+func AcmeDnsRecordValue(keyAuthorization string) string {
+	sum := sha256.Sum256([]byte(keyAuthorization))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AcmeErrorStatusCode returns the HTTP status code for an ACME error type
+func AcmeErrorStatusCode(errorType appmodels.AcmeErrorType) int {
+	switch errorType {
+	case appmodels.ACME_ERROR_SERVER_INTERNAL:
+		return http.StatusInternalServerError
+	case appmodels.ACME_ERROR_UNAUTHORIZED, appmodels.ACME_ERROR_ORDER_NOT_READY:
+		return http.StatusForbidden
+	case appmodels.ACME_ERROR_NOT_FOUND:
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// IsAcmeWildcard returns true if the DNS name is a wildcard identifier
+func IsAcmeWildcard(dnsName string) bool {
+	return strings.HasPrefix(dnsName, "*.")
+}
+
+func ToAcmeProblemDTO(err *appmodels.AcmeError) appdtos.AcmeProblemDTO {
+	return appdtos.NewAcmeProblemDTO(
+		appdtos.AcmeErrorTypePrefix+err.Type().String(),
+		err.Detail(),
+		AcmeErrorStatusCode(err.Type()),
+	)
+}
+
+func ToAcmeAccountDTO(account appmodels.AcmeAccount, ordersURL string) appdtos.AcmeAccountDTO {
+	return appdtos.NewAcmeAccountDTO(
+		account.Status().String(),
+		account.Contact(),
+		ordersURL,
+	)
+}
+
+// ToAcmeOrderDTO converts an order to a DTO
+//   - order: The order
+//   - authorizationURLs: The URLs of the order authorizations in the same order
+//   - finalizeURL: The URL for finalizing the order
+//   - certificateURL: The URL of the certificate, used only for valid orders
+func ToAcmeOrderDTO(
+	order appmodels.AcmeOrder,
+	authorizationURLs []string,
+	finalizeURL string,
+	certificateURL string,
+) appdtos.AcmeOrderDTO {
+	identifiers := make([]appdtos.AcmeIdentifierDTO, len(order.DNSNames()))
+	for i, name := range order.DNSNames() {
+		identifiers[i] = appdtos.NewAcmeIdentifierDTO(appdtos.AcmeDnsIdentifier, name)
+	}
+	if order.Status() != appmodels.ACME_STATUS_VALID {
+		certificateURL = ""
+	}
+	var problem *appdtos.AcmeProblemDTO
+	if order.Error() != nil {
+		dto := ToAcmeProblemDTO(order.Error())
+		problem = &dto
+	}
+	return appdtos.NewAcmeOrderDTO(
+		order.Status().String(),
+		toAcmeTime(order.Expires()),
+		identifiers,
+		authorizationURLs,
+		finalizeURL,
+		certificateURL,
+		problem,
+	)
+}
+
+// ToAcmeAuthorizationDTO converts an authorization to a DTO
+//   - authorization: The authorization
+//   - challengeURL: The URL prefix of the challenges, the challenge ID is appended to it
+func ToAcmeAuthorizationDTO(authorization appmodels.AcmeAuthorization, challengeURL string) appdtos.AcmeAuthorizationDTO {
+	challenges := make([]appdtos.AcmeChallengeDTO, len(authorization.Challenges()))
+	for i, challenge := range authorization.Challenges() {
+		challenges[i] = ToAcmeChallengeDTO(challenge, challengeURL+"/"+challenge.ID())
+	}
+	return appdtos.NewAcmeAuthorizationDTO(
+		appdtos.NewAcmeIdentifierDTO(appdtos.AcmeDnsIdentifier, authorization.DNSName()),
+		authorization.Status().String(),
+		toAcmeTime(authorization.Expires()),
+		challenges,
+		authorization.Wildcard(),
+	)
+}
+
+func ToAcmeChallengeDTO(challenge appmodels.AcmeChallenge, url string) appdtos.AcmeChallengeDTO {
+	var problem *appdtos.AcmeProblemDTO
+	if challenge.Error() != nil {
+		dto := ToAcmeProblemDTO(challenge.Error())
+		problem = &dto
+	}
+	return appdtos.NewAcmeChallengeDTO(
+		challenge.Type().String(),
+		url,
+		challenge.Token(),
+		challenge.Status().String(),
+		toAcmeTime(challenge.Validated()),
+		problem,
+	)
+}
+
+func toAcmeTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// ParseAcmeStatus parses an ACME status, e.g. "pending"
+func ParseAcmeStatus(value string) (appmodels.AcmeStatus, error) {
+	for s := appmodels.ACME_STATUS_PENDING; s <= appmodels.ACME_STATUS_REVOKED; s++ {
+		if strings.EqualFold(s.String(), value) {
+			return s, nil
+		}
+	}
+	return appmodels.NIL_ACME_STATUS, fmt.Errorf("ParseAcmeStatus: unsupported: %s", value)
+}
+
+// ParseAcmeChallengeType parses an ACME challenge type, e.g. "http-01"
+func ParseAcmeChallengeType(value string) (appmodels.AcmeChallengeType, error) {
+	for t := appmodels.ACME_HTTP_01; t <= appmodels.ACME_TLS_ALPN_01; t++ {
+		if strings.EqualFold(t.String(), value) {
+			return t, nil
+		}
+	}
+	return appmodels.NIL_ACME_CHALLENGE_TYPE, fmt.Errorf("ParseAcmeChallengeType: unsupported: %s", value)
+}
+
+// ParseAcmeErrorType parses an ACME error type, e.g. "badNonce". The
+// "malformed" type returns appmodels.ACME_ERROR_MALFORMED.
+func ParseAcmeErrorType(value string) (appmodels.AcmeErrorType, error) {
+	for t := appmodels.ACME_ERROR_SERVER_INTERNAL; t <= appmodels.ACME_ERROR_NOT_FOUND; t++ {
+		if strings.EqualFold(t.String(), value) {
+			return t, nil
+		}
+	}
+	return appmodels.ACME_ERROR_SERVER_INTERNAL, fmt.Errorf("ParseAcmeErrorType: unsupported: %s", value)
+}
+
+// ToAcmeAccountRecordDTO converts an ACME account to its stored form
+func ToAcmeAccountRecordDTO(account appmodels.AcmeAccount) (appdtos.AcmeAccountRecordDTO, error) {
+	publicKey, err := x509.MarshalPKIXPublicKey(account.PublicKey())
+	if err != nil {
+		return appdtos.AcmeAccountRecordDTO{}, fmt.Errorf("ToAcmeAccountRecordDTO: failed to marshal public key: %w", err)
+	}
+	return appdtos.NewAcmeAccountRecordDTO(
+		account.ID(),
+		account.OrganizationID().String(),
+		account.IssuerSerialNumber().String(),
+		account.Status().String(),
+		account.Contact(),
+		publicKey,
+		account.Thumbprint(),
+		account.CreatedAt(),
+	), nil
+}
+
+// FromAcmeAccountRecordDTO converts the stored form of an ACME account to a
+// model
+func FromAcmeAccountRecordDTO(dto appdtos.AcmeAccountRecordDTO) (appmodels.AcmeAccount, error) {
+	organization, err := ParseBigInt(dto.Organization, 10)
+	if err != nil {
+		return nil, fmt.Errorf("FromAcmeAccountRecordDTO: invalid organization: %w", err)
+	}
+	issuer, err := ParseBigInt(dto.Issuer, 10)
+	if err != nil {
+		return nil, fmt.Errorf("FromAcmeAccountRecordDTO: invalid issuer: %w", err)
+	}
+	status, err := ParseAcmeStatus(dto.Status)
+	if err != nil {
+		return nil, fmt.Errorf("FromAcmeAccountRecordDTO: %w", err)
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(dto.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("FromAcmeAccountRecordDTO: invalid public key: %w", err)
+	}
+	return appmodels.NewAcmeAccount(
+		dto.ID,
+		organization,
+		issuer,
+		status,
+		dto.Contact,
+		publicKey,
+		dto.Thumbprint,
+		dto.CreatedAt,
+	), nil
+}
+
+// ToAcmeOrderRecordDTO converts an ACME order to its stored form
+func ToAcmeOrderRecordDTO(order appmodels.AcmeOrder) appdtos.AcmeOrderRecordDTO {
+	certificate := ""
+	if order.CertificateSerialNumber() != nil {
+		certificate = order.CertificateSerialNumber().String()
+	}
+	return appdtos.NewAcmeOrderRecordDTO(
+		order.ID(),
+		order.AccountID(),
+		order.Status().String(),
+		order.Expires(),
+		order.DNSNames(),
+		order.AuthorizationIDs(),
+		certificate,
+		toAcmeErrorRecordDTO(order.Error()),
+	)
+}
+
+// FromAcmeOrderRecordDTO converts the stored form of an ACME order to a
+// model
+func FromAcmeOrderRecordDTO(dto appdtos.AcmeOrderRecordDTO) (appmodels.AcmeOrder, error) {
+	status, err := ParseAcmeStatus(dto.Status)
+	if err != nil {
+		return nil, fmt.Errorf("FromAcmeOrderRecordDTO: %w", err)
+	}
+	var certificate *big.Int
+	if dto.Certificate != "" {
+		certificate, err = ParseBigInt(dto.Certificate, 10)
+		if err != nil {
+			return nil, fmt.Errorf("FromAcmeOrderRecordDTO: invalid certificate: %w", err)
+		}
+	}
+	orderError, err := fromAcmeErrorRecordDTO(dto.Error)
+	if err != nil {
+		return nil, fmt.Errorf("FromAcmeOrderRecordDTO: %w", err)
+	}
+	return appmodels.NewAcmeOrder(
+		dto.ID,
+		dto.Account,
+		status,
+		dto.Expires,
+		dto.DNSNames,
+		dto.Authorizations,
+		certificate,
+		orderError,
+	), nil
+}
+
+// ToAcmeAuthorizationRecordDTO converts an ACME authorization and its
+// challenges to the stored form
+func ToAcmeAuthorizationRecordDTO(authorization appmodels.AcmeAuthorization) appdtos.AcmeAuthorizationRecordDTO {
+	challenges := make([]appdtos.AcmeChallengeRecordDTO, len(authorization.Challenges()))
+	for i, challenge := range authorization.Challenges() {
+		challenges[i] = appdtos.NewAcmeChallengeRecordDTO(
+			challenge.ID(),
+			challenge.Type().String(),
+			challenge.Token(),
+			challenge.Status().String(),
+			challenge.Validated(),
+			toAcmeErrorRecordDTO(challenge.Error()),
+		)
+	}
+	return appdtos.NewAcmeAuthorizationRecordDTO(
+		authorization.ID(),
+		authorization.AccountID(),
+		authorization.OrderID(),
+		authorization.Status().String(),
+		authorization.Expires(),
+		authorization.DNSName(),
+		authorization.Wildcard(),
+		challenges,
+	)
+}
+
+// FromAcmeAuthorizationRecordDTO converts the stored form of an ACME
+// authorization to a model
+func FromAcmeAuthorizationRecordDTO(dto appdtos.AcmeAuthorizationRecordDTO) (appmodels.AcmeAuthorization, error) {
+	status, err := ParseAcmeStatus(dto.Status)
+	if err != nil {
+		return nil, fmt.Errorf("FromAcmeAuthorizationRecordDTO: %w", err)
+	}
+	challenges := make([]appmodels.AcmeChallenge, len(dto.Challenges))
+	for i, challenge := range dto.Challenges {
+		challengeType, err := ParseAcmeChallengeType(challenge.Type)
+		if err != nil {
+			return nil, fmt.Errorf("FromAcmeAuthorizationRecordDTO: %w", err)
+		}
+		challengeStatus, err := ParseAcmeStatus(challenge.Status)
+		if err != nil {
+			return nil, fmt.Errorf("FromAcmeAuthorizationRecordDTO: %w", err)
+		}
+		challengeError, err := fromAcmeErrorRecordDTO(challenge.Error)
+		if err != nil {
+			return nil, fmt.Errorf("FromAcmeAuthorizationRecordDTO: %w", err)
+		}
+		challenges[i] = appmodels.NewAcmeChallenge(
+			challenge.ID,
+			challengeType,
+			challenge.Token,
+			challengeStatus,
+			challenge.Validated,
+			challengeError,
+		)
+	}
+	return appmodels.NewAcmeAuthorization(
+		dto.ID,
+		dto.Account,
+		dto.Order,
+		status,
+		dto.Expires,
+		dto.DNSName,
+		dto.Wildcard,
+		challenges,
+	), nil
+}
+
+func toAcmeErrorRecordDTO(err *appmodels.AcmeError) *appdtos.AcmeErrorRecordDTO {
+	if err == nil {
+		return nil
+	}
+	dto := appdtos.NewAcmeErrorRecordDTO(err.Type().String(), err.Detail())
+	return &dto
+}
+
+func fromAcmeErrorRecordDTO(dto *appdtos.AcmeErrorRecordDTO) (*appmodels.AcmeError, error) {
+	if dto == nil {
+		return nil, nil
+	}
+	errorType, err := ParseAcmeErrorType(dto.Type)
+	if err != nil {
+		return nil, err
+	}
+	return appmodels.NewAcmeError(errorType, "%s", dto.Detail), nil
+}
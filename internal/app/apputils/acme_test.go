@@ -0,0 +1,191 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/commonmocks"
+)
+
+func TestGenerateAcmeToken(t *testing.T) {
+	randomManager := commonmocks.NewMockRandomManager()
+	randomManager.On("CreateBigInt", mock.Anything).Return(big.NewInt(1), nil).Once()
+	token, err := apputils.GenerateAcmeToken(randomManager)
+	assert.NoError(t, err)
+	assert.Equal(t, "AAAAAAAAAAAAAAAAAAAAAQ", token)
+
+	randomManager.On("CreateBigInt", mock.Anything).Return(nil, errors.New("fail")).Once()
+	_, err = apputils.GenerateAcmeToken(randomManager)
+	assert.Error(t, err)
+
+	_, err = apputils.GenerateAcmeToken(nil)
+	assert.Error(t, err)
+}
+
+func TestAcmeKeyAuthorization(t *testing.T) {
+	assert.Equal(t, "token.thumbprint", apputils.AcmeKeyAuthorization("token", "thumbprint"))
+}
+
+func TestAcmeDnsRecordValue(t *testing.T) {
+	assert.Equal(t, "LPJNul-wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ", apputils.AcmeDnsRecordValue("hello"))
+}
+
+func TestAcmeErrorStatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusInternalServerError, apputils.AcmeErrorStatusCode(appmodels.ACME_ERROR_SERVER_INTERNAL))
+	assert.Equal(t, http.StatusForbidden, apputils.AcmeErrorStatusCode(appmodels.ACME_ERROR_UNAUTHORIZED))
+	assert.Equal(t, http.StatusForbidden, apputils.AcmeErrorStatusCode(appmodels.ACME_ERROR_ORDER_NOT_READY))
+	assert.Equal(t, http.StatusNotFound, apputils.AcmeErrorStatusCode(appmodels.ACME_ERROR_NOT_FOUND))
+	assert.Equal(t, http.StatusBadRequest, apputils.AcmeErrorStatusCode(appmodels.ACME_ERROR_BAD_NONCE))
+}
+
+func TestIsAcmeWildcard(t *testing.T) {
+	assert.True(t, apputils.IsAcmeWildcard("*.example.com"))
+	assert.False(t, apputils.IsAcmeWildcard("example.com"))
+}
+
+func TestToAcmeProblemDTO(t *testing.T) {
+	dto := apputils.ToAcmeProblemDTO(appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_CSR, "bad %s", "csr"))
+	assert.Equal(t, "urn:ietf:params:acme:error:badCSR", dto.Type)
+	assert.Equal(t, "bad csr", dto.Detail)
+	assert.Equal(t, http.StatusBadRequest, dto.Status)
+}
+
+func TestToAcmeAccountDTO(t *testing.T) {
+	account := appmodels.NewAcmeAccount("a1", big.NewInt(1), big.NewInt(2), appmodels.ACME_STATUS_VALID, []string{"mailto:a@example.com"}, nil, "tp", time.Now())
+	dto := apputils.ToAcmeAccountDTO(account, "https://x/account/a1/orders")
+	assert.Equal(t, "valid", dto.Status)
+	assert.Equal(t, []string{"mailto:a@example.com"}, dto.Contact)
+	assert.Equal(t, "https://x/account/a1/orders", dto.Orders)
+}
+
+func TestToAcmeOrderDTO(t *testing.T) {
+	expires := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	order := appmodels.NewAcmeOrder("o1", "a1", appmodels.ACME_STATUS_PENDING, expires, []string{"example.com"}, []string{"z1"}, nil, nil)
+	dto := apputils.ToAcmeOrderDTO(order, []string{"https://x/authz/z1"}, "https://x/order/o1/finalize", "https://x/cert/1")
+	assert.Equal(t, "pending", dto.Status)
+	assert.Equal(t, "2024-01-02T03:04:05Z", dto.Expires)
+	assert.Equal(t, "dns", dto.Identifiers[0].Type)
+	assert.Equal(t, "example.com", dto.Identifiers[0].Value)
+	assert.Equal(t, []string{"https://x/authz/z1"}, dto.Authorizations)
+	assert.Equal(t, "https://x/order/o1/finalize", dto.Finalize)
+	assert.Empty(t, dto.Certificate)
+	assert.Nil(t, dto.Error)
+
+	invalid := appmodels.NewAcmeOrder("o1", "a1", appmodels.ACME_STATUS_INVALID, expires, []string{"example.com"}, []string{"z1"}, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_DNS, "fail"))
+	dto = apputils.ToAcmeOrderDTO(invalid, nil, "", "")
+	assert.Equal(t, "urn:ietf:params:acme:error:dns", dto.Error.Type)
+
+	valid := appmodels.NewAcmeOrder("o1", "a1", appmodels.ACME_STATUS_VALID, expires, []string{"example.com"}, []string{"z1"}, big.NewInt(1), nil)
+	dto = apputils.ToAcmeOrderDTO(valid, nil, "", "https://x/cert/1")
+	assert.Equal(t, "https://x/cert/1", dto.Certificate)
+}
+
+func TestToAcmeAuthorizationDTO(t *testing.T) {
+	challenge := appmodels.NewAcmeChallenge("c1", appmodels.ACME_DNS_01, "token", appmodels.ACME_STATUS_VALID, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	authorization := appmodels.NewAcmeAuthorization("z1", "a1", "o1", appmodels.ACME_STATUS_VALID, time.Time{}, "example.com", true, []appmodels.AcmeChallenge{challenge})
+	dto := apputils.ToAcmeAuthorizationDTO(authorization, "https://x/chall/z1")
+	assert.Equal(t, "example.com", dto.Identifier.Value)
+	assert.Equal(t, "valid", dto.Status)
+	assert.Empty(t, dto.Expires)
+	assert.True(t, dto.Wildcard)
+	assert.Len(t, dto.Challenges, 1)
+	assert.Equal(t, "dns-01", dto.Challenges[0].Type)
+	assert.Equal(t, "https://x/chall/z1/c1", dto.Challenges[0].URL)
+	assert.Equal(t, "token", dto.Challenges[0].Token)
+	assert.Equal(t, "2024-01-01T00:00:00Z", dto.Challenges[0].Validated)
+}
+
+func TestParseAcmeStatus(t *testing.T) {
+	status, err := apputils.ParseAcmeStatus("Valid")
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ACME_STATUS_VALID, status)
+	_, err = apputils.ParseAcmeStatus("")
+	assert.Error(t, err)
+}
+
+func TestParseAcmeChallengeType(t *testing.T) {
+	challengeType, err := apputils.ParseAcmeChallengeType("tls-alpn-01")
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ACME_TLS_ALPN_01, challengeType)
+	_, err = apputils.ParseAcmeChallengeType("http-02")
+	assert.Error(t, err)
+}
+
+func TestParseAcmeErrorType(t *testing.T) {
+	errorType, err := apputils.ParseAcmeErrorType("badCSR")
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ACME_ERROR_BAD_CSR, errorType)
+	errorType, err = apputils.ParseAcmeErrorType("malformed")
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ACME_ERROR_MALFORMED, errorType)
+	_, err = apputils.ParseAcmeErrorType("unknown")
+	assert.Error(t, err)
+}
+
+func TestAcmeAccountRecordDTO(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	account := appmodels.NewAcmeAccount("id", big.NewInt(1), big.NewInt(2), appmodels.ACME_STATUS_VALID, []string{"mailto:admin@example.com"}, &key.PublicKey, "thumbprint", createdAt)
+
+	dto, err := apputils.ToAcmeAccountRecordDTO(account)
+	require.NoError(t, err)
+	model, err := apputils.FromAcmeAccountRecordDTO(dto)
+	require.NoError(t, err)
+	assert.Equal(t, account, model)
+
+	_, err = apputils.ToAcmeAccountRecordDTO(appmodels.NewAcmeAccount("id", big.NewInt(1), big.NewInt(2), appmodels.ACME_STATUS_VALID, nil, "invalid", "thumbprint", createdAt))
+	assert.Error(t, err)
+
+	dto.PublicKey = []byte("invalid")
+	_, err = apputils.FromAcmeAccountRecordDTO(dto)
+	assert.Error(t, err)
+}
+
+func TestAcmeOrderRecordDTO(t *testing.T) {
+	expires := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, order := range []appmodels.AcmeOrder{
+		appmodels.NewAcmeOrder("id", "account", appmodels.ACME_STATUS_PENDING, expires, []string{"www.example.com"}, []string{"authz"}, nil, nil),
+		appmodels.NewAcmeOrder("id", "account", appmodels.ACME_STATUS_VALID, expires, []string{"www.example.com"}, []string{"authz"}, big.NewInt(3), nil),
+		appmodels.NewAcmeOrder("id", "account", appmodels.ACME_STATUS_INVALID, expires, []string{"www.example.com"}, []string{"authz"}, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_CSR, "invalid")),
+	} {
+		model, err := apputils.FromAcmeOrderRecordDTO(apputils.ToAcmeOrderRecordDTO(order))
+		require.NoError(t, err)
+		assert.Equal(t, order, model)
+	}
+
+	dto := apputils.ToAcmeOrderRecordDTO(appmodels.NewAcmeOrder("id", "account", appmodels.ACME_STATUS_PENDING, expires, nil, nil, nil, nil))
+	dto.Status = "unknown"
+	_, err := apputils.FromAcmeOrderRecordDTO(dto)
+	assert.Error(t, err)
+}
+
+func TestAcmeAuthorizationRecordDTO(t *testing.T) {
+	expires := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	authorization := appmodels.NewAcmeAuthorization("id", "account", "order", appmodels.ACME_STATUS_INVALID, expires, "example.com", true, []appmodels.AcmeChallenge{
+		appmodels.NewAcmeChallenge("1", appmodels.ACME_DNS_01, "token", appmodels.ACME_STATUS_INVALID, time.Time{}, appmodels.NewAcmeError(appmodels.ACME_ERROR_DNS, "no record")),
+		appmodels.NewAcmeChallenge("2", appmodels.ACME_HTTP_01, "token2", appmodels.ACME_STATUS_VALID, expires, nil),
+	})
+	model, err := apputils.FromAcmeAuthorizationRecordDTO(apputils.ToAcmeAuthorizationRecordDTO(authorization))
+	require.NoError(t, err)
+	assert.Equal(t, authorization, model)
+
+	dto := apputils.ToAcmeAuthorizationRecordDTO(authorization)
+	dto.Challenges[0].Type = "unknown"
+	_, err = apputils.FromAcmeAuthorizationRecordDTO(dto)
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// AcmeTlsAlpnProtocol is the ALPN protocol of tls-alpn-01 challenges
+const AcmeTlsAlpnProtocol = "acme-tls/1"
+
+// AcmeIdentifierExtensionOID is the id-pe-acmeIdentifier extension (RFC 8737)
+var AcmeIdentifierExtensionOID = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}
+
+// ValidateAcmeChallenge validates a challenge using the method of its type.
+// It returns an AcmeError describing why the validation failed.
+//   - networkManager: The network manager
+//   - challengeType: The challenge type
+//   - dnsName: The DNS name to validate
+//   - port: The port for http-01 or tls-alpn-01 challenges
+//   - keyAuthorization: The expected key authorization
+func ValidateAcmeChallenge(
+	networkManager managers.NetworkManager,
+	challengeType appmodels.AcmeChallengeType,
+	dnsName string,
+	port int,
+	keyAuthorization string,
+) *appmodels.AcmeError {
+	switch challengeType {
+	case appmodels.ACME_HTTP_01:
+		return ValidateAcmeHttp01(networkManager, dnsName, port, keyAuthorization)
+	case appmodels.ACME_DNS_01:
+		return ValidateAcmeDns01(networkManager, dnsName, keyAuthorization)
+	case appmodels.ACME_TLS_ALPN_01:
+		return ValidateAcmeTlsAlpn01(networkManager, dnsName, port, keyAuthorization)
+	default:
+		return appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "unsupported challenge type: %s", challengeType)
+	}
+}
+
+// ValidateAcmeHttp01 validates an http-01 challenge (RFC 8555 section 8.3)
+func ValidateAcmeHttp01(
+	networkManager managers.NetworkManager,
+	dnsName string,
+	port int,
+	keyAuthorization string,
+) *appmodels.AcmeError {
+	token, _, _ := strings.Cut(keyAuthorization, ".")
+	url := fmt.Sprintf("http://%s/.well-known/acme-challenge/%s", net.JoinHostPort(dnsName, strconv.Itoa(port)), token)
+	body, err := networkManager.HttpGet(url)
+	if err != nil {
+		return appmodels.NewAcmeError(appmodels.ACME_ERROR_CONNECTION, "failed to fetch %s: %v", url, err)
+	}
+	if strings.TrimSpace(string(body)) != keyAuthorization {
+		return appmodels.NewAcmeError(appmodels.ACME_ERROR_INCORRECT_RESPONSE, "unexpected key authorization at %s", url)
+	}
+	return nil
+}
+
+// ValidateAcmeDns01 validates a dns-01 challenge (RFC 8555 section 8.4)
+func ValidateAcmeDns01(
+	networkManager managers.NetworkManager,
+	dnsName string,
+	keyAuthorization string,
+) *appmodels.AcmeError {
+	name := "_acme-challenge." + dnsName
+	records, err := networkManager.LookupTXT(name)
+	if err != nil {
+		return appmodels.NewAcmeError(appmodels.ACME_ERROR_DNS, "failed to look up TXT records for %s: %v", name, err)
+	}
+	expected := AcmeDnsRecordValue(keyAuthorization)
+	for _, record := range records {
+		if record == expected {
+			return nil
+		}
+	}
+	return appmodels.NewAcmeError(appmodels.ACME_ERROR_INCORRECT_RESPONSE, "no matching TXT record for %s", name)
+}
+
+// ValidateAcmeTlsAlpn01 validates a tls-alpn-01 challenge (RFC 8737)
+func ValidateAcmeTlsAlpn01(
+	networkManager managers.NetworkManager,
+	dnsName string,
+	port int,
+	keyAuthorization string,
+) *appmodels.AcmeError {
+	address := net.JoinHostPort(dnsName, strconv.Itoa(port))
+	cert, err := networkManager.TLSPeerCertificate(address, dnsName, []string{AcmeTlsAlpnProtocol})
+	if err != nil {
+		return appmodels.NewAcmeError(appmodels.ACME_ERROR_TLS, "failed to connect to %s: %v", address, err)
+	}
+
+	if len(cert.DNSNames) != 1 || !strings.EqualFold(cert.DNSNames[0], dnsName) {
+		return appmodels.NewAcmeError(appmodels.ACME_ERROR_INCORRECT_RESPONSE, "certificate from %s must have only %s as subject alternative name", address, dnsName)
+	}
+
+	expected := sha256.Sum256([]byte(keyAuthorization))
+	for _, extension := range cert.Extensions {
+		if !extension.Id.Equal(AcmeIdentifierExtensionOID) {
+			continue
+		}
+		if !extension.Critical {
+			return appmodels.NewAcmeError(appmodels.ACME_ERROR_INCORRECT_RESPONSE, "acmeIdentifier extension from %s must be critical", address)
+		}
+		var value []byte
+		if rest, err := asn1.Unmarshal(extension.Value, &value); err != nil || len(rest) != 0 {
+			return appmodels.NewAcmeError(appmodels.ACME_ERROR_INCORRECT_RESPONSE, "malformed acmeIdentifier extension from %s", address)
+		}
+		if !bytes.Equal(value, expected[:]) {
+			return appmodels.NewAcmeError(appmodels.ACME_ERROR_INCORRECT_RESPONSE, "unexpected key authorization from %s", address)
+		}
+		return nil
+	}
+	return appmodels.NewAcmeError(appmodels.ACME_ERROR_INCORRECT_RESPONSE, "certificate from %s has no acmeIdentifier extension", address)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/commonmocks"
+)
+
+func TestValidateAcmeHttp01(t *testing.T) {
+	networkManager := commonmocks.NewMockNetworkManager()
+	url := "http://example.com:80/.well-known/acme-challenge/token"
+	networkManager.On("HttpGet", url).Return([]byte("token.thumbprint\n"), nil).Once()
+	assert.Nil(t, apputils.ValidateAcmeChallenge(networkManager, appmodels.ACME_HTTP_01, "example.com", 80, "token.thumbprint"))
+
+	networkManager.On("HttpGet", url).Return([]byte("wrong"), nil).Once()
+	err := apputils.ValidateAcmeHttp01(networkManager, "example.com", 80, "token.thumbprint")
+	require.NotNil(t, err)
+	assert.Equal(t, appmodels.ACME_ERROR_INCORRECT_RESPONSE, err.Type())
+
+	networkManager.On("HttpGet", url).Return(nil, errors.New("refused")).Once()
+	err = apputils.ValidateAcmeHttp01(networkManager, "example.com", 80, "token.thumbprint")
+	require.NotNil(t, err)
+	assert.Equal(t, appmodels.ACME_ERROR_CONNECTION, err.Type())
+}
+
+func TestValidateAcmeDns01(t *testing.T) {
+	networkManager := commonmocks.NewMockNetworkManager()
+	value := apputils.AcmeDnsRecordValue("token.thumbprint")
+	networkManager.On("LookupTXT", "_acme-challenge.example.com").Return([]string{"other", value}, nil).Once()
+	assert.Nil(t, apputils.ValidateAcmeChallenge(networkManager, appmodels.ACME_DNS_01, "example.com", 0, "token.thumbprint"))
+
+	networkManager.On("LookupTXT", "_acme-challenge.example.com").Return([]string{"other"}, nil).Once()
+	err := apputils.ValidateAcmeDns01(networkManager, "example.com", "token.thumbprint")
+	require.NotNil(t, err)
+	assert.Equal(t, appmodels.ACME_ERROR_INCORRECT_RESPONSE, err.Type())
+
+	networkManager.On("LookupTXT", "_acme-challenge.example.com").Return(nil, errors.New("nxdomain")).Once()
+	err = apputils.ValidateAcmeDns01(networkManager, "example.com", "token.thumbprint")
+	require.NotNil(t, err)
+	assert.Equal(t, appmodels.ACME_ERROR_DNS, err.Type())
+}
+
+func newTlsAlpnCertificate(t *testing.T, dnsName string, keyAuthorization string, critical bool) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte(keyAuthorization))
+	value, err := asn1.Marshal(sum[:])
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: dnsName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{dnsName},
+		ExtraExtensions: []pkix.Extension{
+			{Id: apputils.AcmeIdentifierExtensionOID, Critical: critical, Value: value},
+		},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestValidateAcmeTlsAlpn01(t *testing.T) {
+	networkManager := commonmocks.NewMockNetworkManager()
+	protocols := []string{apputils.AcmeTlsAlpnProtocol}
+
+	networkManager.On("TLSPeerCertificate", "example.com:443", "example.com", protocols).Return(newTlsAlpnCertificate(t, "example.com", "token.thumbprint", true), nil).Once()
+	assert.Nil(t, apputils.ValidateAcmeChallenge(networkManager, appmodels.ACME_TLS_ALPN_01, "example.com", 443, "token.thumbprint"))
+
+	networkManager.On("TLSPeerCertificate", "example.com:443", "example.com", protocols).Return(newTlsAlpnCertificate(t, "example.com", "other", true), nil).Once()
+	err := apputils.ValidateAcmeTlsAlpn01(networkManager, "example.com", 443, "token.thumbprint")
+	require.NotNil(t, err)
+	assert.Equal(t, appmodels.ACME_ERROR_INCORRECT_RESPONSE, err.Type())
+
+	networkManager.On("TLSPeerCertificate", "example.com:443", "example.com", protocols).Return(newTlsAlpnCertificate(t, "example.com", "token.thumbprint", false), nil).Once()
+	err = apputils.ValidateAcmeTlsAlpn01(networkManager, "example.com", 443, "token.thumbprint")
+	require.NotNil(t, err)
+
+	networkManager.On("TLSPeerCertificate", "example.com:443", "example.com", protocols).Return(newTlsAlpnCertificate(t, "other.com", "token.thumbprint", true), nil).Once()
+	err = apputils.ValidateAcmeTlsAlpn01(networkManager, "example.com", 443, "token.thumbprint")
+	require.NotNil(t, err)
+
+	networkManager.On("TLSPeerCertificate", "example.com:443", "example.com", protocols).Return(nil, errors.New("refused")).Once()
+	err = apputils.ValidateAcmeTlsAlpn01(networkManager, "example.com", 443, "token.thumbprint")
+	require.NotNil(t, err)
+	assert.Equal(t, appmodels.ACME_ERROR_TLS, err.Type())
+}
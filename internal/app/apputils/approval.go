@@ -0,0 +1,150 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// ToPendingOperationDTO converts an operation to a DTO
+//   - operation: The operation
+//   - threshold: The number of approvals needed
+func ToPendingOperationDTO(operation appmodels.PendingOperation, threshold int) appdtos.PendingOperationDTO {
+	certificate := ""
+	if operation.SerialNumber() != nil {
+		certificate = operation.SerialNumber().String()
+	}
+	var executedAt *time.Time
+	if !operation.ExecutedAt().IsZero() {
+		value := operation.ExecutedAt()
+		executedAt = &value
+	}
+	approvals := make([]appdtos.OperationApprovalDTO, len(operation.Approvals()))
+	for i, approval := range operation.Approvals() {
+		approvals[i] = appdtos.NewOperationApprovalDTO(
+			approval.Approver(),
+			approval.Approved(),
+			approval.CreatedAt(),
+		)
+	}
+	return appdtos.NewPendingOperationDTO(
+		operation.ID(),
+		operation.Type().String(),
+		operation.OrganizationID().String(),
+		certificate,
+		operation.Details(),
+		operation.RequestedBy(),
+		operation.Status().String(),
+		threshold,
+		operation.CreatedAt(),
+		operation.ExpiresAt(),
+		executedAt,
+		approvals,
+	)
+}
+
+// ToPendingOperationListDTO converts operations to a list DTO
+func ToPendingOperationListDTO(list []appmodels.PendingOperation, threshold int) appdtos.PendingOperationListDTO {
+	payload := make([]appdtos.PendingOperationDTO, len(list))
+	for i, operation := range list {
+		payload[i] = ToPendingOperationDTO(operation, threshold)
+	}
+	return appdtos.NewPendingOperationListDTO(payload)
+}
+
+// ParseOperationType parses an operation type, e.g.
+// "create-root-certificate"
+func ParseOperationType(value string) (appmodels.OperationType, error) {
+	for t := appmodels.OPERATION_CREATE_ROOT_CERTIFICATE; t <= appmodels.OPERATION_IMPORT_PRIVATE_KEY; t++ {
+		if strings.EqualFold(t.String(), value) {
+			return t, nil
+		}
+	}
+	return appmodels.NIL_OPERATION_TYPE, fmt.Errorf("ParseOperationType: unsupported: %s", value)
+}
+
+// ParseOperationStatus parses an operation status, e.g. "pending"
+func ParseOperationStatus(value string) (appmodels.OperationStatus, error) {
+	for s := appmodels.OPERATION_STATUS_PENDING; s <= appmodels.OPERATION_STATUS_EXPIRED; s++ {
+		if strings.EqualFold(s.String(), value) {
+			return s, nil
+		}
+	}
+	return appmodels.NIL_OPERATION_STATUS, fmt.Errorf("ParseOperationStatus: unsupported: %s", value)
+}
+
+// ToPendingOperationRecordDTO converts an operation to its stored form
+func ToPendingOperationRecordDTO(operation appmodels.PendingOperation) appdtos.PendingOperationRecordDTO {
+	certificate := ""
+	if operation.SerialNumber() != nil {
+		certificate = operation.SerialNumber().String()
+	}
+	approvals := make([]appdtos.OperationApprovalDTO, len(operation.Approvals()))
+	for i, approval := range operation.Approvals() {
+		approvals[i] = appdtos.NewOperationApprovalDTO(
+			approval.Approver(),
+			approval.Approved(),
+			approval.CreatedAt(),
+		)
+	}
+	return appdtos.NewPendingOperationRecordDTO(
+		operation.ID(),
+		operation.Type().String(),
+		operation.OrganizationID().String(),
+		certificate,
+		operation.Details(),
+		operation.RequestedBy(),
+		operation.Status().String(),
+		operation.CreatedAt(),
+		operation.ExpiresAt(),
+		approvals,
+		operation.ExecutedAt(),
+	)
+}
+
+// FromPendingOperationRecordDTO converts the stored form of an operation to
+// a model
+func FromPendingOperationRecordDTO(dto appdtos.PendingOperationRecordDTO) (appmodels.PendingOperation, error) {
+	operationType, err := ParseOperationType(dto.Type)
+	if err != nil {
+		return nil, fmt.Errorf("FromPendingOperationRecordDTO: %w", err)
+	}
+	status, err := ParseOperationStatus(dto.Status)
+	if err != nil {
+		return nil, fmt.Errorf("FromPendingOperationRecordDTO: %w", err)
+	}
+	organization, err := ParseBigInt(dto.Organization, 10)
+	if err != nil {
+		return nil, fmt.Errorf("FromPendingOperationRecordDTO: invalid organization: %w", err)
+	}
+	var certificate *big.Int
+	if dto.Certificate != "" {
+		certificate, err = ParseBigInt(dto.Certificate, 10)
+		if err != nil {
+			return nil, fmt.Errorf("FromPendingOperationRecordDTO: invalid certificate: %w", err)
+		}
+	}
+	approvals := make([]appmodels.OperationApproval, len(dto.Approvals))
+	for i, approval := range dto.Approvals {
+		approvals[i] = appmodels.NewOperationApproval(approval.Approver, approval.Approved, approval.CreatedAt)
+	}
+	return appmodels.NewPendingOperation(
+		dto.ID,
+		operationType,
+		organization,
+		certificate,
+		dto.Details,
+		dto.RequestedBy,
+		status,
+		dto.CreatedAt,
+		dto.ExpiresAt,
+		approvals,
+		dto.ExecutedAt,
+	), nil
+}
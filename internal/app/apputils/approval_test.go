@@ -0,0 +1,82 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+func TestToPendingOperationDTO(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	operation := appmodels.NewPendingOperation(
+		"id",
+		appmodels.OPERATION_REVOKE_ROOT_CERTIFICATE,
+		big.NewInt(1),
+		big.NewInt(2),
+		"",
+		"bob",
+		appmodels.OPERATION_STATUS_PENDING,
+		createdAt,
+		createdAt.Add(time.Hour),
+		[]appmodels.OperationApproval{appmodels.NewOperationApproval("alice", true, createdAt)},
+		time.Time{},
+	)
+
+	dto := apputils.ToPendingOperationDTO(operation, 2)
+	assert.Equal(t, "id", dto.ID)
+	assert.Equal(t, "revoke-root-certificate", dto.Type)
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "2", dto.Certificate)
+	assert.Equal(t, "bob", dto.RequestedBy)
+	assert.Equal(t, "pending", dto.Status)
+	assert.Equal(t, 2, dto.Threshold)
+	assert.Nil(t, dto.ExecutedAt)
+	require.Len(t, dto.Approvals, 1)
+	assert.Equal(t, "alice", dto.Approvals[0].Approver)
+
+	list := apputils.ToPendingOperationListDTO([]appmodels.PendingOperation{operation}, 2)
+	assert.Equal(t, []string{"id"}, []string{list.Payload[0].ID})
+}
+
+func TestPendingOperationRecordDTO(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, certificate := range []*big.Int{nil, big.NewInt(2)} {
+		operation := appmodels.NewPendingOperation(
+			"id",
+			appmodels.OPERATION_IMPORT_PRIVATE_KEY,
+			big.NewInt(1),
+			certificate,
+			"shares=2",
+			"bob",
+			appmodels.OPERATION_STATUS_EXECUTED,
+			createdAt,
+			createdAt.Add(time.Hour),
+			[]appmodels.OperationApproval{appmodels.NewOperationApproval("alice", true, createdAt)},
+			createdAt.Add(time.Minute),
+		)
+		model, err := apputils.FromPendingOperationRecordDTO(apputils.ToPendingOperationRecordDTO(operation))
+		require.NoError(t, err)
+		assert.Equal(t, operation, model)
+	}
+
+	dto := apputils.ToPendingOperationRecordDTO(appmodels.NewPendingOperation("id", appmodels.OPERATION_BACKUP_ORGANIZATION, big.NewInt(1), nil, "", "bob", appmodels.OPERATION_STATUS_PENDING, createdAt, createdAt, nil, time.Time{}))
+	dto.Type = "unknown"
+	_, err := apputils.FromPendingOperationRecordDTO(dto)
+	assert.Error(t, err)
+}
+
+func TestParseOperationStatus(t *testing.T) {
+	status, err := apputils.ParseOperationStatus("Approved")
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.OPERATION_STATUS_APPROVED, status)
+	_, err = apputils.ParseOperationStatus("")
+	assert.Error(t, err)
+}
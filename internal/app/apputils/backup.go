@@ -0,0 +1,906 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// BackupArchiveVersion is the newest supported format version of backup
+// archives. Archives of newer versions are refused. Version 2 added the last
+// CRL of each CA certificate, and version 3 the state of the EST, SCEP,
+// Vault and ACME servers.
+const BackupArchiveVersion = 3
+
+// Files of the unencrypted outer tar archive
+const (
+	BackupManifestFileName  = "manifest.json"
+	BackupSignatureFileName = "manifest.sig"
+	BackupPayloadFileName   = "payload.enc"
+)
+
+// Files of the encrypted payload, which is also a tar archive
+const (
+	BackupOrganizationFileName = "organization.json"
+	BackupCertificatesDirName  = "certificates"
+	BackupIssuersFileName      = "certificates.json"
+	BackupPrivateKeysDirName   = "private-keys"
+	BackupRevocationsFileName  = "revocations.json"
+
+	BackupRevocationListsFileName = "revocation-lists.json"
+
+	BackupEstLabelsFileName          = "est-labels.json"
+	BackupScepChallengeFileName      = "scep-challenge.json"
+	BackupVaultRolesFileName         = "vault-roles.json"
+	BackupAcmeAccountsFileName       = "acme-accounts.json"
+	BackupAcmeOrdersFileName         = "acme-orders.json"
+	BackupAcmeAuthorizationsFileName = "acme-authorizations.json"
+)
+
+// BackupCipher and BackupKdf are the only supported encryption methods
+const (
+	BackupCipher = "AES-256-GCM"
+	BackupKdf    = "scrypt"
+)
+
+// Scrypt cost parameters of new archives. Archives which would need more
+// memory or parallelism are refused to limit the cost of verifying them.
+const (
+	BackupScryptN         = 1 << 15
+	BackupScryptR         = 8
+	BackupScryptP         = 1
+	BackupScryptMaxMemory = 1 << 30
+	BackupScryptMaxP      = 16
+)
+
+// BackupErrorStatusCode returns the HTTP status code of a backup error type
+func BackupErrorStatusCode(errorType appmodels.BackupErrorType) int {
+	switch errorType {
+	case appmodels.BACKUP_ERROR_BAD_REQUEST:
+		return http.StatusBadRequest
+	case appmodels.BACKUP_ERROR_PERMISSION_DENIED:
+		return http.StatusForbidden
+	case appmodels.BACKUP_ERROR_NOT_FOUND:
+		return http.StatusNotFound
+	case appmodels.BACKUP_ERROR_CONFLICT:
+		return http.StatusConflict
+	case appmodels.BACKUP_ERROR_SEALED:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteBackupArchive encodes an organization backup as an archive. The
+// payload is encrypted with a key derived from the passphrase, and the
+// manifest is signed with the private key of a root certificate.
+//   - certManager: The certificate manager
+//   - backup: The content of the archive
+//   - signerCertificate: The root certificate which signs the archive
+//   - signerPrivateKey: The private key of the root certificate
+//   - passphrase: The passphrase used to encrypt the payload
+func WriteBackupArchive(
+	certManager managers.CertificateManager,
+	backup appmodels.OrganizationBackup,
+	signerCertificate appmodels.Certificate,
+	signerPrivateKey appmodels.PrivateKey,
+	passphrase string,
+) ([]byte, error) {
+
+	if passphrase == "" {
+		return nil, errors.New("WriteBackupArchive: passphrase must be defined")
+	}
+
+	payload, err := marshalBackupPayload(certManager, backup)
+	if err != nil {
+		return nil, fmt.Errorf("WriteBackupArchive: %w", err)
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("WriteBackupArchive: salt: %w", err)
+	}
+	aead, err := newBackupCipher(passphrase, salt, BackupScryptN, BackupScryptR, BackupScryptP)
+	if err != nil {
+		return nil, fmt.Errorf("WriteBackupArchive: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("WriteBackupArchive: nonce: %w", err)
+	}
+	encrypted := aead.Seal(nil, nonce, payload, nil)
+	digest := sha256.Sum256(encrypted)
+
+	manifest, err := json.MarshalIndent(appdtos.NewBackupManifestDTO(
+		backup.Version(),
+		backup.Organization().ID().String(),
+		backup.CreatedAt().UTC(),
+		string(CertificateToPEMBytes(signerCertificate)),
+		appdtos.NewBackupEncryptionDTO(BackupCipher, BackupKdf, salt, BackupScryptN, BackupScryptR, BackupScryptP, nonce),
+		digest[:],
+	), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("WriteBackupArchive: manifest: %w", err)
+	}
+
+	signature, err := signBackupManifest(signerPrivateKey, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("WriteBackupArchive: %w", err)
+	}
+
+	var buffer bytes.Buffer
+	writer := tar.NewWriter(&buffer)
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{
+		{BackupManifestFileName, manifest},
+		{BackupSignatureFileName, signature},
+		{BackupPayloadFileName, encrypted},
+	} {
+		if err := writeTarFile(writer, file.name, file.data, backup.CreatedAt()); err != nil {
+			return nil, fmt.Errorf("WriteBackupArchive: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("WriteBackupArchive: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// ReadBackupArchive verifies the signature of an archive, decrypts it and
+// parses the content. The archive must be signed by one of the trust anchors
+// of the organization which is also one of the root certificates in the
+// archive, and every private key must belong to a certificate in the archive.
+//   - certManager: The certificate manager
+//   - archive: The archive
+//   - passphrase: The passphrase used to encrypt the payload
+//   - trustAnchors: Returns the certificates trusted to sign backups of an
+//     organization
+func ReadBackupArchive(
+	certManager managers.CertificateManager,
+	archive []byte,
+	passphrase string,
+	trustAnchors func(organization *big.Int) ([]*x509.Certificate, error),
+) (appmodels.OrganizationBackup, error) {
+
+	files, err := readTarFiles(bytes.NewReader(archive), func(name string) bool {
+		return name == BackupManifestFileName || name == BackupSignatureFileName || name == BackupPayloadFileName
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ReadBackupArchive: %w", err)
+	}
+	manifest, signature, encrypted := files[BackupManifestFileName], files[BackupSignatureFileName], files[BackupPayloadFileName]
+	if manifest == nil || signature == nil || encrypted == nil {
+		return nil, errors.New("ReadBackupArchive: not a backup archive")
+	}
+
+	var dto appdtos.BackupManifestDTO
+	if err := json.Unmarshal(manifest, &dto); err != nil {
+		return nil, fmt.Errorf("ReadBackupArchive: manifest: %w", err)
+	}
+	if dto.Version < 1 || dto.Version > BackupArchiveVersion {
+		return nil, fmt.Errorf("ReadBackupArchive: unsupported version %d, newest supported is %d", dto.Version, BackupArchiveVersion)
+	}
+
+	signer, err := parseCertificatePEM(certManager, []byte(dto.Signer))
+	if err != nil {
+		return nil, fmt.Errorf("ReadBackupArchive: signer: %w", err)
+	}
+	if err := verifyBackupManifest(signer, manifest, signature); err != nil {
+		return nil, fmt.Errorf("ReadBackupArchive: %w", err)
+	}
+	digest := sha256.Sum256(encrypted)
+	if subtle.ConstantTimeCompare(digest[:], dto.PayloadSha256) != 1 {
+		return nil, errors.New("ReadBackupArchive: payload digest does not match the manifest")
+	}
+
+	organization, err := ParseBigInt(dto.Organization, 10)
+	if err != nil {
+		return nil, fmt.Errorf("ReadBackupArchive: organization: %w", err)
+	}
+	anchors, err := trustAnchors(organization)
+	if err != nil {
+		return nil, fmt.Errorf("ReadBackupArchive: trust anchors: %w", err)
+	}
+	if !isBackupTrustAnchor(anchors, signer) {
+		return nil, fmt.Errorf("ReadBackupArchive: signer is not trusted for organization %s", organization)
+	}
+
+	encryption := dto.Encryption
+	if encryption.Cipher != BackupCipher || encryption.Kdf != BackupKdf {
+		return nil, fmt.Errorf("ReadBackupArchive: unsupported encryption: %s with %s", encryption.Cipher, encryption.Kdf)
+	}
+	if encryption.N <= 0 || encryption.R <= 0 || encryption.P <= 0 ||
+		int64(encryption.N)*int64(encryption.R) > BackupScryptMaxMemory/128 ||
+		encryption.P > BackupScryptMaxP {
+		return nil, fmt.Errorf("ReadBackupArchive: unacceptable scrypt cost: N=%d r=%d p=%d", encryption.N, encryption.R, encryption.P)
+	}
+	aead, err := newBackupCipher(passphrase, encryption.Salt, encryption.N, encryption.R, encryption.P)
+	if err != nil {
+		return nil, fmt.Errorf("ReadBackupArchive: %w", err)
+	}
+	if len(encryption.Nonce) != aead.NonceSize() {
+		return nil, errors.New("ReadBackupArchive: invalid nonce")
+	}
+	payload, err := aead.Open(nil, encryption.Nonce, encrypted, nil)
+	if err != nil {
+		return nil, errors.New("ReadBackupArchive: wrong passphrase or corrupted payload")
+	}
+
+	backup, err := unmarshalBackupPayload(certManager, payload, dto.Version, dto.CreatedAt, organization)
+	if err != nil {
+		return nil, fmt.Errorf("ReadBackupArchive: %w", err)
+	}
+	if err := verifyBackupSigner(backup, signer); err != nil {
+		return nil, fmt.Errorf("ReadBackupArchive: %w", err)
+	}
+	return backup, nil
+}
+
+// ToOrganizationBackupDTO summarizes the content of a backup
+func ToOrganizationBackupDTO(b appmodels.OrganizationBackup) appdtos.OrganizationBackupDTO {
+	return appdtos.NewOrganizationBackupDTO(
+		b.Version(),
+		b.CreatedAt(),
+		ToOrganizationDTO(b.Organization()),
+		len(b.Certificates()),
+		len(b.PrivateKeys()),
+		len(b.Revocations()),
+	)
+}
+
+func ToCertificateRevocationDTO(r appmodels.CertificateRevocation) appdtos.CertificateRevocationDTO {
+	revoked := r.RevokedCertificate()
+	return appdtos.NewCertificateRevocationDTO(
+		r.IssuerSerialNumber().String(),
+		revoked.SerialNumber().String(),
+		revoked.RevocationTime(),
+		revoked.ExpirationTime(),
+	)
+}
+
+// FromCertificateRevocationDTO converts a revocation of an organization to
+// a model
+func FromCertificateRevocationDTO(organization *big.Int, dto appdtos.CertificateRevocationDTO) (appmodels.CertificateRevocation, error) {
+	issuer, err := ParseBigInt(dto.Issuer, 10)
+	if err != nil {
+		return nil, fmt.Errorf("issuer: %w", err)
+	}
+	serialNumber, err := ParseBigInt(dto.SerialNumber, 10)
+	if err != nil {
+		return nil, fmt.Errorf("serial number: %w", err)
+	}
+	return appmodels.NewCertificateRevocation(
+		organization,
+		issuer,
+		appmodels.NewRevokedCertificate(serialNumber, dto.RevocationTime, dto.ExpirationTime),
+	), nil
+}
+
+func ToRevocationListDTO(r appmodels.RevocationList) appdtos.RevocationListDTO {
+	return appdtos.NewRevocationListDTO(
+		r.IssuerSerialNumber().String(),
+		r.Number().String(),
+		r.ThisUpdate(),
+	)
+}
+
+// FromRevocationListDTO converts the last CRL of an issuer to a model
+func FromRevocationListDTO(organization *big.Int, dto appdtos.RevocationListDTO) (appmodels.RevocationList, error) {
+	issuer, err := ParseBigInt(dto.Issuer, 10)
+	if err != nil {
+		return nil, fmt.Errorf("issuer: %w", err)
+	}
+	number, err := ParseBigInt(dto.Number, 10)
+	if err != nil {
+		return nil, fmt.Errorf("number: %w", err)
+	}
+	return appmodels.NewRevocationList(organization, issuer, number, dto.ThisUpdate), nil
+}
+
+// marshalBackupPayload encodes the content of a backup as a tar archive
+func marshalBackupPayload(certManager managers.CertificateManager, backup appmodels.OrganizationBackup) ([]byte, error) {
+
+	organization, err := json.MarshalIndent(ToOrganizationDTO(backup.Organization()), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("organization: %w", err)
+	}
+
+	var buffer bytes.Buffer
+	writer := tar.NewWriter(&buffer)
+	modTime := backup.CreatedAt()
+	if err := writeTarFile(writer, BackupOrganizationFileName, organization, modTime); err != nil {
+		return nil, err
+	}
+
+	issuers := make(map[string]string)
+	for _, certificate := range backup.Certificates() {
+		serialNumber := certificate.SerialNumber().String()
+		issuers[serialNumber] = ""
+		if signedBy := certificate.SignedBy(); signedBy != nil {
+			issuers[serialNumber] = signedBy.String()
+		}
+		name := path.Join(BackupCertificatesDirName, serialNumber+".pem")
+		if err := writeTarFile(writer, name, CertificateToPEMBytes(certificate), modTime); err != nil {
+			return nil, err
+		}
+	}
+	issuerData, err := json.MarshalIndent(issuers, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("issuers: %w", err)
+	}
+	if err := writeTarFile(writer, BackupIssuersFileName, issuerData, modTime); err != nil {
+		return nil, err
+	}
+
+	for _, key := range backup.PrivateKeys() {
+		data, err := MarshalPrivateKeyAsPEM(certManager, key.PrivateKey())
+		if err != nil {
+			return nil, fmt.Errorf("private key %s: %w", key.SerialNumber(), err)
+		}
+		name := path.Join(BackupPrivateKeysDirName, key.SerialNumber().String()+".pem")
+		if err := writeTarFile(writer, name, data, modTime); err != nil {
+			return nil, err
+		}
+	}
+
+	revocations := make([]appdtos.CertificateRevocationDTO, len(backup.Revocations()))
+	for i, revocation := range backup.Revocations() {
+		revocations[i] = ToCertificateRevocationDTO(revocation)
+	}
+	revocationData, err := json.MarshalIndent(revocations, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("revocations: %w", err)
+	}
+	if err := writeTarFile(writer, BackupRevocationsFileName, revocationData, modTime); err != nil {
+		return nil, err
+	}
+
+	revocationLists := make([]appdtos.RevocationListDTO, len(backup.RevocationLists()))
+	for i, revocationList := range backup.RevocationLists() {
+		revocationLists[i] = ToRevocationListDTO(revocationList)
+	}
+	revocationListData, err := json.MarshalIndent(revocationLists, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("revocation lists: %w", err)
+	}
+	if err := writeTarFile(writer, BackupRevocationListsFileName, revocationListData, modTime); err != nil {
+		return nil, err
+	}
+
+	if err := marshalBackupProtocolState(writer, backup); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// marshalBackupProtocolState writes the state of the EST, SCEP, Vault and
+// ACME servers. The SCEP challenge file is only written if the organization
+// has a challenge password.
+func marshalBackupProtocolState(writer *tar.Writer, backup appmodels.OrganizationBackup) error {
+	modTime := backup.CreatedAt()
+
+	estLabels := make([]appdtos.EstLabelRecordDTO, len(backup.EstLabels()))
+	for i, label := range backup.EstLabels() {
+		estLabels[i] = ToEstLabelRecordDTO(label)
+	}
+	if err := writeBackupJsonFile(writer, BackupEstLabelsFileName, estLabels, modTime); err != nil {
+		return err
+	}
+
+	if challenge := backup.ScepChallenge(); challenge != nil {
+		if err := writeBackupJsonFile(writer, BackupScepChallengeFileName, ToScepChallengeRecordDTO(challenge), modTime); err != nil {
+			return err
+		}
+	}
+
+	vaultRoles := make([]appdtos.VaultRoleRecordDTO, len(backup.VaultRoles()))
+	for i, role := range backup.VaultRoles() {
+		vaultRoles[i] = ToVaultRoleRecordDTO(role)
+	}
+	if err := writeBackupJsonFile(writer, BackupVaultRolesFileName, vaultRoles, modTime); err != nil {
+		return err
+	}
+
+	acmeAccounts := make([]appdtos.AcmeAccountRecordDTO, len(backup.AcmeAccounts()))
+	for i, account := range backup.AcmeAccounts() {
+		dto, err := ToAcmeAccountRecordDTO(account)
+		if err != nil {
+			return fmt.Errorf("ACME account %s: %w", account.ID(), err)
+		}
+		acmeAccounts[i] = dto
+	}
+	if err := writeBackupJsonFile(writer, BackupAcmeAccountsFileName, acmeAccounts, modTime); err != nil {
+		return err
+	}
+
+	acmeOrders := make([]appdtos.AcmeOrderRecordDTO, len(backup.AcmeOrders()))
+	for i, order := range backup.AcmeOrders() {
+		acmeOrders[i] = ToAcmeOrderRecordDTO(order)
+	}
+	if err := writeBackupJsonFile(writer, BackupAcmeOrdersFileName, acmeOrders, modTime); err != nil {
+		return err
+	}
+
+	acmeAuthorizations := make([]appdtos.AcmeAuthorizationRecordDTO, len(backup.AcmeAuthorizations()))
+	for i, authorization := range backup.AcmeAuthorizations() {
+		acmeAuthorizations[i] = ToAcmeAuthorizationRecordDTO(authorization)
+	}
+	return writeBackupJsonFile(writer, BackupAcmeAuthorizationsFileName, acmeAuthorizations, modTime)
+}
+
+// unmarshalBackupPayload parses the decrypted payload of a backup archive
+func unmarshalBackupPayload(
+	certManager managers.CertificateManager,
+	payload []byte,
+	version int,
+	createdAt time.Time,
+	organizationID *big.Int,
+) (appmodels.OrganizationBackup, error) {
+
+	files, err := readTarFiles(bytes.NewReader(payload), func(name string) bool {
+		return name == BackupOrganizationFileName ||
+			name == BackupIssuersFileName ||
+			name == BackupRevocationsFileName ||
+			name == BackupRevocationListsFileName ||
+			name == BackupEstLabelsFileName ||
+			name == BackupScepChallengeFileName ||
+			name == BackupVaultRolesFileName ||
+			name == BackupAcmeAccountsFileName ||
+			name == BackupAcmeOrdersFileName ||
+			name == BackupAcmeAuthorizationsFileName ||
+			isBackupPemFile(name, BackupCertificatesDirName) ||
+			isBackupPemFile(name, BackupPrivateKeysDirName)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("payload: %w", err)
+	}
+
+	organization, err := unmarshalBackupOrganization(files[BackupOrganizationFileName])
+	if err != nil {
+		return nil, err
+	}
+	if organization.ID().Cmp(organizationID) != 0 {
+		return nil, fmt.Errorf("organization %s does not match the manifest: %s", organization.ID(), organizationID)
+	}
+
+	var issuers map[string]string
+	if err := json.Unmarshal(files[BackupIssuersFileName], &issuers); err != nil {
+		return nil, fmt.Errorf("issuers: %w", err)
+	}
+
+	var certificates []appmodels.Certificate
+	publicKeys := make(map[string]crypto.PublicKey)
+	var privateKeys []appmodels.PrivateKey
+	for name, data := range files {
+		if isBackupPemFile(name, BackupCertificatesDirName) {
+			cert, err := parseCertificatePEM(certManager, data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			serialNumber := cert.SerialNumber.String()
+			if name != path.Join(BackupCertificatesDirName, serialNumber+".pem") {
+				return nil, fmt.Errorf("%s: serial number does not match: %s", name, serialNumber)
+			}
+			issuer, exists := issuers[serialNumber]
+			if !exists {
+				return nil, fmt.Errorf("%s: issuer not found", name)
+			}
+			var signedBy *big.Int
+			if issuer != "" {
+				if signedBy, err = ParseBigInt(issuer, 10); err != nil {
+					return nil, fmt.Errorf("%s: issuer: %w", name, err)
+				}
+			}
+			certificates = append(certificates, appmodels.NewCertificate(organizationID, signedBy, cert))
+			publicKeys[serialNumber] = cert.PublicKey
+		}
+	}
+	for name, data := range files {
+		if isBackupPemFile(name, BackupPrivateKeysDirName) {
+			serialNumber, err := ParseBigInt(strings.TrimSuffix(path.Base(name), ".pem"), 10)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			privateKey, keyType, err := ParsePrivateKeyFromPEMBytes(certManager, data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			publicKey, exists := publicKeys[serialNumber.String()]
+			if !exists {
+				return nil, fmt.Errorf("%s: certificate not found", name)
+			}
+			if !publicKeyMatches(publicKey, privateKey) {
+				return nil, fmt.Errorf("%s: private key does not match the certificate", name)
+			}
+			privateKeys = append(privateKeys, appmodels.NewPrivateKey(organizationID, serialNumber, keyType, privateKey))
+		}
+	}
+	sortCertificates(certificates)
+	sortPrivateKeys(privateKeys)
+
+	var revocationDTOs []appdtos.CertificateRevocationDTO
+	if err := json.Unmarshal(files[BackupRevocationsFileName], &revocationDTOs); err != nil {
+		return nil, fmt.Errorf("revocations: %w", err)
+	}
+	revocations := make([]appmodels.CertificateRevocation, len(revocationDTOs))
+	for i, dto := range revocationDTOs {
+		revocations[i], err = FromCertificateRevocationDTO(organizationID, dto)
+		if err != nil {
+			return nil, fmt.Errorf("revocations: %w", err)
+		}
+	}
+
+	// Archives of version 1 have no revocation lists
+	var revocationListDTOs []appdtos.RevocationListDTO
+	if data, exists := files[BackupRevocationListsFileName]; exists {
+		if err := json.Unmarshal(data, &revocationListDTOs); err != nil {
+			return nil, fmt.Errorf("revocation lists: %w", err)
+		}
+	}
+	revocationLists := make([]appmodels.RevocationList, len(revocationListDTOs))
+	for i, dto := range revocationListDTOs {
+		revocationLists[i], err = FromRevocationListDTO(organizationID, dto)
+		if err != nil {
+			return nil, fmt.Errorf("revocation lists: %w", err)
+		}
+	}
+
+	// Archives before version 3 have no protocol state
+	estLabels, scepChallenge, vaultRoles, err := unmarshalBackupProtocolState(files, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	acmeAccounts, acmeOrders, acmeAuthorizations, err := unmarshalBackupAcmeState(files, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return appmodels.NewOrganizationBackup(
+		version,
+		createdAt,
+		organization,
+		certificates,
+		privateKeys,
+		revocations,
+		revocationLists,
+		estLabels,
+		scepChallenge,
+		vaultRoles,
+		acmeAccounts,
+		acmeOrders,
+		acmeAuthorizations,
+	), nil
+}
+
+// unmarshalBackupProtocolState parses the EST labels, the SCEP challenge
+// password and the Vault roles. Each of them must belong to the
+// organization.
+func unmarshalBackupProtocolState(files map[string][]byte, organizationID *big.Int) ([]appmodels.EstLabel, appmodels.ScepChallenge, []appmodels.VaultRole, error) {
+
+	var estLabelDTOs []appdtos.EstLabelRecordDTO
+	if err := unmarshalBackupJsonFile(files, BackupEstLabelsFileName, &estLabelDTOs); err != nil {
+		return nil, nil, nil, err
+	}
+	estLabels := make([]appmodels.EstLabel, len(estLabelDTOs))
+	for i, dto := range estLabelDTOs {
+		label, err := FromEstLabelRecordDTO(dto)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("EST labels: %w", err)
+		}
+		if label.OrganizationID().Cmp(organizationID) != 0 {
+			return nil, nil, nil, fmt.Errorf("EST labels: %s belongs to organization %s", label.Label(), label.OrganizationID())
+		}
+		estLabels[i] = label
+	}
+
+	var scepChallenge appmodels.ScepChallenge
+	if _, exists := files[BackupScepChallengeFileName]; exists {
+		var dto appdtos.ScepChallengeRecordDTO
+		if err := unmarshalBackupJsonFile(files, BackupScepChallengeFileName, &dto); err != nil {
+			return nil, nil, nil, err
+		}
+		challenge, err := FromScepChallengeRecordDTO(dto)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("SCEP challenge: %w", err)
+		}
+		if challenge.OrganizationID().Cmp(organizationID) != 0 {
+			return nil, nil, nil, fmt.Errorf("SCEP challenge belongs to organization %s", challenge.OrganizationID())
+		}
+		scepChallenge = challenge
+	}
+
+	var vaultRoleDTOs []appdtos.VaultRoleRecordDTO
+	if err := unmarshalBackupJsonFile(files, BackupVaultRolesFileName, &vaultRoleDTOs); err != nil {
+		return nil, nil, nil, err
+	}
+	vaultRoles := make([]appmodels.VaultRole, len(vaultRoleDTOs))
+	for i, dto := range vaultRoleDTOs {
+		role, err := FromVaultRoleRecordDTO(dto)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Vault roles: %w", err)
+		}
+		if role.OrganizationID().Cmp(organizationID) != 0 {
+			return nil, nil, nil, fmt.Errorf("Vault roles: %s belongs to organization %s", role.Name(), role.OrganizationID())
+		}
+		vaultRoles[i] = role
+	}
+	return estLabels, scepChallenge, vaultRoles, nil
+}
+
+// unmarshalBackupAcmeState parses the ACME accounts, orders and
+// authorizations. The accounts must belong to the organization, the orders
+// to the accounts and the authorizations to the orders.
+func unmarshalBackupAcmeState(files map[string][]byte, organizationID *big.Int) ([]appmodels.AcmeAccount, []appmodels.AcmeOrder, []appmodels.AcmeAuthorization, error) {
+
+	var accountDTOs []appdtos.AcmeAccountRecordDTO
+	if err := unmarshalBackupJsonFile(files, BackupAcmeAccountsFileName, &accountDTOs); err != nil {
+		return nil, nil, nil, err
+	}
+	accountIDs := make(map[string]bool)
+	accounts := make([]appmodels.AcmeAccount, len(accountDTOs))
+	for i, dto := range accountDTOs {
+		account, err := FromAcmeAccountRecordDTO(dto)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("ACME accounts: %w", err)
+		}
+		if account.OrganizationID().Cmp(organizationID) != 0 {
+			return nil, nil, nil, fmt.Errorf("ACME accounts: %s belongs to organization %s", account.ID(), account.OrganizationID())
+		}
+		accountIDs[account.ID()] = true
+		accounts[i] = account
+	}
+
+	var orderDTOs []appdtos.AcmeOrderRecordDTO
+	if err := unmarshalBackupJsonFile(files, BackupAcmeOrdersFileName, &orderDTOs); err != nil {
+		return nil, nil, nil, err
+	}
+	orderAccounts := make(map[string]string)
+	orders := make([]appmodels.AcmeOrder, len(orderDTOs))
+	for i, dto := range orderDTOs {
+		order, err := FromAcmeOrderRecordDTO(dto)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("ACME orders: %w", err)
+		}
+		if !accountIDs[order.AccountID()] {
+			return nil, nil, nil, fmt.Errorf("ACME orders: %s: account not found: %s", order.ID(), order.AccountID())
+		}
+		orderAccounts[order.ID()] = order.AccountID()
+		orders[i] = order
+	}
+
+	var authorizationDTOs []appdtos.AcmeAuthorizationRecordDTO
+	if err := unmarshalBackupJsonFile(files, BackupAcmeAuthorizationsFileName, &authorizationDTOs); err != nil {
+		return nil, nil, nil, err
+	}
+	authorizations := make([]appmodels.AcmeAuthorization, len(authorizationDTOs))
+	for i, dto := range authorizationDTOs {
+		authorization, err := FromAcmeAuthorizationRecordDTO(dto)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("ACME authorizations: %w", err)
+		}
+		account, exists := orderAccounts[authorization.OrderID()]
+		if !exists || account != authorization.AccountID() {
+			return nil, nil, nil, fmt.Errorf("ACME authorizations: %s: order not found: %s", authorization.ID(), authorization.OrderID())
+		}
+		authorizations[i] = authorization
+	}
+	return accounts, orders, authorizations, nil
+}
+
+func unmarshalBackupOrganization(data []byte) (appmodels.Organization, error) {
+	var dto appdtos.OrganizationDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("organization: %w", err)
+	}
+	id, err := ParseBigInt(dto.ID, 10)
+	if err != nil {
+		return nil, fmt.Errorf("organization: id: %w", err)
+	}
+	signatureAlgorithm, err := ParseSignatureAlgorithm(dto.SignatureAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("organization: %w", err)
+	}
+	keyRetentionPolicy, err := ParseKeyRetentionPolicy(dto.KeyRetentionPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("organization: %w", err)
+	}
+	return appmodels.NewOrganization(id, dto.Slug, dto.AllNames, signatureAlgorithm, dto.SpiffeTrustDomain, keyRetentionPolicy), nil
+}
+
+// verifyBackupSigner checks that the signer is a root certificate of the
+// backup
+func verifyBackupSigner(backup appmodels.OrganizationBackup, signer *x509.Certificate) error {
+	for _, certificate := range backup.Certificates() {
+		if certificate.IsRootCertificate() && bytes.Equal(certificate.Certificate().Raw, signer.Raw) {
+			return nil
+		}
+	}
+	return errors.New("signer is not a root certificate of the organization")
+}
+
+// isBackupTrustAnchor returns true if the signer is one of the trust anchors
+func isBackupTrustAnchor(anchors []*x509.Certificate, signer *x509.Certificate) bool {
+	for _, anchor := range anchors {
+		if anchor != nil && bytes.Equal(anchor.Raw, signer.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// newBackupCipher derives the payload encryption key from the passphrase
+func newBackupCipher(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, 32)
+	if err != nil {
+		return nil, fmt.Errorf("key derivation: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// signBackupManifest signs the manifest with SHA-256, or with Ed25519 which
+// signs the message itself
+func signBackupManifest(privateKey appmodels.PrivateKey, manifest []byte) ([]byte, error) {
+	signer, ok := privateKey.PrivateKey().(crypto.Signer)
+	if !ok {
+		return nil, errors.New("signer: not a signing key")
+	}
+	if _, ok := signer.(ed25519.PrivateKey); ok {
+		return signer.Sign(rand.Reader, manifest, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(manifest)
+	return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// verifyBackupManifest verifies the signature made by signBackupManifest
+func verifyBackupManifest(signer *x509.Certificate, manifest, signature []byte) error {
+	var algorithm x509.SignatureAlgorithm
+	switch signer.PublicKey.(type) {
+	case *rsa.PublicKey:
+		algorithm = x509.SHA256WithRSA
+	case *ecdsa.PublicKey:
+		algorithm = x509.ECDSAWithSHA256
+	case ed25519.PublicKey:
+		algorithm = x509.PureEd25519
+	default:
+		return errors.New("signer: unsupported public key")
+	}
+	if err := signer.CheckSignature(algorithm, manifest, signature); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	return nil
+}
+
+// publicKeyMatches returns true if the private key belongs to the public key
+func publicKeyMatches(publicKey crypto.PublicKey, privateKey any) bool {
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return false
+	}
+	key, ok := publicKey.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && key.Equal(signer.Public())
+}
+
+func parseCertificatePEM(certManager managers.CertificateManager, data []byte) (*x509.Certificate, error) {
+	block, _ := certManager.DecodePEM(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("failed to decode PEM block containing the certificate")
+	}
+	return certManager.ParseCertificate(block.Bytes)
+}
+
+func isBackupPemFile(name, dir string) bool {
+	return path.Dir(name) == dir && strings.HasSuffix(name, ".pem")
+}
+
+func writeBackupJsonFile(writer *tar.Writer, name string, value any, modTime time.Time) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return writeTarFile(writer, name, data, modTime)
+}
+
+// unmarshalBackupJsonFile parses a JSON file of the payload. A missing file
+// leaves the value unchanged.
+func unmarshalBackupJsonFile(files map[string][]byte, name string, value any) error {
+	data, exists := files[name]
+	if !exists {
+		return nil
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func writeTarFile(writer *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := writer.WriteHeader(header); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// readTarFiles reads the regular files of a tar archive. Files which are not
+// accepted, or appear twice, are an error.
+func readTarFiles(reader io.Reader, accept func(name string) bool) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || !accept(header.Name) {
+			return nil, fmt.Errorf("unexpected file: %s", header.Name)
+		}
+		if _, exists := files[header.Name]; exists {
+			return nil, fmt.Errorf("duplicate file: %s", header.Name)
+		}
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", header.Name, err)
+		}
+		files[header.Name] = data
+	}
+}
+
+func sortCertificates(list []appmodels.Certificate) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].SerialNumber().Cmp(list[j].SerialNumber()) < 0
+	})
+}
+
+func sortPrivateKeys(list []appmodels.PrivateKey) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].SerialNumber().Cmp(list[j].SerialNumber()) < 0
+	})
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// newTestBackup creates a backup of an organization with a root certificate,
+// a server certificate, a revocation, the last CRL of the root and the state
+// of the EST, SCEP, Vault and ACME servers
+func newTestBackup(t *testing.T, keyType appmodels.KeyType) (appmodels.OrganizationBackup, appmodels.Certificate, appmodels.PrivateKey) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	organizationID := big.NewInt(10)
+	organization := appmodels.NewOrganization(organizationID, "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.KEY_RETENTION_ESCROW)
+
+	rootKey, err := apputils.GeneratePrivateKey(organizationID, big.NewInt(1), keyType)
+	require.NoError(t, err)
+	root, err := apputils.NewRootCertificate(certManager, big.NewInt(1), organization, time.Hour, appmodels.NIL_SIGNATURE_ALGORITHM, rootKey, "Test Root")
+	require.NoError(t, err)
+
+	serverKey, err := apputils.GeneratePrivateKey(organizationID, big.NewInt(2), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	server, err := apputils.NewServerCertificate(certManager, big.NewInt(2), organization, time.Hour, appmodels.NIL_SIGNATURE_ALGORITHM, serverKey, root, rootKey, "www.example.com", "www.example.com")
+	require.NoError(t, err)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	revocation := appmodels.NewCertificateRevocation(organizationID, big.NewInt(1), appmodels.NewRevokedCertificate(big.NewInt(2), createdAt, server.NotAfter().UTC()))
+
+	backup := appmodels.NewOrganizationBackup(
+		apputils.BackupArchiveVersion,
+		createdAt,
+		organization,
+		[]appmodels.Certificate{root, server},
+		[]appmodels.PrivateKey{rootKey, serverKey},
+		[]appmodels.CertificateRevocation{revocation},
+		[]appmodels.RevocationList{appmodels.NewRevocationList(organizationID, big.NewInt(1), big.NewInt(42), createdAt)},
+		[]appmodels.EstLabel{appmodels.NewEstLabel("devices", organizationID, big.NewInt(1), "alice", []byte("hash"))},
+		appmodels.NewScepChallenge(organizationID, []byte("hash")),
+		[]appmodels.VaultRole{appmodels.NewVaultRole("web", organizationID, big.NewInt(1), []string{"example.com"}, true, false, false, true, appmodels.ECDSA_P256, time.Hour, 24*time.Hour)},
+		[]appmodels.AcmeAccount{appmodels.NewAcmeAccount("account", organizationID, big.NewInt(1), appmodels.ACME_STATUS_VALID, []string{"mailto:alice@example.com"}, serverKey.PublicKey(), "thumbprint", createdAt)},
+		[]appmodels.AcmeOrder{appmodels.NewAcmeOrder("order", "account", appmodels.ACME_STATUS_VALID, createdAt, []string{"www.example.com"}, []string{"authorization"}, big.NewInt(2), nil)},
+		[]appmodels.AcmeAuthorization{appmodels.NewAcmeAuthorization("authorization", "account", "order", appmodels.ACME_STATUS_VALID, createdAt, "www.example.com", false, nil)},
+	)
+	return backup, root, rootKey
+}
+
+// trustBackupSigner returns trust anchors which trust only the root
+func trustBackupSigner(root appmodels.Certificate) func(*big.Int) ([]*x509.Certificate, error) {
+	return func(*big.Int) ([]*x509.Certificate, error) {
+		return []*x509.Certificate{root.Certificate()}, nil
+	}
+}
+
+func TestBackupArchive_RoundTrip(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	for _, keyType := range []appmodels.KeyType{appmodels.ECDSA_P256, appmodels.RSA_2048, appmodels.Ed25519} {
+		t.Run(keyType.String(), func(t *testing.T) {
+			backup, root, rootKey := newTestBackup(t, keyType)
+
+			archive, err := apputils.WriteBackupArchive(certManager, backup, root, rootKey, "secret")
+			require.NoError(t, err)
+
+			restored, err := apputils.ReadBackupArchive(certManager, archive, "secret", trustBackupSigner(root))
+			require.NoError(t, err)
+
+			assert.Equal(t, apputils.BackupArchiveVersion, restored.Version())
+			assert.True(t, backup.CreatedAt().Equal(restored.CreatedAt()))
+			assert.Equal(t, apputils.ToOrganizationDTO(backup.Organization()), apputils.ToOrganizationDTO(restored.Organization()))
+			require.Len(t, restored.Certificates(), 2)
+			for i, certificate := range restored.Certificates() {
+				assert.Equal(t, backup.Certificates()[i].Certificate().Raw, certificate.Certificate().Raw)
+				assert.Equal(t, backup.Certificates()[i].SignedBy(), certificate.SignedBy())
+			}
+			require.Len(t, restored.PrivateKeys(), 2)
+			for i, key := range restored.PrivateKeys() {
+				assert.Equal(t, backup.PrivateKeys()[i].SerialNumber(), key.SerialNumber())
+				assert.Equal(t, backup.PrivateKeys()[i].KeyType(), key.KeyType())
+			}
+			require.Len(t, restored.Revocations(), 1)
+			assert.Equal(t, apputils.ToCertificateRevocationDTO(backup.Revocations()[0]), apputils.ToCertificateRevocationDTO(restored.Revocations()[0]))
+			require.Len(t, restored.RevocationLists(), 1)
+			assert.Equal(t, apputils.ToRevocationListDTO(backup.RevocationLists()[0]), apputils.ToRevocationListDTO(restored.RevocationLists()[0]))
+			require.Len(t, restored.EstLabels(), 1)
+			assert.Equal(t, apputils.ToEstLabelRecordDTO(backup.EstLabels()[0]), apputils.ToEstLabelRecordDTO(restored.EstLabels()[0]))
+			require.NotNil(t, restored.ScepChallenge())
+			assert.Equal(t, apputils.ToScepChallengeRecordDTO(backup.ScepChallenge()), apputils.ToScepChallengeRecordDTO(restored.ScepChallenge()))
+			require.Len(t, restored.VaultRoles(), 1)
+			assert.Equal(t, apputils.ToVaultRoleRecordDTO(backup.VaultRoles()[0]), apputils.ToVaultRoleRecordDTO(restored.VaultRoles()[0]))
+			require.Len(t, restored.AcmeAccounts(), 1)
+			assert.Equal(t, "thumbprint", restored.AcmeAccounts()[0].Thumbprint())
+			require.Len(t, restored.AcmeOrders(), 1)
+			assert.Equal(t, apputils.ToAcmeOrderRecordDTO(backup.AcmeOrders()[0]), apputils.ToAcmeOrderRecordDTO(restored.AcmeOrders()[0]))
+			require.Len(t, restored.AcmeAuthorizations(), 1)
+			assert.Equal(t, apputils.ToAcmeAuthorizationRecordDTO(backup.AcmeAuthorizations()[0]), apputils.ToAcmeAuthorizationRecordDTO(restored.AcmeAuthorizations()[0]))
+		})
+	}
+}
+
+func TestBackupArchive_NoProtocolState(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	full, root, rootKey := newTestBackup(t, appmodels.ECDSA_P256)
+	backup := appmodels.NewOrganizationBackup(2, full.CreatedAt(), full.Organization(), full.Certificates(), full.PrivateKeys(), full.Revocations(), full.RevocationLists(), nil, nil, nil, nil, nil, nil)
+
+	archive, err := apputils.WriteBackupArchive(certManager, backup, root, rootKey, "secret")
+	require.NoError(t, err)
+	restored, err := apputils.ReadBackupArchive(certManager, archive, "secret", trustBackupSigner(root))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, restored.Version())
+	assert.Empty(t, restored.EstLabels())
+	assert.Nil(t, restored.ScepChallenge())
+	assert.Empty(t, restored.VaultRoles())
+	assert.Empty(t, restored.AcmeAccounts())
+	assert.Empty(t, restored.AcmeOrders())
+	assert.Empty(t, restored.AcmeAuthorizations())
+}
+
+func TestReadBackupArchive_WrongPassphrase(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	backup, root, rootKey := newTestBackup(t, appmodels.ECDSA_P256)
+	archive, err := apputils.WriteBackupArchive(certManager, backup, root, rootKey, "secret")
+	require.NoError(t, err)
+
+	_, err = apputils.ReadBackupArchive(certManager, archive, "wrong", trustBackupSigner(root))
+	assert.ErrorContains(t, err, "wrong passphrase")
+}
+
+func TestWriteBackupArchive_NoPassphrase(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	backup, root, rootKey := newTestBackup(t, appmodels.ECDSA_P256)
+	_, err := apputils.WriteBackupArchive(certManager, backup, root, rootKey, "")
+	assert.ErrorContains(t, err, "passphrase must be defined")
+}
+
+// rewriteBackupArchive returns a copy of the archive with a file modified
+func rewriteBackupArchive(t *testing.T, archive []byte, name string, modify func([]byte) []byte) []byte {
+	var buffer bytes.Buffer
+	writer := tar.NewWriter(&buffer)
+	reader := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		if header.Name == name {
+			data = modify(data)
+		}
+		header.Size = int64(len(data))
+		require.NoError(t, writer.WriteHeader(header))
+		_, err = writer.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func TestReadBackupArchive_Tampered(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	backup, root, rootKey := newTestBackup(t, appmodels.ECDSA_P256)
+	archive, err := apputils.WriteBackupArchive(certManager, backup, root, rootKey, "secret")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		file     string
+		modify   func([]byte) []byte
+		expected string
+	}{
+		{"manifest", apputils.BackupManifestFileName, func(data []byte) []byte {
+			return bytes.Replace(data, []byte(`"organization": "10"`), []byte(`"organization": "11"`), 1)
+		}, "invalid signature"},
+		{"version", apputils.BackupManifestFileName, func(data []byte) []byte {
+			return bytes.Replace(data, []byte(`"version": 3`), []byte(`"version": 4`), 1)
+		}, "unsupported version 4"},
+		{"signature", apputils.BackupSignatureFileName, func(data []byte) []byte {
+			data[len(data)-1] ^= 1
+			return data
+		}, "invalid signature"},
+		{"payload", apputils.BackupPayloadFileName, func(data []byte) []byte {
+			data[0] ^= 1
+			return data
+		}, "payload digest does not match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := rewriteBackupArchive(t, archive, tt.file, func(data []byte) []byte {
+				return tt.modify(append([]byte(nil), data...))
+			})
+			_, err := apputils.ReadBackupArchive(certManager, modified, "secret", trustBackupSigner(root))
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestReadBackupArchive_SignerNotInBackup(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	backup, _, _ := newTestBackup(t, appmodels.ECDSA_P256)
+	_, otherRoot, otherKey := newTestBackup(t, appmodels.ECDSA_P256)
+
+	archive, err := apputils.WriteBackupArchive(certManager, backup, otherRoot, otherKey, "secret")
+	require.NoError(t, err)
+
+	_, err = apputils.ReadBackupArchive(certManager, archive, "secret", trustBackupSigner(otherRoot))
+	assert.ErrorContains(t, err, "signer is not a root certificate of the organization")
+}
+
+func TestReadBackupArchive_UntrustedSigner(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	backup, root, rootKey := newTestBackup(t, appmodels.ECDSA_P256)
+	_, otherRoot, _ := newTestBackup(t, appmodels.ECDSA_P256)
+
+	archive, err := apputils.WriteBackupArchive(certManager, backup, root, rootKey, "secret")
+	require.NoError(t, err)
+
+	_, err = apputils.ReadBackupArchive(certManager, archive, "secret", trustBackupSigner(otherRoot))
+	assert.ErrorContains(t, err, "signer is not trusted for organization 10")
+
+	_, err = apputils.ReadBackupArchive(certManager, archive, "secret", func(*big.Int) ([]*x509.Certificate, error) {
+		return nil, errors.New("storage failed")
+	})
+	assert.ErrorContains(t, err, "storage failed")
+}
+
+func TestReadBackupArchive_NotAnArchive(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	_, err := apputils.ReadBackupArchive(certManager, []byte("not a tar archive"), "secret", nil)
+	assert.Error(t, err)
+
+	var buffer bytes.Buffer
+	require.NoError(t, tar.NewWriter(&buffer).Close())
+	_, err = apputils.ReadBackupArchive(certManager, buffer.Bytes(), "secret", nil)
+	assert.ErrorContains(t, err, "not a backup archive")
+}
+
+func TestToOrganizationBackupDTO(t *testing.T) {
+	backup, _, _ := newTestBackup(t, appmodels.ECDSA_P256)
+	dto := apputils.ToOrganizationBackupDTO(backup)
+	assert.Equal(t, apputils.BackupArchiveVersion, dto.Version)
+	assert.Equal(t, backup.CreatedAt(), dto.CreatedAt)
+	assert.Equal(t, "10", dto.Organization.ID)
+	assert.Equal(t, 2, dto.Certificates)
+	assert.Equal(t, 2, dto.PrivateKeys)
+	assert.Equal(t, 1, dto.Revocations)
+}
+
+func TestBackupErrorStatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusBadRequest, apputils.BackupErrorStatusCode(appmodels.BACKUP_ERROR_BAD_REQUEST))
+	assert.Equal(t, http.StatusForbidden, apputils.BackupErrorStatusCode(appmodels.BACKUP_ERROR_PERMISSION_DENIED))
+	assert.Equal(t, http.StatusNotFound, apputils.BackupErrorStatusCode(appmodels.BACKUP_ERROR_NOT_FOUND))
+	assert.Equal(t, http.StatusConflict, apputils.BackupErrorStatusCode(appmodels.BACKUP_ERROR_CONFLICT))
+	assert.Equal(t, http.StatusServiceUnavailable, apputils.BackupErrorStatusCode(appmodels.BACKUP_ERROR_SEALED))
+	assert.Equal(t, http.StatusInternalServerError, apputils.BackupErrorStatusCode(appmodels.BACKUP_ERROR_SERVER_INTERNAL))
+}
@@ -4,8 +4,10 @@ package apputils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
 	"encoding/pem"
 	"fmt"
 	"math/big"
@@ -67,6 +69,9 @@ func ToCertificateDTO(c appmodels.Certificate) appdtos.CertificateDTO {
 		c.IsIntermediateCertificate(),
 		c.IsServerCertificate(),
 		c.IsClientCertificate(),
+		SignatureAlgorithmToString(c.SignatureAlgorithm()),
+		c.SpiffeID(),
+		"",
 		string(CertificateToPEMBytes(c)),
 	)
 }
@@ -154,6 +159,26 @@ func FilterRootCertificates(list []appmodels.Certificate) []appmodels.Certificat
 	return result
 }
 
+// ShouldRenewCertificate checks if a certificate is past its half-life and
+// should be renewed
+func ShouldRenewCertificate(certificate appmodels.Certificate, now time.Time) bool {
+	lifetime := certificate.NotAfter().Sub(certificate.NotBefore())
+	return !now.Before(certificate.NotBefore().Add(lifetime / 2))
+}
+
+// FilterValidRootCertificates returns the root certificates which are valid
+// at the given time
+func FilterValidRootCertificates(list []appmodels.Certificate, now time.Time) []appmodels.Certificate {
+	var result []appmodels.Certificate
+	for _, v := range FilterRootCertificates(list) {
+		if now.Before(v.NotBefore()) || now.After(v.NotAfter()) {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
 func FilterClientCertificates(list []appmodels.Certificate) []appmodels.Certificate {
 	result := make([]appmodels.Certificate, 0)
 	for _, v := range list {
@@ -189,6 +214,13 @@ func ToCertificateListDTO(list []appmodels.Certificate) appdtos.CertificateListD
 	return appdtos.NewCertificateListDTO(payload)
 }
 
+// CertificateFingerprint returns the hex encoded SHA-256 digest of the DER
+// bytes of a certificate
+func CertificateFingerprint(c appmodels.Certificate) string {
+	digest := sha256.Sum256(c.Certificate().Raw)
+	return hex.EncodeToString(digest[:])
+}
+
 func CertificateToPEMBytes(c appmodels.Certificate) []byte {
 	// Convert the certificate to a PEM block
 	pemBlock := &pem.Block{
@@ -205,6 +237,7 @@ func CertificateToPEMBytes(c appmodels.Certificate) []byte {
 //   - serialNumber *big.Int is the serial number for the new certificate
 //   - organization appmodels.Organization is the organization for the new certificate
 //   - expiration time.Duration is the expiration duration of the new certificate
+//   - signatureAlgorithm appmodels.SignatureAlgorithm is the algorithm used to sign the new certificate
 //   - publicKey appmodels.PublicKey is public key of the new certificate
 //   - parentCertificate appmodels.Certificate is the certificate of the part who signs this certificate
 //   - parentPrivateKey appmodels.PrivateKey is the private key of the part who signs this certificate
@@ -216,6 +249,7 @@ func NewIntermediateCertificate(
 	serialNumber *big.Int,
 	organization appmodels.Organization,
 	expiration time.Duration,
+	signatureAlgorithm appmodels.SignatureAlgorithm,
 	publicKey appmodels.PublicKey,
 	parentCertificate appmodels.Certificate,
 	parentPrivateKey appmodels.PrivateKey,
@@ -242,6 +276,10 @@ func NewIntermediateCertificate(
 		return nil, fmt.Errorf("NewIntermediateCertificate: parentPrivateKey: must be defined")
 	}
 
+	if err := ValidateSignatureAlgorithm(signatureAlgorithm, parentPrivateKey); err != nil {
+		return nil, fmt.Errorf("NewIntermediateCertificate: signatureAlgorithm: %w", err)
+	}
+
 	if err := ValidateRootCertificateCommonName(commonName); err != nil {
 		return nil, fmt.Errorf("NewIntermediateCertificate: commonName: %s: %s", err, commonName)
 	}
@@ -254,9 +292,10 @@ func NewIntermediateCertificate(
 		},
 		NotBefore:             time.Now(),
 		NotAfter:              time.Now().Add(expiration),
-		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
 		ExtKeyUsage:           []x509.ExtKeyUsage{},
 		BasicConstraintsValid: true,
+		SignatureAlgorithm:    signatureAlgorithm.X509(),
 		IsCA:                  true,
 
 		// Restrict this intermediate CA from issuing further intermediate CAs
@@ -289,6 +328,7 @@ func NewIntermediateCertificate(
 //   - serialNumber: Serial number for the new certificate
 //   - organization: The organization for the new certificate
 //   - expiration: The expiration duration
+//   - signatureAlgorithm: The algorithm used to sign the new certificate
 //   - publicKey appmodels.PublicKey is public key of the new certificate
 //   - parentCertificate: The certificate to use for signing
 //   - parentPrivateKey: The private key to use for signing
@@ -301,6 +341,7 @@ func NewServerCertificate(
 	serialNumber *big.Int,
 	organization appmodels.Organization,
 	expiration time.Duration,
+	signatureAlgorithm appmodels.SignatureAlgorithm,
 	publicKey appmodels.PublicKey,
 	parentCertificate appmodels.Certificate,
 	parentPrivateKey appmodels.PrivateKey,
@@ -328,6 +369,10 @@ func NewServerCertificate(
 		return nil, fmt.Errorf("NewServerCertificate: parentPrivateKey: must be defined")
 	}
 
+	if err := ValidateSignatureAlgorithm(signatureAlgorithm, parentPrivateKey); err != nil {
+		return nil, fmt.Errorf("NewServerCertificate: signatureAlgorithm: %w", err)
+	}
+
 	if err := ValidateServerCertificateCommonName(commonName); err != nil {
 		return nil, fmt.Errorf("NewServerCertificate: commonName: %s: %s", err, commonName)
 	}
@@ -351,6 +396,7 @@ func NewServerCertificate(
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		BasicConstraintsValid: true,
+		SignatureAlgorithm:    signatureAlgorithm.X509(),
 		DNSNames:              dnsNames,
 	}
 
@@ -378,6 +424,7 @@ func NewServerCertificate(
 //   - serialNumber: Serial number for the new certificate
 //   - organization: The organization for the new certificate
 //   - expiration: The expiration duration
+//   - signatureAlgorithm: The algorithm used to sign the new certificate
 //   - publicKey appmodels.PublicKey is public key of the new certificate
 //   - parentCertificate: The certificate to use for signing
 //   - parentPrivateKey: The private key to use for signing
@@ -390,6 +437,7 @@ func NewClientCertificate(
 	serialNumber *big.Int,
 	organization appmodels.Organization,
 	expiration time.Duration,
+	signatureAlgorithm appmodels.SignatureAlgorithm,
 	publicKey appmodels.PublicKey,
 	parentCertificate appmodels.Certificate,
 	parentPrivateKey appmodels.PrivateKey,
@@ -416,6 +464,10 @@ func NewClientCertificate(
 		return nil, fmt.Errorf("NewClientCertificate: parentPrivateKey: must be defined")
 	}
 
+	if err := ValidateSignatureAlgorithm(signatureAlgorithm, parentPrivateKey); err != nil {
+		return nil, fmt.Errorf("NewClientCertificate: signatureAlgorithm: %w", err)
+	}
+
 	if err := ValidateClientCertificateCommonName(commonName); err != nil {
 		return nil, fmt.Errorf("NewClientCertificate: commonName: %s: %s", err, commonName)
 	}
@@ -431,6 +483,7 @@ func NewClientCertificate(
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
 		BasicConstraintsValid: true,
+		SignatureAlgorithm:    signatureAlgorithm.X509(),
 	}
 
 	// Use the parent certificate to sign the intermediate certificate
@@ -457,6 +510,7 @@ func NewClientCertificate(
 //   - serialNumber: Serial number for the new root certificate
 //   - organization: The organization for the new certificate
 //   - expiration: The expiration duration
+//   - signatureAlgorithm: The algorithm used to sign the new certificate
 //   - privateKey: The private key to use for signing
 //   - commonName: The common name for the new root certificate
 //
@@ -466,6 +520,7 @@ func NewRootCertificate(
 	serialNumber *big.Int,
 	organization appmodels.Organization,
 	expiration time.Duration,
+	signatureAlgorithm appmodels.SignatureAlgorithm,
 	privateKey appmodels.PrivateKey,
 	commonName string,
 ) (appmodels.Certificate, error) {
@@ -486,6 +541,10 @@ func NewRootCertificate(
 		return nil, fmt.Errorf("NewRootCertificate: privateKey: must be defined")
 	}
 
+	if err := ValidateSignatureAlgorithm(signatureAlgorithm, privateKey); err != nil {
+		return nil, fmt.Errorf("NewRootCertificate: signatureAlgorithm: %w", err)
+	}
+
 	if err := ValidateRootCertificateCommonName(commonName); err != nil {
 		return nil, fmt.Errorf("NewRootCertificate: commonName: %s: %s", err, commonName)
 	}
@@ -498,9 +557,10 @@ func NewRootCertificate(
 		},
 		NotBefore:             time.Now(),
 		NotAfter:              time.Now().Add(expiration),
-		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
 		ExtKeyUsage:           []x509.ExtKeyUsage{},
 		BasicConstraintsValid: true,
+		SignatureAlgorithm:    signatureAlgorithm.X509(),
 		IsCA:                  true,
 	}
 
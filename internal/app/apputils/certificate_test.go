@@ -225,6 +225,7 @@ func TestNewIntermediateCertificate(t *testing.T) {
 		serialNumber,
 		organization,
 		expiration,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		publicKey,
 		parentCertificate,
 		parentPrivateKey,
@@ -279,6 +280,7 @@ func TestNewServerCertificate(t *testing.T) {
 		serialNumber,
 		organization,
 		expiration,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		mockPublicKey,
 		parentCertificate,
 		parentPrivateKey,
@@ -333,6 +335,7 @@ func TestNewClientCertificate(t *testing.T) {
 		serialNumber,
 		organization,
 		expiration,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		mockPublicKey,
 		parentCertificate,
 		parentPrivateKey,
@@ -389,6 +392,7 @@ func TestNewRootCertificate_Success(t *testing.T) {
 		serialNumber,
 		mockOrganization,
 		expiration,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		mockPrivateKey,
 		commonName,
 	)
@@ -466,6 +470,7 @@ func TestNewIntermediateCertificate_NilManager(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -482,6 +487,7 @@ func TestNewIntermediateCertificate_NilSerialNumber(t *testing.T) {
 		nil, // serialNumber is nil
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -498,6 +504,7 @@ func TestNewIntermediateCertificate_NilOrganization(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		nil, // organization is nil
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -514,6 +521,7 @@ func TestNewIntermediateCertificate_NilParentCertificate(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		nil, // parentCertificate is nil
 		&appmocks.MockPrivateKey{},
@@ -530,6 +538,7 @@ func TestNewIntermediateCertificate_NilParentPrivateKey(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		nil, // parentPrivateKey is nil
@@ -546,6 +555,7 @@ func TestNewIntermediateCertificate_EmptyCommonName(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -583,6 +593,7 @@ func TestNewIntermediateCertificate_FailingCreateCertificate(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		organization,
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		parentCertificate,
 		parentPrivateKey,
@@ -598,6 +609,7 @@ func TestNewServerCertificate_NilManager(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -615,6 +627,7 @@ func TestNewServerCertificate_NilSerialNumber(t *testing.T) {
 		nil, // serialNumber is nil
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -632,6 +645,7 @@ func TestNewServerCertificate_NilOrganization(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		nil, // organization is nil
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -649,6 +663,7 @@ func TestNewServerCertificate_NilParentCertificate(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		nil, // parentCertificate is nil
 		&appmocks.MockPrivateKey{},
@@ -666,6 +681,7 @@ func TestNewServerCertificate_NilParentPrivateKey(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		nil, // parentPrivateKey is nil
@@ -683,6 +699,7 @@ func TestNewServerCertificate_EmptyCommonName(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -701,6 +718,7 @@ func TestNewServerCertificate_NilDnsNames(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -739,6 +757,7 @@ func TestNewServerCertificate_FailingCreateCertificate(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		organization,
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		parentCertificate,
 		parentPrivateKey,
@@ -777,6 +796,7 @@ func TestNewClientCertificate_FailingCreateCertificate(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		organization,
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		parentCertificate,
 		parentPrivateKey,
@@ -810,6 +830,7 @@ func TestNewRootCertificate_FailingCreateCertificate(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		organization,
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		parentPrivateKey,
 		commonName,
 	)
@@ -823,6 +844,7 @@ func TestNewClientCertificate_NilManager(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -839,6 +861,7 @@ func TestNewClientCertificate_NilSerialNumber(t *testing.T) {
 		nil, // serialNumber is nil
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -855,6 +878,7 @@ func TestNewClientCertificate_NilOrganization(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		nil, // organization is nil
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -871,6 +895,7 @@ func TestNewClientCertificate_NilParentCertificate(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		nil, // parentCertificate is nil
 		&appmocks.MockPrivateKey{},
@@ -887,6 +912,7 @@ func TestNewClientCertificate_NilParentPrivateKey(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		nil, // parentPrivateKey is nil
@@ -903,6 +929,7 @@ func TestNewClientCertificate_EmptyCommonName(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		appmocks.NewMockRsaPublicKey(),
 		&appmocks.MockCertificate{},
 		&appmocks.MockPrivateKey{},
@@ -918,6 +945,7 @@ func TestNewRootCertificate_NilManager(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		&appmocks.MockPrivateKey{},
 		"Root CA",
 	)
@@ -932,6 +960,7 @@ func TestNewRootCertificate_NilSerialNumber(t *testing.T) {
 		nil, // serialNumber is nil
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		&appmocks.MockPrivateKey{},
 		"Root CA",
 	)
@@ -946,6 +975,7 @@ func TestNewRootCertificate_NilOrganization(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		nil, // organization is nil
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		&appmocks.MockPrivateKey{},
 		"Root CA",
 	)
@@ -960,6 +990,7 @@ func TestNewRootCertificate_NilParentPrivateKey(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		nil, // parentPrivateKey is nil
 		"Root CA",
 	)
@@ -974,6 +1005,7 @@ func TestNewRootCertificate_EmptyCommonName(t *testing.T) {
 		appmodels.NewSerialNumber(1),
 		&appmocks.MockOrganization{},
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		&appmocks.MockPrivateKey{},
 		"", // commonName is empty
 	)
@@ -1039,6 +1071,8 @@ func TestToCertificateCreatedDTO(t *testing.T) {
 	mockCertificate.On("IsIntermediateCertificate").Return(false)
 	mockCertificate.On("IsServerCertificate").Return(true)
 	mockCertificate.On("IsClientCertificate").Return(false)
+	mockCertificate.On("SignatureAlgorithm").Return(appmodels.ECDSA_WITH_SHA384)
+	mockCertificate.On("SpiffeID").Return("")
 
 	// Setup mock private key behavior
 	privateKeyDTO := appdtos.PrivateKeyDTO{
@@ -1064,6 +1098,7 @@ func TestToCertificateCreatedDTO(t *testing.T) {
 			IsIntermediateCertificate: false,
 			IsServerCertificate:       true,
 			IsClientCertificate:       false,
+			SignatureAlgorithm:        "ECDSA_WITH_SHA384",
 			Certificate:               "-----BEGIN CERTIFICATE-----\n-----END CERTIFICATE-----\n",
 		},
 		PrivateKey: privateKeyDTO,
@@ -1125,6 +1160,8 @@ func TestToListOfCertificateDTO(t *testing.T) {
 	mockCert1.On("IsIntermediateCertificate").Return(false)
 	mockCert1.On("IsServerCertificate").Return(true)
 	mockCert1.On("IsClientCertificate").Return(false)
+	mockCert1.On("SignatureAlgorithm").Return(appmodels.ECDSA_WITH_SHA384)
+	mockCert1.On("SpiffeID").Return("")
 
 	mockCert2.On("CommonName").Return(commonName2)
 	mockCert2.On("Certificate").Return(&x509.Certificate{})
@@ -1136,6 +1173,8 @@ func TestToListOfCertificateDTO(t *testing.T) {
 	mockCert2.On("IsIntermediateCertificate").Return(false)
 	mockCert2.On("IsServerCertificate").Return(true)
 	mockCert2.On("IsClientCertificate").Return(false)
+	mockCert2.On("SignatureAlgorithm").Return(appmodels.ECDSA_WITH_SHA384)
+	mockCert2.On("SpiffeID").Return("")
 
 	// Assume other necessary mocks here as per ToCertificateDTO usage
 
@@ -1182,6 +1221,35 @@ func TestFilterRootCertificates(t *testing.T) {
 	mockCert3.AssertExpectations(t)
 }
 
+func TestShouldRenewCertificate(t *testing.T) {
+	now := time.Now()
+	certificate := new(appmocks.MockCertificate)
+	certificate.On("NotBefore").Return(now.Add(-time.Hour))
+	certificate.On("NotAfter").Return(now.Add(3 * time.Hour))
+	assert.False(t, apputils.ShouldRenewCertificate(certificate, now))
+	assert.True(t, apputils.ShouldRenewCertificate(certificate, now.Add(time.Hour)))
+}
+
+func TestFilterValidRootCertificates(t *testing.T) {
+	now := time.Now()
+
+	validRoot := new(appmocks.MockCertificate)
+	validRoot.On("IsRootCertificate").Return(true)
+	validRoot.On("NotBefore").Return(now.Add(-time.Hour))
+	validRoot.On("NotAfter").Return(now.Add(time.Hour))
+
+	expiredRoot := new(appmocks.MockCertificate)
+	expiredRoot.On("IsRootCertificate").Return(true)
+	expiredRoot.On("NotBefore").Return(now.Add(-2 * time.Hour))
+	expiredRoot.On("NotAfter").Return(now.Add(-time.Hour))
+
+	intermediate := new(appmocks.MockCertificate)
+	intermediate.On("IsRootCertificate").Return(false)
+
+	filteredCerts := apputils.FilterValidRootCertificates([]appmodels.Certificate{validRoot, expiredRoot, intermediate}, now)
+	assert.Equal(t, []appmodels.Certificate{validRoot}, filteredCerts)
+}
+
 func TestFilterClientCertificates(t *testing.T) {
 	// Setup mock certificates
 	mockCert1 := new(appmocks.MockCertificate)
@@ -1333,6 +1401,8 @@ func TestToCertificateListDTO(t *testing.T) {
 	mockCert1.On("IsIntermediateCertificate").Return(false)
 	mockCert1.On("IsServerCertificate").Return(true)
 	mockCert1.On("IsClientCertificate").Return(false)
+	mockCert1.On("SignatureAlgorithm").Return(appmodels.ECDSA_WITH_SHA384)
+	mockCert1.On("SpiffeID").Return("")
 	mockCert1.On("SignedBy").Return(appmodels.NewSerialNumber(987654321))
 	mockCert1.On("OrganizationName").Return("Example Org")
 
@@ -1344,6 +1414,8 @@ func TestToCertificateListDTO(t *testing.T) {
 	mockCert2.On("IsIntermediateCertificate").Return(true)
 	mockCert2.On("IsServerCertificate").Return(false)
 	mockCert2.On("IsClientCertificate").Return(false)
+	mockCert2.On("SignatureAlgorithm").Return(appmodels.ECDSA_WITH_SHA384)
+	mockCert2.On("SpiffeID").Return("")
 	mockCert2.On("SignedBy").Return(appmodels.NewSerialNumber(987654321))
 	mockCert2.On("OrganizationName").Return("Example Org")
 
@@ -1391,6 +1463,7 @@ func TestNewServerCertificate_ValidateDNSNamesError(t *testing.T) {
 		mockSerialNumber,
 		mockOrganization,
 		365*24*time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
 		mockPublicKey,
 		mockParentCertificate,
 		mockParentPrivateKey,
@@ -1409,3 +1482,9 @@ func TestNewServerCertificate_ValidateDNSNamesError(t *testing.T) {
 	mockParentCertificate.AssertExpectations(t)
 	mockParentPrivateKey.AssertExpectations(t)
 }
+
+func TestCertificateFingerprint(t *testing.T) {
+	mockCert := new(appmocks.MockCertificate)
+	mockCert.On("Certificate").Return(&x509.Certificate{Raw: []byte("certificate")})
+	assert.Equal(t, "03d66dd08835c1ca3f128cceacd1f31ac94163096b20f445ae84285bc0832d72", apputils.CertificateFingerprint(mockCert))
+}
@@ -0,0 +1,414 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// ParseCertificateType parses a certificate type case-insensitively. An
+// empty value is appmodels.NIL_CERTIFICATE_TYPE.
+func ParseCertificateType(value string) (appmodels.CertificateType, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return appmodels.NIL_CERTIFICATE_TYPE, nil
+	}
+	for t := appmodels.ROOT_CERTIFICATE; t <= appmodels.CLIENT_CERTIFICATE; t++ {
+		if strings.EqualFold(t.String(), value) {
+			return t, nil
+		}
+	}
+	return appmodels.NIL_CERTIFICATE_TYPE, fmt.Errorf("ParseCertificateType: unsupported: %s", value)
+}
+
+// ParseCertificateStatus parses a certificate status case-insensitively. An
+// empty value is appmodels.NIL_CERTIFICATE_STATUS.
+func ParseCertificateStatus(value string) (appmodels.CertificateStatus, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return appmodels.NIL_CERTIFICATE_STATUS, nil
+	}
+	for s := appmodels.VALID_CERTIFICATE; s <= appmodels.EXPIRED_CERTIFICATE; s++ {
+		if strings.EqualFold(s.String(), value) {
+			return s, nil
+		}
+	}
+	return appmodels.NIL_CERTIFICATE_STATUS, fmt.Errorf("ParseCertificateStatus: unsupported: %s", value)
+}
+
+// ParseCertificateSortField parses a certificate sort field
+// case-insensitively. An empty value is appmodels.NIL_CERTIFICATE_SORT_FIELD.
+func ParseCertificateSortField(value string) (appmodels.CertificateSortField, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return appmodels.NIL_CERTIFICATE_SORT_FIELD, nil
+	}
+	for f := appmodels.SORT_BY_NOT_AFTER; f <= appmodels.SORT_BY_COMMON_NAME; f++ {
+		if strings.EqualFold(f.String(), value) {
+			return f, nil
+		}
+	}
+	return appmodels.NIL_CERTIFICATE_SORT_FIELD, fmt.Errorf("ParseCertificateSortField: unsupported: %s", value)
+}
+
+// CertificateSortFieldToString returns the name of the sort field, or an
+// empty string for appmodels.NIL_CERTIFICATE_SORT_FIELD
+func CertificateSortFieldToString(f appmodels.CertificateSortField) string {
+	if f == appmodels.NIL_CERTIFICATE_SORT_FIELD {
+		return ""
+	}
+	return f.String()
+}
+
+// CertificateSearchNames returns the lower case common name and subject
+// alternative names of a certificate
+func CertificateSearchNames(cert *x509.Certificate) []string {
+	names := CertificateSubjectAlternativeNames(cert)
+	if cert.Subject.CommonName != "" {
+		names = append([]string{strings.ToLower(cert.Subject.CommonName)}, names...)
+	}
+	return names
+}
+
+// CertificateSubjectAlternativeNames returns the lower case DNS names, email
+// addresses, IP addresses and URIs of a certificate
+func CertificateSubjectAlternativeNames(cert *x509.Certificate) []string {
+	names := make([]string, 0, len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.IPAddresses)+len(cert.URIs))
+	for _, name := range cert.DNSNames {
+		names = append(names, strings.ToLower(name))
+	}
+	for _, email := range cert.EmailAddresses {
+		names = append(names, strings.ToLower(email))
+	}
+	for _, ip := range cert.IPAddresses {
+		names = append(names, strings.ToLower(ip.String()))
+	}
+	for _, uri := range cert.URIs {
+		names = append(names, strings.ToLower(uri.String()))
+	}
+	return names
+}
+
+// CertificateKeyType returns the type of the public key of a certificate, or
+// appmodels.NIL_KEY_TYPE if it is not supported
+func CertificateKeyType(cert *x509.Certificate) appmodels.KeyType {
+	keyType, err := DeterminePublicKeyType(cert.PublicKey)
+	if err != nil {
+		return appmodels.NIL_KEY_TYPE
+	}
+	return keyType
+}
+
+// ParseHexBytes parses a hex string like a fingerprint or a key identifier.
+// Colons between the bytes and the letter case are ignored.
+func ParseHexBytes(value string) ([]byte, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ":", "")
+	if value == "" {
+		return nil, errors.New("ParseHexBytes: no value provided")
+	}
+	data, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("ParseHexBytes: %w", err)
+	}
+	return data, nil
+}
+
+// CertificateMatchesName returns true if the common name or a subject
+// alternative name contains the substring, case-insensitively
+func CertificateMatchesName(certificate appmodels.Certificate, name string) bool {
+	return containsSubstring(CertificateSearchNames(certificate.Certificate()), strings.ToLower(name))
+}
+
+// CertificateMatchesQuery returns true if the certificate passes the filters
+// of the query. The position of the cursor is not checked.
+func CertificateMatchesQuery(certificate appmodels.Certificate, query appmodels.CertificateQuery) bool {
+	if query.SignedBy != nil {
+		signedBy := certificate.SignedBy()
+		if signedBy == nil || signedBy.Cmp(query.SignedBy) != 0 {
+			return false
+		}
+	}
+	if !query.Type.Matches(certificate) {
+		return false
+	}
+	if !query.Status.Matches(certificate, query.Now) {
+		return false
+	}
+	if !query.ExpiringBefore.IsZero() && !certificate.NotAfter().Before(query.ExpiringBefore) {
+		return false
+	}
+	if query.Name != "" && !CertificateMatchesName(certificate, query.Name) {
+		return false
+	}
+	return certificateMatchesSearch(certificate, query)
+}
+
+// certificateMatchesSearch checks the search criteria of a query
+func certificateMatchesSearch(certificate appmodels.Certificate, query appmodels.CertificateQuery) bool {
+	cert := certificate.Certificate()
+	if query.CommonName != "" && !strings.Contains(strings.ToLower(cert.Subject.CommonName), strings.ToLower(query.CommonName)) {
+		return false
+	}
+	if query.SubjectAlternativeName != "" && !containsSubstring(CertificateSubjectAlternativeNames(cert), strings.ToLower(query.SubjectAlternativeName)) {
+		return false
+	}
+	if query.Fingerprint != "" && CertificateFingerprint(certificate) != query.Fingerprint {
+		return false
+	}
+	if query.SubjectKeyId != nil && !bytes.Equal(cert.SubjectKeyId, query.SubjectKeyId) {
+		return false
+	}
+	if query.AuthorityKeyId != nil && !bytes.Equal(cert.AuthorityKeyId, query.AuthorityKeyId) {
+		return false
+	}
+	if query.SerialNumber != nil && certificate.SerialNumber().Cmp(query.SerialNumber) != 0 {
+		return false
+	}
+	if query.KeyType != appmodels.NIL_KEY_TYPE && CertificateKeyType(cert) != query.KeyType {
+		return false
+	}
+	if query.Issuer != "" && !strings.Contains(strings.ToLower(cert.Issuer.String()), strings.ToLower(query.Issuer)) {
+		return false
+	}
+	if !query.IssuedAfter.IsZero() && certificate.NotBefore().Before(query.IssuedAfter) {
+		return false
+	}
+	if !query.IssuedBefore.IsZero() && !certificate.NotBefore().Before(query.IssuedBefore) {
+		return false
+	}
+	if !query.ExpiringAfter.IsZero() && certificate.NotAfter().Before(query.ExpiringAfter) {
+		return false
+	}
+	return true
+}
+
+// containsSubstring returns true if one of the values contains the substring
+func containsSubstring(values []string, substring string) bool {
+	for _, v := range values {
+		if strings.Contains(v, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareCertificateCursors compares the positions of two cursors created
+// for the same order. The result is negative if a comes first, positive if b
+// comes first and zero if they are equal.
+func CompareCertificateCursors(a, b *appmodels.CertificateCursor) int {
+	result := 0
+	switch a.Sort {
+	case appmodels.SORT_BY_NOT_AFTER, appmodels.SORT_BY_CREATED_AT:
+		if a.Time < b.Time {
+			result = -1
+		} else if a.Time > b.Time {
+			result = 1
+		}
+	case appmodels.SORT_BY_COMMON_NAME:
+		result = strings.Compare(a.CommonName, b.CommonName)
+	}
+	if result == 0 {
+		result = a.SerialNumber.Cmp(b.SerialNumber)
+	}
+	if a.Descending {
+		return -result
+	}
+	return result
+}
+
+// QueryCertificates returns a page of certificates from a list by filtering,
+// sorting and paginating it in memory. Repositories which cannot push the
+// query down to the storage use it.
+func QueryCertificates(list []appmodels.Certificate, query appmodels.CertificateQuery) appmodels.CertificatePage {
+	type item struct {
+		certificate appmodels.Certificate
+		cursor      *appmodels.CertificateCursor
+	}
+	items := make([]item, 0, len(list))
+	for _, certificate := range list {
+		if !CertificateMatchesQuery(certificate, query) {
+			continue
+		}
+		cursor := appmodels.NewCertificateCursor(query, certificate)
+		if query.After != nil && CompareCertificateCursors(cursor, query.After) <= 0 {
+			continue
+		}
+		items = append(items, item{certificate, cursor})
+	}
+	sort.Slice(items, func(a, b int) bool {
+		return CompareCertificateCursors(items[a].cursor, items[b].cursor) < 0
+	})
+
+	page := appmodels.CertificatePage{
+		Certificates: make([]appmodels.Certificate, 0, len(items)),
+	}
+	for _, v := range items {
+		if query.Limit > 0 && len(page.Certificates) == query.Limit {
+			page.Next = appmodels.NewCertificateCursor(query, page.Certificates[len(page.Certificates)-1])
+			break
+		}
+		page.Certificates = append(page.Certificates, v.certificate)
+	}
+	return page
+}
+
+// certificateCursorJSON is the serialized form of a certificate cursor
+type certificateCursorJSON struct {
+	Sort       string `json:"sort,omitempty"`
+	Descending bool   `json:"desc,omitempty"`
+	Time       int64  `json:"time,omitempty"`
+	CommonName string `json:"cn,omitempty"`
+	Serial     string `json:"serial"`
+}
+
+// EncodeCertificateCursor returns the cursor as an opaque URL safe string,
+// or an empty string for a nil cursor
+func EncodeCertificateCursor(cursor *appmodels.CertificateCursor) string {
+	if cursor == nil {
+		return ""
+	}
+	data, _ := json.Marshal(certificateCursorJSON{
+		Sort:       CertificateSortFieldToString(cursor.Sort),
+		Descending: cursor.Descending,
+		Time:       cursor.Time,
+		CommonName: cursor.CommonName,
+		Serial:     cursor.SerialNumber.String(),
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCertificateCursor parses a cursor returned by
+// EncodeCertificateCursor. An empty value is a nil cursor.
+func DecodeCertificateCursor(value string) (*appmodels.CertificateCursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeCertificateCursor: invalid encoding: %w", err)
+	}
+	var decoded certificateCursorJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("DecodeCertificateCursor: invalid cursor: %w", err)
+	}
+	sortField, err := ParseCertificateSortField(decoded.Sort)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeCertificateCursor: %w", err)
+	}
+	serialNumber, err := ParseBigInt(decoded.Serial, 10)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeCertificateCursor: invalid serial number: %w", err)
+	}
+	return &appmodels.CertificateCursor{
+		Sort:         sortField,
+		Descending:   decoded.Descending,
+		Time:         decoded.Time,
+		CommonName:   decoded.CommonName,
+		SerialNumber: serialNumber,
+	}, nil
+}
+
+// ValidateCertificateCursor returns an error if the cursor of the query was
+// created for a different order
+func ValidateCertificateCursor(query appmodels.CertificateQuery) error {
+	if query.After == nil {
+		return nil
+	}
+	if query.After.Sort != query.Sort || query.After.Descending != query.Descending {
+		return errors.New("cursor does not match the sort order")
+	}
+	return nil
+}
+
+// ToCertificatePageDTO converts a page of certificates to a list DTO with
+// the cursor of the next page
+func ToCertificatePageDTO(page appmodels.CertificatePage) appdtos.CertificateListDTO {
+	dto := ToCertificateListDTO(page.Certificates)
+	dto.NextCursor = EncodeCertificateCursor(page.Next)
+	return dto
+}
+
+// QueryOrganizations returns a page of organizations from a list by
+// filtering and paginating it in memory
+func QueryOrganizations(list []appmodels.Organization, query appmodels.OrganizationQuery) appmodels.OrganizationPage {
+	matches := make([]appmodels.Organization, 0, len(list))
+	for _, organization := range list {
+		if query.After != nil && organization.ID().Cmp(query.After) <= 0 {
+			continue
+		}
+		if query.Name != "" && !OrganizationMatchesName(organization, query.Name) {
+			continue
+		}
+		matches = append(matches, organization)
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		return matches[a].ID().Cmp(matches[b].ID()) < 0
+	})
+	page := appmodels.OrganizationPage{Organizations: matches}
+	if query.Limit > 0 && len(matches) > query.Limit {
+		page.Organizations = matches[:query.Limit]
+		page.Next = page.Organizations[query.Limit-1].ID()
+	}
+	return page
+}
+
+// OrganizationMatchesName returns true if the slug or a name of the
+// organization contains the substring, case-insensitively
+func OrganizationMatchesName(organization appmodels.Organization, name string) bool {
+	name = strings.ToLower(name)
+	if strings.Contains(strings.ToLower(organization.Slug()), name) {
+		return true
+	}
+	for _, v := range organization.Names() {
+		if strings.Contains(strings.ToLower(v), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeOrganizationCursor returns the organization ID as an opaque URL safe
+// cursor, or an empty string for nil
+func EncodeOrganizationCursor(id *big.Int) string {
+	if id == nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(id.String()))
+}
+
+// DecodeOrganizationCursor parses a cursor returned by
+// EncodeOrganizationCursor. An empty value is nil.
+func DecodeOrganizationCursor(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeOrganizationCursor: invalid encoding: %w", err)
+	}
+	id, err := ParseBigInt(string(data), 10)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeOrganizationCursor: invalid cursor: %w", err)
+	}
+	return id, nil
+}
+
+// ToOrganizationPageDTO converts a page of organizations to a list DTO with
+// the cursor of the next page
+func ToOrganizationPageDTO(page appmodels.OrganizationPage) appdtos.OrganizationListDTO {
+	dto := ToOrganizationListDTO(page.Organizations)
+	dto.NextCursor = EncodeOrganizationCursor(page.Next)
+	return dto
+}
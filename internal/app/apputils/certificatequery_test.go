@@ -0,0 +1,228 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+func newTestQueryCertificate(serialNumber int64, commonName string, notAfter time.Time) appmodels.Certificate {
+	return appmodels.NewCertificate(big.NewInt(1), big.NewInt(100), &x509.Certificate{
+		SerialNumber: big.NewInt(serialNumber),
+		Subject:      pkix.Name{CommonName: commonName},
+		Issuer:       pkix.Name{CommonName: "Root"},
+		DNSNames:     []string{commonName},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+}
+
+func serialsOf(list []appmodels.Certificate) []int64 {
+	result := make([]int64, 0, len(list))
+	for _, cert := range list {
+		result = append(result, cert.SerialNumber().Int64())
+	}
+	return result
+}
+
+func TestParseCertificateQueryEnums(t *testing.T) {
+	certificateType, err := apputils.ParseCertificateType("Intermediate")
+	assert.NoError(t, err)
+	assert.Equal(t, appmodels.INTERMEDIATE_CERTIFICATE, certificateType)
+	certificateType, err = apputils.ParseCertificateType("")
+	assert.NoError(t, err)
+	assert.Equal(t, appmodels.NIL_CERTIFICATE_TYPE, certificateType)
+	_, err = apputils.ParseCertificateType("leaf")
+	assert.Error(t, err)
+
+	status, err := apputils.ParseCertificateStatus("expired")
+	assert.NoError(t, err)
+	assert.Equal(t, appmodels.EXPIRED_CERTIFICATE, status)
+	_, err = apputils.ParseCertificateStatus("revoked")
+	assert.Error(t, err)
+
+	field, err := apputils.ParseCertificateSortField("commonname")
+	assert.NoError(t, err)
+	assert.Equal(t, appmodels.SORT_BY_COMMON_NAME, field)
+	_, err = apputils.ParseCertificateSortField("serial")
+	assert.Error(t, err)
+	assert.Equal(t, "", apputils.CertificateSortFieldToString(appmodels.NIL_CERTIFICATE_SORT_FIELD))
+	assert.Equal(t, "notAfter", apputils.CertificateSortFieldToString(appmodels.SORT_BY_NOT_AFTER))
+}
+
+func TestCertificateSearchNames(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "Example"},
+		DNSNames:       []string{"WWW.example.com"},
+		EmailAddresses: []string{"Admin@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+	}
+	assert.Equal(t, []string{"example", "www.example.com", "admin@example.com", "10.0.0.1"}, apputils.CertificateSearchNames(cert))
+}
+
+func TestParseHexBytes(t *testing.T) {
+	data, err := apputils.ParseHexBytes("AB:cd:01")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xab, 0xcd, 0x01}, data)
+	_, err = apputils.ParseHexBytes("")
+	assert.Error(t, err)
+	_, err = apputils.ParseHexBytes("xyz")
+	assert.Error(t, err)
+}
+
+func TestCertificateMatchesQuery_Search(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	cert := newTestQueryCertificate(42, "www.example.com", now)
+	cert.Certificate().SubjectKeyId = []byte{0x01, 0x02}
+	cert.Certificate().AuthorityKeyId = []byte{0x03, 0x04}
+	cert.Certificate().Raw = []byte("certificate")
+
+	tests := []struct {
+		name  string
+		query appmodels.CertificateQuery
+		want  bool
+	}{
+		{"CommonName", appmodels.CertificateQuery{CommonName: "WWW"}, true},
+		{"CommonNameMismatch", appmodels.CertificateQuery{CommonName: "api"}, false},
+		{"SubjectAlternativeName", appmodels.CertificateQuery{SubjectAlternativeName: "example.com"}, true},
+		{"Fingerprint", appmodels.CertificateQuery{Fingerprint: apputils.CertificateFingerprint(cert)}, true},
+		{"FingerprintMismatch", appmodels.CertificateQuery{Fingerprint: "00"}, false},
+		{"SubjectKeyId", appmodels.CertificateQuery{SubjectKeyId: []byte{0x01, 0x02}}, true},
+		{"AuthorityKeyIdMismatch", appmodels.CertificateQuery{AuthorityKeyId: []byte{0x01, 0x02}}, false},
+		{"SerialNumber", appmodels.CertificateQuery{SerialNumber: big.NewInt(42)}, true},
+		{"SerialNumberMismatch", appmodels.CertificateQuery{SerialNumber: big.NewInt(43)}, false},
+		{"KeyTypeUnknown", appmodels.CertificateQuery{KeyType: appmodels.RSA_2048}, false},
+		{"Issuer", appmodels.CertificateQuery{Issuer: "cn=root"}, true},
+		{"IssuedAfter", appmodels.CertificateQuery{IssuedAfter: now.Add(-48 * time.Hour)}, true},
+		{"IssuedBefore", appmodels.CertificateQuery{IssuedBefore: now.Add(-24 * time.Hour)}, false},
+		{"ExpiringAfter", appmodels.CertificateQuery{ExpiringAfter: now.Add(time.Second)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, apputils.CertificateMatchesQuery(cert, tt.query))
+		})
+	}
+}
+
+func TestQueryCertificates(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	list := []appmodels.Certificate{
+		newTestQueryCertificate(3, "c.example.com", now.Add(1*time.Hour)),
+		newTestQueryCertificate(1, "b.example.com", now.Add(3*time.Hour)),
+		newTestQueryCertificate(2, "a.example.com", now.Add(2*time.Hour)),
+		newTestQueryCertificate(4, "a.example.com", now.Add(-time.Hour)),
+	}
+
+	page := apputils.QueryCertificates(list, appmodels.CertificateQuery{})
+	assert.Equal(t, []int64{1, 2, 3, 4}, serialsOf(page.Certificates))
+	assert.Nil(t, page.Next)
+
+	page = apputils.QueryCertificates(list, appmodels.CertificateQuery{Sort: appmodels.SORT_BY_NOT_AFTER})
+	assert.Equal(t, []int64{4, 3, 2, 1}, serialsOf(page.Certificates))
+
+	page = apputils.QueryCertificates(list, appmodels.CertificateQuery{Sort: appmodels.SORT_BY_COMMON_NAME, Descending: true})
+	assert.Equal(t, []int64{3, 1, 4, 2}, serialsOf(page.Certificates))
+
+	page = apputils.QueryCertificates(list, appmodels.CertificateQuery{Status: appmodels.EXPIRED_CERTIFICATE, Now: now})
+	assert.Equal(t, []int64{4}, serialsOf(page.Certificates))
+
+	page = apputils.QueryCertificates(list, appmodels.CertificateQuery{ExpiringBefore: now.Add(2 * time.Hour)})
+	assert.Equal(t, []int64{3, 4}, serialsOf(page.Certificates))
+
+	page = apputils.QueryCertificates(list, appmodels.CertificateQuery{Name: "A.EXAMPLE"})
+	assert.Equal(t, []int64{2, 4}, serialsOf(page.Certificates))
+
+	page = apputils.QueryCertificates(list, appmodels.CertificateQuery{Type: appmodels.CLIENT_CERTIFICATE})
+	assert.Empty(t, page.Certificates)
+
+	page = apputils.QueryCertificates(list, appmodels.CertificateQuery{SignedBy: big.NewInt(7)})
+	assert.Empty(t, page.Certificates)
+
+	query := appmodels.CertificateQuery{Sort: appmodels.SORT_BY_COMMON_NAME, Limit: 3}
+	page = apputils.QueryCertificates(list, query)
+	assert.Equal(t, []int64{2, 4, 1}, serialsOf(page.Certificates))
+	require.NotNil(t, page.Next)
+	query.After = page.Next
+	page = apputils.QueryCertificates(list, query)
+	assert.Equal(t, []int64{3}, serialsOf(page.Certificates))
+	assert.Nil(t, page.Next)
+}
+
+func TestCertificateCursor_EncodeDecode(t *testing.T) {
+	cursor := &appmodels.CertificateCursor{
+		Sort:         appmodels.SORT_BY_COMMON_NAME,
+		Descending:   true,
+		CommonName:   "example.com",
+		SerialNumber: big.NewInt(123),
+	}
+	decoded, err := apputils.DecodeCertificateCursor(apputils.EncodeCertificateCursor(cursor))
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	assert.Equal(t, "", apputils.EncodeCertificateCursor(nil))
+	decoded, err = apputils.DecodeCertificateCursor("")
+	assert.NoError(t, err)
+	assert.Nil(t, decoded)
+
+	_, err = apputils.DecodeCertificateCursor("not a cursor!")
+	assert.Error(t, err)
+	_, err = apputils.DecodeCertificateCursor("e30")
+	assert.Error(t, err)
+}
+
+func TestValidateCertificateCursor(t *testing.T) {
+	cursor := &appmodels.CertificateCursor{Sort: appmodels.SORT_BY_NOT_AFTER, SerialNumber: big.NewInt(1)}
+	assert.NoError(t, apputils.ValidateCertificateCursor(appmodels.CertificateQuery{}))
+	assert.NoError(t, apputils.ValidateCertificateCursor(appmodels.CertificateQuery{Sort: appmodels.SORT_BY_NOT_AFTER, After: cursor}))
+	assert.Error(t, apputils.ValidateCertificateCursor(appmodels.CertificateQuery{Sort: appmodels.SORT_BY_COMMON_NAME, After: cursor}))
+	assert.Error(t, apputils.ValidateCertificateCursor(appmodels.CertificateQuery{Sort: appmodels.SORT_BY_NOT_AFTER, Descending: true, After: cursor}))
+}
+
+func TestQueryOrganizations(t *testing.T) {
+	list := []appmodels.Organization{
+		appmodels.NewOrganization(big.NewInt(10), "gamma", []string{"Gamma Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY),
+		appmodels.NewOrganization(big.NewInt(1), "alpha", []string{"Alpha Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY),
+		appmodels.NewOrganization(big.NewInt(2), "beta", []string{"Beta Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY),
+	}
+
+	page := apputils.QueryOrganizations(list, appmodels.OrganizationQuery{Limit: 2})
+	require.Len(t, page.Organizations, 2)
+	assert.Equal(t, "alpha", page.Organizations[0].Slug())
+	assert.Equal(t, "beta", page.Organizations[1].Slug())
+	assert.Equal(t, big.NewInt(2), page.Next)
+
+	page = apputils.QueryOrganizations(list, appmodels.OrganizationQuery{Limit: 2, After: page.Next})
+	require.Len(t, page.Organizations, 1)
+	assert.Equal(t, "gamma", page.Organizations[0].Slug())
+	assert.Nil(t, page.Next)
+
+	page = apputils.QueryOrganizations(list, appmodels.OrganizationQuery{Name: "BETA ORG"})
+	require.Len(t, page.Organizations, 1)
+	assert.Equal(t, "beta", page.Organizations[0].Slug())
+}
+
+func TestOrganizationCursor_EncodeDecode(t *testing.T) {
+	decoded, err := apputils.DecodeOrganizationCursor(apputils.EncodeOrganizationCursor(big.NewInt(42)))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), decoded)
+
+	assert.Equal(t, "", apputils.EncodeOrganizationCursor(nil))
+	decoded, err = apputils.DecodeOrganizationCursor("")
+	assert.NoError(t, err)
+	assert.Nil(t, decoded)
+
+	_, err = apputils.DecodeOrganizationCursor("eA")
+	assert.Error(t, err)
+}
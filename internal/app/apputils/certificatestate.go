@@ -0,0 +1,103 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// MaxExpiringWindow is the longest time before its NotAfter time a
+// certificate is reported as expiring
+const MaxExpiringWindow = 30 * 24 * time.Hour
+
+// CertificateExpiringWindow returns how long before its NotAfter time a
+// certificate is expiring: the last third of its lifetime, but at most
+// MaxExpiringWindow
+func CertificateExpiringWindow(certificate appmodels.Certificate) time.Duration {
+	window := certificate.NotAfter().Sub(certificate.NotBefore()) / 3
+	if window > MaxExpiringWindow {
+		return MaxExpiringWindow
+	}
+	return window
+}
+
+// CertificateStateAt returns the lifecycle state of a certificate. A
+// revocation takes precedence over the expiration, and the expiration over
+// a newer certificate.
+//   - certificate: The certificate
+//   - now: The time to evaluate the state at
+//   - revoked: The certificate has been revoked
+//   - superseded: A newer certificate has replaced the certificate
+func CertificateStateAt(certificate appmodels.Certificate, now time.Time, revoked, superseded bool) appmodels.CertificateState {
+	switch {
+	case revoked:
+		return appmodels.CERTIFICATE_STATE_REVOKED
+	case now.After(certificate.NotAfter()):
+		return appmodels.CERTIFICATE_STATE_EXPIRED
+	case superseded:
+		return appmodels.CERTIFICATE_STATE_SUPERSEDED
+	case now.Before(certificate.NotBefore()):
+		return appmodels.CERTIFICATE_STATE_PENDING
+	case !now.Before(certificate.NotAfter().Add(-CertificateExpiringWindow(certificate))):
+		return appmodels.CERTIFICATE_STATE_EXPIRING
+	default:
+		return appmodels.CERTIFICATE_STATE_ACTIVE
+	}
+}
+
+// SupersededCertificates returns the time each superseded certificate of the
+// list was replaced, indexed by the decimal serial number. A certificate is
+// superseded by a newer certificate with the same common name, type and
+// issuer, once the newer one is valid. Revoked certificates do not supersede
+// others.
+//   - certificates: The certificates of an organization
+//   - revoked: Revoked serial numbers
+//   - now: The time to evaluate the validity of newer certificates at
+func SupersededCertificates(certificates []appmodels.Certificate, revoked map[string]bool, now time.Time) map[string]time.Time {
+	successors := make(map[string][]appmodels.Certificate)
+	for _, certificate := range certificates {
+		if revoked[certificate.SerialNumber().String()] || now.Before(certificate.NotBefore()) {
+			continue
+		}
+		key := certificateLineage(certificate)
+		successors[key] = append(successors[key], certificate)
+	}
+
+	result := make(map[string]time.Time)
+	for _, certificate := range certificates {
+		for _, successor := range successors[certificateLineage(certificate)] {
+			if !successor.NotBefore().After(certificate.NotBefore()) {
+				continue
+			}
+			serialNumber := certificate.SerialNumber().String()
+			if since, exists := result[serialNumber]; !exists || successor.NotBefore().Before(since) {
+				result[serialNumber] = successor.NotBefore()
+			}
+		}
+	}
+	return result
+}
+
+// certificateLineage returns a key which is the same for certificates
+// replacing each other
+func certificateLineage(certificate appmodels.Certificate) string {
+	signedBy := ""
+	if certificate.SignedBy() != nil {
+		signedBy = certificate.SignedBy().String()
+	}
+	flags := []byte("----")
+	for i, set := range []bool{
+		certificate.IsRootCertificate(),
+		certificate.IsIntermediateCertificate(),
+		certificate.IsServerCertificate(),
+		certificate.IsClientCertificate(),
+	} {
+		if set {
+			flags[i] = '+'
+		}
+	}
+	return strings.Join([]string{signedBy, string(flags), certificate.CommonName()}, "/")
+}
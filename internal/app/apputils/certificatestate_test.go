@@ -0,0 +1,72 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+func TestCertificateExpiringWindow(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 8*time.Hour, apputils.CertificateExpiringWindow(newTestQueryCertificate(1, "a", now)))
+
+	long := appmodels.NewCertificate(big.NewInt(1), nil, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    now,
+		NotAfter:     now.AddDate(10, 0, 0),
+	})
+	assert.Equal(t, apputils.MaxExpiringWindow, apputils.CertificateExpiringWindow(long))
+}
+
+func TestCertificateStateAt(t *testing.T) {
+	notAfter := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+	certificate := newTestQueryCertificate(1, "a.example.com", notAfter)
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		revoked    bool
+		superseded bool
+		want       appmodels.CertificateState
+	}{
+		{"Pending", notAfter.Add(-25 * time.Hour), false, false, appmodels.CERTIFICATE_STATE_PENDING},
+		{"Active", notAfter.Add(-12 * time.Hour), false, false, appmodels.CERTIFICATE_STATE_ACTIVE},
+		{"Expiring", notAfter.Add(-8 * time.Hour), false, false, appmodels.CERTIFICATE_STATE_EXPIRING},
+		{"Expired", notAfter.Add(time.Second), false, true, appmodels.CERTIFICATE_STATE_EXPIRED},
+		{"Revoked", notAfter.Add(time.Second), true, true, appmodels.CERTIFICATE_STATE_REVOKED},
+		{"Superseded", notAfter.Add(-12 * time.Hour), false, true, appmodels.CERTIFICATE_STATE_SUPERSEDED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, apputils.CertificateStateAt(certificate, tt.now, tt.revoked, tt.superseded))
+		})
+	}
+}
+
+func TestSupersededCertificates(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	oldest := newTestQueryCertificate(1, "a.example.com", now.Add(1*time.Hour))
+	older := newTestQueryCertificate(2, "a.example.com", now.Add(2*time.Hour))
+	newest := newTestQueryCertificate(3, "a.example.com", now.Add(3*time.Hour))
+	revoked := newTestQueryCertificate(4, "a.example.com", now.Add(4*time.Hour))
+	pending := newTestQueryCertificate(5, "a.example.com", now.Add(48*time.Hour))
+	other := newTestQueryCertificate(6, "b.example.com", now.Add(5*time.Hour))
+
+	superseded := apputils.SupersededCertificates(
+		[]appmodels.Certificate{oldest, older, newest, revoked, pending, other},
+		map[string]bool{"4": true},
+		now,
+	)
+	assert.Equal(t, map[string]time.Time{
+		"1": older.NotBefore(),
+		"2": newest.NotBefore(),
+	}, superseded)
+}
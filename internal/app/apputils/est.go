@@ -0,0 +1,143 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// signatureAlgorithmOIDs maps signature algorithms to the object identifiers
+// used in CSR attributes responses
+var signatureAlgorithmOIDs = map[x509.SignatureAlgorithm]asn1.ObjectIdentifier{
+	x509.SHA256WithRSA:    {1, 2, 840, 113549, 1, 1, 11},
+	x509.SHA384WithRSA:    {1, 2, 840, 113549, 1, 1, 12},
+	x509.SHA512WithRSA:    {1, 2, 840, 113549, 1, 1, 13},
+	x509.SHA256WithRSAPSS: {1, 2, 840, 113549, 1, 1, 10},
+	x509.SHA384WithRSAPSS: {1, 2, 840, 113549, 1, 1, 10},
+	x509.SHA512WithRSAPSS: {1, 2, 840, 113549, 1, 1, 10},
+	x509.ECDSAWithSHA256:  {1, 2, 840, 10045, 4, 3, 2},
+	x509.ECDSAWithSHA384:  {1, 2, 840, 10045, 4, 3, 3},
+	x509.ECDSAWithSHA512:  {1, 2, 840, 10045, 4, 3, 4},
+	x509.PureEd25519:      {1, 3, 101, 112},
+}
+
+// SignatureAlgorithmOID returns the object identifier of a signature
+// algorithm, or nil if it is not known
+func SignatureAlgorithmOID(algorithm x509.SignatureAlgorithm) asn1.ObjectIdentifier {
+	return signatureAlgorithmOIDs[algorithm]
+}
+
+// EstErrorStatusCode returns the HTTP status code for an EST error type
+func EstErrorStatusCode(errorType appmodels.EstErrorType) int {
+	switch errorType {
+	case appmodels.EST_ERROR_BAD_REQUEST:
+		return http.StatusBadRequest
+	case appmodels.EST_ERROR_UNAUTHORIZED:
+		return http.StatusUnauthorized
+	case appmodels.EST_ERROR_NOT_FOUND:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// DecodeEstBase64 decodes a base64 encoded EST message body. Line breaks and
+// other white space are ignored.
+func DecodeEstBase64(body []byte) ([]byte, error) {
+	data := strings.Join(strings.Fields(string(body)), "")
+	der, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeEstBase64: %w", err)
+	}
+	return der, nil
+}
+
+// EncodeEstBase64 encodes an EST message body as base64 with 64 character
+// lines
+func EncodeEstBase64(der []byte) []byte {
+	data := base64.StdEncoding.EncodeToString(der)
+	var buffer bytes.Buffer
+	for len(data) > 64 {
+		buffer.WriteString(data[:64])
+		buffer.WriteString("\r\n")
+		data = data[64:]
+	}
+	buffer.WriteString(data)
+	buffer.WriteString("\r\n")
+	return buffer.Bytes()
+}
+
+// ParseEstCsr parses a base64 encoded PKCS #10 certificate signing request
+// and checks its signature
+func ParseEstCsr(body []byte) (*x509.CertificateRequest, error) {
+	der, err := DecodeEstBase64(body)
+	if err != nil {
+		return nil, fmt.Errorf("ParseEstCsr: %w", err)
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, fmt.Errorf("ParseEstCsr: %w", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("ParseEstCsr: invalid signature: %w", err)
+	}
+	return csr, nil
+}
+
+// EncodeEstCsrAttributes encodes a CSR attributes response (RFC 7030 section
+// 4.5.2) which contains only object identifiers
+func EncodeEstCsrAttributes(oids []asn1.ObjectIdentifier) ([]byte, error) {
+	der, err := asn1.Marshal(oids)
+	if err != nil {
+		return nil, fmt.Errorf("EncodeEstCsrAttributes: %w", err)
+	}
+	return der, nil
+}
+
+func ToEstLabelDTO(label appmodels.EstLabel) appdtos.EstLabelDTO {
+	return appdtos.NewEstLabelDTO(
+		label.Label(),
+		label.OrganizationID().String(),
+		label.IssuerSerialNumber().String(),
+		label.Username(),
+	)
+}
+
+// ToEstLabelRecordDTO converts an EST label to its stored form
+func ToEstLabelRecordDTO(label appmodels.EstLabel) appdtos.EstLabelRecordDTO {
+	return appdtos.NewEstLabelRecordDTO(
+		label.Label(),
+		label.OrganizationID().String(),
+		label.IssuerSerialNumber().String(),
+		label.Username(),
+		label.PasswordHash(),
+	)
+}
+
+// FromEstLabelRecordDTO converts the stored form of an EST label to a model
+func FromEstLabelRecordDTO(dto appdtos.EstLabelRecordDTO) (appmodels.EstLabel, error) {
+	organization, err := ParseBigInt(dto.Organization, 10)
+	if err != nil {
+		return nil, fmt.Errorf("FromEstLabelRecordDTO: invalid organization: %w", err)
+	}
+	issuer, err := ParseBigInt(dto.SerialNumber, 10)
+	if err != nil {
+		return nil, fmt.Errorf("FromEstLabelRecordDTO: invalid serial number: %w", err)
+	}
+	return appmodels.NewEstLabel(
+		dto.Label,
+		organization,
+		issuer,
+		dto.Username,
+		dto.PasswordHash,
+	), nil
+}
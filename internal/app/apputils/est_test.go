@@ -0,0 +1,92 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+func TestSignatureAlgorithmOID(t *testing.T) {
+	assert.Equal(t, asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}, apputils.SignatureAlgorithmOID(x509.ECDSAWithSHA384))
+	assert.Nil(t, apputils.SignatureAlgorithmOID(x509.UnknownSignatureAlgorithm))
+}
+
+func TestEstErrorStatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusBadRequest, apputils.EstErrorStatusCode(appmodels.EST_ERROR_BAD_REQUEST))
+	assert.Equal(t, http.StatusUnauthorized, apputils.EstErrorStatusCode(appmodels.EST_ERROR_UNAUTHORIZED))
+	assert.Equal(t, http.StatusNotFound, apputils.EstErrorStatusCode(appmodels.EST_ERROR_NOT_FOUND))
+	assert.Equal(t, http.StatusInternalServerError, apputils.EstErrorStatusCode(appmodels.EST_ERROR_SERVER_INTERNAL))
+}
+
+func TestEstBase64(t *testing.T) {
+	data := []byte(strings.Repeat("x", 100))
+	encoded := apputils.EncodeEstBase64(data)
+	assert.Contains(t, string(encoded), "\r\n")
+
+	decoded, err := apputils.DecodeEstBase64(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, data, decoded)
+
+	_, err = apputils.DecodeEstBase64([]byte("!"))
+	assert.Error(t, err)
+}
+
+func TestParseEstCsr(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: "device"}}, key)
+	require.NoError(t, err)
+
+	csr, err := apputils.ParseEstCsr(apputils.EncodeEstBase64(der))
+	require.NoError(t, err)
+	assert.Equal(t, "device", csr.Subject.CommonName)
+
+	_, err = apputils.ParseEstCsr([]byte("aW52YWxpZA=="))
+	assert.Error(t, err)
+}
+
+func TestEncodeEstCsrAttributes(t *testing.T) {
+	oid := apputils.SignatureAlgorithmOID(x509.ECDSAWithSHA256)
+	der, err := apputils.EncodeEstCsrAttributes([]asn1.ObjectIdentifier{oid})
+	require.NoError(t, err)
+
+	var oids []asn1.ObjectIdentifier
+	_, err = asn1.Unmarshal(der, &oids)
+	require.NoError(t, err)
+	assert.Equal(t, []asn1.ObjectIdentifier{oid}, oids)
+}
+
+func TestToEstLabelDTO(t *testing.T) {
+	dto := apputils.ToEstLabelDTO(appmodels.NewEstLabel("devices", big.NewInt(1), big.NewInt(2), "user", []byte("hash")))
+	assert.Equal(t, "devices", dto.Label)
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "2", dto.SerialNumber)
+	assert.Equal(t, "user", dto.Username)
+}
+
+func TestEstLabelRecordDTO(t *testing.T) {
+	label := appmodels.NewEstLabel("devices", big.NewInt(1), big.NewInt(2), "user", []byte("hash"))
+	model, err := apputils.FromEstLabelRecordDTO(apputils.ToEstLabelRecordDTO(label))
+	require.NoError(t, err)
+	assert.Equal(t, label, model)
+
+	dto := apputils.ToEstLabelRecordDTO(label)
+	dto.SerialNumber = "invalid"
+	_, err = apputils.FromEstLabelRecordDTO(dto)
+	assert.Error(t, err)
+}
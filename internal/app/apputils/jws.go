@@ -0,0 +1,262 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// ParseJwk parses a JSON Web Key into a public key
+//   - data: The JWK in JSON format
+//
+// Returns *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey
+func ParseJwk(data []byte) (any, error) {
+
+	var dto appdtos.JwkDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("ParseJwk: failed to parse JSON: %w", err)
+	}
+
+	switch dto.KeyType {
+	case "RSA":
+		n, err := decodeJwkBigInt(dto.N)
+		if err != nil {
+			return nil, fmt.Errorf("ParseJwk: n: %w", err)
+		}
+		e, err := decodeJwkBigInt(dto.E)
+		if err != nil {
+			return nil, fmt.Errorf("ParseJwk: e: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("ParseJwk: e: unsupported exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch dto.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("ParseJwk: crv: unsupported curve: %s", dto.Curve)
+		}
+		x, err := decodeJwkBigInt(dto.X)
+		if err != nil {
+			return nil, fmt.Errorf("ParseJwk: x: %w", err)
+		}
+		y, err := decodeJwkBigInt(dto.Y)
+		if err != nil {
+			return nil, fmt.Errorf("ParseJwk: y: %w", err)
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if _, err := key.ECDH(); err != nil {
+			return nil, fmt.Errorf("ParseJwk: invalid point: %w", err)
+		}
+		return key, nil
+
+	case "OKP":
+		if dto.Curve != "Ed25519" {
+			return nil, fmt.Errorf("ParseJwk: crv: unsupported curve: %s", dto.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(dto.X)
+		if err != nil {
+			return nil, fmt.Errorf("ParseJwk: x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("ParseJwk: x: invalid length: %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("ParseJwk: kty: unsupported key type: %s", dto.KeyType)
+	}
+}
+
+// ToJwkDTO converts a public key to a JSON Web Key
+func ToJwkDTO(publicKey any) (appdtos.JwkDTO, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return appdtos.NewJwkDTO(
+			"RSA",
+			"",
+			"",
+			"",
+			base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		), nil
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return appdtos.NewJwkDTO(
+			"EC",
+			key.Curve.Params().Name,
+			base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+			"",
+			"",
+		), nil
+	case ed25519.PublicKey:
+		return appdtos.NewJwkDTO(
+			"OKP",
+			"Ed25519",
+			base64.RawURLEncoding.EncodeToString(key),
+			"",
+			"",
+			"",
+		), nil
+	default:
+		return appdtos.JwkDTO{}, fmt.Errorf("ToJwkDTO: unsupported key type: %T", publicKey)
+	}
+}
+
+// JwkThumbprint returns the RFC 7638 thumbprint of a public key in base64url
+// encoding
+func JwkThumbprint(publicKey any) (string, error) {
+	dto, err := ToJwkDTO(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("JwkThumbprint: %w", err)
+	}
+
+	// The required members in lexicographic order without whitespace
+	var data string
+	switch dto.KeyType {
+	case "RSA":
+		data = fmt.Sprintf(`{"e":%q,"kty":%q,"n":%q}`, dto.E, dto.KeyType, dto.N)
+	case "EC":
+		data = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, dto.Curve, dto.KeyType, dto.X, dto.Y)
+	default:
+		data = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, dto.Curve, dto.KeyType, dto.X)
+	}
+
+	sum := sha256.Sum256([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// ParseAcmeJws parses an ACME request body in the flattened JWS JSON
+// serialization and decodes its protected header and payload. The signature
+// is not verified.
+func ParseAcmeJws(body []byte) (appdtos.AcmeJwsDTO, appdtos.AcmeJwsHeaderDTO, []byte, error) {
+
+	var jws appdtos.AcmeJwsDTO
+	if err := json.Unmarshal(body, &jws); err != nil {
+		return jws, appdtos.AcmeJwsHeaderDTO{}, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "ParseAcmeJws: failed to parse JWS: %v", err)
+	}
+
+	protected, err := base64.RawURLEncoding.DecodeString(jws.Protected)
+	if err != nil {
+		return jws, appdtos.AcmeJwsHeaderDTO{}, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "ParseAcmeJws: protected: %v", err)
+	}
+
+	var header appdtos.AcmeJwsHeaderDTO
+	if err := json.Unmarshal(protected, &header); err != nil {
+		return jws, header, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "ParseAcmeJws: protected: %v", err)
+	}
+
+	if header.Nonce == "" {
+		return jws, header, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_NONCE, "ParseAcmeJws: nonce: must be defined")
+	}
+	if header.URL == "" {
+		return jws, header, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "ParseAcmeJws: url: must be defined")
+	}
+	if (len(header.Key) == 0) == (header.KeyID == "") {
+		return jws, header, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "ParseAcmeJws: exactly one of jwk and kid must be defined")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return jws, header, nil, appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "ParseAcmeJws: payload: %v", err)
+	}
+
+	return jws, header, payload, nil
+}
+
+// VerifyAcmeJws verifies the signature of an ACME JWS
+//   - jws: The JWS
+//   - algorithm: The "alg" from the protected header
+//   - publicKey: The account public key
+func VerifyAcmeJws(jws appdtos.AcmeJwsDTO, algorithm string, publicKey any) error {
+
+	signature, err := base64.RawURLEncoding.DecodeString(jws.Signature)
+	if err != nil {
+		return appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "VerifyAcmeJws: signature: %v", err)
+	}
+	signed := []byte(jws.Protected + "." + jws.Payload)
+
+	switch key := publicKey.(type) {
+
+	case *rsa.PublicKey:
+		if algorithm != "RS256" {
+			return appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_SIGNATURE_ALGORITHM, "VerifyAcmeJws: unsupported algorithm for RSA key: %s", algorithm)
+		}
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "VerifyAcmeJws: invalid signature")
+		}
+		return nil
+
+	case *ecdsa.PublicKey:
+		var digest []byte
+		switch {
+		case algorithm == "ES256" && key.Curve == elliptic.P256():
+			sum := sha256.Sum256(signed)
+			digest = sum[:]
+		case algorithm == "ES384" && key.Curve == elliptic.P384():
+			sum := sha512.Sum384(signed)
+			digest = sum[:]
+		case algorithm == "ES512" && key.Curve == elliptic.P521():
+			sum := sha512.Sum512(signed)
+			digest = sum[:]
+		default:
+			return appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_SIGNATURE_ALGORITHM, "VerifyAcmeJws: unsupported algorithm for %s key: %s", key.Curve.Params().Name, algorithm)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "VerifyAcmeJws: invalid signature length: %d", len(signature))
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "VerifyAcmeJws: invalid signature")
+		}
+		return nil
+
+	case ed25519.PublicKey:
+		if algorithm != "EdDSA" {
+			return appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_SIGNATURE_ALGORITHM, "VerifyAcmeJws: unsupported algorithm for Ed25519 key: %s", algorithm)
+		}
+		if !ed25519.Verify(key, signed, signature) {
+			return appmodels.NewAcmeError(appmodels.ACME_ERROR_MALFORMED, "VerifyAcmeJws: invalid signature")
+		}
+		return nil
+
+	default:
+		return appmodels.NewAcmeError(appmodels.ACME_ERROR_BAD_SIGNATURE_ALGORITHM, "VerifyAcmeJws: unsupported key type: %T", publicKey)
+	}
+}
+
+func decodeJwkBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, fmt.Errorf("must be defined")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
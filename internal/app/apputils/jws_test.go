@@ -0,0 +1,155 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+func signTestJws(t *testing.T, key crypto.Signer, algorithm string, header appdtos.AcmeJwsHeaderDTO, payload string) appdtos.AcmeJwsDTO {
+	header.Algorithm = algorithm
+	protectedJSON, err := json.Marshal(header)
+	require.NoError(t, err)
+	protected := base64.RawURLEncoding.EncodeToString(protectedJSON)
+	encodedPayload := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	signed := []byte(protected + "." + encodedPayload)
+
+	var signature []byte
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(signed)
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256(signed)
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, signed)
+	}
+	return appdtos.NewAcmeJwsDTO(protected, encodedPayload, base64.RawURLEncoding.EncodeToString(signature))
+}
+
+func TestParseJwk_RoundTrip(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for _, publicKey := range []any{&ecKey.PublicKey, &rsaKey.PublicKey, edKey.Public()} {
+		dto, err := apputils.ToJwkDTO(publicKey)
+		require.NoError(t, err)
+		data, err := json.Marshal(dto)
+		require.NoError(t, err)
+		parsed, err := apputils.ParseJwk(data)
+		require.NoError(t, err)
+		assert.True(t, parsed.(interface{ Equal(crypto.PublicKey) bool }).Equal(publicKey))
+	}
+}
+
+func TestParseJwk_Invalid(t *testing.T) {
+	for _, data := range []string{
+		`not json`,
+		`{"kty":"oct"}`,
+		`{"kty":"EC","crv":"P-192","x":"AA","y":"AA"}`,
+		`{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}`,
+		`{"kty":"RSA","n":"","e":"AQAB"}`,
+		`{"kty":"OKP","crv":"Ed25519","x":"AA"}`,
+	} {
+		_, err := apputils.ParseJwk([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestJwkThumbprint(t *testing.T) {
+	// Example from RFC 7638 section 3.1
+	n, _ := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}
+	thumbprint, err := apputils.JwkThumbprint(key)
+	require.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+
+	_, err = apputils.JwkThumbprint("invalid")
+	assert.Error(t, err)
+}
+
+func TestParseAcmeJws(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	jws := signTestJws(t, key, "ES256", appdtos.NewAcmeJwsHeaderDTO("", "nonce", "https://x/new-order", nil, "https://x/account/1"), `{"a":1}`)
+	body, _ := json.Marshal(jws)
+
+	parsed, header, payload, err := apputils.ParseAcmeJws(body)
+	require.NoError(t, err)
+	assert.Equal(t, jws, parsed)
+	assert.Equal(t, "ES256", header.Algorithm)
+	assert.Equal(t, "nonce", header.Nonce)
+	assert.Equal(t, "https://x/new-order", header.URL)
+	assert.Equal(t, "https://x/account/1", header.KeyID)
+	assert.Equal(t, `{"a":1}`, string(payload))
+}
+
+func TestParseAcmeJws_Invalid(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	_, _, _, err := apputils.ParseAcmeJws([]byte("not json"))
+	assert.Error(t, err)
+
+	noNonce := signTestJws(t, key, "ES256", appdtos.NewAcmeJwsHeaderDTO("", "", "https://x", nil, "kid"), "")
+	body, _ := json.Marshal(noNonce)
+	_, _, _, err = apputils.ParseAcmeJws(body)
+	var acmeErr *appmodels.AcmeError
+	require.ErrorAs(t, err, &acmeErr)
+	assert.Equal(t, appmodels.ACME_ERROR_BAD_NONCE, acmeErr.Type())
+
+	noKey := signTestJws(t, key, "ES256", appdtos.NewAcmeJwsHeaderDTO("", "nonce", "https://x", nil, ""), "")
+	body, _ = json.Marshal(noKey)
+	_, _, _, err = apputils.ParseAcmeJws(body)
+	assert.Error(t, err)
+}
+
+func TestVerifyAcmeJws(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	header := appdtos.NewAcmeJwsHeaderDTO("", "nonce", "https://x", nil, "kid")
+
+	tests := []struct {
+		key       crypto.Signer
+		algorithm string
+	}{
+		{ecKey, "ES256"},
+		{rsaKey, "RS256"},
+		{edKey, "EdDSA"},
+	}
+	for _, tt := range tests {
+		jws := signTestJws(t, tt.key, tt.algorithm, header, "{}")
+		assert.NoError(t, apputils.VerifyAcmeJws(jws, tt.algorithm, tt.key.Public()), tt.algorithm)
+
+		tampered := jws
+		tampered.Payload = base64.RawURLEncoding.EncodeToString([]byte(`{"x":1}`))
+		assert.Error(t, apputils.VerifyAcmeJws(tampered, tt.algorithm, tt.key.Public()), tt.algorithm)
+	}
+
+	jws := signTestJws(t, ecKey, "ES256", header, "{}")
+	err := apputils.VerifyAcmeJws(jws, "RS256", &ecKey.PublicKey)
+	var acmeErr *appmodels.AcmeError
+	require.ErrorAs(t, err, &acmeErr)
+	assert.Equal(t, appmodels.ACME_ERROR_BAD_SIGNATURE_ALGORITHM, acmeErr.Type())
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// ParseKeyRetentionPolicy parses a key retention policy name like "ESCROW".
+// An empty string returns appmodels.NIL_KEY_RETENTION_POLICY.
+func ParseKeyRetentionPolicy(value string) (appmodels.KeyRetentionPolicy, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return appmodels.NIL_KEY_RETENTION_POLICY, nil
+	}
+	for p := appmodels.KEY_RETENTION_NONE; p <= appmodels.KEY_RETENTION_RETAIN; p++ {
+		if strings.EqualFold(p.String(), value) {
+			return p, nil
+		}
+	}
+	return appmodels.NIL_KEY_RETENTION_POLICY, fmt.Errorf("ParseKeyRetentionPolicy: unsupported: %s", value)
+}
+
+// KeyRetentionPolicyToString returns the name of the key retention policy, or
+// an empty string for appmodels.NIL_KEY_RETENTION_POLICY
+func KeyRetentionPolicyToString(p appmodels.KeyRetentionPolicy) string {
+	if p == appmodels.NIL_KEY_RETENTION_POLICY {
+		return ""
+	}
+	return p.String()
+}
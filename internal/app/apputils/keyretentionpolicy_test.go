@@ -0,0 +1,41 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+func TestParseKeyRetentionPolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    appmodels.KeyRetentionPolicy
+		wantErr bool
+	}{
+		{"", appmodels.NIL_KEY_RETENTION_POLICY, false},
+		{"NONE", appmodels.KEY_RETENTION_NONE, false},
+		{"escrow", appmodels.KEY_RETENTION_ESCROW, false},
+		{" Retain ", appmodels.KEY_RETENTION_RETAIN, false},
+		{"forever", appmodels.NIL_KEY_RETENTION_POLICY, true},
+	}
+
+	for _, tt := range tests {
+		got, err := apputils.ParseKeyRetentionPolicy(tt.value)
+		if tt.wantErr {
+			assert.Error(t, err, tt.value)
+		} else {
+			assert.NoError(t, err, tt.value)
+		}
+		assert.Equal(t, tt.want, got, tt.value)
+	}
+}
+
+func TestKeyRetentionPolicyToString(t *testing.T) {
+	assert.Equal(t, "", apputils.KeyRetentionPolicyToString(appmodels.NIL_KEY_RETENTION_POLICY))
+	assert.Equal(t, "RETAIN", apputils.KeyRetentionPolicyToString(appmodels.KEY_RETENTION_RETAIN))
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+	"github.com/hyperifyio/gocertcenter/internal/common/shamirutils"
+)
+
+// KeyShareVersion is the newest supported format version of private key
+// shares
+const KeyShareVersion = 1
+
+// KeySharePemType is the PEM block type of private key shares. The
+// metadata of the share is in the headers of the block.
+const KeySharePemType = "PRIVATE KEY SHARE"
+
+// SplitPrivateKey splits the PKCS #8 encoding of a private key into shares
+// of which threshold shares reconstruct the key
+//   - certManager: The certificate manager
+//   - key: The private key
+//   - shares: The number of shares, from threshold to 255
+//   - threshold: The number of shares needed to reconstruct, at least 2
+func SplitPrivateKey(
+	certManager managers.CertificateManager,
+	key appmodels.PrivateKey,
+	shares int,
+	threshold int,
+) ([]appmodels.PrivateKeyShare, error) {
+	if _, ok := key.PrivateKey().(appmodels.SealedPrivateKey); ok {
+		return nil, errors.New("SplitPrivateKey: private key is sealed")
+	}
+	der, err := certManager.MarshalPKCS8PrivateKey(key.PrivateKey())
+	if err != nil {
+		return nil, fmt.Errorf("SplitPrivateKey: failed to marshal: %w", err)
+	}
+	defer clear(der)
+
+	data, err := shamirutils.Split(der, shares, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("SplitPrivateKey: %w", err)
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("SplitPrivateKey: id: %w", err)
+	}
+
+	result := make([]appmodels.PrivateKeyShare, len(data))
+	for i, share := range data {
+		result[i] = appmodels.NewPrivateKeyShare(
+			key.OrganizationID(),
+			key.SerialNumber(),
+			key.KeyType(),
+			hex.EncodeToString(id),
+			i+1,
+			shares,
+			threshold,
+			share,
+		)
+	}
+	return result, nil
+}
+
+// CombinePrivateKeyShares reconstructs the private key of a certificate
+// from shares created by SplitPrivateKey. The shares must be from the same
+// split, and the key must belong to the certificate.
+//   - certManager: The certificate manager
+//   - certificate: The certificate of the key
+//   - shares: At least threshold shares
+func CombinePrivateKeyShares(
+	certManager managers.CertificateManager,
+	certificate appmodels.Certificate,
+	shares []appmodels.PrivateKeyShare,
+) (appmodels.PrivateKey, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("CombinePrivateKeyShares: no shares")
+	}
+	first := shares[0]
+	if first.OrganizationID().Cmp(certificate.OrganizationID()) != 0 || first.SerialNumber().Cmp(certificate.SerialNumber()) != 0 {
+		return nil, fmt.Errorf("CombinePrivateKeyShares: shares are for certificate %s of organization %s", first.SerialNumber(), first.OrganizationID())
+	}
+
+	data := make([][]byte, len(shares))
+	seen := make(map[int]bool, len(shares))
+	for i, share := range shares {
+		if share.OrganizationID().Cmp(first.OrganizationID()) != 0 ||
+			share.SerialNumber().Cmp(first.SerialNumber()) != 0 ||
+			share.KeyType() != first.KeyType() ||
+			share.SplitID() != first.SplitID() ||
+			share.Shares() != first.Shares() ||
+			share.Threshold() != first.Threshold() {
+			return nil, fmt.Errorf("CombinePrivateKeyShares: share %d is from a different split", share.Index())
+		}
+		if seen[share.Index()] {
+			return nil, fmt.Errorf("CombinePrivateKeyShares: share %d is included twice", share.Index())
+		}
+		seen[share.Index()] = true
+		data[i] = share.Data()
+	}
+	if len(shares) < first.Threshold() {
+		return nil, fmt.Errorf("CombinePrivateKeyShares: %d of %d shares provided", len(shares), first.Threshold())
+	}
+
+	der, err := shamirutils.Combine(data)
+	if err != nil {
+		return nil, fmt.Errorf("CombinePrivateKeyShares: %w", err)
+	}
+	defer clear(der)
+	privateKey, keyType, err := ParsePKCS8PrivateKey(certManager, der)
+	if err != nil {
+		return nil, fmt.Errorf("CombinePrivateKeyShares: %w", err)
+	}
+	if keyType != first.KeyType() {
+		return nil, fmt.Errorf("CombinePrivateKeyShares: key type %s is not %s", keyType, first.KeyType())
+	}
+	if !publicKeyMatches(certificate.Certificate().PublicKey, privateKey) {
+		return nil, errors.New("CombinePrivateKeyShares: private key does not belong to the certificate")
+	}
+	return appmodels.NewPrivateKey(certificate.OrganizationID(), certificate.SerialNumber(), keyType, privateKey), nil
+}
+
+// MarshalPrivateKeyShareAsPEM converts a private key share to PEM data
+// bytes. The metadata is written as headers with a checksum over the
+// metadata and the share.
+func MarshalPrivateKeyShareAsPEM(
+	certManager managers.CertificateManager,
+	share appmodels.PrivateKeyShare,
+) ([]byte, error) {
+	headers := privateKeyShareHeaders(share)
+	headers["Checksum"] = privateKeyShareChecksum(headers, share.Data())
+	data := certManager.EncodePEMToMemory(&pem.Block{
+		Type:    KeySharePemType,
+		Headers: headers,
+		Bytes:   share.Data(),
+	})
+	if data == nil {
+		return nil, errors.New("MarshalPrivateKeyShareAsPEM: could not encode to PEM")
+	}
+	return data, nil
+}
+
+// ParsePrivateKeySharesFromPEMBytes parses every private key share of PEM
+// data bytes. Text around the PEM blocks is ignored, so shares can be
+// concatenated.
+func ParsePrivateKeySharesFromPEMBytes(
+	certManager managers.CertificateManager,
+	data []byte,
+) ([]appmodels.PrivateKeyShare, error) {
+	var shares []appmodels.PrivateKeyShare
+	for {
+		block, rest := certManager.DecodePEM(data)
+		if block == nil {
+			break
+		}
+		share, err := ParsePrivateKeyShareFromPEMBlock(block)
+		if err != nil {
+			return nil, fmt.Errorf("ParsePrivateKeySharesFromPEMBytes: share %d: %w", len(shares)+1, err)
+		}
+		shares = append(shares, share)
+		data = rest
+	}
+	if len(shares) == 0 {
+		return nil, errors.New("ParsePrivateKeySharesFromPEMBytes: failed to decode PEM block containing a private key share")
+	}
+	return shares, nil
+}
+
+// ParsePrivateKeyShareFromPEMBlock parses a private key share and verifies
+// its checksum
+func ParsePrivateKeyShareFromPEMBlock(block *pem.Block) (appmodels.PrivateKeyShare, error) {
+	if block.Type != KeySharePemType {
+		return nil, fmt.Errorf("unsupported block type: %s", block.Type)
+	}
+	if block.Headers["Version"] != strconv.Itoa(KeyShareVersion) {
+		return nil, fmt.Errorf("unsupported version: %s", block.Headers["Version"])
+	}
+
+	organization, err := ParseBigInt(block.Headers["Organization"], 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid organization: %w", err)
+	}
+	serialNumber, err := ParseBigInt(block.Headers["Certificate"], 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	keyType, err := ParseKeyType(block.Headers["Key-Type"])
+	if err != nil || keyType == appmodels.NIL_KEY_TYPE {
+		return nil, fmt.Errorf("invalid key type: %s", block.Headers["Key-Type"])
+	}
+	if id, err := hex.DecodeString(block.Headers["Split-Id"]); err != nil || len(id) != 16 {
+		return nil, fmt.Errorf("invalid split ID: %s", block.Headers["Split-Id"])
+	}
+	index, indexErr := strconv.Atoi(block.Headers["Share"])
+	shares, sharesErr := strconv.Atoi(block.Headers["Shares"])
+	threshold, thresholdErr := strconv.Atoi(block.Headers["Threshold"])
+	if indexErr != nil || sharesErr != nil || thresholdErr != nil ||
+		threshold < 2 || shares < threshold || shares > shamirutils.MaxShares || index < 1 || index > shares {
+		return nil, fmt.Errorf("invalid share %s of %s with threshold %s", block.Headers["Share"], block.Headers["Shares"], block.Headers["Threshold"])
+	}
+	if len(block.Bytes) < 2 || int(block.Bytes[len(block.Bytes)-1]) != index {
+		return nil, errors.New("invalid share data")
+	}
+
+	share := appmodels.NewPrivateKeyShare(
+		organization,
+		serialNumber,
+		keyType,
+		block.Headers["Split-Id"],
+		index,
+		shares,
+		threshold,
+		block.Bytes,
+	)
+	checksum := privateKeyShareChecksum(privateKeyShareHeaders(share), share.Data())
+	if subtle.ConstantTimeCompare([]byte(checksum), []byte(block.Headers["Checksum"])) != 1 {
+		return nil, errors.New("checksum does not match")
+	}
+	return share, nil
+}
+
+// ToKeySharesDTO converts the shares of one split to a DTO with PEM
+// encoded shares
+func ToKeySharesDTO(
+	certManager managers.CertificateManager,
+	shares []appmodels.PrivateKeyShare,
+) (appdtos.KeySharesDTO, error) {
+	if len(shares) == 0 {
+		return appdtos.KeySharesDTO{}, errors.New("ToKeySharesDTO: no shares")
+	}
+	list := make([]string, len(shares))
+	for i, share := range shares {
+		data, err := MarshalPrivateKeyShareAsPEM(certManager, share)
+		if err != nil {
+			return appdtos.KeySharesDTO{}, fmt.Errorf("ToKeySharesDTO: %w", err)
+		}
+		list[i] = string(data)
+	}
+	return appdtos.NewKeySharesDTO(
+		shares[0].OrganizationID().String(),
+		shares[0].SerialNumber().String(),
+		shares[0].KeyType().String(),
+		shares[0].Threshold(),
+		list,
+	), nil
+}
+
+// privateKeyShareHeaders returns the PEM headers of a private key share
+// without the checksum
+func privateKeyShareHeaders(share appmodels.PrivateKeyShare) map[string]string {
+	return map[string]string{
+		"Version":      strconv.Itoa(KeyShareVersion),
+		"Organization": share.OrganizationID().String(),
+		"Certificate":  share.SerialNumber().String(),
+		"Key-Type":     share.KeyType().String(),
+		"Split-Id":     share.SplitID(),
+		"Share":        strconv.Itoa(share.Index()),
+		"Shares":       strconv.Itoa(share.Shares()),
+		"Threshold":    strconv.Itoa(share.Threshold()),
+	}
+}
+
+// privateKeyShareChecksum returns a SHA-256 checksum of the headers and the
+// share, which detects shares damaged when stored or copied
+func privateKeyShareChecksum(headers map[string]string, data []byte) string {
+	hash := sha256.New()
+	for _, name := range []string{"Version", "Organization", "Certificate", "Key-Type", "Split-Id", "Share", "Shares", "Threshold"} {
+		hash.Write([]byte(name + ": " + headers[name] + "\n"))
+	}
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil))
+}
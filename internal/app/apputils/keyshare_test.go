@@ -0,0 +1,123 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"bytes"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestSplitPrivateKey(t *testing.T) {
+	for _, keyType := range []appmodels.KeyType{appmodels.RSA_2048, appmodels.ECDSA_P384, appmodels.Ed25519} {
+		t.Run(keyType.String(), func(t *testing.T) {
+			certManager := managers.NewCertificateManager(managers.NewRandomManager())
+			backup, root, rootKey := newTestBackup(t, keyType)
+
+			shares, err := apputils.SplitPrivateKey(certManager, rootKey, 5, 3)
+			require.NoError(t, err)
+			require.Len(t, shares, 5)
+
+			// Shares are combined after a round trip through PEM
+			var data []byte
+			for _, share := range shares {
+				pemData, err := apputils.MarshalPrivateKeyShareAsPEM(certManager, share)
+				require.NoError(t, err)
+				data = append(data, pemData...)
+			}
+			parsed, err := apputils.ParsePrivateKeySharesFromPEMBytes(certManager, data)
+			require.NoError(t, err)
+			require.Equal(t, shares, parsed)
+
+			key, err := apputils.CombinePrivateKeyShares(certManager, root, []appmodels.PrivateKeyShare{parsed[4], parsed[1], parsed[2]})
+			require.NoError(t, err)
+			assert.Equal(t, rootKey.PrivateKey(), key.PrivateKey())
+			assert.Equal(t, keyType, key.KeyType())
+			assert.Equal(t, root.OrganizationID(), key.OrganizationID())
+			assert.Equal(t, root.SerialNumber(), key.SerialNumber())
+
+			_, err = apputils.CombinePrivateKeyShares(certManager, root, parsed[:2])
+			assert.ErrorContains(t, err, "2 of 3 shares")
+			_, err = apputils.CombinePrivateKeyShares(certManager, root, []appmodels.PrivateKeyShare{parsed[0], parsed[1], parsed[1]})
+			assert.ErrorContains(t, err, "twice")
+			_, err = apputils.CombinePrivateKeyShares(certManager, backup.Certificates()[1], parsed)
+			assert.ErrorContains(t, err, "shares are for certificate")
+
+			// Shares of another split of the same key cannot be mixed
+			other, err := apputils.SplitPrivateKey(certManager, rootKey, 5, 3)
+			require.NoError(t, err)
+			_, err = apputils.CombinePrivateKeyShares(certManager, root, []appmodels.PrivateKeyShare{parsed[0], parsed[1], other[2]})
+			assert.ErrorContains(t, err, "different split")
+
+			dto, err := apputils.ToKeySharesDTO(certManager, shares)
+			require.NoError(t, err)
+			assert.Equal(t, root.OrganizationID().String(), dto.Organization)
+			assert.Equal(t, root.SerialNumber().String(), dto.Certificate)
+			assert.Equal(t, keyType.String(), dto.Type)
+			assert.Equal(t, 3, dto.Threshold)
+			assert.Len(t, dto.Shares, 5)
+		})
+	}
+}
+
+func TestSplitPrivateKey_Invalid(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	_, _, rootKey := newTestBackup(t, appmodels.ECDSA_P256)
+
+	_, err := apputils.SplitPrivateKey(certManager, rootKey, 5, 1)
+	assert.Error(t, err)
+	_, err = apputils.SplitPrivateKey(certManager, rootKey, 2, 3)
+	assert.Error(t, err)
+	_, err = apputils.SplitPrivateKey(certManager, rootKey, 256, 3)
+	assert.Error(t, err)
+
+	sealed := appmodels.NewPrivateKey(rootKey.OrganizationID(), rootKey.SerialNumber(), rootKey.KeyType(), appmodels.NewSealedPrivateKey(rootKey.KeyType(), []byte("ciphertext")))
+	_, err = apputils.SplitPrivateKey(certManager, sealed, 5, 3)
+	assert.ErrorContains(t, err, "sealed")
+}
+
+func TestParsePrivateKeyShareFromPEMBlock_Invalid(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	_, _, rootKey := newTestBackup(t, appmodels.ECDSA_P256)
+	shares, err := apputils.SplitPrivateKey(certManager, rootKey, 3, 2)
+	require.NoError(t, err)
+	data, err := apputils.MarshalPrivateKeyShareAsPEM(certManager, shares[1])
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(block *pem.Block)
+		error  string
+	}{
+		{"type", func(block *pem.Block) { block.Type = "PRIVATE KEY" }, "unsupported block type"},
+		{"version", func(block *pem.Block) { block.Headers["Version"] = "2" }, "unsupported version"},
+		{"organization", func(block *pem.Block) { block.Headers["Organization"] = "x" }, "invalid organization"},
+		{"key type", func(block *pem.Block) { block.Headers["Key-Type"] = "" }, "invalid key type"},
+		{"split ID", func(block *pem.Block) { block.Headers["Split-Id"] = "abc" }, "invalid split ID"},
+		{"threshold", func(block *pem.Block) { block.Headers["Threshold"] = "4" }, "invalid share"},
+		{"index", func(block *pem.Block) { block.Headers["Share"] = "1" }, "invalid share data"},
+		{"metadata", func(block *pem.Block) { block.Headers["Shares"] = "4" }, "checksum"},
+		{"data", func(block *pem.Block) { block.Bytes[0] ^= 1 }, "checksum"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block, _ := pem.Decode(data)
+			require.NotNil(t, block)
+			tt.modify(block)
+			_, err := apputils.ParsePrivateKeyShareFromPEMBlock(block)
+			assert.ErrorContains(t, err, tt.error)
+		})
+	}
+
+	_, err = apputils.ParsePrivateKeySharesFromPEMBytes(certManager, []byte("not a share"))
+	assert.Error(t, err)
+	_, err = apputils.ParsePrivateKeySharesFromPEMBytes(certManager, bytes.Replace(data, []byte("Checksum: "), []byte("Checksum: 0"), 1))
+	assert.ErrorContains(t, err, "share 1: checksum")
+}
@@ -13,6 +13,9 @@ func ToOrganizationDTO(o appmodels.Organization) appdtos.OrganizationDTO {
 		o.Slug(),
 		o.Name(),
 		o.Names(),
+		SignatureAlgorithmToString(o.SignatureAlgorithm()),
+		o.SpiffeTrustDomain(),
+		KeyRetentionPolicyToString(o.KeyRetentionPolicy()),
 	)
 }
 
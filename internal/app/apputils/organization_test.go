@@ -18,7 +18,7 @@ func TestGetOrganizationDTO(t *testing.T) {
 	orgID := big.NewInt(123)
 	orgSlug := "org123"
 	names := []string{"Test Org", "Test Org Department"}
-	org := appmodels.NewOrganization(orgID, orgSlug, names)
+	org := appmodels.NewOrganization(orgID, orgSlug, names, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
 
 	dto := apputils.ToOrganizationDTO(org)
 
@@ -61,6 +61,9 @@ func TestToListOfOrganizationDTO(t *testing.T) {
 	org1.On("Name").Return(name1)
 	org1.On("Slug").Return(slug1)
 	org1.On("Names").Return(names1)
+	org1.On("SignatureAlgorithm").Return(appmodels.NIL_SIGNATURE_ALGORITHM)
+	org1.On("SpiffeTrustDomain").Return("")
+	org1.On("KeyRetentionPolicy").Return(appmodels.KEY_RETENTION_NONE)
 
 	orgID2 := big.NewInt(456)
 	name2 := "Test Org 2"
@@ -71,6 +74,9 @@ func TestToListOfOrganizationDTO(t *testing.T) {
 	org2.On("Name").Return(name2)
 	org2.On("Slug").Return(slug2)
 	org2.On("Names").Return(names2)
+	org2.On("SignatureAlgorithm").Return(appmodels.NIL_SIGNATURE_ALGORITHM)
+	org2.On("SpiffeTrustDomain").Return("")
+	org2.On("KeyRetentionPolicy").Return(appmodels.KEY_RETENTION_NONE)
 
 	orgList := []appmodels.Organization{org1, org2}
 
@@ -101,6 +107,9 @@ func TestToOrganizationListDTO(t *testing.T) {
 	org1.On("Name").Return(name1)
 	org1.On("Slug").Return(orgSlug1)
 	org1.On("Names").Return(names1)
+	org1.On("SignatureAlgorithm").Return(appmodels.NIL_SIGNATURE_ALGORITHM)
+	org1.On("SpiffeTrustDomain").Return("")
+	org1.On("KeyRetentionPolicy").Return(appmodels.KEY_RETENTION_NONE)
 
 	orgList := []appmodels.Organization{org1}
 
@@ -0,0 +1,685 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+)
+
+// Pkcs7DataOID is the PKCS #7 data content type
+var Pkcs7DataOID = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+
+// Pkcs7SignedDataOID is the PKCS #7 signed data content type
+var Pkcs7SignedDataOID = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
+// Pkcs7EnvelopedDataOID is the PKCS #7 enveloped data content type
+var Pkcs7EnvelopedDataOID = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 3}
+
+// Pkcs7ContentTypeOID is the content type signed attribute
+var Pkcs7ContentTypeOID = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+
+// Pkcs7MessageDigestOID is the message digest signed attribute
+var Pkcs7MessageDigestOID = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+
+// Content encryption algorithms supported in enveloped data
+var (
+	Pkcs7DesCbcOID     = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 7}
+	Pkcs7DesEde3CbcOID = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	Pkcs7Aes128CbcOID  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	Pkcs7Aes192CbcOID  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	Pkcs7Aes256CbcOID  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+var (
+	pkcs7RsaEncryptionOID   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	pkcs7EcdsaWithSha256OID = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	pkcs7Sha1OID            = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	pkcs7Sha256OID          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	pkcs7Sha384OID          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	pkcs7Sha512OID          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+)
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      pkcs7ContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      asn1.RawValue
+}
+
+type pkcs7IssuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type pkcs7SignerInfo struct {
+	Version                   int
+	IssuerAndSerialNumber     pkcs7IssuerAndSerialNumber
+	DigestAlgorithm           pkix.AlgorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue `asn1:"optional,tag:0"`
+	DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedDigest           []byte
+	UnauthenticatedAttributes asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type pkcs7EnvelopedData struct {
+	Version              int
+	RecipientInfos       asn1.RawValue
+	EncryptedContentInfo pkcs7EncryptedContentInfo
+}
+
+type pkcs7RecipientInfo struct {
+	Version                int
+	IssuerAndSerialNumber  pkcs7IssuerAndSerialNumber
+	KeyEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedKey           []byte
+}
+
+type pkcs7EncryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+// Pkcs7Attribute is an attribute of a PKCS #7 signer
+type Pkcs7Attribute struct {
+	Type asn1.ObjectIdentifier
+
+	// Value is the DER encoded attribute value
+	Value asn1.RawValue
+}
+
+type pkcs7AttributeSet struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+// NewPkcs7Attribute creates an attribute from a value which can be marshaled
+// with encoding/asn1
+func NewPkcs7Attribute(attributeType asn1.ObjectIdentifier, value any) (Pkcs7Attribute, error) {
+	der, err := asn1.Marshal(value)
+	if err != nil {
+		return Pkcs7Attribute{}, fmt.Errorf("NewPkcs7Attribute: %v: %w", attributeType, err)
+	}
+	return Pkcs7Attribute{Type: attributeType, Value: asn1.RawValue{FullBytes: der}}, nil
+}
+
+// Pkcs7SignedMessage is a parsed PKCS #7 SignedData structure with a verified
+// signature
+type Pkcs7SignedMessage struct {
+
+	// Content is the signed content, or nil if it was not included
+	Content []byte
+
+	// Certificates are the certificates included in the message
+	Certificates []*x509.Certificate
+
+	// Signer is the certificate of the signer
+	Signer *x509.Certificate
+
+	// Attributes are the signed attributes
+	Attributes []Pkcs7Attribute
+}
+
+// Attribute returns the value of a signed attribute
+func (m *Pkcs7SignedMessage) Attribute(attributeType asn1.ObjectIdentifier) (asn1.RawValue, bool) {
+	for _, attribute := range m.Attributes {
+		if attribute.Type.Equal(attributeType) {
+			return attribute.Value, true
+		}
+	}
+	return asn1.RawValue{}, false
+}
+
+// EncodePkcs7Certificates encodes certificates as a degenerate "certs-only"
+// PKCS #7 SignedData structure without signers (RFC 2315, RFC 5652)
+func EncodePkcs7Certificates(certificates []*x509.Certificate) ([]byte, error) {
+
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("EncodePkcs7Certificates: certificates: must be defined")
+	}
+
+	der, err := encodePkcs7SignedData(pkcs7SignedData{
+		Version:          1,
+		DigestAlgorithms: pkcs7Set(nil),
+		ContentInfo:      pkcs7ContentInfo{ContentType: Pkcs7DataOID},
+		Certificates:     pkcs7CertificateSet(certificates),
+		SignerInfos:      pkcs7Set(nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7Certificates: %w", err)
+	}
+	return der, nil
+}
+
+// ParsePkcs7Certificates returns the certificates of a PKCS #7 SignedData
+// structure. Signatures are not verified.
+func ParsePkcs7Certificates(der []byte) ([]*x509.Certificate, error) {
+
+	signedData, err := parsePkcs7SignedData(der)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePkcs7Certificates: %w", err)
+	}
+
+	certificates, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePkcs7Certificates: failed to parse certificates: %w", err)
+	}
+	return certificates, nil
+}
+
+// EncodePkcs7SignedData signs content as a PKCS #7 SignedData structure with
+// one signer using SHA-256. The content type and message digest attributes
+// are added to the signed attributes.
+//   - content: The content to sign, or nil to sign an empty detached content
+//   - signer: The certificate of the signer
+//   - privateKey: The RSA or ECDSA private key of the signer
+//   - attributes: Additional signed attributes
+//   - certificates: Certificates to include, usually the signer certificate
+func EncodePkcs7SignedData(
+	content []byte,
+	signer *x509.Certificate,
+	privateKey crypto.Signer,
+	attributes []Pkcs7Attribute,
+	certificates []*x509.Certificate,
+) ([]byte, error) {
+
+	if signer == nil {
+		return nil, fmt.Errorf("EncodePkcs7SignedData: signer: must be defined")
+	}
+
+	if privateKey == nil {
+		return nil, fmt.Errorf("EncodePkcs7SignedData: privateKey: must be defined")
+	}
+
+	var signatureAlgorithm asn1.ObjectIdentifier
+	switch privateKey.Public().(type) {
+	case *rsa.PublicKey:
+		signatureAlgorithm = pkcs7RsaEncryptionOID
+	case *ecdsa.PublicKey:
+		signatureAlgorithm = pkcs7EcdsaWithSha256OID
+	default:
+		return nil, fmt.Errorf("EncodePkcs7SignedData: privateKey: unsupported key type: %T", privateKey.Public())
+	}
+
+	digest := crypto.SHA256.New()
+	digest.Write(content)
+
+	contentType, err := NewPkcs7Attribute(Pkcs7ContentTypeOID, Pkcs7DataOID)
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7SignedData: %w", err)
+	}
+	messageDigest, err := NewPkcs7Attribute(Pkcs7MessageDigestOID, digest.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7SignedData: %w", err)
+	}
+
+	attributeBytes, err := encodePkcs7Attributes(append([]Pkcs7Attribute{contentType, messageDigest}, attributes...))
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7SignedData: %w", err)
+	}
+
+	// The signature is calculated over the attributes encoded as a SET
+	signedAttributes, err := asn1.Marshal(pkcs7Set(attributeBytes))
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7SignedData: failed to marshal attributes: %w", err)
+	}
+	hash := crypto.SHA256.New()
+	hash.Write(signedAttributes)
+	signature, err := privateKey.Sign(rand.Reader, hash.Sum(nil), crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7SignedData: failed to sign: %w", err)
+	}
+
+	digestAlgorithm := pkix.AlgorithmIdentifier{Algorithm: pkcs7Sha256OID, Parameters: asn1.NullRawValue}
+	signerInfo, err := asn1.Marshal(pkcs7SignerInfo{
+		Version: 1,
+		IssuerAndSerialNumber: pkcs7IssuerAndSerialNumber{
+			Issuer:       asn1.RawValue{FullBytes: signer.RawIssuer},
+			SerialNumber: signer.SerialNumber,
+		},
+		DigestAlgorithm:           digestAlgorithm,
+		AuthenticatedAttributes:   asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attributeBytes},
+		DigestEncryptionAlgorithm: pkix.AlgorithmIdentifier{Algorithm: signatureAlgorithm},
+		EncryptedDigest:           signature,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7SignedData: failed to marshal signer: %w", err)
+	}
+
+	digestAlgorithmBytes, err := asn1.Marshal(digestAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7SignedData: failed to marshal digest algorithm: %w", err)
+	}
+
+	contentInfo := pkcs7ContentInfo{ContentType: Pkcs7DataOID}
+	if content != nil {
+		octets, err := asn1.Marshal(content)
+		if err != nil {
+			return nil, fmt.Errorf("EncodePkcs7SignedData: failed to marshal content: %w", err)
+		}
+		contentInfo.Content = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: octets}
+	}
+
+	der, err := encodePkcs7SignedData(pkcs7SignedData{
+		Version:          1,
+		DigestAlgorithms: pkcs7Set(digestAlgorithmBytes),
+		ContentInfo:      contentInfo,
+		Certificates:     pkcs7CertificateSet(certificates),
+		SignerInfos:      pkcs7Set(signerInfo),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7SignedData: %w", err)
+	}
+	return der, nil
+}
+
+// ParsePkcs7SignedMessage parses a PKCS #7 SignedData structure and verifies
+// the signature of its first signer. The signer certificate must be included
+// in the message. SHA-1 is accepted for legacy clients.
+func ParsePkcs7SignedMessage(der []byte) (*Pkcs7SignedMessage, error) {
+
+	signedData, err := parsePkcs7SignedData(der)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePkcs7SignedMessage: %w", err)
+	}
+
+	certificates, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePkcs7SignedMessage: failed to parse certificates: %w", err)
+	}
+
+	message := &Pkcs7SignedMessage{Certificates: certificates}
+
+	if len(signedData.ContentInfo.Content.Bytes) != 0 {
+		if _, err := asn1.Unmarshal(signedData.ContentInfo.Content.Bytes, &message.Content); err != nil {
+			return nil, fmt.Errorf("ParsePkcs7SignedMessage: failed to parse content: %w", err)
+		}
+	}
+
+	var signerInfo pkcs7SignerInfo
+	if _, err := asn1.Unmarshal(signedData.SignerInfos.Bytes, &signerInfo); err != nil {
+		return nil, fmt.Errorf("ParsePkcs7SignedMessage: failed to parse signer: %w", err)
+	}
+
+	for _, certificate := range certificates {
+		if certificate.SerialNumber.Cmp(signerInfo.IssuerAndSerialNumber.SerialNumber) == 0 &&
+			bytes.Equal(certificate.RawIssuer, signerInfo.IssuerAndSerialNumber.Issuer.FullBytes) {
+			message.Signer = certificate
+		}
+	}
+	if message.Signer == nil {
+		return nil, fmt.Errorf("ParsePkcs7SignedMessage: signer certificate not found")
+	}
+
+	hashType, err := pkcs7HashType(signerInfo.DigestAlgorithm.Algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePkcs7SignedMessage: %w", err)
+	}
+
+	signed := message.Content
+	if len(signerInfo.AuthenticatedAttributes.Bytes) != 0 {
+
+		rest := signerInfo.AuthenticatedAttributes.Bytes
+		for len(rest) != 0 {
+			var attribute pkcs7AttributeSet
+			if rest, err = asn1.Unmarshal(rest, &attribute); err != nil {
+				return nil, fmt.Errorf("ParsePkcs7SignedMessage: failed to parse attribute: %w", err)
+			}
+			var value asn1.RawValue
+			if _, err := asn1.Unmarshal(attribute.Values.Bytes, &value); err != nil {
+				return nil, fmt.Errorf("ParsePkcs7SignedMessage: failed to parse attribute %v: %w", attribute.Type, err)
+			}
+			message.Attributes = append(message.Attributes, Pkcs7Attribute{Type: attribute.Type, Value: value})
+		}
+
+		digestValue, exists := message.Attribute(Pkcs7MessageDigestOID)
+		if !exists {
+			return nil, fmt.Errorf("ParsePkcs7SignedMessage: message digest attribute missing")
+		}
+		digest := hashType.New()
+		digest.Write(message.Content)
+		if !bytes.Equal(digestValue.Bytes, digest.Sum(nil)) {
+			return nil, fmt.Errorf("ParsePkcs7SignedMessage: message digest does not match")
+		}
+
+		// The signature is calculated over the attributes encoded as a SET
+		signed, err = asn1.Marshal(pkcs7Set(signerInfo.AuthenticatedAttributes.Bytes))
+		if err != nil {
+			return nil, fmt.Errorf("ParsePkcs7SignedMessage: failed to marshal attributes: %w", err)
+		}
+	}
+
+	hash := hashType.New()
+	hash.Write(signed)
+	hashed := hash.Sum(nil)
+
+	switch publicKey := message.Signer.PublicKey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(publicKey, hashType, hashed, signerInfo.EncryptedDigest)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(publicKey, hashed, signerInfo.EncryptedDigest) {
+			err = fmt.Errorf("ecdsa verification failed")
+		}
+	default:
+		err = fmt.Errorf("unsupported key type: %T", publicKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ParsePkcs7SignedMessage: invalid signature: %w", err)
+	}
+
+	return message, nil
+}
+
+// EncodePkcs7EnvelopedData encrypts content for an RSA recipient as a
+// PKCS #7 EnvelopedData structure
+//   - content: The content to encrypt
+//   - recipient: The certificate of the recipient
+//   - algorithm: The content encryption algorithm, e.g. Pkcs7Aes256CbcOID
+func EncodePkcs7EnvelopedData(content []byte, recipient *x509.Certificate, algorithm asn1.ObjectIdentifier) ([]byte, error) {
+
+	if recipient == nil {
+		return nil, fmt.Errorf("EncodePkcs7EnvelopedData: recipient: must be defined")
+	}
+
+	publicKey, ok := recipient.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("EncodePkcs7EnvelopedData: recipient: unsupported key type: %T", recipient.PublicKey)
+	}
+
+	keySize, newCipher, err := pkcs7Cipher(algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7EnvelopedData: %w", err)
+	}
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("EncodePkcs7EnvelopedData: failed to create key: %w", err)
+	}
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7EnvelopedData: %w", err)
+	}
+	iv := make([]byte, block.BlockSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("EncodePkcs7EnvelopedData: failed to create iv: %w", err)
+	}
+
+	padding := block.BlockSize() - len(content)%block.BlockSize()
+	encrypted := append(bytes.Clone(content), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+
+	encryptedKey, err := rsa.EncryptPKCS1v15(rand.Reader, publicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7EnvelopedData: failed to encrypt key: %w", err)
+	}
+
+	recipientInfo, err := asn1.Marshal(pkcs7RecipientInfo{
+		Version: 0,
+		IssuerAndSerialNumber: pkcs7IssuerAndSerialNumber{
+			Issuer:       asn1.RawValue{FullBytes: recipient.RawIssuer},
+			SerialNumber: recipient.SerialNumber,
+		},
+		KeyEncryptionAlgorithm: pkix.AlgorithmIdentifier{Algorithm: pkcs7RsaEncryptionOID, Parameters: asn1.NullRawValue},
+		EncryptedKey:           encryptedKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7EnvelopedData: failed to marshal recipient: %w", err)
+	}
+
+	envelopedData, err := asn1.Marshal(pkcs7EnvelopedData{
+		Version:        0,
+		RecipientInfos: pkcs7Set(recipientInfo),
+		EncryptedContentInfo: pkcs7EncryptedContentInfo{
+			ContentType: Pkcs7DataOID,
+			ContentEncryptionAlgorithm: pkix.AlgorithmIdentifier{
+				Algorithm:  algorithm,
+				Parameters: asn1.RawValue{Tag: asn1.TagOctetString, Bytes: iv},
+			},
+			EncryptedContent: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: encrypted},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7EnvelopedData: failed to marshal enveloped data: %w", err)
+	}
+
+	der, err := asn1.Marshal(pkcs7ContentInfo{
+		ContentType: Pkcs7EnvelopedDataOID,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: envelopedData},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("EncodePkcs7EnvelopedData: failed to marshal content info: %w", err)
+	}
+	return der, nil
+}
+
+// ErrPkcs7Decrypt is returned for any failure to decrypt the key or the
+// content of enveloped data. The causes are not told apart so that the errors
+// cannot be used as a padding oracle of the private key.
+var ErrPkcs7Decrypt = errors.New("DecryptPkcs7EnvelopedData: failed to decrypt")
+
+// DecryptPkcs7EnvelopedData decrypts a PKCS #7 EnvelopedData structure
+// addressed to the certificate. It returns the content and the content
+// encryption algorithm.
+//   - der: The enveloped data
+//   - certificate: The certificate of the recipient
+//   - privateKey: The RSA private key of the recipient
+func DecryptPkcs7EnvelopedData(der []byte, certificate *x509.Certificate, privateKey *rsa.PrivateKey) ([]byte, asn1.ObjectIdentifier, error) {
+
+	if certificate == nil || privateKey == nil {
+		return nil, nil, fmt.Errorf("DecryptPkcs7EnvelopedData: certificate and privateKey must be defined")
+	}
+
+	var contentInfo pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(der, &contentInfo); err != nil {
+		return nil, nil, fmt.Errorf("DecryptPkcs7EnvelopedData: failed to parse content info: %w", err)
+	}
+	if !contentInfo.ContentType.Equal(Pkcs7EnvelopedDataOID) {
+		return nil, nil, fmt.Errorf("DecryptPkcs7EnvelopedData: unsupported content type: %v", contentInfo.ContentType)
+	}
+
+	var envelopedData pkcs7EnvelopedData
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &envelopedData); err != nil {
+		return nil, nil, fmt.Errorf("DecryptPkcs7EnvelopedData: failed to parse enveloped data: %w", err)
+	}
+
+	var encryptedKey []byte
+	rest := envelopedData.RecipientInfos.Bytes
+	for len(rest) != 0 {
+		var recipientInfo pkcs7RecipientInfo
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &recipientInfo); err != nil {
+			return nil, nil, fmt.Errorf("DecryptPkcs7EnvelopedData: failed to parse recipient: %w", err)
+		}
+		if recipientInfo.IssuerAndSerialNumber.SerialNumber.Cmp(certificate.SerialNumber) == 0 &&
+			bytes.Equal(recipientInfo.IssuerAndSerialNumber.Issuer.FullBytes, certificate.RawIssuer) {
+			encryptedKey = recipientInfo.EncryptedKey
+		}
+	}
+	if encryptedKey == nil {
+		return nil, nil, fmt.Errorf("DecryptPkcs7EnvelopedData: not encrypted for the certificate")
+	}
+
+	encryptedContentInfo := envelopedData.EncryptedContentInfo
+	algorithm := encryptedContentInfo.ContentEncryptionAlgorithm.Algorithm
+	keySize, newCipher, err := pkcs7Cipher(algorithm)
+	if err != nil {
+		return nil, nil, fmt.Errorf("DecryptPkcs7EnvelopedData: %w", err)
+	}
+
+	// An invalid encrypted key leaves the random key in place, so that it
+	// fails like invalid content instead of revealing the RSA padding
+	// (Bleichenbacher). Failures after this point are not told apart.
+	key := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, nil, fmt.Errorf("DecryptPkcs7EnvelopedData: failed to generate key: %w", err)
+	}
+	if err := rsa.DecryptPKCS1v15SessionKey(rand.Reader, privateKey, encryptedKey, key); err != nil {
+		return nil, nil, ErrPkcs7Decrypt
+	}
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, nil, ErrPkcs7Decrypt
+	}
+
+	iv := encryptedContentInfo.ContentEncryptionAlgorithm.Parameters.Bytes
+	if len(iv) != block.BlockSize() {
+		return nil, nil, ErrPkcs7Decrypt
+	}
+
+	encrypted, err := pkcs7OctetString(encryptedContentInfo.EncryptedContent)
+	if err != nil || len(encrypted) == 0 || len(encrypted)%block.BlockSize() != 0 {
+		return nil, nil, ErrPkcs7Decrypt
+	}
+
+	content := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(content, encrypted)
+
+	padding := int(content[len(content)-1])
+	if padding == 0 || padding > block.BlockSize() || subtle.ConstantTimeCompare(content[len(content)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) != 1 {
+		return nil, nil, ErrPkcs7Decrypt
+	}
+
+	return content[:len(content)-padding], algorithm, nil
+}
+
+func encodePkcs7SignedData(signedData pkcs7SignedData) ([]byte, error) {
+	content, err := asn1.Marshal(signedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal signed data: %w", err)
+	}
+	der, err := asn1.Marshal(pkcs7ContentInfo{
+		ContentType: Pkcs7SignedDataOID,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal content info: %w", err)
+	}
+	return der, nil
+}
+
+func parsePkcs7SignedData(der []byte) (pkcs7SignedData, error) {
+	var contentInfo pkcs7ContentInfo
+	if rest, err := asn1.Unmarshal(der, &contentInfo); err != nil {
+		return pkcs7SignedData{}, fmt.Errorf("failed to parse content info: %w", err)
+	} else if len(rest) != 0 {
+		return pkcs7SignedData{}, fmt.Errorf("trailing data")
+	}
+	if !contentInfo.ContentType.Equal(Pkcs7SignedDataOID) {
+		return pkcs7SignedData{}, fmt.Errorf("unsupported content type: %v", contentInfo.ContentType)
+	}
+	var signedData pkcs7SignedData
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		return pkcs7SignedData{}, fmt.Errorf("failed to parse signed data: %w", err)
+	}
+	return signedData, nil
+}
+
+// pkcs7Set returns a SET containing already encoded elements
+func pkcs7Set(elements []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: elements}
+}
+
+// pkcs7CertificateSet returns the implicitly tagged certificates field
+func pkcs7CertificateSet(certificates []*x509.Certificate) asn1.RawValue {
+	if len(certificates) == 0 {
+		return asn1.RawValue{}
+	}
+	var raw []byte
+	for _, certificate := range certificates {
+		raw = append(raw, certificate.Raw...)
+	}
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: raw}
+}
+
+// encodePkcs7Attributes encodes attributes in the DER order of a SET OF
+func encodePkcs7Attributes(attributes []Pkcs7Attribute) ([]byte, error) {
+	encoded := make([][]byte, len(attributes))
+	for i, attribute := range attributes {
+		der, err := asn1.Marshal(pkcs7AttributeSet{
+			Type:   attribute.Type,
+			Values: pkcs7Set(attribute.Value.FullBytes),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal attribute %v: %w", attribute.Type, err)
+		}
+		encoded[i] = der
+	}
+	sort.Slice(encoded, func(i, j int) bool {
+		return bytes.Compare(encoded[i], encoded[j]) < 0
+	})
+	return bytes.Join(encoded, nil), nil
+}
+
+// pkcs7OctetString returns the content of a primitive or a constructed
+// OCTET STRING
+func pkcs7OctetString(value asn1.RawValue) ([]byte, error) {
+	if !value.IsCompound {
+		return value.Bytes, nil
+	}
+	var content []byte
+	rest := value.Bytes
+	for len(rest) != 0 {
+		var chunk asn1.RawValue
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &chunk); err != nil {
+			return nil, fmt.Errorf("failed to parse content: %w", err)
+		}
+		content = append(content, chunk.Bytes...)
+	}
+	return content, nil
+}
+
+func pkcs7HashType(algorithm asn1.ObjectIdentifier) (crypto.Hash, error) {
+	switch {
+	case algorithm.Equal(pkcs7Sha1OID):
+		return crypto.SHA1, nil
+	case algorithm.Equal(pkcs7Sha256OID):
+		return crypto.SHA256, nil
+	case algorithm.Equal(pkcs7Sha384OID):
+		return crypto.SHA384, nil
+	case algorithm.Equal(pkcs7Sha512OID):
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported digest algorithm: %v", algorithm)
+	}
+}
+
+func pkcs7Cipher(algorithm asn1.ObjectIdentifier) (int, func(key []byte) (cipher.Block, error), error) {
+	switch {
+	case algorithm.Equal(Pkcs7DesCbcOID):
+		return 8, des.NewCipher, nil
+	case algorithm.Equal(Pkcs7DesEde3CbcOID):
+		return 24, des.NewTripleDESCipher, nil
+	case algorithm.Equal(Pkcs7Aes128CbcOID):
+		return 16, aes.NewCipher, nil
+	case algorithm.Equal(Pkcs7Aes192CbcOID):
+		return 24, aes.NewCipher, nil
+	case algorithm.Equal(Pkcs7Aes256CbcOID):
+		return 32, aes.NewCipher, nil
+	default:
+		return 0, nil, fmt.Errorf("unsupported content encryption algorithm: %v", algorithm)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+func newTestSelfSignedCertificate(t *testing.T, commonName string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return certificate
+}
+
+func TestEncodePkcs7Certificates(t *testing.T) {
+	first := newTestSelfSignedCertificate(t, "first")
+	second := newTestSelfSignedCertificate(t, "second")
+
+	der, err := apputils.EncodePkcs7Certificates([]*x509.Certificate{first, second})
+	require.NoError(t, err)
+
+	certificates, err := apputils.ParsePkcs7Certificates(der)
+	require.NoError(t, err)
+	require.Len(t, certificates, 2)
+	assert.Equal(t, first.Raw, certificates[0].Raw)
+	assert.Equal(t, second.Raw, certificates[1].Raw)
+
+	_, err = apputils.EncodePkcs7Certificates(nil)
+	assert.Error(t, err)
+}
+
+func TestParsePkcs7Certificates_Invalid(t *testing.T) {
+	_, err := apputils.ParsePkcs7Certificates([]byte("invalid"))
+	assert.Error(t, err)
+
+	der, _ := asn1.Marshal(struct{ ContentType asn1.ObjectIdentifier }{apputils.Pkcs7DataOID})
+	_, err = apputils.ParsePkcs7Certificates(der)
+	assert.Error(t, err)
+}
+
+func newTestRsaCertificate(t *testing.T, commonName string) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return certificate, key
+}
+
+func TestEncodePkcs7SignedData(t *testing.T) {
+	certificate, key := newTestRsaCertificate(t, "signer")
+	ecCertificate := newTestSelfSignedCertificate(t, "ec")
+
+	attribute, err := apputils.NewPkcs7Attribute(asn1.ObjectIdentifier{1, 2, 3}, "value")
+	require.NoError(t, err)
+
+	der, err := apputils.EncodePkcs7SignedData([]byte("content"), certificate, key, []apputils.Pkcs7Attribute{attribute}, []*x509.Certificate{ecCertificate, certificate})
+	require.NoError(t, err)
+
+	message, err := apputils.ParsePkcs7SignedMessage(der)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("content"), message.Content)
+	assert.Equal(t, certificate.Raw, message.Signer.Raw)
+	assert.Len(t, message.Certificates, 2)
+
+	value, exists := message.Attribute(asn1.ObjectIdentifier{1, 2, 3})
+	require.True(t, exists)
+	var text string
+	_, err = asn1.Unmarshal(value.FullBytes, &text)
+	require.NoError(t, err)
+	assert.Equal(t, "value", text)
+
+	_, exists = message.Attribute(asn1.ObjectIdentifier{1, 2, 4})
+	assert.False(t, exists)
+
+	// Tampering with the signature must fail the verification
+	tampered := append([]byte(nil), der...)
+	tampered[len(tampered)-1] ^= 0xff
+	_, err = apputils.ParsePkcs7SignedMessage(tampered)
+	assert.Error(t, err)
+}
+
+func TestEncodePkcs7SignedData_Ecdsa(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "ec signer"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+
+	der, err := apputils.EncodePkcs7SignedData(nil, certificate, key, nil, []*x509.Certificate{certificate})
+	require.NoError(t, err)
+
+	message, err := apputils.ParsePkcs7SignedMessage(der)
+	require.NoError(t, err)
+	assert.Empty(t, message.Content)
+	assert.Equal(t, certificate.Raw, message.Signer.Raw)
+}
+
+func TestEncodePkcs7SignedData_Invalid(t *testing.T) {
+	certificate, key := newTestRsaCertificate(t, "signer")
+
+	_, err := apputils.EncodePkcs7SignedData(nil, nil, key, nil, nil)
+	assert.Error(t, err)
+
+	_, err = apputils.EncodePkcs7SignedData(nil, certificate, nil, nil, nil)
+	assert.Error(t, err)
+
+	// The signer certificate must be included
+	der, err := apputils.EncodePkcs7SignedData([]byte("content"), certificate, key, nil, nil)
+	require.NoError(t, err)
+	_, err = apputils.ParsePkcs7SignedMessage(der)
+	assert.Error(t, err)
+}
+
+func TestEncodePkcs7EnvelopedData(t *testing.T) {
+	certificate, key := newTestRsaCertificate(t, "recipient")
+	other, otherKey := newTestRsaCertificate(t, "other")
+
+	for _, algorithm := range []asn1.ObjectIdentifier{
+		apputils.Pkcs7DesCbcOID,
+		apputils.Pkcs7DesEde3CbcOID,
+		apputils.Pkcs7Aes128CbcOID,
+		apputils.Pkcs7Aes192CbcOID,
+		apputils.Pkcs7Aes256CbcOID,
+	} {
+		der, err := apputils.EncodePkcs7EnvelopedData([]byte("secret content"), certificate, algorithm)
+		require.NoError(t, err)
+
+		content, contentAlgorithm, err := apputils.DecryptPkcs7EnvelopedData(der, certificate, key)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("secret content"), content)
+		assert.True(t, algorithm.Equal(contentAlgorithm))
+
+		// The serial numbers are equal, but the issuers are not
+		_, _, err = apputils.DecryptPkcs7EnvelopedData(der, other, otherKey)
+		assert.Error(t, err)
+
+		// A wrong key fails like invalid content
+		_, _, err = apputils.DecryptPkcs7EnvelopedData(der, certificate, otherKey)
+		assert.ErrorIs(t, err, apputils.ErrPkcs7Decrypt)
+		_, _, err = apputils.DecryptPkcs7EnvelopedData(der[:len(der)-1], certificate, key)
+		assert.Error(t, err)
+	}
+}
+
+func TestEncodePkcs7EnvelopedData_Invalid(t *testing.T) {
+	certificate, key := newTestRsaCertificate(t, "recipient")
+
+	_, err := apputils.EncodePkcs7EnvelopedData([]byte("content"), nil, apputils.Pkcs7Aes128CbcOID)
+	assert.Error(t, err)
+
+	_, err = apputils.EncodePkcs7EnvelopedData([]byte("content"), newTestSelfSignedCertificate(t, "ec"), apputils.Pkcs7Aes128CbcOID)
+	assert.Error(t, err)
+
+	_, err = apputils.EncodePkcs7EnvelopedData([]byte("content"), certificate, asn1.ObjectIdentifier{1, 2, 3})
+	assert.Error(t, err)
+
+	_, _, err = apputils.DecryptPkcs7EnvelopedData([]byte("invalid"), certificate, key)
+	assert.Error(t, err)
+
+	_, _, err = apputils.DecryptPkcs7EnvelopedData(nil, nil, nil)
+	assert.Error(t, err)
+}
@@ -8,10 +8,13 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
 	"github.com/hyperifyio/gocertcenter/internal/common/managers"
@@ -45,6 +48,21 @@ func ToPrivateKeyDTOList(certManager managers.CertificateManager, list []appmode
 	return result, nil
 }
 
+// PrivateKeyFingerprint returns the hex encoded SHA-256 digest of the PKCS #8
+// encoding of a private key, or of the ciphertext of a sealed private key
+func PrivateKeyFingerprint(certManager managers.CertificateManager, key appmodels.PrivateKey) (string, error) {
+	if sealed, ok := key.PrivateKey().(appmodels.SealedPrivateKey); ok {
+		digest := sha256.Sum256(sealed.Ciphertext())
+		return hex.EncodeToString(digest[:]), nil
+	}
+	der, err := certManager.MarshalPKCS8PrivateKey(key.PrivateKey())
+	if err != nil {
+		return "", fmt.Errorf("PrivateKeyFingerprint: failed to marshal: %w", err)
+	}
+	digest := sha256.Sum256(der)
+	return hex.EncodeToString(digest[:]), nil
+}
+
 // GeneratePrivateKey creates a new private key of type models.KeyType
 //   - organization: The organization ID
 //   - certificates: The certificate signatures from root certificate to the one owning this private key
@@ -158,6 +176,13 @@ func MarshalPrivateKeyAsPEM(
 		}
 		pemBlock = &pem.Block{Type: "PRIVATE KEY", Bytes: edBytes}
 
+	case appmodels.SealedPrivateKey:
+		pemBlock = &pem.Block{
+			Type:    SealedPrivateKeyPemType,
+			Headers: map[string]string{"Key-Type": typedKey.KeyType().String()},
+			Bytes:   typedKey.Ciphertext(),
+		}
+
 	default:
 		return nil, fmt.Errorf("MarshalPrivateKeyAsPEM: unsupported private key type")
 	}
@@ -234,6 +259,47 @@ func DetermineKeyType(privateKey any) (appmodels.KeyType, error) {
 
 }
 
+// DeterminePublicKeyType returns the key type of a public key
+func DeterminePublicKeyType(publicKey any) (appmodels.KeyType, error) {
+	switch key := publicKey.(type) {
+
+	case *rsa.PublicKey:
+		keyType, err := DetermineRSATypeFromSize(key.N.BitLen())
+		if err != nil {
+			return appmodels.NIL_KEY_TYPE, fmt.Errorf("DeterminePublicKeyType: could not detect RSA key type: %w", err)
+		}
+		return keyType, nil
+
+	case *ecdsa.PublicKey:
+		keyType, err := DetermineECDSACurve(key.Curve)
+		if err != nil {
+			return appmodels.NIL_KEY_TYPE, fmt.Errorf("DeterminePublicKeyType: could not detect ecdsa key type: %w", err)
+		}
+		return keyType, nil
+
+	case ed25519.PublicKey:
+		return appmodels.Ed25519, nil
+
+	default:
+		return appmodels.NIL_KEY_TYPE, fmt.Errorf("DeterminePublicKeyType: unknown or unsupported key type")
+	}
+}
+
+// ParseKeyType parses a key type name like "RSA_3072" or "ECDSA_P384". An
+// empty string returns appmodels.NIL_KEY_TYPE.
+func ParseKeyType(value string) (appmodels.KeyType, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return appmodels.NIL_KEY_TYPE, nil
+	}
+	for kt := appmodels.RSA_1024; kt <= appmodels.Ed25519; kt++ {
+		if strings.EqualFold(kt.String(), value) {
+			return kt, nil
+		}
+	}
+	return appmodels.NIL_KEY_TYPE, fmt.Errorf("ParseKeyType: unsupported: %s", value)
+}
+
 func ReadRSAKeySize(key *rsa.PrivateKey) int {
 	if key == nil {
 		return 0
@@ -265,6 +331,8 @@ func ParsePrivateKeyFromPEMBlock(
 		return ParseRSAPrivateKey(certManager, block.Bytes)
 	} else if block.Type == "EC PRIVATE KEY" {
 		return ParseECPrivateKey(certManager, block.Bytes)
+	} else if block.Type == SealedPrivateKeyPemType {
+		return ParseSealedPrivateKey(block)
 	}
 	return nil, appmodels.NIL_KEY_TYPE, fmt.Errorf("ParsePrivateKeyFromPEMBlock: unsupported block type: %s", block.Type)
 }
@@ -281,6 +349,19 @@ func ParsePKCS8PrivateKey(certManager managers.CertificateManager, der []byte) (
 	return privateKey, keyType, nil
 }
 
+// ParseSealedPrivateKey parses a private key encrypted with the seal key
+// without decrypting it
+func ParseSealedPrivateKey(block *pem.Block) (any, appmodels.KeyType, error) {
+	keyType, err := ParseKeyType(block.Headers["Key-Type"])
+	if err != nil {
+		return nil, appmodels.NIL_KEY_TYPE, fmt.Errorf("ParseSealedPrivateKey: %w", err)
+	}
+	if keyType == appmodels.NIL_KEY_TYPE {
+		return nil, appmodels.NIL_KEY_TYPE, errors.New("ParseSealedPrivateKey: no key type")
+	}
+	return appmodels.NewSealedPrivateKey(keyType, block.Bytes), keyType, nil
+}
+
 func ParseRSAPrivateKey(certManager managers.CertificateManager, bytes []byte) (any, appmodels.KeyType, error) {
 	privateKey, err := certManager.ParsePKCS1PrivateKey(bytes)
 	if err != nil {
@@ -596,6 +596,33 @@ func TestDetermineKeyType(t *testing.T) {
 	}
 }
 
+func TestDeterminePublicKeyType(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	if got, err := apputils.DeterminePublicKeyType(&rsaKey.PublicKey); err != nil || got != appmodels.RSA_2048 {
+		t.Errorf("DeterminePublicKeyType() with RSA key got = %v, err = %v; want %v", got, err, appmodels.RSA_2048)
+	}
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate ECDSA key: %v", err)
+	}
+	if got, err := apputils.DeterminePublicKeyType(&ecdsaKey.PublicKey); err != nil || got != appmodels.ECDSA_P384 {
+		t.Errorf("DeterminePublicKeyType() with ECDSA key got = %v, err = %v; want %v", got, err, appmodels.ECDSA_P384)
+	}
+
+	ed25519Key, _, _ := ed25519.GenerateKey(rand.Reader)
+	if got, err := apputils.DeterminePublicKeyType(ed25519Key); err != nil || got != appmodels.Ed25519 {
+		t.Errorf("DeterminePublicKeyType() with Ed25519 key got = %v, err = %v; want %v", got, err, appmodels.Ed25519)
+	}
+
+	if _, err := apputils.DeterminePublicKeyType("this is not a key"); err == nil {
+		t.Errorf("DeterminePublicKeyType() with unsupported key type did not return an error")
+	}
+}
+
 func TestParsePrivateKeyFromPEMBytes(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -957,3 +984,33 @@ func TestParseECPrivateKey_FailCurveDetermination(t *testing.T) {
 	assert.Equal(t, appmodels.NIL_KEY_TYPE, keyType)
 	assert.Contains(t, err.Error(), "could not detect key type")
 }
+
+func TestPrivateKeyFingerprint(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	key, err := apputils.GeneratePrivateKey(big.NewInt(1), big.NewInt(2), appmodels.ECDSA_P256)
+	assert.NoError(t, err)
+	other, err := apputils.GeneratePrivateKey(big.NewInt(1), big.NewInt(2), appmodels.ECDSA_P256)
+	assert.NoError(t, err)
+
+	fingerprint, err := apputils.PrivateKeyFingerprint(certManager, key)
+	assert.NoError(t, err)
+	assert.Len(t, fingerprint, 64)
+
+	again, err := apputils.PrivateKeyFingerprint(certManager, key)
+	assert.NoError(t, err)
+	assert.Equal(t, fingerprint, again)
+
+	otherFingerprint, err := apputils.PrivateKeyFingerprint(certManager, other)
+	assert.NoError(t, err)
+	assert.NotEqual(t, fingerprint, otherFingerprint)
+}
+
+func TestPrivateKeyFingerprint_MarshalError(t *testing.T) {
+	mockCertManager := new(commonmocks.MockCertificateManager)
+	mockCertManager.On("MarshalPKCS8PrivateKey", mock.Anything).Return(nil, fmt.Errorf("marshal failed"))
+	mockKey := new(appmocks.MockPrivateKey)
+	mockKey.On("PrivateKey").Return("key")
+
+	_, err := apputils.PrivateKeyFingerprint(mockCertManager, mockKey)
+	assert.ErrorContains(t, err, "marshal failed")
+}
@@ -0,0 +1,421 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// SCEP signed attributes (RFC 8894 section 3.2.1)
+var (
+	ScepMessageTypeOID    = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 2}
+	ScepPkiStatusOID      = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 3}
+	ScepFailInfoOID       = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 4}
+	ScepSenderNonceOID    = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 5}
+	ScepRecipientNonceOID = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 6}
+	ScepTransactionIDOID  = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 7}
+)
+
+// ScepChallengePasswordOID is the PKCS #9 challenge password attribute
+var ScepChallengePasswordOID = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 7}
+
+// SCEP message types
+const (
+	SCEP_MESSAGE_CERT_REP    = "3"
+	SCEP_MESSAGE_RENEWAL_REQ = "17"
+	SCEP_MESSAGE_PKCS_REQ    = "19"
+)
+
+// SCEP pkiStatus values
+const (
+	SCEP_STATUS_SUCCESS = "0"
+	SCEP_STATUS_FAILURE = "2"
+	SCEP_STATUS_PENDING = "3"
+)
+
+// SCEP failInfo values
+const (
+	SCEP_FAIL_BAD_ALG           = "0"
+	SCEP_FAIL_BAD_MESSAGE_CHECK = "1"
+	SCEP_FAIL_BAD_REQUEST       = "2"
+	SCEP_FAIL_BAD_TIME          = "3"
+	SCEP_FAIL_BAD_CERT_ID       = "4"
+)
+
+// ScepRequest is a parsed SCEP PKIMessage with a verified signature
+type ScepRequest struct {
+	MessageType   string
+	TransactionID string
+	SenderNonce   []byte
+
+	// Signer is the certificate which signed the message. For PKCSReq it is
+	// usually a self-signed certificate of the requested key.
+	Signer *x509.Certificate
+
+	// EnvelopedData is the pkcsPKIEnvelope encrypted for the RA
+	EnvelopedData []byte
+}
+
+// ScepResponse is a parsed SCEP CertRep message
+type ScepResponse struct {
+	TransactionID  string
+	Status         string
+	FailInfo       string
+	RecipientNonce []byte
+	Certificates   []*x509.Certificate
+}
+
+// DecodeScepMessage decodes the base64 encoded message query parameter of a
+// GET PKIOperation. Some clients do not escape the plus sign.
+func DecodeScepMessage(message string) ([]byte, error) {
+	message = strings.NewReplacer(" ", "+", "\r", "", "\n", "").Replace(message)
+	der, err := base64.StdEncoding.DecodeString(message)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeScepMessage: %w", err)
+	}
+	return der, nil
+}
+
+// ScepChallengePassword returns the challenge password attribute of a
+// certificate signing request, or an empty string if it does not have one
+func ScepChallengePassword(csr *x509.CertificateRequest) (string, error) {
+
+	if csr == nil {
+		return "", fmt.Errorf("ScepChallengePassword: csr: must be defined")
+	}
+
+	var tbs scepCertificateRequestInfo
+	if _, err := asn1.Unmarshal(csr.RawTBSCertificateRequest, &tbs); err != nil {
+		return "", fmt.Errorf("ScepChallengePassword: %w", err)
+	}
+
+	for _, attribute := range tbs.Attributes {
+		if !attribute.Type.Equal(ScepChallengePasswordOID) {
+			continue
+		}
+		var value asn1.RawValue
+		if _, err := asn1.Unmarshal(attribute.Values.Bytes, &value); err != nil {
+			return "", fmt.Errorf("ScepChallengePassword: %w", err)
+		}
+		return string(value.Bytes), nil
+	}
+
+	return "", nil
+}
+
+// NewScepCertificateRequest creates a DER encoded certificate signing request
+// with a challenge password attribute
+func NewScepCertificateRequest(template *x509.CertificateRequest, challengePassword string, privateKey *rsa.PrivateKey) ([]byte, error) {
+
+	if template == nil {
+		return nil, fmt.Errorf("NewScepCertificateRequest: template: must be defined")
+	}
+
+	if privateKey == nil {
+		return nil, fmt.Errorf("NewScepCertificateRequest: privateKey: must be defined")
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("NewScepCertificateRequest: %w", err)
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, fmt.Errorf("NewScepCertificateRequest: %w", err)
+	}
+
+	var tbs scepCertificateRequestInfo
+	if _, err := asn1.Unmarshal(csr.RawTBSCertificateRequest, &tbs); err != nil {
+		return nil, fmt.Errorf("NewScepCertificateRequest: %w", err)
+	}
+
+	if challengePassword != "" {
+		value, err := asn1.Marshal(challengePassword)
+		if err != nil {
+			return nil, fmt.Errorf("NewScepCertificateRequest: %w", err)
+		}
+		tbs.Attributes = append(tbs.Attributes, pkcs7AttributeSet{Type: ScepChallengePasswordOID, Values: pkcs7Set(value)})
+	}
+
+	rawTbs, err := asn1.Marshal(tbs)
+	if err != nil {
+		return nil, fmt.Errorf("NewScepCertificateRequest: %w", err)
+	}
+	hash := crypto.SHA256.New()
+	hash.Write(rawTbs)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hash.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("NewScepCertificateRequest: %w", err)
+	}
+
+	der, err = asn1.Marshal(struct {
+		Info      asn1.RawValue
+		Algorithm pkix.AlgorithmIdentifier
+		Signature asn1.BitString
+	}{
+		Info:      asn1.RawValue{FullBytes: rawTbs},
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: SignatureAlgorithmOID(x509.SHA256WithRSA), Parameters: asn1.NullRawValue},
+		Signature: asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("NewScepCertificateRequest: %w", err)
+	}
+	return der, nil
+}
+
+// EncodeScepRequest creates a PKCSReq or RenewalReq PKIMessage
+//   - messageType: SCEP_MESSAGE_PKCS_REQ or SCEP_MESSAGE_RENEWAL_REQ
+//   - transactionID: The transaction ID
+//   - senderNonce: The nonce of the client
+//   - csr: The DER encoded certificate signing request
+//   - recipient: The RA certificate
+//   - signer: The certificate of the client
+//   - privateKey: The private key of the client
+//   - algorithm: The content encryption algorithm
+func EncodeScepRequest(
+	messageType string,
+	transactionID string,
+	senderNonce []byte,
+	csr []byte,
+	recipient *x509.Certificate,
+	signer *x509.Certificate,
+	privateKey crypto.Signer,
+	algorithm asn1.ObjectIdentifier,
+) ([]byte, error) {
+
+	envelopedData, err := EncodePkcs7EnvelopedData(csr, recipient, algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("EncodeScepRequest: %w", err)
+	}
+
+	attributes, err := newScepAttributes(
+		ScepMessageTypeOID, messageType,
+		ScepTransactionIDOID, transactionID,
+		ScepSenderNonceOID, senderNonce,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("EncodeScepRequest: %w", err)
+	}
+
+	der, err := EncodePkcs7SignedData(envelopedData, signer, privateKey, attributes, []*x509.Certificate{signer})
+	if err != nil {
+		return nil, fmt.Errorf("EncodeScepRequest: %w", err)
+	}
+	return der, nil
+}
+
+// ParseScepRequest parses a PKIMessage and verifies its signature
+func ParseScepRequest(der []byte) (*ScepRequest, error) {
+
+	message, err := ParsePkcs7SignedMessage(der)
+	if err != nil {
+		return nil, fmt.Errorf("ParseScepRequest: %w", err)
+	}
+
+	request := &ScepRequest{
+		Signer:        message.Signer,
+		EnvelopedData: message.Content,
+	}
+
+	if request.MessageType, err = scepStringAttribute(message, ScepMessageTypeOID); err != nil {
+		return nil, fmt.Errorf("ParseScepRequest: %w", err)
+	}
+	if request.TransactionID, err = scepStringAttribute(message, ScepTransactionIDOID); err != nil {
+		return nil, fmt.Errorf("ParseScepRequest: %w", err)
+	}
+	if request.SenderNonce, err = scepBytesAttribute(message, ScepSenderNonceOID); err != nil {
+		return nil, fmt.Errorf("ParseScepRequest: %w", err)
+	}
+
+	return request, nil
+}
+
+// EncodeScepResponse creates a CertRep PKIMessage for a request
+//   - request: The request to answer
+//   - status: SCEP_STATUS_SUCCESS, SCEP_STATUS_FAILURE or SCEP_STATUS_PENDING
+//   - failInfo: The reason of the failure, or an empty string
+//   - certificates: The issued certificate on success
+//   - signer: The RA certificate
+//   - privateKey: The RA private key
+//   - algorithm: The content encryption algorithm of the request
+func EncodeScepResponse(
+	request *ScepRequest,
+	status string,
+	failInfo string,
+	certificates []*x509.Certificate,
+	signer *x509.Certificate,
+	privateKey crypto.Signer,
+	algorithm asn1.ObjectIdentifier,
+) ([]byte, error) {
+
+	if request == nil {
+		return nil, fmt.Errorf("EncodeScepResponse: request: must be defined")
+	}
+
+	senderNonce := make([]byte, 16)
+	if _, err := rand.Read(senderNonce); err != nil {
+		return nil, fmt.Errorf("EncodeScepResponse: failed to create nonce: %w", err)
+	}
+
+	attributes, err := newScepAttributes(
+		ScepMessageTypeOID, SCEP_MESSAGE_CERT_REP,
+		ScepTransactionIDOID, request.TransactionID,
+		ScepPkiStatusOID, status,
+		ScepSenderNonceOID, senderNonce,
+		ScepRecipientNonceOID, request.SenderNonce,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("EncodeScepResponse: %w", err)
+	}
+
+	if status == SCEP_STATUS_FAILURE {
+		attribute, err := NewPkcs7Attribute(ScepFailInfoOID, failInfo)
+		if err != nil {
+			return nil, fmt.Errorf("EncodeScepResponse: %w", err)
+		}
+		attributes = append(attributes, attribute)
+	}
+
+	var content []byte
+	if status == SCEP_STATUS_SUCCESS {
+		certs, err := EncodePkcs7Certificates(certificates)
+		if err != nil {
+			return nil, fmt.Errorf("EncodeScepResponse: %w", err)
+		}
+		content, err = EncodePkcs7EnvelopedData(certs, request.Signer, algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("EncodeScepResponse: %w", err)
+		}
+	}
+
+	der, err := EncodePkcs7SignedData(content, signer, privateKey, attributes, []*x509.Certificate{signer})
+	if err != nil {
+		return nil, fmt.Errorf("EncodeScepResponse: %w", err)
+	}
+	return der, nil
+}
+
+// ParseScepResponse parses a CertRep PKIMessage and decrypts the issued
+// certificates
+//   - der: The response
+//   - certificate: The certificate used to sign the request
+//   - privateKey: The private key of the certificate
+func ParseScepResponse(der []byte, certificate *x509.Certificate, privateKey *rsa.PrivateKey) (*ScepResponse, error) {
+
+	message, err := ParsePkcs7SignedMessage(der)
+	if err != nil {
+		return nil, fmt.Errorf("ParseScepResponse: %w", err)
+	}
+
+	messageType, err := scepStringAttribute(message, ScepMessageTypeOID)
+	if err != nil {
+		return nil, fmt.Errorf("ParseScepResponse: %w", err)
+	}
+	if messageType != SCEP_MESSAGE_CERT_REP {
+		return nil, fmt.Errorf("ParseScepResponse: unexpected message type: %s", messageType)
+	}
+
+	response := &ScepResponse{}
+	if response.TransactionID, err = scepStringAttribute(message, ScepTransactionIDOID); err != nil {
+		return nil, fmt.Errorf("ParseScepResponse: %w", err)
+	}
+	if response.Status, err = scepStringAttribute(message, ScepPkiStatusOID); err != nil {
+		return nil, fmt.Errorf("ParseScepResponse: %w", err)
+	}
+	if response.RecipientNonce, err = scepBytesAttribute(message, ScepRecipientNonceOID); err != nil {
+		return nil, fmt.Errorf("ParseScepResponse: %w", err)
+	}
+
+	switch response.Status {
+	case SCEP_STATUS_FAILURE:
+		if response.FailInfo, err = scepStringAttribute(message, ScepFailInfoOID); err != nil {
+			return nil, fmt.Errorf("ParseScepResponse: %w", err)
+		}
+	case SCEP_STATUS_SUCCESS:
+		content, _, err := DecryptPkcs7EnvelopedData(message.Content, certificate, privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("ParseScepResponse: %w", err)
+		}
+		if response.Certificates, err = ParsePkcs7Certificates(content); err != nil {
+			return nil, fmt.Errorf("ParseScepResponse: %w", err)
+		}
+	}
+
+	return response, nil
+}
+
+func ToScepChallengeDTO(challenge appmodels.ScepChallenge) appdtos.ScepChallengeDTO {
+	return appdtos.NewScepChallengeDTO(
+		challenge.OrganizationID().String(),
+	)
+}
+
+// ToScepChallengeRecordDTO converts a SCEP challenge password to its stored
+// form
+func ToScepChallengeRecordDTO(challenge appmodels.ScepChallenge) appdtos.ScepChallengeRecordDTO {
+	return appdtos.NewScepChallengeRecordDTO(
+		challenge.OrganizationID().String(),
+		challenge.PasswordHash(),
+	)
+}
+
+// FromScepChallengeRecordDTO converts the stored form of a SCEP challenge
+// password to a model
+func FromScepChallengeRecordDTO(dto appdtos.ScepChallengeRecordDTO) (appmodels.ScepChallenge, error) {
+	organization, err := ParseBigInt(dto.Organization, 10)
+	if err != nil {
+		return nil, fmt.Errorf("FromScepChallengeRecordDTO: invalid organization: %w", err)
+	}
+	return appmodels.NewScepChallenge(organization, dto.PasswordHash), nil
+}
+
+type scepCertificateRequestInfo struct {
+	Version       int
+	Subject       asn1.RawValue
+	PublicKeyInfo asn1.RawValue
+	Attributes    []pkcs7AttributeSet `asn1:"tag:0,set"`
+}
+
+// newScepAttributes creates signed attributes from pairs of an object
+// identifier and a value
+func newScepAttributes(pairs ...any) ([]Pkcs7Attribute, error) {
+	attributes := make([]Pkcs7Attribute, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		attribute, err := NewPkcs7Attribute(pairs[i].(asn1.ObjectIdentifier), pairs[i+1])
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, attribute)
+	}
+	return attributes, nil
+}
+
+func scepStringAttribute(message *Pkcs7SignedMessage, attributeType asn1.ObjectIdentifier) (string, error) {
+	value, exists := message.Attribute(attributeType)
+	if !exists {
+		return "", fmt.Errorf("attribute %v: must be defined", attributeType)
+	}
+	return string(value.Bytes), nil
+}
+
+func scepBytesAttribute(message *Pkcs7SignedMessage, attributeType asn1.ObjectIdentifier) ([]byte, error) {
+	value, exists := message.Attribute(attributeType)
+	if !exists {
+		return nil, fmt.Errorf("attribute %v: must be defined", attributeType)
+	}
+	if value.Tag != asn1.TagOctetString {
+		return nil, fmt.Errorf("attribute %v: must be an octet string", attributeType)
+	}
+	return value.Bytes, nil
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+func TestDecodeScepMessage(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0xfe}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	require.Contains(t, encoded, "+")
+
+	decoded, err := apputils.DecodeScepMessage(strings.ReplaceAll(encoded, "+", " "))
+	require.NoError(t, err)
+	assert.Equal(t, data, decoded)
+
+	_, err = apputils.DecodeScepMessage("!")
+	assert.Error(t, err)
+}
+
+func TestScepChallengePassword(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := apputils.NewScepCertificateRequest(&x509.CertificateRequest{Subject: pkix.Name{CommonName: "device"}}, "secret", key)
+	require.NoError(t, err)
+	csr, err := x509.ParseCertificateRequest(der)
+	require.NoError(t, err)
+	require.NoError(t, csr.CheckSignature())
+	assert.Equal(t, "device", csr.Subject.CommonName)
+
+	password, err := apputils.ScepChallengePassword(csr)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", password)
+
+	der, err = apputils.NewScepCertificateRequest(&x509.CertificateRequest{Subject: pkix.Name{CommonName: "device"}}, "", key)
+	require.NoError(t, err)
+	csr, err = x509.ParseCertificateRequest(der)
+	require.NoError(t, err)
+	password, err = apputils.ScepChallengePassword(csr)
+	require.NoError(t, err)
+	assert.Empty(t, password)
+
+	_, err = apputils.ScepChallengePassword(nil)
+	assert.Error(t, err)
+}
+
+func TestScepRequestAndResponse(t *testing.T) {
+	ra, raKey := newTestRsaCertificate(t, "ra")
+	client, clientKey := newTestRsaCertificate(t, "client")
+
+	message, err := apputils.EncodeScepRequest(apputils.SCEP_MESSAGE_PKCS_REQ, "id", []byte("nonce"), []byte("csr"), ra, client, clientKey, apputils.Pkcs7Aes256CbcOID)
+	require.NoError(t, err)
+
+	request, err := apputils.ParseScepRequest(message)
+	require.NoError(t, err)
+	assert.Equal(t, apputils.SCEP_MESSAGE_PKCS_REQ, request.MessageType)
+	assert.Equal(t, "id", request.TransactionID)
+	assert.Equal(t, []byte("nonce"), request.SenderNonce)
+	assert.Equal(t, client.Raw, request.Signer.Raw)
+
+	content, algorithm, err := apputils.DecryptPkcs7EnvelopedData(request.EnvelopedData, ra, raKey)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("csr"), content)
+
+	response, err := apputils.EncodeScepResponse(request, apputils.SCEP_STATUS_SUCCESS, "", []*x509.Certificate{client}, ra, raKey, algorithm)
+	require.NoError(t, err)
+	parsed, err := apputils.ParseScepResponse(response, client, clientKey)
+	require.NoError(t, err)
+	assert.Equal(t, apputils.SCEP_STATUS_SUCCESS, parsed.Status)
+	assert.Equal(t, []byte("nonce"), parsed.RecipientNonce)
+	require.Len(t, parsed.Certificates, 1)
+	assert.Equal(t, client.Raw, parsed.Certificates[0].Raw)
+
+	response, err = apputils.EncodeScepResponse(request, apputils.SCEP_STATUS_FAILURE, apputils.SCEP_FAIL_BAD_REQUEST, nil, ra, raKey, nil)
+	require.NoError(t, err)
+	parsed, err = apputils.ParseScepResponse(response, client, clientKey)
+	require.NoError(t, err)
+	assert.Equal(t, apputils.SCEP_STATUS_FAILURE, parsed.Status)
+	assert.Equal(t, apputils.SCEP_FAIL_BAD_REQUEST, parsed.FailInfo)
+	assert.Empty(t, parsed.Certificates)
+
+	// A request is not a response
+	_, err = apputils.ParseScepResponse(message, client, clientKey)
+	assert.Error(t, err)
+
+	_, err = apputils.ParseScepRequest([]byte("invalid"))
+	assert.Error(t, err)
+}
+
+func TestToScepChallengeDTO(t *testing.T) {
+	dto := apputils.ToScepChallengeDTO(appmodels.NewScepChallenge(appmodels.NewSerialNumber(10), []byte("hash")))
+	assert.Equal(t, "10", dto.Organization)
+}
+
+func TestScepChallengeRecordDTO(t *testing.T) {
+	challenge := appmodels.NewScepChallenge(big.NewInt(1), []byte("hash"))
+	model, err := apputils.FromScepChallengeRecordDTO(apputils.ToScepChallengeRecordDTO(challenge))
+	require.NoError(t, err)
+	assert.Equal(t, challenge, model)
+
+	_, err = apputils.FromScepChallengeRecordDTO(appdtos.NewScepChallengeRecordDTO("invalid", nil))
+	assert.Error(t, err)
+}
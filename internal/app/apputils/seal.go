@@ -0,0 +1,348 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+	"github.com/hyperifyio/gocertcenter/internal/common/shamirutils"
+)
+
+// SealConfigVersion is the newest supported format version of seal files
+const SealConfigVersion = 1
+
+// SealKeySize is the size of the AES-256 seal key
+const SealKeySize = 32
+
+// SealedPrivateKeyPemType is the PEM block type of private keys encrypted
+// with the seal key. The key type is in the Key-Type header.
+const SealedPrivateKeyPemType = "SEALED PRIVATE KEY"
+
+// sealKeyCheckMessage is the message of the key check of seal files
+var sealKeyCheckMessage = []byte("gocertcenter seal key")
+
+// NewSealConfig creates a random seal key and a seal file which keeps it
+// encrypted with the passphrase. With shares, the key is also split into
+// key shares of which threshold shares unseal.
+//   - passphrase: The passphrase which encrypts the seal key
+//   - shares: The number of key shares, or 0 for none
+//   - threshold: The number of key shares needed to unseal
+func NewSealConfig(passphrase string, shares, threshold int) (appdtos.SealConfigDTO, []byte, [][]byte, error) {
+
+	if passphrase == "" {
+		return appdtos.SealConfigDTO{}, nil, nil, errors.New("NewSealConfig: passphrase must be defined")
+	}
+
+	key := make([]byte, SealKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return appdtos.SealConfigDTO{}, nil, nil, fmt.Errorf("NewSealConfig: key: %w", err)
+	}
+
+	var keyShares [][]byte
+	if shares != 0 {
+		var err error
+		if keyShares, err = shamirutils.Split(key, shares, threshold); err != nil {
+			return appdtos.SealConfigDTO{}, nil, nil, fmt.Errorf("NewSealConfig: %w", err)
+		}
+	} else {
+		threshold = 0
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return appdtos.SealConfigDTO{}, nil, nil, fmt.Errorf("NewSealConfig: salt: %w", err)
+	}
+	aead, err := newBackupCipher(passphrase, salt, BackupScryptN, BackupScryptR, BackupScryptP)
+	if err != nil {
+		return appdtos.SealConfigDTO{}, nil, nil, fmt.Errorf("NewSealConfig: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return appdtos.SealConfigDTO{}, nil, nil, fmt.Errorf("NewSealConfig: nonce: %w", err)
+	}
+
+	config := appdtos.NewSealConfigDTO(
+		SealConfigVersion,
+		appdtos.NewBackupEncryptionDTO(BackupCipher, BackupKdf, salt, BackupScryptN, BackupScryptR, BackupScryptP, nonce),
+		aead.Seal(nil, nonce, key, nil),
+		SealKeyCheck(key),
+		shares,
+		threshold,
+	)
+	return config, key, keyShares, nil
+}
+
+// ParseSealConfig decodes and validates a seal file
+func ParseSealConfig(data []byte) (appdtos.SealConfigDTO, error) {
+	var config appdtos.SealConfigDTO
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("ParseSealConfig: %w", err)
+	}
+	if config.Version < 1 || config.Version > SealConfigVersion {
+		return config, fmt.Errorf("ParseSealConfig: unsupported version: %d", config.Version)
+	}
+	encryption := config.Encryption
+	if encryption.Cipher != BackupCipher || encryption.Kdf != BackupKdf {
+		return config, fmt.Errorf("ParseSealConfig: unsupported encryption: %s with %s", encryption.Cipher, encryption.Kdf)
+	}
+	if encryption.N <= 0 || encryption.R <= 0 || encryption.P <= 0 ||
+		int64(encryption.N)*int64(encryption.R) > BackupScryptMaxMemory/128 ||
+		encryption.P > BackupScryptMaxP {
+		return config, fmt.Errorf("ParseSealConfig: unacceptable scrypt cost: N=%d r=%d p=%d", encryption.N, encryption.R, encryption.P)
+	}
+	if config.Shares != 0 && (config.Threshold < 2 || config.Threshold > config.Shares) {
+		return config, fmt.Errorf("ParseSealConfig: invalid threshold: %d of %d", config.Threshold, config.Shares)
+	}
+	return config, nil
+}
+
+// OpenSealConfig decrypts the seal key of a seal file with the passphrase
+func OpenSealConfig(config appdtos.SealConfigDTO, passphrase string) ([]byte, error) {
+	encryption := config.Encryption
+	aead, err := newBackupCipher(passphrase, encryption.Salt, encryption.N, encryption.R, encryption.P)
+	if err != nil {
+		return nil, fmt.Errorf("OpenSealConfig: %w", err)
+	}
+	if len(encryption.Nonce) != aead.NonceSize() {
+		return nil, errors.New("OpenSealConfig: invalid nonce")
+	}
+	key, err := aead.Open(nil, encryption.Nonce, config.WrappedKey, nil)
+	if err != nil {
+		return nil, errors.New("OpenSealConfig: wrong passphrase")
+	}
+	if !hmac.Equal(SealKeyCheck(key), config.KeyCheck) {
+		return nil, errors.New("OpenSealConfig: key check does not match")
+	}
+	return key, nil
+}
+
+// CombineSealKeyShares reconstructs the seal key of a seal file from key
+// shares
+func CombineSealKeyShares(config appdtos.SealConfigDTO, shares [][]byte) ([]byte, error) {
+	if config.Threshold == 0 {
+		return nil, errors.New("CombineSealKeyShares: the seal key has no shares")
+	}
+	if len(shares) < config.Threshold {
+		return nil, fmt.Errorf("CombineSealKeyShares: %d of %d shares", len(shares), config.Threshold)
+	}
+	key, err := shamirutils.Combine(shares)
+	if err != nil {
+		return nil, fmt.Errorf("CombineSealKeyShares: %w", err)
+	}
+	if !hmac.Equal(SealKeyCheck(key), config.KeyCheck) {
+		return nil, errors.New("CombineSealKeyShares: wrong key shares")
+	}
+	return key, nil
+}
+
+// SealKeyCheck returns the key check of a seal key
+func SealKeyCheck(key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(sealKeyCheckMessage)
+	return mac.Sum(nil)
+}
+
+// EncodeSealKeyShare encodes a key share as base64
+func EncodeSealKeyShare(share []byte) string {
+	return base64.StdEncoding.EncodeToString(share)
+}
+
+// DecodeSealKeyShare decodes a key share encoded by EncodeSealKeyShare
+func DecodeSealKeyShare(value string) ([]byte, error) {
+	share, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeSealKeyShare: %w", err)
+	}
+	if len(share) != SealKeySize+1 {
+		return nil, fmt.Errorf("DecodeSealKeyShare: invalid length: %d", len(share))
+	}
+	return share, nil
+}
+
+// SealEncrypt encrypts data with AES-256-GCM. The random nonce is prefixed
+// to the ciphertext.
+//   - key: The seal key
+//   - plaintext: The data to encrypt
+//   - additionalData: The data which must match when decrypting
+func SealEncrypt(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newSealCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("SealEncrypt: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("SealEncrypt: nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// SealDecrypt decrypts data encrypted by SealEncrypt
+//   - key: The seal key
+//   - ciphertext: The encrypted data
+//   - additionalData: The data which was used when encrypting
+func SealDecrypt(key, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newSealCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("SealDecrypt: %w", err)
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("SealDecrypt: ciphertext is too short")
+	}
+	nonce := ciphertext[:aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, ciphertext[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, errors.New("SealDecrypt: wrong key or corrupted data")
+	}
+	return plaintext, nil
+}
+
+// SealPrivateKey encrypts a private key with the seal key. The key is bound
+// to its organization and certificate, so it cannot be moved to another
+// certificate.
+//   - certManager: The certificate manager
+//   - sealController: The seal controller which has the seal key
+//   - key: The private key
+func SealPrivateKey(
+	certManager managers.CertificateManager,
+	sealController appmodels.SealController,
+	key appmodels.PrivateKey,
+) (appmodels.PrivateKey, error) {
+	sealed, err := sealPrivateKey(certManager, sealController, key, sealedPrivateKeyAdditionalData(key.OrganizationID(), key.SerialNumber()))
+	if err != nil {
+		return nil, fmt.Errorf("SealPrivateKey: %w", err)
+	}
+	return sealed, nil
+}
+
+// UnsealPrivateKey decrypts a private key encrypted by SealPrivateKey. Keys
+// which are not encrypted are returned as they are.
+//   - certManager: The certificate manager
+//   - sealController: The seal controller which has the seal key
+//   - key: The private key
+func UnsealPrivateKey(
+	certManager managers.CertificateManager,
+	sealController appmodels.SealController,
+	key appmodels.PrivateKey,
+) (appmodels.PrivateKey, error) {
+	unsealed, err := unsealPrivateKey(certManager, sealController, key, sealedPrivateKeyAdditionalData(key.OrganizationID(), key.SerialNumber()))
+	if err != nil {
+		return nil, fmt.Errorf("UnsealPrivateKey: %w", err)
+	}
+	return unsealed, nil
+}
+
+// SealSshAuthorityKey encrypts the private key of an SSH certificate
+// authority with the seal key. The key is bound to its organization and
+// authority, so it cannot be used as the key of a certificate.
+//   - certManager: The certificate manager
+//   - sealController: The seal controller which has the seal key
+//   - authority: The SSH certificate authority
+func SealSshAuthorityKey(
+	certManager managers.CertificateManager,
+	sealController appmodels.SealController,
+	authority appmodels.SshAuthority,
+) (appmodels.SshAuthority, error) {
+	sealed, err := sealPrivateKey(certManager, sealController, authority.PrivateKey(), sealedSshAuthorityAdditionalData(authority.OrganizationID(), authority.ID()))
+	if err != nil {
+		return nil, fmt.Errorf("SealSshAuthorityKey: %w", err)
+	}
+	return appmodels.NewSshAuthority(authority.OrganizationID(), authority.ID(), sealed, authority.CreatedAt()), nil
+}
+
+// UnsealSshAuthorityKey decrypts the private key of an SSH certificate
+// authority encrypted by SealSshAuthorityKey. Keys which are not encrypted
+// are returned as they are.
+//   - certManager: The certificate manager
+//   - sealController: The seal controller which has the seal key
+//   - authority: The SSH certificate authority
+func UnsealSshAuthorityKey(
+	certManager managers.CertificateManager,
+	sealController appmodels.SealController,
+	authority appmodels.SshAuthority,
+) (appmodels.SshAuthority, error) {
+	unsealed, err := unsealPrivateKey(certManager, sealController, authority.PrivateKey(), sealedSshAuthorityAdditionalData(authority.OrganizationID(), authority.ID()))
+	if err != nil {
+		return nil, fmt.Errorf("UnsealSshAuthorityKey: %w", err)
+	}
+	return appmodels.NewSshAuthority(authority.OrganizationID(), authority.ID(), unsealed, authority.CreatedAt()), nil
+}
+
+func sealPrivateKey(
+	certManager managers.CertificateManager,
+	sealController appmodels.SealController,
+	key appmodels.PrivateKey,
+	additionalData []byte,
+) (appmodels.PrivateKey, error) {
+	if _, ok := key.PrivateKey().(appmodels.SealedPrivateKey); ok {
+		return key, nil
+	}
+	der, err := certManager.MarshalPKCS8PrivateKey(key.PrivateKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", err)
+	}
+	defer clear(der)
+	ciphertext, err := sealController.Encrypt(der, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return appmodels.NewPrivateKey(
+		key.OrganizationID(),
+		key.SerialNumber(),
+		key.KeyType(),
+		appmodels.NewSealedPrivateKey(key.KeyType(), ciphertext),
+	), nil
+}
+
+func unsealPrivateKey(
+	certManager managers.CertificateManager,
+	sealController appmodels.SealController,
+	key appmodels.PrivateKey,
+	additionalData []byte,
+) (appmodels.PrivateKey, error) {
+	sealed, ok := key.PrivateKey().(appmodels.SealedPrivateKey)
+	if !ok {
+		return key, nil
+	}
+	der, err := sealController.Decrypt(sealed.Ciphertext(), additionalData)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(der)
+	privateKey, keyType, err := ParsePKCS8PrivateKey(certManager, der)
+	if err != nil {
+		return nil, err
+	}
+	return appmodels.NewPrivateKey(key.OrganizationID(), key.SerialNumber(), keyType, privateKey), nil
+}
+
+// sealedPrivateKeyAdditionalData returns the additional data which binds an
+// encrypted private key to its certificate
+func sealedPrivateKeyAdditionalData(organization, serialNumber *big.Int) []byte {
+	return []byte(organization.String() + "/" + serialNumber.String())
+}
+
+// sealedSshAuthorityAdditionalData returns the additional data which binds an
+// encrypted SSH CA key to its authority
+func sealedSshAuthorityAdditionalData(organization, id *big.Int) []byte {
+	return []byte("ssh/" + organization.String() + "/" + id.String())
+}
+
+func newSealCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
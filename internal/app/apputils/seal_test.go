@@ -0,0 +1,196 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestNewSealConfig(t *testing.T) {
+	config, key, shares, err := apputils.NewSealConfig("secret", 5, 3)
+	require.NoError(t, err)
+	require.Len(t, key, apputils.SealKeySize)
+	require.Len(t, shares, 5)
+	assert.Equal(t, 5, config.Shares)
+	assert.Equal(t, 3, config.Threshold)
+
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+	parsed, err := apputils.ParseSealConfig(data)
+	require.NoError(t, err)
+	assert.Equal(t, config, parsed)
+
+	opened, err := apputils.OpenSealConfig(parsed, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, key, opened)
+	_, err = apputils.OpenSealConfig(parsed, "wrong")
+	assert.Error(t, err)
+
+	combined, err := apputils.CombineSealKeyShares(parsed, [][]byte{shares[4], shares[0], shares[2]})
+	require.NoError(t, err)
+	assert.Equal(t, key, combined)
+	_, err = apputils.CombineSealKeyShares(parsed, shares[:2])
+	assert.Error(t, err, "too few shares")
+	wrong := append([]byte(nil), shares[1]...)
+	wrong[0] ^= 1
+	_, err = apputils.CombineSealKeyShares(parsed, [][]byte{shares[0], wrong, shares[2]})
+	assert.Error(t, err, "modified share")
+
+	for _, share := range shares {
+		decoded, err := apputils.DecodeSealKeyShare(apputils.EncodeSealKeyShare(share))
+		require.NoError(t, err)
+		assert.Equal(t, share, decoded)
+	}
+	_, err = apputils.DecodeSealKeyShare("c2hvcnQ=")
+	assert.Error(t, err)
+}
+
+func TestNewSealConfig_WithoutShares(t *testing.T) {
+	config, key, shares, err := apputils.NewSealConfig("secret", 0, 3)
+	require.NoError(t, err)
+	assert.Nil(t, shares)
+	assert.Equal(t, 0, config.Threshold)
+	_, err = apputils.CombineSealKeyShares(config, [][]byte{key, key})
+	assert.Error(t, err)
+
+	_, _, _, err = apputils.NewSealConfig("", 0, 0)
+	assert.Error(t, err)
+	_, _, _, err = apputils.NewSealConfig("secret", 2, 3)
+	assert.Error(t, err)
+}
+
+func TestParseSealConfig_Invalid(t *testing.T) {
+	config, _, _, err := apputils.NewSealConfig("secret", 3, 2)
+	require.NoError(t, err)
+
+	tests := map[string]func(){
+		"version":   func() { config.Version = apputils.SealConfigVersion + 1 },
+		"cipher":    func() { config.Encryption.Cipher = "DES" },
+		"cost":      func() { config.Encryption.N = 1 << 30 },
+		"threshold": func() { config.Threshold = 4 },
+	}
+	for name, modify := range tests {
+		original := config
+		modify()
+		data, err := json.Marshal(config)
+		require.NoError(t, err)
+		_, err = apputils.ParseSealConfig(data)
+		assert.Error(t, err, name)
+		config = original
+	}
+	_, err = apputils.ParseSealConfig([]byte("{"))
+	assert.Error(t, err)
+}
+
+func TestSealEncrypt(t *testing.T) {
+	key := make([]byte, apputils.SealKeySize)
+	ciphertext, err := apputils.SealEncrypt(key, []byte("data"), []byte("1/2"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(ciphertext), "data")
+
+	plaintext, err := apputils.SealDecrypt(key, ciphertext, []byte("1/2"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), plaintext)
+
+	_, err = apputils.SealDecrypt(key, ciphertext, []byte("1/3"))
+	assert.Error(t, err, "wrong additional data")
+	_, err = apputils.SealDecrypt(make([]byte, 16), ciphertext, []byte("1/2"))
+	assert.Error(t, err, "wrong key")
+	_, err = apputils.SealDecrypt(key, ciphertext[:4], []byte("1/2"))
+	assert.Error(t, err, "too short")
+}
+
+func TestSealPrivateKey(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	key, err := apputils.GeneratePrivateKey(big.NewInt(1), big.NewInt(2), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+
+	// The mock stores the plaintext and returns it for the same
+	// additional data
+	var stored []byte
+	sealController := new(appmocks.MockSealController)
+	sealController.On("Encrypt", mock.Anything, []byte("1/2")).Run(func(args mock.Arguments) {
+		stored = append([]byte(nil), args.Get(0).([]byte)...)
+	}).Return([]byte("ciphertext"), nil)
+
+	sealed, err := apputils.SealPrivateKey(certManager, sealController, key)
+	require.NoError(t, err)
+	require.Implements(t, (*appmodels.SealedPrivateKey)(nil), sealed.PrivateKey())
+	assert.Equal(t, appmodels.ECDSA_P256, sealed.KeyType())
+
+	// Sealed keys are stored as PEM without decrypting them
+	pemData, err := apputils.MarshalPrivateKeyAsPEM(certManager, sealed.PrivateKey())
+	require.NoError(t, err)
+	assert.Contains(t, string(pemData), apputils.SealedPrivateKeyPemType)
+	data, keyType, err := apputils.ParsePrivateKeyFromPEMBytes(certManager, pemData)
+	require.NoError(t, err)
+	assert.Equal(t, appmodels.ECDSA_P256, keyType)
+	read := appmodels.NewPrivateKey(big.NewInt(1), big.NewInt(2), keyType, data)
+
+	sealController.On("Decrypt", []byte("ciphertext"), []byte("1/2")).Return(stored, nil)
+	unsealed, err := apputils.UnsealPrivateKey(certManager, sealController, read)
+	require.NoError(t, err)
+	assert.Equal(t, key.PrivateKey(), unsealed.PrivateKey())
+
+	// Keys are sealed only once, and plain keys are returned as they are
+	again, err := apputils.SealPrivateKey(certManager, sealController, sealed)
+	require.NoError(t, err)
+	assert.Same(t, sealed, again)
+	plain, err := apputils.UnsealPrivateKey(certManager, sealController, key)
+	require.NoError(t, err)
+	assert.Same(t, key, plain)
+
+	sealedFingerprint, err := apputils.PrivateKeyFingerprint(certManager, read)
+	require.NoError(t, err)
+	plainFingerprint, err := apputils.PrivateKeyFingerprint(certManager, key)
+	require.NoError(t, err)
+	assert.NotEqual(t, plainFingerprint, sealedFingerprint)
+
+	// A key which is moved to another certificate is not decrypted
+	moved := appmodels.NewPrivateKey(big.NewInt(1), big.NewInt(3), keyType, data)
+	sealController.On("Decrypt", mock.Anything, []byte("1/3")).Return(nil, appmodels.ErrSealed)
+	_, err = apputils.UnsealPrivateKey(certManager, sealController, moved)
+	assert.ErrorIs(t, err, appmodels.ErrSealed)
+}
+
+func TestSealSshAuthorityKey(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	key, err := apputils.GeneratePrivateKey(big.NewInt(1), big.NewInt(2), appmodels.Ed25519)
+	require.NoError(t, err)
+	createdAt := time.Now()
+	authority := appmodels.NewSshAuthority(big.NewInt(1), big.NewInt(2), key, createdAt)
+
+	var stored []byte
+	sealController := new(appmocks.MockSealController)
+	sealController.On("Encrypt", mock.Anything, []byte("ssh/1/2")).Run(func(args mock.Arguments) {
+		stored = append([]byte(nil), args.Get(0).([]byte)...)
+	}).Return([]byte("ciphertext"), nil)
+
+	sealed, err := apputils.SealSshAuthorityKey(certManager, sealController, authority)
+	require.NoError(t, err)
+	require.Implements(t, (*appmodels.SealedPrivateKey)(nil), sealed.PrivateKey().PrivateKey())
+	assert.Equal(t, authority.ID(), sealed.ID())
+	assert.Equal(t, createdAt, sealed.CreatedAt())
+
+	sealController.On("Decrypt", []byte("ciphertext"), []byte("ssh/1/2")).Return(stored, nil)
+	unsealed, err := apputils.UnsealSshAuthorityKey(certManager, sealController, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, key.PrivateKey(), unsealed.PrivateKey().PrivateKey())
+
+	// The key of an SSH CA is not decrypted as the key of a certificate
+	sealController.On("Decrypt", []byte("ciphertext"), []byte("1/2")).Return(nil, appmodels.ErrSealed)
+	_, err = apputils.UnsealPrivateKey(certManager, sealController, sealed.PrivateKey())
+	assert.ErrorIs(t, err, appmodels.ErrSealed)
+}
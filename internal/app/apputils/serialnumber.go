@@ -4,6 +4,7 @@ package apputils
 
 import (
 	"math/big"
+	"strings"
 
 	"github.com/hyperifyio/gocertcenter/internal/common/managers"
 )
@@ -15,3 +16,20 @@ func GenerateSerialNumber(randomManager managers.RandomManager) (*big.Int, error
 	}
 	return value, nil
 }
+
+// ParseSerialNumber parses a serial number written in decimal or in hex. Hex
+// is recognized by a 0x prefix, hex letters or colon separated bytes like in
+// the OpenSSL output; digits only are decimal.
+func ParseSerialNumber(value string) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		return ParseBigInt(value[2:], 16)
+	}
+	if strings.Contains(value, ":") {
+		return ParseBigInt(strings.ReplaceAll(value, ":", ""), 16)
+	}
+	if strings.ContainsAny(value, "abcdefABCDEF") {
+		return ParseBigInt(value, 16)
+	}
+	return ParseBigInt(value, 10)
+}
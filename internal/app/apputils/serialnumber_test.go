@@ -74,3 +74,28 @@ func TestNewSerialNumber(t *testing.T) {
 		t.Fatalf("Expected serial number to be positive, got %s", serialNumber.String())
 	}
 }
+
+func TestParseSerialNumber(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{"255", 255},
+		{"0xff", 255},
+		{"0XFF", 255},
+		{"ff", 255},
+		{"01:00", 256},
+	}
+	for _, tt := range tests {
+		serialNumber, err := apputils.ParseSerialNumber(tt.value)
+		if err != nil {
+			t.Fatalf("ParseSerialNumber(%q) failed: %v", tt.value, err)
+		}
+		if serialNumber.Cmp(big.NewInt(tt.want)) != 0 {
+			t.Errorf("ParseSerialNumber(%q) = %v, expected %d", tt.value, serialNumber, tt.want)
+		}
+	}
+	if _, err := apputils.ParseSerialNumber("0xg"); err == nil {
+		t.Errorf("Expected ParseSerialNumber() to fail, did not fail")
+	}
+}
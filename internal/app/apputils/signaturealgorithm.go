@@ -0,0 +1,59 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// ParseSignatureAlgorithm parses a signature algorithm name like
+// "SHA384_WITH_RSA_PSS". The x509 package names, like "SHA384-RSAPSS", are
+// accepted as well. An empty string returns appmodels.NIL_SIGNATURE_ALGORITHM.
+func ParseSignatureAlgorithm(value string) (appmodels.SignatureAlgorithm, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return appmodels.NIL_SIGNATURE_ALGORITHM, nil
+	}
+	for sa := appmodels.SHA256_WITH_RSA; sa <= appmodels.PURE_Ed25519; sa++ {
+		if strings.EqualFold(sa.String(), value) || strings.EqualFold(sa.X509().String(), value) {
+			return sa, nil
+		}
+	}
+	return appmodels.NIL_SIGNATURE_ALGORITHM, fmt.Errorf("ParseSignatureAlgorithm: unsupported: %s", value)
+}
+
+// SignatureAlgorithmToString returns the name of the signature algorithm, or
+// an empty string for appmodels.NIL_SIGNATURE_ALGORITHM
+func SignatureAlgorithmToString(sa appmodels.SignatureAlgorithm) string {
+	if sa == appmodels.NIL_SIGNATURE_ALGORITHM {
+		return ""
+	}
+	return sa.String()
+}
+
+// ValidateSignatureAlgorithm checks that the issuer key can sign using the
+// signature algorithm. The issuer key is not inspected when the algorithm is
+// appmodels.NIL_SIGNATURE_ALGORITHM.
+func ValidateSignatureAlgorithm(
+	signatureAlgorithm appmodels.SignatureAlgorithm,
+	issuerKey appmodels.PrivateKey,
+) error {
+	if signatureAlgorithm == appmodels.NIL_SIGNATURE_ALGORITHM {
+		return nil
+	}
+	if signatureAlgorithm.X509() == x509.UnknownSignatureAlgorithm {
+		return fmt.Errorf("unsupported signature algorithm: %s", signatureAlgorithm)
+	}
+	if issuerKey == nil {
+		return fmt.Errorf("no issuer key for %s", signatureAlgorithm)
+	}
+	keyType := issuerKey.KeyType()
+	if !signatureAlgorithm.IsCompatibleWith(keyType) {
+		return fmt.Errorf("%s is not compatible with issuer key type %s", signatureAlgorithm, keyType)
+	}
+	return nil
+}
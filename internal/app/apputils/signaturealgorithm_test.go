@@ -0,0 +1,109 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestParseSignatureAlgorithm(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    appmodels.SignatureAlgorithm
+		wantErr bool
+	}{
+		{"", appmodels.NIL_SIGNATURE_ALGORITHM, false},
+		{"SHA384_WITH_RSA_PSS", appmodels.SHA384_WITH_RSA_PSS, false},
+		{"sha256_with_rsa", appmodels.SHA256_WITH_RSA, false},
+		{"SHA512-RSAPSS", appmodels.SHA512_WITH_RSA_PSS, false},
+		{"ECDSA-SHA384", appmodels.ECDSA_WITH_SHA384, false},
+		{"Ed25519", appmodels.PURE_Ed25519, false},
+		{"MD5_WITH_RSA", appmodels.NIL_SIGNATURE_ALGORITHM, true},
+	}
+
+	for _, tt := range tests {
+		got, err := apputils.ParseSignatureAlgorithm(tt.value)
+		if tt.wantErr {
+			assert.Error(t, err, tt.value)
+		} else {
+			assert.NoError(t, err, tt.value)
+		}
+		assert.Equal(t, tt.want, got, tt.value)
+	}
+}
+
+func TestSignatureAlgorithmToString(t *testing.T) {
+	assert.Equal(t, "", apputils.SignatureAlgorithmToString(appmodels.NIL_SIGNATURE_ALGORITHM))
+	assert.Equal(t, "SHA256_WITH_RSA_PSS", apputils.SignatureAlgorithmToString(appmodels.SHA256_WITH_RSA_PSS))
+}
+
+func TestValidateSignatureAlgorithm(t *testing.T) {
+	rsaKey := &appmocks.MockPrivateKey{}
+	rsaKey.On("KeyType").Return(appmodels.RSA_2048)
+
+	assert.NoError(t, apputils.ValidateSignatureAlgorithm(appmodels.NIL_SIGNATURE_ALGORITHM, nil))
+	assert.NoError(t, apputils.ValidateSignatureAlgorithm(appmodels.SHA256_WITH_RSA_PSS, rsaKey))
+	assert.Error(t, apputils.ValidateSignatureAlgorithm(appmodels.ECDSA_WITH_SHA256, rsaKey))
+	assert.Error(t, apputils.ValidateSignatureAlgorithm(appmodels.SHA256_WITH_RSA, nil))
+	assert.Error(t, apputils.ValidateSignatureAlgorithm(appmodels.SignatureAlgorithm(999), rsaKey))
+}
+
+func TestParseKeyType(t *testing.T) {
+	keyType, err := apputils.ParseKeyType("rsa_3072")
+	assert.NoError(t, err)
+	assert.Equal(t, appmodels.RSA_3072, keyType)
+
+	_, err = apputils.ParseKeyType("DSA_1024")
+	assert.Error(t, err)
+}
+
+func TestNewRootCertificate_RSAPSS(t *testing.T) {
+	privateKey, err := apputils.GeneratePrivateKey(appmodels.NewSerialNumber(1), appmodels.NewSerialNumber(1), appmodels.RSA_2048)
+	assert.NoError(t, err)
+
+	organization := appmodels.NewOrganization(appmodels.NewSerialNumber(1), "org", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
+	manager := managers.NewCertificateManager(managers.NewRandomManager())
+
+	cert, err := apputils.NewRootCertificate(
+		manager,
+		appmodels.NewSerialNumber(1),
+		organization,
+		time.Hour,
+		appmodels.SHA384_WITH_RSA_PSS,
+		privateKey,
+		"Test Root",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, x509.SHA384WithRSAPSS, cert.Certificate().SignatureAlgorithm)
+	assert.Equal(t, appmodels.SHA384_WITH_RSA_PSS, cert.SignatureAlgorithm())
+	assert.NoError(t, cert.Certificate().CheckSignatureFrom(cert.Certificate()))
+}
+
+func TestNewRootCertificate_IncompatibleSignatureAlgorithm(t *testing.T) {
+	privateKey, err := apputils.GeneratePrivateKey(appmodels.NewSerialNumber(1), appmodels.NewSerialNumber(1), appmodels.ECDSA_P256)
+	assert.NoError(t, err)
+
+	organization := appmodels.NewOrganization(appmodels.NewSerialNumber(1), "org", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
+	manager := managers.NewCertificateManager(managers.NewRandomManager())
+
+	_, err = apputils.NewRootCertificate(
+		manager,
+		appmodels.NewSerialNumber(1),
+		organization,
+		time.Hour,
+		appmodels.SHA256_WITH_RSA_PSS,
+		privateKey,
+		"Test Root",
+	)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "signatureAlgorithm")
+}
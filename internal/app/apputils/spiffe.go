@@ -0,0 +1,224 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+const (
+
+	// SpiffeScheme is the URI scheme of SPIFFE IDs
+	SpiffeScheme = "spiffe"
+
+	// SpiffeX509SvidUse is the key use of X.509 authorities in SPIFFE bundles
+	SpiffeX509SvidUse = "x509-svid"
+
+	// DefaultSpiffeBundleRefreshHint is how often bundle consumers should
+	// check for updates
+	DefaultSpiffeBundleRefreshHint = 5 * time.Minute
+
+	// maxSpiffeIDLength is the maximum length of a SPIFFE ID in bytes
+	maxSpiffeIDLength = 2048
+
+	// maxSpiffeTrustDomainLength is the maximum length of a trust domain name
+	maxSpiffeTrustDomainLength = 255
+)
+
+// ValidateSpiffeTrustDomain checks if the trust domain name contains only
+// lower case letters, digits, dots, dashes and underscores as required by the
+// SPIFFE ID specification
+func ValidateSpiffeTrustDomain(trustDomain string) error {
+	if trustDomain == "" {
+		return errors.New("cannot be empty")
+	}
+	if len(trustDomain) > maxSpiffeTrustDomainLength {
+		return fmt.Errorf("longer than %d characters", maxSpiffeTrustDomainLength)
+	}
+	for _, c := range trustDomain {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+			return fmt.Errorf("invalid character: '%c'", c)
+		}
+	}
+	return nil
+}
+
+// ParseSpiffeID parses and validates a SPIFFE ID of a workload, e.g.
+// "spiffe://example.org/ns/default/sa/web". The ID must have a path, since
+// the trust domain itself is not a workload.
+func ParseSpiffeID(value string) (*url.URL, error) {
+
+	if len(value) > maxSpiffeIDLength {
+		return nil, fmt.Errorf("ParseSpiffeID: longer than %d bytes", maxSpiffeIDLength)
+	}
+
+	rest, found := strings.CutPrefix(value, SpiffeScheme+"://")
+	if !found {
+		return nil, fmt.Errorf("ParseSpiffeID: scheme must be '%s': %s", SpiffeScheme, value)
+	}
+
+	trustDomain, path, _ := strings.Cut(rest, "/")
+	if err := ValidateSpiffeTrustDomain(trustDomain); err != nil {
+		return nil, fmt.Errorf("ParseSpiffeID: trust domain: %w", err)
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("ParseSpiffeID: path: must be defined")
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return nil, fmt.Errorf("ParseSpiffeID: path: invalid segment: '%s'", segment)
+		}
+		for _, c := range segment {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+				return nil, fmt.Errorf("ParseSpiffeID: path: invalid character: '%c'", c)
+			}
+		}
+	}
+
+	return &url.URL{
+		Scheme: SpiffeScheme,
+		Host:   trustDomain,
+		Path:   "/" + path,
+	}, nil
+}
+
+// NewSpiffeCertificate creates a new X.509-SVID. It has exactly one URI SAN,
+// is not a CA, and may be used for both client and server authentication.
+//   - manager: Certificate manager
+//   - serialNumber: Serial number for the new certificate
+//   - organization: The organization, which must have a SPIFFE trust domain
+//   - expiration: The expiration duration
+//   - signatureAlgorithm: The algorithm used to sign the new certificate
+//   - publicKey: The public key of the workload
+//   - parentCertificate: The issuing certificate
+//   - parentPrivateKey: The private key of the issuing certificate
+//   - spiffeID: The SPIFFE ID in the trust domain of the organization
+func NewSpiffeCertificate(
+	manager managers.CertificateManager,
+	serialNumber *big.Int,
+	organization appmodels.Organization,
+	expiration time.Duration,
+	signatureAlgorithm appmodels.SignatureAlgorithm,
+	publicKey appmodels.PublicKey,
+	parentCertificate appmodels.Certificate,
+	parentPrivateKey appmodels.PrivateKey,
+	spiffeID string,
+) (appmodels.Certificate, error) {
+
+	if manager == nil {
+		return nil, fmt.Errorf("NewSpiffeCertificate: manager: must be defined")
+	}
+
+	if serialNumber == nil {
+		return nil, fmt.Errorf("NewSpiffeCertificate: serialNumber: must be defined")
+	}
+
+	if organization == nil {
+		return nil, fmt.Errorf("NewSpiffeCertificate: organization: must be defined")
+	}
+
+	if parentCertificate == nil {
+		return nil, fmt.Errorf("NewSpiffeCertificate: parentCertificate: must be defined")
+	}
+
+	if parentPrivateKey == nil {
+		return nil, fmt.Errorf("NewSpiffeCertificate: parentPrivateKey: must be defined")
+	}
+
+	if publicKey == nil || publicKey.PublicKey() == nil {
+		return nil, fmt.Errorf("NewSpiffeCertificate: publicKey: must be defined")
+	}
+
+	if err := ValidateSignatureAlgorithm(signatureAlgorithm, parentPrivateKey); err != nil {
+		return nil, fmt.Errorf("NewSpiffeCertificate: signatureAlgorithm: %w", err)
+	}
+
+	if organization.SpiffeTrustDomain() == "" {
+		return nil, fmt.Errorf("NewSpiffeCertificate: organization: SPIFFE trust domain is not defined")
+	}
+
+	uri, err := ParseSpiffeID(spiffeID)
+	if err != nil {
+		return nil, fmt.Errorf("NewSpiffeCertificate: %w", err)
+	}
+	if uri.Host != organization.SpiffeTrustDomain() {
+		return nil, fmt.Errorf("NewSpiffeCertificate: spiffeID: not in trust domain '%s': %s", organization.SpiffeTrustDomain(), spiffeID)
+	}
+
+	// Key encipherment is only meaningful for RSA keys
+	keyUsage := x509.KeyUsageDigitalSignature
+	if _, ok := publicKey.PublicKey().(*rsa.PublicKey); ok {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	certificateTemplate := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: organization.Names(),
+		},
+		URIs:                  []*url.URL{uri},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(expiration),
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		SignatureAlgorithm:    signatureAlgorithm.X509(),
+	}
+
+	cert, err := CreateSignedCertificate(
+		manager,
+		&certificateTemplate,
+		parentCertificate.Certificate(),
+		publicKey.PublicKey(),
+		parentPrivateKey.PrivateKey(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("NewSpiffeCertificate: failed: %w", err)
+	}
+
+	return appmodels.NewCertificate(
+		organization.ID(),
+		parentCertificate.SerialNumber(),
+		cert,
+	), nil
+}
+
+// FilterSpiffeBundleCertificates returns the root certificates which are
+// valid at the given time
+func FilterSpiffeBundleCertificates(list []appmodels.Certificate, now time.Time) []appmodels.Certificate {
+	return FilterValidRootCertificates(list, now)
+}
+
+// ToSpiffeBundleDTO converts X.509 authorities to a SPIFFE bundle
+//   - certificates: The X.509 authorities
+//   - sequence: The sequence number of the bundle, or zero
+//   - refreshHint: The refresh hint, or zero
+func ToSpiffeBundleDTO(certificates []appmodels.Certificate, sequence uint64, refreshHint time.Duration) (appdtos.SpiffeBundleDTO, error) {
+	keys := make([]appdtos.SpiffeJwkDTO, len(certificates))
+	for i, certificate := range certificates {
+		jwk, err := ToJwkDTO(certificate.Certificate().PublicKey)
+		if err != nil {
+			return appdtos.SpiffeBundleDTO{}, fmt.Errorf("ToSpiffeBundleDTO: %s: %w", certificate.SerialNumber(), err)
+		}
+		keys[i] = appdtos.NewSpiffeJwkDTO(
+			jwk,
+			SpiffeX509SvidUse,
+			[]string{base64.StdEncoding.EncodeToString(certificate.Certificate().Raw)},
+		)
+	}
+	return appdtos.NewSpiffeBundleDTO(keys, sequence, int(refreshHint/time.Second)), nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestSpiffeRoot(t *testing.T, trustDomain string) (appmodels.Organization, appmodels.Certificate, appmodels.PrivateKey) {
+	organization := appmodels.NewOrganization(big.NewInt(1), "testorg", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, trustDomain, appmodels.NIL_KEY_RETENTION_POLICY)
+	privateKey, err := apputils.GeneratePrivateKey(organization.ID(), big.NewInt(2), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	certificate, err := apputils.NewRootCertificate(
+		managers.NewCertificateManager(managers.NewRandomManager()),
+		big.NewInt(2),
+		organization,
+		time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
+		privateKey,
+		"Test Root",
+	)
+	require.NoError(t, err)
+	return organization, certificate, privateKey
+}
+
+func TestValidateSpiffeTrustDomain(t *testing.T) {
+	assert.NoError(t, apputils.ValidateSpiffeTrustDomain("example.org"))
+	assert.NoError(t, apputils.ValidateSpiffeTrustDomain("my-domain_1.example"))
+	assert.Error(t, apputils.ValidateSpiffeTrustDomain(""))
+	assert.Error(t, apputils.ValidateSpiffeTrustDomain("Example.org"))
+	assert.Error(t, apputils.ValidateSpiffeTrustDomain("example.org:8080"))
+	assert.Error(t, apputils.ValidateSpiffeTrustDomain(string(make([]byte, 256))))
+}
+
+func TestParseSpiffeID(t *testing.T) {
+	uri, err := apputils.ParseSpiffeID("spiffe://example.org/ns/default/sa/web")
+	require.NoError(t, err)
+	assert.Equal(t, "example.org", uri.Host)
+	assert.Equal(t, "/ns/default/sa/web", uri.Path)
+	assert.Equal(t, "spiffe://example.org/ns/default/sa/web", uri.String())
+
+	for _, value := range []string{
+		"",
+		"https://example.org/web",
+		"spiffe://example.org",
+		"spiffe://example.org/",
+		"spiffe://example.org/web/",
+		"spiffe://example.org//web",
+		"spiffe://example.org/../web",
+		"spiffe://example.org/web?query",
+		"spiffe://example.org/web#fragment",
+		"spiffe://user@example.org/web",
+		"spiffe://EXAMPLE.org/web",
+	} {
+		_, err := apputils.ParseSpiffeID(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestNewSpiffeCertificate(t *testing.T) {
+	organization, root, rootKey := newTestSpiffeRoot(t, "example.org")
+	privateKey, err := apputils.GeneratePrivateKey(organization.ID(), big.NewInt(3), appmodels.RSA_2048)
+	require.NoError(t, err)
+
+	certificate, err := apputils.NewSpiffeCertificate(
+		managers.NewCertificateManager(managers.NewRandomManager()),
+		big.NewInt(3),
+		organization,
+		time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
+		appmodels.NewPublicKey(privateKey.PublicKey()),
+		root,
+		rootKey,
+		"spiffe://example.org/web",
+	)
+	require.NoError(t, err)
+
+	cert := certificate.Certificate()
+	require.Len(t, cert.URIs, 1)
+	assert.Equal(t, "spiffe://example.org/web", certificate.SpiffeID())
+	assert.False(t, cert.IsCA)
+	assert.Equal(t, x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment, cert.KeyUsage)
+	assert.ElementsMatch(t, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}, cert.ExtKeyUsage)
+	assert.Empty(t, cert.DNSNames)
+	assert.NoError(t, cert.CheckSignatureFrom(root.Certificate()))
+}
+
+func TestNewSpiffeCertificate_InvalidSpiffeID(t *testing.T) {
+	organization, root, rootKey := newTestSpiffeRoot(t, "example.org")
+	privateKey, err := apputils.GeneratePrivateKey(organization.ID(), big.NewInt(3), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+
+	for _, spiffeID := range []string{"spiffe://other.org/web", "spiffe://example.org", "web"} {
+		_, err := apputils.NewSpiffeCertificate(
+			managers.NewCertificateManager(managers.NewRandomManager()),
+			big.NewInt(3),
+			organization,
+			time.Hour,
+			appmodels.NIL_SIGNATURE_ALGORITHM,
+			appmodels.NewPublicKey(privateKey.PublicKey()),
+			root,
+			rootKey,
+			spiffeID,
+		)
+		assert.Error(t, err, spiffeID)
+	}
+}
+
+func TestNewSpiffeCertificate_NoTrustDomain(t *testing.T) {
+	organization, root, rootKey := newTestSpiffeRoot(t, "")
+	privateKey, err := apputils.GeneratePrivateKey(organization.ID(), big.NewInt(3), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+
+	_, err = apputils.NewSpiffeCertificate(
+		managers.NewCertificateManager(managers.NewRandomManager()),
+		big.NewInt(3),
+		organization,
+		time.Hour,
+		appmodels.NIL_SIGNATURE_ALGORITHM,
+		appmodels.NewPublicKey(privateKey.PublicKey()),
+		root,
+		rootKey,
+		"spiffe://example.org/web",
+	)
+	assert.ErrorContains(t, err, "trust domain")
+}
+
+func TestFilterSpiffeBundleCertificates(t *testing.T) {
+	_, root, _ := newTestSpiffeRoot(t, "example.org")
+	list := []appmodels.Certificate{root}
+	assert.Len(t, apputils.FilterSpiffeBundleCertificates(list, time.Now()), 1)
+	assert.Empty(t, apputils.FilterSpiffeBundleCertificates(list, time.Now().Add(2*time.Hour)))
+}
+
+func TestToSpiffeBundleDTO(t *testing.T) {
+	_, root, _ := newTestSpiffeRoot(t, "example.org")
+
+	dto, err := apputils.ToSpiffeBundleDTO([]appmodels.Certificate{root}, 0, apputils.DefaultSpiffeBundleRefreshHint)
+	require.NoError(t, err)
+	assert.Equal(t, 300, dto.RefreshHint)
+	require.Len(t, dto.Keys, 1)
+	assert.Equal(t, apputils.SpiffeX509SvidUse, dto.Keys[0].Use)
+	assert.Equal(t, "EC", dto.Keys[0].KeyType)
+	require.Len(t, dto.Keys[0].X5c, 1)
+	der, err := base64.StdEncoding.DecodeString(dto.Keys[0].X5c[0])
+	require.NoError(t, err)
+	assert.Equal(t, root.Certificate().Raw, der)
+}
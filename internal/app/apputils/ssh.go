@@ -0,0 +1,372 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+const (
+
+	// SshKrlMagic is the magic number of OpenSSH key revocation lists
+	SshKrlMagic = "SSHKRL\n\x00"
+
+	// SshKrlFormatVersion is the format version of OpenSSH key revocation
+	// lists
+	SshKrlFormatVersion = 1
+
+	// sshKrlSectionCertificates is the KRL section of revoked certificates
+	sshKrlSectionCertificates = 1
+
+	// sshKrlCertSectionSerialList is the certificate subsection of revoked
+	// serial numbers
+	sshKrlCertSectionSerialList = 0x20
+)
+
+// SshDefaultUserExtensions are the extensions ssh-keygen adds to user
+// certificates by default
+var SshDefaultUserExtensions = map[string]string{
+	"permit-X11-forwarding":   "",
+	"permit-agent-forwarding": "",
+	"permit-port-forwarding":  "",
+	"permit-pty":              "",
+	"permit-user-rc":          "",
+}
+
+// sshUserExtensions are the extensions OpenSSH defines for user certificates
+var sshUserExtensions = map[string]bool{
+	"no-touch-required":       true,
+	"permit-X11-forwarding":   true,
+	"permit-agent-forwarding": true,
+	"permit-port-forwarding":  true,
+	"permit-pty":              true,
+	"permit-user-rc":          true,
+}
+
+// NewSshSigner creates an SSH signer from a private key. RSA keys sign with
+// rsa-sha2-512 since ssh-rsa signatures are no longer accepted by OpenSSH.
+func NewSshSigner(privateKey appmodels.PrivateKey) (ssh.Signer, error) {
+	if privateKey == nil {
+		return nil, fmt.Errorf("NewSshSigner: privateKey: must be defined")
+	}
+	signer, err := ssh.NewSignerFromKey(privateKey.PrivateKey())
+	if err != nil {
+		return nil, fmt.Errorf("NewSshSigner: %w", err)
+	}
+	if _, ok := privateKey.PrivateKey().(*rsa.PrivateKey); ok {
+		algorithmSigner, ok := signer.(ssh.AlgorithmSigner)
+		if !ok {
+			return nil, fmt.Errorf("NewSshSigner: rsa: algorithm signer not supported")
+		}
+		signer, err = ssh.NewSignerWithAlgorithms(algorithmSigner, []string{ssh.KeyAlgoRSASHA512})
+		if err != nil {
+			return nil, fmt.Errorf("NewSshSigner: %w", err)
+		}
+	}
+	return signer, nil
+}
+
+// ParseSshPublicKey parses a public key in the authorized_keys format.
+// Certificates are not accepted.
+func ParseSshPublicKey(value string) (ssh.PublicKey, error) {
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(value))
+	if err != nil {
+		return nil, fmt.Errorf("ParseSshPublicKey: %w", err)
+	}
+	if _, ok := publicKey.(*ssh.Certificate); ok {
+		return nil, fmt.Errorf("ParseSshPublicKey: certificates are not supported")
+	}
+	return publicKey, nil
+}
+
+// ParseSshCertificateType parses a certificate type name "user" or "host"
+func ParseSshCertificateType(value string) (appmodels.SshCertificateType, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "user":
+		return appmodels.SSH_USER_CERTIFICATE, nil
+	case "host":
+		return appmodels.SSH_HOST_CERTIFICATE, nil
+	default:
+		return appmodels.NIL_SSH_CERTIFICATE_TYPE, fmt.Errorf("ParseSshCertificateType: unsupported: %s", value)
+	}
+}
+
+// SshCertificateTypeName returns the name of a certificate type as used by
+// ParseSshCertificateType
+func SshCertificateTypeName(certificateType appmodels.SshCertificateType) string {
+	switch certificateType {
+	case appmodels.SSH_USER_CERTIFICATE:
+		return "user"
+	case appmodels.SSH_HOST_CERTIFICATE:
+		return "host"
+	default:
+		return ""
+	}
+}
+
+// FormatSshPublicKey formats a public key or a certificate in the
+// authorized_keys format without a trailing new line
+func FormatSshPublicKey(publicKey ssh.PublicKey) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+}
+
+// FormatSshAuthorizedKeysAuthority formats a CA public key as an
+// authorized_keys line which trusts user certificates signed by the CA
+func FormatSshAuthorizedKeysAuthority(publicKey ssh.PublicKey) string {
+	return "cert-authority " + FormatSshPublicKey(publicKey)
+}
+
+// FormatSshKnownHostsAuthority formats a CA public key as a known_hosts line
+// which trusts host certificates signed by the CA
+//   - publicKey: The CA public key
+//   - hosts: The host name patterns, or empty for all hosts
+func FormatSshKnownHostsAuthority(publicKey ssh.PublicKey, hosts []string) string {
+	pattern := "*"
+	if len(hosts) != 0 {
+		pattern = strings.Join(hosts, ",")
+	}
+	return "@cert-authority " + pattern + " " + FormatSshPublicKey(publicKey)
+}
+
+// ValidateSshPrincipals checks the principals of a certificate. At least one
+// principal is required, since a certificate without principals is valid for
+// any user or host.
+func ValidateSshPrincipals(principals []string) error {
+	if len(principals) == 0 {
+		return errors.New("at least one principal is required")
+	}
+	for _, principal := range principals {
+		if principal == "" || strings.ContainsAny(principal, ", \t\r\n") {
+			return fmt.Errorf("invalid principal: '%s'", principal)
+		}
+	}
+	return nil
+}
+
+// ValidateSshCriticalOptions checks the critical options of a certificate.
+// OpenSSH defines critical options only for user certificates, and rejects
+// certificates with unknown critical options.
+func ValidateSshCriticalOptions(certificateType appmodels.SshCertificateType, options map[string]string) error {
+	for name, value := range options {
+		if certificateType != appmodels.SSH_USER_CERTIFICATE {
+			return fmt.Errorf("critical options are not supported for host certificates: %s", name)
+		}
+		switch name {
+		case "force-command":
+			if value == "" {
+				return fmt.Errorf("%s: must be defined", name)
+			}
+		case "source-address":
+			for _, address := range strings.Split(value, ",") {
+				if _, _, err := net.ParseCIDR(address); err != nil && net.ParseIP(address) == nil {
+					return fmt.Errorf("%s: invalid address: '%s'", name, address)
+				}
+			}
+		case "verify-required":
+			if value != "" {
+				return fmt.Errorf("%s: must not have a value", name)
+			}
+		default:
+			return fmt.Errorf("unsupported critical option: %s", name)
+		}
+	}
+	return nil
+}
+
+// ValidateSshExtensions checks the extensions of a certificate. Vendor
+// extensions must have a domain suffix, e.g. "name@example.com".
+func ValidateSshExtensions(certificateType appmodels.SshCertificateType, extensions map[string]string) error {
+	for name := range extensions {
+		if strings.Contains(name, "@") {
+			continue
+		}
+		if certificateType != appmodels.SSH_USER_CERTIFICATE || !sshUserExtensions[name] {
+			return fmt.Errorf("unsupported extension: %s", name)
+		}
+	}
+	return nil
+}
+
+// EncodeSshKrl encodes an OpenSSH key revocation list (KRL) which revokes
+// certificates of a CA by their serial numbers. See PROTOCOL.krl in OpenSSH.
+//   - caKey: The CA public key
+//   - serials: The serial numbers of revoked certificates
+//   - version: The version of the list, which must increase on changes
+//   - generatedAt: The generation time
+//   - comment: Free form comment
+func EncodeSshKrl(caKey ssh.PublicKey, serials []uint64, version uint64, generatedAt time.Time, comment string) ([]byte, error) {
+
+	if caKey == nil {
+		return nil, fmt.Errorf("EncodeSshKrl: caKey: must be defined")
+	}
+
+	sorted := append([]uint64(nil), serials...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var buffer bytes.Buffer
+	buffer.WriteString(SshKrlMagic)
+	writeSshUint32(&buffer, SshKrlFormatVersion)
+	writeSshUint64(&buffer, version)
+	writeSshUint64(&buffer, uint64(generatedAt.Unix()))
+	writeSshUint64(&buffer, 0) // flags
+	writeSshString(&buffer, nil)
+	writeSshString(&buffer, []byte(comment))
+
+	if len(sorted) != 0 {
+		var serialList bytes.Buffer
+		for _, serial := range sorted {
+			writeSshUint64(&serialList, serial)
+		}
+		var section bytes.Buffer
+		writeSshString(&section, caKey.Marshal())
+		writeSshString(&section, nil)
+		section.WriteByte(sshKrlCertSectionSerialList)
+		writeSshString(&section, serialList.Bytes())
+
+		buffer.WriteByte(sshKrlSectionCertificates)
+		writeSshString(&buffer, section.Bytes())
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func ToSshAuthorityDTO(authority appmodels.SshAuthority) (appdtos.SshAuthorityDTO, error) {
+	signer, err := NewSshSigner(authority.PrivateKey())
+	if err != nil {
+		return appdtos.SshAuthorityDTO{}, fmt.Errorf("ToSshAuthorityDTO: %w", err)
+	}
+	return appdtos.NewSshAuthorityDTO(
+		authority.OrganizationID().String(),
+		authority.PrivateKey().KeyType().String(),
+		FormatSshPublicKey(signer.PublicKey()),
+	), nil
+}
+
+func ToSshCertificateDTO(certificate appmodels.SshCertificate) appdtos.SshCertificateDTO {
+	return appdtos.NewSshCertificateDTO(
+		certificate.OrganizationID().String(),
+		strconv.FormatUint(certificate.Serial(), 10),
+		SshCertificateTypeName(certificate.Type()),
+		certificate.KeyID(),
+		certificate.Principals(),
+		certificate.ValidAfter(),
+		certificate.ValidBefore(),
+		certificate.IsRevoked(),
+		FormatSshPublicKey(certificate.Certificate()),
+	)
+}
+
+func ToSshCertificateListDTO(list []appmodels.SshCertificate) appdtos.SshCertificateListDTO {
+	payload := make([]appdtos.SshCertificateDTO, len(list))
+	for i, v := range list {
+		payload[i] = ToSshCertificateDTO(v)
+	}
+	return appdtos.NewSshCertificateListDTO(payload)
+}
+
+func writeSshUint32(buffer *bytes.Buffer, value uint32) {
+	_ = binary.Write(buffer, binary.BigEndian, value)
+}
+
+func writeSshUint64(buffer *bytes.Buffer, value uint64) {
+	_ = binary.Write(buffer, binary.BigEndian, value)
+}
+
+func writeSshString(buffer *bytes.Buffer, value []byte) {
+	writeSshUint32(buffer, uint32(len(value)))
+	buffer.Write(value)
+}
+
+// ParseSshCertificate parses a certificate in the OpenSSH wire format
+func ParseSshCertificate(data []byte) (*ssh.Certificate, error) {
+	publicKey, err := ssh.ParsePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("ParseSshCertificate: %w", err)
+	}
+	certificate, ok := publicKey.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("ParseSshCertificate: not a certificate: %s", publicKey.Type())
+	}
+	return certificate, nil
+}
+
+// ToSshAuthorityRecordDTO converts an SSH CA to its stored form. A sealed
+// private key stays encrypted.
+func ToSshAuthorityRecordDTO(
+	certManager managers.CertificateManager,
+	authority appmodels.SshAuthority,
+) (appdtos.SshAuthorityRecordDTO, error) {
+	pemData, err := MarshalPrivateKeyAsPEM(certManager, authority.PrivateKey().PrivateKey())
+	if err != nil {
+		return appdtos.SshAuthorityRecordDTO{}, fmt.Errorf("ToSshAuthorityRecordDTO: %w", err)
+	}
+	return appdtos.NewSshAuthorityRecordDTO(
+		authority.OrganizationID().String(),
+		authority.ID().String(),
+		string(pemData),
+		authority.CreatedAt(),
+	), nil
+}
+
+// FromSshAuthorityRecordDTO converts the stored form of an SSH CA to a model
+func FromSshAuthorityRecordDTO(
+	certManager managers.CertificateManager,
+	dto appdtos.SshAuthorityRecordDTO,
+) (appmodels.SshAuthority, error) {
+	organization, err := ParseBigInt(dto.Organization, 10)
+	if err != nil {
+		return nil, fmt.Errorf("FromSshAuthorityRecordDTO: invalid organization: %w", err)
+	}
+	id, err := ParseBigInt(dto.ID, 10)
+	if err != nil {
+		return nil, fmt.Errorf("FromSshAuthorityRecordDTO: invalid id: %w", err)
+	}
+	privateKey, keyType, err := ParsePrivateKeyFromPEMBytes(certManager, []byte(dto.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("FromSshAuthorityRecordDTO: %w", err)
+	}
+	return appmodels.NewSshAuthority(
+		organization,
+		id,
+		appmodels.NewPrivateKey(organization, id, keyType, privateKey),
+		dto.CreatedAt,
+	), nil
+}
+
+// ToSshCertificateRecordDTO converts an SSH certificate to its stored form
+func ToSshCertificateRecordDTO(certificate appmodels.SshCertificate) appdtos.SshCertificateRecordDTO {
+	return appdtos.NewSshCertificateRecordDTO(
+		certificate.OrganizationID().String(),
+		certificate.Certificate().Marshal(),
+		certificate.RevokedAt(),
+	)
+}
+
+// FromSshCertificateRecordDTO converts the stored form of an SSH certificate
+// to a model
+func FromSshCertificateRecordDTO(dto appdtos.SshCertificateRecordDTO) (appmodels.SshCertificate, error) {
+	organization, err := ParseBigInt(dto.Organization, 10)
+	if err != nil {
+		return nil, fmt.Errorf("FromSshCertificateRecordDTO: invalid organization: %w", err)
+	}
+	certificate, err := ParseSshCertificate(dto.Certificate)
+	if err != nil {
+		return nil, fmt.Errorf("FromSshCertificateRecordDTO: %w", err)
+	}
+	return appmodels.NewSshCertificate(organization, certificate, dto.RevokedAt), nil
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func newTestSshAuthority(t *testing.T, keyType appmodels.KeyType) appmodels.SshAuthority {
+	privateKey, err := apputils.GeneratePrivateKey(big.NewInt(1), big.NewInt(2), keyType)
+	require.NoError(t, err)
+	return appmodels.NewSshAuthority(big.NewInt(1), big.NewInt(2), privateKey, time.Now())
+}
+
+func TestNewSshSigner(t *testing.T) {
+	tests := []struct {
+		keyType   appmodels.KeyType
+		algorithm string
+	}{
+		{appmodels.RSA_2048, ssh.KeyAlgoRSASHA512},
+		{appmodels.ECDSA_P256, ssh.KeyAlgoECDSA256},
+		{appmodels.ECDSA_P384, ssh.KeyAlgoECDSA384},
+		{appmodels.Ed25519, ssh.KeyAlgoED25519},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keyType.String(), func(t *testing.T) {
+			signer, err := apputils.NewSshSigner(newTestSshAuthority(t, tt.keyType).PrivateKey())
+			require.NoError(t, err)
+			certificate := &ssh.Certificate{Key: signer.PublicKey(), CertType: ssh.UserCert}
+			require.NoError(t, certificate.SignCert(rand.Reader, signer))
+			assert.Equal(t, tt.algorithm, certificate.Signature.Format)
+		})
+	}
+
+	_, err := apputils.NewSshSigner(nil)
+	assert.Error(t, err)
+}
+
+func TestParseSshPublicKey(t *testing.T) {
+	signer, err := apputils.NewSshSigner(newTestSshAuthority(t, appmodels.Ed25519).PrivateKey())
+	require.NoError(t, err)
+	line := apputils.FormatSshPublicKey(signer.PublicKey()) + " comment"
+
+	publicKey, err := apputils.ParseSshPublicKey(line)
+	require.NoError(t, err)
+	assert.Equal(t, signer.PublicKey().Marshal(), publicKey.Marshal())
+
+	certificate := &ssh.Certificate{Key: publicKey, CertType: ssh.UserCert}
+	require.NoError(t, certificate.SignCert(rand.Reader, signer))
+	_, err = apputils.ParseSshPublicKey(apputils.FormatSshPublicKey(certificate))
+	assert.Error(t, err)
+
+	_, err = apputils.ParseSshPublicKey("invalid")
+	assert.Error(t, err)
+}
+
+func TestParseSshCertificateType(t *testing.T) {
+	certificateType, err := apputils.ParseSshCertificateType("user")
+	assert.NoError(t, err)
+	assert.Equal(t, appmodels.SSH_USER_CERTIFICATE, certificateType)
+	assert.Equal(t, "user", apputils.SshCertificateTypeName(certificateType))
+
+	certificateType, err = apputils.ParseSshCertificateType(" Host ")
+	assert.NoError(t, err)
+	assert.Equal(t, appmodels.SSH_HOST_CERTIFICATE, certificateType)
+	assert.Equal(t, "host", apputils.SshCertificateTypeName(certificateType))
+
+	_, err = apputils.ParseSshCertificateType("other")
+	assert.Error(t, err)
+	assert.Equal(t, "", apputils.SshCertificateTypeName(appmodels.NIL_SSH_CERTIFICATE_TYPE))
+}
+
+func TestFormatSshAuthority(t *testing.T) {
+	signer, err := apputils.NewSshSigner(newTestSshAuthority(t, appmodels.Ed25519).PrivateKey())
+	require.NoError(t, err)
+	key := apputils.FormatSshPublicKey(signer.PublicKey())
+	assert.True(t, strings.HasPrefix(key, "ssh-ed25519 "))
+
+	assert.Equal(t, "cert-authority "+key, apputils.FormatSshAuthorizedKeysAuthority(signer.PublicKey()))
+	assert.Equal(t, "@cert-authority * "+key, apputils.FormatSshKnownHostsAuthority(signer.PublicKey(), nil))
+	assert.Equal(t, "@cert-authority *.example.com,10.0.0.1 "+key, apputils.FormatSshKnownHostsAuthority(signer.PublicKey(), []string{"*.example.com", "10.0.0.1"}))
+}
+
+func TestValidateSshPrincipals(t *testing.T) {
+	assert.NoError(t, apputils.ValidateSshPrincipals([]string{"alice", "root"}))
+	assert.Error(t, apputils.ValidateSshPrincipals(nil))
+	assert.Error(t, apputils.ValidateSshPrincipals([]string{""}))
+	assert.Error(t, apputils.ValidateSshPrincipals([]string{"alice,root"}))
+	assert.Error(t, apputils.ValidateSshPrincipals([]string{"alice root"}))
+}
+
+func TestValidateSshCriticalOptions(t *testing.T) {
+	assert.NoError(t, apputils.ValidateSshCriticalOptions(appmodels.SSH_USER_CERTIFICATE, map[string]string{
+		"force-command":   "/bin/true",
+		"source-address":  "10.0.0.0/8,192.168.1.1",
+		"verify-required": "",
+	}))
+	assert.NoError(t, apputils.ValidateSshCriticalOptions(appmodels.SSH_HOST_CERTIFICATE, nil))
+	assert.Error(t, apputils.ValidateSshCriticalOptions(appmodels.SSH_USER_CERTIFICATE, map[string]string{"force-command": ""}))
+	assert.Error(t, apputils.ValidateSshCriticalOptions(appmodels.SSH_USER_CERTIFICATE, map[string]string{"source-address": "invalid"}))
+	assert.Error(t, apputils.ValidateSshCriticalOptions(appmodels.SSH_USER_CERTIFICATE, map[string]string{"verify-required": "yes"}))
+	assert.Error(t, apputils.ValidateSshCriticalOptions(appmodels.SSH_USER_CERTIFICATE, map[string]string{"unknown": ""}))
+	assert.Error(t, apputils.ValidateSshCriticalOptions(appmodels.SSH_HOST_CERTIFICATE, map[string]string{"force-command": "/bin/true"}))
+}
+
+func TestValidateSshExtensions(t *testing.T) {
+	assert.NoError(t, apputils.ValidateSshExtensions(appmodels.SSH_USER_CERTIFICATE, apputils.SshDefaultUserExtensions))
+	assert.NoError(t, apputils.ValidateSshExtensions(appmodels.SSH_HOST_CERTIFICATE, map[string]string{"name@example.com": "value"}))
+	assert.Error(t, apputils.ValidateSshExtensions(appmodels.SSH_USER_CERTIFICATE, map[string]string{"unknown": ""}))
+	assert.Error(t, apputils.ValidateSshExtensions(appmodels.SSH_HOST_CERTIFICATE, map[string]string{"permit-pty": ""}))
+}
+
+func TestEncodeSshKrl(t *testing.T) {
+	signer, err := apputils.NewSshSigner(newTestSshAuthority(t, appmodels.Ed25519).PrivateKey())
+	require.NoError(t, err)
+
+	krl, err := apputils.EncodeSshKrl(signer.PublicKey(), []uint64{20, 10}, 5, time.Unix(1000, 0), "test")
+	require.NoError(t, err)
+
+	reader := bytes.NewReader(krl)
+	readBytes := func(n int) []byte {
+		value := make([]byte, n)
+		_, err := reader.Read(value)
+		require.NoError(t, err)
+		return value
+	}
+	readUint32 := func() uint32 { return binary.BigEndian.Uint32(readBytes(4)) }
+	readUint64 := func() uint64 { return binary.BigEndian.Uint64(readBytes(8)) }
+	readString := func() []byte { return readBytes(int(readUint32())) }
+
+	assert.Equal(t, []byte(apputils.SshKrlMagic), readBytes(8))
+	assert.Equal(t, uint32(apputils.SshKrlFormatVersion), readUint32())
+	assert.Equal(t, uint64(5), readUint64())
+	assert.Equal(t, uint64(1000), readUint64())
+	assert.Equal(t, uint64(0), readUint64())
+	assert.Equal(t, 0, int(readUint32()))
+	assert.Equal(t, []byte("test"), readString())
+
+	assert.Equal(t, []byte{1}, readBytes(1))
+	section := readString()
+	assert.Equal(t, 0, reader.Len())
+
+	reader = bytes.NewReader(section)
+	assert.Equal(t, signer.PublicKey().Marshal(), readString())
+	assert.Equal(t, 0, int(readUint32()))
+	assert.Equal(t, []byte{0x20}, readBytes(1))
+	assert.Equal(t, 16, int(readUint32()))
+	assert.Equal(t, uint64(10), readUint64())
+	assert.Equal(t, uint64(20), readUint64())
+	assert.Equal(t, 0, reader.Len())
+
+	empty, err := apputils.EncodeSshKrl(signer.PublicKey(), nil, 0, time.Unix(1000, 0), "")
+	require.NoError(t, err)
+	assert.Len(t, empty, 8+4+8+8+8+4+4)
+
+	_, err = apputils.EncodeSshKrl(nil, nil, 0, time.Now(), "")
+	assert.Error(t, err)
+}
+
+func TestToSshDTOs(t *testing.T) {
+	authority := newTestSshAuthority(t, appmodels.Ed25519)
+	dto, err := apputils.ToSshAuthorityDTO(authority)
+	require.NoError(t, err)
+	assert.Equal(t, "1", dto.Organization)
+	assert.Equal(t, "Ed25519", dto.KeyType)
+	assert.True(t, strings.HasPrefix(dto.PublicKey, "ssh-ed25519 "))
+
+	signer, err := apputils.NewSshSigner(authority.PrivateKey())
+	require.NoError(t, err)
+	certificate := &ssh.Certificate{
+		Key:             signer.PublicKey(),
+		Serial:          42,
+		CertType:        ssh.HostCert,
+		KeyId:           "host",
+		ValidPrincipals: []string{"host.example.com"},
+		ValidAfter:      1000,
+		ValidBefore:     2000,
+	}
+	require.NoError(t, certificate.SignCert(rand.Reader, signer))
+
+	list := apputils.ToSshCertificateListDTO([]appmodels.SshCertificate{appmodels.NewSshCertificate(big.NewInt(1), certificate, time.Now())})
+	require.Len(t, list.Payload, 1)
+	certificateDTO := list.Payload[0]
+	assert.Equal(t, "1", certificateDTO.Organization)
+	assert.Equal(t, "42", certificateDTO.Serial)
+	assert.Equal(t, "host", certificateDTO.Type)
+	assert.Equal(t, "host", certificateDTO.KeyID)
+	assert.Equal(t, []string{"host.example.com"}, certificateDTO.Principals)
+	assert.Equal(t, time.Unix(1000, 0), certificateDTO.ValidAfter)
+	assert.True(t, certificateDTO.Revoked)
+	assert.True(t, strings.HasPrefix(certificateDTO.Certificate, "ssh-ed25519-cert-v01@openssh.com "))
+}
+
+func TestSshRecordDTOs(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	authority := newTestSshAuthority(t, appmodels.Ed25519)
+
+	authorityDTO, err := apputils.ToSshAuthorityRecordDTO(certManager, authority)
+	require.NoError(t, err)
+	assert.Equal(t, "1", authorityDTO.Organization)
+	assert.Equal(t, "2", authorityDTO.ID)
+
+	parsedAuthority, err := apputils.FromSshAuthorityRecordDTO(certManager, authorityDTO)
+	require.NoError(t, err)
+	assert.Equal(t, authority.ID(), parsedAuthority.ID())
+	assert.Equal(t, appmodels.Ed25519, parsedAuthority.PrivateKey().KeyType())
+	assert.Equal(t, authority.PrivateKey().PublicKey(), parsedAuthority.PrivateKey().PublicKey())
+
+	signer, err := apputils.NewSshSigner(authority.PrivateKey())
+	require.NoError(t, err)
+	certificate := &ssh.Certificate{
+		Key:             signer.PublicKey(),
+		Serial:          42,
+		CertType:        ssh.UserCert,
+		KeyId:           "alice",
+		ValidPrincipals: []string{"alice"},
+		ValidAfter:      1000,
+		ValidBefore:     2000,
+	}
+	require.NoError(t, certificate.SignCert(rand.Reader, signer))
+	revokedAt := time.Unix(1500, 0).UTC()
+
+	certificateDTO := apputils.ToSshCertificateRecordDTO(appmodels.NewSshCertificate(big.NewInt(1), certificate, revokedAt))
+	parsedCertificate, err := apputils.FromSshCertificateRecordDTO(certificateDTO)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), parsedCertificate.OrganizationID())
+	assert.Equal(t, uint64(42), parsedCertificate.Serial())
+	assert.Equal(t, []string{"alice"}, parsedCertificate.Principals())
+	assert.True(t, revokedAt.Equal(parsedCertificate.RevokedAt()))
+	assert.Equal(t, certificate.Marshal(), parsedCertificate.Certificate().Marshal())
+
+	_, err = apputils.ParseSshCertificate(signer.PublicKey().Marshal())
+	assert.Error(t, err)
+	_, err = apputils.FromSshAuthorityRecordDTO(certManager, appdtos.NewSshAuthorityRecordDTO("1", "2", "invalid", time.Now()))
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"crypto/tls"
+	"errors"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+)
+
+// ToTlsCertificate converts a certificate chain and the private key of the
+// first certificate to a certificate for crypto/tls
+//   - chain: The leaf certificate followed by its issuers
+//   - privateKey: The private key of the leaf certificate
+func ToTlsCertificate(chain []appmodels.Certificate, privateKey appmodels.PrivateKey) (*tls.Certificate, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("ToTlsCertificate: no certificates")
+	}
+	if privateKey == nil || privateKey.PrivateKey() == nil {
+		return nil, errors.New("ToTlsCertificate: no private key")
+	}
+	if _, ok := privateKey.PrivateKey().(appmodels.SealedPrivateKey); ok {
+		return nil, errors.New("ToTlsCertificate: private key is sealed")
+	}
+	if !publicKeyMatches(chain[0].Certificate().PublicKey, privateKey.PrivateKey()) {
+		return nil, errors.New("ToTlsCertificate: private key does not belong to the certificate")
+	}
+	raw := make([][]byte, len(chain))
+	for i, certificate := range chain {
+		raw[i] = certificate.Certificate().Raw
+	}
+	return &tls.Certificate{
+		Certificate: raw,
+		PrivateKey:  privateKey.PrivateKey(),
+		Leaf:        chain[0].Certificate(),
+	}, nil
+}
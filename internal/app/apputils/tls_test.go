@@ -0,0 +1,34 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+)
+
+func TestToTlsCertificate(t *testing.T) {
+	_, certificate, privateKey := newTestSpiffeRoot(t, "")
+
+	tlsCertificate, err := apputils.ToTlsCertificate([]appmodels.Certificate{certificate}, privateKey)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{certificate.Certificate().Raw}, tlsCertificate.Certificate)
+	assert.Equal(t, privateKey.PrivateKey(), tlsCertificate.PrivateKey)
+	assert.Equal(t, certificate.Certificate(), tlsCertificate.Leaf)
+
+	_, err = apputils.ToTlsCertificate(nil, privateKey)
+	assert.Error(t, err)
+	_, err = apputils.ToTlsCertificate([]appmodels.Certificate{certificate}, nil)
+	assert.Error(t, err)
+
+	otherKey, err := apputils.GeneratePrivateKey(big.NewInt(1), big.NewInt(3), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	_, err = apputils.ToTlsCertificate([]appmodels.Certificate{certificate}, otherKey)
+	assert.ErrorContains(t, err, "does not belong")
+}
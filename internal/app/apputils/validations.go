@@ -183,6 +183,19 @@ func ValidateOrganizationSlug(id string) error {
 	return nil
 }
 
+// ValidateEstLabel checks if the provided EST label is a lower case path
+// segment
+func ValidateEstLabel(label string) error {
+	if label == "" {
+		return errors.New("cannot be empty")
+	}
+	matched, _ := regexp.MatchString(`^[a-z0-9\-.]+$`, label)
+	if !matched || label != strings.Trim(label, "-.") {
+		return errors.New("contains invalid characters, or has leading/trailing '-' or '.'")
+	}
+	return nil
+}
+
 func ValidateOrganizationModel(model appmodels.Organization) error {
 	id := model.Slug()
 	if err := ValidateOrganizationSlug(id); err != nil {
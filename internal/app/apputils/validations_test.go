@@ -471,3 +471,24 @@ func TestValidateDNSNames(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateEstLabel(t *testing.T) {
+	tests := []struct {
+		label   string
+		wantErr bool
+	}{
+		{"devices", false},
+		{"lab-1.example", false},
+		{"", true},
+		{"Devices", true},
+		{"-devices", true},
+		{"a/b", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			if err := apputils.ValidateEstLabel(tt.label); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateEstLabel(%q) error = %v, wantErr %v", tt.label, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,423 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package apputils
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appdtos"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// VaultErrorStatusCode returns the HTTP status code of a Vault error type
+func VaultErrorStatusCode(errorType appmodels.VaultErrorType) int {
+	switch errorType {
+	case appmodels.VAULT_ERROR_BAD_REQUEST:
+		return http.StatusBadRequest
+	case appmodels.VAULT_ERROR_PERMISSION_DENIED:
+		return http.StatusForbidden
+	case appmodels.VAULT_ERROR_NOT_FOUND:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ParseVaultDuration parses a Vault duration, which is a number of seconds or
+// a string like "72h", "30m", "3600" or "7d". Empty values return zero.
+func ParseVaultDuration(value any) (time.Duration, error) {
+	switch typed := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		if typed < 0 {
+			return 0, fmt.Errorf("ParseVaultDuration: must not be negative: %v", typed)
+		}
+		return time.Duration(typed) * time.Second, nil
+	case int:
+		if typed < 0 {
+			return 0, fmt.Errorf("ParseVaultDuration: must not be negative: %d", typed)
+		}
+		return time.Duration(typed) * time.Second, nil
+	case string:
+		typed = strings.TrimSpace(typed)
+		if typed == "" {
+			return 0, nil
+		}
+		if seconds, err := strconv.ParseUint(typed, 10, 32); err == nil {
+			return time.Duration(seconds) * time.Second, nil
+		}
+		if days, found := strings.CutSuffix(typed, "d"); found {
+			count, err := strconv.ParseUint(days, 10, 16)
+			if err != nil {
+				return 0, fmt.Errorf("ParseVaultDuration: invalid: %s", typed)
+			}
+			return time.Duration(count) * 24 * time.Hour, nil
+		}
+		duration, err := time.ParseDuration(typed)
+		if err != nil || duration < 0 {
+			return 0, fmt.Errorf("ParseVaultDuration: invalid: %s", typed)
+		}
+		return duration, nil
+	default:
+		return 0, fmt.Errorf("ParseVaultDuration: unsupported type: %T", value)
+	}
+}
+
+// ParseVaultStringList parses a list of strings or a comma separated string.
+// Empty items are ignored.
+func ParseVaultStringList(value any) ([]string, error) {
+	var items []string
+	switch typed := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		items = strings.Split(typed, ",")
+	case []string:
+		items = typed
+	case []any:
+		for _, item := range typed {
+			text, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("ParseVaultStringList: unsupported item type: %T", item)
+			}
+			items = append(items, text)
+		}
+	default:
+		return nil, fmt.Errorf("ParseVaultStringList: unsupported type: %T", value)
+	}
+	var list []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list, nil
+}
+
+// FormatVaultSerialNumber formats a serial number like Vault, as colon
+// separated hex bytes, e.g. "39:dd:2e"
+func FormatVaultSerialNumber(serialNumber *big.Int) string {
+	data := serialNumber.Bytes()
+	if len(data) == 0 {
+		data = []byte{0}
+	}
+	parts := make([]string, len(data))
+	for i, b := range data {
+		parts[i] = hex.EncodeToString([]byte{b})
+	}
+	return strings.Join(parts, ":")
+}
+
+// ParseVaultSerialNumber parses a colon or hyphen separated hex serial number
+func ParseVaultSerialNumber(value string) (*big.Int, error) {
+	value = strings.NewReplacer(":", "", "-", "").Replace(strings.TrimSpace(value))
+	if value == "" {
+		return nil, errors.New("ParseVaultSerialNumber: cannot be empty")
+	}
+	serialNumber, ok := new(big.Int).SetString(value, 16)
+	if !ok || serialNumber.Sign() < 0 {
+		return nil, fmt.Errorf("ParseVaultSerialNumber: invalid: %s", value)
+	}
+	return serialNumber, nil
+}
+
+// ParseVaultCsr parses a PEM encoded certificate signing request
+func ParseVaultCsr(value string) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode([]byte(value))
+	if block == nil {
+		return nil, errors.New("ParseVaultCsr: no PEM data found")
+	}
+	if block.Type != "CERTIFICATE REQUEST" && block.Type != "NEW CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("ParseVaultCsr: unsupported PEM type: %s", block.Type)
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("ParseVaultCsr: %w", err)
+	}
+	return csr, nil
+}
+
+// ParseVaultKeyType parses the key_type and key_bits of a Vault role. The
+// key type "any" or an empty string returns appmodels.NIL_KEY_TYPE.
+func ParseVaultKeyType(keyType string, keyBits int) (appmodels.KeyType, error) {
+	switch strings.ToLower(strings.TrimSpace(keyType)) {
+	case "", "any":
+		return appmodels.NIL_KEY_TYPE, nil
+	case "rsa":
+		if keyBits == 0 {
+			return appmodels.RSA_2048, nil
+		}
+		if keyBits < 2048 {
+			return appmodels.NIL_KEY_TYPE, fmt.Errorf("ParseVaultKeyType: RSA key size not supported: %d", keyBits)
+		}
+		return DetermineRSATypeFromSize(keyBits)
+	case "ec":
+		switch keyBits {
+		case 0, 256:
+			return appmodels.ECDSA_P256, nil
+		case 224:
+			return appmodels.ECDSA_P224, nil
+		case 384:
+			return appmodels.ECDSA_P384, nil
+		case 521:
+			return appmodels.ECDSA_P521, nil
+		default:
+			return appmodels.NIL_KEY_TYPE, fmt.Errorf("ParseVaultKeyType: EC key size not supported: %d", keyBits)
+		}
+	case "ed25519":
+		return appmodels.Ed25519, nil
+	default:
+		return appmodels.NIL_KEY_TYPE, fmt.Errorf("ParseVaultKeyType: unsupported: %s", keyType)
+	}
+}
+
+// VaultKeyType returns the Vault key_type and key_bits of a key type
+func VaultKeyType(keyType appmodels.KeyType) (string, int) {
+	switch keyType {
+	case appmodels.RSA_1024:
+		return "rsa", 1024
+	case appmodels.RSA_2048:
+		return "rsa", 2048
+	case appmodels.RSA_3072:
+		return "rsa", 3072
+	case appmodels.RSA_4096:
+		return "rsa", 4096
+	case appmodels.ECDSA_P224:
+		return "ec", 224
+	case appmodels.ECDSA_P256:
+		return "ec", 256
+	case appmodels.ECDSA_P384:
+		return "ec", 384
+	case appmodels.ECDSA_P521:
+		return "ec", 521
+	case appmodels.Ed25519:
+		return "ed25519", 0
+	default:
+		return "any", 0
+	}
+}
+
+// ValidateVaultRoleName checks if the provided name can be used as a Vault
+// role name in a path segment
+func ValidateVaultRoleName(name string) error {
+	if name == "" {
+		return errors.New("cannot be empty")
+	}
+	matched, _ := regexp.MatchString(`^[a-zA-Z0-9_\-.]+$`, name)
+	if !matched || name != strings.Trim(name, "-.") {
+		return errors.New("contains invalid characters, or has leading/trailing '-' or '.'")
+	}
+	return nil
+}
+
+// VaultRoleAllowsName checks if a role allows a common name or a DNS name
+func VaultRoleAllowsName(role appmodels.VaultRole, name string) bool {
+	if role.AllowAnyName() {
+		return true
+	}
+	name = strings.ToLower(name)
+	for _, domain := range role.AllowedDomains() {
+		domain = strings.ToLower(domain)
+		if role.AllowBareDomains() && name == domain {
+			return true
+		}
+		if role.AllowSubdomains() && strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateCertificateRevocationList creates a DER encoded CRL signed by the
+// issuer
+//   - issuer: The issuing certificate
+//   - issuerKey: The private key of the issuing certificate
+//   - revoked: The revoked certificates issued by the issuer
+//   - number: The CRL number, which must increase on every update
+//   - now: The time of this update
+//   - nextUpdate: How long until the next update
+func CreateCertificateRevocationList(
+	issuer appmodels.Certificate,
+	issuerKey appmodels.PrivateKey,
+	revoked []appmodels.RevokedCertificate,
+	number *big.Int,
+	now time.Time,
+	nextUpdate time.Duration,
+) ([]byte, error) {
+	signer, ok := issuerKey.PrivateKey().(crypto.Signer)
+	if !ok {
+		return nil, errors.New("CreateCertificateRevocationList: issuerKey: not a signer")
+	}
+	entries := make([]x509.RevocationListEntry, len(revoked))
+	for i, certificate := range revoked {
+		entries[i] = x509.RevocationListEntry{
+			SerialNumber:   certificate.SerialNumber(),
+			RevocationTime: certificate.RevocationTime(),
+		}
+	}
+	template := &x509.RevocationList{
+		RevokedCertificateEntries: entries,
+		Number:                    number,
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(nextUpdate),
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, template, issuer.Certificate(), signer)
+	if err != nil {
+		return nil, fmt.Errorf("CreateCertificateRevocationList: %w", err)
+	}
+	return der, nil
+}
+
+// NextRevocationListNumber returns the CRL number of the next CRL of an
+// issuer. The number is based on the time so that it increases even if the
+// previous CRL is unknown, but always exceeds the previous number.
+//   - previous: The last CRL of the issuer, or nil
+//   - now: The time of the next CRL
+func NextRevocationListNumber(previous appmodels.RevocationList, now time.Time) *big.Int {
+	number := big.NewInt(now.UnixNano())
+	if previous != nil && previous.Number().Cmp(number) >= 0 {
+		number = new(big.Int).Add(previous.Number(), big.NewInt(1))
+	}
+	return number
+}
+
+func ToVaultRoleDTO(role appmodels.VaultRole) appdtos.VaultRoleDTO {
+	keyType, keyBits := VaultKeyType(role.KeyType())
+	return appdtos.NewVaultRoleDTO(
+		role.IssuerSerialNumber().String(),
+		role.AllowedDomains(),
+		role.AllowSubdomains(),
+		role.AllowBareDomains(),
+		role.AllowAnyName(),
+		role.ServerFlag(),
+		true,
+		keyType,
+		keyBits,
+		int64(role.TTL()/time.Second),
+		int64(role.MaxTTL()/time.Second),
+	)
+}
+
+// ToVaultRoleRecordDTO converts a Vault role to its stored form
+func ToVaultRoleRecordDTO(role appmodels.VaultRole) appdtos.VaultRoleRecordDTO {
+	keyType := ""
+	if role.KeyType() != appmodels.NIL_KEY_TYPE {
+		keyType = role.KeyType().String()
+	}
+	return appdtos.NewVaultRoleRecordDTO(
+		role.Name(),
+		role.OrganizationID().String(),
+		role.IssuerSerialNumber().String(),
+		role.AllowedDomains(),
+		role.AllowSubdomains(),
+		role.AllowBareDomains(),
+		role.AllowAnyName(),
+		role.ServerFlag(),
+		keyType,
+		int64(role.TTL()),
+		int64(role.MaxTTL()),
+	)
+}
+
+// FromVaultRoleRecordDTO converts the stored form of a Vault role to a model
+func FromVaultRoleRecordDTO(dto appdtos.VaultRoleRecordDTO) (appmodels.VaultRole, error) {
+	organization, err := ParseBigInt(dto.Organization, 10)
+	if err != nil {
+		return nil, fmt.Errorf("FromVaultRoleRecordDTO: invalid organization: %w", err)
+	}
+	issuer, err := ParseBigInt(dto.Issuer, 10)
+	if err != nil {
+		return nil, fmt.Errorf("FromVaultRoleRecordDTO: invalid issuer: %w", err)
+	}
+	keyType, err := ParseKeyType(dto.KeyType)
+	if err != nil {
+		return nil, fmt.Errorf("FromVaultRoleRecordDTO: %w", err)
+	}
+	return appmodels.NewVaultRole(
+		dto.Name,
+		organization,
+		issuer,
+		dto.AllowedDomains,
+		dto.AllowSubdomains,
+		dto.AllowBareDomains,
+		dto.AllowAnyName,
+		dto.ServerFlag,
+		keyType,
+		time.Duration(dto.TTL),
+		time.Duration(dto.MaxTTL),
+	), nil
+}
+
+// ToVaultCertificateDTO converts an issued certificate to Vault issue and
+// sign response data
+//   - certManager: The certificate manager used to encode the private key
+//   - certificate: The issued certificate
+//   - privateKey: The private key of the certificate, or nil
+//   - caCertificates: The issuing certificate followed by its parents
+func ToVaultCertificateDTO(
+	certManager managers.CertificateManager,
+	certificate appmodels.Certificate,
+	privateKey appmodels.PrivateKey,
+	caCertificates []appmodels.Certificate,
+) (appdtos.VaultCertificateDTO, error) {
+	caChain := make([]string, len(caCertificates))
+	for i, ca := range caCertificates {
+		caChain[i] = strings.TrimSpace(string(CertificateToPEMBytes(ca)))
+	}
+	issuingCA := ""
+	if len(caChain) != 0 {
+		issuingCA = caChain[0]
+	}
+	privateKeyPEM := ""
+	privateKeyType := ""
+	if privateKey != nil {
+		data, err := MarshalPrivateKeyAsPEM(certManager, privateKey.PrivateKey())
+		if err != nil {
+			return appdtos.VaultCertificateDTO{}, fmt.Errorf("ToVaultCertificateDTO: %w", err)
+		}
+		privateKeyPEM = strings.TrimSpace(string(data))
+		privateKeyType, _ = VaultKeyType(privateKey.KeyType())
+	}
+	return appdtos.NewVaultCertificateDTO(
+		strings.TrimSpace(string(CertificateToPEMBytes(certificate))),
+		issuingCA,
+		caChain,
+		privateKeyPEM,
+		privateKeyType,
+		FormatVaultSerialNumber(certificate.SerialNumber()),
+		certificate.NotAfter().Unix(),
+	), nil
+}
+
+func ToVaultRevokeDTO(revocation appmodels.CertificateRevocation) appdtos.VaultRevokeDTO {
+	revocationTime := revocation.RevokedCertificate().RevocationTime()
+	return appdtos.NewVaultRevokeDTO(
+		revocationTime.Unix(),
+		revocationTime.UTC().Format(time.RFC3339),
+	)
+}
+
+// NewVaultRequestID returns a random UUID for the request_id of Vault
+// responses
+func NewVaultRequestID() string {
+	var data [16]byte
+	_, _ = rand.Read(data[:])
+	data[6] = data[6]&0x0f | 0x40
+	data[8] = data[8]&0x3f | 0x80
+	value := hex.EncodeToString(data[:])
+	return value[0:8] + "-" + value[8:12] + "-" + value[12:16] + "-" + value[16:20] + "-" + value[20:]
+}
@@ -4,6 +4,7 @@ package apimocks
 
 import (
 	"bytes"
+	"crypto/x509"
 	"io"
 	"net/url"
 
@@ -19,6 +20,13 @@ type MockRequest struct {
 	MockQueryParams      map[string]string
 	MockBodyContent      []byte
 	MockBodyContentError error
+	MockHost             string
+	MockIsTLS            bool
+	MockRemoteAddr       string
+	MockPeerCertificates []*x509.Certificate
+	MockUsername         string
+	MockPassword         string
+	MockHasBasicAuth     bool
 }
 
 func (m *MockRequest) Header(name string) string {
@@ -26,6 +34,26 @@ func (m *MockRequest) Header(name string) string {
 	panic("implement me")
 }
 
+func (m *MockRequest) Host() string {
+	return m.MockHost
+}
+
+func (m *MockRequest) IsTLS() bool {
+	return m.MockIsTLS
+}
+
+func (m *MockRequest) RemoteAddr() string {
+	return m.MockRemoteAddr
+}
+
+func (m *MockRequest) PeerCertificates() []*x509.Certificate {
+	return m.MockPeerCertificates
+}
+
+func (m *MockRequest) BasicAuth() (string, string, bool) {
+	return m.MockUsername, m.MockPassword, m.MockHasBasicAuth
+}
+
 func (m *MockRequest) Body() io.ReadCloser {
 	return io.NopCloser(bytes.NewBufferString(string(m.MockBodyContent)))
 }
@@ -27,6 +27,10 @@ func (m *MockResponse) SetHeader(name, value string) {
 	panic("implement me")
 }
 
+func (m *MockResponse) SendStatus(statusCode int) {
+	m.SentStatusCode = statusCode
+}
+
 func (m *MockResponse) Send(statusCode int, data interface{}) {
 	m.SentStatusCode = statusCode
 	m.SentData = data
@@ -3,6 +3,7 @@
 package apirequests
 
 import (
+	"crypto/x509"
 	"fmt"
 	"io"
 	"net/http"
@@ -21,6 +22,29 @@ func (r *HttpRequest) Header(name string) string {
 	return r.request.Header.Get(name)
 }
 
+func (r *HttpRequest) Host() string {
+	return r.request.Host
+}
+
+func (r *HttpRequest) IsTLS() bool {
+	return r.request.TLS != nil
+}
+
+func (r *HttpRequest) RemoteAddr() string {
+	return r.request.RemoteAddr
+}
+
+func (r *HttpRequest) PeerCertificates() []*x509.Certificate {
+	if r.request.TLS == nil {
+		return nil
+	}
+	return r.request.TLS.PeerCertificates
+}
+
+func (r *HttpRequest) BasicAuth() (string, string, bool) {
+	return r.request.BasicAuth()
+}
+
 func (r *HttpRequest) Body() io.ReadCloser {
 	return r.request.Body
 }
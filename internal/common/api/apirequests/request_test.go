@@ -3,6 +3,7 @@
 package apirequests_test
 
 import (
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -179,6 +180,38 @@ func TestRequestImpl_GetBodyBytes_ReadError(t *testing.T) {
 	}
 }
 
+func TestRequestImpl_Host(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://example.com:8080/test", nil)
+	if err != nil {
+		t.Fatal("Creating request failed:", err)
+	}
+
+	requestImpl := apirequests.NewRequest(req)
+	if got := requestImpl.Host(); got != "example.com:8080" {
+		t.Errorf("Host() = %v, want %v", got, "example.com:8080")
+	}
+	if requestImpl.IsTLS() {
+		t.Errorf("IsTLS() = true, want false")
+	}
+}
+
+func TestRequestImpl_IsTLS(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/test", nil)
+	requestImpl := apirequests.NewRequest(req)
+	if !requestImpl.IsTLS() {
+		t.Errorf("IsTLS() = false, want true")
+	}
+}
+
+func TestRequestImpl_RemoteAddr(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/test", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	requestImpl := apirequests.NewRequest(req)
+	if got := requestImpl.RemoteAddr(); got != "192.0.2.1:1234" {
+		t.Errorf("RemoteAddr() = %v, want %v", got, "192.0.2.1:1234")
+	}
+}
+
 type errorReader struct{}
 
 func (e errorReader) Read(p []byte) (n int, err error) {
@@ -188,3 +221,26 @@ func (e errorReader) Read(p []byte) (n int, err error) {
 func (e errorReader) Close() error {
 	return nil
 }
+
+func TestRequestImpl_PeerCertificates(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/test", nil)
+	if certificates := apirequests.NewRequest(req).PeerCertificates(); certificates != nil {
+		t.Errorf("PeerCertificates() = %v, want nil", certificates)
+	}
+
+	certificate := &x509.Certificate{}
+	req = httptest.NewRequest(http.MethodGet, "https://example.com/test", nil)
+	req.TLS.PeerCertificates = []*x509.Certificate{certificate}
+	if certificates := apirequests.NewRequest(req).PeerCertificates(); len(certificates) != 1 || certificates[0] != certificate {
+		t.Errorf("PeerCertificates() = %v, want %v", certificates, certificate)
+	}
+}
+
+func TestRequestImpl_BasicAuth(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/test", nil)
+	req.SetBasicAuth("user", "secret")
+	username, password, ok := apirequests.NewRequest(req).BasicAuth()
+	if !ok || username != "user" || password != "secret" {
+		t.Errorf("BasicAuth() = %v, %v, %v", username, password, ok)
+	}
+}
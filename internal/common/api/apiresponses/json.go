@@ -27,6 +27,10 @@ func (sender *JSONResponse) SetHeader(name, value string) {
 	sender.writer.Header().Set(name, value)
 }
 
+func (sender *JSONResponse) SendStatus(statusCode int) {
+	sender.writer.WriteHeader(statusCode)
+}
+
 func (sender *JSONResponse) Send(statusCode int, data interface{}) {
 
 	jsonData, err := json.Marshal(data)
@@ -36,7 +40,9 @@ func (sender *JSONResponse) Send(statusCode int, data interface{}) {
 	}
 
 	jsonData = append(jsonData, '\n')
-	sender.writer.Header().Set("Content-Type", "application/json")
+	if sender.writer.Header().Get("Content-Type") == "" {
+		sender.writer.Header().Set("Content-Type", "application/json")
+	}
 	sender.writer.WriteHeader(statusCode)
 	_, err = sender.writer.Write(jsonData)
 
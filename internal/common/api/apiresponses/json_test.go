@@ -231,3 +231,26 @@ func TestJSONResponse_SetHeader(t *testing.T) {
 	// Verify the header is set correctly
 	assert.Equal(t, contentTypeValue, w.Header().Get("Content-Type"), "Content-Type header mismatch")
 }
+
+func TestJSONResponse_SendStatus(t *testing.T) {
+	w := httptest.NewRecorder()
+	sender := apiresponses.NewJSONResponse(w)
+
+	sender.SetHeader("Replay-Nonce", "abc")
+	sender.SendStatus(http.StatusNoContent)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "Status code mismatch")
+	assert.Equal(t, "abc", w.Header().Get("Replay-Nonce"), "Replay-Nonce header mismatch")
+	assert.Empty(t, w.Body.String(), "Response body should be empty")
+}
+
+func TestJSONResponse_Send_KeepsContentType(t *testing.T) {
+	w := httptest.NewRecorder()
+	sender := apiresponses.NewJSONResponse(w)
+
+	sender.SetHeader("Content-Type", "application/problem+json")
+	sender.Send(http.StatusBadRequest, map[string]string{"type": "test"})
+
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"), "Content-Type header mismatch")
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Status code mismatch")
+}
@@ -4,6 +4,7 @@ package apiserver
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"hash/fnv"
@@ -26,6 +27,7 @@ import (
 
 const DefaultHostname = "localhost"
 const DefaultProtocol = "http"
+const DefaultTLSProtocol = "https"
 const DefaultOpenApiTitle = "Example API"
 const DefaultOpenApiVersion = "0.0.0"
 
@@ -44,6 +46,10 @@ type ApplicationServer struct {
 	serverFactory  apitypes.NewServerManagerFunc
 	info           *openapi3.Info
 	hashFactory    apitypes.Hash64FactoryFunc
+
+	// tlsConfig makes the server listen TLS. The server listens plain HTTP
+	// if it is nil.
+	tlsConfig *tls.Config
 }
 
 func (s *ApplicationServer) IsStarted() bool {
@@ -68,12 +74,21 @@ func (s *ApplicationServer) Info() *openapi3.Info {
 	return s.info
 }
 
+// IsTLS returns true if the server listens TLS
+func (s *ApplicationServer) IsTLS() bool {
+	return s.tlsConfig != nil
+}
+
 func (s *ApplicationServer) URL() string {
+	protocol := DefaultProtocol
+	if s.IsTLS() {
+		protocol = DefaultTLSProtocol
+	}
 	url := s.listen
 	if strings.HasPrefix(url, ":") {
-		return fmt.Sprintf("%s://%s%s", DefaultProtocol, DefaultHostname, url)
+		return fmt.Sprintf("%s://%s%s", protocol, DefaultHostname, url)
 	}
-	return fmt.Sprintf("%s://%s", DefaultProtocol, url)
+	return fmt.Sprintf("%s://%s", protocol, url)
 }
 
 func (s *ApplicationServer) SetSwaggerFactory(factory apitypes.NewSwaggerManagerFunc) {
@@ -187,6 +202,9 @@ func (s *ApplicationServer) Start() error {
 
 	if listen == "" {
 		listen = ":http"
+		if s.IsTLS() {
+			listen = ":https"
+		}
 	}
 
 	if s.serverFactory == nil {
@@ -202,6 +220,9 @@ func (s *ApplicationServer) Start() error {
 	if err != nil {
 		return fmt.Errorf("[server] failed to listen the address %s: %v", listen, err)
 	}
+	if s.IsTLS() {
+		ln = tls.NewListener(ln, s.tlsConfig)
+	}
 	s.listener = &ln
 
 	// Channel to signal the server start errors
@@ -314,6 +335,29 @@ func NewServer(
 	return s, nil
 }
 
+// NewTLSServer creates a server which listens TLS
+//   - listen: The address, e.g. ":8443"
+//   - tlsConfig: The TLS configuration, which must provide the server
+//     certificate in Certificates or GetCertificate
+//   - swaggerFactory: The swagger factory, or nil for the default
+func NewTLSServer(
+	listen string,
+	tlsConfig *tls.Config,
+	swaggerFactory apitypes.NewSwaggerManagerFunc,
+) (*ApplicationServer, error) {
+	if tlsConfig == nil {
+		return nil, errors.New("[server] TLS configuration must be defined")
+	}
+	s := NewUninitializedServer(listen, swaggerFactory)
+	s.tlsConfig = tlsConfig
+	if err := s.InitSetup(); err != nil {
+		return nil, fmt.Errorf("[server] server initialization failed: %v", err)
+	}
+	s.SetupNotFoundHandler(apierrors.NotFound)
+	s.SetupMethodNotAllowedHandler(apierrors.MethodNotAllowed)
+	return s, nil
+}
+
 func NewUninitializedServer(
 	listen string,
 	swaggerFactory apitypes.NewSwaggerManagerFunc,
@@ -4,10 +4,17 @@ package apiserver_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net"
 	"net/http"
 	"strings"
@@ -117,6 +124,93 @@ func TestGetURL(t *testing.T) {
 	}
 }
 
+// newTestTLSConfig returns a TLS configuration with a self-signed
+// certificate for localhost, and a pool which trusts it
+func newTestTLSConfig(t *testing.T) (*tls.Config, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}},
+	}, pool
+}
+
+func TestGetURL_TLS(t *testing.T) {
+	config, _ := newTestTLSConfig(t)
+	tests := []struct {
+		listen string
+		want   string
+	}{
+		{":8443", "https://localhost:8443"},
+		{"localhost:8443", "https://localhost:8443"},
+	}
+
+	for _, tt := range tests {
+		server, err := apiserver.NewTLSServer(tt.listen, config, nil)
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+		assert.True(t, server.IsTLS())
+		assert.Equal(t, tt.want, server.URL())
+	}
+}
+
+func TestNewTLSServer_NoConfig(t *testing.T) {
+	_, err := apiserver.NewTLSServer(":8443", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestServer_StartStopTLS(t *testing.T) {
+	config, pool := newTestTLSConfig(t)
+	listenAddr := "localhost:8443"
+
+	server, err := apiserver.NewTLSServer(listenAddr, config, nil)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get(server.URL())
+	if err != nil {
+		t.Fatalf("Failed to connect to server: %v", err)
+	}
+	resp.Body.Close()
+	assert.NotNil(t, resp.TLS)
+
+	// Plain HTTP is not served
+	if resp, err := http.Get("http://" + listenAddr); err == nil {
+		resp.Body.Close()
+		assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
 func TestServer_StartError(t *testing.T) {
 	server, err := apiserver.NewServer("invalidAddress", nil)
 	if err != nil {
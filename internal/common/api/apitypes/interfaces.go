@@ -4,6 +4,7 @@ package apitypes
 
 import (
 	"context"
+	"crypto/x509"
 	"hash"
 	"io"
 	"net/url"
@@ -41,6 +42,9 @@ type Response interface {
 	SendConflictError(error string)
 	SendInternalServerError(error string)
 	SetHeader(name, value string)
+
+	// SendStatus sends only the status code and headers without a body
+	SendStatus(statusCode int)
 }
 
 // Server defines the methods available from the Server
@@ -99,6 +103,24 @@ type Request interface {
 	Body() io.ReadCloser
 	BodyBytes() ([]byte, error)
 	Header(name string) string
+
+	// Host returns the host the request was sent to, e.g. "example.com:8080"
+	Host() string
+
+	// IsTLS returns true if the request was received over TLS
+	IsTLS() bool
+
+	// RemoteAddr returns the network address of the client, e.g.
+	// "192.0.2.1:1234"
+	RemoteAddr() string
+
+	// PeerCertificates returns the certificates the client presented in the
+	// TLS handshake, or nil
+	PeerCertificates() []*x509.Certificate
+
+	// BasicAuth returns the username and password of HTTP basic
+	// authentication
+	BasicAuth() (username, password string, ok bool)
 }
 
 // RequestHandlerFunc defines the type for handlers in this API.
@@ -45,6 +45,19 @@ func (m *MockFileManager) Chmod(file string, mode os.FileMode) error {
 	return args.Error(0)
 }
 
+func (m *MockFileManager) ReadDir(dir string) ([]os.DirEntry, error) {
+	args := m.Called(dir)
+	return args.Get(0).([]os.DirEntry), args.Error(1)
+}
+
+func (m *MockFileManager) Stat(name string) (os.FileInfo, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(os.FileInfo), args.Error(1)
+}
+
 func NewMockFileManager() *MockFileManager {
 	return &MockFileManager{}
 }
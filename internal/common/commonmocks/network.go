@@ -0,0 +1,54 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package commonmocks
+
+import (
+	"crypto/x509"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// MockNetworkManager is a mock implementation of managers.NetworkManager for testing.
+type MockNetworkManager struct {
+	mock.Mock
+}
+
+func NewMockNetworkManager() *MockNetworkManager {
+	return &MockNetworkManager{}
+}
+
+func (m *MockNetworkManager) HttpGet(url string) ([]byte, error) {
+	args := m.Called(url)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockNetworkManager) HttpPost(url, contentType string, body []byte) ([]byte, error) {
+	args := m.Called(url, contentType, body)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockNetworkManager) LookupTXT(name string) ([]string, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockNetworkManager) TLSPeerCertificate(address, serverName string, nextProtos []string) (*x509.Certificate, error) {
+	args := m.Called(address, serverName, nextProtos)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*x509.Certificate), args.Error(1)
+}
+
+var _ managers.NetworkManager = (*MockNetworkManager)(nil)
@@ -82,3 +82,43 @@ func SaveBytes(fs managers.FileManager, fileName string, data []byte, filePerms,
 	return nil
 
 }
+
+// ValidateDirectory checks that a directory for private data exists, is not
+// writable by the group or other users, and can be written by this process.
+// A missing directory is created with dirPerms.
+func ValidateDirectory(fs managers.FileManager, dir string, dirPerms os.FileMode) error {
+
+	if err := fs.MkdirAll(dir, dirPerms); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	if err := ValidateReadOnlyDirectory(fs, dir); err != nil {
+		return err
+	}
+
+	tmpFile, err := fs.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return fmt.Errorf("directory %s is not writable: %w", dir, err)
+	}
+	_ = tmpFile.Close()
+	if err := fs.Remove(tmpFile.Name()); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", tmpFile.Name(), err)
+	}
+	return nil
+}
+
+// ValidateReadOnlyDirectory checks that a directory for private data exists
+// and is not writable by the group or other users, without writing to it
+func ValidateReadOnlyDirectory(fs managers.FileManager, dir string) error {
+	info, err := fs.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf("directory %s must not be writable by group or others (mode %s)", dir, info.Mode().Perm())
+	}
+	return nil
+}
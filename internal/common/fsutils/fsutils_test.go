@@ -5,6 +5,7 @@ package fsutils_test
 import (
 	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -203,3 +204,53 @@ func TestSaveBytes_FailToMoveTempFile(t *testing.T) {
 	mockFS.AssertExpectations(t)
 	mockFile.AssertExpectations(t)
 }
+
+func TestValidateDirectory(t *testing.T) {
+	fm := managers.NewFileManager()
+
+	dir := filepath.Join(t.TempDir(), "data")
+	assert.NoError(t, fsutils.ValidateDirectory(fm, dir, 0700))
+	info, err := os.Stat(dir)
+	assert.NoError(t, err)
+	assert.True(t, info.IsDir())
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	assert.NoError(t, os.Chmod(dir, 0777))
+	assert.ErrorContains(t, fsutils.ValidateDirectory(fm, dir, 0700), "must not be writable by group or others")
+
+	file := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(file, []byte("data"), 0600))
+	assert.Error(t, fsutils.ValidateDirectory(fm, file, 0700))
+}
+
+func TestValidateReadOnlyDirectory(t *testing.T) {
+	fm := managers.NewFileManager()
+
+	dir := t.TempDir()
+	assert.NoError(t, fsutils.ValidateReadOnlyDirectory(fm, dir))
+	assert.Error(t, fsutils.ValidateReadOnlyDirectory(fm, filepath.Join(dir, "missing")))
+	_, err := os.Stat(filepath.Join(dir, "missing"))
+	assert.True(t, os.IsNotExist(err), "Expected the directory not to be created")
+
+	assert.NoError(t, os.Chmod(dir, 0777))
+	assert.ErrorContains(t, fsutils.ValidateReadOnlyDirectory(fm, dir), "must not be writable by group or others")
+	assert.NoError(t, os.Chmod(dir, 0700))
+
+	file := filepath.Join(dir, "file")
+	assert.NoError(t, os.WriteFile(file, []byte("data"), 0600))
+	assert.ErrorContains(t, fsutils.ValidateReadOnlyDirectory(fm, file), "is not a directory")
+}
+
+func TestValidateDirectory_NotWritable(t *testing.T) {
+	mockFS := new(commonmocks.MockFileManager)
+	info, err := os.Stat(t.TempDir())
+	assert.NoError(t, err)
+	mockFS.On("MkdirAll", "/data", os.FileMode(0700)).Return(nil)
+	mockFS.On("Stat", "/data").Return(info, nil)
+	mockFS.On("CreateTemp", "/data", "*.tmp").Return(managers.NewFile(nil), errors.New("read-only file system"))
+
+	err = fsutils.ValidateDirectory(mockFS, "/data", 0700)
+	assert.ErrorContains(t, err, "is not writable")
+}
@@ -42,6 +42,16 @@ func (f *OSFileManager) Chmod(file string, mode os.FileMode) error {
 	return os.Chmod(file, mode)
 }
 
+// ReadDir wraps up a call to os.ReadDir
+func (f *OSFileManager) ReadDir(dir string) ([]os.DirEntry, error) {
+	return os.ReadDir(filepath.Clean(dir))
+}
+
+// Stat wraps up a call to os.Stat
+func (f *OSFileManager) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(filepath.Clean(name))
+}
+
 func NewFileManager() *OSFileManager {
 	return &OSFileManager{}
 }
@@ -96,3 +96,37 @@ func TestFileManager_Rename(t *testing.T) {
 	_, err = os.Stat(newPath)
 	require.NoError(t, err)
 }
+
+func TestFileManager_ReadDir(t *testing.T) {
+	// Setup: Create a temporary directory with one file and one subdirectory
+	tmpDirPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDirPath, "file.txt"), []byte("content"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDirPath, "subdir"), 0700))
+
+	fm := managers.NewFileManager()
+
+	// Test
+	entries, err := fm.ReadDir(tmpDirPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "file.txt", entries[0].Name())
+	assert.False(t, entries[0].IsDir())
+	assert.Equal(t, "subdir", entries[1].Name())
+	assert.True(t, entries[1].IsDir())
+
+	// Missing directory
+	_, err = fm.ReadDir(filepath.Join(tmpDirPath, "missing"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFileManager_Stat(t *testing.T) {
+	tmpDirPath := t.TempDir()
+	fm := managers.NewFileManager()
+
+	info, err := fm.Stat(tmpDirPath)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	_, err = fm.Stat(filepath.Join(tmpDirPath, "missing"))
+	assert.True(t, os.IsNotExist(err))
+}
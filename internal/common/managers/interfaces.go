@@ -106,4 +106,29 @@ type FileManager interface {
 	CreateTemp(dir, pattern string) (File, error)
 	Remove(name string) error
 	Chmod(file string, mode os.FileMode) error
+	ReadDir(dir string) ([]os.DirEntry, error)
+	Stat(name string) (os.FileInfo, error)
+}
+
+// NetworkManager wraps up outgoing network operations, e.g. HTTP requests and
+// DNS lookups, to make testing other parts of the application easier
+type NetworkManager interface {
+
+	// HttpGet fetches the body of a URL using an HTTP GET request. Non-2xx
+	// responses are returned as errors.
+	HttpGet(url string) ([]byte, error)
+
+	// HttpPost sends a body using an HTTP POST request and returns the body
+	// of the response. Non-2xx responses are returned as errors.
+	HttpPost(url, contentType string, body []byte) ([]byte, error)
+
+	// LookupTXT wraps up a call to net.LookupTXT
+	LookupTXT(name string) ([]string, error)
+
+	// TLSPeerCertificate connects to a TLS server and returns the leaf
+	// certificate it presented. The certificate is not verified.
+	//  - address: host:port to connect to
+	//  - serverName: SNI server name
+	//  - nextProtos: ALPN protocols to offer
+	TLSPeerCertificate(address, serverName string, nextProtos []string) (*x509.Certificate, error)
 }
@@ -0,0 +1,108 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package managers
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultNetworkTimeout is the timeout for a single network operation
+const DefaultNetworkTimeout = 10 * time.Second
+
+// MaxHttpGetBodySize is the largest response body HttpGet and HttpPost read
+const MaxHttpGetBodySize = 64 * 1024
+
+// NetNetworkManager implements NetworkManager using the net package
+type NetNetworkManager struct {
+	timeout time.Duration
+	client  *http.Client
+}
+
+// HttpGet wraps up a call to http.Client.Get
+func (m *NetNetworkManager) HttpGet(url string) ([]byte, error) {
+	res, err := m.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("HttpGet: %w", err)
+	}
+	body, err := readHttpResponse(res)
+	if err != nil {
+		return nil, fmt.Errorf("HttpGet: %w", err)
+	}
+	return body, nil
+}
+
+// HttpPost wraps up a call to http.Client.Post
+func (m *NetNetworkManager) HttpPost(url, contentType string, body []byte) ([]byte, error) {
+	res, err := m.client.Post(url, contentType, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("HttpPost: %w", err)
+	}
+	data, err := readHttpResponse(res)
+	if err != nil {
+		return nil, fmt.Errorf("HttpPost: %w", err)
+	}
+	return data, nil
+}
+
+// LookupTXT wraps up a call to net.LookupTXT
+func (m *NetNetworkManager) LookupTXT(name string) ([]string, error) {
+	return net.LookupTXT(name)
+}
+
+// TLSPeerCertificate wraps up a call to tls.DialWithDialer
+func (m *NetNetworkManager) TLSPeerCertificate(address, serverName string, nextProtos []string) (*x509.Certificate, error) {
+	dialer := &net.Dialer{Timeout: m.timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		ServerName:         serverName,
+		NextProtos:         nextProtos,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("TLSPeerCertificate: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	state := conn.ConnectionState()
+	if len(nextProtos) != 0 && state.NegotiatedProtocol == "" {
+		return nil, errors.New("TLSPeerCertificate: no protocol negotiated")
+	}
+	if len(state.PeerCertificates) == 0 {
+		return nil, errors.New("TLSPeerCertificate: no peer certificates")
+	}
+	return state.PeerCertificates[0], nil
+}
+
+// readHttpResponse reads and closes the body of a response. Non-2xx
+// responses are returned as errors.
+func readHttpResponse(res *http.Response) ([]byte, error) {
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status: %s", res.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, MaxHttpGetBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	return body, nil
+}
+
+// NewNetworkManager creates a NetworkManager using DefaultNetworkTimeout
+func NewNetworkManager() *NetNetworkManager {
+	return &NetNetworkManager{
+		timeout: DefaultNetworkTimeout,
+		client:  &http.Client{Timeout: DefaultNetworkTimeout},
+	}
+}
+
+var _ NetworkManager = (*NetNetworkManager)(nil)
@@ -0,0 +1,76 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package managers_test
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestNetworkManager_HttpGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	manager := managers.NewNetworkManager()
+
+	body, err := manager.HttpGet(server.URL + "/")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+
+	_, err = manager.HttpGet(server.URL + "/missing")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status")
+}
+
+func TestNetworkManager_HttpPost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(r.Method + " " + r.Header.Get("Content-Type") + " " + string(body)))
+	}))
+	defer server.Close()
+
+	manager := managers.NewNetworkManager()
+
+	body, err := manager.HttpPost(server.URL+"/", "application/json", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "POST application/json {}", string(body))
+
+	_, err = manager.HttpPost(server.URL+"/missing", "application/json", nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status")
+}
+
+func TestNetworkManager_TLSPeerCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{NextProtos: []string{"http/1.1"}}
+	server.StartTLS()
+	defer server.Close()
+
+	manager := managers.NewNetworkManager()
+	address := strings.TrimPrefix(server.URL, "https://")
+
+	cert, err := manager.TLSPeerCertificate(address, "example.com", []string{"http/1.1"})
+	assert.NoError(t, err)
+	assert.NotNil(t, cert)
+
+	_, err = manager.TLSPeerCertificate(address, "example.com", []string{"acme-tls/1"})
+	assert.Error(t, err)
+}
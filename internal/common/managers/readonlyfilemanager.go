@@ -0,0 +1,80 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package managers
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// ErrReadOnlyFileSystem is returned by ReadOnlyFileManager for operations
+// which would change files
+var ErrReadOnlyFileSystem = fmt.Errorf("read-only file system: %w", fs.ErrPermission)
+
+// ReadOnlyFileManager wraps a file manager and refuses every operation which
+// would change files
+type ReadOnlyFileManager struct {
+	fileManager FileManager
+}
+
+// ReadFile reads a file with the wrapped file manager
+func (f *ReadOnlyFileManager) ReadFile(fileName string) ([]byte, error) {
+	return f.fileManager.ReadFile(fileName)
+}
+
+// MkdirAll succeeds if the directory exists, since it would not be created
+func (f *ReadOnlyFileManager) MkdirAll(dir string, _ os.FileMode) error {
+	info, err := f.fileManager.Stat(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrReadOnlyFileSystem
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	return nil
+}
+
+// CreateTemp returns ErrReadOnlyFileSystem
+func (f *ReadOnlyFileManager) CreateTemp(_, _ string) (File, error) {
+	return nil, ErrReadOnlyFileSystem
+}
+
+// Remove returns ErrReadOnlyFileSystem
+func (f *ReadOnlyFileManager) Remove(_ string) error {
+	return ErrReadOnlyFileSystem
+}
+
+// Rename returns ErrReadOnlyFileSystem
+func (f *ReadOnlyFileManager) Rename(_, _ string) error {
+	return ErrReadOnlyFileSystem
+}
+
+// Chmod returns ErrReadOnlyFileSystem
+func (f *ReadOnlyFileManager) Chmod(_ string, _ os.FileMode) error {
+	return ErrReadOnlyFileSystem
+}
+
+// ReadDir reads a directory with the wrapped file manager
+func (f *ReadOnlyFileManager) ReadDir(dir string) ([]os.DirEntry, error) {
+	return f.fileManager.ReadDir(dir)
+}
+
+// Stat returns the file info from the wrapped file manager
+func (f *ReadOnlyFileManager) Stat(name string) (os.FileInfo, error) {
+	return f.fileManager.Stat(name)
+}
+
+// NewReadOnlyFileManager creates a file manager which only reads files with
+// the wrapped file manager
+func NewReadOnlyFileManager(fileManager FileManager) *ReadOnlyFileManager {
+	return &ReadOnlyFileManager{
+		fileManager: fileManager,
+	}
+}
+
+var _ FileManager = (*ReadOnlyFileManager)(nil)
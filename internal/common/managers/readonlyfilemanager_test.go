@@ -0,0 +1,46 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package managers_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+func TestReadOnlyFileManager(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "test.txt")
+	require.NoError(t, os.WriteFile(fileName, []byte("content"), 0600))
+
+	fm := managers.NewReadOnlyFileManager(managers.NewFileManager())
+
+	data, err := fm.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("content"), data)
+	entries, err := fm.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	_, err = fm.Stat(fileName)
+	assert.NoError(t, err)
+
+	// An existing directory is accepted, since nothing would be created
+	assert.NoError(t, fm.MkdirAll(dir, 0700))
+	assert.ErrorIs(t, fm.MkdirAll(filepath.Join(dir, "missing"), 0700), managers.ErrReadOnlyFileSystem)
+	assert.Error(t, fm.MkdirAll(fileName, 0700))
+
+	_, err = fm.CreateTemp(dir, "*.tmp")
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.ErrorIs(t, fm.Remove(fileName), managers.ErrReadOnlyFileSystem)
+	assert.ErrorIs(t, fm.Rename(fileName, fileName+".new"), managers.ErrReadOnlyFileSystem)
+	assert.ErrorIs(t, fm.Chmod(fileName, 0644), managers.ErrReadOnlyFileSystem)
+
+	_, err = os.Stat(fileName)
+	assert.NoError(t, err, "Expected the file to be unchanged")
+}
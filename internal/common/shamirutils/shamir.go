@@ -0,0 +1,151 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+// Package shamirutils implements Shamir's secret sharing over GF(2^8).
+//
+// Each share is the secret length plus one byte. The last byte is the x
+// coordinate of the share and the other bytes are the values of a random
+// polynomial, one for each byte of the secret.
+package shamirutils
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// MaxShares is the largest number of shares, since x coordinates are
+// non-zero bytes
+const MaxShares = 255
+
+// expTable and logTable are the exponents and logarithms of the generator 3
+// in GF(2^8) with the AES polynomial x^8 + x^4 + x^3 + x + 1
+var expTable, logTable = newTables()
+
+func newTables() (exp [510]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		exp[i+255] = x
+		log[x] = byte(i)
+		// Multiply by the generator 3, i.e. x * 2 + x
+		high := x & 0x80
+		double := x << 1
+		if high != 0 {
+			double ^= 0x1b
+		}
+		x ^= double
+	}
+	return exp, log
+}
+
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+func div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
+
+// Split splits a secret into shares of which any threshold shares
+// reconstruct the secret
+//   - secret: The secret to split
+//   - shares: The number of shares, from threshold to MaxShares
+//   - threshold: The number of shares needed to combine, at least 2
+func Split(secret []byte, shares, threshold int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("Split: secret must not be empty")
+	}
+	if threshold < 2 {
+		return nil, fmt.Errorf("Split: threshold must be at least 2: %d", threshold)
+	}
+	if shares < threshold || shares > MaxShares {
+		return nil, fmt.Errorf("Split: shares must be from %d to %d: %d", threshold, MaxShares, shares)
+	}
+
+	// The first coefficient of each polynomial is the byte of the secret
+	coefficients := make([]byte, threshold)
+	result := make([][]byte, shares)
+	for i := range result {
+		result[i] = make([]byte, len(secret)+1)
+		result[i][len(secret)] = byte(i + 1)
+	}
+	for j, value := range secret {
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, fmt.Errorf("Split: %w", err)
+		}
+		coefficients[0] = value
+		for i := range result {
+			result[i][j] = evaluate(coefficients, byte(i+1))
+		}
+	}
+	clear(coefficients)
+	return result, nil
+}
+
+// Combine reconstructs a secret from shares created by Split. A wrong set
+// of shares, e.g. fewer than the threshold, returns a wrong secret without
+// an error, so the result must be verified by the caller.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, errors.New("Combine: at least 2 shares are needed")
+	}
+	length := len(shares[0])
+	if length < 2 {
+		return nil, errors.New("Combine: share is too short")
+	}
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool, len(shares))
+	for i, share := range shares {
+		if len(share) != length {
+			return nil, errors.New("Combine: shares must have the same length")
+		}
+		x := share[length-1]
+		if x == 0 || seen[x] {
+			return nil, errors.New("Combine: shares must be different")
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+
+	secret := make([]byte, length-1)
+	ys := make([]byte, len(shares))
+	for j := range secret {
+		for i, share := range shares {
+			ys[i] = share[j]
+		}
+		secret[j] = interpolateAtZero(xs, ys)
+	}
+	return secret, nil
+}
+
+// evaluate returns the value of the polynomial at x
+func evaluate(coefficients []byte, x byte) byte {
+	result := byte(0)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = mul(result, x) ^ coefficients[i]
+	}
+	return result
+}
+
+// interpolateAtZero returns the value at zero of the polynomial which goes
+// through the points, using Lagrange interpolation
+func interpolateAtZero(xs, ys []byte) byte {
+	result := byte(0)
+	for i := range xs {
+		basis := byte(1)
+		for j := range xs {
+			if i != j {
+				// In GF(2^8) subtraction is xor, so 0 - x_j is x_j
+				basis = mul(basis, div(xs[j], xs[i]^xs[j]))
+			}
+		}
+		result ^= mul(ys[i], basis)
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package shamirutils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/common/shamirutils"
+)
+
+func TestSplitAndCombine(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	shares, err := shamirutils.Split(secret, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+	for _, share := range shares {
+		assert.Len(t, share, len(secret)+1)
+	}
+
+	// Any three shares combine to the secret
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var selected [][]byte
+		for _, i := range subset {
+			selected = append(selected, shares[i])
+		}
+		combined, err := shamirutils.Combine(selected)
+		require.NoError(t, err)
+		assert.Equal(t, secret, combined, subset)
+	}
+
+	// Two shares do not
+	combined, err := shamirutils.Combine(shares[:2])
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, combined)
+}
+
+func TestSplit_Invalid(t *testing.T) {
+	_, err := shamirutils.Split(nil, 3, 2)
+	assert.Error(t, err)
+	_, err = shamirutils.Split([]byte("secret"), 3, 1)
+	assert.Error(t, err)
+	_, err = shamirutils.Split([]byte("secret"), 2, 3)
+	assert.Error(t, err)
+	_, err = shamirutils.Split([]byte("secret"), 256, 3)
+	assert.Error(t, err)
+}
+
+func TestCombine_Invalid(t *testing.T) {
+	shares, err := shamirutils.Split([]byte("secret"), 3, 2)
+	require.NoError(t, err)
+
+	_, err = shamirutils.Combine(shares[:1])
+	assert.Error(t, err, "too few shares")
+	_, err = shamirutils.Combine([][]byte{shares[0], shares[0]})
+	assert.Error(t, err, "duplicate shares")
+	_, err = shamirutils.Combine([][]byte{shares[0], shares[1][1:]})
+	assert.Error(t, err, "different lengths")
+	_, err = shamirutils.Combine([][]byte{{1}, {2}})
+	assert.Error(t, err, "too short")
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdscontrollers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdsmodels"
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdsutils"
+)
+
+// DefaultRefreshInterval is how often secrets are checked for renewal
+const DefaultRefreshInterval = 30 * time.Second
+
+// CertSecretController implements sdsmodels.SecretController
+type CertSecretController struct {
+	appController appmodels.ApplicationController
+	certManager   managers.CertificateManager
+	cache         *cache.LinearCache
+
+	// mu protects names, certificates and bundles
+	mu sync.Mutex
+
+	// names are the watched secrets by secret name
+	names map[string]sdsmodels.SecretName
+
+	// certificates are the issued leaf certificates by secret name
+	certificates map[string]appmodels.Certificate
+
+	// bundles are the published root certificates by secret name
+	bundles map[string][]appmodels.Certificate
+}
+
+func (r *CertSecretController) Cache() cache.Cache {
+	return r.cache
+}
+
+func (r *CertSecretController) Watch(names []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for _, name := range names {
+		if _, ok := r.names[name]; ok {
+			continue
+		}
+		secretName, err := sdsutils.ParseSecretName(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := r.update(name, secretName); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.names[name] = secretName
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("[Watch]: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (r *CertSecretController) Refresh() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var errs []error
+	for name, secretName := range r.names {
+		if certificate, ok := r.certificates[name]; ok && !sdsutils.ShouldRenew(certificate, now) {
+			continue
+		}
+		if err := r.update(name, secretName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("[Refresh]: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Run refreshes secrets until the context is cancelled
+//   - ctx: The context
+//   - interval: How often secrets are checked
+func (r *CertSecretController) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := r.Refresh(); err != nil {
+			log.Printf("[Run]: %v", err)
+		}
+	}
+}
+
+// update issues a new certificate or refreshes the trust bundle of the
+// secret. The cache is updated only if the secret changed.
+func (r *CertSecretController) update(name string, secretName sdsmodels.SecretName) error {
+
+	organizationController, err := r.appController.OrganizationController(secretName.OrganizationID())
+	if err != nil {
+		return fmt.Errorf("[%s:update]: %w", name, err)
+	}
+
+	if secretName.Type() == sdsmodels.TrustSecretType {
+		list, err := organizationController.CertificateCollection()
+		if err != nil {
+			return fmt.Errorf("[%s:update]: %w", name, err)
+		}
+		roots := apputils.FilterValidRootCertificates(list, time.Now())
+		if bundle, ok := r.bundles[name]; ok && sdsutils.SameCertificates(bundle, roots) {
+			return nil
+		}
+		if err := r.cache.UpdateResource(name, sdsutils.ToValidationContextSecret(name, roots)); err != nil {
+			return fmt.Errorf("[%s:update]: %w", name, err)
+		}
+		r.bundles[name] = roots
+		log.Printf("[%s:update]: Published %d root certificates", name, len(roots))
+		return nil
+	}
+
+	issuerController, err := organizationController.CertificateController(secretName.IssuerSerialNumber())
+	if err != nil {
+		return fmt.Errorf("[%s:update]: %w", name, err)
+	}
+
+	var certificate appmodels.Certificate
+	var privateKey appmodels.PrivateKey
+	if secretName.Type() == sdsmodels.ClientSecretType {
+		certificate, privateKey, err = issuerController.NewClientCertificate(secretName.Name())
+	} else {
+		certificate, privateKey, err = issuerController.NewServerCertificate(secretName.Name())
+	}
+	if err != nil {
+		return fmt.Errorf("[%s:update]: %w", name, err)
+	}
+
+	chain, err := certificateChain(organizationController, certificate, issuerController.Certificate())
+	if err != nil {
+		return fmt.Errorf("[%s:update]: %w", name, err)
+	}
+
+	secret, err := sdsutils.ToTlsCertificateSecret(r.certManager, name, chain, privateKey)
+	if err != nil {
+		return fmt.Errorf("[%s:update]: %w", name, err)
+	}
+	if err := r.cache.UpdateResource(name, secret); err != nil {
+		return fmt.Errorf("[%s:update]: %w", name, err)
+	}
+	r.certificates[name] = certificate
+	log.Printf("[%s:update]: Issued certificate: %s, expires %s", name, certificate.SerialNumber(), certificate.NotAfter().Format(time.RFC3339))
+	return nil
+}
+
+// certificateChain returns the certificate and its issuers up to, but not
+// including, the root certificate
+//   - organizationController: The organization who owns the certificates
+//   - certificate: The leaf certificate
+//   - issuer: The certificate which signed the leaf certificate
+func certificateChain(
+	organizationController appmodels.OrganizationController,
+	certificate appmodels.Certificate,
+	issuer appmodels.Certificate,
+) ([]appmodels.Certificate, error) {
+	chain := []appmodels.Certificate{certificate}
+	for !issuer.IsSelfSigned() {
+		chain = append(chain, issuer)
+		if issuer.SignedBy() == nil || issuer.SignedBy().Cmp(issuer.SerialNumber()) == 0 {
+			break
+		}
+		parent, err := organizationController.Certificate(issuer.SignedBy())
+		if err != nil {
+			return nil, fmt.Errorf("certificateChain: %w", err)
+		}
+		issuer = parent
+	}
+	return chain, nil
+}
+
+// NewSecretController creates a controller which issues certificates for
+// Envoy secrets
+//   - appController: The application controller used to issue certificates
+//   - certManager: The certificate manager
+func NewSecretController(
+	appController appmodels.ApplicationController,
+	certManager managers.CertificateManager,
+) *CertSecretController {
+	return &CertSecretController{
+		appController: appController,
+		certManager:   certManager,
+		cache:         cache.NewLinearCache(resource.SecretType),
+		names:         make(map[string]sdsmodels.SecretName),
+		certificates:  make(map[string]appmodels.Certificate),
+		bundles:       make(map[string][]appmodels.Certificate),
+	}
+}
+
+var _ sdsmodels.SecretController = (*CertSecretController)(nil)
@@ -0,0 +1,152 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdscontrollers_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"testing"
+	"time"
+
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdscontrollers"
+)
+
+func newTestSecretController(t *testing.T, expiration time.Duration) (*sdscontrollers.CertSecretController, appmodels.OrganizationController, appmodels.Certificate, appmodels.PrivateKeyRepository) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := filerepository.NewCollection(certManager, managers.NewFileManager(), t.TempDir())
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		expiration,
+	)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(appmodels.NewSerialNumber(10), "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(appmodels.NewSerialNumber(10))
+	require.NoError(t, err)
+	organizationController.SetExpirationDuration(time.Hour)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+	return sdscontrollers.NewSecretController(appController, certManager), organizationController, root, repository.PrivateKey
+}
+
+func secretCertificates(t *testing.T, secret *tlsv3.Secret) []*x509.Certificate {
+	data := secret.GetTlsCertificate().GetCertificateChain().GetInlineBytes()
+	if secret.GetValidationContext() != nil {
+		data = secret.GetValidationContext().GetTrustedCa().GetInlineBytes()
+	}
+	var result []*x509.Certificate
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			return result
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		require.NoError(t, err)
+		result = append(result, certificate)
+		data = rest
+	}
+}
+
+func cachedSecret(t *testing.T, controller *sdscontrollers.CertSecretController, name string) *tlsv3.Secret {
+	resources := controller.Cache().(*cache.LinearCache).GetResources()
+	require.Contains(t, resources, name)
+	return resources[name].(*tlsv3.Secret)
+}
+
+func TestSecretController_Watch(t *testing.T) {
+	controller, organizationController, root, privateKeyRepository := newTestSecretController(t, time.Hour)
+	intermediate, intermediateKey, err := mustCertificateController(t, organizationController, root).NewIntermediateCertificate("Test Intermediate")
+	require.NoError(t, err)
+	_, err = privateKeyRepository.Save(intermediateKey)
+	require.NoError(t, err)
+
+	serverName := fmt.Sprintf("server/10/%s/www.example.com", intermediate.SerialNumber())
+	clientName := fmt.Sprintf("client/10/%s/client", root.SerialNumber())
+	require.NoError(t, controller.Watch([]string{serverName, clientName}))
+
+	server := secretCertificates(t, cachedSecret(t, controller, serverName))
+	require.Len(t, server, 2)
+	assert.Equal(t, []string{"www.example.com"}, server[0].DNSNames)
+	assert.Equal(t, intermediate.Certificate().Raw, server[1].Raw)
+	assert.NotEmpty(t, cachedSecret(t, controller, serverName).GetTlsCertificate().GetPrivateKey().GetInlineBytes())
+
+	client := secretCertificates(t, cachedSecret(t, controller, clientName))
+	require.Len(t, client, 1)
+	assert.Equal(t, "client", client[0].Subject.CommonName)
+	assert.Contains(t, client[0].ExtKeyUsage, x509.ExtKeyUsageClientAuth)
+
+	// Watching again does not issue a new certificate
+	require.NoError(t, controller.Watch([]string{serverName}))
+	assert.Equal(t, server[0].Raw, secretCertificates(t, cachedSecret(t, controller, serverName))[0].Raw)
+}
+
+func TestSecretController_WatchInvalid(t *testing.T) {
+	controller, _, root, _ := newTestSecretController(t, time.Hour)
+	err := controller.Watch([]string{"default", "server/10/1/www.example.com", "ca/20", fmt.Sprintf("server/10/%s/www.example.com", root.SerialNumber())})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "default")
+	assert.Len(t, controller.Cache().(*cache.LinearCache).GetResources(), 1)
+}
+
+func TestSecretController_RefreshRollover(t *testing.T) {
+	_, _, root, _ := newTestSecretController(t, time.Hour)
+	_, _, newRoot, _ := newTestSecretController(t, time.Hour)
+
+	organizationController := new(appmocks.MockOrganizationController)
+	organizationController.On("CertificateCollection").Return([]appmodels.Certificate{root}, nil).Twice()
+	organizationController.On("CertificateCollection").Return([]appmodels.Certificate{root, newRoot}, nil).Once()
+	appController := new(appmocks.MockApplicationController)
+	appController.On("OrganizationController", mock.Anything).Return(organizationController, nil)
+
+	controller := sdscontrollers.NewSecretController(appController, managers.NewCertificateManager(managers.NewRandomManager()))
+	require.NoError(t, controller.Watch([]string{"ca/10"}))
+	first := cachedSecret(t, controller, "ca/10")
+	assert.Equal(t, []*x509.Certificate{root.Certificate()}, secretCertificates(t, first))
+
+	// Nothing changed
+	require.NoError(t, controller.Refresh())
+	assert.Same(t, first, cachedSecret(t, controller, "ca/10"))
+
+	// A new root certificate is published
+	require.NoError(t, controller.Refresh())
+	assert.Equal(t, []*x509.Certificate{root.Certificate(), newRoot.Certificate()}, secretCertificates(t, cachedSecret(t, controller, "ca/10")))
+	organizationController.AssertExpectations(t)
+}
+
+func TestSecretController_RefreshRenewal(t *testing.T) {
+	controller, _, root, _ := newTestSecretController(t, 2*time.Second)
+	name := fmt.Sprintf("server/10/%s/www.example.com", root.SerialNumber())
+	require.NoError(t, controller.Watch([]string{name}))
+	first := secretCertificates(t, cachedSecret(t, controller, name))[0]
+
+	require.NoError(t, controller.Refresh())
+	assert.Equal(t, first.Raw, secretCertificates(t, cachedSecret(t, controller, name))[0].Raw)
+
+	// Past the half-life the certificate is renewed
+	time.Sleep(time.Until(first.NotBefore.Add(first.NotAfter.Sub(first.NotBefore) / 2)))
+	require.NoError(t, controller.Refresh())
+	assert.NotEqual(t, first.Raw, secretCertificates(t, cachedSecret(t, controller, name))[0].Raw)
+}
+
+func mustCertificateController(t *testing.T, organizationController appmodels.OrganizationController, certificate appmodels.Certificate) appmodels.CertificateController {
+	certificateController, err := organizationController.CertificateController(certificate.SerialNumber())
+	require.NoError(t, err)
+	return certificateController
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdsmodels
+
+import (
+	"math/big"
+
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+)
+
+// SecretType is the kind of Envoy secret a secret name refers to
+type SecretType string
+
+const (
+
+	// ServerSecretType is a tls_certificate secret with a server certificate
+	ServerSecretType SecretType = "server"
+
+	// ClientSecretType is a tls_certificate secret with a client certificate
+	ClientSecretType SecretType = "client"
+
+	// TrustSecretType is a validation_context secret with the root
+	// certificates of the organization
+	TrustSecretType SecretType = "ca"
+)
+
+// SecretName identifies the certificate behind an Envoy secret. Secret names
+// are formatted as:
+//   - "server/{organization}/{issuer}/{dnsName}"
+//   - "client/{organization}/{issuer}/{commonName}"
+//   - "ca/{organization}"
+type SecretName interface {
+	Type() SecretType
+	OrganizationID() *big.Int
+
+	// IssuerSerialNumber returns the serial number of the issuing
+	// certificate, or nil for trust bundles
+	IssuerSerialNumber() *big.Int
+
+	// Name returns the DNS name of a server certificate or the common name of
+	// a client certificate, or an empty string for trust bundles
+	Name() string
+}
+
+// SecretController issues the certificates behind Envoy secrets and keeps
+// them up to date in the xDS cache
+type SecretController interface {
+
+	// Cache returns the xDS cache with the current secrets
+	Cache() cache.Cache
+
+	// Watch makes sure the secrets are in the cache. Certificates are issued
+	// when a secret is requested for the first time.
+	Watch(names []string) error
+
+	// Refresh renews certificates which are past their half-life and updates
+	// trust bundles which have changed
+	Refresh() error
+}
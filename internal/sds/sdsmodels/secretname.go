@@ -0,0 +1,53 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdsmodels
+
+import (
+	"math/big"
+)
+
+// SecretNameModel implements SecretName
+type SecretNameModel struct {
+	secretType   SecretType
+	organization *big.Int
+	issuer       *big.Int
+	name         string
+}
+
+func (n *SecretNameModel) Type() SecretType {
+	return n.secretType
+}
+
+func (n *SecretNameModel) OrganizationID() *big.Int {
+	return n.organization
+}
+
+func (n *SecretNameModel) IssuerSerialNumber() *big.Int {
+	return n.issuer
+}
+
+func (n *SecretNameModel) Name() string {
+	return n.name
+}
+
+// NewSecretName creates a secret name model
+//   - secretType: The kind of the secret
+//   - organization: The organization ID
+//   - issuer: The serial number of the issuing certificate, or nil
+//   - name: The DNS name or common name, or an empty string
+func NewSecretName(
+	secretType SecretType,
+	organization *big.Int,
+	issuer *big.Int,
+	name string,
+) *SecretNameModel {
+	return &SecretNameModel{
+		secretType:   secretType,
+		organization: organization,
+		issuer:       issuer,
+		name:         name,
+	}
+}
+
+// Compile time assertion for implementing the interface
+var _ SecretName = (*SecretNameModel)(nil)
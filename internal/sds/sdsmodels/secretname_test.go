@@ -0,0 +1,20 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdsmodels_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdsmodels"
+)
+
+func TestNewSecretName(t *testing.T) {
+	name := sdsmodels.NewSecretName(sdsmodels.ServerSecretType, big.NewInt(10), big.NewInt(20), "www.example.com")
+	assert.Equal(t, sdsmodels.ServerSecretType, name.Type())
+	assert.Equal(t, big.NewInt(10), name.OrganizationID())
+	assert.Equal(t, big.NewInt(20), name.IssuerSerialNumber())
+	assert.Equal(t, "www.example.com", name.Name())
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdsserver
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// UnixAddressPrefix is the prefix of SDS addresses which are Unix socket paths
+const UnixAddressPrefix = "unix:"
+
+// Listen opens the listener of the SDS server. Addresses prefixed with
+// "unix:" are Unix sockets which only the owner and the group of the
+// process can connect to. Other addresses are TCP addresses, which any
+// client who can reach them could use to fetch private keys, so they are
+// refused unless allowTcp is set.
+//   - address: The address, e.g. unix:/run/gocertcenter/sds.sock
+//   - allowTcp: If TCP addresses are allowed
+func Listen(address string, allowTcp bool) (net.Listener, error) {
+	if socketPath, ok := strings.CutPrefix(address, UnixAddressPrefix); ok {
+		return listenUnix(socketPath)
+	}
+	if !allowTcp {
+		return nil, fmt.Errorf("Listen: the SDS server does not authenticate clients, so TCP address %q must be explicitly allowed; use %s<path> for a Unix socket", address, UnixAddressPrefix)
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("Listen: %w", err)
+	}
+	return listener, nil
+}
+
+// listenUnix listens on a Unix socket, replacing a stale socket left by a
+// previous process, and restricts it to the owner and the group
+func listenUnix(socketPath string) (net.Listener, error) {
+	if socketPath == "" {
+		return nil, fmt.Errorf("Listen: empty socket path")
+	}
+	if info, err := os.Lstat(socketPath); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("Listen: not a socket: %s", socketPath)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, fmt.Errorf("Listen: %w", err)
+		}
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("Listen: %w", err)
+	}
+	if err := os.Chmod(socketPath, 0660); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("Listen: %w", err)
+	}
+	return listener, nil
+}
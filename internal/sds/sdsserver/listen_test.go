@@ -0,0 +1,51 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdsserver_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdsserver"
+)
+
+func TestListen_UnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "sds.sock")
+
+	listener, err := sdsserver.Listen(sdsserver.UnixAddressPrefix+socketPath, false)
+	require.NoError(t, err)
+	assert.Equal(t, "unix", listener.Addr().Network())
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0660), info.Mode().Perm())
+
+	// A stale socket left by a previous process is replaced
+	stale, err := sdsserver.Listen(sdsserver.UnixAddressPrefix+socketPath, false)
+	require.NoError(t, err)
+	_ = stale.Close()
+	_ = listener.Close()
+}
+
+func TestListen_NotSocket(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "sds.sock")
+	require.NoError(t, os.WriteFile(filePath, []byte("data"), 0600))
+
+	_, err := sdsserver.Listen(sdsserver.UnixAddressPrefix+filePath, false)
+	assert.ErrorContains(t, err, "not a socket")
+}
+
+func TestListen_TcpRefused(t *testing.T) {
+	_, err := sdsserver.Listen("127.0.0.1:0", false)
+	assert.ErrorContains(t, err, "must be explicitly allowed")
+}
+
+func TestListen_TcpAllowed(t *testing.T) {
+	listener, err := sdsserver.Listen("127.0.0.1:0", true)
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", listener.Addr().Network())
+	_ = listener.Close()
+}
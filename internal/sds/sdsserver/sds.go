@@ -0,0 +1,49 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdsserver
+
+import (
+	"context"
+	"log"
+
+	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	secretv3 "github.com/envoyproxy/go-control-plane/envoy/service/secret/v3"
+	serverv3 "github.com/envoyproxy/go-control-plane/pkg/server/v3"
+	"google.golang.org/grpc"
+
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdsmodels"
+)
+
+// NewCallbacks returns xDS server callbacks which issue the requested
+// secrets before the server looks them up from the cache. Secrets which
+// cannot be issued are logged and left out of the response.
+func NewCallbacks(controller sdsmodels.SecretController) serverv3.CallbackFuncs {
+	watch := func(names []string) {
+		if err := controller.Watch(names); err != nil {
+			log.Printf("[SDS]: %v", err)
+		}
+	}
+	return serverv3.CallbackFuncs{
+		StreamRequestFunc: func(_ int64, request *discoveryv3.DiscoveryRequest) error {
+			watch(request.GetResourceNames())
+			return nil
+		},
+		StreamDeltaRequestFunc: func(_ int64, request *discoveryv3.DeltaDiscoveryRequest) error {
+			watch(request.GetResourceNamesSubscribe())
+			return nil
+		},
+	}
+}
+
+// NewGrpcServer creates a gRPC server with the Envoy Secret Discovery
+// Service. The server does not authenticate clients, so it must only be
+// reachable by trusted Envoy proxies, e.g. on a Unix socket opened with
+// Listen.
+//   - ctx: The context which stops the xDS server
+//   - controller: The controller which issues secrets
+func NewGrpcServer(ctx context.Context, controller sdsmodels.SecretController) *grpc.Server {
+	xdsServer := serverv3.NewServer(ctx, controller.Cache(), NewCallbacks(controller))
+	server := grpc.NewServer()
+	secretv3.RegisterSecretDiscoveryServiceServer(server, xdsServer)
+	return server
+}
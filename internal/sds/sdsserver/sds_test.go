@@ -0,0 +1,129 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdsserver_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	secretv3 "github.com/envoyproxy/go-control-plane/envoy/service/secret/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appcontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apprepositories/filerepository"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdscontrollers"
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdsserver"
+)
+
+// startTestSdsServer starts an SDS server with one organization and a root
+// certificate, and returns a connected client and the controller
+func startTestSdsServer(t *testing.T, expiration time.Duration) (secretv3.SecretDiscoveryServiceClient, *sdscontrollers.CertSecretController, appmodels.Certificate) {
+	randomManager := managers.NewRandomManager()
+	certManager := managers.NewCertificateManager(randomManager)
+	repository := filerepository.NewCollection(certManager, managers.NewFileManager(), t.TempDir())
+	appController := appcontrollers.NewApplicationController(
+		repository.Organization,
+		repository.Certificate,
+		repository.PrivateKey,
+		repository.UnitOfWork,
+		certManager,
+		randomManager,
+		expiration,
+	)
+	_, err := appController.NewOrganization(appmodels.NewOrganization(appmodels.NewSerialNumber(10), "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY))
+	require.NoError(t, err)
+	organizationController, err := appController.OrganizationController(appmodels.NewSerialNumber(10))
+	require.NoError(t, err)
+	organizationController.SetExpirationDuration(time.Hour)
+	root, err := organizationController.NewRootCertificate("Test Root")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	controller := sdscontrollers.NewSecretController(appController, certManager)
+	server := sdsserver.NewGrpcServer(ctx, controller)
+	listener, err := sdsserver.Listen(sdsserver.UnixAddressPrefix+filepath.Join(t.TempDir(), "sds.sock"), false)
+	require.NoError(t, err)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(sdsserver.UnixAddressPrefix+listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return secretv3.NewSecretDiscoveryServiceClient(conn), controller, root
+}
+
+func receiveSecrets(t *testing.T, stream secretv3.SecretDiscoveryService_StreamSecretsClient) (*discoveryv3.DiscoveryResponse, map[string]*tlsv3.Secret) {
+	response, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, resource.SecretType, response.GetTypeUrl())
+	secrets := make(map[string]*tlsv3.Secret)
+	for _, item := range response.GetResources() {
+		secret := new(tlsv3.Secret)
+		require.NoError(t, item.UnmarshalTo(secret))
+		secrets[secret.GetName()] = secret
+	}
+	return response, secrets
+}
+
+func leafCertificate(t *testing.T, secret *tlsv3.Secret) *x509.Certificate {
+	block, _ := pem.Decode(secret.GetTlsCertificate().GetCertificateChain().GetInlineBytes())
+	require.NotNil(t, block)
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return certificate
+}
+
+func TestSds_StreamSecrets(t *testing.T) {
+	client, controller, root := startTestSdsServer(t, 2*time.Second)
+	name := fmt.Sprintf("server/10/%s/www.example.com", root.SerialNumber())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := client.StreamSecrets(ctx)
+	require.NoError(t, err)
+
+	node := &corev3.Node{Id: "envoy"}
+	require.NoError(t, stream.Send(&discoveryv3.DiscoveryRequest{
+		Node:          node,
+		TypeUrl:       resource.SecretType,
+		ResourceNames: []string{name, "unknown"},
+	}))
+	response, secrets := receiveSecrets(t, stream)
+	require.Len(t, secrets, 1)
+	first := leafCertificate(t, secrets[name])
+	assert.Equal(t, []string{"www.example.com"}, first.DNSNames)
+	assert.NoError(t, first.CheckSignatureFrom(root.Certificate()))
+	assert.NotEmpty(t, secrets[name].GetTlsCertificate().GetPrivateKey().GetInlineBytes())
+
+	// Acknowledge the response and wait for the renewal push
+	require.NoError(t, stream.Send(&discoveryv3.DiscoveryRequest{
+		Node:          node,
+		TypeUrl:       resource.SecretType,
+		ResourceNames: []string{name, "unknown"},
+		VersionInfo:   response.GetVersionInfo(),
+		ResponseNonce: response.GetNonce(),
+	}))
+	time.Sleep(time.Until(first.NotBefore.Add(first.NotAfter.Sub(first.NotBefore) / 2)))
+	require.NoError(t, controller.Refresh())
+
+	_, secrets = receiveSecrets(t, stream)
+	require.Len(t, secrets, 1)
+	second := leafCertificate(t, secrets[name])
+	assert.NotEqual(t, first.SerialNumber, second.SerialNumber)
+	assert.Equal(t, []string{"www.example.com"}, second.DNSNames)
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdsutils
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+)
+
+// ShouldRenew checks if a certificate is past its half-life and should be
+// renewed
+func ShouldRenew(certificate appmodels.Certificate, now time.Time) bool {
+	return apputils.ShouldRenewCertificate(certificate, now)
+}
+
+// SameCertificates checks if two lists have the same certificates in the
+// same order
+func SameCertificates(a, b []appmodels.Certificate) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, certificate := range a {
+		if !bytes.Equal(certificate.Certificate().Raw, b[i].Certificate().Raw) {
+			return false
+		}
+	}
+	return true
+}
+
+// CertificatesToPEMBytes returns the certificates as concatenated PEM blocks
+func CertificatesToPEMBytes(certificates []appmodels.Certificate) []byte {
+	var buffer bytes.Buffer
+	for _, certificate := range certificates {
+		buffer.Write(apputils.CertificateToPEMBytes(certificate))
+	}
+	return buffer.Bytes()
+}
+
+// ToTlsCertificateSecret converts a certificate chain and its private key to
+// an Envoy tls_certificate secret
+//   - certManager: The certificate manager used to encode the key
+//   - name: The secret name
+//   - chain: The certificate chain, the leaf certificate first
+//   - privateKey: The private key of the leaf certificate
+func ToTlsCertificateSecret(
+	certManager managers.CertificateManager,
+	name string,
+	chain []appmodels.Certificate,
+	privateKey appmodels.PrivateKey,
+) (*tlsv3.Secret, error) {
+	keyBytes, err := apputils.MarshalPrivateKeyAsPEM(certManager, privateKey.PrivateKey())
+	if err != nil {
+		return nil, fmt.Errorf("ToTlsCertificateSecret: %s: %w", name, err)
+	}
+	return &tlsv3.Secret{
+		Name: name,
+		Type: &tlsv3.Secret_TlsCertificate{
+			TlsCertificate: &tlsv3.TlsCertificate{
+				CertificateChain: inlineBytes(CertificatesToPEMBytes(chain)),
+				PrivateKey:       inlineBytes(keyBytes),
+			},
+		},
+	}, nil
+}
+
+// ToValidationContextSecret converts trusted CA certificates to an Envoy
+// validation_context secret
+//   - name: The secret name
+//   - certificates: The trusted CA certificates
+func ToValidationContextSecret(name string, certificates []appmodels.Certificate) *tlsv3.Secret {
+	return &tlsv3.Secret{
+		Name: name,
+		Type: &tlsv3.Secret_ValidationContext{
+			ValidationContext: &tlsv3.CertificateValidationContext{
+				TrustedCa: inlineBytes(CertificatesToPEMBytes(certificates)),
+			},
+		},
+	}
+}
+
+func inlineBytes(data []byte) *corev3.DataSource {
+	return &corev3.DataSource{
+		Specifier: &corev3.DataSource_InlineBytes{
+			InlineBytes: data,
+		},
+	}
+}
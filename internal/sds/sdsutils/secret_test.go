@@ -0,0 +1,90 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdsutils_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/appmocks"
+	"github.com/hyperifyio/gocertcenter/internal/app/appmodels"
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/common/managers"
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdsutils"
+)
+
+func newTestRoot(t *testing.T, serialNumber int64) (appmodels.Certificate, appmodels.PrivateKey) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	organization := appmodels.NewOrganization(big.NewInt(10), "test", []string{"Test Org"}, appmodels.NIL_SIGNATURE_ALGORITHM, "", appmodels.NIL_KEY_RETENTION_POLICY)
+	privateKey, err := apputils.GeneratePrivateKey(organization.ID(), big.NewInt(serialNumber), appmodels.ECDSA_P256)
+	require.NoError(t, err)
+	root, err := apputils.NewRootCertificate(certManager, big.NewInt(serialNumber), organization, time.Hour, appmodels.NIL_SIGNATURE_ALGORITHM, privateKey, "Test Root")
+	require.NoError(t, err)
+	return root, privateKey
+}
+
+func decodePEMCertificates(t *testing.T, data []byte) []*x509.Certificate {
+	var result []*x509.Certificate
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		require.NoError(t, err)
+		result = append(result, certificate)
+		data = rest
+	}
+	return result
+}
+
+func TestShouldRenew(t *testing.T) {
+	now := time.Now()
+	certificate := new(appmocks.MockCertificate)
+	certificate.On("NotBefore").Return(now.Add(-time.Hour))
+	certificate.On("NotAfter").Return(now.Add(3 * time.Hour))
+	assert.False(t, sdsutils.ShouldRenew(certificate, now))
+	assert.True(t, sdsutils.ShouldRenew(certificate, now.Add(time.Hour)))
+}
+
+func TestSameCertificates(t *testing.T) {
+	first, _ := newTestRoot(t, 1)
+	second, _ := newTestRoot(t, 2)
+	assert.True(t, sdsutils.SameCertificates([]appmodels.Certificate{first, second}, []appmodels.Certificate{first, second}))
+	assert.False(t, sdsutils.SameCertificates([]appmodels.Certificate{first, second}, []appmodels.Certificate{second, first}))
+	assert.False(t, sdsutils.SameCertificates([]appmodels.Certificate{first}, []appmodels.Certificate{first, second}))
+	assert.True(t, sdsutils.SameCertificates(nil, nil))
+}
+
+func TestToTlsCertificateSecret(t *testing.T) {
+	certManager := managers.NewCertificateManager(managers.NewRandomManager())
+	root, privateKey := newTestRoot(t, 1)
+
+	secret, err := sdsutils.ToTlsCertificateSecret(certManager, "server/10/1/www.example.com", []appmodels.Certificate{root}, privateKey)
+	require.NoError(t, err)
+	assert.Equal(t, "server/10/1/www.example.com", secret.GetName())
+
+	tlsCertificate := secret.GetTlsCertificate()
+	require.NotNil(t, tlsCertificate)
+	assert.Equal(t, []*x509.Certificate{root.Certificate()}, decodePEMCertificates(t, tlsCertificate.GetCertificateChain().GetInlineBytes()))
+
+	key, _, err := apputils.ParsePrivateKeyFromPEMBytes(certManager, tlsCertificate.GetPrivateKey().GetInlineBytes())
+	require.NoError(t, err)
+	assert.Equal(t, privateKey.PrivateKey(), key)
+}
+
+func TestToValidationContextSecret(t *testing.T) {
+	first, _ := newTestRoot(t, 1)
+	second, _ := newTestRoot(t, 2)
+
+	secret := sdsutils.ToValidationContextSecret("ca/10", []appmodels.Certificate{first, second})
+	assert.Equal(t, "ca/10", secret.GetName())
+	require.NotNil(t, secret.GetValidationContext())
+	assert.Equal(t, []*x509.Certificate{first.Certificate(), second.Certificate()}, decodePEMCertificates(t, secret.GetValidationContext().GetTrustedCa().GetInlineBytes()))
+}
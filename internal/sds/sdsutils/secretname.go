@@ -0,0 +1,56 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdsutils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperifyio/gocertcenter/internal/app/apputils"
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdsmodels"
+)
+
+// ParseSecretName parses an Envoy secret name, e.g.
+// "server/{organization}/{issuer}/{dnsName}" or "ca/{organization}"
+func ParseSecretName(value string) (sdsmodels.SecretName, error) {
+	parts := strings.SplitN(value, "/", 4)
+	secretType := sdsmodels.SecretType(parts[0])
+
+	switch secretType {
+
+	case sdsmodels.TrustSecretType:
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("ParseSecretName: %s: expected %s/{organization}", value, secretType)
+		}
+		organization, err := apputils.ParseBigInt(parts[1], 10)
+		if err != nil {
+			return nil, fmt.Errorf("ParseSecretName: %s: organization: %w", value, err)
+		}
+		return sdsmodels.NewSecretName(secretType, organization, nil, ""), nil
+
+	case sdsmodels.ServerSecretType, sdsmodels.ClientSecretType:
+		if len(parts) != 4 || parts[3] == "" {
+			return nil, fmt.Errorf("ParseSecretName: %s: expected %s/{organization}/{issuer}/{name}", value, secretType)
+		}
+		organization, err := apputils.ParseBigInt(parts[1], 10)
+		if err != nil {
+			return nil, fmt.Errorf("ParseSecretName: %s: organization: %w", value, err)
+		}
+		issuer, err := apputils.ParseBigInt(parts[2], 10)
+		if err != nil {
+			return nil, fmt.Errorf("ParseSecretName: %s: issuer: %w", value, err)
+		}
+		return sdsmodels.NewSecretName(secretType, organization, issuer, parts[3]), nil
+
+	default:
+		return nil, fmt.Errorf("ParseSecretName: %s: unsupported secret type: %s", value, secretType)
+	}
+}
+
+// FormatSecretName formats a secret name as requested by Envoy
+func FormatSecretName(name sdsmodels.SecretName) string {
+	if name.Type() == sdsmodels.TrustSecretType {
+		return fmt.Sprintf("%s/%s", name.Type(), name.OrganizationID())
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", name.Type(), name.OrganizationID(), name.IssuerSerialNumber(), name.Name())
+}
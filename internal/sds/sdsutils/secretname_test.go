@@ -0,0 +1,59 @@
+// Copyright (c) 2024. Heusala Group Oy <info@heusalagroup.fi>. All rights reserved.
+
+package sdsutils_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdsmodels"
+	"github.com/hyperifyio/gocertcenter/internal/sds/sdsutils"
+)
+
+func TestParseSecretName(t *testing.T) {
+	name, err := sdsutils.ParseSecretName("server/10/20/www.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, sdsmodels.ServerSecretType, name.Type())
+	assert.Equal(t, big.NewInt(10), name.OrganizationID())
+	assert.Equal(t, big.NewInt(20), name.IssuerSerialNumber())
+	assert.Equal(t, "www.example.com", name.Name())
+
+	name, err = sdsutils.ParseSecretName("client/10/20/CN/with/slashes")
+	require.NoError(t, err)
+	assert.Equal(t, sdsmodels.ClientSecretType, name.Type())
+	assert.Equal(t, "CN/with/slashes", name.Name())
+
+	name, err = sdsutils.ParseSecretName("ca/10")
+	require.NoError(t, err)
+	assert.Equal(t, sdsmodels.TrustSecretType, name.Type())
+	assert.Equal(t, big.NewInt(10), name.OrganizationID())
+	assert.Nil(t, name.IssuerSerialNumber())
+}
+
+func TestParseSecretName_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"default",
+		"ca",
+		"ca/10/20",
+		"ca/org",
+		"server/10/20",
+		"server/10/20/",
+		"server/org/20/www.example.com",
+		"client/10/issuer/client",
+	} {
+		_, err := sdsutils.ParseSecretName(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestFormatSecretName(t *testing.T) {
+	for _, value := range []string{"server/10/20/www.example.com", "client/10/20/client", "ca/10"} {
+		name, err := sdsutils.ParseSecretName(value)
+		require.NoError(t, err)
+		assert.Equal(t, value, sdsutils.FormatSecretName(name))
+	}
+}